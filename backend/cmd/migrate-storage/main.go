@@ -0,0 +1,68 @@
+// Command migrate-storage moves existing attachments and assessment reports into the storage
+// backend selected by the attachment_storage system setting.
+//
+// Usage:
+//
+//	migrate-storage [-dry-run] [-delete-source]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Count the files that would be moved without copying anything")
+	deleteSource := flag.Bool("delete-source", false, "Delete each source file once it has been copied")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+	cfg := config.Load()
+	utils.InitLogger(cfg.GoEnv == "development")
+	if _, err := config.LoadVaultSecrets(context.Background(), cfg); err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Failed to load secrets from Vault")
+	}
+
+	if err := database.Connect(cfg.DatabaseDSN(), false); err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	defer database.Close()
+
+	// Unlike the server, refuse to fall back to local disk: a typo in the settings would
+	// otherwise "migrate" nothing and report success
+	if err := services.InitAttachmentStorage(database.GetDB(), services.AttachmentStorageCredentials{
+		S3AccessKeyID:     cfg.StorageS3AccessKeyID,
+		S3SecretAccessKey: cfg.StorageS3SecretAccessKey,
+		AzureAccountKey:   cfg.StorageAzureAccountKey,
+	}); err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Attachment storage misconfigured")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := services.MigrateAttachmentStorage(ctx, database.GetDB(), *dryRun, *deleteSource)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Attachment storage migration aborted")
+	}
+	if result != nil {
+		fmt.Printf("target=%s pending=%d moved=%d failed=%d dry_run=%t\n",
+			result.Target, result.Pending, result.Moved, result.Failed, result.DryRun)
+	}
+	if err != nil || (result != nil && result.Failed > 0) {
+		os.Exit(1)
+	}
+}
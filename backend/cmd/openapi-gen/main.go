@@ -0,0 +1,521 @@
+// Command openapi-gen generates the handler annotations the OpenAPI document is built from.
+// It reads every fiber handler of a package — functions and methods taking a *fiber.Ctx and
+// returning an error — and records its doc comment, its swag-style annotations (@Summary,
+// @Description, @Tags, @Param, @Success, @Failure) and the request body and query parameters
+// it reads, so endpoints are documented without maintaining a separate spec.
+//
+// Usage (from the handlers package, see go:generate in docs_handler.go):
+//
+//	openapi-gen [-dir .] [-out openapi_annotations_gen.go] [-var openAPIAnnotations]
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// handler is everything recorded about one handler
+type handler struct {
+	key         string // openapi.HandlerName of the handler
+	summary     string
+	description string
+	tags        []string
+	params      []param
+	responses   []response
+}
+
+type param struct {
+	name, in, typ, description, def string
+	required                        bool
+	model                           string // Go type expression
+}
+
+type response struct {
+	status      int
+	description string
+	model       string // Go type expression
+	array, file bool
+}
+
+// generator resolves type expressions of one package
+type generator struct {
+	pkg        string
+	types      map[string]bool   // Package-level type names
+	imports    map[string]string // Import name to path, across the package's files
+	usedImport map[string]bool
+}
+
+var (
+	paramPattern    = regexp.MustCompile(`^(\S+)\s+(\S+)\s+(\S+)\s+(true|false)(?:\s+"([^"]*)")?(?:\s+default:"([^"]*)")?`)
+	responsePattern = regexp.MustCompile(`^(\d{3})\s+\{(\w+)\}\s+(\S+)(?:\s+"([^"]*)")?`)
+	queryMethods    = map[string]string{"Query": "string", "QueryInt": "int", "QueryBool": "bool", "QueryFloat": "number"}
+	// successStatuses are the fiber constants of success statuses handlers set explicitly
+	successStatuses = map[string]int{"StatusCreated": 201, "StatusAccepted": 202, "StatusNoContent": 204}
+)
+
+// predeclared are the builtin types a type expression may use
+var predeclared = map[string]bool{
+	"bool": true, "string": true, "int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "float32": true,
+	"float64": true, "byte": true, "rune": true, "any": true,
+}
+
+func main() {
+	dir := flag.String("dir", ".", "Directory of the handlers package")
+	out := flag.String("out", "openapi_annotations_gen.go", "Output file, relative to -dir")
+	variable := flag.String("var", "openAPIAnnotations", "Name of the generated annotations variable")
+	flag.Parse()
+
+	source, err := generate(*dir, filepath.Base(*out), *variable)
+	if err != nil {
+		log.Fatalf("openapi-gen: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *out), source, 0o644); err != nil {
+		log.Fatalf("openapi-gen: %v", err)
+	}
+}
+
+// generate returns the source of the annotations file of the package in dir
+func generate(dir, outFile, variable string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != outFile
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	var pkg *ast.Package
+	for _, p := range pkgs {
+		pkg = p
+	}
+	g := &generator{pkg: pkg.Name, types: map[string]bool{}, imports: map[string]string{}, usedImport: map[string]bool{}}
+
+	var fileNames []string
+	for name := range pkg.Files {
+		fileNames = append(fileNames, name)
+	}
+	sort.Strings(fileNames)
+
+	for _, name := range fileNames {
+		file := pkg.Files[name]
+		for _, spec := range file.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			name := defaultImportName(path)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			g.imports[name] = path
+		}
+		for _, decl := range file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+				for _, spec := range gen.Specs {
+					g.types[spec.(*ast.TypeSpec).Name.Name] = true
+				}
+			}
+		}
+	}
+
+	var handlers []handler
+	for _, name := range fileNames {
+		for _, decl := range pkg.Files[name].Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			ctx := fiberCtxParam(fn)
+			if ctx == "" {
+				continue
+			}
+			if h, ok := g.handler(fn, ctx); ok {
+				handlers = append(handlers, h)
+			}
+		}
+	}
+	sort.Slice(handlers, func(i, j int) bool { return handlers[i].key < handlers[j].key })
+
+	return g.render(handlers, variable)
+}
+
+// fiberCtxParam returns the *fiber.Ctx parameter name of a handler, or "" when fn is not one
+func fiberCtxParam(fn *ast.FuncDecl) string {
+	params, results := fn.Type.Params.List, fn.Type.Results
+	if len(params) != 1 || len(params[0].Names) != 1 || results == nil || len(results.List) != 1 {
+		return ""
+	}
+	if types.ExprString(params[0].Type) != "*fiber.Ctx" || types.ExprString(results.List[0].Type) != "error" {
+		return ""
+	}
+	return params[0].Names[0].Name
+}
+
+// handler records the documentation of one handler, reporting false when there is none
+func (g *generator) handler(fn *ast.FuncDecl, ctx string) (handler, bool) {
+	h := handler{key: g.pkg + "." + fn.Name.Name}
+	if fn.Recv != nil && len(fn.Recv.List) == 1 {
+		recv := fn.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			h.key = g.pkg + ".(*" + types.ExprString(star.X) + ")." + fn.Name.Name
+		} else {
+			h.key = g.pkg + "." + types.ExprString(recv) + "." + fn.Name.Name
+		}
+	}
+
+	var prose []string
+	if fn.Doc != nil {
+		for _, line := range strings.Split(fn.Doc.Text(), "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "@") {
+				g.annotate(&h, line)
+			} else if line != "" && len(h.params) == 0 && len(h.responses) == 0 && h.summary == "" {
+				prose = append(prose, line)
+			}
+		}
+	}
+	if len(prose) > 0 {
+		summary, description := docSummary(fn.Name.Name, prose)
+		if h.summary == "" {
+			h.summary = summary
+		}
+		if h.description == "" {
+			h.description = description
+		}
+	}
+
+	g.inspectBody(&h, fn.Body, ctx)
+	return h, h.summary != "" || len(h.params) > 0 || len(h.responses) > 0 || len(h.tags) > 0
+}
+
+// annotate applies one swag annotation line
+func (g *generator) annotate(h *handler, line string) {
+	keyword, value, _ := strings.Cut(line, " ")
+	value = strings.TrimSpace(value)
+	switch keyword {
+	case "@Summary":
+		h.summary = value
+	case "@Description":
+		h.description = strings.TrimSpace(h.description + " " + value)
+	case "@Tags":
+		for _, tag := range strings.Split(value, ",") {
+			h.tags = append(h.tags, strings.TrimSpace(tag))
+		}
+	case "@Param":
+		m := paramPattern.FindStringSubmatch(value)
+		if m == nil {
+			return
+		}
+		p := param{name: m[1], in: m[2], required: m[4] == "true", description: m[5], def: m[6]}
+		if p.in == "body" {
+			p.model = g.resolve(m[3])
+		} else {
+			p.typ = m[3]
+		}
+		h.params = append(h.params, p)
+	case "@Success", "@Failure":
+		m := responsePattern.FindStringSubmatch(value)
+		if m == nil {
+			return
+		}
+		status, _ := strconv.Atoi(m[1])
+		r := response{status: status, description: m[4], array: m[2] == "array", file: m[2] == "file"}
+		if !r.file {
+			r.model = g.resolve(m[3])
+		}
+		h.responses = append(h.responses, r)
+	}
+}
+
+// inspectBody records the request body (BodyParser), query struct (QueryParser) and query
+// parameters (Query, QueryInt, ...) a handler reads
+func (g *generator) inspectBody(h *handler, body *ast.BlockStmt, ctx string) {
+	declared := map[string]ast.Expr{}
+	hasBody := false
+	for _, p := range h.params {
+		hasBody = hasBody || p.in == "body"
+	}
+	documented := map[string]bool{}
+	for _, p := range h.params {
+		documented[p.name] = true
+	}
+	hasSuccess := false
+	for _, r := range h.responses {
+		hasSuccess = hasSuccess || r.status < 300
+	}
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			// Closures may run with another context
+			return false
+		case *ast.ValueSpec:
+			if n.Type != nil {
+				for _, name := range n.Names {
+					declared[name.Name] = n.Type
+				}
+			}
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE && len(n.Lhs) == len(n.Rhs) {
+				for i, lhs := range n.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok {
+						if lit, ok := n.Rhs[i].(*ast.CompositeLit); ok && lit.Type != nil {
+							declared[ident.Name] = lit.Type
+						}
+					}
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || len(n.Args) == 0 {
+				return true
+			}
+			if recv, ok := sel.X.(*ast.Ident); !ok || recv.Name != ctx {
+				return true
+			}
+
+			switch method := sel.Sel.Name; method {
+			case "Status", "SendStatus":
+				status, ok := n.Args[0].(*ast.SelectorExpr)
+				if !ok || successStatuses[status.Sel.Name] == 0 || hasSuccess {
+					return true
+				}
+				hasSuccess = true
+				h.responses = append(h.responses, response{status: successStatuses[status.Sel.Name]})
+			case "BodyParser", "QueryParser":
+				target := n.Args[0]
+				if unary, ok := target.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+					target = unary.X
+				}
+				ident, ok := target.(*ast.Ident)
+				if !ok || declared[ident.Name] == nil {
+					return true
+				}
+				model := g.resolve(types.ExprString(declared[ident.Name]))
+				if model == "" {
+					return true
+				}
+				if method == "BodyParser" && !hasBody {
+					hasBody = true
+					h.params = append(h.params, param{in: "body", required: true, model: model})
+				} else if method == "QueryParser" {
+					h.params = append(h.params, param{in: "query", model: model})
+				}
+			default:
+				typ, ok := queryMethods[method]
+				if !ok {
+					return true
+				}
+				lit, ok := n.Args[0].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return true
+				}
+				name, _ := strconv.Unquote(lit.Value)
+				if !documented[name] {
+					documented[name] = true
+					h.params = append(h.params, param{name: name, in: "query", typ: typ})
+				}
+			}
+		}
+		return true
+	})
+}
+
+// resolve returns a Go expression of a type name usable from the generated file, or "" when
+// it cannot be referenced there
+func (g *generator) resolve(name string) string {
+	switch name {
+	case "file", "string", "int", "bool", "number", "integer", "boolean":
+		return ""
+	case "ErrorResponse":
+		if !g.types[name] {
+			// The error body written by middleware.ErrorHandler and its helpers
+			name = "middleware.ErrorResponse"
+		}
+	}
+
+	expr, err := parser.ParseExpr(name)
+	if err != nil {
+		return ""
+	}
+	valid := true
+	var qualifiers []string
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.SelectorExpr:
+			pkg, ok := n.X.(*ast.Ident)
+			if !ok || g.imports[pkg.Name] == "" {
+				valid = false
+			} else {
+				qualifiers = append(qualifiers, pkg.Name)
+			}
+			return false
+		case *ast.Ident:
+			if !g.types[n.Name] && !predeclared[n.Name] {
+				valid = false
+			}
+		case *ast.StructType, *ast.FuncType, *ast.ChanType:
+			// Anonymous types are not worth a named schema
+			valid = false
+		}
+		return valid
+	})
+	if !valid {
+		return ""
+	}
+	for _, qualifier := range qualifiers {
+		g.usedImport[qualifier] = true
+	}
+	return types.ExprString(expr)
+}
+
+// defaultImportName returns the name a package is imported as without an alias, skipping
+// major version suffixes such as github.com/gofiber/fiber/v2
+func defaultImportName(path string) string {
+	name := filepath.Base(path)
+	if version, ok := strings.CutPrefix(name, "v"); ok && strings.Contains(path, "/") {
+		if _, err := strconv.Atoi(version); err == nil {
+			return filepath.Base(filepath.Dir(path))
+		}
+	}
+	return name
+}
+
+// docSummary turns a Go doc comment into an operation summary and description. The summary
+// is the first sentence, so "GetVulnerability retrieves a vulnerability by ID" becomes
+// "Retrieves a vulnerability by ID"; lines that do not continue it describe the operation.
+func docSummary(funcName string, lines []string) (string, string) {
+	first := 1
+	for first < len(lines) && !strings.HasSuffix(lines[first-1], ".") && startsLower(lines[first]) {
+		first++
+	}
+	summary := strings.TrimSpace(strings.TrimPrefix(strings.Join(lines[:first], " "), funcName))
+	summary = strings.TrimSuffix(summary, ".")
+	if summary != "" {
+		runes := []rune(summary)
+		runes[0] = unicode.ToUpper(runes[0])
+		summary = string(runes)
+	}
+	return summary, strings.Join(lines[first:], " ")
+}
+
+// startsLower reports whether s starts with a lower-case letter
+func startsLower(s string) bool {
+	for _, r := range s {
+		return unicode.IsLower(r)
+	}
+	return false
+}
+
+// render writes the generated file
+func (g *generator) render(handlers []handler, variable string) ([]byte, error) {
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "// %s documents the handlers of this package, keyed by openapi.HandlerName\n", variable)
+	fmt.Fprintf(&body, "var %s = map[string]openapi.Annotation{\n", variable)
+	usesReflect := false
+	for _, h := range handlers {
+		fmt.Fprintf(&body, "%q: {\n", h.key)
+		if h.summary != "" {
+			fmt.Fprintf(&body, "Summary: %q,\n", h.summary)
+		}
+		if h.description != "" {
+			fmt.Fprintf(&body, "Description: %q,\n", h.description)
+		}
+		if len(h.tags) > 0 {
+			fmt.Fprintf(&body, "Tags: %#v,\n", h.tags)
+		}
+		if len(h.params) > 0 {
+			body.WriteString("Params: []openapi.ParamAnnotation{\n")
+			for _, p := range h.params {
+				body.WriteString("{")
+				fields := []string{}
+				if p.name != "" {
+					fields = append(fields, fmt.Sprintf("Name: %q", p.name))
+				}
+				fields = append(fields, fmt.Sprintf("In: %q", p.in))
+				if p.typ != "" {
+					fields = append(fields, fmt.Sprintf("Type: %q", p.typ))
+				}
+				if p.required {
+					fields = append(fields, "Required: true")
+				}
+				if p.description != "" {
+					fields = append(fields, fmt.Sprintf("Description: %q", p.description))
+				}
+				if p.def != "" {
+					fields = append(fields, fmt.Sprintf("Default: %q", p.def))
+				}
+				if p.model != "" {
+					usesReflect = true
+					fields = append(fields, fmt.Sprintf("Model: reflect.TypeOf((*%s)(nil)).Elem()", p.model))
+				}
+				body.WriteString(strings.Join(fields, ", "))
+				body.WriteString("},\n")
+			}
+			body.WriteString("},\n")
+		}
+		if len(h.responses) > 0 {
+			body.WriteString("Responses: []openapi.ResponseAnnotation{\n")
+			for _, r := range h.responses {
+				fields := []string{fmt.Sprintf("Status: %d", r.status)}
+				if r.description != "" {
+					fields = append(fields, fmt.Sprintf("Description: %q", r.description))
+				}
+				if r.model != "" {
+					usesReflect = true
+					fields = append(fields, fmt.Sprintf("Model: reflect.TypeOf((*%s)(nil)).Elem()", r.model))
+				}
+				if r.array {
+					fields = append(fields, "Array: true")
+				}
+				if r.file {
+					fields = append(fields, "File: true")
+				}
+				body.WriteString("{" + strings.Join(fields, ", ") + "},\n")
+			}
+			body.WriteString("},\n")
+		}
+		body.WriteString("},\n")
+	}
+	body.WriteString("}\n")
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by openapi-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\nimport (\n", g.pkg)
+	if usesReflect {
+		src.WriteString("\"reflect\"\n\n")
+	}
+	var imports []string
+	for qualifier := range g.usedImport {
+		path := g.imports[qualifier]
+		if defaultImportName(path) == qualifier {
+			imports = append(imports, strconv.Quote(path))
+		} else {
+			imports = append(imports, qualifier+" "+strconv.Quote(path))
+		}
+	}
+	imports = append(imports, strconv.Quote("github.com/cyops/cyops-backend/pkg/openapi"))
+	sort.Strings(imports)
+	for _, imp := range imports {
+		src.WriteString(imp + "\n")
+	}
+	src.WriteString(")\n\n")
+	src.Write(body.Bytes())
+
+	return format.Source(src.Bytes())
+}
@@ -0,0 +1,67 @@
+// Command rotate-secrets re-encrypts stored integration credentials under the active key of the
+// KMS_PROVIDER backend. Run it after switching providers or local keys (keeping the previous local
+// key in KMS_PREVIOUS_LOCAL_KEYS until it completes), and with -force after rotating the key
+// version in Vault or AWS KMS.
+//
+// Usage:
+//
+//	rotate-secrets [-dry-run] [-force]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/kms"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Count the secrets that would be re-encrypted without changing anything")
+	force := flag.Bool("force", false, "Also re-encrypt secrets already under the active key")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+	cfg := config.Load()
+	utils.InitLogger(cfg.GoEnv == "development")
+	if _, err := config.LoadVaultSecrets(context.Background(), cfg); err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Failed to load secrets from Vault")
+	}
+
+	keyring, err := kms.New(cfg.KMS())
+	if err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Secret encryption misconfigured")
+	}
+	keyring.WithLegacyKey(services.LegacySecretKey(cfg.JWTSecret))
+
+	if err := database.Connect(cfg.DatabaseDSN(), false); err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	defer database.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := services.ReencryptIntegrationSecrets(ctx, database.GetDB(), keyring, *dryRun, *force)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Secret re-encryption aborted")
+	}
+	if result != nil {
+		fmt.Printf("provider=%s key_id=%s scanned=%d reencrypted=%d current=%d failed=%d dry_run=%t\n",
+			result.Provider, result.KeyID, result.Scanned, result.Reencrypted, result.Current, result.Failed, result.DryRun)
+	}
+	if err != nil || (result != nil && result.Failed > 0) {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/scheduler"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// registerBackgroundJobs registers all background jobs. Cluster jobs run on one replica per
+// period; instance jobs refresh state kept in each replica's memory.
+func registerBackgroundJobs(jobs *scheduler.Scheduler, cfg *config.Config) error {
+	sessionService := services.NewSessionService()
+	cleanupService := services.NewCleanupService()
+	riskAcceptanceService := services.NewRiskAcceptanceService(database.GetDB())
+	findingAutoCloseService := services.NewFindingAutoCloseService(database.GetDB())
+	metricsService := services.NewMetricsSnapshotService(database.GetDB())
+	assetGroupService := services.NewAssetGroupService(database.GetDB())
+	assetService := services.NewAssetService(database.GetDB())
+	criticalityScoringService := services.NewCriticalityScoringService(database.GetDB())
+	emailIngestionService := services.NewEmailIngestionService(database.GetDB())
+	agentCheckinService := services.NewAgentCheckinService(database.GetDB())
+	policyViolationService := services.NewPolicyViolationService(database.GetDB())
+	escalationService := services.NewEscalationService(database.GetDB())
+	apiKeyService := services.NewAPIKeyService()
+	emailDeliveryService := services.NewEmailDeliveryService(database.GetDB(), cfg)
+	notificationDigestService := services.NewNotificationDigestService(database.GetDB(), cfg)
+	exploitFeedService := services.NewExploitFeedService(database.GetDB(), cfg.ExploitDBFeedURL, cfg.MetasploitFeedURL)
+	nvdRemediationService := services.NewNVDRemediationService(database.GetDB(), cfg.NVDAPIURL, cfg.NVDAPIKey)
+
+	registered := []scheduler.Job{
+		{
+			Name:        "session-cleanup",
+			Description: "Deletes expired sessions",
+			Interval:    1 * time.Hour,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				count, err := sessionService.CleanupExpiredSessions()
+				if err != nil {
+					return fmt.Errorf("failed to cleanup expired sessions: %w", err)
+				}
+				if count > 0 {
+					utils.Logger.Info().Int64("count", count).Msg("Cleaned up expired sessions")
+				}
+				return nil
+			},
+		},
+		{
+			// Purges once a day when enabled; the hourly check catches up after downtime
+			Name:        "data-retention",
+			Description: "Purges data older than the data_retention periods",
+			Interval:    1 * time.Hour,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				if _, err := cleanupService.RunScheduledRetention(ctx); err != nil {
+					return fmt.Errorf("failed to run data retention: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "partition-maintenance",
+			Description: "Creates the monthly partitions of the coming months",
+			Interval:    1 * time.Hour,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				created, err := database.EnsurePartitions(database.GetDB(), time.Now(), partitionMonthsAhead)
+				if len(created) > 0 {
+					utils.Logger.Info().Strs("partitions", created).Msg("Created table partitions")
+				}
+				if err != nil {
+					return fmt.Errorf("failed to create table partitions: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "risk-acceptance-expiry",
+			Description: "Re-opens findings whose accepted risk has lapsed",
+			Interval:    1 * time.Hour,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				count, err := riskAcceptanceService.ExpireRiskAcceptances()
+				if err != nil {
+					return fmt.Errorf("failed to expire risk acceptances: %w", err)
+				}
+				if count > 0 {
+					utils.Logger.Info().Int("count", count).Msg("Re-opened findings with expired risk acceptances")
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "finding-auto-close",
+			Description: "Closes findings missing from enough consecutive scans",
+			Interval:    24 * time.Hour,
+			Next:        untilNightlyRun,
+			Run: func(ctx context.Context) error {
+				count, err := findingAutoCloseService.Evaluate(time.Now())
+				if err != nil {
+					return fmt.Errorf("failed to auto-close findings: %w", err)
+				}
+				if count > 0 {
+					utils.Logger.Info().Int("count", count).Msg("Auto-closed findings missing from consecutive scans")
+				}
+				return nil
+			},
+		},
+		{
+			// The first run backfills history
+			Name:        "metrics-snapshot",
+			Description: "Maintains the daily dashboard metrics tables",
+			Interval:    1 * time.Hour,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				if err := metricsService.RefreshSnapshots(time.Now()); err != nil {
+					return fmt.Errorf("failed to refresh metrics snapshots: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "decommissioned-asset-cleanup",
+			Description: "Moves decommissioned assets to the recycle bin once their grace period ends",
+			Interval:    1 * time.Hour,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				count, err := assetService.CleanupDecommissionedAssets(time.Now())
+				if count > 0 {
+					utils.Logger.Info().Int("count", count).Msg("Cleaned up decommissioned assets")
+				}
+				if err != nil {
+					return fmt.Errorf("failed to clean up decommissioned assets: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "asset-group-membership",
+			Description: "Re-evaluates dynamic asset group rules after asset changes",
+			Interval:    1 * time.Minute,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				count, err := assetGroupService.RecomputeStale()
+				if err != nil {
+					return fmt.Errorf("failed to recompute asset group membership: %w", err)
+				}
+				if count > 0 {
+					utils.Logger.Info().Int("count", count).Msg("Recomputed asset group membership")
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "criticality-scoring",
+			Description: "Rescores assets after asset or profile changes",
+			Interval:    1 * time.Minute,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				count, err := criticalityScoringService.RescoreStale()
+				if err != nil {
+					return fmt.Errorf("failed to rescore asset criticality: %w", err)
+				}
+				if count > 0 {
+					utils.Logger.Info().Int("count", count).Msg("Rescored asset criticality")
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "attachment-storage-reload",
+			Description: "Picks up attachment storage changes saved through other instances",
+			Scope:       scheduler.ScopeInstance,
+			Interval:    1 * time.Minute,
+			Run: func(ctx context.Context) error {
+				if err := services.ReloadAttachmentStorage(database.GetDB()); err != nil {
+					return fmt.Errorf("failed to reload attachment storage settings: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "siem-forwarder-reload",
+			Description: "Picks up SIEM forwarding changes saved through other instances",
+			Scope:       scheduler.ScopeInstance,
+			Interval:    1 * time.Minute,
+			Run: func(ctx context.Context) error {
+				if err := services.ReloadSIEMForwarder(database.GetDB()); err != nil {
+					return fmt.Errorf("failed to reload SIEM forwarder settings: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			// Checked every minute; polls once the configured poll interval has passed
+			Name:        "email-ingestion",
+			Description: "Turns reports in the configured mailbox into draft vulnerabilities",
+			Interval:    1 * time.Minute,
+			Period:      emailIngestionService.PollInterval,
+			Run: func(ctx context.Context) error {
+				count, err := emailIngestionService.Poll()
+				if err != nil {
+					return fmt.Errorf("failed to poll the vulnerability report mailbox: %w", err)
+				}
+				if count > 0 {
+					utils.Logger.Info().Int("count", count).Msg("Processed emailed vulnerability reports")
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "email-outbox",
+			Description: "Sends queued emails, retrying failures with backoff",
+			Interval:    5 * time.Second,
+			Run: func(ctx context.Context) error {
+				// Drain the due emails in batches before waiting for the next run
+				for {
+					count, err := emailDeliveryService.ProcessOutbox(ctx, 50)
+					if err != nil {
+						return fmt.Errorf("failed to process email outbox: %w", err)
+					}
+					if count < 50 || ctx.Err() != nil {
+						return nil
+					}
+				}
+			},
+		},
+		{
+			Name:        "email-outbox-purge",
+			Description: "Purges outbox entries of emails sent more than a week ago",
+			Interval:    1 * time.Hour,
+			Run: func(ctx context.Context) error {
+				count, err := emailDeliveryService.PurgeSentOutbox(7 * 24 * time.Hour)
+				if err != nil {
+					return fmt.Errorf("failed to purge email outbox: %w", err)
+				}
+				if count > 0 {
+					utils.Logger.Info().Int64("count", count).Msg("Purged sent email outbox entries")
+				}
+				return nil
+			},
+		},
+		{
+			// Digests go out at services.DigestHour in each user's timezone
+			Name:        "notification-digest",
+			Description: "Emails daily and weekly notification digests to the users who chose them",
+			Interval:    15 * time.Minute,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				count, err := notificationDigestService.SendDueDigests(time.Now())
+				if err != nil {
+					return fmt.Errorf("failed to send notification digests: %w", err)
+				}
+				if count > 0 {
+					utils.Logger.Info().Int("count", count).Msg("Sent notification digests")
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "stale-agents",
+			Description: "Flags agent-managed assets that stopped checking in",
+			Interval:    1 * time.Hour,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				count, err := agentCheckinService.MarkStale(time.Now())
+				if err != nil {
+					return fmt.Errorf("failed to flag stale agents: %w", err)
+				}
+				if count > 0 {
+					utils.Logger.Info().Int64("count", count).Msg("Flagged assets with stale agents")
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "policy-evaluation",
+			Description: "Opens and resolves policy rule violations",
+			Interval:    1 * time.Hour,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				result, err := policyViolationService.Evaluate(time.Now())
+				if err != nil {
+					return fmt.Errorf("failed to evaluate policy rules: %w", err)
+				}
+				if result.Opened > 0 || result.Resolved > 0 {
+					utils.Logger.Info().
+						Int("opened", result.Opened).
+						Int("resolved", result.Resolved).
+						Msg("Evaluated policy rules")
+				}
+				return nil
+			},
+		},
+		{
+			Name:        "escalation",
+			Description: "Escalates open vulnerabilities past their policies' age thresholds",
+			Interval:    1 * time.Hour,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				result, err := escalationService.Evaluate(time.Now())
+				if err != nil {
+					return fmt.Errorf("failed to evaluate escalation policies: %w", err)
+				}
+				if result.Escalated > 0 {
+					utils.Logger.Info().
+						Int("escalated", result.Escalated).
+						Int("notified", result.Notified).
+						Int("notify_failed", result.NotifyFailed).
+						Msg("Escalated vulnerabilities")
+				}
+				return nil
+			},
+		},
+		{
+			// Usage is buffered in each instance; the counters of the last requests are kept on shutdown
+			Name:        "api-key-usage-flush",
+			Description: "Persists buffered per-key usage counters",
+			Scope:       scheduler.ScopeInstance,
+			Interval:    10 * time.Second,
+			RunOnStop:   true,
+			Run: func(ctx context.Context) error {
+				if _, err := apiKeyService.FlushUsage(); err != nil {
+					return fmt.Errorf("failed to flush API key usage: %w", err)
+				}
+				return nil
+			},
+		},
+	}
+
+	// Batched invalidations are collected from this instance's database writes
+	if statsCache := services.ActiveCache(); statsCache != nil {
+		registered = append(registered, scheduler.Job{
+			Name:        "cache-invalidation-flush",
+			Description: "Applies batched cache invalidations from database writes",
+			Scope:       scheduler.ScopeInstance,
+			Interval:    1 * time.Second,
+			Run: func(ctx context.Context) error {
+				if err := statsCache.FlushInvalidations(ctx); err != nil {
+					return fmt.Errorf("failed to flush cache invalidations: %w", err)
+				}
+				return nil
+			},
+		})
+	}
+
+	// Public exploits are synced unless both EXPLOITDB_FEED_URL and METASPLOIT_FEED_URL are empty
+	if exploitFeedService.Enabled() {
+		registered = append(registered, scheduler.Job{
+			Name:        "exploit-feed-sync",
+			Description: "Syncs the Exploit-DB and Metasploit feeds and flags exploitable vulnerabilities",
+			Interval:    24 * time.Hour,
+			RunOnStart:  true,
+			Run: func(ctx context.Context) error {
+				result, err := exploitFeedService.Sync(ctx)
+				if result != nil && result.Flagged > 0 {
+					utils.Logger.Info().Int64("count", result.Flagged).Msg("Flagged vulnerabilities with known public exploits")
+				}
+				if err != nil {
+					return fmt.Errorf("failed to sync exploit feeds: %w", err)
+				}
+				return nil
+			},
+		})
+	}
+
+	// CVE vulnerabilities are checked against NVD unless NVD_API_URL is empty
+	if nvdRemediationService.Enabled() {
+		registered = append(registered, scheduler.Job{
+			Name:        "nvd-remediation",
+			Description: "Fills in patch and advisory details of CVE vulnerabilities from NVD",
+			Interval:    1 * time.Hour,
+			Run: func(ctx context.Context) error {
+				count, err := nvdRemediationService.Enrich(ctx)
+				if count > 0 {
+					utils.Logger.Info().Int64("count", count).Msg("Checked vulnerabilities against NVD")
+				}
+				if err != nil {
+					return fmt.Errorf("failed to check vulnerabilities against NVD: %w", err)
+				}
+				return nil
+			},
+		})
+	}
+
+	if searchIndex := services.ActiveSearchIndex(); searchIndex != nil {
+		registered = append(registered,
+			scheduler.Job{
+				Name:        "search-outbox",
+				Description: "Mirrors writes into OpenSearch",
+				Interval:    5 * time.Second,
+				Run: func(ctx context.Context) error {
+					// Drain the backlog in batches before waiting for the next run
+					for {
+						count, err := searchIndex.ProcessOutbox(ctx, 500)
+						if err != nil {
+							return fmt.Errorf("failed to process search outbox: %w", err)
+						}
+						if count < 500 {
+							return nil
+						}
+					}
+				},
+			},
+			scheduler.Job{
+				Name:        "search-outbox-purge",
+				Description: "Purges processed search outbox entries older than a week",
+				Interval:    1 * time.Hour,
+				Run: func(ctx context.Context) error {
+					count, err := searchIndex.PurgeProcessedOutbox(7 * 24 * time.Hour)
+					if err != nil {
+						return fmt.Errorf("failed to purge search outbox: %w", err)
+					}
+					if count > 0 {
+						utils.Logger.Info().Int64("count", count).Msg("Purged processed search outbox entries")
+					}
+					return nil
+				},
+			},
+		)
+	}
+
+	for _, job := range registered {
+		if err := jobs.Register(job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
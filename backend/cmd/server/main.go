@@ -6,20 +6,30 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Timezone preferences work in images without a zoneinfo database
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/joho/godotenv"
 	"github.com/cyops/cyops-backend/internal/handlers"
 	"github.com/cyops/cyops-backend/internal/middleware"
 	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/cache"
 	"github.com/cyops/cyops-backend/pkg/config"
 	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/faultinject"
+	"github.com/cyops/cyops-backend/pkg/kms"
+	"github.com/cyops/cyops-backend/pkg/scheduler"
+	"github.com/cyops/cyops-backend/pkg/shutdown"
+	"github.com/cyops/cyops-backend/pkg/search"
+	"github.com/cyops/cyops-backend/pkg/storage"
+	"github.com/cyops/cyops-backend/pkg/telemetry"
+	"github.com/cyops/cyops-backend/pkg/tenant"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
@@ -36,37 +46,220 @@ func main() {
 	utils.InitLogger(cfg.GoEnv == "development")
 	utils.Logger.Info().Str("environment", cfg.GoEnv).Msg("Starting application")
 
-	// Connect to database
-	if err := database.Connect(cfg.DatabaseDSN(), cfg.GoEnv == "development"); err != nil {
+	// Secrets stored in Vault override their environment variables when VAULT_ADDR is set
+	vaultSecrets, err := config.LoadVaultSecrets(context.Background(), cfg)
+	if err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Failed to load secrets from Vault")
+	}
+	if vaultSecrets != nil {
+		utils.Logger.Info().Str("path", cfg.VaultKVMount+"/"+cfg.VaultSecretsPath).Msg("Secrets loaded from Vault")
+	}
+
+	// Connect to database (pool size and slow query thresholds come from the DB_* settings)
+	if err := database.ConnectWithConfig(cfg.DatabaseConnection()); err != nil {
 		utils.Logger.Fatal().Err(err).Msg("Failed to connect to database")
 	}
 	defer database.Close()
 
+	// Tenant scoping (queries carrying an organization in their context are filtered by org_id)
+	if err := tenant.RegisterGORMCallbacks(database.GetDB()); err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Failed to register tenant callbacks")
+	}
+
 	// Run migrations
 	if err := runMigrations(cfg); err != nil {
 		utils.Logger.Fatal().Err(err).Msg("Failed to run migrations")
 	}
 
-	// Start background jobs
+	// Attachment storage backend (local disk unless the attachment_storage setting selects S3/MinIO or Azure Blob)
+	storageCreds := services.AttachmentStorageCredentials{
+		S3AccessKeyID:     cfg.StorageS3AccessKeyID,
+		S3SecretAccessKey: cfg.StorageS3SecretAccessKey,
+		AzureAccountKey:   cfg.StorageAzureAccountKey,
+	}
+	if err := services.InitAttachmentStorage(database.GetDB(), storageCreds); err != nil {
+		utils.Logger.Error().Err(err).Msg("Attachment storage misconfigured, storing new files on local disk")
+	}
+	utils.Logger.Info().Str("driver", services.ActiveAttachmentStorage().Store.Driver()).Msg("Attachment storage ready")
+
+	// Integration credentials are envelope encrypted under the KMS_PROVIDER key; credentials
+	// stored before envelope encryption were encrypted with the JWT secret
+	keyring, err := kms.New(cfg.KMS())
+	if err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Secret encryption misconfigured")
+	}
+	services.SetSecretKeyring(keyring.WithLegacyKey(services.LegacySecretKey(cfg.JWTSecret)))
+	utils.Logger.Info().Str("provider", keyring.Active().Name()).Str("key_id", keyring.Active().KeyID()).Msg("Secret encryption ready")
+
+	// Generated reports and CSV report exports are signed with REPORT_SIGNING_KEY (derived from
+	// ENCRYPTION_KEY when unset)
+	reportSigner, err := cfg.ReportSigner()
+	if err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Report signing misconfigured")
+	}
+	services.SetReportSigner(reportSigner)
+	utils.Logger.Info().Str("key_id", reportSigner.Active().KeyID()).Msg("Report signing ready")
+
+	// Language model API key for report summaries (the provider is chosen by the report_summarizer setting)
+	services.SetReportSummarizerAPIKey(cfg.LLMAPIKey)
+
+	// Backups taken through the admin API are written to BACKUP_STORAGE (local disk or S3)
+	if backupStore, err := storage.New(cfg.BackupStorageConfig()); err != nil {
+		utils.Logger.Warn().Err(err).Msg("Backup storage misconfigured, backups are disabled")
+	} else {
+		services.SetBackupConfig(&services.BackupConfig{
+			Store:      backupStore,
+			DBHost:     cfg.DBHost,
+			DBPort:     cfg.DBPort,
+			DBUser:     cfg.DBUser,
+			DBPassword: cfg.DBPassword,
+			DBName:     cfg.DBName,
+			DBSSLMode:  cfg.DBSSLMode,
+		})
+	}
+
+	// Components of uploaded SBOMs are correlated against OSV advisories unless OSV_API_URL is empty
+	if cfg.OSVAPIURL != "" {
+		services.SetKnownVulnerabilitySource(services.NewOSVSource(cfg.OSVAPIURL))
+	}
+
+	// Scan imports write vulnerabilities in batches of IMPORT_BATCH_SIZE, one transaction each, and
+	// export up to NESSUS_IMPORT_WORKERS scans at once; uploads accept up to NESSUS_UPLOAD_LIMIT_MB
+	services.SetImportBatchSize(cfg.ImportBatchSize)
+	services.SetNessusImportWorkers(cfg.NessusImportWorkers)
+	services.SetNessusUploadLimit(cfg.NessusUploadLimitMB)
+
+	// Audit and vulnerability lifecycle events are forwarded to a SIEM when the siem_forwarder setting enables it
+	if err := services.RegisterSIEMCallbacks(database.GetDB()); err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Failed to register SIEM forwarding callbacks")
+	}
+	if err := services.ReloadSIEMForwarder(database.GetDB()); err != nil {
+		utils.Logger.Error().Err(err).Msg("SIEM forwarder misconfigured, events are not forwarded")
+	}
+	defer services.CloseSIEMForwarder()
+
+	// Asset writes mark dynamic asset group membership stale for the recompute job
+	if err := services.RegisterAssetGroupCallbacks(database.GetDB()); err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Failed to register asset group callbacks")
+	}
+
+	// Asset and tag writes mark criticality scores stale for the rescoring job
+	if err := services.RegisterCriticalityCallbacks(database.GetDB()); err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Failed to register criticality scoring callbacks")
+	}
+
+	// Fault injection hooks (chaos builds only, never in production)
+	if faultinject.Enabled() {
+		if cfg.GoEnv == "production" {
+			utils.Logger.Warn().Msg("Fault injection build detected in production, fault injection disabled")
+		} else {
+			if err := faultinject.RegisterGORMCallbacks(database.GetDB()); err != nil {
+				utils.Logger.Fatal().Err(err).Msg("Failed to register fault injection callbacks")
+			}
+			utils.Logger.Warn().Msg("Fault injection enabled, configure faults via /api/v1/admin/faults")
+		}
+	}
+
+	// Optional OpenSearch backend for list/search queries
+	if cfg.SearchBackend == "opensearch" {
+		if err := services.RegisterSearchOutboxCallbacks(database.GetDB()); err != nil {
+			utils.Logger.Fatal().Err(err).Msg("Failed to register search outbox callbacks")
+		}
+		client := search.NewClient(cfg.OpenSearchURL, cfg.OpenSearchUsername, cfg.OpenSearchPassword, cfg.OpenSearchIndexPrefix)
+		searchIndex := services.NewSearchIndexService(database.GetDB(), client)
+		ensureCtx, ensureCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := searchIndex.EnsureIndices(ensureCtx); err != nil {
+			utils.Logger.Warn().Err(err).Msg("Search indices unavailable, list queries will fall back to Postgres until reachable")
+		}
+		ensureCancel()
+		services.SetActiveSearchIndex(searchIndex)
+		utils.Logger.Info().Str("url", cfg.OpenSearchURL).Msg("OpenSearch search backend enabled")
+	}
+
+	// Optional Redis for the stats and report cache and shared rate limit counters
+	if cfg.CacheEnabled || cfg.RateLimitStore == "redis" {
+		if err := cache.Connect(cfg.RedisURL); err != nil {
+			utils.Logger.Warn().Err(err).Msg("Redis unavailable, stats and report caching disabled and rate limits enforced per instance")
+		} else {
+			defer cache.Close()
+		}
+	}
+	if cfg.CacheEnabled && cache.GetClient() != nil {
+		statsCache := services.NewCacheService(cache.GetClient(), time.Duration(cfg.CacheTTLSeconds)*time.Second)
+		if err := statsCache.RegisterInvalidationCallbacks(database.GetDB()); err != nil {
+			utils.Logger.Fatal().Err(err).Msg("Failed to register cache invalidation callbacks")
+		}
+		services.SetActiveCache(statsCache)
+		utils.Logger.Info().Int("ttl_seconds", cfg.CacheTTLSeconds).Msg("Stats and report caching enabled")
+	}
+	if cfg.RateLimitStore == "redis" && cache.GetClient() != nil {
+		middleware.SetRateLimitStore(middleware.NewRedisRateLimitStore(cache.GetClient()))
+		utils.Logger.Info().Msg("Rate limit counters shared through Redis")
+	}
+
+	// OpenTelemetry tracing
+	shutdownTracing, err := telemetry.Init(context.Background(), telemetry.Config{
+		Enabled:     cfg.TracingEnabled,
+		ServiceName: cfg.TracingServiceName,
+		Environment: cfg.GoEnv,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Failed to initialize tracing")
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			utils.Logger.Warn().Err(err).Msg("Failed to flush traces")
+		}
+	}()
+	if cfg.TracingEnabled {
+		if err := telemetry.RegisterGORMCallbacks(database.GetDB()); err != nil {
+			utils.Logger.Fatal().Err(err).Msg("Failed to register tracing callbacks")
+		}
+		utils.Logger.Info().Str("service", cfg.TracingServiceName).Msg("OpenTelemetry tracing enabled")
+	}
+
+	// Start background jobs; cluster jobs run on one replica per period
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	startBackgroundJobs(ctx)
+	jobs := scheduler.New(scheduler.NewPostgresCoordinator(database.GetDB(), scheduler.InstanceName()))
+	if err := registerBackgroundJobs(jobs, cfg); err != nil {
+		utils.Logger.Fatal().Err(err).Msg("Failed to register background jobs")
+	}
+	jobs.Start(ctx)
+	go database.ReportPoolStats(ctx, time.Duration(cfg.DBPoolStatsIntervalSeconds)*time.Second)
+	if vaultSecrets != nil {
+		go vaultSecrets.Watch(ctx, func(changed map[string]string) {
+			applyRefreshedSecrets(&storageCreds, changed)
+		}, func(err error) {
+			utils.Logger.Error().Err(err).Msg("Failed to refresh secrets from Vault")
+		})
+	}
 
 	// Create Fiber app with configuration
 	app := fiber.New(fiber.Config{
 		AppName:               "Auth Backend API v1.0.0",
 		ErrorHandler:          middleware.ErrorHandler(),
 		DisableStartupMessage: false,
-		BodyLimit:             100 * 1024 * 1024, // 100MB for file uploads (Nessus files)
+		// Bodies larger than the default limit are streamed (uploads to temporary files) instead of
+		// buffered; each route's limit is enforced by middleware.RequestBodyLimit
+		BodyLimit:         cfg.RequestBodyLimitMB * 1024 * 1024,
+		StreamRequestBody: true,
+		// Behind the reverse proxy the client address comes from its X-Real-IP header, which is
+		// only trusted from the configured proxies; IP access lists and rate limits rely on it
+		ProxyHeader:             proxyHeader(cfg),
+		EnableTrustedProxyCheck: cfg.TrustedProxies != "",
+		TrustedProxies:          trustedProxies(cfg),
 	})
 
 	// Global middleware
 	app.Use(recover.New())                // Panic recovery
-	app.Use(middleware.RequestID())       // Request ID tracking with logging
+	app.Use(middleware.RequestID())       // Request ID tracking
+	app.Use(middleware.Tracing())         // OpenTelemetry server spans
+	app.Use(middleware.AccessLog())       // Structured JSON access logs
 	app.Use(middleware.SecurityHeaders()) // Security headers
-	app.Use(logger.New(logger.Config{
-		Format: "[${time}] ${status} - ${latency} ${method} ${path}\n",
-	}))
 
 	// CORS configuration - whitelist approach for security
 	corsOrigins := cfg.CORSOrigins
@@ -84,23 +277,36 @@ func main() {
 	
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     corsOrigins,
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Request-ID",
+		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-Request-ID, X-Org-ID, traceparent, tracestate, If-Match, If-None-Match, If-Modified-Since, X-API-Envelope",
 		AllowMethods:     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
 		AllowCredentials: true,
-		ExposeHeaders:    "X-Request-ID",
+		ExposeHeaders:    "X-Request-ID, X-Trace-ID, ETag, Last-Modified, X-API-Envelope, API-Version, Deprecation, Sunset, Link, X-Report-Signature",
 	}))
 
 	// Setup routes
 	handlers.SetupRoutes(app, cfg)
 
-	// Graceful shutdown
+	// Graceful shutdown: stop taking new imports, reports and backups, let running ones reach a
+	// checkpoint and the background jobs finish, then close the remaining connections
+	stopped := make(chan struct{})
 	go func() {
+		defer close(stopped)
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		<-sigChan
-		utils.Logger.Info().Msg("Shutting down server...")
+		timeout := time.Duration(cfg.ShutdownTimeoutSeconds) * time.Second
+		utils.Logger.Info().Dur("timeout", timeout).Msg("Shutting down server...")
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), timeout)
+		defer drainCancel()
+
 		cancel() // Stop background jobs
-		if err := app.Shutdown(); err != nil {
+		if running := shutdown.Drain(drainCtx); len(running) > 0 {
+			utils.Logger.Warn().Strs("running", running).Msg("Shutdown timeout reached with work still running")
+		}
+		if !jobs.Wait(drainCtx) {
+			utils.Logger.Warn().Msg("Shutdown timeout reached with background jobs still running")
+		}
+		if err := app.ShutdownWithContext(drainCtx); err != nil {
 			utils.Logger.Error().Err(err).Msg("Error during shutdown")
 		}
 	}()
@@ -111,8 +317,14 @@ func main() {
 	if err := app.Listen(addr); err != nil {
 		utils.Logger.Fatal().Err(err).Msg("Failed to start server")
 	}
+	// Listen returns as soon as the listener closes; wait for the drain to finish
+	<-stopped
+	utils.Logger.Info().Msg("Server stopped")
 }
 
+// partitionMonthsAhead is how many months of partitions are created ahead of time
+const partitionMonthsAhead = 3
+
 // runMigrations runs database migrations
 func runMigrations(cfg *config.Config) error {
 	utils.Logger.Info().Msg("Running database migrations...")
@@ -122,39 +334,20 @@ func runMigrations(cfg *config.Config) error {
 		return fmt.Errorf("failed to enable UUID extension: %w", err)
 	}
 
-	// Register all models here for GORM AutoMigrate
-	if err := database.AutoMigrate(
-		&models.User{},
-		&models.Role{},
-		&models.VerificationToken{},
-		&models.AuthEvent{},
-		&models.Session{},
-		&models.APIKey{}, // Managed by GORM with datatypes.JSON
-		// Vulnerability Management models
-		&models.Vulnerability{},
-		&models.AffectedSystem{},
-		&models.VulnerabilityStatusHistory{},
-		&models.VulnerabilityAffectedSystem{},
-		&models.VulnerabilityFinding{},
-		&models.FindingStatusHistory{},
-		&models.FindingAttachment{},
-		&models.VulnerabilityAttachment{},
-		// Asset Management models
-		&models.AssetTag{},
-		// Integration models
-		&models.IntegrationConfig{},
-		// Assessment models
-		&models.Assessment{},
-		&models.AssessmentVulnerability{},
-		&models.AssessmentAsset{},
-		&models.AssessmentReport{},
-		// System Settings
-		&models.SystemSetting{},
-		// Add other models as they are created
-	); err != nil {
+	// Models are registered in models.MigrationModels
+	if err := database.AutoMigrate(models.MigrationModels()...); err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
 
+	// Findings and their status history are partitioned by month; converting recreates the
+	// tables, so migrate them again to restore their indexes and foreign keys
+	if err := database.PartitionTables(database.GetDB(), partitionMonthsAhead); err != nil {
+		return fmt.Errorf("failed to partition tables: %w", err)
+	}
+	if err := database.AutoMigrate(&models.VulnerabilityFinding{}, &models.FindingStatusHistory{}); err != nil {
+		return fmt.Errorf("migration of partitioned tables failed: %w", err)
+	}
+
 	// Create custom indexes for asset management
 	if err := createAssetManagementIndexes(); err != nil {
 		return fmt.Errorf("failed to create asset management indexes: %w", err)
@@ -162,6 +355,19 @@ func runMigrations(cfg *config.Config) error {
 
 	utils.Logger.Info().Msg("Migrations completed successfully")
 
+	// Seed default organization and assign pre-existing data to it
+	utils.Logger.Info().Msg("Seeding default organization...")
+	_, backfilled, err := database.SeedDefaultOrganization(database.GetDB())
+	if err != nil {
+		return fmt.Errorf("organization seeding failed: %w", err)
+	}
+	if backfilled > 0 && cfg.SearchBackend == "opensearch" {
+		// The backfill bypasses the outbox callbacks; reindex so documents carry org_id
+		if _, err := services.NewSearchIndexService(database.GetDB(), nil).EnqueueFullReindex(); err != nil {
+			return fmt.Errorf("search reindex after organization backfill failed: %w", err)
+		}
+	}
+
 	// Seed default roles
 	utils.Logger.Info().Msg("Seeding default roles...")
 	if err := database.SeedRoles(database.GetDB()); err != nil {
@@ -199,6 +405,11 @@ func enableUUIDExtension() error {
 	}
 
 	utils.Logger.Info().Msg("UUID extension enabled successfully")
+
+	// pg_trgm backs fuzzy duplicate detection; without it that falls back to matching in Go
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		utils.Logger.Warn().Err(err).Msg("Failed to create pg_trgm extension, fuzzy duplicate detection will be slower")
+	}
 	return nil
 }
 
@@ -207,16 +418,21 @@ func createAssetManagementIndexes() error {
 	db := database.GetDB()
 	utils.Logger.Info().Msg("Creating asset management indexes...")
 
-	// Unique constraints for duplicate prevention (per environment)
+	// Unique constraints for duplicate prevention (per organization and environment)
 	indexes := []string{
+		// Superseded by the per-organization indexes below
+		`DROP INDEX IF EXISTS idx_assets_hostname_env`,
+		`DROP INDEX IF EXISTS idx_assets_ip_env`,
+		`DROP INDEX IF EXISTS idx_metrics_snapshot_date`,
+
 		// Unique hostname + environment
-		`CREATE UNIQUE INDEX IF NOT EXISTS idx_assets_hostname_env 
-		 ON affected_systems(hostname, environment) 
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_assets_org_hostname_env 
+		 ON affected_systems(org_id, hostname, environment) 
 		 WHERE hostname IS NOT NULL AND deleted_at IS NULL`,
 
 		// Unique IP + environment
-		`CREATE UNIQUE INDEX IF NOT EXISTS idx_assets_ip_env 
-		 ON affected_systems(ip_address, environment) 
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_assets_org_ip_env 
+		 ON affected_systems(org_id, ip_address, environment) 
 		 WHERE ip_address IS NOT NULL AND deleted_at IS NULL`,
 
 		// Performance indexes
@@ -246,6 +462,16 @@ func createAssetManagementIndexes() error {
 			 )
 		 )`,
 
+		// Trigram index for fuzzy duplicate detection (requires pg_trgm)
+		`CREATE INDEX IF NOT EXISTS idx_assets_asset_id_trgm 
+		 ON affected_systems USING GIN(lower(asset_id) gin_trgm_ops) 
+		 WHERE deleted_at IS NULL`,
+
+		// One asset per endpoint agent
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_assets_org_agent 
+		 ON affected_systems(org_id, agent_id) 
+		 WHERE agent_id IS NOT NULL AND agent_id <> '' AND deleted_at IS NULL`,
+
 		// Tag indexes
 		`CREATE INDEX IF NOT EXISTS idx_asset_tags_tag ON asset_tags(tag)`,
 		`CREATE INDEX IF NOT EXISTS idx_asset_tags_asset ON asset_tags(asset_id)`,
@@ -263,35 +489,60 @@ func createAssetManagementIndexes() error {
 }
 
 
-// startBackgroundJobs starts all background jobs
-func startBackgroundJobs(ctx context.Context) {
-	sessionService := services.NewSessionService()
-
-	// Session cleanup job - runs every hour
-	go func() {
-		ticker := time.NewTicker(1 * time.Hour)
-		defer ticker.Stop()
-
-		// Run immediately on startup
-		utils.Logger.Info().Msg("Starting session cleanup job")
-		if count, err := sessionService.CleanupExpiredSessions(); err != nil {
-			utils.Logger.Error().Err(err).Msg("Failed to cleanup expired sessions")
-		} else if count > 0 {
-			utils.Logger.Info().Int64("count", count).Msg("Cleaned up expired sessions on startup")
+// applyRefreshedSecrets puts secrets rotated in Vault into effect where they can be swapped
+// at runtime; the others take effect on the next restart
+func applyRefreshedSecrets(creds *services.AttachmentStorageCredentials, changed map[string]string) {
+	storageChanged := false
+	for key, value := range changed {
+		switch key {
+		case "LLM_API_KEY":
+			services.SetReportSummarizerAPIKey(value)
+		case "STORAGE_S3_ACCESS_KEY_ID":
+			creds.S3AccessKeyID, storageChanged = value, true
+		case "STORAGE_S3_SECRET_ACCESS_KEY":
+			creds.S3SecretAccessKey, storageChanged = value, true
+		case "STORAGE_AZURE_ACCOUNT_KEY":
+			creds.AzureAccountKey, storageChanged = value, true
+		default:
+			utils.Logger.Warn().Str("secret", key).Msg("Secret changed in Vault, restart to apply it")
+			continue
+		}
+		utils.Logger.Info().Str("secret", key).Msg("Secret refreshed from Vault")
+	}
+	if storageChanged {
+		if err := services.InitAttachmentStorage(database.GetDB(), *creds); err != nil {
+			utils.Logger.Error().Err(err).Msg("Attachment storage misconfigured after secret refresh")
 		}
+	}
+}
 
-		for {
-			select {
-			case <-ctx.Done():
-				utils.Logger.Info().Msg("Stopping session cleanup job")
-				return
-			case <-ticker.C:
-				if count, err := sessionService.CleanupExpiredSessions(); err != nil {
-					utils.Logger.Error().Err(err).Msg("Failed to cleanup expired sessions")
-				} else if count > 0 {
-					utils.Logger.Info().Int64("count", count).Msg("Cleaned up expired sessions")
-				}
-			}
+// nightlyRunHour is the local hour nightly jobs run at
+const nightlyRunHour = 2
+
+// untilNightlyRun returns the time from now until the next nightly run
+func untilNightlyRun(now time.Time) time.Duration {
+	next := time.Date(now.Year(), now.Month(), now.Day(), nightlyRunHour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next.Sub(now)
+}
+
+// proxyHeader returns the header holding the client address, when proxies are trusted
+func proxyHeader(cfg *config.Config) string {
+	if cfg.TrustedProxies == "" {
+		return ""
+	}
+	return "X-Real-IP"
+}
+
+// trustedProxies splits the configured proxy addresses and CIDRs
+func trustedProxies(cfg *config.Config) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(cfg.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
 		}
-	}()
+	}
+	return proxies
 }
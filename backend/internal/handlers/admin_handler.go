@@ -1,11 +1,20 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
 	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
 	"github.com/cyops/cyops-backend/pkg/auth"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/scheduler"
+	"github.com/cyops/cyops-backend/pkg/shutdown"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
@@ -14,6 +23,7 @@ type AdminHandler struct {
 	userService    *services.UserService
 	roleService    *services.RoleService
 	cleanupService *services.CleanupService
+	backupService  *services.BackupService
 }
 
 // NewAdminHandler creates a new admin handler
@@ -22,6 +32,7 @@ func NewAdminHandler() *AdminHandler {
 		userService:    services.NewUserService(),
 		roleService:    services.NewRoleService(),
 		cleanupService: services.NewCleanupService(),
+		backupService:  services.NewBackupService(),
 	}
 }
 
@@ -51,7 +62,7 @@ func (h *AdminHandler) ListUsers(c *fiber.Ctx) error {
 	}
 
 	// Build query
-	db := h.userService.GetDB().Model(&models.User{}).Preload("Role")
+	db := h.userService.WithContext(c.UserContext()).GetDB().Model(&models.User{}).Preload("Role")
 
 	// Apply search filter
 	if req.Search != "" {
@@ -111,7 +122,7 @@ func (h *AdminHandler) GetUser(c *fiber.Ctx) error {
 		})
 	}
 
-	user, err := h.userService.GetUserByID(userID)
+	user, err := h.userService.WithContext(c.UserContext()).GetUserByID(userID)
 	if err != nil {
 		utils.Logger.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to get user")
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -161,7 +172,7 @@ func (h *AdminHandler) CreateUser(c *fiber.Ctx) error {
 	}
 
 	// Check if user already exists
-	existingUser, _ := h.userService.GetUserByEmail(req.Email)
+	existingUser, _ := h.userService.GetUserByEmail(req.Email) // Emails are unique across organizations
 	if existingUser != nil {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
 			"error": "User with this email already exists",
@@ -202,7 +213,7 @@ func (h *AdminHandler) CreateUser(c *fiber.Ctx) error {
 	}
 
 	// Save to database
-	if err := h.userService.GetDB().Create(user).Error; err != nil {
+	if err := h.userService.WithContext(c.UserContext()).GetDB().Create(user).Error; err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to create user")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create user",
@@ -262,6 +273,13 @@ func (h *AdminHandler) AssignRole(c *fiber.Ctx) error {
 		})
 	}
 
+	// Only users in the admin's organization can be changed
+	if _, err := h.userService.WithContext(c.UserContext()).GetUserByID(userID); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
 	if err := h.roleService.AssignRoleToUser(userID, roleID); err != nil {
 		utils.Logger.Error().
 			Err(err).
@@ -274,7 +292,7 @@ func (h *AdminHandler) AssignRole(c *fiber.Ctx) error {
 	}
 
 	// Get updated user
-	user, err := h.userService.GetUserByID(userID)
+	user, err := h.userService.WithContext(c.UserContext()).GetUserByID(userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve updated user",
@@ -308,7 +326,7 @@ func (h *AdminHandler) UpdateUserStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	user, err := h.userService.GetUserByID(userID)
+	user, err := h.userService.WithContext(c.UserContext()).GetUserByID(userID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "User not found",
@@ -320,7 +338,7 @@ func (h *AdminHandler) UpdateUserStatus(c *fiber.Ctx) error {
 		user.EmailVerified = *req.EmailVerified
 	}
 
-	if err := h.userService.GetDB().Save(user).Error; err != nil {
+	if err := h.userService.WithContext(c.UserContext()).GetDB().Save(user).Error; err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to update user status")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update user",
@@ -350,7 +368,7 @@ func (h *AdminHandler) DeleteUser(c *fiber.Ctx) error {
 		})
 	}
 
-	user, err := h.userService.GetUserByID(userID)
+	user, err := h.userService.WithContext(c.UserContext()).GetUserByID(userID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "User not found",
@@ -358,7 +376,7 @@ func (h *AdminHandler) DeleteUser(c *fiber.Ctx) error {
 	}
 
 	// Soft delete
-	if err := h.userService.GetDB().Delete(user).Error; err != nil {
+	if err := h.userService.WithContext(c.UserContext()).GetDB().Delete(user).Error; err != nil {
 		utils.Logger.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to delete user")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete user",
@@ -375,6 +393,90 @@ func (h *AdminHandler) DeleteUser(c *fiber.Ctx) error {
 	})
 }
 
+// ImpersonateUserRequest represents a request to act as another user
+type ImpersonateUserRequest struct {
+	Reason          string `json:"reason"`
+	DurationMinutes int    `json:"duration_minutes"`
+}
+
+// ImpersonateUser starts a time-boxed session acting as a user, so support staff can see
+// exactly what the user sees. The session is audited under the administrator's ID and cannot
+// change the user's credentials.
+func (h *AdminHandler) ImpersonateUser(c *fiber.Ctx) error {
+	targetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	// Impersonation must be started by an administrator in person, not by a key or another impersonation
+	if authMethod, _ := c.Locals("auth_method").(string); authMethod != "session" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Impersonation requires a signed-in administrator session",
+		})
+	}
+	if _, impersonating := c.Locals("impersonator_id").(uuid.UUID); impersonating {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Impersonation sessions cannot start another impersonation",
+		})
+	}
+
+	var req ImpersonateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	maxMinutes := int(auth.MaxImpersonationDuration / time.Minute)
+	if req.DurationMinutes < 0 || req.DurationMinutes > maxMinutes {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("duration_minutes must be at most %d", maxMinutes),
+		})
+	}
+
+	adminID := c.Locals("user_id").(uuid.UUID)
+	admin, err := h.userService.GetUserByID(adminID)
+	if err != nil {
+		utils.Logger.Error().Err(err).Str("user_id", adminID.String()).Msg("Failed to get current user")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify admin identity",
+		})
+	}
+
+	// Scoped to the administrator's organization, so tenants cannot be crossed
+	target, err := h.userService.WithContext(c.UserContext()).GetUserByID(targetID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	sessionService := services.NewSessionService()
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	session, err := sessionService.StartImpersonation(admin, target, duration, req.Reason, c.IP(), c.Get("User-Agent"))
+	if err != nil {
+		if errors.Is(err, services.ErrImpersonationNotAllowed) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		utils.Logger.Error().Err(err).Str("user_id", targetID.String()).Msg("Failed to start impersonation")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to start impersonation",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":       "Impersonation session started",
+		"token":         session.Token,
+		"expires_at":    session.ExpiresAt,
+		"session_id":    session.ID.String(),
+		"user":          target.ToPublic(),
+		"impersonation": session.ToImpersonationInfo(admin),
+	})
+}
+
 // GetCleanupStats retrieves statistics about soft-deleted items
 func (h *AdminHandler) GetCleanupStats(c *fiber.Ctx) error {
 	stats, err := h.cleanupService.GetCleanupStats()
@@ -468,3 +570,398 @@ func (h *AdminHandler) CleanupAllData(c *fiber.Ctx) error {
 		"deleted_count": result.DeletedCount,
 	})
 }
+
+// GetRetentionSettings returns the data retention periods and the report of the latest run
+func (h *AdminHandler) GetRetentionSettings(c *fiber.Ctx) error {
+	settings, err := h.cleanupService.RetentionSettings()
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to load data retention settings")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve data retention settings",
+		})
+	}
+
+	runs, err := h.cleanupService.ListRetentionRuns(1)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to list retention runs")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve data retention settings",
+		})
+	}
+
+	response := fiber.Map{
+		"key":      string(models.SystemSettingDataRetention),
+		"settings": settings,
+	}
+	if len(runs) > 0 {
+		response["last_run"] = runs[0]
+	}
+	return c.JSON(response)
+}
+
+// PreviewRetention reports what a retention run would purge without deleting anything
+//
+// The request body may carry retention settings to preview before saving them; without a body
+// the saved settings are previewed.
+func (h *AdminHandler) PreviewRetention(c *fiber.Ctx) error {
+	var settings *services.DataRetentionSettings
+	var err error
+	if len(c.Body()) > 0 {
+		if settings, err = services.ParseDataRetentionSettings(string(c.Body())); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	} else if settings, err = h.cleanupService.RetentionSettings(); err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to load data retention settings")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to preview data retention",
+		})
+	}
+
+	return c.JSON(h.cleanupService.PreviewRetention(c.UserContext(), settings))
+}
+
+// RunRetention purges data older than the saved retention periods now
+func (h *AdminHandler) RunRetention(c *fiber.Ctx) error {
+	currentUserID := c.Locals("user_id").(uuid.UUID)
+
+	settings, err := h.cleanupService.RetentionSettings()
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to load data retention settings")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to run data retention",
+		})
+	}
+
+	run, err := h.cleanupService.RunRetention(c.UserContext(), settings, models.RetentionTriggerManual, &currentUserID)
+	if err != nil {
+		utils.Logger.Error().
+			Err(err).
+			Str("admin_id", currentUserID.String()).
+			Msg("Failed to run data retention")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to run data retention",
+		})
+	}
+
+	utils.Logger.Warn().
+		Int64("deleted_count", run.TotalDeleted).
+		Str("admin_id", currentUserID.String()).
+		Msg("Data retention run by admin")
+
+	return c.JSON(run)
+}
+
+// ListRetentionRuns returns the reports of recent data retention runs, newest first
+func (h *AdminHandler) ListRetentionRuns(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	runs, err := h.cleanupService.ListRetentionRuns(limit)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to list retention runs")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve data retention runs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"runs": runs,
+	})
+}
+
+// ListTrashRequest represents recycle bin pagination and filter parameters
+type ListTrashRequest struct {
+	Page    int    `query:"page"`
+	PerPage int    `query:"per_page"`
+	Type    string `query:"type"` // vulnerability or asset; both when empty
+}
+
+// ListTrash lists soft-deleted vulnerabilities and assets with who deleted them and when
+func (h *AdminHandler) ListTrash(c *fiber.Ctx) error {
+	var req ListTrashRequest
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid query parameters",
+		})
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PerPage < 1 || req.PerPage > 100 {
+		req.PerPage = 20
+	}
+
+	items, total, err := h.cleanupService.ListTrash(req.Type, req.Page, req.PerPage)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to list deleted items")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve deleted items",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"items":       items,
+		"total":       total,
+		"page":        req.Page,
+		"per_page":    req.PerPage,
+		"total_pages": (int(total) + req.PerPage - 1) / req.PerPage,
+	})
+}
+
+// RestoreTrashItem restores a soft-deleted vulnerability or asset from the recycle bin
+func (h *AdminHandler) RestoreTrashItem(c *fiber.Ctx) error {
+	currentUserID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid item ID",
+		})
+	}
+
+	itemType := c.Params("type")
+	if err := h.cleanupService.RestoreTrashItem(itemType, id, currentUserID); err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Deleted item not found",
+			})
+		}
+		utils.Logger.Error().
+			Err(err).
+			Str("admin_id", currentUserID.String()).
+			Str("type", itemType).
+			Str("id", id.String()).
+			Msg("Failed to restore deleted item")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to restore deleted item",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": fmt.Sprintf("Restored %s successfully", itemType),
+		"type":    itemType,
+		"id":      id,
+	})
+}
+
+// RestoreBackupRequest represents a request to restore a backup
+type RestoreBackupRequest struct {
+	TargetDatabase string `json:"target_database"` // Existing, empty database on the same server
+}
+
+// StartBackup starts a backup of the database and the attachment manifest; poll the returned
+// job for progress
+func (h *AdminHandler) StartBackup(c *fiber.Ctx) error {
+	currentUserID := c.Locals("user_id").(uuid.UUID)
+
+	job, err := h.backupService.StartBackup(currentUserID, c.IP(), c.Get("User-Agent"))
+	if err != nil {
+		return h.backupError(c, err, "Failed to start backup")
+	}
+
+	utils.Logger.Warn().
+		Str("job_id", job.ID.String()).
+		Str("admin_id", currentUserID.String()).
+		Msg("Backup started by admin")
+
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
+
+// ListBackups lists the completed backups available for restore, newest first
+func (h *AdminHandler) ListBackups(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	perPage := c.QueryInt("per_page", 20)
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	backups, total, err := h.backupService.ListBackups(page, perPage)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to list backups")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve backups",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"backups":     backups,
+		"total":       total,
+		"page":        page,
+		"per_page":    perPage,
+		"total_pages": (int(total) + perPage - 1) / perPage,
+	})
+}
+
+// GetBackupJob returns the status and progress of a backup or restore
+func (h *AdminHandler) GetBackupJob(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid job ID",
+		})
+	}
+
+	job, err := h.backupService.GetJob(id)
+	if err != nil {
+		return h.backupError(c, err, "Failed to retrieve backup job")
+	}
+	return c.JSON(job)
+}
+
+// RestoreBackup starts restoring a backup into an empty database; poll the returned job for
+// progress
+func (h *AdminHandler) RestoreBackup(c *fiber.Ctx) error {
+	currentUserID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid backup ID",
+		})
+	}
+
+	var req RestoreBackupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	job, err := h.backupService.StartRestore(id, req.TargetDatabase, currentUserID, c.IP(), c.Get("User-Agent"))
+	if err != nil {
+		return h.backupError(c, err, "Failed to start restore")
+	}
+
+	utils.Logger.Warn().
+		Str("job_id", job.ID.String()).
+		Str("backup_id", id.String()).
+		Str("target_database", req.TargetDatabase).
+		Str("admin_id", currentUserID.String()).
+		Msg("Backup restore started by admin")
+
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
+
+// backupError maps backup service errors to responses
+func (h *AdminHandler) backupError(c *fiber.Ctx, err error, message string) error {
+	switch {
+	case errors.Is(err, shutdown.ErrDraining):
+		return middleware.ShuttingDownError(c)
+	case errors.Is(err, services.ErrBackupInProgress):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case strings.HasPrefix(err.Error(), "invalid"):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case strings.Contains(err.Error(), "not found"):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case strings.Contains(err.Error(), "not configured"):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	utils.Logger.Error().Err(err).Msg(message)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
+
+// GetDatabaseStats returns connection pool usage and slow query counts of this instance
+func (h *AdminHandler) GetDatabaseStats(c *fiber.Ctx) error {
+	return c.JSON(database.QueryStats())
+}
+
+// ListScheduledJobs returns the background jobs that run once across instances, with the
+// instance, outcome and duration of their last run
+func (h *AdminHandler) ListScheduledJobs(c *fiber.Ctx) error {
+	jobs, err := scheduler.ListJobs(database.GetDB())
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to list scheduled jobs")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve scheduled jobs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"jobs": jobs,
+	})
+}
+
+// GetAdminIPAccess returns the client networks allowed and denied access to the admin API,
+// and the address the caller is seen from
+func (h *AdminHandler) GetAdminIPAccess(c *fiber.Ctx) error {
+	list, err := services.LoadAdminIPAccess(database.GetDB())
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to load admin IP access list")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve admin IP access list",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"key":       string(models.SystemSettingAdminIPAccess),
+		"access":    list,
+		"client_ip": c.IP(),
+	})
+}
+
+// UpdateAdminIPAccess replaces the admin allowlist and denylist. Lists that would block the
+// caller's own address are refused so an administrator cannot lock themselves out.
+func (h *AdminHandler) UpdateAdminIPAccess(c *fiber.Ctx) error {
+	var req services.IPAccessList
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	list, err := req.Normalize()
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	user := c.Locals("user").(*models.User)
+	settingsService := services.NewSystemSettingsService(database.GetDB())
+	if _, err := settingsService.UpdateAdminIPAccess(list, c.IP(), user.Email); err != nil {
+		if errors.Is(err, services.ErrAdminIPLockout) {
+			return middleware.ValidationError(c, "The lists would block your own address", map[string]interface{}{
+				"client_ip": c.IP(),
+			})
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to save admin IP access list")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save admin IP access list",
+		})
+	}
+
+	utils.Logger.Warn().
+		Str("updated_by", user.Email).
+		Strs("allow", list.Allow).
+		Strs("deny", list.Deny).
+		Msg("Admin IP access list updated")
+
+	return c.JSON(fiber.Map{
+		"message": "Admin IP access list updated",
+		"key":     string(models.SystemSettingAdminIPAccess),
+		"access":  list,
+	})
+}
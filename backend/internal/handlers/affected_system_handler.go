@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/middleware"
 	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/policy"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
@@ -58,8 +60,11 @@ func (h *AffectedSystemHandler) CreateAffectedSystem(c *fiber.Ctx) error {
 		Environment: req.Environment,
 	}
 
-	system, err := h.affectedSystemService.CreateAffectedSystem(serviceReq)
+	system, err := h.affectedSystemService.WithContext(c.UserContext()).CreateAffectedSystem(serviceReq)
 	if err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
 		utils.Logger.Error().Err(err).Msg("Failed to create affected system")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create affected system",
@@ -88,7 +93,7 @@ func (h *AffectedSystemHandler) ListAffectedSystems(c *fiber.Ctx) error {
 		Search:      search,
 	}
 
-	systems, total, err := h.affectedSystemService.ListAffectedSystems(req)
+	systems, total, err := h.affectedSystemService.WithContext(c.UserContext()).ListAffectedSystems(req)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to list affected systems")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -117,7 +122,7 @@ func (h *AffectedSystemHandler) GetAffectedSystem(c *fiber.Ctx) error {
 		return middleware.ValidationError(c, "Invalid system ID", nil)
 	}
 
-	system, err := h.affectedSystemService.GetAffectedSystemByID(id)
+	system, err := h.affectedSystemService.WithContext(c.UserContext()).GetAffectedSystemByID(id)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -143,7 +148,7 @@ func (h *AffectedSystemHandler) GetVulnerabilityAffectedSystems(c *fiber.Ctx) er
 		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
 	}
 
-	systems, err := h.affectedSystemService.GetSystemsForVulnerability(id)
+	systems, err := h.affectedSystemService.WithContext(c.UserContext()).GetSystemsForVulnerability(id)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to get affected systems for vulnerability")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -188,7 +193,10 @@ func (h *AffectedSystemHandler) AddVulnerabilityAffectedSystems(c *fiber.Ctx) er
 		systemIDs = append(systemIDs, systemID)
 	}
 
-	if err := h.vulnerabilityService.AddAffectedSystems(vulnerabilityID, systemIDs); err != nil {
+	if err := h.vulnerabilityService.WithContext(c.UserContext()).AddAffectedSystems(vulnerabilityID, systemIDs); err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": err.Error(),
@@ -219,7 +227,10 @@ func (h *AffectedSystemHandler) RemoveVulnerabilityAffectedSystem(c *fiber.Ctx)
 		return middleware.ValidationError(c, "Invalid system ID", nil)
 	}
 
-	if err := h.vulnerabilityService.RemoveAffectedSystem(vulnerabilityID, systemID); err != nil {
+	if err := h.vulnerabilityService.WithContext(c.UserContext()).RemoveAffectedSystem(vulnerabilityID, systemID); err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": err.Error(),
@@ -268,8 +279,11 @@ func (h *AffectedSystemHandler) UpdateAffectedSystem(c *fiber.Ctx) error {
 		Environment: req.Environment,
 	}
 
-	system, err := h.affectedSystemService.UpdateAffectedSystem(id, serviceReq)
+	system, err := h.affectedSystemService.WithContext(c.UserContext()).UpdateAffectedSystem(id, serviceReq)
 	if err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Affected system not found",
@@ -295,7 +309,12 @@ func (h *AffectedSystemHandler) DeleteAffectedSystem(c *fiber.Ctx) error {
 		return middleware.ValidationError(c, "Invalid system ID", nil)
 	}
 
-	if err := h.affectedSystemService.DeleteAffectedSystem(id); err != nil {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	if err := h.affectedSystemService.WithContext(c.UserContext()).DeleteAffectedSystem(id, userID); err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Affected system not found",
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// AgentHandler handles endpoint agent check-ins and the inventory they report
+type AgentHandler struct {
+	service *services.AgentCheckinService
+}
+
+// NewAgentHandler creates a new agent handler
+func NewAgentHandler() *AgentHandler {
+	return &AgentHandler{
+		service: services.NewAgentCheckinService(database.GetDB()),
+	}
+}
+
+// agentErrorResponse maps agent check-in service errors to HTTP responses
+func agentErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case msg == "asset not found":
+		return middleware.NotFoundError(c, "Asset")
+	case strings.Contains(msg, "required"), strings.HasPrefix(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// CheckIn records an agent check-in, creating or updating the agent's asset and replacing
+// its package inventory. Only API keys can check in.
+// @Summary Agent check-in
+// @Tags Agents
+// @Accept json
+// @Produce json
+// @Param request body services.AgentCheckinRequest true "Endpoint inventory"
+// @Success 200 {object} services.AgentCheckinResult
+// @Success 201 {object} services.AgentCheckinResult
+// @Router /api/v1/agent/checkin [post]
+// @Security BearerAuth
+func (h *AgentHandler) CheckIn(c *fiber.Ctx) error {
+	if c.Locals("auth_method") != "api_key" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Agent check-ins require an API key",
+		})
+	}
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.AgentCheckinRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	result, err := h.service.WithContext(c.UserContext()).CheckIn(req, userID)
+	if err != nil {
+		return agentErrorResponse(c, err, "Failed to record agent check-in")
+	}
+
+	status := fiber.StatusOK
+	if result.Created {
+		status = fiber.StatusCreated
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"data": result,
+		"meta": fiber.Map{
+			"stale_after_seconds": int(services.AgentStaleAfter.Seconds()),
+		},
+	})
+}
+
+// ListAssetPackages lists the packages an asset's agent last reported
+// @Summary List asset packages
+// @Tags Assets
+// @Produce json
+// @Param id path string true "Asset ID"
+// @Param search query string false "Filter by package name"
+// @Param page query int false "Page"
+// @Param limit query int false "Page size (max 500)"
+// @Success 200 {array} models.AssetPackage
+// @Router /api/v1/assets/{id}/packages [get]
+// @Security BearerAuth
+func (h *AgentHandler) ListAssetPackages(c *fiber.Ctx) error {
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset ID", nil)
+	}
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 100)
+
+	packages, total, err := h.service.WithContext(c.UserContext()).ListPackages(assetID, c.Query("search"), page, limit)
+	if err != nil {
+		return agentErrorResponse(c, err, "Failed to list asset packages")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": packages,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
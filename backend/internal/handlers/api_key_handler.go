@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -29,6 +31,7 @@ type CreateAPIKeyRequest struct {
 	ExpiresAt   *time.Time            `json:"expires_at,omitempty"`
 	Description string                `json:"description,omitempty" validate:"max=500"`
 	RateLimitPerMinute int            `json:"rate_limit_per_minute,omitempty" validate:"min=1,max=1000"`
+	AllowedCIDRs       []string       `json:"allowed_cidrs,omitempty"` // e.g. ["203.0.113.0/24"]; empty allows any address
 }
 
 // CreateAPIKeyResponse represents the response after creating an API key
@@ -82,18 +85,31 @@ func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate scope format
+	// Validate scopes against the catalog
 	for _, scope := range req.Scopes {
-		if !isValidScope(scope) {
-			return middleware.ValidationError(c, "Invalid scope format", map[string]interface{}{
-				"scope": scope,
-				"valid_format": "resource:action (e.g., vulnerabilities:read, assets:write, *:*)",
+		if !models.IsCatalogScope(scope) {
+			return middleware.ValidationError(c, "Unknown scope", map[string]interface{}{
+				"scope":        scope,
+				"valid_format": "resource:action from GET /api/v1/api-keys/scopes (e.g., vulnerabilities:read, assets:*, *:*)",
 			})
 		}
 	}
 
+	// A key may not mint a key with scopes it does not hold itself
+	if c.Locals("auth_method") == "api_key" {
+		callerScopes, _ := c.Locals("api_key_scopes").([]string)
+		for _, scope := range req.Scopes {
+			if !models.ScopeGranted(callerScopes, scope) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "Cannot grant a scope the calling API key does not hold",
+					"scope": scope,
+				})
+			}
+		}
+	}
+
 	// Create API key
-	result, err := h.service.Create(services.CreateAPIKeyInput{
+	result, err := h.service.WithContext(c.UserContext()).Create(services.CreateAPIKeyInput{
 		UserID:             userID,
 		Name:               req.Name,
 		Type:               req.Type,
@@ -101,11 +117,15 @@ func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
 		ExpiresAt:          req.ExpiresAt,
 		Description:        req.Description,
 		RateLimitPerMinute: req.RateLimitPerMinute,
+		AllowedCIDRs:       req.AllowedCIDRs,
 	})
 	if err != nil {
 		if err == services.ErrDuplicateKeyName {
 			return middleware.ValidationError(c, "API key name already exists", nil)
 		}
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return middleware.ValidationError(c, err.Error(), nil)
+		}
 		utils.Logger.Error().Err(err).Msg("Failed to create API key")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create API key",
@@ -135,7 +155,7 @@ func (h *APIKeyHandler) ListAPIKeys(c *fiber.Ctx) error {
 		})
 	}
 
-	apiKeys, err := h.service.List(userID)
+	apiKeys, err := h.service.WithContext(c.UserContext()).List(userID)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to list API keys")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -149,6 +169,47 @@ func (h *APIKeyHandler) ListAPIKeys(c *fiber.Ctx) error {
 	})
 }
 
+// APIKeyIntrospectionResponse describes the API key authenticating the request
+type APIKeyIntrospectionResponse struct {
+	APIKey *models.APIKey `json:"api_key"`
+	// GrantedScopes lists every catalog scope the key holds, with wildcards expanded
+	GrantedScopes []string `json:"granted_scopes"`
+	// Secret is "primary", or "secondary" when the request used the previous secret of a rotated key
+	Secret string `json:"secret"`
+}
+
+// RotateAPIKeyRequest represents the request body for rotating an API key
+type RotateAPIKeyRequest struct {
+	// GracePeriodHours keeps the previous secret valid while integrations switch (default 24, 0 = revoke now)
+	GracePeriodHours *int `json:"grace_period_hours,omitempty"`
+}
+
+// ListScopes returns the catalog of scopes API keys can be granted
+func (h *APIKeyHandler) ListScopes(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"data": models.APIKeyScopeCatalog,
+	})
+}
+
+// GetCurrentAPIKey describes the API key used to authenticate the request, including its scopes
+func (h *APIKeyHandler) GetCurrentAPIKey(c *fiber.Ctx) error {
+	apiKey, ok := c.Locals("api_key").(*models.APIKey)
+	if !ok || c.Locals("auth_method") != "api_key" {
+		return middleware.ValidationError(c, "Request is not authenticated with an API key", nil)
+	}
+
+	secret := "primary"
+	if apiKey.AuthenticatedWithSecondary {
+		secret = "secondary"
+	}
+
+	return c.JSON(APIKeyIntrospectionResponse{
+		APIKey:        apiKey,
+		GrantedScopes: models.ExpandScopes(apiKey.GetScopes()),
+		Secret:        secret,
+	})
+}
+
 // GetAPIKey retrieves a specific API key by ID
 func (h *APIKeyHandler) GetAPIKey(c *fiber.Ctx) error {
 	keyID, err := uuid.Parse(c.Params("id"))
@@ -163,7 +224,7 @@ func (h *APIKeyHandler) GetAPIKey(c *fiber.Ctx) error {
 		})
 	}
 
-	apiKey, err := h.service.GetByID(keyID, userID)
+	apiKey, err := h.service.WithContext(c.UserContext()).GetByID(keyID, userID)
 	if err != nil {
 		if err == services.ErrAPIKeyNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -179,6 +240,141 @@ func (h *APIKeyHandler) GetAPIKey(c *fiber.Ctx) error {
 	return c.JSON(apiKey)
 }
 
+// GetAPIKeyUsage returns request counts for one of the user's API keys, per day and per endpoint
+func (h *APIKeyHandler) GetAPIKeyUsage(c *fiber.Ctx) error {
+	keyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid API key ID", nil)
+	}
+
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	days := c.QueryInt("days", 30)
+	if days < 1 || days > 90 {
+		return middleware.ValidationError(c, "days must be between 1 and 90", nil)
+	}
+
+	usage, err := h.service.WithContext(c.UserContext()).Usage(keyID, userID, days)
+	if err != nil {
+		if err == services.ErrAPIKeyNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "API key not found",
+			})
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to get API key usage")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get API key usage",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": usage,
+	})
+}
+
+// RotateAPIKey issues a new secret for a service or MCP key; the previous secret keeps working
+// as the secondary for the grace period
+func (h *APIKeyHandler) RotateAPIKey(c *fiber.Ctx) error {
+	keyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid API key ID", nil)
+	}
+
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	var req RotateAPIKeyRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return middleware.ValidationError(c, "Invalid request body", nil)
+		}
+	}
+	graceHours := 24
+	if req.GracePeriodHours != nil {
+		graceHours = *req.GracePeriodHours
+	}
+	maxHours := int(services.MaxRotationGracePeriod.Hours())
+	if graceHours < 0 || graceHours > maxHours {
+		return middleware.ValidationError(c, fmt.Sprintf("grace_period_hours must be between 0 and %d", maxHours), nil)
+	}
+
+	result, err := h.service.WithContext(c.UserContext()).Rotate(keyID, userID, time.Duration(graceHours)*time.Hour)
+	if err != nil {
+		switch err {
+		case services.ErrAPIKeyNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "API key not found",
+			})
+		case services.ErrRotationNotSupported, services.ErrAPIKeyRevoked:
+			return middleware.ValidationError(c, err.Error(), nil)
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to rotate API key")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to rotate API key",
+		})
+	}
+
+	utils.Logger.Info().
+		Str("user_id", userID.String()).
+		Str("api_key_id", keyID.String()).
+		Int("grace_period_hours", graceHours).
+		Msg("API key rotated")
+
+	return c.JSON(CreateAPIKeyResponse{
+		APIKey:   result.APIKey,
+		PlainKey: result.PlainKey,
+		Message:  "API key rotated successfully. Save the new key securely - it will not be shown again!",
+	})
+}
+
+// RetireSecondaryAPIKey invalidates the previous secret of a rotated key once integrations have switched
+func (h *APIKeyHandler) RetireSecondaryAPIKey(c *fiber.Ctx) error {
+	keyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid API key ID", nil)
+	}
+
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if err := h.service.WithContext(c.UserContext()).RetireSecondary(keyID, userID); err != nil {
+		switch err {
+		case services.ErrAPIKeyNotFound:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "API key not found",
+			})
+		case services.ErrNoSecondaryKey:
+			return middleware.ValidationError(c, err.Error(), nil)
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to retire secondary API key secret")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retire secondary API key secret",
+		})
+	}
+
+	utils.Logger.Info().
+		Str("user_id", userID.String()).
+		Str("api_key_id", keyID.String()).
+		Msg("Secondary API key secret retired")
+
+	return c.JSON(fiber.Map{
+		"message": "Previous API key secret revoked successfully",
+	})
+}
+
 // RevokeAPIKey revokes an API key
 func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
 	keyID, err := uuid.Parse(c.Params("id"))
@@ -193,7 +389,7 @@ func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.service.Revoke(keyID, userID); err != nil {
+	if err := h.service.WithContext(c.UserContext()).Revoke(keyID, userID); err != nil {
 		if err == services.ErrAPIKeyNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "API key not found",
@@ -229,7 +425,7 @@ func (h *APIKeyHandler) DeleteAPIKey(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.service.Delete(keyID, userID); err != nil {
+	if err := h.service.WithContext(c.UserContext()).Delete(keyID, userID); err != nil {
 		if err == services.ErrAPIKeyNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "API key not found",
@@ -280,7 +476,7 @@ func (h *APIKeyHandler) UpdateAPIKeyStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.service.UpdateStatus(keyID, userID, req.Status); err != nil {
+	if err := h.service.WithContext(c.UserContext()).UpdateStatus(keyID, userID, req.Status); err != nil {
 		if err == services.ErrAPIKeyNotFound {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "API key not found",
@@ -303,42 +499,55 @@ func (h *APIKeyHandler) UpdateAPIKeyStatus(c *fiber.Ctx) error {
 	})
 }
 
-// isValidScope validates the scope format (resource:action)
-func isValidScope(scope string) bool {
-	// Allow wildcard
-	if scope == "*:*" {
-		return true
+// UpdateAPIKeyAllowedCIDRsRequest represents the request body for restricting where an API key is used from
+type UpdateAPIKeyAllowedCIDRsRequest struct {
+	AllowedCIDRs []string `json:"allowed_cidrs"`
+}
+
+// UpdateAPIKeyAllowedCIDRs replaces the client networks an API key may be used from
+// PUT /api/v1/api-keys/:id/allowed-cidrs
+func (h *APIKeyHandler) UpdateAPIKeyAllowedCIDRs(c *fiber.Ctx) error {
+	keyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid API key ID", nil)
 	}
 
-	// Check format: resource:action
-	parts := make([]rune, 0, len(scope))
-	colonCount := 0
-	for _, r := range scope {
-		parts = append(parts, r)
-		if r == ':' {
-			colonCount++
-		}
+	var req UpdateAPIKeyAllowedCIDRsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
 	}
 
-	if colonCount != 1 {
-		return false
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
 	}
 
-	// Split and check both parts are non-empty
-	beforeColon := ""
-	afterColon := ""
-	foundColon := false
-	for _, r := range scope {
-		if r == ':' {
-			foundColon = true
-			continue
+	apiKey, err := h.service.WithContext(c.UserContext()).UpdateAllowedCIDRs(keyID, userID, req.AllowedCIDRs)
+	if err != nil {
+		if err == services.ErrAPIKeyNotFound {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "API key not found",
+			})
 		}
-		if !foundColon {
-			beforeColon += string(r)
-		} else {
-			afterColon += string(r)
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return middleware.ValidationError(c, err.Error(), nil)
 		}
+		utils.Logger.Error().Err(err).Msg("Failed to update API key allowed networks")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update API key allowed networks",
+		})
 	}
 
-	return len(beforeColon) > 0 && len(afterColon) > 0
+	utils.Logger.Info().
+		Str("user_id", userID.String()).
+		Str("api_key_id", keyID.String()).
+		Strs("allowed_cidrs", apiKey.AllowedCIDRs).
+		Msg("API key allowed networks updated")
+
+	return c.JSON(fiber.Map{
+		"message": "API key allowed networks updated successfully",
+		"api_key": apiKey,
+	})
 }
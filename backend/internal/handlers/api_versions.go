@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// apiVersion is one mounted REST API version, served at /api/<name>
+type apiVersion struct {
+	name       string
+	release    string
+	middleware []fiber.Handler // Runs before every route of the version
+	errorModel interface{}     // Error response body, for the API documentation
+}
+
+// apiVersions lists the mounted API versions. A breaking response-shape change ships as a new
+// version with its own serializer, leaving existing consumers on the previous one.
+func apiVersions(cfg *config.Config) []apiVersion {
+	v1 := apiVersion{
+		name:       "v1",
+		release:    "1.0.0",
+		middleware: []fiber.Handler{middleware.APIVersion("v1")},
+		errorModel: middleware.ErrorResponse{},
+	}
+	if deprecation, ok := v1Deprecation(cfg); ok {
+		v1.middleware = append(v1.middleware, middleware.Deprecation(deprecation))
+	}
+	// Standard {data, meta} / {error: {code, ...}} envelope (API_RESPONSE_ENVELOPE or X-API-Envelope)
+	v1.middleware = append(v1.middleware, middleware.ResponseEnvelope(cfg.APIResponseEnvelope))
+
+	// v2 always uses the standard envelope and pages lists with opaque cursors
+	v2 := apiVersion{
+		name:    "v2",
+		release: "2.0.0",
+		middleware: []fiber.Handler{
+			middleware.APIVersion("v2"),
+			middleware.CursorPagination(),
+			middleware.SerializeResponses(middleware.V2Response),
+		},
+		errorModel: middleware.APIErrorEnvelope{},
+	}
+
+	return []apiVersion{v1, v2}
+}
+
+// v1Deprecation returns the configured deprecation of /api/v1, if any
+func v1Deprecation(cfg *config.Config) (middleware.DeprecationPolicy, bool) {
+	if cfg.APIV1DeprecationDate == "" {
+		return middleware.DeprecationPolicy{}, false
+	}
+
+	date, err := time.Parse("2006-01-02", cfg.APIV1DeprecationDate)
+	if err != nil {
+		utils.Logger.Warn().Err(err).Msg("Invalid API_V1_DEPRECATION_DATE, not announcing the deprecation of /api/v1")
+		return middleware.DeprecationPolicy{}, false
+	}
+
+	policy := middleware.DeprecationPolicy{
+		Date:          date,
+		Documentation: cfg.APIDeprecationDocURL,
+		Successor: func(path string) string {
+			return strings.Replace(path, "/api/v1", "/api/v2", 1)
+		},
+	}
+	if cfg.APIV1SunsetDate != "" {
+		sunset, err := time.Parse("2006-01-02", cfg.APIV1SunsetDate)
+		if err != nil {
+			utils.Logger.Warn().Err(err).Msg("Invalid API_V1_SUNSET_DATE, announcing the deprecation without a sunset date")
+		} else {
+			policy.Sunset = sunset
+		}
+	}
+	return policy, true
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// AssessmentExportHandler handles regulator export packages of assessments
+type AssessmentExportHandler struct {
+	service *services.AssessmentExportService
+}
+
+// NewAssessmentExportHandler creates a new assessment export handler
+func NewAssessmentExportHandler() *AssessmentExportHandler {
+	return &AssessmentExportHandler{
+		service: services.NewAssessmentExportService(database.GetDB()),
+	}
+}
+
+// ExportPackage streams a ZIP export package of an assessment for regulator submission: the
+// generated report, evidence attachments, an audit trail excerpt and machine-readable JSON, with
+// a SHA-256 manifest of every file
+// POST /api/v1/assessments/:id/export-package
+func (h *AssessmentExportHandler) ExportPackage(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid assessment ID", nil)
+	}
+
+	service := h.service.WithContext(c.UserContext())
+	pkg, err := service.PreparePackage(assessmentID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return middleware.NotFoundError(c, "assessment")
+		}
+		utils.Logger.Error().Err(err).Str("assessment_id", assessmentID.String()).Msg("Failed to prepare export package")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to prepare export package",
+		})
+	}
+
+	c.Set("Content-Type", "application/zip")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", pkg.Filename))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if _, err := service.WritePackage(pkg, w); err != nil {
+			utils.Logger.Error().Err(err).Str("assessment_id", assessmentID.String()).Msg("Failed to stream export package")
+			return
+		}
+		if err := w.Flush(); err != nil {
+			utils.Logger.Error().Err(err).Str("assessment_id", assessmentID.String()).Msg("Failed to stream export package")
+		}
+	})
+	return nil
+}
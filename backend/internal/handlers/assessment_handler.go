@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -10,6 +11,7 @@ import (
 	"github.com/cyops/cyops-backend/internal/services"
 	"github.com/cyops/cyops-backend/pkg/database"
 	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
 )
 
 // AssessmentHandler handles assessment-related requests
@@ -35,6 +37,7 @@ type CreateAssessmentRequest struct {
 	EndDate              string   `json:"end_date"`   // ISO date format (optional)
 	VulnerabilityIDs     []string `json:"vulnerability_ids"`
 	AssetIDs             []string `json:"asset_ids"`
+	AssetGroupIDs        []string `json:"asset_group_ids"`
 }
 
 // UpdateAssessmentRequest represents an update assessment request
@@ -57,6 +60,7 @@ type UpdateAssessmentRequest struct {
 type LinkRequest struct {
 	VulnerabilityID string `json:"vulnerability_id,omitempty"`
 	AssetID         string `json:"asset_id,omitempty"`
+	AssetGroupID    string `json:"asset_group_id,omitempty"`
 	Notes           string `json:"notes,omitempty"`
 }
 
@@ -119,6 +123,16 @@ func (h *AssessmentHandler) CreateAssessment(c *fiber.Ctx) error {
 		assetIDs = append(assetIDs, id)
 	}
 
+	// Parse asset group IDs
+	var assetGroupIDs []uuid.UUID
+	for _, idStr := range req.AssetGroupIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid asset group ID format", nil)
+		}
+		assetGroupIDs = append(assetGroupIDs, id)
+	}
+
 	// Create service request
 	serviceReq := services.CreateAssessmentRequest{
 		Name:                 req.Name,
@@ -130,10 +144,11 @@ func (h *AssessmentHandler) CreateAssessment(c *fiber.Ctx) error {
 		EndDate:              endDate,
 		VulnerabilityIDs:     vulnerabilityIDs,
 		AssetIDs:             assetIDs,
+		AssetGroupIDs:        assetGroupIDs,
 	}
 
 	// Create assessment
-	assessment, err := h.assessmentService.CreateAssessment(serviceReq, userID)
+	assessment, err := h.assessmentService.WithContext(c.UserContext()).CreateAssessment(serviceReq, userID)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to create assessment")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -153,13 +168,18 @@ func (h *AssessmentHandler) GetAssessment(c *fiber.Ctx) error {
 		return middleware.ValidationError(c, "Invalid assessment ID", nil)
 	}
 
-	assessment, err := h.assessmentService.GetAssessment(id)
+	assessment, err := h.assessmentService.WithContext(c.UserContext()).GetAssessment(id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Assessment not found",
 		})
 	}
 
+	// Conditional request (If-None-Match / If-Modified-Since)
+	if middleware.NotModified(c, assessment.UpdatedAt) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	return c.JSON(fiber.Map{
 		"data": assessment,
 	})
@@ -184,7 +204,7 @@ func (h *AssessmentHandler) ListAssessments(c *fiber.Ctx) error {
 		assessmentType = &t
 	}
 
-	assessments, total, err := h.assessmentService.ListAssessments(page, limit, status, assessmentType)
+	assessments, total, err := h.assessmentService.WithContext(c.UserContext()).ListAssessments(page, limit, status, assessmentType)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to list assessments")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -226,6 +246,7 @@ func (h *AssessmentHandler) UpdateAssessment(c *fiber.Ctx) error {
 		FindingsSummary:      req.FindingsSummary,
 		Recommendations:      req.Recommendations,
 		Score:                req.Score,
+		IfMatch:              c.Get(fiber.HeaderIfMatch),
 	}
 
 	if req.Status != nil {
@@ -249,14 +270,18 @@ func (h *AssessmentHandler) UpdateAssessment(c *fiber.Ctx) error {
 		serviceReq.EndDate = &parsed
 	}
 
-	assessment, err := h.assessmentService.UpdateAssessment(id, serviceReq)
+	assessment, err := h.assessmentService.WithContext(c.UserContext()).UpdateAssessment(id, serviceReq)
 	if err != nil {
+		if errors.Is(err, services.ErrPreconditionFailed) {
+			return middleware.PreconditionFailedError(c, "Assessment was modified by another request")
+		}
 		utils.Logger.Error().Err(err).Msg("Failed to update assessment")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update assessment",
 		})
 	}
 
+	c.Set(fiber.HeaderETag, utils.ResourceETag(assessment.UpdatedAt))
 	return c.JSON(fiber.Map{
 		"data": assessment,
 	})
@@ -269,7 +294,7 @@ func (h *AssessmentHandler) DeleteAssessment(c *fiber.Ctx) error {
 		return middleware.ValidationError(c, "Invalid assessment ID", nil)
 	}
 
-	if err := h.assessmentService.DeleteAssessment(id); err != nil {
+	if err := h.assessmentService.WithContext(c.UserContext()).DeleteAssessment(id); err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to delete assessment")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete assessment",
@@ -298,7 +323,7 @@ func (h *AssessmentHandler) LinkVulnerability(c *fiber.Ctx) error {
 		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
 	}
 
-	if err := h.assessmentService.LinkVulnerability(assessmentID, vulnerabilityID, req.Notes); err != nil {
+	if err := h.assessmentService.WithContext(c.UserContext()).LinkVulnerability(assessmentID, vulnerabilityID, req.Notes); err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to link vulnerability")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to link vulnerability",
@@ -322,7 +347,7 @@ func (h *AssessmentHandler) UnlinkVulnerability(c *fiber.Ctx) error {
 		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
 	}
 
-	if err := h.assessmentService.UnlinkVulnerability(assessmentID, vulnerabilityID); err != nil {
+	if err := h.assessmentService.WithContext(c.UserContext()).UnlinkVulnerability(assessmentID, vulnerabilityID); err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to unlink vulnerability")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to unlink vulnerability",
@@ -351,7 +376,7 @@ func (h *AssessmentHandler) LinkAsset(c *fiber.Ctx) error {
 		return middleware.ValidationError(c, "Invalid asset ID", nil)
 	}
 
-	if err := h.assessmentService.LinkAsset(assessmentID, assetID, req.Notes); err != nil {
+	if err := h.assessmentService.WithContext(c.UserContext()).LinkAsset(assessmentID, assetID, req.Notes); err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to link asset")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to link asset",
@@ -375,7 +400,7 @@ func (h *AssessmentHandler) UnlinkAsset(c *fiber.Ctx) error {
 		return middleware.ValidationError(c, "Invalid asset ID", nil)
 	}
 
-	if err := h.assessmentService.UnlinkAsset(assessmentID, assetID); err != nil {
+	if err := h.assessmentService.WithContext(c.UserContext()).UnlinkAsset(assessmentID, assetID); err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to unlink asset")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to unlink asset",
@@ -387,9 +412,91 @@ func (h *AssessmentHandler) UnlinkAsset(c *fiber.Ctx) error {
 	})
 }
 
+// LinkAssetGroup adds an asset group to an assessment's scope
+func (h *AssessmentHandler) LinkAssetGroup(c *fiber.Ctx) error {
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid assessment ID", nil)
+	}
+
+	var req LinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	groupID, err := uuid.Parse(req.AssetGroupID)
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset group ID", nil)
+	}
+
+	if err := h.assessmentService.WithContext(c.UserContext()).LinkAssetGroup(assessmentID, groupID); err != nil {
+		if err.Error() == "asset group not found" {
+			return middleware.NotFoundError(c, "Asset group")
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to link asset group")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to link asset group",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Asset group linked successfully",
+	})
+}
+
+// UnlinkAssetGroup removes an asset group from an assessment's scope
+func (h *AssessmentHandler) UnlinkAssetGroup(c *fiber.Ctx) error {
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid assessment ID", nil)
+	}
+
+	groupID, err := uuid.Parse(c.Params("group_id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset group ID", nil)
+	}
+
+	if err := h.assessmentService.WithContext(c.UserContext()).UnlinkAssetGroup(assessmentID, groupID); err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to unlink asset group")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to unlink asset group",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Asset group unlinked successfully",
+	})
+}
+
+// GetAssessmentScope returns every asset in scope: linked assets plus linked group members
+func (h *AssessmentHandler) GetAssessmentScope(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid assessment ID", nil)
+	}
+
+	assets, err := h.assessmentService.WithContext(c.UserContext()).GetScope(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Assessment not found",
+			})
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to get assessment scope")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get assessment scope",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data":  assets,
+		"total": len(assets),
+	})
+}
+
 // GetAssessmentStats returns statistics about assessments
 func (h *AssessmentHandler) GetAssessmentStats(c *fiber.Ctx) error {
-	stats, err := h.assessmentService.GetAssessmentStats()
+	stats, err := h.assessmentService.WithContext(c.UserContext()).GetAssessmentStats()
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to get assessment stats")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
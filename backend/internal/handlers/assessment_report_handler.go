@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"encoding/base64"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
 	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
 	"github.com/cyops/cyops-backend/pkg/utils"
@@ -50,7 +54,12 @@ func (h *AssessmentReportHandler) UploadReport(c *fiber.Ctx) error {
 	user := c.Locals("user").(*models.User)
 
 	// Upload report
-	report, err := h.service.UploadReport(assessmentID, file, title, description, user.ID)
+	report, err := h.service.WithContext(c.UserContext()).UploadReport(assessmentID, file, title, description, user.ID)
+	if services.IsAttachmentPolicyViolation(err) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to upload report")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -64,6 +73,61 @@ func (h *AssessmentReportHandler) UploadReport(c *fiber.Ctx) error {
 	})
 }
 
+// GenerateReport renders a report from the assessment's linked data as PDF or DOCX
+// POST /api/v1/assessments/:id/reports/generate
+func (h *AssessmentReportHandler) GenerateReport(c *fiber.Ctx) error {
+	// Parse assessment ID
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid assessment ID",
+		})
+	}
+
+	var req services.GenerateReportRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+	if err := req.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Get current user from context
+	user := c.Locals("user").(*models.User)
+
+	report, err := h.service.WithContext(c.UserContext()).GenerateReport(assessmentID, req, user.ID)
+	if isShuttingDown(err) {
+		return middleware.ShuttingDownError(c)
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "assessment not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Assessment not found",
+			})
+		}
+		if strings.Contains(err.Error(), "report template not found") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Report template not found",
+			})
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to generate report")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate report",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Report generated successfully",
+		"data":    report,
+	})
+}
+
 // GetAssessmentReports retrieves all reports for an assessment
 // GET /api/v1/assessments/:id/reports?include_all_versions=false
 func (h *AssessmentReportHandler) GetAssessmentReports(c *fiber.Ctx) error {
@@ -79,7 +143,7 @@ func (h *AssessmentReportHandler) GetAssessmentReports(c *fiber.Ctx) error {
 	includeAllVersions := c.Query("include_all_versions", "false") == "true"
 
 	// Get reports
-	reports, err := h.service.GetAssessmentReports(assessmentID, includeAllVersions)
+	reports, err := h.service.WithContext(c.UserContext()).GetAssessmentReports(assessmentID, includeAllVersions)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to retrieve reports")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -108,7 +172,7 @@ func (h *AssessmentReportHandler) GetReport(c *fiber.Ctx) error {
 	}
 
 	// Get report
-	report, err := h.service.GetReport(reportID)
+	report, err := h.service.WithContext(c.UserContext()).GetReport(reportID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Report not found",
@@ -140,7 +204,7 @@ func (h *AssessmentReportHandler) GetReportFile(c *fiber.Ctx) error {
 	}
 
 	// Get report metadata
-	report, err := h.service.GetReport(reportID)
+	report, err := h.service.WithContext(c.UserContext()).GetReport(reportID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Report not found",
@@ -156,7 +220,7 @@ func (h *AssessmentReportHandler) GetReportFile(c *fiber.Ctx) error {
 	}
 
 	// Get file data
-	fileData, err := h.service.GetReportFile(report)
+	fileData, err := h.service.WithContext(c.UserContext()).GetReportFile(report)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to read report file")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -168,10 +232,73 @@ func (h *AssessmentReportHandler) GetReportFile(c *fiber.Ctx) error {
 	c.Set("Content-Type", report.MimeType)
 	c.Set("Content-Disposition", "inline; filename=\""+report.OriginalName+"\"")
 	c.Set("Content-Length", string(rune(report.FileSize)))
+	if report.Signature != "" {
+		c.Set(services.ReportSignatureHeader, base64.StdEncoding.EncodeToString([]byte(report.Signature)))
+	}
 
 	return c.Send(fileData)
 }
 
+// GetReportSignature serves the detached signature of a generated report, to keep alongside the
+// report and check with POST /api/v1/reports/verify
+// GET /api/v1/assessments/:id/reports/:reportId/signature
+func (h *AssessmentReportHandler) GetReportSignature(c *fiber.Ctx) error {
+	// Parse report ID
+	reportID, err := uuid.Parse(c.Params("reportId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid report ID",
+		})
+	}
+
+	// Get report and verify it belongs to the specified assessment
+	report, err := h.service.WithContext(c.UserContext()).GetReport(reportID)
+	assessmentID, _ := uuid.Parse(c.Params("id"))
+	if err != nil || report.AssessmentID != assessmentID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Report not found",
+		})
+	}
+
+	signature, err := h.service.WithContext(c.UserContext()).GetReportSignature(report)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Report is not signed",
+			})
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to read report signature")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to read report signature",
+		})
+	}
+
+	c.Set("Content-Disposition", "attachment; filename=\""+report.OriginalName+".sig\"")
+	return c.JSON(signature)
+}
+
+// GetReportDownloadURL returns a time-limited download URL for the report file
+// GET /api/v1/assessments/:id/reports/:reportId/download-url
+func (h *AssessmentReportHandler) GetReportDownloadURL(c *fiber.Ctx) error {
+	reportID, err := uuid.Parse(c.Params("reportId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid report ID",
+		})
+	}
+
+	report, err := h.service.WithContext(c.UserContext()).GetReport(reportID)
+	assessmentID, _ := uuid.Parse(c.Params("id"))
+	if err != nil || report.AssessmentID != assessmentID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Report not found",
+		})
+	}
+
+	url, expiresAt, err := h.service.WithContext(c.UserContext()).GetDownloadURL(report)
+	return downloadURLResponse(c, url, expiresAt, err)
+}
+
 // GetReportVersions retrieves version history for a report title
 // GET /api/v1/assessments/:id/reports/:reportId/versions
 func (h *AssessmentReportHandler) GetReportVersions(c *fiber.Ctx) error {
@@ -192,7 +319,7 @@ func (h *AssessmentReportHandler) GetReportVersions(c *fiber.Ctx) error {
 	}
 
 	// Get the report to extract its title
-	report, err := h.service.GetReport(reportID)
+	report, err := h.service.WithContext(c.UserContext()).GetReport(reportID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Report not found",
@@ -200,7 +327,7 @@ func (h *AssessmentReportHandler) GetReportVersions(c *fiber.Ctx) error {
 	}
 
 	// Get all versions
-	versions, err := h.service.GetReportVersions(assessmentID, report.Title)
+	versions, err := h.service.WithContext(c.UserContext()).GetReportVersions(assessmentID, report.Title)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to retrieve report versions")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -229,7 +356,7 @@ func (h *AssessmentReportHandler) DeleteReport(c *fiber.Ctx) error {
 	}
 
 	// Get report to verify it belongs to the assessment
-	report, err := h.service.GetReport(reportID)
+	report, err := h.service.WithContext(c.UserContext()).GetReport(reportID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Report not found",
@@ -245,7 +372,7 @@ func (h *AssessmentReportHandler) DeleteReport(c *fiber.Ctx) error {
 	}
 
 	// Delete report
-	if err := h.service.DeleteReport(reportID); err != nil {
+	if err := h.service.WithContext(c.UserContext()).DeleteReport(reportID); err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to delete report")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete report",
@@ -269,7 +396,7 @@ func (h *AssessmentReportHandler) GetReportStats(c *fiber.Ctx) error {
 	}
 
 	// Get stats
-	stats, err := h.service.GetReportStats(&assessmentID)
+	stats, err := h.service.WithContext(c.UserContext()).GetReportStats(&assessmentID)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to retrieve report stats")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// AssessmentRetestHandler handles the retest workflow of assessment findings
+type AssessmentRetestHandler struct {
+	service *services.AssessmentRetestService
+}
+
+// NewAssessmentRetestHandler creates a new assessment retest handler
+func NewAssessmentRetestHandler() *AssessmentRetestHandler {
+	return &AssessmentRetestHandler{
+		service: services.NewAssessmentRetestService(database.GetDB()),
+	}
+}
+
+// AssignRetesterRequest represents a retester assignment
+type AssignRetesterRequest struct {
+	RetesterID uuid.UUID `json:"retester_id"`
+}
+
+// retestErrorResponse maps retest service errors to HTTP responses
+func retestErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		return middleware.NotFoundError(c, strings.TrimSuffix(msg, " not found"))
+	case strings.Contains(msg, "only the assigned retester"):
+		return middleware.ForbiddenError(c, msg)
+	case strings.Contains(msg, "not pending"), strings.Contains(msg, "already"):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": msg,
+		})
+	case strings.Contains(msg, "required"), strings.Contains(msg, "must be"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// parseRetestIDs parses the assessment and retest IDs from the route
+func parseRetestIDs(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid assessment ID")
+	}
+	retestID, err := uuid.Parse(c.Params("retestId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid retest ID")
+	}
+	return assessmentID, retestID, nil
+}
+
+// ListRetests returns an assessment's retest rounds
+// GET /api/v1/assessments/:id/retests?vulnerability_id=&status=READY_FOR_RETEST
+func (h *AssessmentRetestHandler) ListRetests(c *fiber.Ctx) error {
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid assessment ID", nil)
+	}
+
+	var vulnerabilityID *uuid.UUID
+	if value := c.Query("vulnerability_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+		}
+		vulnerabilityID = &id
+	}
+
+	retests, err := h.service.WithContext(c.UserContext()).ListRetests(assessmentID, vulnerabilityID, c.Query("status"))
+	if err != nil {
+		return retestErrorResponse(c, err, "Failed to list retests")
+	}
+
+	return c.JSON(fiber.Map{
+		"data":  retests,
+		"total": len(retests),
+	})
+}
+
+// GetRetestSummary returns the current retest status of every finding of an assessment
+// GET /api/v1/assessments/:id/retests/summary
+func (h *AssessmentRetestHandler) GetRetestSummary(c *fiber.Ctx) error {
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid assessment ID", nil)
+	}
+
+	summary, err := h.service.WithContext(c.UserContext()).GetRetestSummary(assessmentID)
+	if err != nil {
+		return retestErrorResponse(c, err, "Failed to get retest summary")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": summary,
+	})
+}
+
+// GetRetest returns a single retest round
+// GET /api/v1/assessments/:id/retests/:retestId
+func (h *AssessmentRetestHandler) GetRetest(c *fiber.Ctx) error {
+	assessmentID, retestID, err := parseRetestIDs(c)
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	retest, err := h.service.WithContext(c.UserContext()).GetRetest(assessmentID, retestID)
+	if err != nil {
+		return retestErrorResponse(c, err, "Failed to get retest")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": retest,
+	})
+}
+
+// RequestRetest marks an assessment finding ready for retest
+// POST /api/v1/assessments/:id/retests
+func (h *AssessmentRetestHandler) RequestRetest(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid assessment ID", nil)
+	}
+
+	var req services.RequestRetestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	if req.VulnerabilityID == uuid.Nil {
+		return middleware.ValidationError(c, "vulnerability_id is required", nil)
+	}
+	req.Notes = utils.SanitizeString(req.Notes)
+
+	retest, err := h.service.WithContext(c.UserContext()).RequestRetest(assessmentID, req, userID)
+	if err != nil {
+		return retestErrorResponse(c, err, "Failed to request retest")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Finding marked ready for retest",
+		"data":    retest,
+	})
+}
+
+// AssignRetester assigns the retester of a pending retest
+// PUT /api/v1/assessments/:id/retests/:retestId/retester
+func (h *AssessmentRetestHandler) AssignRetester(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	assessmentID, retestID, err := parseRetestIDs(c)
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	var req AssignRetesterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	if req.RetesterID == uuid.Nil {
+		return middleware.ValidationError(c, "retester_id is required", nil)
+	}
+
+	retest, err := h.service.WithContext(c.UserContext()).AssignRetester(assessmentID, retestID, req.RetesterID, userID)
+	if err != nil {
+		return retestErrorResponse(c, err, "Failed to assign retester")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Retester assigned",
+		"data":    retest,
+	})
+}
+
+// CompleteRetest records the outcome and evidence of a pending retest
+// POST /api/v1/assessments/:id/retests/:retestId/complete
+func (h *AssessmentRetestHandler) CompleteRetest(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	assessmentID, retestID, err := parseRetestIDs(c)
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	var req services.CompleteRetestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	req.Notes = utils.SanitizeString(req.Notes)
+	req.Evidence = utils.SanitizeString(req.Evidence)
+
+	retest, err := h.service.WithContext(c.UserContext()).CompleteRetest(assessmentID, retestID, req, userID)
+	if err != nil {
+		return retestErrorResponse(c, err, "Failed to complete retest")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Retest completed",
+		"data":    retest,
+	})
+}
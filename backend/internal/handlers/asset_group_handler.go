@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// AssetGroupHandler handles asset group management
+type AssetGroupHandler struct {
+	service *services.AssetGroupService
+}
+
+// NewAssetGroupHandler creates a new asset group handler
+func NewAssetGroupHandler() *AssetGroupHandler {
+	return &AssetGroupHandler{
+		service: services.NewAssetGroupService(database.GetDB()),
+	}
+}
+
+// AddAssetGroupMembersRequest is the payload for adding static members to an asset group
+type AddAssetGroupMembersRequest struct {
+	AssetIDs []uuid.UUID `json:"asset_ids"`
+}
+
+// assetGroupErrorResponse maps asset group service errors to HTTP responses
+func assetGroupErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case msg == "asset group not found":
+		return middleware.NotFoundError(c, "Asset group")
+	case msg == "asset not found":
+		return middleware.NotFoundError(c, "Asset")
+	case strings.Contains(msg, "already exists"):
+		return middleware.ConflictError(c, msg)
+	case strings.Contains(msg, "required"), strings.HasPrefix(msg, "invalid"), strings.Contains(msg, "not a static member"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// ListAssetGroups lists the asset groups of the caller's organization
+// @Summary List asset groups
+// @Tags Asset Groups
+// @Produce json
+// @Param search query string false "Filter by name"
+// @Success 200 {array} models.AssetGroup
+// @Router /api/v1/asset-groups [get]
+// @Security BearerAuth
+func (h *AssetGroupHandler) ListAssetGroups(c *fiber.Ctx) error {
+	groups, err := h.service.WithContext(c.UserContext()).List(c.Query("search"))
+	if err != nil {
+		return assetGroupErrorResponse(c, err, "Failed to list asset groups")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": groups,
+	})
+}
+
+// GetAssetGroup returns an asset group with its rules and member count
+// @Summary Get asset group
+// @Tags Asset Groups
+// @Produce json
+// @Param id path string true "Asset group ID"
+// @Success 200 {object} models.AssetGroup
+// @Router /api/v1/asset-groups/{id} [get]
+// @Security BearerAuth
+func (h *AssetGroupHandler) GetAssetGroup(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset group ID", nil)
+	}
+
+	group, err := h.service.WithContext(c.UserContext()).GetByID(id)
+	if err != nil {
+		return assetGroupErrorResponse(c, err, "Failed to get asset group")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": group,
+	})
+}
+
+// CreateAssetGroup creates an asset group from static members and/or dynamic rules
+// @Summary Create asset group
+// @Tags Asset Groups
+// @Accept json
+// @Produce json
+// @Param request body services.AssetGroupRequest true "Asset group"
+// @Success 201 {object} models.AssetGroup
+// @Router /api/v1/asset-groups [post]
+// @Security BearerAuth
+func (h *AssetGroupHandler) CreateAssetGroup(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.AssetGroupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	req.Name = sanitizeStringPtr(req.Name)
+	req.Description = sanitizeStringPtr(req.Description)
+
+	group, err := h.service.WithContext(c.UserContext()).Create(req, userID)
+	if err != nil {
+		return assetGroupErrorResponse(c, err, "Failed to create asset group")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Asset group created successfully",
+		"data":    group,
+	})
+}
+
+// UpdateAssetGroup updates an asset group's name, description or rules
+// @Summary Update asset group
+// @Tags Asset Groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Asset group ID"
+// @Param request body services.AssetGroupRequest true "Asset group"
+// @Success 200 {object} models.AssetGroup
+// @Router /api/v1/asset-groups/{id} [put]
+// @Security BearerAuth
+func (h *AssetGroupHandler) UpdateAssetGroup(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset group ID", nil)
+	}
+
+	var req services.AssetGroupRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	req.Name = sanitizeStringPtr(req.Name)
+	req.Description = sanitizeStringPtr(req.Description)
+
+	group, err := h.service.WithContext(c.UserContext()).Update(id, req)
+	if err != nil {
+		return assetGroupErrorResponse(c, err, "Failed to update asset group")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Asset group updated successfully",
+		"data":    group,
+	})
+}
+
+// DeleteAssetGroup deletes an asset group and removes it from assessment scopes
+// @Summary Delete asset group
+// @Tags Asset Groups
+// @Param id path string true "Asset group ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/asset-groups/{id} [delete]
+// @Security BearerAuth
+func (h *AssetGroupHandler) DeleteAssetGroup(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset group ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).Delete(id); err != nil {
+		return assetGroupErrorResponse(c, err, "Failed to delete asset group")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Asset group deleted successfully",
+	})
+}
+
+// ListAssetGroupMembers lists the assets in a group, static and rule-matched
+// @Summary List asset group members
+// @Tags Asset Groups
+// @Produce json
+// @Param id path string true "Asset group ID"
+// @Param page query int false "Page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {array} models.AffectedSystem
+// @Router /api/v1/asset-groups/{id}/members [get]
+// @Security BearerAuth
+func (h *AssetGroupHandler) ListAssetGroupMembers(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset group ID", nil)
+	}
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+
+	assets, total, err := h.service.WithContext(c.UserContext()).ListMembers(id, page, limit)
+	if err != nil {
+		return assetGroupErrorResponse(c, err, "Failed to list asset group members")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": assets,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// AddAssetGroupMembers adds static members to an asset group
+// @Summary Add asset group members
+// @Tags Asset Groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Asset group ID"
+// @Param request body AddAssetGroupMembersRequest true "Members"
+// @Success 200 {object} models.AssetGroup
+// @Router /api/v1/asset-groups/{id}/members [post]
+// @Security BearerAuth
+func (h *AssetGroupHandler) AddAssetGroupMembers(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset group ID", nil)
+	}
+
+	var req AddAssetGroupMembersRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	group, err := h.service.WithContext(c.UserContext()).AddAssets(id, req.AssetIDs)
+	if err != nil {
+		return assetGroupErrorResponse(c, err, "Failed to add asset group members")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Asset group members added successfully",
+		"data":    group,
+	})
+}
+
+// RemoveAssetGroupMember removes a static member from an asset group
+// @Summary Remove asset group member
+// @Tags Asset Groups
+// @Produce json
+// @Param id path string true "Asset group ID"
+// @Param assetId path string true "Asset ID"
+// @Success 200 {object} models.AssetGroup
+// @Router /api/v1/asset-groups/{id}/members/{assetId} [delete]
+// @Security BearerAuth
+func (h *AssetGroupHandler) RemoveAssetGroupMember(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset group ID", nil)
+	}
+	assetID, err := uuid.Parse(c.Params("assetId"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset ID", nil)
+	}
+
+	group, err := h.service.WithContext(c.UserContext()).RemoveAsset(id, assetID)
+	if err != nil {
+		return assetGroupErrorResponse(c, err, "Failed to remove asset group member")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Asset group member removed successfully",
+		"data":    group,
+	})
+}
+
+// RecomputeAssetGroup re-evaluates an asset group's rules immediately instead of waiting
+// for the background job
+// @Summary Recompute asset group membership
+// @Tags Asset Groups
+// @Produce json
+// @Param id path string true "Asset group ID"
+// @Success 200 {object} models.AssetGroup
+// @Router /api/v1/asset-groups/{id}/recompute [post]
+// @Security BearerAuth
+func (h *AssetGroupHandler) RecomputeAssetGroup(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset group ID", nil)
+	}
+
+	group, err := h.service.WithContext(c.UserContext()).Recompute(id)
+	if err != nil {
+		return assetGroupErrorResponse(c, err, "Failed to recompute asset group")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Asset group membership recomputed",
+		"data":    group,
+	})
+}
@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
 	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/policy"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
@@ -37,11 +42,14 @@ type AssetCreateRequest struct {
 	Description string                   `json:"description,omitempty"`
 	Environment models.Environment       `json:"environment" validate:"required"`
 	Criticality *models.AssetCriticality `json:"criticality,omitempty"`
-	Status      models.AssetStatus       `json:"status,omitempty"`
-	OwnerID     *uuid.UUID               `json:"owner_id,omitempty"`
-	Department  string                   `json:"department,omitempty"`
-	Location    string                   `json:"location,omitempty"`
-	Tags        []string                 `json:"tags,omitempty"`
+	// Keep the given criticality instead of deriving it from the scoring profile
+	CriticalityOverride bool               `json:"criticality_override,omitempty"`
+	Status              models.AssetStatus `json:"status,omitempty"`
+	OwnerID             *uuid.UUID         `json:"owner_id,omitempty"`
+	OwnerTeamID         *uuid.UUID         `json:"owner_team_id,omitempty"`
+	Department          string             `json:"department,omitempty"`
+	Location            string             `json:"location,omitempty"`
+	Tags                []string           `json:"tags,omitempty"`
 }
 
 // AssetResponse defines the response for asset operations
@@ -60,6 +68,11 @@ type AssetCreateResponse struct {
 
 // ListAssets handles GET /api/v1/assets
 func (h *AssetHandler) ListAssets(c *fiber.Ctx) error {
+	// Apply a saved view's filters (?view_id=) before parsing
+	if err := applySavedView(c, models.SavedViewResourceAsset); err != nil {
+		return savedViewErrorResponse(c, err, "Failed to apply saved view")
+	}
+
 	// Parse query parameters
 	params := services.AssetListParams{
 		Page:      c.QueryInt("page", 1),
@@ -96,8 +109,27 @@ func (h *AssetHandler) ListAssets(c *fiber.Ctx) error {
 		}
 	}
 
+	if ownerTeamID := c.Query("owner_team_id"); ownerTeamID != "" {
+		if id, err := uuid.Parse(ownerTeamID); err == nil {
+			params.OwnerTeamID = &id
+		}
+	}
+
+	// Agent-managed assets that stopped (or kept) checking in
+	if agentStale := c.Query("agent_stale"); agentStale != "" {
+		stale := c.QueryBool("agent_stale")
+		params.AgentStale = &stale
+	}
+
+	// Parse sparse fieldset (?fields=, ?include=)
+	fieldset, err := parseFieldset(c, services.AssetFields)
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+	params.Fieldset = fieldset
+
 	// Get assets
-	response, err := h.assetService.List(params)
+	response, err := h.assetService.WithContext(c.UserContext()).List(params)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to list assets")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -105,7 +137,25 @@ func (h *AssetHandler) ListAssets(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(response)
+	if !fieldset.Sparse() {
+		return c.JSON(response)
+	}
+
+	data, err := fieldset.Project(response.Data)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to project assets")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve assets",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data":        data,
+		"total":       response.Total,
+		"page":        response.Page,
+		"limit":       response.Limit,
+		"total_pages": response.TotalPages,
+	})
 }
 
 // CreateAsset handles POST /api/v1/assets
@@ -128,19 +178,22 @@ func (h *AssetHandler) CreateAsset(c *fiber.Ctx) error {
 		Criticality: req.Criticality,
 		Status:      req.Status,
 		OwnerID:     req.OwnerID,
+		OwnerTeamID: req.OwnerTeamID,
 		Department:  req.Department,
 		Location:    req.Location,
+
+		CriticalityOverride: req.CriticalityOverride,
 	}
 
 	// Validate the asset
-	if err := h.validationService.ValidateCreate(asset); err != nil {
+	if err := h.validationService.WithContext(c.UserContext()).ValidateCreate(asset); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
 	// Check for duplicates (soft warning, not blocking)
-	duplicateCheck, err := h.validationService.CheckDuplicate(
+	duplicateCheck, err := h.validationService.WithContext(c.UserContext()).CheckDuplicate(
 		req.Hostname,
 		req.IPAddress,
 		req.Environment,
@@ -150,7 +203,10 @@ func (h *AssetHandler) CreateAsset(c *fiber.Ctx) error {
 	}
 
 	// Create the asset
-	if err := h.assetService.Create(asset); err != nil {
+	if err := h.assetService.WithContext(c.UserContext()).Create(asset); err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
 		utils.Logger.Error().Err(err).Msg("Failed to create asset")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create asset",
@@ -159,7 +215,8 @@ func (h *AssetHandler) CreateAsset(c *fiber.Ctx) error {
 
 	// Handle tags if provided
 	if len(req.Tags) > 0 {
-		if err := h.assetService.AddTags(asset.ID.String(), req.Tags); err != nil {
+		userID := c.Locals("user_id").(uuid.UUID)
+		if err := h.assetService.WithContext(c.UserContext()).AddTags(asset.ID.String(), req.Tags, userID); err != nil {
 			utils.Logger.Error().Err(err).Msg("Failed to add tags to asset")
 			// Don't fail the request, just log the error
 		}
@@ -188,7 +245,12 @@ func (h *AssetHandler) GetAsset(c *fiber.Ctx) error {
 	id := c.Params("id")
 	includeVulns := c.QueryBool("include_vulnerabilities", false)
 
-	asset, err := h.assetService.GetByID(id, includeVulns)
+	fieldset, err := parseFieldset(c, services.AssetFields)
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	asset, err := h.assetService.WithContext(c.UserContext()).GetByIDWithFieldset(id, includeVulns, fieldset)
 	if err != nil {
 		utils.Logger.Error().Err(err).Str("asset_id", id).Msg("Failed to get asset")
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -196,27 +258,42 @@ func (h *AssetHandler) GetAsset(c *fiber.Ctx) error {
 		})
 	}
 
+	// Conditional request (If-None-Match / If-Modified-Since)
+	if middleware.NotModified(c, asset.UpdatedAt) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
 	// Add vulnerability statistics using GetVulnerabilityStats
 	response := AssetResponse{
 		AffectedSystem: *asset,
 	}
 
-	// Get vulnerability stats from the database
-	stats, err := asset.GetVulnerabilityStats(h.assetService.GetDB())
-	if err != nil {
-		utils.Logger.Error().Err(err).Str("asset_id", id).Msg("Failed to get vulnerability stats")
-		// Don't fail the request, just omit stats
-	} else {
-		response.VulnerabilityStats = stats
-		// Calculate total count
-		totalCount := 0
-		for _, count := range stats {
-			totalCount += count
+	// Get vulnerability stats from the database, unless a sparse request left them out
+	if fieldset.Wants("vulnerability_stats") || fieldset.Wants("vulnerability_count") {
+		stats, err := asset.GetVulnerabilityStats(h.assetService.WithContext(c.UserContext()).GetDB())
+		if err != nil {
+			utils.Logger.Error().Err(err).Str("asset_id", id).Msg("Failed to get vulnerability stats")
+			// Don't fail the request, just omit stats
+		} else {
+			response.VulnerabilityStats = stats
+			// Calculate total count
+			totalCount := 0
+			for _, count := range stats {
+				totalCount += count
+			}
+			response.VulnerabilityCount = totalCount
 		}
-		response.VulnerabilityCount = totalCount
 	}
 
-	return c.JSON(response)
+	data, err := fieldset.Project(response)
+	if err != nil {
+		utils.Logger.Error().Err(err).Str("asset_id", id).Msg("Failed to project asset")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get asset",
+		})
+	}
+
+	return c.JSON(data)
 }
 
 // UpdateAsset handles PUT /api/v1/assets/:id
@@ -231,7 +308,7 @@ func (h *AssetHandler) UpdateAsset(c *fiber.Ctx) error {
 	}
 
 	// Get existing asset for validation
-	existingAsset, err := h.assetService.GetByID(id, false)
+	existingAsset, err := h.assetService.WithContext(c.UserContext()).GetByID(id, false)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Asset not found",
@@ -239,15 +316,22 @@ func (h *AssetHandler) UpdateAsset(c *fiber.Ctx) error {
 	}
 
 	// Validate updates
-	if err := h.validationService.ValidateUpdate(existingAsset, req); err != nil {
+	if err := h.validationService.WithContext(c.UserContext()).ValidateUpdate(existingAsset, req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
 	// Update the asset
-	updatedAsset, err := h.assetService.Update(id, req)
+	userID := c.Locals("user_id").(uuid.UUID)
+	updatedAsset, err := h.assetService.WithContext(c.UserContext()).UpdateIfMatch(id, req, userID, c.Get(fiber.HeaderIfMatch))
 	if err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
+		if errors.Is(err, services.ErrPreconditionFailed) {
+			return middleware.PreconditionFailedError(c, "Asset was modified by another request")
+		}
 		utils.Logger.Error().Err(err).Str("asset_id", id).Msg("Failed to update asset")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update asset",
@@ -258,14 +342,19 @@ func (h *AssetHandler) UpdateAsset(c *fiber.Ctx) error {
 		Str("asset_id", id).
 		Msg("Asset updated successfully")
 
+	c.Set(fiber.HeaderETag, utils.ResourceETag(updatedAsset.UpdatedAt))
 	return c.JSON(updatedAsset)
 }
 
 // DeleteAsset handles DELETE /api/v1/assets/:id
 func (h *AssetHandler) DeleteAsset(c *fiber.Ctx) error {
 	id := c.Params("id")
+	userID := c.Locals("user_id").(uuid.UUID)
 
-	if err := h.assetService.Delete(id); err != nil {
+	if err := h.assetService.WithContext(c.UserContext()).Delete(id, userID); err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
 		utils.Logger.Error().Err(err).Str("asset_id", id).Msg("Failed to delete asset")
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Asset not found",
@@ -313,8 +402,12 @@ func (h *AssetHandler) UpdateAssetStatus(c *fiber.Ctx) error {
 	status := models.AssetStatus(req.Status)
 
 	// Update status
-	asset, err := h.assetService.UpdateStatus(assetID.String(), status, req.Notes)
+	userID := c.Locals("user_id").(uuid.UUID)
+	asset, err := h.assetService.WithContext(c.UserContext()).UpdateStatus(assetID.String(), status, req.Notes, userID)
 	if err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
 		if err.Error() == "asset not found" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Asset not found",
@@ -326,6 +419,11 @@ func (h *AssetHandler) UpdateAssetStatus(c *fiber.Ctx) error {
 				"error": err.Error(),
 			})
 		}
+		if strings.Contains(err.Error(), "already decommissioned") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update asset status",
 		})
@@ -334,6 +432,84 @@ func (h *AssetHandler) UpdateAssetStatus(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(asset)
 }
 
+// decommissionErrorResponse maps asset decommission errors to HTTP responses
+func decommissionErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case errors.Is(err, policy.ErrDenied):
+		return middleware.ForbiddenError(c, msg)
+	case msg == "asset not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Asset not found",
+		})
+	case msg == "replacement asset not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Replacement asset not found",
+		})
+	case strings.Contains(msg, "already"):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": msg,
+		})
+	case strings.Contains(msg, "required"), strings.Contains(msg, "invalid"):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// PreviewDecommission handles GET /api/v1/assets/:id/decommission: the open findings a
+// decommission would dispose of and the assignees it would notify
+func (h *AssetHandler) PreviewDecommission(c *fiber.Ctx) error {
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid asset ID format",
+		})
+	}
+
+	userID := c.Locals("user_id").(uuid.UUID)
+	preview, err := h.assetService.WithContext(c.UserContext()).PreviewDecommission(assetID, userID)
+	if err != nil {
+		return decommissionErrorResponse(c, err, "Failed to preview asset decommission")
+	}
+
+	return c.JSON(preview)
+}
+
+// DecommissionAsset handles POST /api/v1/assets/:id/decommission: retires the asset, closing
+// or reassigning its open findings, and schedules its cleanup after the grace period
+func (h *AssetHandler) DecommissionAsset(c *fiber.Ctx) error {
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid asset ID format",
+		})
+	}
+
+	var req services.AssetDecommissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	userID := c.Locals("user_id").(uuid.UUID)
+	result, err := h.assetService.WithContext(c.UserContext()).DecommissionAsset(assetID, req, userID)
+	if err != nil {
+		return decommissionErrorResponse(c, err, "Failed to decommission asset")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Asset decommissioned successfully",
+		"data":    result,
+	})
+}
+
 // GetAssetVulnerabilities handles GET /api/v1/assets/:id/vulnerabilities
 func (h *AssetHandler) GetAssetVulnerabilities(c *fiber.Ctx) error {
 	// Parse asset ID
@@ -345,7 +521,7 @@ func (h *AssetHandler) GetAssetVulnerabilities(c *fiber.Ctx) error {
 	}
 
 	// Check if asset exists
-	_, err = h.assetService.GetByID(assetID.String(), false)
+	_, err = h.assetService.WithContext(c.UserContext()).GetByID(assetID.String(), false)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Asset not found",
@@ -375,7 +551,7 @@ func (h *AssetHandler) GetAssetVulnerabilities(c *fiber.Ctx) error {
 	}
 
 	// Get vulnerabilities
-	response, err := h.assetService.GetVulnerabilities(assetID, params)
+	response, err := h.assetService.WithContext(c.UserContext()).GetVulnerabilities(assetID, params)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve vulnerabilities",
@@ -385,6 +561,42 @@ func (h *AssetHandler) GetAssetVulnerabilities(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
+// GetAssetHistory handles GET /api/v1/assets/:id/history
+func (h *AssetHandler) GetAssetHistory(c *fiber.Ctx) error {
+	// Parse asset ID
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid asset ID format",
+		})
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+
+	history, total, err := h.assetService.WithContext(c.UserContext()).GetHistory(assetID, page, limit)
+	if err != nil {
+		if err.Error() == "asset not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Asset not found",
+			})
+		}
+		utils.Logger.Error().Err(err).Str("asset_id", assetID.String()).Msg("Failed to get asset history")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve asset history",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"data": history,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
 // AddAssetTags handles POST /api/v1/assets/:id/tags
 func (h *AssetHandler) AddAssetTags(c *fiber.Ctx) error {
 	// Parse asset ID
@@ -413,8 +625,12 @@ func (h *AssetHandler) AddAssetTags(c *fiber.Ctx) error {
 	}
 
 	// Add tags
-	err = h.assetService.AddTags(assetID.String(), req.Tags)
+	userID := c.Locals("user_id").(uuid.UUID)
+	err = h.assetService.WithContext(c.UserContext()).AddTags(assetID.String(), req.Tags, userID)
 	if err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
 		if err.Error() == "asset not found" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Asset not found",
@@ -426,7 +642,7 @@ func (h *AssetHandler) AddAssetTags(c *fiber.Ctx) error {
 	}
 
 	// Fetch updated asset to return
-	asset, err := h.assetService.GetByID(assetID.String(), false)
+	asset, err := h.assetService.WithContext(c.UserContext()).GetByID(assetID.String(), false)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Tags added but failed to fetch updated asset",
@@ -455,8 +671,12 @@ func (h *AssetHandler) RemoveAssetTag(c *fiber.Ctx) error {
 	}
 
 	// Remove tag
-	err = h.assetService.RemoveTag(assetID.String(), tag)
+	userID := c.Locals("user_id").(uuid.UUID)
+	err = h.assetService.WithContext(c.UserContext()).RemoveTag(assetID.String(), tag, userID)
 	if err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
 		if err.Error() == "asset not found" {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Asset not found",
@@ -473,7 +693,7 @@ func (h *AssetHandler) RemoveAssetTag(c *fiber.Ctx) error {
 	}
 
 	// Fetch updated asset to return
-	asset, err := h.assetService.GetByID(assetID.String(), false)
+	asset, err := h.assetService.WithContext(c.UserContext()).GetByID(assetID.String(), false)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Tag removed but failed to fetch updated asset",
@@ -486,7 +706,7 @@ func (h *AssetHandler) RemoveAssetTag(c *fiber.Ctx) error {
 // GetAssetStats handles GET /api/v1/assets/stats
 func (h *AssetHandler) GetAssetStats(c *fiber.Ctx) error {
 	// Get statistics
-	stats, err := h.assetService.GetStats()
+	stats, err := h.assetService.WithContext(c.UserContext()).GetStats()
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve statistics",
@@ -519,7 +739,7 @@ func (h *AssetHandler) CheckDuplicateAsset(c *fiber.Ctx) error {
 	}
 
 	// Check for duplicates
-	results, err := h.assetService.CheckDuplicate(req.Name, req.IPAddress, req.Hostname, req.Threshold)
+	results, err := h.assetService.WithContext(c.UserContext()).CheckDuplicate(req.Name, req.IPAddress, req.Hostname, req.Threshold)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to check for duplicates",
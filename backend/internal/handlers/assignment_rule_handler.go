@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+)
+
+// AssignmentRuleHandler handles management of the rules that route new vulnerabilities
+type AssignmentRuleHandler struct {
+	service *services.AssignmentRuleService
+}
+
+// NewAssignmentRuleHandler creates a new assignment rule handler
+func NewAssignmentRuleHandler() *AssignmentRuleHandler {
+	return &AssignmentRuleHandler{
+		service: services.NewAssignmentRuleService(database.GetDB()),
+	}
+}
+
+// ListRules lists assignment rules in evaluation order
+// GET /api/v1/assignment-rules?enabled=true
+func (h *AssignmentRuleHandler) ListRules(c *fiber.Ctx) error {
+	rules, err := h.service.WithContext(c.UserContext()).ListRules(c.QueryBool("enabled", false))
+	if err != nil {
+		return policyErrorResponse(c, err, "Assignment rule", "Failed to list assignment rules")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": rules,
+	})
+}
+
+// GetRule returns an assignment rule
+// GET /api/v1/assignment-rules/:id
+func (h *AssignmentRuleHandler) GetRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid assignment rule ID", nil)
+	}
+
+	rule, err := h.service.WithContext(c.UserContext()).GetRule(id)
+	if err != nil {
+		return policyErrorResponse(c, err, "Assignment rule", "Failed to get assignment rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": rule,
+	})
+}
+
+// CreateRule creates an assignment rule applied to vulnerabilities created or imported afterwards
+// POST /api/v1/assignment-rules
+func (h *AssignmentRuleHandler) CreateRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.AssignmentRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	rule, err := h.service.WithContext(c.UserContext()).CreateRule(req, userID)
+	if err != nil {
+		return policyErrorResponse(c, err, "Assignment rule", "Failed to create assignment rule")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Assignment rule created successfully",
+		"data":    rule,
+	})
+}
+
+// UpdateRule updates an assignment rule
+// PUT /api/v1/assignment-rules/:id
+func (h *AssignmentRuleHandler) UpdateRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid assignment rule ID", nil)
+	}
+
+	var req services.AssignmentRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	rule, err := h.service.WithContext(c.UserContext()).UpdateRule(id, req, userID)
+	if err != nil {
+		return policyErrorResponse(c, err, "Assignment rule", "Failed to update assignment rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Assignment rule updated successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteRule deletes an assignment rule; vulnerabilities it routed keep their assignment
+// DELETE /api/v1/assignment-rules/:id
+func (h *AssignmentRuleHandler) DeleteRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid assignment rule ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).DeleteRule(id, userID); err != nil {
+		return policyErrorResponse(c, err, "Assignment rule", "Failed to delete assignment rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Assignment rule deleted successfully",
+	})
+}
+
+// SimulateRules dry-runs the enabled rules, or an unsaved rule, against existing
+// vulnerabilities and reports where they would be routed without assigning them
+// POST /api/v1/assignment-rules/simulate
+func (h *AssignmentRuleHandler) SimulateRules(c *fiber.Ctx) error {
+	var req services.AssignmentSimulationRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return middleware.ValidationError(c, "Invalid request body", nil)
+		}
+	}
+
+	result, err := h.service.WithContext(c.UserContext()).Simulate(req)
+	if err != nil {
+		return policyErrorResponse(c, err, "Assignment rule", "Failed to simulate assignment rules")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": result,
+	})
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// AuditorTokenHeader carries the share token of auditor portal requests
+const AuditorTokenHeader = "X-Auditor-Token"
+
+// AuditorShareHandler handles auditor share tokens and the read-only auditor portal
+type AuditorShareHandler struct {
+	service *services.AuditorShareService
+}
+
+// NewAuditorShareHandler creates a new auditor share handler
+func NewAuditorShareHandler() *AuditorShareHandler {
+	return &AuditorShareHandler{
+		service: services.NewAuditorShareService(database.GetDB()),
+	}
+}
+
+// auditorShareErrorResponse maps auditor share service errors to HTTP responses
+func auditorShareErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case errors.Is(err, services.ErrAuditorTokenInvalid):
+		return middleware.UnauthorizedError(c, msg)
+	case strings.Contains(msg, "not found"):
+		return middleware.NotFoundError(c, strings.TrimSuffix(msg, " not found"))
+	case strings.Contains(msg, "already"):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": msg,
+		})
+	case strings.Contains(msg, "required"), strings.HasPrefix(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// parseShareTokenIDs parses the assessment and share token IDs from the route
+func parseShareTokenIDs(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid assessment ID")
+	}
+	tokenID, err := uuid.Parse(c.Params("tokenId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("Invalid share token ID")
+	}
+	return assessmentID, tokenID, nil
+}
+
+// IssueShareToken issues a read-only share token for an external auditor. The token is only
+// returned in this response.
+// POST /api/v1/assessments/:id/share-tokens
+func (h *AuditorShareHandler) IssueShareToken(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid assessment ID", nil)
+	}
+
+	var req services.IssueAuditorTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	req.AuditorName = utils.SanitizeString(req.AuditorName)
+
+	token, err := h.service.WithContext(c.UserContext()).IssueToken(assessmentID, req, userID)
+	if err != nil {
+		return auditorShareErrorResponse(c, err, "Failed to issue share token")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Share token issued. Store it now, it cannot be shown again.",
+		"data":    token,
+	})
+}
+
+// ListShareTokens returns the share tokens of an assessment
+// GET /api/v1/assessments/:id/share-tokens
+func (h *AuditorShareHandler) ListShareTokens(c *fiber.Ctx) error {
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid assessment ID", nil)
+	}
+
+	tokens, err := h.service.WithContext(c.UserContext()).ListTokens(assessmentID)
+	if err != nil {
+		return auditorShareErrorResponse(c, err, "Failed to list share tokens")
+	}
+
+	return c.JSON(fiber.Map{
+		"data":  tokens,
+		"total": len(tokens),
+	})
+}
+
+// RevokeShareToken revokes a share token
+// DELETE /api/v1/assessments/:id/share-tokens/:tokenId
+func (h *AuditorShareHandler) RevokeShareToken(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	assessmentID, tokenID, err := parseShareTokenIDs(c)
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	token, err := h.service.WithContext(c.UserContext()).RevokeToken(assessmentID, tokenID, userID)
+	if err != nil {
+		return auditorShareErrorResponse(c, err, "Failed to revoke share token")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Share token revoked",
+		"data":    token,
+	})
+}
+
+// GetShareTokenAccessLog returns the requests made with a share token
+// GET /api/v1/assessments/:id/share-tokens/:tokenId/access-log?page=1&limit=50
+func (h *AuditorShareHandler) GetShareTokenAccessLog(c *fiber.Ctx) error {
+	assessmentID, tokenID, err := parseShareTokenIDs(c)
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	accesses, total, err := h.service.WithContext(c.UserContext()).ListAccessLog(assessmentID, tokenID, page, limit)
+	if err != nil {
+		return auditorShareErrorResponse(c, err, "Failed to get share token access log")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": accesses,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// authenticateAuditor resolves the share token of a portal request, from its header or, for
+// file links, the token query parameter, and returns a service scoped to the token's organization
+func (h *AuditorShareHandler) authenticateAuditor(c *fiber.Ctx) (*models.AuditorShareToken, *services.AuditorShareService, error) {
+	secret := c.Get(AuditorTokenHeader)
+	if secret == "" {
+		secret = c.Query("token")
+	}
+	service := h.service.WithContext(c.UserContext())
+	token, err := service.Authenticate(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	return token, service.ForToken(token), nil
+}
+
+// recordAuditorAccess logs a portal request. A failure to log does not fail the request.
+func recordAuditorAccess(c *fiber.Ctx, service *services.AuditorShareService, token *models.AuditorShareToken, resource string, resourceID *uuid.UUID) {
+	if err := service.RecordAccess(token, resource, resourceID, c.IP(), c.Get(fiber.HeaderUserAgent)); err != nil {
+		utils.Logger.Error().Err(err).Str("token_id", token.ID.String()).Msg("Failed to log auditor portal access")
+	}
+}
+
+// GetPortal returns the assessment, reports, findings and evidence a share token grants access to
+// GET /api/v1/auditor-portal
+func (h *AuditorShareHandler) GetPortal(c *fiber.Ctx) error {
+	token, service, err := h.authenticateAuditor(c)
+	if err != nil {
+		return auditorShareErrorResponse(c, err, "Failed to check share token")
+	}
+
+	view, err := service.PortalView(token)
+	if err != nil {
+		return auditorShareErrorResponse(c, err, "Failed to load assessment")
+	}
+	recordAuditorAccess(c, service, token, models.AuditorResourceAssessment, &token.AssessmentID)
+
+	return c.JSON(fiber.Map{
+		"data": view,
+	})
+}
+
+// GetPortalReportFile serves a report file of the shared assessment
+// GET /api/v1/auditor-portal/reports/:reportId/file
+func (h *AuditorShareHandler) GetPortalReportFile(c *fiber.Ctx) error {
+	token, service, err := h.authenticateAuditor(c)
+	if err != nil {
+		return auditorShareErrorResponse(c, err, "Failed to check share token")
+	}
+
+	reportID, err := uuid.Parse(c.Params("reportId"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid report ID", nil)
+	}
+
+	report, data, err := service.PortalReportFile(token, reportID)
+	if err != nil {
+		return auditorShareErrorResponse(c, err, "Failed to read report file")
+	}
+	recordAuditorAccess(c, service, token, models.AuditorResourceReport, &report.ID)
+
+	c.Set("Content-Type", report.MimeType)
+	c.Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", report.OriginalName))
+	return c.Send(data)
+}
+
+// GetPortalEvidenceFile serves an evidence file of a finding of the shared assessment
+// GET /api/v1/auditor-portal/evidence/:attachmentId/file
+func (h *AuditorShareHandler) GetPortalEvidenceFile(c *fiber.Ctx) error {
+	token, service, err := h.authenticateAuditor(c)
+	if err != nil {
+		return auditorShareErrorResponse(c, err, "Failed to check share token")
+	}
+
+	attachmentID, err := uuid.Parse(c.Params("attachmentId"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid evidence ID", nil)
+	}
+
+	attachment, data, err := service.PortalEvidenceFile(token, attachmentID)
+	if err != nil {
+		return auditorShareErrorResponse(c, err, "Failed to read evidence file")
+	}
+	recordAuditorAccess(c, service, token, models.AuditorResourceEvidence, &attachment.ID)
+
+	c.Set("Content-Type", attachment.MimeType)
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", attachment.OriginalName))
+	return c.Send(data)
+}
@@ -1,27 +1,34 @@
 package handlers
 
 import (
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/auth"
 	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/cyops/cyops-backend/pkg/database"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
 // AuthHandler handles authentication requests
 type AuthHandler struct {
-	userService  *services.UserService
-	emailService *services.EmailService
+	userService       *services.UserService
+	emailService      *services.EmailService
+	geoHeadersTrusted bool
 }
 
 // NewAuthHandler creates a new auth handler
 func NewAuthHandler(cfg *config.Config) *AuthHandler {
 	return &AuthHandler{
-		userService:  services.NewUserService(),
-		emailService: services.NewEmailService(cfg),
+		userService:       services.NewUserService(),
+		emailService:      services.NewEmailService(database.GetDB(), cfg),
+		geoHeadersTrusted: cfg.GeoHeadersTrusted,
 	}
 }
 
@@ -74,7 +81,9 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	}
 
 	// Send verification email
-	if err := h.emailService.SendVerificationEmail(user.Email, user.Name, token.Token); err != nil {
+	expiresAt := services.NewUserPreferenceService(database.GetDB()).FormatTime(user.ID, token.ExpiresAt)
+	lang := middleware.LanguageFor(c, user.ID)
+	if err := h.emailService.SendVerificationEmail(user.Email, user.Name, lang, token.Token, expiresAt); err != nil {
 		utils.Logger.Error().Err(err).Str("email", user.Email).Msg("Failed to send verification email")
 		// Don't fail registration if email fails
 	}
@@ -131,14 +140,22 @@ type LoginRequest struct {
 	Email         string `json:"email"`
 	Password      string `json:"password"`
 	TwoFactorCode string `json:"two_factor_code,omitempty"`
+	BackupCode    string `json:"backup_code,omitempty"` // recovery code when the TOTP device is lost
 }
 
 // LoginResponse represents a login response
 type LoginResponse struct {
-	Message           string      `json:"message"`
-	User              interface{} `json:"user,omitempty"`
-	Token             string      `json:"token,omitempty"`
-	RequiresTwoFactor bool        `json:"requires_two_factor,omitempty"`
+	Message string      `json:"message"`
+	User    interface{} `json:"user,omitempty"`
+	// Token is a short-lived access token; RefreshToken obtains the next one from /auth/refresh
+	Token             string     `json:"token,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	RefreshToken      string     `json:"refresh_token,omitempty"`
+	RefreshExpiresAt  *time.Time `json:"refresh_expires_at,omitempty"`
+	SessionID         string     `json:"session_id,omitempty"`
+	RequiresTwoFactor bool       `json:"requires_two_factor,omitempty"`
+	// BackupCodesRemaining is returned when the login used a backup code
+	BackupCodesRemaining *int `json:"backup_codes_remaining,omitempty"`
 }
 
 // Login handles user login
@@ -172,6 +189,12 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 			Str("email", req.Email).
 			Str("ip", ipAddress).
 			Msg("Login failed - user not found")
+		sessionService.RecordLogin(services.LoginAttempt{
+			FailReason: "unknown account",
+			IPAddress:  ipAddress,
+			UserAgent:  userAgent,
+			Location:   h.clientLocation(c),
+		})
 		return middleware.ValidationError(c, "Invalid email or password", nil)
 	}
 
@@ -186,22 +209,39 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 			Str("email", req.Email).
 			Str("ip", ipAddress).
 			Msg("Login failed - invalid password")
+		sessionService.RecordLogin(services.LoginAttempt{
+			UserID:     &user.ID,
+			FailReason: "invalid password",
+			IPAddress:  ipAddress,
+			UserAgent:  userAgent,
+			Location:   h.clientLocation(c),
+		})
 		return middleware.ValidationError(c, "Invalid email or password", nil)
 	}
 
 	// Check if 2FA is enabled
+	var backupCodesRemaining *int
 	if user.TwoFactorEnabled {
 		// If no 2FA code provided, request it
-		if req.TwoFactorCode == "" {
+		if req.TwoFactorCode == "" && req.BackupCode == "" {
 			return c.JSON(LoginResponse{
 				Message:           "Two-factor authentication required",
 				RequiresTwoFactor: true,
 			})
 		}
 
-		// Verify 2FA code
+		// Verify 2FA code, or the backup code when the TOTP device is unavailable
 		twoFactorService := services.NewTwoFactorService()
-		valid, err := twoFactorService.VerifyTOTP(user.ID, req.TwoFactorCode)
+		var valid bool
+		if req.TwoFactorCode != "" {
+			valid, err = twoFactorService.VerifyTOTP(user.ID, req.TwoFactorCode)
+		} else {
+			var remaining int
+			valid, remaining, err = twoFactorService.VerifyBackupCode(user.ID, req.BackupCode, ipAddress, userAgent)
+			if valid {
+				backupCodesRemaining = &remaining
+			}
+		}
 		if err != nil {
 			utils.Logger.Error().Err(err).Msg("2FA verification error")
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -214,18 +254,34 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 				Str("email", req.Email).
 				Str("ip", ipAddress).
 				Msg("Login failed - invalid 2FA code")
+			sessionService.RecordLogin(services.LoginAttempt{
+				UserID:     &user.ID,
+				FailReason: "invalid two-factor code",
+				IPAddress:  ipAddress,
+				UserAgent:  userAgent,
+				Location:   h.clientLocation(c),
+			})
 			return middleware.ValidationError(c, "Invalid two-factor authentication code", nil)
 		}
 	}
 
 	// Create session
-	session, err := sessionService.CreateSession(user.ID, ipAddress, userAgent)
+	location := h.clientLocation(c)
+	tokens, err := sessionService.CreateSession(user.ID, ipAddress, userAgent, location)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to create session")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create session",
 		})
 	}
+	sessionService.RecordLogin(services.LoginAttempt{
+		UserID:    &user.ID,
+		SessionID: &tokens.Session.ID,
+		Success:   true,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Location:  location,
+	})
 
 	// Update last login time
 	if err := h.userService.UpdateLastLogin(user.ID); err != nil {
@@ -239,9 +295,14 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		Msg("User logged in successfully")
 
 	return c.JSON(LoginResponse{
-		Message: "Login successful",
-		User:    user.ToPublic(),
-		Token:   session.Token,
+		Message:              "Login successful",
+		User:                 user.ToPublic(),
+		Token:                tokens.AccessToken,
+		ExpiresAt:            &tokens.AccessExpiresAt,
+		RefreshToken:         tokens.RefreshToken,
+		RefreshExpiresAt:     &tokens.RefreshExpiresAt,
+		SessionID:            tokens.Session.ID.String(),
+		BackupCodesRemaining: backupCodesRemaining,
 	})
 }
 
@@ -273,6 +334,19 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 
 	token := parts[1]
 
+	// Ending an impersonation is audited under the administrator
+	if session, ok := sessionValue.(*models.Session); ok && session.IsImpersonation() {
+		if err := sessionService.EndImpersonation(session, c.IP(), c.Get("User-Agent")); err != nil {
+			utils.Logger.Error().Err(err).Msg("Failed to end impersonation")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to logout",
+			})
+		}
+		return c.JSON(LogoutResponse{
+			Message: "Impersonation ended",
+		})
+	}
+
 	// Revoke session
 	if err := sessionService.RevokeSession(token); err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to revoke session")
@@ -297,6 +371,86 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	})
 }
 
+// RefreshRequest represents a token refresh request
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshResponse represents a token refresh response
+type RefreshResponse struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	SessionID        string    `json:"session_id"`
+}
+
+// Refresh exchanges a refresh token for a new access token and the next refresh token.
+// Each refresh token can be redeemed once; replaying one revokes its session.
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req RefreshRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	if req.RefreshToken == "" {
+		return middleware.ValidationError(c, "Refresh token is required", map[string]interface{}{
+			"refresh_token": "required",
+		})
+	}
+
+	sessionService := services.NewSessionService()
+	tokens, err := sessionService.Refresh(req.RefreshToken, c.IP(), c.Get("User-Agent"), h.clientLocation(c))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrRefreshTokenReused) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to refresh session")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to refresh session",
+		})
+	}
+
+	return c.JSON(RefreshResponse{
+		Token:            tokens.AccessToken,
+		ExpiresAt:        tokens.AccessExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+		SessionID:        tokens.Session.ID.String(),
+	})
+}
+
+// geoHeaders are the (country, city) headers set by common edge proxies and CDNs
+var geoHeaders = [][2]string{
+	{"CF-IPCountry", "CF-IPCity"},
+	{"CloudFront-Viewer-Country", "CloudFront-Viewer-City"},
+	{"X-Geo-Country", "X-Geo-City"},
+}
+
+// clientLocation describes where the request comes from for the sessions list. Private and
+// loopback addresses are recognised directly; public addresses are located from the edge
+// proxy's geo headers when GEO_HEADERS_TRUSTED is set, since clients can forge them otherwise.
+func (h *AuthHandler) clientLocation(c *fiber.Ctx) string {
+	if location := auth.LocateIP(c.IP()); location != "" {
+		return location
+	}
+	if !h.geoHeadersTrusted {
+		return ""
+	}
+	for _, headers := range geoHeaders {
+		country := strings.TrimSpace(c.Get(headers[0]))
+		if country == "" || country == "XX" || country == "T1" {
+			continue
+		}
+		if city := strings.TrimSpace(c.Get(headers[1])); city != "" {
+			return city + ", " + country
+		}
+		return country
+	}
+	return ""
+}
+
 // ForgotPasswordRequest represents a forgot password request
 type ForgotPasswordRequest struct {
 	Email string `json:"email"`
@@ -337,7 +491,9 @@ func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
 
 	// Send reset email only if user exists
 	if user != nil && token != nil {
-		if err := h.emailService.SendPasswordResetEmail(user.Email, user.Name, token.Token); err != nil {
+		expiresAt := services.NewUserPreferenceService(database.GetDB()).FormatTime(user.ID, token.ExpiresAt)
+		lang := middleware.LanguageFor(c, user.ID)
+		if err := h.emailService.SendPasswordResetEmail(user.Email, user.Name, lang, token.Token, expiresAt); err != nil {
 			utils.Logger.Error().Err(err).Str("email", user.Email).Msg("Failed to send password reset email")
 			// Don't fail the request if email fails
 		}
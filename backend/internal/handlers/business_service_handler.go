@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// BusinessServiceHandler handles business service (application) management
+type BusinessServiceHandler struct {
+	service *services.BusinessServiceService
+}
+
+// NewBusinessServiceHandler creates a new business service handler
+func NewBusinessServiceHandler() *BusinessServiceHandler {
+	return &BusinessServiceHandler{
+		service: services.NewBusinessServiceService(database.GetDB()),
+	}
+}
+
+// BusinessServiceAssetsRequest is the payload for linking assets to a business service
+type BusinessServiceAssetsRequest struct {
+	AssetIDs []uuid.UUID `json:"asset_ids"`
+}
+
+// businessServiceErrorResponse maps business service errors to HTTP responses
+func businessServiceErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case msg == "business service not found":
+		return middleware.NotFoundError(c, "Business service")
+	case msg == "asset not found":
+		return middleware.NotFoundError(c, "Asset")
+	case msg == "owner not found":
+		return middleware.NotFoundError(c, "User")
+	case msg == "team not found":
+		return middleware.NotFoundError(c, "Team")
+	case strings.Contains(msg, "already exists"):
+		return middleware.ConflictError(c, msg)
+	case strings.Contains(msg, "required"), strings.HasPrefix(msg, "invalid"), strings.Contains(msg, "not linked"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// ListBusinessServices lists the business services of the caller's organization
+// @Summary List business services
+// @Tags Business Services
+// @Produce json
+// @Param search query string false "Filter by name"
+// @Param tier query string false "Filter by tier"
+// @Success 200 {array} models.BusinessService
+// @Router /api/v1/business-services [get]
+// @Security BearerAuth
+func (h *BusinessServiceHandler) ListBusinessServices(c *fiber.Ctx) error {
+	businessServices, err := h.service.WithContext(c.UserContext()).List(c.Query("search"), c.Query("tier"))
+	if err != nil {
+		return businessServiceErrorResponse(c, err, "Failed to list business services")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": businessServices,
+	})
+}
+
+// GetBusinessService returns a business service with its owners and asset count
+// @Summary Get business service
+// @Tags Business Services
+// @Produce json
+// @Param id path string true "Business service ID"
+// @Success 200 {object} models.BusinessService
+// @Router /api/v1/business-services/{id} [get]
+// @Security BearerAuth
+func (h *BusinessServiceHandler) GetBusinessService(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid business service ID", nil)
+	}
+
+	bs, err := h.service.WithContext(c.UserContext()).GetByID(id)
+	if err != nil {
+		return businessServiceErrorResponse(c, err, "Failed to get business service")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": bs,
+	})
+}
+
+// CreateBusinessService creates a business service and links its initial assets
+// @Summary Create business service
+// @Tags Business Services
+// @Accept json
+// @Produce json
+// @Param request body services.BusinessServiceRequest true "Business service"
+// @Success 201 {object} models.BusinessService
+// @Router /api/v1/business-services [post]
+// @Security BearerAuth
+func (h *BusinessServiceHandler) CreateBusinessService(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.BusinessServiceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	req.Name = sanitizeStringPtr(req.Name)
+	req.Description = sanitizeStringPtr(req.Description)
+
+	bs, err := h.service.WithContext(c.UserContext()).Create(req, userID)
+	if err != nil {
+		return businessServiceErrorResponse(c, err, "Failed to create business service")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Business service created successfully",
+		"data":    bs,
+	})
+}
+
+// UpdateBusinessService updates a business service's name, description, tier or owners
+// @Summary Update business service
+// @Tags Business Services
+// @Accept json
+// @Produce json
+// @Param id path string true "Business service ID"
+// @Param request body services.BusinessServiceRequest true "Business service"
+// @Success 200 {object} models.BusinessService
+// @Router /api/v1/business-services/{id} [put]
+// @Security BearerAuth
+func (h *BusinessServiceHandler) UpdateBusinessService(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid business service ID", nil)
+	}
+
+	var req services.BusinessServiceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	req.Name = sanitizeStringPtr(req.Name)
+	req.Description = sanitizeStringPtr(req.Description)
+
+	bs, err := h.service.WithContext(c.UserContext()).Update(id, req)
+	if err != nil {
+		return businessServiceErrorResponse(c, err, "Failed to update business service")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Business service updated successfully",
+		"data":    bs,
+	})
+}
+
+// DeleteBusinessService deletes a business service and unlinks its assets
+// @Summary Delete business service
+// @Tags Business Services
+// @Param id path string true "Business service ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/business-services/{id} [delete]
+// @Security BearerAuth
+func (h *BusinessServiceHandler) DeleteBusinessService(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid business service ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).Delete(id); err != nil {
+		return businessServiceErrorResponse(c, err, "Failed to delete business service")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Business service deleted successfully",
+	})
+}
+
+// GetBusinessServiceRisk rolls up the open findings on a business service's assets
+// @Summary Get business service risk
+// @Tags Business Services
+// @Produce json
+// @Param id path string true "Business service ID"
+// @Success 200 {object} services.BusinessServiceRisk
+// @Router /api/v1/business-services/{id}/risk [get]
+// @Security BearerAuth
+func (h *BusinessServiceHandler) GetBusinessServiceRisk(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid business service ID", nil)
+	}
+
+	risk, err := h.service.WithContext(c.UserContext()).GetRisk(id)
+	if err != nil {
+		return businessServiceErrorResponse(c, err, "Failed to get business service risk")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": risk,
+	})
+}
+
+// GetBusinessServicesRisk rolls up every business service, riskiest first
+// @Summary Get business service risk rollup
+// @Tags Business Services
+// @Produce json
+// @Param limit query int false "Maximum services (0 for all)"
+// @Success 200 {array} services.BusinessServiceRisk
+// @Router /api/v1/business-services/risk [get]
+// @Security BearerAuth
+func (h *BusinessServiceHandler) GetBusinessServicesRisk(c *fiber.Ctx) error {
+	risks, err := h.service.WithContext(c.UserContext()).RiskRollup(c.QueryInt("limit", 0))
+	if err != nil {
+		return businessServiceErrorResponse(c, err, "Failed to get business service risk")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": risks,
+	})
+}
+
+// ListBusinessServiceAssets lists the assets that run a business service
+// @Summary List business service assets
+// @Tags Business Services
+// @Produce json
+// @Param id path string true "Business service ID"
+// @Param page query int false "Page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {array} models.AffectedSystem
+// @Router /api/v1/business-services/{id}/assets [get]
+// @Security BearerAuth
+func (h *BusinessServiceHandler) ListBusinessServiceAssets(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid business service ID", nil)
+	}
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+
+	assets, total, err := h.service.WithContext(c.UserContext()).ListAssets(id, page, limit)
+	if err != nil {
+		return businessServiceErrorResponse(c, err, "Failed to list business service assets")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": assets,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// AddBusinessServiceAssets links assets to a business service
+// @Summary Add business service assets
+// @Tags Business Services
+// @Accept json
+// @Produce json
+// @Param id path string true "Business service ID"
+// @Param request body BusinessServiceAssetsRequest true "Assets"
+// @Success 200 {object} models.BusinessService
+// @Router /api/v1/business-services/{id}/assets [post]
+// @Security BearerAuth
+func (h *BusinessServiceHandler) AddBusinessServiceAssets(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid business service ID", nil)
+	}
+
+	var req BusinessServiceAssetsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	bs, err := h.service.WithContext(c.UserContext()).AddAssets(id, req.AssetIDs)
+	if err != nil {
+		return businessServiceErrorResponse(c, err, "Failed to add business service assets")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Business service assets added successfully",
+		"data":    bs,
+	})
+}
+
+// RemoveBusinessServiceAsset unlinks an asset from a business service
+// @Summary Remove business service asset
+// @Tags Business Services
+// @Produce json
+// @Param id path string true "Business service ID"
+// @Param assetId path string true "Asset ID"
+// @Success 200 {object} models.BusinessService
+// @Router /api/v1/business-services/{id}/assets/{assetId} [delete]
+// @Security BearerAuth
+func (h *BusinessServiceHandler) RemoveBusinessServiceAsset(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid business service ID", nil)
+	}
+	assetID, err := uuid.Parse(c.Params("assetId"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset ID", nil)
+	}
+
+	bs, err := h.service.WithContext(c.UserContext()).RemoveAsset(id, assetID)
+	if err != nil {
+		return businessServiceErrorResponse(c, err, "Failed to remove business service asset")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Business service asset removed successfully",
+		"data":    bs,
+	})
+}
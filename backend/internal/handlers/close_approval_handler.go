@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// CloseApprovalHandler handles the two-person rule for closing CRITICAL vulnerabilities
+type CloseApprovalHandler struct {
+	service *services.CloseApprovalService
+}
+
+// NewCloseApprovalHandler creates a new close approval handler
+func NewCloseApprovalHandler() *CloseApprovalHandler {
+	return &CloseApprovalHandler{
+		service: services.NewCloseApprovalService(database.GetDB()),
+	}
+}
+
+// CloseApprovalNotesRequest carries the notes of a close request or its review
+type CloseApprovalNotesRequest struct {
+	Notes string `json:"notes"`
+}
+
+// closeApprovalErrorResponse maps close approval service errors to HTTP responses
+func closeApprovalErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		return middleware.NotFoundError(c, strings.TrimSuffix(msg, " not found"))
+	case strings.Contains(msg, "only the requester"), strings.Contains(msg, "cannot review their own"):
+		return middleware.ForbiddenError(c, msg)
+	case strings.Contains(msg, "not pending"), strings.Contains(msg, "already"), strings.Contains(msg, "no longer"):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": msg,
+		})
+	case strings.Contains(msg, "required"), strings.Contains(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// parseCloseApprovalIDs parses the vulnerability and close approval IDs of a route
+func parseCloseApprovalIDs(c *fiber.Ctx) (uuid.UUID, uuid.UUID, bool) {
+	vulnerabilityID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	approvalID, err := uuid.Parse(c.Params("approval_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return vulnerabilityID, approvalID, true
+}
+
+// ListCloseApprovals returns the close requests of a vulnerability, the audit trail of its closure
+// GET /api/v1/vulnerabilities/:id/close-approvals
+func (h *CloseApprovalHandler) ListCloseApprovals(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	approvals, err := h.service.WithContext(c.UserContext()).ListApprovals(id)
+	if err != nil {
+		return closeApprovalErrorResponse(c, err, "Failed to list close approvals")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": approvals,
+	})
+}
+
+// GetCloseApproval returns a close request of a vulnerability
+// GET /api/v1/vulnerabilities/:id/close-approvals/:approval_id
+func (h *CloseApprovalHandler) GetCloseApproval(c *fiber.Ctx) error {
+	vulnerabilityID, approvalID, ok := parseCloseApprovalIDs(c)
+	if !ok {
+		return middleware.ValidationError(c, "Invalid vulnerability or close approval ID", nil)
+	}
+
+	approval, err := h.service.WithContext(c.UserContext()).GetApproval(vulnerabilityID, approvalID)
+	if err != nil {
+		return closeApprovalErrorResponse(c, err, "Failed to get close approval")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": approval,
+	})
+}
+
+// RequestCloseApproval asks a second user to approve closing a CRITICAL vulnerability
+// POST /api/v1/vulnerabilities/:id/close-approvals
+func (h *CloseApprovalHandler) RequestCloseApproval(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	var req CloseApprovalNotesRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return middleware.ValidationError(c, "Invalid request body", nil)
+		}
+	}
+
+	approval, err := h.service.WithContext(c.UserContext()).RequestApproval(id, utils.SanitizeString(req.Notes), userID)
+	if err != nil {
+		return closeApprovalErrorResponse(c, err, "Failed to request close approval")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Close request submitted for approval",
+		"data":    approval,
+	})
+}
+
+// ApproveCloseApproval approves a pending close request, closing the vulnerability. The
+// approver must be a different user than the requester.
+// POST /api/v1/vulnerabilities/:id/close-approvals/:approval_id/approve
+func (h *CloseApprovalHandler) ApproveCloseApproval(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	vulnerabilityID, approvalID, ok := parseCloseApprovalIDs(c)
+	if !ok {
+		return middleware.ValidationError(c, "Invalid vulnerability or close approval ID", nil)
+	}
+
+	var req CloseApprovalNotesRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return middleware.ValidationError(c, "Invalid request body", nil)
+		}
+	}
+
+	approval, err := h.service.WithContext(c.UserContext()).ApproveRequest(vulnerabilityID, approvalID, userID, utils.SanitizeString(req.Notes))
+	if err != nil {
+		return closeApprovalErrorResponse(c, err, "Failed to approve close request")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Close request approved and vulnerability closed",
+		"data":    approval,
+	})
+}
+
+// RejectCloseApproval rejects a pending close request; review notes are required
+// POST /api/v1/vulnerabilities/:id/close-approvals/:approval_id/reject
+func (h *CloseApprovalHandler) RejectCloseApproval(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	vulnerabilityID, approvalID, ok := parseCloseApprovalIDs(c)
+	if !ok {
+		return middleware.ValidationError(c, "Invalid vulnerability or close approval ID", nil)
+	}
+
+	var req CloseApprovalNotesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	approval, err := h.service.WithContext(c.UserContext()).RejectRequest(vulnerabilityID, approvalID, userID, utils.SanitizeString(req.Notes))
+	if err != nil {
+		return closeApprovalErrorResponse(c, err, "Failed to reject close request")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Close request rejected",
+		"data":    approval,
+	})
+}
+
+// CancelCloseApproval withdraws a pending close request (requester only)
+// POST /api/v1/vulnerabilities/:id/close-approvals/:approval_id/cancel
+func (h *CloseApprovalHandler) CancelCloseApproval(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	vulnerabilityID, approvalID, ok := parseCloseApprovalIDs(c)
+	if !ok {
+		return middleware.ValidationError(c, "Invalid vulnerability or close approval ID", nil)
+	}
+
+	approval, err := h.service.WithContext(c.UserContext()).CancelRequest(vulnerabilityID, approvalID, userID)
+	if err != nil {
+		return closeApprovalErrorResponse(c, err, "Failed to cancel close approval")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Close request cancelled",
+		"data":    approval,
+	})
+}
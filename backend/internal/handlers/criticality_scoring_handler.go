@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// CriticalityScoringHandler handles asset criticality scoring requests
+type CriticalityScoringHandler struct {
+	service *services.CriticalityScoringService
+}
+
+// NewCriticalityScoringHandler creates a new criticality scoring handler
+func NewCriticalityScoringHandler() *CriticalityScoringHandler {
+	return &CriticalityScoringHandler{
+		service: services.NewCriticalityScoringService(database.GetDB()),
+	}
+}
+
+// criticalityErrorResponse maps criticality scoring service errors to HTTP responses
+func criticalityErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case msg == "asset not found":
+		return middleware.NotFoundError(c, "Asset")
+	case strings.Contains(msg, "required"), strings.HasPrefix(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// GetAssetScore explains an asset's criticality score under the organization's scoring profile
+// @Summary Get asset criticality score
+// @Tags Assets
+// @Produce json
+// @Param id path string true "Asset ID"
+// @Success 200 {object} services.AssetCriticalityScore
+// @Router /api/v1/assets/{id}/score [get]
+// @Security BearerAuth
+func (h *CriticalityScoringHandler) GetAssetScore(c *fiber.Ctx) error {
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset ID", nil)
+	}
+
+	score, err := h.service.WithContext(c.UserContext()).ScoreAsset(assetID)
+	if err != nil {
+		return criticalityErrorResponse(c, err, "Failed to score asset")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": score,
+	})
+}
+
+// GetProfile returns the organization's criticality scoring profile
+// @Summary Get criticality scoring profile
+// @Tags Assets
+// @Produce json
+// @Success 200 {object} models.CriticalityScoringProfile
+// @Router /api/v1/asset-scoring/profile [get]
+// @Security BearerAuth
+func (h *CriticalityScoringHandler) GetProfile(c *fiber.Ctx) error {
+	profile, err := h.service.WithContext(c.UserContext()).GetProfile()
+	if err != nil {
+		return criticalityErrorResponse(c, err, "Failed to get scoring profile")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": profile,
+	})
+}
+
+// UpdateProfile updates the organization's criticality scoring profile. Assets without a
+// criticality override are rescored in the background.
+// @Summary Update criticality scoring profile
+// @Tags Assets
+// @Accept json
+// @Produce json
+// @Param request body services.CriticalityProfileRequest true "Scoring profile"
+// @Success 200 {object} models.CriticalityScoringProfile
+// @Router /api/v1/asset-scoring/profile [put]
+// @Security BearerAuth
+func (h *CriticalityScoringHandler) UpdateProfile(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.CriticalityProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	profile, err := h.service.WithContext(c.UserContext()).UpdateProfile(req, userID)
+	if err != nil {
+		return criticalityErrorResponse(c, err, "Failed to update scoring profile")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": profile,
+	})
+}
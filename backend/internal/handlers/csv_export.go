@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// utf8BOM is the byte order mark Excel uses to detect UTF-8 CSV files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// CSVExportOptions controls how CSV exports are written
+type CSVExportOptions struct {
+	Delimiter  rune
+	Encoding   models.CSVEncoding
+	IncludeBOM bool
+}
+
+// resolveCSVExportOptions builds export options from the user's saved preferences,
+// overridden by the delimiter, encoding, and bom query parameters when present
+func resolveCSVExportOptions(c *fiber.Ctx) (*CSVExportOptions, error) {
+	opts := &CSVExportOptions{
+		Delimiter: ',',
+		Encoding:  models.CSVEncodingUTF8,
+	}
+
+	// Start from saved preferences when the request is authenticated
+	if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		prefService := services.NewUserPreferenceService(database.GetDB())
+		pref, err := prefService.GetPreferences(userID)
+		if err != nil {
+			utils.Logger.Warn().Err(err).Msg("Failed to load CSV export preferences, using defaults")
+		} else {
+			opts.Delimiter = pref.Delimiter()
+			opts.Encoding = pref.CSVEncoding
+			opts.IncludeBOM = pref.CSVIncludeBOM
+		}
+	}
+
+	if delimiterParam := c.Query("delimiter"); delimiterParam != "" {
+		delimiter, err := services.NormalizeCSVDelimiter(delimiterParam)
+		if err != nil {
+			return nil, err
+		}
+		opts.Delimiter = []rune(delimiter)[0]
+	}
+
+	if encodingParam := c.Query("encoding"); encodingParam != "" {
+		enc, err := services.NormalizeCSVEncoding(encodingParam)
+		if err != nil {
+			return nil, err
+		}
+		opts.Encoding = enc
+	}
+
+	if bomParam := c.Query("bom"); bomParam != "" {
+		opts.IncludeBOM = c.QueryBool("bom")
+	}
+
+	return opts, nil
+}
+
+// newCSVExportWriter sets the CSV response headers and returns a writer honoring the export options.
+// The returned flush function must be called once all rows are written; it signs the export.
+func newCSVExportWriter(c *fiber.Ctx, filename string, opts *CSVExportOptions) (*csv.Writer, func()) {
+	c.Set("Content-Type", "text/csv; charset="+string(opts.Encoding))
+	c.Set("Content-Disposition", "attachment; filename="+filename)
+
+	var out io.Writer = c
+	var closer io.Closer
+	if opts.Encoding == models.CSVEncodingUTF8 {
+		// A BOM only makes sense for Unicode output
+		if opts.IncludeBOM {
+			c.Write(utf8BOM)
+		}
+	} else {
+		// Characters that the target charset cannot represent are replaced
+		encoded := transform.NewWriter(c, encoding.ReplaceUnsupported(csvCharmap(opts.Encoding).NewEncoder()))
+		out = encoded
+		closer = encoded
+	}
+
+	writer := csv.NewWriter(out)
+	writer.Comma = opts.Delimiter
+
+	return writer, func() {
+		writer.Flush()
+		if closer != nil {
+			closer.Close()
+		}
+		if signature := services.SignReportArtifact(c.Response().Body()); signature != nil {
+			c.Set(services.ReportSignatureHeader, services.EncodeReportSignature(signature))
+		}
+	}
+}
+
+// csvCharmap returns the single-byte charmap for a non-UTF-8 export encoding
+func csvCharmap(enc models.CSVEncoding) *charmap.Charmap {
+	switch enc {
+	case models.CSVEncodingISO885915:
+		return charmap.ISO8859_15
+	case models.CSVEncodingWindows1252:
+		return charmap.Windows1252
+	default:
+		return charmap.ISO8859_1
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// CustomDashboardHandler handles user-composed dashboards and their widget data
+type CustomDashboardHandler struct {
+	service *services.CustomDashboardService
+}
+
+// NewCustomDashboardHandler creates a new custom dashboard handler
+func NewCustomDashboardHandler() *CustomDashboardHandler {
+	return &CustomDashboardHandler{
+		service: services.NewCustomDashboardService(database.GetDB()),
+	}
+}
+
+// dashboardErrorResponse maps custom dashboard service errors to HTTP responses
+func dashboardErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "dashboard not found"):
+		return middleware.NotFoundError(c, "Dashboard")
+	case strings.Contains(msg, "only the owner"):
+		return middleware.ForbiddenError(c, msg)
+	case strings.Contains(msg, "required"), strings.HasPrefix(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// ListDashboards lists the caller's own and shared dashboards
+// GET /api/v1/dashboards
+func (h *CustomDashboardHandler) ListDashboards(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	dashboards, err := h.service.WithContext(c.UserContext()).ListDashboards(userID)
+	if err != nil {
+		return dashboardErrorResponse(c, err, "Failed to list dashboards")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": dashboards,
+	})
+}
+
+// GetDashboard returns a dashboard definition
+// GET /api/v1/dashboards/:id
+func (h *CustomDashboardHandler) GetDashboard(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid dashboard ID", nil)
+	}
+
+	dashboard, err := h.service.WithContext(c.UserContext()).GetDashboard(id, userID)
+	if err != nil {
+		return dashboardErrorResponse(c, err, "Failed to get dashboard")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": dashboard,
+	})
+}
+
+// CreateDashboard creates a dashboard
+// POST /api/v1/dashboards
+func (h *CustomDashboardHandler) CreateDashboard(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.DashboardRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	dashboard, err := h.service.WithContext(c.UserContext()).CreateDashboard(req, userID)
+	if err != nil {
+		return dashboardErrorResponse(c, err, "Failed to create dashboard")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Dashboard created successfully",
+		"data":    dashboard,
+	})
+}
+
+// UpdateDashboard updates a dashboard (owner only)
+// PUT /api/v1/dashboards/:id
+func (h *CustomDashboardHandler) UpdateDashboard(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid dashboard ID", nil)
+	}
+
+	var req services.DashboardRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	dashboard, err := h.service.WithContext(c.UserContext()).UpdateDashboard(id, userID, req)
+	if err != nil {
+		return dashboardErrorResponse(c, err, "Failed to update dashboard")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Dashboard updated successfully",
+		"data":    dashboard,
+	})
+}
+
+// DeleteDashboard deletes a dashboard (owner only)
+// DELETE /api/v1/dashboards/:id
+func (h *CustomDashboardHandler) DeleteDashboard(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid dashboard ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).DeleteDashboard(id, userID); err != nil {
+		return dashboardErrorResponse(c, err, "Failed to delete dashboard")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Dashboard deleted successfully",
+	})
+}
+
+// GetDashboardData returns the datasets of all widgets of a dashboard in one response
+// @Summary Get dashboard widget data
+// @Description Batched, cached datasets for every widget of the dashboard, in widget order
+// @Tags Dashboard
+// @Produce json
+// @Param id path string true "Dashboard ID"
+// @Success 200 {object} services.DashboardData
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/dashboards/{id}/data [get]
+// @Security BearerAuth
+func (h *CustomDashboardHandler) GetDashboardData(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid dashboard ID", nil)
+	}
+
+	data, err := h.service.WithContext(c.UserContext()).GetDashboardData(id, userID)
+	if err != nil {
+		return dashboardErrorResponse(c, err, "Failed to get dashboard data")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": data,
+	})
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/pkg/cwe"
+)
+
+// CWEHandler serves the Common Weakness Enumeration reference vulnerabilities are classified by
+type CWEHandler struct{}
+
+// NewCWEHandler creates a new CWE handler
+func NewCWEHandler() *CWEHandler {
+	return &CWEHandler{}
+}
+
+// ListWeaknesses lists CWE entries, ordered by number
+// @Summary List CWE entries
+// @Description Names and descriptions of the weakness types scanners and advisories commonly report
+// @Tags Vulnerabilities
+// @Produce json
+// @Param search query string false "Only list entries whose ID or name contains this text"
+// @Success 200 {array} cwe.Weakness
+// @Router /api/v1/vulnerabilities/cwe [get]
+// @Security BearerAuth
+func (h *CWEHandler) ListWeaknesses(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"data": cwe.Search(c.Query("search")),
+	})
+}
+
+// GetWeakness returns a CWE entry
+// @Summary Get CWE entry
+// @Tags Vulnerabilities
+// @Produce json
+// @Param id path string true "CWE ID, e.g. CWE-79 or 79"
+// @Success 200 {object} cwe.Weakness
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/vulnerabilities/cwe/{id} [get]
+// @Security BearerAuth
+func (h *CWEHandler) GetWeakness(c *fiber.Ctx) error {
+	if _, ok := cwe.Normalize(c.Params("id")); !ok {
+		return middleware.ValidationError(c, "Invalid CWE ID", nil)
+	}
+	weakness, ok := cwe.Lookup(c.Params("id"))
+	if !ok {
+		return middleware.NotFoundError(c, "CWE entry")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": weakness,
+	})
+}
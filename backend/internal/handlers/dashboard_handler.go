@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// DashboardHandler handles dashboard display endpoints
+type DashboardHandler struct {
+	dashboardService *services.DashboardService
+	metricsService   *services.MetricsSnapshotService
+}
+
+// NewDashboardHandler creates a new dashboard handler
+func NewDashboardHandler(dashboardService *services.DashboardService, metricsService *services.MetricsSnapshotService) *DashboardHandler {
+	return &DashboardHandler{
+		dashboardService: dashboardService,
+		metricsService:   metricsService,
+	}
+}
+
+// GetWallboard returns a compact payload for rotating SOC wallboard displays
+// @Summary Get wallboard data
+// @Description Top counts, newest criticals, and SLA breach ticker, cached for 30 seconds
+// @Tags Dashboard
+// @Produce json
+// @Success 200 {object} services.WallboardData
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/dashboard/wallboard [get]
+// @Security BearerAuth
+func (h *DashboardHandler) GetWallboard(c *fiber.Ctx) error {
+	data, err := h.dashboardService.GetWallboard(c.UserContext())
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to build wallboard data")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get wallboard data",
+		})
+	}
+
+	// Let displays and proxies reuse the snapshot until it is refreshed
+	c.Set("Cache-Control", fmt.Sprintf("private, max-age=%d", data.RefreshIntervalSeconds))
+	c.Set(fiber.HeaderLastModified, data.GeneratedAt.UTC().Format(http.TimeFormat))
+
+	return c.JSON(data)
+}
+
+// GetTrends returns the precomputed daily metrics snapshots for trend charts
+// @Summary Get daily metric trends
+// @Description Daily vulnerability, finding, asset, and MTTR snapshots for the last N days (default 90, max 400)
+// @Tags Dashboard
+// @Produce json
+// @Param days query int false "Number of days"
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/dashboard/trends [get]
+// @Security BearerAuth
+func (h *DashboardHandler) GetTrends(c *fiber.Ctx) error {
+	days := c.QueryInt("days", 90)
+	if days < 1 {
+		days = 90
+	}
+	if days > services.MetricsBackfillDays {
+		days = services.MetricsBackfillDays
+	}
+
+	snapshots, err := h.metricsService.WithContext(c.UserContext()).ListSnapshots(days)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to load metrics snapshots")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get trend data",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": snapshots,
+		"meta": fiber.Map{"days": days},
+	})
+}
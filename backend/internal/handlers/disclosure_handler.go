@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/captcha"
+	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/policy"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// DisclosureHandler handles the public vulnerability disclosure form and its triage queue
+type DisclosureHandler struct {
+	service *services.DisclosureService
+	// verifier checks captcha responses; nil when no captcha secret is configured
+	verifier *captcha.Verifier
+	// allowWithoutCaptcha accepts submissions without a captcha in development
+	allowWithoutCaptcha bool
+}
+
+// NewDisclosureHandler creates a new disclosure handler
+func NewDisclosureHandler(cfg *config.Config) *DisclosureHandler {
+	h := &DisclosureHandler{
+		service:             services.NewDisclosureService(database.GetDB()),
+		allowWithoutCaptcha: cfg.GoEnv == "development",
+	}
+	if cfg.CaptchaSecret != "" {
+		h.verifier = captcha.NewVerifier(cfg.CaptchaVerifyURL, cfg.CaptchaSecret)
+	}
+	return h
+}
+
+// SubmitDisclosureRequest is the body of the public disclosure form
+type SubmitDisclosureRequest struct {
+	Title             string `json:"title"`
+	Description       string `json:"description"`
+	StepsToReproduce  string `json:"steps_to_reproduce"`
+	Impact            string `json:"impact"`
+	AffectedTarget    string `json:"affected_target"`
+	SuggestedSeverity string `json:"suggested_severity"`
+	CVEID             string `json:"cve_id"`
+	ReporterName      string `json:"reporter_name"`
+	ReporterEmail     string `json:"reporter_email"`
+	CaptchaToken      string `json:"captcha_token"`
+}
+
+// AcceptDisclosureBody accepts a disclosure into the vulnerability registry
+type AcceptDisclosureBody struct {
+	Title        string `json:"title"`
+	Severity     string `json:"severity"`
+	AssignedToID string `json:"assigned_to_id"`
+	OwnerTeamID  string `json:"owner_team_id"`
+	Notes        string `json:"notes"`
+}
+
+// DuplicateDisclosureBody closes a disclosure as a duplicate of a tracked vulnerability
+type DuplicateDisclosureBody struct {
+	VulnerabilityID string `json:"vulnerability_id"`
+	Notes           string `json:"notes"`
+}
+
+// RejectDisclosureBody closes a disclosure that is not a vulnerability
+type RejectDisclosureBody struct {
+	Notes string `json:"notes"`
+}
+
+// disclosureErrorResponse maps disclosure service errors to HTTP responses
+func disclosureErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	var fieldErr *utils.FieldError
+	if errors.As(err, &fieldErr) {
+		return middleware.FieldValidationError(c, err)
+	}
+	if errors.Is(err, policy.ErrDenied) {
+		return middleware.ForbiddenError(c, err.Error())
+	}
+
+	msg := err.Error()
+	switch {
+	case msg == "team not found":
+		return middleware.ValidationError(c, "Owner team not found", nil)
+	case strings.Contains(msg, "not found"):
+		return middleware.NotFoundError(c, strings.TrimSuffix(msg, " not found"))
+	case strings.Contains(msg, "already been triaged"):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": msg,
+		})
+	case strings.Contains(msg, "required"), strings.Contains(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// SubmitDisclosure accepts a vulnerability report from an external reporter into the triage
+// queue. The endpoint is unauthenticated, so it requires a solved captcha and only returns the
+// reference of the report.
+// POST /api/v1/disclosures
+func (h *DisclosureHandler) SubmitDisclosure(c *fiber.Ctx) error {
+	var req SubmitDisclosureRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	switch {
+	case h.verifier != nil:
+		ok, err := h.verifier.Verify(c.UserContext(), req.CaptchaToken, c.IP())
+		if err != nil {
+			utils.Logger.Error().Err(err).Msg("Failed to verify disclosure captcha")
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Captcha verification is unavailable, please try again later",
+			})
+		}
+		if !ok {
+			return middleware.ValidationError(c, "Captcha verification failed", nil)
+		}
+	case !h.allowWithoutCaptcha:
+		utils.Logger.Error().Msg("Disclosure submission refused: CAPTCHA_SECRET is not configured")
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Disclosure submissions are not available",
+		})
+	}
+
+	disclosure, err := h.service.Submit(services.DisclosureSubmission{
+		Title:             req.Title,
+		Description:       req.Description,
+		StepsToReproduce:  req.StepsToReproduce,
+		Impact:            req.Impact,
+		AffectedTarget:    req.AffectedTarget,
+		SuggestedSeverity: models.VulnerabilitySeverity(req.SuggestedSeverity),
+		CVEID:             req.CVEID,
+		ReporterName:      req.ReporterName,
+		ReporterEmail:     req.ReporterEmail,
+	}, c.IP(), c.Get("User-Agent"))
+	if err != nil {
+		return disclosureErrorResponse(c, err, "Failed to submit disclosure")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":   "Thank you, your report has been received and will be reviewed",
+		"reference": disclosure.ID,
+	})
+}
+
+// ListDisclosures lists the disclosure triage queue
+// GET /api/v1/disclosures?status=NEW
+func (h *DisclosureHandler) ListDisclosures(c *fiber.Ctx) error {
+	status := models.DisclosureStatus(strings.ToUpper(c.Query("status")))
+	switch status {
+	case "", models.DisclosureNew, models.DisclosureAccepted, models.DisclosureDuplicate, models.DisclosureRejected:
+	default:
+		return middleware.ValidationError(c, "Invalid status, must be one of: NEW, ACCEPTED, DUPLICATE, REJECTED", nil)
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	disclosures, total, err := h.service.WithContext(c.UserContext()).ListDisclosures(status, page, limit)
+	if err != nil {
+		return disclosureErrorResponse(c, err, "Failed to list disclosures")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": disclosures,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetDisclosure returns a disclosure
+// GET /api/v1/disclosures/:id
+func (h *DisclosureHandler) GetDisclosure(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid disclosure ID", nil)
+	}
+
+	disclosure, err := h.service.WithContext(c.UserContext()).GetDisclosure(id)
+	if err != nil {
+		return disclosureErrorResponse(c, err, "Failed to get disclosure")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": disclosure,
+	})
+}
+
+// AcceptDisclosure creates a vulnerability in the registry from a disclosure
+// POST /api/v1/disclosures/:id/accept
+func (h *DisclosureHandler) AcceptDisclosure(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid disclosure ID", nil)
+	}
+
+	var body AcceptDisclosureBody
+	if err := c.BodyParser(&body); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	req := services.AcceptDisclosureRequest{
+		Title:    utils.SanitizeString(body.Title),
+		Severity: models.VulnerabilitySeverity(strings.ToUpper(body.Severity)),
+		Notes:    body.Notes,
+	}
+	if body.AssignedToID != "" {
+		assignedToID, err := uuid.Parse(body.AssignedToID)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid assigned user ID", nil)
+		}
+		req.AssignedToID = &assignedToID
+	}
+	if body.OwnerTeamID != "" {
+		ownerTeamID, err := uuid.Parse(body.OwnerTeamID)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid owner team ID", nil)
+		}
+		req.OwnerTeamID = &ownerTeamID
+	}
+
+	disclosure, err := h.service.WithContext(c.UserContext()).AcceptDisclosure(id, req, userID)
+	if err != nil {
+		return disclosureErrorResponse(c, err, "Failed to accept disclosure")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Disclosure accepted into the vulnerability registry",
+		"data":    disclosure,
+	})
+}
+
+// MarkDuplicate closes a disclosure as a duplicate of an existing vulnerability
+// POST /api/v1/disclosures/:id/duplicate
+func (h *DisclosureHandler) MarkDuplicate(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid disclosure ID", nil)
+	}
+
+	var body DuplicateDisclosureBody
+	if err := c.BodyParser(&body); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	vulnerabilityID, err := uuid.Parse(body.VulnerabilityID)
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	disclosure, err := h.service.WithContext(c.UserContext()).MarkDuplicate(id, vulnerabilityID, body.Notes, userID)
+	if err != nil {
+		return disclosureErrorResponse(c, err, "Failed to mark disclosure as duplicate")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Disclosure marked as duplicate",
+		"data":    disclosure,
+	})
+}
+
+// RejectDisclosure closes a disclosure that is not a vulnerability
+// POST /api/v1/disclosures/:id/reject
+func (h *DisclosureHandler) RejectDisclosure(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid disclosure ID", nil)
+	}
+
+	var body RejectDisclosureBody
+	if err := c.BodyParser(&body); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	disclosure, err := h.service.WithContext(c.UserContext()).RejectDisclosure(id, body.Notes, userID)
+	if err != nil {
+		return disclosureErrorResponse(c, err, "Failed to reject disclosure")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Disclosure rejected",
+		"data":    disclosure,
+	})
+}
@@ -1,37 +1,74 @@
 package handlers
 
+//go:generate go run ../../cmd/openapi-gen
+
 import (
-	"os"
+	"reflect"
+	"sync"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/pkg/openapi"
+	"gopkg.in/yaml.v3"
 )
 
 // DocsHandler handles API documentation requests
 type DocsHandler struct {
-	openAPIPath string
+	version apiVersion
+
+	once sync.Once
+	spec *openapi.Document
 }
 
-// NewDocsHandler creates a new documentation handler
-func NewDocsHandler() *DocsHandler {
-	return &DocsHandler{
-		openAPIPath: "openapi.yaml",
-	}
+// NewDocsHandler creates a documentation handler for one API version
+func NewDocsHandler(version apiVersion) *DocsHandler {
+	return &DocsHandler{version: version}
 }
 
-// ServeOpenAPISpec serves the OpenAPI specification file
+// basePath returns the path the documented API version is served at
+func (h *DocsHandler) basePath() string {
+	return "/api/" + h.version.name
+}
+
+// document returns the OpenAPI document of the version's routes. It is generated from the
+// routes registered on the app, so it is built on first use, once all routes exist.
+func (h *DocsHandler) document(c *fiber.Ctx) *openapi.Document {
+	h.once.Do(func() {
+		h.spec = openapi.Generate(openapi.Routes(c.App()), openapi.Options{
+			Info: openapi.Info{
+				Title:       "CYOPS Vulnerability Management API",
+				Description: "API endpoints for vulnerability tracking and management",
+				Version:     h.version.release,
+			},
+			BasePath:       h.basePath(),
+			Annotations:    openAPIAnnotations,
+			Authenticators: []string{"middleware.AuthMiddleware."},
+			ErrorModel:     reflect.TypeOf(h.version.errorModel),
+			// Handlers annotate the legacy error body of ErrorHandler and its helpers
+			AnnotatedErrorModel: reflect.TypeOf(middleware.ErrorResponse{}),
+		})
+	})
+	return h.spec
+}
+
+// ServeOpenAPISpec serves the OpenAPI specification generated from the registered routes
 func (h *DocsHandler) ServeOpenAPISpec(c *fiber.Ctx) error {
-	// Read OpenAPI spec from file
-	content, err := os.ReadFile(h.openAPIPath)
+	content, err := yaml.Marshal(h.document(c))
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "OpenAPI specification not found",
-		})
+		return middleware.InternalError(c, err)
 	}
 
 	c.Set("Content-Type", "application/yaml")
 	return c.Send(content)
 }
 
+// ServeOpenAPIJSON serves the OpenAPI specification as JSON
+func (h *DocsHandler) ServeOpenAPIJSON(c *fiber.Ctx) error {
+	// The document's shape is fixed by the OpenAPI specification
+	middleware.SkipEnvelope(c)
+	return c.JSON(h.document(c))
+}
+
 // ServeSwaggerUI serves the Swagger UI interface using CDN
 func (h *DocsHandler) ServeSwaggerUI(c *fiber.Ctx) error {
 	html := `<!DOCTYPE html>
@@ -55,7 +92,7 @@ func (h *DocsHandler) ServeSwaggerUI(c *fiber.Ctx) error {
     <script>
         window.onload = function() {
             window.ui = SwaggerUIBundle({
-                url: "/api/docs/openapi.yaml",
+                url: "` + h.basePath() + `/docs/openapi.yaml",
                 dom_id: '#swagger-ui',
                 deepLinking: true,
                 presets: [
@@ -95,7 +132,7 @@ func (h *DocsHandler) ServeRedocUI(c *fiber.Ctx) error {
     </style>
 </head>
 <body>
-    <redoc spec-url='/api/docs/openapi.yaml'></redoc>
+    <redoc spec-url='` + h.basePath() + `/docs/openapi.yaml'></redoc>
     <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
 </body>
 </html>`
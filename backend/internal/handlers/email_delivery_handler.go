@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/mailer"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// EmailDeliveryHandler handles the outgoing email log and provider delivery events
+type EmailDeliveryHandler struct {
+	service *services.EmailDeliveryService
+}
+
+// NewEmailDeliveryHandler creates a new email delivery handler
+func NewEmailDeliveryHandler() *EmailDeliveryHandler {
+	return &EmailDeliveryHandler{
+		service: services.NewEmailDeliveryService(database.GetDB(), nil),
+	}
+}
+
+// ReceiveDeliveryEvents records delivery status events posted by an email provider (sendgrid,
+// mailgun or ses). The webhook secret of the email_delivery setting is passed as the token query
+// parameter or X-Webhook-Token header. SES events arrive as Amazon SNS notifications of the
+// configuration set's event destination.
+// POST /api/v1/email-webhooks/:provider?token=...
+func (h *EmailDeliveryHandler) ReceiveDeliveryEvents(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		token = c.Get("X-Webhook-Token")
+	}
+	ok, err := h.service.CheckWebhookSecret(token)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to check email delivery webhook secret")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process delivery events",
+		})
+	}
+	if !ok {
+		return middleware.UnauthorizedError(c, "Invalid webhook token")
+	}
+
+	provider := strings.ToLower(c.Params("provider"))
+	var events []mailer.Event
+	switch provider {
+	case mailer.ProviderSendGrid:
+		events, err = mailer.ParseSendGridEvents(c.Body())
+	case mailer.ProviderMailgun:
+		events, err = mailer.ParseMailgunEvent(c.Body())
+	case mailer.ProviderSES:
+		var subscribeURL string
+		events, subscribeURL, err = mailer.ParseSESEvent(c.Body())
+		if err == nil && subscribeURL != "" {
+			// Subscriptions are confirmed by an administrator rather than by fetching a URL from the request
+			utils.Logger.Warn().Str("subscribe_url", subscribeURL).Msg("Amazon SNS subscription to the email delivery webhook awaits confirmation")
+			return c.JSON(fiber.Map{
+				"message": "Subscription confirmation received; confirm it with the subscribe URL logged by the server",
+			})
+		}
+	default:
+		return middleware.NotFoundError(c, "Email provider")
+	}
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	recorded, err := h.service.RecordEvents(provider, events)
+	if err != nil {
+		utils.Logger.Error().Err(err).Str("provider", provider).Msg("Failed to record email delivery events")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process delivery events",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"received": len(events),
+		"recorded": recorded,
+	})
+}
+
+// ListDeliveries lists sent emails with their latest delivery status, newest first
+// GET /api/v1/admin/email/deliveries?status=BOUNCED&provider=sendgrid&category=verification&recipient=user@example.com
+func (h *EmailDeliveryHandler) ListDeliveries(c *fiber.Ctx) error {
+	filter := services.EmailDeliveryFilter{
+		Status:    models.EmailDeliveryStatus(strings.ToUpper(c.Query("status"))),
+		Provider:  strings.ToLower(c.Query("provider")),
+		Category:  c.Query("category"),
+		Recipient: strings.TrimSpace(c.Query("recipient")),
+	}
+	switch filter.Status {
+	case "", models.EmailDeliverySent, models.EmailDeliveryFailed, models.EmailDeliveryDelivered, models.EmailDeliveryDeferred,
+		models.EmailDeliveryBounced, models.EmailDeliveryDropped, models.EmailDeliveryComplained:
+	default:
+		return middleware.ValidationError(c, "Invalid status, must be one of: SENT, FAILED, DELIVERED, DEFERRED, BOUNCED, DROPPED, COMPLAINED", nil)
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	deliveries, total, err := h.service.ListDeliveries(filter, page, limit)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to list email deliveries")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list email deliveries",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": deliveries,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetDelivery returns a sent email with the delivery events reported for it
+// GET /api/v1/admin/email/deliveries/:id
+func (h *EmailDeliveryHandler) GetDelivery(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid email delivery ID", nil)
+	}
+
+	delivery, err := h.service.GetDelivery(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return middleware.NotFoundError(c, "Email delivery")
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to get email delivery")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get email delivery",
+		})
+	}
+
+	return c.JSON(delivery)
+}
+
+// ListOutbox lists queued emails; DEAD ones failed every attempt and wait for a retry
+// GET /api/v1/admin/email/outbox?status=DEAD
+func (h *EmailDeliveryHandler) ListOutbox(c *fiber.Ctx) error {
+	status := models.EmailOutboxStatus(strings.ToUpper(c.Query("status")))
+	switch status {
+	case "", models.EmailOutboxPending, models.EmailOutboxSent, models.EmailOutboxDead:
+	default:
+		return middleware.ValidationError(c, "Invalid status, must be one of: PENDING, SENT, DEAD", nil)
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	entries, total, err := h.service.ListOutbox(status, page, limit)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to list queued emails")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list queued emails",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": entries,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// RetryOutboxEntry queues a dead-lettered email again
+// POST /api/v1/admin/email/outbox/:id/retry
+func (h *EmailDeliveryHandler) RetryOutboxEntry(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid queued email ID", nil)
+	}
+
+	entry, err := h.service.RetryOutboxEntry(id)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			return middleware.NotFoundError(c, "Queued email")
+		case strings.HasPrefix(err.Error(), "invalid"):
+			return middleware.ConflictError(c, err.Error())
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to retry queued email")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retry queued email",
+		})
+	}
+
+	return c.JSON(entry)
+}
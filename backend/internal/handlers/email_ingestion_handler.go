@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// EmailIngestionHandler handles vulnerability reports received by email
+type EmailIngestionHandler struct {
+	service *services.EmailIngestionService
+}
+
+// NewEmailIngestionHandler creates a new email ingestion handler
+func NewEmailIngestionHandler() *EmailIngestionHandler {
+	return &EmailIngestionHandler{
+		service: services.NewEmailIngestionService(database.GetDB()),
+	}
+}
+
+// ReceiveInboundEmail accepts an emailed vulnerability report from an inbound mail provider and
+// creates a draft vulnerability. The webhook secret is passed as the token query parameter or
+// X-Webhook-Token header. The message is read from the raw MIME body (message/rfc822), the
+// "email" field of a SendGrid Inbound Parse post with raw delivery enabled, or an Amazon SNS
+// delivery of an SES receipt notification.
+// POST /api/v1/inbound-email?token=...
+func (h *EmailIngestionHandler) ReceiveInboundEmail(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		token = c.Get("X-Webhook-Token")
+	}
+	ok, err := h.service.CheckWebhookSecret(token)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to check inbound email webhook secret")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process inbound email",
+		})
+	}
+	if !ok {
+		return middleware.UnauthorizedError(c, "Invalid webhook token")
+	}
+
+	var raw []byte
+	contentType := strings.ToLower(c.Get(fiber.HeaderContentType))
+	switch {
+	case strings.HasPrefix(contentType, fiber.MIMEMultipartForm), strings.HasPrefix(contentType, fiber.MIMEApplicationForm):
+		raw = []byte(c.FormValue("email"))
+		if len(raw) == 0 {
+			return middleware.ValidationError(c, "Missing raw message in the email field; enable raw delivery of the full MIME message", nil)
+		}
+	case strings.HasPrefix(contentType, fiber.MIMEApplicationJSON), strings.HasPrefix(contentType, fiber.MIMETextPlain) && c.Get("X-Amz-Sns-Message-Type") != "":
+		var subscribeURL string
+		raw, subscribeURL, err = services.ParseSESNotification(c.Body())
+		if err != nil {
+			return middleware.ValidationError(c, err.Error(), nil)
+		}
+		if raw == nil {
+			// Subscriptions are confirmed by an administrator rather than by fetching a URL from the request
+			utils.Logger.Warn().Str("subscribe_url", subscribeURL).Msg("Amazon SNS subscription to the inbound email webhook awaits confirmation")
+			return c.JSON(fiber.Map{
+				"message": "Subscription confirmation received; confirm it with the subscribe URL logged by the server",
+			})
+		}
+	default:
+		raw = c.Body()
+	}
+
+	record, err := h.service.Ingest(raw, models.InboundEmailChannelWebhook)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidInboundEmail):
+			return middleware.ValidationError(c, err.Error(), nil)
+		case errors.Is(err, services.ErrEmailIngestionDisabled):
+			return middleware.UnauthorizedError(c, "Invalid webhook token")
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to ingest emailed vulnerability report")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to process inbound email",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":           record.Status,
+		"vulnerability_id": record.VulnerabilityID,
+	})
+}
+
+// ListInboundEmails lists received report emails and what became of them
+// GET /api/v1/settings/email-ingestion/messages?status=REJECTED
+func (h *EmailIngestionHandler) ListInboundEmails(c *fiber.Ctx) error {
+	status := models.InboundEmailStatus(strings.ToUpper(c.Query("status")))
+	switch status {
+	case "", models.InboundEmailCreated, models.InboundEmailRejected, models.InboundEmailFailed:
+	default:
+		return middleware.ValidationError(c, "Invalid status, must be one of: CREATED, REJECTED, FAILED", nil)
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	emails, total, err := h.service.ListInboundEmails(status, page, limit)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to list inbound emails")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list inbound emails",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": emails,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// PollMailbox polls the configured mailbox now instead of waiting for the next scheduled poll
+// POST /api/v1/settings/email-ingestion/poll
+func (h *EmailIngestionHandler) PollMailbox(c *fiber.Ctx) error {
+	processed, err := h.service.Poll()
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to poll the vulnerability report mailbox")
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": "Failed to poll mailbox: " + err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":   "Mailbox polled",
+		"processed": processed,
+	})
+}
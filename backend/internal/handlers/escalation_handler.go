@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+)
+
+// EscalationHandler handles escalation policy management and vulnerability escalation history
+type EscalationHandler struct {
+	service *services.EscalationService
+}
+
+// NewEscalationHandler creates a new escalation handler
+func NewEscalationHandler() *EscalationHandler {
+	return &EscalationHandler{
+		service: services.NewEscalationService(database.GetDB()),
+	}
+}
+
+// ListPolicies lists escalation policies
+// GET /api/v1/escalation-policies?enabled=true
+func (h *EscalationHandler) ListPolicies(c *fiber.Ctx) error {
+	policies, err := h.service.WithContext(c.UserContext()).ListPolicies(c.QueryBool("enabled", false))
+	if err != nil {
+		return policyErrorResponse(c, err, "Escalation policy", "Failed to list escalation policies")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": policies,
+	})
+}
+
+// GetPolicy returns an escalation policy
+// GET /api/v1/escalation-policies/:id
+func (h *EscalationHandler) GetPolicy(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid escalation policy ID", nil)
+	}
+
+	policy, err := h.service.WithContext(c.UserContext()).GetPolicy(id)
+	if err != nil {
+		return policyErrorResponse(c, err, "Escalation policy", "Failed to get escalation policy")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": policy,
+	})
+}
+
+// CreatePolicy creates an escalation policy applied by the escalation job
+// POST /api/v1/escalation-policies
+func (h *EscalationHandler) CreatePolicy(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.EscalationPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	policy, err := h.service.WithContext(c.UserContext()).CreatePolicy(req, userID)
+	if err != nil {
+		return policyErrorResponse(c, err, "Escalation policy", "Failed to create escalation policy")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Escalation policy created successfully",
+		"data":    policy,
+	})
+}
+
+// UpdatePolicy updates an escalation policy
+// PUT /api/v1/escalation-policies/:id
+func (h *EscalationHandler) UpdatePolicy(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid escalation policy ID", nil)
+	}
+
+	var req services.EscalationPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	policy, err := h.service.WithContext(c.UserContext()).UpdatePolicy(id, req, userID)
+	if err != nil {
+		return policyErrorResponse(c, err, "Escalation policy", "Failed to update escalation policy")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Escalation policy updated successfully",
+		"data":    policy,
+	})
+}
+
+// DeletePolicy deletes an escalation policy, keeping the escalations it made
+// DELETE /api/v1/escalation-policies/:id
+func (h *EscalationHandler) DeletePolicy(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid escalation policy ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).DeletePolicy(id, userID); err != nil {
+		return policyErrorResponse(c, err, "Escalation policy", "Failed to delete escalation policy")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Escalation policy deleted successfully",
+	})
+}
+
+// EvaluatePolicies runs the escalation policies now instead of waiting for the hourly job
+// POST /api/v1/escalation-policies/evaluate
+func (h *EscalationHandler) EvaluatePolicies(c *fiber.Ctx) error {
+	result, err := h.service.Evaluate(time.Now())
+	if err != nil {
+		return policyErrorResponse(c, err, "Escalation policy", "Failed to evaluate escalation policies")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Escalation policies evaluated",
+		"data":    result,
+	})
+}
+
+// ListVulnerabilityEscalations returns the escalation history of a vulnerability
+// GET /api/v1/vulnerabilities/:id/escalations
+func (h *EscalationHandler) ListVulnerabilityEscalations(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	escalations, err := h.service.WithContext(c.UserContext()).ListEscalations(id)
+	if err != nil {
+		return policyErrorResponse(c, err, "Vulnerability", "Failed to list escalations")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": escalations,
+	})
+}
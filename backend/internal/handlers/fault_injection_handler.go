@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/pkg/faultinject"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// FaultInjectionHandler handles chaos testing fault configuration
+type FaultInjectionHandler struct{}
+
+// NewFaultInjectionHandler creates a new fault injection handler
+func NewFaultInjectionHandler() *FaultInjectionHandler {
+	return &FaultInjectionHandler{}
+}
+
+// ListFaults returns the configured faults and available injection points
+// @Summary List injected faults
+// @Description Returns the active faults (chaos builds only)
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/faults [get]
+// @Security BearerAuth
+func (h *FaultInjectionHandler) ListFaults(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"data": fiber.Map{
+			"faults": faultinject.List(),
+			"points": faultinject.Points,
+		},
+	})
+}
+
+// SetFault configures the fault injected at a point
+// @Summary Configure an injected fault
+// @Description Adds delay, error rate, or timeout behavior at an injection point (chaos builds only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param point path string true "Injection point (database, integration, import, report)"
+// @Param fault body faultinject.Fault true "Fault configuration"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/admin/faults/{point} [put]
+// @Security BearerAuth
+func (h *FaultInjectionHandler) SetFault(c *fiber.Ctx) error {
+	point, err := faultinject.ParsePoint(c.Params("point"))
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	var fault faultinject.Fault
+	if err := c.BodyParser(&fault); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	if err := faultinject.Set(point, fault); err != nil {
+		if errors.Is(err, faultinject.ErrDisabled) {
+			return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	userID, _ := c.Locals("user_id").(uuid.UUID)
+	utils.Logger.Warn().
+		Str("user_id", userID.String()).
+		Str("point", string(point)).
+		Msg("Fault injection enabled by admin")
+
+	return c.JSON(fiber.Map{
+		"data":    fault,
+		"message": "Fault configured",
+	})
+}
+
+// ClearFault removes the fault configured at a point
+// @Summary Clear an injected fault
+// @Tags Admin
+// @Produce json
+// @Param point path string true "Injection point"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/admin/faults/{point} [delete]
+// @Security BearerAuth
+func (h *FaultInjectionHandler) ClearFault(c *fiber.Ctx) error {
+	point, err := faultinject.ParsePoint(c.Params("point"))
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	faultinject.Clear(point)
+
+	return c.JSON(fiber.Map{
+		"message": "Fault cleared",
+	})
+}
+
+// ClearAllFaults removes every configured fault
+// @Summary Clear all injected faults
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/faults [delete]
+// @Security BearerAuth
+func (h *FaultInjectionHandler) ClearAllFaults(c *fiber.Ctx) error {
+	faultinject.ClearAll()
+
+	return c.JSON(fiber.Map{
+		"message": "All faults cleared",
+	})
+}
@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/services"
 	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/storage"
+	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
 type FindingAttachmentHandler struct {
@@ -59,13 +63,18 @@ func (h *FindingAttachmentHandler) UploadAttachment(c *fiber.Ctx) error {
 	}
 
 	// Upload and process attachment
-	attachment, err := h.service.UploadAttachment(
+	attachment, err := h.service.WithContext(c.UserContext()).UploadAttachment(
 		findingID,
 		file,
 		attachmentType,
 		description,
 		userID,
 	)
+	if services.IsAttachmentPolicyViolation(err) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to upload attachment: %v", err),
@@ -88,7 +97,7 @@ func (h *FindingAttachmentHandler) GetAttachment(c *fiber.Ctx) error {
 		})
 	}
 
-	attachment, err := h.service.GetAttachment(attachmentID)
+	attachment, err := h.service.WithContext(c.UserContext()).GetAttachment(attachmentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Attachment not found",
@@ -113,7 +122,7 @@ func (h *FindingAttachmentHandler) GetAttachmentFile(c *fiber.Ctx) error {
 	// Check if thumbnail is requested
 	thumbnail := c.Query("thumbnail") == "true"
 
-	attachment, err := h.service.GetAttachment(attachmentID)
+	attachment, err := h.service.WithContext(c.UserContext()).GetAttachment(attachmentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Attachment not found",
@@ -121,7 +130,7 @@ func (h *FindingAttachmentHandler) GetAttachmentFile(c *fiber.Ctx) error {
 	}
 
 	// Get file data
-	fileData, err := h.service.GetAttachmentFile(attachment, thumbnail)
+	fileData, err := h.service.WithContext(c.UserContext()).GetAttachmentFile(attachment, thumbnail)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to read attachment file",
@@ -145,7 +154,7 @@ func (h *FindingAttachmentHandler) DownloadAttachmentFile(c *fiber.Ctx) error {
 		})
 	}
 
-	attachment, err := h.service.GetAttachment(attachmentID)
+	attachment, err := h.service.WithContext(c.UserContext()).GetAttachment(attachmentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Attachment not found",
@@ -153,7 +162,7 @@ func (h *FindingAttachmentHandler) DownloadAttachmentFile(c *fiber.Ctx) error {
 	}
 
 	// Get file data (always full file, never thumbnail)
-	fileData, err := h.service.GetAttachmentFile(attachment, false)
+	fileData, err := h.service.WithContext(c.UserContext()).GetAttachmentFile(attachment, false)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to read attachment file",
@@ -167,6 +176,27 @@ func (h *FindingAttachmentHandler) DownloadAttachmentFile(c *fiber.Ctx) error {
 	return c.Send(fileData)
 }
 
+// GetAttachmentDownloadURL returns a time-limited download URL for the attachment file
+// GET /api/attachments/:id/download-url
+func (h *FindingAttachmentHandler) GetAttachmentDownloadURL(c *fiber.Ctx) error {
+	attachmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid attachment ID",
+		})
+	}
+
+	attachment, err := h.service.WithContext(c.UserContext()).GetAttachment(attachmentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Attachment not found",
+		})
+	}
+
+	url, expiresAt, err := h.service.WithContext(c.UserContext()).GetDownloadURL(attachment)
+	return downloadURLResponse(c, url, expiresAt, err)
+}
+
 // ListFindingAttachments lists all attachments for a finding
 // GET /api/findings/:id/attachments
 func (h *FindingAttachmentHandler) ListFindingAttachments(c *fiber.Ctx) error {
@@ -177,7 +207,7 @@ func (h *FindingAttachmentHandler) ListFindingAttachments(c *fiber.Ctx) error {
 		})
 	}
 
-	attachments, err := h.service.GetFindingAttachments(findingID)
+	attachments, err := h.service.WithContext(c.UserContext()).GetFindingAttachments(findingID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to list attachments",
@@ -199,7 +229,8 @@ func (h *FindingAttachmentHandler) DeleteAttachment(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.service.DeleteAttachment(attachmentID); err != nil {
+	userID := c.Locals("user_id").(uuid.UUID)
+	if err := h.service.WithContext(c.UserContext()).DeleteAttachment(attachmentID, userID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete attachment",
 		})
@@ -210,6 +241,65 @@ func (h *FindingAttachmentHandler) DeleteAttachment(c *fiber.Ctx) error {
 	})
 }
 
+// VerifyAttachmentIntegrity re-hashes the stored file and compares it with the hash recorded at upload
+// POST /api/attachments/:id/verify
+func (h *FindingAttachmentHandler) VerifyAttachmentIntegrity(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	attachmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid attachment ID",
+		})
+	}
+
+	attachment, err := h.service.WithContext(c.UserContext()).GetAttachment(attachmentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Attachment not found",
+		})
+	}
+
+	result, err := h.service.WithContext(c.UserContext()).VerifyIntegrity(attachment, userID)
+	if err != nil {
+		utils.Logger.Error().Err(err).Str("attachment_id", attachmentID.String()).Msg("Failed to verify attachment integrity")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to verify attachment integrity",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": result,
+	})
+}
+
+// GetAttachmentCustody returns the attachment's chain of custody (also for deleted attachments)
+// GET /api/attachments/:id/custody
+func (h *FindingAttachmentHandler) GetAttachmentCustody(c *fiber.Ctx) error {
+	attachmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid attachment ID",
+		})
+	}
+
+	events, err := h.service.WithContext(c.UserContext()).GetCustodyChain(attachmentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to load chain of custody",
+		})
+	}
+	if len(events) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Attachment not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": events,
+	})
+}
+
 // GetAttachmentStats returns statistics about attachments
 // GET /api/findings/:id/attachments/stats
 func (h *FindingAttachmentHandler) GetAttachmentStats(c *fiber.Ctx) error {
@@ -226,7 +316,7 @@ func (h *FindingAttachmentHandler) GetAttachmentStats(c *fiber.Ctx) error {
 		findingID = &id
 	}
 
-	stats, err := h.service.GetAttachmentStats(findingID)
+	stats, err := h.service.WithContext(c.UserContext()).GetAttachmentStats(findingID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get attachment statistics",
@@ -237,3 +327,34 @@ func (h *FindingAttachmentHandler) GetAttachmentStats(c *fiber.Ctx) error {
 		"data": stats,
 	})
 }
+
+// GetAttachmentPolicy returns the allowed MIME types and maximum sizes per attachment category
+// GET /api/attachments/policy
+func (h *FindingAttachmentHandler) GetAttachmentPolicy(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"data": services.LoadAttachmentPolicy(database.GetDB().WithContext(c.UserContext())),
+	})
+}
+
+// downloadURLResponse renders a presigned download URL, or a 400 when the file's backend
+// cannot issue one (local disk) so clients fall back to the download endpoint
+func downloadURLResponse(c *fiber.Ctx, url string, expiresAt time.Time, err error) error {
+	if errors.Is(err, storage.ErrPresignUnsupported) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Download URLs are not supported by the local storage backend",
+		})
+	}
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to create download URL")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create download URL",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": fiber.Map{
+			"url":        url,
+			"expires_at": expiresAt,
+		},
+	})
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// FindingCommentHandler handles finding comment requests
+type FindingCommentHandler struct {
+	service *services.FindingCommentService
+}
+
+// NewFindingCommentHandler creates a new finding comment handler
+func NewFindingCommentHandler() *FindingCommentHandler {
+	return &FindingCommentHandler{
+		service: services.NewFindingCommentService(database.GetDB()),
+	}
+}
+
+// ListComments lists comments on a finding
+// GET /api/v1/vulnerabilities/findings/:id/comments
+func (h *FindingCommentHandler) ListComments(c *fiber.Ctx) error {
+	findingID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid finding ID", nil)
+	}
+
+	comments, err := h.service.WithContext(c.UserContext()).ListComments(findingID)
+	if err != nil {
+		return commentErrorResponse(c, err, "Failed to list comments")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": comments,
+	})
+}
+
+// CreateComment adds a comment to a finding; @mentions notify the mentioned users
+// POST /api/v1/vulnerabilities/findings/:id/comments
+func (h *FindingCommentHandler) CreateComment(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	findingID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid finding ID", nil)
+	}
+
+	var req CommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	comment, err := h.service.WithContext(c.UserContext()).CreateComment(findingID, userID, utils.SanitizeString(req.Body))
+	if err != nil {
+		return commentErrorResponse(c, err, "Failed to create comment")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Comment added successfully",
+		"data":    comment,
+	})
+}
+
+// UpdateComment edits a comment on a finding
+// PUT /api/v1/vulnerabilities/findings/:id/comments/:comment_id
+func (h *FindingCommentHandler) UpdateComment(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	findingID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid finding ID", nil)
+	}
+
+	commentID, err := uuid.Parse(c.Params("comment_id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid comment ID", nil)
+	}
+
+	var req CommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	comment, err := h.service.WithContext(c.UserContext()).UpdateComment(findingID, commentID, userID, utils.SanitizeString(req.Body))
+	if err != nil {
+		return commentErrorResponse(c, err, "Failed to update comment")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Comment updated successfully",
+		"data":    comment,
+	})
+}
+
+// DeleteComment deletes a comment on a finding
+// DELETE /api/v1/vulnerabilities/findings/:id/comments/:comment_id
+func (h *FindingCommentHandler) DeleteComment(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	findingID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid finding ID", nil)
+	}
+
+	commentID, err := uuid.Parse(c.Params("comment_id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid comment ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).DeleteComment(findingID, commentID, userID); err != nil {
+		return commentErrorResponse(c, err, "Failed to delete comment")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Comment deleted successfully",
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/graphql-go/graphql"
+)
+
+// GraphQLHandler serves the read-only GraphQL API
+type GraphQLHandler struct {
+	schema graphql.Schema
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+func NewGraphQLHandler() *GraphQLHandler {
+	schema, err := NewGraphQLSchema()
+	if err != nil {
+		// The schema is static, so this only fails on a programming error
+		panic(fmt.Sprintf("invalid GraphQL schema: %v", err))
+	}
+	return &GraphQLHandler{schema: schema}
+}
+
+// graphQLRequest is a GraphQL request sent as a JSON body or as query parameters
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Execute runs a GraphQL query. Each field checks the caller's permission (and API key scope)
+// for the resource it returns; fields the caller may not read resolve to null with an error.
+// @Summary Execute a GraphQL query
+// @Description Read-only queries over vulnerabilities, assets, findings and assessments with relationship traversal
+// @Tags GraphQL
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/graphql [post]
+// @Security BearerAuth
+func (h *GraphQLHandler) Execute(c *fiber.Ctx) error {
+	// GraphQL responses keep the {data, errors} shape of the GraphQL specification
+	middleware.SkipEnvelope(c)
+
+	var req graphQLRequest
+	if c.Method() == fiber.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return middleware.ValidationError(c, "Invalid variables", nil)
+			}
+		}
+	} else if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	if req.Query == "" {
+		return middleware.ValidationError(c, "query is required", nil)
+	}
+	// Syntax errors are reported by the executor in the GraphQL error format
+	if depth, err := GraphQLQueryDepth(req.Query); err == nil && depth > GraphQLMaxDepth {
+		return middleware.ValidationError(c, fmt.Sprintf("Query is nested too deeply (maximum depth %d)", GraphQLMaxDepth), nil)
+	}
+
+	principal := &graphQLPrincipal{
+		userID:  c.Locals("user_id").(uuid.UUID),
+		apiKey:  c.Locals("auth_method") == "api_key",
+		granted: make(map[string]error),
+	}
+	principal.scopes, _ = c.Locals("api_key_scopes").([]string)
+
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        withGraphQLPrincipal(c.UserContext(), principal),
+	})
+
+	return c.JSON(result)
+}
@@ -0,0 +1,561 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"gorm.io/gorm"
+)
+
+// GraphQL query limits
+const (
+	GraphQLMaxDepth     = 6   // Maximum nesting of selection sets
+	graphQLDefaultLimit = 50  // Default page size of list fields
+	graphQLMaxLimit     = 100 // Maximum page size of list fields
+)
+
+// graphQLAccess maps each GraphQL resource to the RBAC permission and API key scope its REST
+// read endpoints require
+var graphQLAccess = map[string]struct{ resource, scope string }{
+	"vulnerability": {"vulnerability", "vulnerabilities:read"},
+	"asset":         {"asset", "assets:read"},
+	"finding":       {"finding", "findings:read"},
+	"assessment":    {"assessment", "assessments:read"},
+}
+
+// graphQLPrincipal is the authenticated caller of a GraphQL request. Permission checks are
+// memoized for the request since many fields check the same permission.
+type graphQLPrincipal struct {
+	userID uuid.UUID
+	apiKey bool
+	scopes []string
+
+	mu      sync.Mutex
+	granted map[string]error
+}
+
+type graphQLPrincipalKey struct{}
+
+// withGraphQLPrincipal attaches the caller to the request context
+func withGraphQLPrincipal(ctx context.Context, principal *graphQLPrincipal) context.Context {
+	return context.WithValue(ctx, graphQLPrincipalKey{}, principal)
+}
+
+// authorizeGraphQL checks that the caller may read a resource: the RBAC permission for users,
+// and additionally the API key scope for API key callers
+func authorizeGraphQL(ctx context.Context, name string) error {
+	principal, ok := ctx.Value(graphQLPrincipalKey{}).(*graphQLPrincipal)
+	if !ok {
+		return fmt.Errorf("authentication required")
+	}
+	access := graphQLAccess[name]
+
+	principal.mu.Lock()
+	defer principal.mu.Unlock()
+	if err, checked := principal.granted[name]; checked {
+		return err
+	}
+
+	var err error
+	if principal.apiKey && !models.ScopeGranted(principal.scopes, access.scope) {
+		err = fmt.Errorf("insufficient permissions: API key requires the %s scope", access.scope)
+	} else if allowed, checkErr := services.NewRoleService().CheckPermission(principal.userID, access.resource, "read"); checkErr != nil {
+		// Not memoized so a transient failure does not deny the rest of the request
+		return fmt.Errorf("permission check failed")
+	} else if !allowed {
+		err = fmt.Errorf("you do not have permission to read %s data", access.resource)
+	}
+	principal.granted[name] = err
+	return err
+}
+
+// GraphQLQueryDepth returns the maximum selection set nesting of a query document, following
+// fragment spreads
+func GraphQLQueryDepth(query string) (int, error) {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return 0, err
+	}
+
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	var depth func(set *ast.SelectionSet, visiting map[string]bool) int
+	depth = func(set *ast.SelectionSet, visiting map[string]bool) int {
+		if set == nil {
+			return 0
+		}
+		deepest := 0
+		for _, selection := range set.Selections {
+			var nested int
+			switch node := selection.(type) {
+			case *ast.Field:
+				if node.SelectionSet != nil {
+					nested = 1 + depth(node.SelectionSet, visiting)
+				}
+			case *ast.InlineFragment:
+				nested = depth(node.SelectionSet, visiting)
+			case *ast.FragmentSpread:
+				name := node.Name.Value
+				if fragment, ok := fragments[name]; ok && !visiting[name] {
+					visiting[name] = true
+					nested = depth(fragment.SelectionSet, visiting)
+					delete(visiting, name)
+				}
+			}
+			if nested > deepest {
+				deepest = nested
+			}
+		}
+		return deepest
+	}
+
+	deepest := 0
+	for _, definition := range document.Definitions {
+		if operation, ok := definition.(*ast.OperationDefinition); ok {
+			if d := depth(operation.SelectionSet, map[string]bool{}); d > deepest {
+				deepest = d
+			}
+		}
+	}
+	return deepest, nil
+}
+
+// graphQLDB returns the database handle for a resolver, scoped to the request's tenant
+func graphQLDB(p graphql.ResolveParams) *gorm.DB {
+	return database.GetDB().WithContext(p.Context)
+}
+
+// graphQLPage applies the limit and offset arguments of a list field
+func graphQLPage(query *gorm.DB, args map[string]interface{}) *gorm.DB {
+	limit, _ := args["limit"].(int)
+	if limit < 1 || limit > graphQLMaxLimit {
+		limit = graphQLDefaultLimit
+	}
+	offset, _ := args["offset"].(int)
+	if offset < 0 {
+		offset = 0
+	}
+	return query.Limit(limit).Offset(offset)
+}
+
+// graphQLID parses the id argument of a single-object field
+func graphQLID(args map[string]interface{}) (uuid.UUID, error) {
+	id, err := uuid.Parse(fmt.Sprint(args["id"]))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid id")
+	}
+	return id, nil
+}
+
+// graphQLFirst loads a single record, resolving to null when it does not exist
+func graphQLFirst[T any](query *gorm.DB, id uuid.UUID) (*T, error) {
+	var record T
+	if err := query.First(&record, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load record")
+	}
+	return &record, nil
+}
+
+// graphQLFind loads a list of records
+func graphQLFind[T any](query *gorm.DB) ([]T, error) {
+	var records []T
+	if err := query.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load records")
+	}
+	return records, nil
+}
+
+// modelField defines a scalar field read from a model
+func modelField[T any](fieldType graphql.Output, get func(*T) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			source, ok := p.Source.(*T)
+			if !ok || source == nil {
+				return nil, nil
+			}
+			return get(source), nil
+		},
+	}
+}
+
+// relationField defines a field traversing to related records the caller must be allowed to read
+func relationField[T any](fieldType graphql.Output, resource string, args graphql.FieldConfigArgument, resolve func(p graphql.ResolveParams, source *T) (interface{}, error)) *graphql.Field {
+	return &graphql.Field{
+		Type: fieldType,
+		Args: args,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			source, ok := p.Source.(*T)
+			if !ok || source == nil {
+				return nil, nil
+			}
+			if err := authorizeGraphQL(p.Context, resource); err != nil {
+				return nil, err
+			}
+			return resolve(p, source)
+		},
+	}
+}
+
+// pointers converts loaded records to pointers so nested resolvers receive a single source type
+func pointers[T any](records []T) []*T {
+	result := make([]*T, len(records))
+	for i := range records {
+		result[i] = &records[i]
+	}
+	return result
+}
+
+func optionalString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func optionalUUID(value *uuid.UUID) interface{} {
+	if value == nil {
+		return nil
+	}
+	return value.String()
+}
+
+func optionalTime(value *time.Time) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+// NewGraphQLSchema builds the read-only schema over vulnerabilities, assets, findings and
+// assessments
+func NewGraphQLSchema() (graphql.Schema, error) {
+	pageArgs := func(extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{
+			"limit":  &graphql.ArgumentConfig{Type: graphql.Int, Description: fmt.Sprintf("Page size (default %d, max %d)", graphQLDefaultLimit, graphQLMaxLimit)},
+			"offset": &graphql.ArgumentConfig{Type: graphql.Int},
+		}
+		for name, arg := range extra {
+			args[name] = arg
+		}
+		return args
+	}
+	idArgs := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+	}
+
+	var vulnerabilityType, assetType, findingType, assessmentType *graphql.Object
+
+	vulnerabilityType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Vulnerability",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          modelField(graphql.NewNonNull(graphql.ID), func(v *models.Vulnerability) interface{} { return v.ID.String() }),
+				"title":       modelField(graphql.String, func(v *models.Vulnerability) interface{} { return v.Title }),
+				"description": modelField(graphql.String, func(v *models.Vulnerability) interface{} { return v.Description }),
+				"severity":    modelField(graphql.String, func(v *models.Vulnerability) interface{} { return string(v.Severity) }),
+				"status":      modelField(graphql.String, func(v *models.Vulnerability) interface{} { return string(v.Status) }),
+				"cvssScore": modelField(graphql.Float, func(v *models.Vulnerability) interface{} {
+					if v.CVSSScore == nil {
+						return nil
+					}
+					return *v.CVSSScore
+				}),
+				"cvssVector":       modelField(graphql.String, func(v *models.Vulnerability) interface{} { return optionalString(v.CVSSVector) }),
+				"cveId":            modelField(graphql.String, func(v *models.Vulnerability) interface{} { return optionalString(v.CVEID) }),
+				"source":           modelField(graphql.String, func(v *models.Vulnerability) interface{} { return v.Source }),
+				"discoveryDate":    modelField(graphql.DateTime, func(v *models.Vulnerability) interface{} { return v.DiscoveryDate }),
+				"remediationNotes": modelField(graphql.String, func(v *models.Vulnerability) interface{} { return optionalString(v.RemediationNotes) }),
+				"assignedToId":     modelField(graphql.ID, func(v *models.Vulnerability) interface{} { return optionalUUID(v.AssignedToID) }),
+				"ownerTeamId":      modelField(graphql.ID, func(v *models.Vulnerability) interface{} { return optionalUUID(v.OwnerTeamID) }),
+				"createdAt":        modelField(graphql.DateTime, func(v *models.Vulnerability) interface{} { return v.CreatedAt }),
+				"updatedAt":        modelField(graphql.DateTime, func(v *models.Vulnerability) interface{} { return v.UpdatedAt }),
+				"assets": relationField(graphql.NewList(assetType), "asset", pageArgs(nil), func(p graphql.ResolveParams, v *models.Vulnerability) (interface{}, error) {
+					records, err := graphQLFind[models.AffectedSystem](graphQLPage(graphQLDB(p).
+						Joins("JOIN vulnerability_affected_systems ON vulnerability_affected_systems.affected_system_id = affected_systems.id").
+						Where("vulnerability_affected_systems.vulnerability_id = ?", v.ID).
+						Order("affected_systems.hostname ASC"), p.Args))
+					return pointers(records), err
+				}),
+				"findings": relationField(graphql.NewList(findingType), "finding", pageArgs(nil), func(p graphql.ResolveParams, v *models.Vulnerability) (interface{}, error) {
+					records, err := graphQLFind[models.VulnerabilityFinding](graphQLPage(graphQLDB(p).
+						Where("vulnerability_id = ?", v.ID).
+						Order("first_detected DESC"), p.Args))
+					return pointers(records), err
+				}),
+				"assessments": relationField(graphql.NewList(assessmentType), "assessment", pageArgs(nil), func(p graphql.ResolveParams, v *models.Vulnerability) (interface{}, error) {
+					records, err := graphQLFind[models.Assessment](graphQLPage(graphQLDB(p).
+						Joins("JOIN assessment_vulnerabilities ON assessment_vulnerabilities.assessment_id = assessments.id").
+						Where("assessment_vulnerabilities.vulnerability_id = ?", v.ID).
+						Order("assessments.start_date DESC"), p.Args))
+					return pointers(records), err
+				}),
+			}
+		}),
+	})
+
+	assetType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Asset",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          modelField(graphql.NewNonNull(graphql.ID), func(a *models.AffectedSystem) interface{} { return a.ID.String() }),
+				"hostname":    modelField(graphql.String, func(a *models.AffectedSystem) interface{} { return optionalString(a.Hostname) }),
+				"ipAddress":   modelField(graphql.String, func(a *models.AffectedSystem) interface{} { return optionalString(a.IPAddress) }),
+				"assetId":     modelField(graphql.String, func(a *models.AffectedSystem) interface{} { return optionalString(a.AssetID) }),
+				"systemType":  modelField(graphql.String, func(a *models.AffectedSystem) interface{} { return string(a.SystemType) }),
+				"description": modelField(graphql.String, func(a *models.AffectedSystem) interface{} { return optionalString(a.Description) }),
+				"environment": modelField(graphql.String, func(a *models.AffectedSystem) interface{} { return string(a.Environment) }),
+				"criticality": modelField(graphql.String, func(a *models.AffectedSystem) interface{} {
+					if a.Criticality == nil {
+						return nil
+					}
+					return string(*a.Criticality)
+				}),
+				"status":       modelField(graphql.String, func(a *models.AffectedSystem) interface{} { return string(a.Status) }),
+				"ownerId":      modelField(graphql.ID, func(a *models.AffectedSystem) interface{} { return optionalUUID(a.OwnerID) }),
+				"ownerTeamId":  modelField(graphql.ID, func(a *models.AffectedSystem) interface{} { return optionalUUID(a.OwnerTeamID) }),
+				"department":   modelField(graphql.String, func(a *models.AffectedSystem) interface{} { return optionalString(a.Department) }),
+				"location":     modelField(graphql.String, func(a *models.AffectedSystem) interface{} { return optionalString(a.Location) }),
+				"lastScanDate": modelField(graphql.DateTime, func(a *models.AffectedSystem) interface{} { return optionalTime(a.LastScanDate) }),
+				"createdAt":    modelField(graphql.DateTime, func(a *models.AffectedSystem) interface{} { return a.CreatedAt }),
+				"updatedAt":    modelField(graphql.DateTime, func(a *models.AffectedSystem) interface{} { return a.UpdatedAt }),
+				"vulnerabilities": relationField(graphql.NewList(vulnerabilityType), "vulnerability", pageArgs(nil), func(p graphql.ResolveParams, a *models.AffectedSystem) (interface{}, error) {
+					records, err := graphQLFind[models.Vulnerability](graphQLPage(graphQLDB(p).
+						Joins("JOIN vulnerability_affected_systems ON vulnerability_affected_systems.vulnerability_id = vulnerabilities.id").
+						Where("vulnerability_affected_systems.affected_system_id = ?", a.ID).
+						Order("vulnerabilities.discovery_date DESC"), p.Args))
+					return pointers(records), err
+				}),
+				"findings": relationField(graphql.NewList(findingType), "finding", pageArgs(nil), func(p graphql.ResolveParams, a *models.AffectedSystem) (interface{}, error) {
+					records, err := graphQLFind[models.VulnerabilityFinding](graphQLPage(graphQLDB(p).
+						Where("affected_system_id = ?", a.ID).
+						Order("first_detected DESC"), p.Args))
+					return pointers(records), err
+				}),
+			}
+		}),
+	})
+
+	findingType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Finding",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":              modelField(graphql.NewNonNull(graphql.ID), func(f *models.VulnerabilityFinding) interface{} { return f.ID.String() }),
+				"vulnerabilityId": modelField(graphql.ID, func(f *models.VulnerabilityFinding) interface{} { return f.VulnerabilityID.String() }),
+				"assetId":         modelField(graphql.ID, func(f *models.VulnerabilityFinding) interface{} { return f.AffectedSystemID.String() }),
+				"port":            modelField(graphql.String, func(f *models.VulnerabilityFinding) interface{} { return optionalString(f.Port) }),
+				"protocol":        modelField(graphql.String, func(f *models.VulnerabilityFinding) interface{} { return optionalString(f.Protocol) }),
+				"serviceName":     modelField(graphql.String, func(f *models.VulnerabilityFinding) interface{} { return optionalString(f.ServiceName) }),
+				"pluginId":        modelField(graphql.String, func(f *models.VulnerabilityFinding) interface{} { return optionalString(f.PluginID) }),
+				"scannerName":     modelField(graphql.String, func(f *models.VulnerabilityFinding) interface{} { return optionalString(f.ScannerName) }),
+				"status":          modelField(graphql.String, func(f *models.VulnerabilityFinding) interface{} { return string(f.Status) }),
+				"firstDetected":   modelField(graphql.DateTime, func(f *models.VulnerabilityFinding) interface{} { return f.FirstDetected }),
+				"lastSeen":        modelField(graphql.DateTime, func(f *models.VulnerabilityFinding) interface{} { return f.LastSeen }),
+				"fixedAt":         modelField(graphql.DateTime, func(f *models.VulnerabilityFinding) interface{} { return optionalTime(f.FixedAt) }),
+				"verifiedAt":      modelField(graphql.DateTime, func(f *models.VulnerabilityFinding) interface{} { return optionalTime(f.VerifiedAt) }),
+				"vulnerability": relationField(vulnerabilityType, "vulnerability", nil, func(p graphql.ResolveParams, f *models.VulnerabilityFinding) (interface{}, error) {
+					return graphQLFirst[models.Vulnerability](graphQLDB(p), f.VulnerabilityID)
+				}),
+				"asset": relationField(assetType, "asset", nil, func(p graphql.ResolveParams, f *models.VulnerabilityFinding) (interface{}, error) {
+					return graphQLFirst[models.AffectedSystem](graphQLDB(p), f.AffectedSystemID)
+				}),
+			}
+		}),
+	})
+
+	assessmentType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Assessment",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":                   modelField(graphql.NewNonNull(graphql.ID), func(a *models.Assessment) interface{} { return a.ID.String() }),
+				"name":                 modelField(graphql.String, func(a *models.Assessment) interface{} { return a.Name }),
+				"description":          modelField(graphql.String, func(a *models.Assessment) interface{} { return optionalString(a.Description) }),
+				"assessmentType":       modelField(graphql.String, func(a *models.Assessment) interface{} { return string(a.AssessmentType) }),
+				"status":               modelField(graphql.String, func(a *models.Assessment) interface{} { return string(a.Status) }),
+				"assessorName":         modelField(graphql.String, func(a *models.Assessment) interface{} { return a.AssessorName }),
+				"assessorOrganization": modelField(graphql.String, func(a *models.Assessment) interface{} { return optionalString(a.AssessorOrganization) }),
+				"startDate":            modelField(graphql.DateTime, func(a *models.Assessment) interface{} { return a.StartDate }),
+				"endDate":              modelField(graphql.DateTime, func(a *models.Assessment) interface{} { return optionalTime(a.EndDate) }),
+				"executiveSummary":     modelField(graphql.String, func(a *models.Assessment) interface{} { return optionalString(a.ExecutiveSummary) }),
+				"score": modelField(graphql.Int, func(a *models.Assessment) interface{} {
+					if a.Score == nil {
+						return nil
+					}
+					return *a.Score
+				}),
+				"createdAt": modelField(graphql.DateTime, func(a *models.Assessment) interface{} { return a.CreatedAt }),
+				"updatedAt": modelField(graphql.DateTime, func(a *models.Assessment) interface{} { return a.UpdatedAt }),
+				"vulnerabilities": relationField(graphql.NewList(vulnerabilityType), "vulnerability", pageArgs(nil), func(p graphql.ResolveParams, a *models.Assessment) (interface{}, error) {
+					records, err := graphQLFind[models.Vulnerability](graphQLPage(graphQLDB(p).
+						Joins("JOIN assessment_vulnerabilities ON assessment_vulnerabilities.vulnerability_id = vulnerabilities.id").
+						Where("assessment_vulnerabilities.assessment_id = ?", a.ID).
+						Order("vulnerabilities.discovery_date DESC"), p.Args))
+					return pointers(records), err
+				}),
+				"assets": relationField(graphql.NewList(assetType), "asset", pageArgs(nil), func(p graphql.ResolveParams, a *models.Assessment) (interface{}, error) {
+					// The assessment's scope: directly linked assets plus members of linked asset groups
+					db := graphQLDB(p)
+					direct := db.Model(&models.AssessmentAsset{}).Select("asset_id").Where("assessment_id = ?", a.ID.String())
+					grouped := db.Model(&models.AssetGroupMember{}).Select("asset_id").
+						Where("group_id IN (?)", db.Model(&models.AssessmentAssetGroup{}).Select("asset_group_id").Where("assessment_id = ?", a.ID.String()))
+					records, err := graphQLFind[models.AffectedSystem](graphQLPage(db.
+						Where("id IN (?) OR id IN (?)", direct, grouped).
+						Order("hostname ASC"), p.Args))
+					return pointers(records), err
+				}),
+			}
+		}),
+	})
+
+	// rootField authorizes a top-level field before resolving it
+	rootField := func(fieldType graphql.Output, resource string, args graphql.FieldConfigArgument, resolve graphql.FieldResolveFn) *graphql.Field {
+		return &graphql.Field{
+			Type: fieldType,
+			Args: args,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if err := authorizeGraphQL(p.Context, resource); err != nil {
+					return nil, err
+				}
+				return resolve(p)
+			},
+		}
+	}
+	stringArg := func(description string) *graphql.ArgumentConfig {
+		return &graphql.ArgumentConfig{Type: graphql.String, Description: description}
+	}
+	// filterEqual adds an equality filter for a string argument when it is set
+	filterEqual := func(query *gorm.DB, args map[string]interface{}, name, column string, upper bool) *gorm.DB {
+		value, _ := args[name].(string)
+		if value == "" {
+			return query
+		}
+		if upper {
+			value = strings.ToUpper(value)
+		}
+		return query.Where(column+" = ?", value)
+	}
+	// filterID adds an equality filter for an ID argument when it is set
+	filterID := func(query *gorm.DB, args map[string]interface{}, name, column string) (*gorm.DB, error) {
+		value, _ := args[name].(string)
+		if value == "" {
+			return query, nil
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s", name)
+		}
+		return query.Where(column+" = ?", id), nil
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"vulnerability": rootField(vulnerabilityType, "vulnerability", idArgs, func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := graphQLID(p.Args)
+				if err != nil {
+					return nil, err
+				}
+				return graphQLFirst[models.Vulnerability](graphQLDB(p), id)
+			}),
+			"vulnerabilities": rootField(graphql.NewList(vulnerabilityType), "vulnerability", pageArgs(graphql.FieldConfigArgument{
+				"severity": stringArg("CRITICAL, HIGH, MEDIUM, LOW or NONE"),
+				"status":   stringArg("Vulnerability status"),
+				"search":   stringArg("Matches title or CVE ID"),
+			}), func(p graphql.ResolveParams) (interface{}, error) {
+				q := graphQLDB(p).Model(&models.Vulnerability{})
+				q = filterEqual(q, p.Args, "severity", "severity", true)
+				q = filterEqual(q, p.Args, "status", "status", true)
+				if search, _ := p.Args["search"].(string); search != "" {
+					pattern := "%" + strings.ToLower(search) + "%"
+					q = q.Where("LOWER(title) LIKE ? OR LOWER(cve_id) LIKE ?", pattern, pattern)
+				}
+				records, err := graphQLFind[models.Vulnerability](graphQLPage(q.Order("discovery_date DESC"), p.Args))
+				return pointers(records), err
+			}),
+			"asset": rootField(assetType, "asset", idArgs, func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := graphQLID(p.Args)
+				if err != nil {
+					return nil, err
+				}
+				return graphQLFirst[models.AffectedSystem](graphQLDB(p), id)
+			}),
+			"assets": rootField(graphql.NewList(assetType), "asset", pageArgs(graphql.FieldConfigArgument{
+				"environment": stringArg("PRODUCTION, STAGING, DEVELOPMENT or TEST"),
+				"criticality": stringArg("CRITICAL, HIGH, MEDIUM or LOW"),
+				"status":      stringArg("Asset status"),
+				"search":      stringArg("Matches hostname, IP address or asset ID"),
+			}), func(p graphql.ResolveParams) (interface{}, error) {
+				q := graphQLDB(p).Model(&models.AffectedSystem{})
+				q = filterEqual(q, p.Args, "environment", "environment", true)
+				q = filterEqual(q, p.Args, "criticality", "criticality", true)
+				q = filterEqual(q, p.Args, "status", "status", true)
+				if search, _ := p.Args["search"].(string); search != "" {
+					pattern := "%" + strings.ToLower(search) + "%"
+					q = q.Where("LOWER(hostname) LIKE ? OR ip_address LIKE ? OR LOWER(asset_id) LIKE ?", pattern, pattern, pattern)
+				}
+				records, err := graphQLFind[models.AffectedSystem](graphQLPage(q.Order("hostname ASC"), p.Args))
+				return pointers(records), err
+			}),
+			"finding": rootField(findingType, "finding", idArgs, func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := graphQLID(p.Args)
+				if err != nil {
+					return nil, err
+				}
+				return graphQLFirst[models.VulnerabilityFinding](graphQLDB(p), id)
+			}),
+			"findings": rootField(graphql.NewList(findingType), "finding", pageArgs(graphql.FieldConfigArgument{
+				"status":          stringArg("Finding status"),
+				"vulnerabilityId": &graphql.ArgumentConfig{Type: graphql.ID},
+				"assetId":         &graphql.ArgumentConfig{Type: graphql.ID},
+			}), func(p graphql.ResolveParams) (interface{}, error) {
+				q := filterEqual(graphQLDB(p).Model(&models.VulnerabilityFinding{}), p.Args, "status", "status", true)
+				q, err := filterID(q, p.Args, "vulnerabilityId", "vulnerability_id")
+				if err != nil {
+					return nil, err
+				}
+				if q, err = filterID(q, p.Args, "assetId", "affected_system_id"); err != nil {
+					return nil, err
+				}
+				records, err := graphQLFind[models.VulnerabilityFinding](graphQLPage(q.Order("first_detected DESC"), p.Args))
+				return pointers(records), err
+			}),
+			"assessment": rootField(assessmentType, "assessment", idArgs, func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := graphQLID(p.Args)
+				if err != nil {
+					return nil, err
+				}
+				return graphQLFirst[models.Assessment](graphQLDB(p), id)
+			}),
+			"assessments": rootField(graphql.NewList(assessmentType), "assessment", pageArgs(graphql.FieldConfigArgument{
+				"status":         stringArg("Assessment status"),
+				"assessmentType": stringArg("Assessment type"),
+			}), func(p graphql.ResolveParams) (interface{}, error) {
+				q := graphQLDB(p).Model(&models.Assessment{})
+				q = filterEqual(q, p.Args, "status", "status", true)
+				q = filterEqual(q, p.Args, "assessmentType", "assessment_type", true)
+				records, err := graphQLFind[models.Assessment](graphQLPage(q.Order("start_date DESC"), p.Args))
+				return pointers(records), err
+			}),
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
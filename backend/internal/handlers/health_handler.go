@@ -4,16 +4,21 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/internal/services"
 	"github.com/cyops/cyops-backend/pkg/database"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
 // HealthHandler handles health check endpoints
-type HealthHandler struct{}
+type HealthHandler struct {
+	healthService *services.HealthService
+}
 
 // NewHealthHandler creates a new health check handler
 func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+	return &HealthHandler{
+		healthService: services.NewHealthService(database.GetDB()),
+	}
 }
 
 // HealthResponse represents the health check response
@@ -79,42 +84,45 @@ func (h *HealthHandler) Health(c *fiber.Ctx) error {
 	return c.Status(statusCode).JSON(response)
 }
 
-// Ready returns readiness status
+// ReadinessResponse is the public readiness status; dependency details need an administrator
+type ReadinessResponse struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Ready returns the readiness status for load balancers and orchestrators
 // @Summary Readiness check endpoint
-// @Description Returns whether the API is ready to accept traffic
+// @Description Checks the database, schema migrations, attachment disk space, Redis, search, and integrations. Returns 503 only when a critical dependency is down; optional dependencies report "degraded". Per-dependency results are at GET /api/v1/admin/health.
 // @Tags health
 // @Produce json
-// @Success 200 {object} map[string]interface{}
+// @Success 200 {object} ReadinessResponse
+// @Failure 503 {object} ReadinessResponse
 // @Router /health/ready [get]
 func (h *HealthHandler) Ready(c *fiber.Ctx) error {
-	// Check if database is ready
-	db := database.GetDB()
-	if db == nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"status": "not ready",
-			"reason": "database not initialized",
-		})
+	report := h.healthService.CheckReadiness(c.UserContext())
+	response := ReadinessResponse{
+		Status:    report.Status,
+		Timestamp: report.Timestamp,
 	}
 
-	sqlDB, err := db.DB()
-	if err != nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"status": "not ready",
-			"reason": "cannot get database connection: " + err.Error(),
-		})
+	if report.Status == services.ReadinessNotReady {
+		utils.Logger.Warn().Str("status", report.Status).Msg("Readiness check failed")
+		return c.Status(fiber.StatusServiceUnavailable).JSON(response)
 	}
 
-	err = sqlDB.Ping()
-	if err != nil {
-		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
-			"status": "not ready",
-			"reason": "database ping failed: " + err.Error(),
-		})
+	return c.JSON(response)
+}
+
+// ReadinessDetails returns the readiness status with the result of each dependency check
+// GET /api/v1/admin/health
+func (h *HealthHandler) ReadinessDetails(c *fiber.Ctx) error {
+	report := h.healthService.CheckReadiness(c.UserContext())
+
+	if report.Status == services.ReadinessNotReady {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(report)
 	}
 
-	return c.JSON(fiber.Map{
-		"status": "ready",
-	})
+	return c.JSON(report)
 }
 
 // Live returns liveness status
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// ImportRuleHandler handles the rules that classify the hosts of imported scans
+type ImportRuleHandler struct {
+	service *services.ImportRuleService
+}
+
+// NewImportRuleHandler creates a new import rule handler
+func NewImportRuleHandler() *ImportRuleHandler {
+	return &ImportRuleHandler{
+		service: services.NewImportRuleService(database.GetDB()),
+	}
+}
+
+// importRuleErrorResponse maps import rule service errors to HTTP responses
+func importRuleErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case msg == "import rule not found":
+		return middleware.NotFoundError(c, "Import rule")
+	case msg == "owner not found":
+		return middleware.NotFoundError(c, "User")
+	case msg == "team not found":
+		return middleware.NotFoundError(c, "Team")
+	case strings.Contains(msg, "required"), strings.Contains(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// ListRules lists import rules in evaluation order
+// GET /api/v1/settings/import-rules
+func (h *ImportRuleHandler) ListRules(c *fiber.Ctx) error {
+	rules, err := h.service.WithContext(c.UserContext()).ListRules()
+	if err != nil {
+		return importRuleErrorResponse(c, err, "Failed to list import rules")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": rules,
+	})
+}
+
+// TestRules shows how an import would classify a host with the enabled rules, or with the
+// unsaved rule in the request
+// POST /api/v1/settings/import-rules/test
+func (h *ImportRuleHandler) TestRules(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.ImportRuleTestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	result, err := h.service.WithContext(c.UserContext()).Test(req, userID)
+	if err != nil {
+		return importRuleErrorResponse(c, err, "Failed to test import rules")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": result,
+	})
+}
+
+// GetRule returns an import rule
+// GET /api/v1/settings/import-rules/:id
+func (h *ImportRuleHandler) GetRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid import rule ID", nil)
+	}
+
+	rule, err := h.service.WithContext(c.UserContext()).GetRule(id)
+	if err != nil {
+		return importRuleErrorResponse(c, err, "Failed to get import rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": rule,
+	})
+}
+
+// CreateRule creates an import rule applied to hosts of future imports
+// POST /api/v1/settings/import-rules
+func (h *ImportRuleHandler) CreateRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.ImportRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	req.Name = sanitizeStringPtr(req.Name)
+	req.Description = sanitizeStringPtr(req.Description)
+
+	rule, err := h.service.WithContext(c.UserContext()).CreateRule(req, userID)
+	if err != nil {
+		return importRuleErrorResponse(c, err, "Failed to create import rule")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Import rule created successfully",
+		"data":    rule,
+	})
+}
+
+// UpdateRule updates an import rule
+// PUT /api/v1/settings/import-rules/:id
+func (h *ImportRuleHandler) UpdateRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid import rule ID", nil)
+	}
+
+	var req services.ImportRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	req.Name = sanitizeStringPtr(req.Name)
+	req.Description = sanitizeStringPtr(req.Description)
+
+	rule, err := h.service.WithContext(c.UserContext()).UpdateRule(id, req, userID)
+	if err != nil {
+		return importRuleErrorResponse(c, err, "Failed to update import rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Import rule updated successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteRule deletes an import rule; assets already classified by it keep their values
+// DELETE /api/v1/settings/import-rules/:id
+func (h *ImportRuleHandler) DeleteRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid import rule ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).DeleteRule(id, userID); err != nil {
+		return importRuleErrorResponse(c, err, "Failed to delete import rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Import rule deleted successfully",
+	})
+}
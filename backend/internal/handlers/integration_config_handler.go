@@ -37,6 +37,11 @@ func (h *IntegrationConfigHandler) CreateConfig(c *fiber.Ctx) error {
 		Config           map[string]interface{}     `json:"config"`
 		AutoSync         bool                       `json:"auto_sync"`
 		SyncIntervalMins int                        `json:"sync_interval_mins"`
+		AutoCloseMissing bool                       `json:"auto_close_missing"`
+		VerifyTLS        *bool                      `json:"verify_tls"` // Defaults to true
+		CACertPEM        string                     `json:"ca_cert_pem"`
+		ClientCertPEM    string                     `json:"client_cert_pem"`
+		ClientKeyPEM     string                     `json:"client_key_pem"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -54,10 +59,29 @@ func (h *IntegrationConfigHandler) CreateConfig(c *fiber.Ctx) error {
 		Config:           req.Config,
 		AutoSync:         req.AutoSync,
 		SyncIntervalMins: req.SyncIntervalMins,
+		AutoCloseMissing: req.AutoCloseMissing,
+		TLSSkipVerify:    req.VerifyTLS != nil && !*req.VerifyTLS,
+		CACertPEM:        req.CACertPEM,
+		ClientCertPEM:    req.ClientCertPEM,
+		ClientKeyPEM:     req.ClientKeyPEM,
 		Active:           true,
 		CreatedBy:        userID,
 	}
 
+	if _, err := services.IntegrationTLSConfig(config); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if config.Type == models.IntegrationTypeNessus {
+		if err := services.NormalizeNessusConfig(config); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	if err := h.service.CreateConfig(config); err != nil {
 		// Check if it's a duplicate error
 		if strings.Contains(err.Error(), "already exists") {
@@ -146,6 +170,11 @@ func (h *IntegrationConfigHandler) UpdateConfig(c *fiber.Ctx) error {
 		Active           *bool                  `json:"active"`
 		AutoSync         *bool                  `json:"auto_sync"`
 		SyncIntervalMins *int                   `json:"sync_interval_mins"`
+		AutoCloseMissing *bool                  `json:"auto_close_missing"`
+		VerifyTLS        *bool                  `json:"verify_tls"`
+		CACertPEM        *string                `json:"ca_cert_pem"`
+		ClientCertPEM    *string                `json:"client_cert_pem"`
+		ClientKeyPEM     *string                `json:"client_key_pem"`
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -171,6 +200,61 @@ func (h *IntegrationConfigHandler) UpdateConfig(c *fiber.Ctx) error {
 	if req.Config != nil {
 		updates["config"] = req.Config
 	}
+
+	if req.VerifyTLS != nil {
+		updates["tls_skip_verify"] = !*req.VerifyTLS
+	}
+	if req.CACertPEM != nil {
+		updates["ca_cert_pem"] = *req.CACertPEM
+	}
+	if req.ClientCertPEM != nil {
+		updates["client_cert_pem"] = *req.ClientCertPEM
+	}
+	if req.ClientKeyPEM != nil {
+		updates["client_key_pem"] = *req.ClientKeyPEM
+	}
+
+	// Changing the URL, mode or TLS settings must leave the integration reachable
+	if req.BaseURL != nil || req.Config != nil || req.VerifyTLS != nil || req.CACertPEM != nil || req.ClientCertPEM != nil || req.ClientKeyPEM != nil {
+		existing, err := h.service.GetConfig(configID)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Integration config not found",
+			})
+		}
+		if req.BaseURL != nil {
+			existing.BaseURL = *req.BaseURL
+		}
+		if req.Config != nil {
+			existing.Config = req.Config
+		}
+		if req.VerifyTLS != nil {
+			existing.TLSSkipVerify = !*req.VerifyTLS
+		}
+		if req.CACertPEM != nil {
+			existing.CACertPEM = *req.CACertPEM
+		}
+		if req.ClientCertPEM != nil {
+			existing.ClientCertPEM = *req.ClientCertPEM
+		}
+		if req.ClientKeyPEM != nil {
+			existing.ClientKeyPEM = *req.ClientKeyPEM
+		}
+
+		if _, err := services.IntegrationTLSConfig(existing); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if existing.Type == models.IntegrationTypeNessus {
+			if err := services.NormalizeNessusConfig(existing); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			updates["base_url"] = existing.BaseURL
+		}
+	}
 	if req.Active != nil {
 		updates["active"] = *req.Active
 	}
@@ -180,6 +264,9 @@ func (h *IntegrationConfigHandler) UpdateConfig(c *fiber.Ctx) error {
 	if req.SyncIntervalMins != nil {
 		updates["sync_interval_mins"] = *req.SyncIntervalMins
 	}
+	if req.AutoCloseMissing != nil {
+		updates["auto_close_missing"] = *req.AutoCloseMissing
+	}
 
 	if err := h.service.UpdateConfig(configID, updates); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -229,6 +316,14 @@ func (h *IntegrationConfigHandler) TestConnection(c *fiber.Ctx) error {
 		})
 	}
 
+	// TLS settings are checked before connecting so a bad certificate is reported as such
+	if _, err := services.IntegrationTLSConfig(config); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Connection test failed",
+			"details": err.Error(),
+		})
+	}
+
 	// Test connection based on integration type
 	var testErr error
 	switch config.Type {
@@ -258,3 +353,86 @@ func (h *IntegrationConfigHandler) TestConnection(c *fiber.Ctx) error {
 		"type":    config.Type,
 	})
 }
+
+// GetSeverityMapping returns how the integration's scanner severities map to ours on import,
+// with the default levels used where it sets none
+// GET /api/v1/vulnerabilities/integrations/configs/:id/severity-mapping
+func (h *IntegrationConfigHandler) GetSeverityMapping(c *fiber.Ctx) error {
+	configID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid config ID",
+		})
+	}
+
+	mapping, err := h.service.GetSeverityMapping(configID)
+	if err != nil {
+		return severityMappingErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": fiber.Map{
+			"severity_mapping": mapping,
+			"default_levels":   models.DefaultScannerSeverities,
+		},
+	})
+}
+
+// UpdateSeverityMapping replaces the integration's severity mapping; an empty mapping restores
+// the defaults. It applies to later imports only.
+// PUT /api/v1/vulnerabilities/integrations/configs/:id/severity-mapping
+func (h *IntegrationConfigHandler) UpdateSeverityMapping(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	configID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid config ID",
+		})
+	}
+
+	var req models.SeverityMapping
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	mapping, err := h.service.UpdateSeverityMapping(configID, &req)
+	if err != nil {
+		return severityMappingErrorResponse(c, err)
+	}
+
+	utils.Logger.Info().
+		Str("integration_config_id", configID.String()).
+		Bool("mapped", mapping != nil).
+		Str("updated_by", userID.String()).
+		Msg("Integration severity mapping updated")
+
+	return c.JSON(fiber.Map{
+		"message": "Severity mapping updated successfully",
+		"data": fiber.Map{
+			"severity_mapping": mapping,
+			"default_levels":   models.DefaultScannerSeverities,
+		},
+	})
+}
+
+// severityMappingErrorResponse maps severity mapping errors to HTTP responses
+func severityMappingErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Integration config not found",
+		})
+	case strings.HasPrefix(err.Error(), "invalid"):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		utils.Logger.Error().Err(err).Msg("Failed to handle severity mapping")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to handle severity mapping",
+		})
+	}
+}
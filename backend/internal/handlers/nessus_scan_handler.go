@@ -1,14 +1,21 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
 	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/shutdown"
+	"github.com/cyops/cyops-backend/pkg/telemetry"
 	"github.com/cyops/cyops-backend/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type NessusScanHandler struct {
@@ -24,9 +31,27 @@ func NewNessusScanHandler(encryptionKey string) *NessusScanHandler {
 	}
 }
 
-// ListScans retrieves all available scans from Nessus
+// ListScans retrieves all available scans from Nessus, optionally only those in one folder
+// (?folder_id=) or of one type (?type=agent)
 // GET /api/v1/vulnerabilities/integrations/nessus/:config_id/scans
 func (h *NessusScanHandler) ListScans(c *fiber.Ctx) error {
+	return h.listScans(c, c.QueryInt("folder_id", 0))
+}
+
+// ListFolderScans retrieves the scans in a Nessus folder, optionally only those of one type (?type=agent)
+// GET /api/v1/vulnerabilities/integrations/nessus/:config_id/folders/:folder_id/scans
+func (h *NessusScanHandler) ListFolderScans(c *fiber.Ctx) error {
+	folderID, err := strconv.Atoi(c.Params("folder_id"))
+	if err != nil || folderID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid folder ID",
+		})
+	}
+	return h.listScans(c, folderID)
+}
+
+// listScans responds with the scans in a folder (all folders when folderID is 0)
+func (h *NessusScanHandler) listScans(c *fiber.Ctx, folderID int) error {
 	configID, err := uuid.Parse(c.Params("config_id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -34,7 +59,7 @@ func (h *NessusScanHandler) ListScans(c *fiber.Ctx) error {
 		})
 	}
 
-	scans, err := h.apiService.ListScans(configID)
+	scans, err := h.apiService.ListFolderScans(configID, folderID)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to list scans from Nessus")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -43,6 +68,16 @@ func (h *NessusScanHandler) ListScans(c *fiber.Ctx) error {
 		})
 	}
 
+	if scanType := c.Query("type"); scanType != "" {
+		filtered := make([]services.NessusScan, 0, len(scans))
+		for _, scan := range scans {
+			if strings.EqualFold(scan.Type, scanType) {
+				filtered = append(filtered, scan)
+			}
+		}
+		scans = filtered
+	}
+
 	return c.JSON(fiber.Map{
 		"message": "Scans retrieved successfully",
 		"data":    scans,
@@ -50,7 +85,34 @@ func (h *NessusScanHandler) ListScans(c *fiber.Ctx) error {
 	})
 }
 
-// GetScanDetails retrieves detailed information about a specific scan
+// ListFolders retrieves the folders scans are organized in
+// GET /api/v1/vulnerabilities/integrations/nessus/:config_id/folders
+func (h *NessusScanHandler) ListFolders(c *fiber.Ctx) error {
+	configID, err := uuid.Parse(c.Params("config_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid config ID",
+		})
+	}
+
+	folders, err := h.apiService.ListFolders(configID)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to list folders from Nessus")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to list folders",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Folders retrieved successfully",
+		"data":    folders,
+		"count":   len(folders),
+	})
+}
+
+// GetScanDetails retrieves detailed information about a specific scan, or about one of its
+// runs (?history_id=), e.g. an earlier run of an agent scan
 // GET /api/v1/vulnerabilities/integrations/nessus/:config_id/scans/:scan_id
 func (h *NessusScanHandler) GetScanDetails(c *fiber.Ctx) error {
 	configID, err := uuid.Parse(c.Params("config_id"))
@@ -67,7 +129,7 @@ func (h *NessusScanHandler) GetScanDetails(c *fiber.Ctx) error {
 		})
 	}
 
-	details, err := h.apiService.GetScanDetails(configID, scanID)
+	details, err := h.apiService.GetScanRunDetails(configID, scanID, c.QueryInt("history_id", 0))
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to get scan details")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -121,7 +183,9 @@ func (h *NessusScanHandler) ImportSingleScan(c *fiber.Ctx) error {
 		Msg("Importing single scan from Nessus")
 
 	// Import and parse scan
-	vulnerabilities, err := h.apiService.ImportScan(configID, scanID)
+	fetchCtx, fetchSpan := telemetry.StartSpan(c.UserContext(), "NessusAPIService.ImportScan", attribute.Int("nessus.scan_id", scanID))
+	vulnerabilities, err := h.apiService.WithContext(fetchCtx).ImportScan(configID, scanID)
+	telemetry.EndSpan(fetchSpan, err)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to import scan")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -133,11 +197,19 @@ func (h *NessusScanHandler) ImportSingleScan(c *fiber.Ctx) error {
 	// Save to database using existing import service
 	// Note: skipDuplicates is opposite of update_existing
 	skipDuplicates := !req.UpdateExisting
-	result, err := h.importService.ImportFromNessus(
+	result, err := h.importService.WithContext(c.UserContext()).ImportFromNessus(
 		vulnerabilities,
 		userID,
 		skipDuplicates,
+		services.ImportSource{
+			Scanner:             "nessus",
+			IntegrationConfigID: &configID,
+			ScanID:              strconv.Itoa(scanID),
+		},
 	)
+	if isShuttingDown(err) {
+		return middleware.ShuttingDownError(c)
+	}
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to save imported vulnerabilities")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -159,6 +231,8 @@ func (h *NessusScanHandler) ImportSingleScan(c *fiber.Ctx) error {
 		"assets_created": result.CreatedAssets,
 		"findings_created": result.CreatedFindings,
 		"errors":         result.Errors,
+		"import_job_id":  result.ImportJobID,
+		"diff":           result.Diff,
 	}
 
 	return c.JSON(fiber.Map{
@@ -210,21 +284,17 @@ func (h *NessusScanHandler) ImportMultipleScans(c *fiber.Ctx) error {
 		Msg("Importing multiple scans from Nessus")
 
 	// Import all scans
-	results, errors := h.apiService.ImportMultipleScans(configID, req.ScanIDs)
-
-	// Aggregate all vulnerabilities
-	allVulns := []services.ParsedVulnerability{}
-	for _, vulns := range results {
-		allVulns = append(allVulns, vulns...)
-	}
+	fetchCtx, fetchSpan := telemetry.StartSpan(c.UserContext(), "NessusAPIService.ImportMultipleScans", attribute.IntSlice("nessus.scan_ids", req.ScanIDs))
+	results, errors := h.apiService.WithContext(fetchCtx).ImportMultipleScans(configID, req.ScanIDs, logScanImportProgress(configID))
+	fetchSpan.SetAttributes(attribute.Int("nessus.failed_scans", len(errors)))
+	telemetry.EndSpan(fetchSpan, nil)
 
 	// Save to database
 	skipDuplicates := !req.UpdateExisting
-	importResult, err := h.importService.ImportFromNessus(
-		allVulns,
-		userID,
-		skipDuplicates,
-	)
+	importResult, importJobs, err := h.importScanResults(c, configID, req.ScanIDs, results, userID, skipDuplicates)
+	if isShuttingDown(err) {
+		return middleware.ShuttingDownError(c)
+	}
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to save imported vulnerabilities")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -240,6 +310,7 @@ func (h *NessusScanHandler) ImportMultipleScans(c *fiber.Ctx) error {
 		if vulns, ok := results[scanID]; ok {
 			scanResult["status"] = "success"
 			scanResult["vulnerabilities_found"] = len(vulns)
+			scanResult["import_job_id"] = importJobs[scanID]
 		} else if err, ok := errors[scanID]; ok {
 			scanResult["status"] = "failed"
 			scanResult["error"] = err.Error()
@@ -266,6 +337,64 @@ func (h *NessusScanHandler) ImportMultipleScans(c *fiber.Ctx) error {
 	})
 }
 
+// importScanResults saves the vulnerabilities of each exported scan as an import of its own,
+// so that re-imports of a scan are diffed against it, and merges the results. It returns the
+// import job of each scan.
+func (h *NessusScanHandler) importScanResults(
+	c *fiber.Ctx,
+	configID uuid.UUID,
+	scanIDs []int,
+	results map[int][]services.ParsedVulnerability,
+	userID uuid.UUID,
+	skipDuplicates bool,
+) (*services.ImportResult, map[int]*uuid.UUID, error) {
+	merged := &services.ImportResult{
+		Errors:   []string{},
+		Warnings: []string{},
+		Summary:  make(map[string]interface{}),
+	}
+	importJobs := make(map[int]*uuid.UUID, len(results))
+	for _, scanID := range scanIDs {
+		vulns, ok := results[scanID]
+		if !ok {
+			continue
+		}
+		result, err := h.importService.WithContext(c.UserContext()).ImportFromNessus(
+			vulns,
+			userID,
+			skipDuplicates,
+			services.ImportSource{
+				Scanner:             "nessus",
+				IntegrationConfigID: &configID,
+				ScanID:              strconv.Itoa(scanID),
+			},
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		importJobs[scanID] = result.ImportJobID
+		merged.Merge(result)
+	}
+	return merged, importJobs, nil
+}
+
+// logScanImportProgress logs a multi-scan import each time one of its scans finishes
+func logScanImportProgress(configID uuid.UUID) func(services.ScanImportProgress) {
+	finished := 0
+	return func(progress services.ScanImportProgress) {
+		if progress.Completed+progress.Failed == finished {
+			return
+		}
+		finished = progress.Completed + progress.Failed
+		utils.Logger.Info().
+			Str("config_id", configID.String()).
+			Int("total", progress.Total).
+			Int("completed", progress.Completed).
+			Int("failed", progress.Failed).
+			Msg("Scan import progress")
+	}
+}
+
 // ImportAllScans imports all completed scans from Nessus
 // POST /api/v1/vulnerabilities/integrations/nessus/:config_id/scans/import-all
 func (h *NessusScanHandler) ImportAllScans(c *fiber.Ctx) error {
@@ -284,6 +413,7 @@ func (h *NessusScanHandler) ImportAllScans(c *fiber.Ctx) error {
 		UpdateExisting      bool       `json:"update_existing"`
 		DefaultAssigneeID   *uuid.UUID `json:"default_assignee_id"`
 		StatusFilter        string     `json:"status_filter"` // "completed", "running", "all"
+		FolderID            int        `json:"folder_id"`     // Only scans in this folder; scans in the trash are skipped otherwise
 	}
 
 	if err := c.BodyParser(&req); err != nil {
@@ -297,20 +427,35 @@ func (h *NessusScanHandler) ImportAllScans(c *fiber.Ctx) error {
 	utils.Logger.Info().
 		Str("config_id", configID.String()).
 		Str("status_filter", req.StatusFilter).
+		Int("folder_id", req.FolderID).
 		Msg("Importing all scans from Nessus")
 
 	// Get all scans first
-	scans, err := h.apiService.ListScans(configID)
+	scans, err := h.apiService.ListFolderScans(configID, req.FolderID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   "Failed to list scans",
 			"details": err.Error(),
 		})
 	}
+	trashID := 0
+	if req.FolderID == 0 {
+		folders, err := h.apiService.ListFolders(configID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   "Failed to list folders",
+				"details": err.Error(),
+			})
+		}
+		trashID = services.TrashFolderID(folders)
+	}
 
 	// Filter scans by status
 	scanIDs := make([]int, 0)
 	for _, scan := range scans {
+		if trashID != 0 && scan.FolderID == trashID {
+			continue
+		}
 		if req.StatusFilter == "all" || strings.ToLower(scan.Status) == strings.ToLower(req.StatusFilter) {
 			scanIDs = append(scanIDs, scan.ID)
 		}
@@ -326,21 +471,17 @@ func (h *NessusScanHandler) ImportAllScans(c *fiber.Ctx) error {
 	}
 
 	// Import all filtered scans
-	results, errors := h.apiService.ImportMultipleScans(configID, scanIDs)
-
-	// Aggregate all vulnerabilities
-	allVulns := []services.ParsedVulnerability{}
-	for _, vulns := range results {
-		allVulns = append(allVulns, vulns...)
-	}
+	fetchCtx, fetchSpan := telemetry.StartSpan(c.UserContext(), "NessusAPIService.ImportMultipleScans", attribute.IntSlice("nessus.scan_ids", scanIDs))
+	results, errors := h.apiService.WithContext(fetchCtx).ImportMultipleScans(configID, scanIDs, logScanImportProgress(configID))
+	fetchSpan.SetAttributes(attribute.Int("nessus.failed_scans", len(errors)))
+	telemetry.EndSpan(fetchSpan, nil)
 
 	// Save to database
 	skipDuplicates := !req.UpdateExisting
-	importResult, err := h.importService.ImportFromNessus(
-		allVulns,
-		userID,
-		skipDuplicates,
-	)
+	importResult, _, err := h.importScanResults(c, configID, scanIDs, results, userID, skipDuplicates)
+	if isShuttingDown(err) {
+		return middleware.ShuttingDownError(c)
+	}
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to save imported vulnerabilities")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -368,6 +509,85 @@ func (h *NessusScanHandler) ImportAllScans(c *fiber.Ctx) error {
 	})
 }
 
+// ImportTenableVulnerabilities imports open vulnerabilities from Tenable.io through its vulnerability
+// export API, optionally only those seen since a point in time
+// POST /api/v1/vulnerabilities/integrations/nessus/:config_id/vulns/import
+func (h *NessusScanHandler) ImportTenableVulnerabilities(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	configID, err := uuid.Parse(c.Params("config_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid config ID",
+		})
+	}
+
+	var req struct {
+		Since          *time.Time `json:"since"`
+		UpdateExisting bool       `json:"update_existing"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+	var since time.Time
+	if req.Since != nil {
+		since = *req.Since
+	}
+
+	utils.Logger.Info().
+		Str("config_id", configID.String()).
+		Time("since", since).
+		Msg("Importing vulnerabilities from Tenable.io")
+
+	fetchCtx, fetchSpan := telemetry.StartSpan(c.UserContext(), "NessusAPIService.ExportVulnerabilities")
+	vulnerabilities, err := h.apiService.WithContext(fetchCtx).ExportVulnerabilities(configID, since)
+	telemetry.EndSpan(fetchSpan, err)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to export vulnerabilities from Tenable.io")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to export vulnerabilities",
+			"details": err.Error(),
+		})
+	}
+
+	skipDuplicates := !req.UpdateExisting
+	importResult, err := h.importService.WithContext(c.UserContext()).ImportFromNessus(
+		vulnerabilities,
+		userID,
+		skipDuplicates,
+		services.ImportSource{Scanner: "nessus", IntegrationConfigID: &configID},
+	)
+	if isShuttingDown(err) {
+		return middleware.ShuttingDownError(c)
+	}
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to save imported vulnerabilities")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to save vulnerabilities",
+			"details": err.Error(),
+		})
+	}
+
+	utils.Logger.Info().
+		Int("vulnerabilities_imported", importResult.ImportedVulnerabilities).
+		Int("assets_created", importResult.CreatedAssets).
+		Msg("Tenable.io vulnerability import completed")
+
+	return c.JSON(fiber.Map{
+		"message": "Vulnerabilities imported successfully",
+		"data":    importResult,
+	})
+}
+
 // PreviewScan previews what will be imported from a scan without saving
 // GET /api/v1/vulnerabilities/integrations/nessus/:config_id/scans/:scan_id/preview
 func (h *NessusScanHandler) PreviewScan(c *fiber.Ctx) error {
@@ -391,7 +611,9 @@ func (h *NessusScanHandler) PreviewScan(c *fiber.Ctx) error {
 		Msg("Previewing scan")
 
 	// Import and parse scan (without saving)
-	vulnerabilities, err := h.apiService.ImportScan(configID, scanID)
+	fetchCtx, fetchSpan := telemetry.StartSpan(c.UserContext(), "NessusAPIService.ImportScan", attribute.Int("nessus.scan_id", scanID))
+	vulnerabilities, err := h.apiService.WithContext(fetchCtx).ImportScan(configID, scanID)
+	telemetry.EndSpan(fetchSpan, err)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to preview scan")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -421,3 +643,81 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// ResumeImportJob continues a scan import interrupted by a server shutdown from its
+// checkpoint, with a new export of the scan
+// POST /api/v1/vulnerabilities/imports/:id/resume
+func (h *NessusScanHandler) ResumeImportJob(c *fiber.Ctx) error {
+	jobID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid import job ID",
+		})
+	}
+
+	importService := h.importService.WithContext(c.UserContext())
+	job, err := importService.GetImportJob(jobID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return middleware.NotFoundError(c, "Import job")
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to get import job")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve import job",
+		})
+	}
+	if job.Status != models.ImportJobInterrupted {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Only interrupted imports can be resumed",
+		})
+	}
+	scanID, err := strconv.Atoi(job.ScanID)
+	if job.IntegrationConfigID == nil || err != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Only scan imports through an integration can be resumed; import the file again",
+		})
+	}
+
+	fetchCtx, fetchSpan := telemetry.StartSpan(c.UserContext(), "NessusAPIService.ImportScan", attribute.Int("nessus.scan_id", scanID))
+	vulnerabilities, err := h.apiService.WithContext(fetchCtx).ImportScan(*job.IntegrationConfigID, scanID)
+	telemetry.EndSpan(fetchSpan, err)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to import scan")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to import scan",
+			"details": err.Error(),
+		})
+	}
+
+	result, err := importService.ResumeImport(job.ID, vulnerabilities)
+	if isShuttingDown(err) {
+		return middleware.ShuttingDownError(c)
+	}
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "only interrupted") || strings.HasPrefix(err.Error(), "the results have fewer") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to resume import")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to resume import",
+			"details": err.Error(),
+		})
+	}
+
+	utils.Logger.Info().
+		Str("import_job_id", job.ID.String()).
+		Int("vulnerabilities_imported", result.ImportedVulnerabilities).
+		Msg("Scan import resumed")
+
+	return c.JSON(fiber.Map{
+		"message": "Import resumed",
+		"data":    result,
+	})
+}
+
+// isShuttingDown reports whether work was refused because the server is draining
+func isShuttingDown(err error) bool {
+	return errors.Is(err, shutdown.ErrDraining)
+}
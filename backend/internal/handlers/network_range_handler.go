@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// NetworkRangeHandler handles network range management
+type NetworkRangeHandler struct {
+	service *services.NetworkRangeService
+}
+
+// NewNetworkRangeHandler creates a new network range handler
+func NewNetworkRangeHandler() *NetworkRangeHandler {
+	return &NetworkRangeHandler{
+		service: services.NewNetworkRangeService(database.GetDB()),
+	}
+}
+
+// networkRangeErrorResponse maps network range service errors to HTTP responses
+func networkRangeErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case msg == "network range not found":
+		return middleware.NotFoundError(c, "Network range")
+	case msg == "owner not found":
+		return middleware.NotFoundError(c, "User")
+	case msg == "team not found":
+		return middleware.NotFoundError(c, "Team")
+	case strings.Contains(msg, "already exists"):
+		return middleware.ConflictError(c, msg)
+	case strings.Contains(msg, "required"), strings.Contains(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// ListRanges lists network ranges
+// GET /api/v1/network-ranges?search=
+func (h *NetworkRangeHandler) ListRanges(c *fiber.Ctx) error {
+	ranges, err := h.service.WithContext(c.UserContext()).List(c.Query("search"))
+	if err != nil {
+		return networkRangeErrorResponse(c, err, "Failed to list network ranges")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": ranges,
+	})
+}
+
+// LookupRange returns the most specific range containing an IP address
+// GET /api/v1/network-ranges/lookup?ip=10.1.2.3
+func (h *NetworkRangeHandler) LookupRange(c *fiber.Ctx) error {
+	ip := c.Query("ip")
+	if ip == "" {
+		return middleware.ValidationError(c, "ip is required", nil)
+	}
+
+	r, err := h.service.WithContext(c.UserContext()).Lookup(ip)
+	if err != nil {
+		return networkRangeErrorResponse(c, err, "Failed to look up network range")
+	}
+
+	return c.JSON(fiber.Map{
+		"data":    r,
+		"matched": r != nil,
+	})
+}
+
+// GetRange returns a network range
+// GET /api/v1/network-ranges/:id
+func (h *NetworkRangeHandler) GetRange(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid network range ID", nil)
+	}
+
+	r, err := h.service.WithContext(c.UserContext()).Get(id)
+	if err != nil {
+		return networkRangeErrorResponse(c, err, "Failed to get network range")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": r,
+	})
+}
+
+// CreateRange creates a network range applied to hosts of future imports
+// POST /api/v1/network-ranges
+func (h *NetworkRangeHandler) CreateRange(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.NetworkRangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	req.Name = sanitizeStringPtr(req.Name)
+	req.Description = sanitizeStringPtr(req.Description)
+	req.Location = sanitizeStringPtr(req.Location)
+
+	r, err := h.service.WithContext(c.UserContext()).Create(req, userID)
+	if err != nil {
+		return networkRangeErrorResponse(c, err, "Failed to create network range")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Network range created successfully",
+		"data":    r,
+	})
+}
+
+// UpdateRange updates a network range
+// PUT /api/v1/network-ranges/:id
+func (h *NetworkRangeHandler) UpdateRange(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid network range ID", nil)
+	}
+
+	var req services.NetworkRangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	req.Name = sanitizeStringPtr(req.Name)
+	req.Description = sanitizeStringPtr(req.Description)
+	req.Location = sanitizeStringPtr(req.Location)
+
+	r, err := h.service.WithContext(c.UserContext()).Update(id, req)
+	if err != nil {
+		return networkRangeErrorResponse(c, err, "Failed to update network range")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Network range updated successfully",
+		"data":    r,
+	})
+}
+
+// DeleteRange deletes a network range; assets already classified by it keep their values
+// DELETE /api/v1/network-ranges/:id
+func (h *NetworkRangeHandler) DeleteRange(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid network range ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).Delete(id); err != nil {
+		return networkRangeErrorResponse(c, err, "Failed to delete network range")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Network range deleted successfully",
+	})
+}
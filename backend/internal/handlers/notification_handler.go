@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// NotificationHandler handles the current user's in-app notifications
+type NotificationHandler struct {
+	service *services.NotificationService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler() *NotificationHandler {
+	return &NotificationHandler{
+		service: services.NewNotificationService(database.GetDB()),
+	}
+}
+
+// ListNotifications lists the current user's notifications, newest first
+// GET /api/v1/notifications?unread=true&page=1&limit=20
+func (h *NotificationHandler) ListNotifications(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	notifications, total, err := h.service.ListNotifications(userID, c.QueryBool("unread", false), page, limit)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to list notifications")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list notifications",
+		})
+	}
+
+	unread, err := h.service.CountUnread(userID)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to count unread notifications")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list notifications",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": notifications,
+		"meta": fiber.Map{
+			"page":   page,
+			"limit":  limit,
+			"total":  total,
+			"unread": unread,
+		},
+	})
+}
+
+// MarkRead marks a notification as read
+// POST /api/v1/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	notificationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid notification ID", nil)
+	}
+
+	if err := h.service.MarkRead(userID, notificationID); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return middleware.NotFoundError(c, "Notification")
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to mark notification as read")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to mark notification as read",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Notification marked as read",
+	})
+}
+
+// MarkAllRead marks all of the current user's notifications as read
+// POST /api/v1/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	count, err := h.service.MarkAllRead(userID)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to mark notifications as read")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to mark notifications as read",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Notifications marked as read",
+		"data": fiber.Map{
+			"updated": count,
+		},
+	})
+}
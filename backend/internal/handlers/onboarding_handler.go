@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// OnboardingHandler handles policy acceptance and first-login setup steps
+type OnboardingHandler struct {
+	service *services.OnboardingService
+}
+
+// NewOnboardingHandler creates a new onboarding handler
+func NewOnboardingHandler() *OnboardingHandler {
+	return &OnboardingHandler{
+		service: services.NewOnboardingService(database.GetDB()),
+	}
+}
+
+// onboardingErrorResponse maps onboarding service errors to HTTP responses
+func onboardingErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case msg == "user not found":
+		return middleware.NotFoundError(c, "User")
+	case msg == "policy not found":
+		return middleware.NotFoundError(c, "Policy")
+	case msg == "step not found":
+		return middleware.NotFoundError(c, "Onboarding step")
+	case strings.HasPrefix(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// GetOnboarding returns the policies the user has to accept and the setup steps left
+// GET /api/v1/onboarding
+func (h *OnboardingHandler) GetOnboarding(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	state, err := h.service.WithContext(c.UserContext()).GetState(userID)
+	if err != nil {
+		return onboardingErrorResponse(c, err, "Failed to retrieve onboarding state")
+	}
+	return c.JSON(state)
+}
+
+// AcceptPolicyRequest names the policy version the user was shown
+type AcceptPolicyRequest struct {
+	Version string `json:"version" validate:"required"`
+}
+
+// AcceptPolicy records the user accepting the current version of a policy
+// POST /api/v1/onboarding/policies/:key/accept
+func (h *OnboardingHandler) AcceptPolicy(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req AcceptPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	if req.Version == "" {
+		return middleware.ValidationError(c, "Version is required", map[string]interface{}{
+			"version": "required",
+		})
+	}
+
+	service := h.service.WithContext(c.UserContext())
+	acceptance, err := service.AcceptPolicy(userID, c.Params("key"), req.Version, c.IP(), c.Get("User-Agent"))
+	if err != nil {
+		return onboardingErrorResponse(c, err, "Failed to record policy acceptance")
+	}
+
+	state, err := service.GetState(userID)
+	if err != nil {
+		return onboardingErrorResponse(c, err, "Failed to retrieve onboarding state")
+	}
+	return c.JSON(fiber.Map{
+		"acceptance": acceptance,
+		"onboarding": state,
+	})
+}
+
+// SkipOnboardingStep dismisses an optional setup step such as the two-factor prompt
+// POST /api/v1/onboarding/steps/:step/skip
+func (h *OnboardingHandler) SkipOnboardingStep(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	service := h.service.WithContext(c.UserContext())
+	if err := service.SkipStep(userID, models.OnboardingStep(c.Params("step"))); err != nil {
+		return onboardingErrorResponse(c, err, "Failed to skip onboarding step")
+	}
+
+	state, err := service.GetState(userID)
+	if err != nil {
+		return onboardingErrorResponse(c, err, "Failed to retrieve onboarding state")
+	}
+	return c.JSON(state)
+}
+
+// GetUserOnboarding returns a user's onboarding state for administrators
+// GET /api/v1/admin/users/:id/onboarding
+func (h *OnboardingHandler) GetUserOnboarding(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid user ID", nil)
+	}
+
+	state, err := h.service.WithContext(c.UserContext()).GetState(userID)
+	if err != nil {
+		return onboardingErrorResponse(c, err, "Failed to retrieve onboarding state")
+	}
+	return c.JSON(state)
+}
+
+// ResetUserOnboarding makes a user accept the policies and go through the setup steps again.
+// ?scope=policies or ?scope=steps limits the reset; by default both are reset.
+// POST /api/v1/admin/users/:id/onboarding/reset
+func (h *OnboardingHandler) ResetUserOnboarding(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid user ID", nil)
+	}
+
+	resetPolicies, resetSteps := true, true
+	switch c.Query("scope", "all") {
+	case "all":
+	case "policies":
+		resetSteps = false
+	case "steps":
+		resetPolicies = false
+	default:
+		return middleware.ValidationError(c, "Invalid scope. Must be 'all', 'policies' or 'steps'", nil)
+	}
+
+	adminID := c.Locals("user_id").(uuid.UUID)
+	service := h.service.WithContext(c.UserContext())
+	if err := service.ResetOnboarding(userID, adminID, resetPolicies, resetSteps, c.IP(), c.Get("User-Agent")); err != nil {
+		return onboardingErrorResponse(c, err, "Failed to reset onboarding")
+	}
+
+	state, err := service.GetState(userID)
+	if err != nil {
+		return onboardingErrorResponse(c, err, "Failed to retrieve onboarding state")
+	}
+	return c.JSON(fiber.Map{
+		"message":    "Onboarding reset",
+		"onboarding": state,
+	})
+}
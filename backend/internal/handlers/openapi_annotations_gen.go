@@ -0,0 +1,2667 @@
+// Code generated by openapi-gen. DO NOT EDIT.
+
+package handlers
+
+import (
+	"reflect"
+
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/cwe"
+	"github.com/cyops/cyops-backend/pkg/faultinject"
+	"github.com/cyops/cyops-backend/pkg/openapi"
+	"github.com/gofiber/fiber/v2"
+)
+
+// openAPIAnnotations documents the handlers of this package, keyed by openapi.HandlerName
+var openAPIAnnotations = map[string]openapi.Annotation{
+	"handlers.(*APIKeyHandler).CreateAPIKey": {
+		Summary: "Creates a new API key",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*CreateAPIKeyRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*APIKeyHandler).DeleteAPIKey": {
+		Summary: "Soft-deletes an API key",
+	},
+	"handlers.(*APIKeyHandler).GetAPIKey": {
+		Summary: "Retrieves a specific API key by ID",
+	},
+	"handlers.(*APIKeyHandler).GetAPIKeyUsage": {
+		Summary: "Returns request counts for one of the user's API keys, per day and per endpoint",
+		Params: []openapi.ParamAnnotation{
+			{Name: "days", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*APIKeyHandler).GetCurrentAPIKey": {
+		Summary: "Describes the API key used to authenticate the request, including its scopes",
+	},
+	"handlers.(*APIKeyHandler).ListAPIKeys": {
+		Summary: "Lists all API keys for the authenticated user",
+	},
+	"handlers.(*APIKeyHandler).ListScopes": {
+		Summary: "Returns the catalog of scopes API keys can be granted",
+	},
+	"handlers.(*APIKeyHandler).RetireSecondaryAPIKey": {
+		Summary: "Invalidates the previous secret of a rotated key once integrations have switched",
+	},
+	"handlers.(*APIKeyHandler).RevokeAPIKey": {
+		Summary: "Revokes an API key",
+	},
+	"handlers.(*APIKeyHandler).RotateAPIKey": {
+		Summary: "Issues a new secret for a service or MCP key; the previous secret keeps working as the secondary for the grace period",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*RotateAPIKeyRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*APIKeyHandler).UpdateAPIKeyAllowedCIDRs": {
+		Summary:     "Replaces the client networks an API key may be used from",
+		Description: "PUT /api/v1/api-keys/:id/allowed-cidrs",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*UpdateAPIKeyAllowedCIDRsRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*APIKeyHandler).UpdateAPIKeyStatus": {
+		Summary: "Updates the status of an API key",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*UpdateAPIKeyStatusRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AdminHandler).AssignRole": {
+		Summary: "Assigns a role to a user",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*AssignRoleRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AdminHandler).CleanupAllData": {
+		Summary:     "Permanently deletes ALL vulnerability and asset data",
+		Description: "This is a destructive operation that removes all data but preserves users/auth",
+	},
+	"handlers.(*AdminHandler).CleanupAssets": {
+		Summary: "Permanently deletes all soft-deleted assets",
+	},
+	"handlers.(*AdminHandler).CleanupVulnerabilities": {
+		Summary: "Permanently deletes all soft-deleted vulnerabilities",
+	},
+	"handlers.(*AdminHandler).CreateUser": {
+		Summary: "Creates a new user account (admin only)",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*CreateUserRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*AdminHandler).DeleteUser": {
+		Summary: "Deletes a user account (admin only)",
+	},
+	"handlers.(*AdminHandler).GetAdminIPAccess": {
+		Summary: "Returns the client networks allowed and denied access to the admin API, and the address the caller is seen from",
+	},
+	"handlers.(*AdminHandler).GetBackupJob": {
+		Summary: "Returns the status and progress of a backup or restore",
+	},
+	"handlers.(*AdminHandler).GetCleanupStats": {
+		Summary: "Retrieves statistics about soft-deleted items",
+	},
+	"handlers.(*AdminHandler).GetDatabaseStats": {
+		Summary: "Returns connection pool usage and slow query counts of this instance",
+	},
+	"handlers.(*AdminHandler).GetRetentionSettings": {
+		Summary: "Returns the data retention periods and the report of the latest run",
+	},
+	"handlers.(*AdminHandler).GetUser": {
+		Summary: "Retrieves a specific user by ID",
+	},
+	"handlers.(*AdminHandler).ImpersonateUser": {
+		Summary: "Starts a time-boxed session acting as a user, so support staff can see exactly what the user sees. The session is audited under the administrator's ID and cannot change the user's credentials",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*ImpersonateUserRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*AdminHandler).ListBackups": {
+		Summary: "Lists the completed backups available for restore, newest first",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "per_page", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*AdminHandler).ListRetentionRuns": {
+		Summary: "Returns the reports of recent data retention runs, newest first",
+		Params: []openapi.ParamAnnotation{
+			{Name: "limit", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*AdminHandler).ListScheduledJobs": {
+		Summary: "Returns the background jobs that run once across instances, with the instance, outcome and duration of their last run",
+	},
+	"handlers.(*AdminHandler).ListTrash": {
+		Summary: "Lists soft-deleted vulnerabilities and assets with who deleted them and when",
+		Params: []openapi.ParamAnnotation{
+			{In: "query", Model: reflect.TypeOf((*ListTrashRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AdminHandler).ListUsers": {
+		Summary: "Retrieves a paginated list of all users",
+		Params: []openapi.ParamAnnotation{
+			{In: "query", Model: reflect.TypeOf((*ListUsersRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AdminHandler).PreviewRetention": {
+		Summary:     "Reports what a retention run would purge without deleting anything",
+		Description: "The request body may carry retention settings to preview before saving them; without a body the saved settings are previewed.",
+	},
+	"handlers.(*AdminHandler).RestoreBackup": {
+		Summary: "Starts restoring a backup into an empty database; poll the returned job for progress",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*RestoreBackupRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 202},
+		},
+	},
+	"handlers.(*AdminHandler).RestoreTrashItem": {
+		Summary: "Restores a soft-deleted vulnerability or asset from the recycle bin",
+	},
+	"handlers.(*AdminHandler).RunRetention": {
+		Summary: "Purges data older than the saved retention periods now",
+	},
+	"handlers.(*AdminHandler).StartBackup": {
+		Summary: "Starts a backup of the database and the attachment manifest; poll the returned job for progress",
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 202},
+		},
+	},
+	"handlers.(*AdminHandler).UpdateAdminIPAccess": {
+		Summary: "Replaces the admin allowlist and denylist. Lists that would block the caller's own address are refused so an administrator cannot lock themselves out",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.IPAccessList)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AdminHandler).UpdateUserStatus": {
+		Summary: "Updates user account status (admin only)",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*UpdateUserStatusRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AffectedSystemHandler).AddVulnerabilityAffectedSystems": {
+		Summary: "Adds affected systems to a vulnerability",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*AddAffectedSystemsRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AffectedSystemHandler).CreateAffectedSystem": {
+		Summary: "Creates a new affected system",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*CreateAffectedSystemRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*AffectedSystemHandler).DeleteAffectedSystem": {
+		Summary: "Deletes an affected system",
+	},
+	"handlers.(*AffectedSystemHandler).GetAffectedSystem": {
+		Summary: "Returns a single affected system",
+	},
+	"handlers.(*AffectedSystemHandler).GetVulnerabilityAffectedSystems": {
+		Summary: "Returns affected systems for a vulnerability",
+	},
+	"handlers.(*AffectedSystemHandler).ListAffectedSystems": {
+		Summary: "Returns a list of affected systems",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+			{Name: "type", In: "query", Type: "string"},
+			{Name: "environment", In: "query", Type: "string"},
+			{Name: "search", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*AffectedSystemHandler).RemoveVulnerabilityAffectedSystem": {
+		Summary: "Removes an affected system from a vulnerability",
+	},
+	"handlers.(*AffectedSystemHandler).UpdateAffectedSystem": {
+		Summary: "Updates an affected system",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*UpdateAffectedSystemRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AgentHandler).CheckIn": {
+		Summary: "Agent check-in",
+		Tags:    []string{"Agents"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Endpoint inventory", Model: reflect.TypeOf((*services.AgentCheckinRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.AgentCheckinResult)(nil)).Elem()},
+			{Status: 201, Model: reflect.TypeOf((*services.AgentCheckinResult)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AgentHandler).ListAssetPackages": {
+		Summary: "List asset packages",
+		Tags:    []string{"Assets"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Asset ID"},
+			{Name: "search", In: "query", Type: "string", Description: "Filter by package name"},
+			{Name: "page", In: "query", Type: "int", Description: "Page"},
+			{Name: "limit", In: "query", Type: "int", Description: "Page size (max 500)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.AssetPackage)(nil)).Elem(), Array: true},
+		},
+	},
+	"handlers.(*AssessmentExportHandler).ExportPackage": {
+		Summary:     "Streams a ZIP export package of an assessment for regulator submission: the generated report, evidence attachments, an audit trail excerpt and machine-readable JSON, with a SHA-256 manifest of every file",
+		Description: "POST /api/v1/assessments/:id/export-package",
+	},
+	"handlers.(*AssessmentHandler).CreateAssessment": {
+		Summary: "Creates a new assessment",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*CreateAssessmentRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*AssessmentHandler).DeleteAssessment": {
+		Summary: "Deletes an assessment",
+	},
+	"handlers.(*AssessmentHandler).GetAssessment": {
+		Summary: "Retrieves a single assessment by ID",
+	},
+	"handlers.(*AssessmentHandler).GetAssessmentScope": {
+		Summary: "Returns every asset in scope: linked assets plus linked group members",
+	},
+	"handlers.(*AssessmentHandler).GetAssessmentStats": {
+		Summary: "Returns statistics about assessments",
+	},
+	"handlers.(*AssessmentHandler).LinkAsset": {
+		Summary: "Links an asset to an assessment",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*LinkRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssessmentHandler).LinkAssetGroup": {
+		Summary: "Adds an asset group to an assessment's scope",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*LinkRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssessmentHandler).LinkVulnerability": {
+		Summary: "Links a vulnerability to an assessment",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*LinkRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssessmentHandler).ListAssessments": {
+		Summary: "Retrieves a list of assessments with pagination",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+			{Name: "status", In: "query", Type: "string"},
+			{Name: "type", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*AssessmentHandler).UnlinkAsset": {
+		Summary: "Removes an asset from an assessment",
+	},
+	"handlers.(*AssessmentHandler).UnlinkAssetGroup": {
+		Summary: "Removes an asset group from an assessment's scope",
+	},
+	"handlers.(*AssessmentHandler).UnlinkVulnerability": {
+		Summary: "Removes a vulnerability from an assessment",
+	},
+	"handlers.(*AssessmentHandler).UpdateAssessment": {
+		Summary: "Updates an existing assessment",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*UpdateAssessmentRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssessmentReportHandler).DeleteReport": {
+		Summary:     "Soft deletes a report",
+		Description: "DELETE /api/v1/assessments/:id/reports/:reportId",
+	},
+	"handlers.(*AssessmentReportHandler).GenerateReport": {
+		Summary:     "Renders a report from the assessment's linked data as PDF or DOCX",
+		Description: "POST /api/v1/assessments/:id/reports/generate",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.GenerateReportRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*AssessmentReportHandler).GetAssessmentReports": {
+		Summary:     "Retrieves all reports for an assessment",
+		Description: "GET /api/v1/assessments/:id/reports?include_all_versions=false",
+		Params: []openapi.ParamAnnotation{
+			{Name: "include_all_versions", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*AssessmentReportHandler).GetReport": {
+		Summary:     "Retrieves a single report's metadata",
+		Description: "GET /api/v1/assessments/:id/reports/:reportId",
+	},
+	"handlers.(*AssessmentReportHandler).GetReportDownloadURL": {
+		Summary:     "Returns a time-limited download URL for the report file",
+		Description: "GET /api/v1/assessments/:id/reports/:reportId/download-url",
+	},
+	"handlers.(*AssessmentReportHandler).GetReportFile": {
+		Summary:     "Serves the PDF file for viewing/download",
+		Description: "GET /api/v1/assessments/:id/reports/:reportId/file",
+	},
+	"handlers.(*AssessmentReportHandler).GetReportSignature": {
+		Summary:     "Serves the detached signature of a generated report, to keep alongside the report and check with POST /api/v1/reports/verify",
+		Description: "GET /api/v1/assessments/:id/reports/:reportId/signature",
+	},
+	"handlers.(*AssessmentReportHandler).GetReportStats": {
+		Summary:     "Retrieves statistics about reports",
+		Description: "GET /api/v1/assessments/:id/reports/stats",
+	},
+	"handlers.(*AssessmentReportHandler).GetReportVersions": {
+		Summary:     "Retrieves version history for a report title",
+		Description: "GET /api/v1/assessments/:id/reports/:reportId/versions",
+	},
+	"handlers.(*AssessmentReportHandler).UploadReport": {
+		Summary:     "Handles PDF upload for an assessment",
+		Description: "POST /api/v1/assessments/:id/reports",
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*AssessmentRetestHandler).AssignRetester": {
+		Summary:     "Assigns the retester of a pending retest",
+		Description: "PUT /api/v1/assessments/:id/retests/:retestId/retester",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*AssignRetesterRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssessmentRetestHandler).CompleteRetest": {
+		Summary:     "Records the outcome and evidence of a pending retest",
+		Description: "POST /api/v1/assessments/:id/retests/:retestId/complete",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.CompleteRetestRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssessmentRetestHandler).GetRetest": {
+		Summary:     "Returns a single retest round",
+		Description: "GET /api/v1/assessments/:id/retests/:retestId",
+	},
+	"handlers.(*AssessmentRetestHandler).GetRetestSummary": {
+		Summary:     "Returns the current retest status of every finding of an assessment",
+		Description: "GET /api/v1/assessments/:id/retests/summary",
+	},
+	"handlers.(*AssessmentRetestHandler).ListRetests": {
+		Summary:     "Returns an assessment's retest rounds",
+		Description: "GET /api/v1/assessments/:id/retests?vulnerability_id=&status=READY_FOR_RETEST",
+		Params: []openapi.ParamAnnotation{
+			{Name: "vulnerability_id", In: "query", Type: "string"},
+			{Name: "status", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*AssessmentRetestHandler).RequestRetest": {
+		Summary:     "Marks an assessment finding ready for retest",
+		Description: "POST /api/v1/assessments/:id/retests",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.RequestRetestRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*AssetGroupHandler).AddAssetGroupMembers": {
+		Summary: "Add asset group members",
+		Tags:    []string{"Asset Groups"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Asset group ID"},
+			{Name: "request", In: "body", Required: true, Description: "Members", Model: reflect.TypeOf((*AddAssetGroupMembersRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.AssetGroup)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssetGroupHandler).CreateAssetGroup": {
+		Summary: "Create asset group",
+		Tags:    []string{"Asset Groups"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Asset group", Model: reflect.TypeOf((*services.AssetGroupRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201, Model: reflect.TypeOf((*models.AssetGroup)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssetGroupHandler).DeleteAssetGroup": {
+		Summary: "Delete asset group",
+		Tags:    []string{"Asset Groups"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Asset group ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]string)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssetGroupHandler).GetAssetGroup": {
+		Summary: "Get asset group",
+		Tags:    []string{"Asset Groups"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Asset group ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.AssetGroup)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssetGroupHandler).ListAssetGroupMembers": {
+		Summary: "List asset group members",
+		Tags:    []string{"Asset Groups"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Asset group ID"},
+			{Name: "page", In: "query", Type: "int", Description: "Page"},
+			{Name: "limit", In: "query", Type: "int", Description: "Page size (max 100)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.AffectedSystem)(nil)).Elem(), Array: true},
+		},
+	},
+	"handlers.(*AssetGroupHandler).ListAssetGroups": {
+		Summary: "List asset groups",
+		Tags:    []string{"Asset Groups"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "search", In: "query", Type: "string", Description: "Filter by name"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.AssetGroup)(nil)).Elem(), Array: true},
+		},
+	},
+	"handlers.(*AssetGroupHandler).RecomputeAssetGroup": {
+		Summary: "Recompute asset group membership",
+		Tags:    []string{"Asset Groups"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Asset group ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.AssetGroup)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssetGroupHandler).RemoveAssetGroupMember": {
+		Summary: "Remove asset group member",
+		Tags:    []string{"Asset Groups"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Asset group ID"},
+			{Name: "assetId", In: "path", Type: "string", Required: true, Description: "Asset ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.AssetGroup)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssetGroupHandler).UpdateAssetGroup": {
+		Summary: "Update asset group",
+		Tags:    []string{"Asset Groups"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Asset group ID"},
+			{Name: "request", In: "body", Required: true, Description: "Asset group", Model: reflect.TypeOf((*services.AssetGroupRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.AssetGroup)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssetHandler).AddAssetTags": {
+		Summary: "Handles POST /api/v1/assets/:id/tags",
+	},
+	"handlers.(*AssetHandler).CheckDuplicateAsset": {
+		Summary: "Handles POST /api/v1/assets/check-duplicate",
+	},
+	"handlers.(*AssetHandler).CreateAsset": {
+		Summary: "Handles POST /api/v1/assets",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*AssetCreateRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*AssetHandler).DecommissionAsset": {
+		Summary: "Handles POST /api/v1/assets/:id/decommission: retires the asset, closing or reassigning its open findings, and schedules its cleanup after the grace period",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.AssetDecommissionRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssetHandler).DeleteAsset": {
+		Summary: "Handles DELETE /api/v1/assets/:id",
+	},
+	"handlers.(*AssetHandler).GetAsset": {
+		Summary: "Handles GET /api/v1/assets/:id",
+		Params: []openapi.ParamAnnotation{
+			{Name: "include_vulnerabilities", In: "query", Type: "bool"},
+		},
+	},
+	"handlers.(*AssetHandler).GetAssetHistory": {
+		Summary: "Handles GET /api/v1/assets/:id/history",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*AssetHandler).GetAssetStats": {
+		Summary: "Handles GET /api/v1/assets/stats",
+	},
+	"handlers.(*AssetHandler).GetAssetVulnerabilities": {
+		Summary: "Handles GET /api/v1/assets/:id/vulnerabilities",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+			{Name: "severity", In: "query", Type: "string"},
+			{Name: "status", In: "query", Type: "string"},
+			{Name: "sort_by", In: "query", Type: "string"},
+			{Name: "sort_order", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*AssetHandler).ListAssets": {
+		Summary: "Handles GET /api/v1/assets",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+			{Name: "search", In: "query", Type: "string"},
+			{Name: "sort_by", In: "query", Type: "string"},
+			{Name: "sort_order", In: "query", Type: "string"},
+			{Name: "criticality", In: "query", Type: "string"},
+			{Name: "status", In: "query", Type: "string"},
+			{Name: "environment", In: "query", Type: "string"},
+			{Name: "system_type", In: "query", Type: "string"},
+			{Name: "owner_id", In: "query", Type: "string"},
+			{Name: "owner_team_id", In: "query", Type: "string"},
+			{Name: "agent_stale", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*AssetHandler).PreviewDecommission": {
+		Summary: "Handles GET /api/v1/assets/:id/decommission: the open findings a decommission would dispose of and the assignees it would notify",
+	},
+	"handlers.(*AssetHandler).RemoveAssetTag": {
+		Summary: "Handles DELETE /api/v1/assets/:id/tags/:tag",
+	},
+	"handlers.(*AssetHandler).UpdateAsset": {
+		Summary: "Handles PUT /api/v1/assets/:id",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*map[string]interface{})(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssetHandler).UpdateAssetStatus": {
+		Summary: "Handles PATCH /api/v1/assets/:id/status",
+	},
+	"handlers.(*AssignmentRuleHandler).CreateRule": {
+		Summary:     "Creates an assignment rule applied to vulnerabilities created or imported afterwards",
+		Description: "POST /api/v1/assignment-rules",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.AssignmentRuleRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*AssignmentRuleHandler).DeleteRule": {
+		Summary:     "Deletes an assignment rule; vulnerabilities it routed keep their assignment",
+		Description: "DELETE /api/v1/assignment-rules/:id",
+	},
+	"handlers.(*AssignmentRuleHandler).GetRule": {
+		Summary:     "Returns an assignment rule",
+		Description: "GET /api/v1/assignment-rules/:id",
+	},
+	"handlers.(*AssignmentRuleHandler).ListRules": {
+		Summary:     "Lists assignment rules in evaluation order",
+		Description: "GET /api/v1/assignment-rules?enabled=true",
+		Params: []openapi.ParamAnnotation{
+			{Name: "enabled", In: "query", Type: "bool"},
+		},
+	},
+	"handlers.(*AssignmentRuleHandler).SimulateRules": {
+		Summary:     "Dry-runs the enabled rules, or an unsaved rule, against existing vulnerabilities and reports where they would be routed without assigning them",
+		Description: "POST /api/v1/assignment-rules/simulate",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.AssignmentSimulationRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AssignmentRuleHandler).UpdateRule": {
+		Summary:     "Updates an assignment rule",
+		Description: "PUT /api/v1/assignment-rules/:id",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.AssignmentRuleRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AuditorShareHandler).GetPortal": {
+		Summary:     "Returns the assessment, reports, findings and evidence a share token grants access to",
+		Description: "GET /api/v1/auditor-portal",
+	},
+	"handlers.(*AuditorShareHandler).GetPortalEvidenceFile": {
+		Summary:     "Serves an evidence file of a finding of the shared assessment",
+		Description: "GET /api/v1/auditor-portal/evidence/:attachmentId/file",
+	},
+	"handlers.(*AuditorShareHandler).GetPortalReportFile": {
+		Summary:     "Serves a report file of the shared assessment",
+		Description: "GET /api/v1/auditor-portal/reports/:reportId/file",
+	},
+	"handlers.(*AuditorShareHandler).GetShareTokenAccessLog": {
+		Summary:     "Returns the requests made with a share token",
+		Description: "GET /api/v1/assessments/:id/share-tokens/:tokenId/access-log?page=1&limit=50",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*AuditorShareHandler).IssueShareToken": {
+		Summary:     "Issues a read-only share token for an external auditor. The token is only returned in this response",
+		Description: "POST /api/v1/assessments/:id/share-tokens",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.IssueAuditorTokenRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*AuditorShareHandler).ListShareTokens": {
+		Summary:     "Returns the share tokens of an assessment",
+		Description: "GET /api/v1/assessments/:id/share-tokens",
+	},
+	"handlers.(*AuditorShareHandler).RevokeShareToken": {
+		Summary:     "Revokes a share token",
+		Description: "DELETE /api/v1/assessments/:id/share-tokens/:tokenId",
+	},
+	"handlers.(*AuthHandler).ForgotPassword": {
+		Summary: "Handles password reset requests",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*ForgotPasswordRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AuthHandler).Login": {
+		Summary: "Handles user login",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*LoginRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AuthHandler).Logout": {
+		Summary: "Handles user logout",
+	},
+	"handlers.(*AuthHandler).Refresh": {
+		Summary:     "Exchanges a refresh token for a new access token and the next refresh token",
+		Description: "Each refresh token can be redeemed once; replaying one revokes its session.",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*RefreshRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AuthHandler).Register": {
+		Summary: "Handles user registration",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*RegisterRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*AuthHandler).ResetPassword": {
+		Summary: "Handles password reset with token",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*ResetPasswordRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*AuthHandler).VerifyEmail": {
+		Summary: "Handles email verification",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*VerifyEmailRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*BusinessServiceHandler).AddBusinessServiceAssets": {
+		Summary: "Add business service assets",
+		Tags:    []string{"Business Services"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Business service ID"},
+			{Name: "request", In: "body", Required: true, Description: "Assets", Model: reflect.TypeOf((*BusinessServiceAssetsRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.BusinessService)(nil)).Elem()},
+		},
+	},
+	"handlers.(*BusinessServiceHandler).CreateBusinessService": {
+		Summary: "Create business service",
+		Tags:    []string{"Business Services"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Business service", Model: reflect.TypeOf((*services.BusinessServiceRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201, Model: reflect.TypeOf((*models.BusinessService)(nil)).Elem()},
+		},
+	},
+	"handlers.(*BusinessServiceHandler).DeleteBusinessService": {
+		Summary: "Delete business service",
+		Tags:    []string{"Business Services"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Business service ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]string)(nil)).Elem()},
+		},
+	},
+	"handlers.(*BusinessServiceHandler).GetBusinessService": {
+		Summary: "Get business service",
+		Tags:    []string{"Business Services"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Business service ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.BusinessService)(nil)).Elem()},
+		},
+	},
+	"handlers.(*BusinessServiceHandler).GetBusinessServiceRisk": {
+		Summary: "Get business service risk",
+		Tags:    []string{"Business Services"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Business service ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.BusinessServiceRisk)(nil)).Elem()},
+		},
+	},
+	"handlers.(*BusinessServiceHandler).GetBusinessServicesRisk": {
+		Summary: "Get business service risk rollup",
+		Tags:    []string{"Business Services"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "limit", In: "query", Type: "int", Description: "Maximum services (0 for all)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.BusinessServiceRisk)(nil)).Elem(), Array: true},
+		},
+	},
+	"handlers.(*BusinessServiceHandler).ListBusinessServiceAssets": {
+		Summary: "List business service assets",
+		Tags:    []string{"Business Services"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Business service ID"},
+			{Name: "page", In: "query", Type: "int", Description: "Page"},
+			{Name: "limit", In: "query", Type: "int", Description: "Page size (max 100)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.AffectedSystem)(nil)).Elem(), Array: true},
+		},
+	},
+	"handlers.(*BusinessServiceHandler).ListBusinessServices": {
+		Summary: "List business services",
+		Tags:    []string{"Business Services"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "search", In: "query", Type: "string", Description: "Filter by name"},
+			{Name: "tier", In: "query", Type: "string", Description: "Filter by tier"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.BusinessService)(nil)).Elem(), Array: true},
+		},
+	},
+	"handlers.(*BusinessServiceHandler).RemoveBusinessServiceAsset": {
+		Summary: "Remove business service asset",
+		Tags:    []string{"Business Services"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Business service ID"},
+			{Name: "assetId", In: "path", Type: "string", Required: true, Description: "Asset ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.BusinessService)(nil)).Elem()},
+		},
+	},
+	"handlers.(*BusinessServiceHandler).UpdateBusinessService": {
+		Summary: "Update business service",
+		Tags:    []string{"Business Services"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Business service ID"},
+			{Name: "request", In: "body", Required: true, Description: "Business service", Model: reflect.TypeOf((*services.BusinessServiceRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.BusinessService)(nil)).Elem()},
+		},
+	},
+	"handlers.(*CWEHandler).GetWeakness": {
+		Summary: "Get CWE entry",
+		Tags:    []string{"Vulnerabilities"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "CWE ID, e.g. CWE-79 or 79"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*cwe.Weakness)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 404, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*CWEHandler).ListWeaknesses": {
+		Summary:     "List CWE entries",
+		Description: "Names and descriptions of the weakness types scanners and advisories commonly report",
+		Tags:        []string{"Vulnerabilities"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "search", In: "query", Type: "string", Description: "Only list entries whose ID or name contains this text"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*cwe.Weakness)(nil)).Elem(), Array: true},
+		},
+	},
+	"handlers.(*CloseApprovalHandler).ApproveCloseApproval": {
+		Summary:     "Approves a pending close request, closing the vulnerability. The approver must be a different user than the requester",
+		Description: "POST /api/v1/vulnerabilities/:id/close-approvals/:approval_id/approve",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*CloseApprovalNotesRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*CloseApprovalHandler).CancelCloseApproval": {
+		Summary:     "Withdraws a pending close request (requester only)",
+		Description: "POST /api/v1/vulnerabilities/:id/close-approvals/:approval_id/cancel",
+	},
+	"handlers.(*CloseApprovalHandler).GetCloseApproval": {
+		Summary:     "Returns a close request of a vulnerability",
+		Description: "GET /api/v1/vulnerabilities/:id/close-approvals/:approval_id",
+	},
+	"handlers.(*CloseApprovalHandler).ListCloseApprovals": {
+		Summary:     "Returns the close requests of a vulnerability, the audit trail of its closure",
+		Description: "GET /api/v1/vulnerabilities/:id/close-approvals",
+	},
+	"handlers.(*CloseApprovalHandler).RejectCloseApproval": {
+		Summary:     "Rejects a pending close request; review notes are required",
+		Description: "POST /api/v1/vulnerabilities/:id/close-approvals/:approval_id/reject",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*CloseApprovalNotesRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*CloseApprovalHandler).RequestCloseApproval": {
+		Summary:     "Asks a second user to approve closing a CRITICAL vulnerability",
+		Description: "POST /api/v1/vulnerabilities/:id/close-approvals",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*CloseApprovalNotesRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*CriticalityScoringHandler).GetAssetScore": {
+		Summary: "Get asset criticality score",
+		Tags:    []string{"Assets"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Asset ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.AssetCriticalityScore)(nil)).Elem()},
+		},
+	},
+	"handlers.(*CriticalityScoringHandler).GetProfile": {
+		Summary: "Get criticality scoring profile",
+		Tags:    []string{"Assets"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.CriticalityScoringProfile)(nil)).Elem()},
+		},
+	},
+	"handlers.(*CriticalityScoringHandler).UpdateProfile": {
+		Summary: "Update criticality scoring profile",
+		Tags:    []string{"Assets"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Scoring profile", Model: reflect.TypeOf((*services.CriticalityProfileRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.CriticalityScoringProfile)(nil)).Elem()},
+		},
+	},
+	"handlers.(*CustomDashboardHandler).CreateDashboard": {
+		Summary:     "Creates a dashboard",
+		Description: "POST /api/v1/dashboards",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.DashboardRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*CustomDashboardHandler).DeleteDashboard": {
+		Summary:     "Deletes a dashboard (owner only)",
+		Description: "DELETE /api/v1/dashboards/:id",
+	},
+	"handlers.(*CustomDashboardHandler).GetDashboard": {
+		Summary:     "Returns a dashboard definition",
+		Description: "GET /api/v1/dashboards/:id",
+	},
+	"handlers.(*CustomDashboardHandler).GetDashboardData": {
+		Summary:     "Get dashboard widget data",
+		Description: "Batched, cached datasets for every widget of the dashboard, in widget order",
+		Tags:        []string{"Dashboard"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Dashboard ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.DashboardData)(nil)).Elem()},
+			{Status: 404, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*CustomDashboardHandler).ListDashboards": {
+		Summary:     "Lists the caller's own and shared dashboards",
+		Description: "GET /api/v1/dashboards",
+	},
+	"handlers.(*CustomDashboardHandler).UpdateDashboard": {
+		Summary:     "Updates a dashboard (owner only)",
+		Description: "PUT /api/v1/dashboards/:id",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.DashboardRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*DashboardHandler).GetTrends": {
+		Summary:     "Get daily metric trends",
+		Description: "Daily vulnerability, finding, asset, and MTTR snapshots for the last N days (default 90, max 400)",
+		Tags:        []string{"Dashboard"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "days", In: "query", Type: "int", Description: "Number of days"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]interface{})(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*DashboardHandler).GetWallboard": {
+		Summary:     "Get wallboard data",
+		Description: "Top counts, newest criticals, and SLA breach ticker, cached for 30 seconds",
+		Tags:        []string{"Dashboard"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.WallboardData)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*DisclosureHandler).AcceptDisclosure": {
+		Summary:     "Creates a vulnerability in the registry from a disclosure",
+		Description: "POST /api/v1/disclosures/:id/accept",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*AcceptDisclosureBody)(nil)).Elem()},
+		},
+	},
+	"handlers.(*DisclosureHandler).GetDisclosure": {
+		Summary:     "Returns a disclosure",
+		Description: "GET /api/v1/disclosures/:id",
+	},
+	"handlers.(*DisclosureHandler).ListDisclosures": {
+		Summary:     "Lists the disclosure triage queue",
+		Description: "GET /api/v1/disclosures?status=NEW",
+		Params: []openapi.ParamAnnotation{
+			{Name: "status", In: "query", Type: "string"},
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*DisclosureHandler).MarkDuplicate": {
+		Summary:     "Closes a disclosure as a duplicate of an existing vulnerability",
+		Description: "POST /api/v1/disclosures/:id/duplicate",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*DuplicateDisclosureBody)(nil)).Elem()},
+		},
+	},
+	"handlers.(*DisclosureHandler).RejectDisclosure": {
+		Summary:     "Closes a disclosure that is not a vulnerability",
+		Description: "POST /api/v1/disclosures/:id/reject",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*RejectDisclosureBody)(nil)).Elem()},
+		},
+	},
+	"handlers.(*DisclosureHandler).SubmitDisclosure": {
+		Summary:     "Accepts a vulnerability report from an external reporter into the triage queue. The endpoint is unauthenticated, so it requires a solved captcha and only returns the reference of the report",
+		Description: "POST /api/v1/disclosures",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*SubmitDisclosureRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*DocsHandler).ServeOpenAPIJSON": {
+		Summary: "Serves the OpenAPI specification as JSON",
+	},
+	"handlers.(*DocsHandler).ServeOpenAPISpec": {
+		Summary: "Serves the OpenAPI specification generated from the registered routes",
+	},
+	"handlers.(*DocsHandler).ServeRedocUI": {
+		Summary: "Serves the Redoc documentation interface (alternative to Swagger UI)",
+	},
+	"handlers.(*DocsHandler).ServeSwaggerUI": {
+		Summary: "Serves the Swagger UI interface using CDN",
+	},
+	"handlers.(*EmailDeliveryHandler).GetDelivery": {
+		Summary:     "Returns a sent email with the delivery events reported for it",
+		Description: "GET /api/v1/admin/email/deliveries/:id",
+	},
+	"handlers.(*EmailDeliveryHandler).ListDeliveries": {
+		Summary:     "Lists sent emails with their latest delivery status, newest first",
+		Description: "GET /api/v1/admin/email/deliveries?status=BOUNCED&provider=sendgrid&category=verification&recipient=user@example.com",
+		Params: []openapi.ParamAnnotation{
+			{Name: "status", In: "query", Type: "string"},
+			{Name: "provider", In: "query", Type: "string"},
+			{Name: "category", In: "query", Type: "string"},
+			{Name: "recipient", In: "query", Type: "string"},
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*EmailDeliveryHandler).ListOutbox": {
+		Summary:     "Lists queued emails; DEAD ones failed every attempt and wait for a retry",
+		Description: "GET /api/v1/admin/email/outbox?status=DEAD",
+		Params: []openapi.ParamAnnotation{
+			{Name: "status", In: "query", Type: "string"},
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*EmailDeliveryHandler).ReceiveDeliveryEvents": {
+		Summary:     "Records delivery status events posted by an email provider (sendgrid, mailgun or ses). The webhook secret of the email_delivery setting is passed as the token query parameter or X-Webhook-Token header. SES events arrive as Amazon SNS notifications of the configuration set's event destination",
+		Description: "POST /api/v1/email-webhooks/:provider?token=...",
+		Params: []openapi.ParamAnnotation{
+			{Name: "token", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*EmailDeliveryHandler).RetryOutboxEntry": {
+		Summary:     "Queues a dead-lettered email again",
+		Description: "POST /api/v1/admin/email/outbox/:id/retry",
+	},
+	"handlers.(*EmailIngestionHandler).ListInboundEmails": {
+		Summary:     "Lists received report emails and what became of them",
+		Description: "GET /api/v1/settings/email-ingestion/messages?status=REJECTED",
+		Params: []openapi.ParamAnnotation{
+			{Name: "status", In: "query", Type: "string"},
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*EmailIngestionHandler).PollMailbox": {
+		Summary:     "Polls the configured mailbox now instead of waiting for the next scheduled poll",
+		Description: "POST /api/v1/settings/email-ingestion/poll",
+	},
+	"handlers.(*EmailIngestionHandler).ReceiveInboundEmail": {
+		Summary:     "Accepts an emailed vulnerability report from an inbound mail provider and creates a draft vulnerability. The webhook secret is passed as the token query parameter or",
+		Description: "X-Webhook-Token header. The message is read from the raw MIME body (message/rfc822), the \"email\" field of a SendGrid Inbound Parse post with raw delivery enabled, or an Amazon SNS delivery of an SES receipt notification. POST /api/v1/inbound-email?token=...",
+		Params: []openapi.ParamAnnotation{
+			{Name: "token", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*EscalationHandler).CreatePolicy": {
+		Summary:     "Creates an escalation policy applied by the escalation job",
+		Description: "POST /api/v1/escalation-policies",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.EscalationPolicyRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*EscalationHandler).DeletePolicy": {
+		Summary:     "Deletes an escalation policy, keeping the escalations it made",
+		Description: "DELETE /api/v1/escalation-policies/:id",
+	},
+	"handlers.(*EscalationHandler).EvaluatePolicies": {
+		Summary:     "Runs the escalation policies now instead of waiting for the hourly job",
+		Description: "POST /api/v1/escalation-policies/evaluate",
+	},
+	"handlers.(*EscalationHandler).GetPolicy": {
+		Summary:     "Returns an escalation policy",
+		Description: "GET /api/v1/escalation-policies/:id",
+	},
+	"handlers.(*EscalationHandler).ListPolicies": {
+		Summary:     "Lists escalation policies",
+		Description: "GET /api/v1/escalation-policies?enabled=true",
+		Params: []openapi.ParamAnnotation{
+			{Name: "enabled", In: "query", Type: "bool"},
+		},
+	},
+	"handlers.(*EscalationHandler).ListVulnerabilityEscalations": {
+		Summary:     "Returns the escalation history of a vulnerability",
+		Description: "GET /api/v1/vulnerabilities/:id/escalations",
+	},
+	"handlers.(*EscalationHandler).UpdatePolicy": {
+		Summary:     "Updates an escalation policy",
+		Description: "PUT /api/v1/escalation-policies/:id",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.EscalationPolicyRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*FaultInjectionHandler).ClearAllFaults": {
+		Summary: "Clear all injected faults",
+		Tags:    []string{"Admin"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]interface{})(nil)).Elem()},
+		},
+	},
+	"handlers.(*FaultInjectionHandler).ClearFault": {
+		Summary: "Clear an injected fault",
+		Tags:    []string{"Admin"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "point", In: "path", Type: "string", Required: true, Description: "Injection point"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]interface{})(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*FaultInjectionHandler).ListFaults": {
+		Summary:     "List injected faults",
+		Description: "Returns the active faults (chaos builds only)",
+		Tags:        []string{"Admin"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]interface{})(nil)).Elem()},
+		},
+	},
+	"handlers.(*FaultInjectionHandler).SetFault": {
+		Summary:     "Configure an injected fault",
+		Description: "Adds delay, error rate, or timeout behavior at an injection point (chaos builds only)",
+		Tags:        []string{"Admin"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "point", In: "path", Type: "string", Required: true, Description: "Injection point (database, integration, import, report)"},
+			{Name: "fault", In: "body", Required: true, Description: "Fault configuration", Model: reflect.TypeOf((*faultinject.Fault)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]interface{})(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*FindingAttachmentHandler).DeleteAttachment": {
+		Summary:     "Soft deletes an attachment",
+		Description: "DELETE /api/attachments/:id",
+	},
+	"handlers.(*FindingAttachmentHandler).DownloadAttachmentFile": {
+		Summary:     "Downloads the attachment file",
+		Description: "GET /api/attachments/:id/download",
+	},
+	"handlers.(*FindingAttachmentHandler).GetAttachment": {
+		Summary:     "Retrieves an attachment by ID",
+		Description: "GET /api/attachments/:id",
+	},
+	"handlers.(*FindingAttachmentHandler).GetAttachmentCustody": {
+		Summary:     "Returns the attachment's chain of custody (also for deleted attachments)",
+		Description: "GET /api/attachments/:id/custody",
+	},
+	"handlers.(*FindingAttachmentHandler).GetAttachmentDownloadURL": {
+		Summary:     "Returns a time-limited download URL for the attachment file",
+		Description: "GET /api/attachments/:id/download-url",
+	},
+	"handlers.(*FindingAttachmentHandler).GetAttachmentFile": {
+		Summary:     "Serves the attachment file",
+		Description: "GET /api/attachments/:id/file",
+		Params: []openapi.ParamAnnotation{
+			{Name: "thumbnail", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*FindingAttachmentHandler).GetAttachmentPolicy": {
+		Summary:     "Returns the allowed MIME types and maximum sizes per attachment category",
+		Description: "GET /api/attachments/policy",
+	},
+	"handlers.(*FindingAttachmentHandler).GetAttachmentStats": {
+		Summary:     "Returns statistics about attachments",
+		Description: "GET /api/findings/:id/attachments/stats",
+	},
+	"handlers.(*FindingAttachmentHandler).ListFindingAttachments": {
+		Summary:     "Lists all attachments for a finding",
+		Description: "GET /api/findings/:id/attachments",
+	},
+	"handlers.(*FindingAttachmentHandler).UploadAttachment": {
+		Summary:     "Uploads a file attachment for a finding",
+		Description: "POST /api/findings/:id/attachments",
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*FindingAttachmentHandler).VerifyAttachmentIntegrity": {
+		Summary:     "Re-hashes the stored file and compares it with the hash recorded at upload",
+		Description: "POST /api/attachments/:id/verify",
+	},
+	"handlers.(*FindingCommentHandler).CreateComment": {
+		Summary:     "Adds a comment to a finding; @mentions notify the mentioned users",
+		Description: "POST /api/v1/vulnerabilities/findings/:id/comments",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*CommentRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*FindingCommentHandler).DeleteComment": {
+		Summary:     "Deletes a comment on a finding",
+		Description: "DELETE /api/v1/vulnerabilities/findings/:id/comments/:comment_id",
+	},
+	"handlers.(*FindingCommentHandler).ListComments": {
+		Summary:     "Lists comments on a finding",
+		Description: "GET /api/v1/vulnerabilities/findings/:id/comments",
+	},
+	"handlers.(*FindingCommentHandler).UpdateComment": {
+		Summary:     "Edits a comment on a finding",
+		Description: "PUT /api/v1/vulnerabilities/findings/:id/comments/:comment_id",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*CommentRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*GraphQLHandler).Execute": {
+		Summary:     "Execute a GraphQL query",
+		Description: "Read-only queries over vulnerabilities, assets, findings and assessments with relationship traversal",
+		Tags:        []string{"GraphQL"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "query", In: "query", Type: "string"},
+			{Name: "operationName", In: "query", Type: "string"},
+			{Name: "variables", In: "query", Type: "string"},
+			{In: "body", Required: true, Model: reflect.TypeOf((*graphQLRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]interface{})(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*HealthHandler).Health": {
+		Summary:     "Health check endpoint",
+		Description: "Returns the health status of the API",
+		Tags:        []string{"health"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*HealthResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*HealthHandler).Live": {
+		Summary:     "Liveness check endpoint",
+		Description: "Returns whether the API is alive",
+		Tags:        []string{"health"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]interface{})(nil)).Elem()},
+		},
+	},
+	"handlers.(*HealthHandler).ReadinessDetails": {
+		Summary:     "Returns the readiness status with the result of each dependency check",
+		Description: "GET /api/v1/admin/health",
+	},
+	"handlers.(*HealthHandler).Ready": {
+		Summary:     "Readiness check endpoint",
+		Description: "Checks the database, schema migrations, attachment disk space, Redis, search, and integrations. Returns 503 only when a critical dependency is down; optional dependencies report \"degraded\". Per-dependency results are at GET /api/v1/admin/health.",
+		Tags:        []string{"health"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*ReadinessResponse)(nil)).Elem()},
+			{Status: 503, Model: reflect.TypeOf((*ReadinessResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ImportRuleHandler).CreateRule": {
+		Summary:     "Creates an import rule applied to hosts of future imports",
+		Description: "POST /api/v1/settings/import-rules",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.ImportRuleRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*ImportRuleHandler).DeleteRule": {
+		Summary:     "Deletes an import rule; assets already classified by it keep their values",
+		Description: "DELETE /api/v1/settings/import-rules/:id",
+	},
+	"handlers.(*ImportRuleHandler).GetRule": {
+		Summary:     "Returns an import rule",
+		Description: "GET /api/v1/settings/import-rules/:id",
+	},
+	"handlers.(*ImportRuleHandler).ListRules": {
+		Summary:     "Lists import rules in evaluation order",
+		Description: "GET /api/v1/settings/import-rules",
+	},
+	"handlers.(*ImportRuleHandler).TestRules": {
+		Summary:     "Shows how an import would classify a host with the enabled rules, or with the unsaved rule in the request",
+		Description: "POST /api/v1/settings/import-rules/test",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.ImportRuleTestRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ImportRuleHandler).UpdateRule": {
+		Summary:     "Updates an import rule",
+		Description: "PUT /api/v1/settings/import-rules/:id",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.ImportRuleRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*IntegrationConfigHandler).CreateConfig": {
+		Summary: "Creates a new integration configuration",
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*IntegrationConfigHandler).DeleteConfig": {
+		Summary: "Deletes an integration configuration",
+	},
+	"handlers.(*IntegrationConfigHandler).GetConfig": {
+		Summary: "Retrieves a specific integration configuration",
+	},
+	"handlers.(*IntegrationConfigHandler).GetSeverityMapping": {
+		Summary:     "Returns how the integration's scanner severities map to ours on import, with the default levels used where it sets none",
+		Description: "GET /api/v1/vulnerabilities/integrations/configs/:id/severity-mapping",
+	},
+	"handlers.(*IntegrationConfigHandler).ListConfigs": {
+		Summary: "Lists all integration configurations",
+		Params: []openapi.ParamAnnotation{
+			{Name: "type", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*IntegrationConfigHandler).TestConnection": {
+		Summary: "Tests the connection to the external API",
+	},
+	"handlers.(*IntegrationConfigHandler).UpdateConfig": {
+		Summary: "Updates an integration configuration",
+	},
+	"handlers.(*IntegrationConfigHandler).UpdateSeverityMapping": {
+		Summary:     "Replaces the integration's severity mapping; an empty mapping restores the defaults. It applies to later imports only",
+		Description: "PUT /api/v1/vulnerabilities/integrations/configs/:id/severity-mapping",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*models.SeverityMapping)(nil)).Elem()},
+		},
+	},
+	"handlers.(*NessusScanHandler).GetScanDetails": {
+		Summary:     "Retrieves detailed information about a specific scan, or about one of its runs (?history_id=), e.g. an earlier run of an agent scan",
+		Description: "GET /api/v1/vulnerabilities/integrations/nessus/:config_id/scans/:scan_id",
+		Params: []openapi.ParamAnnotation{
+			{Name: "history_id", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*NessusScanHandler).ImportAllScans": {
+		Summary:     "Imports all completed scans from Nessus",
+		Description: "POST /api/v1/vulnerabilities/integrations/nessus/:config_id/scans/import-all",
+	},
+	"handlers.(*NessusScanHandler).ImportMultipleScans": {
+		Summary:     "Imports multiple selected scans from Nessus",
+		Description: "POST /api/v1/vulnerabilities/integrations/nessus/:config_id/scans/import-multiple",
+	},
+	"handlers.(*NessusScanHandler).ImportSingleScan": {
+		Summary:     "Imports a single scan from Nessus",
+		Description: "POST /api/v1/vulnerabilities/integrations/nessus/:config_id/scans/:scan_id/import",
+	},
+	"handlers.(*NessusScanHandler).ImportTenableVulnerabilities": {
+		Summary:     "Imports open vulnerabilities from Tenable.io through its vulnerability export API, optionally only those seen since a point in time",
+		Description: "POST /api/v1/vulnerabilities/integrations/nessus/:config_id/vulns/import",
+	},
+	"handlers.(*NessusScanHandler).ListFolderScans": {
+		Summary:     "Retrieves the scans in a Nessus folder, optionally only those of one type (?type=agent)",
+		Description: "GET /api/v1/vulnerabilities/integrations/nessus/:config_id/folders/:folder_id/scans",
+	},
+	"handlers.(*NessusScanHandler).ListFolders": {
+		Summary:     "Retrieves the folders scans are organized in",
+		Description: "GET /api/v1/vulnerabilities/integrations/nessus/:config_id/folders",
+	},
+	"handlers.(*NessusScanHandler).ListScans": {
+		Summary:     "Retrieves all available scans from Nessus, optionally only those in one folder",
+		Description: "(?folder_id=) or of one type (?type=agent) GET /api/v1/vulnerabilities/integrations/nessus/:config_id/scans",
+		Params: []openapi.ParamAnnotation{
+			{Name: "folder_id", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*NessusScanHandler).PreviewScan": {
+		Summary:     "Previews what will be imported from a scan without saving",
+		Description: "GET /api/v1/vulnerabilities/integrations/nessus/:config_id/scans/:scan_id/preview",
+	},
+	"handlers.(*NessusScanHandler).ResumeImportJob": {
+		Summary:     "Continues a scan import interrupted by a server shutdown from its checkpoint, with a new export of the scan",
+		Description: "POST /api/v1/vulnerabilities/imports/:id/resume",
+	},
+	"handlers.(*NetworkRangeHandler).CreateRange": {
+		Summary:     "Creates a network range applied to hosts of future imports",
+		Description: "POST /api/v1/network-ranges",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.NetworkRangeRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*NetworkRangeHandler).DeleteRange": {
+		Summary:     "Deletes a network range; assets already classified by it keep their values",
+		Description: "DELETE /api/v1/network-ranges/:id",
+	},
+	"handlers.(*NetworkRangeHandler).GetRange": {
+		Summary:     "Returns a network range",
+		Description: "GET /api/v1/network-ranges/:id",
+	},
+	"handlers.(*NetworkRangeHandler).ListRanges": {
+		Summary:     "Lists network ranges",
+		Description: "GET /api/v1/network-ranges?search=",
+		Params: []openapi.ParamAnnotation{
+			{Name: "search", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*NetworkRangeHandler).LookupRange": {
+		Summary:     "Returns the most specific range containing an IP address",
+		Description: "GET /api/v1/network-ranges/lookup?ip=10.1.2.3",
+		Params: []openapi.ParamAnnotation{
+			{Name: "ip", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*NetworkRangeHandler).UpdateRange": {
+		Summary:     "Updates a network range",
+		Description: "PUT /api/v1/network-ranges/:id",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.NetworkRangeRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*NotificationHandler).ListNotifications": {
+		Summary:     "Lists the current user's notifications, newest first",
+		Description: "GET /api/v1/notifications?unread=true&page=1&limit=20",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+			{Name: "unread", In: "query", Type: "bool"},
+		},
+	},
+	"handlers.(*NotificationHandler).MarkAllRead": {
+		Summary:     "Marks all of the current user's notifications as read",
+		Description: "POST /api/v1/notifications/read-all",
+	},
+	"handlers.(*NotificationHandler).MarkRead": {
+		Summary:     "Marks a notification as read",
+		Description: "POST /api/v1/notifications/:id/read",
+	},
+	"handlers.(*OnboardingHandler).AcceptPolicy": {
+		Summary:     "Records the user accepting the current version of a policy",
+		Description: "POST /api/v1/onboarding/policies/:key/accept",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*AcceptPolicyRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*OnboardingHandler).GetOnboarding": {
+		Summary:     "Returns the policies the user has to accept and the setup steps left",
+		Description: "GET /api/v1/onboarding",
+	},
+	"handlers.(*OnboardingHandler).GetUserOnboarding": {
+		Summary:     "Returns a user's onboarding state for administrators",
+		Description: "GET /api/v1/admin/users/:id/onboarding",
+	},
+	"handlers.(*OnboardingHandler).ResetUserOnboarding": {
+		Summary:     "Makes a user accept the policies and go through the setup steps again",
+		Description: "?scope=policies or ?scope=steps limits the reset; by default both are reset. POST /api/v1/admin/users/:id/onboarding/reset",
+		Params: []openapi.ParamAnnotation{
+			{Name: "scope", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*OnboardingHandler).SkipOnboardingStep": {
+		Summary:     "Dismisses an optional setup step such as the two-factor prompt",
+		Description: "POST /api/v1/onboarding/steps/:step/skip",
+	},
+	"handlers.(*OrganizationHandler).AssignUser": {
+		Summary: "Assign user to organization",
+		Tags:    []string{"Organizations"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Organization ID"},
+			{Name: "userId", In: "path", Type: "string", Required: true, Description: "User ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]interface{})(nil)).Elem()},
+		},
+	},
+	"handlers.(*OrganizationHandler).CreateOrganization": {
+		Summary: "Create organization",
+		Tags:    []string{"Organizations"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Organization", Model: reflect.TypeOf((*services.CreateOrganizationRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201, Model: reflect.TypeOf((*models.Organization)(nil)).Elem()},
+		},
+	},
+	"handlers.(*OrganizationHandler).DeleteOrganization": {
+		Summary: "Delete organization",
+		Tags:    []string{"Organizations"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Organization ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]interface{})(nil)).Elem()},
+		},
+	},
+	"handlers.(*OrganizationHandler).GetCurrent": {
+		Summary: "Get current organization",
+		Tags:    []string{"Organizations"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Organization)(nil)).Elem()},
+		},
+	},
+	"handlers.(*OrganizationHandler).GetOrganization": {
+		Summary: "Get organization",
+		Tags:    []string{"Organizations"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Organization ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Organization)(nil)).Elem()},
+		},
+	},
+	"handlers.(*OrganizationHandler).ListOrganizations": {
+		Summary: "List organizations",
+		Tags:    []string{"Organizations"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Organization)(nil)).Elem(), Array: true},
+		},
+	},
+	"handlers.(*OrganizationHandler).UpdateOrganization": {
+		Summary: "Update organization",
+		Tags:    []string{"Organizations"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Organization ID"},
+			{Name: "request", In: "body", Required: true, Description: "Changes", Model: reflect.TypeOf((*services.UpdateOrganizationRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Organization)(nil)).Elem()},
+		},
+	},
+	"handlers.(*PolicyViolationHandler).CreateRule": {
+		Summary:     "Creates a policy rule checked by the policy evaluation job",
+		Description: "POST /api/v1/policy-rules",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.PolicyRuleRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*PolicyViolationHandler).DeleteRule": {
+		Summary:     "Deletes a policy rule and resolves its open violations",
+		Description: "DELETE /api/v1/policy-rules/:id",
+	},
+	"handlers.(*PolicyViolationHandler).EvaluateRules": {
+		Summary:     "Runs the policy evaluation immediately instead of waiting for the background job",
+		Description: "POST /api/v1/policy-rules/evaluate",
+	},
+	"handlers.(*PolicyViolationHandler).GetRule": {
+		Summary:     "Returns a policy rule",
+		Description: "GET /api/v1/policy-rules/:id",
+	},
+	"handlers.(*PolicyViolationHandler).GetViolation": {
+		Summary:     "Returns a policy violation",
+		Description: "GET /api/v1/policy-violations/:id",
+	},
+	"handlers.(*PolicyViolationHandler).ListRules": {
+		Summary:     "Lists policy rules",
+		Description: "GET /api/v1/policy-rules?enabled=true",
+		Params: []openapi.ParamAnnotation{
+			{Name: "enabled", In: "query", Type: "bool"},
+		},
+	},
+	"handlers.(*PolicyViolationHandler).ListViolations": {
+		Summary:     "Lists policy violations",
+		Description: "GET /api/v1/policy-violations?status=OPEN&rule_id=&vulnerability_id=&severity=&page=1&limit=50",
+		Params: []openapi.ParamAnnotation{
+			{Name: "status", In: "query", Type: "string"},
+			{Name: "severity", In: "query", Type: "string"},
+			{Name: "rule_id", In: "query", Type: "string"},
+			{Name: "vulnerability_id", In: "query", Type: "string"},
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*PolicyViolationHandler).UpdateRule": {
+		Summary:     "Updates a policy rule",
+		Description: "PUT /api/v1/policy-rules/:id",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.PolicyRuleRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ProfileHandler).ChangePassword": {
+		Summary: "Changes the authenticated user's password",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*ChangePasswordRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ProfileHandler).GetActiveSessions": {
+		Summary: "Retrieves all active sessions for the authenticated user",
+	},
+	"handlers.(*ProfileHandler).GetPreferences": {
+		Summary: "Retrieves the authenticated user's preferences",
+	},
+	"handlers.(*ProfileHandler).GetProfile": {
+		Summary: "Retrieves the authenticated user's profile",
+	},
+	"handlers.(*ProfileHandler).GetSecurityOverview": {
+		Summary:     "Returns the user's recent logins, API keys, 2FA status and recent sensitive account changes so they can audit their own account",
+		Description: "GET /api/v1/profile/security",
+	},
+	"handlers.(*ProfileHandler).RevokeAllSessions": {
+		Summary: "Revokes all sessions except the current one. Their refresh chains are revoked too, so the other devices are signed out rather than silently refreshing",
+	},
+	"handlers.(*ProfileHandler).RevokeSession": {
+		Summary: "Revokes a specific session",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*RevokeSessionRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ProfileHandler).UpdatePreferences": {
+		Summary: "Updates the authenticated user's preferences",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.UpdatePreferencesRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ProfileHandler).UpdateProfile": {
+		Summary: "Updates the authenticated user's profile",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*UpdateProfileRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).ComparePeriods": {
+		Summary:     "Compare two periods",
+		Description: "New and resolved vulnerabilities, risk score and backlog of two months side by side, with the change of each metric and the largest regressions",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "from", In: "query", Type: "string", Required: true, Description: "Earlier month (YYYY-MM)"},
+			{Name: "to", In: "query", Type: "string", Required: true, Description: "Later month (YYYY-MM); the current month covers up to today"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.PeriodComparison)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).ExportAnalystReportCSV": {
+		Summary:     "Export analyst report as CSV",
+		Description: "Export a detailed analyst report in CSV format",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "start_date", In: "query", Type: "string", Description: "Start date (YYYY-MM-DD)", Default: "30 days ago"},
+			{Name: "end_date", In: "query", Type: "string", Description: "End date (YYYY-MM-DD)", Default: "today"},
+			{Name: "timezone", In: "query", Type: "string", Description: "IANA timezone the dates are days in (defaults to the user's preferred timezone)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+			{Name: "delimiter", In: "query", Type: "string", Description: "Field delimiter: comma, semicolon, tab, pipe"},
+			{Name: "encoding", In: "query", Type: "string", Description: "Character encoding: utf-8, iso-8859-1, iso-8859-15, windows-1252"},
+			{Name: "bom", In: "query", Type: "bool", Description: "Prefix UTF-8 output with a byte order mark"},
+			{Name: "template_id", In: "query", Type: "string", Description: "Report template to brand the export with (defaults to the default template)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, File: true},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).ExportAuditReportCSV": {
+		Summary:     "Export audit report as CSV",
+		Description: "Export a compliance and audit trail report in CSV format",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "start_date", In: "query", Type: "string", Description: "Start date (YYYY-MM-DD)", Default: "30 days ago"},
+			{Name: "end_date", In: "query", Type: "string", Description: "End date (YYYY-MM-DD)", Default: "today"},
+			{Name: "timezone", In: "query", Type: "string", Description: "IANA timezone the dates are days in (defaults to the user's preferred timezone)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+			{Name: "delimiter", In: "query", Type: "string", Description: "Field delimiter: comma, semicolon, tab, pipe"},
+			{Name: "encoding", In: "query", Type: "string", Description: "Character encoding: utf-8, iso-8859-1, iso-8859-15, windows-1252"},
+			{Name: "bom", In: "query", Type: "bool", Description: "Prefix UTF-8 output with a byte order mark"},
+			{Name: "template_id", In: "query", Type: "string", Description: "Report template to brand the export with (defaults to the default template)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, File: true},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).ExportExecutiveReportCSV": {
+		Summary:     "Export executive report as CSV",
+		Description: "Export a high-level executive report in CSV format",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "start_date", In: "query", Type: "string", Description: "Start date (YYYY-MM-DD)", Default: "30 days ago"},
+			{Name: "end_date", In: "query", Type: "string", Description: "End date (YYYY-MM-DD)", Default: "today"},
+			{Name: "timezone", In: "query", Type: "string", Description: "IANA timezone the dates are days in (defaults to the user's preferred timezone)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+			{Name: "delimiter", In: "query", Type: "string", Description: "Field delimiter: comma, semicolon, tab, pipe"},
+			{Name: "encoding", In: "query", Type: "string", Description: "Character encoding: utf-8, iso-8859-1, iso-8859-15, windows-1252"},
+			{Name: "bom", In: "query", Type: "bool", Description: "Prefix UTF-8 output with a byte order mark"},
+			{Name: "template_id", In: "query", Type: "string", Description: "Report template to brand the export with (defaults to the default template)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, File: true},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).GetAgingAnalytics": {
+		Summary:     "Get open vulnerability aging",
+		Description: "Open vulnerabilities grouped into age buckets by severity",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "timezone", In: "query", Type: "string", Description: "IANA timezone the dates are days in (defaults to the user's preferred timezone)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.AgingAnalytics)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).GetAnalystReport": {
+		Summary:     "Get analyst report",
+		Description: "Generate a detailed technical report for security analysts",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "start_date", In: "query", Type: "string", Description: "Start date (YYYY-MM-DD)", Default: "30 days ago"},
+			{Name: "end_date", In: "query", Type: "string", Description: "End date (YYYY-MM-DD)", Default: "today"},
+			{Name: "timezone", In: "query", Type: "string", Description: "IANA timezone the dates are days in (defaults to the user's preferred timezone)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.AnalystReportData)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).GetAuditReport": {
+		Summary:     "Get audit report",
+		Description: "Generate a compliance and audit trail report",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "start_date", In: "query", Type: "string", Description: "Start date (YYYY-MM-DD)", Default: "30 days ago"},
+			{Name: "end_date", In: "query", Type: "string", Description: "End date (YYYY-MM-DD)", Default: "today"},
+			{Name: "timezone", In: "query", Type: "string", Description: "IANA timezone the dates are days in (defaults to the user's preferred timezone)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.AuditReportData)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).GetBurnDownAnalytics": {
+		Summary:     "Get vulnerability burn-down",
+		Description: "Opened, closed and reopened vulnerabilities and the open backlog per day (per week for periods over 90 days)",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "start_date", In: "query", Type: "string", Description: "Start date (YYYY-MM-DD)", Default: "30 days ago"},
+			{Name: "end_date", In: "query", Type: "string", Description: "End date (YYYY-MM-DD)", Default: "today"},
+			{Name: "timezone", In: "query", Type: "string", Description: "IANA timezone the dates are days in (defaults to the user's preferred timezone)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.BurnDownAnalytics)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).GetCoverageHeatmap": {
+		Summary:     "Get OWASP Top 10 and ATT&CK coverage heatmap",
+		Description: "Vulnerabilities created in the period per OWASP Top 10 category and per ATT&CK technique grouped by tactic, by severity, with the share of vulnerabilities classified",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "start_date", In: "query", Type: "string", Description: "Start date (YYYY-MM-DD)", Default: "30 days ago"},
+			{Name: "end_date", In: "query", Type: "string", Description: "End date (YYYY-MM-DD)", Default: "today"},
+			{Name: "timezone", In: "query", Type: "string", Description: "IANA timezone the dates are days in (defaults to the user's preferred timezone)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.CoverageHeatmap)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).GetExecutiveReport": {
+		Summary:     "Get executive report",
+		Description: "Generate a high-level report for executives with key metrics",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "start_date", In: "query", Type: "string", Description: "Start date (YYYY-MM-DD)", Default: "30 days ago"},
+			{Name: "end_date", In: "query", Type: "string", Description: "End date (YYYY-MM-DD)", Default: "today"},
+			{Name: "timezone", In: "query", Type: "string", Description: "IANA timezone the dates are days in (defaults to the user's preferred timezone)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.ExecutiveReportData)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).GetMTTRAnalytics": {
+		Summary:     "Get mean time to remediate",
+		Description: "Mean and median days to remediate, overall and by severity, owning team and month",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "start_date", In: "query", Type: "string", Description: "Start date (YYYY-MM-DD)", Default: "30 days ago"},
+			{Name: "end_date", In: "query", Type: "string", Description: "End date (YYYY-MM-DD)", Default: "today"},
+			{Name: "timezone", In: "query", Type: "string", Description: "IANA timezone the dates are days in (defaults to the user's preferred timezone)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.MTTRAnalytics)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).GetRemediationAnalytics": {
+		Summary:     "Get remediation analytics",
+		Description: "Mean time to remediate by severity, team and month, aging buckets of open vulnerabilities, and burn-down data",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "start_date", In: "query", Type: "string", Description: "Start date (YYYY-MM-DD)", Default: "30 days ago"},
+			{Name: "end_date", In: "query", Type: "string", Description: "End date (YYYY-MM-DD)", Default: "today"},
+			{Name: "timezone", In: "query", Type: "string", Description: "IANA timezone the dates are days in (defaults to the user's preferred timezone)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.RemediationAnalytics)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).GetRemediationForecast": {
+		Summary:     "Get remediation forecast",
+		Description: "Projects the open backlog per severity from the daily rates vulnerabilities were opened and resolved over the lookback period, with the date each severity is projected to be cleared",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "lookback_days", In: "query", Type: "int", Description: "Days of history the remediation velocity is measured over (1-365)"},
+			{Name: "horizon_days", In: "query", Type: "int", Description: "Days to project ahead (1-730)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.RemediationForecast)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportHandler).GetTopAssetAnalytics": {
+		Summary:     "Get top risky assets",
+		Description: "Assets ranked by open risk weighted by severity and criticality, assets whose fixed findings recurred in the period, and the time asset owners and owner teams took to fix findings",
+		Tags:        []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "start_date", In: "query", Type: "string", Description: "Start date (YYYY-MM-DD)", Default: "30 days ago"},
+			{Name: "end_date", In: "query", Type: "string", Description: "End date (YYYY-MM-DD)", Default: "today"},
+			{Name: "timezone", In: "query", Type: "string", Description: "IANA timezone the dates are days in (defaults to the user's preferred timezone)"},
+			{Name: "limit", In: "query", Type: "int", Description: "Assets per ranking (1-100)"},
+			{Name: "asset_group", In: "query", Type: "string", Description: "Only cover assets in this asset group (UUID)"},
+			{Name: "environment", In: "query", Type: "string", Description: "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"},
+			{Name: "tags", In: "query", Type: "string", Description: "Only cover assets carrying all of these comma-separated tags"},
+			{Name: "owner_team", In: "query", Type: "string", Description: "Only cover assets owned by this team (UUID)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.TopAssetAnalytics)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportSignatureHandler).GetSigningKey": {
+		Summary:     "Returns the public key reports are signed with, for verification offline",
+		Description: "GET /api/v1/reports/signing-key",
+	},
+	"handlers.(*ReportSignatureHandler).VerifyReport": {
+		Summary:     "Checks a report against its detached signature: the .sig document served with generated reports, or the X-Report-Signature header of a CSV export",
+		Description: "POST /api/v1/reports/verify (multipart: file, signature as a file or form field)",
+	},
+	"handlers.(*ReportSummaryHandler).ListAssessmentSummaries": {
+		Summary: "List assessment summaries",
+		Tags:    []string{"Assessments"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Assessment ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.ReportSummary)(nil)).Elem(), Array: true},
+		},
+	},
+	"handlers.(*ReportSummaryHandler).SummarizeAssessment": {
+		Summary: "Summarize assessment",
+		Tags:    []string{"Assessments"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Assessment ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201, Model: reflect.TypeOf((*models.ReportSummary)(nil)).Elem()},
+			{Status: 404, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 502, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 503, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportSummaryHandler).SummarizeExecutiveReport": {
+		Summary: "Summarize executive report",
+		Tags:    []string{"Reports"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "start_date", In: "query", Type: "string", Description: "Start date (YYYY-MM-DD)", Default: "30 days ago"},
+			{Name: "end_date", In: "query", Type: "string", Description: "End date (YYYY-MM-DD)", Default: "today"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201, Model: reflect.TypeOf((*models.ReportSummary)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 502, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 503, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportTemplateHandler).CreateTemplate": {
+		Summary:     "Creates a report template; making it the default applies it to reports that don't name a template",
+		Description: "POST /api/v1/admin/report-templates",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.ReportTemplateRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*ReportTemplateHandler).DeleteLogo": {
+		Summary:     "Removes a report template's logo",
+		Description: "DELETE /api/v1/admin/report-templates/:id/logo",
+	},
+	"handlers.(*ReportTemplateHandler).DeleteTemplate": {
+		Summary:     "Deletes a report template",
+		Description: "DELETE /api/v1/admin/report-templates/:id",
+	},
+	"handlers.(*ReportTemplateHandler).GetLogo": {
+		Summary:     "Returns a report template's logo image",
+		Description: "GET /api/v1/reports/templates/:id/logo",
+	},
+	"handlers.(*ReportTemplateHandler).GetTemplate": {
+		Summary:     "Returns a report template",
+		Description: "GET /api/v1/reports/templates/:id",
+	},
+	"handlers.(*ReportTemplateHandler).ListTemplates": {
+		Summary:     "Lists report templates, the default first",
+		Description: "GET /api/v1/reports/templates",
+	},
+	"handlers.(*ReportTemplateHandler).UpdateTemplate": {
+		Summary:     "Updates a report template",
+		Description: "PUT /api/v1/admin/report-templates/:id",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.ReportTemplateRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*ReportTemplateHandler).UploadLogo": {
+		Summary:     "Sets a report template's logo (PNG, JPEG or GIF, at most 1 MB) from the \"logo\" multipart field or the raw request body",
+		Description: "PUT /api/v1/admin/report-templates/:id/logo",
+	},
+	"handlers.(*RiskAcceptanceHandler).ApproveRiskAcceptance": {
+		Summary:     "Approves a pending request",
+		Description: "POST /api/v1/risk-acceptances/:id/approve",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*ReviewRiskAcceptanceRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*RiskAcceptanceHandler).CancelRiskAcceptance": {
+		Summary:     "Withdraws the caller's own pending request",
+		Description: "POST /api/v1/risk-acceptances/:id/cancel",
+	},
+	"handlers.(*RiskAcceptanceHandler).CreateRiskAcceptance": {
+		Summary:     "Submits a risk acceptance request for review",
+		Description: "POST /api/v1/risk-acceptances",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.CreateRiskAcceptanceRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*RiskAcceptanceHandler).GetRiskAcceptance": {
+		Summary:     "Returns a single risk acceptance",
+		Description: "GET /api/v1/risk-acceptances/:id",
+	},
+	"handlers.(*RiskAcceptanceHandler).ListRiskAcceptances": {
+		Summary:     "Returns the risk acceptance review queue",
+		Description: "GET /api/v1/risk-acceptances?status=PENDING&finding_id=&mine=true",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+			{Name: "status", In: "query", Type: "string"},
+			{Name: "finding_id", In: "query", Type: "string"},
+			{Name: "mine", In: "query", Type: "bool"},
+		},
+	},
+	"handlers.(*RiskAcceptanceHandler).RejectRiskAcceptance": {
+		Summary:     "Rejects a pending request",
+		Description: "POST /api/v1/risk-acceptances/:id/reject",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*ReviewRiskAcceptanceRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*RoleHandler).CreateRole": {
+		Summary: "Creates a new role",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*CreateRoleRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*RoleHandler).DeleteRole": {
+		Summary: "Deletes a role",
+	},
+	"handlers.(*RoleHandler).GetEffectivePermissions": {
+		Summary: "Returns a role's permissions after inheritance and deny rules",
+	},
+	"handlers.(*RoleHandler).GetRole": {
+		Summary: "Retrieves a specific role by ID",
+	},
+	"handlers.(*RoleHandler).ListPermissionCatalog": {
+		Summary: "Returns every resource:action pair a role can grant or deny",
+	},
+	"handlers.(*RoleHandler).ListRoles": {
+		Summary: "Retrieves all roles",
+	},
+	"handlers.(*RoleHandler).UpdateRole": {
+		Summary: "Updates an existing role",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*UpdateRoleRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*SBOMHandler).ExportVEX": {
+		Summary: "Export asset VEX",
+		Tags:    []string{"Assets"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Asset ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200},
+			{Status: 404, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*SBOMHandler).ListSBOMComponents": {
+		Summary: "List asset SBOM components",
+		Tags:    []string{"Assets"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Asset ID"},
+			{Name: "search", In: "query", Type: "string", Description: "Filter by component name or package URL"},
+			{Name: "vulnerable", In: "query", Type: "bool", Description: "Only components affected by a known vulnerability"},
+			{Name: "page", In: "query", Type: "int", Description: "Page"},
+			{Name: "limit", In: "query", Type: "int", Description: "Page size (max 500)"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.AssetSBOMComponent)(nil)).Elem(), Array: true},
+		},
+	},
+	"handlers.(*SBOMHandler).UploadSBOM": {
+		Summary: "Upload asset SBOM",
+		Tags:    []string{"Assets"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Asset ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.SBOMImportResult)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 404, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*SavedViewHandler).CreateView": {
+		Summary:     "Creates a saved view",
+		Description: "POST /api/v1/saved-views",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.SavedViewRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*SavedViewHandler).DeleteView": {
+		Summary:     "Deletes a saved view (owner only)",
+		Description: "DELETE /api/v1/saved-views/:id",
+	},
+	"handlers.(*SavedViewHandler).GetView": {
+		Summary:     "Returns a saved view",
+		Description: "GET /api/v1/saved-views/:id",
+	},
+	"handlers.(*SavedViewHandler).ListViews": {
+		Summary:     "Lists the caller's own and shared saved views",
+		Description: "GET /api/v1/saved-views?resource_type=vulnerability",
+		Params: []openapi.ParamAnnotation{
+			{Name: "resource_type", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*SavedViewHandler).UpdateView": {
+		Summary:     "Updates a saved view (owner only)",
+		Description: "PUT /api/v1/saved-views/:id",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.SavedViewRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*SearchIndexHandler).GetSearchStatus": {
+		Summary:     "Search backend status",
+		Description: "Returns the configured search backend, cluster reachability, and outbox backlog",
+		Tags:        []string{"Admin"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]interface{})(nil)).Elem()},
+		},
+	},
+	"handlers.(*SearchIndexHandler).ReindexSearch": {
+		Summary:     "Rebuild search index",
+		Description: "Enqueues a full re-index of the OpenSearch backend",
+		Tags:        []string{"Admin"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 202, Model: reflect.TypeOf((*map[string]interface{})(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*SuppressionRuleHandler).CreateRule": {
+		Summary:     "Creates a suppression rule applied to future imports",
+		Description: "POST /api/v1/suppression-rules",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.SuppressionRuleRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*SuppressionRuleHandler).DeleteRule": {
+		Summary:     "Deletes a suppression rule",
+		Description: "DELETE /api/v1/suppression-rules/:id",
+	},
+	"handlers.(*SuppressionRuleHandler).GetRule": {
+		Summary:     "Returns a suppression rule",
+		Description: "GET /api/v1/suppression-rules/:id",
+	},
+	"handlers.(*SuppressionRuleHandler).ListRuleHits": {
+		Summary:     "Returns the audit log of findings suppressed by a rule",
+		Description: "GET /api/v1/suppression-rules/:id/hits",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+		},
+	},
+	"handlers.(*SuppressionRuleHandler).ListRules": {
+		Summary:     "Lists suppression rules",
+		Description: "GET /api/v1/suppression-rules?enabled=true",
+		Params: []openapi.ParamAnnotation{
+			{Name: "enabled", In: "query", Type: "bool"},
+		},
+	},
+	"handlers.(*SuppressionRuleHandler).UpdateRule": {
+		Summary:     "Updates a suppression rule",
+		Description: "PUT /api/v1/suppression-rules/:id",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.SuppressionRuleRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*SystemSettingsHandler).GetAllSettings": {
+		Summary:     "Returns all system settings",
+		Description: "GET /api/v1/settings",
+	},
+	"handlers.(*SystemSettingsHandler).GetMCPStatus": {
+		Summary:     "Returns the current MCP server status",
+		Description: "GET /api/v1/settings/mcp/status",
+	},
+	"handlers.(*SystemSettingsHandler).GetSIEMStatus": {
+		Summary:     "Returns whether events are forwarded to a SIEM and the forwarder's delivery counters",
+		Description: "GET /api/v1/settings/siem/status",
+	},
+	"handlers.(*SystemSettingsHandler).GetSetting": {
+		Summary:     "Returns a specific system setting",
+		Description: "GET /api/v1/settings/:key",
+	},
+	"handlers.(*SystemSettingsHandler).ToggleMCPServer": {
+		Summary:     "Enables or disables the MCP server",
+		Description: "POST /api/v1/settings/mcp/toggle",
+	},
+	"handlers.(*SystemSettingsHandler).UpdateSetting": {
+		Summary:     "Updates a system setting",
+		Description: "PUT /api/v1/settings/:key",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*UpdateSettingRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*TaxonomyHandler).GetTaxonomy": {
+		Summary:     "Get classification taxonomy",
+		Description: "The OWASP Top 10 (2021) categories and the ATT&CK techniques and tactics vulnerabilities are mapped to",
+		Tags:        []string{"Vulnerabilities"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*TaxonomyResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*TeamHandler).AddMember": {
+		Summary: "Add team member",
+		Tags:    []string{"Teams"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Team ID"},
+			{Name: "request", In: "body", Required: true, Description: "Member", Model: reflect.TypeOf((*AddTeamMemberRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Team)(nil)).Elem()},
+		},
+	},
+	"handlers.(*TeamHandler).CreateTeam": {
+		Summary: "Create team",
+		Tags:    []string{"Teams"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Team", Model: reflect.TypeOf((*services.CreateTeamRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201, Model: reflect.TypeOf((*models.Team)(nil)).Elem()},
+		},
+	},
+	"handlers.(*TeamHandler).DeleteTeam": {
+		Summary: "Delete team",
+		Tags:    []string{"Teams"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Team ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*map[string]string)(nil)).Elem()},
+		},
+	},
+	"handlers.(*TeamHandler).GetTeam": {
+		Summary: "Get team",
+		Tags:    []string{"Teams"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Team ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Team)(nil)).Elem()},
+		},
+	},
+	"handlers.(*TeamHandler).ListTeams": {
+		Summary: "List teams",
+		Tags:    []string{"Teams"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "search", In: "query", Type: "string", Description: "Filter by name"},
+			{Name: "member_id", In: "query", Type: "string", Description: "Only teams this user belongs to"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Team)(nil)).Elem(), Array: true},
+		},
+	},
+	"handlers.(*TeamHandler).RemoveMember": {
+		Summary: "Remove team member",
+		Tags:    []string{"Teams"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Team ID"},
+			{Name: "userId", In: "path", Type: "string", Required: true, Description: "User ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Team)(nil)).Elem()},
+		},
+	},
+	"handlers.(*TeamHandler).UpdateTeam": {
+		Summary: "Update team",
+		Tags:    []string{"Teams"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Team ID"},
+			{Name: "request", In: "body", Required: true, Description: "Team", Model: reflect.TypeOf((*services.UpdateTeamRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Team)(nil)).Elem()},
+		},
+	},
+	"handlers.(*TwoFactorHandler).DisableTwoFactor": {
+		Summary:     "Disable Two-Factor Authentication",
+		Description: "Disables 2FA after verifying password and TOTP code (or a backup code)",
+		Tags:        []string{"2FA"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Password and TOTP code", Model: reflect.TypeOf((*DisableTwoFactorRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+			{Status: 401, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+		},
+	},
+	"handlers.(*TwoFactorHandler).EnableTwoFactor": {
+		Summary:     "Enable Two-Factor Authentication",
+		Description: "Initiates 2FA setup by generating secret and QR code",
+		Tags:        []string{"2FA"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Issuer name (e.g., 'MyApp')", Model: reflect.TypeOf((*EnableTwoFactorRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.Enable2FAResponse)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+			{Status: 401, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+		},
+	},
+	"handlers.(*TwoFactorHandler).GetBackupCodesStatus": {
+		Summary: "Get 2FA backup code status",
+		Tags:    []string{"2FA"},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+			{Status: 401, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+		},
+	},
+	"handlers.(*TwoFactorHandler).RegenerateBackupCodes": {
+		Summary:     "Regenerate 2FA backup codes",
+		Description: "Issues ten new single-use backup codes after verifying password and TOTP code; previous codes stop working",
+		Tags:        []string{"2FA"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Password and TOTP code", Model: reflect.TypeOf((*RegenerateBackupCodesRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+			{Status: 401, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+		},
+	},
+	"handlers.(*TwoFactorHandler).VerifyTwoFactor": {
+		Summary:     "Verify and Enable Two-Factor Authentication",
+		Description: "Verifies the TOTP code and completes 2FA setup",
+		Tags:        []string{"2FA"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "TOTP code from authenticator app", Model: reflect.TypeOf((*VerifyTwoFactorRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+			{Status: 401, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+			{Status: 500, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityAttachmentHandler).DeleteAttachment": {
+		Summary:     "Soft deletes an attachment",
+		Description: "DELETE /api/vulnerability-attachments/:id",
+	},
+	"handlers.(*VulnerabilityAttachmentHandler).DownloadAttachmentFile": {
+		Summary:     "Downloads the attachment file",
+		Description: "GET /api/vulnerability-attachments/:id/download",
+	},
+	"handlers.(*VulnerabilityAttachmentHandler).GetAttachment": {
+		Summary:     "Retrieves an attachment by ID",
+		Description: "GET /api/vulnerability-attachments/:id",
+	},
+	"handlers.(*VulnerabilityAttachmentHandler).GetAttachmentDownloadURL": {
+		Summary:     "Returns a time-limited download URL for the attachment file",
+		Description: "GET /api/vulnerability-attachments/:id/download-url",
+	},
+	"handlers.(*VulnerabilityAttachmentHandler).GetAttachmentFile": {
+		Summary:     "Serves the attachment file",
+		Description: "GET /api/vulnerability-attachments/:id/file",
+		Params: []openapi.ParamAnnotation{
+			{Name: "thumbnail", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*VulnerabilityAttachmentHandler).GetAttachmentStats": {
+		Summary:     "Returns statistics about attachments",
+		Description: "GET /api/vulnerabilities/:id/attachments/stats",
+	},
+	"handlers.(*VulnerabilityAttachmentHandler).ListVulnerabilityAttachments": {
+		Summary:     "Lists all attachments for a vulnerability",
+		Description: "GET /api/vulnerabilities/:id/attachments",
+	},
+	"handlers.(*VulnerabilityAttachmentHandler).UploadAttachment": {
+		Summary:     "Uploads a file attachment for a vulnerability",
+		Description: "POST /api/vulnerabilities/:id/attachments",
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*VulnerabilityCommentHandler).CreateComment": {
+		Summary:     "Adds a comment to a vulnerability",
+		Description: "POST /api/v1/vulnerabilities/:id/comments",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*CommentRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201},
+		},
+	},
+	"handlers.(*VulnerabilityCommentHandler).DeleteComment": {
+		Summary:     "Deletes a comment on a vulnerability",
+		Description: "DELETE /api/v1/vulnerabilities/:id/comments/:comment_id",
+	},
+	"handlers.(*VulnerabilityCommentHandler).GetActivity": {
+		Summary:     "Returns the combined activity timeline for a vulnerability",
+		Description: "GET /api/v1/vulnerabilities/:id/activity",
+	},
+	"handlers.(*VulnerabilityCommentHandler).ListComments": {
+		Summary:     "Lists comments on a vulnerability",
+		Description: "GET /api/v1/vulnerabilities/:id/comments",
+	},
+	"handlers.(*VulnerabilityCommentHandler).UpdateComment": {
+		Summary:     "Edits a comment on a vulnerability",
+		Description: "PUT /api/v1/vulnerabilities/:id/comments/:comment_id",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*CommentRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityFindingHandler).AcceptRisk": {
+		Summary: "Accepts risk for a finding",
+	},
+	"handlers.(*VulnerabilityFindingHandler).GetFinding": {
+		Summary: "Retrieves a single finding",
+	},
+	"handlers.(*VulnerabilityFindingHandler).GetFindingStatistics": {
+		Summary: "Returns statistics for findings with optional filters",
+		Params: []openapi.ParamAnnotation{
+			{Name: "status", In: "query", Type: "string"},
+			{Name: "severity", In: "query", Type: "string"},
+			{Name: "plugin_id", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*VulnerabilityFindingHandler).ListFindings": {
+		Summary: "Lists all findings with filters",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "limit", In: "query", Type: "int"},
+			{Name: "status", In: "query", Type: "string"},
+			{Name: "severity", In: "query", Type: "string"},
+			{Name: "plugin_id", In: "query", Type: "string"},
+			{Name: "asset_group_id", In: "query", Type: "string"},
+			{Name: "has_exploit", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*VulnerabilityFindingHandler).ListFindingsBySystem": {
+		Summary: "Lists all findings for a system",
+		Params: []openapi.ParamAnnotation{
+			{Name: "status", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*VulnerabilityFindingHandler).ListFindingsByVulnerability": {
+		Summary: "Lists all findings for a vulnerability",
+		Params: []openapi.ParamAnnotation{
+			{Name: "status", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*VulnerabilityFindingHandler).MarkFindingFixed": {
+		Summary: "Marks a finding as fixed",
+	},
+	"handlers.(*VulnerabilityFindingHandler).MarkFindingVerified": {
+		Summary: "Marks a finding as verified",
+	},
+	"handlers.(*VulnerabilityHandler).AddVulnerabilityTags": {
+		Summary: "Adds tags to a vulnerability",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*VulnerabilityTagsRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).AssignVulnerability": {
+		Summary: "Assigns a vulnerability to a user",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*AssignVulnerabilityRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).AssignVulnerabilityTeam": {
+		Summary: "Sets or clears the team that owns a vulnerability",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*AssignVulnerabilityTeamRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).BatchGetVulnerabilities": {
+		Summary: "Batch get vulnerabilities",
+		Tags:    []string{"Vulnerabilities"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Vulnerability IDs", Model: reflect.TypeOf((*services.BatchGetVulnerabilitiesRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Vulnerability)(nil)).Elem(), Array: true},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).BulkTagVulnerabilities": {
+		Summary: "Adds and removes tags across many vulnerabilities",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.BulkTagVulnerabilitiesRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).CreateVulnerability": {
+		Summary: "Create vulnerability",
+		Tags:    []string{"Vulnerabilities"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "request", In: "body", Required: true, Description: "Vulnerability", Model: reflect.TypeOf((*CreateVulnerabilityRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 201, Model: reflect.TypeOf((*models.Vulnerability)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).DeleteVulnerability": {
+		Summary: "Delete vulnerability",
+		Tags:    []string{"Vulnerabilities"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Vulnerability ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*fiber.Map)(nil)).Elem()},
+			{Status: 404, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).GetVulnerability": {
+		Summary: "Get vulnerability",
+		Tags:    []string{"Vulnerabilities"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Vulnerability ID"},
+			{Name: "fields", In: "query", Type: "string", Description: "Comma-separated fields to return"},
+			{Name: "include", In: "query", Type: "string", Description: "Comma-separated relations to embed"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Vulnerability)(nil)).Elem()},
+			{Status: 404, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).GetVulnerabilityRemediation": {
+		Summary:     "Get remediation guidance",
+		Description: "Returns the patch availability, advisory, fixed version and workaround of a vulnerability, and for each unpatched affected system the versions its scanners detected, the version to upgrade to and the suggested action (upgrade, apply_patch, apply_workaround or review).",
+		Tags:        []string{"Vulnerabilities"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Vulnerability ID"},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*services.RemediationGuidance)(nil)).Elem()},
+			{Status: 404, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).GetVulnerabilityStats": {
+		Summary: "Returns statistics about vulnerabilities",
+	},
+	"handlers.(*VulnerabilityHandler).ListVulnerabilities": {
+		Summary: "List vulnerabilities",
+		Tags:    []string{"Vulnerabilities"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "view_id", In: "query", Type: "string", Description: "Saved view whose filters to apply"},
+			{Name: "fields", In: "query", Type: "string", Description: "Comma-separated fields to return"},
+			{Name: "include", In: "query", Type: "string", Description: "Comma-separated relations to embed"},
+			{In: "query", Model: reflect.TypeOf((*ListVulnerabilitiesQuery)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Vulnerability)(nil)).Elem(), Array: true},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).ListVulnerabilityTags": {
+		Summary: "Lists the tags in use with their vulnerability counts",
+		Params: []openapi.ParamAnnotation{
+			{Name: "search", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).RemoveVulnerabilityTag": {
+		Summary: "Removes a tag from a vulnerability",
+	},
+	"handlers.(*VulnerabilityHandler).SetVulnerabilityClassifications": {
+		Summary:     "Classify vulnerability",
+		Description: "Replaces the OWASP Top 10 categories and/or ATT&CK techniques of a vulnerability with manual ones, including those inferred from its CWE IDs or scanner plugin family. A framework left out of the body is kept; an empty list clears it.",
+		Tags:        []string{"Vulnerabilities"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Vulnerability ID"},
+			{Name: "request", In: "body", Required: true, Description: "Classifications", Model: reflect.TypeOf((*services.SetClassificationsRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Vulnerability)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 404, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).UpdateVulnerability": {
+		Summary: "Update vulnerability",
+		Tags:    []string{"Vulnerabilities"},
+		Params: []openapi.ParamAnnotation{
+			{Name: "id", In: "path", Type: "string", Required: true, Description: "Vulnerability ID"},
+			{Name: "If-Match", In: "header", Type: "string", Description: "ETag of the version being updated"},
+			{Name: "request", In: "body", Required: true, Description: "Changes", Model: reflect.TypeOf((*UpdateVulnerabilityRequest)(nil)).Elem()},
+		},
+		Responses: []openapi.ResponseAnnotation{
+			{Status: 200, Model: reflect.TypeOf((*models.Vulnerability)(nil)).Elem()},
+			{Status: 400, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 404, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+			{Status: 412, Model: reflect.TypeOf((*middleware.ErrorResponse)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityHandler).UpdateVulnerabilityStatus": {
+		Summary: "Updates a vulnerability's status",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*UpdateStatusRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityImportHandler).DismissQuarantinedHost": {
+		Summary:     "Discards a quarantined host without importing it",
+		Description: "POST /api/v1/vulnerabilities/imports/quarantine/:hostId/dismiss",
+	},
+	"handlers.(*VulnerabilityImportHandler).GetImportJob": {
+		Summary:     "Returns an import job with its source and findings diff",
+		Description: "GET /api/v1/vulnerabilities/imports/:id",
+	},
+	"handlers.(*VulnerabilityImportHandler).ListImportJobs": {
+		Summary:     "Lists import jobs with their source and findings diff, newest first, optionally only those of one integration (?integration_config_id=) or scan (?scan_id=)",
+		Description: "GET /api/v1/vulnerabilities/imports",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "per_page", In: "query", Type: "int"},
+			{Name: "integration_config_id", In: "query", Type: "string"},
+			{Name: "scan_id", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*VulnerabilityImportHandler).ListQuarantinedHosts": {
+		Summary:     "Lists report hosts held back by import validation, newest first, optionally only those of one import (?import_job_id=) or status (?status=)",
+		Description: "GET /api/v1/vulnerabilities/imports/quarantine",
+		Params: []openapi.ParamAnnotation{
+			{Name: "page", In: "query", Type: "int"},
+			{Name: "per_page", In: "query", Type: "int"},
+			{Name: "import_job_id", In: "query", Type: "string"},
+			{Name: "status", In: "query", Type: "string"},
+		},
+	},
+	"handlers.(*VulnerabilityImportHandler).PreviewNessusFile": {
+		Summary: "Previews what will be imported without actually importing",
+	},
+	"handlers.(*VulnerabilityImportHandler).ReleaseQuarantinedHost": {
+		Summary:     "Imports a quarantined host, optionally with a corrected hostname or",
+		Description: "IP address POST /api/v1/vulnerabilities/imports/quarantine/:hostId/release",
+		Params: []openapi.ParamAnnotation{
+			{In: "body", Required: true, Model: reflect.TypeOf((*services.QuarantineReleaseRequest)(nil)).Elem()},
+		},
+	},
+	"handlers.(*VulnerabilityImportHandler).RollbackImportJob": {
+		Summary:     "Reverts a finished import: the records it created are removed and the findings it updated are restored",
+		Description: "POST /api/v1/import-jobs/:id/rollback",
+	},
+	"handlers.(*VulnerabilityImportHandler).UploadNessusFile": {
+		Summary: "Handles Nessus file upload and import",
+	},
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// OrganizationHandler handles organization (tenant) management
+type OrganizationHandler struct {
+	service *services.OrganizationService
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler() *OrganizationHandler {
+	return &OrganizationHandler{
+		service: services.NewOrganizationService(),
+	}
+}
+
+// organizationErrorResponse maps organization service errors to HTTP responses
+func organizationErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case msg == "organization not found":
+		return middleware.NotFoundError(c, "Organization")
+	case msg == "user not found":
+		return middleware.NotFoundError(c, "User")
+	case strings.Contains(msg, "already exists"), strings.Contains(msg, "still has"):
+		return middleware.ConflictError(c, msg)
+	case strings.Contains(msg, "cannot be"):
+		return middleware.ForbiddenError(c, msg)
+	case strings.Contains(msg, "required"), strings.Contains(msg, "must be"), strings.Contains(msg, "not active"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// GetCurrent returns the organization the request runs in
+// @Summary Get current organization
+// @Tags Organizations
+// @Produce json
+// @Success 200 {object} models.Organization
+// @Router /api/v1/organizations/current [get]
+// @Security BearerAuth
+func (h *OrganizationHandler) GetCurrent(c *fiber.Ctx) error {
+	orgID, ok := middleware.GetOrgID(c)
+	if !ok {
+		return middleware.NotFoundError(c, "Organization")
+	}
+
+	org, err := h.service.GetByID(orgID)
+	if err != nil {
+		return organizationErrorResponse(c, err, "Failed to get organization")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": org,
+	})
+}
+
+// ListOrganizations lists all organizations
+// @Summary List organizations
+// @Tags Organizations
+// @Produce json
+// @Success 200 {array} models.Organization
+// @Router /api/v1/organizations [get]
+// @Security BearerAuth
+func (h *OrganizationHandler) ListOrganizations(c *fiber.Ctx) error {
+	orgs, err := h.service.List()
+	if err != nil {
+		return organizationErrorResponse(c, err, "Failed to list organizations")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": orgs,
+	})
+}
+
+// GetOrganization returns an organization
+// @Summary Get organization
+// @Tags Organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} models.Organization
+// @Router /api/v1/organizations/{id} [get]
+// @Security BearerAuth
+func (h *OrganizationHandler) GetOrganization(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid organization ID", nil)
+	}
+
+	org, err := h.service.GetByID(id)
+	if err != nil {
+		return organizationErrorResponse(c, err, "Failed to get organization")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": org,
+	})
+}
+
+// CreateOrganization creates an organization
+// @Summary Create organization
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param request body services.CreateOrganizationRequest true "Organization"
+// @Success 201 {object} models.Organization
+// @Router /api/v1/organizations [post]
+// @Security BearerAuth
+func (h *OrganizationHandler) CreateOrganization(c *fiber.Ctx) error {
+	var req services.CreateOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	org, err := h.service.Create(req)
+	if err != nil {
+		return organizationErrorResponse(c, err, "Failed to create organization")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Organization created successfully",
+		"data":    org,
+	})
+}
+
+// UpdateOrganization updates an organization
+// @Summary Update organization
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body services.UpdateOrganizationRequest true "Changes"
+// @Success 200 {object} models.Organization
+// @Router /api/v1/organizations/{id} [put]
+// @Security BearerAuth
+func (h *OrganizationHandler) UpdateOrganization(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid organization ID", nil)
+	}
+
+	var req services.UpdateOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	org, err := h.service.Update(id, req)
+	if err != nil {
+		return organizationErrorResponse(c, err, "Failed to update organization")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Organization updated successfully",
+		"data":    org,
+	})
+}
+
+// DeleteOrganization deletes an organization without users
+// @Summary Delete organization
+// @Tags Organizations
+// @Param id path string true "Organization ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/organizations/{id} [delete]
+// @Security BearerAuth
+func (h *OrganizationHandler) DeleteOrganization(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid organization ID", nil)
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		return organizationErrorResponse(c, err, "Failed to delete organization")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Organization deleted successfully",
+	})
+}
+
+// AssignUser moves a user (and the user's API keys) into an organization
+// @Summary Assign user to organization
+// @Tags Organizations
+// @Param id path string true "Organization ID"
+// @Param userId path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/organizations/{id}/users/{userId} [put]
+// @Security BearerAuth
+func (h *OrganizationHandler) AssignUser(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid organization ID", nil)
+	}
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid user ID", nil)
+	}
+
+	if err := h.service.AssignUser(id, userID); err != nil {
+		return organizationErrorResponse(c, err, "Failed to assign user")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "User assigned to organization successfully",
+	})
+}
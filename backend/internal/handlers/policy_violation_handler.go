@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// PolicyViolationHandler handles policy rule management and policy violation tracking
+type PolicyViolationHandler struct {
+	service *services.PolicyViolationService
+}
+
+// NewPolicyViolationHandler creates a new policy violation handler
+func NewPolicyViolationHandler() *PolicyViolationHandler {
+	return &PolicyViolationHandler{
+		service: services.NewPolicyViolationService(database.GetDB()),
+	}
+}
+
+// policyErrorResponse maps policy service errors to HTTP responses
+func policyErrorResponse(c *fiber.Ctx, err error, resource, fallback string) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		return middleware.NotFoundError(c, resource)
+	case strings.Contains(msg, "required"), strings.Contains(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// ListRules lists policy rules
+// GET /api/v1/policy-rules?enabled=true
+func (h *PolicyViolationHandler) ListRules(c *fiber.Ctx) error {
+	rules, err := h.service.WithContext(c.UserContext()).ListRules(c.QueryBool("enabled", false))
+	if err != nil {
+		return policyErrorResponse(c, err, "Policy rule", "Failed to list policy rules")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": rules,
+	})
+}
+
+// GetRule returns a policy rule
+// GET /api/v1/policy-rules/:id
+func (h *PolicyViolationHandler) GetRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid policy rule ID", nil)
+	}
+
+	rule, err := h.service.WithContext(c.UserContext()).GetRule(id)
+	if err != nil {
+		return policyErrorResponse(c, err, "Policy rule", "Failed to get policy rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": rule,
+	})
+}
+
+// CreateRule creates a policy rule checked by the policy evaluation job
+// POST /api/v1/policy-rules
+func (h *PolicyViolationHandler) CreateRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.PolicyRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	rule, err := h.service.WithContext(c.UserContext()).CreateRule(req, userID)
+	if err != nil {
+		return policyErrorResponse(c, err, "Policy rule", "Failed to create policy rule")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Policy rule created successfully",
+		"data":    rule,
+	})
+}
+
+// UpdateRule updates a policy rule
+// PUT /api/v1/policy-rules/:id
+func (h *PolicyViolationHandler) UpdateRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid policy rule ID", nil)
+	}
+
+	var req services.PolicyRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	rule, err := h.service.WithContext(c.UserContext()).UpdateRule(id, req, userID)
+	if err != nil {
+		return policyErrorResponse(c, err, "Policy rule", "Failed to update policy rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Policy rule updated successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteRule deletes a policy rule and resolves its open violations
+// DELETE /api/v1/policy-rules/:id
+func (h *PolicyViolationHandler) DeleteRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid policy rule ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).DeleteRule(id, userID); err != nil {
+		return policyErrorResponse(c, err, "Policy rule", "Failed to delete policy rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Policy rule deleted successfully",
+	})
+}
+
+// EvaluateRules runs the policy evaluation immediately instead of waiting for the background job
+// POST /api/v1/policy-rules/evaluate
+func (h *PolicyViolationHandler) EvaluateRules(c *fiber.Ctx) error {
+	result, err := h.service.Evaluate(time.Now())
+	if err != nil {
+		return policyErrorResponse(c, err, "Policy rule", "Failed to evaluate policy rules")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Policy rules evaluated",
+		"data":    result,
+	})
+}
+
+// ListViolations lists policy violations
+// GET /api/v1/policy-violations?status=OPEN&rule_id=&vulnerability_id=&severity=&page=1&limit=50
+func (h *PolicyViolationHandler) ListViolations(c *fiber.Ctx) error {
+	filter := services.PolicyViolationFilter{
+		Status:   models.PolicyViolationStatus(strings.ToUpper(c.Query("status"))),
+		Severity: models.VulnerabilitySeverity(strings.ToUpper(c.Query("severity"))),
+	}
+	switch filter.Status {
+	case "", models.PolicyViolationOpen, models.PolicyViolationResolved:
+	default:
+		return middleware.ValidationError(c, "Invalid status, must be one of: OPEN, RESOLVED", nil)
+	}
+	if value := c.Query("rule_id"); value != "" {
+		ruleID, err := uuid.Parse(value)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid rule_id", nil)
+		}
+		filter.RuleID = &ruleID
+	}
+	if value := c.Query("vulnerability_id"); value != "" {
+		vulnerabilityID, err := uuid.Parse(value)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid vulnerability_id", nil)
+		}
+		filter.VulnerabilityID = &vulnerabilityID
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	violations, total, err := h.service.WithContext(c.UserContext()).ListViolations(filter, page, limit)
+	if err != nil {
+		return policyErrorResponse(c, err, "Policy violation", "Failed to list policy violations")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": violations,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetViolation returns a policy violation
+// GET /api/v1/policy-violations/:id
+func (h *PolicyViolationHandler) GetViolation(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid policy violation ID", nil)
+	}
+
+	violation, err := h.service.WithContext(c.UserContext()).GetViolation(id)
+	if err != nil {
+		return policyErrorResponse(c, err, "Policy violation", "Failed to get policy violation")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": violation,
+	})
+}
@@ -4,19 +4,23 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
 // ProfileHandler handles user profile requests
 type ProfileHandler struct {
-	profileService *services.ProfileService
+	profileService    *services.ProfileService
+	preferenceService *services.UserPreferenceService
 }
 
 // NewProfileHandler creates a new profile handler
 func NewProfileHandler() *ProfileHandler {
 	return &ProfileHandler{
-		profileService: services.NewProfileService(),
+		profileService:    services.NewProfileService(),
+		preferenceService: services.NewUserPreferenceService(database.GetDB()),
 	}
 }
 
@@ -32,9 +36,20 @@ func (h *ProfileHandler) GetProfile(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{
+	response := fiber.Map{
 		"user": user.ToPublic(),
-	})
+	}
+
+	// Tell the client to show the impersonation banner
+	if session, ok := c.Locals("session").(*models.Session); ok && session.IsImpersonation() {
+		impersonator, err := h.profileService.GetProfile(*session.ImpersonatorID)
+		if err != nil {
+			utils.Logger.Warn().Err(err).Str("impersonator_id", session.ImpersonatorID.String()).Msg("Failed to load impersonator")
+		}
+		response["impersonation"] = session.ToImpersonationInfo(impersonator)
+	}
+
+	return c.JSON(response)
 }
 
 // UpdateProfileRequest represents a profile update request
@@ -136,10 +151,13 @@ func (h *ProfileHandler) GetActiveSessions(c *fiber.Ctx) error {
 		})
 	}
 
-	// Convert to public format
+	// Convert to public format, flagging the session making this request
+	currentSessionID, _ := c.Locals("session_id").(uuid.UUID)
 	publicSessions := make([]interface{}, len(sessions))
 	for i, session := range sessions {
-		publicSessions[i] = session.ToPublic()
+		public := session.ToPublic()
+		public.IsCurrent = session.ID == currentSessionID
+		publicSessions[i] = public
 	}
 
 	return c.JSON(fiber.Map{
@@ -147,6 +165,26 @@ func (h *ProfileHandler) GetActiveSessions(c *fiber.Ctx) error {
 	})
 }
 
+// GetSecurityOverview returns the user's recent logins, API keys, 2FA status and recent
+// sensitive account changes so they can audit their own account
+// GET /api/v1/profile/security
+func (h *ProfileHandler) GetSecurityOverview(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	overview, err := h.profileService.GetSecurityOverview(userID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return middleware.NotFoundError(c, "User")
+		}
+		utils.Logger.Error().Err(err).Str("user_id", userID.String()).Msg("Failed to get security overview")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve security overview",
+		})
+	}
+
+	return c.JSON(overview)
+}
+
 // RevokeSessionRequest represents a session revocation request
 type RevokeSessionRequest struct {
 	SessionID string `json:"session_id"`
@@ -156,9 +194,12 @@ type RevokeSessionRequest struct {
 func (h *ProfileHandler) RevokeSession(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uuid.UUID)
 
-	var req RevokeSessionRequest
-	if err := c.BodyParser(&req); err != nil {
-		return middleware.ValidationError(c, "Invalid request body", nil)
+	// The session ID comes from the route, or from the body for older clients
+	req := RevokeSessionRequest{SessionID: c.Params("id")}
+	if req.SessionID == "" {
+		if err := c.BodyParser(&req); err != nil {
+			return middleware.ValidationError(c, "Invalid request body", nil)
+		}
 	}
 
 	if req.SessionID == "" {
@@ -183,12 +224,16 @@ func (h *ProfileHandler) RevokeSession(c *fiber.Ctx) error {
 	})
 }
 
-// RevokeAllSessions revokes all sessions except the current one
+// RevokeAllSessions revokes all sessions except the current one. Their refresh chains are
+// revoked too, so the other devices are signed out rather than silently refreshing.
 func (h *ProfileHandler) RevokeAllSessions(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uuid.UUID)
-	currentSessionID := c.Locals("session_id").(uuid.UUID)
+	currentSessionID, ok := c.Locals("session_id").(uuid.UUID)
+	if !ok {
+		return middleware.ValidationError(c, "Revoking other sessions requires a session login", nil)
+	}
 
-	err := h.profileService.RevokeAllSessions(userID, &currentSessionID)
+	count, err := h.profileService.RevokeAllSessions(userID, &currentSessionID, c.IP(), c.Get("User-Agent"))
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to revoke all sessions")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -198,5 +243,44 @@ func (h *ProfileHandler) RevokeAllSessions(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{
 		"message": "All other sessions revoked successfully",
+		"revoked": count,
+	})
+}
+
+// GetPreferences retrieves the authenticated user's preferences
+func (h *ProfileHandler) GetPreferences(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	prefs, err := h.preferenceService.WithContext(c.UserContext()).GetPreferences(userID)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to get preferences")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve preferences",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"preferences": prefs,
+	})
+}
+
+// UpdatePreferences updates the authenticated user's preferences
+func (h *ProfileHandler) UpdatePreferences(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.UpdatePreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	prefs, err := h.preferenceService.WithContext(c.UserContext()).UpdatePreferences(userID, req)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to update preferences")
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	return c.JSON(fiber.Map{
+		"message":     "Preferences updated successfully",
+		"preferences": prefs,
 	})
 }
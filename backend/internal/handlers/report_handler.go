@@ -6,19 +6,26 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
 	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
 // ReportHandler handles report generation endpoints
 type ReportHandler struct {
-	reportService *services.ReportService
+	reportService    *services.ReportService
+	analyticsService *services.RemediationAnalyticsService
+	summaryService   *services.ReportSummaryService
 }
 
 // NewReportHandler creates a new report handler
-func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+func NewReportHandler(reportService *services.ReportService, analyticsService *services.RemediationAnalyticsService, summaryService *services.ReportSummaryService) *ReportHandler {
 	return &ReportHandler{
-		reportService: reportService,
+		reportService:    reportService,
+		analyticsService: analyticsService,
+		summaryService:   summaryService,
 	}
 }
 
@@ -36,6 +43,11 @@ type ReportRequest struct {
 // @Produce json
 // @Param start_date query string false "Start date (YYYY-MM-DD)" default:"30 days ago"
 // @Param end_date query string false "End date (YYYY-MM-DD)" default:"today"
+// @Param timezone query string false "IANA timezone the dates are days in (defaults to the user's preferred timezone)"
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
 // @Success 200 {object} services.AnalystReportData
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -50,8 +62,15 @@ func (h *ReportHandler) GetAnalystReport(c *fiber.Ctx) error {
 		})
 	}
 
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Generate report
-	report, err := h.reportService.GenerateAnalystReport(startDate, endDate)
+	report, err := h.reportService.WithContext(c.UserContext()).WithFilter(filter).GenerateAnalystReport(startDate, endDate)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to generate analyst report")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -70,6 +89,11 @@ func (h *ReportHandler) GetAnalystReport(c *fiber.Ctx) error {
 // @Produce json
 // @Param start_date query string false "Start date (YYYY-MM-DD)" default:"30 days ago"
 // @Param end_date query string false "End date (YYYY-MM-DD)" default:"today"
+// @Param timezone query string false "IANA timezone the dates are days in (defaults to the user's preferred timezone)"
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
 // @Success 200 {object} services.ExecutiveReportData
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -84,8 +108,15 @@ func (h *ReportHandler) GetExecutiveReport(c *fiber.Ctx) error {
 		})
 	}
 
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Generate report
-	report, err := h.reportService.GenerateExecutiveReport(startDate, endDate)
+	report, err := h.reportService.WithContext(c.UserContext()).WithFilter(filter).GenerateExecutiveReport(startDate, endDate)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to generate executive report")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -93,6 +124,17 @@ func (h *ReportHandler) GetExecutiveReport(c *fiber.Ctx) error {
 		})
 	}
 
+	// Attach the stored narrative to a copy, as the report itself may be shared through the cache.
+	// Narratives describe the unfiltered report, so filtered reports go without one.
+	summary, err := h.summaryService.WithContext(c.UserContext()).LatestExecutiveSummary(startDate, endDate)
+	if err != nil {
+		utils.Logger.Warn().Err(err).Msg("Failed to load executive report summary")
+	} else if summary != nil && filter.IsZero() {
+		withSummary := *report
+		withSummary.Summary = summary
+		report = &withSummary
+	}
+
 	return c.JSON(report)
 }
 
@@ -104,6 +146,11 @@ func (h *ReportHandler) GetExecutiveReport(c *fiber.Ctx) error {
 // @Produce json
 // @Param start_date query string false "Start date (YYYY-MM-DD)" default:"30 days ago"
 // @Param end_date query string false "End date (YYYY-MM-DD)" default:"today"
+// @Param timezone query string false "IANA timezone the dates are days in (defaults to the user's preferred timezone)"
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
 // @Success 200 {object} services.AuditReportData
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -118,8 +165,15 @@ func (h *ReportHandler) GetAuditReport(c *fiber.Ctx) error {
 		})
 	}
 
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Generate report
-	report, err := h.reportService.GenerateAuditReport(startDate, endDate)
+	report, err := h.reportService.WithContext(c.UserContext()).WithFilter(filter).GenerateAuditReport(startDate, endDate)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to generate audit report")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -130,6 +184,348 @@ func (h *ReportHandler) GetAuditReport(c *fiber.Ctx) error {
 	return c.JSON(report)
 }
 
+// GetRemediationAnalytics returns MTTR, aging and burn-down data for dashboards
+// @Summary Get remediation analytics
+// @Description Mean time to remediate by severity, team and month, aging buckets of open vulnerabilities, and burn-down data
+// @Tags Reports
+// @Produce json
+// @Param start_date query string false "Start date (YYYY-MM-DD)" default:"30 days ago"
+// @Param end_date query string false "End date (YYYY-MM-DD)" default:"today"
+// @Param timezone query string false "IANA timezone the dates are days in (defaults to the user's preferred timezone)"
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
+// @Success 200 {object} services.RemediationAnalytics
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reports/analytics [get]
+// @Security BearerAuth
+func (h *ReportHandler) GetRemediationAnalytics(c *fiber.Ctx) error {
+	startDate, endDate, err := h.parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	analytics, err := h.analyticsService.WithContext(c.UserContext()).WithFilter(filter).InLocation(startDate.Location()).GetAnalytics(startDate, endDate)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to compute remediation analytics")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute remediation analytics",
+		})
+	}
+
+	return c.JSON(analytics.Localized(middleware.Language(c)))
+}
+
+// GetMTTRAnalytics returns the mean time to remediate of vulnerabilities remediated in the period
+// @Summary Get mean time to remediate
+// @Description Mean and median days to remediate, overall and by severity, owning team and month
+// @Tags Reports
+// @Produce json
+// @Param start_date query string false "Start date (YYYY-MM-DD)" default:"30 days ago"
+// @Param end_date query string false "End date (YYYY-MM-DD)" default:"today"
+// @Param timezone query string false "IANA timezone the dates are days in (defaults to the user's preferred timezone)"
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
+// @Success 200 {object} services.MTTRAnalytics
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reports/analytics/mttr [get]
+// @Security BearerAuth
+func (h *ReportHandler) GetMTTRAnalytics(c *fiber.Ctx) error {
+	startDate, endDate, err := h.parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	mttr, err := h.analyticsService.WithContext(c.UserContext()).WithFilter(filter).InLocation(startDate.Location()).MTTR(startDate, endDate)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to compute MTTR")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute remediation analytics",
+		})
+	}
+
+	return c.JSON(mttr.Localized(middleware.Language(c)))
+}
+
+// GetAgingAnalytics returns the age distribution of the currently open vulnerabilities
+// @Summary Get open vulnerability aging
+// @Description Open vulnerabilities grouped into age buckets by severity
+// @Tags Reports
+// @Produce json
+// @Param timezone query string false "IANA timezone the dates are days in (defaults to the user's preferred timezone)"
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
+// @Success 200 {object} services.AgingAnalytics
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reports/analytics/aging [get]
+// @Security BearerAuth
+func (h *ReportHandler) GetAgingAnalytics(c *fiber.Ctx) error {
+	loc, err := reportLocation(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	aging, err := h.analyticsService.WithContext(c.UserContext()).WithFilter(filter).InLocation(loc).Aging()
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to compute vulnerability aging")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute remediation analytics",
+		})
+	}
+
+	return c.JSON(aging.Localized(middleware.Language(c)))
+}
+
+// GetBurnDownAnalytics returns the open vulnerability backlog over the period
+// @Summary Get vulnerability burn-down
+// @Description Opened, closed and reopened vulnerabilities and the open backlog per day (per week for periods over 90 days)
+// @Tags Reports
+// @Produce json
+// @Param start_date query string false "Start date (YYYY-MM-DD)" default:"30 days ago"
+// @Param end_date query string false "End date (YYYY-MM-DD)" default:"today"
+// @Param timezone query string false "IANA timezone the dates are days in (defaults to the user's preferred timezone)"
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
+// @Success 200 {object} services.BurnDownAnalytics
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reports/analytics/burndown [get]
+// @Security BearerAuth
+func (h *ReportHandler) GetBurnDownAnalytics(c *fiber.Ctx) error {
+	startDate, endDate, err := h.parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	burnDown, err := h.analyticsService.WithContext(c.UserContext()).WithFilter(filter).InLocation(startDate.Location()).BurnDown(startDate, endDate)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to compute burn-down")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute remediation analytics",
+		})
+	}
+
+	return c.JSON(burnDown)
+}
+
+// GetRemediationForecast projects the burn-down of the open backlog
+// @Summary Get remediation forecast
+// @Description Projects the open backlog per severity from the daily rates vulnerabilities were opened and resolved over the lookback period, with the date each severity is projected to be cleared
+// @Tags Reports
+// @Produce json
+// @Param lookback_days query int false "Days of history the remediation velocity is measured over (1-365)" default(90)
+// @Param horizon_days query int false "Days to project ahead (1-730)" default(180)
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
+// @Success 200 {object} services.RemediationForecast
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reports/analytics/forecast [get]
+// @Security BearerAuth
+func (h *ReportHandler) GetRemediationForecast(c *fiber.Ctx) error {
+	lookbackDays := c.QueryInt("lookback_days", services.DefaultForecastLookbackDays)
+	if lookbackDays < 1 || lookbackDays > services.MaxForecastLookbackDays {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("invalid lookback_days, must be between 1 and %d", services.MaxForecastLookbackDays),
+		})
+	}
+	horizonDays := c.QueryInt("horizon_days", services.DefaultForecastHorizonDays)
+	if horizonDays < 1 || horizonDays > services.MaxForecastHorizonDays {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("invalid horizon_days, must be between 1 and %d", services.MaxForecastHorizonDays),
+		})
+	}
+
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	forecast, err := h.analyticsService.WithContext(c.UserContext()).WithFilter(filter).Forecast(time.Now(), lookbackDays, horizonDays)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to compute remediation forecast")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute remediation analytics",
+		})
+	}
+
+	return c.JSON(forecast.Localized(middleware.Language(c)))
+}
+
+// GetTopAssetAnalytics ranks assets for quarterly reviews
+// @Summary Get top risky assets
+// @Description Assets ranked by open risk weighted by severity and criticality, assets whose fixed findings recurred in the period, and the time asset owners and owner teams took to fix findings
+// @Tags Reports
+// @Produce json
+// @Param start_date query string false "Start date (YYYY-MM-DD)" default:"30 days ago"
+// @Param end_date query string false "End date (YYYY-MM-DD)" default:"today"
+// @Param timezone query string false "IANA timezone the dates are days in (defaults to the user's preferred timezone)"
+// @Param limit query int false "Assets per ranking (1-100)" default(10)
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
+// @Success 200 {object} services.TopAssetAnalytics
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reports/analytics/top-assets [get]
+// @Security BearerAuth
+func (h *ReportHandler) GetTopAssetAnalytics(c *fiber.Ctx) error {
+	startDate, endDate, err := h.parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	limit := c.QueryInt("limit", services.DefaultTopAssetsLimit)
+	if limit < 1 || limit > services.MaxTopAssetsLimit {
+		limit = services.DefaultTopAssetsLimit
+	}
+
+	topAssets, err := h.analyticsService.WithContext(c.UserContext()).WithFilter(filter).TopAssets(startDate, endDate, limit)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to rank top assets")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute remediation analytics",
+		})
+	}
+
+	return c.JSON(topAssets.Localized(middleware.Language(c)))
+}
+
+// GetCoverageHeatmap maps the period's vulnerabilities onto the OWASP Top 10 and ATT&CK
+// @Summary Get OWASP Top 10 and ATT&CK coverage heatmap
+// @Description Vulnerabilities created in the period per OWASP Top 10 category and per ATT&CK technique grouped by tactic, by severity, with the share of vulnerabilities classified
+// @Tags Reports
+// @Produce json
+// @Param start_date query string false "Start date (YYYY-MM-DD)" default:"30 days ago"
+// @Param end_date query string false "End date (YYYY-MM-DD)" default:"today"
+// @Param timezone query string false "IANA timezone the dates are days in (defaults to the user's preferred timezone)"
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
+// @Success 200 {object} services.CoverageHeatmap
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reports/analytics/coverage [get]
+// @Security BearerAuth
+func (h *ReportHandler) GetCoverageHeatmap(c *fiber.Ctx) error {
+	startDate, endDate, err := h.parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	heatmap, err := h.analyticsService.WithContext(c.UserContext()).WithFilter(filter).Coverage(startDate, endDate)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to compute coverage heatmap")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compute remediation analytics",
+		})
+	}
+
+	return c.JSON(heatmap.Localized(middleware.Language(c)))
+}
+
+// ComparePeriods compares two months from the daily metrics snapshots for a delta dashboard
+// @Summary Compare two periods
+// @Description New and resolved vulnerabilities, risk score and backlog of two months side by side, with the change of each metric and the largest regressions
+// @Tags Reports
+// @Produce json
+// @Param from query string true "Earlier month (YYYY-MM)"
+// @Param to query string true "Later month (YYYY-MM); the current month covers up to today"
+// @Success 200 {object} services.PeriodComparison
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/reports/compare [get]
+// @Security BearerAuth
+func (h *ReportHandler) ComparePeriods(c *fiber.Ctx) error {
+	now := time.Now()
+	from, to, err := services.ParseComparisonPeriods(c.Query("from"), c.Query("to"), now)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	comparison, err := h.reportService.WithContext(c.UserContext()).ComparePeriods(from, to, now)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to compare report periods")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to compare periods",
+		})
+	}
+
+	return c.JSON(comparison)
+}
+
 // ExportAnalystReportCSV exports the analyst report as CSV
 // @Summary Export analyst report as CSV
 // @Description Export a detailed analyst report in CSV format
@@ -137,6 +533,15 @@ func (h *ReportHandler) GetAuditReport(c *fiber.Ctx) error {
 // @Produce text/csv
 // @Param start_date query string false "Start date (YYYY-MM-DD)" default:"30 days ago"
 // @Param end_date query string false "End date (YYYY-MM-DD)" default:"today"
+// @Param timezone query string false "IANA timezone the dates are days in (defaults to the user's preferred timezone)"
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
+// @Param delimiter query string false "Field delimiter: comma, semicolon, tab, pipe"
+// @Param encoding query string false "Character encoding: utf-8, iso-8859-1, iso-8859-15, windows-1252"
+// @Param bom query bool false "Prefix UTF-8 output with a byte order mark"
+// @Param template_id query string false "Report template to brand the export with (defaults to the default template)"
 // @Success 200 {file} file
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -151,8 +556,31 @@ func (h *ReportHandler) ExportAnalystReportCSV(c *fiber.Ctx) error {
 		})
 	}
 
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Resolve CSV format options (query params override saved preferences)
+	exportOpts, err := resolveCSVExportOptions(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Resolve the report template's branding and sections
+	branding, err := csvReportBranding(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Generate report
-	report, err := h.reportService.GenerateAnalystReport(startDate, endDate)
+	report, err := h.reportService.WithContext(c.UserContext()).WithFilter(filter).GenerateAnalystReport(startDate, endDate)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to generate analyst report")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -160,22 +588,21 @@ func (h *ReportHandler) ExportAnalystReportCSV(c *fiber.Ctx) error {
 		})
 	}
 
-	// Set headers for CSV download
-	c.Set("Content-Type", "text/csv")
-	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=analyst-report-%s.csv", time.Now().Format("2006-01-02")))
-
-	// Create CSV writer
-	writer := csv.NewWriter(c)
-	defer writer.Flush()
+	// Create CSV writer honoring delimiter/encoding preferences
+	writer, flush := newCSVExportWriter(c, fmt.Sprintf("analyst-report-%s.csv", time.Now().Format("2006-01-02")), exportOpts)
+	defer flush()
+	writeCSVBranding(writer, branding)
 
-	// Write summary section
-	writer.Write([]string{"ANALYST REPORT SUMMARY"})
-	writer.Write([]string{"Generated At", report.GeneratedAt.Format(time.RFC3339)})
-	writer.Write([]string{"Total Vulnerabilities", fmt.Sprintf("%d", report.TotalVulnerabilities)})
-	writer.Write([]string{"Open Vulnerabilities", fmt.Sprintf("%d", report.OpenVulnerabilities)})
-	writer.Write([]string{"Resolved Vulnerabilities", fmt.Sprintf("%d", report.ResolvedVulnerabilities)})
-	writer.Write([]string{"Total Assets", fmt.Sprintf("%d", report.TotalAssets)})
-	writer.Write([]string{})
+	if branding.Sections.Summary {
+		// Write summary section
+		writer.Write([]string{"ANALYST REPORT SUMMARY"})
+		writer.Write([]string{"Generated At", report.GeneratedAt.Format(time.RFC3339)})
+		writer.Write([]string{"Total Vulnerabilities", fmt.Sprintf("%d", report.TotalVulnerabilities)})
+		writer.Write([]string{"Open Vulnerabilities", fmt.Sprintf("%d", report.OpenVulnerabilities)})
+		writer.Write([]string{"Resolved Vulnerabilities", fmt.Sprintf("%d", report.ResolvedVulnerabilities)})
+		writer.Write([]string{"Total Assets", fmt.Sprintf("%d", report.TotalAssets)})
+		writer.Write([]string{})
+	}
 
 	// Vulnerabilities by severity
 	writer.Write([]string{"VULNERABILITIES BY SEVERITY"})
@@ -193,32 +620,51 @@ func (h *ReportHandler) ExportAnalystReportCSV(c *fiber.Ctx) error {
 	}
 	writer.Write([]string{})
 
-	// Recent vulnerabilities
-	writer.Write([]string{"RECENT VULNERABILITIES"})
-	writer.Write([]string{"ID", "Title", "Severity", "Status", "Discovery Date", "Assigned To"})
-	for _, vuln := range report.RecentVulnerabilities {
+	// Vulnerabilities by weakness type
+	writer.Write([]string{"VULNERABILITIES BY CWE"})
+	writer.Write([]string{"CWE", "Name", "Total", "Open", "Critical", "High"})
+	for _, weakness := range report.VulnerabilitiesByCWE {
 		writer.Write([]string{
-			vuln.ID,
-			vuln.Title,
-			vuln.Severity,
-			vuln.Status,
-			vuln.DiscoveryDate.Format("2006-01-02"),
-			vuln.AssignedTo,
+			weakness.CWEID,
+			weakness.Name,
+			fmt.Sprintf("%d", weakness.Total),
+			fmt.Sprintf("%d", weakness.Open),
+			fmt.Sprintf("%d", weakness.Critical),
+			fmt.Sprintf("%d", weakness.High),
 		})
 	}
 	writer.Write([]string{})
 
-	// Assigned vulnerabilities
-	writer.Write([]string{"ASSIGNED VULNERABILITIES"})
-	writer.Write([]string{"Assignee", "Total", "Open", "In Progress", "Resolved"})
-	for _, assignee := range report.AssignedVulnerabilities {
-		writer.Write([]string{
-			assignee.AssigneeName,
-			fmt.Sprintf("%d", assignee.Total),
-			fmt.Sprintf("%d", assignee.Open),
-			fmt.Sprintf("%d", assignee.InProgress),
-			fmt.Sprintf("%d", assignee.Resolved),
-		})
+	if branding.Sections.Findings {
+		// Recent vulnerabilities
+		writer.Write([]string{"RECENT VULNERABILITIES"})
+		writer.Write([]string{"ID", "Title", "Severity", "Status", "Discovery Date", "Assigned To"})
+		for _, vuln := range report.RecentVulnerabilities {
+			writer.Write([]string{
+				vuln.ID,
+				vuln.Title,
+				vuln.Severity,
+				vuln.Status,
+				vuln.DiscoveryDate.Format("2006-01-02"),
+				vuln.AssignedTo,
+			})
+		}
+		writer.Write([]string{})
+	}
+
+	if branding.Sections.Remediation {
+		// Assigned vulnerabilities
+		writer.Write([]string{"ASSIGNED VULNERABILITIES"})
+		writer.Write([]string{"Assignee", "Total", "Open", "In Progress", "Resolved"})
+		for _, assignee := range report.AssignedVulnerabilities {
+			writer.Write([]string{
+				assignee.AssigneeName,
+				fmt.Sprintf("%d", assignee.Total),
+				fmt.Sprintf("%d", assignee.Open),
+				fmt.Sprintf("%d", assignee.InProgress),
+				fmt.Sprintf("%d", assignee.Resolved),
+			})
+		}
 	}
 
 	return nil
@@ -231,6 +677,15 @@ func (h *ReportHandler) ExportAnalystReportCSV(c *fiber.Ctx) error {
 // @Produce text/csv
 // @Param start_date query string false "Start date (YYYY-MM-DD)" default:"30 days ago"
 // @Param end_date query string false "End date (YYYY-MM-DD)" default:"today"
+// @Param timezone query string false "IANA timezone the dates are days in (defaults to the user's preferred timezone)"
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
+// @Param delimiter query string false "Field delimiter: comma, semicolon, tab, pipe"
+// @Param encoding query string false "Character encoding: utf-8, iso-8859-1, iso-8859-15, windows-1252"
+// @Param bom query bool false "Prefix UTF-8 output with a byte order mark"
+// @Param template_id query string false "Report template to brand the export with (defaults to the default template)"
 // @Success 200 {file} file
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -245,8 +700,31 @@ func (h *ReportHandler) ExportExecutiveReportCSV(c *fiber.Ctx) error {
 		})
 	}
 
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Resolve CSV format options (query params override saved preferences)
+	exportOpts, err := resolveCSVExportOptions(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Resolve the report template's branding and sections
+	branding, err := csvReportBranding(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Generate report
-	report, err := h.reportService.GenerateExecutiveReport(startDate, endDate)
+	report, err := h.reportService.WithContext(c.UserContext()).WithFilter(filter).GenerateExecutiveReport(startDate, endDate)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to generate executive report")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -254,41 +732,44 @@ func (h *ReportHandler) ExportExecutiveReportCSV(c *fiber.Ctx) error {
 		})
 	}
 
-	// Set headers for CSV download
-	c.Set("Content-Type", "text/csv")
-	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=executive-report-%s.csv", time.Now().Format("2006-01-02")))
-
-	// Create CSV writer
-	writer := csv.NewWriter(c)
-	defer writer.Flush()
+	// Create CSV writer honoring delimiter/encoding preferences
+	writer, flush := newCSVExportWriter(c, fmt.Sprintf("executive-report-%s.csv", time.Now().Format("2006-01-02")), exportOpts)
+	defer flush()
+	writeCSVBranding(writer, branding)
 
-	// Write executive summary
-	writer.Write([]string{"EXECUTIVE REPORT SUMMARY"})
-	writer.Write([]string{"Generated At", report.GeneratedAt.Format(time.RFC3339)})
-	writer.Write([]string{"Risk Score", fmt.Sprintf("%.2f/100", report.RiskScore)})
-	writer.Write([]string{"Security Posture", report.SecurityPosture})
-	writer.Write([]string{"Critical Vulnerabilities", fmt.Sprintf("%d", report.CriticalVulnerabilities)})
-	writer.Write([]string{"High Vulnerabilities", fmt.Sprintf("%d", report.HighVulnerabilities)})
-	writer.Write([]string{"Total Assets", fmt.Sprintf("%d", report.TotalAssets)})
-	writer.Write([]string{"Compliance Score", fmt.Sprintf("%.2f%%", report.ComplianceScore)})
-	writer.Write([]string{"Remediation Rate", fmt.Sprintf("%.2f%%", report.RemediationRate)})
-	writer.Write([]string{"Average Time To Remediate", fmt.Sprintf("%.2f days", report.AverageTimeToRemediate)})
-	writer.Write([]string{"Cost Impact Estimate", fmt.Sprintf("$%.2f", report.CostImpactEstimate)})
-	writer.Write([]string{})
+	if branding.Sections.Summary {
+		// Write executive summary
+		writer.Write([]string{"EXECUTIVE REPORT SUMMARY"})
+		writer.Write([]string{"Generated At", report.GeneratedAt.Format(time.RFC3339)})
+		writer.Write([]string{"Risk Score", fmt.Sprintf("%.2f/100", report.RiskScore)})
+		writer.Write([]string{"Security Posture", report.SecurityPosture})
+		writer.Write([]string{"Critical Vulnerabilities", fmt.Sprintf("%d", report.CriticalVulnerabilities)})
+		writer.Write([]string{"High Vulnerabilities", fmt.Sprintf("%d", report.HighVulnerabilities)})
+		writer.Write([]string{"Total Assets", fmt.Sprintf("%d", report.TotalAssets)})
+		writer.Write([]string{"Compliance Score", fmt.Sprintf("%.2f%%", report.ComplianceScore)})
+		writer.Write([]string{"Remediation Rate", fmt.Sprintf("%.2f%%", report.RemediationRate)})
+		writer.Write([]string{"Average Time To Remediate", fmt.Sprintf("%.2f days", report.AverageTimeToRemediate)})
+		writer.Write([]string{"Cost Impact Estimate", fmt.Sprintf("$%.2f", report.CostImpactEstimate)})
+		writer.Write([]string{})
+	}
 
-	// Key risks
-	writer.Write([]string{"KEY RISKS"})
-	for _, risk := range report.KeyRisks {
-		writer.Write([]string{risk})
+	if branding.Sections.Findings {
+		// Key risks
+		writer.Write([]string{"KEY RISKS"})
+		for _, risk := range report.KeyRisks {
+			writer.Write([]string{risk})
+		}
+		writer.Write([]string{})
 	}
-	writer.Write([]string{})
 
-	// Recommended actions
-	writer.Write([]string{"RECOMMENDED ACTIONS"})
-	for _, action := range report.RecommendedActions {
-		writer.Write([]string{action})
+	if branding.Sections.Remediation {
+		// Recommended actions
+		writer.Write([]string{"RECOMMENDED ACTIONS"})
+		for _, action := range report.RecommendedActions {
+			writer.Write([]string{action})
+		}
+		writer.Write([]string{})
 	}
-	writer.Write([]string{})
 
 	// Monthly trend
 	writer.Write([]string{"MONTHLY TREND"})
@@ -302,6 +783,26 @@ func (h *ReportHandler) ExportExecutiveReportCSV(c *fiber.Ctx) error {
 		})
 	}
 
+	// Remediation forecast
+	if report.Forecast != nil {
+		writer.Write([]string{})
+		writer.Write([]string{"REMEDIATION FORECAST"})
+		writer.Write([]string{"Severity", "Open", "Opened Per Day", "Resolved Per Day", "Projected Clear Date"})
+		for _, severity := range report.Forecast.BySeverity {
+			clearDate := "Not projected"
+			if severity.ClearDate != nil {
+				clearDate = severity.ClearDate.Format("2006-01-02")
+			}
+			writer.Write([]string{
+				string(severity.Severity),
+				fmt.Sprintf("%d", severity.Open),
+				fmt.Sprintf("%.2f", severity.OpenedPerDay),
+				fmt.Sprintf("%.2f", severity.ResolvedPerDay),
+				clearDate,
+			})
+		}
+	}
+
 	return nil
 }
 
@@ -312,6 +813,15 @@ func (h *ReportHandler) ExportExecutiveReportCSV(c *fiber.Ctx) error {
 // @Produce text/csv
 // @Param start_date query string false "Start date (YYYY-MM-DD)" default:"30 days ago"
 // @Param end_date query string false "End date (YYYY-MM-DD)" default:"today"
+// @Param timezone query string false "IANA timezone the dates are days in (defaults to the user's preferred timezone)"
+// @Param asset_group query string false "Only cover assets in this asset group (UUID)"
+// @Param environment query string false "Only cover assets in this environment: PRODUCTION, STAGING, DEVELOPMENT, TEST"
+// @Param tags query string false "Only cover assets carrying all of these comma-separated tags"
+// @Param owner_team query string false "Only cover assets owned by this team (UUID)"
+// @Param delimiter query string false "Field delimiter: comma, semicolon, tab, pipe"
+// @Param encoding query string false "Character encoding: utf-8, iso-8859-1, iso-8859-15, windows-1252"
+// @Param bom query bool false "Prefix UTF-8 output with a byte order mark"
+// @Param template_id query string false "Report template to brand the export with (defaults to the default template)"
 // @Success 200 {file} file
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -326,8 +836,31 @@ func (h *ReportHandler) ExportAuditReportCSV(c *fiber.Ctx) error {
 		})
 	}
 
+	filter, err := reportFilter(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Resolve CSV format options (query params override saved preferences)
+	exportOpts, err := resolveCSVExportOptions(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Resolve the report template's branding and sections
+	branding, err := csvReportBranding(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Generate report
-	report, err := h.reportService.GenerateAuditReport(startDate, endDate)
+	report, err := h.reportService.WithContext(c.UserContext()).WithFilter(filter).GenerateAuditReport(startDate, endDate)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to generate audit report")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -335,27 +868,27 @@ func (h *ReportHandler) ExportAuditReportCSV(c *fiber.Ctx) error {
 		})
 	}
 
-	// Set headers for CSV download
-	c.Set("Content-Type", "text/csv")
-	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=audit-report-%s.csv", time.Now().Format("2006-01-02")))
-
-	// Create CSV writer
-	writer := csv.NewWriter(c)
-	defer writer.Flush()
+	// Create CSV writer honoring delimiter/encoding preferences
+	writer, flush := newCSVExportWriter(c, fmt.Sprintf("audit-report-%s.csv", time.Now().Format("2006-01-02")), exportOpts)
+	defer flush()
+	writeCSVBranding(writer, branding)
 
-	// Write audit summary
-	writer.Write([]string{"AUDIT REPORT SUMMARY"})
-	writer.Write([]string{"Generated At", report.GeneratedAt.Format(time.RFC3339)})
-	writer.Write([]string{"Report Period", fmt.Sprintf("%s to %s", report.ReportPeriodStart.Format("2006-01-02"), report.ReportPeriodEnd.Format("2006-01-02"))})
-	writer.Write([]string{"Total Vulnerabilities", fmt.Sprintf("%d", report.TotalVulnerabilities)})
-	writer.Write([]string{"Vulnerabilities Resolved", fmt.Sprintf("%d", report.VulnerabilitiesResolved)})
-	writer.Write([]string{"Vulnerabilities Open", fmt.Sprintf("%d", report.VulnerabilitiesOpen)})
-	writer.Write([]string{"Completed Assessments", fmt.Sprintf("%d", report.CompletedAssessments)})
-	writer.Write([]string{"Documented Findings", fmt.Sprintf("%d", report.DocumentedFindings)})
-	writer.Write([]string{"Verified Remediations", fmt.Sprintf("%d", report.VerifiedRemediations)})
-	writer.Write([]string{"Assets Scanned", fmt.Sprintf("%d", report.AssetsScanned)})
-	writer.Write([]string{"Remediation Compliance", fmt.Sprintf("%.2f%%", report.RemediationCompliance)})
-	writer.Write([]string{})
+	if branding.Sections.Summary {
+		// Write audit summary
+		writer.Write([]string{"AUDIT REPORT SUMMARY"})
+		writer.Write([]string{"Generated At", report.GeneratedAt.Format(time.RFC3339)})
+		writer.Write([]string{"Report Period", fmt.Sprintf("%s to %s", report.ReportPeriodStart.Format("2006-01-02"), report.ReportPeriodEnd.Format("2006-01-02"))})
+		writer.Write([]string{"Total Vulnerabilities", fmt.Sprintf("%d", report.TotalVulnerabilities)})
+		writer.Write([]string{"Vulnerabilities Resolved", fmt.Sprintf("%d", report.VulnerabilitiesResolved)})
+		writer.Write([]string{"Vulnerabilities Open", fmt.Sprintf("%d", report.VulnerabilitiesOpen)})
+		writer.Write([]string{"Completed Assessments", fmt.Sprintf("%d", report.CompletedAssessments)})
+		writer.Write([]string{"Documented Findings", fmt.Sprintf("%d", report.DocumentedFindings)})
+		writer.Write([]string{"Verified Remediations", fmt.Sprintf("%d", report.VerifiedRemediations)})
+		writer.Write([]string{"Assets Scanned", fmt.Sprintf("%d", report.AssetsScanned)})
+		writer.Write([]string{"Remediation Compliance", fmt.Sprintf("%.2f%%", report.RemediationCompliance)})
+		writer.Write([]string{"Policy Violations", fmt.Sprintf("%d", report.PolicyViolations)})
+		writer.Write([]string{})
+	}
 
 	// Compliance frameworks
 	writer.Write([]string{"COMPLIANCE FRAMEWORKS"})
@@ -369,35 +902,160 @@ func (h *ReportHandler) ExportAuditReportCSV(c *fiber.Ctx) error {
 	}
 	writer.Write([]string{})
 
-	// Audit trail
-	writer.Write([]string{"AUDIT TRAIL"})
-	writer.Write([]string{"Timestamp", "Action", "Resource", "User", "Description"})
-	for _, entry := range report.AuditTrail {
+	if branding.Sections.Findings {
+		// Audit trail
+		writer.Write([]string{"AUDIT TRAIL"})
+		writer.Write([]string{"Timestamp", "Action", "Resource", "User", "Description"})
+		for _, entry := range report.AuditTrail {
+			writer.Write([]string{
+				entry.Timestamp.Format(time.RFC3339),
+				entry.Action,
+				entry.Resource,
+				entry.User,
+				entry.Description,
+			})
+		}
+		writer.Write([]string{})
+	}
+
+	if branding.Sections.Evidence {
+		// Evidence chain of custody
+		writer.Write([]string{"EVIDENCE INTEGRITY"})
+		writer.Write([]string{"Attachment ID", "Finding ID", "File", "SHA-256", "Original SHA-256", "Uploaded By", "Uploaded At", "Last Verification", "Last Verified At"})
+		for _, evidence := range report.EvidenceAttachments {
+			lastVerifiedAt := ""
+			if evidence.LastVerifiedAt != nil {
+				lastVerifiedAt = evidence.LastVerifiedAt.Format(time.RFC3339)
+			}
+			writer.Write([]string{
+				evidence.AttachmentID,
+				evidence.FindingID,
+				evidence.OriginalName,
+				evidence.SHA256,
+				evidence.OriginalSHA256,
+				evidence.UploadedBy,
+				evidence.UploadedAt.Format(time.RFC3339),
+				evidence.LastVerification,
+				lastVerifiedAt,
+			})
+		}
+		writer.Write([]string{})
+	}
+
+	if branding.Sections.Retests {
+		// Retest outcomes
+		writer.Write([]string{"RETESTS"})
+		writer.Write([]string{"Assessment", "Finding", "Round", "Outcome", "Retested By", "Retested At", "Evidence"})
+		for _, retest := range report.Retests {
+			writer.Write([]string{
+				retest.AssessmentName,
+				retest.VulnerabilityTitle,
+				fmt.Sprintf("%d", retest.Round),
+				retest.Outcome,
+				retest.RetestedBy,
+				retest.RetestedAt.Format(time.RFC3339),
+				retest.Evidence,
+			})
+		}
+		writer.Write([]string{})
+	}
+
+	// Policy violations
+	writer.Write([]string{"POLICY VIOLATIONS"})
+	writer.Write([]string{"Rule", "Finding", "Severity", "Status", "Due At", "Detected At", "Resolved At", "Resolution"})
+	for _, violation := range report.Violations {
+		resolvedAt := ""
+		if violation.ResolvedAt != nil {
+			resolvedAt = violation.ResolvedAt.Format(time.RFC3339)
+		}
 		writer.Write([]string{
-			entry.Timestamp.Format(time.RFC3339),
-			entry.Action,
-			entry.Resource,
-			entry.User,
-			entry.Description,
+			violation.RuleName,
+			violation.VulnerabilityTitle,
+			violation.Severity,
+			violation.Status,
+			violation.DueAt.Format(time.RFC3339),
+			violation.DetectedAt.Format(time.RFC3339),
+			resolvedAt,
+			violation.Resolution,
 		})
 	}
 
 	return nil
 }
 
-// Helper function to parse date range from query parameters
+// csvReportBranding returns the branding of the ?template_id= report template, else of the
+// default template
+func csvReportBranding(c *fiber.Ctx) (services.ReportBranding, error) {
+	var templateID *uuid.UUID
+	if raw := c.Query("template_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return services.ReportBranding{}, fmt.Errorf("invalid template_id")
+		}
+		templateID = &id
+	}
+	reportTemplate, err := services.NewReportTemplateService(database.GetDB()).WithContext(c.UserContext()).ResolveTemplate(templateID)
+	if err != nil {
+		return services.ReportBranding{}, err
+	}
+	return services.NewReportBranding(reportTemplate, nil), nil
+}
+
+// writeCSVBranding writes the company name and intro text of a report template above an export
+func writeCSVBranding(writer *csv.Writer, branding services.ReportBranding) {
+	if branding.CompanyName == "" && branding.IntroText == "" {
+		return
+	}
+	if branding.CompanyName != "" {
+		writer.Write([]string{"Prepared By", branding.CompanyName})
+	}
+	if branding.IntroText != "" {
+		writer.Write([]string{branding.IntroText})
+	}
+	writer.Write([]string{})
+}
+
+// reportFilter returns the assets a report covers from the asset_group, environment, tags and
+// owner_team query parameters
+func reportFilter(c *fiber.Ctx) (services.ReportFilter, error) {
+	return services.ParseReportFilter(c.Query("asset_group"), c.Query("environment"), c.Query("tags"), c.Query("owner_team"))
+}
+
+// reportLocation returns the timezone whose days the report covers: the ?timezone= parameter,
+// else the user's preferred timezone
+func reportLocation(c *fiber.Ctx) (*time.Location, error) {
+	if tz := c.Query("timezone"); tz != "" {
+		name, err := services.NormalizeTimezone(tz)
+		if err != nil {
+			return nil, err
+		}
+		return time.LoadLocation(name)
+	}
+	if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		return services.NewUserPreferenceService(database.GetDB()).GetLocation(userID), nil
+	}
+	return time.UTC, nil
+}
+
+// Helper function to parse date range from query parameters. Dates are days in the user's
+// timezone (see reportLocation).
 func (h *ReportHandler) parseDateRange(c *fiber.Ctx) (time.Time, time.Time, error) {
+	loc, err := reportLocation(c)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
 	// Get query parameters
 	startDateStr := c.Query("start_date")
 	endDateStr := c.Query("end_date")
 
 	// Default to last 30 days if not provided
-	endDate := time.Now()
+	endDate := time.Now().In(loc)
 	startDate := endDate.AddDate(0, 0, -30)
 
 	// Parse start date if provided
 	if startDateStr != "" {
-		parsed, err := time.Parse("2006-01-02", startDateStr)
+		parsed, err := time.ParseInLocation("2006-01-02", startDateStr, loc)
 		if err != nil {
 			return time.Time{}, time.Time{}, fmt.Errorf("invalid start_date format, use YYYY-MM-DD")
 		}
@@ -406,7 +1064,7 @@ func (h *ReportHandler) parseDateRange(c *fiber.Ctx) (time.Time, time.Time, erro
 
 	// Parse end date if provided
 	if endDateStr != "" {
-		parsed, err := time.Parse("2006-01-02", endDateStr)
+		parsed, err := time.ParseInLocation("2006-01-02", endDateStr, loc)
 		if err != nil {
 			return time.Time{}, time.Time{}, fmt.Errorf("invalid end_date format, use YYYY-MM-DD")
 		}
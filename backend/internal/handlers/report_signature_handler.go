@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"mime/multipart"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/signing"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// ReportSignatureHandler lets report recipients check that a report came unmodified from this
+// instance
+type ReportSignatureHandler struct{}
+
+// NewReportSignatureHandler creates a new report signature handler
+func NewReportSignatureHandler() *ReportSignatureHandler {
+	return &ReportSignatureHandler{}
+}
+
+// GetSigningKey returns the public key reports are signed with, for verification offline
+// GET /api/v1/reports/signing-key
+func (h *ReportSignatureHandler) GetSigningKey(c *fiber.Ctx) error {
+	signer := services.ReportSigner()
+	if signer == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Report signing is not configured",
+		})
+	}
+
+	public := signer.Active().PublicKey()
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to encode report signing key")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to encode report signing key",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": fiber.Map{
+			"algorithm":      signing.Algorithm,
+			"key_id":         signer.Active().KeyID(),
+			"public_key":     base64.StdEncoding.EncodeToString(public),
+			"public_key_pem": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		},
+	})
+}
+
+// VerifyReport checks a report against its detached signature: the .sig document served with
+// generated reports, or the X-Report-Signature header of a CSV export
+// POST /api/v1/reports/verify (multipart: file, signature as a file or form field)
+func (h *ReportSignatureHandler) VerifyReport(c *fiber.Ctx) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return middleware.ValidationError(c, "file is required", nil)
+	}
+	data, err := readFormFile(file)
+	if err != nil {
+		return middleware.ValidationError(c, "Failed to read file", nil)
+	}
+
+	var encoded []byte
+	if signatureFile, err := c.FormFile("signature"); err == nil {
+		if encoded, err = readFormFile(signatureFile); err != nil {
+			return middleware.ValidationError(c, "Failed to read signature", nil)
+		}
+	} else {
+		encoded = []byte(c.FormValue("signature"))
+	}
+	if len(encoded) == 0 {
+		return middleware.ValidationError(c, "signature is required", nil)
+	}
+	signature, err := services.DecodeReportSignature(encoded)
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	result, err := services.VerifyReportArtifact(data, signature)
+	if err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Report signing is not configured",
+		})
+	}
+
+	utils.Logger.Info().
+		Bool("valid", result.Valid).
+		Str("key_id", result.KeyID).
+		Str("sha256", result.SHA256).
+		Str("ip", c.IP()).
+		Msg("Report signature checked")
+	return c.JSON(fiber.Map{
+		"data": result,
+	})
+}
+
+// readFormFile reads an uploaded multipart file
+func readFormFile(file *multipart.FileHeader) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
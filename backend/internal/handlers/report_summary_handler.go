@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// ReportSummaryHandler handles natural-language report summary endpoints
+type ReportSummaryHandler struct {
+	service *services.ReportSummaryService
+	reports *ReportHandler // Parses report date ranges
+}
+
+// NewReportSummaryHandler creates a new report summary handler
+func NewReportSummaryHandler(service *services.ReportSummaryService) *ReportSummaryHandler {
+	return &ReportSummaryHandler{service: service, reports: &ReportHandler{}}
+}
+
+// SummarizeExecutiveReport writes an executive summary and key-risk narrative of the executive
+// report with the configured language model, and stores it alongside the report
+// @Summary Summarize executive report
+// @Tags Reports
+// @Produce json
+// @Param start_date query string false "Start date (YYYY-MM-DD)" default:"30 days ago"
+// @Param end_date query string false "End date (YYYY-MM-DD)" default:"today"
+// @Success 201 {object} models.ReportSummary
+// @Failure 400 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/reports/executive/summarize [post]
+// @Security BearerAuth
+func (h *ReportSummaryHandler) SummarizeExecutiveReport(c *fiber.Ctx) error {
+	startDate, endDate, err := h.reports.parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	user := c.Locals("user").(*models.User)
+	summary, err := h.service.WithContext(c.UserContext()).SummarizeExecutiveReport(startDate, endDate, user.ID)
+	if err != nil {
+		return reportSummaryErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Summary generated successfully",
+		"data":    summary,
+	})
+}
+
+// SummarizeAssessment writes an executive summary and key-risk narrative of an assessment with
+// the configured language model, and stores it alongside the assessment
+// @Summary Summarize assessment
+// @Tags Assessments
+// @Produce json
+// @Param id path string true "Assessment ID"
+// @Success 201 {object} models.ReportSummary
+// @Failure 404 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/assessments/{id}/summarize [post]
+// @Security BearerAuth
+func (h *ReportSummaryHandler) SummarizeAssessment(c *fiber.Ctx) error {
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid assessment ID",
+		})
+	}
+
+	user := c.Locals("user").(*models.User)
+	summary, err := h.service.WithContext(c.UserContext()).SummarizeAssessment(assessmentID, user.ID)
+	if err != nil {
+		return reportSummaryErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Summary generated successfully",
+		"data":    summary,
+	})
+}
+
+// ListAssessmentSummaries lists the stored summaries of an assessment, newest first
+// @Summary List assessment summaries
+// @Tags Assessments
+// @Produce json
+// @Param id path string true "Assessment ID"
+// @Success 200 {array} models.ReportSummary
+// @Router /api/v1/assessments/{id}/summaries [get]
+// @Security BearerAuth
+func (h *ReportSummaryHandler) ListAssessmentSummaries(c *fiber.Ctx) error {
+	assessmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid assessment ID",
+		})
+	}
+
+	summaries, err := h.service.WithContext(c.UserContext()).ListAssessmentSummaries(assessmentID)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to list assessment summaries")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list summaries",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": summaries,
+	})
+}
+
+// reportSummaryErrorResponse maps report summary service errors to HTTP responses
+func reportSummaryErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrSummarizerNotConfigured):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "Report summaries are not configured; set the report_summarizer system setting",
+		})
+	case strings.Contains(err.Error(), "assessment not found"):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Assessment not found",
+		})
+	case strings.HasPrefix(err.Error(), "invalid report summarizer settings"):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case strings.HasPrefix(err.Error(), "summary generation failed"):
+		utils.Logger.Warn().Err(err).Msg("Language model failed to summarize report")
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": "The language model failed to generate a summary",
+		})
+	}
+	utils.Logger.Error().Err(err).Msg("Failed to summarize report")
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Failed to generate summary",
+	})
+}
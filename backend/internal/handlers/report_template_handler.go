@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// ReportTemplateHandler handles the templates that brand generated reports and exports
+type ReportTemplateHandler struct {
+	service *services.ReportTemplateService
+}
+
+// NewReportTemplateHandler creates a new report template handler
+func NewReportTemplateHandler() *ReportTemplateHandler {
+	return &ReportTemplateHandler{
+		service: services.NewReportTemplateService(database.GetDB()),
+	}
+}
+
+// ListTemplates lists report templates, the default first
+// GET /api/v1/reports/templates
+func (h *ReportTemplateHandler) ListTemplates(c *fiber.Ctx) error {
+	templates, err := h.service.WithContext(c.UserContext()).ListTemplates()
+	if err != nil {
+		return policyErrorResponse(c, err, "Report template", "Failed to list report templates")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": templates,
+	})
+}
+
+// GetTemplate returns a report template
+// GET /api/v1/reports/templates/:id
+func (h *ReportTemplateHandler) GetTemplate(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid report template ID", nil)
+	}
+
+	template, err := h.service.WithContext(c.UserContext()).GetTemplate(id)
+	if err != nil {
+		return policyErrorResponse(c, err, "Report template", "Failed to get report template")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": template,
+	})
+}
+
+// GetLogo returns a report template's logo image
+// GET /api/v1/reports/templates/:id/logo
+func (h *ReportTemplateHandler) GetLogo(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid report template ID", nil)
+	}
+
+	template, err := h.service.WithContext(c.UserContext()).GetTemplate(id)
+	if err != nil {
+		return policyErrorResponse(c, err, "Report template", "Failed to get report template logo")
+	}
+	if !template.HasLogo {
+		return middleware.NotFoundError(c, "Report template logo")
+	}
+
+	c.Set("Content-Type", template.LogoMimeType)
+	c.Set("Cache-Control", "private, no-cache")
+	return c.Send(template.Logo)
+}
+
+// CreateTemplate creates a report template; making it the default applies it to reports that
+// don't name a template
+// POST /api/v1/admin/report-templates
+func (h *ReportTemplateHandler) CreateTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.ReportTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	template, err := h.service.WithContext(c.UserContext()).CreateTemplate(req, userID)
+	if err != nil {
+		return policyErrorResponse(c, err, "Report template", "Failed to create report template")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Report template created successfully",
+		"data":    template,
+	})
+}
+
+// UpdateTemplate updates a report template
+// PUT /api/v1/admin/report-templates/:id
+func (h *ReportTemplateHandler) UpdateTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid report template ID", nil)
+	}
+
+	var req services.ReportTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	template, err := h.service.WithContext(c.UserContext()).UpdateTemplate(id, req, userID)
+	if err != nil {
+		return policyErrorResponse(c, err, "Report template", "Failed to update report template")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Report template updated successfully",
+		"data":    template,
+	})
+}
+
+// DeleteTemplate deletes a report template
+// DELETE /api/v1/admin/report-templates/:id
+func (h *ReportTemplateHandler) DeleteTemplate(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid report template ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).DeleteTemplate(id, userID); err != nil {
+		return policyErrorResponse(c, err, "Report template", "Failed to delete report template")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Report template deleted successfully",
+	})
+}
+
+// UploadLogo sets a report template's logo (PNG, JPEG or GIF, at most 1 MB) from the "logo"
+// multipart field or the raw request body
+// PUT /api/v1/admin/report-templates/:id/logo
+func (h *ReportTemplateHandler) UploadLogo(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid report template ID", nil)
+	}
+
+	data := c.Body()
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		file, err := c.FormFile("logo")
+		if err != nil {
+			return middleware.ValidationError(c, "No logo uploaded", nil)
+		}
+		if file.Size > services.MaxReportLogoSize {
+			return middleware.ValidationError(c, "Logo exceeds the maximum size of 1 MB", nil)
+		}
+		src, err := file.Open()
+		if err != nil {
+			utils.Logger.Error().Err(err).Msg("Failed to open uploaded report template logo")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to process uploaded file",
+			})
+		}
+		defer src.Close()
+		if data, err = io.ReadAll(src); err != nil {
+			utils.Logger.Error().Err(err).Msg("Failed to read uploaded report template logo")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to read uploaded file",
+			})
+		}
+	}
+	if data == nil {
+		// An empty upload is rejected rather than removing the logo
+		data = []byte{}
+	}
+
+	template, err := h.service.WithContext(c.UserContext()).SetLogo(id, data, userID)
+	if err != nil {
+		return policyErrorResponse(c, err, "Report template", "Failed to update report template logo")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Report template logo updated successfully",
+		"data":    template,
+	})
+}
+
+// DeleteLogo removes a report template's logo
+// DELETE /api/v1/admin/report-templates/:id/logo
+func (h *ReportTemplateHandler) DeleteLogo(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid report template ID", nil)
+	}
+
+	template, err := h.service.WithContext(c.UserContext()).SetLogo(id, nil, userID)
+	if err != nil {
+		return policyErrorResponse(c, err, "Report template", "Failed to remove report template logo")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Report template logo removed successfully",
+		"data":    template,
+	})
+}
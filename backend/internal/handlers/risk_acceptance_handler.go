@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// RiskAcceptanceHandler handles risk acceptance requests and the review queue
+type RiskAcceptanceHandler struct {
+	service *services.RiskAcceptanceService
+}
+
+// NewRiskAcceptanceHandler creates a new risk acceptance handler
+func NewRiskAcceptanceHandler() *RiskAcceptanceHandler {
+	return &RiskAcceptanceHandler{
+		service: services.NewRiskAcceptanceService(database.GetDB()),
+	}
+}
+
+// ReviewRiskAcceptanceRequest represents an approve or reject request
+type ReviewRiskAcceptanceRequest struct {
+	Notes string `json:"notes"`
+}
+
+// riskAcceptanceErrorResponse maps risk acceptance service errors to HTTP responses
+func riskAcceptanceErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		return middleware.NotFoundError(c, strings.TrimSuffix(msg, " not found"))
+	case strings.Contains(msg, "only the requester"), strings.Contains(msg, "cannot review their own"):
+		return middleware.ForbiddenError(c, msg)
+	case strings.Contains(msg, "not pending"), strings.Contains(msg, "already"):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": msg,
+		})
+	case strings.Contains(msg, "required"), strings.Contains(msg, "expires_at"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// ListRiskAcceptances returns the risk acceptance review queue
+// GET /api/v1/risk-acceptances?status=PENDING&finding_id=&mine=true
+func (h *RiskAcceptanceHandler) ListRiskAcceptances(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Default to the pending review queue
+	filters := services.RiskAcceptanceFilters{
+		Status: c.Query("status", "PENDING"),
+	}
+	if strings.EqualFold(filters.Status, "all") {
+		filters.Status = ""
+	}
+	if findingID := c.Query("finding_id"); findingID != "" {
+		id, err := uuid.Parse(findingID)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid finding ID", nil)
+		}
+		filters.FindingID = &id
+	}
+	if c.QueryBool("mine", false) {
+		filters.RequestedByID = &userID
+	}
+
+	acceptances, total, err := h.service.WithContext(c.UserContext()).ListRiskAcceptances(filters, page, limit)
+	if err != nil {
+		return riskAcceptanceErrorResponse(c, err, "Failed to list risk acceptances")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": acceptances,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// GetRiskAcceptance returns a single risk acceptance
+// GET /api/v1/risk-acceptances/:id
+func (h *RiskAcceptanceHandler) GetRiskAcceptance(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid risk acceptance ID", nil)
+	}
+
+	acceptance, err := h.service.WithContext(c.UserContext()).GetRiskAcceptance(id)
+	if err != nil {
+		return riskAcceptanceErrorResponse(c, err, "Failed to get risk acceptance")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": acceptance,
+	})
+}
+
+// CreateRiskAcceptance submits a risk acceptance request for review
+// POST /api/v1/risk-acceptances
+func (h *RiskAcceptanceHandler) CreateRiskAcceptance(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.CreateRiskAcceptanceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	if req.FindingID == uuid.Nil {
+		return middleware.ValidationError(c, "finding_id is required", nil)
+	}
+	req.Justification = utils.SanitizeString(req.Justification)
+	req.CompensatingControls = utils.SanitizeString(req.CompensatingControls)
+
+	acceptance, err := h.service.WithContext(c.UserContext()).RequestRiskAcceptance(req, userID)
+	if err != nil {
+		return riskAcceptanceErrorResponse(c, err, "Failed to request risk acceptance")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Risk acceptance submitted for review",
+		"data":    acceptance,
+	})
+}
+
+// ApproveRiskAcceptance approves a pending request
+// POST /api/v1/risk-acceptances/:id/approve
+func (h *RiskAcceptanceHandler) ApproveRiskAcceptance(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid risk acceptance ID", nil)
+	}
+
+	var req ReviewRiskAcceptanceRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return middleware.ValidationError(c, "Invalid request body", nil)
+		}
+	}
+
+	acceptance, err := h.service.WithContext(c.UserContext()).ApproveRiskAcceptance(id, userID, utils.SanitizeString(req.Notes))
+	if err != nil {
+		return riskAcceptanceErrorResponse(c, err, "Failed to approve risk acceptance")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Risk acceptance approved",
+		"data":    acceptance,
+	})
+}
+
+// RejectRiskAcceptance rejects a pending request
+// POST /api/v1/risk-acceptances/:id/reject
+func (h *RiskAcceptanceHandler) RejectRiskAcceptance(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid risk acceptance ID", nil)
+	}
+
+	var req ReviewRiskAcceptanceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	acceptance, err := h.service.WithContext(c.UserContext()).RejectRiskAcceptance(id, userID, utils.SanitizeString(req.Notes))
+	if err != nil {
+		return riskAcceptanceErrorResponse(c, err, "Failed to reject risk acceptance")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Risk acceptance rejected",
+		"data":    acceptance,
+	})
+}
+
+// CancelRiskAcceptance withdraws the caller's own pending request
+// POST /api/v1/risk-acceptances/:id/cancel
+func (h *RiskAcceptanceHandler) CancelRiskAcceptance(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid risk acceptance ID", nil)
+	}
+
+	acceptance, err := h.service.WithContext(c.UserContext()).CancelRiskAcceptance(id, userID)
+	if err != nil {
+		return riskAcceptanceErrorResponse(c, err, "Failed to cancel risk acceptance")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Risk acceptance cancelled",
+		"data":    acceptance,
+	})
+}
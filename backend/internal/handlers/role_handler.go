@@ -22,19 +22,61 @@ func NewRoleHandler() *RoleHandler {
 
 // CreateRoleRequest represents a role creation request
 type CreateRoleRequest struct {
-	Name        string               `json:"name" validate:"required,min=2,max=50"`
-	DisplayName string               `json:"display_name" validate:"required,min=2,max=100"`
-	Description string               `json:"description,omitempty" validate:"max=255"`
-	Level       int                  `json:"level" validate:"required,min=0,max=1000"`
-	Permissions models.PermissionMap `json:"permissions"`
+	Name              string                    `json:"name" validate:"required,min=2,max=50"`
+	DisplayName       string                    `json:"display_name" validate:"required,min=2,max=100"`
+	Description       string                    `json:"description,omitempty" validate:"max=255"`
+	Level             int                       `json:"level" validate:"required,min=0,max=1000"`
+	Permissions       models.PermissionMap      `json:"permissions"`
+	DeniedPermissions models.PermissionMap      `json:"denied_permissions,omitempty"`
+	Constraints       []models.AccessConstraint `json:"constraints,omitempty"`
+	ParentID          *uuid.UUID                `json:"parent_id,omitempty"`
 }
 
 // UpdateRoleRequest represents a role update request
 type UpdateRoleRequest struct {
-	DisplayName string               `json:"display_name" validate:"required,min=2,max=100"`
-	Description string               `json:"description,omitempty" validate:"max=255"`
-	Level       int                  `json:"level" validate:"required,min=0,max=1000"`
-	Permissions models.PermissionMap `json:"permissions"`
+	DisplayName       string                    `json:"display_name" validate:"required,min=2,max=100"`
+	Description       string                    `json:"description,omitempty" validate:"max=255"`
+	Level             int                       `json:"level" validate:"required,min=0,max=1000"`
+	Permissions       models.PermissionMap      `json:"permissions"`
+	DeniedPermissions models.PermissionMap      `json:"denied_permissions,omitempty"`
+	Constraints       []models.AccessConstraint `json:"constraints,omitempty"`
+	ParentID          *uuid.UUID                `json:"parent_id,omitempty"`
+}
+
+// ListPermissionCatalog returns every resource:action pair a role can grant or deny
+func (h *RoleHandler) ListPermissionCatalog(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"resources": models.PermissionCatalog,
+		"wildcard":  models.PermissionWildcard,
+	})
+}
+
+// GetEffectivePermissions returns a role's permissions after inheritance and deny rules
+func (h *RoleHandler) GetEffectivePermissions(c *fiber.Ctx) error {
+	roleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role ID",
+		})
+	}
+
+	effective, err := h.roleService.GetEffectivePermissions(roleID)
+	if err != nil {
+		if err.Error() == "role not found" {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Role not found",
+			})
+		}
+		utils.Logger.Error().Err(err).Str("role_id", roleID.String()).Msg("Failed to resolve role permissions")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to resolve role permissions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"role_id":     roleID,
+		"permissions": effective,
+	})
 }
 
 // ListRoles retrieves all roles
@@ -95,13 +137,15 @@ func (h *RoleHandler) CreateRole(c *fiber.Ctx) error {
 		})
 	}
 
-	role, err := h.roleService.CreateRole(
-		req.Name,
-		req.DisplayName,
-		req.Description,
-		req.Level,
-		req.Permissions,
-	)
+	role, err := h.roleService.CreateRole(req.Name, services.RoleDefinition{
+		DisplayName:       req.DisplayName,
+		Description:       req.Description,
+		Level:             req.Level,
+		Permissions:       req.Permissions,
+		DeniedPermissions: req.DeniedPermissions,
+		Constraints:       req.Constraints,
+		ParentID:          req.ParentID,
+	})
 	if err != nil {
 		utils.Logger.Error().Err(err).Str("role_name", req.Name).Msg("Failed to create role")
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -138,13 +182,15 @@ func (h *RoleHandler) UpdateRole(c *fiber.Ctx) error {
 		})
 	}
 
-	role, err := h.roleService.UpdateRole(
-		roleID,
-		req.DisplayName,
-		req.Description,
-		req.Level,
-		req.Permissions,
-	)
+	role, err := h.roleService.UpdateRole(roleID, services.RoleDefinition{
+		DisplayName:       req.DisplayName,
+		Description:       req.Description,
+		Level:             req.Level,
+		Permissions:       req.Permissions,
+		DeniedPermissions: req.DeniedPermissions,
+		Constraints:       req.Constraints,
+		ParentID:          req.ParentID,
+	})
 	if err != nil {
 		utils.Logger.Error().Err(err).Str("role_id", roleID.String()).Msg("Failed to update role")
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
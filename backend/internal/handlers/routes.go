@@ -6,24 +6,55 @@ import (
 	"github.com/cyops/cyops-backend/internal/services"
 	"github.com/cyops/cyops-backend/pkg/config"
 	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/faultinject"
 )
 
 // SetupRoutes configures all application routes
 func SetupRoutes(app *fiber.App, cfg *config.Config) {
+	// Request bodies are checked against their route's limit before they are read
+	app.Use(middleware.RequestBodyLimit(int64(cfg.RequestBodyLimitMB)*1024*1024, bodyLimitRules(cfg)))
+
 	// Health check routes at root level
 	healthHandler := NewHealthHandler()
 	app.Get("/health", healthHandler.Health)
 	app.Get("/health/ready", healthHandler.Ready)
 	app.Get("/health/live", healthHandler.Live)
 
-	// API v1 group
-	api := app.Group("/api/v1")
+	// Every API version serves the same routes; versions differ in their middleware, e.g. the
+	// serializer that shapes responses
+	for _, version := range apiVersions(cfg) {
+		api := app.Group("/api/"+version.name, version.middleware...)
+		setupAPIRoutes(api, cfg, version)
+	}
+}
+
+// uploadOverhead is the room left for the multipart encoding and form fields around a file
+const uploadOverhead = 1024 * 1024
+
+// bodyLimitRules returns the body limits of the routes that accept more than the JSON API
+// default: file uploads, whose files are also checked against their own limits
+func bodyLimitRules(cfg *config.Config) []middleware.BodyLimitRule {
+	nessusLimit := int64(cfg.NessusUploadLimitMB)*1024*1024 + uploadOverhead
+	attachmentLimit := int64(services.MaxAttachmentSizeBytes) + uploadOverhead
+	return []middleware.BodyLimitRule{
+		{Method: fiber.MethodPost, Path: "/vulnerabilities/import/nessus", Limit: nessusLimit},
+		{Method: fiber.MethodPost, Path: "/vulnerabilities/import/nessus/preview", Limit: nessusLimit},
+		{Method: fiber.MethodPost, Path: "/vulnerabilities/:id/attachments", Limit: attachmentLimit},
+		{Method: fiber.MethodPost, Path: "/vulnerabilities/findings/:id/attachments", Limit: attachmentLimit},
+		{Method: fiber.MethodPost, Path: "/assessments/:id/reports", Limit: attachmentLimit},
+		{Method: fiber.MethodPost, Path: "/reports/verify", Limit: attachmentLimit},
+		{Method: fiber.MethodPost, Path: "/assets/:id/sbom", Limit: 50*1024*1024 + uploadOverhead},
+		{Method: fiber.MethodPost, Path: "/inbound-email", Limit: 25*1024*1024 + uploadOverhead},
+	}
+}
 
+// setupAPIRoutes registers the routes of one API version
+func setupAPIRoutes(api fiber.Router, cfg *config.Config, version apiVersion) {
 	// API info endpoint
 	api.Get("/", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
-			"message": "Welcome to Auth API v1",
-			"version": "1.0.0",
+			"message": "Welcome to Auth API " + version.name,
+			"version": version.release,
 			"status":  "operational",
 		})
 	})
@@ -44,9 +75,21 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	twoFactor := api.Group("/auth/2fa")
 	SetupTwoFactorRoutes(twoFactor)
 
+	// Policy acceptance and first-login setup steps (protected)
+	onboarding := api.Group("/onboarding")
+	SetupOnboardingRoutes(onboarding)
+
+	// Organization (tenant) routes (protected)
+	organizations := api.Group("/organizations")
+	SetupOrganizationRoutes(organizations)
+
+	// Team routes (protected)
+	teams := api.Group("/teams")
+	SetupTeamRoutes(teams)
+
 	// Admin routes (protected, admin only)
 	admin := api.Group("/admin")
-	SetupAdminRoutes(admin)
+	SetupAdminRoutes(admin, cfg)
 
 	// Vulnerability routes (protected)
 	vulnerabilities := api.Group("/vulnerabilities")
@@ -60,6 +103,18 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	assets := api.Group("/assets")
 	SetupAssetRoutes(assets)
 
+	// Asset group routes (protected)
+	assetGroups := api.Group("/asset-groups")
+	SetupAssetGroupRoutes(assetGroups)
+
+	// Business service (application) routes (protected)
+	businessServices := api.Group("/business-services")
+	SetupBusinessServiceRoutes(businessServices)
+
+	// Asset criticality scoring routes (protected)
+	assetScoring := api.Group("/asset-scoring")
+	SetupCriticalityScoringRoutes(assetScoring)
+
 	// Assessment routes (protected)
 	assessments := api.Group("/assessments")
 	SetupAssessmentRoutes(assessments)
@@ -68,17 +123,93 @@ func SetupRoutes(app *fiber.App, cfg *config.Config) {
 	reports := api.Group("/reports")
 	SetupReportRoutes(reports)
 
+	// Dashboard routes (protected)
+	dashboard := api.Group("/dashboard")
+	SetupDashboardRoutes(dashboard)
+
+	// GraphQL routes (protected, optional)
+	if cfg.GraphQLEnabled {
+		graphQL := api.Group("/graphql")
+		SetupGraphQLRoutes(graphQL)
+	}
+
+	// Configurable dashboard routes (protected)
+	dashboards := api.Group("/dashboards")
+	SetupCustomDashboardRoutes(dashboards)
+
+	// Risk acceptance workflow routes (protected)
+	riskAcceptances := api.Group("/risk-acceptances")
+	SetupRiskAcceptanceRoutes(riskAcceptances)
+
+	// Saved view routes (protected)
+	savedViews := api.Group("/saved-views")
+	SetupSavedViewRoutes(savedViews)
+
+	// Suppression rule routes (protected)
+	suppressionRules := api.Group("/suppression-rules")
+	SetupSuppressionRuleRoutes(suppressionRules)
+
+	// Import job routes (protected)
+	importJobs := api.Group("/import-jobs")
+	SetupImportJobRoutes(importJobs)
+
+	// Policy rule and violation routes (protected)
+	policyRules := api.Group("/policy-rules")
+	SetupPolicyRuleRoutes(policyRules)
+	policyViolations := api.Group("/policy-violations")
+	SetupPolicyViolationRoutes(policyViolations)
+
+	// Escalation policy routes (protected)
+	escalationPolicies := api.Group("/escalation-policies")
+	SetupEscalationPolicyRoutes(escalationPolicies)
+
+	// Assignment rule routes (protected)
+	assignmentRules := api.Group("/assignment-rules")
+	SetupAssignmentRuleRoutes(assignmentRules)
+
+	// Network range routes (protected)
+	networkRanges := api.Group("/network-ranges")
+	SetupNetworkRangeRoutes(networkRanges)
+
+	// Endpoint agent routes (API keys only)
+	agent := api.Group("/agent")
+	SetupAgentRoutes(agent)
+
+	// Inbound email webhook (authenticated by its webhook secret)
+	inboundEmail := api.Group("/inbound-email")
+	SetupInboundEmailRoutes(inboundEmail)
+
+	// Email delivery status webhooks (authenticated by their webhook secret)
+	emailWebhooks := api.Group("/email-webhooks")
+	SetupEmailWebhookRoutes(emailWebhooks)
+
+	// Public disclosure form and its triage queue
+	disclosures := api.Group("/disclosures")
+	SetupDisclosureRoutes(disclosures, cfg)
+
+	// Read-only auditor portal (authenticated by an assessment share token)
+	auditorPortal := api.Group("/auditor-portal")
+	SetupAuditorPortalRoutes(auditorPortal)
+
+	// Notification routes (protected, current user only)
+	notifications := api.Group("/notifications")
+	SetupNotificationRoutes(notifications)
+
 	// API Key management routes (protected)
 	apiKeys := api.Group("/api-keys")
 	SetupAPIKeyRoutes(apiKeys)
 
+	// Import rule routes (protected); registered ahead of the admin-only settings routes
+	importRules := api.Group("/settings/import-rules")
+	SetupImportRuleRoutes(importRules)
+
 	// System Settings routes (protected, admin only)
 	settings := api.Group("/settings")
 	SetupSystemSettingsRoutes(settings)
 
 	// API Documentation routes (public)
 	docs := api.Group("/docs")
-	SetupDocsRoutes(docs)
+	SetupDocsRoutes(docs, version)
 }
 
 // SetupAuthRoutes configures authentication routes
@@ -95,6 +226,9 @@ func SetupAuthRoutes(router fiber.Router, cfg *config.Config) {
 	// Login (with rate limiting)
 	router.Post("/login", middleware.AuthRateLimiter(), handler.Login)
 
+	// Token refresh (the refresh token is the credential; each one is single-use)
+	router.Post("/refresh", middleware.AuthRateLimiter(), handler.Refresh)
+
 	// Password reset (with rate limiting)
 	router.Post("/forgot-password", middleware.PasswordResetRateLimiter(), handler.ForgotPassword)
 	router.Post("/reset-password", middleware.PasswordResetRateLimiter(), handler.ResetPassword)
@@ -111,6 +245,7 @@ func SetupAuthRoutes(router fiber.Router, cfg *config.Config) {
 				"POST /register - User registration",
 				"POST /verify-email - Email verification",
 				"POST /login - User login",
+				"POST /refresh - Exchange a refresh token for a new access token",
 				"POST /logout - User logout (requires auth)",
 				"POST /forgot-password - Password reset request",
 				"POST /reset-password - Password reset",
@@ -136,59 +271,167 @@ func SetupProfileRoutes(router fiber.Router) {
 	// All profile routes require authentication
 	router.Use(middleware.AuthMiddleware())
 
+	// Impersonators can view the profile but not change its email, password or sessions
+	noImpersonation := middleware.DenyDuringImpersonation()
+
+	// API keys need profile:read to view and profile:write to change anything
+	canRead := middleware.RequireScope("profile:read")
+	canWrite := middleware.RequireScope("profile:write")
+
 	// Profile management
-	router.Get("/", handler.GetProfile)
-	router.Put("/", handler.UpdateProfile)
-	router.Post("/change-password", handler.ChangePassword)
+	router.Get("/", canRead, handler.GetProfile)
+	router.Put("/", noImpersonation, canWrite, handler.UpdateProfile)
+	router.Post("/change-password", noImpersonation, canWrite, handler.ChangePassword)
+
+	// Preferences (CSV export format defaults, etc.)
+	router.Get("/preferences", canRead, handler.GetPreferences)
+	router.Put("/preferences", canWrite, handler.UpdatePreferences)
+
+	// Login history, API keys, 2FA status and recent sensitive actions for self-audit
+	router.Get("/security", canRead, handler.GetSecurityOverview)
 
 	// Session management
-	router.Get("/sessions", handler.GetActiveSessions)
-	router.Delete("/sessions/:id", handler.RevokeSession)
-	router.Delete("/sessions", handler.RevokeAllSessions)
+	router.Get("/sessions", canRead, handler.GetActiveSessions)
+	router.Delete("/sessions/:id", noImpersonation, canWrite, handler.RevokeSession)
+	router.Delete("/sessions", noImpersonation, canWrite, handler.RevokeAllSessions)
 }
 
 // SetupTwoFactorRoutes configures 2FA routes
 func SetupTwoFactorRoutes(router fiber.Router) {
 	handler := NewTwoFactorHandler()
 
-	// All 2FA routes require authentication
+	// All 2FA routes require authentication and are off limits while impersonating
 	router.Use(middleware.AuthMiddleware())
+	router.Use(middleware.DenyDuringImpersonation())
 
 	// 2FA management
 	router.Post("/enable", handler.EnableTwoFactor)
 	router.Post("/verify", handler.VerifyTwoFactor)
 	router.Post("/disable", handler.DisableTwoFactor)
+
+	// Backup (recovery) codes
+	router.Get("/backup-codes", handler.GetBackupCodesStatus)
+	router.Post("/backup-codes/regenerate", handler.RegenerateBackupCodes)
+}
+
+// SetupOnboardingRoutes configures policy acceptance and setup step routes
+func SetupOnboardingRoutes(router fiber.Router) {
+	handler := NewOnboardingHandler()
+
+	// All onboarding routes require authentication; an impersonator cannot accept policies on
+	// the user's behalf
+	router.Use(middleware.AuthMiddleware())
+	noImpersonation := middleware.DenyDuringImpersonation()
+
+	router.Get("/", middleware.RequireScope("profile:read"), handler.GetOnboarding)
+	router.Post("/policies/:key/accept", noImpersonation, middleware.RequireScope("profile:write"), handler.AcceptPolicy)
+	router.Post("/steps/:step/skip", noImpersonation, middleware.RequireScope("profile:write"), handler.SkipOnboardingStep)
 }
 
 // SetupAdminRoutes configures admin routes
-func SetupAdminRoutes(router fiber.Router) {
+func SetupAdminRoutes(router fiber.Router, cfg *config.Config) {
 	adminHandler := NewAdminHandler()
 	roleHandler := NewRoleHandler()
 
 	// All admin routes require authentication and admin role
 	router.Use(middleware.AuthMiddleware())
+	router.Use(middleware.RequireAdminIPAccess())
 	router.Use(middleware.RequireAdmin())
 
+	// API keys need admin:read to view and admin:write to change anything
+	canRead := middleware.RequireScope("admin:read")
+	canWrite := middleware.RequireScope("admin:write")
+
 	// User management
-	router.Get("/users", adminHandler.ListUsers)
-	router.Post("/users", adminHandler.CreateUser)
-	router.Get("/users/:id", adminHandler.GetUser)
-	router.Put("/users/:id/role", adminHandler.AssignRole)
-	router.Put("/users/:id/status", adminHandler.UpdateUserStatus)
-	router.Delete("/users/:id", adminHandler.DeleteUser)
-
-	// Role management
-	router.Get("/roles", roleHandler.ListRoles)
-	router.Get("/roles/:id", roleHandler.GetRole)
-	router.Post("/roles", roleHandler.CreateRole)
-	router.Put("/roles/:id", roleHandler.UpdateRole)
-	router.Delete("/roles/:id", roleHandler.DeleteRole)
-
-	// Database cleanup management
-	router.Get("/cleanup/stats", adminHandler.GetCleanupStats)
-	router.Post("/cleanup/assets", adminHandler.CleanupAssets)
-	router.Post("/cleanup/vulnerabilities", adminHandler.CleanupVulnerabilities)
-	router.Post("/cleanup/all", adminHandler.CleanupAllData)
+	router.Get("/users", canRead, adminHandler.ListUsers)
+	router.Post("/users", canWrite, adminHandler.CreateUser)
+	router.Get("/users/:id", canRead, adminHandler.GetUser)
+	router.Put("/users/:id/role", canWrite, adminHandler.AssignRole)
+	router.Put("/users/:id/status", canWrite, adminHandler.UpdateUserStatus)
+	router.Delete("/users/:id", canWrite, adminHandler.DeleteUser)
+
+	// Onboarding state; a reset asks the user to accept the policies and set up again
+	onboardingHandler := NewOnboardingHandler()
+	router.Get("/users/:id/onboarding", canRead, onboardingHandler.GetUserOnboarding)
+	router.Post("/users/:id/onboarding/reset", canWrite, onboardingHandler.ResetUserOnboarding)
+
+	// Act as a user to reproduce permission issues (time-boxed, audited under the admin's ID)
+	router.Post("/users/:id/impersonate", canWrite, adminHandler.ImpersonateUser)
+
+	// Role management (roles are shared by all organizations)
+	router.Get("/permissions", canRead, roleHandler.ListPermissionCatalog)
+	router.Get("/roles", canRead, roleHandler.ListRoles)
+	router.Get("/roles/:id", canRead, roleHandler.GetRole)
+	router.Get("/roles/:id/permissions", canRead, roleHandler.GetEffectivePermissions)
+	router.Post("/roles", canWrite, middleware.RequirePlatformOrganization(), roleHandler.CreateRole)
+	router.Put("/roles/:id", canWrite, middleware.RequirePlatformOrganization(), roleHandler.UpdateRole)
+	router.Delete("/roles/:id", canWrite, middleware.RequirePlatformOrganization(), roleHandler.DeleteRole)
+
+	// Database cleanup management (spans all organizations)
+	router.Get("/cleanup/stats", canRead, middleware.RequirePlatformOrganization(), adminHandler.GetCleanupStats)
+	router.Post("/cleanup/assets", canWrite, middleware.RequirePlatformOrganization(), adminHandler.CleanupAssets)
+	router.Post("/cleanup/vulnerabilities", canWrite, middleware.RequirePlatformOrganization(), adminHandler.CleanupVulnerabilities)
+	router.Post("/cleanup/all", canWrite, middleware.RequirePlatformOrganization(), adminHandler.CleanupAllData)
+
+	// Recycle bin of soft-deleted vulnerabilities and assets (restorable until cleaned up or purged by retention)
+	router.Get("/trash", canRead, middleware.RequirePlatformOrganization(), adminHandler.ListTrash)
+	router.Post("/trash/:type/:id/restore", canWrite, middleware.RequirePlatformOrganization(), adminHandler.RestoreTrashItem)
+
+	// Data retention (periods are saved through the data_retention system setting)
+	router.Get("/retention", canRead, middleware.RequirePlatformOrganization(), adminHandler.GetRetentionSettings)
+	router.Get("/retention/runs", canRead, middleware.RequirePlatformOrganization(), adminHandler.ListRetentionRuns)
+	router.Post("/retention/preview", canRead, middleware.RequirePlatformOrganization(), adminHandler.PreviewRetention)
+	router.Post("/retention/run", canWrite, middleware.RequirePlatformOrganization(), adminHandler.RunRetention)
+
+	// Backups (pg_dump archive plus attachment manifest) and restores into an empty database;
+	// both run in the background and report progress through their job
+	router.Get("/backups", canRead, middleware.RequirePlatformOrganization(), adminHandler.ListBackups)
+	router.Post("/backups", canWrite, middleware.RequirePlatformOrganization(), adminHandler.StartBackup)
+	router.Get("/backups/jobs/:id", canRead, middleware.RequirePlatformOrganization(), adminHandler.GetBackupJob)
+	router.Post("/backups/:id/restore", canWrite, middleware.RequirePlatformOrganization(), adminHandler.RestoreBackup)
+
+	// Database connection pool metrics and slow query counts (per instance)
+	router.Get("/database/stats", canRead, middleware.RequirePlatformOrganization(), adminHandler.GetDatabaseStats)
+
+	// Readiness with the result of each dependency check (the public probe only reports status)
+	router.Get("/health", canRead, middleware.RequirePlatformOrganization(), NewHealthHandler().ReadinessDetails)
+
+	// Background jobs run once across instances and the outcome of their last run
+	router.Get("/jobs", canRead, middleware.RequirePlatformOrganization(), adminHandler.ListScheduledJobs)
+
+	// Client networks allowed and denied access to these admin routes
+	router.Get("/ip-access", canRead, middleware.RequirePlatformOrganization(), adminHandler.GetAdminIPAccess)
+	router.Put("/ip-access", canWrite, middleware.RequirePlatformOrganization(), adminHandler.UpdateAdminIPAccess)
+
+	// Outgoing email, its delivery status reported by the provider and the queue it is sent from
+	emailDeliveryHandler := NewEmailDeliveryHandler()
+	router.Get("/email/deliveries", canRead, middleware.RequirePlatformOrganization(), emailDeliveryHandler.ListDeliveries)
+	router.Get("/email/deliveries/:id", canRead, middleware.RequirePlatformOrganization(), emailDeliveryHandler.GetDelivery)
+	router.Get("/email/outbox", canRead, middleware.RequirePlatformOrganization(), emailDeliveryHandler.ListOutbox)
+	router.Post("/email/outbox/:id/retry", canWrite, middleware.RequirePlatformOrganization(), emailDeliveryHandler.RetryOutboxEntry)
+
+	// Report templates (logo, company name, intro text and sections) that brand client deliverables
+	reportTemplateHandler := NewReportTemplateHandler()
+	router.Post("/report-templates", canWrite, middleware.RequirePlatformOrganization(), reportTemplateHandler.CreateTemplate)
+	router.Put("/report-templates/:id", canWrite, middleware.RequirePlatformOrganization(), reportTemplateHandler.UpdateTemplate)
+	router.Delete("/report-templates/:id", canWrite, middleware.RequirePlatformOrganization(), reportTemplateHandler.DeleteTemplate)
+	router.Put("/report-templates/:id/logo", canWrite, middleware.RequirePlatformOrganization(), reportTemplateHandler.UploadLogo)
+	router.Delete("/report-templates/:id/logo", canWrite, middleware.RequirePlatformOrganization(), reportTemplateHandler.DeleteLogo)
+
+	// Search backend management
+	searchIndexHandler := NewSearchIndexHandler()
+	router.Get("/search/status", canRead, middleware.RequirePlatformOrganization(), searchIndexHandler.GetSearchStatus)
+	router.Post("/search/reindex", canWrite, middleware.RequirePlatformOrganization(), searchIndexHandler.ReindexSearch)
+
+	// Fault injection (chaos builds only, never in production)
+	if faultinject.Enabled() && cfg.GoEnv != "production" {
+		faultHandler := NewFaultInjectionHandler()
+		faults := router.Group("/faults", middleware.RequirePlatformOrganization())
+		faults.Get("/", canRead, faultHandler.ListFaults)
+		faults.Put("/:point", canWrite, faultHandler.SetFault)
+		faults.Delete("/:point", canWrite, faultHandler.ClearFault)
+		faults.Delete("/", canWrite, faultHandler.ClearAllFaults)
+	}
 }
 
 // SetupVulnerabilityRoutes configures vulnerability management routes
@@ -202,47 +445,130 @@ func SetupVulnerabilityRoutes(router fiber.Router, cfg *config.Config) {
 	// Note: This must come BEFORE /:id to avoid route conflict
 	router.Get("/stats",
 		middleware.RequirePermission("vulnerability", "read"),
-		middleware.RequireScope("vulnerabilities:read"),
+		middleware.RequireAnyScope("vulnerabilities:read", "vulnerabilities:stats"),
 		handler.GetVulnerabilityStats,
 	)
 
+	// Batch get by IDs (must come BEFORE /:id to avoid route conflict)
+	router.Post("/batch-get",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		handler.BatchGetVulnerabilities,
+	)
+
+	// CWE reference (must come BEFORE /:id to avoid route conflict)
+	cweHandler := NewCWEHandler()
+	router.Get("/cwe",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		cweHandler.ListWeaknesses,
+	)
+	router.Get("/cwe/:id",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		cweHandler.GetWeakness,
+	)
+
+	// OWASP Top 10 and ATT&CK reference (must come BEFORE /:id to avoid route conflict)
+	router.Get("/taxonomy",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		NewTaxonomyHandler().GetTaxonomy,
+	)
+
+	// Tag routes (must come BEFORE /:id to avoid route conflict)
+	router.Get("/tags",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		handler.ListVulnerabilityTags,
+	)
+	router.Post("/tags/bulk",
+		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
+		handler.BulkTagVulnerabilities,
+	)
+
 	// Integration configuration routes (must come BEFORE /:id to avoid route conflict)
 	integrationHandler := NewIntegrationConfigHandler(cfg.JWTSecret)
 	router.Post("/integrations/configs",
 		middleware.RequirePermission("integration", "configure"),
+		middleware.RequireScope("integrations:write"),
 		integrationHandler.CreateConfig,
 	)
 	router.Get("/integrations/configs",
 		middleware.RequirePermission("integration", "read"),
+		middleware.RequireScope("integrations:read"),
 		integrationHandler.ListConfigs,
 	)
 	router.Get("/integrations/configs/:id",
 		middleware.RequirePermission("integration", "read"),
+		middleware.RequireScope("integrations:read"),
 		integrationHandler.GetConfig,
 	)
 	router.Put("/integrations/configs/:id",
 		middleware.RequirePermission("integration", "configure"),
+		middleware.RequireScope("integrations:write"),
 		integrationHandler.UpdateConfig,
 	)
 	router.Delete("/integrations/configs/:id",
 		middleware.RequirePermission("integration", "configure"),
+		middleware.RequireScope("integrations:write"),
 		integrationHandler.DeleteConfig,
 	)
 	router.Post("/integrations/configs/:id/test",
 		middleware.RequirePermission("integration", "test"),
+		middleware.RequireScope("integrations:write"),
 		integrationHandler.TestConnection,
 	)
+	router.Get("/integrations/configs/:id/severity-mapping",
+		middleware.RequirePermission("integration", "read"),
+		middleware.RequireScope("integrations:read"),
+		integrationHandler.GetSeverityMapping,
+	)
+	router.Put("/integrations/configs/:id/severity-mapping",
+		middleware.RequirePermission("integration", "configure"),
+		middleware.RequireScope("integrations:write"),
+		integrationHandler.UpdateSeverityMapping,
+	)
 
 	// Import routes (must come BEFORE /:id to avoid route conflict)
 	importHandler := NewVulnerabilityImportHandler()
 	router.Post("/import/nessus/preview",
 		middleware.RequirePermission("vulnerability", "import"),
+		middleware.RequireScope("vulnerabilities:import"),
 		importHandler.PreviewNessusFile,
 	)
 	router.Post("/import/nessus",
 		middleware.RequirePermission("vulnerability", "import"),
+		middleware.RequireScope("vulnerabilities:import"),
 		importHandler.UploadNessusFile,
 	)
+	router.Get("/imports",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		importHandler.ListImportJobs,
+	)
+	// Hosts held back by import validation (must come BEFORE /imports/:id)
+	router.Get("/imports/quarantine",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		importHandler.ListQuarantinedHosts,
+	)
+	router.Post("/imports/quarantine/:hostId/release",
+		middleware.RequirePermission("vulnerability", "import"),
+		middleware.RequireScope("vulnerabilities:import"),
+		importHandler.ReleaseQuarantinedHost,
+	)
+	router.Post("/imports/quarantine/:hostId/dismiss",
+		middleware.RequirePermission("vulnerability", "import"),
+		middleware.RequireScope("vulnerabilities:import"),
+		importHandler.DismissQuarantinedHost,
+	)
+	router.Get("/imports/:id",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		importHandler.GetImportJob,
+	)
 
 	// Nessus API integration routes (scan browsing and import)
 	nessusScanHandler := NewNessusScanHandler(cfg.JWTSecret)
@@ -250,120 +576,222 @@ func SetupVulnerabilityRoutes(router fiber.Router, cfg *config.Config) {
 	// List all scans from Nessus
 	router.Get("/integrations/nessus/:config_id/scans",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		nessusScanHandler.ListScans,
 	)
 
+	// List scan folders
+	router.Get("/integrations/nessus/:config_id/folders",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		nessusScanHandler.ListFolders,
+	)
+
+	// List the scans in a folder
+	router.Get("/integrations/nessus/:config_id/folders/:folder_id/scans",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		nessusScanHandler.ListFolderScans,
+	)
+
 	// Get scan details
 	router.Get("/integrations/nessus/:config_id/scans/:scan_id",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		nessusScanHandler.GetScanDetails,
 	)
 
 	// Preview scan before importing
 	router.Get("/integrations/nessus/:config_id/scans/:scan_id/preview",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		nessusScanHandler.PreviewScan,
 	)
 
 	// Import single scan
 	router.Post("/integrations/nessus/:config_id/scans/:scan_id/import",
 		middleware.RequirePermission("vulnerability", "import"),
+		middleware.RequireScope("vulnerabilities:import"),
 		nessusScanHandler.ImportSingleScan,
 	)
 
 	// Import multiple selected scans
 	router.Post("/integrations/nessus/:config_id/scans/import-multiple",
 		middleware.RequirePermission("vulnerability", "import"),
+		middleware.RequireScope("vulnerabilities:import"),
 		nessusScanHandler.ImportMultipleScans,
 	)
 
 	// Import all scans
 	router.Post("/integrations/nessus/:config_id/scans/import-all",
 		middleware.RequirePermission("vulnerability", "import"),
+		middleware.RequireScope("vulnerabilities:import"),
 		nessusScanHandler.ImportAllScans,
 	)
 
+	// Import open vulnerabilities through the Tenable.io vulnerability export API
+	router.Post("/integrations/nessus/:config_id/vulns/import",
+		middleware.RequirePermission("vulnerability", "import"),
+		middleware.RequireScope("vulnerabilities:import"),
+		nessusScanHandler.ImportTenableVulnerabilities,
+	)
+
+	// Resume a scan import interrupted by a server shutdown
+	router.Post("/imports/:id/resume",
+		middleware.RequirePermission("vulnerability", "import"),
+		middleware.RequireScope("vulnerabilities:import"),
+		nessusScanHandler.ResumeImportJob,
+	)
+
 	// Finding management routes (must come BEFORE /:id to avoid route conflict)
 	findingHandler := NewVulnerabilityFindingHandler()
 
 	// Get findings statistics (must come BEFORE /findings/:id)
 	router.Get("/findings/stats",
 		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
 		findingHandler.GetFindingStatistics,
 	)
 
 	// List all findings with filters
 	router.Get("/findings",
 		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
 		findingHandler.ListFindings,
 	)
 
 	// Get specific finding details
 	router.Get("/findings/:id",
 		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
 		findingHandler.GetFinding,
 	)
 
 	// Mark finding as fixed
 	router.Post("/findings/:id/mark-fixed",
 		middleware.RequirePermission("finding", "mark_fixed"),
+		middleware.RequireScope("findings:write"),
 		findingHandler.MarkFindingFixed,
 	)
 
 	// Mark finding as verified
 	router.Post("/findings/:id/mark-verified",
 		middleware.RequirePermission("finding", "verify"),
+		middleware.RequireScope("findings:write"),
 		findingHandler.MarkFindingVerified,
 	)
 
 	// Accept risk for a finding
 	router.Post("/findings/:id/accept-risk",
 		middleware.RequirePermission("finding", "accept_risk"),
+		middleware.RequireScope("findings:write"),
 		findingHandler.AcceptRisk,
 	)
 
+	// Finding comment routes (@mentions notify the mentioned users)
+	findingCommentHandler := NewFindingCommentHandler()
+
+	router.Get("/findings/:id/comments",
+		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
+		findingCommentHandler.ListComments,
+	)
+
+	router.Post("/findings/:id/comments",
+		middleware.RequirePermission("finding", "comment"),
+		middleware.RequireScope("findings:write"),
+		findingCommentHandler.CreateComment,
+	)
+
+	router.Put("/findings/:id/comments/:comment_id",
+		middleware.RequirePermission("finding", "comment"),
+		middleware.RequireScope("findings:write"),
+		findingCommentHandler.UpdateComment,
+	)
+
+	router.Delete("/findings/:id/comments/:comment_id",
+		middleware.RequirePermission("finding", "comment"),
+		middleware.RequireScope("findings:write"),
+		findingCommentHandler.DeleteComment,
+	)
+
 	// Finding attachment routes
 	attachmentHandler := NewFindingAttachmentHandler()
 
 	// Upload attachment to a finding
 	router.Post("/findings/:id/attachments",
 		middleware.RequirePermission("finding", "upload_attachment"),
+		middleware.RequireScope("findings:write"),
 		attachmentHandler.UploadAttachment,
 	)
 
 	// List attachments for a finding
 	router.Get("/findings/:id/attachments",
 		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
 		attachmentHandler.ListFindingAttachments,
 	)
 
 	// Get attachment stats for a finding
 	router.Get("/findings/:id/attachments/stats",
 		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
 		attachmentHandler.GetAttachmentStats,
 	)
 
+	// Get the effective upload policy (allowed MIME types and sizes per category)
+	router.Get("/attachments/policy",
+		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
+		attachmentHandler.GetAttachmentPolicy,
+	)
+
 	// Get attachment metadata
 	router.Get("/attachments/:id",
 		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
 		attachmentHandler.GetAttachment,
 	)
 
 	// View/serve attachment file (inline)
 	router.Get("/attachments/:id/file",
 		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
 		attachmentHandler.GetAttachmentFile,
 	)
 
 	// Download attachment file
 	router.Get("/attachments/:id/download",
 		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
 		attachmentHandler.DownloadAttachmentFile,
 	)
 
+	// Get a presigned download URL for an attachment file (object storage backends)
+	router.Get("/attachments/:id/download-url",
+		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
+		attachmentHandler.GetAttachmentDownloadURL,
+	)
+
+	// Verify an attachment against the SHA-256 hash recorded at upload
+	router.Post("/attachments/:id/verify",
+		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
+		attachmentHandler.VerifyAttachmentIntegrity,
+	)
+
+	// Get an attachment's chain of custody
+	router.Get("/attachments/:id/custody",
+		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
+		attachmentHandler.GetAttachmentCustody,
+	)
+
 	// Delete attachment
 	router.Delete("/attachments/:id",
 		middleware.RequirePermission("finding", "upload_attachment"),
+		middleware.RequireScope("findings:write"),
 		attachmentHandler.DeleteAttachment,
 	)
 
@@ -373,42 +801,56 @@ func SetupVulnerabilityRoutes(router fiber.Router, cfg *config.Config) {
 	// Upload attachment to a vulnerability
 	router.Post("/:id/attachments",
 		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
 		vulnAttachmentHandler.UploadAttachment,
 	)
 
 	// List attachments for a vulnerability
 	router.Get("/:id/attachments",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		vulnAttachmentHandler.ListVulnerabilityAttachments,
 	)
 
 	// Get attachment stats for a vulnerability
 	router.Get("/:id/attachments/stats",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		vulnAttachmentHandler.GetAttachmentStats,
 	)
 
 	// Get vulnerability attachment metadata
 	router.Get("/vulnerability-attachments/:id",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		vulnAttachmentHandler.GetAttachment,
 	)
 
 	// View/serve vulnerability attachment file (inline)
 	router.Get("/vulnerability-attachments/:id/file",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		vulnAttachmentHandler.GetAttachmentFile,
 	)
 
 	// Download vulnerability attachment file
 	router.Get("/vulnerability-attachments/:id/download",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		vulnAttachmentHandler.DownloadAttachmentFile,
 	)
 
+	// Get a presigned download URL for a vulnerability attachment file
+	router.Get("/vulnerability-attachments/:id/download-url",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		vulnAttachmentHandler.GetAttachmentDownloadURL,
+	)
+
 	// Delete vulnerability attachment
 	router.Delete("/vulnerability-attachments/:id",
 		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
 		vulnAttachmentHandler.DeleteAttachment,
 	)
 
@@ -455,6 +897,32 @@ func SetupVulnerabilityRoutes(router fiber.Router, cfg *config.Config) {
 		handler.AssignVulnerability,
 	)
 
+	// Assign owner team (requires vulnerability:assign permission)
+	router.Patch("/:id/team",
+		middleware.RequirePermission("vulnerability", "assign"),
+		middleware.RequireScope("vulnerabilities:write"),
+		handler.AssignVulnerabilityTeam,
+	)
+
+	// Tag vulnerability (requires vulnerability:write permission)
+	router.Post("/:id/tags",
+		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
+		handler.AddVulnerabilityTags,
+	)
+	router.Delete("/:id/tags/:tag",
+		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
+		handler.RemoveVulnerabilityTag,
+	)
+
+	// Map to OWASP Top 10 categories and ATT&CK techniques (requires vulnerability:write permission)
+	router.Put("/:id/classifications",
+		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
+		handler.SetVulnerabilityClassifications,
+	)
+
 	// Delete vulnerability (requires vulnerability:delete permission)
 	router.Delete("/:id",
 		middleware.RequirePermission("vulnerability", "delete"),
@@ -467,22 +935,107 @@ func SetupVulnerabilityRoutes(router fiber.Router, cfg *config.Config) {
 
 	router.Get("/:id/affected-systems",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		affectedSystemHandler.GetVulnerabilityAffectedSystems,
 	)
 
 	router.Post("/:id/affected-systems",
 		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
 		affectedSystemHandler.AddVulnerabilityAffectedSystems,
 	)
 
 	router.Delete("/:id/affected-systems/:system_id",
 		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
 		affectedSystemHandler.RemoveVulnerabilityAffectedSystem,
 	)
 
+	// Escalation history
+	escalationHandler := NewEscalationHandler()
+	router.Get("/:id/escalations",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		escalationHandler.ListVulnerabilityEscalations,
+	)
+
+	// Two-person rule for closing CRITICAL vulnerabilities
+	closeApprovalHandler := NewCloseApprovalHandler()
+	router.Get("/:id/close-approvals",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		closeApprovalHandler.ListCloseApprovals,
+	)
+	router.Post("/:id/close-approvals",
+		middleware.RequirePermission("vulnerability", "status_change"),
+		middleware.RequireScope("vulnerabilities:write"),
+		closeApprovalHandler.RequestCloseApproval,
+	)
+	router.Get("/:id/close-approvals/:approval_id",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		closeApprovalHandler.GetCloseApproval,
+	)
+	router.Post("/:id/close-approvals/:approval_id/approve",
+		middleware.RequirePermission("finding", "verify"),
+		middleware.RequireScope("vulnerabilities:write"),
+		closeApprovalHandler.ApproveCloseApproval,
+	)
+	router.Post("/:id/close-approvals/:approval_id/reject",
+		middleware.RequirePermission("finding", "verify"),
+		middleware.RequireScope("vulnerabilities:write"),
+		closeApprovalHandler.RejectCloseApproval,
+	)
+	router.Post("/:id/close-approvals/:approval_id/cancel",
+		middleware.RequirePermission("vulnerability", "status_change"),
+		middleware.RequireScope("vulnerabilities:write"),
+		closeApprovalHandler.CancelCloseApproval,
+	)
+
+	// Comments and activity timeline
+	commentHandler := NewVulnerabilityCommentHandler()
+
+	router.Get("/:id/comments",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		commentHandler.ListComments,
+	)
+
+	router.Post("/:id/comments",
+		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
+		commentHandler.CreateComment,
+	)
+
+	router.Put("/:id/comments/:comment_id",
+		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
+		commentHandler.UpdateComment,
+	)
+
+	router.Delete("/:id/comments/:comment_id",
+		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
+		commentHandler.DeleteComment,
+	)
+
+	router.Get("/:id/activity",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		commentHandler.GetActivity,
+	)
+
+	// Patch, advisory and workaround guidance per affected system
+	router.Get("/:id/remediation",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		handler.GetVulnerabilityRemediation,
+	)
+
 	// List findings for a specific vulnerability
 	router.Get("/:id/findings",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		findingHandler.ListFindingsByVulnerability,
 	)
 }
@@ -497,30 +1050,35 @@ func SetupAffectedSystemRoutes(router fiber.Router, authMiddleware fiber.Handler
 	// List affected systems (requires vulnerability:read permission)
 	router.Get("/",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		handler.ListAffectedSystems,
 	)
 
 	// Create affected system (requires vulnerability:write permission)
 	router.Post("/",
 		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
 		handler.CreateAffectedSystem,
 	)
 
 	// Get single affected system (requires vulnerability:read permission)
 	router.Get("/:id",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		handler.GetAffectedSystem,
 	)
 
 	// Update affected system (requires vulnerability:write permission)
 	router.Put("/:id",
 		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
 		handler.UpdateAffectedSystem,
 	)
 
 	// Delete affected system (requires vulnerability:delete permission)
 	router.Delete("/:id",
 		middleware.RequirePermission("vulnerability", "delete"),
+		middleware.RequireScope("vulnerabilities:delete"),
 		handler.DeleteAffectedSystem,
 	)
 
@@ -528,6 +1086,7 @@ func SetupAffectedSystemRoutes(router fiber.Router, authMiddleware fiber.Handler
 	findingHandler := NewVulnerabilityFindingHandler()
 	router.Get("/:id/findings",
 		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
 		findingHandler.ListFindingsBySystem,
 	)
 }
@@ -547,48 +1106,68 @@ func SetupAssetRoutes(router fiber.Router) {
 	// Note: This must come BEFORE /:id to avoid route conflict
 	router.Get("/stats",
 		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
 		handler.GetAssetStats,
 	)
 
 	// Check for duplicate assets (requires asset:read permission)
 	router.Post("/check-duplicate",
 		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
 		handler.CheckDuplicateAsset,
 	)
 
 	// List assets (requires asset:read permission)
 	router.Get("/",
 		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
 		handler.ListAssets,
 	)
 
 	// Get asset details (requires asset:read permission)
 	router.Get("/:id",
 		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
 		handler.GetAsset,
 	)
 
 	// Create asset (requires asset:write permission)
 	router.Post("/",
 		middleware.RequirePermission("asset", "write"),
+		middleware.RequireScope("assets:write"),
 		handler.CreateAsset,
 	)
 
 	// Update asset (requires asset:write permission)
 	router.Put("/:id",
 		middleware.RequirePermission("asset", "write"),
+		middleware.RequireScope("assets:write"),
 		handler.UpdateAsset,
 	)
 
 	// Update asset status (requires asset:write permission)
 	router.Patch("/:id/status",
 		middleware.RequirePermission("asset", "write"),
+		middleware.RequireScope("assets:write"),
 		handler.UpdateAssetStatus,
 	)
 
+	// Decommission workflow: preview the open findings, then retire the asset disposing of them
+	router.Get("/:id/decommission",
+		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
+		handler.PreviewDecommission,
+	)
+	router.Post("/:id/decommission",
+		middleware.RequirePermission("asset", "write"),
+		middleware.RequireScope("assets:write"),
+		handler.DecommissionAsset,
+	)
+
 	// Get asset vulnerabilities (requires asset:read permission)
 	router.Get("/:id/vulnerabilities",
 		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
 		handler.GetAssetVulnerabilities,
 	)
 
@@ -596,34 +1175,85 @@ func SetupAssetRoutes(router fiber.Router) {
 	findingHandler := NewVulnerabilityFindingHandler()
 	router.Get("/:id/findings",
 		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
 		findingHandler.ListFindingsBySystem,
 	)
 
+	// Get installed packages reported by the asset's agent (requires asset:read permission)
+	agentHandler := NewAgentHandler()
+	router.Get("/:id/packages",
+		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
+		agentHandler.ListAssetPackages,
+	)
+
+	// Upload an SBOM, replacing the asset's components (requires asset:write permission)
+	sbomHandler := NewSBOMHandler()
+	router.Post("/:id/sbom",
+		middleware.RequirePermission("asset", "write"),
+		middleware.RequireScope("assets:write"),
+		sbomHandler.UploadSBOM,
+	)
+
+	// Get the components of the asset's SBOM (requires asset:read permission)
+	router.Get("/:id/sbom",
+		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
+		sbomHandler.ListSBOMComponents,
+	)
+
+	// Export a VEX document of the asset's finding statuses (requires asset:read permission)
+	router.Get("/:id/vex",
+		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
+		sbomHandler.ExportVEX,
+	)
+
+	// Explain the asset's criticality score (requires asset:read permission)
+	scoringHandler := NewCriticalityScoringHandler()
+	router.Get("/:id/score",
+		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
+		scoringHandler.GetAssetScore,
+	)
+
+	// Get asset change history (requires asset:read permission)
+	router.Get("/:id/history",
+		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
+		handler.GetAssetHistory,
+	)
+
 	// Add tags to asset (requires asset:write permission)
 	router.Post("/:id/tags",
 		middleware.RequirePermission("asset", "write"),
+		middleware.RequireScope("assets:write"),
 		handler.AddAssetTags,
 	)
 
 	// Remove tag from asset (requires asset:write permission)
 	router.Delete("/:id/tags/:tag",
 		middleware.RequirePermission("asset", "write"),
+		middleware.RequireScope("assets:write"),
 		handler.RemoveAssetTag,
 	)
 
 	// Delete asset (requires asset:delete permission)
 	router.Delete("/:id",
 		middleware.RequirePermission("asset", "delete"),
+		middleware.RequireScope("assets:delete"),
 		handler.DeleteAsset,
 	)
 }
 
 // SetupDocsRoutes configures API documentation routes
-func SetupDocsRoutes(router fiber.Router) {
-	handler := NewDocsHandler()
+func SetupDocsRoutes(router fiber.Router, version apiVersion) {
+	handler := NewDocsHandler(version)
 
-	// Serve OpenAPI spec at /api/v1/docs/openapi.yaml
+	// Serve the OpenAPI spec generated from the registered routes at /api/v1/docs/openapi.yaml
+	// (and /api/v1/docs/openapi.json)
 	router.Get("/openapi.yaml", handler.ServeOpenAPISpec)
+	router.Get("/openapi.json", handler.ServeOpenAPIJSON)
 
 	// Serve Swagger UI at /api/v1/docs (default)
 	router.Get("/", handler.ServeSwaggerUI)
@@ -643,177 +1273,920 @@ func SetupAssessmentRoutes(router fiber.Router) {
 	// Get assessment statistics (requires assessment:read permission)
 	router.Get("/stats",
 		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
 		handler.GetAssessmentStats,
 	)
 
 	// List assessments (requires assessment:read permission)
 	router.Get("/",
 		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
 		handler.ListAssessments,
 	)
 
 	// Get assessment details (requires assessment:read permission)
 	router.Get("/:id",
 		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
 		handler.GetAssessment,
 	)
 
 	// Create assessment (requires assessment:create permission)
 	router.Post("/",
 		middleware.RequirePermission("assessment", "create"),
+		middleware.RequireScope("assessments:write"),
 		handler.CreateAssessment,
 	)
 
 	// Update assessment (requires assessment:update permission)
 	router.Put("/:id",
 		middleware.RequirePermission("assessment", "update"),
+		middleware.RequireScope("assessments:write"),
 		handler.UpdateAssessment,
 	)
 
 	// Delete assessment (requires assessment:delete permission)
 	router.Delete("/:id",
 		middleware.RequirePermission("assessment", "delete"),
+		middleware.RequireScope("assessments:delete"),
 		handler.DeleteAssessment,
 	)
 
 	// Link vulnerability to assessment (requires assessment:link_vulnerability permission)
 	router.Post("/:id/vulnerabilities",
 		middleware.RequirePermission("assessment", "link_vulnerability"),
+		middleware.RequireScope("assessments:write"),
 		handler.LinkVulnerability,
 	)
 
 	// Unlink vulnerability from assessment (requires assessment:link_vulnerability permission)
 	router.Delete("/:id/vulnerabilities/:vulnerability_id",
 		middleware.RequirePermission("assessment", "link_vulnerability"),
+		middleware.RequireScope("assessments:write"),
 		handler.UnlinkVulnerability,
 	)
 
 	// Link asset to assessment (requires assessment:update permission)
 	router.Post("/:id/assets",
 		middleware.RequirePermission("assessment", "update"),
+		middleware.RequireScope("assessments:write"),
 		handler.LinkAsset,
 	)
 
 	// Unlink asset from assessment (requires assessment:update permission)
 	router.Delete("/:id/assets/:asset_id",
 		middleware.RequirePermission("assessment", "update"),
+		middleware.RequireScope("assessments:write"),
 		handler.UnlinkAsset,
 	)
 
+	// Link asset group to assessment scope (requires assessment:update permission)
+	router.Post("/:id/asset-groups",
+		middleware.RequirePermission("assessment", "update"),
+		middleware.RequireScope("assessments:write"),
+		handler.LinkAssetGroup,
+	)
+
+	// Unlink asset group from assessment scope (requires assessment:update permission)
+	router.Delete("/:id/asset-groups/:group_id",
+		middleware.RequirePermission("assessment", "update"),
+		middleware.RequireScope("assessments:write"),
+		handler.UnlinkAssetGroup,
+	)
+
+	// Get the assets in scope, including asset group members (requires assessment:read permission)
+	router.Get("/:id/scope",
+		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
+		handler.GetAssessmentScope,
+	)
+
+	// Natural-language summaries written by the configured language model
+	summaryHandler := NewReportSummaryHandler(services.NewReportSummaryService(database.GetDB()))
+
+	// Summarize assessment (requires assessment:update permission)
+	router.Post("/:id/summarize",
+		middleware.RequirePermission("assessment", "update"),
+		middleware.RequireScope("assessments:write"),
+		summaryHandler.SummarizeAssessment,
+	)
+
+	// List stored assessment summaries (requires assessment:read permission)
+	router.Get("/:id/summaries",
+		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
+		summaryHandler.ListAssessmentSummaries,
+	)
+
 	// Assessment report routes
 	reportHandler := NewAssessmentReportHandler(services.NewAssessmentReportService(database.GetDB()))
 
 	// Upload PDF report (requires assessment:upload_report permission)
 	router.Post("/:id/reports",
 		middleware.RequirePermission("assessment", "upload_report"),
+		middleware.RequireScope("assessments:write"),
 		reportHandler.UploadReport,
 	)
 
+	// Generate a PDF/DOCX report from the assessment's linked data (requires assessment:upload_report permission)
+	router.Post("/:id/reports/generate",
+		middleware.RequirePermission("assessment", "upload_report"),
+		middleware.RequireScope("assessments:write"),
+		reportHandler.GenerateReport,
+	)
+
 	// List reports for assessment (requires assessment:read permission)
 	router.Get("/:id/reports",
 		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
 		reportHandler.GetAssessmentReports,
 	)
 
 	// Get report statistics (requires assessment:read permission)
 	router.Get("/:id/reports/stats",
 		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
 		reportHandler.GetReportStats,
 	)
 
 	// Get specific report metadata (requires assessment:read permission)
 	router.Get("/:id/reports/:reportId",
 		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
 		reportHandler.GetReport,
 	)
 
 	// Get report file for viewing/download (requires assessment:read permission)
 	router.Get("/:id/reports/:reportId/file",
 		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
 		reportHandler.GetReportFile,
 	)
 
+	// Get the detached signature of a generated report (requires assessment:read permission)
+	router.Get("/:id/reports/:reportId/signature",
+		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
+		reportHandler.GetReportSignature,
+	)
+
+	// Get a presigned download URL for a report file (requires assessment:read permission)
+	router.Get("/:id/reports/:reportId/download-url",
+		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
+		reportHandler.GetReportDownloadURL,
+	)
+
 	// Get report version history (requires assessment:read permission)
 	router.Get("/:id/reports/:reportId/versions",
 		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
 		reportHandler.GetReportVersions,
 	)
 
 	// Delete report (requires assessment:delete permission)
 	router.Delete("/:id/reports/:reportId",
 		middleware.RequirePermission("assessment", "delete"),
+		middleware.RequireScope("assessments:delete"),
 		reportHandler.DeleteReport,
 	)
-}
 
-// SetupReportRoutes configures report generation routes
-func SetupReportRoutes(router fiber.Router) {
+	// Export a ZIP package for regulator submission (requires report:export permission)
+	exportHandler := NewAssessmentExportHandler()
+	router.Post("/:id/export-package",
+		middleware.RequirePermission("report", "export"),
+		middleware.RequireScope("assessments:read"),
+		exportHandler.ExportPackage,
+	)
+
+	// Assessment finding retest routes
+	retestHandler := NewAssessmentRetestHandler()
+
+	// List retest rounds (requires assessment:read permission)
+	router.Get("/:id/retests",
+		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
+		retestHandler.ListRetests,
+	)
+
+	// Get the current retest status of every finding (requires assessment:read permission)
+	router.Get("/:id/retests/summary",
+		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
+		retestHandler.GetRetestSummary,
+	)
+
+	// Get a retest round (requires assessment:read permission)
+	router.Get("/:id/retests/:retestId",
+		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
+		retestHandler.GetRetest,
+	)
+
+	// Mark a finding ready for retest (requires assessment:request_retest permission)
+	router.Post("/:id/retests",
+		middleware.RequirePermission("assessment", "request_retest"),
+		middleware.RequireScope("assessments:write"),
+		retestHandler.RequestRetest,
+	)
+
+	// Assign the retester of a pending retest (requires assessment:request_retest permission)
+	router.Put("/:id/retests/:retestId/retester",
+		middleware.RequirePermission("assessment", "request_retest"),
+		middleware.RequireScope("assessments:write"),
+		retestHandler.AssignRetester,
+	)
+
+	// Record a retest outcome with evidence (requires assessment:retest permission)
+	router.Post("/:id/retests/:retestId/complete",
+		middleware.RequirePermission("assessment", "retest"),
+		middleware.RequireScope("assessments:write"),
+		retestHandler.CompleteRetest,
+	)
+
+	// Auditor share token routes
+	shareHandler := NewAuditorShareHandler()
+
+	// List share tokens (requires assessment:read permission)
+	router.Get("/:id/share-tokens",
+		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
+		shareHandler.ListShareTokens,
+	)
+
+	// Issue a read-only share token for an external auditor (requires assessment:update permission)
+	router.Post("/:id/share-tokens",
+		middleware.RequirePermission("assessment", "update"),
+		middleware.RequireScope("assessments:write"),
+		shareHandler.IssueShareToken,
+	)
+
+	// Revoke a share token (requires assessment:update permission)
+	router.Delete("/:id/share-tokens/:tokenId",
+		middleware.RequirePermission("assessment", "update"),
+		middleware.RequireScope("assessments:write"),
+		shareHandler.RevokeShareToken,
+	)
+
+	// Get the access log of a share token (requires assessment:read permission)
+	router.Get("/:id/share-tokens/:tokenId/access-log",
+		middleware.RequirePermission("assessment", "read"),
+		middleware.RequireScope("assessments:read"),
+		shareHandler.GetShareTokenAccessLog,
+	)
+}
+
+// SetupAuditorPortalRoutes configures the read-only auditor portal, authenticated by a share token
+// instead of a user session
+func SetupAuditorPortalRoutes(router fiber.Router) {
+	handler := NewAuditorShareHandler()
+
+	router.Use(middleware.AuditorPortalRateLimiter())
+
+	router.Get("/", handler.GetPortal)
+	router.Get("/reports/:reportId/file", handler.GetPortalReportFile)
+	router.Get("/evidence/:attachmentId/file", handler.GetPortalEvidenceFile)
+}
+
+// SetupReportRoutes configures report generation routes
+func SetupReportRoutes(router fiber.Router) {
 	db := database.GetDB()
 	reportService := services.NewReportService(db)
-	handler := NewReportHandler(reportService)
+	summaryService := services.NewReportSummaryService(db)
+	handler := NewReportHandler(reportService, services.NewRemediationAnalyticsService(db), summaryService)
+
+	// Report signature checks for report recipients, who need no account. Registered before the
+	// authentication middleware, which does not run for them.
+	signatureHandler := NewReportSignatureHandler()
+	router.Get("/signing-key", middleware.ReportVerifyRateLimiter(), signatureHandler.GetSigningKey)
+	router.Post("/verify", middleware.ReportVerifyRateLimiter(), signatureHandler.VerifyReport)
 
-	// All report routes require authentication
+	// All other report routes require authentication
 	router.Use(middleware.AuthMiddleware())
 
 	// Analyst report - detailed technical report (requires report:generate permission)
 	router.Get("/analyst",
 		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
 		handler.GetAnalystReport,
 	)
 
 	// Executive report - high-level metrics (requires report:generate permission)
 	router.Get("/executive",
 		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
 		handler.GetExecutiveReport,
 	)
 
+	// Executive summary and key-risk narrative written by the configured language model (requires report:generate permission)
+	summaryHandler := NewReportSummaryHandler(summaryService)
+	router.Post("/executive/summarize",
+		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
+		summaryHandler.SummarizeExecutiveReport,
+	)
+
 	// Audit report - compliance and audit trail (requires report:generate permission)
 	router.Get("/audit",
 		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
 		handler.GetAuditReport,
 	)
 
+	// Remediation analytics for dashboards - MTTR, aging, burn-down, forecast, top assets and OWASP/ATT&CK coverage (requires report:generate permission)
+	router.Get("/analytics",
+		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
+		handler.GetRemediationAnalytics,
+	)
+
+	router.Get("/analytics/mttr",
+		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
+		handler.GetMTTRAnalytics,
+	)
+
+	router.Get("/analytics/aging",
+		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
+		handler.GetAgingAnalytics,
+	)
+
+	router.Get("/analytics/burndown",
+		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
+		handler.GetBurnDownAnalytics,
+	)
+
+	router.Get("/analytics/forecast",
+		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
+		handler.GetRemediationForecast,
+	)
+
+	router.Get("/analytics/top-assets",
+		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
+		handler.GetTopAssetAnalytics,
+	)
+
+	router.Get("/analytics/coverage",
+		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
+		handler.GetCoverageHeatmap,
+	)
+
+	// Month-over-month comparison from the daily metrics snapshots (requires report:generate permission)
+	router.Get("/compare",
+		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
+		handler.ComparePeriods,
+	)
+
 	// Export endpoints (requires report:export permission)
 	router.Get("/analyst/export/csv",
 		middleware.RequirePermission("report", "export"),
+		middleware.RequireScope("reports:export"),
 		handler.ExportAnalystReportCSV,
 	)
 
 	router.Get("/executive/export/csv",
 		middleware.RequirePermission("report", "export"),
+		middleware.RequireScope("reports:export"),
 		handler.ExportExecutiveReportCSV,
 	)
 
 	router.Get("/audit/export/csv",
 		middleware.RequirePermission("report", "export"),
+		middleware.RequireScope("reports:export"),
 		handler.ExportAuditReportCSV,
 	)
+
+	// Report templates to brand reports and exports with; managed under /admin/report-templates
+	// (requires report:generate permission)
+	templateHandler := NewReportTemplateHandler()
+	router.Get("/templates",
+		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
+		templateHandler.ListTemplates,
+	)
+	router.Get("/templates/:id",
+		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
+		templateHandler.GetTemplate,
+	)
+	router.Get("/templates/:id/logo",
+		middleware.RequirePermission("report", "generate"),
+		middleware.RequireScope("reports:read"),
+		templateHandler.GetLogo,
+	)
+}
+
+// SetupDashboardRoutes configures dashboard display routes
+func SetupDashboardRoutes(router fiber.Router) {
+	db := database.GetDB()
+	handler := NewDashboardHandler(services.NewDashboardService(db), services.NewMetricsSnapshotService(db))
+
+	// All dashboard routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	// Wallboard data (API keys need the restricted dashboard:wallboard scope)
+	router.Get("/wallboard",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("dashboard:wallboard"),
+		handler.GetWallboard,
+	)
+
+	// Daily metric snapshots for trend charts
+	router.Get("/trends",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireAnyScope("vulnerabilities:read", "vulnerabilities:stats"),
+		handler.GetTrends,
+	)
+}
+
+// SetupCustomDashboardRoutes configures user-composed dashboard routes
+func SetupCustomDashboardRoutes(router fiber.Router) {
+	handler := NewCustomDashboardHandler()
+
+	// All dashboard routes require authentication; dashboards are scoped to their owner or shared
+	router.Use(middleware.AuthMiddleware())
+
+	router.Get("/",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("dashboard:read"),
+		handler.ListDashboards,
+	)
+
+	router.Post("/",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("dashboard:write"),
+		handler.CreateDashboard,
+	)
+
+	router.Get("/:id",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("dashboard:read"),
+		handler.GetDashboard,
+	)
+
+	router.Put("/:id",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("dashboard:write"),
+		handler.UpdateDashboard,
+	)
+
+	router.Delete("/:id",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireScope("dashboard:write"),
+		handler.DeleteDashboard,
+	)
+
+	// Batched widget data
+	router.Get("/:id/data",
+		middleware.RequirePermission("vulnerability", "read"),
+		middleware.RequireAnyScope("vulnerabilities:read", "vulnerabilities:stats"),
+		handler.GetDashboardData,
+	)
+}
+
+// SetupGraphQLRoutes configures the read-only GraphQL endpoint
+func SetupGraphQLRoutes(router fiber.Router) {
+	handler := NewGraphQLHandler()
+
+	// Requires authentication; permissions and API key scopes are checked per field
+	router.Use(middleware.AuthMiddleware())
+
+	router.Get("/", handler.Execute)
+	router.Post("/", handler.Execute)
+}
+
+// SetupRiskAcceptanceRoutes configures the risk acceptance request and review routes
+func SetupRiskAcceptanceRoutes(router fiber.Router) {
+	handler := NewRiskAcceptanceHandler()
+
+	// All risk acceptance routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	// Review queue
+	router.Get("/",
+		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
+		handler.ListRiskAcceptances,
+	)
+
+	router.Get("/:id",
+		middleware.RequirePermission("finding", "read"),
+		middleware.RequireScope("findings:read"),
+		handler.GetRiskAcceptance,
+	)
+
+	// Submit a request for review
+	router.Post("/",
+		middleware.RequirePermission("finding", "request_risk_acceptance"),
+		middleware.RequireScope("findings:write"),
+		handler.CreateRiskAcceptance,
+	)
+
+	// Approver actions (requesters cannot review their own requests)
+	router.Post("/:id/approve",
+		middleware.RequirePermission("finding", "accept_risk"),
+		middleware.RequireScope("findings:write"),
+		handler.ApproveRiskAcceptance,
+	)
+
+	router.Post("/:id/reject",
+		middleware.RequirePermission("finding", "accept_risk"),
+		middleware.RequireScope("findings:write"),
+		handler.RejectRiskAcceptance,
+	)
+
+	// Requester withdraws a pending request
+	router.Post("/:id/cancel",
+		middleware.RequirePermission("finding", "request_risk_acceptance"),
+		middleware.RequireScope("findings:write"),
+		handler.CancelRiskAcceptance,
+	)
+}
+
+// SetupSavedViewRoutes configures saved filter/sort view routes
+func SetupSavedViewRoutes(router fiber.Router) {
+	handler := NewSavedViewHandler()
+
+	// All saved view routes require authentication; views are scoped to their owner or shared
+	router.Use(middleware.AuthMiddleware())
+
+	canRead := middleware.RequireScope("saved_views:read")
+	canWrite := middleware.RequireScope("saved_views:write")
+
+	router.Get("/", canRead, handler.ListViews)
+	router.Post("/", canWrite, handler.CreateView)
+	router.Get("/:id", canRead, handler.GetView)
+	router.Put("/:id", canWrite, handler.UpdateView)
+	router.Delete("/:id", canWrite, handler.DeleteView)
+}
+
+// SetupSuppressionRuleRoutes configures suppression rule management routes
+func SetupSuppressionRuleRoutes(router fiber.Router) {
+	handler := NewSuppressionRuleHandler()
+
+	// All suppression rule routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	router.Get("/",
+		middleware.RequirePermission("suppression", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.ListRules,
+	)
+
+	router.Post("/",
+		middleware.RequirePermission("suppression", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.CreateRule,
+	)
+
+	router.Get("/:id",
+		middleware.RequirePermission("suppression", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.GetRule,
+	)
+
+	// Audit log of findings suppressed by the rule
+	router.Get("/:id/hits",
+		middleware.RequirePermission("suppression", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.ListRuleHits,
+	)
+
+	router.Put("/:id",
+		middleware.RequirePermission("suppression", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.UpdateRule,
+	)
+
+	router.Delete("/:id",
+		middleware.RequirePermission("suppression", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.DeleteRule,
+	)
+}
+
+// SetupPolicyRuleRoutes configures policy rule management routes
+func SetupPolicyRuleRoutes(router fiber.Router) {
+	handler := NewPolicyViolationHandler()
+
+	// All policy rule routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	router.Get("/",
+		middleware.RequirePermission("policy", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.ListRules,
+	)
+
+	router.Post("/",
+		middleware.RequirePermission("policy", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.CreateRule,
+	)
+
+	// Run the policy evaluation now instead of waiting for the hourly job
+	router.Post("/evaluate",
+		middleware.RequirePermission("policy", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.EvaluateRules,
+	)
+
+	router.Get("/:id",
+		middleware.RequirePermission("policy", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.GetRule,
+	)
+
+	router.Put("/:id",
+		middleware.RequirePermission("policy", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.UpdateRule,
+	)
+
+	router.Delete("/:id",
+		middleware.RequirePermission("policy", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.DeleteRule,
+	)
+}
+
+// SetupPolicyViolationRoutes configures policy violation tracking routes
+func SetupPolicyViolationRoutes(router fiber.Router) {
+	handler := NewPolicyViolationHandler()
+
+	// All policy violation routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	router.Get("/",
+		middleware.RequirePermission("policy", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.ListViolations,
+	)
+
+	router.Get("/:id",
+		middleware.RequirePermission("policy", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.GetViolation,
+	)
+}
+
+// SetupEscalationPolicyRoutes configures escalation policy management routes
+func SetupEscalationPolicyRoutes(router fiber.Router) {
+	handler := NewEscalationHandler()
+
+	// All escalation policy routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	router.Get("/",
+		middleware.RequirePermission("policy", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.ListPolicies,
+	)
+
+	router.Post("/",
+		middleware.RequirePermission("policy", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.CreatePolicy,
+	)
+
+	// Run the escalation policies now instead of waiting for the hourly job
+	router.Post("/evaluate",
+		middleware.RequirePermission("policy", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.EvaluatePolicies,
+	)
+
+	router.Get("/:id",
+		middleware.RequirePermission("policy", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.GetPolicy,
+	)
+
+	router.Put("/:id",
+		middleware.RequirePermission("policy", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.UpdatePolicy,
+	)
+
+	router.Delete("/:id",
+		middleware.RequirePermission("policy", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.DeletePolicy,
+	)
+}
+
+// SetupAssignmentRuleRoutes configures assignment rule management routes
+func SetupAssignmentRuleRoutes(router fiber.Router) {
+	handler := NewAssignmentRuleHandler()
+
+	// All assignment rule routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	router.Get("/",
+		middleware.RequirePermission("policy", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.ListRules,
+	)
+
+	router.Post("/",
+		middleware.RequirePermission("policy", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.CreateRule,
+	)
+
+	// Dry run of the rules against existing vulnerabilities
+	router.Post("/simulate",
+		middleware.RequirePermission("policy", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.SimulateRules,
+	)
+
+	router.Get("/:id",
+		middleware.RequirePermission("policy", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.GetRule,
+	)
+
+	router.Put("/:id",
+		middleware.RequirePermission("policy", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.UpdateRule,
+	)
+
+	router.Delete("/:id",
+		middleware.RequirePermission("policy", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.DeleteRule,
+	)
+}
+
+// SetupNetworkRangeRoutes configures network range management routes
+func SetupNetworkRangeRoutes(router fiber.Router) {
+	handler := NewNetworkRangeHandler()
+
+	// All network range routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	router.Get("/",
+		middleware.RequirePermission("network_range", "read"),
+		middleware.RequireScope("assets:read"),
+		handler.ListRanges,
+	)
+
+	// Resolve an IP address to its range (must come before /:id)
+	router.Get("/lookup",
+		middleware.RequirePermission("network_range", "read"),
+		middleware.RequireScope("assets:read"),
+		handler.LookupRange,
+	)
+
+	router.Post("/",
+		middleware.RequirePermission("network_range", "manage"),
+		middleware.RequireScope("assets:write"),
+		handler.CreateRange,
+	)
+
+	router.Get("/:id",
+		middleware.RequirePermission("network_range", "read"),
+		middleware.RequireScope("assets:read"),
+		handler.GetRange,
+	)
+
+	router.Put("/:id",
+		middleware.RequirePermission("network_range", "manage"),
+		middleware.RequireScope("assets:write"),
+		handler.UpdateRange,
+	)
+
+	router.Delete("/:id",
+		middleware.RequirePermission("network_range", "manage"),
+		middleware.RequireScope("assets:write"),
+		handler.DeleteRange,
+	)
+}
+
+// SetupImportJobRoutes configures the routes acting on finished import jobs
+func SetupImportJobRoutes(router fiber.Router) {
+	handler := NewVulnerabilityImportHandler()
+
+	// All import job routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	// Revert a finished import: delete what it created and restore what it updated
+	router.Post("/:id/rollback",
+		middleware.RequirePermission("vulnerability", "import"),
+		middleware.RequireScope("vulnerabilities:import"),
+		handler.RollbackImportJob,
+	)
+}
+
+// SetupImportRuleRoutes configures the routes managing the rules that classify imported hosts
+func SetupImportRuleRoutes(router fiber.Router) {
+	handler := NewImportRuleHandler()
+
+	// All import rule routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	router.Get("/",
+		middleware.RequirePermission("network_range", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.ListRules,
+	)
+
+	router.Post("/",
+		middleware.RequirePermission("network_range", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.CreateRule,
+	)
+
+	// Classify a sample host (must come before /:id)
+	router.Post("/test",
+		middleware.RequirePermission("network_range", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.TestRules,
+	)
+
+	router.Get("/:id",
+		middleware.RequirePermission("network_range", "read"),
+		middleware.RequireScope("rules:read"),
+		handler.GetRule,
+	)
+
+	router.Put("/:id",
+		middleware.RequirePermission("network_range", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.UpdateRule,
+	)
+
+	router.Delete("/:id",
+		middleware.RequirePermission("network_range", "manage"),
+		middleware.RequireScope("rules:write"),
+		handler.DeleteRule,
+	)
+}
+
+// SetupNotificationRoutes configures the current user's notification routes
+func SetupNotificationRoutes(router fiber.Router) {
+	handler := NewNotificationHandler()
+
+	// All notification routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	router.Get("/", middleware.RequireScope("notifications:read"), handler.ListNotifications)
+	router.Post("/read-all", middleware.RequireScope("notifications:write"), handler.MarkAllRead)
+	router.Post("/:id/read", middleware.RequireScope("notifications:write"), handler.MarkRead)
 }
 
 // SetupAPIKeyRoutes configures API key management routes
 func SetupAPIKeyRoutes(router fiber.Router) {
 	handler := NewAPIKeyHandler()
 
-	// All API key routes require authentication
+	// All API key routes require authentication; impersonators cannot mint or manage keys
 	router.Use(middleware.AuthMiddleware())
+	router.Use(middleware.DenyDuringImpersonation())
+
+	// Keys may only manage keys when granted the api_keys scopes
+	canRead := middleware.RequireScope("api_keys:read")
+	canWrite := middleware.RequireScope("api_keys:write")
 
 	// List user's API keys (no additional permission required - users manage their own keys)
-	router.Get("/", handler.ListAPIKeys)
+	router.Get("/", canRead, handler.ListAPIKeys)
+
+	// Scope catalog and introspection of the calling key
+	// Note: These must come BEFORE /:id to avoid route conflict
+	router.Get("/scopes", handler.ListScopes)
+	router.Get("/current", handler.GetCurrentAPIKey)
 
 	// Create new API key (no additional permission required)
-	router.Post("/", handler.CreateAPIKey)
+	router.Post("/", canWrite, handler.CreateAPIKey)
 
 	// Get specific API key (no additional permission required)
-	router.Get("/:id", handler.GetAPIKey)
+	router.Get("/:id", canRead, handler.GetAPIKey)
+
+	// Usage analytics of an API key (requests per day and per endpoint)
+	router.Get("/:id/usage", canRead, handler.GetAPIKeyUsage)
 
 	// Update API key status (no additional permission required)
-	router.Patch("/:id/status", handler.UpdateAPIKeyStatus)
+	router.Patch("/:id/status", canWrite, handler.UpdateAPIKeyStatus)
+
+	// Restrict the networks the key may be used from
+	router.Put("/:id/allowed-cidrs", canWrite, handler.UpdateAPIKeyAllowedCIDRs)
 
 	// Revoke API key (no additional permission required)
-	router.Post("/:id/revoke", handler.RevokeAPIKey)
+	router.Post("/:id/revoke", canWrite, handler.RevokeAPIKey)
+
+	// Rotate a service/MCP key; the previous secret stays valid for a grace period
+	router.Post("/:id/rotate", canWrite, handler.RotateAPIKey)
+	router.Delete("/:id/secondary", canWrite, handler.RetireSecondaryAPIKey)
 
 	// Delete API key (no additional permission required)
-	router.Delete("/:id", handler.DeleteAPIKey)
+	router.Delete("/:id", canWrite, handler.DeleteAPIKey)
 }
 
 // SetupSystemSettingsRoutes configures system settings routes
@@ -822,20 +2195,229 @@ func SetupSystemSettingsRoutes(router fiber.Router) {
 		services.NewSystemSettingsService(database.GetDB()),
 	)
 
-	// All system settings routes require authentication and admin permission
+	// All system settings routes require authentication and admin permission; settings are
+	// global, so only platform administrators may manage them, from the admin networks
 	router.Use(middleware.AuthMiddleware())
+	router.Use(middleware.RequireAdminIPAccess())
 	router.Use(middleware.RequireAdmin())
+	router.Use(middleware.RequirePlatformOrganization())
+
+	canRead := middleware.RequireScope("admin:read")
+	canWrite := middleware.RequireScope("admin:write")
 
 	// Get all system settings
-	router.Get("/", handler.GetAllSettings)
+	router.Get("/", canRead, handler.GetAllSettings)
 
 	// Get specific system setting
-	router.Get("/:key", handler.GetSetting)
+	router.Get("/:key", canRead, handler.GetSetting)
 
 	// Update system setting
-	router.Put("/:key", handler.UpdateSetting)
+	router.Put("/:key", canWrite, handler.UpdateSetting)
+
+	// SIEM forwarder delivery status
+	router.Get("/siem/status", canRead, handler.GetSIEMStatus)
+
+	// Emailed vulnerability reports
+	emailIngestionHandler := NewEmailIngestionHandler()
+	router.Get("/email-ingestion/messages", canRead, emailIngestionHandler.ListInboundEmails)
+	router.Post("/email-ingestion/poll", canWrite, emailIngestionHandler.PollMailbox)
 
 	// MCP Server specific endpoints
-	router.Get("/mcp/status", handler.GetMCPStatus)
-	router.Post("/mcp/toggle", handler.ToggleMCPServer)
+	router.Get("/mcp/status", canRead, handler.GetMCPStatus)
+	router.Post("/mcp/toggle", canWrite, handler.ToggleMCPServer)
+}
+
+// SetupInboundEmailRoutes configures the webhook inbound mail providers post vulnerability
+// report emails to
+func SetupInboundEmailRoutes(router fiber.Router) {
+	handler := NewEmailIngestionHandler()
+
+	router.Post("/", middleware.AuthRateLimiter(), handler.ReceiveInboundEmail)
+}
+
+// SetupEmailWebhookRoutes configures the webhooks email providers post delivery status events to
+func SetupEmailWebhookRoutes(router fiber.Router) {
+	handler := NewEmailDeliveryHandler()
+
+	router.Post("/:provider", middleware.AuthRateLimiter(), handler.ReceiveDeliveryEvents)
+}
+
+// SetupDisclosureRoutes configures the public vulnerability disclosure form and the triage
+// queue its submissions land in
+func SetupDisclosureRoutes(router fiber.Router, cfg *config.Config) {
+	handler := NewDisclosureHandler(cfg)
+
+	// Unauthenticated submissions (captcha verified by the handler)
+	if cfg.DisclosuresEnabled {
+		router.Post("/", middleware.DisclosureRateLimiter(), handler.SubmitDisclosure)
+	}
+
+	router.Get("/",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission("disclosure", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		handler.ListDisclosures,
+	)
+
+	router.Get("/:id",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission("disclosure", "read"),
+		middleware.RequireScope("vulnerabilities:read"),
+		handler.GetDisclosure,
+	)
+
+	router.Post("/:id/accept",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission("disclosure", "triage"),
+		middleware.RequirePermission("vulnerability", "write"),
+		middleware.RequireScope("vulnerabilities:write"),
+		handler.AcceptDisclosure,
+	)
+
+	router.Post("/:id/duplicate",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission("disclosure", "triage"),
+		middleware.RequireScope("vulnerabilities:write"),
+		handler.MarkDuplicate,
+	)
+
+	router.Post("/:id/reject",
+		middleware.AuthMiddleware(),
+		middleware.RequirePermission("disclosure", "triage"),
+		middleware.RequireScope("vulnerabilities:write"),
+		handler.RejectDisclosure,
+	)
+}
+
+// SetupOrganizationRoutes configures organization (tenant) management routes
+func SetupOrganizationRoutes(router fiber.Router) {
+	handler := NewOrganizationHandler()
+
+	// All organization routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	readScope := middleware.RequireScope("admin:read")
+	writeScope := middleware.RequireScope("admin:write")
+
+	// Current organization of the caller (no additional permission required)
+	// Note: This must come BEFORE /:id to avoid route conflict
+	router.Get("/current", readScope, handler.GetCurrent)
+
+	// Managing organizations is reserved to platform administrators
+	platformOnly := middleware.RequirePlatformOrganization()
+	canManage := middleware.RequirePermission("organization", "manage")
+
+	router.Get("/", platformOnly, canManage, readScope, handler.ListOrganizations)
+	router.Post("/", platformOnly, canManage, writeScope, handler.CreateOrganization)
+	router.Get("/:id", platformOnly, canManage, readScope, handler.GetOrganization)
+	router.Put("/:id", platformOnly, canManage, writeScope, handler.UpdateOrganization)
+	router.Delete("/:id", platformOnly, canManage, writeScope, handler.DeleteOrganization)
+	router.Put("/:id/users/:userId", platformOnly, canManage, writeScope, handler.AssignUser)
+}
+
+// SetupTeamRoutes configures team management routes
+func SetupTeamRoutes(router fiber.Router) {
+	handler := NewTeamHandler()
+
+	// All team routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	canRead := middleware.RequirePermission("team", "read")
+	canManage := middleware.RequirePermission("team", "manage")
+	readScope := middleware.RequireScope("admin:read")
+	writeScope := middleware.RequireScope("admin:write")
+
+	router.Get("/", canRead, readScope, handler.ListTeams)
+	router.Post("/", canManage, writeScope, handler.CreateTeam)
+	router.Get("/:id", canRead, readScope, handler.GetTeam)
+	router.Put("/:id", canManage, writeScope, handler.UpdateTeam)
+	router.Delete("/:id", canManage, writeScope, handler.DeleteTeam)
+	router.Post("/:id/members", canManage, writeScope, handler.AddMember)
+	router.Delete("/:id/members/:userId", canManage, writeScope, handler.RemoveMember)
+}
+
+// SetupAssetGroupRoutes configures asset group routes
+func SetupAssetGroupRoutes(router fiber.Router) {
+	handler := NewAssetGroupHandler()
+
+	// All asset group routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	canRead := middleware.RequirePermission("asset", "read")
+	canWrite := middleware.RequirePermission("asset", "write")
+	canDelete := middleware.RequirePermission("asset", "delete")
+	readScope := middleware.RequireScope("assets:read")
+	writeScope := middleware.RequireScope("assets:write")
+	deleteScope := middleware.RequireScope("assets:delete")
+
+	router.Get("/", canRead, readScope, handler.ListAssetGroups)
+	router.Post("/", canWrite, writeScope, handler.CreateAssetGroup)
+	router.Get("/:id", canRead, readScope, handler.GetAssetGroup)
+	router.Put("/:id", canWrite, writeScope, handler.UpdateAssetGroup)
+	router.Delete("/:id", canDelete, deleteScope, handler.DeleteAssetGroup)
+	router.Get("/:id/members", canRead, readScope, handler.ListAssetGroupMembers)
+	router.Post("/:id/members", canWrite, writeScope, handler.AddAssetGroupMembers)
+	router.Delete("/:id/members/:assetId", canWrite, writeScope, handler.RemoveAssetGroupMember)
+	router.Post("/:id/recompute", canWrite, writeScope, handler.RecomputeAssetGroup)
+}
+
+// SetupBusinessServiceRoutes configures business service routes
+func SetupBusinessServiceRoutes(router fiber.Router) {
+	handler := NewBusinessServiceHandler()
+
+	// All business service routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	canRead := middleware.RequirePermission("asset", "read")
+	canWrite := middleware.RequirePermission("asset", "write")
+	canDelete := middleware.RequirePermission("asset", "delete")
+	readScope := middleware.RequireScope("assets:read")
+	writeScope := middleware.RequireScope("assets:write")
+	deleteScope := middleware.RequireScope("assets:delete")
+
+	router.Get("/", canRead, readScope, handler.ListBusinessServices)
+	router.Post("/", canWrite, writeScope, handler.CreateBusinessService)
+	// Rollup of every service (must come before /:id)
+	router.Get("/risk", canRead, readScope, handler.GetBusinessServicesRisk)
+	router.Get("/:id", canRead, readScope, handler.GetBusinessService)
+	router.Put("/:id", canWrite, writeScope, handler.UpdateBusinessService)
+	router.Delete("/:id", canDelete, deleteScope, handler.DeleteBusinessService)
+	router.Get("/:id/risk", canRead, readScope, handler.GetBusinessServiceRisk)
+	router.Get("/:id/assets", canRead, readScope, handler.ListBusinessServiceAssets)
+	router.Post("/:id/assets", canWrite, writeScope, handler.AddBusinessServiceAssets)
+	router.Delete("/:id/assets/:assetId", canWrite, writeScope, handler.RemoveBusinessServiceAsset)
+}
+
+// SetupCriticalityScoringRoutes configures asset criticality scoring profile routes
+func SetupCriticalityScoringRoutes(router fiber.Router) {
+	handler := NewCriticalityScoringHandler()
+
+	// All scoring routes require authentication
+	router.Use(middleware.AuthMiddleware())
+
+	router.Get("/profile",
+		middleware.RequirePermission("asset", "read"),
+		middleware.RequireScope("assets:read"),
+		handler.GetProfile,
+	)
+
+	router.Put("/profile",
+		middleware.RequirePermission("asset", "write"),
+		middleware.RequireScope("assets:write"),
+		handler.UpdateProfile,
+	)
+}
+
+// SetupAgentRoutes configures endpoint agent routes
+func SetupAgentRoutes(router fiber.Router) {
+	handler := NewAgentHandler()
+
+	// Agents authenticate with API keys granted the agent:checkin scope
+	router.Use(middleware.AuthMiddleware())
+
+	router.Post("/checkin",
+		middleware.RequirePermission("asset", "write"),
+		middleware.RequireScope("agent:checkin"),
+		handler.CheckIn,
+	)
 }
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// SavedViewHandler handles saved filter/sort views
+type SavedViewHandler struct {
+	service *services.SavedViewService
+}
+
+// NewSavedViewHandler creates a new saved view handler
+func NewSavedViewHandler() *SavedViewHandler {
+	return &SavedViewHandler{
+		service: services.NewSavedViewService(database.GetDB()),
+	}
+}
+
+// savedViewErrorResponse maps saved view service errors to HTTP responses
+func savedViewErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		return middleware.NotFoundError(c, "Saved view")
+	case strings.Contains(msg, "only the owner"):
+		return middleware.ForbiddenError(c, msg)
+	case strings.Contains(msg, "required"), strings.Contains(msg, "invalid"), strings.Contains(msg, "saved view is for"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// applySavedView merges the filters of the view referenced by ?view_id= into the request's
+// query parameters. Parameters given explicitly on the request take precedence.
+// Errors should be rendered with savedViewErrorResponse.
+func applySavedView(c *fiber.Ctx, resourceType models.SavedViewResource) error {
+	viewIDParam := c.Query("view_id")
+	if viewIDParam == "" {
+		return nil
+	}
+
+	viewID, err := uuid.Parse(viewIDParam)
+	if err != nil {
+		return fmt.Errorf("invalid view_id")
+	}
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	filters, err := services.NewSavedViewService(database.GetDB().WithContext(c.UserContext())).ResolveFilters(viewID, userID, resourceType)
+	if err != nil {
+		return err
+	}
+
+	args := c.Request().URI().QueryArgs()
+	for key, value := range filters {
+		if len(args.Peek(key)) == 0 {
+			args.Set(key, value)
+		}
+	}
+	return nil
+}
+
+// ListViews lists the caller's own and shared saved views
+// GET /api/v1/saved-views?resource_type=vulnerability
+func (h *SavedViewHandler) ListViews(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	views, err := h.service.WithContext(c.UserContext()).ListViews(userID, c.Query("resource_type"))
+	if err != nil {
+		return savedViewErrorResponse(c, err, "Failed to list saved views")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": views,
+	})
+}
+
+// GetView returns a saved view
+// GET /api/v1/saved-views/:id
+func (h *SavedViewHandler) GetView(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid saved view ID", nil)
+	}
+
+	view, err := h.service.WithContext(c.UserContext()).GetView(id, userID)
+	if err != nil {
+		return savedViewErrorResponse(c, err, "Failed to get saved view")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": view,
+	})
+}
+
+// CreateView creates a saved view
+// POST /api/v1/saved-views
+func (h *SavedViewHandler) CreateView(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.SavedViewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	view, err := h.service.WithContext(c.UserContext()).CreateView(req, userID)
+	if err != nil {
+		return savedViewErrorResponse(c, err, "Failed to create saved view")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Saved view created successfully",
+		"data":    view,
+	})
+}
+
+// UpdateView updates a saved view (owner only)
+// PUT /api/v1/saved-views/:id
+func (h *SavedViewHandler) UpdateView(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid saved view ID", nil)
+	}
+
+	var req services.SavedViewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	view, err := h.service.WithContext(c.UserContext()).UpdateView(id, userID, req)
+	if err != nil {
+		return savedViewErrorResponse(c, err, "Failed to update saved view")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Saved view updated successfully",
+		"data":    view,
+	})
+}
+
+// DeleteView deletes a saved view (owner only)
+// DELETE /api/v1/saved-views/:id
+func (h *SavedViewHandler) DeleteView(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid saved view ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).DeleteView(id, userID); err != nil {
+		return savedViewErrorResponse(c, err, "Failed to delete saved view")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Saved view deleted successfully",
+	})
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// SBOMHandler handles software bills of materials uploaded for assets
+type SBOMHandler struct {
+	service *services.SBOMService
+}
+
+// NewSBOMHandler creates a new SBOM handler
+func NewSBOMHandler() *SBOMHandler {
+	return &SBOMHandler{
+		service: services.NewSBOMService(database.GetDB()),
+	}
+}
+
+// sbomErrorResponse maps SBOM service errors to HTTP responses
+func sbomErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case msg == "asset not found":
+		return middleware.NotFoundError(c, "Asset")
+	case strings.HasPrefix(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// UploadSBOM replaces an asset's software components with those of a CycloneDX or SPDX JSON
+// document, sent as the request body or as the "file" field of a multipart form, and creates
+// findings for components affected by known vulnerabilities
+// @Summary Upload asset SBOM
+// @Tags Assets
+// @Accept json
+// @Produce json
+// @Param id path string true "Asset ID"
+// @Success 200 {object} services.SBOMImportResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/assets/{id}/sbom [post]
+// @Security BearerAuth
+func (h *SBOMHandler) UploadSBOM(c *fiber.Ctx) error {
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset ID", nil)
+	}
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	data := c.Body()
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		file, err := c.FormFile("file")
+		if err != nil {
+			return middleware.ValidationError(c, "No file uploaded", nil)
+		}
+		src, err := file.Open()
+		if err != nil {
+			utils.Logger.Error().Err(err).Msg("Failed to open uploaded SBOM")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to process uploaded file",
+			})
+		}
+		defer src.Close()
+		if data, err = io.ReadAll(src); err != nil {
+			utils.Logger.Error().Err(err).Msg("Failed to read uploaded SBOM")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to read uploaded file",
+			})
+		}
+	}
+
+	result, err := h.service.WithContext(c.UserContext()).ImportSBOM(assetID, data, userID)
+	if err != nil {
+		return sbomErrorResponse(c, err, "Failed to import SBOM")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "SBOM imported successfully",
+		"data":    result,
+	})
+}
+
+// ListSBOMComponents lists the software components of the SBOM last uploaded for an asset
+// @Summary List asset SBOM components
+// @Tags Assets
+// @Produce json
+// @Param id path string true "Asset ID"
+// @Param search query string false "Filter by component name or package URL"
+// @Param vulnerable query bool false "Only components affected by a known vulnerability"
+// @Param page query int false "Page"
+// @Param limit query int false "Page size (max 500)"
+// @Success 200 {array} models.AssetSBOMComponent
+// @Router /api/v1/assets/{id}/sbom [get]
+// @Security BearerAuth
+func (h *SBOMHandler) ListSBOMComponents(c *fiber.Ctx) error {
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset ID", nil)
+	}
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 100)
+
+	components, total, err := h.service.WithContext(c.UserContext()).ListComponents(assetID, c.Query("search"), c.QueryBool("vulnerable"), page, limit)
+	if err != nil {
+		return sbomErrorResponse(c, err, "Failed to list asset components")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": components,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
+
+// ExportVEX downloads a CycloneDX VEX document stating, for each of the asset's findings, whether
+// it is exploitable, resolved or not affected (mitigated or risk accepted), so consumers of the
+// asset's SBOM can act on the decisions made about it
+// @Summary Export asset VEX
+// @Tags Assets
+// @Produce json
+// @Param id path string true "Asset ID"
+// @Success 200 {object} sbom.VEXDocument
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/assets/{id}/vex [get]
+// @Security BearerAuth
+func (h *SBOMHandler) ExportVEX(c *fiber.Ctx) error {
+	assetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid asset ID", nil)
+	}
+
+	doc, err := h.service.WithContext(c.UserContext()).ExportVEX(assetID)
+	if err != nil {
+		return sbomErrorResponse(c, err, "Failed to export VEX")
+	}
+
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"asset-%s.vex.json\"", assetID))
+	return c.Status(fiber.StatusOK).JSON(doc, "application/vnd.cyclonedx+json")
+}
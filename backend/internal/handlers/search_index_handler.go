@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+)
+
+// SearchIndexHandler handles search backend administration
+type SearchIndexHandler struct{}
+
+// NewSearchIndexHandler creates a new search index handler
+func NewSearchIndexHandler() *SearchIndexHandler {
+	return &SearchIndexHandler{}
+}
+
+// GetSearchStatus returns the active search backend and outbox backlog
+// @Summary Search backend status
+// @Description Returns the configured search backend, cluster reachability, and outbox backlog
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /api/v1/admin/search/status [get]
+// @Security BearerAuth
+func (h *SearchIndexHandler) GetSearchStatus(c *fiber.Ctx) error {
+	idx := services.ActiveSearchIndex()
+	if idx == nil {
+		return c.JSON(fiber.Map{
+			"data": services.SearchIndexStatus{Backend: "postgres", Reachable: true},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), 5*time.Second)
+	defer cancel()
+
+	status, err := idx.GetStatus(ctx)
+	if err != nil {
+		return middleware.InternalError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": status,
+	})
+}
+
+// ReindexSearch queues every vulnerability, asset, and finding for re-indexing
+// @Summary Rebuild search index
+// @Description Enqueues a full re-index of the OpenSearch backend
+// @Tags Admin
+// @Produce json
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/admin/search/reindex [post]
+// @Security BearerAuth
+func (h *SearchIndexHandler) ReindexSearch(c *fiber.Ctx) error {
+	idx := services.ActiveSearchIndex()
+	if idx == nil {
+		return middleware.ValidationError(c, "Search backend is postgres, nothing to reindex", nil)
+	}
+
+	count, err := idx.EnqueueFullReindex()
+	if err != nil {
+		return middleware.InternalError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"data":    fiber.Map{"queued": count},
+		"message": "Search reindex queued",
+	})
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/internal/services"
+)
+
+// parseFieldset reads the JSON:API-style ?fields= and ?include= parameters for a resource.
+// An empty ?include= is distinct from a missing one: it loads no relations at all.
+func parseFieldset(c *fiber.Ctx, resource *services.SparseResource) (*services.Fieldset, error) {
+	var include *string
+	if c.Context().QueryArgs().Has("include") {
+		value := c.Query("include")
+		include = &value
+	}
+	return services.ParseFieldset(resource, c.Query("fields"), include)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// SuppressionRuleHandler handles suppression rule management
+type SuppressionRuleHandler struct {
+	service *services.SuppressionService
+}
+
+// NewSuppressionRuleHandler creates a new suppression rule handler
+func NewSuppressionRuleHandler() *SuppressionRuleHandler {
+	return &SuppressionRuleHandler{
+		service: services.NewSuppressionService(database.GetDB()),
+	}
+}
+
+// suppressionErrorResponse maps suppression service errors to HTTP responses
+func suppressionErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		return middleware.NotFoundError(c, "Suppression rule")
+	case strings.Contains(msg, "required"), strings.Contains(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// ListRules lists suppression rules
+// GET /api/v1/suppression-rules?enabled=true
+func (h *SuppressionRuleHandler) ListRules(c *fiber.Ctx) error {
+	rules, err := h.service.WithContext(c.UserContext()).ListRules(c.QueryBool("enabled", false))
+	if err != nil {
+		return suppressionErrorResponse(c, err, "Failed to list suppression rules")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": rules,
+	})
+}
+
+// GetRule returns a suppression rule
+// GET /api/v1/suppression-rules/:id
+func (h *SuppressionRuleHandler) GetRule(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid suppression rule ID", nil)
+	}
+
+	rule, err := h.service.WithContext(c.UserContext()).GetRule(id)
+	if err != nil {
+		return suppressionErrorResponse(c, err, "Failed to get suppression rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": rule,
+	})
+}
+
+// CreateRule creates a suppression rule applied to future imports
+// POST /api/v1/suppression-rules
+func (h *SuppressionRuleHandler) CreateRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	var req services.SuppressionRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	rule, err := h.service.WithContext(c.UserContext()).CreateRule(req, userID)
+	if err != nil {
+		return suppressionErrorResponse(c, err, "Failed to create suppression rule")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Suppression rule created successfully",
+		"data":    rule,
+	})
+}
+
+// UpdateRule updates a suppression rule
+// PUT /api/v1/suppression-rules/:id
+func (h *SuppressionRuleHandler) UpdateRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid suppression rule ID", nil)
+	}
+
+	var req services.SuppressionRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	rule, err := h.service.WithContext(c.UserContext()).UpdateRule(id, req, userID)
+	if err != nil {
+		return suppressionErrorResponse(c, err, "Failed to update suppression rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Suppression rule updated successfully",
+		"data":    rule,
+	})
+}
+
+// DeleteRule deletes a suppression rule
+// DELETE /api/v1/suppression-rules/:id
+func (h *SuppressionRuleHandler) DeleteRule(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid suppression rule ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).DeleteRule(id, userID); err != nil {
+		return suppressionErrorResponse(c, err, "Failed to delete suppression rule")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Suppression rule deleted successfully",
+	})
+}
+
+// ListRuleHits returns the audit log of findings suppressed by a rule
+// GET /api/v1/suppression-rules/:id/hits
+func (h *SuppressionRuleHandler) ListRuleHits(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid suppression rule ID", nil)
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 50)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	hits, total, err := h.service.WithContext(c.UserContext()).ListRuleHits(id, page, limit)
+	if err != nil {
+		return suppressionErrorResponse(c, err, "Failed to list suppression hits")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": hits,
+		"meta": fiber.Map{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	})
+}
@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"net"
+	"strconv"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
@@ -89,13 +93,19 @@ func (h *SystemSettingsHandler) UpdateSetting(c *fiber.Ctx) error {
 	// Update setting
 	setting, err := h.service.UpdateSetting(key, req.Value, req.Description, user.Email)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		utils.Logger.Error().Err(err).Str("key", key).Msg("Failed to update system setting")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update system setting",
 		})
 	}
 
-	utils.Logger.Info().Str("key", key).Str("value", req.Value).Str("updated_by", user.Email).Msg("System setting updated")
+	// Log the stored value, in which secrets are encrypted
+	utils.Logger.Info().Str("key", key).Str("value", setting.Value).Str("updated_by", user.Email).Msg("System setting updated")
 
 	return c.JSON(fiber.Map{
 		"message": "Setting updated successfully",
@@ -103,6 +113,27 @@ func (h *SystemSettingsHandler) UpdateSetting(c *fiber.Ctx) error {
 	})
 }
 
+// GetSIEMStatus returns whether events are forwarded to a SIEM and the forwarder's delivery counters
+// GET /api/v1/settings/siem/status
+func (h *SystemSettingsHandler) GetSIEMStatus(c *fiber.Ctx) error {
+	forwarder := services.ActiveSIEMForwarder()
+	if forwarder == nil {
+		return c.JSON(fiber.Map{
+			"enabled": false,
+			"key":     string(models.SystemSettingSIEMForwarder),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"enabled":     true,
+		"key":         string(models.SystemSettingSIEMForwarder),
+		"destination": net.JoinHostPort(forwarder.Settings.Host, strconv.Itoa(forwarder.Settings.Port)),
+		"protocol":    forwarder.Settings.Protocol,
+		"format":      forwarder.Settings.Format,
+		"stats":       forwarder.Stats(),
+	})
+}
+
 // GetMCPStatus returns the current MCP server status
 // GET /api/v1/settings/mcp/status
 func (h *SystemSettingsHandler) GetMCPStatus(c *fiber.Ctx) error {
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/pkg/taxonomy"
+)
+
+// TaxonomyHandler serves the OWASP Top 10 categories and ATT&CK techniques vulnerabilities are
+// classified in
+type TaxonomyHandler struct{}
+
+// NewTaxonomyHandler creates a new taxonomy handler
+func NewTaxonomyHandler() *TaxonomyHandler {
+	return &TaxonomyHandler{}
+}
+
+// TaxonomyResponse lists the categories and techniques vulnerabilities can be classified in
+type TaxonomyResponse struct {
+	OWASP   []taxonomy.Entry  `json:"owasp"`   // In rank order
+	ATTACK  []taxonomy.Entry  `json:"attack"`  // By tactic
+	Tactics []taxonomy.Tactic `json:"tactics"` // In kill chain order
+}
+
+// GetTaxonomy lists the OWASP Top 10 categories and ATT&CK techniques
+// @Summary Get classification taxonomy
+// @Description The OWASP Top 10 (2021) categories and the ATT&CK techniques and tactics vulnerabilities are mapped to
+// @Tags Vulnerabilities
+// @Produce json
+// @Success 200 {object} TaxonomyResponse
+// @Router /api/v1/vulnerabilities/taxonomy [get]
+// @Security BearerAuth
+func (h *TaxonomyHandler) GetTaxonomy(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"data": TaxonomyResponse{
+			OWASP:   taxonomy.Entries(taxonomy.FrameworkOWASP),
+			ATTACK:  taxonomy.Entries(taxonomy.FrameworkATTACK),
+			Tactics: taxonomy.Tactics(),
+		},
+	})
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// TeamHandler handles team management
+type TeamHandler struct {
+	service *services.TeamService
+}
+
+// NewTeamHandler creates a new team handler
+func NewTeamHandler() *TeamHandler {
+	return &TeamHandler{
+		service: services.NewTeamService(database.GetDB()),
+	}
+}
+
+// AddTeamMemberRequest is the payload for adding a team member
+type AddTeamMemberRequest struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// teamErrorResponse maps team service errors to HTTP responses
+func teamErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case msg == "team not found":
+		return middleware.NotFoundError(c, "Team")
+	case msg == "user not found":
+		return middleware.NotFoundError(c, "User")
+	case strings.Contains(msg, "already exists"):
+		return middleware.ConflictError(c, msg)
+	case strings.Contains(msg, "required"), strings.Contains(msg, "cannot be"), strings.Contains(msg, "not a member"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// ListTeams lists the teams of the caller's organization
+// @Summary List teams
+// @Tags Teams
+// @Produce json
+// @Param search query string false "Filter by name"
+// @Param member_id query string false "Only teams this user belongs to"
+// @Success 200 {array} models.Team
+// @Router /api/v1/teams [get]
+// @Security BearerAuth
+func (h *TeamHandler) ListTeams(c *fiber.Ctx) error {
+	var memberID *uuid.UUID
+	if value := c.Query("member_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid member_id format", nil)
+		}
+		memberID = &parsed
+	}
+
+	teams, err := h.service.WithContext(c.UserContext()).List(c.Query("search"), memberID)
+	if err != nil {
+		return teamErrorResponse(c, err, "Failed to list teams")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": teams,
+	})
+}
+
+// GetTeam returns a team with its members
+// @Summary Get team
+// @Tags Teams
+// @Produce json
+// @Param id path string true "Team ID"
+// @Success 200 {object} models.Team
+// @Router /api/v1/teams/{id} [get]
+// @Security BearerAuth
+func (h *TeamHandler) GetTeam(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid team ID", nil)
+	}
+
+	team, err := h.service.WithContext(c.UserContext()).GetByID(id)
+	if err != nil {
+		return teamErrorResponse(c, err, "Failed to get team")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": team,
+	})
+}
+
+// CreateTeam creates a team
+// @Summary Create team
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Param request body services.CreateTeamRequest true "Team"
+// @Success 201 {object} models.Team
+// @Router /api/v1/teams [post]
+// @Security BearerAuth
+func (h *TeamHandler) CreateTeam(c *fiber.Ctx) error {
+	var req services.CreateTeamRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	req.Name = utils.SanitizeString(req.Name)
+	req.Description = utils.SanitizeString(req.Description)
+
+	team, err := h.service.WithContext(c.UserContext()).Create(req)
+	if err != nil {
+		return teamErrorResponse(c, err, "Failed to create team")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Team created successfully",
+		"data":    team,
+	})
+}
+
+// UpdateTeam updates a team's name, description or lead
+// @Summary Update team
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param request body services.UpdateTeamRequest true "Team"
+// @Success 200 {object} models.Team
+// @Router /api/v1/teams/{id} [put]
+// @Security BearerAuth
+func (h *TeamHandler) UpdateTeam(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid team ID", nil)
+	}
+
+	var req services.UpdateTeamRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+	req.Name = sanitizeStringPtr(req.Name)
+	req.Description = sanitizeStringPtr(req.Description)
+
+	team, err := h.service.WithContext(c.UserContext()).Update(id, req)
+	if err != nil {
+		return teamErrorResponse(c, err, "Failed to update team")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Team updated successfully",
+		"data":    team,
+	})
+}
+
+// DeleteTeam deletes a team; assets and vulnerabilities it owned become unowned
+// @Summary Delete team
+// @Tags Teams
+// @Param id path string true "Team ID"
+// @Success 200 {object} map[string]string
+// @Router /api/v1/teams/{id} [delete]
+// @Security BearerAuth
+func (h *TeamHandler) DeleteTeam(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid team ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).Delete(id); err != nil {
+		return teamErrorResponse(c, err, "Failed to delete team")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Team deleted successfully",
+	})
+}
+
+// AddMember adds a user to a team
+// @Summary Add team member
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param request body AddTeamMemberRequest true "Member"
+// @Success 200 {object} models.Team
+// @Router /api/v1/teams/{id}/members [post]
+// @Security BearerAuth
+func (h *TeamHandler) AddMember(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid team ID", nil)
+	}
+
+	var req AddTeamMemberRequest
+	if err := c.BodyParser(&req); err != nil || req.UserID == uuid.Nil {
+		return middleware.ValidationError(c, "user_id is required", nil)
+	}
+
+	team, err := h.service.WithContext(c.UserContext()).AddMember(id, req.UserID)
+	if err != nil {
+		return teamErrorResponse(c, err, "Failed to add team member")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Team member added successfully",
+		"data":    team,
+	})
+}
+
+// RemoveMember removes a user from a team
+// @Summary Remove team member
+// @Tags Teams
+// @Produce json
+// @Param id path string true "Team ID"
+// @Param userId path string true "User ID"
+// @Success 200 {object} models.Team
+// @Router /api/v1/teams/{id}/members/{userId} [delete]
+// @Security BearerAuth
+func (h *TeamHandler) RemoveMember(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid team ID", nil)
+	}
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid user ID", nil)
+	}
+
+	team, err := h.service.WithContext(c.UserContext()).RemoveMember(id, userID)
+	if err != nil {
+		return teamErrorResponse(c, err, "Failed to remove team member")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Team member removed successfully",
+		"data":    team,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/auth"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
@@ -31,6 +32,12 @@ type VerifyTwoFactorRequest struct {
 
 // DisableTwoFactorRequest represents the request to disable 2FA
 type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required,min=8"`
+	Code     string `json:"code" validate:"required"` // TOTP code or backup code
+}
+
+// RegenerateBackupCodesRequest represents the request to replace the 2FA backup codes
+type RegenerateBackupCodesRequest struct {
 	Password string `json:"password" validate:"required,min=8"`
 	Code     string `json:"code" validate:"required,len=6"`
 }
@@ -134,7 +141,7 @@ func (h *TwoFactorHandler) VerifyTwoFactor(c *fiber.Ctx) error {
 
 // DisableTwoFactor disables 2FA for a user
 // @Summary Disable Two-Factor Authentication
-// @Description Disables 2FA after verifying password and TOTP code
+// @Description Disables 2FA after verifying password and TOTP code (or a backup code)
 // @Tags 2FA
 // @Accept json
 // @Produce json
@@ -162,9 +169,9 @@ func (h *TwoFactorHandler) DisableTwoFactor(c *fiber.Ctx) error {
 			"error": "Password is required",
 		})
 	}
-	if req.Code == "" || len(req.Code) != 6 {
+	if len(req.Code) != 6 && !auth.IsBackupCodeFormat(req.Code) {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Valid 6-digit code is required",
+			"error": "Valid 6-digit code or backup code is required",
 		})
 	}
 
@@ -187,3 +194,82 @@ func (h *TwoFactorHandler) DisableTwoFactor(c *fiber.Ctx) error {
 		"message": "Two-factor authentication disabled successfully",
 	})
 }
+
+// RegenerateBackupCodes replaces the user's 2FA backup codes
+// @Summary Regenerate 2FA backup codes
+// @Description Issues ten new single-use backup codes after verifying password and TOTP code; previous codes stop working
+// @Tags 2FA
+// @Accept json
+// @Produce json
+// @Param request body RegenerateBackupCodesRequest true "Password and TOTP code"
+// @Success 200 {object} fiber.Map
+// @Failure 400 {object} fiber.Map
+// @Failure 401 {object} fiber.Map
+// @Router /auth/2fa/backup-codes/regenerate [post]
+func (h *TwoFactorHandler) RegenerateBackupCodes(c *fiber.Ctx) error {
+	// Get user from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	// Parse request body
+	var req RegenerateBackupCodesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Validate fields
+	if req.Password == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Password is required",
+		})
+	}
+	if len(req.Code) != 6 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Valid 6-digit code is required",
+		})
+	}
+
+	codes, err := h.twoFactorService.RegenerateBackupCodes(userID, req.Password, req.Code, c.IP(), c.Get("User-Agent"))
+	if err != nil {
+		utils.Logger.Error().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("Failed to regenerate 2FA backup codes")
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":      "Backup codes regenerated. Store them securely - they will not be shown again!",
+		"backup_codes": codes,
+	})
+}
+
+// GetBackupCodesStatus returns how many unused backup codes the user has left
+// @Summary Get 2FA backup code status
+// @Tags 2FA
+// @Produce json
+// @Success 200 {object} fiber.Map
+// @Failure 401 {object} fiber.Map
+// @Router /auth/2fa/backup-codes [get]
+func (h *TwoFactorHandler) GetBackupCodesStatus(c *fiber.Ctx) error {
+	// Get user from context
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	remaining, err := h.twoFactorService.BackupCodesRemaining(userID)
+	if err != nil {
+		utils.Logger.Error().
+			Err(err).
+			Str("user_id", userID.String()).
+			Msg("Failed to get 2FA backup code status")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get backup code status",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"remaining": remaining,
+	})
+}
@@ -59,13 +59,18 @@ func (h *VulnerabilityAttachmentHandler) UploadAttachment(c *fiber.Ctx) error {
 	}
 
 	// Upload and process attachment
-	attachment, err := h.service.UploadAttachment(
+	attachment, err := h.service.WithContext(c.UserContext()).UploadAttachment(
 		vulnerabilityID,
 		file,
 		attachmentType,
 		description,
 		userID,
 	)
+	if services.IsAttachmentPolicyViolation(err) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to upload attachment: %v", err),
@@ -88,7 +93,7 @@ func (h *VulnerabilityAttachmentHandler) GetAttachment(c *fiber.Ctx) error {
 		})
 	}
 
-	attachment, err := h.service.GetAttachment(attachmentID)
+	attachment, err := h.service.WithContext(c.UserContext()).GetAttachment(attachmentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Attachment not found",
@@ -113,7 +118,7 @@ func (h *VulnerabilityAttachmentHandler) GetAttachmentFile(c *fiber.Ctx) error {
 	// Check if thumbnail is requested
 	thumbnail := c.Query("thumbnail") == "true"
 
-	attachment, err := h.service.GetAttachment(attachmentID)
+	attachment, err := h.service.WithContext(c.UserContext()).GetAttachment(attachmentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Attachment not found",
@@ -121,7 +126,7 @@ func (h *VulnerabilityAttachmentHandler) GetAttachmentFile(c *fiber.Ctx) error {
 	}
 
 	// Get file data
-	fileData, err := h.service.GetAttachmentFile(attachment, thumbnail)
+	fileData, err := h.service.WithContext(c.UserContext()).GetAttachmentFile(attachment, thumbnail)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to read attachment file",
@@ -145,7 +150,7 @@ func (h *VulnerabilityAttachmentHandler) DownloadAttachmentFile(c *fiber.Ctx) er
 		})
 	}
 
-	attachment, err := h.service.GetAttachment(attachmentID)
+	attachment, err := h.service.WithContext(c.UserContext()).GetAttachment(attachmentID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Attachment not found",
@@ -153,7 +158,7 @@ func (h *VulnerabilityAttachmentHandler) DownloadAttachmentFile(c *fiber.Ctx) er
 	}
 
 	// Get file data (always full file, never thumbnail)
-	fileData, err := h.service.GetAttachmentFile(attachment, false)
+	fileData, err := h.service.WithContext(c.UserContext()).GetAttachmentFile(attachment, false)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to read attachment file",
@@ -167,6 +172,27 @@ func (h *VulnerabilityAttachmentHandler) DownloadAttachmentFile(c *fiber.Ctx) er
 	return c.Send(fileData)
 }
 
+// GetAttachmentDownloadURL returns a time-limited download URL for the attachment file
+// GET /api/vulnerability-attachments/:id/download-url
+func (h *VulnerabilityAttachmentHandler) GetAttachmentDownloadURL(c *fiber.Ctx) error {
+	attachmentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid attachment ID",
+		})
+	}
+
+	attachment, err := h.service.WithContext(c.UserContext()).GetAttachment(attachmentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Attachment not found",
+		})
+	}
+
+	url, expiresAt, err := h.service.WithContext(c.UserContext()).GetDownloadURL(attachment)
+	return downloadURLResponse(c, url, expiresAt, err)
+}
+
 // ListVulnerabilityAttachments lists all attachments for a vulnerability
 // GET /api/vulnerabilities/:id/attachments
 func (h *VulnerabilityAttachmentHandler) ListVulnerabilityAttachments(c *fiber.Ctx) error {
@@ -177,7 +203,7 @@ func (h *VulnerabilityAttachmentHandler) ListVulnerabilityAttachments(c *fiber.C
 		})
 	}
 
-	attachments, err := h.service.GetVulnerabilityAttachments(vulnerabilityID)
+	attachments, err := h.service.WithContext(c.UserContext()).GetVulnerabilityAttachments(vulnerabilityID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to list attachments",
@@ -199,7 +225,7 @@ func (h *VulnerabilityAttachmentHandler) DeleteAttachment(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.service.DeleteAttachment(attachmentID); err != nil {
+	if err := h.service.WithContext(c.UserContext()).DeleteAttachment(attachmentID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete attachment",
 		})
@@ -226,7 +252,7 @@ func (h *VulnerabilityAttachmentHandler) GetAttachmentStats(c *fiber.Ctx) error
 		vulnerabilityID = &id
 	}
 
-	stats, err := h.service.GetAttachmentStats(vulnerabilityID)
+	stats, err := h.service.WithContext(c.UserContext()).GetAttachmentStats(vulnerabilityID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get attachment statistics",
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// VulnerabilityCommentHandler handles vulnerability comments and activity timeline requests
+type VulnerabilityCommentHandler struct {
+	service *services.VulnerabilityCommentService
+}
+
+// NewVulnerabilityCommentHandler creates a new vulnerability comment handler
+func NewVulnerabilityCommentHandler() *VulnerabilityCommentHandler {
+	return &VulnerabilityCommentHandler{
+		service: services.NewVulnerabilityCommentService(database.GetDB()),
+	}
+}
+
+// CommentRequest represents a create or update comment request
+type CommentRequest struct {
+	Body string `json:"body"`
+}
+
+// commentErrorResponse maps comment service errors to HTTP responses
+func commentErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		return middleware.NotFoundError(c, strings.TrimSuffix(err.Error(), " not found"))
+	case strings.Contains(err.Error(), "only the author"):
+		return middleware.ForbiddenError(c, err.Error())
+	case strings.Contains(err.Error(), "is required"):
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// ListComments lists comments on a vulnerability
+// GET /api/v1/vulnerabilities/:id/comments
+func (h *VulnerabilityCommentHandler) ListComments(c *fiber.Ctx) error {
+	vulnerabilityID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	comments, err := h.service.WithContext(c.UserContext()).ListComments(vulnerabilityID)
+	if err != nil {
+		return commentErrorResponse(c, err, "Failed to list comments")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": comments,
+	})
+}
+
+// CreateComment adds a comment to a vulnerability
+// POST /api/v1/vulnerabilities/:id/comments
+func (h *VulnerabilityCommentHandler) CreateComment(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	vulnerabilityID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	var req CommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	comment, err := h.service.WithContext(c.UserContext()).CreateComment(vulnerabilityID, userID, utils.SanitizeString(req.Body))
+	if err != nil {
+		return commentErrorResponse(c, err, "Failed to create comment")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Comment added successfully",
+		"data":    comment,
+	})
+}
+
+// UpdateComment edits a comment on a vulnerability
+// PUT /api/v1/vulnerabilities/:id/comments/:comment_id
+func (h *VulnerabilityCommentHandler) UpdateComment(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	vulnerabilityID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	commentID, err := uuid.Parse(c.Params("comment_id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid comment ID", nil)
+	}
+
+	var req CommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	comment, err := h.service.WithContext(c.UserContext()).UpdateComment(vulnerabilityID, commentID, userID, utils.SanitizeString(req.Body))
+	if err != nil {
+		return commentErrorResponse(c, err, "Failed to update comment")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Comment updated successfully",
+		"data":    comment,
+	})
+}
+
+// DeleteComment deletes a comment on a vulnerability
+// DELETE /api/v1/vulnerabilities/:id/comments/:comment_id
+func (h *VulnerabilityCommentHandler) DeleteComment(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	vulnerabilityID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	commentID, err := uuid.Parse(c.Params("comment_id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid comment ID", nil)
+	}
+
+	if err := h.service.WithContext(c.UserContext()).DeleteComment(vulnerabilityID, commentID, userID); err != nil {
+		return commentErrorResponse(c, err, "Failed to delete comment")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Comment deleted successfully",
+	})
+}
+
+// GetActivity returns the combined activity timeline for a vulnerability
+// GET /api/v1/vulnerabilities/:id/activity
+func (h *VulnerabilityCommentHandler) GetActivity(c *fiber.Ctx) error {
+	vulnerabilityID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	activity, err := h.service.WithContext(c.UserContext()).GetActivity(vulnerabilityID)
+	if err != nil {
+		return commentErrorResponse(c, err, "Failed to get activity timeline")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": activity,
+	})
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -8,6 +10,7 @@ import (
 	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
 	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/taxonomy"
 )
 
 type VulnerabilityFindingHandler struct {
@@ -36,7 +39,7 @@ func (h *VulnerabilityFindingHandler) ListFindingsByVulnerability(c *fiber.Ctx)
 		status = &s
 	}
 
-	findings, err := h.service.ListFindingsByVulnerability(vulnID, status)
+	findings, err := h.service.WithContext(c.UserContext()).ListFindingsByVulnerability(vulnID, status)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to list findings",
@@ -44,7 +47,7 @@ func (h *VulnerabilityFindingHandler) ListFindingsByVulnerability(c *fiber.Ctx)
 	}
 
 	// Get summary
-	summary, err := h.service.GetFindingSummaryByVulnerability(vulnID)
+	summary, err := h.service.WithContext(c.UserContext()).GetFindingSummaryByVulnerability(vulnID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get finding summary",
@@ -73,7 +76,7 @@ func (h *VulnerabilityFindingHandler) ListFindingsBySystem(c *fiber.Ctx) error {
 		status = &s
 	}
 
-	findings, err := h.service.ListFindingsBySystem(systemID, status)
+	findings, err := h.service.WithContext(c.UserContext()).ListFindingsBySystem(systemID, status)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to list findings",
@@ -94,15 +97,29 @@ func (h *VulnerabilityFindingHandler) GetFinding(c *fiber.Ctx) error {
 		})
 	}
 
-	finding, err := h.service.GetFinding(findingID)
+	fieldset, err := parseFieldset(c, services.FindingFields)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	finding, err := h.service.WithContext(c.UserContext()).GetFindingWithFieldset(findingID, fieldset)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Finding not found",
 		})
 	}
 
+	data, err := fieldset.Project(finding)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get finding",
+		})
+	}
+
 	return c.JSON(fiber.Map{
-		"data": finding,
+		"data": data,
 	})
 }
 
@@ -123,7 +140,7 @@ func (h *VulnerabilityFindingHandler) MarkFindingFixed(c *fiber.Ctx) error {
 		// Notes are optional
 	}
 
-	if err := h.service.MarkFindingFixed(findingID, userID, req.Notes); err != nil {
+	if err := h.service.WithContext(c.UserContext()).MarkFindingFixed(findingID, userID, req.Notes); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to mark finding as fixed",
 		})
@@ -151,7 +168,7 @@ func (h *VulnerabilityFindingHandler) MarkFindingVerified(c *fiber.Ctx) error {
 		// Notes are optional
 	}
 
-	if err := h.service.MarkFindingVerified(findingID, userID, req.Notes); err != nil {
+	if err := h.service.WithContext(c.UserContext()).MarkFindingVerified(findingID, userID, req.Notes); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to mark finding as verified",
 		})
@@ -188,7 +205,7 @@ func (h *VulnerabilityFindingHandler) AcceptRisk(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.service.AcceptRisk(findingID, userID, req.Reason, req.ExpiresAt); err != nil {
+	if err := h.service.WithContext(c.UserContext()).AcceptRisk(findingID, userID, req.Reason, req.ExpiresAt); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to accept risk for finding",
 		})
@@ -214,8 +231,57 @@ func (h *VulnerabilityFindingHandler) ListFindings(c *fiber.Ctx) error {
 	if pluginID := c.Query("plugin_id"); pluginID != "" {
 		filters["plugin_id"] = pluginID
 	}
+	if groupID := c.Query("asset_group_id"); groupID != "" {
+		parsed, err := uuid.Parse(groupID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid asset_group_id format",
+			})
+		}
+		filters["asset_group_id"] = parsed
+	}
+	// Comma-separated OWASP Top 10 categories (owasp=A03:2021) and ATT&CK techniques (attack=T1190)
+	for _, framework := range taxonomy.Frameworks() {
+		if raw := c.Query(framework); raw != "" {
+			codes, err := taxonomy.ParseCodes(framework, strings.Split(raw, ","))
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": err.Error(),
+				})
+			}
+			filters[framework] = codes
+		}
+	}
+	if raw := c.Query("has_exploit"); raw != "" {
+		hasExploit, err := strconv.ParseBool(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid has_exploit format, use true or false",
+			})
+		}
+		filters["has_exploit"] = hasExploit
+	}
+	// Creation date bounds (YYYY-MM-DD, end exclusive) limit the scan to the matching monthly partitions
+	for _, param := range []string{"created_after", "created_before"} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid " + param + " format, use YYYY-MM-DD",
+				})
+			}
+			filters[param] = parsed
+		}
+	}
 
-	findings, total, err := h.service.ListFindings(filters, page, limit)
+	fieldset, err := parseFieldset(c, services.FindingFields)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	findings, total, err := h.service.WithContext(c.UserContext()).ListFindingsWithFieldset(filters, page, limit, fieldset)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to list findings",
@@ -230,19 +296,27 @@ func (h *VulnerabilityFindingHandler) ListFindings(c *fiber.Ctx) error {
 
 	enhancedFindings := make([]FindingWithAttachments, len(findings))
 	for i, finding := range findings {
+		enhancedFindings[i] = FindingWithAttachments{VulnerabilityFinding: finding}
+		if !fieldset.Wants("attachments_count") {
+			continue
+		}
+
 		var count int64
 		database.GetDB().Model(&models.FindingAttachment{}).
 			Where("finding_id = ?", finding.ID).
 			Count(&count)
+		enhancedFindings[i].AttachmentsCount = int(count)
+	}
 
-		enhancedFindings[i] = FindingWithAttachments{
-			VulnerabilityFinding: finding,
-			AttachmentsCount:     int(count),
-		}
+	data, err := fieldset.Project(enhancedFindings)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list findings",
+		})
 	}
 
 	return c.JSON(fiber.Map{
-		"data": enhancedFindings,
+		"data": data,
 		"meta": fiber.Map{
 			"page":  page,
 			"limit": limit,
@@ -264,7 +338,7 @@ func (h *VulnerabilityFindingHandler) GetFindingStatistics(c *fiber.Ctx) error {
 		filters["plugin_id"] = pluginID
 	}
 
-	stats, err := h.service.GetFindingStatistics(filters)
+	stats, err := h.service.WithContext(c.UserContext()).GetFindingStatistics(filters)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get finding statistics",
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -9,6 +12,9 @@ import (
 	"github.com/cyops/cyops-backend/internal/middleware"
 	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/cwe"
+	"github.com/cyops/cyops-backend/pkg/policy"
+	"github.com/cyops/cyops-backend/pkg/taxonomy"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
@@ -43,16 +49,31 @@ type CreateVulnerabilityRequest struct {
 	CVSSScore                 *float64 `json:"cvss_score,omitempty"`
 	CVSSVector                string   `json:"cvss_vector,omitempty"`
 	CVEID                     string   `json:"cve_id,omitempty"`
+	CWEIDs                    []string `json:"cwe_ids,omitempty"` // e.g. CWE-79
 	Source                    string   `json:"source,omitempty"`
 	DiscoveryDate             string   `json:"discovery_date"` // ISO date format
 	ImpactAssessment          string   `json:"impact_assessment,omitempty"`
 	StepsToReproduce          string   `json:"steps_to_reproduce,omitempty"`
 	MitigationRecommendations string   `json:"mitigation_recommendations,omitempty"`
+	PatchAvailable            bool     `json:"patch_available,omitempty"`
+	AdvisoryURL               string   `json:"advisory_url,omitempty"` // Vendor advisory or KB article
+	FixedVersion              string   `json:"fixed_version,omitempty"`
+	Workaround                string   `json:"workaround,omitempty"`
 	AssignedToID              *string  `json:"assigned_to_id,omitempty"`
+	OwnerTeamID               *string  `json:"owner_team_id,omitempty"`
 	AffectedSystemIDs         []string `json:"affected_system_ids,omitempty"`
 }
 
 // CreateVulnerability creates a new vulnerability
+// @Summary Create vulnerability
+// @Tags Vulnerabilities
+// @Accept json
+// @Produce json
+// @Param request body CreateVulnerabilityRequest true "Vulnerability"
+// @Success 201 {object} models.Vulnerability
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/vulnerabilities [post]
+// @Security BearerAuth
 func (h *VulnerabilityHandler) CreateVulnerability(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uuid.UUID)
 
@@ -64,9 +85,13 @@ func (h *VulnerabilityHandler) CreateVulnerability(c *fiber.Ctx) error {
 	// Validate CVE ID format if provided
 	if req.CVEID != "" {
 		if err := utils.ValidateCVEID(req.CVEID); err != nil {
-			return middleware.ValidationError(c, err.Error(), nil)
+			return middleware.FieldValidationError(c, utils.FieldErr("cve_id", err))
 		}
 	}
+	cweIDs, err := cwe.ParseIDs(req.CWEIDs)
+	if err != nil {
+		return middleware.FieldValidationError(c, utils.FieldErr("cwe_ids", err))
+	}
 
 	// Parse discovery date
 	discoveryDate, err := time.Parse("2006-01-02", req.DiscoveryDate)
@@ -84,6 +109,16 @@ func (h *VulnerabilityHandler) CreateVulnerability(c *fiber.Ctx) error {
 		assignedToID = &parsed
 	}
 
+	// Parse owner team ID if provided
+	var ownerTeamID *uuid.UUID
+	if req.OwnerTeamID != nil && *req.OwnerTeamID != "" {
+		parsed, err := uuid.Parse(*req.OwnerTeamID)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid owner_team_id format", nil)
+		}
+		ownerTeamID = &parsed
+	}
+
 	// Parse affected system IDs
 	var affectedSystemIDs []uuid.UUID
 	for _, idStr := range req.AffectedSystemIDs {
@@ -108,23 +143,35 @@ func (h *VulnerabilityHandler) CreateVulnerability(c *fiber.Ctx) error {
 		CVSSScore:                 req.CVSSScore,
 		CVSSVector:                req.CVSSVector,
 		CVEID:                     req.CVEID,
+		CWEIDs:                    cweIDs,
 		Source:                    source,
 		DiscoveryDate:             discoveryDate,
 		ImpactAssessment:          utils.SanitizeString(req.ImpactAssessment),
 		StepsToReproduce:          utils.SanitizeString(req.StepsToReproduce),
 		MitigationRecommendations: utils.SanitizeString(req.MitigationRecommendations),
+		PatchAvailable:            req.PatchAvailable,
+		AdvisoryURL:               strings.TrimSpace(req.AdvisoryURL),
+		FixedVersion:              strings.TrimSpace(req.FixedVersion),
+		Workaround:                utils.SanitizeString(req.Workaround),
 		AssignedToID:              assignedToID,
+		OwnerTeamID:               ownerTeamID,
 		AffectedSystemIDs:         affectedSystemIDs,
 	}
 
 	// Validate request
 	if err := h.validationService.ValidateCreateRequest(serviceReq); err != nil {
-		return middleware.ValidationError(c, err.Error(), nil)
+		return middleware.FieldValidationError(c, err)
 	}
 
 	// Create vulnerability
-	vulnerability, err := h.vulnerabilityService.CreateVulnerability(serviceReq, userID)
+	vulnerability, err := h.vulnerabilityService.WithContext(c.UserContext()).CreateVulnerability(serviceReq, userID)
 	if err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
+		if err.Error() == "team not found" {
+			return middleware.ValidationError(c, "Owner team not found", nil)
+		}
 		utils.Logger.Error().Err(err).Msg("Failed to create vulnerability")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to create vulnerability",
@@ -139,20 +186,41 @@ func (h *VulnerabilityHandler) CreateVulnerability(c *fiber.Ctx) error {
 
 // ListVulnerabilitiesQuery represents query parameters for listing vulnerabilities
 type ListVulnerabilitiesQuery struct {
-	Page       int    `query:"page"`
-	Limit      int    `query:"limit"`
-	Severity   string `query:"severity"` // Comma-separated
-	Status     string `query:"status"`   // Comma-separated
-	Search     string `query:"search"`
-	AssignedTo string `query:"assignedTo"`
-	CreatedBy  string `query:"createdBy"`
-	AssetID    string `query:"asset_id"` // Filter by affected system/asset
-	SortBy     string `query:"sortBy"`
-	SortOrder  string `query:"sortOrder"`
+	Page         int    `query:"page"`
+	Limit        int    `query:"limit"`
+	Severity     string `query:"severity"` // Comma-separated
+	Status       string `query:"status"`   // Comma-separated
+	Search       string `query:"search"`
+	AssignedTo   string `query:"assignedTo"`
+	OwnerTeamID  string `query:"owner_team_id"`
+	CreatedBy    string `query:"createdBy"`
+	AssetID      string `query:"asset_id"`       // Filter by affected system/asset
+	AssetGroupID string `query:"asset_group_id"` // Filter by asset group membership
+	Tags         string `query:"tags"`           // Comma-separated; vulnerabilities must carry every tag
+	OWASP        string `query:"owasp"`          // Comma-separated OWASP Top 10 categories, e.g. A03:2021
+	ATTACK       string `query:"attack"`         // Comma-separated ATT&CK techniques, e.g. T1190
+	HasExploit   string `query:"has_exploit"`    // true or false: a public exploit is known
+	SortBy       string `query:"sortBy"`
+	SortOrder    string `query:"sortOrder"`
 }
 
 // ListVulnerabilities lists vulnerabilities with pagination and filters
+// @Summary List vulnerabilities
+// @Tags Vulnerabilities
+// @Produce json
+// @Param view_id query string false "Saved view whose filters to apply"
+// @Param fields query string false "Comma-separated fields to return"
+// @Param include query string false "Comma-separated relations to embed"
+// @Success 200 {array} models.Vulnerability
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/vulnerabilities [get]
+// @Security BearerAuth
 func (h *VulnerabilityHandler) ListVulnerabilities(c *fiber.Ctx) error {
+	// Apply a saved view's filters (?view_id=) before parsing
+	if err := applySavedView(c, models.SavedViewResourceVulnerability); err != nil {
+		return savedViewErrorResponse(c, err, "Failed to apply saved view")
+	}
+
 	var query ListVulnerabilitiesQuery
 	if err := c.QueryParser(&query); err != nil {
 		return middleware.ValidationError(c, "Invalid query parameters", nil)
@@ -184,6 +252,16 @@ func (h *VulnerabilityHandler) ListVulnerabilities(c *fiber.Ctx) error {
 		assignedTo = &parsed
 	}
 
+	// Parse owner team filter
+	var ownerTeamID *uuid.UUID
+	if query.OwnerTeamID != "" {
+		parsed, err := uuid.Parse(query.OwnerTeamID)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid owner_team_id format", nil)
+		}
+		ownerTeamID = &parsed
+	}
+
 	// Parse created by filter
 	var createdBy *uuid.UUID
 	if query.CreatedBy != "" {
@@ -204,22 +282,78 @@ func (h *VulnerabilityHandler) ListVulnerabilities(c *fiber.Ctx) error {
 		assetID = &parsed
 	}
 
+	// Parse asset group filter
+	var assetGroupID *uuid.UUID
+	if query.AssetGroupID != "" {
+		parsed, err := uuid.Parse(query.AssetGroupID)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid asset_group_id format", nil)
+		}
+		assetGroupID = &parsed
+	}
+
+	// Parse tags filter
+	var tags []string
+	if query.Tags != "" {
+		parsed, err := services.NormalizeTags(strings.Split(query.Tags, ","))
+		if err != nil {
+			return middleware.ValidationError(c, err.Error(), nil)
+		}
+		tags = parsed
+	}
+
+	// Parse OWASP Top 10 and ATT&CK filters
+	classifications := map[string][]string{}
+	for framework, raw := range map[string]string{taxonomy.FrameworkOWASP: query.OWASP, taxonomy.FrameworkATTACK: query.ATTACK} {
+		if raw == "" {
+			continue
+		}
+		codes, err := taxonomy.ParseCodes(framework, strings.Split(raw, ","))
+		if err != nil {
+			return middleware.ValidationError(c, err.Error(), nil)
+		}
+		classifications[framework] = codes
+	}
+
+	// Parse known public exploit filter
+	var hasExploit *bool
+	if query.HasExploit != "" {
+		parsed, err := strconv.ParseBool(query.HasExploit)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid has_exploit format, use true or false", nil)
+		}
+		hasExploit = &parsed
+	}
+
+	// Parse sparse fieldset (?fields=, ?include=)
+	fieldset, err := parseFieldset(c, services.VulnerabilityFields)
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
 	// Build service request
 	serviceReq := services.ListVulnerabilitiesRequest{
-		Page:       query.Page,
-		Limit:      query.Limit,
-		Severity:   severities,
-		Status:     statuses,
-		Search:     query.Search,
-		AssignedTo: assignedTo,
-		CreatedBy:  createdBy,
-		AssetID:    assetID,
-		SortBy:     query.SortBy,
-		SortOrder:  query.SortOrder,
+		Page:         query.Page,
+		Limit:        query.Limit,
+		Severity:     severities,
+		Status:       statuses,
+		Search:       query.Search,
+		AssignedTo:   assignedTo,
+		OwnerTeamID:  ownerTeamID,
+		CreatedBy:    createdBy,
+		AssetID:      assetID,
+		AssetGroupID: assetGroupID,
+		Tags:         tags,
+		OWASP:        classifications[taxonomy.FrameworkOWASP],
+		ATTACK:       classifications[taxonomy.FrameworkATTACK],
+		HasExploit:   hasExploit,
+		SortBy:       query.SortBy,
+		SortOrder:    query.SortOrder,
+		Fieldset:     fieldset,
 	}
 
 	// Get vulnerabilities
-	vulnerabilities, total, err := h.vulnerabilityService.ListVulnerabilities(serviceReq)
+	vulnerabilities, total, err := h.vulnerabilityService.WithContext(c.UserContext()).ListVulnerabilities(serviceReq)
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to list vulnerabilities")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -238,8 +372,16 @@ func (h *VulnerabilityHandler) ListVulnerabilities(c *fiber.Ctx) error {
 	}
 	totalPages := int((total + int64(limit) - 1) / int64(limit))
 
+	data, err := fieldset.Project(vulnerabilities)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to project vulnerabilities")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to list vulnerabilities",
+		})
+	}
+
 	return c.JSON(fiber.Map{
-		"data": vulnerabilities,
+		"data": data,
 		"meta": fiber.Map{
 			"page":        page,
 			"limit":       limit,
@@ -250,6 +392,16 @@ func (h *VulnerabilityHandler) ListVulnerabilities(c *fiber.Ctx) error {
 }
 
 // GetVulnerability retrieves a vulnerability by ID
+// @Summary Get vulnerability
+// @Tags Vulnerabilities
+// @Produce json
+// @Param id path string true "Vulnerability ID"
+// @Param fields query string false "Comma-separated fields to return"
+// @Param include query string false "Comma-separated relations to embed"
+// @Success 200 {object} models.Vulnerability
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/vulnerabilities/{id} [get]
+// @Security BearerAuth
 func (h *VulnerabilityHandler) GetVulnerability(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
@@ -257,7 +409,12 @@ func (h *VulnerabilityHandler) GetVulnerability(c *fiber.Ctx) error {
 		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
 	}
 
-	vulnerability, err := h.vulnerabilityService.GetVulnerabilityByID(id)
+	fieldset, err := parseFieldset(c, services.VulnerabilityFields)
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	vulnerability, err := h.vulnerabilityService.WithContext(c.UserContext()).GetVulnerabilityWithFieldset(id, fieldset)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -270,8 +427,21 @@ func (h *VulnerabilityHandler) GetVulnerability(c *fiber.Ctx) error {
 		})
 	}
 
+	// Conditional request (If-None-Match / If-Modified-Since)
+	if middleware.NotModified(c, vulnerability.UpdatedAt) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	data, err := fieldset.Project(vulnerability)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to project vulnerability")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get vulnerability",
+		})
+	}
+
 	return c.JSON(fiber.Map{
-		"data": vulnerability,
+		"data": data,
 	})
 }
 
@@ -283,13 +453,31 @@ type UpdateVulnerabilityRequest struct {
 	CVSSScore                 *float64 `json:"cvss_score,omitempty"`
 	CVSSVector                *string  `json:"cvss_vector,omitempty"`
 	CVEID                     *string  `json:"cve_id,omitempty"`
+	CWEIDs                    []string `json:"cwe_ids,omitempty"` // Replaces the weakness types; [] clears them
 	RemediationNotes          *string  `json:"remediation_notes,omitempty"`
 	ImpactAssessment          *string  `json:"impact_assessment,omitempty"`
 	StepsToReproduce          *string  `json:"steps_to_reproduce,omitempty"`
 	MitigationRecommendations *string  `json:"mitigation_recommendations,omitempty"`
+	PatchAvailable            *bool    `json:"patch_available,omitempty"`
+	AdvisoryURL               *string  `json:"advisory_url,omitempty"`
+	FixedVersion              *string  `json:"fixed_version,omitempty"`
+	Workaround                *string  `json:"workaround,omitempty"`
 }
 
 // UpdateVulnerability updates a vulnerability
+// @Summary Update vulnerability
+// @Tags Vulnerabilities
+// @Accept json
+// @Produce json
+// @Param id path string true "Vulnerability ID"
+// @Param If-Match header string false "ETag of the version being updated"
+// @Param request body UpdateVulnerabilityRequest true "Changes"
+// @Success 200 {object} models.Vulnerability
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Router /api/v1/vulnerabilities/{id} [put]
+// @Security BearerAuth
 func (h *VulnerabilityHandler) UpdateVulnerability(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
@@ -305,9 +493,20 @@ func (h *VulnerabilityHandler) UpdateVulnerability(c *fiber.Ctx) error {
 	// Validate CVE ID format if provided
 	if req.CVEID != nil && *req.CVEID != "" {
 		if err := utils.ValidateCVEID(*req.CVEID); err != nil {
-			return middleware.ValidationError(c, err.Error(), nil)
+			return middleware.FieldValidationError(c, utils.FieldErr("cve_id", err))
 		}
 	}
+	var cweIDs *[]string
+	if req.CWEIDs != nil {
+		parsed, err := cwe.ParseIDs(req.CWEIDs)
+		if err != nil {
+			return middleware.FieldValidationError(c, utils.FieldErr("cwe_ids", err))
+		}
+		if parsed == nil {
+			parsed = []string{}
+		}
+		cweIDs = &parsed
+	}
 
 	// Convert to service request with input sanitization
 	serviceReq := services.UpdateVulnerabilityRequest{
@@ -316,10 +515,16 @@ func (h *VulnerabilityHandler) UpdateVulnerability(c *fiber.Ctx) error {
 		CVSSScore:                 req.CVSSScore,
 		CVSSVector:                req.CVSSVector,
 		CVEID:                     req.CVEID,
+		CWEIDs:                    cweIDs,
 		RemediationNotes:          sanitizeStringPtr(req.RemediationNotes),
 		ImpactAssessment:          sanitizeStringPtr(req.ImpactAssessment),
 		StepsToReproduce:          sanitizeStringPtr(req.StepsToReproduce),
 		MitigationRecommendations: sanitizeStringPtr(req.MitigationRecommendations),
+		PatchAvailable:            req.PatchAvailable,
+		AdvisoryURL:               req.AdvisoryURL,
+		FixedVersion:              req.FixedVersion,
+		Workaround:                sanitizeStringPtr(req.Workaround),
+		IfMatch:                   c.Get(fiber.HeaderIfMatch),
 	}
 
 	// Convert severity if provided
@@ -330,12 +535,18 @@ func (h *VulnerabilityHandler) UpdateVulnerability(c *fiber.Ctx) error {
 
 	// Validate request
 	if err := h.validationService.ValidateUpdateRequest(serviceReq); err != nil {
-		return middleware.ValidationError(c, err.Error(), nil)
+		return middleware.FieldValidationError(c, err)
 	}
 
 	// Update vulnerability
-	vulnerability, err := h.vulnerabilityService.UpdateVulnerability(id, serviceReq)
+	vulnerability, err := h.vulnerabilityService.WithContext(c.UserContext()).UpdateVulnerability(id, serviceReq)
 	if err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
+		if errors.Is(err, services.ErrPreconditionFailed) {
+			return middleware.PreconditionFailedError(c, "Vulnerability was modified by another request")
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Vulnerability not found",
@@ -347,6 +558,7 @@ func (h *VulnerabilityHandler) UpdateVulnerability(c *fiber.Ctx) error {
 		})
 	}
 
+	c.Set(fiber.HeaderETag, utils.ResourceETag(vulnerability.UpdatedAt))
 	return c.JSON(fiber.Map{
 		"message": "Vulnerability updated successfully",
 		"data":    vulnerability,
@@ -382,7 +594,7 @@ func (h *VulnerabilityHandler) UpdateVulnerabilityStatus(c *fiber.Ctx) error {
 	}
 
 	// Get current vulnerability to check status transition
-	currentVuln, err := h.vulnerabilityService.GetVulnerabilityByID(id)
+	currentVuln, err := h.vulnerabilityService.WithContext(c.UserContext()).GetVulnerabilityByID(id)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
@@ -405,8 +617,17 @@ func (h *VulnerabilityHandler) UpdateVulnerabilityStatus(c *fiber.Ctx) error {
 	}
 
 	// Update status
-	vulnerability, err := h.vulnerabilityService.UpdateVulnerabilityStatus(id, newStatus, notes, userID)
+	vulnerability, err := h.vulnerabilityService.WithContext(c.UserContext()).UpdateVulnerabilityStatus(id, newStatus, notes, userID)
 	if err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
+		if errors.Is(err, services.ErrCloseApprovalRequired) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+				"hint":  fmt.Sprintf("Request approval with POST /api/v1/vulnerabilities/%s/close-approvals", id),
+			})
+		}
 		utils.Logger.Error().Err(err).Msg("Failed to update vulnerability status")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -426,6 +647,8 @@ type AssignVulnerabilityRequest struct {
 
 // AssignVulnerability assigns a vulnerability to a user
 func (h *VulnerabilityHandler) AssignVulnerability(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
 	if err != nil {
@@ -448,8 +671,11 @@ func (h *VulnerabilityHandler) AssignVulnerability(c *fiber.Ctx) error {
 	}
 
 	// Assign vulnerability
-	vulnerability, err := h.vulnerabilityService.AssignVulnerability(id, assignedToID)
+	vulnerability, err := h.vulnerabilityService.WithContext(c.UserContext()).AssignVulnerability(id, assignedToID, userID)
 	if err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Vulnerability not found",
@@ -467,7 +693,69 @@ func (h *VulnerabilityHandler) AssignVulnerability(c *fiber.Ctx) error {
 	})
 }
 
+// AssignVulnerabilityTeamRequest represents a team ownership request
+type AssignVulnerabilityTeamRequest struct {
+	OwnerTeamID *string `json:"owner_team_id"`
+}
+
+// AssignVulnerabilityTeam sets or clears the team that owns a vulnerability
+func (h *VulnerabilityHandler) AssignVulnerabilityTeam(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	idParam := c.Params("id")
+	id, err := uuid.Parse(idParam)
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	var req AssignVulnerabilityTeamRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	// Parse owner team ID (empty or null clears the owner team)
+	var ownerTeamID *uuid.UUID
+	if req.OwnerTeamID != nil && *req.OwnerTeamID != "" {
+		parsed, err := uuid.Parse(*req.OwnerTeamID)
+		if err != nil {
+			return middleware.ValidationError(c, "Invalid owner_team_id format", nil)
+		}
+		ownerTeamID = &parsed
+	}
+
+	vulnerability, err := h.vulnerabilityService.WithContext(c.UserContext()).AssignVulnerabilityTeam(id, ownerTeamID, userID)
+	if err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
+		switch err.Error() {
+		case "vulnerability not found":
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Vulnerability not found",
+			})
+		case "team not found":
+			return middleware.ValidationError(c, "Owner team not found", nil)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to assign vulnerability team",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Vulnerability team assigned successfully",
+		"data":    vulnerability,
+	})
+}
+
 // DeleteVulnerability soft deletes a vulnerability
+// @Summary Delete vulnerability
+// @Tags Vulnerabilities
+// @Produce json
+// @Param id path string true "Vulnerability ID"
+// @Success 200 {object} fiber.Map
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/vulnerabilities/{id} [delete]
+// @Security BearerAuth
 func (h *VulnerabilityHandler) DeleteVulnerability(c *fiber.Ctx) error {
 	idParam := c.Params("id")
 	id, err := uuid.Parse(idParam)
@@ -475,8 +763,13 @@ func (h *VulnerabilityHandler) DeleteVulnerability(c *fiber.Ctx) error {
 		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
 	}
 
+	userID := c.Locals("user_id").(uuid.UUID)
+
 	// Delete vulnerability
-	if err := h.vulnerabilityService.DeleteVulnerability(id); err != nil {
+	if err := h.vulnerabilityService.WithContext(c.UserContext()).DeleteVulnerability(id, userID); err != nil {
+		if errors.Is(err, policy.ErrDenied) {
+			return middleware.ForbiddenError(c, err.Error())
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Vulnerability not found",
@@ -495,7 +788,7 @@ func (h *VulnerabilityHandler) DeleteVulnerability(c *fiber.Ctx) error {
 
 // GetVulnerabilityStats returns statistics about vulnerabilities
 func (h *VulnerabilityHandler) GetVulnerabilityStats(c *fiber.Ctx) error {
-	stats, err := h.vulnerabilityService.GetVulnerabilityStats()
+	stats, err := h.vulnerabilityService.WithContext(c.UserContext()).GetVulnerabilityStats()
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to get vulnerability stats")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -507,3 +800,219 @@ func (h *VulnerabilityHandler) GetVulnerabilityStats(c *fiber.Ctx) error {
 		"data": stats,
 	})
 }
+
+// VulnerabilityTagsRequest is the payload for tagging a vulnerability
+type VulnerabilityTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// vulnerabilityTagErrorResponse maps vulnerability tagging errors to HTTP responses
+func vulnerabilityTagErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	if errors.Is(err, policy.ErrDenied) {
+		return middleware.ForbiddenError(c, err.Error())
+	}
+	msg := err.Error()
+	switch {
+	case msg == "vulnerability not found":
+		return middleware.NotFoundError(c, "Vulnerability")
+	case msg == "tag not found on vulnerability":
+		return middleware.NotFoundError(c, "Tag")
+	case strings.Contains(msg, "required"), strings.HasPrefix(msg, "invalid"):
+		return middleware.ValidationError(c, msg, nil)
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// AddVulnerabilityTags adds tags to a vulnerability
+func (h *VulnerabilityHandler) AddVulnerabilityTags(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	var req VulnerabilityTagsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	vulnerability, err := h.vulnerabilityService.WithContext(c.UserContext()).AddTags(id, req.Tags)
+	if err != nil {
+		return vulnerabilityTagErrorResponse(c, err, "Failed to tag vulnerability")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Vulnerability tagged successfully",
+		"data":    vulnerability,
+	})
+}
+
+// GetVulnerabilityRemediation returns the remediation guidance of a vulnerability per affected system
+// @Summary Get remediation guidance
+// @Description Returns the patch availability, advisory, fixed version and workaround of a vulnerability, and for each unpatched affected system the versions its scanners detected, the version to upgrade to and the suggested action (upgrade, apply_patch, apply_workaround or review).
+// @Tags Vulnerabilities
+// @Produce json
+// @Param id path string true "Vulnerability ID"
+// @Success 200 {object} services.RemediationGuidance
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/vulnerabilities/{id}/remediation [get]
+// @Security BearerAuth
+func (h *VulnerabilityHandler) GetVulnerabilityRemediation(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	guidance, err := h.vulnerabilityService.WithContext(c.UserContext()).GetRemediationGuidance(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Vulnerability not found",
+			})
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to get remediation guidance")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get remediation guidance",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": guidance,
+	})
+}
+
+// SetVulnerabilityClassifications maps a vulnerability to OWASP Top 10 categories and ATT&CK techniques
+// @Summary Classify vulnerability
+// @Description Replaces the OWASP Top 10 categories and/or ATT&CK techniques of a vulnerability with manual ones, including those inferred from its CWE IDs or scanner plugin family. A framework left out of the body is kept; an empty list clears it.
+// @Tags Vulnerabilities
+// @Accept json
+// @Produce json
+// @Param id path string true "Vulnerability ID"
+// @Param request body services.SetClassificationsRequest true "Classifications"
+// @Success 200 {object} models.Vulnerability
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/vulnerabilities/{id}/classifications [put]
+// @Security BearerAuth
+func (h *VulnerabilityHandler) SetVulnerabilityClassifications(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	var req services.SetClassificationsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	vulnerability, err := h.vulnerabilityService.WithContext(c.UserContext()).SetClassifications(id, req)
+	if err != nil {
+		return vulnerabilityTagErrorResponse(c, err, "Failed to classify vulnerability")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Vulnerability classified successfully",
+		"data":    vulnerability,
+	})
+}
+
+// RemoveVulnerabilityTag removes a tag from a vulnerability
+func (h *VulnerabilityHandler) RemoveVulnerabilityTag(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return middleware.ValidationError(c, "Invalid vulnerability ID", nil)
+	}
+
+	vulnerability, err := h.vulnerabilityService.WithContext(c.UserContext()).RemoveTag(id, c.Params("tag"))
+	if err != nil {
+		return vulnerabilityTagErrorResponse(c, err, "Failed to remove vulnerability tag")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Vulnerability tag removed successfully",
+		"data":    vulnerability,
+	})
+}
+
+// BulkTagVulnerabilities adds and removes tags across many vulnerabilities
+func (h *VulnerabilityHandler) BulkTagVulnerabilities(c *fiber.Ctx) error {
+	var req services.BulkTagVulnerabilitiesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	result, err := h.vulnerabilityService.WithContext(c.UserContext()).BulkTag(req)
+	if err != nil {
+		return vulnerabilityTagErrorResponse(c, err, "Failed to tag vulnerabilities")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Vulnerabilities tagged successfully",
+		"data":    result,
+	})
+}
+
+// BatchGetVulnerabilities returns up to 500 vulnerabilities by ID in request order; IDs that
+// do not exist or are not visible are listed in meta.not_found
+// @Summary Batch get vulnerabilities
+// @Tags Vulnerabilities
+// @Accept json
+// @Produce json
+// @Param request body services.BatchGetVulnerabilitiesRequest true "Vulnerability IDs"
+// @Success 200 {array} models.Vulnerability
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/vulnerabilities/batch-get [post]
+// @Security BearerAuth
+func (h *VulnerabilityHandler) BatchGetVulnerabilities(c *fiber.Ctx) error {
+	var req services.BatchGetVulnerabilitiesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	}
+
+	fieldset, err := parseFieldset(c, services.VulnerabilityFields)
+	if err != nil {
+		return middleware.ValidationError(c, err.Error(), nil)
+	}
+
+	result, err := h.vulnerabilityService.WithContext(c.UserContext()).BatchGetVulnerabilities(req.IDs, fieldset)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), "is required") || strings.HasPrefix(err.Error(), "invalid") {
+			return middleware.ValidationError(c, err.Error(), nil)
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to batch get vulnerabilities")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get vulnerabilities",
+		})
+	}
+
+	data, err := fieldset.Project(result.Vulnerabilities)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to project vulnerabilities")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get vulnerabilities",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": data,
+		"meta": fiber.Map{
+			"found":     len(result.Vulnerabilities),
+			"not_found": result.NotFound,
+		},
+	})
+}
+
+// ListVulnerabilityTags lists the tags in use with their vulnerability counts
+func (h *VulnerabilityHandler) ListVulnerabilityTags(c *fiber.Ctx) error {
+	tags, err := h.vulnerabilityService.WithContext(c.UserContext()).ListTags(c.Query("search"))
+	if err != nil {
+		return vulnerabilityTagErrorResponse(c, err, "Failed to list vulnerability tags")
+	}
+
+	return c.JSON(fiber.Map{
+		"data": tags,
+	})
+}
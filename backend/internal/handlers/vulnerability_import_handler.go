@@ -3,11 +3,15 @@ package handlers
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
 	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/telemetry"
 	"github.com/cyops/cyops-backend/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // VulnerabilityImportHandler handles vulnerability import requests
@@ -49,6 +53,13 @@ func (h *VulnerabilityImportHandler) UploadNessusFile(c *fiber.Ctx) error {
 		})
 	}
 
+	// Reject oversized files before reading them
+	if err := h.importService.CheckNessusFileSize(file.Size); err != nil {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Open uploaded file
 	src, err := file.Open()
 	if err != nil {
@@ -77,7 +88,9 @@ func (h *VulnerabilityImportHandler) UploadNessusFile(c *fiber.Ctx) error {
 	}
 
 	// Parse Nessus file
+	_, parseSpan := telemetry.StartSpan(c.UserContext(), "NessusParserService.ParseNessusFile", attribute.Int("file.size", len(fileData)))
 	vulnerabilities, err := h.parserService.ParseNessusFile(fileData)
+	telemetry.EndSpan(parseSpan, err)
 	if err != nil {
 		utils.Logger.Error().Err(err).Str("filename", file.Filename).Msg("Failed to parse Nessus file")
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -95,7 +108,10 @@ func (h *VulnerabilityImportHandler) UploadNessusFile(c *fiber.Ctx) error {
 	skipDuplicates := c.FormValue("skip_duplicates") == "true"
 
 	// Import vulnerabilities
-	result, err := h.importService.ImportFromNessus(vulnerabilities, userID, skipDuplicates)
+	result, err := h.importService.WithContext(c.UserContext()).ImportFromNessus(vulnerabilities, userID, skipDuplicates, services.ImportSource{Scanner: "nessus"})
+	if isShuttingDown(err) {
+		return middleware.ShuttingDownError(c)
+	}
 	if err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to import vulnerabilities")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -116,6 +132,240 @@ func (h *VulnerabilityImportHandler) UploadNessusFile(c *fiber.Ctx) error {
 	})
 }
 
+// ListImportJobs lists import jobs with their source and findings diff, newest first,
+// optionally only those of one integration (?integration_config_id=) or scan (?scan_id=)
+// GET /api/v1/vulnerabilities/imports
+func (h *VulnerabilityImportHandler) ListImportJobs(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	perPage := c.QueryInt("per_page", 20)
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	var integrationConfigID *uuid.UUID
+	if raw := c.Query("integration_config_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid integration config ID",
+			})
+		}
+		integrationConfigID = &id
+	}
+
+	jobs, total, err := h.importService.WithContext(c.UserContext()).ListImportJobs(integrationConfigID, c.Query("scan_id"), page, perPage)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to list import jobs")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve import jobs",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"imports":     jobs,
+		"total":       total,
+		"page":        page,
+		"per_page":    perPage,
+		"total_pages": (int(total) + perPage - 1) / perPage,
+	})
+}
+
+// GetImportJob returns an import job with its source and findings diff
+// GET /api/v1/vulnerabilities/imports/:id
+func (h *VulnerabilityImportHandler) GetImportJob(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid import job ID",
+		})
+	}
+
+	job, err := h.importService.WithContext(c.UserContext()).GetImportJob(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Import job not found",
+			})
+		}
+		utils.Logger.Error().Err(err).Msg("Failed to get import job")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve import job",
+		})
+	}
+	return c.JSON(job)
+}
+
+// RollbackImportJob reverts a finished import: the records it created are removed and the
+// findings it updated are restored
+// POST /api/v1/import-jobs/:id/rollback
+func (h *VulnerabilityImportHandler) RollbackImportJob(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid import job ID",
+		})
+	}
+
+	result, err := h.importService.WithContext(c.UserContext()).RollbackImport(id, userID)
+	if err != nil {
+		msg := err.Error()
+		switch {
+		case msg == "import job not found":
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Import job not found",
+			})
+		case strings.Contains(msg, "already"), strings.Contains(msg, "cannot be rolled back"):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": msg,
+			})
+		}
+		utils.Logger.Error().Err(err).Str("import_job_id", id.String()).Msg("Failed to roll back import job")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to roll back import job",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Import job rolled back successfully",
+		"result":  result,
+	})
+}
+
+// quarantineErrorResponse maps quarantine resolution errors to HTTP responses
+func quarantineErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	msg := err.Error()
+	switch {
+	case msg == "quarantined host not found":
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Quarantined host not found",
+		})
+	case strings.Contains(msg, "already"):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": msg,
+		})
+	case strings.Contains(msg, "required"), strings.Contains(msg, "invalid"):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": msg,
+		})
+	}
+
+	utils.Logger.Error().Err(err).Msg(fallback)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": fallback,
+	})
+}
+
+// ListQuarantinedHosts lists report hosts held back by import validation, newest first,
+// optionally only those of one import (?import_job_id=) or status (?status=)
+// GET /api/v1/vulnerabilities/imports/quarantine
+func (h *VulnerabilityImportHandler) ListQuarantinedHosts(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	perPage := c.QueryInt("per_page", 20)
+	if perPage < 1 || perPage > 100 {
+		perPage = 20
+	}
+
+	var importJobID *uuid.UUID
+	if raw := c.Query("import_job_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid import job ID",
+			})
+		}
+		importJobID = &id
+	}
+
+	hosts, total, err := h.importService.WithContext(c.UserContext()).ListQuarantinedHosts(importJobID, c.Query("status"), page, perPage)
+	if err != nil {
+		return quarantineErrorResponse(c, err, "Failed to retrieve quarantined hosts")
+	}
+
+	return c.JSON(fiber.Map{
+		"hosts":       hosts,
+		"total":       total,
+		"page":        page,
+		"per_page":    perPage,
+		"total_pages": (int(total) + perPage - 1) / perPage,
+	})
+}
+
+// ReleaseQuarantinedHost imports a quarantined host, optionally with a corrected hostname or
+// IP address
+// POST /api/v1/vulnerabilities/imports/quarantine/:hostId/release
+func (h *VulnerabilityImportHandler) ReleaseQuarantinedHost(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("hostId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid quarantined host ID",
+		})
+	}
+
+	var req services.QuarantineReleaseRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	host, result, err := h.importService.WithContext(c.UserContext()).ReleaseQuarantinedHost(id, req, userID)
+	if err != nil {
+		return quarantineErrorResponse(c, err, "Failed to release quarantined host")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Quarantined host released",
+		"host":    host,
+		"result":  result,
+	})
+}
+
+// DismissQuarantinedHost discards a quarantined host without importing it
+// POST /api/v1/vulnerabilities/imports/quarantine/:hostId/dismiss
+func (h *VulnerabilityImportHandler) DismissQuarantinedHost(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(uuid.UUID)
+
+	id, err := uuid.Parse(c.Params("hostId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid quarantined host ID",
+		})
+	}
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid request body",
+			})
+		}
+	}
+
+	host, err := h.importService.WithContext(c.UserContext()).DismissQuarantinedHost(id, req.Note, userID)
+	if err != nil {
+		return quarantineErrorResponse(c, err, "Failed to dismiss quarantined host")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Quarantined host dismissed",
+		"host":    host,
+	})
+}
+
 // PreviewNessusFile previews what will be imported without actually importing
 func (h *VulnerabilityImportHandler) PreviewNessusFile(c *fiber.Ctx) error {
 	// Parse multipart form
@@ -133,6 +383,13 @@ func (h *VulnerabilityImportHandler) PreviewNessusFile(c *fiber.Ctx) error {
 		})
 	}
 
+	// Reject oversized files before reading them
+	if err := h.importService.CheckNessusFileSize(file.Size); err != nil {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Open and read file
 	src, err := file.Open()
 	if err != nil {
@@ -157,7 +414,9 @@ func (h *VulnerabilityImportHandler) PreviewNessusFile(c *fiber.Ctx) error {
 	}
 
 	// Parse Nessus file
+	_, parseSpan := telemetry.StartSpan(c.UserContext(), "NessusParserService.ParseNessusFile", attribute.Int("file.size", len(fileData)))
 	vulnerabilities, err := h.parserService.ParseNessusFile(fileData)
+	telemetry.EndSpan(parseSpan, err)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": fmt.Sprintf("Failed to parse Nessus file: %v", err),
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+)
+
+// defaultAPIKeyRateLimit applies to keys created before rate limits were stored
+const defaultAPIKeyRateLimit = 60
+
+// SlidingWindowLimiter limits requests per key over a sliding window. It keeps the counts of
+// the current and previous fixed windows and weights the previous one by how much of it still
+// overlaps the sliding window, which approximates a sliding log in constant memory per key.
+type SlidingWindowLimiter struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*windowEntry
+	calls   int
+}
+
+type windowEntry struct {
+	start    time.Time // start of the current fixed window
+	current  int
+	previous int
+}
+
+// NewSlidingWindowLimiter creates a limiter with the given window length
+func NewSlidingWindowLimiter(window time.Duration) *SlidingWindowLimiter {
+	return &SlidingWindowLimiter{
+		window:  window,
+		entries: make(map[string]*windowEntry),
+	}
+}
+
+// Allow records a request for key at now and reports whether it is within limit. It also
+// returns the requests remaining in the window and, when rejected, how long to wait.
+func (l *SlidingWindowLimiter) Allow(key string, limit int, now time.Time) (bool, int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls++
+	if l.calls%1000 == 0 {
+		l.prune(now)
+	}
+
+	windowStart := now.Truncate(l.window)
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &windowEntry{start: windowStart}
+		l.entries[key] = entry
+	}
+	switch elapsed := windowStart.Sub(entry.start); {
+	case elapsed >= 2*l.window:
+		entry.previous, entry.current = 0, 0
+		entry.start = windowStart
+	case elapsed >= l.window:
+		entry.previous, entry.current = entry.current, 0
+		entry.start = windowStart
+	}
+
+	used := slidingWindowUsed(entry.previous, entry.current, windowStart, l.window, now)
+	if used >= limit {
+		return false, 0, slidingWindowRetryAfter(entry.previous, entry.current, used, limit, windowStart, l.window, now)
+	}
+
+	entry.current++
+	return true, limit - used - 1, 0
+}
+
+// slidingWindowOverlap is the share of the previous fixed window still inside the sliding window
+func slidingWindowOverlap(windowStart time.Time, window time.Duration, now time.Time) float64 {
+	return 1 - float64(now.Sub(windowStart))/float64(window)
+}
+
+// slidingWindowUsed estimates the requests made in the sliding window ending at now
+func slidingWindowUsed(previous, current int, windowStart time.Time, window time.Duration, now time.Time) int {
+	return int(math.Floor(float64(previous)*slidingWindowOverlap(windowStart, window, now))) + current
+}
+
+// slidingWindowRetryAfter returns how long a rejected request should wait. The weighted previous
+// window shrinks as time passes; at worst the next window frees capacity.
+func slidingWindowRetryAfter(previous, current, used, limit int, windowStart time.Time, window time.Duration, now time.Time) time.Duration {
+	retryAfter := windowStart.Add(window).Sub(now)
+	if previous > 0 && current < limit {
+		needed := float64(used-limit+1) / float64(previous)
+		retryAfter = time.Duration(needed * float64(window))
+	}
+	return retryAfter
+}
+
+// prune drops keys that have been idle for two windows
+func (l *SlidingWindowLimiter) prune(now time.Time) {
+	cutoff := now.Truncate(l.window).Add(-2 * l.window)
+	for key, entry := range l.entries {
+		if !entry.start.After(cutoff) {
+			delete(l.entries, key)
+		}
+	}
+}
+
+// limitAPIKey applies the key's per-minute rate limit and, when API rate limits are enabled,
+// the API key quota of the route group, and sets the X-RateLimit headers. It returns false
+// after writing a 429 response when a limit is exceeded.
+func limitAPIKey(c *fiber.Ctx, apiKey *models.APIKey) (bool, error) {
+	limit := apiKey.RateLimitPerMinute
+	if limit <= 0 {
+		limit = defaultAPIKeyRateLimit
+	}
+
+	checks := []rateLimitCheck{{
+		key:     "apikey:" + apiKey.ID.String(),
+		limit:   limit,
+		window:  time.Minute,
+		message: "API key rate limit exceeded. Please try again later.",
+	}}
+	if settings := services.CurrentAPIRateLimitSettings(); settings.Enabled {
+		group := RouteGroup(c.Path())
+		checks = append(checks, rateLimitCheck{
+			key:     "apikey:" + apiKey.ID.String() + ":" + group,
+			limit:   settings.LimitFor(group).APIKeyPerMinute,
+			window:  time.Minute,
+			message: "API key rate limit for " + group + " exceeded. Please try again later.",
+		})
+	}
+	return enforceRateLimits(c, checks...)
+}
+
+// limitUser applies the user quota of the route group to a request authenticated with a
+// session. It returns false after writing a 429 response when the quota is exceeded.
+func limitUser(c *fiber.Ctx, userID uuid.UUID) (bool, error) {
+	settings := services.CurrentAPIRateLimitSettings()
+	if !settings.Enabled {
+		return true, nil
+	}
+	group := RouteGroup(c.Path())
+	return enforceRateLimits(c, rateLimitCheck{
+		key:     "user:" + userID.String() + ":" + group,
+		limit:   settings.LimitFor(group).UserPerMinute,
+		window:  time.Minute,
+		message: "Rate limit for " + group + " exceeded. Please try again later.",
+	})
+}
+
+// RouteGroup returns the route group of a request path: the first segment after /api/<version>
+// ("vulnerabilities" for /api/v1/vulnerabilities/123)
+func RouteGroup(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) >= 2 && segments[0] == "api" && strings.HasPrefix(segments[1], "v") {
+		segments = segments[2:]
+	}
+	if len(segments) == 0 {
+		return ""
+	}
+	return strings.ToLower(segments[0])
+}
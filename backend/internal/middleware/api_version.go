@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIVersionHeader reports the API version that served a response
+const APIVersionHeader = "API-Version"
+
+// APIVersion tags requests with the API version of their route group
+func APIVersion(version string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("api_version", version)
+		c.Set(APIVersionHeader, version)
+		return c.Next()
+	}
+}
+
+// DeprecationPolicy describes a deprecated API version or route
+type DeprecationPolicy struct {
+	Date          time.Time                // When it was deprecated
+	Sunset        time.Time                // When it stops being served; zero when not scheduled
+	Successor     func(path string) string // Replacement URL of a request path; nil when there is none
+	Documentation string                   // Migration guide URL; empty when there is none
+}
+
+// Deprecation announces a deprecation on every response with the Deprecation (RFC 9745),
+// Sunset (RFC 8594) and Link headers, so clients can migrate before the sunset date
+func Deprecation(policy DeprecationPolicy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", "@"+strconv.FormatInt(policy.Date.Unix(), 10))
+		if !policy.Sunset.IsZero() {
+			c.Set("Sunset", policy.Sunset.UTC().Format(http.TimeFormat))
+		}
+
+		var links []string
+		if policy.Successor != nil {
+			links = append(links, fmt.Sprintf(`<%s>; rel="successor-version"`, policy.Successor(c.Path())))
+		}
+		if policy.Documentation != "" {
+			links = append(links, fmt.Sprintf(`<%s>; rel="deprecation"; type="text/html"`, policy.Documentation))
+		}
+		if len(links) > 0 {
+			c.Append(fiber.HeaderLink, strings.Join(links, ", "))
+		}
+		return c.Next()
+	}
+}
+
+// cursorPrefix versions the opaque cursor format
+const cursorPrefix = "p1:"
+
+// EncodeCursor returns the opaque cursor of a list page
+func EncodeCursor(page int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(page)))
+}
+
+// DecodeCursor returns the list page of a cursor created by EncodeCursor
+func DecodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	page, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || page < 1 {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	return page, nil
+}
+
+// CursorPagination lets list requests page with ?cursor= instead of ?page=. Cursors are opaque
+// to clients, so lists can move to keyset pagination without another response-shape change.
+func CursorPagination() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if cursor := c.Query("cursor"); cursor != "" {
+			page, err := DecodeCursor(cursor)
+			if err != nil {
+				return ValidationError(c, err.Error(), nil)
+			}
+			c.Request().URI().QueryArgs().Set("page", strconv.Itoa(page))
+		}
+		return c.Next()
+	}
+}
+
+// V2Response is the response serializer of API v2: the standard envelope, with list pages
+// described by cursors ({next_cursor, prev_cursor, has_more, limit, total}) instead of page
+// numbers
+func V2Response(status int, body []byte, requestID string) ([]byte, error) {
+	body, err := StandardEnvelope(status, body, requestID)
+	if err != nil || status >= 400 {
+		return body, err
+	}
+
+	var envelope APIEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	if !cursorMeta(envelope.Meta) {
+		return body, nil
+	}
+	return json.Marshal(envelope)
+}
+
+// cursorMeta replaces page-number pagination keys with cursors, reporting whether meta
+// described a page
+func cursorMeta(meta map[string]interface{}) bool {
+	page, ok := meta["page"].(float64)
+	if !ok {
+		return false
+	}
+	limit, _ := meta["limit"].(float64)
+	total, hasTotal := meta["total"].(float64)
+	totalPages, hasTotalPages := meta["total_pages"].(float64)
+
+	hasMore := false
+	switch {
+	case hasTotalPages:
+		hasMore = page < totalPages
+	case hasTotal && limit > 0:
+		hasMore = page*limit < total
+	}
+
+	delete(meta, "page")
+	delete(meta, "total_pages")
+	meta["has_more"] = hasMore
+	if hasMore {
+		meta["next_cursor"] = EncodeCursor(int(page) + 1)
+	}
+	if page > 1 {
+		meta["prev_cursor"] = EncodeCursor(int(page) - 1)
+	}
+	return true
+}
@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
 	"github.com/cyops/cyops-backend/pkg/utils"
@@ -39,12 +41,12 @@ func AuthMiddleware() fiber.Handler {
 			return authenticateAPIKey(c, token, apiKeyService)
 		}
 
-		// Otherwise, treat as JWT session token
+		// Otherwise, treat as a session access token
 		return authenticateSession(c, token, sessionService)
 	}
 }
 
-// authenticateSession validates a JWT session token
+// authenticateSession validates a session access token
 func authenticateSession(c *fiber.Ctx, token string, sessionService *services.SessionService) error {
 	session, err := sessionService.ValidateSession(token)
 	if err != nil {
@@ -53,6 +55,13 @@ func authenticateSession(c *fiber.Ctx, token string, sessionService *services.Se
 			Str("ip", c.IP()).
 			Msg("Session validation failed")
 
+		if errors.Is(err, services.ErrAccessTokenExpired) {
+			// The session is still alive; the client should redeem its refresh token
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Access token expired",
+				"code":  "token_expired",
+			})
+		}
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Invalid or expired session",
 		})
@@ -65,13 +74,83 @@ func authenticateSession(c *fiber.Ctx, token string, sessionService *services.Se
 	c.Locals("session_id", session.ID)
 	c.Locals("auth_method", "session")
 
+	if err := attachOrganization(c, session.User, nil); err != nil {
+		utils.Logger.Warn().
+			Err(err).
+			Str("user_id", session.UserID.String()).
+			Str("requested_org", c.Get(OrgHeader)).
+			Msg("Organization resolution failed")
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access to the requested organization is not permitted",
+		})
+	}
+
+	if err := attachPolicy(c, session.User); err != nil {
+		utils.Logger.Error().
+			Err(err).
+			Str("user_id", session.UserID.String()).
+			Msg("Failed to resolve role constraints")
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to resolve access policy",
+		})
+	}
+
+	// Enforce the user's quota for the route group
+	if allowed, err := limitUser(c, session.UserID); !allowed {
+		utils.Logger.Warn().
+			Str("user_id", session.UserID.String()).
+			Str("path", c.Path()).
+			Msg("User rate limit exceeded")
+		return err
+	}
+
 	utils.Logger.Debug().
 		Str("user_id", session.UserID.String()).
 		Str("session_id", session.ID.String()).
 		Str("path", c.Path()).
 		Msg("Request authenticated via session")
 
-	return c.Next()
+	if !session.IsImpersonation() {
+		return c.Next()
+	}
+
+	// Impersonation: name the administrator on every response and audit every change
+	c.Locals("impersonator_id", *session.ImpersonatorID)
+	c.Set("X-Impersonated-By", session.ImpersonatorID.String())
+
+	err = c.Next()
+	if !isReadOnlyMethod(c.Method()) {
+		// Errors are rendered by the app's error handler after this middleware returns
+		status := c.Response().StatusCode()
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			status = fiberErr.Code
+		} else if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+		sessionService.RecordImpersonatedRequest(session, c.Method(), c.Path(), status, GetRequestID(c), c.IP(), c.Get("User-Agent"))
+	}
+	return err
+}
+
+// isReadOnlyMethod reports whether an HTTP method cannot change state
+func isReadOnlyMethod(method string) bool {
+	return method == fiber.MethodGet || method == fiber.MethodHead || method == fiber.MethodOptions
+}
+
+// DenyDuringImpersonation refuses requests made from an impersonation session. It guards
+// endpoints that would let an administrator take over the account they are acting as, such
+// as changing credentials or minting API keys.
+func DenyDuringImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("impersonator_id").(uuid.UUID); ok {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "This action is not available while impersonating a user",
+			})
+		}
+		return c.Next()
+	}
 }
 
 // authenticateAPIKey validates an API key
@@ -106,19 +185,57 @@ func authenticateAPIKey(c *fiber.Ctx, key string, apiKeyService *services.APIKey
 	c.Locals("user", user)
 	c.Locals("user_id", user.ID)
 	c.Locals("api_key", apiKey)
+
+	// The key may only be used from its allowed networks
+	if len(apiKey.AllowedCIDRs) > 0 && !services.CIDRListContains(apiKey.AllowedCIDRs, c.IP()) {
+		return ipAccessDenied(c, "api_key")
+	}
 	c.Locals("api_key_id", apiKey.ID)
 	c.Locals("api_key_scopes", apiKey.GetScopes())
 	c.Locals("auth_method", "api_key")
 
-	// Update last used timestamp (async to avoid blocking the request)
-	go func() {
-		if err := apiKeyService.UpdateLastUsed(apiKey.ID); err != nil {
-			utils.Logger.Error().
-				Err(err).
-				Str("api_key_id", apiKey.ID.String()).
-				Msg("Failed to update API key last_used_at")
-		}
-	}()
+	if err := attachOrganization(c, user, apiKey.OrgID); err != nil {
+		utils.Logger.Warn().
+			Err(err).
+			Str("user_id", user.ID.String()).
+			Str("requested_org", c.Get(OrgHeader)).
+			Msg("Organization resolution failed")
+
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Access to the requested organization is not permitted",
+		})
+	}
+
+	if err := attachPolicy(c, user); err != nil {
+		utils.Logger.Error().
+			Err(err).
+			Str("user_id", user.ID.String()).
+			Msg("Failed to resolve role constraints")
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to resolve access policy",
+		})
+	}
+
+	// Enforce the key's per-minute rate limit
+	if allowed, err := limitAPIKey(c, apiKey); !allowed {
+		utils.Logger.Warn().
+			Str("api_key_id", apiKey.ID.String()).
+			Int("rate_limit_per_minute", apiKey.RateLimitPerMinute).
+			Str("path", c.Path()).
+			Msg("API key rate limit exceeded")
+
+		services.RecordAPIKeyUsage(services.APIKeyUsageEvent{
+			APIKeyID:    apiKey.ID,
+			Method:      c.Method(),
+			Route:       c.Route().Path, // the route group; the endpoint has not been matched yet
+			IP:          c.IP(),
+			Failed:      true,
+			RateLimited: true,
+			At:          time.Now(),
+		})
+		return err
+	}
 
 	utils.Logger.Debug().
 		Str("user_id", user.ID.String()).
@@ -127,7 +244,26 @@ func authenticateAPIKey(c *fiber.Ctx, key string, apiKeyService *services.APIKey
 		Str("path", c.Path()).
 		Msg("Request authenticated via API key")
 
-	return c.Next()
+	// Nudge integrations still using the previous secret of a rotated key
+	if apiKey.AuthenticatedWithSecondary {
+		utils.Logger.Warn().
+			Str("api_key_id", apiKey.ID.String()).
+			Time("secondary_expires_at", *apiKey.SecondaryExpiresAt).
+			Msg("Request authenticated with the previous secret of a rotated API key")
+		c.Set("X-API-Key-Rotation", "secondary; expires="+apiKey.SecondaryExpiresAt.UTC().Format(time.RFC3339))
+	}
+
+	// Record usage per endpoint once the request has been handled (flushed by a background job)
+	err = c.Next()
+	services.RecordAPIKeyUsage(services.APIKeyUsageEvent{
+		APIKeyID: apiKey.ID,
+		Method:   c.Method(),
+		Route:    c.Route().Path,
+		IP:       c.IP(),
+		Failed:   err != nil || c.Response().StatusCode() >= fiber.StatusBadRequest,
+		At:       time.Now(),
+	})
+	return err
 }
 
 // extractKeyPrefix extracts the prefix from an API key for logging (without exposing the full key)
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// NotModified sets the ETag and Last-Modified headers of a detail response and reports whether
+// the client's cached copy is still current, in which case the handler should reply 304.
+// If-None-Match takes precedence over If-Modified-Since.
+func NotModified(c *fiber.Ctx, updatedAt time.Time) bool {
+	etag := utils.ResourceETag(updatedAt)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderLastModified, updatedAt.UTC().Format(http.TimeFormat))
+	// Cached copies must be revalidated, since access can change independently of the record
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+
+	if ifNoneMatch := c.Get(fiber.HeaderIfNoneMatch); ifNoneMatch != "" {
+		return utils.ETagMatches(ifNoneMatch, etag, true)
+	}
+	if ifModifiedSince := c.Get(fiber.HeaderIfModifiedSince); ifModifiedSince != "" {
+		since, err := http.ParseTime(ifModifiedSince)
+		return err == nil && !updatedAt.Truncate(time.Second).After(since)
+	}
+	return false
+}
@@ -0,0 +1,278 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// Machine-readable error codes of the standard response envelope
+const (
+	CodeBadRequest         = "bad_request"
+	CodeValidation         = "validation_error"
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeNotFound           = "not_found"
+	CodeConflict           = "conflict"
+	CodePreconditionFailed = "precondition_failed"
+	CodePayloadTooLarge    = "payload_too_large"
+	CodeRateLimited        = "rate_limited"
+	CodeInternal           = "internal_error"
+	CodeNotImplemented     = "not_implemented"
+	CodeUnavailable        = "service_unavailable"
+)
+
+// Response envelope modes (API_RESPONSE_ENVELOPE, or per request via the X-API-Envelope header)
+const (
+	EnvelopeLegacy   = "legacy"   // Responses as each handler writes them
+	EnvelopeStandard = "standard" // {data, meta, message} and {error: {code, message, fields}}
+)
+
+// EnvelopeHeader selects the envelope mode of a single request and reports it on the response
+const EnvelopeHeader = "X-API-Envelope"
+
+// APIEnvelope is the standard success response
+type APIEnvelope struct {
+	Data    interface{}            `json:"data"`
+	Meta    map[string]interface{} `json:"meta,omitempty"`
+	Message string                 `json:"message,omitempty"`
+}
+
+// APIErrorEnvelope is the standard error response
+type APIErrorEnvelope struct {
+	Error APIError `json:"error"`
+}
+
+// APIError is a machine-readable error with optional field-level details
+type APIError struct {
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Status    int                    `json:"status"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Fields    []FieldErrorDetail     `json:"fields,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// FieldErrorDetail is a validation error of one request field
+type FieldErrorDetail struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+var errorCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// FieldValidationError creates a validation error response, listing the offending field when
+// err is a utils.FieldError
+func FieldValidationError(c *fiber.Ctx, err error) error {
+	var fieldErr *utils.FieldError
+	if !errors.As(err, &fieldErr) {
+		return ValidationError(c, err.Error(), nil)
+	}
+	return ValidationError(c, err.Error(), map[string]interface{}{
+		"fields": []FieldErrorDetail{{Field: fieldErr.Field, Message: fieldErr.Error()}},
+	})
+}
+
+// ErrorCodeForStatus returns the default error code of an HTTP status
+func ErrorCodeForStatus(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeBadRequest
+	case fiber.StatusUnprocessableEntity:
+		return CodeValidation
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusForbidden:
+		return CodeForbidden
+	case fiber.StatusNotFound:
+		return CodeNotFound
+	case fiber.StatusConflict:
+		return CodeConflict
+	case fiber.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case fiber.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case fiber.StatusTooManyRequests:
+		return CodeRateLimited
+	case fiber.StatusNotImplemented:
+		return CodeNotImplemented
+	case fiber.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// SkipEnvelope keeps a response out of the standard envelope, for endpoints whose format is
+// fixed by another specification (e.g. GraphQL)
+func SkipEnvelope(c *fiber.Ctx) {
+	c.Locals("skip_envelope", true)
+}
+
+// ResponseSerializer rewrites a handler's JSON response body into an API's response shape
+type ResponseSerializer func(status int, body []byte, requestID string) ([]byte, error)
+
+// ResponseEnvelope rewrites JSON responses into the standard envelope when mode is
+// EnvelopeStandard, or when the request asks for it with the X-API-Envelope header. Handlers
+// keep writing their existing shapes, so legacy clients are unaffected.
+func ResponseEnvelope(mode string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requested := mode
+		if header := strings.ToLower(c.Get(EnvelopeHeader)); header == EnvelopeStandard || header == EnvelopeLegacy {
+			requested = header
+		}
+		if requested != EnvelopeStandard {
+			return c.Next()
+		}
+		return serializeJSON(c, StandardEnvelope)
+	}
+}
+
+// SerializeResponses rewrites every JSON response of the routes below it with serialize,
+// e.g. to give an API version its own response shape
+func SerializeResponses(serialize ResponseSerializer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return serializeJSON(c, serialize)
+	}
+}
+
+// serializeJSON runs the handler chain and rewrites its JSON response with serialize
+func serializeJSON(c *fiber.Ctx, serialize ResponseSerializer) error {
+	if err := c.Next(); err != nil {
+		// Render the error now so it can be serialized like any other response
+		if handlerErr := c.App().Config().ErrorHandler(c, err); handlerErr != nil {
+			return handlerErr
+		}
+	}
+	if c.Locals("skip_envelope") != nil {
+		return nil
+	}
+
+	response := c.Response()
+	status := response.StatusCode()
+	if response.IsBodyStream() || status == fiber.StatusNoContent || status == fiber.StatusNotModified ||
+		!strings.HasPrefix(string(response.Header.ContentType()), fiber.MIMEApplicationJSON) {
+		return nil
+	}
+
+	requestID, _ := c.Locals("requestid").(string)
+	body, err := serialize(status, response.Body(), requestID)
+	if err != nil {
+		// Not a JSON document; leave it untouched
+		return nil
+	}
+	c.Set(EnvelopeHeader, EnvelopeStandard)
+	response.SetBodyRaw(body)
+	return nil
+}
+
+// StandardEnvelope converts a handler's JSON response body into the standard envelope.
+// Error statuses become {error: {code, message, status, fields, details}}; anything else
+// becomes {data, meta, message}, with pagination and other keys next to data moved into meta.
+func StandardEnvelope(status int, body []byte, requestID string) ([]byte, error) {
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, err
+	}
+
+	object, isObject := decoded.(map[string]interface{})
+	if status >= 400 {
+		if _, enveloped := object["error"].(map[string]interface{}); enveloped {
+			return body, nil
+		}
+		return json.Marshal(APIErrorEnvelope{Error: standardError(status, object, requestID)})
+	}
+
+	if !isObject {
+		return json.Marshal(APIEnvelope{Data: decoded})
+	}
+
+	envelope := APIEnvelope{Meta: map[string]interface{}{}}
+	if message, ok := object["message"].(string); ok {
+		envelope.Message = message
+		delete(object, "message")
+	}
+	if meta, ok := object["meta"].(map[string]interface{}); ok {
+		for key, value := range meta {
+			envelope.Meta[key] = value
+		}
+		delete(object, "meta")
+	}
+
+	if data, ok := object["data"]; ok {
+		// Everything next to data describes it
+		delete(object, "data")
+		envelope.Data = data
+		for key, value := range object {
+			envelope.Meta[key] = value
+		}
+	} else if len(object) > 0 {
+		envelope.Data = object
+	}
+
+	if len(envelope.Meta) == 0 {
+		envelope.Meta = nil
+	}
+	return json.Marshal(envelope)
+}
+
+// standardError builds the standard error from a legacy error body: ErrorResponse
+// ({error: code, message, details}), {error: message, code} or {error: message}
+func standardError(status int, object map[string]interface{}, requestID string) APIError {
+	apiErr := APIError{Code: ErrorCodeForStatus(status), Status: status, RequestID: requestID}
+
+	errorValue, _ := object["error"].(string)
+	message, _ := object["message"].(string)
+	code, _ := object["code"].(string)
+	switch {
+	case code != "":
+		apiErr.Code = code
+		apiErr.Message = errorValue
+	case errorValue != "" && message != "" && errorCodePattern.MatchString(errorValue):
+		// ErrorHandler's generic http_error carries no more than the status
+		if errorValue != "http_error" {
+			apiErr.Code = errorValue
+		}
+	default:
+		apiErr.Message = errorValue
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = message
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(status)
+	}
+	if id, ok := object["request_id"].(string); ok && id != "" {
+		apiErr.RequestID = id
+	}
+
+	details, _ := object["details"].(map[string]interface{})
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	for key, value := range object {
+		switch key {
+		case "error", "message", "code", "status", "request_id", "details":
+		default:
+			// Extra context some handlers attach to errors
+			details[key] = value
+		}
+	}
+	if fields, ok := details["fields"]; ok {
+		if data, err := json.Marshal(fields); err == nil {
+			_ = json.Unmarshal(data, &apiErr.Fields)
+		}
+		delete(details, "fields")
+	}
+	if len(details) > 0 {
+		apiErr.Details = details
+	}
+	return apiErr
+}
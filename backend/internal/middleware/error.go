@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/pkg/i18n"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
@@ -19,7 +20,7 @@ func ErrorHandler() fiber.ErrorHandler {
 	return func(c *fiber.Ctx, err error) error {
 		code := fiber.StatusInternalServerError
 		message := "Internal Server Error"
-		errorType := "internal_error"
+		errorType := CodeInternal
 
 		// Check if it's a Fiber error
 		if e, ok := err.(*fiber.Error); ok {
@@ -46,7 +47,7 @@ func ErrorHandler() fiber.ErrorHandler {
 		// Send error response
 		return c.Status(code).JSON(ErrorResponse{
 			Error:     errorType,
-			Message:   message,
+			Message:   localize(c, message),
 			Status:    code,
 			RequestID: requestIDStr,
 		})
@@ -62,8 +63,8 @@ func ValidationError(c *fiber.Ctx, message string, details map[string]interface{
 	}
 
 	return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-		Error:     "validation_error",
-		Message:   message,
+		Error:     CodeValidation,
+		Message:   localize(c, message),
 		Status:    fiber.StatusBadRequest,
 		RequestID: requestIDStr,
 		Details:   details,
@@ -83,8 +84,8 @@ func UnauthorizedError(c *fiber.Ctx, message string) error {
 	}
 
 	return c.Status(fiber.StatusUnauthorized).JSON(ErrorResponse{
-		Error:     "unauthorized",
-		Message:   message,
+		Error:     CodeUnauthorized,
+		Message:   localize(c, message),
 		Status:    fiber.StatusUnauthorized,
 		RequestID: requestIDStr,
 	})
@@ -103,8 +104,8 @@ func ForbiddenError(c *fiber.Ctx, message string) error {
 	}
 
 	return c.Status(fiber.StatusForbidden).JSON(ErrorResponse{
-		Error:     "forbidden",
-		Message:   message,
+		Error:     CodeForbidden,
+		Message:   localize(c, message),
 		Status:    fiber.StatusForbidden,
 		RequestID: requestIDStr,
 	})
@@ -112,9 +113,11 @@ func ForbiddenError(c *fiber.Ctx, message string) error {
 
 // NotFoundError creates a not found error response
 func NotFoundError(c *fiber.Ctx, resource string) error {
-	message := "Resource not found"
+	lang := Language(c)
+	c.Set(fiber.HeaderContentLanguage, lang)
+	message := i18n.T(lang, "error.resource_not_found")
 	if resource != "" {
-		message = resource + " not found"
+		message = i18n.T(lang, "error.not_found", i18n.Translate(lang, resource))
 	}
 
 	requestID := c.Locals("requestid")
@@ -124,7 +127,7 @@ func NotFoundError(c *fiber.Ctx, resource string) error {
 	}
 
 	return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
-		Error:     "not_found",
+		Error:     CodeNotFound,
 		Message:   message,
 		Status:    fiber.StatusNotFound,
 		RequestID: requestIDStr,
@@ -144,13 +147,34 @@ func ConflictError(c *fiber.Ctx, message string) error {
 	}
 
 	return c.Status(fiber.StatusConflict).JSON(ErrorResponse{
-		Error:     "conflict",
-		Message:   message,
+		Error:     CodeConflict,
+		Message:   localize(c, message),
 		Status:    fiber.StatusConflict,
 		RequestID: requestIDStr,
 	})
 }
 
+// PreconditionFailedError creates a precondition failed error response, returned when an
+// If-Match version no longer matches the stored resource
+func PreconditionFailedError(c *fiber.Ctx, message string) error {
+	if message == "" {
+		message = "Resource was modified by another request"
+	}
+
+	requestID := c.Locals("requestid")
+	requestIDStr := ""
+	if requestID != nil {
+		requestIDStr = requestID.(string)
+	}
+
+	return c.Status(fiber.StatusPreconditionFailed).JSON(ErrorResponse{
+		Error:     CodePreconditionFailed,
+		Message:   localize(c, message),
+		Status:    fiber.StatusPreconditionFailed,
+		RequestID: requestIDStr,
+	})
+}
+
 // InternalError creates an internal server error response
 func InternalError(c *fiber.Ctx, err error) error {
 	requestID := c.Locals("requestid")
@@ -166,9 +190,27 @@ func InternalError(c *fiber.Ctx, err error) error {
 		Msg("Internal server error")
 
 	return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-		Error:     "internal_error",
-		Message:   "An internal error occurred",
+		Error:     CodeInternal,
+		Message:   localize(c, "An internal error occurred"),
 		Status:    fiber.StatusInternalServerError,
 		RequestID: requestIDStr,
 	})
 }
+
+// ShuttingDownError creates a service unavailable error response for work refused while the
+// server drains before stopping; another instance can take the retried request
+func ShuttingDownError(c *fiber.Ctx) error {
+	requestID := c.Locals("requestid")
+	requestIDStr := ""
+	if requestID != nil {
+		requestIDStr = requestID.(string)
+	}
+
+	c.Set(fiber.HeaderRetryAfter, "5")
+	return c.Status(fiber.StatusServiceUnavailable).JSON(ErrorResponse{
+		Error:     CodeUnavailable,
+		Message:   localize(c, "The server is shutting down, retry the request"),
+		Status:    fiber.StatusServiceUnavailable,
+		RequestID: requestIDStr,
+	})
+}
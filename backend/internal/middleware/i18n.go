@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/i18n"
+)
+
+// Language returns the language to respond in: the signed-in user's preferred language, else
+// the best match for the Accept-Language header
+func Language(c *fiber.Ctx) string {
+	if lang, ok := c.Locals("language").(string); ok {
+		return lang
+	}
+
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		// Not cached: the user may not be authenticated yet
+		return i18n.Negotiate(c.Get(fiber.HeaderAcceptLanguage))
+	}
+	lang := LanguageFor(c, userID)
+	c.Locals("language", lang)
+	return lang
+}
+
+// LanguageFor returns the language to write to a user in, such as for an email sent on their
+// behalf before they signed in: their preferred language, else the request's Accept-Language
+func LanguageFor(c *fiber.Ctx, userID uuid.UUID) string {
+	if db := database.GetDB(); db != nil {
+		if lang := services.NewUserPreferenceService(db).GetLanguage(userID); lang != "" {
+			return lang
+		}
+	}
+	return i18n.Negotiate(c.Get(fiber.HeaderAcceptLanguage))
+}
+
+// localize translates an error message into the response language
+func localize(c *fiber.Ctx, message string) string {
+	lang := Language(c)
+	c.Set(fiber.HeaderContentLanguage, lang)
+	return i18n.Translate(lang, message)
+}
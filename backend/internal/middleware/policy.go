@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/policy"
+)
+
+// attachPolicy resolves the attribute constraints of the user's role (including inherited ones)
+// and carries them in the request context, where services enforce them.
+func attachPolicy(c *fiber.Ctx, user *models.User) error {
+	if user == nil || user.RoleID == nil {
+		return nil
+	}
+
+	var constraints []models.AccessConstraint
+	if user.Role != nil && user.Role.ParentID == nil {
+		// Preloaded role without inheritance: no further lookups needed
+		own, err := user.Role.GetConstraints()
+		if err != nil {
+			return err
+		}
+		constraints = own
+	} else {
+		roleID, err := uuid.Parse(*user.RoleID)
+		if err != nil {
+			return err
+		}
+		effective, err := services.NewRoleService().GetEffectivePermissions(roleID)
+		if err != nil {
+			return err
+		}
+		constraints = effective.Constraints
+	}
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	c.SetUserContext(policy.WithSubject(c.UserContext(), &policy.Subject{
+		UserID:      user.ID,
+		Constraints: constraints,
+	}))
+	return nil
+}
@@ -4,7 +4,6 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
 )
 
 // RateLimitConfig holds rate limiting configuration
@@ -13,22 +12,22 @@ type RateLimitConfig struct {
 	Expiration time.Duration
 }
 
-// NewRateLimiter creates a new rate limiter middleware
+// NewRateLimiter creates a new rate limiter middleware that limits each client IP per route.
+// Requests are counted in the configured rate limit store, so limits are shared by all
+// replicas when the Redis store is enabled.
 func NewRateLimiter(config RateLimitConfig) fiber.Handler {
-	return limiter.New(limiter.Config{
-		Max:        config.Max,
-		Expiration: config.Expiration,
-		KeyGenerator: func(c *fiber.Ctx) string {
-			return c.IP()
-		},
-		LimitReached: func(c *fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(ErrorResponse{
-				Error:   "rate_limit_exceeded",
-				Message: "Too many requests. Please try again later.",
-				Status:  fiber.StatusTooManyRequests,
-			})
-		},
-	})
+	return func(c *fiber.Ctx) error {
+		allowed, err := enforceRateLimits(c, rateLimitCheck{
+			key:     "ip:" + c.Method() + ":" + c.Route().Path + ":" + c.IP(),
+			limit:   config.Max,
+			window:  config.Expiration,
+			message: "Too many requests. Please try again later.",
+		})
+		if !allowed {
+			return err
+		}
+		return c.Next()
+	}
 }
 
 // AuthRateLimiter creates a rate limiter for authentication endpoints
@@ -70,3 +69,27 @@ func VulnerabilityCreationRateLimiter() fiber.Handler {
 		Expiration: 1 * time.Minute, // per minute
 	})
 }
+
+// DisclosureRateLimiter creates a rate limiter for the public vulnerability disclosure form
+func DisclosureRateLimiter() fiber.Handler {
+	return NewRateLimiter(RateLimitConfig{
+		Max:        5,                // 5 submissions
+		Expiration: 60 * time.Minute, // per hour
+	})
+}
+
+// ReportVerifyRateLimiter creates a rate limiter for the public report signature checks
+func ReportVerifyRateLimiter() fiber.Handler {
+	return NewRateLimiter(RateLimitConfig{
+		Max:        20,              // 20 requests
+		Expiration: 1 * time.Minute, // per minute
+	})
+}
+
+// AuditorPortalRateLimiter creates a rate limiter for the token-authenticated auditor portal
+func AuditorPortalRateLimiter() fiber.Handler {
+	return NewRateLimiter(RateLimitConfig{
+		Max:        60,              // 60 requests
+		Expiration: 1 * time.Minute, // per minute
+	})
+}
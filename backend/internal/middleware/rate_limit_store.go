@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// rateLimitKeyPrefix namespaces rate limit counters in Redis
+const rateLimitKeyPrefix = "cyops:ratelimit:"
+
+// RateLimitResult is the outcome of counting a request against a limit
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // How long to wait when the request was rejected
+}
+
+// RateLimitStore counts requests per key over a sliding window
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (RateLimitResult, error)
+}
+
+// MemoryRateLimitStore keeps counters in this instance's memory, so every replica enforces
+// its own limits
+type MemoryRateLimitStore struct {
+	mu       sync.Mutex
+	limiters map[time.Duration]*SlidingWindowLimiter
+}
+
+// NewMemoryRateLimitStore creates an in-memory rate limit store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{limiters: make(map[time.Duration]*SlidingWindowLimiter)}
+}
+
+// Allow records a request for key and reports whether it is within limit
+func (s *MemoryRateLimitStore) Allow(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	limiter, ok := s.limiters[window]
+	if !ok {
+		limiter = NewSlidingWindowLimiter(window)
+		s.limiters[window] = limiter
+	}
+	s.mu.Unlock()
+
+	allowed, remaining, retryAfter := limiter.Allow(key, limit, now)
+	return RateLimitResult{Allowed: allowed, Limit: limit, Remaining: remaining, RetryAfter: retryAfter}, nil
+}
+
+// slidingWindowScript counts a request in the current fixed window unless the weighted
+// previous window plus the current one already reach the limit. It returns whether the request
+// was allowed and the counts of the previous and current windows before it.
+var slidingWindowScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local used = math.floor(previous * tonumber(ARGV[2])) + current
+if used >= tonumber(ARGV[1]) then
+	return {0, previous, current}
+end
+if redis.call('INCR', KEYS[1]) == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return {1, previous, current}
+`)
+
+// RedisRateLimitStore keeps counters in Redis so all replicas share the same limits. It uses
+// the same sliding window approximation as the in-memory store, with one counter per fixed
+// window that expires once it can no longer affect the sliding window.
+type RedisRateLimitStore struct {
+	client *redis.Client
+}
+
+// NewRedisRateLimitStore creates a Redis-backed rate limit store
+func NewRedisRateLimitStore(client *redis.Client) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client}
+}
+
+// Allow records a request for key and reports whether it is within limit
+func (s *RedisRateLimitStore) Allow(ctx context.Context, key string, limit int, window time.Duration, now time.Time) (RateLimitResult, error) {
+	windowStart := now.Truncate(window)
+	currentKey := fmt.Sprintf("%s%s:%d", rateLimitKeyPrefix, key, windowStart.Unix())
+	previousKey := fmt.Sprintf("%s%s:%d", rateLimitKeyPrefix, key, windowStart.Add(-window).Unix())
+	overlap := slidingWindowOverlap(windowStart, window, now)
+
+	values, err := slidingWindowScript.Run(ctx, s.client, []string{currentKey, previousKey},
+		limit, strconv.FormatFloat(overlap, 'f', 6, 64), (2 * window).Milliseconds()).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("failed to count request: %w", err)
+	}
+	if len(values) != 3 {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	previous, current := int(values[1]), int(values[2])
+	used := slidingWindowUsed(previous, current, windowStart, window, now)
+	if values[0] == 0 {
+		return RateLimitResult{
+			Limit:      limit,
+			RetryAfter: slidingWindowRetryAfter(previous, current, used, limit, windowStart, window, now),
+		}, nil
+	}
+	return RateLimitResult{Allowed: true, Limit: limit, Remaining: limit - used - 1}, nil
+}
+
+var (
+	rateLimitStoreMu sync.RWMutex
+	rateLimitStore   RateLimitStore = NewMemoryRateLimitStore()
+
+	// localRateLimitStore counts requests while the shared store is unreachable
+	localRateLimitStore = NewMemoryRateLimitStore()
+)
+
+// SetRateLimitStore sets the store all rate limiters count requests in (nil restores the
+// in-memory store)
+func SetRateLimitStore(store RateLimitStore) {
+	rateLimitStoreMu.Lock()
+	defer rateLimitStoreMu.Unlock()
+	if store == nil {
+		store = NewMemoryRateLimitStore()
+	}
+	rateLimitStore = store
+}
+
+// activeRateLimitStore returns the configured rate limit store
+func activeRateLimitStore() RateLimitStore {
+	rateLimitStoreMu.RLock()
+	defer rateLimitStoreMu.RUnlock()
+	return rateLimitStore
+}
+
+// allowRequest counts a request against a limit. When the shared store fails, the request is
+// counted in this instance's memory instead so limits stay enforced per replica.
+func allowRequest(c *fiber.Ctx, key string, limit int, window time.Duration) RateLimitResult {
+	now := time.Now()
+	result, err := activeRateLimitStore().Allow(c.UserContext(), key, limit, window, now)
+	if err != nil {
+		utils.Logger.Warn().Err(err).Str("key", key).Msg("Rate limit store unavailable, enforcing the limit per instance")
+		result, _ = localRateLimitStore.Allow(c.UserContext(), key, limit, window, now)
+	}
+	return result
+}
+
+// rateLimitCheck is one limit a request is counted against
+type rateLimitCheck struct {
+	key     string
+	limit   int
+	window  time.Duration
+	message string // Error message when the limit is exceeded
+}
+
+// enforceRateLimits counts a request against each limit in turn and sets the X-RateLimit
+// headers of the tightest one. It returns false after writing a 429 response with a
+// Retry-After header when a limit is exceeded.
+func enforceRateLimits(c *fiber.Ctx, checks ...rateLimitCheck) (bool, error) {
+	var tightest *RateLimitResult
+	for _, check := range checks {
+		if check.limit <= 0 {
+			continue
+		}
+		result := allowRequest(c, check.key, check.limit, check.window)
+		if !result.Allowed {
+			return false, rateLimitExceeded(c, result, check.message)
+		}
+		if tightest == nil || result.Remaining < tightest.Remaining {
+			tightest = &result
+		}
+	}
+	if tightest != nil {
+		c.Set("X-RateLimit-Limit", strconv.Itoa(tightest.Limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(tightest.Remaining))
+	}
+	return true, nil
+}
+
+// rateLimitExceeded writes the 429 response of a rejected request
+func rateLimitExceeded(c *fiber.Ctx, result RateLimitResult, message string) error {
+	seconds := int(math.Ceil(result.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Set("X-RateLimit-Remaining", "0")
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return c.Status(fiber.StatusTooManyRequests).JSON(ErrorResponse{
+		Error:   "rate_limit_exceeded",
+		Message: message,
+		Status:  fiber.StatusTooManyRequests,
+	})
+}
@@ -1,14 +1,22 @@
 package middleware
 
 import (
+	"fmt"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
 // RequirePermission middleware checks if the authenticated user has a specific permission
 func RequirePermission(resource, action string) fiber.Handler {
+	// Every permission check must be grantable through the catalog
+	if !models.IsCatalogPermission(resource, action) {
+		panic(fmt.Sprintf("permission %s:%s is not in the permission catalog", resource, action))
+	}
+
 	return func(c *fiber.Ctx) error {
 		// Get user ID from context (set by AuthMiddleware)
 		userIDVal := c.Locals("user_id")
@@ -3,7 +3,6 @@ package middleware
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
 // RequestID middleware adds a unique request ID to each request
@@ -23,27 +22,8 @@ func RequestID() fiber.Handler {
 		// Add request ID to response header
 		c.Set("X-Request-ID", requestID)
 
-		// Log request with ID
-		utils.Logger.Info().
-			Str("request_id", requestID).
-			Str("method", c.Method()).
-			Str("path", c.Path()).
-			Str("ip", c.IP()).
-			Str("user_agent", c.Get("User-Agent")).
-			Msg("Incoming request")
-
-		// Continue to next handler
-		err := c.Next()
-
-		// Log response with ID
-		utils.Logger.Info().
-			Str("request_id", requestID).
-			Int("status", c.Response().StatusCode()).
-			Str("method", c.Method()).
-			Str("path", c.Path()).
-			Msg("Request completed")
-
-		return err
+		// Continue to next handler (AccessLog records the request with this ID)
+		return c.Next()
 	}
 }
 
@@ -1,13 +1,18 @@
 package middleware
 
 import (
+	"fmt"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
 // RequireScope checks if API key has required scope
 // For session-based auth (JWT), this middleware is bypassed (uses RBAC instead)
 func RequireScope(scope string) fiber.Handler {
+	mustBeCatalogScopes(scope)
+
 	return func(c *fiber.Ctx) error {
 		authMethod := c.Locals("auth_method")
 
@@ -41,16 +46,8 @@ func RequireScope(scope string) fiber.Handler {
 			})
 		}
 
-		// Check for wildcard permission
-		if contains(scopes, "*:*") {
-			utils.Logger.Debug().
-				Str("required_scope", scope).
-				Msg("API key has wildcard permission")
-			return c.Next()
-		}
-
-		// Check specific scope
-		if !contains(scopes, scope) {
+		// Check specific scope (or a wildcard covering it)
+		if !models.ScopeGranted(scopes, scope) {
 			utils.Logger.Warn().
 				Str("required_scope", scope).
 				Strs("available_scopes", scopes).
@@ -73,6 +70,8 @@ func RequireScope(scope string) fiber.Handler {
 
 // RequireAnyScope checks if API key has any of the specified scopes
 func RequireAnyScope(scopes ...string) fiber.Handler {
+	mustBeCatalogScopes(scopes...)
+
 	return func(c *fiber.Ctx) error {
 		authMethod := c.Locals("auth_method")
 
@@ -96,14 +95,9 @@ func RequireAnyScope(scopes ...string) fiber.Handler {
 			})
 		}
 
-		// Check for wildcard permission
-		if contains(apiKeyScopes, "*:*") {
-			return c.Next()
-		}
-
 		// Check if any of the required scopes are present
 		for _, requiredScope := range scopes {
-			if contains(apiKeyScopes, requiredScope) {
+			if models.ScopeGranted(apiKeyScopes, requiredScope) {
 				utils.Logger.Debug().
 					Str("matched_scope", requiredScope).
 					Msg("API key has one of the required scopes")
@@ -126,6 +120,8 @@ func RequireAnyScope(scopes ...string) fiber.Handler {
 
 // RequireAllScopes checks if API key has all of the specified scopes
 func RequireAllScopes(scopes ...string) fiber.Handler {
+	mustBeCatalogScopes(scopes...)
+
 	return func(c *fiber.Ctx) error {
 		authMethod := c.Locals("auth_method")
 
@@ -149,14 +145,9 @@ func RequireAllScopes(scopes ...string) fiber.Handler {
 			})
 		}
 
-		// Check for wildcard permission
-		if contains(apiKeyScopes, "*:*") {
-			return c.Next()
-		}
-
 		// Check if all required scopes are present
 		for _, requiredScope := range scopes {
-			if !contains(apiKeyScopes, requiredScope) {
+			if !models.ScopeGranted(apiKeyScopes, requiredScope) {
 				utils.Logger.Warn().
 					Strs("required_scopes", scopes).
 					Strs("available_scopes", apiKeyScopes).
@@ -180,14 +171,14 @@ func RequireAllScopes(scopes ...string) fiber.Handler {
 	}
 }
 
-// contains checks if a slice contains a specific string
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
+// mustBeCatalogScopes panics on scopes that API keys cannot be granted, so a typo in a
+// route definition fails at startup instead of locking every API key out of the route
+func mustBeCatalogScopes(scopes ...string) {
+	for _, scope := range scopes {
+		if !models.IsCatalogScope(scope) {
+			panic(fmt.Sprintf("scope %s is not in the API key scope catalog", scope))
 		}
 	}
-	return false
 }
 
 // joinStrings joins strings with a separator
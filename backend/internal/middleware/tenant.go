@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+)
+
+// OrgHeader lets platform administrators act within another organization
+const OrgHeader = "X-Org-ID"
+
+// attachOrganization resolves the request's organization and scopes the request context to it.
+// Services called with c.UserContext() then only see that organization's data.
+func attachOrganization(c *fiber.Ctx, user *models.User, keyOrgID *uuid.UUID) error {
+	orgID, err := services.NewOrganizationService().ResolveRequestOrg(user, keyOrgID, c.Get(OrgHeader))
+	if err != nil {
+		return err
+	}
+
+	c.Locals("org_id", orgID)
+	c.SetUserContext(tenant.WithOrg(c.UserContext(), orgID))
+	return nil
+}
+
+// GetOrgID returns the organization of an authenticated request
+func GetOrgID(c *fiber.Ctx) (uuid.UUID, bool) {
+	orgID, ok := c.Locals("org_id").(uuid.UUID)
+	return orgID, ok
+}
+
+// RequirePlatformOrganization restricts a route to users of the platform organization.
+// Use it for operations that span tenants (organization management, global settings, data cleanup).
+func RequirePlatformOrganization() fiber.Handler {
+	orgService := services.NewOrganizationService()
+
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(*models.User)
+		if !ok || user == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Authentication required",
+			})
+		}
+
+		if !orgService.IsPlatformUser(user) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "This action is restricted to platform administrators",
+			})
+		}
+
+		return c.Next()
+	}
+}
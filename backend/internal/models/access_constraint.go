@@ -0,0 +1,108 @@
+package models
+
+import "fmt"
+
+// AccessConstraint restricts a role's modifying actions on a resource to assets with matching
+// attributes, e.g. "vulnerabilities may only be changed on STAGING assets up to MEDIUM criticality".
+// For vulnerabilities the attributes of every affected asset are checked.
+type AccessConstraint struct {
+	Resource string `json:"resource"` // asset or vulnerability
+	// Actions the constraint applies to; empty means every modifying action of the resource
+	Actions      []string      `json:"actions,omitempty"`
+	Environments []Environment `json:"environments,omitempty"`
+	// MaxCriticality is the highest asset criticality allowed (inclusive). Assets without a
+	// criticality are treated as LOW.
+	MaxCriticality *AssetCriticality `json:"max_criticality,omitempty"`
+}
+
+// ConstrainableResources lists the resources that support attribute constraints
+var ConstrainableResources = map[string]bool{
+	"asset":         true,
+	"vulnerability": true,
+}
+
+// CriticalityRank orders asset criticality (higher is more critical); unset criticality ranks as LOW
+func CriticalityRank(criticality *AssetCriticality) int {
+	if criticality == nil {
+		return 1
+	}
+	switch *criticality {
+	case CriticalityCritical:
+		return 4
+	case CriticalityHigh:
+		return 3
+	case CriticalityMedium:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// AppliesTo reports whether the constraint covers resource:action. Reads are never constrained.
+func (c AccessConstraint) AppliesTo(resource, action string) bool {
+	if c.Resource != resource || action == "read" {
+		return false
+	}
+	if len(c.Actions) == 0 {
+		return true
+	}
+	for _, a := range c.Actions {
+		if a == action || a == PermissionWildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// Permits reports whether an asset with the given attributes satisfies the constraint
+func (c AccessConstraint) Permits(environment Environment, criticality *AssetCriticality) bool {
+	if len(c.Environments) > 0 {
+		allowed := false
+		for _, env := range c.Environments {
+			if env == environment {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	if c.MaxCriticality != nil && CriticalityRank(criticality) > CriticalityRank(c.MaxCriticality) {
+		return false
+	}
+	return true
+}
+
+// Validate checks the constraint against the permission catalog and the asset enums
+func (c AccessConstraint) Validate() error {
+	if !ConstrainableResources[c.Resource] {
+		return fmt.Errorf("constraints are only supported on asset and vulnerability, not '%s'", c.Resource)
+	}
+	for _, action := range c.Actions {
+		if action == "read" {
+			return fmt.Errorf("constraints apply to modifying actions; '%s:read' cannot be constrained", c.Resource)
+		}
+		if action != PermissionWildcard && !IsCatalogPermission(c.Resource, action) {
+			return fmt.Errorf("unknown permission '%s:%s'", c.Resource, action)
+		}
+	}
+	if len(c.Environments) == 0 && c.MaxCriticality == nil {
+		return fmt.Errorf("a constraint on '%s' must set environments or max_criticality", c.Resource)
+	}
+	for _, env := range c.Environments {
+		switch env {
+		case EnvProduction, EnvStaging, EnvDevelopment, EnvTest:
+		default:
+			return fmt.Errorf("invalid environment '%s' in constraint", env)
+		}
+	}
+	if c.MaxCriticality != nil {
+		switch *c.MaxCriticality {
+		case CriticalityCritical, CriticalityHigh, CriticalityMedium, CriticalityLow:
+		default:
+			return fmt.Errorf("invalid max_criticality '%s' in constraint", *c.MaxCriticality)
+		}
+	}
+	return nil
+}
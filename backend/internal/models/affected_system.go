@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
 )
 
@@ -55,6 +56,7 @@ const (
 // AffectedSystem represents a system or asset that can be affected by vulnerabilities
 type AffectedSystem struct {
 	BaseModel
+	OrgID *uuid.UUID `gorm:"type:uuid;index" json:"org_id,omitempty"`
 
 	// Existing fields (from 002-vulnerability-management)
 	Hostname    string      `gorm:"type:varchar(255)" json:"hostname,omitempty"`
@@ -69,12 +71,33 @@ type AffectedSystem struct {
 	Status       AssetStatus       `gorm:"type:varchar(30);not null;default:ACTIVE" json:"status"`
 	OwnerID      *uuid.UUID        `gorm:"type:uuid" json:"owner_id,omitempty"`
 	Owner        *User             `gorm:"foreignKey:OwnerID;constraint:OnDelete:SET NULL" json:"owner,omitempty"`
+	OwnerTeamID  *uuid.UUID        `gorm:"type:uuid;index" json:"owner_team_id,omitempty"`
+	OwnerTeam    *Team             `gorm:"foreignKey:OwnerTeamID;constraint:OnDelete:SET NULL" json:"owner_team,omitempty"`
 	Department   string            `gorm:"type:varchar(100)" json:"department,omitempty"`
 	Location     string            `gorm:"type:varchar(255)" json:"location,omitempty"`
 	LastScanDate *time.Time        `gorm:"type:timestamp" json:"last_scan_date,omitempty"`
 
+	// Automatic criticality scoring; an override keeps a manually set criticality
+	CriticalityScore    *int `gorm:"type:integer" json:"criticality_score,omitempty"`
+	CriticalityOverride bool `gorm:"not null;default:false" json:"criticality_override"`
+
+	// Endpoint agent inventory (maintained by agent check-ins)
+	AgentID         string         `gorm:"type:varchar(100)" json:"agent_id,omitempty"`
+	OperatingSystem string         `gorm:"type:varchar(255)" json:"operating_system,omitempty"`
+	IPAddresses     pq.StringArray `gorm:"type:text[]" json:"ip_addresses,omitempty"`
+	LastSeenAt      *time.Time     `gorm:"type:timestamp" json:"last_seen_at,omitempty"`
+	AgentStale      bool           `gorm:"not null;default:false" json:"agent_stale"` // No check-in within the stale threshold
+
+	// Decommissioning: who retired the asset, and when it moves to the recycle bin
+	DecommissionedByID *uuid.UUID `gorm:"type:uuid" json:"decommissioned_by_id,omitempty"`
+	CleanupAt          *time.Time `gorm:"type:timestamp;index" json:"cleanup_at,omitempty"`
+
+	// Who moved the asset to the recycle bin
+	DeletedByID *uuid.UUID `gorm:"type:uuid" json:"deleted_by_id,omitempty"`
+
 	// Relationships
-	Tags []AssetTag `gorm:"foreignKey:AssetID" json:"tags,omitempty"`
+	Tags     []AssetTag     `gorm:"foreignKey:AssetID" json:"tags,omitempty"`
+	Packages []AssetPackage `gorm:"foreignKey:AssetID" json:"packages,omitempty"`
 }
 
 // TableName specifies the table name for AffectedSystem model
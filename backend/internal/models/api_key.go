@@ -33,20 +33,29 @@ type Scopes []string
 type APIKey struct {
 	ID                 uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	UserID             uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	OrgID              *uuid.UUID     `gorm:"type:uuid;index" json:"org_id,omitempty"`
 	Name               string         `gorm:"not null" json:"name"`
 	Type               APIKeyType     `gorm:"type:varchar(20);not null" json:"type"`
 	Status             APIKeyStatus   `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
 	KeyHash            string         `gorm:"type:text;not null;uniqueIndex" json:"-"`
 	KeyPrefix          string         `gorm:"type:varchar(20);not null" json:"key_prefix"`
+	SecondaryKeyHash   string         `gorm:"type:text" json:"-"` // previous secret of a rotated key, accepted until SecondaryExpiresAt
+	SecondaryExpiresAt *time.Time     `json:"secondary_expires_at,omitempty"`
+	RotatedAt          *time.Time     `json:"rotated_at,omitempty"`
 	Scopes             pq.StringArray `gorm:"type:text[];not null" json:"scopes"`
 	ExpiresAt          *time.Time     `json:"expires_at,omitempty"`
 	LastUsedAt         *time.Time     `json:"last_used_at,omitempty"`
+	LastUsedIP         string         `gorm:"type:varchar(45)" json:"last_used_ip,omitempty"`
 	RateLimitPerMinute int            `gorm:"default:60" json:"rate_limit_per_minute"`
+	AllowedCIDRs       pq.StringArray `gorm:"type:text[]" json:"allowed_cidrs"` // client networks the key may be used from; empty allows any
 	Description        string         `json:"description"`
 	CreatedAt          time.Time      `json:"created_at"`
 	UpdatedAt          time.Time      `json:"updated_at"`
 	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
 	User               *User          `gorm:"foreignKey:UserID" json:"user,omitempty"`
+
+	// AuthenticatedWithSecondary is set by validation when the request used the previous secret
+	AuthenticatedWithSecondary bool `gorm:"-" json:"-"`
 }
 
 // TableName specifies the table name for APIKey
@@ -65,15 +74,20 @@ func (a *APIKey) IsValid() bool {
 	return true
 }
 
-// HasScope checks if the API key has a specific scope
+// HasScope checks if the API key has a specific scope (directly or through a wildcard)
 func (a *APIKey) HasScope(scope string) bool {
-	// Check for wildcard permission
-	for _, s := range a.Scopes {
-		if s == "*:*" || s == scope {
-			return true
-		}
-	}
-	return false
+	return ScopeGranted(a.GetScopes(), scope)
+}
+
+// SupportsRotation reports whether the key type can hold two active secrets for rotation.
+// Service and MCP keys are used by unattended integrations that cannot swap secrets atomically.
+func (a *APIKey) SupportsRotation() bool {
+	return a.Type == APIKeyTypeService || a.Type == APIKeyTypeMCP
+}
+
+// HasActiveSecondary reports whether the previous secret of a rotated key is still accepted
+func (a *APIKey) HasActiveSecondary() bool {
+	return a.SecondaryKeyHash != "" && a.SecondaryExpiresAt != nil && time.Now().Before(*a.SecondaryExpiresAt)
 }
 
 // GetScopes returns the scopes as a string slice
@@ -0,0 +1,140 @@
+package models
+
+import "strings"
+
+// ScopeWildcard grants every scope to an API key
+const ScopeWildcard = "*:*"
+
+// APIKeyScope describes one scope an API key can be granted
+type APIKeyScope struct {
+	Scope       string `json:"scope"`
+	Description string `json:"description"`
+}
+
+// APIKeyScopeGroup groups the scopes of a resource
+type APIKeyScopeGroup struct {
+	Resource    string        `json:"resource"`
+	Description string        `json:"description"`
+	Scopes      []APIKeyScope `json:"scopes"`
+}
+
+// APIKeyScopeCatalog enumerates every scope that API keys can be granted. Scopes narrow what a
+// key may do on top of its owner's role permissions; RequireScope refuses scopes not listed here.
+// "<resource>:*" grants every scope of a resource and "*:*" grants all scopes.
+var APIKeyScopeCatalog = []APIKeyScopeGroup{
+	{Resource: "vulnerabilities", Description: "Vulnerabilities and their affected systems", Scopes: []APIKeyScope{
+		{Scope: "vulnerabilities:read", Description: "List and view vulnerabilities"},
+		{Scope: "vulnerabilities:stats", Description: "View vulnerability statistics"},
+		{Scope: "vulnerabilities:write", Description: "Create, update, assign and comment on vulnerabilities"},
+		{Scope: "vulnerabilities:delete", Description: "Delete vulnerabilities"},
+		{Scope: "vulnerabilities:import", Description: "Import scanner results"},
+	}},
+	{Resource: "findings", Description: "Vulnerability findings and risk acceptance", Scopes: []APIKeyScope{
+		{Scope: "findings:read", Description: "List and view findings, attachments and risk acceptance requests"},
+		{Scope: "findings:write", Description: "Update findings, comment, upload attachments and review risk acceptance"},
+	}},
+	{Resource: "assets", Description: "Assets", Scopes: []APIKeyScope{
+		{Scope: "assets:read", Description: "List and view assets"},
+		{Scope: "assets:write", Description: "Create and update assets"},
+		{Scope: "assets:delete", Description: "Delete assets"},
+	}},
+	{Resource: "agent", Description: "Endpoint agents", Scopes: []APIKeyScope{
+		{Scope: "agent:checkin", Description: "Report endpoint inventory and register assets through agent check-ins"},
+	}},
+	{Resource: "assessments", Description: "Assessments and assessment reports", Scopes: []APIKeyScope{
+		{Scope: "assessments:read", Description: "List and view assessments and their reports"},
+		{Scope: "assessments:write", Description: "Create and update assessments, link vulnerabilities and upload reports"},
+		{Scope: "assessments:delete", Description: "Delete assessments and assessment reports"},
+	}},
+	{Resource: "reports", Description: "Generated reports", Scopes: []APIKeyScope{
+		{Scope: "reports:read", Description: "Generate analyst, executive and audit reports"},
+		{Scope: "reports:export", Description: "Export reports as CSV"},
+	}},
+	{Resource: "integrations", Description: "Scanner and ticketing integrations", Scopes: []APIKeyScope{
+		{Scope: "integrations:read", Description: "View integration configurations and browse scanner data"},
+		{Scope: "integrations:write", Description: "Create, update, delete and test integration configurations"},
+	}},
+	{Resource: "rules", Description: "Automation rules applied to findings", Scopes: []APIKeyScope{
+		{Scope: "rules:read", Description: "List and view suppression, assignment, escalation, import and policy rules, and policy violations"},
+		{Scope: "rules:write", Description: "Create, update, delete and test suppression, assignment, escalation, import and policy rules"},
+	}},
+	{Resource: "admin", Description: "Administration endpoints", Scopes: []APIKeyScope{
+		{Scope: "admin:read", Description: "View users, roles, settings and maintenance status"},
+		{Scope: "admin:write", Description: "Manage users, roles, settings and maintenance tasks"},
+	}},
+	{Resource: "dashboard", Description: "Dashboards", Scopes: []APIKeyScope{
+		{Scope: "dashboard:read", Description: "List and view custom dashboards"},
+		{Scope: "dashboard:write", Description: "Create, update and delete custom dashboards"},
+		{Scope: "dashboard:wallboard", Description: "Read wallboard data for unattended displays"},
+	}},
+	{Resource: "profile", Description: "Profile of the key owner", Scopes: []APIKeyScope{
+		{Scope: "profile:read", Description: "View the profile, preferences, security overview, sessions and onboarding status"},
+		{Scope: "profile:write", Description: "Update the profile and preferences, revoke sessions, accept policies and skip onboarding steps"},
+	}},
+	{Resource: "notifications", Description: "Notifications of the key owner", Scopes: []APIKeyScope{
+		{Scope: "notifications:read", Description: "List notifications"},
+		{Scope: "notifications:write", Description: "Mark notifications as read"},
+	}},
+	{Resource: "saved_views", Description: "Saved list views", Scopes: []APIKeyScope{
+		{Scope: "saved_views:read", Description: "List and view saved views"},
+		{Scope: "saved_views:write", Description: "Create, update and delete saved views"},
+	}},
+	{Resource: "api_keys", Description: "API keys of the key owner", Scopes: []APIKeyScope{
+		{Scope: "api_keys:read", Description: "List and view API keys and their usage"},
+		{Scope: "api_keys:write", Description: "Create, rotate, restrict, revoke and delete API keys; new keys get at most the calling key's scopes"},
+	}},
+}
+
+// scopeIndex maps scope -> true for fast lookups
+var scopeIndex = func() map[string]bool {
+	index := make(map[string]bool)
+	for _, group := range APIKeyScopeCatalog {
+		for _, scope := range group.Scopes {
+			index[scope.Scope] = true
+		}
+	}
+	return index
+}()
+
+// scopeResources maps resource -> true for resource wildcards
+var scopeResources = func() map[string]bool {
+	index := make(map[string]bool, len(APIKeyScopeCatalog))
+	for _, group := range APIKeyScopeCatalog {
+		index[group.Resource] = true
+	}
+	return index
+}()
+
+// IsCatalogScope reports whether scope can be granted to an API key: a catalog scope, a
+// resource wildcard of a catalog resource, or the global wildcard.
+func IsCatalogScope(scope string) bool {
+	if scope == ScopeWildcard || scopeIndex[scope] {
+		return true
+	}
+	resource, action, ok := strings.Cut(scope, ":")
+	return ok && action == "*" && scopeResources[resource]
+}
+
+// ScopeGranted reports whether granted scopes cover the required scope, honouring wildcards
+func ScopeGranted(granted []string, required string) bool {
+	resource, _, _ := strings.Cut(required, ":")
+	for _, scope := range granted {
+		if scope == ScopeWildcard || scope == required || scope == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandScopes returns the catalog scopes covered by granted scopes, in catalog order
+func ExpandScopes(granted []string) []string {
+	expanded := []string{}
+	for _, group := range APIKeyScopeCatalog {
+		for _, scope := range group.Scopes {
+			if ScopeGranted(granted, scope.Scope) {
+				expanded = append(expanded, scope.Scope)
+			}
+		}
+	}
+	return expanded
+}
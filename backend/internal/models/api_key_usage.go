@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyUsage aggregates the requests made with an API key per day and endpoint
+type APIKeyUsage struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	APIKeyID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_api_key_usage_bucket,priority:1" json:"api_key_id"`
+	Day          time.Time `gorm:"type:date;not null;uniqueIndex:idx_api_key_usage_bucket,priority:2" json:"day"`
+	Method       string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_api_key_usage_bucket,priority:3" json:"method"`
+	Route        string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_api_key_usage_bucket,priority:4" json:"route"` // route pattern, e.g. /api/v1/assets/:id
+	RequestCount int64     `gorm:"not null;default:0" json:"request_count"`
+	ErrorCount   int64     `gorm:"not null;default:0" json:"error_count"`        // responses with status >= 400
+	RateLimited  int64     `gorm:"not null;default:0" json:"rate_limited_count"` // requests rejected by the key's rate limit
+	LastIP       string    `gorm:"type:varchar(45)" json:"last_ip"`
+	LastUsedAt   time.Time `gorm:"not null" json:"last_used_at"`
+}
+
+// TableName specifies the table name for APIKeyUsage
+func (APIKeyUsage) TableName() string {
+	return "api_key_usage"
+}
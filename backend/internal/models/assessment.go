@@ -34,6 +34,7 @@ const (
 // Assessment represents a security assessment or audit
 type Assessment struct {
 	BaseModel
+	OrgID                 *uuid.UUID       `gorm:"type:uuid;index" json:"org_id,omitempty"`
 	Name                  string           `gorm:"type:varchar(255);not null" json:"name"`
 	Description           string           `gorm:"type:text" json:"description,omitempty"`
 	AssessmentType        AssessmentType   `gorm:"type:varchar(50);not null" json:"assessment_type"`
@@ -51,6 +52,7 @@ type Assessment struct {
 	CreatedBy             *User            `gorm:"foreignKey:CreatedByID;constraint:OnDelete:RESTRICT" json:"created_by,omitempty"`
 	Vulnerabilities       []Vulnerability  `gorm:"many2many:assessment_vulnerabilities" json:"vulnerabilities,omitempty"`
 	Assets                []AffectedSystem `gorm:"many2many:assessment_assets" json:"assets,omitempty"`
+	AssetGroups           []AssetGroup     `gorm:"many2many:assessment_asset_groups" json:"asset_groups,omitempty"`
 }
 
 // TableName specifies the table name for Assessment model
@@ -83,3 +85,16 @@ type AssessmentAsset struct {
 func (AssessmentAsset) TableName() string {
 	return "assessment_assets"
 }
+
+// AssessmentAssetGroup represents the junction table between assessments and asset groups.
+// Every current member of a linked group is in the assessment's scope.
+type AssessmentAssetGroup struct {
+	AssessmentID string    `gorm:"type:uuid;primaryKey;not null" json:"assessment_id"`
+	AssetGroupID string    `gorm:"type:uuid;primaryKey;not null" json:"asset_group_id"`
+	CreatedAt    time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for AssessmentAssetGroup model
+func (AssessmentAssetGroup) TableName() string {
+	return "assessment_asset_groups"
+}
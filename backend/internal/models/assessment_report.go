@@ -22,11 +22,18 @@ type AssessmentReport struct {
 	FileSize     int64  `gorm:"not null" json:"file_size"` // in bytes
 
 	// Storage information
-	StoragePath string `gorm:"type:varchar(500);not null" json:"storage_path"` // relative path in storage
+	StoragePath    string `gorm:"type:varchar(500);not null" json:"storage_path"`                 // relative path in storage
+	StorageBackend string `gorm:"type:varchar(20);not null;default:local" json:"storage_backend"` // storage driver holding the file
 
 	// Report metadata
 	Title       string `gorm:"type:varchar(255);not null" json:"title"` // e.g., "Main Report", "Executive Summary"
 	Description string `gorm:"type:text" json:"description,omitempty"`
+	Generated   bool   `gorm:"not null;default:false" json:"generated"` // Rendered by the server rather than uploaded
+
+	// Detached Ed25519 signature of generated reports, checked by POST /reports/verify
+	SigningKeyID string     `gorm:"type:varchar(16)" json:"signing_key_id,omitempty"`
+	SignedAt     *time.Time `gorm:"type:timestamp" json:"signed_at,omitempty"`
+	Signature    string     `gorm:"type:text" json:"-"` // Signature document (JSON)
 
 	// Version control
 	Version  int  `gorm:"not null;default:1" json:"version"`          // Version number for this title
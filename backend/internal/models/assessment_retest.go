@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// RetestStatus is the state of one retest round of an assessment finding
+type RetestStatus string
+
+const (
+	RetestReady          RetestStatus = "READY_FOR_RETEST"
+	RetestFixed          RetestStatus = "FIXED"
+	RetestNotFixed       RetestStatus = "NOT_FIXED"
+	RetestPartiallyFixed RetestStatus = "PARTIALLY_FIXED"
+)
+
+// IsOutcome reports whether the status records a completed retest
+func (s RetestStatus) IsOutcome() bool {
+	return s == RetestFixed || s == RetestNotFixed || s == RetestPartiallyFixed
+}
+
+// AssessmentRetest is one retest round of a vulnerability linked to an assessment. A finding is
+// marked ready for retest, optionally assigned to a retester, and completed with an outcome and
+// evidence. A finding may go through several rounds; the latest is its current retest status.
+type AssessmentRetest struct {
+	BaseModel
+	AssessmentID    uuid.UUID      `gorm:"type:uuid;not null;index:idx_retest_finding" json:"assessment_id"`
+	VulnerabilityID uuid.UUID      `gorm:"type:uuid;not null;index:idx_retest_finding" json:"vulnerability_id"`
+	Vulnerability   *Vulnerability `gorm:"foreignKey:VulnerabilityID;constraint:OnDelete:CASCADE" json:"vulnerability,omitempty"`
+	Round           int            `gorm:"not null;default:1" json:"round"`
+	Status          RetestStatus   `gorm:"type:varchar(20);not null;default:READY_FOR_RETEST;index" json:"status"`
+
+	// Request tracking
+	RequestedByID uuid.UUID `gorm:"type:uuid;not null" json:"requested_by_id"`
+	RequestedBy   *User     `gorm:"foreignKey:RequestedByID;constraint:OnDelete:RESTRICT" json:"requested_by,omitempty"`
+	RequestNotes  string    `gorm:"type:text" json:"request_notes,omitempty"`
+
+	// Retester assignment
+	RetesterID *uuid.UUID `gorm:"type:uuid;index" json:"retester_id,omitempty"`
+	Retester   *User      `gorm:"foreignKey:RetesterID;constraint:OnDelete:SET NULL" json:"retester,omitempty"`
+
+	// Outcome, set when the retest is completed
+	RetestedByID          *uuid.UUID     `gorm:"type:uuid" json:"retested_by_id,omitempty"`
+	RetestedBy            *User          `gorm:"foreignKey:RetestedByID;constraint:OnDelete:SET NULL" json:"retested_by,omitempty"`
+	RetestedAt            *time.Time     `gorm:"type:timestamp" json:"retested_at,omitempty"`
+	OutcomeNotes          string         `gorm:"type:text" json:"outcome_notes,omitempty"`
+	Evidence              string         `gorm:"type:text" json:"evidence,omitempty"`                  // What was tested and observed
+	EvidenceAttachmentIDs pq.StringArray `gorm:"type:text[]" json:"evidence_attachment_ids,omitempty"` // Vulnerability attachments backing the outcome
+}
+
+// TableName specifies the table name for AssessmentRetest model
+func (AssessmentRetest) TableName() string {
+	return "assessment_retests"
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// AssetGroupMemberSource records how an asset became a member of a group
+type AssetGroupMemberSource string
+
+const (
+	AssetGroupMemberStatic  AssetGroupMemberSource = "static"  // Added explicitly
+	AssetGroupMemberDynamic AssetGroupMemberSource = "dynamic" // Matched by the group's rules
+)
+
+// AssetGroup is a named set of assets made of static members plus the assets matching its
+// dynamic rules. Within a rule any listed value matches (OR); populated rules must all
+// match (AND). A group without rules has static members only.
+type AssetGroup struct {
+	BaseModel
+	OrgID       *uuid.UUID `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Name        string     `gorm:"type:varchar(255);not null" json:"name"`
+	Description string     `gorm:"type:text" json:"description,omitempty"`
+
+	// Dynamic membership rules
+	RuleTags         pq.StringArray `gorm:"type:text[]" json:"rule_tags"`
+	RuleEnvironments pq.StringArray `gorm:"type:text[]" json:"rule_environments"`
+	RuleSystemTypes  pq.StringArray `gorm:"type:text[]" json:"rule_system_types"`
+	RuleCIDRs        pq.StringArray `gorm:"type:text[]" json:"rule_cidrs"`
+
+	// Set when the dynamic members were last recomputed
+	MembershipComputedAt *time.Time `gorm:"type:timestamp" json:"membership_computed_at,omitempty"`
+	MemberCount          int64      `gorm:"-" json:"member_count"`
+
+	CreatedByID uuid.UUID          `gorm:"type:uuid;not null" json:"created_by_id"`
+	CreatedBy   *User              `gorm:"foreignKey:CreatedByID;constraint:OnDelete:RESTRICT" json:"created_by,omitempty"`
+	Members     []AssetGroupMember `gorm:"foreignKey:GroupID" json:"members,omitempty"`
+}
+
+// TableName specifies the table name for AssetGroup model
+func (AssetGroup) TableName() string {
+	return "asset_groups"
+}
+
+// HasRules reports whether the group has any dynamic membership rule
+func (g *AssetGroup) HasRules() bool {
+	return len(g.RuleTags) > 0 || len(g.RuleEnvironments) > 0 || len(g.RuleSystemTypes) > 0 || len(g.RuleCIDRs) > 0
+}
+
+// AssetGroupMember links an asset to a group
+type AssetGroupMember struct {
+	GroupID   uuid.UUID              `gorm:"type:uuid;primaryKey;not null" json:"group_id"`
+	AssetID   uuid.UUID              `gorm:"type:uuid;primaryKey;not null;index:idx_asset_group_member_asset" json:"asset_id"`
+	Source    AssetGroupMemberSource `gorm:"type:varchar(10);primaryKey;not null" json:"source"`
+	Asset     *AffectedSystem        `gorm:"foreignKey:AssetID;constraint:OnDelete:CASCADE" json:"asset,omitempty"`
+	CreatedAt time.Time              `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for AssetGroupMember model
+func (AssetGroupMember) TableName() string {
+	return "asset_group_members"
+}
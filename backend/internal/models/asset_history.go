@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AssetHistoryAction identifies the kind of change recorded in an asset's history
+type AssetHistoryAction string
+
+const (
+	AssetHistoryUpdated       AssetHistoryAction = "UPDATED"
+	AssetHistoryStatusChanged AssetHistoryAction = "STATUS_CHANGED"
+	AssetHistoryTagAdded      AssetHistoryAction = "TAG_ADDED"
+	AssetHistoryTagRemoved    AssetHistoryAction = "TAG_REMOVED"
+	AssetHistoryDeleted       AssetHistoryAction = "DELETED"
+	AssetHistoryRestored      AssetHistoryAction = "RESTORED"
+)
+
+// AssetHistory records one change to an asset for its lifecycle timeline and audit reports.
+// Field updates produce one row per changed field; status changes carry the caller's notes,
+// which for DECOMMISSIONED is the decommission reason.
+type AssetHistory struct {
+	ID          uuid.UUID          `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	AssetID     uuid.UUID          `gorm:"type:uuid;not null;index:idx_asset_history_asset" json:"asset_id"`
+	Action      AssetHistoryAction `gorm:"type:varchar(30);not null" json:"action"`
+	Field       string             `gorm:"type:varchar(50)" json:"field,omitempty"`
+	OldValue    string             `gorm:"type:text" json:"old_value,omitempty"`
+	NewValue    string             `gorm:"type:text" json:"new_value,omitempty"`
+	Notes       string             `gorm:"type:text" json:"notes,omitempty"`
+	ChangedByID *uuid.UUID         `gorm:"type:uuid" json:"changed_by_id,omitempty"` // Nil for system changes
+	ChangedBy   *User              `gorm:"foreignKey:ChangedByID;constraint:OnDelete:SET NULL" json:"changed_by,omitempty"`
+	ChangedAt   time.Time          `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_asset_history_asset" json:"changed_at"`
+}
+
+// TableName specifies the table name for AssetHistory model
+func (AssetHistory) TableName() string {
+	return "asset_history"
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AssetPackage is a software package installed on an asset, as last reported by its agent.
+// Each check-in replaces the asset's package list.
+type AssetPackage struct {
+	AssetID      uuid.UUID `gorm:"type:uuid;primaryKey;not null" json:"asset_id"`
+	Name         string    `gorm:"type:varchar(255);primaryKey;not null;index:idx_asset_packages_name" json:"name"`
+	Version      string    `gorm:"type:varchar(100);primaryKey;not null" json:"version"`
+	Architecture string    `gorm:"type:varchar(50)" json:"architecture,omitempty"`
+	ReportedAt   time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"reported_at"`
+}
+
+// TableName specifies the table name for AssetPackage model
+func (AssetPackage) TableName() string {
+	return "asset_packages"
+}
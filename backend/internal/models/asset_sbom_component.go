@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// AssetSBOMComponent is a software component listed in the SBOM last uploaded for an asset.
+// Each upload replaces the asset's component list.
+type AssetSBOMComponent struct {
+	ID       uuid.UUID       `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	AssetID  uuid.UUID       `gorm:"type:uuid;not null;index" json:"asset_id"`
+	Asset    *AffectedSystem `gorm:"foreignKey:AssetID;constraint:OnDelete:CASCADE" json:"-"`
+	BOMRef   string          `gorm:"type:text" json:"bom_ref,omitempty"` // CycloneDX bom-ref or SPDX SPDXID
+	Type     string          `gorm:"type:varchar(50)" json:"type,omitempty"`
+	Group    string          `gorm:"type:varchar(255)" json:"group,omitempty"`
+	Name     string          `gorm:"type:varchar(255);not null;index:idx_asset_sbom_components_name" json:"name"`
+	Version  string          `gorm:"type:varchar(255)" json:"version,omitempty"`
+	PURL     string          `gorm:"column:purl;type:text;index" json:"purl,omitempty"` // Package URL
+	CPE      string          `gorm:"column:cpe;type:text" json:"cpe,omitempty"`
+	Licenses pq.StringArray  `gorm:"type:text[]" json:"licenses,omitempty"`
+	Supplier string          `gorm:"type:varchar(255)" json:"supplier,omitempty"`
+
+	// Known advisories that affect this component version: OSV IDs and the CVEs they alias
+	Advisories pq.StringArray `gorm:"type:text[]" json:"advisories,omitempty"`
+
+	SBOMFormat string    `gorm:"column:sbom_format;type:varchar(20);not null" json:"sbom_format"` // cyclonedx or spdx
+	ImportedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"imported_at"`
+}
+
+// TableName specifies the table name for AssetSBOMComponent model
+func (AssetSBOMComponent) TableName() string {
+	return "asset_sbom_components"
+}
@@ -24,19 +24,32 @@ func (AssetTag) TableName() string {
 
 // BeforeCreate hook to normalize and validate tag
 func (at *AssetTag) BeforeCreate(tx *gorm.DB) error {
+	tag, err := NormalizeTag(at.Tag)
+	if err != nil {
+		return err
+	}
+	at.Tag = tag
+	return nil
+}
+
+// tagPattern is the allowed format of a normalized tag
+var tagPattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// NormalizeTag lowercases and trims a tag and validates it; asset and vulnerability tags
+// share the same format
+func NormalizeTag(tag string) (string, error) {
 	// Normalize tag to lowercase
-	at.Tag = strings.ToLower(strings.TrimSpace(at.Tag))
+	tag = strings.ToLower(strings.TrimSpace(tag))
 
 	// Validate tag length
-	if len(at.Tag) < 1 || len(at.Tag) > 50 {
-		return errors.New("tag must be 1-50 characters")
+	if len(tag) < 1 || len(tag) > 50 {
+		return "", errors.New("tag must be 1-50 characters")
 	}
 
 	// Validate tag format (alphanumeric + dash/underscore)
-	matched, _ := regexp.MatchString(`^[a-z0-9_-]+$`, at.Tag)
-	if !matched {
-		return errors.New("tag must contain only lowercase letters, numbers, dash, and underscore")
+	if !tagPattern.MatchString(tag) {
+		return "", errors.New("tag must contain only lowercase letters, numbers, dash, and underscore")
 	}
 
-	return nil
+	return tag, nil
 }
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AssignmentRule routes new vulnerabilities to an analyst or team when they are created or
+// imported. Rules are evaluated by ascending position and the first rule whose populated
+// criteria all match (AND) assigns the vulnerability. Asset criteria must all match the same
+// affected asset.
+type AssignmentRule struct {
+	BaseModel
+	OrgID       *uuid.UUID `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Name        string     `gorm:"type:varchar(255);not null" json:"name"`
+	Description string     `gorm:"type:text" json:"description,omitempty"`
+	Enabled     bool       `gorm:"not null;default:true;index" json:"enabled"`
+	Position    int        `gorm:"not null;default:0;index" json:"position"` // Evaluation order, lowest first
+
+	// Match criteria
+	AssetOwnerID *uuid.UUID  `gorm:"type:uuid" json:"asset_owner_id,omitempty"`
+	AssetOwner   *User       `gorm:"foreignKey:AssetOwnerID;constraint:OnDelete:CASCADE" json:"asset_owner,omitempty"`
+	AssetTag     string      `gorm:"type:varchar(50)" json:"asset_tag,omitempty"`
+	Environment  Environment `gorm:"type:varchar(50)" json:"environment,omitempty"`
+	CVEPattern   string      `gorm:"type:varchar(100)" json:"cve_pattern,omitempty"` // Glob matched against the CVE ID, e.g. CVE-2024-*
+
+	// Assignment
+	AssignToID       *uuid.UUID `gorm:"type:uuid" json:"assign_to_id,omitempty"`
+	AssignTo         *User      `gorm:"foreignKey:AssignToID;constraint:OnDelete:CASCADE" json:"assign_to,omitempty"`
+	AssignTeamID     *uuid.UUID `gorm:"type:uuid" json:"assign_team_id,omitempty"`
+	AssignTeam       *Team      `gorm:"foreignKey:AssignTeamID;constraint:OnDelete:CASCADE" json:"assign_team,omitempty"`
+	AssignAssetOwner bool       `gorm:"not null;default:false" json:"assign_asset_owner"` // Assign to the owner and owner team of the matched asset
+
+	// Hit tracking
+	HitCount  int64      `gorm:"not null;default:0" json:"hit_count"`
+	LastHitAt *time.Time `gorm:"type:timestamp" json:"last_hit_at,omitempty"`
+
+	CreatedByID uuid.UUID `gorm:"type:uuid;not null" json:"created_by_id"`
+	CreatedBy   *User     `gorm:"foreignKey:CreatedByID;constraint:OnDelete:RESTRICT" json:"created_by,omitempty"`
+}
+
+// TableName specifies the table name for AssignmentRule model
+func (AssignmentRule) TableName() string {
+	return "assignment_rules"
+}
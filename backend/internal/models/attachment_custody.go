@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AttachmentCustodyAction identifies an event in a finding attachment's chain of custody
+type AttachmentCustodyAction string
+
+const (
+	AttachmentCustodyUploaded AttachmentCustodyAction = "UPLOADED"
+	AttachmentCustodyVerified AttachmentCustodyAction = "VERIFIED" // Stored file matched its recorded hash
+	AttachmentCustodyModified AttachmentCustodyAction = "MODIFIED" // Stored file no longer matches its recorded hash
+	AttachmentCustodyMissing  AttachmentCustodyAction = "MISSING"  // Stored file could not be read
+	AttachmentCustodyDeleted  AttachmentCustodyAction = "DELETED"
+)
+
+// AttachmentCustodyEvent records one event in a finding attachment's chain of custody. Events
+// are append-only and not tied to the attachment row by a foreign key, so the chain outlives
+// the attachment itself.
+type AttachmentCustodyEvent struct {
+	ID           uuid.UUID               `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	AttachmentID uuid.UUID               `gorm:"type:uuid;not null;index:idx_custody_attachment" json:"attachment_id"`
+	FindingID    uuid.UUID               `gorm:"type:uuid;not null;index" json:"finding_id"`
+	Action       AttachmentCustodyAction `gorm:"type:varchar(20);not null" json:"action"`
+	SHA256       string                  `gorm:"type:varchar(64)" json:"sha256,omitempty"` // Hash observed at the time of the event
+	Notes        string                  `gorm:"type:text" json:"notes,omitempty"`
+	ActorID      *uuid.UUID              `gorm:"type:uuid" json:"actor_id,omitempty"` // Nil for system events
+	Actor        *User                   `gorm:"foreignKey:ActorID;constraint:OnDelete:SET NULL" json:"actor,omitempty"`
+	CreatedAt    time.Time               `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_custody_attachment" json:"created_at"`
+}
+
+// TableName specifies the table name for AttachmentCustodyEvent model
+func (AttachmentCustodyEvent) TableName() string {
+	return "attachment_custody_events"
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditorShareToken grants an external auditor read-only access to one assessment's reports,
+// linked findings and evidence through the auditor portal, without a user account. Only a hash
+// of the token is stored; the token itself is shown once, when it is issued.
+type AuditorShareToken struct {
+	BaseModel
+	OrgID          *uuid.UUID  `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	AssessmentID   uuid.UUID   `gorm:"type:uuid;not null;index" json:"assessment_id"`
+	Assessment     *Assessment `gorm:"foreignKey:AssessmentID;constraint:OnDelete:CASCADE" json:"assessment,omitempty"`
+	AuditorName    string      `gorm:"type:varchar(255);not null" json:"auditor_name"`
+	AuditorEmail   string      `gorm:"type:varchar(320)" json:"auditor_email,omitempty"`
+	TokenHash      string      `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	TokenPrefix    string      `gorm:"type:varchar(20);not null" json:"token_prefix"` // Leading characters of the token, to tell tokens apart
+	ExpiresAt      time.Time   `gorm:"not null;index" json:"expires_at"`
+	RevokedAt      *time.Time  `gorm:"type:timestamp" json:"revoked_at,omitempty"`
+	RevokedByID    *uuid.UUID  `gorm:"type:uuid" json:"revoked_by_id,omitempty"`
+	CreatedByID    uuid.UUID   `gorm:"type:uuid;not null" json:"created_by_id"`
+	CreatedBy      *User       `gorm:"foreignKey:CreatedByID;constraint:OnDelete:RESTRICT" json:"created_by,omitempty"`
+	LastAccessedAt *time.Time  `gorm:"type:timestamp" json:"last_accessed_at,omitempty"`
+	AccessCount    int         `gorm:"not null;default:0" json:"access_count"`
+}
+
+// TableName specifies the table name for AuditorShareToken model
+func (AuditorShareToken) TableName() string {
+	return "auditor_share_tokens"
+}
+
+// IsActive reports whether the token can still be used
+func (t *AuditorShareToken) IsActive() bool {
+	return t.RevokedAt == nil && time.Now().Before(t.ExpiresAt)
+}
+
+// Resources of the auditor portal recorded in the access log
+const (
+	AuditorResourceAssessment = "assessment"
+	AuditorResourceReport     = "report"
+	AuditorResourceEvidence   = "evidence"
+)
+
+// AuditorShareAccess records one request made with an auditor share token
+type AuditorShareAccess struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	TokenID      uuid.UUID  `gorm:"type:uuid;not null;index:idx_auditor_access_token" json:"token_id"`
+	AssessmentID uuid.UUID  `gorm:"type:uuid;not null" json:"assessment_id"`
+	Resource     string     `gorm:"type:varchar(20);not null" json:"resource"`
+	ResourceID   *uuid.UUID `gorm:"type:uuid" json:"resource_id,omitempty"`
+	IPAddress    string     `gorm:"type:varchar(45)" json:"ip_address,omitempty"`
+	UserAgent    string     `gorm:"type:varchar(500)" json:"user_agent,omitempty"`
+	AccessedAt   time.Time  `gorm:"not null;index:idx_auditor_access_token" json:"accessed_at"`
+}
+
+// TableName specifies the table name for AuditorShareAccess model
+func (AuditorShareAccess) TableName() string {
+	return "auditor_share_accesses"
+}
@@ -19,10 +19,25 @@ const (
 	EventTypeTwoFactorEnabled     EventType = "two_factor_enabled"
 	EventTypeTwoFactorDisabled    EventType = "two_factor_disabled"
 	EventTypeTwoFactorVerified    EventType = "two_factor_verified"
+	EventTypeBackupCodeUsed       EventType = "two_factor_backup_code_used"
+	EventTypeBackupCodesRenewed   EventType = "two_factor_backup_codes_regenerated"
 	EventTypeProfileUpdate        EventType = "profile_update"
 	EventTypeSessionRevoked       EventType = "session_revoked"
+	EventTypeRefreshTokenReuse    EventType = "refresh_token_reuse"
+	EventTypeImpersonationStarted EventType = "impersonation_started"
+	EventTypeImpersonationEnded   EventType = "impersonation_ended"
+	EventTypeImpersonatedRequest  EventType = "impersonated_request"
 	EventTypeAccountLocked        EventType = "account_locked"
 	EventTypeAccountUnlocked      EventType = "account_unlocked"
+	EventTypeBackupStarted        EventType = "backup_started"
+	EventTypeBackupCompleted      EventType = "backup_completed"
+	EventTypeBackupFailed         EventType = "backup_failed"
+	EventTypeRestoreStarted       EventType = "restore_started"
+	EventTypeRestoreCompleted     EventType = "restore_completed"
+	EventTypeRestoreFailed        EventType = "restore_failed"
+	EventTypeIPBlocked            EventType = "ip_blocked"
+	EventTypePolicyAccepted       EventType = "policy_accepted"
+	EventTypeOnboardingReset      EventType = "onboarding_reset"
 )
 
 // AuthEvent represents an authentication or security event
@@ -37,6 +52,8 @@ type AuthEvent struct {
 	FailReason string     `gorm:"type:text" json:"fail_reason,omitempty"`
 	Metadata   string     `gorm:"type:jsonb" json:"metadata,omitempty"` // Additional event data as JSON
 	RequestID  string     `gorm:"type:varchar(100);index" json:"request_id,omitempty"`
+	// ImpersonatorID is the administrator who acted as UserID, for events during impersonation
+	ImpersonatorID *uuid.UUID `gorm:"type:uuid;index" json:"impersonator_id,omitempty"`
 }
 
 // TableName specifies the table name for AuthEvent model
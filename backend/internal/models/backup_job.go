@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BackupOperation is what a backup job does
+type BackupOperation string
+
+const (
+	BackupOperationBackup  BackupOperation = "backup"
+	BackupOperationRestore BackupOperation = "restore"
+)
+
+// BackupJobStatus is the state of a backup job
+type BackupJobStatus string
+
+const (
+	BackupJobRunning   BackupJobStatus = "running"
+	BackupJobCompleted BackupJobStatus = "completed"
+	BackupJobFailed    BackupJobStatus = "failed"
+)
+
+// BackupJob tracks a logical backup of the application data (a pg_dump archive plus a manifest
+// of the stored attachments and reports) or the restore of one into an empty database. A
+// completed backup job is an available backup.
+type BackupJob struct {
+	BaseModel
+	Operation       BackupOperation `gorm:"type:varchar(20);not null;index" json:"operation"`
+	Status          BackupJobStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	Phase           string          `gorm:"type:varchar(50)" json:"phase"`              // Current step, e.g. dumping or uploading
+	Progress        int             `gorm:"not null;default:0" json:"progress"`         // Percent complete
+	BackupID        *uuid.UUID      `gorm:"type:uuid;index" json:"backup_id,omitempty"` // Backup being restored
+	StorageDriver   string          `gorm:"type:varchar(20)" json:"storage_driver"`
+	Location        string          `gorm:"type:varchar(500)" json:"location"` // Key prefix of the backup files
+	TargetDatabase  string          `gorm:"type:varchar(63)" json:"target_database,omitempty"`
+	DumpBytes       int64           `gorm:"not null;default:0" json:"dump_bytes"`
+	AttachmentCount int             `gorm:"not null;default:0" json:"attachment_count"` // Files listed in the attachment manifest
+	AttachmentBytes int64           `gorm:"not null;default:0" json:"attachment_bytes"`
+	Error           string          `gorm:"type:text" json:"error,omitempty"`
+	CreatedByID     *uuid.UUID      `gorm:"type:uuid" json:"created_by_id,omitempty"`
+	StartedAt       time.Time       `gorm:"not null" json:"started_at"`
+	CompletedAt     *time.Time      `json:"completed_at,omitempty"`
+}
+
+// TableName specifies the table name for BackupJob model
+func (BackupJob) TableName() string {
+	return "backup_jobs"
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BusinessServiceTier ranks an application by how critical it is to the business
+type BusinessServiceTier string
+
+const (
+	BusinessTier1 BusinessServiceTier = "TIER_1" // Mission critical
+	BusinessTier2 BusinessServiceTier = "TIER_2" // Business critical
+	BusinessTier3 BusinessServiceTier = "TIER_3" // Business operational
+	BusinessTier4 BusinessServiceTier = "TIER_4" // Administrative
+)
+
+// BusinessService is an application or business capability and the assets that run it.
+// Vulnerability posture is rolled up per service for executive reporting, and the tier
+// feeds the criticality score of its assets.
+type BusinessService struct {
+	BaseModel
+	OrgID       *uuid.UUID          `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Name        string              `gorm:"type:varchar(255);not null" json:"name"`
+	Description string              `gorm:"type:text" json:"description,omitempty"`
+	Tier        BusinessServiceTier `gorm:"type:varchar(10);not null;default:TIER_3" json:"tier"`
+
+	// Ownership
+	OwnerID     *uuid.UUID `gorm:"type:uuid" json:"owner_id,omitempty"`
+	Owner       *User      `gorm:"foreignKey:OwnerID;constraint:OnDelete:SET NULL" json:"owner,omitempty"`
+	OwnerTeamID *uuid.UUID `gorm:"type:uuid" json:"owner_team_id,omitempty"`
+	OwnerTeam   *Team      `gorm:"foreignKey:OwnerTeamID;constraint:OnDelete:SET NULL" json:"owner_team,omitempty"`
+
+	AssetCount int64 `gorm:"-" json:"asset_count"`
+
+	CreatedByID uuid.UUID `gorm:"type:uuid;not null" json:"created_by_id"`
+	CreatedBy   *User     `gorm:"foreignKey:CreatedByID;constraint:OnDelete:RESTRICT" json:"created_by,omitempty"`
+}
+
+// TableName specifies the table name for BusinessService model
+func (BusinessService) TableName() string {
+	return "business_services"
+}
+
+// BusinessServiceAsset links an asset to a business service; an asset can support several
+type BusinessServiceAsset struct {
+	ServiceID uuid.UUID       `gorm:"type:uuid;primaryKey;not null" json:"service_id"`
+	AssetID   uuid.UUID       `gorm:"type:uuid;primaryKey;not null;index:idx_business_service_asset_asset" json:"asset_id"`
+	Asset     *AffectedSystem `gorm:"foreignKey:AssetID;constraint:OnDelete:CASCADE" json:"asset,omitempty"`
+	CreatedAt time.Time       `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for BusinessServiceAsset model
+func (BusinessServiceAsset) TableName() string {
+	return "business_service_assets"
+}
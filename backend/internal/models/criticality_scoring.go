@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CriticalityThresholds are the minimum scores for each criticality; lower scores are LOW
+type CriticalityThresholds struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+}
+
+// Criticality returns the criticality of a score
+func (t CriticalityThresholds) Criticality(score int) AssetCriticality {
+	switch {
+	case score >= t.Critical:
+		return CriticalityCritical
+	case score >= t.High:
+		return CriticalityHigh
+	case score >= t.Medium:
+		return CriticalityMedium
+	default:
+		return CriticalityLow
+	}
+}
+
+// CriticalityWeights are the points each asset attribute contributes to its criticality score
+type CriticalityWeights struct {
+	Environments  map[string]int        `json:"environments"`   // Keyed by environment
+	Tags          map[string]int        `json:"tags"`           // Keyed by tag; every matching tag counts
+	PublicIP      int                   `json:"public_ip"`      // Asset has a publicly routable address
+	BusinessTiers map[string]int        `json:"business_tiers"` // Keyed by tier; only the asset's highest-weighted business service counts
+	Thresholds    CriticalityThresholds `json:"thresholds"`
+}
+
+// DefaultCriticalityWeights are the weights used until an organization configures its own
+func DefaultCriticalityWeights() CriticalityWeights {
+	return CriticalityWeights{
+		Environments: map[string]int{
+			string(EnvProduction):  40,
+			string(EnvStaging):     20,
+			string(EnvDevelopment): 10,
+			string(EnvTest):        0,
+		},
+		Tags: map[string]int{
+			"pci":             25,
+			"pii":             25,
+			"crown-jewel":     40,
+			"internet-facing": 20,
+		},
+		PublicIP: 25,
+		BusinessTiers: map[string]int{
+			string(BusinessTier1): 40,
+			string(BusinessTier2): 25,
+			string(BusinessTier3): 10,
+			string(BusinessTier4): 0,
+		},
+		Thresholds: CriticalityThresholds{Critical: 80, High: 55, Medium: 30},
+	}
+}
+
+// CriticalityScoringProfile configures automatic criticality scoring for an organization.
+// Scoring is off until enabled; assets with a criticality override are never rescored.
+type CriticalityScoringProfile struct {
+	BaseModel
+	OrgID       *uuid.UUID         `gorm:"type:uuid;uniqueIndex" json:"org_id,omitempty"`
+	Enabled     bool               `gorm:"not null;default:false" json:"enabled"`
+	Weights     string             `gorm:"type:jsonb;not null;default:'{}'" json:"-"`
+	Config      CriticalityWeights `gorm:"-" json:"weights"`
+	UpdatedByID *uuid.UUID         `gorm:"type:uuid" json:"updated_by_id,omitempty"`
+	UpdatedBy   *User              `gorm:"foreignKey:UpdatedByID;constraint:OnDelete:SET NULL" json:"updated_by,omitempty"`
+}
+
+// TableName specifies the table name for CriticalityScoringProfile model
+func (CriticalityScoringProfile) TableName() string {
+	return "criticality_scoring_profiles"
+}
+
+// BeforeSave serializes the weights into the JSONB column
+func (p *CriticalityScoringProfile) BeforeSave(tx *gorm.DB) error {
+	data, err := json.Marshal(p.Config)
+	if err != nil {
+		return err
+	}
+	p.Weights = string(data)
+	return nil
+}
+
+// AfterFind parses the JSONB column into weights; a profile without weights uses the defaults
+func (p *CriticalityScoringProfile) AfterFind(tx *gorm.DB) error {
+	if p.Weights == "" || p.Weights == "{}" {
+		p.Config = DefaultCriticalityWeights()
+		return nil
+	}
+	p.Config = CriticalityWeights{}
+	return json.Unmarshal([]byte(p.Weights), &p.Config)
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DashboardWidgetType identifies the dataset a dashboard widget displays
+type DashboardWidgetType string
+
+const (
+	WidgetSeverityDonut  DashboardWidgetType = "severity_donut"   // Open vulnerabilities by severity
+	WidgetTrendLine      DashboardWidgetType = "trend_line"       // Daily metric snapshots
+	WidgetTopRiskyAssets DashboardWidgetType = "top_risky_assets" // Assets ranked by weighted open vulnerabilities
+	WidgetSLAStatus      DashboardWidgetType = "sla_status"       // Within-SLA, due-soon and breached counts per severity
+)
+
+// DashboardWidgetOptions tunes a widget's dataset; options a widget type does not use are ignored
+type DashboardWidgetOptions struct {
+	Days        int         `json:"days,omitempty"`        // trend_line: number of days
+	Limit       int         `json:"limit,omitempty"`       // top_risky_assets: number of assets
+	Environment Environment `json:"environment,omitempty"` // Restrict to vulnerabilities affecting assets in this environment
+}
+
+// DashboardWidgetLayout positions a widget on the dashboard grid
+type DashboardWidgetLayout struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"w"`
+	Height int `json:"h"`
+}
+
+// DashboardWidget is one widget definition of a dashboard
+type DashboardWidget struct {
+	ID      string                 `json:"id"`
+	Type    DashboardWidgetType    `json:"type"`
+	Title   string                 `json:"title,omitempty"`
+	Options DashboardWidgetOptions `json:"options"`
+	Layout  *DashboardWidgetLayout `json:"layout,omitempty"`
+}
+
+// Dashboard is a user-composed set of widgets. Widget definitions are stored as JSON.
+type Dashboard struct {
+	BaseModel
+	OrgID       *uuid.UUID        `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Name        string            `gorm:"type:varchar(255);not null" json:"name"`
+	Description string            `gorm:"type:text" json:"description,omitempty"`
+	Widgets     string            `gorm:"type:jsonb;not null;default:'[]'" json:"-"`
+	WidgetList  []DashboardWidget `gorm:"-" json:"widgets"`
+	Shared      bool              `gorm:"not null;default:false;index" json:"shared"` // Visible to all users of the organization
+	OwnerID     uuid.UUID         `gorm:"type:uuid;not null;index" json:"owner_id"`
+	Owner       *User             `gorm:"foreignKey:OwnerID;constraint:OnDelete:CASCADE" json:"owner,omitempty"`
+}
+
+// TableName specifies the table name for Dashboard model
+func (Dashboard) TableName() string {
+	return "dashboards"
+}
+
+// BeforeSave serializes the widget definitions into the JSONB column
+func (d *Dashboard) BeforeSave(tx *gorm.DB) error {
+	if d.WidgetList == nil {
+		d.WidgetList = []DashboardWidget{}
+	}
+	data, err := json.Marshal(d.WidgetList)
+	if err != nil {
+		return err
+	}
+	d.Widgets = string(data)
+	return nil
+}
+
+// AfterFind parses the JSONB column into widget definitions
+func (d *Dashboard) AfterFind(tx *gorm.DB) error {
+	d.WidgetList = []DashboardWidget{}
+	if d.Widgets == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(d.Widgets), &d.WidgetList)
+}
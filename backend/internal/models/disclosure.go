@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DisclosureStatus is the triage state of a disclosure
+type DisclosureStatus string
+
+const (
+	DisclosureNew       DisclosureStatus = "NEW"       // Awaiting triage
+	DisclosureAccepted  DisclosureStatus = "ACCEPTED"  // Turned into a vulnerability in the registry
+	DisclosureDuplicate DisclosureStatus = "DUPLICATE" // Already tracked by an existing vulnerability
+	DisclosureRejected  DisclosureStatus = "REJECTED"  // Not a vulnerability, out of scope or spam
+)
+
+// Disclosure is a vulnerability report submitted by an external reporter through the public
+// disclosure form. Disclosures wait in a triage queue, separate from the vulnerability registry,
+// until a triager accepts them into the registry or closes them.
+type Disclosure struct {
+	BaseModel
+	OrgID             *uuid.UUID            `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Title             string                `gorm:"type:varchar(255);not null" json:"title"`
+	Description       string                `gorm:"type:text;not null" json:"description"`
+	StepsToReproduce  string                `gorm:"type:text" json:"steps_to_reproduce,omitempty"`
+	Impact            string                `gorm:"type:text" json:"impact,omitempty"`
+	AffectedTarget    string                `gorm:"type:varchar(500)" json:"affected_target,omitempty"` // Product, URL or host as named by the reporter
+	SuggestedSeverity VulnerabilitySeverity `gorm:"type:varchar(20)" json:"suggested_severity,omitempty"`
+	CVEID             string                `gorm:"type:varchar(20)" json:"cve_id,omitempty"`
+
+	// Reporter (contact details are optional so reports can be anonymous)
+	ReporterName  string `gorm:"type:varchar(200)" json:"reporter_name,omitempty"`
+	ReporterEmail string `gorm:"type:varchar(320);index" json:"reporter_email,omitempty"`
+	SubmitterIP   string `gorm:"type:varchar(45)" json:"submitter_ip,omitempty"`
+	UserAgent     string `gorm:"type:varchar(500)" json:"user_agent,omitempty"`
+
+	// Triage
+	Status          DisclosureStatus `gorm:"type:varchar(20);not null;default:NEW;index" json:"status"`
+	TriagedByID     *uuid.UUID       `gorm:"type:uuid" json:"triaged_by_id,omitempty"`
+	TriagedBy       *User            `gorm:"foreignKey:TriagedByID;constraint:OnDelete:SET NULL" json:"triaged_by,omitempty"`
+	TriagedAt       *time.Time       `gorm:"type:timestamp" json:"triaged_at,omitempty"`
+	TriageNotes     string           `gorm:"type:text" json:"triage_notes,omitempty"`
+	VulnerabilityID *uuid.UUID       `gorm:"type:uuid;index" json:"vulnerability_id,omitempty"` // Vulnerability created on acceptance, or the one it duplicates
+	Vulnerability   *Vulnerability   `gorm:"foreignKey:VulnerabilityID;constraint:OnDelete:SET NULL" json:"vulnerability,omitempty"`
+}
+
+// TableName specifies the table name for Disclosure model
+func (Disclosure) TableName() string {
+	return "disclosures"
+}
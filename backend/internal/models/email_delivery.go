@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailDeliveryStatus is where an outgoing email stands. Messages start SENT (or FAILED when the
+// provider refused them); provider webhooks then move them on.
+type EmailDeliveryStatus string
+
+const (
+	EmailDeliverySent       EmailDeliveryStatus = "SENT"       // Handed to the provider
+	EmailDeliveryFailed     EmailDeliveryStatus = "FAILED"     // The provider refused the message
+	EmailDeliveryDelivered  EmailDeliveryStatus = "DELIVERED"  // Accepted by the recipient's mail server
+	EmailDeliveryDeferred   EmailDeliveryStatus = "DEFERRED"   // Temporarily refused; the provider retries
+	EmailDeliveryBounced    EmailDeliveryStatus = "BOUNCED"    // Permanently refused
+	EmailDeliveryDropped    EmailDeliveryStatus = "DROPPED"    // Not sent by the provider (suppressed or rejected)
+	EmailDeliveryComplained EmailDeliveryStatus = "COMPLAINED" // Marked as spam by the recipient
+)
+
+// EmailDelivery records an email sent by the platform and its latest delivery status
+type EmailDelivery struct {
+	BaseModel
+	Provider          string              `gorm:"type:varchar(20);not null;index:idx_email_deliveries_provider_message" json:"provider"`
+	ProviderMessageID string              `gorm:"type:varchar(255);index:idx_email_deliveries_provider_message" json:"provider_message_id,omitempty"`
+	Category          string              `gorm:"type:varchar(50);not null;index" json:"category"` // What the email is, e.g. password_reset
+	Recipient         string              `gorm:"type:varchar(320);not null;index" json:"recipient"`
+	Subject           string              `gorm:"type:varchar(500)" json:"subject"`
+	Status            EmailDeliveryStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	Error             string              `gorm:"type:text" json:"error,omitempty"` // Provider error or latest bounce reason
+	SentAt            time.Time           `gorm:"not null" json:"sent_at"`
+	StatusAt          time.Time           `gorm:"not null" json:"status_at"` // When the status was last reported
+
+	Events []EmailDeliveryEvent `gorm:"foreignKey:DeliveryID" json:"events,omitempty"`
+}
+
+// TableName specifies the table name for EmailDelivery model
+func (EmailDelivery) TableName() string {
+	return "email_deliveries"
+}
+
+// EmailDeliveryEvent is a delivery status event a provider posted for a message
+type EmailDeliveryEvent struct {
+	BaseModel
+	DeliveryID    uuid.UUID           `gorm:"type:uuid;not null;index" json:"delivery_id"`
+	Delivery      *EmailDelivery      `gorm:"foreignKey:DeliveryID;constraint:OnDelete:CASCADE" json:"-"`
+	Status        EmailDeliveryStatus `gorm:"type:varchar(20);not null" json:"status"`
+	ProviderEvent string              `gorm:"type:varchar(50)" json:"provider_event,omitempty"`
+	Recipient     string              `gorm:"type:varchar(320)" json:"recipient,omitempty"`
+	Reason        string              `gorm:"type:text" json:"reason,omitempty"`
+	OccurredAt    time.Time           `gorm:"not null;index" json:"occurred_at"`
+}
+
+// TableName specifies the table name for EmailDeliveryEvent model
+func (EmailDeliveryEvent) TableName() string {
+	return "email_delivery_events"
+}
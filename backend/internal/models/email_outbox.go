@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailOutboxStatus is where a queued email stands
+type EmailOutboxStatus string
+
+const (
+	EmailOutboxPending EmailOutboxStatus = "PENDING" // Waiting for its first or next attempt
+	EmailOutboxSent    EmailOutboxStatus = "SENT"    // Handed to the provider
+	EmailOutboxDead    EmailOutboxStatus = "DEAD"    // Every attempt failed; left for an administrator to retry
+)
+
+// EmailOutboxEntry is an email queued by a request and sent by the email-outbox background job,
+// retried with backoff until it is sent or dead-lettered. The body is cleared once it is sent, so
+// the links it carries don't outlive the send.
+type EmailOutboxEntry struct {
+	BaseModel
+	Category      string            `gorm:"type:varchar(50);not null" json:"category"`
+	Recipient     string            `gorm:"type:varchar(320);not null;index" json:"recipient"`
+	Subject       string            `gorm:"type:varchar(500);not null" json:"subject"`
+	HTML          string            `gorm:"type:text" json:"-"`
+	Status        EmailOutboxStatus `gorm:"type:varchar(20);not null;index:idx_email_outbox_due" json:"status"`
+	Attempts      int               `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time         `gorm:"not null;index:idx_email_outbox_due" json:"next_attempt_at"`
+	LastError     string            `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveryID    *uuid.UUID        `gorm:"type:uuid" json:"delivery_id,omitempty"` // Set once sent
+	SentAt        *time.Time        `gorm:"type:timestamp" json:"sent_at,omitempty"`
+}
+
+// TableName specifies the table name for EmailOutboxEntry model
+func (EmailOutboxEntry) TableName() string {
+	return "email_outbox"
+}
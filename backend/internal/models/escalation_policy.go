@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EscalationPolicy escalates open vulnerabilities of a severity that stay unresolved longer
+// than AgeDays, e.g. "reassign HIGH vulnerabilities open for 30 days to the team lead". Each
+// policy escalates a vulnerability once; several policies of one severity with growing ages
+// form escalation tiers.
+type EscalationPolicy struct {
+	BaseModel
+	Name        string                `gorm:"type:varchar(255);not null" json:"name"`
+	Description string                `gorm:"type:text" json:"description,omitempty"`
+	Enabled     bool                  `gorm:"not null;default:true;index" json:"enabled"`
+	Severity    VulnerabilitySeverity `gorm:"type:varchar(20);not null" json:"severity"`
+	AgeDays     int                   `gorm:"not null" json:"age_days"`
+
+	// Actions taken on escalation
+	ReassignToTeamLead bool   `gorm:"not null;default:false" json:"reassign_to_team_lead"` // Reassign to the lead of the owner team
+	RaisePriority      bool   `gorm:"not null;default:false" json:"raise_priority"`
+	NotifyWebhookURL   string `gorm:"type:text" json:"notify_webhook_url,omitempty"` // Channel notified, e.g. a Slack or Teams incoming webhook
+
+	LastEvaluatedAt *time.Time `gorm:"type:timestamp" json:"last_evaluated_at,omitempty"`
+
+	CreatedByID uuid.UUID `gorm:"type:uuid;not null" json:"created_by_id"`
+	CreatedBy   *User     `gorm:"foreignKey:CreatedByID;constraint:OnDelete:RESTRICT" json:"created_by,omitempty"`
+}
+
+// TableName specifies the table name for EscalationPolicy model
+func (EscalationPolicy) TableName() string {
+	return "escalation_policies"
+}
+
+// VulnerabilityEscalation records an escalation policy acting on a vulnerability and what it changed
+type VulnerabilityEscalation struct {
+	BaseModel
+	OrgID           *uuid.UUID            `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	PolicyID        uuid.UUID             `gorm:"type:uuid;not null;uniqueIndex:idx_escalation_policy_vulnerability" json:"policy_id"`
+	Policy          *EscalationPolicy     `gorm:"foreignKey:PolicyID;constraint:OnDelete:CASCADE" json:"policy,omitempty"`
+	VulnerabilityID uuid.UUID             `gorm:"type:uuid;not null;index;uniqueIndex:idx_escalation_policy_vulnerability" json:"vulnerability_id"`
+	Severity        VulnerabilitySeverity `gorm:"type:varchar(20);not null" json:"severity"`
+	AgeDays         int                   `gorm:"not null" json:"age_days"` // Age of the vulnerability when escalated
+
+	PreviousAssigneeID *uuid.UUID `gorm:"type:uuid" json:"previous_assignee_id,omitempty"`
+	AssigneeID         *uuid.UUID `gorm:"type:uuid" json:"assignee_id,omitempty"`
+	Assignee           *User      `gorm:"foreignKey:AssigneeID;constraint:OnDelete:SET NULL" json:"assignee,omitempty"`
+	PreviousPriority   int        `gorm:"not null;default:0" json:"previous_priority"`
+	Priority           int        `gorm:"not null;default:0" json:"priority"`
+
+	Notified    bool   `gorm:"not null;default:false" json:"notified"` // The policy's channel accepted the notification
+	NotifyError string `gorm:"type:text" json:"notify_error,omitempty"`
+
+	EscalatedAt time.Time `gorm:"type:timestamp;not null;index" json:"escalated_at"`
+}
+
+// TableName specifies the table name for VulnerabilityEscalation model
+func (VulnerabilityEscalation) TableName() string {
+	return "vulnerability_escalations"
+}
@@ -13,7 +13,7 @@ type FindingAttachment struct {
 
 	// Link to finding
 	FindingID   uuid.UUID              `gorm:"type:uuid;not null;index:idx_attachment_finding" json:"finding_id"`
-	Finding     *VulnerabilityFinding  `gorm:"foreignKey:FindingID;constraint:OnDelete:CASCADE" json:"finding,omitempty"`
+	Finding     *VulnerabilityFinding  `gorm:"foreignKey:FindingID;-:migration" json:"finding,omitempty"`
 
 	// File metadata
 	Filename    string                 `gorm:"type:varchar(255);not null" json:"filename"`
@@ -24,6 +24,11 @@ type FindingAttachment struct {
 	// Storage information
 	StoragePath string                 `gorm:"type:varchar(500);not null" json:"storage_path"` // relative path in storage
 	StorageURL  string                 `gorm:"type:varchar(500)" json:"storage_url,omitempty"` // public URL if applicable
+	StorageBackend string              `gorm:"type:varchar(20);not null;default:local" json:"storage_backend"` // storage driver holding the file
+
+	// Chain of custody: hashes recorded at upload (uploader and time are UploadedBy/CreatedAt)
+	SHA256         string              `gorm:"type:varchar(64);index" json:"sha256,omitempty"`   // hash of the stored file
+	OriginalSHA256 string              `gorm:"type:varchar(64)" json:"original_sha256,omitempty"` // hash of the file as uploaded (differs when images are normalized)
 
 	// Image-specific metadata (for screenshots)
 	IsImage     bool                   `gorm:"default:false" json:"is_image"`
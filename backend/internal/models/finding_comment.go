@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FindingComment represents a discussion comment on a specific finding
+type FindingComment struct {
+	BaseModel
+	FindingID      uuid.UUID             `gorm:"type:uuid;not null;index:idx_finding_comment_finding" json:"finding_id"`
+	Finding        *VulnerabilityFinding `gorm:"foreignKey:FindingID;-:migration" json:"finding,omitempty"`
+	AuthorID       uuid.UUID             `gorm:"type:uuid;not null;index" json:"author_id"`
+	Author         *User                 `gorm:"foreignKey:AuthorID;constraint:OnDelete:RESTRICT" json:"author,omitempty"`
+	Body           string                `gorm:"type:text;not null" json:"body"`
+	EditedAt       *time.Time            `gorm:"type:timestamp" json:"edited_at,omitempty"`
+	MentionedUsers []User                `gorm:"many2many:finding_comment_mentions" json:"mentioned_users,omitempty"`
+}
+
+// TableName specifies the table name for FindingComment model
+func (FindingComment) TableName() string {
+	return "finding_comments"
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// ImportChangeAction is how an import changed a record
+type ImportChangeAction string
+
+const (
+	ImportChangeCreated ImportChangeAction = "created"
+	ImportChangeSeen    ImportChangeAction = "seen"    // Finding reported again; its scan source and last sighting were updated
+	ImportChangeMissing ImportChangeAction = "missing" // Finding no longer detected; its clean scans were counted and it may have been closed
+)
+
+// Import change entity types
+const (
+	ImportChangeAsset         = "asset"
+	ImportChangeVulnerability = "vulnerability"
+	ImportChangeFinding       = "finding"
+	ImportChangeAssetLink     = "asset_link" // Vulnerability linked to an asset; EntityID is the vulnerability, RelatedID the asset
+)
+
+// ImportChange journals one record an import job created or updated, with the values an
+// update replaced, so that the job can be rolled back
+type ImportChange struct {
+	BaseModel
+	OrgID       *uuid.UUID         `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	ImportJobID uuid.UUID          `gorm:"type:uuid;not null;index" json:"import_job_id"`
+	EntityType  string             `gorm:"type:varchar(20);not null" json:"entity_type"`
+	EntityID    uuid.UUID          `gorm:"type:uuid;not null;index" json:"entity_id"`
+	RelatedID   *uuid.UUID         `gorm:"type:uuid" json:"related_id,omitempty"` // Asset of an asset link
+	Action      ImportChangeAction `gorm:"type:varchar(20);not null" json:"action"`
+	Previous    string             `gorm:"type:jsonb;not null;default:'{}'" json:"-"` // Values the update replaced
+}
+
+// TableName specifies the table name for ImportChange model
+func (ImportChange) TableName() string {
+	return "import_changes"
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportJobStatus is the state of an import job
+type ImportJobStatus string
+
+const (
+	ImportJobRunning   ImportJobStatus = "running"
+	ImportJobCompleted ImportJobStatus = "completed"
+	ImportJobFailed    ImportJobStatus = "failed"
+	// ImportJobInterrupted jobs were stopped at a checkpoint by a server shutdown and can be resumed
+	ImportJobInterrupted ImportJobStatus = "interrupted"
+	// ImportJobRolledBack jobs had the records they created removed and the ones they updated restored
+	ImportJobRolledBack ImportJobStatus = "rolled_back"
+)
+
+// ImportJob records one import of scanner results: where they came from and how the findings
+// changed compared to the previous import of the same scan. Vulnerabilities and findings
+// carry the job that created or last saw them, and every record the job created or updated
+// is journaled as an ImportChange.
+type ImportJob struct {
+	BaseModel
+	OrgID               *uuid.UUID      `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Scanner             string          `gorm:"type:varchar(50);not null;index:idx_import_jobs_scan" json:"scanner"`
+	IntegrationConfigID *uuid.UUID      `gorm:"type:uuid;index:idx_import_jobs_scan" json:"integration_config_id,omitempty"`
+	ScanID              string          `gorm:"type:varchar(100);index:idx_import_jobs_scan" json:"scan_id,omitempty"` // Empty for uploaded files
+	ScanDate            *time.Time      `json:"scan_date,omitempty"`                                                   // Latest host scan time in the results
+	Status              ImportJobStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	AutoCloseMissing    bool            `gorm:"not null;default:false" json:"auto_close_missing"` // Findings no longer detected were closed
+	SkipDuplicates      bool            `gorm:"not null;default:false" json:"skip_duplicates"`
+
+	// Checkpoint: parsed vulnerabilities written by committed batches, where a resume continues
+	ProcessedVulnerabilities int `gorm:"not null;default:0" json:"processed_vulnerabilities"`
+
+	// Outcome
+	ImportedVulnerabilities  int    `gorm:"not null;default:0" json:"imported_vulnerabilities"`
+	SkippedVulnerabilities   int    `gorm:"not null;default:0" json:"skipped_vulnerabilities"`
+	NewFindings              int    `gorm:"not null;default:0" json:"new_findings"`
+	StillPresentFindings     int    `gorm:"not null;default:0" json:"still_present_findings"`
+	NoLongerDetectedFindings int    `gorm:"not null;default:0" json:"no_longer_detected_findings"`
+	AutoClosedFindings       int    `gorm:"not null;default:0" json:"auto_closed_findings"`
+	QuarantinedHosts         int    `gorm:"not null;default:0" json:"quarantined_hosts"` // Hosts held back by validation
+	Errors                   int    `gorm:"not null;default:0" json:"errors"`
+	Error                    string `gorm:"type:text" json:"error,omitempty"` // Why the job failed
+
+	CreatedByID uuid.UUID  `gorm:"type:uuid;not null" json:"created_by_id"`
+	StartedAt   time.Time  `gorm:"not null" json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	// Rollback
+	RolledBackByID *uuid.UUID `gorm:"type:uuid" json:"rolled_back_by_id,omitempty"`
+	RolledBackAt   *time.Time `json:"rolled_back_at,omitempty"`
+}
+
+// TableName specifies the table name for ImportJob model
+func (ImportJob) TableName() string {
+	return "import_jobs"
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// ImportRuleMatch is the host attribute an import rule's pattern is matched against
+type ImportRuleMatch string
+
+const (
+	ImportRuleMatchHostname ImportRuleMatch = "hostname"  // Regular expression matched against the hostname
+	ImportRuleMatchCIDR     ImportRuleMatch = "cidr"      // Network containing the host's IP address
+	ImportRuleMatchScanName ImportRuleMatch = "scan_name" // Regular expression matched against the scan name
+	ImportRuleMatchTag      ImportRuleMatch = "tag"       // Scanner host tag, as name or name=value regular expression
+)
+
+// ImportRule assigns an environment and owner to the hosts of imported scans. Rules are
+// evaluated by ascending position: the first matching rule that sets an environment and the
+// first that sets an owner win, ahead of network ranges. Rules only apply to assets an import
+// creates; existing assets keep their values.
+type ImportRule struct {
+	BaseModel
+	OrgID       *uuid.UUID      `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Name        string          `gorm:"type:varchar(255);not null" json:"name"`
+	Description string          `gorm:"type:text" json:"description,omitempty"`
+	Enabled     bool            `gorm:"not null;default:true;index" json:"enabled"`
+	Position    int             `gorm:"not null;default:0;index" json:"position"` // Evaluation order, lowest first
+	MatchType   ImportRuleMatch `gorm:"type:varchar(20);not null" json:"match_type"`
+	Pattern     string          `gorm:"type:varchar(500);not null" json:"pattern"`
+
+	// Classification; empty fields are left to later rules
+	Environment Environment `gorm:"type:varchar(50)" json:"environment,omitempty"`
+	OwnerID     *uuid.UUID  `gorm:"type:uuid" json:"owner_id,omitempty"`
+	Owner       *User       `gorm:"foreignKey:OwnerID;constraint:OnDelete:SET NULL" json:"owner,omitempty"`
+	OwnerTeamID *uuid.UUID  `gorm:"type:uuid;index" json:"owner_team_id,omitempty"`
+	OwnerTeam   *Team       `gorm:"foreignKey:OwnerTeamID;constraint:OnDelete:SET NULL" json:"owner_team,omitempty"`
+
+	CreatedByID uuid.UUID `gorm:"type:uuid;not null" json:"created_by_id"`
+	CreatedBy   *User     `gorm:"foreignKey:CreatedByID;constraint:OnDelete:RESTRICT" json:"created_by,omitempty"`
+}
+
+// TableName specifies the table name for ImportRule model
+func (ImportRule) TableName() string {
+	return "import_rules"
+}
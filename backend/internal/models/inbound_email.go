@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InboundEmailChannel is how a vulnerability report email reached the platform
+type InboundEmailChannel string
+
+const (
+	InboundEmailChannelIMAP    InboundEmailChannel = "IMAP"    // Polled from the configured mailbox
+	InboundEmailChannelWebhook InboundEmailChannel = "WEBHOOK" // Posted by an inbound mail provider (SES, SendGrid)
+)
+
+// InboundEmailStatus is the outcome of processing a vulnerability report email
+type InboundEmailStatus string
+
+const (
+	InboundEmailCreated  InboundEmailStatus = "CREATED"  // A draft vulnerability was created
+	InboundEmailRejected InboundEmailStatus = "REJECTED" // Sender not allowed or message unusable
+	InboundEmailFailed   InboundEmailStatus = "FAILED"   // Processing error; the message can be retried
+)
+
+// InboundEmail records a vulnerability report received by email and what became of it. The
+// message ID makes ingestion idempotent when a mailbox or provider delivers a message twice.
+type InboundEmail struct {
+	BaseModel
+	MessageID       string              `gorm:"type:varchar(500);not null;uniqueIndex" json:"message_id"`
+	Channel         InboundEmailChannel `gorm:"type:varchar(20);not null" json:"channel"`
+	FromAddress     string              `gorm:"type:varchar(320);not null;index" json:"from_address"`
+	Subject         string              `gorm:"type:varchar(500)" json:"subject"`
+	SentAt          *time.Time          `json:"sent_at,omitempty"`
+	Status          InboundEmailStatus  `gorm:"type:varchar(20);not null;index" json:"status"`
+	Reason          string              `gorm:"type:text" json:"reason,omitempty"` // Why the message was rejected or failed, or attachments that were skipped
+	VulnerabilityID *uuid.UUID          `gorm:"type:uuid;index" json:"vulnerability_id,omitempty"`
+	Vulnerability   *Vulnerability      `gorm:"foreignKey:VulnerabilityID;constraint:OnDelete:SET NULL" json:"vulnerability,omitempty"`
+	AttachmentCount int                 `gorm:"not null;default:0" json:"attachment_count"`
+}
+
+// TableName specifies the table name for InboundEmail model
+func (InboundEmail) TableName() string {
+	return "inbound_emails"
+}
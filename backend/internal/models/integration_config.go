@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -29,6 +30,12 @@ type IntegrationConfig struct {
 	AccessKey     string `gorm:"type:text" json:"-"`                         // API access key (encrypted, not in JSON)
 	SecretKey     string `gorm:"type:text" json:"-"`                         // API secret key (encrypted, not in JSON)
 
+	// TLS settings
+	TLSSkipVerify bool   `gorm:"default:false" json:"tls_skip_verify"`      // Accept any server certificate (verification is on by default)
+	CACertPEM     string `gorm:"type:text" json:"ca_cert_pem,omitempty"`     // PEM CA certificates trusted in addition to the system pool
+	ClientCertPEM string `gorm:"type:text" json:"client_cert_pem,omitempty"` // PEM client certificate for mutual TLS
+	ClientKeyPEM  string `gorm:"type:text" json:"-"`                         // PEM client certificate key (encrypted, not in JSON)
+
 	// Additional configuration (stored as JSONB for flexibility)
 	Config map[string]interface{} `gorm:"type:jsonb" json:"config,omitempty"`
 
@@ -37,6 +44,12 @@ type IntegrationConfig struct {
 	SyncIntervalMins int   `gorm:"default:60" json:"sync_interval_mins"`    // Sync interval in minutes
 	LastSyncAt       *time.Time `json:"last_sync_at,omitempty"`             // Last successful sync
 
+	// Re-import settings
+	AutoCloseMissing bool `gorm:"default:false" json:"auto_close_missing"` // Close findings a re-imported scan no longer reports
+
+	// Severity mapping applied to imported results (JSONB SeverityMapping, empty for the defaults)
+	SeverityMapping string `gorm:"type:jsonb" json:"-"`
+
 	// Metadata
 	CreatedBy uuid.UUID      `gorm:"type:uuid;not null" json:"created_by"`
 	CreatedAt time.Time      `json:"created_at"`
@@ -68,16 +81,23 @@ type PublicIntegrationConfig struct {
 	Active           bool                   `json:"active"`
 	BaseURL          string                 `json:"base_url"`
 	HasCredentials   bool                   `json:"has_credentials"`    // Indicates if credentials are configured
+	VerifyTLS        bool                   `json:"verify_tls"`
+	CACertPEM        string                 `json:"ca_cert_pem,omitempty"`
+	ClientCertPEM    string                 `json:"client_cert_pem,omitempty"`
+	HasClientKey     bool                   `json:"has_client_key"`     // Indicates if a client certificate key is configured
 	Config           map[string]interface{} `json:"config,omitempty"`
 	AutoSync         bool                   `json:"auto_sync"`
 	SyncIntervalMins int                    `json:"sync_interval_mins"`
 	LastSyncAt       *time.Time             `json:"last_sync_at,omitempty"`
+	AutoCloseMissing bool                   `json:"auto_close_missing"`
+	SeverityMapping  *SeverityMapping       `json:"severity_mapping,omitempty"` // Nil when the scanner's severities are used as they are
 	CreatedAt        time.Time              `json:"created_at"`
 	UpdatedAt        time.Time              `json:"updated_at"`
 }
 
 // ToPublic converts IntegrationConfig to PublicIntegrationConfig (hides sensitive data)
 func (i *IntegrationConfig) ToPublic() PublicIntegrationConfig {
+	mapping, _ := i.ParseSeverityMapping()
 	return PublicIntegrationConfig{
 		ID:               i.ID,
 		Name:             i.Name,
@@ -85,11 +105,103 @@ func (i *IntegrationConfig) ToPublic() PublicIntegrationConfig {
 		Active:           i.Active,
 		BaseURL:          i.BaseURL,
 		HasCredentials:   i.AccessKey != "" && i.SecretKey != "",
+		VerifyTLS:        !i.TLSSkipVerify,
+		CACertPEM:        i.CACertPEM,
+		ClientCertPEM:    i.ClientCertPEM,
+		HasClientKey:     i.ClientKeyPEM != "",
 		Config:           i.Config,
 		AutoSync:         i.AutoSync,
 		SyncIntervalMins: i.SyncIntervalMins,
 		LastSyncAt:       i.LastSyncAt,
+		AutoCloseMissing: i.AutoCloseMissing,
+		SeverityMapping:  mapping,
 		CreatedAt:        i.CreatedAt,
 		UpdatedAt:        i.UpdatedAt,
 	}
 }
+
+// ParseSeverityMapping returns the integration's severity mapping, or nil when it has none
+func (i *IntegrationConfig) ParseSeverityMapping() (*SeverityMapping, error) {
+	return ParseSeverityMapping(i.SeverityMapping)
+}
+
+// Scanner severity levels, the scale severity mappings are keyed by. Nessus severities 0-4 are
+// info through critical.
+const (
+	ScannerSeverityInfo     = "info"
+	ScannerSeverityLow      = "low"
+	ScannerSeverityMedium   = "medium"
+	ScannerSeverityHigh     = "high"
+	ScannerSeverityCritical = "critical"
+)
+
+// DefaultScannerSeverities maps scanner severity levels to severities when an integration has
+// no mapping for them
+var DefaultScannerSeverities = map[string]VulnerabilitySeverity{
+	ScannerSeverityInfo:     SeverityNone,
+	ScannerSeverityLow:      SeverityLow,
+	ScannerSeverityMedium:   SeverityMedium,
+	ScannerSeverityHigh:     SeverityHigh,
+	ScannerSeverityCritical: SeverityCritical,
+}
+
+// CVSSSeverityThresholds are the minimum CVSS scores for each severity; lower scores are NONE
+type CVSSSeverityThresholds struct {
+	Critical float64 `json:"critical"`
+	High     float64 `json:"high"`
+	Medium   float64 `json:"medium"`
+	Low      float64 `json:"low"`
+}
+
+// Severity returns the severity of a CVSS score
+func (t CVSSSeverityThresholds) Severity(score float64) VulnerabilitySeverity {
+	switch {
+	case score >= t.Critical:
+		return SeverityCritical
+	case score >= t.High:
+		return SeverityHigh
+	case score >= t.Medium:
+		return SeverityMedium
+	case score >= t.Low:
+		return SeverityLow
+	default:
+		return SeverityNone
+	}
+}
+
+// SeverityMapping translates an integration's scanner severities into ours on import. CVSS
+// thresholds, when set, classify results that have a CVSS score; other results are mapped by
+// their scanner severity level, falling back to DefaultScannerSeverities for unmapped levels.
+type SeverityMapping struct {
+	Levels         map[string]VulnerabilitySeverity `json:"levels,omitempty"` // Keyed by scanner severity level
+	CVSSThresholds *CVSSSeverityThresholds          `json:"cvss_thresholds,omitempty"`
+}
+
+// ParseSeverityMapping parses a stored severity mapping; an empty value is no mapping
+func ParseSeverityMapping(value string) (*SeverityMapping, error) {
+	if value == "" || value == "{}" || value == "null" {
+		return nil, nil
+	}
+	var mapping SeverityMapping
+	if err := json.Unmarshal([]byte(value), &mapping); err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+// Severity maps a result with the given scanner severity level and CVSS score. A nil mapping
+// uses the default levels; an unknown level keeps fallback, the parser's severity.
+func (m *SeverityMapping) Severity(level string, cvssScore *float64, fallback VulnerabilitySeverity) VulnerabilitySeverity {
+	if m != nil && m.CVSSThresholds != nil && cvssScore != nil {
+		return m.CVSSThresholds.Severity(*cvssScore)
+	}
+	if m != nil {
+		if severity, ok := m.Levels[level]; ok {
+			return severity
+		}
+	}
+	if severity, ok := DefaultScannerSeverities[level]; ok {
+		return severity
+	}
+	return fallback
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// KnownExploit is a public exploit of a CVE, synced from the Exploit-DB and Metasploit feeds.
+// Known exploits are shared by all organizations; an exploit of several CVEs has a row per CVE.
+type KnownExploit struct {
+	Source      string     `gorm:"type:varchar(20);primaryKey;not null" json:"source"`       // exploit-db or metasploit
+	ExternalID  string     `gorm:"type:varchar(255);primaryKey;not null" json:"external_id"` // Exploit-DB ID or Metasploit module name
+	CVEID       string     `gorm:"type:varchar(20);primaryKey;not null;index:idx_known_exploit_cve" json:"cve_id"`
+	Title       string     `gorm:"type:text" json:"title"`
+	URL         string     `gorm:"type:text" json:"url"`
+	Verified    bool       `gorm:"not null;default:false" json:"verified"`
+	PublishedAt *time.Time `gorm:"type:date" json:"published_at,omitempty"`
+	SyncedAt    time.Time  `gorm:"not null" json:"synced_at"`
+}
+
+// TableName specifies the table name for KnownExploit model
+func (KnownExploit) TableName() string {
+	return "known_exploits"
+}
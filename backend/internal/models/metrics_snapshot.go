@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DailyMetricsSnapshot holds precomputed dashboard metrics for one organization and UTC day.
+// Flow metrics (new/resolved counts, MTTR) describe activity during the day. Point-in-time
+// counts describe the state when the snapshot was last refreshed; rows backfilled from
+// history only carry flow metrics.
+type DailyMetricsSnapshot struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	OrgID        *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_metrics_snapshot_org_date,priority:1" json:"org_id,omitempty"`
+	SnapshotDate time.Time  `gorm:"type:date;not null;uniqueIndex:idx_metrics_snapshot_org_date,priority:2" json:"snapshot_date"`
+	Backfilled   bool       `gorm:"not null;default:false" json:"backfilled"`
+
+	// Flow metrics
+	NewVulnerabilities      int64   `gorm:"not null;default:0" json:"new_vulnerabilities"`
+	NewCritical             int64   `gorm:"not null;default:0" json:"new_critical"`
+	ResolvedVulnerabilities int64   `gorm:"not null;default:0" json:"resolved_vulnerabilities"`
+	NewFindings             int64   `gorm:"not null;default:0" json:"new_findings"`
+	MTTRHours               float64 `gorm:"not null;default:0" json:"mttr_hours"` // Mean time to resolve for vulnerabilities resolved that day
+
+	// Point-in-time vulnerability counts by severity
+	CriticalCount int64 `gorm:"not null;default:0" json:"critical_count"`
+	HighCount     int64 `gorm:"not null;default:0" json:"high_count"`
+	MediumCount   int64 `gorm:"not null;default:0" json:"medium_count"`
+	LowCount      int64 `gorm:"not null;default:0" json:"low_count"`
+	NoneCount     int64 `gorm:"not null;default:0" json:"none_count"`
+
+	// Point-in-time vulnerability counts by status
+	OpenCount          int64 `gorm:"not null;default:0" json:"open_count"`
+	InProgressCount    int64 `gorm:"not null;default:0" json:"in_progress_count"`
+	ResolvedCount      int64 `gorm:"not null;default:0" json:"resolved_count"`
+	VerifiedCount      int64 `gorm:"not null;default:0" json:"verified_count"`
+	ClosedCount        int64 `gorm:"not null;default:0" json:"closed_count"`
+	FalsePositiveCount int64 `gorm:"not null;default:0" json:"false_positive_count"`
+
+	// Point-in-time asset counts
+	TotalAssets  int64 `gorm:"not null;default:0" json:"total_assets"`
+	ActiveAssets int64 `gorm:"not null;default:0" json:"active_assets"`
+
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName specifies the table name for DailyMetricsSnapshot model
+func (DailyMetricsSnapshot) TableName() string {
+	return "daily_metrics_snapshots"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// NetworkRange is an admin-defined subnet. Imported hosts whose IP falls inside a range
+// inherit its environment, location and owner; the most specific (longest prefix) range wins.
+type NetworkRange struct {
+	BaseModel
+	OrgID       *uuid.UUID  `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Name        string      `gorm:"type:varchar(255);not null" json:"name"`
+	CIDR        string      `gorm:"type:varchar(50);not null" json:"cidr"` // Stored in canonical network form
+	Description string      `gorm:"type:text" json:"description,omitempty"`
+	Environment Environment `gorm:"type:varchar(50);not null;default:PRODUCTION" json:"environment"`
+	Location    string      `gorm:"type:varchar(255)" json:"location,omitempty"`
+	OwnerID     *uuid.UUID  `gorm:"type:uuid" json:"owner_id,omitempty"`
+	Owner       *User       `gorm:"foreignKey:OwnerID;constraint:OnDelete:SET NULL" json:"owner,omitempty"`
+	OwnerTeamID *uuid.UUID  `gorm:"type:uuid;index" json:"owner_team_id,omitempty"`
+	OwnerTeam   *Team       `gorm:"foreignKey:OwnerTeamID;constraint:OnDelete:SET NULL" json:"owner_team,omitempty"`
+	CreatedByID uuid.UUID   `gorm:"type:uuid;not null" json:"created_by_id"`
+	CreatedBy   *User       `gorm:"foreignKey:CreatedByID;constraint:OnDelete:RESTRICT" json:"created_by,omitempty"`
+}
+
+// TableName specifies the table name for NetworkRange model
+func (NetworkRange) TableName() string {
+	return "network_ranges"
+}
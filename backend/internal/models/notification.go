@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationType represents the kind of event a notification is about
+type NotificationType string
+
+const (
+	NotificationTypeMention                 NotificationType = "mention"
+	NotificationTypeRiskAcceptanceRequested NotificationType = "risk_acceptance_requested"
+	NotificationTypeRiskAcceptanceApproved  NotificationType = "risk_acceptance_approved"
+	NotificationTypeRiskAcceptanceRejected  NotificationType = "risk_acceptance_rejected"
+	NotificationTypeRiskAcceptanceExpired   NotificationType = "risk_acceptance_expired"
+	NotificationTypeTeamAssigned            NotificationType = "team_assigned"
+	NotificationTypeTeamStatusChanged       NotificationType = "team_status_changed"
+	NotificationTypeRetestAssigned          NotificationType = "retest_assigned"
+	NotificationTypeRetestCompleted         NotificationType = "retest_completed"
+	NotificationTypeVulnerabilityEscalated  NotificationType = "vulnerability_escalated"
+	NotificationTypeCloseApprovalRequested  NotificationType = "close_approval_requested"
+	NotificationTypeCloseApprovalApproved   NotificationType = "close_approval_approved"
+	NotificationTypeCloseApprovalRejected   NotificationType = "close_approval_rejected"
+	NotificationTypeAssetDecommissioned     NotificationType = "asset_decommissioned"
+)
+
+// Notification represents an in-app notification delivered to a user
+type Notification struct {
+	BaseModel
+	UserID       uuid.UUID        `gorm:"type:uuid;not null;index:idx_notification_user" json:"user_id"`
+	User         *User            `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Type         NotificationType `gorm:"type:varchar(50);not null" json:"type"`
+	Title        string           `gorm:"type:varchar(255);not null" json:"title"`
+	Message      string           `gorm:"type:text" json:"message,omitempty"`
+	ResourceType string           `gorm:"type:varchar(50)" json:"resource_type,omitempty"` // finding, vulnerability, etc.
+	ResourceID   *uuid.UUID       `gorm:"type:uuid" json:"resource_id,omitempty"`
+	ActorID      *uuid.UUID       `gorm:"type:uuid" json:"actor_id,omitempty"`
+	Actor        *User            `gorm:"foreignKey:ActorID;constraint:OnDelete:SET NULL" json:"actor,omitempty"`
+	ReadAt       *time.Time       `gorm:"type:timestamp" json:"read_at,omitempty"`
+}
+
+// TableName specifies the table name for Notification model
+func (Notification) TableName() string {
+	return "notifications"
+}
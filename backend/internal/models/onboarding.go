@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OnboardingStep is a setup step offered to users after their first login
+type OnboardingStep string
+
+const (
+	OnboardingStepTwoFactor OnboardingStep = "two_factor" // Prompt to enable two-factor authentication
+	OnboardingStepProfile   OnboardingStep = "profile"    // Complete the profile (display name)
+)
+
+// OnboardingStepStatus is the state of a setup step for a user
+type OnboardingStepStatus string
+
+const (
+	OnboardingStepPending   OnboardingStepStatus = "pending"
+	OnboardingStepCompleted OnboardingStepStatus = "completed"
+	OnboardingStepSkipped   OnboardingStepStatus = "skipped"
+)
+
+// PolicyAcceptance records a user accepting a version of a policy such as the terms of use.
+// Rows are kept as evidence; an administrator reset revokes them instead of deleting them.
+type PolicyAcceptance struct {
+	BaseModel
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index:idx_policy_acceptances_user_policy" json:"user_id"`
+	User       *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Policy     string     `gorm:"type:varchar(50);not null;index:idx_policy_acceptances_user_policy" json:"policy"`
+	Version    string     `gorm:"type:varchar(50);not null" json:"version"`
+	AcceptedAt time.Time  `gorm:"not null" json:"accepted_at"`
+	IPAddress  string     `gorm:"type:varchar(45)" json:"ip_address,omitempty"`
+	UserAgent  string     `gorm:"type:text" json:"user_agent,omitempty"`
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	RevokedBy  *uuid.UUID `gorm:"type:uuid" json:"revoked_by,omitempty"`
+}
+
+// TableName specifies the table name for PolicyAcceptance model
+func (PolicyAcceptance) TableName() string {
+	return "policy_acceptances"
+}
+
+// OnboardingStepState stores a setup step a user completed or skipped; steps without a row
+// are pending unless their completion can be derived from the account itself
+type OnboardingStepState struct {
+	BaseModel
+	UserID uuid.UUID            `gorm:"type:uuid;not null;uniqueIndex:idx_onboarding_steps_user_step" json:"user_id"`
+	User   *User                `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Step   OnboardingStep       `gorm:"type:varchar(30);not null;uniqueIndex:idx_onboarding_steps_user_step" json:"step"`
+	Status OnboardingStepStatus `gorm:"type:varchar(20);not null" json:"status"`
+}
+
+// TableName specifies the table name for OnboardingStepState model
+func (OnboardingStepState) TableName() string {
+	return "onboarding_steps"
+}
@@ -0,0 +1,23 @@
+package models
+
+// Organization is a tenant. Users, assets, vulnerabilities, findings, assessments and API keys
+// belong to exactly one organization and are only visible within it.
+type Organization struct {
+	BaseModel
+	Name        string `gorm:"type:varchar(255);not null" json:"name"`
+	Slug        string `gorm:"type:varchar(100);uniqueIndex;not null" json:"slug"`
+	Description string `gorm:"type:text" json:"description,omitempty"`
+	Active      bool   `gorm:"not null;default:true" json:"active"`
+	// IsPlatform marks the operator organization whose administrators can manage and switch
+	// into other organizations
+	IsPlatform bool `gorm:"not null;default:false" json:"is_platform"`
+}
+
+// TableName specifies the table name for Organization model
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// DefaultOrganizationSlug is the slug of the organization created on first start; existing
+// data is assigned to it
+const DefaultOrganizationSlug = "default"
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PermissionWildcard grants (or denies) every action of a resource
+const PermissionWildcard = "*"
+
+// PermissionAction describes one action of a resource in the permission catalog
+type PermissionAction struct {
+	Action      string `json:"action"`
+	Description string `json:"description"`
+}
+
+// PermissionResource groups the actions available on a resource
+type PermissionResource struct {
+	Resource    string             `json:"resource"`
+	Description string             `json:"description"`
+	Actions     []PermissionAction `json:"actions"`
+}
+
+// PermissionCatalog enumerates every resource:action pair that roles can grant or deny.
+// RequirePermission refuses pairs that are not listed here, so new checks must be added to it.
+var PermissionCatalog = []PermissionResource{
+	{Resource: "admin", Description: "Administration console", Actions: []PermissionAction{
+		{Action: "access", Description: "Open the administration console"},
+	}},
+	{Resource: "users", Description: "User accounts", Actions: []PermissionAction{
+		{Action: "read", Description: "View users"},
+		{Action: "create", Description: "Create users"},
+		{Action: "update", Description: "Update users and their roles"},
+		{Action: "delete", Description: "Delete users"},
+	}},
+	{Resource: "roles", Description: "Roles and permissions", Actions: []PermissionAction{
+		{Action: "read", Description: "View roles"},
+		{Action: "create", Description: "Create custom roles"},
+		{Action: "update", Description: "Update custom roles"},
+		{Action: "delete", Description: "Delete custom roles"},
+	}},
+	{Resource: "profile", Description: "Own user profile", Actions: []PermissionAction{
+		{Action: "read", Description: "View own profile"},
+		{Action: "update", Description: "Update own profile"},
+	}},
+	{Resource: "organization", Description: "Organizations (tenants)", Actions: []PermissionAction{
+		{Action: "read", Description: "View organizations"},
+		{Action: "manage", Description: "Create, update and switch between organizations"},
+	}},
+	{Resource: "team", Description: "Teams", Actions: []PermissionAction{
+		{Action: "read", Description: "View teams and their members"},
+		{Action: "manage", Description: "Create, update and delete teams and manage membership"},
+	}},
+	{Resource: "vulnerability", Description: "Vulnerabilities", Actions: []PermissionAction{
+		{Action: "read", Description: "View vulnerabilities"},
+		{Action: "write", Description: "Create and update vulnerabilities"},
+		{Action: "delete", Description: "Delete vulnerabilities"},
+		{Action: "assign", Description: "Assign vulnerabilities to users and teams"},
+		{Action: "import", Description: "Import scanner results"},
+		{Action: "export", Description: "Export vulnerabilities"},
+		{Action: "status_change", Description: "Change vulnerability status"},
+	}},
+	{Resource: "disclosure", Description: "Vulnerability disclosures submitted through the public form", Actions: []PermissionAction{
+		{Action: "read", Description: "View the disclosure triage queue"},
+		{Action: "triage", Description: "Accept disclosures into the registry or reject them"},
+	}},
+	{Resource: "finding", Description: "Vulnerability findings", Actions: []PermissionAction{
+		{Action: "read", Description: "View findings"},
+		{Action: "mark_fixed", Description: "Mark findings as fixed"},
+		{Action: "verify", Description: "Verify fixed findings"},
+		{Action: "accept_risk", Description: "Accept risk and review risk acceptance requests"},
+		{Action: "upload_attachment", Description: "Upload finding attachments"},
+		{Action: "comment", Description: "Comment on findings"},
+		{Action: "request_risk_acceptance", Description: "Request risk acceptance"},
+	}},
+	{Resource: "asset", Description: "Assets", Actions: []PermissionAction{
+		{Action: "read", Description: "View assets"},
+		{Action: "write", Description: "Create and update assets"},
+		{Action: "delete", Description: "Delete assets"},
+	}},
+	{Resource: "network_range", Description: "Network ranges and import rules (used to classify imported hosts)", Actions: []PermissionAction{
+		{Action: "read", Description: "View network ranges and import rules"},
+		{Action: "manage", Description: "Create, update and delete network ranges and import rules"},
+	}},
+	{Resource: "assessment", Description: "Assessments", Actions: []PermissionAction{
+		{Action: "read", Description: "View assessments"},
+		{Action: "create", Description: "Create assessments"},
+		{Action: "update", Description: "Update assessments"},
+		{Action: "delete", Description: "Delete assessments"},
+		{Action: "link_vulnerability", Description: "Link vulnerabilities and assets to assessments"},
+		{Action: "upload_report", Description: "Upload assessment reports"},
+		{Action: "request_retest", Description: "Mark assessment findings ready for retest and assign retesters"},
+		{Action: "retest", Description: "Record retest outcomes for assessment findings"},
+	}},
+	{Resource: "report", Description: "Reports", Actions: []PermissionAction{
+		{Action: "read", Description: "View reports"},
+		{Action: "generate", Description: "Generate reports"},
+		{Action: "export", Description: "Export reports"},
+	}},
+	{Resource: "integration", Description: "Scanner and ticketing integrations", Actions: []PermissionAction{
+		{Action: "read", Description: "View integration configurations"},
+		{Action: "configure", Description: "Create, update and delete integration configurations"},
+		{Action: "test", Description: "Test integration connections"},
+		{Action: "execute", Description: "Run integrations"},
+	}},
+	{Resource: "suppression", Description: "Finding suppression rules", Actions: []PermissionAction{
+		{Action: "read", Description: "View suppression rules"},
+		{Action: "manage", Description: "Create, update and delete suppression rules"},
+	}},
+	{Resource: "policy", Description: "Remediation policy rules and violations", Actions: []PermissionAction{
+		{Action: "read", Description: "View policy rules and violations"},
+		{Action: "manage", Description: "Create, update, delete and evaluate policy rules"},
+	}},
+}
+
+// catalogIndex maps resource -> action -> true for fast lookups
+var catalogIndex = func() map[string]map[string]bool {
+	index := make(map[string]map[string]bool, len(PermissionCatalog))
+	for _, resource := range PermissionCatalog {
+		actions := make(map[string]bool, len(resource.Actions))
+		for _, action := range resource.Actions {
+			actions[action.Action] = true
+		}
+		index[resource.Resource] = actions
+	}
+	return index
+}()
+
+// IsCatalogPermission reports whether resource:action is listed in the permission catalog
+func IsCatalogPermission(resource, action string) bool {
+	return catalogIndex[resource][action]
+}
+
+// ValidatePermissions checks every resource and action of a permission map against the catalog.
+// The wildcard action is accepted for any known resource.
+func ValidatePermissions(perms PermissionMap) error {
+	resources := make([]string, 0, len(perms))
+	for resource := range perms {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	for _, resource := range resources {
+		actions, ok := catalogIndex[resource]
+		if !ok {
+			return fmt.Errorf("unknown permission resource '%s'", resource)
+		}
+		for _, action := range perms[resource] {
+			if action != PermissionWildcard && !actions[action] {
+				return fmt.Errorf("unknown permission '%s:%s'", resource, action)
+			}
+		}
+	}
+	return nil
+}
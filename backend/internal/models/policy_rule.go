@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PolicyRule is a remediation policy open vulnerabilities are checked against, e.g. "no CRITICAL
+// vulnerability open longer than 7 days in PRODUCTION". An open vulnerability of the rule's
+// severity that is older than MaxAgeDays and affects an asset matching the populated asset
+// criteria violates the rule.
+type PolicyRule struct {
+	BaseModel
+	Name        string                `gorm:"type:varchar(255);not null" json:"name"`
+	Description string                `gorm:"type:text" json:"description,omitempty"`
+	Enabled     bool                  `gorm:"not null;default:true;index" json:"enabled"`
+	Severity    VulnerabilitySeverity `gorm:"type:varchar(20);not null" json:"severity"`
+	MaxAgeDays  int                   `gorm:"not null" json:"max_age_days"`
+
+	// Asset criteria; empty matches vulnerabilities regardless of their assets
+	Environment Environment       `gorm:"type:varchar(50)" json:"environment,omitempty"`
+	Criticality *AssetCriticality `gorm:"type:varchar(20)" json:"criticality,omitempty"`
+
+	LastEvaluatedAt *time.Time `gorm:"type:timestamp" json:"last_evaluated_at,omitempty"`
+
+	CreatedByID uuid.UUID `gorm:"type:uuid;not null" json:"created_by_id"`
+	CreatedBy   *User     `gorm:"foreignKey:CreatedByID;constraint:OnDelete:RESTRICT" json:"created_by,omitempty"`
+}
+
+// TableName specifies the table name for PolicyRule model
+func (PolicyRule) TableName() string {
+	return "policy_rules"
+}
+
+// PolicyViolationStatus is the state of a policy violation
+type PolicyViolationStatus string
+
+const (
+	PolicyViolationOpen     PolicyViolationStatus = "OPEN"
+	PolicyViolationResolved PolicyViolationStatus = "RESOLVED"
+)
+
+// PolicyViolation records a vulnerability breaching a policy rule. It stays open while the
+// vulnerability keeps violating the rule and is resolved once it no longer does (remediated,
+// downgraded, or the rule was changed, disabled or deleted).
+type PolicyViolation struct {
+	BaseModel
+	OrgID           *uuid.UUID            `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	RuleID          uuid.UUID             `gorm:"type:uuid;not null;uniqueIndex:idx_policy_violation_open,where:status = 'OPEN'" json:"rule_id"`
+	Rule            *PolicyRule           `gorm:"foreignKey:RuleID;constraint:OnDelete:CASCADE" json:"rule,omitempty"`
+	VulnerabilityID uuid.UUID             `gorm:"type:uuid;not null;index;uniqueIndex:idx_policy_violation_open,where:status = 'OPEN'" json:"vulnerability_id"`
+	Vulnerability   *Vulnerability        `gorm:"foreignKey:VulnerabilityID;constraint:OnDelete:CASCADE" json:"vulnerability,omitempty"`
+	Status          PolicyViolationStatus `gorm:"type:varchar(20);not null;default:OPEN;index" json:"status"`
+	Severity        VulnerabilitySeverity `gorm:"type:varchar(20);not null" json:"severity"`
+	DiscoveredAt    time.Time             `gorm:"type:timestamp;not null" json:"discovered_at"` // When the vulnerability was discovered
+	DueAt           time.Time             `gorm:"type:timestamp;not null" json:"due_at"`        // When the vulnerability started violating the rule
+	DetectedAt      time.Time             `gorm:"type:timestamp;not null;index" json:"detected_at"`
+	ResolvedAt      *time.Time            `gorm:"type:timestamp" json:"resolved_at,omitempty"`
+	Resolution      string                `gorm:"type:varchar(255)" json:"resolution,omitempty"`
+}
+
+// TableName specifies the table name for PolicyViolation model
+func (PolicyViolation) TableName() string {
+	return "policy_violations"
+}
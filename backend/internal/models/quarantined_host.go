@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuarantineReason is the import validation rule a report host failed
+type QuarantineReason string
+
+const (
+	QuarantineMissingIdentity QuarantineReason = "missing_identity" // Neither a hostname nor an IP address
+	QuarantineOutOfScope      QuarantineReason = "out_of_scope"     // IP address outside the import scope CIDRs
+	QuarantineTestData        QuarantineReason = "test_data"        // Loopback, documentation or example addresses and names
+)
+
+// QuarantineStatus is the resolution state of a quarantined host
+type QuarantineStatus string
+
+const (
+	QuarantinePending   QuarantineStatus = "pending"
+	QuarantineReleased  QuarantineStatus = "released"  // Imported after review, possibly with a corrected hostname or IP
+	QuarantineDismissed QuarantineStatus = "dismissed" // Discarded as junk
+)
+
+// QuarantinedHost is a report host an import held back because it failed validation. Its
+// results are kept so that it can be released into an import after review instead of
+// creating an asset from bad data.
+type QuarantinedHost struct {
+	BaseModel
+	OrgID           *uuid.UUID       `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	ImportJobID     uuid.UUID        `gorm:"type:uuid;not null;index" json:"import_job_id"`
+	Hostname        string           `gorm:"type:varchar(255)" json:"hostname,omitempty"`
+	IPAddress       string           `gorm:"type:varchar(100)" json:"ip_address,omitempty"`
+	ScanName        string           `gorm:"type:varchar(255)" json:"scan_name,omitempty"`
+	Reason          QuarantineReason `gorm:"type:varchar(30);not null;index" json:"reason"`
+	Detail          string           `gorm:"type:text" json:"detail"`
+	Vulnerabilities int              `gorm:"not null;default:0" json:"vulnerabilities"` // Vulnerabilities reported on the host
+	Results         string           `gorm:"type:jsonb;not null;default:'[]'" json:"-"` // The host's parsed results, imported on release
+
+	// Resolution
+	Status              QuarantineStatus `gorm:"type:varchar(20);not null;default:pending;index" json:"status"`
+	ResolvedByID        *uuid.UUID       `gorm:"type:uuid" json:"resolved_by_id,omitempty"`
+	ResolvedAt          *time.Time       `json:"resolved_at,omitempty"`
+	ResolutionNote      string           `gorm:"type:text" json:"resolution_note,omitempty"`
+	ReleasedImportJobID *uuid.UUID       `gorm:"type:uuid" json:"released_import_job_id,omitempty"` // Import the host was released into
+}
+
+// TableName specifies the table name for QuarantinedHost model
+func (QuarantinedHost) TableName() string {
+	return "quarantined_hosts"
+}
@@ -0,0 +1,108 @@
+package models
+
+// MigrationModels lists every model managed by GORM AutoMigrate, in migration order.
+// Register new models here; the readiness check uses the same list to detect schema drift.
+func MigrationModels() []interface{} {
+	return []interface{}{
+		// Tenancy
+		&Organization{},
+		&User{},
+		&Role{},
+		&VerificationToken{},
+		&AuthEvent{},
+		&Session{},
+		&RefreshToken{},
+		&UserPreference{},
+		&PolicyAcceptance{},
+		&OnboardingStepState{},
+		&SavedView{},
+		&Dashboard{},
+		&APIKey{}, // Managed by GORM with datatypes.JSON
+		&APIKeyUsage{},
+		// Teams
+		&Team{},
+		&TeamMember{},
+		// Vulnerability Management models
+		&Vulnerability{},
+		&AffectedSystem{},
+		&VulnerabilityStatusHistory{},
+		&VulnerabilityAssignmentHistory{},
+		&VulnerabilityComment{},
+		&VulnerabilityAffectedSystem{},
+		&VulnerabilityFinding{},
+		&FindingStatusHistory{},
+		&FindingComment{},
+		&RiskAcceptance{},
+		&SuppressionRule{},
+		&SuppressionLog{},
+		&PolicyRule{},
+		&PolicyViolation{},
+		&FindingAttachment{},
+		&AttachmentCustodyEvent{},
+		&VulnerabilityAttachment{},
+		// Asset Management models
+		&AssetTag{},
+		&VulnerabilityTag{},
+		&VulnerabilityClassification{},
+		&KnownExploit{},
+		&AssetHistory{},
+		&AssetPackage{},
+		&AssetSBOMComponent{},
+		&AssetGroup{},
+		&AssetGroupMember{},
+		&NetworkRange{},
+		&ImportRule{},
+		&CriticalityScoringProfile{},
+		&BusinessService{},
+		&BusinessServiceAsset{},
+		// Integration models
+		&IntegrationConfig{},
+		// Assessment models
+		&Assessment{},
+		&AssessmentVulnerability{},
+		&AssessmentAsset{},
+		&AssessmentAssetGroup{},
+		&AssessmentReport{},
+		&AssessmentRetest{},
+		&AuditorShareToken{},
+		&AuditorShareAccess{},
+		// Report narratives
+		&ReportSummary{},
+		// Report branding
+		&ReportTemplate{},
+		// System Settings
+		&SystemSetting{},
+		// Notifications
+		&Notification{},
+		// Search index outbox
+		&SearchOutboxEntry{},
+		// Dashboard metrics snapshots
+		&DailyMetricsSnapshot{},
+		// Data retention run reports
+		&RetentionRun{},
+		// Backup and restore jobs
+		&BackupJob{},
+		// Scanner result imports
+		&ImportJob{},
+		&QuarantinedHost{},
+		&ImportChange{},
+		// Vulnerability escalation
+		&EscalationPolicy{},
+		&VulnerabilityEscalation{},
+		// Vulnerability auto-assignment
+		&AssignmentRule{},
+		// Two-person rule for closing critical vulnerabilities
+		&VulnerabilityCloseApproval{},
+		// Vulnerability reports received by email
+		&InboundEmail{},
+		// Outgoing email, its delivery status events and the queue it is sent from
+		&EmailDelivery{},
+		&EmailDeliveryEvent{},
+		&EmailOutboxEntry{},
+		// Public disclosure triage queue
+		&Disclosure{},
+		// Background jobs run once across instances
+		&ScheduledJob{},
+		// Add other models as they are created
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportSummaryKind identifies what a report summary describes
+type ReportSummaryKind string
+
+const (
+	ReportSummaryExecutive  ReportSummaryKind = "executive"  // Executive report of a date range
+	ReportSummaryAssessment ReportSummaryKind = "assessment" // One assessment
+)
+
+// ReportSummary is a natural-language executive summary and key-risk narrative written by the
+// configured language model. Summaries are kept alongside the report they describe, newest
+// first, so a report can be re-summarized without losing earlier versions.
+type ReportSummary struct {
+	BaseModel
+	OrgID *uuid.UUID        `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Kind  ReportSummaryKind `gorm:"type:varchar(20);not null;index:idx_report_summary_subject" json:"kind"`
+
+	// Executive reports are identified by their period, assessments by ID
+	PeriodStart  *time.Time  `gorm:"type:date;index:idx_report_summary_subject" json:"period_start,omitempty"`
+	PeriodEnd    *time.Time  `gorm:"type:date;index:idx_report_summary_subject" json:"period_end,omitempty"`
+	AssessmentID *uuid.UUID  `gorm:"type:uuid;index" json:"assessment_id,omitempty"`
+	Assessment   *Assessment `gorm:"foreignKey:AssessmentID;constraint:OnDelete:CASCADE" json:"-"`
+
+	ExecutiveSummary string `gorm:"type:text;not null" json:"executive_summary"`
+	KeyRisks         string `gorm:"type:text" json:"key_risks"` // Key-risk narrative
+
+	// Model that wrote the text
+	Provider string `gorm:"type:varchar(20);not null" json:"provider"`
+	Model    string `gorm:"type:varchar(255);not null" json:"model"`
+
+	GeneratedByID uuid.UUID `gorm:"type:uuid;not null" json:"generated_by_id"`
+	GeneratedBy   *User     `gorm:"foreignKey:GeneratedByID;constraint:OnDelete:RESTRICT" json:"generated_by,omitempty"`
+}
+
+// TableName specifies the table name for ReportSummary model
+func (ReportSummary) TableName() string {
+	return "report_summaries"
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// ReportSections switches the parts of a generated report on or off. Assessment reports
+// (PDF/DOCX) and CSV exports map them to their own sections:
+//
+//	summary      executive summary and findings overview; the summary block of CSV exports
+//	scope        assets in scope (assessment reports)
+//	findings     per-finding details; vulnerability listings, key risks and the audit trail in CSV exports
+//	evidence     evidence images; evidence integrity in the audit CSV export
+//	remediation  remediation summary; recommended actions and assignee workload in CSV exports
+//	retests      retest status and rounds; retests in the audit CSV export
+//
+// Breakdowns by severity, status or month are always included.
+type ReportSections struct {
+	Summary     bool `gorm:"not null;default:true" json:"summary"`
+	Scope       bool `gorm:"not null;default:true" json:"scope"`
+	Findings    bool `gorm:"not null;default:true" json:"findings"`
+	Evidence    bool `gorm:"not null;default:true" json:"evidence"`
+	Remediation bool `gorm:"not null;default:true" json:"remediation"`
+	Retests     bool `gorm:"not null;default:true" json:"retests"`
+}
+
+// AllReportSections returns sections with every part switched on
+func AllReportSections() ReportSections {
+	return ReportSections{Summary: true, Scope: true, Findings: true, Evidence: true, Remediation: true, Retests: true}
+}
+
+// ReportTemplate brands generated reports and exports (e.g. for an MSSP's client deliverables):
+// a logo and company name on the cover, intro text and the sections to include. The default
+// template applies when a report doesn't name one.
+type ReportTemplate struct {
+	BaseModel
+	Name        string         `gorm:"type:varchar(255);not null;uniqueIndex:idx_report_templates_name,where:deleted_at IS NULL" json:"name"`
+	Description string         `gorm:"type:text" json:"description,omitempty"`
+	IsDefault   bool           `gorm:"not null;default:false" json:"is_default"`
+	CompanyName string         `gorm:"type:varchar(255)" json:"company_name,omitempty"`
+	IntroText   string         `gorm:"type:text" json:"intro_text,omitempty"`
+	Sections    ReportSections `gorm:"embedded;embeddedPrefix:section_" json:"sections"`
+
+	// Logo shown on the cover, as uploaded (PNG, JPEG or GIF); served by the logo endpoint
+	Logo         []byte `gorm:"type:bytea" json:"-"`
+	LogoMimeType string `gorm:"type:varchar(50)" json:"logo_mime_type,omitempty"`
+	HasLogo      bool   `gorm:"-" json:"has_logo"`
+
+	CreatedByID uuid.UUID `gorm:"type:uuid;not null" json:"created_by_id"`
+}
+
+// TableName specifies the table name for ReportTemplate model
+func (ReportTemplate) TableName() string {
+	return "report_templates"
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RetentionCategory identifies a kind of data purged by the data retention job
+type RetentionCategory string
+
+const (
+	RetentionResolvedVulnerabilities RetentionCategory = "resolved_vulnerabilities" // Resolved, verified, closed and false positive vulnerabilities
+	RetentionAuthEvents              RetentionCategory = "auth_events"
+	RetentionSessions                RetentionCategory = "sessions"       // Expired and revoked sessions
+	RetentionAuditLogs               RetentionCategory = "audit_logs"     // Asset history, assignment history, suppression logs and API key usage
+	RetentionReportExports           RetentionCategory = "report_exports" // Server-generated assessment reports and their files
+	RetentionDeletedItems            RetentionCategory = "deleted_items"  // Vulnerabilities and assets in the recycle bin
+)
+
+// RetentionRunTrigger records what started a retention run
+type RetentionRunTrigger string
+
+const (
+	RetentionTriggerScheduled RetentionRunTrigger = "scheduled"
+	RetentionTriggerManual    RetentionRunTrigger = "manual"
+)
+
+// RetentionCategoryResult is the outcome of purging one category
+type RetentionCategoryResult struct {
+	Category      RetentionCategory `json:"category"`
+	RetentionDays int               `json:"retention_days"`
+	Cutoff        time.Time         `json:"cutoff"`  // Records older than this are purged
+	Matched       int64             `json:"matched"` // Records older than the cutoff
+	Deleted       int64             `json:"deleted"`
+	Error         string            `json:"error,omitempty"`
+}
+
+// RetentionRun is the report of one run of the data retention job. Category results are
+// stored as JSON.
+type RetentionRun struct {
+	ID           uuid.UUID                 `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	Trigger      RetentionRunTrigger       `gorm:"type:varchar(20);not null" json:"trigger"`
+	TriggeredBy  *uuid.UUID                `gorm:"type:uuid" json:"triggered_by,omitempty"` // Nil for scheduled runs
+	DryRun       bool                      `gorm:"not null;default:false" json:"dry_run"`
+	StartedAt    time.Time                 `gorm:"not null;index" json:"started_at"`
+	FinishedAt   time.Time                 `gorm:"not null" json:"finished_at"`
+	TotalDeleted int64                     `gorm:"not null;default:0" json:"total_deleted"`
+	Failed       bool                      `gorm:"not null;default:false" json:"failed"` // At least one category failed
+	Results      string                    `gorm:"type:jsonb;not null;default:'[]'" json:"-"`
+	Categories   []RetentionCategoryResult `gorm:"-" json:"categories"`
+}
+
+// TableName specifies the table name for RetentionRun model
+func (RetentionRun) TableName() string {
+	return "retention_runs"
+}
+
+// BeforeSave serializes the category results into the JSONB column
+func (r *RetentionRun) BeforeSave(tx *gorm.DB) error {
+	if r.Categories == nil {
+		r.Categories = []RetentionCategoryResult{}
+	}
+	data, err := json.Marshal(r.Categories)
+	if err != nil {
+		return err
+	}
+	r.Results = string(data)
+	return nil
+}
+
+// AfterFind parses the JSONB column into category results
+func (r *RetentionRun) AfterFind(tx *gorm.DB) error {
+	r.Categories = []RetentionCategoryResult{}
+	if r.Results == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(r.Results), &r.Categories)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RiskAcceptanceStatus represents the review state of a risk acceptance request
+type RiskAcceptanceStatus string
+
+const (
+	RiskAcceptancePending   RiskAcceptanceStatus = "PENDING"
+	RiskAcceptanceApproved  RiskAcceptanceStatus = "APPROVED"
+	RiskAcceptanceRejected  RiskAcceptanceStatus = "REJECTED"
+	RiskAcceptanceCancelled RiskAcceptanceStatus = "CANCELLED"
+	RiskAcceptanceExpired   RiskAcceptanceStatus = "EXPIRED"
+)
+
+// RiskAcceptance is a request to accept the risk of a finding until an expiry date.
+// Approved requests move the finding to ACCEPTED; it re-opens automatically on expiry.
+type RiskAcceptance struct {
+	BaseModel
+	OrgID                *uuid.UUID            `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	FindingID            uuid.UUID             `gorm:"type:uuid;not null;index:idx_risk_acceptance_finding" json:"finding_id"`
+	Finding              *VulnerabilityFinding `gorm:"foreignKey:FindingID;-:migration" json:"finding,omitempty"`
+	Status               RiskAcceptanceStatus  `gorm:"type:varchar(20);not null;default:PENDING;index:idx_risk_acceptance_status" json:"status"`
+	Justification        string                `gorm:"type:text;not null" json:"justification"`
+	CompensatingControls string                `gorm:"type:text" json:"compensating_controls,omitempty"`
+	ExpiresAt            time.Time             `gorm:"type:timestamp;not null;index" json:"expires_at"`
+
+	// Request tracking
+	RequestedByID uuid.UUID `gorm:"type:uuid;not null;index" json:"requested_by_id"`
+	RequestedBy   *User     `gorm:"foreignKey:RequestedByID;constraint:OnDelete:RESTRICT" json:"requested_by,omitempty"`
+
+	// Review tracking
+	ReviewedByID *uuid.UUID `gorm:"type:uuid" json:"reviewed_by_id,omitempty"`
+	ReviewedBy   *User      `gorm:"foreignKey:ReviewedByID;constraint:OnDelete:SET NULL" json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time `gorm:"type:timestamp" json:"reviewed_at,omitempty"`
+	ReviewNotes  string     `gorm:"type:text" json:"review_notes,omitempty"`
+
+	// Set when an approved acceptance lapses and the finding is re-opened
+	ExpiredAt *time.Time `gorm:"type:timestamp" json:"expired_at,omitempty"`
+}
+
+// TableName specifies the table name for RiskAcceptance model
+func (RiskAcceptance) TableName() string {
+	return "risk_acceptances"
+}
@@ -1,6 +1,13 @@
 package models
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// MaxRoleInheritanceDepth limits how many ancestors a role may inherit permissions from
+const MaxRoleInheritanceDepth = 5
 
 // Role represents a user role for RBAC
 type Role struct {
@@ -11,11 +18,17 @@ type Role struct {
 
 	// Permission Data stored as JSON
 	Permissions string `gorm:"type:jsonb" json:"permissions,omitempty"`
+	// DeniedPermissions override grants, including wildcard and inherited ones
+	DeniedPermissions string `gorm:"type:jsonb;default:'{}'" json:"denied_permissions,omitempty"`
+	// Constraints restrict modifying actions to assets with matching attributes (ABAC)
+	Constraints string `gorm:"type:jsonb;default:'[]'" json:"constraints,omitempty"`
 
 	// Hierarchy
-	Level     int  `gorm:"not null;default:0" json:"level"`
-	IsDefault bool `gorm:"default:false" json:"is_default"`
-	IsSystem  bool `gorm:"default:false" json:"is_system"`
+	// ParentID names a role of equal or lower level whose permissions (and deny rules) are inherited
+	ParentID  *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	Level     int        `gorm:"not null;default:0" json:"level"`
+	IsDefault bool       `gorm:"default:false" json:"is_default"`
+	IsSystem  bool       `gorm:"default:false" json:"is_system"`
 }
 
 // TableName specifies the table name for Role model
@@ -28,15 +41,7 @@ type PermissionMap map[string][]string
 
 // GetPermissions parses the JSON permissions into a map
 func (r *Role) GetPermissions() (PermissionMap, error) {
-	if r.Permissions == "" {
-		return PermissionMap{}, nil
-	}
-
-	var perms PermissionMap
-	if err := json.Unmarshal([]byte(r.Permissions), &perms); err != nil {
-		return nil, err
-	}
-	return perms, nil
+	return parsePermissionMap(r.Permissions)
 }
 
 // SetPermissions converts a permission map to JSON string
@@ -49,23 +54,139 @@ func (r *Role) SetPermissions(perms PermissionMap) error {
 	return nil
 }
 
-// HasPermission checks if the role has a specific permission
+// GetDeniedPermissions parses the JSON deny rules into a map
+func (r *Role) GetDeniedPermissions() (PermissionMap, error) {
+	return parsePermissionMap(r.DeniedPermissions)
+}
+
+// SetDeniedPermissions converts a deny rule map to JSON string
+func (r *Role) SetDeniedPermissions(perms PermissionMap) error {
+	if perms == nil {
+		perms = PermissionMap{}
+	}
+	data, err := json.Marshal(perms)
+	if err != nil {
+		return err
+	}
+	r.DeniedPermissions = string(data)
+	return nil
+}
+
+// GetConstraints parses the JSON attribute constraints
+func (r *Role) GetConstraints() ([]AccessConstraint, error) {
+	if r.Constraints == "" || r.Constraints == "null" {
+		return []AccessConstraint{}, nil
+	}
+
+	var constraints []AccessConstraint
+	if err := json.Unmarshal([]byte(r.Constraints), &constraints); err != nil {
+		return nil, err
+	}
+	return constraints, nil
+}
+
+// SetConstraints converts attribute constraints to JSON string
+func (r *Role) SetConstraints(constraints []AccessConstraint) error {
+	if constraints == nil {
+		constraints = []AccessConstraint{}
+	}
+	data, err := json.Marshal(constraints)
+	if err != nil {
+		return err
+	}
+	r.Constraints = string(data)
+	return nil
+}
+
+// HasPermission checks if the role itself grants a specific permission, honoring its deny rules.
+// Inherited permissions are resolved by RoleService.
 func (r *Role) HasPermission(resource, action string) bool {
-	perms, err := r.GetPermissions()
+	effective, err := ResolvePermissions([]*Role{r})
 	if err != nil {
 		return false
 	}
+	return effective.Allows(resource, action)
+}
+
+// EffectivePermissions is the result of merging a role with its ancestors
+type EffectivePermissions struct {
+	Granted     PermissionMap      `json:"granted"`
+	Denied      PermissionMap      `json:"denied"`
+	Constraints []AccessConstraint `json:"constraints"`
+}
 
-	actions, ok := perms[resource]
-	if !ok {
+// ResolvePermissions merges the grants and deny rules of a role chain (the role first, then its ancestors)
+func ResolvePermissions(chain []*Role) (*EffectivePermissions, error) {
+	effective := &EffectivePermissions{Granted: PermissionMap{}, Denied: PermissionMap{}, Constraints: []AccessConstraint{}}
+	for _, role := range chain {
+		granted, err := role.GetPermissions()
+		if err != nil {
+			return nil, err
+		}
+		denied, err := role.GetDeniedPermissions()
+		if err != nil {
+			return nil, err
+		}
+		constraints, err := role.GetConstraints()
+		if err != nil {
+			return nil, err
+		}
+		mergePermissions(effective.Granted, granted)
+		mergePermissions(effective.Denied, denied)
+		// Constraints accumulate: a role is bound by every constraint of its ancestors
+		effective.Constraints = append(effective.Constraints, constraints...)
+	}
+	return effective, nil
+}
+
+// Allows reports whether resource:action is granted and not denied
+func (e *EffectivePermissions) Allows(resource, action string) bool {
+	if containsAction(e.Denied[resource], action) {
 		return false
 	}
+	return containsAction(e.Granted[resource], action)
+}
+
+// parsePermissionMap decodes a JSON permission map, treating an empty value as no permissions
+func parsePermissionMap(data string) (PermissionMap, error) {
+	if data == "" || data == "null" {
+		return PermissionMap{}, nil
+	}
+
+	var perms PermissionMap
+	if err := json.Unmarshal([]byte(data), &perms); err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
 
+// mergePermissions adds the actions of src to dst, skipping duplicates
+func mergePermissions(dst, src PermissionMap) {
+	for resource, actions := range src {
+		for _, action := range actions {
+			if !containsExact(dst[resource], action) {
+				dst[resource] = append(dst[resource], action)
+			}
+		}
+	}
+}
+
+// containsAction reports whether actions include action or the wildcard
+func containsAction(actions []string, action string) bool {
 	for _, a := range actions {
-		if a == action || a == "*" {
+		if a == action || a == PermissionWildcard {
 			return true
 		}
 	}
+	return false
+}
 
+// containsExact reports whether actions include action literally
+func containsExact(actions []string, action string) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
 	return false
 }
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SavedViewResource identifies which list endpoint a saved view applies to
+type SavedViewResource string
+
+const (
+	SavedViewResourceVulnerability SavedViewResource = "vulnerability"
+	SavedViewResourceAsset         SavedViewResource = "asset"
+)
+
+// SavedView is a named filter and sort combination for a list endpoint.
+// Filters are stored as the list endpoint's query parameters.
+type SavedView struct {
+	BaseModel
+	OrgID        *uuid.UUID        `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Name         string            `gorm:"type:varchar(255);not null" json:"name"`
+	Description  string            `gorm:"type:text" json:"description,omitempty"`
+	ResourceType SavedViewResource `gorm:"type:varchar(50);not null;index:idx_saved_view_owner_resource" json:"resource_type"`
+	Filters      string            `gorm:"type:jsonb;not null;default:'{}'" json:"-"`
+	FilterValues map[string]string `gorm:"-" json:"filters"`
+	Shared       bool              `gorm:"not null;default:false;index" json:"shared"` // Visible to all users
+	OwnerID      uuid.UUID         `gorm:"type:uuid;not null;index:idx_saved_view_owner_resource" json:"owner_id"`
+	Owner        *User             `gorm:"foreignKey:OwnerID;constraint:OnDelete:CASCADE" json:"owner,omitempty"`
+}
+
+// TableName specifies the table name for SavedView model
+func (SavedView) TableName() string {
+	return "saved_views"
+}
+
+// BeforeSave serializes the filter values into the JSONB column
+func (v *SavedView) BeforeSave(tx *gorm.DB) error {
+	if v.FilterValues == nil {
+		v.FilterValues = map[string]string{}
+	}
+	data, err := json.Marshal(v.FilterValues)
+	if err != nil {
+		return err
+	}
+	v.Filters = string(data)
+	return nil
+}
+
+// AfterFind parses the JSONB column into filter values
+func (v *SavedView) AfterFind(tx *gorm.DB) error {
+	v.FilterValues = map[string]string{}
+	if v.Filters == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(v.Filters), &v.FilterValues)
+}
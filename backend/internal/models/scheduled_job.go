@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// ScheduledJobStatus is the outcome of a job's last run
+type ScheduledJobStatus string
+
+const (
+	ScheduledJobRunning   ScheduledJobStatus = "RUNNING"
+	ScheduledJobSucceeded ScheduledJobStatus = "SUCCEEDED"
+	ScheduledJobFailed    ScheduledJobStatus = "FAILED"
+)
+
+// ScheduledJob is the registry entry of a background job that runs on one instance at a time.
+// Instances claim each run by advancing LastStartedAt, so a period's run happens exactly once
+// across replicas.
+type ScheduledJob struct {
+	BaseModel
+	Name            string             `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	Description     string             `gorm:"type:varchar(500)" json:"description,omitempty"`
+	IntervalSeconds int64              `gorm:"not null" json:"interval_seconds"`
+	LastStartedAt   *time.Time         `gorm:"type:timestamp" json:"last_started_at,omitempty"`
+	LastFinishedAt  *time.Time         `gorm:"type:timestamp" json:"last_finished_at,omitempty"`
+	LastStatus      ScheduledJobStatus `gorm:"type:varchar(20)" json:"last_status,omitempty"`
+	LastError       string             `gorm:"type:text" json:"last_error,omitempty"`
+	LastDurationMS  int64              `json:"last_duration_ms"`
+	LastInstance    string             `gorm:"type:varchar(255)" json:"last_instance,omitempty"` // Instance that ran the job last
+	RunCount        int64              `gorm:"not null;default:0" json:"run_count"`
+}
+
+// TableName specifies the table name for ScheduledJob model
+func (ScheduledJob) TableName() string {
+	return "scheduled_jobs"
+}
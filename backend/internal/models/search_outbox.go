@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Search index entity types
+const (
+	SearchEntityVulnerability = "vulnerability"
+	SearchEntityAsset         = "asset"
+	SearchEntityFinding       = "finding"
+)
+
+// SearchOutboxEntry records a change that must be mirrored into the search index.
+// Entries are written in the same transaction as the change and processed asynchronously.
+type SearchOutboxEntry struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	EntityType  string     `gorm:"type:varchar(30);not null" json:"entity_type"`
+	EntityID    uuid.UUID  `gorm:"type:uuid;not null" json:"entity_id"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt   time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_search_outbox_pending,where:processed_at IS NULL" json:"created_at"`
+	ProcessedAt *time.Time `gorm:"type:timestamp" json:"processed_at,omitempty"`
+}
+
+// TableName specifies the table name for SearchOutboxEntry model
+func (SearchOutboxEntry) TableName() string {
+	return "search_outbox"
+}
@@ -6,20 +6,42 @@ import (
 	"github.com/google/uuid"
 )
 
-// Session represents an active user session
+// Session represents a signed-in device. Requests authenticate with a short-lived access
+// token (Token) that is replaced whenever the device redeems its refresh token; the chain of
+// refresh tokens lives until ExpiresAt or until the session is revoked.
 type Session struct {
 	BaseModel
-	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
-	User       *User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	Token      string     `gorm:"uniqueIndex;not null;type:varchar(255)" json:"token"`
-	ExpiresAt  time.Time  `gorm:"not null;index" json:"expires_at"`
-	IPAddress  string     `gorm:"type:varchar(45)" json:"ip_address,omitempty"`
-	UserAgent  string     `gorm:"type:text" json:"user_agent,omitempty"`
-	IsActive   bool       `gorm:"default:true;index" json:"is_active"`
-	LastUsedAt *time.Time `gorm:"index" json:"last_used_at,omitempty"`
-	RevokedAt  *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User            *User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Token           string     `gorm:"uniqueIndex;not null;type:varchar(255)" json:"token"`
+	AccessExpiresAt time.Time  `gorm:"index" json:"access_expires_at"`
+	ExpiresAt       time.Time  `gorm:"not null;index" json:"expires_at"`
+	IPAddress       string     `gorm:"type:varchar(45)" json:"ip_address,omitempty"`
+	UserAgent       string     `gorm:"type:text" json:"user_agent,omitempty"`
+	Browser         string     `gorm:"type:varchar(100)" json:"browser,omitempty"`
+	OS              string     `gorm:"type:varchar(100)" json:"os,omitempty"`
+	DeviceType      string     `gorm:"type:varchar(20)" json:"device_type,omitempty"`
+	Location        string     `gorm:"type:varchar(255)" json:"location,omitempty"`
+	IsActive        bool       `gorm:"default:true;index" json:"is_active"`
+	LastUsedAt      *time.Time `gorm:"index" json:"last_used_at,omitempty"`
+	RefreshedAt     *time.Time `json:"refreshed_at,omitempty"`
+	RevokedAt       *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	RevokeReason    string     `gorm:"type:varchar(50)" json:"revoke_reason,omitempty"`
+	// Set when an administrator is acting as the user; such sessions cannot be refreshed
+	ImpersonatorID      *uuid.UUID `gorm:"type:uuid;index" json:"impersonator_id,omitempty"`
+	ImpersonationReason string     `gorm:"type:text" json:"impersonation_reason,omitempty"`
 }
 
+// Reasons recorded when a session is revoked
+const (
+	SessionRevokeLogout       = "logout"
+	SessionRevokeUser         = "revoked_by_user"
+	SessionRevokeOthers       = "revoked_other_sessions"
+	SessionRevokePassword     = "password_changed"
+	SessionRevokeRefreshReuse = "refresh_token_reuse"
+	SessionRevokeImpersonated = "impersonation_ended"
+)
+
 // TableName specifies the table name for Session model
 func (Session) TableName() string {
 	return "sessions"
@@ -30,16 +52,31 @@ func (s *Session) IsExpired() bool {
 	return time.Now().After(s.ExpiresAt)
 }
 
+// IsAccessExpired checks if the session's current access token has expired. Sessions
+// created before access tokens were introduced fall back to the session expiry.
+func (s *Session) IsAccessExpired() bool {
+	if s.AccessExpiresAt.IsZero() {
+		return s.IsExpired()
+	}
+	return time.Now().After(s.AccessExpiresAt)
+}
+
 // IsValid checks if the session is valid (not expired, active, not revoked)
 func (s *Session) IsValid() bool {
 	return s.IsActive && !s.IsExpired() && s.RevokedAt == nil
 }
 
+// IsImpersonation reports whether an administrator is acting as the user in this session
+func (s *Session) IsImpersonation() bool {
+	return s.ImpersonatorID != nil
+}
+
 // Revoke marks the session as revoked
-func (s *Session) Revoke() {
+func (s *Session) Revoke(reason string) {
 	now := time.Now()
 	s.IsActive = false
 	s.RevokedAt = &now
+	s.RevokeReason = reason
 }
 
 // UpdateLastUsed updates the last used timestamp
@@ -50,26 +87,98 @@ func (s *Session) UpdateLastUsed() {
 
 // PublicSession represents the public-facing session data
 type PublicSession struct {
-	ID         string     `json:"id"`
-	UserID     string     `json:"user_id"`
-	ExpiresAt  time.Time  `json:"expires_at"`
-	IPAddress  string     `json:"ip_address,omitempty"`
-	UserAgent  string     `json:"user_agent,omitempty"`
-	IsActive   bool       `json:"is_active"`
-	CreatedAt  time.Time  `json:"created_at"`
-	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	IPAddress    string     `json:"ip_address,omitempty"`
+	UserAgent    string     `json:"user_agent,omitempty"`
+	Browser      string     `json:"browser,omitempty"`
+	OS           string     `json:"os,omitempty"`
+	DeviceType   string     `json:"device_type,omitempty"`
+	Location     string     `json:"location,omitempty"`
+	IsActive     bool       `json:"is_active"`
+	IsCurrent    bool       `json:"is_current"`
+	Impersonated bool       `json:"impersonated"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	RefreshedAt  *time.Time `json:"refreshed_at,omitempty"`
 }
 
 // ToPublic converts a Session to PublicSession
 func (s *Session) ToPublic() PublicSession {
 	return PublicSession{
-		ID:         s.ID.String(),
-		UserID:     s.UserID.String(),
-		ExpiresAt:  s.ExpiresAt,
-		IPAddress:  s.IPAddress,
-		UserAgent:  s.UserAgent,
-		IsActive:   s.IsActive,
-		CreatedAt:  s.CreatedAt,
-		LastUsedAt: s.LastUsedAt,
+		ID:           s.ID.String(),
+		UserID:       s.UserID.String(),
+		ExpiresAt:    s.ExpiresAt,
+		IPAddress:    s.IPAddress,
+		UserAgent:    s.UserAgent,
+		Browser:      s.Browser,
+		OS:           s.OS,
+		DeviceType:   s.DeviceType,
+		Location:     s.Location,
+		IsActive:     s.IsActive,
+		Impersonated: s.IsImpersonation(),
+		CreatedAt:    s.CreatedAt,
+		LastUsedAt:   s.LastUsedAt,
+		RefreshedAt:  s.RefreshedAt,
 	}
 }
+
+// ImpersonationInfo describes an impersonation session to the client, which shows a banner
+// while Banner is set
+type ImpersonationInfo struct {
+	Banner            bool      `json:"banner"`
+	SessionID         string    `json:"session_id"`
+	UserID            string    `json:"user_id"`
+	ImpersonatorID    string    `json:"impersonator_id"`
+	ImpersonatorEmail string    `json:"impersonator_email,omitempty"`
+	ImpersonatorName  string    `json:"impersonator_name,omitempty"`
+	Reason            string    `json:"reason"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// ToImpersonationInfo describes an impersonation session; impersonator may be nil when the
+// administrator's account can no longer be loaded. It returns nil for regular sessions.
+func (s *Session) ToImpersonationInfo(impersonator *User) *ImpersonationInfo {
+	if !s.IsImpersonation() {
+		return nil
+	}
+	info := &ImpersonationInfo{
+		Banner:         true,
+		SessionID:      s.ID.String(),
+		UserID:         s.UserID.String(),
+		ImpersonatorID: s.ImpersonatorID.String(),
+		Reason:         s.ImpersonationReason,
+		ExpiresAt:      s.ExpiresAt,
+	}
+	if impersonator != nil {
+		info.ImpersonatorEmail = impersonator.Email
+		info.ImpersonatorName = impersonator.Name
+	}
+	return info
+}
+
+// RefreshToken is one link of a session's refresh chain. Only the SHA-256 hash of the token
+// is stored. Redeeming a token marks it used and issues its successor; a used token that is
+// presented again means the chain leaked, and the whole session is revoked.
+type RefreshToken struct {
+	BaseModel
+	SessionID  uuid.UUID  `gorm:"type:uuid;not null;index" json:"session_id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	TokenHash  string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedAt     *time.Time `json:"used_at,omitempty"`
+	ReplacedBy *uuid.UUID `gorm:"type:uuid" json:"replaced_by,omitempty"`
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	IPAddress  string     `gorm:"type:varchar(45)" json:"ip_address,omitempty"`
+}
+
+// TableName specifies the table name for RefreshToken model
+func (RefreshToken) TableName() string {
+	return "session_refresh_tokens"
+}
+
+// IsUsable reports whether the token can still be redeemed
+func (t *RefreshToken) IsUsable() bool {
+	return t.UsedAt == nil && t.RevokedAt == nil && time.Now().Before(t.ExpiresAt)
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SuppressionAction is the status applied to findings matched by a suppression rule
+type SuppressionAction string
+
+const (
+	SuppressionActionFalsePositive SuppressionAction = "FALSE_POSITIVE"
+	SuppressionActionSuppress      SuppressionAction = "SUPPRESSED"
+)
+
+// SuppressionRule automatically marks matching findings from future imports as
+// false positive or suppressed. All populated criteria must match (AND).
+type SuppressionRule struct {
+	BaseModel
+	OrgID       *uuid.UUID        `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Name        string            `gorm:"type:varchar(255);not null" json:"name"`
+	Description string            `gorm:"type:text" json:"description,omitempty"`
+	Action      SuppressionAction `gorm:"type:varchar(20);not null;default:SUPPRESSED" json:"action"`
+	Enabled     bool              `gorm:"not null;default:true;index" json:"enabled"`
+	ExpiresAt   *time.Time        `gorm:"type:timestamp" json:"expires_at,omitempty"` // Rule stops matching after this time
+
+	// Match criteria
+	PluginID string          `gorm:"type:varchar(50);index" json:"plugin_id,omitempty"`
+	CVEID    string          `gorm:"type:varchar(50)" json:"cve_id,omitempty"`
+	AssetID  *uuid.UUID      `gorm:"type:uuid" json:"asset_id,omitempty"`
+	Asset    *AffectedSystem `gorm:"foreignKey:AssetID;constraint:OnDelete:CASCADE" json:"asset,omitempty"`
+	CIDR     string          `gorm:"type:varchar(50)" json:"cidr,omitempty"`
+
+	// Hit tracking
+	HitCount  int64      `gorm:"not null;default:0" json:"hit_count"`
+	LastHitAt *time.Time `gorm:"type:timestamp" json:"last_hit_at,omitempty"`
+
+	CreatedByID uuid.UUID `gorm:"type:uuid;not null" json:"created_by_id"`
+	CreatedBy   *User     `gorm:"foreignKey:CreatedByID;constraint:OnDelete:RESTRICT" json:"created_by,omitempty"`
+}
+
+// TableName specifies the table name for SuppressionRule model
+func (SuppressionRule) TableName() string {
+	return "suppression_rules"
+}
+
+// SuppressionLog is the audit record of a finding suppressed by a rule
+type SuppressionLog struct {
+	ID        uuid.UUID             `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	RuleID    uuid.UUID             `gorm:"type:uuid;not null;index:idx_suppression_log_rule" json:"rule_id"`
+	Rule      *SuppressionRule      `gorm:"foreignKey:RuleID;constraint:OnDelete:CASCADE" json:"rule,omitempty"`
+	FindingID uuid.UUID             `gorm:"type:uuid;not null;index:idx_suppression_log_finding" json:"finding_id"`
+	Finding   *VulnerabilityFinding `gorm:"foreignKey:FindingID;-:migration" json:"finding,omitempty"`
+	Action    SuppressionAction     `gorm:"type:varchar(20);not null" json:"action"`
+	OldStatus FindingStatus         `gorm:"type:varchar(20);not null" json:"old_status"`
+	Source    string                `gorm:"type:varchar(50)" json:"source,omitempty"` // e.g. nessus import
+	CreatedAt time.Time             `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for SuppressionLog model
+func (SuppressionLog) TableName() string {
+	return "suppression_logs"
+}
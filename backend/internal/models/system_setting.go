@@ -13,6 +13,47 @@ const (
 	// MCP Server settings
 	SystemSettingMCPEnabled SystemSettingKey = "mcp_server_enabled"
 
+	// Attachment storage backend (JSON: driver, presign TTL and driver options)
+	SystemSettingAttachmentStorage SystemSettingKey = "attachment_storage"
+
+	// Allowed MIME types and maximum sizes per attachment category (JSON)
+	SystemSettingAttachmentPolicy SystemSettingKey = "attachment_policy"
+
+	// Language model that writes report summaries (JSON: provider, base_url, model, ...)
+	SystemSettingReportSummarizer SystemSettingKey = "report_summarizer"
+
+	// Forwarding of audit and vulnerability lifecycle events to a SIEM (JSON: host, port, protocol, format, ...)
+	SystemSettingSIEMForwarder SystemSettingKey = "siem_forwarder"
+
+	// Retention periods of purged data (JSON: enabled and days per category)
+	SystemSettingDataRetention SystemSettingKey = "data_retention"
+
+	// Closing findings that consecutive scans no longer report (JSON: enabled and clean_scans)
+	SystemSettingFindingAutoClose SystemSettingKey = "finding_auto_close"
+
+	// Two-person rule for closing CRITICAL vulnerabilities ("true" or "false")
+	SystemSettingCriticalCloseApproval SystemSettingKey = "critical_close_approval"
+
+	// Mailbox and inbound webhook that turn emailed vulnerability reports into drafts (JSON: host, username, allowed_senders, ...)
+	SystemSettingEmailIngestion SystemSettingKey = "email_ingestion"
+
+	// Per-minute quotas of each user and API key by route group (JSON: enabled, default, groups)
+	SystemSettingAPIRateLimits SystemSettingKey = "api_rate_limits"
+
+	// Client addresses admitted to /api/v1/admin routes (JSON: allow and deny CIDR lists)
+	SystemSettingAdminIPAccess SystemSettingKey = "admin_ip_access"
+
+	// Policies users must accept during onboarding, with their current versions (JSON: policies)
+	SystemSettingOnboardingPolicies SystemSettingKey = "onboarding_policies"
+
+	// Provider outgoing email is sent with (JSON: provider, from and its credentials); SMTP from
+	// the environment when unset
+	SystemSettingEmailDelivery SystemSettingKey = "email_delivery"
+
+	// Validation of report hosts before import; failing hosts are quarantined (JSON: enabled,
+	// quarantine_test_data, scope_cidrs)
+	SystemSettingImportValidation SystemSettingKey = "import_validation"
+
 	// Future settings can be added here
 	// SystemSettingMaintenanceMode SystemSettingKey = "maintenance_mode"
 	// SystemSettingAutoBackup SystemSettingKey = "auto_backup_enabled"
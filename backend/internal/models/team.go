@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Team is a group of users that can own assets and vulnerabilities. Notifications about
+// team-owned vulnerabilities are routed to every member.
+type Team struct {
+	BaseModel
+	OrgID       *uuid.UUID   `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	Name        string       `gorm:"type:varchar(255);not null" json:"name"`
+	Description string       `gorm:"type:text" json:"description,omitempty"`
+	LeadID      *uuid.UUID   `gorm:"type:uuid" json:"lead_id,omitempty"`
+	Lead        *User        `gorm:"foreignKey:LeadID;constraint:OnDelete:SET NULL" json:"lead,omitempty"`
+	Members     []TeamMember `gorm:"foreignKey:TeamID" json:"members,omitempty"`
+}
+
+// TableName specifies the table name for Team model
+func (Team) TableName() string {
+	return "teams"
+}
+
+// TeamMember links a user to a team
+type TeamMember struct {
+	TeamID    uuid.UUID `gorm:"type:uuid;primaryKey;not null" json:"team_id"`
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey;not null;index:idx_team_member_user" json:"user_id"`
+	User      *User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+	CreatedAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for TeamMember model
+func (TeamMember) TableName() string {
+	return "team_members"
+}
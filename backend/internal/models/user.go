@@ -3,12 +3,14 @@ package models
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // User represents a user account in the system
 type User struct {
 	BaseModel
+	OrgID             *uuid.UUID `gorm:"type:uuid;index" json:"org_id,omitempty"`
 	Email             string     `gorm:"uniqueIndex;not null" json:"email"`
 	Password          string     `gorm:"not null" json:"-"` // Never expose password in JSON
 	Name              string     `gorm:"type:varchar(255)" json:"name,omitempty"`
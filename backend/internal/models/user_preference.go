@@ -0,0 +1,131 @@
+package models
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// CSVEncoding represents the character encoding used for CSV exports
+type CSVEncoding string
+
+const (
+	CSVEncodingUTF8        CSVEncoding = "utf-8"
+	CSVEncodingISO88591    CSVEncoding = "iso-8859-1"
+	CSVEncodingISO885915   CSVEncoding = "iso-8859-15"
+	CSVEncodingWindows1252 CSVEncoding = "windows-1252"
+)
+
+// DateFormat is how dates are displayed to a user
+type DateFormat string
+
+const (
+	DateFormatISO DateFormat = "YYYY-MM-DD"
+	DateFormatEU  DateFormat = "DD/MM/YYYY"
+	DateFormatUS  DateFormat = "MM/DD/YYYY"
+	DateFormatDE  DateFormat = "DD.MM.YYYY"
+)
+
+// Layout returns the Go time layout of the format
+func (f DateFormat) Layout() string {
+	switch f {
+	case DateFormatEU:
+		return "02/01/2006"
+	case DateFormatUS:
+		return "01/02/2006"
+	case DateFormatDE:
+		return "02.01.2006"
+	}
+	return "2006-01-02"
+}
+
+// NotificationDigest is how often a user receives a summary of their notifications by email
+type NotificationDigest string
+
+const (
+	NotificationDigestOff    NotificationDigest = "off"
+	NotificationDigestDaily  NotificationDigest = "daily"
+	NotificationDigestWeekly NotificationDigest = "weekly"
+)
+
+// UserPreference stores per-user settings that are not part of the profile itself
+type UserPreference struct {
+	BaseModel
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	User   *User     `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+
+	// CSV export defaults (overridable per request via query parameters)
+	CSVDelimiter  string      `gorm:"type:varchar(5);not null;default:','" json:"csv_delimiter"`
+	CSVEncoding   CSVEncoding `gorm:"type:varchar(20);not null;default:'utf-8'" json:"csv_encoding"`
+	CSVIncludeBOM bool        `gorm:"default:false" json:"csv_include_bom"`
+
+	// Display: dates, times and report day boundaries use the timezone (IANA name). Messages,
+	// emails and report labels use the language; empty follows the browser's Accept-Language.
+	Language    string     `gorm:"type:varchar(10);not null;default:''" json:"language"`
+	Timezone    string     `gorm:"type:varchar(64);not null;default:'UTC'" json:"timezone"`
+	DateFormat  DateFormat `gorm:"type:varchar(20);not null;default:'YYYY-MM-DD'" json:"date_format"`
+	LandingView string     `gorm:"type:varchar(30);not null;default:'dashboard'" json:"landing_view"`
+	RowsPerPage int        `gorm:"not null;default:20" json:"rows_per_page"`
+
+	// Saved views whose filters the vulnerability and asset lists open with
+	DefaultVulnerabilityViewID *uuid.UUID `gorm:"type:uuid" json:"default_vulnerability_view_id,omitempty"`
+	DefaultAssetViewID         *uuid.UUID `gorm:"type:uuid" json:"default_asset_view_id,omitempty"`
+
+	// Summary email of assigned vulnerabilities, SLA deadlines and imports; LastDigestAt is when
+	// the last one was compiled and where the next one picks up
+	NotificationDigest NotificationDigest `gorm:"type:varchar(10);not null;default:'off'" json:"notification_digest"`
+	LastDigestAt       *time.Time         `gorm:"type:timestamp" json:"last_digest_at,omitempty"`
+}
+
+// TableName specifies the table name for UserPreference model
+func (UserPreference) TableName() string {
+	return "user_preferences"
+}
+
+// DefaultUserPreference returns the preferences used when a user has not saved any
+func DefaultUserPreference(userID uuid.UUID) *UserPreference {
+	return &UserPreference{
+		UserID:        userID,
+		CSVDelimiter:  ",",
+		CSVEncoding:   CSVEncodingUTF8,
+		CSVIncludeBOM: false,
+
+		Timezone:           "UTC",
+		DateFormat:         DateFormatISO,
+		LandingView:        "dashboard",
+		RowsPerPage:        20,
+		NotificationDigest: NotificationDigestOff,
+	}
+}
+
+// Location returns the preferred timezone, or UTC when it is unset or unknown
+func (p *UserPreference) Location() *time.Location {
+	if p == nil || p.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Delimiter returns the CSV delimiter character, or a comma when the stored value is not
+// exactly one character
+func (p *UserPreference) Delimiter() rune {
+	if p == nil || utf8.RuneCountInString(p.CSVDelimiter) != 1 {
+		return ','
+	}
+	r, _ := utf8.DecodeRuneInString(p.CSVDelimiter)
+	return r
+}
+
+// FormatTime formats a timestamp in the preferred timezone and date format, e.g. for emails
+func (p *UserPreference) FormatTime(t time.Time) string {
+	format := DateFormatISO
+	if p != nil {
+		format = p.DateFormat
+	}
+	return t.In(p.Location()).Format(format.Layout() + " 15:04 MST")
+}
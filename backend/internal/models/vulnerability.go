@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // VulnerabilitySeverity represents the severity level of a vulnerability
@@ -21,6 +22,7 @@ const (
 type VulnerabilityStatus string
 
 const (
+	StatusDraft         VulnerabilityStatus = "DRAFT" // Reported from outside (e.g. by email) and awaiting triage
 	StatusOpen          VulnerabilityStatus = "OPEN"
 	StatusInProgress    VulnerabilityStatus = "IN_PROGRESS"
 	StatusResolved      VulnerabilityStatus = "RESOLVED"
@@ -32,12 +34,15 @@ const (
 // Vulnerability represents a security vulnerability record
 type Vulnerability struct {
 	BaseModel
+	OrgID                     *uuid.UUID                   `gorm:"type:uuid;index" json:"org_id,omitempty"`
 	Title                     string                       `gorm:"type:varchar(255);not null" json:"title"`
 	Description               string                       `gorm:"type:text;not null" json:"description"`
 	Severity                  VulnerabilitySeverity        `gorm:"type:varchar(20);not null" json:"severity"`
 	CVSSScore                 *float64                     `gorm:"type:decimal(3,1)" json:"cvss_score,omitempty"`
 	CVSSVector                string                       `gorm:"type:varchar(100)" json:"cvss_vector,omitempty"`
 	CVEID                     string                       `gorm:"type:varchar(20)" json:"cve_id,omitempty"`
+	CWEIDs                    pq.StringArray               `gorm:"type:text[]" json:"cwe_ids,omitempty"` // Weakness types, e.g. CWE-79
+	HasExploit                bool                         `gorm:"not null;default:false;index" json:"has_exploit"` // A public exploit is known (exploit feeds or the scanner)
 	Status                    VulnerabilityStatus          `gorm:"type:varchar(20);not null;default:OPEN" json:"status"`
 	Source                    string                       `gorm:"type:varchar(100);not null;default:'Manual';index" json:"source"`
 	DiscoveryDate             time.Time                    `gorm:"type:date;not null" json:"discovery_date"`
@@ -45,12 +50,28 @@ type Vulnerability struct {
 	ImpactAssessment          string                       `gorm:"type:text" json:"impact_assessment,omitempty"`
 	StepsToReproduce          string                       `gorm:"type:text" json:"steps_to_reproduce,omitempty"`
 	MitigationRecommendations string                       `gorm:"type:text" json:"mitigation_recommendations,omitempty"`
+	PatchAvailable            bool                         `gorm:"not null;default:false" json:"patch_available"`
+	AdvisoryURL               string                       `gorm:"type:varchar(500)" json:"advisory_url,omitempty"`  // Vendor advisory or KB article
+	FixedVersion              string                       `gorm:"type:varchar(100)" json:"fixed_version,omitempty"` // First version without the vulnerability
+	Workaround                string                       `gorm:"type:text" json:"workaround,omitempty"`            // Mitigation until the fix is applied
+	NVDCheckedAt              *time.Time                   `json:"nvd_checked_at,omitempty"`                          // Last lookup of the CVE's remediation in NVD
 	CreatedByID               uuid.UUID                    `gorm:"type:uuid;not null" json:"created_by_id"`
 	CreatedBy                 *User                        `gorm:"foreignKey:CreatedByID;constraint:OnDelete:RESTRICT" json:"created_by,omitempty"`
 	AssignedToID              *uuid.UUID                   `gorm:"type:uuid" json:"assigned_to_id,omitempty"`
 	AssignedTo                *User                        `gorm:"foreignKey:AssignedToID;constraint:OnDelete:SET NULL" json:"assigned_to,omitempty"`
+	OwnerTeamID               *uuid.UUID                   `gorm:"type:uuid;index" json:"owner_team_id,omitempty"`
+	OwnerTeam                 *Team                        `gorm:"foreignKey:OwnerTeamID;constraint:OnDelete:SET NULL" json:"owner_team,omitempty"`
+	DeletedByID               *uuid.UUID                   `gorm:"type:uuid" json:"deleted_by_id,omitempty"`          // Who moved the vulnerability to the recycle bin
+	ImportJobID               *uuid.UUID                   `gorm:"type:uuid;index" json:"import_job_id,omitempty"`    // Import that created the vulnerability
+	SourceScanID              string                       `gorm:"type:varchar(100)" json:"source_scan_id,omitempty"` // Scan the vulnerability was first imported from
+	SourceScanDate            *time.Time                   `json:"source_scan_date,omitempty"`
+	Priority                  int                          `gorm:"not null;default:0" json:"priority"`            // Raised by escalation policies; 0 is normal
+	AssignmentRuleID          *uuid.UUID                   `gorm:"type:uuid" json:"assignment_rule_id,omitempty"` // Rule that routed the vulnerability when it was created
 	AffectedSystems           []AffectedSystem             `gorm:"many2many:vulnerability_affected_systems" json:"affected_systems,omitempty"`
 	StatusHistory             []VulnerabilityStatusHistory `gorm:"foreignKey:VulnerabilityID" json:"status_history,omitempty"`
+	Escalations               []VulnerabilityEscalation    `gorm:"foreignKey:VulnerabilityID" json:"escalations,omitempty"`
+	Tags                      []VulnerabilityTag           `gorm:"foreignKey:VulnerabilityID" json:"tags,omitempty"`
+	Classifications           []VulnerabilityClassification `gorm:"foreignKey:VulnerabilityID" json:"classifications,omitempty"` // OWASP Top 10 categories and ATT&CK techniques
 }
 
 // TableName specifies the table name for Vulnerability model
@@ -74,3 +95,21 @@ type VulnerabilityStatusHistory struct {
 func (VulnerabilityStatusHistory) TableName() string {
 	return "vulnerability_status_history"
 }
+
+// VulnerabilityAssignmentHistory tracks assignee changes for the activity timeline
+type VulnerabilityAssignmentHistory struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	VulnerabilityID uuid.UUID  `gorm:"type:uuid;not null;index:idx_vah_vulnerability" json:"vulnerability_id"`
+	OldAssigneeID   *uuid.UUID `gorm:"type:uuid" json:"old_assignee_id,omitempty"`
+	OldAssignee     *User      `gorm:"foreignKey:OldAssigneeID;constraint:OnDelete:SET NULL" json:"old_assignee,omitempty"`
+	NewAssigneeID   *uuid.UUID `gorm:"type:uuid" json:"new_assignee_id,omitempty"`
+	NewAssignee     *User      `gorm:"foreignKey:NewAssigneeID;constraint:OnDelete:SET NULL" json:"new_assignee,omitempty"`
+	ChangedByID     uuid.UUID  `gorm:"type:uuid;not null" json:"changed_by_id"`
+	ChangedBy       *User      `gorm:"foreignKey:ChangedByID;constraint:OnDelete:RESTRICT" json:"changed_by,omitempty"`
+	ChangedAt       time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_vah_vulnerability" json:"changed_at"`
+}
+
+// TableName specifies the table name for VulnerabilityAssignmentHistory model
+func (VulnerabilityAssignmentHistory) TableName() string {
+	return "vulnerability_assignment_history"
+}
@@ -24,6 +24,7 @@ type VulnerabilityAttachment struct {
 	// Storage information
 	StoragePath string           `gorm:"type:varchar(500);not null" json:"storage_path"` // relative path in storage
 	StorageURL  string           `gorm:"type:varchar(500)" json:"storage_url,omitempty"` // public URL if applicable
+	StorageBackend string        `gorm:"type:varchar(20);not null;default:local" json:"storage_backend"` // storage driver holding the file
 
 	// Image-specific metadata (for screenshots)
 	IsImage     bool             `gorm:"default:false" json:"is_image"`
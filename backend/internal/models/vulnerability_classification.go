@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// How a vulnerability classification was made
+const (
+	ClassificationSourceManual    = "manual"
+	ClassificationSourceHeuristic = "heuristic" // Inferred from the CWE IDs or the scanner plugin family
+)
+
+// VulnerabilityClassification maps a vulnerability to an OWASP Top 10 category or a MITRE
+// ATT&CK technique
+type VulnerabilityClassification struct {
+	VulnerabilityID uuid.UUID `gorm:"type:uuid;primaryKey;not null" json:"vulnerability_id"`
+	Framework       string    `gorm:"type:varchar(10);primaryKey;not null" json:"framework"` // owasp or attack
+	Code            string    `gorm:"type:varchar(20);primaryKey;not null;index:idx_vulnerability_classification_code" json:"code"`
+	Source          string    `gorm:"type:varchar(20);not null;default:'manual'" json:"source"`
+	CreatedAt       time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for VulnerabilityClassification model
+func (VulnerabilityClassification) TableName() string {
+	return "vulnerability_classifications"
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CloseApprovalStatus represents the review state of a request to close a vulnerability
+type CloseApprovalStatus string
+
+const (
+	CloseApprovalPending   CloseApprovalStatus = "PENDING"
+	CloseApprovalApproved  CloseApprovalStatus = "APPROVED"
+	CloseApprovalRejected  CloseApprovalStatus = "REJECTED"
+	CloseApprovalCancelled CloseApprovalStatus = "CANCELLED"
+)
+
+// VulnerabilityCloseApproval is a request to close a CRITICAL vulnerability under the
+// two-person rule (critical_close_approval setting). The vulnerability stays in its status
+// until a second user approves the request, which closes it. Requests are never deleted so
+// they form the audit trail of closures.
+type VulnerabilityCloseApproval struct {
+	BaseModel
+	OrgID           *uuid.UUID          `gorm:"type:uuid;index" json:"org_id,omitempty"`
+	VulnerabilityID uuid.UUID           `gorm:"type:uuid;not null;index:idx_close_approval_vulnerability" json:"vulnerability_id"`
+	Status          CloseApprovalStatus `gorm:"type:varchar(20);not null;default:PENDING;index" json:"status"`
+	FromStatus      VulnerabilityStatus `gorm:"type:varchar(20);not null" json:"from_status"` // Vulnerability status when closure was requested
+	Notes           string              `gorm:"type:text" json:"notes,omitempty"`
+
+	// Request tracking
+	RequestedByID uuid.UUID `gorm:"type:uuid;not null;index" json:"requested_by_id"`
+	RequestedBy   *User     `gorm:"foreignKey:RequestedByID;constraint:OnDelete:RESTRICT" json:"requested_by,omitempty"`
+
+	// Review tracking
+	ReviewedByID *uuid.UUID `gorm:"type:uuid" json:"reviewed_by_id,omitempty"`
+	ReviewedBy   *User      `gorm:"foreignKey:ReviewedByID;constraint:OnDelete:SET NULL" json:"reviewed_by,omitempty"`
+	ReviewedAt   *time.Time `gorm:"type:timestamp" json:"reviewed_at,omitempty"`
+	ReviewNotes  string     `gorm:"type:text" json:"review_notes,omitempty"`
+}
+
+// TableName specifies the table name for VulnerabilityCloseApproval model
+func (VulnerabilityCloseApproval) TableName() string {
+	return "vulnerability_close_approvals"
+}
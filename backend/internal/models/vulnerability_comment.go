@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// VulnerabilityComment represents a discussion comment on a vulnerability
+type VulnerabilityComment struct {
+	BaseModel
+	VulnerabilityID uuid.UUID      `gorm:"type:uuid;not null;index:idx_vuln_comment_vulnerability" json:"vulnerability_id"`
+	Vulnerability   *Vulnerability `gorm:"foreignKey:VulnerabilityID;constraint:OnDelete:CASCADE" json:"vulnerability,omitempty"`
+	AuthorID        uuid.UUID      `gorm:"type:uuid;not null;index" json:"author_id"`
+	Author          *User          `gorm:"foreignKey:AuthorID;constraint:OnDelete:RESTRICT" json:"author,omitempty"`
+	Body            string         `gorm:"type:text;not null" json:"body"`
+	EditedAt        *time.Time     `gorm:"type:timestamp" json:"edited_at,omitempty"`
+}
+
+// TableName specifies the table name for VulnerabilityComment model
+func (VulnerabilityComment) TableName() string {
+	return "vulnerability_comments"
+}
@@ -10,18 +10,24 @@ import (
 type FindingStatus string
 
 const (
-	FindingStatusOpen       FindingStatus = "OPEN"
-	FindingStatusMitigated  FindingStatus = "MITIGATED"
-	FindingStatusFixed      FindingStatus = "FIXED"
-	FindingStatusVerified   FindingStatus = "VERIFIED"
-	FindingStatusAccepted   FindingStatus = "ACCEPTED"      // Risk accepted
-	FindingStatusException  FindingStatus = "EXCEPTION"     // Granted exception
+	FindingStatusOpen          FindingStatus = "OPEN"
+	FindingStatusMitigated     FindingStatus = "MITIGATED"
+	FindingStatusFixed         FindingStatus = "FIXED"
+	FindingStatusVerified      FindingStatus = "VERIFIED"
+	FindingStatusAccepted      FindingStatus = "ACCEPTED"       // Risk accepted
+	FindingStatusException     FindingStatus = "EXCEPTION"      // Granted exception
+	FindingStatusFalsePositive FindingStatus = "FALSE_POSITIVE" // Marked false positive by a suppression rule
+	FindingStatusSuppressed    FindingStatus = "SUPPRESSED"     // Suppressed by a suppression rule
 )
 
 // VulnerabilityFinding represents a specific instance of a vulnerability on a particular asset
-// This allows tracking the same vulnerability across multiple systems individually
+// This allows tracking the same vulnerability across multiple systems individually.
+// The table is partitioned by month on created_at (see database.PartitionTables), so its
+// primary key is (id, created_at) and rows referencing a finding carry no foreign key; they
+// are deleted with the finding by a trigger.
 type VulnerabilityFinding struct {
 	ID              uuid.UUID         `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
+	OrgID           *uuid.UUID        `gorm:"type:uuid;index" json:"org_id,omitempty"`
 
 	// Link to parent vulnerability definition
 	VulnerabilityID uuid.UUID         `gorm:"type:uuid;not null;index:idx_finding_vulnerability" json:"vulnerability_id"`
@@ -39,8 +45,17 @@ type VulnerabilityFinding struct {
 	// Scanner-specific data
 	PluginID        string            `gorm:"type:varchar(50);index:idx_finding_plugin" json:"plugin_id,omitempty"`
 	PluginOutput    string            `gorm:"type:text" json:"plugin_output,omitempty"`      // Specific scan output for this host
+	DetectedVersion string            `gorm:"type:varchar(100)" json:"detected_version,omitempty"` // Version the scanner found installed
+	FixedVersion    string            `gorm:"type:varchar(100)" json:"fixed_version,omitempty"`    // Version the scanner reports fixes it on this host
 	ScannerName     string            `gorm:"type:varchar(50)" json:"scanner_name,omitempty"` // nessus, qualys, etc
 
+	// Import source: the scan that last reported the finding and the import that read it
+	IntegrationConfigID *uuid.UUID    `gorm:"type:uuid" json:"integration_config_id,omitempty"`
+	ScanID          string            `gorm:"type:varchar(100);index:idx_finding_scan" json:"scan_id,omitempty"`
+	ScanDate        *time.Time        `json:"scan_date,omitempty"`
+	ImportJobID     *uuid.UUID        `gorm:"type:uuid;index" json:"import_job_id,omitempty"`
+	CleanScans      int               `gorm:"not null;default:0" json:"clean_scans"` // Consecutive scans of the asset that did not report the finding
+
 	// Finding status (independent of parent vulnerability)
 	Status          FindingStatus     `gorm:"type:varchar(20);not null;default:OPEN" json:"status"`
 
@@ -61,6 +76,9 @@ type VulnerabilityFinding struct {
 	AcceptanceReason string           `gorm:"type:text" json:"acceptance_reason,omitempty"`
 	ExpiresAt       *time.Time        `gorm:"type:timestamp" json:"expires_at,omitempty"`    // Risk acceptance expiry
 
+	// Discussion
+	Comments        []FindingComment  `gorm:"foreignKey:FindingID;-:migration" json:"comments,omitempty"`
+
 	// Metadata
 	CreatedBy       uuid.UUID         `gorm:"type:uuid;not null" json:"created_by"`
 	CreatedByUser   *User             `gorm:"foreignKey:CreatedBy;constraint:OnDelete:RESTRICT" json:"created_by_user,omitempty"`
@@ -73,11 +91,12 @@ func (VulnerabilityFinding) TableName() string {
 	return "vulnerability_findings"
 }
 
-// FindingStatusHistory tracks status changes for individual findings
+// FindingStatusHistory tracks status changes for individual findings. The table is
+// partitioned by month on changed_at.
 type FindingStatusHistory struct {
 	ID              uuid.UUID     `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
 	FindingID       uuid.UUID     `gorm:"type:uuid;not null;index:idx_fsh_finding" json:"finding_id"`
-	Finding         *VulnerabilityFinding `gorm:"foreignKey:FindingID;constraint:OnDelete:CASCADE" json:"finding,omitempty"`
+	Finding         *VulnerabilityFinding `gorm:"foreignKey:FindingID;-:migration" json:"finding,omitempty"`
 	OldStatus       FindingStatus `gorm:"type:varchar(20);not null" json:"old_status"`
 	NewStatus       FindingStatus `gorm:"type:varchar(20);not null" json:"new_status"`
 	Notes           string        `gorm:"type:text" json:"notes,omitempty"`
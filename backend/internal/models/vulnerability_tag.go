@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// VulnerabilityTag represents a custom label applied to a vulnerability
+type VulnerabilityTag struct {
+	VulnerabilityID uuid.UUID `gorm:"type:uuid;primaryKey;not null" json:"vulnerability_id"`
+	Tag             string    `gorm:"type:varchar(50);primaryKey;not null;index:idx_vulnerability_tag_tag" json:"tag"`
+	CreatedAt       time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for VulnerabilityTag model
+func (VulnerabilityTag) TableName() string {
+	return "vulnerability_tags"
+}
+
+// BeforeCreate hook to normalize and validate tag
+func (vt *VulnerabilityTag) BeforeCreate(tx *gorm.DB) error {
+	tag, err := NormalizeTag(vt.Tag)
+	if err != nil {
+		return err
+	}
+	vt.Tag = tag
+	return nil
+}
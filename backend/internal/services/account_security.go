@@ -0,0 +1,246 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/auth"
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// Number of logins and sensitive actions listed on the account security page
+const (
+	securityLoginLimit  = 20
+	securityActionLimit = 20
+)
+
+// securitySensitiveEvents are the account changes and security events a user can review on
+// their security page
+var securitySensitiveEvents = []models.EventType{
+	models.EventTypePasswordChange,
+	models.EventTypePasswordReset,
+	models.EventTypePasswordResetRequest,
+	models.EventTypeTwoFactorEnabled,
+	models.EventTypeTwoFactorDisabled,
+	models.EventTypeBackupCodeUsed,
+	models.EventTypeBackupCodesRenewed,
+	models.EventTypeProfileUpdate,
+	models.EventTypeSessionRevoked,
+	models.EventTypeRefreshTokenReuse,
+	models.EventTypeImpersonationStarted,
+	models.EventTypeImpersonationEnded,
+	models.EventTypeAccountLocked,
+	models.EventTypeAccountUnlocked,
+	models.EventTypeIPBlocked,
+	models.EventTypePolicyAccepted,
+	models.EventTypeOnboardingReset,
+}
+
+// LoginAttempt describes a sign-in for the login history
+type LoginAttempt struct {
+	UserID     *uuid.UUID // nil when the email matched no account
+	SessionID  *uuid.UUID // the session created by a successful login
+	Success    bool
+	FailReason string
+	IPAddress  string
+	UserAgent  string
+	Location   string
+}
+
+// RecordLogin writes an audit event for a sign-in, with the device and location it came from
+func (s *SessionService) RecordLogin(attempt LoginAttempt) {
+	device := auth.ParseUserAgent(attempt.UserAgent)
+	metadata := map[string]interface{}{
+		"browser":     device.Browser,
+		"os":          device.OS,
+		"device_type": device.DeviceType,
+	}
+	if attempt.Location != "" {
+		metadata["location"] = attempt.Location
+	}
+	if attempt.SessionID != nil {
+		metadata["session_id"] = attempt.SessionID.String()
+	}
+
+	event := models.NewAuthEvent(attempt.UserID, models.EventTypeLogin, attempt.IPAddress, attempt.UserAgent)
+	if !attempt.Success {
+		event = models.NewFailedAuthEvent(attempt.UserID, models.EventTypeLoginFailed, attempt.IPAddress, attempt.UserAgent, attempt.FailReason)
+	}
+	if encoded, err := json.Marshal(metadata); err == nil {
+		event.Metadata = string(encoded)
+	}
+	if err := s.db.Create(event).Error; err != nil {
+		utils.Logger.Error().Err(err).Str("ip", attempt.IPAddress).Msg("Failed to record login")
+	}
+}
+
+// LoginRecord is a successful or failed sign-in in the login history
+type LoginRecord struct {
+	ID         uuid.UUID `json:"id"`
+	Timestamp  time.Time `json:"timestamp"`
+	Success    bool      `json:"success"`
+	FailReason string    `json:"fail_reason,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	Location   string    `json:"location,omitempty"`
+	Browser    string    `json:"browser,omitempty"`
+	OS         string    `json:"os,omitempty"`
+	DeviceType string    `json:"device_type,omitempty"`
+}
+
+// SecurityAction is a sensitive account change or security event
+type SecurityAction struct {
+	ID         uuid.UUID        `json:"id"`
+	Timestamp  time.Time        `json:"timestamp"`
+	EventType  models.EventType `json:"event_type"`
+	Success    bool             `json:"success"`
+	FailReason string           `json:"fail_reason,omitempty"`
+	IPAddress  string           `json:"ip_address,omitempty"`
+	// Set when an administrator acted as the user
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
+}
+
+// SecurityAPIKey summarizes an API key that can currently authenticate as the user
+type SecurityAPIKey struct {
+	ID           uuid.UUID         `json:"id"`
+	Name         string            `json:"name"`
+	Type         models.APIKeyType `json:"type"`
+	KeyPrefix    string            `json:"key_prefix"`
+	Scopes       []string          `json:"scopes"`
+	AllowedCIDRs []string          `json:"allowed_cidrs"`
+	ExpiresAt    *time.Time        `json:"expires_at,omitempty"`
+	LastUsedAt   *time.Time        `json:"last_used_at,omitempty"`
+	LastUsedIP   string            `json:"last_used_ip,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// TwoFactorStatus reports whether two-factor authentication protects the account
+type TwoFactorStatus struct {
+	Enabled              bool `json:"enabled"`
+	BackupCodesRemaining int  `json:"backup_codes_remaining"`
+}
+
+// SecurityOverview lets a user audit their own account: where they signed in from, what can
+// authenticate as them and which sensitive changes were made
+type SecurityOverview struct {
+	TwoFactor      TwoFactorStatus  `json:"two_factor"`
+	LastLoginAt    *time.Time       `json:"last_login_at,omitempty"`
+	ActiveSessions int              `json:"active_sessions"`
+	RecentLogins   []LoginRecord    `json:"recent_logins"`
+	FailedLogins   int64            `json:"failed_logins_30d"`
+	APIKeys        []SecurityAPIKey `json:"api_keys"`
+	RecentActions  []SecurityAction `json:"recent_actions"`
+}
+
+// GetSecurityOverview returns the account security overview of a user
+func (s *ProfileService) GetSecurityOverview(userID uuid.UUID) (*SecurityOverview, error) {
+	var user models.User
+	if err := s.db.Select("id", "two_factor_enabled", "backup_codes", "last_login_at").
+		Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	overview := &SecurityOverview{
+		TwoFactor: TwoFactorStatus{
+			Enabled:              user.TwoFactorEnabled,
+			BackupCodesRemaining: len(storedBackupCodes(&user)),
+		},
+		LastLoginAt:   user.LastLoginAt,
+		RecentLogins:  []LoginRecord{},
+		APIKeys:       []SecurityAPIKey{},
+		RecentActions: []SecurityAction{},
+	}
+
+	sessions, err := NewSessionService().GetUserSessions(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sessions: %w", err)
+	}
+	overview.ActiveSessions = len(sessions)
+
+	var logins []models.AuthEvent
+	if err := s.db.Where("user_id = ? AND event_type IN ?", userID, []models.EventType{models.EventTypeLogin, models.EventTypeLoginFailed}).
+		Order("created_at DESC").Limit(securityLoginLimit).Find(&logins).Error; err != nil {
+		return nil, fmt.Errorf("failed to load login history: %w", err)
+	}
+	for _, event := range logins {
+		overview.RecentLogins = append(overview.RecentLogins, loginRecord(event))
+	}
+
+	if err := s.db.Model(&models.AuthEvent{}).
+		Where("user_id = ? AND event_type = ? AND created_at > ?", userID, models.EventTypeLoginFailed, time.Now().AddDate(0, 0, -30)).
+		Count(&overview.FailedLogins).Error; err != nil {
+		return nil, fmt.Errorf("failed to count failed logins: %w", err)
+	}
+
+	var keys []models.APIKey
+	if err := s.db.Where("user_id = ? AND status = ? AND (expires_at IS NULL OR expires_at > ?)", userID, models.APIKeyStatusActive, time.Now()).
+		Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to load API keys: %w", err)
+	}
+	for _, key := range keys {
+		overview.APIKeys = append(overview.APIKeys, SecurityAPIKey{
+			ID:           key.ID,
+			Name:         key.Name,
+			Type:         key.Type,
+			KeyPrefix:    key.KeyPrefix,
+			Scopes:       key.GetScopes(),
+			AllowedCIDRs: append([]string{}, key.AllowedCIDRs...),
+			ExpiresAt:    key.ExpiresAt,
+			LastUsedAt:   key.LastUsedAt,
+			LastUsedIP:   key.LastUsedIP,
+			CreatedAt:    key.CreatedAt,
+		})
+	}
+
+	var actions []models.AuthEvent
+	if err := s.db.Where("user_id = ? AND event_type IN ?", userID, securitySensitiveEvents).
+		Order("created_at DESC").Limit(securityActionLimit).Find(&actions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load recent actions: %w", err)
+	}
+	for _, event := range actions {
+		overview.RecentActions = append(overview.RecentActions, SecurityAction{
+			ID:             event.ID,
+			Timestamp:      event.CreatedAt,
+			EventType:      event.EventType,
+			Success:        event.Success,
+			FailReason:     event.FailReason,
+			IPAddress:      event.IPAddress,
+			ImpersonatorID: event.ImpersonatorID,
+		})
+	}
+
+	return overview, nil
+}
+
+// loginRecord converts a login event; logins recorded without device metadata have their
+// device derived from the user agent
+func loginRecord(event models.AuthEvent) LoginRecord {
+	var metadata struct {
+		Browser    string `json:"browser"`
+		OS         string `json:"os"`
+		DeviceType string `json:"device_type"`
+		Location   string `json:"location"`
+	}
+	_ = json.Unmarshal([]byte(event.Metadata), &metadata)
+	if metadata.Browser == "" && metadata.OS == "" && event.UserAgent != "" {
+		device := auth.ParseUserAgent(event.UserAgent)
+		metadata.Browser, metadata.OS, metadata.DeviceType = device.Browser, device.OS, device.DeviceType
+	}
+	if metadata.Location == "" {
+		metadata.Location = auth.LocateIP(event.IPAddress)
+	}
+
+	return LoginRecord{
+		ID:         event.ID,
+		Timestamp:  event.CreatedAt,
+		Success:    event.Success,
+		FailReason: event.FailReason,
+		IPAddress:  event.IPAddress,
+		Location:   metadata.Location,
+		Browser:    metadata.Browser,
+		OS:         metadata.OS,
+		DeviceType: metadata.DeviceType,
+	}
+}
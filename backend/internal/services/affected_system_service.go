@@ -1,11 +1,13 @@
 package services
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/policy"
 	"github.com/cyops/cyops-backend/pkg/utils"
 	"gorm.io/gorm"
 )
@@ -22,6 +24,11 @@ func NewAffectedSystemService() *AffectedSystemService {
 	}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *AffectedSystemService) WithContext(ctx context.Context) *AffectedSystemService {
+	return &AffectedSystemService{db: s.db.WithContext(ctx)}
+}
+
 // CreateAffectedSystemRequest represents a request to create an affected system
 type CreateAffectedSystemRequest struct {
 	Hostname    string
@@ -43,6 +50,10 @@ func (s *AffectedSystemService) CreateAffectedSystem(req CreateAffectedSystemReq
 		Environment: models.Environment(req.Environment),
 	}
 
+	if err := policy.Authorize(s.db.Statement.Context, "asset", "write", policy.Attributes(*system)); err != nil {
+		return nil, err
+	}
+
 	if err := s.db.Create(system).Error; err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to create affected system")
 		return nil, fmt.Errorf("failed to create affected system: %w", err)
@@ -152,6 +163,15 @@ func (s *AffectedSystemService) UpdateAffectedSystem(id uuid.UUID, req UpdateAff
 		return nil, fmt.Errorf("failed to get affected system: %w", err)
 	}
 
+	// The caller must be allowed to modify the system both before and after the change
+	changed := system
+	if req.Environment != nil {
+		changed.Environment = models.Environment(*req.Environment)
+	}
+	if err := policy.Authorize(s.db.Statement.Context, "asset", "write", policy.Attributes(system, changed)); err != nil {
+		return nil, err
+	}
+
 	updates := make(map[string]interface{})
 
 	if req.Hostname != nil {
@@ -186,15 +206,38 @@ func (s *AffectedSystemService) UpdateAffectedSystem(id uuid.UUID, req UpdateAff
 }
 
 // DeleteAffectedSystem soft deletes an affected system
-func (s *AffectedSystemService) DeleteAffectedSystem(id uuid.UUID) error {
-	result := s.db.Delete(&models.AffectedSystem{}, id)
-	if result.Error != nil {
-		utils.Logger.Error().Err(result.Error).Str("id", id.String()).Msg("Failed to delete affected system")
-		return fmt.Errorf("failed to delete affected system: %w", result.Error)
+func (s *AffectedSystemService) DeleteAffectedSystem(id uuid.UUID, deletedByID uuid.UUID) error {
+	if policy.Constrained(s.db.Statement.Context, "asset", "delete") {
+		var system models.AffectedSystem
+		if err := s.db.First(&system, id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("affected system not found")
+			}
+			return fmt.Errorf("failed to get affected system: %w", err)
+		}
+		if err := policy.Authorize(s.db.Statement.Context, "asset", "delete", policy.Attributes(system)); err != nil {
+			return err
+		}
 	}
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("affected system not found")
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Record who deleted it for the recycle bin
+		if err := tx.Model(&models.AffectedSystem{}).Where("id = ?", id).UpdateColumn("deleted_by_id", deletedByID).Error; err != nil {
+			return fmt.Errorf("failed to delete affected system: %w", err)
+		}
+
+		result := tx.Delete(&models.AffectedSystem{}, id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete affected system: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("affected system not found")
+		}
+		return nil
+	})
+	if err != nil {
+		utils.Logger.Error().Err(err).Str("id", id.String()).Msg("Failed to delete affected system")
+		return err
 	}
 
 	utils.Logger.Info().
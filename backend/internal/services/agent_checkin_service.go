@@ -0,0 +1,365 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// AgentStaleAfter is how long an agent-managed asset may go without checking in before it is
+// flagged as stale
+const AgentStaleAfter = 72 * time.Hour
+
+// MaxAgentPackages caps the package inventory accepted in a single check-in
+const MaxAgentPackages = 20000
+
+// AgentCheckinService upserts assets from endpoint agent check-ins
+type AgentCheckinService struct {
+	db                  *gorm.DB
+	networkRangeService *NetworkRangeService
+}
+
+// NewAgentCheckinService creates a new agent check-in service
+func NewAgentCheckinService(db *gorm.DB) *AgentCheckinService {
+	return &AgentCheckinService{
+		db:                  db,
+		networkRangeService: NewNetworkRangeService(db),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *AgentCheckinService) WithContext(ctx context.Context) *AgentCheckinService {
+	return &AgentCheckinService{
+		db:                  s.db.WithContext(ctx),
+		networkRangeService: s.networkRangeService,
+	}
+}
+
+// AgentPackage is an installed package reported by an agent
+type AgentPackage struct {
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	Architecture string `json:"architecture,omitempty"`
+}
+
+// AgentCheckinRequest is the inventory an endpoint agent reports on each check-in
+type AgentCheckinRequest struct {
+	AgentID         string         `json:"agent_id"`
+	Hostname        string         `json:"hostname"`
+	IPAddresses     []string       `json:"ip_addresses"`
+	OperatingSystem string         `json:"operating_system"`
+	Packages        []AgentPackage `json:"packages"`
+	SeenAt          *time.Time     `json:"seen_at,omitempty"` // Defaults to the time of the request
+}
+
+// AgentCheckinResult describes the asset a check-in was applied to
+type AgentCheckinResult struct {
+	AssetID      uuid.UUID `json:"asset_id"`
+	Created      bool      `json:"created"`
+	PackageCount int       `json:"package_count"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+}
+
+// ValidateAgentCheckin normalizes a check-in: it requires an agent ID and a hostname or IP
+// address, rejects invalid IP addresses, and drops duplicate addresses and packages
+func ValidateAgentCheckin(req *AgentCheckinRequest, now time.Time) error {
+	req.AgentID = strings.TrimSpace(req.AgentID)
+	req.Hostname = strings.ToLower(strings.TrimSpace(req.Hostname))
+	req.OperatingSystem = strings.TrimSpace(req.OperatingSystem)
+
+	if req.AgentID == "" {
+		return fmt.Errorf("agent_id is required")
+	}
+	if len(req.AgentID) > 100 {
+		return fmt.Errorf("invalid agent_id: must be at most 100 characters")
+	}
+
+	ips := make([]string, 0, len(req.IPAddresses))
+	seenIPs := make(map[string]bool, len(req.IPAddresses))
+	for _, ip := range req.IPAddresses {
+		parsed := net.ParseIP(strings.TrimSpace(ip))
+		if parsed == nil {
+			return fmt.Errorf("invalid ip address: %s", ip)
+		}
+		normalized := parsed.String()
+		if !seenIPs[normalized] {
+			seenIPs[normalized] = true
+			ips = append(ips, normalized)
+		}
+	}
+	req.IPAddresses = ips
+
+	if req.Hostname == "" && len(req.IPAddresses) == 0 {
+		return fmt.Errorf("hostname or ip_addresses is required")
+	}
+
+	if len(req.Packages) > MaxAgentPackages {
+		return fmt.Errorf("invalid packages: at most %d packages per check-in", MaxAgentPackages)
+	}
+	packages := make([]AgentPackage, 0, len(req.Packages))
+	seenPackages := make(map[string]bool, len(req.Packages))
+	for _, p := range req.Packages {
+		p.Name = strings.TrimSpace(p.Name)
+		p.Version = strings.TrimSpace(p.Version)
+		p.Architecture = strings.TrimSpace(p.Architecture)
+		if p.Name == "" {
+			return fmt.Errorf("invalid packages: package name is required")
+		}
+		key := p.Name + "\x00" + p.Version
+		if !seenPackages[key] {
+			seenPackages[key] = true
+			packages = append(packages, p)
+		}
+	}
+	req.Packages = packages
+
+	// Agents with skewed clocks must not move last-seen into the future
+	if req.SeenAt == nil || req.SeenAt.After(now) {
+		req.SeenAt = &now
+	}
+	return nil
+}
+
+// CheckIn applies a check-in to the agent's asset, creating it on first contact. An existing
+// asset is matched by agent ID, then by hostname or IP address in the environment of the
+// network range containing the primary address. reportedByID is the owner of the agent's API key.
+func (s *AgentCheckinService) CheckIn(req AgentCheckinRequest, reportedByID uuid.UUID) (*AgentCheckinResult, error) {
+	if err := ValidateAgentCheckin(&req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	primaryIP := ""
+	if len(req.IPAddresses) > 0 {
+		primaryIP = req.IPAddresses[0]
+	}
+
+	result := &AgentCheckinResult{PackageCount: len(req.Packages), LastSeenAt: *req.SeenAt}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		networkRanges, err := s.networkRangeService.LoadMatcher(tx)
+		if err != nil {
+			return err
+		}
+
+		networkRange := networkRanges.Match(primaryIP)
+		asset, err := s.findAsset(tx, req, networkRange)
+		if err != nil {
+			return err
+		}
+
+		if asset == nil {
+			asset = newAgentAsset(req, primaryIP, reportedByID, networkRange)
+			if err := tx.Create(asset).Error; err != nil {
+				return fmt.Errorf("failed to create asset: %w", err)
+			}
+			result.Created = true
+		} else {
+			before := *asset
+			if req.Hostname != "" {
+				asset.Hostname = req.Hostname
+			}
+			if primaryIP != "" && !slices.Contains(req.IPAddresses, asset.IPAddress) {
+				asset.IPAddress = primaryIP
+			}
+			asset.AgentID = req.AgentID
+			asset.OperatingSystem = req.OperatingSystem
+			asset.IPAddresses = pq.StringArray(req.IPAddresses)
+			asset.LastSeenAt = req.SeenAt
+			asset.AgentStale = false
+			if err := tx.Model(asset).Select(
+				"hostname", "ip_address", "agent_id", "operating_system", "ip_addresses", "last_seen_at", "agent_stale",
+			).Updates(asset).Error; err != nil {
+				return fmt.Errorf("failed to update asset: %w", err)
+			}
+
+			var entries []models.AssetHistory
+			for _, change := range AssetFieldChanges(before, *asset) {
+				entries = append(entries, models.AssetHistory{
+					AssetID:  asset.ID,
+					Action:   models.AssetHistoryUpdated,
+					Field:    change.Field,
+					OldValue: change.OldValue,
+					NewValue: change.NewValue,
+					Notes:    "Reported by agent " + req.AgentID,
+				})
+			}
+			if err := recordAssetHistory(tx, reportedByID, entries...); err != nil {
+				return err
+			}
+		}
+		result.AssetID = asset.ID
+
+		return replaceAssetPackages(tx, asset.ID, req.Packages, *req.SeenAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Debug().
+		Str("agent_id", req.AgentID).
+		Str("asset_id", result.AssetID.String()).
+		Bool("created", result.Created).
+		Int("packages", result.PackageCount).
+		Msg("Agent checked in")
+
+	return result, nil
+}
+
+// findAsset returns the asset an agent reports for, or nil if it has none yet
+func (s *AgentCheckinService) findAsset(tx *gorm.DB, req AgentCheckinRequest, networkRange *models.NetworkRange) (*models.AffectedSystem, error) {
+	var asset models.AffectedSystem
+	err := tx.Where("agent_id = ?", req.AgentID).First(&asset).Error
+	if err == nil {
+		return &asset, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to find asset: %w", err)
+	}
+
+	environment := models.EnvProduction
+	if networkRange != nil {
+		environment = networkRange.Environment
+	}
+
+	// Adopt an asset created manually or by a scanner import, unless another agent owns it
+	query := tx.Where("environment = ? AND (agent_id IS NULL OR agent_id = '')", environment)
+	switch {
+	case req.Hostname != "" && len(req.IPAddresses) > 0:
+		query = query.Where("hostname = ? OR ip_address IN ?", req.Hostname, req.IPAddresses)
+	case req.Hostname != "":
+		query = query.Where("hostname = ?", req.Hostname)
+	default:
+		query = query.Where("ip_address IN ?", req.IPAddresses)
+	}
+	err = query.Order("created_at ASC").First(&asset).Error
+	if err == nil {
+		return &asset, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to find asset: %w", err)
+	}
+	return nil, nil
+}
+
+// newAgentAsset builds the asset for an agent's first check-in, classified by its network range
+func newAgentAsset(req AgentCheckinRequest, primaryIP string, reportedByID uuid.UUID, networkRange *models.NetworkRange) *models.AffectedSystem {
+	criticality := models.CriticalityMedium
+	asset := &models.AffectedSystem{
+		Hostname:        req.Hostname,
+		IPAddress:       primaryIP,
+		SystemType:      models.SystemTypeWorkstation,
+		Environment:     models.EnvProduction,
+		Status:          models.StatusActive,
+		Criticality:     &criticality,
+		Description:     "Registered by endpoint agent",
+		OwnerID:         &reportedByID,
+		AgentID:         req.AgentID,
+		OperatingSystem: req.OperatingSystem,
+		IPAddresses:     pq.StringArray(req.IPAddresses),
+		LastSeenAt:      req.SeenAt,
+	}
+	if networkRange != nil {
+		asset.Environment = networkRange.Environment
+		asset.Location = networkRange.Location
+		asset.OwnerTeamID = networkRange.OwnerTeamID
+		if networkRange.OwnerID != nil {
+			asset.OwnerID = networkRange.OwnerID
+		}
+	}
+	if isServerOS(req.OperatingSystem) {
+		asset.SystemType = models.SystemTypeServer
+	}
+	return asset
+}
+
+// isServerOS reports whether an operating system name denotes a server edition
+func isServerOS(os string) bool {
+	os = strings.ToLower(os)
+	for _, marker := range []string{"server", "linux", "bsd", "unix", "solaris", "aix"} {
+		if strings.Contains(os, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceAssetPackages replaces an asset's package inventory with the reported packages
+func replaceAssetPackages(tx *gorm.DB, assetID uuid.UUID, packages []AgentPackage, reportedAt time.Time) error {
+	if err := tx.Where("asset_id = ?", assetID).Delete(&models.AssetPackage{}).Error; err != nil {
+		return fmt.Errorf("failed to clear asset packages: %w", err)
+	}
+	if len(packages) == 0 {
+		return nil
+	}
+
+	rows := make([]models.AssetPackage, len(packages))
+	for i, p := range packages {
+		rows[i] = models.AssetPackage{
+			AssetID:      assetID,
+			Name:         p.Name,
+			Version:      p.Version,
+			Architecture: p.Architecture,
+			ReportedAt:   reportedAt,
+		}
+	}
+	if err := tx.CreateInBatches(&rows, 1000).Error; err != nil {
+		return fmt.Errorf("failed to store asset packages: %w", err)
+	}
+	return nil
+}
+
+// ListPackages returns a page of an asset's installed packages ordered by name
+func (s *AgentCheckinService) ListPackages(assetID uuid.UUID, search string, page, limit int) ([]models.AssetPackage, int64, error) {
+	var count int64
+	if err := s.db.Model(&models.AffectedSystem{}).Where("id = ?", assetID).Count(&count).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get asset: %w", err)
+	}
+	if count == 0 {
+		return nil, 0, fmt.Errorf("asset not found")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	query := s.db.Model(&models.AssetPackage{}).Where("asset_id = ?", assetID)
+	if search = strings.TrimSpace(search); search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count asset packages: %w", err)
+	}
+
+	var packages []models.AssetPackage
+	if err := query.Order("name ASC, version ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&packages).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list asset packages: %w", err)
+	}
+	return packages, total, nil
+}
+
+// MarkStale flags agent-managed assets that have not checked in since AgentStaleAfter. It runs
+// without a tenant context and returns the number of assets newly flagged.
+func (s *AgentCheckinService) MarkStale(now time.Time) (int64, error) {
+	result := s.db.Model(&models.AffectedSystem{}).
+		Where("agent_id <> '' AND agent_stale = ? AND last_seen_at < ?", false, now.Add(-AgentStaleAfter)).
+		Update("agent_stale", true)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to flag stale agents: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
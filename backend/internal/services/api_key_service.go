@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
@@ -17,19 +18,24 @@ import (
 )
 
 var (
-	ErrAPIKeyNotFound     = errors.New("API key not found")
-	ErrAPIKeyInvalid      = errors.New("API key is invalid")
-	ErrAPIKeyExpired      = errors.New("API key has expired")
-	ErrAPIKeyRevoked      = errors.New("API key has been revoked")
-	ErrAPIKeyInactive     = errors.New("API key is inactive")
-	ErrInvalidKeyFormat   = errors.New("invalid API key format")
-	ErrDuplicateKeyName   = errors.New("API key with this name already exists")
+	ErrAPIKeyNotFound       = errors.New("API key not found")
+	ErrAPIKeyInvalid        = errors.New("API key is invalid")
+	ErrAPIKeyExpired        = errors.New("API key has expired")
+	ErrAPIKeyRevoked        = errors.New("API key has been revoked")
+	ErrAPIKeyInactive       = errors.New("API key is inactive")
+	ErrInvalidKeyFormat     = errors.New("invalid API key format")
+	ErrDuplicateKeyName     = errors.New("API key with this name already exists")
+	ErrRotationNotSupported = errors.New("only service and MCP API keys can be rotated")
+	ErrNoSecondaryKey       = errors.New("API key has no active secondary secret")
 )
 
 const (
 	// API key format: kfm_<type>_<random32chars>
 	APIKeyPrefix       = "kfm_"
 	APIKeyRandomLength = 32
+
+	// MaxRotationGracePeriod bounds how long the previous secret stays valid after a rotation
+	MaxRotationGracePeriod = 7 * 24 * time.Hour
 )
 
 type APIKeyService struct {
@@ -42,6 +48,11 @@ func NewAPIKeyService() *APIKeyService {
 	}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *APIKeyService) WithContext(ctx context.Context) *APIKeyService {
+	return &APIKeyService{db: s.db.WithContext(ctx)}
+}
+
 // CreateAPIKeyInput represents the input for creating an API key
 type CreateAPIKeyInput struct {
 	UserID             uuid.UUID
@@ -51,6 +62,7 @@ type CreateAPIKeyInput struct {
 	ExpiresAt          *time.Time
 	Description        string
 	RateLimitPerMinute int
+	AllowedCIDRs       []string // client networks the key may be used from; empty allows any
 }
 
 // CreateAPIKeyResult represents the result of creating an API key
@@ -81,6 +93,11 @@ func (s *APIKeyService) Create(input CreateAPIKeyInput) (*CreateAPIKeyResult, er
 		return nil, fmt.Errorf("failed to generate API key: %w", err)
 	}
 
+	allowedCIDRs, err := ParseCIDRList(input.AllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Set default rate limit if not specified
 	if input.RateLimitPerMinute <= 0 {
 		input.RateLimitPerMinute = 60 // Default: 60 requests per minute
@@ -98,6 +115,7 @@ func (s *APIKeyService) Create(input CreateAPIKeyInput) (*CreateAPIKeyResult, er
 		ExpiresAt:          input.ExpiresAt,
 		Description:        input.Description,
 		RateLimitPerMinute: input.RateLimitPerMinute,
+		AllowedCIDRs:       pq.StringArray(allowedCIDRs),
 	}
 
 	if err := s.db.Create(apiKey).Error; err != nil {
@@ -132,9 +150,14 @@ func (s *APIKeyService) ValidateAndGet(plainKey string) (*models.APIKey, *models
 		return nil, nil, ErrAPIKeyNotFound
 	}
 
-	// Check each key's hash
+	// Check each key's hash, then the previous secret of keys in a rotation grace period
 	for _, apiKey := range apiKeys {
-		if auth.CheckPasswordHash(plainKey, apiKey.KeyHash) {
+		matched := auth.CheckPasswordHash(plainKey, apiKey.KeyHash)
+		if !matched && apiKey.HasActiveSecondary() && auth.CheckPasswordHash(plainKey, apiKey.SecondaryKeyHash) {
+			matched = true
+			apiKey.AuthenticatedWithSecondary = true
+		}
+		if matched {
 			// Validate status and expiration
 			if !apiKey.IsValid() {
 				if apiKey.Status == models.APIKeyStatusRevoked {
@@ -156,12 +179,69 @@ func (s *APIKeyService) ValidateAndGet(plainKey string) (*models.APIKey, *models
 	return nil, nil, ErrAPIKeyInvalid
 }
 
-// UpdateLastUsed updates the last_used_at timestamp
-func (s *APIKeyService) UpdateLastUsed(keyID uuid.UUID) error {
+// Rotate issues a new primary secret for a service or MCP key. The previous secret stays valid
+// as the secondary for the grace period so integrations can switch without downtime; a zero
+// grace period invalidates it immediately. Rotating again replaces any existing secondary.
+func (s *APIKeyService) Rotate(keyID, userID uuid.UUID, gracePeriod time.Duration) (*CreateAPIKeyResult, error) {
+	if gracePeriod < 0 || gracePeriod > MaxRotationGracePeriod {
+		return nil, fmt.Errorf("grace period must be between 0 and %s", MaxRotationGracePeriod)
+	}
+
+	apiKey, err := s.GetByID(keyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !apiKey.SupportsRotation() {
+		return nil, ErrRotationNotSupported
+	}
+	if apiKey.Status == models.APIKeyStatusRevoked {
+		return nil, ErrAPIKeyRevoked
+	}
+
+	plainKey, keyHash, _, err := s.generateAPIKey(apiKey.Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+
 	now := time.Now()
-	return s.db.Model(&models.APIKey{}).
-		Where("id = ?", keyID).
-		Update("last_used_at", now).Error
+	updates := map[string]interface{}{
+		"key_hash":             keyHash,
+		"secondary_key_hash":   "",
+		"secondary_expires_at": nil,
+		"rotated_at":           now,
+	}
+	if gracePeriod > 0 {
+		updates["secondary_key_hash"] = apiKey.KeyHash
+		updates["secondary_expires_at"] = now.Add(gracePeriod)
+	}
+	if err := s.db.Model(&models.APIKey{}).Where("id = ?", apiKey.ID).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	rotated, err := s.GetByID(keyID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &CreateAPIKeyResult{
+		APIKey:   rotated,
+		PlainKey: plainKey,
+	}, nil
+}
+
+// RetireSecondary invalidates the previous secret of a rotated key before its grace period ends
+func (s *APIKeyService) RetireSecondary(keyID, userID uuid.UUID) error {
+	apiKey, err := s.GetByID(keyID, userID)
+	if err != nil {
+		return err
+	}
+	if !apiKey.HasActiveSecondary() {
+		return ErrNoSecondaryKey
+	}
+
+	return s.db.Model(&models.APIKey{}).Where("id = ?", apiKey.ID).Updates(map[string]interface{}{
+		"secondary_key_hash":   "",
+		"secondary_expires_at": nil,
+	}).Error
 }
 
 // List returns all API keys for a user
@@ -232,6 +312,26 @@ func (s *APIKeyService) UpdateStatus(keyID, userID uuid.UUID, status models.APIK
 	return nil
 }
 
+// UpdateAllowedCIDRs replaces the client networks an API key may be used from; an empty list
+// lifts the restriction
+func (s *APIKeyService) UpdateAllowedCIDRs(keyID, userID uuid.UUID, cidrs []string) (*models.APIKey, error) {
+	allowedCIDRs, err := ParseCIDRList(cidrs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := s.db.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NULL", keyID, userID).
+		Update("allowed_cidrs", pq.StringArray(allowedCIDRs))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrAPIKeyNotFound
+	}
+	return s.GetByID(keyID, userID)
+}
+
 // generateAPIKey generates a new API key with the format: kfm_<type>_<random32chars>
 func (s *APIKeyService) generateAPIKey(keyType models.APIKeyType) (plainKey, hash, prefix string, err error) {
 	// Generate random bytes
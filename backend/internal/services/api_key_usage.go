@@ -0,0 +1,246 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// APIKeyUsageEvent is one request made with an API key
+type APIKeyUsageEvent struct {
+	APIKeyID    uuid.UUID
+	Method      string
+	Route       string
+	IP          string
+	Failed      bool // response status >= 400
+	RateLimited bool // rejected by the key's rate limit
+	At          time.Time
+}
+
+// usageBucket identifies an api_key_usage row
+type usageBucket struct {
+	apiKeyID uuid.UUID
+	day      time.Time
+	method   string
+	route    string
+}
+
+// usageBuffer aggregates usage events in memory until the next flush, so requests never
+// wait on a database write
+var usageBuffer = struct {
+	sync.Mutex
+	pending map[usageBucket]*models.APIKeyUsage
+}{pending: make(map[usageBucket]*models.APIKeyUsage)}
+
+// RecordAPIKeyUsage buffers a usage event; FlushUsage persists it
+func RecordAPIKeyUsage(event APIKeyUsageEvent) {
+	bucket := usageBucket{apiKeyID: event.APIKeyID, day: utcDay(event.At), method: event.Method, route: event.Route}
+
+	usageBuffer.Lock()
+	defer usageBuffer.Unlock()
+
+	usage, ok := usageBuffer.pending[bucket]
+	if !ok {
+		usage = &models.APIKeyUsage{APIKeyID: bucket.apiKeyID, Day: bucket.day, Method: bucket.method, Route: bucket.route}
+		usageBuffer.pending[bucket] = usage
+	}
+	mergeUsage(usage, &models.APIKeyUsage{
+		RequestCount: 1,
+		ErrorCount:   boolCount(event.Failed),
+		RateLimited:  boolCount(event.RateLimited),
+		LastIP:       event.IP,
+		LastUsedAt:   event.At,
+	})
+}
+
+// FlushUsage writes buffered usage counters to api_key_usage and updates each key's last use.
+// Counters that fail to save are put back and retried on the next flush.
+func (s *APIKeyService) FlushUsage() (int, error) {
+	usageBuffer.Lock()
+	pending := usageBuffer.pending
+	usageBuffer.pending = make(map[usageBucket]*models.APIKeyUsage)
+	usageBuffer.Unlock()
+
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	rows := make([]*models.APIKeyUsage, 0, len(pending))
+	lastUse := make(map[uuid.UUID]*models.APIKeyUsage)
+	for _, usage := range pending {
+		rows = append(rows, usage)
+		if last, ok := lastUse[usage.APIKeyID]; !ok || usage.LastUsedAt.After(last.LastUsedAt) {
+			lastUse[usage.APIKeyID] = usage
+		}
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, usage := range rows {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "api_key_id"}, {Name: "day"}, {Name: "method"}, {Name: "route"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"request_count": gorm.Expr("api_key_usage.request_count + ?", usage.RequestCount),
+					"error_count":   gorm.Expr("api_key_usage.error_count + ?", usage.ErrorCount),
+					"rate_limited":  gorm.Expr("api_key_usage.rate_limited + ?", usage.RateLimited),
+					"last_ip":       usage.LastIP,
+					"last_used_at":  gorm.Expr("GREATEST(api_key_usage.last_used_at, ?)", usage.LastUsedAt),
+				}),
+			}).Create(usage).Error; err != nil {
+				return fmt.Errorf("failed to save API key usage: %w", err)
+			}
+		}
+		for keyID, usage := range lastUse {
+			if err := tx.Model(&models.APIKey{}).Where("id = ?", keyID).Updates(map[string]interface{}{
+				"last_used_at": usage.LastUsedAt,
+				"last_used_ip": usage.LastIP,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update API key last use: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		usageBuffer.Lock()
+		for bucket, usage := range pending {
+			if current, ok := usageBuffer.pending[bucket]; ok {
+				mergeUsage(current, usage)
+			} else {
+				usageBuffer.pending[bucket] = usage
+			}
+		}
+		usageBuffer.Unlock()
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+// APIKeyDailyUsage is the request count of an API key on one day
+type APIKeyDailyUsage struct {
+	Day         time.Time `json:"day"`
+	Requests    int64     `json:"requests"`
+	Errors      int64     `json:"errors"`
+	RateLimited int64     `json:"rate_limited"`
+}
+
+// APIKeyEndpointUsage is the request count of an API key on one endpoint
+type APIKeyEndpointUsage struct {
+	Method      string    `json:"method"`
+	Route       string    `json:"route"`
+	Requests    int64     `json:"requests"`
+	Errors      int64     `json:"errors"`
+	RateLimited int64     `json:"rate_limited"`
+	LastIP      string    `json:"last_ip"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+}
+
+// APIKeyUsageSummary reports the usage of an API key over a period
+type APIKeyUsageSummary struct {
+	APIKeyID           uuid.UUID             `json:"api_key_id"`
+	RateLimitPerMinute int                   `json:"rate_limit_per_minute"`
+	Since              time.Time             `json:"since"`
+	TotalRequests      int64                 `json:"total_requests"`
+	TotalErrors        int64                 `json:"total_errors"`
+	TotalRateLimited   int64                 `json:"total_rate_limited"`
+	LastUsedAt         *time.Time            `json:"last_used_at,omitempty"`
+	LastUsedIP         string                `json:"last_used_ip,omitempty"`
+	Daily              []APIKeyDailyUsage    `json:"daily"`
+	Endpoints          []APIKeyEndpointUsage `json:"endpoints"`
+}
+
+// Usage summarizes the usage of one of a user's API keys over the last days (including today)
+func (s *APIKeyService) Usage(keyID, userID uuid.UUID, days int) (*APIKeyUsageSummary, error) {
+	apiKey, err := s.GetByID(keyID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := utcDay(time.Now()).AddDate(0, 0, -(days - 1))
+	var rows []models.APIKeyUsage
+	if err := s.db.Where("api_key_id = ? AND day >= ?", keyID, since).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load API key usage: %w", err)
+	}
+
+	summary := &APIKeyUsageSummary{
+		APIKeyID:           apiKey.ID,
+		RateLimitPerMinute: apiKey.RateLimitPerMinute,
+		Since:              since,
+		LastUsedAt:         apiKey.LastUsedAt,
+		LastUsedIP:         apiKey.LastUsedIP,
+		Daily:              []APIKeyDailyUsage{},
+		Endpoints:          []APIKeyEndpointUsage{},
+	}
+
+	daily := make(map[time.Time]*APIKeyDailyUsage)
+	endpoints := make(map[string]*APIKeyEndpointUsage)
+	for _, row := range rows {
+		summary.TotalRequests += row.RequestCount
+		summary.TotalErrors += row.ErrorCount
+		summary.TotalRateLimited += row.RateLimited
+
+		day := utcDay(row.Day)
+		d, ok := daily[day]
+		if !ok {
+			d = &APIKeyDailyUsage{Day: day}
+			daily[day] = d
+		}
+		d.Requests += row.RequestCount
+		d.Errors += row.ErrorCount
+		d.RateLimited += row.RateLimited
+
+		key := row.Method + " " + row.Route
+		e, ok := endpoints[key]
+		if !ok {
+			e = &APIKeyEndpointUsage{Method: row.Method, Route: row.Route}
+			endpoints[key] = e
+		}
+		e.Requests += row.RequestCount
+		e.Errors += row.ErrorCount
+		e.RateLimited += row.RateLimited
+		if row.LastUsedAt.After(e.LastUsedAt) {
+			e.LastUsedAt = row.LastUsedAt
+			e.LastIP = row.LastIP
+		}
+	}
+
+	for _, d := range daily {
+		summary.Daily = append(summary.Daily, *d)
+	}
+	sort.Slice(summary.Daily, func(i, j int) bool { return summary.Daily[i].Day.Before(summary.Daily[j].Day) })
+
+	for _, e := range endpoints {
+		summary.Endpoints = append(summary.Endpoints, *e)
+	}
+	sort.Slice(summary.Endpoints, func(i, j int) bool {
+		if summary.Endpoints[i].Requests != summary.Endpoints[j].Requests {
+			return summary.Endpoints[i].Requests > summary.Endpoints[j].Requests
+		}
+		return summary.Endpoints[i].Method+summary.Endpoints[i].Route < summary.Endpoints[j].Method+summary.Endpoints[j].Route
+	})
+
+	return summary, nil
+}
+
+// mergeUsage adds the counters of src to dst, keeping the most recent IP and time
+func mergeUsage(dst, src *models.APIKeyUsage) {
+	dst.RequestCount += src.RequestCount
+	dst.ErrorCount += src.ErrorCount
+	dst.RateLimited += src.RateLimited
+	if !src.LastUsedAt.Before(dst.LastUsedAt) {
+		dst.LastUsedAt = src.LastUsedAt
+		dst.LastIP = src.LastIP
+	}
+}
+
+// boolCount converts a flag to a counter increment
+func boolCount(flag bool) int64 {
+	if flag {
+		return 1
+	}
+	return 0
+}
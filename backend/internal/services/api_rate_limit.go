@@ -0,0 +1,133 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// maxRouteGroupRateLimit bounds a configured per-minute limit
+const maxRouteGroupRateLimit = 100000
+
+// apiRateLimitSettingsTTL is how long each instance reuses the loaded settings; changes made on
+// another replica take effect within this delay
+const apiRateLimitSettingsTTL = 30 * time.Second
+
+// routeGroupPattern matches route group names: the first path segment after /api/<version>
+var routeGroupPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// RouteGroupRateLimit is the per-minute request quota of each user and API key in a route group
+// (0 is unlimited)
+type RouteGroupRateLimit struct {
+	UserPerMinute   int `json:"user_per_minute"`    // Requests of each user signed in with a session
+	APIKeyPerMinute int `json:"api_key_per_minute"` // Requests of each API key, on top of the key's own limit
+}
+
+// APIRateLimitSettings are the quotas of authenticated API requests. Groups override the
+// default quota of a route group, such as "reports" for /api/v1/reports.
+type APIRateLimitSettings struct {
+	Enabled bool                           `json:"enabled"`
+	Default RouteGroupRateLimit            `json:"default"`
+	Groups  map[string]RouteGroupRateLimit `json:"groups,omitempty"`
+}
+
+// validate checks a quota
+func (l RouteGroupRateLimit) validate(name string) error {
+	if l.UserPerMinute < 0 || l.UserPerMinute > maxRouteGroupRateLimit {
+		return fmt.Errorf("invalid API rate limits: %s user_per_minute must be between 0 and %d", name, maxRouteGroupRateLimit)
+	}
+	if l.APIKeyPerMinute < 0 || l.APIKeyPerMinute > maxRouteGroupRateLimit {
+		return fmt.Errorf("invalid API rate limits: %s api_key_per_minute must be between 0 and %d", name, maxRouteGroupRateLimit)
+	}
+	return nil
+}
+
+// ParseAPIRateLimitSettings parses and validates an api_rate_limits setting value
+func ParseAPIRateLimitSettings(value string) (*APIRateLimitSettings, error) {
+	var settings APIRateLimitSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return nil, fmt.Errorf("invalid API rate limits: %v", err)
+	}
+	if err := settings.Default.validate("default"); err != nil {
+		return nil, err
+	}
+	for group, limit := range settings.Groups {
+		if !routeGroupPattern.MatchString(group) {
+			return nil, fmt.Errorf("invalid API rate limits: route group %q must be a lowercase path segment such as \"reports\"", group)
+		}
+		if err := limit.validate(group); err != nil {
+			return nil, err
+		}
+	}
+	return &settings, nil
+}
+
+// LimitFor returns the quota of a route group: its override, or the default
+func (s *APIRateLimitSettings) LimitFor(group string) RouteGroupRateLimit {
+	if limit, ok := s.Groups[group]; ok {
+		return limit
+	}
+	return s.Default
+}
+
+// LoadAPIRateLimitSettings returns the configured quotas; without the setting authenticated
+// requests are only limited by their API key's own rate limit
+func LoadAPIRateLimitSettings(db *gorm.DB) (*APIRateLimitSettings, error) {
+	var setting models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingAPIRateLimits)).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &APIRateLimitSettings{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API rate limits: %w", err)
+	}
+	return ParseAPIRateLimitSettings(setting.Value)
+}
+
+var (
+	apiRateLimitSettingsMu       sync.Mutex
+	apiRateLimitSettings         *APIRateLimitSettings
+	apiRateLimitSettingsLoadedAt time.Time
+)
+
+// CurrentAPIRateLimitSettings returns the quotas, reloading them from the database at most
+// every 30 seconds. When they cannot be loaded the last known quotas stay in force.
+func CurrentAPIRateLimitSettings() *APIRateLimitSettings {
+	apiRateLimitSettingsMu.Lock()
+	defer apiRateLimitSettingsMu.Unlock()
+
+	if apiRateLimitSettings != nil && time.Since(apiRateLimitSettingsLoadedAt) < apiRateLimitSettingsTTL {
+		return apiRateLimitSettings
+	}
+	db := database.GetDB()
+	if db == nil {
+		return &APIRateLimitSettings{}
+	}
+
+	settings, err := LoadAPIRateLimitSettings(db)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to load API rate limits")
+		if apiRateLimitSettings == nil {
+			apiRateLimitSettings = &APIRateLimitSettings{}
+		}
+		settings = apiRateLimitSettings
+	}
+	apiRateLimitSettings = settings
+	apiRateLimitSettingsLoadedAt = time.Now()
+	return settings
+}
+
+// invalidateAPIRateLimitSettings makes this instance reload the quotas on the next request
+func invalidateAPIRateLimitSettings() {
+	apiRateLimitSettingsMu.Lock()
+	defer apiRateLimitSettingsMu.Unlock()
+	apiRateLimitSettingsLoadedAt = time.Time{}
+}
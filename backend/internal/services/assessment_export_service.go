@@ -0,0 +1,618 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/shutdown"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Export package layout
+const (
+	ExportSchemaVersion  = "1"
+	exportManifestFile   = "manifest.json"
+	exportAssessmentFile = "assessment.json"
+	exportAuditTrailFile = "audit_trail.json"
+	exportReportDir      = "report"
+	exportEvidenceDir    = "evidence"
+	// maxExportAuditEntries caps the audit trail excerpt; the most recent entries are kept
+	maxExportAuditEntries = 5000
+)
+
+// Audit trail actions of an export package
+const (
+	ExportAuditStatusChanged   = "vulnerability_status_changed"
+	ExportAuditAssigneeChanged = "vulnerability_assignee_changed"
+	ExportAuditRetestRequested = "retest_requested"
+	ExportAuditRetestCompleted = "retest_completed"
+	ExportAuditReportUploaded  = "report_uploaded"
+	ExportAuditReportGenerated = "report_generated"
+	ExportAuditShareIssued     = "share_token_issued"
+	ExportAuditShareRevoked    = "share_token_revoked"
+)
+
+// AssessmentExportService builds the export packages submitted to regulators: a ZIP of an
+// assessment's report, evidence, audit trail excerpt and machine-readable findings, with a hash
+// manifest of every file
+type AssessmentExportService struct {
+	db *gorm.DB
+}
+
+// NewAssessmentExportService creates a new assessment export service
+func NewAssessmentExportService(db *gorm.DB) *AssessmentExportService {
+	return &AssessmentExportService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *AssessmentExportService) WithContext(ctx context.Context) *AssessmentExportService {
+	return &AssessmentExportService{db: s.db.WithContext(ctx)}
+}
+
+// ExportUser identifies a user in an export package
+type ExportUser struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name,omitempty"`
+	Email string    `json:"email,omitempty"`
+}
+
+// ExportReport describes the report of an export package
+type ExportReport struct {
+	ID       *uuid.UUID `json:"id,omitempty"` // Empty when the report was rendered for the export
+	Title    string     `json:"title"`
+	Version  int        `json:"version,omitempty"`
+	Path     string     `json:"path"`
+	MimeType string     `json:"mime_type"`
+	// Detached signature of the report (see POST /reports/verify); empty when it is unsigned
+	SignaturePath string `json:"signature_path,omitempty"`
+}
+
+// ExportEvidence is an evidence file of a finding in an export package
+type ExportEvidence struct {
+	AttachmentID   uuid.UUID `json:"attachment_id"`
+	Path           string    `json:"path"`
+	OriginalName   string    `json:"original_name"`
+	MimeType       string    `json:"mime_type"`
+	AttachmentType string    `json:"attachment_type"`
+	Description    string    `json:"description,omitempty"`
+	UploadedAt     time.Time `json:"uploaded_at"`
+}
+
+// ExportFinding is a finding of the assessment in an export package
+type ExportFinding struct {
+	Ref              string                       `json:"ref"` // Reference used in the report, e.g. F-01
+	VulnerabilityID  uuid.UUID                    `json:"vulnerability_id"`
+	Title            string                       `json:"title"`
+	Description      string                       `json:"description"`
+	Severity         models.VulnerabilitySeverity `json:"severity"`
+	Status           models.VulnerabilityStatus   `json:"status"`
+	CVSSScore        *float64                     `json:"cvss_score,omitempty"`
+	CVSSVector       string                       `json:"cvss_vector,omitempty"`
+	CVEID            string                       `json:"cve_id,omitempty"`
+	CWEIDs           []string                     `json:"cwe_ids,omitempty"`
+	ImpactAssessment string                       `json:"impact_assessment,omitempty"`
+	Remediation      string                       `json:"remediation,omitempty"`
+	FindingNotes     string                       `json:"finding_notes,omitempty"`
+	DiscoveryDate    time.Time                    `json:"discovery_date"`
+	AffectedSystems  []ExportSystem               `json:"affected_systems"`
+	RetestStatus     models.RetestStatus          `json:"retest_status,omitempty"`
+	RetestedAt       *time.Time                   `json:"retested_at,omitempty"`
+	Evidence         []ExportEvidence             `json:"evidence"`
+}
+
+// ExportSystem is a system in an export package
+type ExportSystem struct {
+	ID        uuid.UUID `json:"id"`
+	Hostname  string    `json:"hostname,omitempty"`
+	IPAddress string    `json:"ip_address,omitempty"`
+}
+
+// ExportSeverityCount counts the findings of a severity
+type ExportSeverityCount struct {
+	Severity models.VulnerabilitySeverity `json:"severity"`
+	Count    int                          `json:"count"`
+}
+
+// ExportAssessmentData is the machine-readable content of an export package
+type ExportAssessmentData struct {
+	SchemaVersion  string                `json:"schema_version"`
+	GeneratedAt    time.Time             `json:"generated_at"`
+	GeneratedBy    ExportUser            `json:"generated_by"`
+	Assessment     models.Assessment     `json:"assessment"`
+	Scope          []ExportSystem        `json:"scope"`
+	Findings       []ExportFinding       `json:"findings"` // Most severe first
+	SeverityCounts []ExportSeverityCount `json:"severity_counts"`
+	Retests        RetestSummary         `json:"retests"`
+	Report         ExportReport          `json:"report"`
+}
+
+// ExportAuditEntry is an event of the audit trail excerpt of an export package
+type ExportAuditEntry struct {
+	Timestamp  time.Time   `json:"timestamp"`
+	Action     string      `json:"action"`
+	Actor      *ExportUser `json:"actor,omitempty"`
+	Resource   string      `json:"resource"`
+	ResourceID uuid.UUID   `json:"resource_id"`
+	Details    string      `json:"details,omitempty"`
+}
+
+// ExportAuditTrail is the audit trail excerpt of an export package: the history of the
+// assessment and its findings since the assessment started, oldest first
+type ExportAuditTrail struct {
+	Since     time.Time          `json:"since"`
+	Truncated bool               `json:"truncated"` // Older entries were left out
+	Entries   []ExportAuditEntry `json:"entries"`
+}
+
+// ExportManifestFile is a file of an export package with its SHA-256 digest
+type ExportManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ExportMissingFile is an evidence file that could not be read into an export package
+type ExportMissingFile struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// ExportManifest lists the files of an export package; it is written last, as manifest.json
+type ExportManifest struct {
+	SchemaVersion string               `json:"schema_version"`
+	AssessmentID  uuid.UUID            `json:"assessment_id"`
+	GeneratedAt   time.Time            `json:"generated_at"`
+	GeneratedBy   ExportUser           `json:"generated_by"`
+	Algorithm     string               `json:"algorithm"`
+	Files         []ExportManifestFile `json:"files"`
+	Missing       []ExportMissingFile  `json:"missing,omitempty"`
+}
+
+// exportEvidenceFile is an evidence file to copy into a package
+type exportEvidenceFile struct {
+	path       string
+	attachment models.VulnerabilityAttachment
+}
+
+// ExportPackage is a prepared export package. Its database content is loaded up front; evidence
+// files are read while the package is written.
+type ExportPackage struct {
+	Filename   string
+	Data       ExportAssessmentData
+	AuditTrail ExportAuditTrail
+	ReportData []byte
+	// Detached signature document of the report, if signed
+	ReportSignature []byte
+	evidence        []exportEvidenceFile
+	done            func()
+}
+
+// PreparePackage loads everything an export package of an assessment contains. The report is the
+// latest generated report; when none was generated, a PDF report is rendered for the package
+// without being stored. The package must be written with WritePackage.
+func (s *AssessmentExportService) PreparePackage(assessmentID, exportedBy uuid.UUID) (*ExportPackage, error) {
+	done, err := shutdown.Begin("export")
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := s.preparePackage(assessmentID, exportedBy)
+	if err != nil {
+		done()
+		return nil, err
+	}
+	pkg.done = done
+	return pkg, nil
+}
+
+func (s *AssessmentExportService) preparePackage(assessmentID, exportedBy uuid.UUID) (*ExportPackage, error) {
+	var assessment models.Assessment
+	if err := s.db.First(&assessment, "id = ?", assessmentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("assessment not found")
+		}
+		return nil, fmt.Errorf("failed to get assessment: %w", err)
+	}
+
+	generatedAt := time.Now().UTC()
+	exporter := ExportUser{ID: exportedBy}
+	var user models.User
+	if err := s.db.Select("name", "email").First(&user, "id = ?", exportedBy).Error; err == nil {
+		exporter.Name, exporter.Email = user.Name, user.Email
+	}
+
+	reports := NewAssessmentReportService(s.db)
+	var report models.AssessmentReport
+	var exportReport ExportReport
+	var reportData, reportSignature []byte
+	var content *AssessmentReportContent
+	err := s.db.Where("assessment_id = ? AND generated = ? AND is_latest = ? AND deleted_at IS NULL", assessmentID, true, true).
+		Order("created_at DESC").First(&report).Error
+	switch {
+	case err == nil:
+		if reportData, err = reports.GetReportFile(&report); err != nil {
+			return nil, fmt.Errorf("failed to read report file: %w", err)
+		}
+		if content, _, err = reports.loadReportContent(&assessment, false); err != nil {
+			return nil, err
+		}
+		exportReport = ExportReport{
+			ID:       &report.ID,
+			Title:    report.Title,
+			Version:  report.Version,
+			Path:     path.Join(exportReportDir, exportFileName(report.OriginalName)),
+			MimeType: report.MimeType,
+		}
+		if report.Signature != "" {
+			reportSignature = []byte(report.Signature)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		req := GenerateReportRequest{}
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+		var mimeType string
+		if content, reportData, mimeType, err = reports.renderReport(&assessment, req, exportedBy); err != nil {
+			return nil, err
+		}
+		exportReport = ExportReport{
+			Title:    req.Title,
+			Path:     path.Join(exportReportDir, reportFilename(assessment.Name, req.Format)),
+			MimeType: mimeType,
+		}
+		if signature := SignReportArtifact(reportData); signature != nil {
+			if reportSignature, err = json.Marshal(signature); err != nil {
+				return nil, fmt.Errorf("failed to encode report signature: %w", err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	if reportSignature != nil {
+		exportReport.SignaturePath = exportReport.Path + ".sig"
+	}
+
+	pkg := &ExportPackage{
+		Filename:        fmt.Sprintf("%s_export_%s.zip", filenameBase(assessment.Name), generatedAt.Format("20060102")),
+		ReportData:      reportData,
+		ReportSignature: reportSignature,
+		Data: ExportAssessmentData{
+			SchemaVersion:  ExportSchemaVersion,
+			GeneratedAt:    generatedAt,
+			GeneratedBy:    exporter,
+			Assessment:     assessment,
+			Scope:          exportSystems(content.Scope),
+			Findings:       make([]ExportFinding, 0, len(content.Findings)),
+			SeverityCounts: make([]ExportSeverityCount, 0, len(content.SeverityCounts)),
+			Retests:        content.Retests,
+			Report:         exportReport,
+		},
+	}
+	for _, count := range content.SeverityCounts {
+		pkg.Data.SeverityCounts = append(pkg.Data.SeverityCounts, ExportSeverityCount{Severity: count.Severity, Count: count.Count})
+	}
+
+	vulnerabilityIDs := make([]uuid.UUID, len(content.Findings))
+	for i, finding := range content.Findings {
+		vulnerabilityIDs[i] = finding.Vulnerability.ID
+	}
+	var attachments []models.VulnerabilityAttachment
+	if len(vulnerabilityIDs) > 0 {
+		if err := s.db.Where("vulnerability_id IN ?", vulnerabilityIDs).Order("created_at").Find(&attachments).Error; err != nil {
+			return nil, fmt.Errorf("failed to load evidence: %w", err)
+		}
+	}
+	evidence := make(map[uuid.UUID][]models.VulnerabilityAttachment)
+	for _, attachment := range attachments {
+		evidence[attachment.VulnerabilityID] = append(evidence[attachment.VulnerabilityID], attachment)
+	}
+
+	for _, finding := range content.Findings {
+		vulnerability := finding.Vulnerability
+		exported := ExportFinding{
+			Ref:              finding.Ref,
+			VulnerabilityID:  vulnerability.ID,
+			Title:            vulnerability.Title,
+			Description:      vulnerability.Description,
+			Severity:         vulnerability.Severity,
+			Status:           vulnerability.Status,
+			CVSSScore:        vulnerability.CVSSScore,
+			CVSSVector:       vulnerability.CVSSVector,
+			CVEID:            vulnerability.CVEID,
+			CWEIDs:           vulnerability.CWEIDs,
+			ImpactAssessment: vulnerability.ImpactAssessment,
+			Remediation:      finding.Remediation,
+			FindingNotes:     finding.Notes,
+			DiscoveryDate:    vulnerability.DiscoveryDate,
+			AffectedSystems:  exportSystems(vulnerability.AffectedSystems),
+			Evidence:         []ExportEvidence{},
+		}
+		if finding.Retest != nil {
+			exported.RetestStatus = finding.Retest.Status
+			exported.RetestedAt = finding.Retest.RetestedAt
+		}
+		for _, attachment := range evidence[vulnerability.ID] {
+			filePath := path.Join(exportEvidenceDir, finding.Ref, attachment.ID.String()+"_"+exportFileName(attachment.OriginalName))
+			exported.Evidence = append(exported.Evidence, ExportEvidence{
+				AttachmentID:   attachment.ID,
+				Path:           filePath,
+				OriginalName:   attachment.OriginalName,
+				MimeType:       attachment.MimeType,
+				AttachmentType: attachment.AttachmentType,
+				Description:    attachment.Description,
+				UploadedAt:     attachment.CreatedAt,
+			})
+			pkg.evidence = append(pkg.evidence, exportEvidenceFile{path: filePath, attachment: attachment})
+		}
+		pkg.Data.Findings = append(pkg.Data.Findings, exported)
+	}
+
+	auditTrail, err := s.loadAuditTrail(&assessment, vulnerabilityIDs)
+	if err != nil {
+		return nil, err
+	}
+	pkg.AuditTrail = *auditTrail
+	return pkg, nil
+}
+
+// loadAuditTrail gathers the history of an assessment and its findings since the assessment
+// started: finding status and assignee changes, retests, reports and auditor share tokens
+func (s *AssessmentExportService) loadAuditTrail(assessment *models.Assessment, vulnerabilityIDs []uuid.UUID) (*ExportAuditTrail, error) {
+	since := assessment.StartDate
+	if assessment.CreatedAt.Before(since) {
+		since = assessment.CreatedAt
+	}
+	var entries []ExportAuditEntry
+	actors := map[uuid.UUID]bool{}
+	add := func(timestamp time.Time, action string, actorID *uuid.UUID, resource string, resourceID uuid.UUID, details string) {
+		entry := ExportAuditEntry{Timestamp: timestamp.UTC(), Action: action, Resource: resource, ResourceID: resourceID, Details: details}
+		if actorID != nil && *actorID != uuid.Nil {
+			entry.Actor = &ExportUser{ID: *actorID}
+			actors[*actorID] = true
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(vulnerabilityIDs) > 0 {
+		var statuses []models.VulnerabilityStatusHistory
+		if err := s.db.Where("vulnerability_id IN ? AND changed_at >= ?", vulnerabilityIDs, since).Find(&statuses).Error; err != nil {
+			return nil, fmt.Errorf("failed to load status history: %w", err)
+		}
+		for _, change := range statuses {
+			details := fmt.Sprintf("%s -> %s", change.OldStatus, change.NewStatus)
+			if change.Notes != "" {
+				details += ": " + change.Notes
+			}
+			add(change.ChangedAt, ExportAuditStatusChanged, &change.ChangedByID, "vulnerability", change.VulnerabilityID, details)
+		}
+
+		var assignments []models.VulnerabilityAssignmentHistory
+		if err := s.db.Where("vulnerability_id IN ? AND changed_at >= ?", vulnerabilityIDs, since).Find(&assignments).Error; err != nil {
+			return nil, fmt.Errorf("failed to load assignment history: %w", err)
+		}
+		for _, change := range assignments {
+			details := "unassigned"
+			if change.NewAssigneeID != nil {
+				details = "assigned to " + change.NewAssigneeID.String()
+			}
+			add(change.ChangedAt, ExportAuditAssigneeChanged, &change.ChangedByID, "vulnerability", change.VulnerabilityID, details)
+		}
+	}
+
+	var retests []models.AssessmentRetest
+	if err := s.db.Where("assessment_id = ?", assessment.ID).Find(&retests).Error; err != nil {
+		return nil, fmt.Errorf("failed to load retests: %w", err)
+	}
+	for _, retest := range retests {
+		add(retest.CreatedAt, ExportAuditRetestRequested, &retest.RequestedByID, "retest", retest.ID,
+			fmt.Sprintf("round %d of vulnerability %s", retest.Round, retest.VulnerabilityID))
+		if retest.RetestedAt != nil {
+			add(*retest.RetestedAt, ExportAuditRetestCompleted, retest.RetestedByID, "retest", retest.ID,
+				fmt.Sprintf("round %d of vulnerability %s: %s", retest.Round, retest.VulnerabilityID, retest.Status))
+		}
+	}
+
+	var reports []models.AssessmentReport
+	if err := s.db.Where("assessment_id = ?", assessment.ID).Find(&reports).Error; err != nil {
+		return nil, fmt.Errorf("failed to load reports: %w", err)
+	}
+	for _, report := range reports {
+		action := ExportAuditReportUploaded
+		if report.Generated {
+			action = ExportAuditReportGenerated
+		}
+		add(report.CreatedAt, action, &report.UploadedBy, "report", report.ID, fmt.Sprintf("%s v%d", report.Title, report.Version))
+	}
+
+	var tokens []models.AuditorShareToken
+	if err := s.db.Where("assessment_id = ?", assessment.ID).Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to load share tokens: %w", err)
+	}
+	for _, token := range tokens {
+		add(token.CreatedAt, ExportAuditShareIssued, &token.CreatedByID, "share_token", token.ID,
+			fmt.Sprintf("for %s until %s", token.AuditorName, token.ExpiresAt.UTC().Format(time.RFC3339)))
+		if token.RevokedAt != nil {
+			add(*token.RevokedAt, ExportAuditShareRevoked, token.RevokedByID, "share_token", token.ID,
+				fmt.Sprintf("%d accesses", token.AccessCount))
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	trail := &ExportAuditTrail{Since: since.UTC(), Entries: entries}
+	if len(entries) > maxExportAuditEntries {
+		trail.Entries = entries[len(entries)-maxExportAuditEntries:]
+		trail.Truncated = true
+	}
+	if trail.Entries == nil {
+		trail.Entries = []ExportAuditEntry{}
+	}
+
+	if len(actors) > 0 {
+		ids := make([]uuid.UUID, 0, len(actors))
+		for id := range actors {
+			ids = append(ids, id)
+		}
+		var users []models.User
+		if err := s.db.Select("id", "name", "email").Where("id IN ?", ids).Find(&users).Error; err != nil {
+			return nil, fmt.Errorf("failed to load users: %w", err)
+		}
+		names := make(map[uuid.UUID]models.User, len(users))
+		for _, user := range users {
+			names[user.ID] = user
+		}
+		for i := range trail.Entries {
+			if actor := trail.Entries[i].Actor; actor != nil {
+				actor.Name, actor.Email = names[actor.ID].Name, names[actor.ID].Email
+			}
+		}
+	}
+	return trail, nil
+}
+
+// WritePackage writes the ZIP of a prepared package to w, with manifest.json last, and logs the
+// export. Evidence files that cannot be read are listed as missing in the manifest instead of
+// failing the package, which is already partly sent.
+func (s *AssessmentExportService) WritePackage(pkg *ExportPackage, w io.Writer) (*ExportManifest, error) {
+	if pkg.done != nil {
+		defer pkg.done()
+	}
+	digest := sha256.New()
+	counter := &countingWriter{w: io.MultiWriter(w, digest)}
+	archive := zip.NewWriter(counter)
+	manifest := &ExportManifest{
+		SchemaVersion: ExportSchemaVersion,
+		AssessmentID:  pkg.Data.Assessment.ID,
+		GeneratedAt:   pkg.Data.GeneratedAt,
+		GeneratedBy:   pkg.Data.GeneratedBy,
+		Algorithm:     "SHA-256",
+	}
+
+	add := func(name string, data []byte) error {
+		file, err := writeExportFile(archive, name, pkg.Data.GeneratedAt, data)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, file)
+		return nil
+	}
+	if err := add(pkg.Data.Report.Path, pkg.ReportData); err != nil {
+		return nil, err
+	}
+	if pkg.Data.Report.SignaturePath != "" {
+		if err := add(pkg.Data.Report.SignaturePath, pkg.ReportSignature); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range pkg.evidence {
+		data, err := readStoredFile(file.attachment.StorageBackend, attachmentObjectKey(storageAreaVulnerabilityAttachments, file.attachment.StoragePath))
+		if err != nil {
+			utils.Logger.Warn().Err(err).Str("attachment_id", file.attachment.ID.String()).Msg("Leaving unreadable evidence out of export package")
+			manifest.Missing = append(manifest.Missing, ExportMissingFile{Path: file.path, Error: "file could not be read"})
+			continue
+		}
+		if err := add(file.path, data); err != nil {
+			return nil, err
+		}
+	}
+	for _, file := range []struct {
+		name  string
+		value interface{}
+	}{
+		{exportAssessmentFile, pkg.Data},
+		{exportAuditTrailFile, pkg.AuditTrail},
+	} {
+		data, err := json.MarshalIndent(file.value, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", file.name, err)
+		}
+		if err := add(file.name, data); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Path < manifest.Files[j].Path })
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if _, err := writeExportFile(archive, exportManifestFile, pkg.Data.GeneratedAt, data); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write export package: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("assessment_id", manifest.AssessmentID.String()).
+		Str("exported_by", manifest.GeneratedBy.ID.String()).
+		Str("filename", pkg.Filename).
+		Int("files", len(manifest.Files)+1).
+		Int("missing_files", len(manifest.Missing)).
+		Int64("bytes", counter.n).
+		Str("sha256", hex.EncodeToString(digest.Sum(nil))).
+		Msg("Assessment export package sent")
+	return manifest, nil
+}
+
+// writeExportFile adds a file to a package archive and returns its manifest entry
+func writeExportFile(archive *zip.Writer, name string, modified time.Time, data []byte) (ExportManifestFile, error) {
+	entry, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return ExportManifestFile{}, fmt.Errorf("failed to add %s to export package: %w", name, err)
+	}
+	digest := sha256.New()
+	if _, err := io.MultiWriter(entry, digest).Write(data); err != nil {
+		return ExportManifestFile{}, fmt.Errorf("failed to add %s to export package: %w", name, err)
+	}
+	return ExportManifestFile{Path: name, Size: int64(len(data)), SHA256: hex.EncodeToString(digest.Sum(nil))}, nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// exportSystems lists systems for an export package
+func exportSystems(systems []models.AffectedSystem) []ExportSystem {
+	exported := make([]ExportSystem, 0, len(systems))
+	for _, system := range systems {
+		exported = append(exported, ExportSystem{ID: system.ID, Hostname: system.Hostname, IPAddress: system.IPAddress})
+	}
+	return exported
+}
+
+// exportFileName reduces an uploaded filename to a safe name inside the package
+func exportFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "." || name == "/" || name == "" {
+		name = "file"
+	}
+	return name
+}
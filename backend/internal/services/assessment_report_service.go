@@ -1,38 +1,50 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/docgen"
+	"github.com/cyops/cyops-backend/pkg/shutdown"
+	"github.com/cyops/cyops-backend/pkg/signing"
 	"github.com/cyops/cyops-backend/pkg/utils"
 	"gorm.io/gorm"
 )
 
 type AssessmentReportService struct {
-	db          *gorm.DB
-	uploadDir   string
-	maxFileSize int64 // in bytes
+	db   *gorm.DB
+	area string // Object key prefix in the attachment store
 }
 
 func NewAssessmentReportService(db *gorm.DB) *AssessmentReportService {
-	// Create uploads directory if it doesn't exist
-	uploadDir := "./uploads/assessment-reports"
-	os.MkdirAll(uploadDir, 0755)
-
 	return &AssessmentReportService{
-		db:          db,
-		uploadDir:   uploadDir,
-		maxFileSize: 100 * 1024 * 1024, // 100MB
+		db:   db,
+		area: storageAreaAssessmentReports,
 	}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *AssessmentReportService) WithContext(ctx context.Context) *AssessmentReportService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// visibleAssessments limits a report query to assessments visible in the service's context
+func (s *AssessmentReportService) visibleAssessments(query *gorm.DB) *gorm.DB {
+	return query.Where("assessment_id IN (?)", s.db.Model(&models.Assessment{}).Select("id"))
+}
+
 // UploadReport uploads a PDF report for an assessment
 func (s *AssessmentReportService) UploadReport(
 	assessmentID uuid.UUID,
@@ -46,20 +58,10 @@ func (s *AssessmentReportService) UploadReport(
 		return nil, fmt.Errorf("assessment not found: %w", err)
 	}
 
-	// Validate file size
-	if file.Size > s.maxFileSize {
-		return nil, fmt.Errorf("file size exceeds maximum allowed size of %d MB", s.maxFileSize/1024/1024)
-	}
-
-	// Validate PDF file type
-	mimeType := file.Header.Get("Content-Type")
-	if mimeType != "application/pdf" && !strings.HasSuffix(strings.ToLower(file.Filename), ".pdf") {
-		return nil, fmt.Errorf("only PDF files are allowed")
-	}
-
-	// Normalize MIME type
-	if mimeType == "" {
-		mimeType = "application/pdf"
+	// Reject oversized files before reading them
+	policy := LoadAttachmentPolicy(s.db)
+	if err := policy.CheckSize(AttachmentCategoryReport, file.Size); err != nil {
+		return nil, err
 	}
 
 	// Open uploaded file
@@ -75,12 +77,310 @@ func (s *AssessmentReportService) UploadReport(
 		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
 	}
 
+	// Detect MIME type from the content (PDF only unless the policy allows more)
+	mimeType := DetectAttachmentMimeType(fileData, file.Header.Get("Content-Type"))
+	if err := policy.CheckAttachment(AttachmentCategoryReport, mimeType, int64(len(fileData))); err != nil {
+		return nil, err
+	}
+
+	report := &models.AssessmentReport{
+		AssessmentID: assessmentID,
+		OriginalName: file.Filename,
+		MimeType:     mimeType,
+		Title:        title,
+		Description:  description,
+		UploadedBy:   uploadedBy,
+	}
+	if err := s.storeReport(report, fileData); err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("report_id", report.ID.String()).
+		Str("assessment_id", assessmentID.String()).
+		Str("title", title).
+		Int("version", report.Version).
+		Str("filename", file.Filename).
+		Msg("Assessment report uploaded successfully")
+
+	return report, nil
+}
+
+// maxEvidenceImagesPerFinding caps the screenshots embedded per finding in generated reports
+const maxEvidenceImagesPerFinding = 10
+
+// GenerateReportRequest configures a generated assessment report
+type GenerateReportRequest struct {
+	Format          string `json:"format"` // pdf (default) or docx
+	Title           string `json:"title"`
+	Description     string `json:"description"`
+	IncludeEvidence *bool  `json:"include_evidence"` // Embed image attachments; defaults to true
+	// Report template to brand the report with; the default template when omitted
+	TemplateID *uuid.UUID `json:"template_id,omitempty"`
+}
+
+// Validate normalizes the request and fills in defaults
+func (r *GenerateReportRequest) Validate() error {
+	r.Format = strings.ToLower(strings.TrimSpace(r.Format))
+	if r.Format == "" {
+		r.Format = ReportFormatPDF
+	}
+	if _, ok := reportFormatMimeTypes[r.Format]; !ok {
+		return fmt.Errorf("format must be %s or %s", ReportFormatPDF, ReportFormatDOCX)
+	}
+	r.Title = strings.TrimSpace(r.Title)
+	if r.Title == "" {
+		r.Title = "Assessment Report"
+	}
+	if len(r.Title) > 255 {
+		return fmt.Errorf("title must be at most 255 characters")
+	}
+	return nil
+}
+
+// GenerateReport renders a report from the assessment's linked scope and vulnerabilities and
+// stores it as a new version of the report with the requested title
+func (s *AssessmentReportService) GenerateReport(assessmentID uuid.UUID, req GenerateReportRequest, generatedBy uuid.UUID) (*models.AssessmentReport, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	done, err := shutdown.Begin("report")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	var assessment models.Assessment
+	if err := s.db.First(&assessment, "id = ?", assessmentID).Error; err != nil {
+		return nil, fmt.Errorf("assessment not found: %w", err)
+	}
+
+	content, data, mimeType, err := s.renderReport(&assessment, req, generatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.AssessmentReport{
+		AssessmentID: assessmentID,
+		OriginalName: reportFilename(assessment.Name, req.Format),
+		MimeType:     mimeType,
+		Title:        req.Title,
+		Description:  req.Description,
+		Generated:    true,
+		UploadedBy:   generatedBy,
+	}
+	if signature := SignReportArtifact(data); signature != nil {
+		document, err := json.Marshal(signature)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode report signature: %w", err)
+		}
+		report.SigningKeyID = signature.KeyID
+		report.SignedAt = &signature.SignedAt
+		report.Signature = string(document)
+	}
+	if err := s.storeReport(report, data); err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("report_id", report.ID.String()).
+		Str("assessment_id", assessmentID.String()).
+		Str("title", report.Title).
+		Int("version", report.Version).
+		Str("format", req.Format).
+		Int("findings", len(content.Findings)).
+		Msg("Assessment report generated successfully")
+
+	return report, nil
+}
+
+// renderReport renders a report of an assessment without storing it and returns its content,
+// document and MIME type. req must be validated.
+func (s *AssessmentReportService) renderReport(assessment *models.Assessment, req GenerateReportRequest, generatedBy uuid.UUID) (*AssessmentReportContent, []byte, string, error) {
+	reportTemplate, err := NewReportTemplateService(s.db).ResolveTemplate(req.TemplateID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	includeEvidence := req.IncludeEvidence == nil || *req.IncludeEvidence
+	if reportTemplate != nil && !reportTemplate.Sections.Evidence {
+		includeEvidence = false
+	}
+
+	content, images, err := s.loadReportContent(assessment, includeEvidence)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	content.Title = req.Title
+	content.Branding = NewReportBranding(reportTemplate, images)
+	var user models.User
+	if err := s.db.Select("name", "email").First(&user, "id = ?", generatedBy).Error; err == nil {
+		content.GeneratedBy = user.Name
+		if content.GeneratedBy == "" {
+			content.GeneratedBy = user.Email
+		}
+	}
+
+	data, mimeType, err := RenderAssessmentReport(*content, images, req.Format)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return content, data, mimeType, nil
+}
+
+// loadReportContent gathers an assessment's scope, linked vulnerabilities and (optionally) their
+// evidence images
+func (s *AssessmentReportService) loadReportContent(assessment *models.Assessment, includeEvidence bool) (*AssessmentReportContent, map[string]docgen.Image, error) {
+	scope, err := NewAssessmentService(s.db).GetScope(assessment.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var links []models.AssessmentVulnerability
+	if err := s.db.Where("assessment_id = ?", assessment.ID.String()).Find(&links).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load linked vulnerabilities: %w", err)
+	}
+	notes := make(map[string]string, len(links))
+	ids := make([]string, 0, len(links))
+	for _, link := range links {
+		notes[link.VulnerabilityID] = link.FindingNotes
+		ids = append(ids, link.VulnerabilityID)
+	}
+
+	var vulnerabilities []models.Vulnerability
+	if len(ids) > 0 {
+		if err := s.db.Preload("AffectedSystems").Where("id IN ?", ids).Find(&vulnerabilities).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to load linked vulnerabilities: %w", err)
+		}
+	}
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		a, b := vulnerabilities[i], vulnerabilities[j]
+		if severityRank(a.Severity) != severityRank(b.Severity) {
+			return severityRank(a.Severity) > severityRank(b.Severity)
+		}
+		if cvssValue(a.CVSSScore) != cvssValue(b.CVSSScore) {
+			return cvssValue(a.CVSSScore) > cvssValue(b.CVSSScore)
+		}
+		return a.Title < b.Title
+	})
+
+	latest, err := latestRetests(s.db, assessment.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	retests := make(map[uuid.UUID]*models.AssessmentRetest, len(latest))
+	for i := range latest {
+		retests[latest[i].VulnerabilityID] = &latest[i]
+	}
+
+	images := make(map[string]docgen.Image)
+	findings := make([]AssessmentReportFinding, len(vulnerabilities))
+	vulnerabilityIDs := make([]uuid.UUID, len(vulnerabilities))
+	for i, vulnerability := range vulnerabilities {
+		vulnerabilityIDs[i] = vulnerability.ID
+		remediation := vulnerability.MitigationRecommendations
+		if remediation == "" {
+			remediation = vulnerability.RemediationNotes
+		}
+		findings[i] = AssessmentReportFinding{
+			Ref:           fmt.Sprintf("F-%02d", i+1),
+			Vulnerability: vulnerability,
+			Notes:         notes[vulnerability.ID.String()],
+			Remediation:   remediation,
+			Retest:        retests[vulnerability.ID],
+		}
+		if includeEvidence {
+			findings[i].Evidence = s.loadEvidence(vulnerability.ID, findings[i].Ref, images)
+		}
+	}
+
+	return &AssessmentReportContent{
+		Assessment:     *assessment,
+		Scope:          scope,
+		Findings:       findings,
+		SeverityCounts: countFindingsBySeverity(findings),
+		Retests:        SummarizeRetests(vulnerabilityIDs, latest),
+		GeneratedAt:    time.Now(),
+	}, images, nil
+}
+
+// loadEvidence reads a vulnerability's image attachments into images. Unreadable images are
+// left out of the report rather than failing it.
+func (s *AssessmentReportService) loadEvidence(vulnerabilityID uuid.UUID, ref string, images map[string]docgen.Image) []AssessmentReportEvidence {
+	var attachments []models.VulnerabilityAttachment
+	if err := s.db.
+		Where("vulnerability_id = ? AND is_image = ?", vulnerabilityID, true).
+		Order("created_at ASC").
+		Limit(maxEvidenceImagesPerFinding).
+		Find(&attachments).Error; err != nil {
+		utils.Logger.Warn().Err(err).Str("vulnerability_id", vulnerabilityID.String()).Msg("Failed to load report evidence")
+		return nil
+	}
+
+	var evidence []AssessmentReportEvidence
+	for _, attachment := range attachments {
+		data, err := readStoredFile(attachment.StorageBackend, attachmentObjectKey(storageAreaVulnerabilityAttachments, attachment.StoragePath))
+		if err == nil {
+			var image docgen.Image
+			if image, err = docgen.NewImage(data); err == nil {
+				images[attachment.ID.String()] = image
+			}
+		}
+		if err != nil {
+			utils.Logger.Warn().Err(err).Str("attachment_id", attachment.ID.String()).Msg("Skipping unreadable report evidence")
+			continue
+		}
+
+		caption := attachment.Description
+		if caption == "" {
+			caption = attachment.OriginalName
+		}
+		evidence = append(evidence, AssessmentReportEvidence{
+			Key:     attachment.ID.String(),
+			Caption: fmt.Sprintf("%s evidence %d: %s", ref, len(evidence)+1, caption),
+		})
+	}
+	return evidence
+}
+
+// reportFilename builds a download filename from the assessment name
+func reportFilename(assessmentName, format string) string {
+	return fmt.Sprintf("%s_report.%s", filenameBase(assessmentName), format)
+}
+
+// filenameBase reduces an assessment name to a safe filename stem
+func filenameBase(assessmentName string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(assessmentName))
+	name = strings.Trim(name, "_")
+	if name == "" {
+		name = "assessment"
+	}
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	return name
+}
+
+// cvssValue returns a CVSS score for sorting, treating a missing score as zero
+func cvssValue(score *float64) float64 {
+	if score == nil {
+		return 0
+	}
+	return *score
+}
+
+// storeReport saves a report file and creates its record as the latest version of its title
+func (s *AssessmentReportService) storeReport(report *models.AssessmentReport, fileData []byte) error {
 	// Check for existing reports with the same title
 	var previousReport *models.AssessmentReport
 	var version int = 1
 	var parentID *uuid.UUID = nil
 
-	err = s.db.Where("assessment_id = ? AND title = ? AND is_latest = ?", assessmentID, title, true).
+	err := s.db.Where("assessment_id = ? AND title = ? AND is_latest = ?", report.AssessmentID, report.Title, true).
 		First(&previousReport).Error
 
 	if err == nil {
@@ -89,69 +389,48 @@ func (s *AssessmentReportService) UploadReport(
 		parentID = &previousReport.ID
 		previousReport.IsLatest = false
 		if err := s.db.Save(previousReport).Error; err != nil {
-			return nil, fmt.Errorf("failed to update previous version: %w", err)
+			return fmt.Errorf("failed to update previous version: %w", err)
 		}
 	} else if err != gorm.ErrRecordNotFound {
-		return nil, fmt.Errorf("failed to check for existing reports: %w", err)
+		return fmt.Errorf("failed to check for existing reports: %w", err)
 	}
 
 	// Generate unique filename
-	ext := filepath.Ext(file.Filename)
+	ext := filepath.Ext(report.OriginalName)
 	if ext == "" {
 		ext = ".pdf"
 	}
 	uniqueName := fmt.Sprintf("%s_%d%s", uuid.New().String(), time.Now().Unix(), ext)
-	storagePath := filepath.Join(assessmentID.String(), uniqueName)
-	fullPath := filepath.Join(s.uploadDir, storagePath)
-
-	// Create directory for this assessment
-	assessmentDir := filepath.Join(s.uploadDir, assessmentID.String())
-	if err := os.MkdirAll(assessmentDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create upload directory: %w", err)
-	}
+	storagePath := filepath.Join(report.AssessmentID.String(), uniqueName)
+	store := ActiveAttachmentStorage().Store
+	ctx := context.Background()
 
-	// Save PDF file
-	if err := os.WriteFile(fullPath, fileData, 0644); err != nil {
-		return nil, fmt.Errorf("failed to save file: %w", err)
+	// Save report file
+	if err := store.Put(ctx, s.objectKey(storagePath), fileData, report.MimeType); err != nil {
+		return fmt.Errorf("failed to save file: %w", err)
 	}
 
 	// Create report record
-	report := &models.AssessmentReport{
-		AssessmentID: assessmentID,
-		Filename:     uniqueName,
-		OriginalName: file.Filename,
-		MimeType:     mimeType,
-		FileSize:     file.Size,
-		StoragePath:  storagePath,
-		Title:        title,
-		Description:  description,
-		Version:      version,
-		IsLatest:     true,
-		ParentID:     parentID,
-		UploadedBy:   uploadedBy,
-	}
+	report.Filename = uniqueName
+	report.FileSize = int64(len(fileData))
+	report.StoragePath = storagePath
+	report.StorageBackend = store.Driver()
+	report.Version = version
+	report.IsLatest = true
+	report.ParentID = parentID
 
 	if err := s.db.Create(report).Error; err != nil {
 		// Clean up uploaded file on database error
-		os.Remove(fullPath)
-		return nil, fmt.Errorf("failed to save report record: %w", err)
+		store.Delete(ctx, s.objectKey(storagePath))
+		return fmt.Errorf("failed to save report record: %w", err)
 	}
-
-	utils.Logger.Info().
-		Str("report_id", report.ID.String()).
-		Str("assessment_id", assessmentID.String()).
-		Str("title", title).
-		Int("version", version).
-		Str("filename", file.Filename).
-		Msg("Assessment report uploaded successfully")
-
-	return report, nil
+	return nil
 }
 
 // GetReport retrieves a report by ID
 func (s *AssessmentReportService) GetReport(id uuid.UUID) (*models.AssessmentReport, error) {
 	var report models.AssessmentReport
-	if err := s.db.Preload("UploadedByUser").First(&report, "id = ?", id).Error; err != nil {
+	if err := s.visibleAssessments(s.db.Preload("UploadedByUser")).First(&report, "id = ?", id).Error; err != nil {
 		return nil, fmt.Errorf("report not found: %w", err)
 	}
 	return &report, nil
@@ -160,7 +439,7 @@ func (s *AssessmentReportService) GetReport(id uuid.UUID) (*models.AssessmentRep
 // GetAssessmentReports retrieves all latest reports for an assessment
 func (s *AssessmentReportService) GetAssessmentReports(assessmentID uuid.UUID, includeAllVersions bool) ([]models.AssessmentReport, error) {
 	var reports []models.AssessmentReport
-	query := s.db.Preload("UploadedByUser").Where("assessment_id = ?", assessmentID)
+	query := s.visibleAssessments(s.db.Preload("UploadedByUser")).Where("assessment_id = ?", assessmentID)
 
 	if !includeAllVersions {
 		query = query.Where("is_latest = ?", true)
@@ -173,8 +452,7 @@ func (s *AssessmentReportService) GetAssessmentReports(assessmentID uuid.UUID, i
 // GetReportVersions retrieves all versions of a report by title
 func (s *AssessmentReportService) GetReportVersions(assessmentID uuid.UUID, title string) ([]models.AssessmentReport, error) {
 	var reports []models.AssessmentReport
-	err := s.db.
-		Preload("UploadedByUser").
+	err := s.visibleAssessments(s.db.Preload("UploadedByUser")).
 		Where("assessment_id = ? AND title = ?", assessmentID, title).
 		Order("version DESC").
 		Find(&reports).Error
@@ -184,20 +462,35 @@ func (s *AssessmentReportService) GetReportVersions(assessmentID uuid.UUID, titl
 
 // GetReportFile retrieves the file data for a report
 func (s *AssessmentReportService) GetReportFile(report *models.AssessmentReport) ([]byte, error) {
-	filePath := filepath.Join(s.uploadDir, report.StoragePath)
+	return readStoredFile(report.StorageBackend, s.objectKey(report.StoragePath))
+}
 
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+// GetReportSignature returns the detached signature of a generated report
+func (s *AssessmentReportService) GetReportSignature(report *models.AssessmentReport) (*signing.Signature, error) {
+	if report.Signature == "" {
+		return nil, fmt.Errorf("report signature not found")
 	}
+	var signature signing.Signature
+	if err := json.Unmarshal([]byte(report.Signature), &signature); err != nil {
+		return nil, fmt.Errorf("failed to decode report signature: %w", err)
+	}
+	return &signature, nil
+}
+
+// GetDownloadURL returns a presigned download URL for a report and when it expires
+func (s *AssessmentReportService) GetDownloadURL(report *models.AssessmentReport) (string, time.Time, error) {
+	return presignStoredFile(report.StorageBackend, s.objectKey(report.StoragePath), report.OriginalName)
+}
 
-	return data, nil
+// objectKey returns the attachment store key of a path relative to the reports area
+func (s *AssessmentReportService) objectKey(storagePath string) string {
+	return attachmentObjectKey(s.area, storagePath)
 }
 
 // DeleteReport deletes a report (soft delete)
 func (s *AssessmentReportService) DeleteReport(id uuid.UUID) error {
 	var report models.AssessmentReport
-	if err := s.db.First(&report, "id = ?", id).Error; err != nil {
+	if err := s.visibleAssessments(s.db).First(&report, "id = ?", id).Error; err != nil {
 		return fmt.Errorf("report not found: %w", err)
 	}
 
@@ -206,7 +499,7 @@ func (s *AssessmentReportService) DeleteReport(id uuid.UUID) error {
 		return fmt.Errorf("failed to delete report: %w", err)
 	}
 
-	// Note: We keep the files on disk even after soft delete for audit purposes
+	// Note: We keep the stored files even after soft delete for audit purposes
 	// Hard delete cleanup should be done by a separate cleanup job
 
 	utils.Logger.Info().
@@ -220,7 +513,7 @@ func (s *AssessmentReportService) DeleteReport(id uuid.UUID) error {
 
 // GetReportStats returns statistics about reports
 func (s *AssessmentReportService) GetReportStats(assessmentID *uuid.UUID) (map[string]interface{}, error) {
-	query := s.db.Model(&models.AssessmentReport{})
+	query := s.visibleAssessments(s.db.Model(&models.AssessmentReport{}))
 	if assessmentID != nil {
 		query = query.Where("assessment_id = ?", *assessmentID)
 	}
@@ -237,7 +530,7 @@ func (s *AssessmentReportService) GetReportStats(assessmentID *uuid.UUID) (map[s
 	query.Select("COALESCE(SUM(file_size), 0) as total_size").Scan(&stats.TotalSize)
 
 	// Count latest versions
-	s.db.Model(&models.AssessmentReport{}).
+	s.visibleAssessments(s.db.Model(&models.AssessmentReport{})).
 		Where("assessment_id = ? AND is_latest = ?", assessmentID, true).
 		Count(&stats.LatestCount)
 
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/docgen"
+)
+
+// Generated report formats
+const (
+	ReportFormatPDF  = "pdf"
+	ReportFormatDOCX = "docx"
+)
+
+var reportFormatMimeTypes = map[string]string{
+	ReportFormatPDF:  "application/pdf",
+	ReportFormatDOCX: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+}
+
+//go:embed templates/assessment_report.tmpl
+var assessmentReportTemplateSource string
+
+var assessmentReportTemplate = template.Must(template.New("assessment_report").Funcs(template.FuncMap{
+	"text": docgen.EscapeText,
+	"cell": docgen.EscapeCell,
+	"code": docgen.EscapeCode,
+	"line": func(value string) string {
+		return strings.Join(strings.Fields(value), " ")
+	},
+	"label": func(value interface{}) string {
+		// Enum values such as PENETRATION_TEST read as "Penetration Test"
+		words := strings.Fields(strings.ReplaceAll(fmt.Sprint(value), "_", " "))
+		for i, word := range words {
+			words[i] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+		}
+		return docgen.EscapeCell(strings.Join(words, " "))
+	},
+	"date": func(value interface{}) string {
+		switch t := value.(type) {
+		case time.Time:
+			return t.Format("2006-01-02")
+		case *time.Time:
+			if t != nil {
+				return t.Format("2006-01-02")
+			}
+		}
+		return "-"
+	},
+	"datetime": func(t time.Time) string {
+		return t.UTC().Format("2006-01-02 15:04 UTC")
+	},
+	"retest": func(retest *models.AssessmentRetest) string {
+		if retest == nil {
+			return retestOutcomeLabel("")
+		}
+		return retestOutcomeLabel(retest.Status)
+	},
+	"cvss": func(score *float64) string {
+		if score == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f", *score)
+	},
+}).Parse(assessmentReportTemplateSource))
+
+// AssessmentReportContent is the data a generated assessment report is rendered from
+type AssessmentReportContent struct {
+	Title          string
+	Assessment     models.Assessment
+	Scope          []models.AffectedSystem
+	Findings       []AssessmentReportFinding // Most severe first
+	SeverityCounts []AssessmentReportSeverityCount
+	Retests        RetestSummary
+	GeneratedAt    time.Time
+	GeneratedBy    string
+	Branding       ReportBranding
+}
+
+// ReportBranding is what a report template puts on a generated report
+type ReportBranding struct {
+	CompanyName string
+	IntroText   string
+	LogoKey     string // Key of the logo in the images passed to RenderAssessmentReport; empty without one
+	Sections    models.ReportSections
+}
+
+// reportLogoKey is the image key of a template's logo
+const reportLogoKey = "report-template-logo"
+
+// Largest logo on a report cover, in pixels
+const (
+	reportLogoMaxWidth  = 320
+	reportLogoMaxHeight = 120
+)
+
+// NewReportBranding returns the branding of a report template, adding its logo to images when
+// given (CSV exports have no images). A nil template brands nothing and includes every section.
+func NewReportBranding(reportTemplate *models.ReportTemplate, images map[string]docgen.Image) ReportBranding {
+	if reportTemplate == nil {
+		return ReportBranding{Sections: models.AllReportSections()}
+	}
+	branding := ReportBranding{
+		CompanyName: reportTemplate.CompanyName,
+		IntroText:   reportTemplate.IntroText,
+		Sections:    reportTemplate.Sections,
+	}
+	if images != nil && len(reportTemplate.Logo) > 0 {
+		logo, err := docgen.NewImageFit(reportTemplate.Logo, reportLogoMaxWidth, reportLogoMaxHeight)
+		if err == nil {
+			images[reportLogoKey] = logo
+			branding.LogoKey = reportLogoKey
+		}
+	}
+	return branding
+}
+
+// AssessmentReportFinding is one linked vulnerability in a generated report
+type AssessmentReportFinding struct {
+	Ref           string // Short reference used across the report, e.g. F-01
+	Vulnerability models.Vulnerability
+	Notes         string // Assessment-specific finding notes
+	Remediation   string
+	Evidence      []AssessmentReportEvidence
+	Retest        *models.AssessmentRetest // Latest retest round, if any
+}
+
+// AssessmentReportEvidence is an evidence image shown with a finding
+type AssessmentReportEvidence struct {
+	Key     string // Key of the image in the images passed to RenderAssessmentReport
+	Caption string
+}
+
+// AssessmentReportSeverityCount is the number of findings of one severity
+type AssessmentReportSeverityCount struct {
+	Severity models.VulnerabilitySeverity
+	Count    int
+}
+
+// RenderAssessmentReport renders report content in the given format (pdf or docx) and returns
+// the document with its MIME type
+func RenderAssessmentReport(content AssessmentReportContent, images map[string]docgen.Image, format string) ([]byte, string, error) {
+	mimeType, ok := reportFormatMimeTypes[format]
+	if !ok {
+		return nil, "", fmt.Errorf("invalid report format: %s", format)
+	}
+
+	var markup bytes.Buffer
+	if err := assessmentReportTemplate.Execute(&markup, content); err != nil {
+		return nil, "", fmt.Errorf("failed to render report template: %w", err)
+	}
+	doc, err := docgen.Parse(content.Title, markup.String(), images)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build report: %w", err)
+	}
+
+	var data []byte
+	if format == ReportFormatDOCX {
+		data, err = docgen.RenderDOCX(doc)
+	} else {
+		data, err = docgen.RenderPDF(doc)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to render report: %w", err)
+	}
+	return data, mimeType, nil
+}
+
+// countFindingsBySeverity tallies findings per severity, most severe first, omitting
+// severities without findings
+func countFindingsBySeverity(findings []AssessmentReportFinding) []AssessmentReportSeverityCount {
+	severities := []models.VulnerabilitySeverity{
+		models.SeverityCritical, models.SeverityHigh, models.SeverityMedium, models.SeverityLow, models.SeverityNone,
+	}
+	var counts []AssessmentReportSeverityCount
+	for _, severity := range severities {
+		count := 0
+		for _, finding := range findings {
+			if finding.Vulnerability.Severity == severity {
+				count++
+			}
+		}
+		if count > 0 {
+			counts = append(counts, AssessmentReportSeverityCount{Severity: severity, Count: count})
+		}
+	}
+	return counts
+}
@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AssessmentRetestService handles the retest workflow of assessment findings: marking them
+// ready for retest, assigning a retester and recording the outcome with evidence
+type AssessmentRetestService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+// NewAssessmentRetestService creates a new assessment retest service
+func NewAssessmentRetestService(db *gorm.DB) *AssessmentRetestService {
+	return &AssessmentRetestService{
+		db:                  db,
+		notificationService: NewNotificationService(db),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *AssessmentRetestService) WithContext(ctx context.Context) *AssessmentRetestService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// visibleAssessmentIDs returns a subquery selecting the assessments visible in db's context,
+// for use in "IN (?)" filters
+func visibleAssessmentIDs(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.Assessment{}).Select("id")
+}
+
+// RequestRetestRequest marks an assessment finding ready for retest
+type RequestRetestRequest struct {
+	VulnerabilityID uuid.UUID  `json:"vulnerability_id"`
+	RetesterID      *uuid.UUID `json:"retester_id,omitempty"`
+	Notes           string     `json:"notes,omitempty"`
+}
+
+// CompleteRetestRequest records the outcome of a retest
+type CompleteRetestRequest struct {
+	Outcome               models.RetestStatus `json:"outcome"`
+	Notes                 string              `json:"notes,omitempty"`
+	Evidence              string              `json:"evidence,omitempty"`
+	EvidenceAttachmentIDs []uuid.UUID         `json:"evidence_attachment_ids,omitempty"`
+}
+
+// Validate normalizes the request and checks that it records an outcome backed by evidence
+func (r *CompleteRetestRequest) Validate() error {
+	r.Outcome = models.RetestStatus(strings.ToUpper(strings.TrimSpace(string(r.Outcome))))
+	if !r.Outcome.IsOutcome() {
+		return fmt.Errorf("outcome must be one of %s, %s, %s", models.RetestFixed, models.RetestNotFixed, models.RetestPartiallyFixed)
+	}
+	r.Notes = strings.TrimSpace(r.Notes)
+	r.Evidence = strings.TrimSpace(r.Evidence)
+	if r.Evidence == "" && len(r.EvidenceAttachmentIDs) == 0 {
+		return fmt.Errorf("evidence or evidence_attachment_ids is required")
+	}
+	return nil
+}
+
+// RetestSummary is the retest status of every finding linked to an assessment
+type RetestSummary struct {
+	TotalFindings  int                   `json:"total_findings"`
+	NotRequested   int                   `json:"not_requested"`
+	ReadyForRetest int                   `json:"ready_for_retest"`
+	Fixed          int                   `json:"fixed"`
+	NotFixed       int                   `json:"not_fixed"`
+	PartiallyFixed int                   `json:"partially_fixed"`
+	Findings       []FindingRetestStatus `json:"findings"`
+}
+
+// FindingRetestStatus is the latest retest round of one assessment finding. Status is empty
+// when no retest has been requested.
+type FindingRetestStatus struct {
+	VulnerabilityID uuid.UUID           `json:"vulnerability_id"`
+	Status          models.RetestStatus `json:"status,omitempty"`
+	Round           int                 `json:"round,omitempty"`
+	RetestID        *uuid.UUID          `json:"retest_id,omitempty"`
+	RetesterID      *uuid.UUID          `json:"retester_id,omitempty"`
+	RetestedAt      *time.Time          `json:"retested_at,omitempty"`
+}
+
+// SummarizeRetests combines an assessment's linked findings with their latest retest rounds
+func SummarizeRetests(vulnerabilityIDs []uuid.UUID, latest []models.AssessmentRetest) RetestSummary {
+	rounds := make(map[uuid.UUID]models.AssessmentRetest, len(latest))
+	for _, retest := range latest {
+		if current, ok := rounds[retest.VulnerabilityID]; !ok || retest.Round > current.Round {
+			rounds[retest.VulnerabilityID] = retest
+		}
+	}
+
+	summary := RetestSummary{TotalFindings: len(vulnerabilityIDs), Findings: make([]FindingRetestStatus, 0, len(vulnerabilityIDs))}
+	for _, vulnerabilityID := range vulnerabilityIDs {
+		status := FindingRetestStatus{VulnerabilityID: vulnerabilityID}
+		retest, ok := rounds[vulnerabilityID]
+		if ok {
+			retestID := retest.ID
+			status.Status = retest.Status
+			status.Round = retest.Round
+			status.RetestID = &retestID
+			status.RetesterID = retest.RetesterID
+			status.RetestedAt = retest.RetestedAt
+		}
+		summary.Findings = append(summary.Findings, status)
+
+		switch status.Status {
+		case models.RetestReady:
+			summary.ReadyForRetest++
+		case models.RetestFixed:
+			summary.Fixed++
+		case models.RetestNotFixed:
+			summary.NotFixed++
+		case models.RetestPartiallyFixed:
+			summary.PartiallyFixed++
+		default:
+			summary.NotRequested++
+		}
+	}
+	return summary
+}
+
+// getRetest loads a retest of an assessment with its relations
+func (s *AssessmentRetestService) getRetest(db *gorm.DB, assessmentID, id uuid.UUID) (*models.AssessmentRetest, error) {
+	var retest models.AssessmentRetest
+	if err := db.
+		Preload("Vulnerability").
+		Preload("RequestedBy").
+		Preload("Retester").
+		Preload("RetestedBy").
+		Where("assessment_id IN (?)", visibleAssessmentIDs(db)).
+		First(&retest, "id = ? AND assessment_id = ?", id, assessmentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("retest not found")
+		}
+		return nil, fmt.Errorf("failed to get retest: %w", err)
+	}
+	return &retest, nil
+}
+
+// GetRetest returns a single retest of an assessment
+func (s *AssessmentRetestService) GetRetest(assessmentID, id uuid.UUID) (*models.AssessmentRetest, error) {
+	return s.getRetest(s.db, assessmentID, id)
+}
+
+// ListRetests returns an assessment's retest rounds, latest round first
+func (s *AssessmentRetestService) ListRetests(assessmentID uuid.UUID, vulnerabilityID *uuid.UUID, status string) ([]models.AssessmentRetest, error) {
+	query := s.db.Where("assessment_id = ?", assessmentID).Where("assessment_id IN (?)", visibleAssessmentIDs(s.db))
+	if vulnerabilityID != nil {
+		query = query.Where("vulnerability_id = ?", *vulnerabilityID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", strings.ToUpper(status))
+	}
+
+	var retests []models.AssessmentRetest
+	if err := query.
+		Preload("Vulnerability").
+		Preload("RequestedBy").
+		Preload("Retester").
+		Preload("RetestedBy").
+		Order("created_at DESC").
+		Find(&retests).Error; err != nil {
+		return nil, fmt.Errorf("failed to list retests: %w", err)
+	}
+	return retests, nil
+}
+
+// GetRetestSummary returns the current retest status of every finding linked to an assessment
+func (s *AssessmentRetestService) GetRetestSummary(assessmentID uuid.UUID) (*RetestSummary, error) {
+	var assessment models.Assessment
+	if err := s.db.Select("id").First(&assessment, "id = ?", assessmentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("assessment not found")
+		}
+		return nil, fmt.Errorf("failed to get assessment: %w", err)
+	}
+
+	var vulnerabilityIDs []uuid.UUID
+	if err := s.db.Model(&models.AssessmentVulnerability{}).
+		Where("assessment_id = ?", assessmentID.String()).
+		Order("created_at ASC").
+		Pluck("vulnerability_id", &vulnerabilityIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load assessment findings: %w", err)
+	}
+
+	latest, err := latestRetests(s.db, assessmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := SummarizeRetests(vulnerabilityIDs, latest)
+	return &summary, nil
+}
+
+// latestRetests returns the latest retest round of each finding of an assessment
+func latestRetests(db *gorm.DB, assessmentID uuid.UUID) ([]models.AssessmentRetest, error) {
+	var latest []models.AssessmentRetest
+	if err := db.
+		Raw(`SELECT DISTINCT ON (vulnerability_id) * FROM assessment_retests
+			WHERE assessment_id = ? AND deleted_at IS NULL
+			ORDER BY vulnerability_id, round DESC`, assessmentID).
+		Scan(&latest).Error; err != nil {
+		return nil, fmt.Errorf("failed to load retests: %w", err)
+	}
+	return latest, nil
+}
+
+// RequestRetest marks an assessment finding ready for retest, starting a new retest round
+func (s *AssessmentRetestService) RequestRetest(assessmentID uuid.UUID, req RequestRetestRequest, requestedByID uuid.UUID) (*models.AssessmentRetest, error) {
+	retest := &models.AssessmentRetest{
+		AssessmentID:    assessmentID,
+		VulnerabilityID: req.VulnerabilityID,
+		Status:          models.RetestReady,
+		RequestedByID:   requestedByID,
+		RequestNotes:    strings.TrimSpace(req.Notes),
+		RetesterID:      req.RetesterID,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var assessment models.Assessment
+		if err := tx.Select("id").First(&assessment, "id = ?", assessmentID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("assessment not found")
+			}
+			return fmt.Errorf("failed to get assessment: %w", err)
+		}
+
+		var link models.AssessmentVulnerability
+		if err := tx.Where("assessment_id = ? AND vulnerability_id = ?", assessmentID.String(), req.VulnerabilityID.String()).
+			First(&link).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("finding not found in this assessment")
+			}
+			return fmt.Errorf("failed to get assessment finding: %w", err)
+		}
+
+		// Lock the finding's rounds so concurrent requests cannot open two
+		var previous models.AssessmentRetest
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("assessment_id = ? AND vulnerability_id = ?", assessmentID, req.VulnerabilityID).
+			Order("round DESC").
+			First(&previous).Error
+		switch {
+		case err == nil:
+			if previous.Status == models.RetestReady {
+				return fmt.Errorf("finding is already awaiting retest")
+			}
+			retest.Round = previous.Round + 1
+		case err == gorm.ErrRecordNotFound:
+			retest.Round = 1
+		default:
+			return fmt.Errorf("failed to check previous retests: %w", err)
+		}
+
+		if req.RetesterID != nil {
+			if err := requireRetester(tx, *req.RetesterID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(retest).Error; err != nil {
+			return fmt.Errorf("failed to create retest: %w", err)
+		}
+
+		if req.RetesterID != nil && *req.RetesterID != requestedByID {
+			return s.notificationService.CreateNotifications(tx, []models.Notification{
+				s.notification(retest, *req.RetesterID, models.NotificationTypeRetestAssigned,
+					"Finding ready for retest", retest.RequestNotes, &requestedByID),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("retest_id", retest.ID.String()).
+		Str("assessment_id", assessmentID.String()).
+		Str("vulnerability_id", req.VulnerabilityID.String()).
+		Int("round", retest.Round).
+		Msg("Finding marked ready for retest")
+
+	return s.getRetest(s.db, assessmentID, retest.ID)
+}
+
+// AssignRetester assigns (or reassigns) the retester of a pending retest
+func (s *AssessmentRetestService) AssignRetester(assessmentID, id, retesterID, assignedByID uuid.UUID) (*models.AssessmentRetest, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		retest, err := s.getPendingRetest(tx, assessmentID, id)
+		if err != nil {
+			return err
+		}
+		if err := requireRetester(tx, retesterID); err != nil {
+			return err
+		}
+
+		if err := tx.Model(retest).Update("retester_id", retesterID).Error; err != nil {
+			return fmt.Errorf("failed to assign retester: %w", err)
+		}
+
+		if retesterID == assignedByID {
+			return nil
+		}
+		return s.notificationService.CreateNotifications(tx, []models.Notification{
+			s.notification(retest, retesterID, models.NotificationTypeRetestAssigned,
+				"Finding ready for retest", retest.RequestNotes, &assignedByID),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("retest_id", id.String()).
+		Str("retester_id", retesterID.String()).
+		Msg("Retester assigned")
+
+	return s.getRetest(s.db, assessmentID, id)
+}
+
+// CompleteRetest records the outcome and evidence of a pending retest. Only the assigned
+// retester may complete an assigned retest; an unassigned one is assigned to whoever completes it.
+func (s *AssessmentRetestService) CompleteRetest(assessmentID, id uuid.UUID, req CompleteRetestRequest, userID uuid.UUID) (*models.AssessmentRetest, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		retest, err := s.getPendingRetest(tx, assessmentID, id)
+		if err != nil {
+			return err
+		}
+		if retest.RetesterID != nil && *retest.RetesterID != userID {
+			return fmt.Errorf("only the assigned retester can record the outcome")
+		}
+
+		attachmentIDs := make([]string, 0, len(req.EvidenceAttachmentIDs))
+		for _, attachmentID := range req.EvidenceAttachmentIDs {
+			attachmentIDs = append(attachmentIDs, attachmentID.String())
+		}
+		if len(attachmentIDs) > 0 {
+			var found int64
+			if err := tx.Model(&models.VulnerabilityAttachment{}).
+				Where("id IN ? AND vulnerability_id = ?", attachmentIDs, retest.VulnerabilityID).
+				Count(&found).Error; err != nil {
+				return fmt.Errorf("failed to check evidence attachments: %w", err)
+			}
+			if int(found) != len(attachmentIDs) {
+				return fmt.Errorf("evidence attachment not found for this vulnerability")
+			}
+		}
+
+		if err := tx.Model(retest).Updates(map[string]interface{}{
+			"status":                  req.Outcome,
+			"retester_id":             userID,
+			"retested_by_id":          userID,
+			"retested_at":             time.Now(),
+			"outcome_notes":           req.Notes,
+			"evidence":                req.Evidence,
+			"evidence_attachment_ids": pq.StringArray(attachmentIDs),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to record retest outcome: %w", err)
+		}
+
+		if retest.RequestedByID == userID {
+			return nil
+		}
+		return s.notificationService.CreateNotifications(tx, []models.Notification{
+			s.notification(retest, retest.RequestedByID, models.NotificationTypeRetestCompleted,
+				fmt.Sprintf("Retest completed: %s", retestOutcomeLabel(req.Outcome)), req.Notes, &userID),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("retest_id", id.String()).
+		Str("outcome", string(req.Outcome)).
+		Str("retested_by", userID.String()).
+		Msg("Retest completed")
+
+	return s.getRetest(s.db, assessmentID, id)
+}
+
+// getPendingRetest locks a retest that is still awaiting its outcome
+func (s *AssessmentRetestService) getPendingRetest(tx *gorm.DB, assessmentID, id uuid.UUID) (*models.AssessmentRetest, error) {
+	var retest models.AssessmentRetest
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("assessment_id IN (?)", visibleAssessmentIDs(tx)).
+		First(&retest, "id = ? AND assessment_id = ?", id, assessmentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("retest not found")
+		}
+		return nil, fmt.Errorf("failed to get retest: %w", err)
+	}
+	if retest.Status != models.RetestReady {
+		return nil, fmt.Errorf("retest is not pending (status: %s)", retest.Status)
+	}
+	return &retest, nil
+}
+
+// notification builds a retest notification
+func (s *AssessmentRetestService) notification(retest *models.AssessmentRetest, userID uuid.UUID, notificationType models.NotificationType, title, message string, actorID *uuid.UUID) models.Notification {
+	resourceID := retest.ID
+	return models.Notification{
+		UserID:       userID,
+		Type:         notificationType,
+		Title:        title,
+		Message:      message,
+		ResourceType: "assessment_retest",
+		ResourceID:   &resourceID,
+		ActorID:      actorID,
+	}
+}
+
+// requireRetester checks that the user assigned as retester exists
+func requireRetester(tx *gorm.DB, userID uuid.UUID) error {
+	var count int64
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to get retester: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("retester not found")
+	}
+	return nil
+}
+
+// retestOutcomeLabel renders a retest outcome for people
+func retestOutcomeLabel(status models.RetestStatus) string {
+	switch status {
+	case models.RetestReady:
+		return "Ready for retest"
+	case models.RetestFixed:
+		return "Fixed"
+	case models.RetestNotFixed:
+		return "Not fixed"
+	case models.RetestPartiallyFixed:
+		return "Partially fixed"
+	}
+	return "Not requested"
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -20,6 +21,11 @@ func NewAssessmentService(db *gorm.DB) *AssessmentService {
 	return &AssessmentService{db: db}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *AssessmentService) WithContext(ctx context.Context) *AssessmentService {
+	return &AssessmentService{db: s.db.WithContext(ctx)}
+}
+
 // CreateAssessmentRequest represents a request to create an assessment
 type CreateAssessmentRequest struct {
 	Name                 string
@@ -31,6 +37,7 @@ type CreateAssessmentRequest struct {
 	EndDate              *time.Time
 	VulnerabilityIDs     []uuid.UUID
 	AssetIDs             []uuid.UUID
+	AssetGroupIDs        []uuid.UUID
 }
 
 // UpdateAssessmentRequest represents a request to update an assessment
@@ -47,6 +54,7 @@ type UpdateAssessmentRequest struct {
 	FindingsSummary      *string
 	Recommendations      *string
 	Score                *int
+	IfMatch              string // Expected version (ETag); empty updates unconditionally
 }
 
 // CreateAssessment creates a new assessment
@@ -105,13 +113,26 @@ func (s *AssessmentService) CreateAssessment(req CreateAssessmentRequest, create
 		}
 	}
 
+	// Link asset groups if provided
+	for _, groupID := range req.AssetGroupIDs {
+		link := &models.AssessmentAssetGroup{
+			AssessmentID: assessment.ID.String(),
+			AssetGroupID: groupID.String(),
+		}
+		if err := tx.Create(link).Error; err != nil {
+			tx.Rollback()
+			utils.Logger.Error().Err(err).Str("asset_group_id", groupID.String()).Msg("Failed to link asset group")
+			return nil, fmt.Errorf("failed to link asset group: %w", err)
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to commit assessment transaction")
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	// Load relationships
-	if err := s.db.Preload("CreatedBy").Preload("Vulnerabilities").Preload("Assets").First(assessment, assessment.ID).Error; err != nil {
+	if err := s.db.Preload("CreatedBy").Preload("Vulnerabilities").Preload("Assets").Preload("AssetGroups").First(assessment, assessment.ID).Error; err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to reload assessment with relationships")
 		return nil, fmt.Errorf("failed to load assessment: %w", err)
 	}
@@ -125,6 +146,7 @@ func (s *AssessmentService) GetAssessment(id uuid.UUID) (*models.Assessment, err
 	if err := s.db.Preload("CreatedBy").
 		Preload("Vulnerabilities").
 		Preload("Assets").
+		Preload("AssetGroups").
 		First(&assessment, id).Error; err != nil {
 		return nil, err
 	}
@@ -172,6 +194,11 @@ func (s *AssessmentService) UpdateAssessment(id uuid.UUID, req UpdateAssessmentR
 		return nil, err
 	}
 
+	if err := checkIfMatch(req.IfMatch, assessment.UpdatedAt); err != nil {
+		return nil, err
+	}
+	version := assessment.UpdatedAt
+
 	// Update fields if provided
 	if req.Name != nil {
 		assessment.Name = *req.Name
@@ -210,14 +237,26 @@ func (s *AssessmentService) UpdateAssessment(id uuid.UUID, req UpdateAssessmentR
 		assessment.Score = req.Score
 	}
 
-	if err := s.db.Save(&assessment).Error; err != nil {
-		return nil, err
+	if req.IfMatch == "" {
+		if err := s.db.Save(&assessment).Error; err != nil {
+			return nil, err
+		}
+	} else {
+		// Save would insert when no row matched, so conditional updates write all fields via Updates
+		result := s.db.Model(&assessment).Where("updated_at = ?", version).Select("*").Omit("id", "created_at").Updates(&assessment)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, ErrPreconditionFailed
+		}
 	}
 
 	// Reload with relationships
 	if err := s.db.Preload("CreatedBy").
 		Preload("Vulnerabilities").
 		Preload("Assets").
+		Preload("AssetGroups").
 		First(&assessment, id).Error; err != nil {
 		return nil, err
 	}
@@ -262,6 +301,46 @@ func (s *AssessmentService) UnlinkAsset(assessmentID, assetID uuid.UUID) error {
 		Delete(&models.AssessmentAsset{}).Error
 }
 
+// LinkAssetGroup adds an asset group to an assessment's scope
+func (s *AssessmentService) LinkAssetGroup(assessmentID, groupID uuid.UUID) error {
+	if err := NewAssetGroupService(s.db).Exists(groupID); err != nil {
+		return err
+	}
+	link := &models.AssessmentAssetGroup{
+		AssessmentID: assessmentID.String(),
+		AssetGroupID: groupID.String(),
+	}
+	return s.db.Create(link).Error
+}
+
+// UnlinkAssetGroup removes an asset group from an assessment's scope
+func (s *AssessmentService) UnlinkAssetGroup(assessmentID, groupID uuid.UUID) error {
+	return s.db.Where("assessment_id = ? AND asset_group_id = ?", assessmentID.String(), groupID.String()).
+		Delete(&models.AssessmentAssetGroup{}).Error
+}
+
+// GetScope returns the assets in an assessment's scope: the directly linked assets plus the
+// current members of its linked asset groups
+func (s *AssessmentService) GetScope(assessmentID uuid.UUID) ([]models.AffectedSystem, error) {
+	var assessment models.Assessment
+	if err := s.db.Select("id").First(&assessment, assessmentID).Error; err != nil {
+		return nil, err
+	}
+
+	direct := s.db.Model(&models.AssessmentAsset{}).Select("asset_id").Where("assessment_id = ?", assessmentID.String())
+	grouped := s.db.Model(&models.AssetGroupMember{}).Select("asset_id").
+		Where("group_id IN (?)", s.db.Model(&models.AssessmentAssetGroup{}).Select("asset_group_id").Where("assessment_id = ?", assessmentID.String()))
+
+	var assets []models.AffectedSystem
+	if err := s.db.Preload("Tags").
+		Where("id IN (?) OR id IN (?)", direct, grouped).
+		Order("hostname ASC").
+		Find(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to load assessment scope: %w", err)
+	}
+	return assets, nil
+}
+
 // GetAssessmentStats returns statistics about assessments
 func (s *AssessmentService) GetAssessmentStats() (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
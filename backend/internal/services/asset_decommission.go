@@ -0,0 +1,348 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Dispositions of the open findings of a decommissioned asset
+const (
+	DecommissionClose    = "close"    // Closed as fixed with the decommission reason
+	DecommissionReassign = "reassign" // Moved to the asset replacing the decommissioned one
+)
+
+// DefaultDecommissionGraceDays is how long a decommissioned asset stays before it moves to
+// the recycle bin, unless the decommission sets another grace period
+const DefaultDecommissionGraceDays = 30
+
+// maxDecommissionGraceDays bounds the grace period of a decommission
+const maxDecommissionGraceDays = 365
+
+// AssetDecommissionRequest retires an asset and decides what happens to its open findings
+type AssetDecommissionRequest struct {
+	Reason             string     `json:"reason"`
+	Disposition        string     `json:"disposition"`                    // close (default) or reassign
+	ReplacementAssetID *uuid.UUID `json:"replacement_asset_id,omitempty"` // Required to reassign
+	GraceDays          *int       `json:"grace_days,omitempty"`           // Days before cleanup; DefaultDecommissionGraceDays when unset
+}
+
+// DecommissionVulnerability is a vulnerability with open findings on an asset being decommissioned
+type DecommissionVulnerability struct {
+	ID           uuid.UUID                    `json:"id"`
+	Title        string                       `json:"title"`
+	Severity     models.VulnerabilitySeverity `json:"severity"`
+	AssignedToID *uuid.UUID                   `json:"assigned_to_id,omitempty"`
+	OpenFindings int                          `json:"open_findings"`
+}
+
+// AssetDecommissionPreview shows what decommissioning an asset affects, to guide the choice
+// of disposition
+type AssetDecommissionPreview struct {
+	AssetID          uuid.UUID                   `json:"asset_id"`
+	OpenFindings     int                         `json:"open_findings"`
+	Vulnerabilities  []DecommissionVulnerability `json:"vulnerabilities"`
+	AssigneesToAlert int                         `json:"assignees_to_alert"`
+	DefaultGraceDays int                         `json:"default_grace_days"`
+}
+
+// AssetDecommissionResult is the outcome of a decommission
+type AssetDecommissionResult struct {
+	Asset              *models.AffectedSystem `json:"asset"`
+	Disposition        string                 `json:"disposition"`
+	ClosedFindings     int                    `json:"closed_findings"`
+	ReassignedFindings int                    `json:"reassigned_findings"`
+	NotifiedAssignees  int                    `json:"notified_assignees"`
+	CleanupAt          time.Time              `json:"cleanup_at"`
+}
+
+// decommissionVulnerabilities loads the vulnerabilities with open findings on an asset
+func decommissionVulnerabilities(db *gorm.DB, assetID uuid.UUID) ([]DecommissionVulnerability, error) {
+	vulnerabilities := []DecommissionVulnerability{}
+	if err := db.Model(&models.VulnerabilityFinding{}).
+		Select("vulnerabilities.id, vulnerabilities.title, vulnerabilities.severity, vulnerabilities.assigned_to_id, COUNT(*) AS open_findings").
+		Joins("JOIN vulnerabilities ON vulnerabilities.id = vulnerability_findings.vulnerability_id AND vulnerabilities.deleted_at IS NULL").
+		Where("vulnerability_findings.affected_system_id = ? AND vulnerability_findings.status = ?", assetID, models.FindingStatusOpen).
+		Group("vulnerabilities.id, vulnerabilities.title, vulnerabilities.severity, vulnerabilities.assigned_to_id").
+		Order("COUNT(*) DESC").
+		Scan(&vulnerabilities).Error; err != nil {
+		return nil, fmt.Errorf("failed to load open findings: %w", err)
+	}
+	return vulnerabilities, nil
+}
+
+// decommissionAssignees returns the users assigned to the vulnerabilities, except the actor
+func decommissionAssignees(vulnerabilities []DecommissionVulnerability, actorID uuid.UUID) map[uuid.UUID][]DecommissionVulnerability {
+	assignees := make(map[uuid.UUID][]DecommissionVulnerability)
+	for _, vulnerability := range vulnerabilities {
+		if vulnerability.AssignedToID != nil && *vulnerability.AssignedToID != actorID {
+			assignees[*vulnerability.AssignedToID] = append(assignees[*vulnerability.AssignedToID], vulnerability)
+		}
+	}
+	return assignees
+}
+
+// PreviewDecommission returns the open findings decommissioning an asset would dispose of and
+// the assignees it would notify
+func (s *AssetService) PreviewDecommission(id uuid.UUID, userID uuid.UUID) (*AssetDecommissionPreview, error) {
+	var asset models.AffectedSystem
+	if err := s.db.First(&asset, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("asset not found")
+	}
+	if err := s.authorize("read", asset); err != nil {
+		return nil, err
+	}
+
+	vulnerabilities, err := decommissionVulnerabilities(s.db, asset.ID)
+	if err != nil {
+		return nil, err
+	}
+	preview := &AssetDecommissionPreview{
+		AssetID:          asset.ID,
+		Vulnerabilities:  vulnerabilities,
+		AssigneesToAlert: len(decommissionAssignees(vulnerabilities, userID)),
+		DefaultGraceDays: DefaultDecommissionGraceDays,
+	}
+	for _, vulnerability := range vulnerabilities {
+		preview.OpenFindings += vulnerability.OpenFindings
+	}
+	return preview, nil
+}
+
+// DecommissionAsset retires an asset: its open findings are closed with the reason or moved to
+// a replacement asset, the assignees of their vulnerabilities are notified, and the asset is
+// scheduled to move to the recycle bin after the grace period
+func (s *AssetService) DecommissionAsset(id uuid.UUID, req AssetDecommissionRequest, userID uuid.UUID) (*AssetDecommissionResult, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	disposition := req.Disposition
+	if disposition == "" {
+		disposition = DecommissionClose
+	}
+	if disposition != DecommissionClose && disposition != DecommissionReassign {
+		return nil, fmt.Errorf("invalid disposition, must be one of: close, reassign")
+	}
+	graceDays := DefaultDecommissionGraceDays
+	if req.GraceDays != nil {
+		graceDays = *req.GraceDays
+	}
+	if graceDays < 0 || graceDays > maxDecommissionGraceDays {
+		return nil, fmt.Errorf("invalid grace_days, must be between 0 and %d", maxDecommissionGraceDays)
+	}
+
+	var asset models.AffectedSystem
+	if err := s.db.First(&asset, "id = ?", id).Error; err != nil {
+		return nil, fmt.Errorf("asset not found")
+	}
+	if err := s.authorize("write", asset); err != nil {
+		return nil, err
+	}
+	if err := s.validateStatusTransition(asset.Status, models.StatusDecommissioned); err != nil {
+		return nil, fmt.Errorf("invalid status transition: %w", err)
+	}
+
+	var replacement models.AffectedSystem
+	if disposition == DecommissionReassign {
+		if req.ReplacementAssetID == nil {
+			return nil, fmt.Errorf("replacement_asset_id is required to reassign findings")
+		}
+		if *req.ReplacementAssetID == asset.ID {
+			return nil, fmt.Errorf("invalid replacement_asset_id: the asset cannot replace itself")
+		}
+		if err := s.db.First(&replacement, "id = ?", *req.ReplacementAssetID).Error; err != nil {
+			return nil, fmt.Errorf("replacement asset not found")
+		}
+		if replacement.Status == models.StatusDecommissioned {
+			return nil, fmt.Errorf("invalid replacement_asset_id: the replacement asset is decommissioned")
+		}
+		if err := s.authorize("write", replacement); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	cleanupAt := now.AddDate(0, 0, graceDays)
+	result := &AssetDecommissionResult{Disposition: disposition, CleanupAt: cleanupAt}
+	label := assetLabel(asset.Hostname, asset.IPAddress, asset.AssetID)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		vulnerabilities, err := decommissionVulnerabilities(tx, asset.ID)
+		if err != nil {
+			return err
+		}
+
+		// Claim the asset so that concurrent decommissions dispose of its findings once
+		claim := tx.Model(&models.AffectedSystem{}).
+			Where("id = ? AND status = ?", asset.ID, asset.Status).
+			Updates(map[string]interface{}{
+				"status":               models.StatusDecommissioned,
+				"decommissioned_by_id": userID,
+				"cleanup_at":           cleanupAt,
+			})
+		if claim.Error != nil {
+			return fmt.Errorf("failed to decommission asset: %w", claim.Error)
+		}
+		if claim.RowsAffected == 0 {
+			return fmt.Errorf("asset is already decommissioned")
+		}
+
+		var findingIDs []uuid.UUID
+		if err := tx.Model(&models.VulnerabilityFinding{}).
+			Where("affected_system_id = ? AND status = ?", asset.ID, models.FindingStatusOpen).
+			Pluck("id", &findingIDs).Error; err != nil {
+			return fmt.Errorf("failed to load open findings: %w", err)
+		}
+
+		disposed := "closed"
+		if disposition == DecommissionClose {
+			notes := fmt.Sprintf("Asset decommissioned: %s", reason)
+			if len(findingIDs) > 0 {
+				if err := tx.Model(&models.VulnerabilityFinding{}).
+					Where("id IN ?", findingIDs).
+					Updates(map[string]interface{}{
+						"status":    models.FindingStatusFixed,
+						"fixed_at":  now,
+						"fixed_by":  userID,
+						"fix_notes": notes,
+					}).Error; err != nil {
+					return fmt.Errorf("failed to close findings: %w", err)
+				}
+				histories := make([]*models.FindingStatusHistory, len(findingIDs))
+				for i, findingID := range findingIDs {
+					histories[i] = &models.FindingStatusHistory{
+						FindingID:   findingID,
+						OldStatus:   models.FindingStatusOpen,
+						NewStatus:   models.FindingStatusFixed,
+						Notes:       notes,
+						ChangedByID: userID,
+						ChangedAt:   now,
+					}
+				}
+				if err := tx.CreateInBatches(histories, importBatchSize).Error; err != nil {
+					return fmt.Errorf("failed to record closed findings: %w", err)
+				}
+			}
+			result.ClosedFindings = len(findingIDs)
+		} else {
+			if len(findingIDs) > 0 {
+				if err := tx.Model(&models.VulnerabilityFinding{}).
+					Where("id IN ?", findingIDs).
+					Update("affected_system_id", replacement.ID).Error; err != nil {
+					return fmt.Errorf("failed to reassign findings: %w", err)
+				}
+				links := make([]models.VulnerabilityAffectedSystem, len(vulnerabilities))
+				for i, vulnerability := range vulnerabilities {
+					links[i] = models.VulnerabilityAffectedSystem{
+						VulnerabilityID:  vulnerability.ID.String(),
+						AffectedSystemID: replacement.ID.String(),
+					}
+				}
+				if len(links) > 0 {
+					if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error; err != nil {
+						return fmt.Errorf("failed to link the replacement asset: %w", err)
+					}
+				}
+			}
+			result.ReassignedFindings = len(findingIDs)
+			disposed = "moved to " + assetLabel(replacement.Hostname, replacement.IPAddress, replacement.AssetID)
+		}
+
+		if err := recordAssetHistory(tx, userID, models.AssetHistory{
+			AssetID:  asset.ID,
+			Action:   models.AssetHistoryStatusChanged,
+			Field:    "status",
+			OldValue: string(asset.Status),
+			NewValue: string(models.StatusDecommissioned),
+			Notes:    fmt.Sprintf("%s (%d open findings %s)", reason, len(findingIDs), disposed),
+		}); err != nil {
+			return err
+		}
+
+		// Tell the assignees of the affected vulnerabilities what happened to their findings
+		assignees := decommissionAssignees(vulnerabilities, userID)
+		var notifications []models.Notification
+		for assigneeID, assigned := range assignees {
+			for _, vulnerability := range assigned {
+				vulnerabilityID := vulnerability.ID
+				actorID := userID
+				notifications = append(notifications, models.Notification{
+					UserID:       assigneeID,
+					Type:         models.NotificationTypeAssetDecommissioned,
+					Title:        fmt.Sprintf("Asset %s decommissioned", label),
+					Message:      fmt.Sprintf("%s: %d open findings were %s. Reason: %s", vulnerability.Title, vulnerability.OpenFindings, disposed, reason),
+					ResourceType: "vulnerability",
+					ResourceID:   &vulnerabilityID,
+					ActorID:      &actorID,
+				})
+			}
+		}
+		if err := NewNotificationService(tx).CreateNotifications(tx, notifications); err != nil {
+			return err
+		}
+		result.NotifiedAssignees = len(assignees)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("asset_id", asset.ID.String()).
+		Str("disposition", disposition).
+		Int("closed_findings", result.ClosedFindings).
+		Int("reassigned_findings", result.ReassignedFindings).
+		Time("cleanup_at", cleanupAt).
+		Str("decommissioned_by", userID.String()).
+		Msg("Asset decommissioned")
+
+	if err := s.db.Preload("Owner").Preload("OwnerTeam").Preload("Tags").First(&asset, "id = ?", asset.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload asset: %w", err)
+	}
+	result.Asset = &asset
+	return result, nil
+}
+
+// CleanupDecommissionedAssets moves decommissioned assets whose grace period has ended to the
+// recycle bin, as deleted by whoever decommissioned them
+func (s *AssetService) CleanupDecommissionedAssets(now time.Time) (int, error) {
+	var assets []models.AffectedSystem
+	if err := s.db.Select("id", "status", "decommissioned_by_id").
+		Where("status = ? AND cleanup_at <= ?", models.StatusDecommissioned, now).
+		Find(&assets).Error; err != nil {
+		return 0, fmt.Errorf("failed to find decommissioned assets: %w", err)
+	}
+
+	cleaned := 0
+	for _, asset := range assets {
+		deletedByID := uuid.Nil
+		if asset.DecommissionedByID != nil {
+			deletedByID = *asset.DecommissionedByID
+		}
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&asset).UpdateColumn("deleted_by_id", asset.DecommissionedByID).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&asset).Error; err != nil {
+				return err
+			}
+			return recordAssetHistory(tx, deletedByID, models.AssetHistory{
+				AssetID:  asset.ID,
+				Action:   models.AssetHistoryDeleted,
+				OldValue: string(asset.Status),
+				Notes:    "Decommission grace period ended",
+			})
+		})
+		if err != nil {
+			return cleaned, fmt.Errorf("failed to clean up asset %s: %w", asset.ID, err)
+		}
+		cleaned++
+	}
+	return cleaned, nil
+}
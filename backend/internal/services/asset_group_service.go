@@ -0,0 +1,620 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AssetGroupService manages asset groups, their static members and rule-based membership
+type AssetGroupService struct {
+	db *gorm.DB
+}
+
+// NewAssetGroupService creates a new asset group service
+func NewAssetGroupService(db *gorm.DB) *AssetGroupService {
+	return &AssetGroupService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *AssetGroupService) WithContext(ctx context.Context) *AssetGroupService {
+	return &AssetGroupService{db: s.db.WithContext(ctx)}
+}
+
+// AssetGroupRequest is the payload for creating or updating an asset group.
+// Nil fields are left unchanged on update; an empty list clears a rule.
+type AssetGroupRequest struct {
+	Name             *string     `json:"name"`
+	Description      *string     `json:"description"`
+	RuleTags         *[]string   `json:"rule_tags"`
+	RuleEnvironments *[]string   `json:"rule_environments"`
+	RuleSystemTypes  *[]string   `json:"rule_system_types"`
+	RuleCIDRs        *[]string   `json:"rule_cidrs"`
+	AssetIDs         []uuid.UUID `json:"asset_ids"` // Static members, create only
+}
+
+// applyTo copies the provided request fields onto a group, normalizing rule values
+func (req AssetGroupRequest) applyTo(group *models.AssetGroup) {
+	if req.Name != nil {
+		group.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		group.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.RuleTags != nil {
+		group.RuleTags = normalizeRuleValues(*req.RuleTags, strings.ToLower)
+	}
+	if req.RuleEnvironments != nil {
+		group.RuleEnvironments = normalizeRuleValues(*req.RuleEnvironments, strings.ToUpper)
+	}
+	if req.RuleSystemTypes != nil {
+		group.RuleSystemTypes = normalizeRuleValues(*req.RuleSystemTypes, strings.ToUpper)
+	}
+	if req.RuleCIDRs != nil {
+		group.RuleCIDRs = normalizeRuleValues(*req.RuleCIDRs, nil)
+	}
+}
+
+// normalizeRuleValues trims, optionally re-cases and de-duplicates rule values
+func normalizeRuleValues(values []string, transform func(string) string) pq.StringArray {
+	seen := make(map[string]bool, len(values))
+	out := pq.StringArray{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if transform != nil {
+			value = transform(value)
+		}
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		out = append(out, value)
+	}
+	return out
+}
+
+// ValidateAssetGroupRules rejects unknown environments and system types and malformed CIDRs
+func ValidateAssetGroupRules(group *models.AssetGroup) error {
+	for _, env := range group.RuleEnvironments {
+		switch models.Environment(env) {
+		case models.EnvProduction, models.EnvStaging, models.EnvDevelopment, models.EnvTest:
+		default:
+			return fmt.Errorf("invalid environment '%s'", env)
+		}
+	}
+	for _, systemType := range group.RuleSystemTypes {
+		switch models.SystemType(systemType) {
+		case models.SystemTypeServer, models.SystemTypeWorkstation, models.SystemTypeNetworkDevice,
+			models.SystemTypeApplication, models.SystemTypeContainer, models.SystemTypeCloudService, models.SystemTypeOther:
+		default:
+			return fmt.Errorf("invalid system type '%s'", systemType)
+		}
+	}
+	for _, cidr := range group.RuleCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid CIDR '%s'", cidr)
+		}
+	}
+	return nil
+}
+
+// AssetGroupMatcher evaluates assets against the dynamic rules of a group
+type AssetGroupMatcher struct {
+	tags         map[string]bool
+	environments map[string]bool
+	systemTypes  map[string]bool
+	networks     []*net.IPNet
+}
+
+// NewAssetGroupMatcher compiles a group's rules; invalid CIDRs are skipped
+func NewAssetGroupMatcher(group *models.AssetGroup) *AssetGroupMatcher {
+	toSet := func(values []string) map[string]bool {
+		set := make(map[string]bool, len(values))
+		for _, value := range values {
+			set[value] = true
+		}
+		return set
+	}
+
+	matcher := &AssetGroupMatcher{
+		tags:         toSet(group.RuleTags),
+		environments: toSet(group.RuleEnvironments),
+		systemTypes:  toSet(group.RuleSystemTypes),
+	}
+	for _, cidr := range group.RuleCIDRs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil {
+			matcher.networks = append(matcher.networks, network)
+		}
+	}
+	return matcher
+}
+
+// Matches reports whether the asset satisfies every populated rule. An asset matches a
+// rule when any of its values is listed; the asset's tags must be loaded.
+func (m *AssetGroupMatcher) Matches(asset *models.AffectedSystem) bool {
+	criteria := 0
+
+	if len(m.tags) > 0 {
+		criteria++
+		tagged := false
+		for _, tag := range asset.Tags {
+			if m.tags[tag.Tag] {
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			return false
+		}
+	}
+	if len(m.environments) > 0 {
+		criteria++
+		if !m.environments[string(asset.Environment)] {
+			return false
+		}
+	}
+	if len(m.systemTypes) > 0 {
+		criteria++
+		if !m.systemTypes[string(asset.SystemType)] {
+			return false
+		}
+	}
+	if len(m.networks) > 0 {
+		criteria++
+		ip := net.ParseIP(asset.IPAddress)
+		if ip == nil {
+			return false
+		}
+		contained := false
+		for _, network := range m.networks {
+			if network.Contains(ip) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return false
+		}
+	}
+
+	// A group without rules has no dynamic members
+	return criteria > 0
+}
+
+// List returns the asset groups, optionally filtered by a name search, with their member counts
+func (s *AssetGroupService) List(search string) ([]models.AssetGroup, error) {
+	query := s.db.Model(&models.AssetGroup{})
+	if search = strings.TrimSpace(search); search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+
+	var groups []models.AssetGroup
+	if err := query.Order("name ASC").Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to list asset groups: %w", err)
+	}
+	if len(groups) == 0 {
+		return groups, nil
+	}
+
+	ids := make([]uuid.UUID, len(groups))
+	for i := range groups {
+		ids[i] = groups[i].ID
+	}
+	var counts []struct {
+		GroupID uuid.UUID
+		Count   int64
+	}
+	if err := s.db.Model(&models.AssetGroupMember{}).
+		Select("group_id, COUNT(DISTINCT asset_id) AS count").
+		Where("group_id IN ?", ids).
+		Group("group_id").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count asset group members: %w", err)
+	}
+	countMap := make(map[uuid.UUID]int64, len(counts))
+	for _, c := range counts {
+		countMap[c.GroupID] = c.Count
+	}
+	for i := range groups {
+		groups[i].MemberCount = countMap[groups[i].ID]
+	}
+	return groups, nil
+}
+
+// GetByID retrieves an asset group with its member count
+func (s *AssetGroupService) GetByID(id uuid.UUID) (*models.AssetGroup, error) {
+	var group models.AssetGroup
+	if err := s.db.Preload("CreatedBy").Where("id = ?", id).First(&group).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("asset group not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if err := s.db.Model(&models.AssetGroupMember{}).
+		Where("group_id = ?", id).
+		Distinct("asset_id").
+		Count(&group.MemberCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count asset group members: %w", err)
+	}
+	return &group, nil
+}
+
+// Exists reports whether an asset group is visible to the caller; used to validate group filters
+func (s *AssetGroupService) Exists(id uuid.UUID) error {
+	var count int64
+	if err := s.db.Model(&models.AssetGroup{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("asset group not found")
+	}
+	return nil
+}
+
+// Create creates an asset group with its static members and computes its dynamic members
+func (s *AssetGroupService) Create(req AssetGroupRequest, createdByID uuid.UUID) (*models.AssetGroup, error) {
+	group := &models.AssetGroup{CreatedByID: createdByID}
+	req.applyTo(group)
+	if group.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if err := ValidateAssetGroupRules(group); err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(group.Name, uuid.Nil); err != nil {
+		return nil, err
+	}
+	if err := s.checkAssetsExist(req.AssetIDs); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(group).Error; err != nil {
+			return fmt.Errorf("failed to create asset group: %w", err)
+		}
+		if err := addAssetGroupMembers(tx, group.ID, req.AssetIDs, models.AssetGroupMemberStatic); err != nil {
+			return err
+		}
+		return s.recompute(tx, group)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("asset_group_id", group.ID.String()).
+		Str("name", group.Name).
+		Int("static_members", len(req.AssetIDs)).
+		Msg("Asset group created")
+
+	return s.GetByID(group.ID)
+}
+
+// Update updates an asset group; changed rules are applied to the membership immediately
+func (s *AssetGroupService) Update(id uuid.UUID, req AssetGroupRequest) (*models.AssetGroup, error) {
+	group, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.applyTo(group)
+	if group.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if err := ValidateAssetGroupRules(group); err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		if err := s.checkNameAvailable(group.Name, id); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"name":              group.Name,
+			"description":       group.Description,
+			"rule_tags":         group.RuleTags,
+			"rule_environments": group.RuleEnvironments,
+			"rule_system_types": group.RuleSystemTypes,
+			"rule_cidrs":        group.RuleCIDRs,
+		}
+		if err := tx.Model(&models.AssetGroup{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update asset group: %w", err)
+		}
+		return s.recompute(tx, group)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(id)
+}
+
+// Delete soft-deletes an asset group and removes it from assessment scopes
+func (s *AssetGroupService) Delete(id uuid.UUID) error {
+	group, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("asset_group_id = ?", id.String()).Delete(&models.AssessmentAssetGroup{}).Error; err != nil {
+			return fmt.Errorf("failed to unlink asset group from assessments: %w", err)
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&models.AssetGroupMember{}).Error; err != nil {
+			return fmt.Errorf("failed to remove asset group members: %w", err)
+		}
+		if err := tx.Delete(group).Error; err != nil {
+			return fmt.Errorf("failed to delete asset group: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListMembers returns a page of the assets in a group
+func (s *AssetGroupService) ListMembers(groupID uuid.UUID, page, limit int) ([]models.AffectedSystem, int64, error) {
+	if err := s.Exists(groupID); err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	query := s.db.Model(&models.AffectedSystem{}).Where("id IN (?)", AssetGroupMemberIDs(s.db, groupID))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count asset group members: %w", err)
+	}
+
+	var assets []models.AffectedSystem
+	if err := query.Preload("Tags").
+		Order("hostname ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&assets).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list asset group members: %w", err)
+	}
+	return assets, total, nil
+}
+
+// AddAssets adds static members to a group
+func (s *AssetGroupService) AddAssets(groupID uuid.UUID, assetIDs []uuid.UUID) (*models.AssetGroup, error) {
+	if len(assetIDs) == 0 {
+		return nil, fmt.Errorf("asset_ids is required")
+	}
+	if err := s.Exists(groupID); err != nil {
+		return nil, err
+	}
+	if err := s.checkAssetsExist(assetIDs); err != nil {
+		return nil, err
+	}
+	if err := addAssetGroupMembers(s.db, groupID, assetIDs, models.AssetGroupMemberStatic); err != nil {
+		return nil, err
+	}
+	return s.GetByID(groupID)
+}
+
+// RemoveAsset removes a static member from a group. Assets matched by the group's rules
+// stay members until the rules or the asset change.
+func (s *AssetGroupService) RemoveAsset(groupID, assetID uuid.UUID) (*models.AssetGroup, error) {
+	if err := s.Exists(groupID); err != nil {
+		return nil, err
+	}
+
+	result := s.db.Where("group_id = ? AND asset_id = ? AND source = ?", groupID, assetID, models.AssetGroupMemberStatic).
+		Delete(&models.AssetGroupMember{})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to remove asset group member: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("asset is not a static member of this group")
+	}
+	return s.GetByID(groupID)
+}
+
+// Recompute re-evaluates the dynamic members of a group
+func (s *AssetGroupService) Recompute(groupID uuid.UUID) (*models.AssetGroup, error) {
+	group, err := s.GetByID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return s.recompute(tx, group)
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetByID(groupID)
+}
+
+// RecomputeStale re-evaluates the dynamic members of every rule-based group whose
+// organization had asset changes since the last run, and of groups never computed.
+// It runs without a tenant context and returns the number of groups recomputed.
+func (s *AssetGroupService) RecomputeStale() (int, error) {
+	all, orgIDs := assetGroupChanges.take()
+
+	query := s.db.Model(&models.AssetGroup{}).
+		Where("cardinality(rule_tags) > 0 OR cardinality(rule_environments) > 0 OR cardinality(rule_system_types) > 0 OR cardinality(rule_cidrs) > 0")
+	if !all {
+		if len(orgIDs) > 0 {
+			query = query.Where("membership_computed_at IS NULL OR org_id IN ?", orgIDs)
+		} else {
+			query = query.Where("membership_computed_at IS NULL")
+		}
+	}
+
+	var groups []models.AssetGroup
+	if err := query.Find(&groups).Error; err != nil {
+		assetGroupChanges.restore(all, orgIDs)
+		return 0, fmt.Errorf("failed to list asset groups: %w", err)
+	}
+
+	recomputed := 0
+	for i := range groups {
+		if err := s.db.Transaction(func(tx *gorm.DB) error {
+			return s.recompute(tx, &groups[i])
+		}); err != nil {
+			// Retry the organization on the next run
+			assetGroupChanges.restore(false, orgIDsOf(groups[i].OrgID))
+			utils.Logger.Error().Err(err).Str("asset_group_id", groups[i].ID.String()).Msg("Failed to recompute asset group membership")
+			continue
+		}
+		recomputed++
+	}
+	return recomputed, nil
+}
+
+// recompute replaces the dynamic members of a group with the assets of its organization
+// that currently match its rules
+func (s *AssetGroupService) recompute(tx *gorm.DB, group *models.AssetGroup) error {
+	var matched []uuid.UUID
+	if group.HasRules() {
+		// Narrow by the rules that map to columns, then evaluate the rest in memory
+		query := tx.Model(&models.AffectedSystem{}).Preload("Tags")
+		if group.OrgID != nil {
+			query = query.Where("org_id = ?", *group.OrgID)
+		}
+		if len(group.RuleEnvironments) > 0 {
+			query = query.Where("environment IN ?", []string(group.RuleEnvironments))
+		}
+		if len(group.RuleSystemTypes) > 0 {
+			query = query.Where("system_type IN ?", []string(group.RuleSystemTypes))
+		}
+		if len(group.RuleTags) > 0 {
+			query = query.Where("id IN (?)", tx.Model(&models.AssetTag{}).Select("asset_id").Where("tag IN ?", []string(group.RuleTags)))
+		}
+
+		matcher := NewAssetGroupMatcher(group)
+		var batch []models.AffectedSystem
+		err := query.FindInBatches(&batch, 500, func(_ *gorm.DB, _ int) error {
+			for i := range batch {
+				if matcher.Matches(&batch[i]) {
+					matched = append(matched, batch[i].ID)
+				}
+			}
+			return nil
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to evaluate asset group rules: %w", err)
+		}
+	}
+
+	if err := tx.Where("group_id = ? AND source = ?", group.ID, models.AssetGroupMemberDynamic).
+		Delete(&models.AssetGroupMember{}).Error; err != nil {
+		return fmt.Errorf("failed to clear dynamic members: %w", err)
+	}
+	if err := addAssetGroupMembers(tx, group.ID, matched, models.AssetGroupMemberDynamic); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := tx.Model(&models.AssetGroup{}).Where("id = ?", group.ID).Update("membership_computed_at", now).Error; err != nil {
+		return fmt.Errorf("failed to update asset group: %w", err)
+	}
+	group.MembershipComputedAt = &now
+	return nil
+}
+
+// AssetGroupMemberIDs returns a subquery selecting the asset IDs of a group, for use in
+// "IN (?)" filters
+func AssetGroupMemberIDs(db *gorm.DB, groupID uuid.UUID) *gorm.DB {
+	return db.Model(&models.AssetGroupMember{}).
+		Select("DISTINCT asset_id").
+		Where("group_id = ?", groupID)
+}
+
+// checkNameAvailable rejects a group name already used by another group in the organization
+func (s *AssetGroupService) checkNameAvailable(name string, exceptID uuid.UUID) error {
+	var count int64
+	if err := s.db.Model(&models.AssetGroup{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("asset group '%s' already exists", name)
+	}
+	return nil
+}
+
+// checkAssetsExist verifies that every asset ID refers to an asset visible to the caller
+func (s *AssetGroupService) checkAssetsExist(assetIDs []uuid.UUID) error {
+	unique := make(map[uuid.UUID]bool, len(assetIDs))
+	for _, id := range assetIDs {
+		unique[id] = true
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(unique))
+	for id := range unique {
+		ids = append(ids, id)
+	}
+	var count int64
+	if err := s.db.Model(&models.AffectedSystem{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if int(count) != len(ids) {
+		return fmt.Errorf("asset not found")
+	}
+	return nil
+}
+
+// addAssetGroupMembers inserts memberships, ignoring assets that are already members
+func addAssetGroupMembers(tx *gorm.DB, groupID uuid.UUID, assetIDs []uuid.UUID, source models.AssetGroupMemberSource) error {
+	if len(assetIDs) == 0 {
+		return nil
+	}
+	members := make([]models.AssetGroupMember, 0, len(assetIDs))
+	for _, assetID := range assetIDs {
+		members = append(members, models.AssetGroupMember{GroupID: groupID, AssetID: assetID, Source: source})
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&members, 500).Error; err != nil {
+		return fmt.Errorf("failed to add asset group members: %w", err)
+	}
+	return nil
+}
+
+// assetGroupChanges tracks the organizations whose dynamic group membership needs recomputing
+var assetGroupChanges = newOrgChangeSet()
+
+// assetGroupSourceTables are the tables whose writes can change dynamic group membership
+var assetGroupSourceTables = map[string]bool{
+	"affected_systems": true,
+	"asset_tags":       true,
+}
+
+// RegisterAssetGroupCallbacks installs GORM callbacks that mark asset group membership
+// stale whenever assets or their tags are written; RecomputeStale applies the changes.
+func RegisterAssetGroupCallbacks(db *gorm.DB) error {
+	markStale := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil || !assetGroupSourceTables[tx.Statement.Schema.Table] {
+			return
+		}
+		if orgID, ok := tenant.OrgFromContext(tx.Statement.Context); ok {
+			assetGroupChanges.mark(&orgID)
+			return
+		}
+		assetGroupChanges.mark(nil)
+	}
+
+	if err := db.Callback().Create().After("gorm:create").Register("asset_group:mark_create", markStale); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("asset_group:mark_update", markStale); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("asset_group:mark_delete", markStale)
+}
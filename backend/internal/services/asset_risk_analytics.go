@@ -0,0 +1,278 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/i18n"
+	"github.com/cyops/cyops-backend/pkg/telemetry"
+)
+
+// Bounds of the number of assets listed by the top assets analytics
+const (
+	DefaultTopAssetsLimit = 10
+	MaxTopAssetsLimit     = 100
+)
+
+// assetCriticalityWeights scale an asset's open risk by its business criticality; assets
+// without a criticality count as medium
+var assetCriticalityWeights = map[models.AssetCriticality]float64{
+	models.CriticalityCritical: 2.0,
+	models.CriticalityHigh:     1.5,
+	models.CriticalityMedium:   1.0,
+	models.CriticalityLow:      0.5,
+}
+
+// recurredFindingStatuses are the finding statuses a finding recurs from when it is reported
+// again
+var recurredFindingStatuses = []models.FindingStatus{
+	models.FindingStatusMitigated, models.FindingStatusFixed, models.FindingStatusVerified,
+}
+
+// TopAssetAnalytics ranks assets for quarterly reviews: by weighted open risk, by findings that
+// came back after being fixed, and the time asset owners take to fix findings
+type TopAssetAnalytics struct {
+	GeneratedAt     time.Time         `json:"generated_at"`
+	PeriodStart     time.Time         `json:"period_start"`
+	PeriodEnd       time.Time         `json:"period_end"`
+	RiskiestAssets  []AssetOpenRisk   `json:"riskiest_assets"`  // Highest weighted risk first
+	RepeatOffenders []AssetRecurrence `json:"repeat_offenders"` // Most recurrences in the period first
+	ByOwner         []MTTRGroup       `json:"by_owner"`         // Findings fixed in the period by asset owner, slowest first
+	ByOwnerTeam     []MTTRGroup       `json:"by_owner_team"`    // Findings fixed in the period by asset owner team, slowest first
+}
+
+// AssetOpenRisk is an asset's unresolved vulnerabilities weighted by severity and criticality
+type AssetOpenRisk struct {
+	ID                  uuid.UUID                `json:"id"`
+	Hostname            string                   `json:"hostname,omitempty"`
+	IPAddress           string                   `json:"ip_address,omitempty"`
+	Environment         models.Environment       `json:"environment"`
+	Criticality         *models.AssetCriticality `json:"criticality,omitempty"`
+	OwnerTeamID         *uuid.UUID               `json:"owner_team_id,omitempty"`
+	OwnerTeamName       string                   `json:"owner_team_name,omitempty"`
+	OpenVulnerabilities int64                    `json:"open_vulnerabilities"`
+	Critical            int64                    `json:"critical"`
+	High                int64                    `json:"high"`
+	Medium              int64                    `json:"medium"`
+	Low                 int64                    `json:"low"`
+	RiskScore           float64                  `json:"risk_score"` // Sum of risk weights of open vulnerabilities times the criticality weight
+}
+
+// AssetRecurrence counts the findings of an asset that scans reported again after they were
+// fixed
+type AssetRecurrence struct {
+	ID                uuid.UUID          `json:"id"`
+	Hostname          string             `json:"hostname,omitempty"`
+	IPAddress         string             `json:"ip_address,omitempty"`
+	Environment       models.Environment `json:"environment"`
+	RecurringFindings int64              `json:"recurring_findings"` // Distinct findings that recurred
+	Recurrences       int64              `json:"recurrences"`        // Times they recurred
+	LastRecurredAt    time.Time          `json:"last_recurred_at"`
+}
+
+// FindingFixSample is one finding fixed on an asset
+type FindingFixSample struct {
+	OwnerID       *uuid.UUID
+	OwnerName     string
+	OwnerTeamID   *uuid.UUID
+	OwnerTeamName string
+	DetectedAt    time.Time
+	FixedAt       time.Time
+}
+
+// TopAssets ranks the riskiest and most often recurring assets and the time to fix of asset
+// owners. Recurrences and fixes are counted in the period; open risk is current.
+func (s *RemediationAnalyticsService) TopAssets(startDate, endDate time.Time, limit int) (analytics *TopAssetAnalytics, err error) {
+	ctx, span := telemetry.StartSpan(s.db.Statement.Context, "RemediationAnalyticsService.TopAssets", reportSpanAttributes(startDate, endDate)...)
+	defer func() { telemetry.EndSpan(span, err) }()
+	traced := s.WithContext(ctx)
+
+	key := reportCacheKey(s.filter.reportKey(fmt.Sprintf("top_assets:%d", limit)), startDate, endDate)
+	return cached(s.db.Statement.Context, ActiveCache(), CacheGroupReports, key, func() (*TopAssetAnalytics, error) {
+		riskiest, err := traced.riskiestAssets(limit)
+		if err != nil {
+			return nil, err
+		}
+		offenders, err := traced.repeatOffenders(startDate, endDate, limit)
+		if err != nil {
+			return nil, err
+		}
+		fixes, err := traced.findingFixes(startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		byOwner, byOwnerTeam := ComputeOwnerTimeToFix(fixes)
+		return &TopAssetAnalytics{
+			GeneratedAt:     time.Now(),
+			PeriodStart:     startDate,
+			PeriodEnd:       endDate,
+			RiskiestAssets:  riskiest,
+			RepeatOffenders: offenders,
+			ByOwner:         byOwner,
+			ByOwnerTeam:     byOwnerTeam,
+		}, nil
+	})
+}
+
+// riskiestAssets ranks assets by the risk weights of their unpatched, unresolved
+// vulnerabilities, scaled by the asset's criticality
+func (s *RemediationAnalyticsService) riskiestAssets(limit int) ([]AssetOpenRisk, error) {
+	weightSQL, args := vulnerabilityRiskWeightSQL()
+	riskSQL := "SUM" + weightSQL + " * (CASE affected_systems.criticality"
+	for _, criticality := range []models.AssetCriticality{
+		models.CriticalityCritical, models.CriticalityHigh, models.CriticalityMedium, models.CriticalityLow,
+	} {
+		riskSQL += " WHEN ? THEN ?"
+		args = append(args, criticality, assetCriticalityWeights[criticality])
+	}
+	riskSQL += " ELSE 1 END)"
+	for _, severity := range dashboardSeverities {
+		args = append(args, severity)
+	}
+
+	assets := []AssetOpenRisk{}
+	if err := s.db.Model(&models.AffectedSystem{}).
+		Scopes(s.filter.scopeAssets("affected_systems.id")).
+		Select(`affected_systems.id, affected_systems.hostname, affected_systems.ip_address,
+			affected_systems.environment, affected_systems.criticality,
+			affected_systems.owner_team_id, COALESCE(teams.name, '') AS owner_team_name,
+			COUNT(*) AS open_vulnerabilities,
+			`+riskSQL+` AS risk_score,
+			COUNT(*) FILTER (WHERE vulnerabilities.severity = ?) AS critical,
+			COUNT(*) FILTER (WHERE vulnerabilities.severity = ?) AS high,
+			COUNT(*) FILTER (WHERE vulnerabilities.severity = ?) AS medium,
+			COUNT(*) FILTER (WHERE vulnerabilities.severity = ?) AS low`, args...).
+		Joins("JOIN vulnerability_affected_systems ON vulnerability_affected_systems.affected_system_id = affected_systems.id AND vulnerability_affected_systems.patched_at IS NULL").
+		Joins("JOIN vulnerabilities ON vulnerabilities.id = vulnerability_affected_systems.vulnerability_id AND vulnerabilities.deleted_at IS NULL").
+		Joins("LEFT JOIN teams ON teams.id = affected_systems.owner_team_id").
+		Where("vulnerabilities.status IN ?", unresolvedStatuses).
+		Group("affected_systems.id, teams.name").
+		Order("risk_score DESC, open_vulnerabilities DESC, affected_systems.hostname ASC").
+		Limit(limit).
+		Scan(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to rank risky assets: %w", err)
+	}
+	for i := range assets {
+		assets[i].RiskScore = roundDays(assets[i].RiskScore)
+	}
+	return assets, nil
+}
+
+// repeatOffenders ranks assets by the findings that recurred in the period: findings moved from
+// fixed back to open, and fixed findings a later import reported again
+func (s *RemediationAnalyticsService) repeatOffenders(startDate, endDate time.Time, limit int) ([]AssetRecurrence, error) {
+	reopened := s.db.Table("finding_status_history").
+		Select("finding_id, changed_at AS recurred_at").
+		Where("old_status IN ? AND new_status = ? AND changed_at BETWEEN ? AND ?",
+			recurredFindingStatuses, models.FindingStatusOpen, startDate, endDate)
+	seenAgain := s.db.Model(&models.VulnerabilityFinding{}).
+		Select("id AS finding_id, last_seen AS recurred_at").
+		Where("status IN ? AND fixed_at IS NOT NULL AND last_seen > fixed_at AND last_seen BETWEEN ? AND ?",
+			recurredFindingStatuses, startDate, endDate)
+
+	offenders := []AssetRecurrence{}
+	if err := s.db.Model(&models.AffectedSystem{}).
+		Scopes(s.filter.scopeAssets("affected_systems.id")).
+		Select(`affected_systems.id, affected_systems.hostname, affected_systems.ip_address,
+			affected_systems.environment,
+			COUNT(DISTINCT vulnerability_findings.id) AS recurring_findings,
+			COUNT(*) AS recurrences,
+			MAX(recurred.recurred_at) AS last_recurred_at`).
+		Joins("JOIN vulnerability_findings ON vulnerability_findings.affected_system_id = affected_systems.id").
+		Joins("JOIN (? UNION ALL ?) AS recurred ON recurred.finding_id = vulnerability_findings.id", reopened, seenAgain).
+		Group("affected_systems.id").
+		Order("recurrences DESC, recurring_findings DESC, affected_systems.hostname ASC").
+		Limit(limit).
+		Scan(&offenders).Error; err != nil {
+		return nil, fmt.Errorf("failed to rank recurring findings: %w", err)
+	}
+	return offenders, nil
+}
+
+// findingFixes loads the findings fixed in the period with the owner and owner team of their
+// asset
+func (s *RemediationAnalyticsService) findingFixes(startDate, endDate time.Time) ([]FindingFixSample, error) {
+	var samples []FindingFixSample
+	if err := s.db.Model(&models.VulnerabilityFinding{}).
+		Scopes(s.filter.scopeAssets("vulnerability_findings.affected_system_id")).
+		Select(`affected_systems.owner_id, COALESCE(NULLIF(users.name, ''), users.email, '') AS owner_name,
+			affected_systems.owner_team_id, COALESCE(teams.name, '') AS owner_team_name,
+			vulnerability_findings.first_detected AS detected_at, vulnerability_findings.fixed_at`).
+		Joins("JOIN affected_systems ON affected_systems.id = vulnerability_findings.affected_system_id").
+		Joins("LEFT JOIN users ON users.id = affected_systems.owner_id").
+		Joins("LEFT JOIN teams ON teams.id = affected_systems.owner_team_id").
+		Where("vulnerability_findings.status IN ? AND vulnerability_findings.fixed_at BETWEEN ? AND ?",
+			[]models.FindingStatus{models.FindingStatusFixed, models.FindingStatusVerified}, startDate, endDate).
+		Scan(&samples).Error; err != nil {
+		return nil, fmt.Errorf("failed to load fixed findings: %w", err)
+	}
+	return samples, nil
+}
+
+// ComputeOwnerTimeToFix summarizes the days from detection to fix of findings by asset owner
+// and by asset owner team, slowest first. Findings on assets without an owner or team are
+// grouped under an empty key.
+func ComputeOwnerTimeToFix(samples []FindingFixSample) (byOwner, byOwnerTeam []MTTRGroup) {
+	owners := make(map[string][]float64)
+	ownerLabels := make(map[string]string)
+	teams := make(map[string][]float64)
+	teamLabels := make(map[string]string)
+
+	for _, sample := range samples {
+		days := sample.FixedAt.Sub(sample.DetectedAt).Hours() / 24
+		if days < 0 {
+			days = 0
+		}
+
+		ownerKey, ownerLabel := "", "Unassigned"
+		if sample.OwnerID != nil {
+			ownerKey, ownerLabel = sample.OwnerID.String(), sample.OwnerName
+		}
+		owners[ownerKey] = append(owners[ownerKey], days)
+		ownerLabels[ownerKey] = ownerLabel
+
+		teamKey, teamLabel := "", "Unassigned"
+		if sample.OwnerTeamID != nil {
+			teamKey, teamLabel = sample.OwnerTeamID.String(), sample.OwnerTeamName
+		}
+		teams[teamKey] = append(teams[teamKey], days)
+		teamLabels[teamKey] = teamLabel
+	}
+
+	return slowestFirst(owners, ownerLabels), slowestFirst(teams, teamLabels)
+}
+
+// slowestFirst summarizes fix times per group, slowest average first
+func slowestFirst(days map[string][]float64, labels map[string]string) []MTTRGroup {
+	groups := make([]MTTRGroup, 0, len(days))
+	for key, d := range days {
+		groups = append(groups, MTTRGroup{Key: key, Label: labels[key], MTTRStats: mttrStats(d)})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].AverageDays != groups[j].AverageDays {
+			return groups[i].AverageDays > groups[j].AverageDays
+		}
+		return groups[i].Label < groups[j].Label
+	})
+	return groups
+}
+
+// Localized returns a copy of the analytics with the unassigned owner groups labelled in lang
+func (a TopAssetAnalytics) Localized(lang string) TopAssetAnalytics {
+	localize := func(groups []MTTRGroup) []MTTRGroup {
+		localized := make([]MTTRGroup, len(groups))
+		for i, group := range groups {
+			if group.Key == "" {
+				group.Label = i18n.T(lang, "report.unassigned")
+			}
+			localized[i] = group
+		}
+		return localized
+	}
+	a.ByOwner = localize(a.ByOwner)
+	a.ByOwnerTeam = localize(a.ByOwnerTeam)
+	return a
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -19,6 +20,11 @@ func NewAssetSearchService(db *gorm.DB) *AssetSearchService {
 	return &AssetSearchService{db: db}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *AssetSearchService) WithContext(ctx context.Context) *AssetSearchService {
+	return &AssetSearchService{db: s.db.WithContext(ctx)}
+}
+
 // BuildSearchQuery builds a GORM query with all filters applied
 func (s *AssetSearchService) BuildSearchQuery(params AssetListParams) *gorm.DB {
 	query := s.db.Model(&models.AffectedSystem{})
@@ -49,6 +55,14 @@ func (s *AssetSearchService) BuildSearchQuery(params AssetListParams) *gorm.DB {
 	if params.OwnerID != nil {
 		query = query.Where("owner_id = ?", *params.OwnerID)
 	}
+	if params.OwnerTeamID != nil {
+		query = query.Where("owner_team_id = ?", *params.OwnerTeamID)
+	}
+
+	// Apply stale agent filter
+	if params.AgentStale != nil {
+		query = query.Where("agent_id <> '' AND agent_stale = ?", *params.AgentStale)
+	}
 
 	// Apply full-text search if provided
 	if params.Search != "" {
@@ -1,11 +1,16 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/policy"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/cyops/cyops-backend/pkg/utils"
 	"gorm.io/gorm"
 )
 
@@ -99,6 +104,14 @@ func NewAssetService(db *gorm.DB) *AssetService {
 	}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *AssetService) WithContext(ctx context.Context) *AssetService {
+	return &AssetService{
+		db:            s.db.WithContext(ctx),
+		searchService: s.searchService.WithContext(ctx),
+	}
+}
+
 // GetDB returns the database connection (for use with model methods)
 func (s *AssetService) GetDB() *gorm.DB {
 	return s.db
@@ -114,9 +127,18 @@ type AssetListParams struct {
 	Environment *models.Environment      `json:"environment,omitempty"`
 	SystemType  *models.SystemType       `json:"system_type,omitempty"`
 	OwnerID     *uuid.UUID               `json:"owner_id,omitempty"`
+	OwnerTeamID *uuid.UUID               `json:"owner_team_id,omitempty"`
 	Tags        []string                 `json:"tags,omitempty"`
+	AgentStale  *bool                    `json:"agent_stale,omitempty"` // Not served by the search index
 	SortBy      string                   `json:"sort_by,omitempty"`
 	SortOrder   string                   `json:"sort_order,omitempty"`
+	OrgID       *uuid.UUID               `json:"-"` // Set from the request context; only used by the search index
+	Fieldset    *Fieldset                `json:"-"` // Columns and relations to load (?fields=, ?include=); nil loads the defaults
+}
+
+// assetPreloads are the relations loaded for assets by default
+func assetPreloads(query *gorm.DB) *gorm.DB {
+	return query.Preload("Owner").Preload("OwnerTeam").Preload("Tags")
 }
 
 // AssetWithVulnCount extends AffectedSystem with vulnerability count
@@ -152,13 +174,17 @@ func (s *AssetService) Create(asset *models.AffectedSystem) error {
 		asset.Status = models.StatusActive
 	}
 
+	if err := s.authorize("write", *asset); err != nil {
+		return err
+	}
+
 	// Create the asset in the database
 	if err := s.db.Create(asset).Error; err != nil {
 		return fmt.Errorf("failed to create asset: %w", err)
 	}
 
 	// Preload relationships for the response
-	if err := s.db.Preload("Owner").Preload("Tags").First(asset, asset.ID).Error; err != nil {
+	if err := s.db.Preload("Owner").Preload("OwnerTeam").Preload("Tags").First(asset, asset.ID).Error; err != nil {
 		return fmt.Errorf("failed to load asset relationships: %w", err)
 	}
 
@@ -178,29 +204,47 @@ func (s *AssetService) List(params AssetListParams) (*AssetListResponse, error)
 		params.Limit = 100
 	}
 
-	// Build search query with all filters
-	query := s.searchService.BuildSearchQuery(params)
-
-	// Get total count before pagination
+	var assets []models.AffectedSystem
 	var total int64
-	if err := query.Count(&total).Error; err != nil {
-		return nil, fmt.Errorf("failed to count assets: %w", err)
+	fromIndex := false
+
+	// Route through the search index when configured, falling back to Postgres on any error
+	if idx := ActiveSearchIndex(); idx != nil && params.AgentStale == nil {
+		if orgID, ok := tenant.OrgFromContext(s.db.Statement.Context); ok {
+			params.OrgID = &orgID
+		}
+		var err error
+		assets, total, err = s.listFromIndex(idx, params)
+		if err == nil {
+			fromIndex = true
+		} else {
+			utils.Logger.Warn().Err(err).Msg("Search index query failed, falling back to Postgres")
+		}
 	}
 
-	// Apply sorting
-	query = s.searchService.ApplySort(query, params.SortBy, params.SortOrder)
+	if !fromIndex {
+		// Build search query with all filters
+		query := s.searchService.BuildSearchQuery(params)
 
-	// Apply pagination
-	offset := (params.Page - 1) * params.Limit
-	query = query.Offset(offset).Limit(params.Limit)
+		// Get total count before pagination
+		if err := query.Count(&total).Error; err != nil {
+			return nil, fmt.Errorf("failed to count assets: %w", err)
+		}
 
-	// Eager load relationships
-	query = query.Preload("Owner").Preload("Tags")
+		// Apply sorting
+		query = s.searchService.ApplySort(query, params.SortBy, params.SortOrder)
 
-	// Execute query
-	var assets []models.AffectedSystem
-	if err := query.Find(&assets).Error; err != nil {
-		return nil, fmt.Errorf("failed to list assets: %w", err)
+		// Apply pagination
+		offset := (params.Page - 1) * params.Limit
+		query = query.Offset(offset).Limit(params.Limit)
+
+		// Eager load relationships
+		query = params.Fieldset.Apply(query, assetPreloads)
+
+		// Execute query
+		if err := query.Find(&assets).Error; err != nil {
+			return nil, fmt.Errorf("failed to list assets: %w", err)
+		}
 	}
 
 	// Optimize: Batch load vulnerability counts for all assets in single query
@@ -216,7 +260,7 @@ func (s *AssetService) List(params AssetListParams) (*AssetListResponse, error)
 		Count   int64
 	}
 	var vulnCounts []VulnCountResult
-	if len(assetIDs) > 0 {
+	if len(assetIDs) > 0 && params.Fieldset.Wants("vulnerability_count") {
 		s.db.Table("vulnerability_affected_systems vas").
 			Select("vas.affected_system_id as asset_id, COUNT(*) as count").
 			Joins("JOIN vulnerabilities v ON vas.vulnerability_id = v.id").
@@ -255,11 +299,35 @@ func (s *AssetService) List(params AssetListParams) (*AssetListResponse, error)
 	}, nil
 }
 
+// listFromIndex resolves a list request against the search index and hydrates from Postgres
+func (s *AssetService) listFromIndex(idx *SearchIndexService, params AssetListParams) ([]models.AffectedSystem, int64, error) {
+	ids, total, err := idx.SearchAssetIDs(params)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(ids) == 0 {
+		return []models.AffectedSystem{}, total, nil
+	}
+
+	var assets []models.AffectedSystem
+	if err := params.Fieldset.Apply(s.db, assetPreloads).Where("id IN ?", ids).Find(&assets).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load assets: %w", err)
+	}
+
+	return orderByIDs(assets, ids, func(a *models.AffectedSystem) uuid.UUID { return a.ID }), total, nil
+}
+
 // GetByID retrieves an asset by ID
 func (s *AssetService) GetByID(id string, includeVulns bool) (*models.AffectedSystem, error) {
+	return s.GetByIDWithFieldset(id, includeVulns, nil)
+}
+
+// GetByIDWithFieldset retrieves an asset by ID, loading only the requested columns and
+// relations (nil loads the default relations)
+func (s *AssetService) GetByIDWithFieldset(id string, includeVulns bool, fieldset *Fieldset) (*models.AffectedSystem, error) {
 	var asset models.AffectedSystem
 
-	query := s.db.Preload("Owner").Preload("Tags")
+	query := fieldset.Apply(s.db, assetPreloads)
 
 	if includeVulns {
 		query = query.Preload("Vulnerabilities")
@@ -272,8 +340,14 @@ func (s *AssetService) GetByID(id string, includeVulns bool) (*models.AffectedSy
 	return &asset, nil
 }
 
-// Update updates an asset
-func (s *AssetService) Update(id string, updates map[string]interface{}) (*models.AffectedSystem, error) {
+// Update updates an asset and records each changed field in its history
+func (s *AssetService) Update(id string, updates map[string]interface{}, changedByID uuid.UUID) (*models.AffectedSystem, error) {
+	return s.UpdateIfMatch(id, updates, changedByID, "")
+}
+
+// UpdateIfMatch updates an asset only if it is still at the version given by ifMatch (an
+// ETag), returning ErrPreconditionFailed otherwise. An empty ifMatch updates unconditionally.
+func (s *AssetService) UpdateIfMatch(id string, updates map[string]interface{}, changedByID uuid.UUID, ifMatch string) (*models.AffectedSystem, error) {
 	var asset models.AffectedSystem
 
 	// Check if asset exists
@@ -281,21 +355,68 @@ func (s *AssetService) Update(id string, updates map[string]interface{}) (*model
 		return nil, fmt.Errorf("asset not found: %w", err)
 	}
 
-	// Apply updates
-	if err := s.db.Model(&asset).Updates(updates).Error; err != nil {
-		return nil, fmt.Errorf("failed to update asset: %w", err)
+	// The caller must be allowed to modify the asset both before and after the change
+	if err := s.authorize("write", asset, withAttributeUpdates(asset, updates)); err != nil {
+		return nil, err
+	}
+
+	if err := checkIfMatch(ifMatch, asset.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	// A manually chosen criticality is kept by automatic scoring unless the caller says otherwise
+	if _, ok := updates["criticality"]; ok {
+		if _, ok := updates["criticality_override"]; !ok {
+			updates["criticality_override"] = true
+		}
+	}
+
+	before := asset
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Apply updates; a conditional update only applies to the version it was checked against
+		query := tx.Model(&asset)
+		if ifMatch != "" {
+			query = query.Where("updated_at = ?", before.UpdatedAt)
+		}
+		result := query.Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update asset: %w", result.Error)
+		}
+		if ifMatch != "" && len(updates) > 0 && result.RowsAffected == 0 {
+			return ErrPreconditionFailed
+		}
+
+		var after models.AffectedSystem
+		if err := tx.First(&after, "id = ?", asset.ID).Error; err != nil {
+			return fmt.Errorf("failed to reload asset: %w", err)
+		}
+
+		var entries []models.AssetHistory
+		for _, change := range AssetFieldChanges(before, after) {
+			entries = append(entries, models.AssetHistory{
+				AssetID:  asset.ID,
+				Action:   models.AssetHistoryUpdated,
+				Field:    change.Field,
+				OldValue: change.OldValue,
+				NewValue: change.NewValue,
+			})
+		}
+		return recordAssetHistory(tx, changedByID, entries...)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Reload with relationships
-	if err := s.db.Preload("Owner").Preload("Tags").First(&asset, asset.ID).Error; err != nil {
+	if err := s.db.Preload("Owner").Preload("OwnerTeam").Preload("Tags").First(&asset, asset.ID).Error; err != nil {
 		return nil, fmt.Errorf("failed to reload asset: %w", err)
 	}
 
 	return &asset, nil
 }
 
-// Delete soft deletes an asset
-func (s *AssetService) Delete(id string) error {
+// Delete soft deletes an asset and records who deleted it
+func (s *AssetService) Delete(id string, deletedByID uuid.UUID) error {
 	var asset models.AffectedSystem
 
 	// Check if asset exists
@@ -303,40 +424,92 @@ func (s *AssetService) Delete(id string) error {
 		return fmt.Errorf("asset not found: %w", err)
 	}
 
-	// Soft delete
-	if err := s.db.Delete(&asset).Error; err != nil {
-		return fmt.Errorf("failed to delete asset: %w", err)
+	if err := s.authorize("delete", asset); err != nil {
+		return err
 	}
 
-	return nil
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Soft delete, recording who deleted the asset for the recycle bin
+		if err := tx.Model(&asset).UpdateColumn("deleted_by_id", deletedByID).Error; err != nil {
+			return fmt.Errorf("failed to delete asset: %w", err)
+		}
+		if err := tx.Delete(&asset).Error; err != nil {
+			return fmt.Errorf("failed to delete asset: %w", err)
+		}
+
+		return recordAssetHistory(tx, deletedByID, models.AssetHistory{
+			AssetID:  asset.ID,
+			Action:   models.AssetHistoryDeleted,
+			OldValue: string(asset.Status),
+		})
+	})
 }
 
-// UpdateStatus updates asset status with validation
-func (s *AssetService) UpdateStatus(id string, status models.AssetStatus, notes string) (*models.AffectedSystem, error) {
+// UpdateStatus updates asset status with validation and records the transition with its notes
+// (the decommission reason when decommissioning) in the asset's history. Decommissioning
+// closes the asset's open findings through DecommissionAsset.
+func (s *AssetService) UpdateStatus(id string, status models.AssetStatus, notes string, changedByID uuid.UUID) (*models.AffectedSystem, error) {
+	if status == models.StatusDecommissioned {
+		assetID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("asset not found")
+		}
+		reason := strings.TrimSpace(notes)
+		if reason == "" {
+			reason = "Decommissioned through a status change"
+		}
+		result, err := s.DecommissionAsset(assetID, AssetDecommissionRequest{Reason: reason}, changedByID)
+		if err != nil {
+			return nil, err
+		}
+		return result.Asset, nil
+	}
+
 	// Get current asset
 	var asset models.AffectedSystem
 	if err := s.db.First(&asset, "id = ?", id).Error; err != nil {
 		return nil, fmt.Errorf("asset not found: %w", err)
 	}
 
+	if err := s.authorize("write", asset); err != nil {
+		return nil, err
+	}
+
 	// Validate status transition
 	if err := s.validateStatusTransition(asset.Status, status); err != nil {
 		return nil, err
 	}
 
 	// Update status
+	oldStatus := asset.Status
 	asset.Status = status
-	if err := s.db.Save(&asset).Error; err != nil {
-		return nil, fmt.Errorf("failed to update status: %w", err)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&asset).Error; err != nil {
+			return fmt.Errorf("failed to update status: %w", err)
+		}
+
+		return recordAssetHistory(tx, changedByID, models.AssetHistory{
+			AssetID:  asset.ID,
+			Action:   models.AssetHistoryStatusChanged,
+			Field:    "status",
+			OldValue: string(oldStatus),
+			NewValue: string(status),
+			Notes:    strings.TrimSpace(notes),
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Log status change (structured logging)
-	// Note: Using fmt.Printf as placeholder until zerolog is fully integrated
-	fmt.Printf("Asset status changed: asset_id=%s, old_status=%s, new_status=%s, notes=%s\n",
-		id, asset.Status, status, notes)
+	utils.Logger.Info().
+		Str("asset_id", id).
+		Str("old_status", string(oldStatus)).
+		Str("new_status", string(status)).
+		Str("changed_by", changedByID.String()).
+		Msg("Asset status changed")
 
 	// Reload with relationships
-	if err := s.db.Preload("Owner").Preload("Tags").First(&asset, "id = ?", id).Error; err != nil {
+	if err := s.db.Preload("Owner").Preload("OwnerTeam").Preload("Tags").First(&asset, "id = ?", id).Error; err != nil {
 		return nil, fmt.Errorf("failed to reload asset: %w", err)
 	}
 
@@ -358,8 +531,8 @@ func (s *AssetService) validateStatusTransition(from, to models.AssetStatus) err
 	return nil
 }
 
-// AddTags adds tags to an asset
-func (s *AssetService) AddTags(assetID string, tags []string) error {
+// AddTags adds tags to an asset; newly added tags are recorded in its history
+func (s *AssetService) AddTags(assetID string, tags []string, changedByID uuid.UUID) error {
 	if len(tags) == 0 {
 		return nil
 	}
@@ -370,6 +543,10 @@ func (s *AssetService) AddTags(assetID string, tags []string) error {
 		return fmt.Errorf("asset not found: %w", err)
 	}
 
+	if err := s.authorize("write", asset); err != nil {
+		return err
+	}
+
 	// Create asset tags (bulk insert with ON CONFLICT DO NOTHING)
 	for _, tag := range tags {
 		assetTag := models.AssetTag{
@@ -377,20 +554,41 @@ func (s *AssetService) AddTags(assetID string, tags []string) error {
 			Tag:     tag,
 		}
 		// Use FirstOrCreate to handle duplicates gracefully
-		if err := s.db.Where("asset_id = ? AND tag = ?", assetID, tag).
-			FirstOrCreate(&assetTag).Error; err != nil {
-			return fmt.Errorf("failed to add tag '%s': %w", tag, err)
+		result := s.db.Where("asset_id = ? AND tag = ?", assetID, tag).FirstOrCreate(&assetTag)
+		if result.Error != nil {
+			return fmt.Errorf("failed to add tag '%s': %w", tag, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		if err := recordAssetHistory(s.db, changedByID, models.AssetHistory{
+			AssetID:  asset.ID,
+			Action:   models.AssetHistoryTagAdded,
+			Field:    "tags",
+			NewValue: assetTag.Tag,
+		}); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-// RemoveTag removes a tag from an asset
-func (s *AssetService) RemoveTag(assetID, tag string) error {
+// RemoveTag removes a tag from an asset and records the removal in its history
+func (s *AssetService) RemoveTag(assetID, tag string, changedByID uuid.UUID) error {
 	// Normalize tag to lowercase
 	tag = strings.ToLower(strings.TrimSpace(tag))
 
+	if policy.Constrained(s.db.Statement.Context, "asset", "write") {
+		var asset models.AffectedSystem
+		if err := s.db.First(&asset, "id = ?", assetID).Error; err != nil {
+			return fmt.Errorf("asset not found: %w", err)
+		}
+		if err := s.authorize("write", asset); err != nil {
+			return err
+		}
+	}
+
 	result := s.db.Where("asset_id = ? AND tag = ?", assetID, tag).
 		Delete(&models.AssetTag{})
 
@@ -402,11 +600,46 @@ func (s *AssetService) RemoveTag(assetID, tag string) error {
 		return fmt.Errorf("tag not found on asset")
 	}
 
-	return nil
+	parsedID, err := uuid.Parse(assetID)
+	if err != nil {
+		return fmt.Errorf("asset not found: %w", err)
+	}
+	return recordAssetHistory(s.db, changedByID, models.AssetHistory{
+		AssetID:  parsedID,
+		Action:   models.AssetHistoryTagRemoved,
+		Field:    "tags",
+		OldValue: tag,
+	})
+}
+
+// authorize checks an asset action against the caller's role constraints
+func (s *AssetService) authorize(action string, assets ...models.AffectedSystem) error {
+	return policy.Authorize(s.db.Statement.Context, "asset", action, policy.Attributes(assets...))
+}
+
+// withAttributeUpdates returns a copy of asset with the constrained attributes of an update map applied
+func withAttributeUpdates(asset models.AffectedSystem, updates map[string]interface{}) models.AffectedSystem {
+	if environment, ok := updates["environment"].(string); ok {
+		asset.Environment = models.Environment(environment)
+	}
+	if value, ok := updates["criticality"]; ok {
+		if criticality, ok := value.(string); ok && criticality != "" {
+			crit := models.AssetCriticality(criticality)
+			asset.Criticality = &crit
+		} else {
+			asset.Criticality = nil
+		}
+	}
+	return asset
 }
 
 // GetStats retrieves aggregated asset statistics
 func (s *AssetService) GetStats() (*AssetStats, error) {
+	return cached(s.db.Statement.Context, ActiveCache(), CacheGroupAssetStats, "all", s.computeStats)
+}
+
+// computeStats aggregates asset statistics from the database
+func (s *AssetService) computeStats() (*AssetStats, error) {
 	stats := &AssetStats{
 		ByCriticality: make(map[string]int),
 		ByStatus:      make(map[string]int),
@@ -697,7 +930,11 @@ type AssetDuplicateMatch struct {
 	MatchedOnHostname bool                   `json:"matched_on_hostname"`
 }
 
-// CheckDuplicate finds potential duplicate assets based on fuzzy matching
+// maxFuzzyDuplicates caps the fuzzy asset ID matches returned by a duplicate check
+const maxFuzzyDuplicates = 50
+
+// CheckDuplicate finds potential duplicate assets based on fuzzy matching. Asset IDs are
+// compared by trigram similarity in the database, threshold being the minimum in percent.
 func (s *AssetService) CheckDuplicate(name, ipAddress, hostname string, threshold float64) ([]*AssetDuplicateMatch, error) {
 	// Default threshold to 80% if not provided
 	if threshold <= 0 || threshold > 100 {
@@ -708,15 +945,17 @@ func (s *AssetService) CheckDuplicate(name, ipAddress, hostname string, threshol
 	exactMatches := make(map[uuid.UUID]bool) // Track exact matches to avoid duplicates
 
 	// Optimize: Use targeted queries instead of loading all assets
-	baseQuery := s.db.Model(&models.AffectedSystem{}).
-		Preload("Owner").
-		Preload("Tags").
-		Where("status != ? AND deleted_at IS NULL", "DECOMMISSIONED")
+	baseQuery := func() *gorm.DB {
+		return s.db.Model(&models.AffectedSystem{}).
+			Preload("Owner").
+			Preload("Tags").
+			Where("status != ? AND deleted_at IS NULL", "DECOMMISSIONED")
+	}
 
 	// 1. Check for exact IP match (most common duplicate scenario)
 	if ipAddress != "" {
 		var ipMatches []models.AffectedSystem
-		if err := baseQuery.Where("ip_address = ?", ipAddress).Find(&ipMatches).Error; err == nil {
+		if err := baseQuery().Where("ip_address = ?", ipAddress).Find(&ipMatches).Error; err == nil {
 			for i := range ipMatches {
 				asset := &ipMatches[i]
 				results = append(results, &AssetDuplicateMatch{
@@ -732,7 +971,7 @@ func (s *AssetService) CheckDuplicate(name, ipAddress, hostname string, threshol
 	// 2. Check for exact hostname match
 	if hostname != "" {
 		var hostnameMatches []models.AffectedSystem
-		if err := baseQuery.Where("hostname = ?", hostname).Find(&hostnameMatches).Error; err == nil {
+		if err := baseQuery().Where("hostname = ?", hostname).Find(&hostnameMatches).Error; err == nil {
 			for i := range hostnameMatches {
 				asset := &hostnameMatches[i]
 				if !exactMatches[asset.ID] { // Avoid duplicate entries
@@ -748,42 +987,16 @@ func (s *AssetService) CheckDuplicate(name, ipAddress, hostname string, threshol
 	}
 
 	// 3. Fuzzy match on asset_id (only if name provided and not too many exact matches)
-	// Limit fuzzy matching to avoid performance issues
 	if name != "" && len(results) < 10 {
-		var candidates []models.AffectedSystem
-		
-		// Use LIKE for initial filtering to reduce candidates
-		// This is a heuristic: if first 3 chars match, it's worth checking similarity
-		likePattern := ""
-		if len(name) >= 3 {
-			likePattern = name[:3] + "%"
-		}
-		
-		candidateQuery := baseQuery
-		if likePattern != "" {
-			candidateQuery = candidateQuery.Where("asset_id ILIKE ?", likePattern)
-		} else {
-			// If name is very short, limit to reasonable number of candidates
-			candidateQuery = candidateQuery.Limit(100)
-		}
-		
-		if err := candidateQuery.Find(&candidates).Error; err == nil {
-			for i := range candidates {
-				asset := &candidates[i]
-				if exactMatches[asset.ID] { // Skip assets already matched
-					continue
-				}
-				
-				if asset.AssetID != "" {
-					similarity := calculateSimilarity(name, asset.AssetID)
-					if similarity >= threshold {
-						results = append(results, &AssetDuplicateMatch{
-							Asset:         asset,
-							Similarity:    similarity,
-							MatchedOnName: true,
-						})
-					}
-				}
+		matches, err := s.similarAssetIDs(name, threshold)
+		if err != nil {
+			// pg_trgm may be unavailable; compare a narrowed candidate set in Go instead
+			utils.Logger.Warn().Err(err).Msg("Trigram duplicate detection failed, falling back to Levenshtein matching")
+			matches = similarAssetIDsInGo(baseQuery(), name, threshold)
+		}
+		for _, match := range matches {
+			if !exactMatches[match.Asset.ID] { // Skip assets already matched
+				results = append(results, match)
 			}
 		}
 	}
@@ -791,3 +1004,203 @@ func (s *AssetService) CheckDuplicate(name, ipAddress, hostname string, threshol
 	return results, nil
 }
 
+// similarAssetIDs finds the assets whose asset ID is trigram-similar to name, most similar
+// first. The % operator is answered from the trigram index using the similarity threshold
+// set for the transaction.
+func (s *AssetService) similarAssetIDs(name string, threshold float64) ([]*AssetDuplicateMatch, error) {
+	var matches []*AssetDuplicateMatch
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config('pg_trgm.similarity_threshold', ?, true)",
+			strconv.FormatFloat(threshold/100, 'f', 4, 64)).Error; err != nil {
+			return err
+		}
+
+		var scored []struct {
+			ID    uuid.UUID
+			Score float64
+		}
+		if err := tx.Model(&models.AffectedSystem{}).
+			Select("id, similarity(lower(asset_id), lower(?)) as score", name).
+			Where("status != ? AND lower(asset_id) % lower(?)", "DECOMMISSIONED", name).
+			Order("score DESC").
+			Limit(maxFuzzyDuplicates).
+			Scan(&scored).Error; err != nil {
+			return err
+		}
+		if len(scored) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(scored))
+		for i, sc := range scored {
+			ids[i] = sc.ID
+		}
+		var assets []models.AffectedSystem
+		if err := tx.Preload("Owner").Preload("Tags").Where("id IN ?", ids).Find(&assets).Error; err != nil {
+			return err
+		}
+		byID := make(map[uuid.UUID]*models.AffectedSystem, len(assets))
+		for i := range assets {
+			byID[assets[i].ID] = &assets[i]
+		}
+
+		for _, sc := range scored {
+			if asset, ok := byID[sc.ID]; ok {
+				matches = append(matches, &AssetDuplicateMatch{
+					Asset:         asset,
+					Similarity:    sc.Score * 100,
+					MatchedOnName: true,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar asset IDs: %w", err)
+	}
+	return matches, nil
+}
+
+// similarAssetIDsInGo compares asset IDs sharing the first characters of name by Levenshtein
+// similarity, for databases without pg_trgm
+func similarAssetIDsInGo(query *gorm.DB, name string, threshold float64) []*AssetDuplicateMatch {
+	// Use LIKE for initial filtering to reduce candidates
+	// This is a heuristic: if first 3 chars match, it's worth checking similarity
+	if len(name) >= 3 {
+		query = query.Where("asset_id ILIKE ?", name[:3]+"%")
+	} else {
+		// If name is very short, limit to reasonable number of candidates
+		query = query.Limit(100)
+	}
+
+	var candidates []models.AffectedSystem
+	if err := query.Find(&candidates).Error; err != nil {
+		return nil
+	}
+
+	var matches []*AssetDuplicateMatch
+	for i := range candidates {
+		asset := &candidates[i]
+		if asset.AssetID == "" {
+			continue
+		}
+		similarity := calculateSimilarity(name, asset.AssetID)
+		if similarity >= threshold {
+			matches = append(matches, &AssetDuplicateMatch{
+				Asset:         asset,
+				Similarity:    similarity,
+				MatchedOnName: true,
+			})
+		}
+	}
+	return matches
+}
+
+
+// AssetFieldChange is a single field difference between two versions of an asset
+type AssetFieldChange struct {
+	Field    string
+	OldValue string
+	NewValue string
+}
+
+// AssetFieldChanges returns the tracked fields that differ between before and after, in a
+// stable order. Status changes made through Update are included; relationships are not.
+func AssetFieldChanges(before, after models.AffectedSystem) []AssetFieldChange {
+	fields := []struct {
+		name          string
+		before, after string
+	}{
+		{"hostname", before.Hostname, after.Hostname},
+		{"ip_address", before.IPAddress, after.IPAddress},
+		{"asset_id", before.AssetID, after.AssetID},
+		{"system_type", string(before.SystemType), string(after.SystemType)},
+		{"description", before.Description, after.Description},
+		{"environment", string(before.Environment), string(after.Environment)},
+		{"criticality", criticalityValue(before.Criticality), criticalityValue(after.Criticality)},
+		{"criticality_override", strconv.FormatBool(before.CriticalityOverride), strconv.FormatBool(after.CriticalityOverride)},
+		{"status", string(before.Status), string(after.Status)},
+		{"owner_id", uuidValue(before.OwnerID), uuidValue(after.OwnerID)},
+		{"owner_team_id", uuidValue(before.OwnerTeamID), uuidValue(after.OwnerTeamID)},
+		{"department", before.Department, after.Department},
+		{"location", before.Location, after.Location},
+		{"operating_system", before.OperatingSystem, after.OperatingSystem},
+	}
+
+	var changes []AssetFieldChange
+	for _, f := range fields {
+		if f.before != f.after {
+			changes = append(changes, AssetFieldChange{Field: f.name, OldValue: f.before, NewValue: f.after})
+		}
+	}
+	return changes
+}
+
+// criticalityValue renders an optional criticality for history entries
+func criticalityValue(c *models.AssetCriticality) string {
+	if c == nil {
+		return ""
+	}
+	return string(*c)
+}
+
+// uuidValue renders an optional ID for history entries
+func uuidValue(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// recordAssetHistory stores history entries attributed to changedByID (uuid.Nil for system changes)
+func recordAssetHistory(tx *gorm.DB, changedByID uuid.UUID, entries ...models.AssetHistory) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var actor *uuid.UUID
+	if changedByID != uuid.Nil {
+		actor = &changedByID
+	}
+	for i := range entries {
+		entries[i].ChangedByID = actor
+	}
+	if err := tx.Create(&entries).Error; err != nil {
+		return fmt.Errorf("failed to record asset history: %w", err)
+	}
+	return nil
+}
+
+// GetHistory returns a page of an asset's change history, newest first. The history of a
+// deleted asset remains available.
+func (s *AssetService) GetHistory(assetID uuid.UUID, page, limit int) ([]models.AssetHistory, int64, error) {
+	var count int64
+	if err := s.db.Unscoped().Model(&models.AffectedSystem{}).Where("id = ?", assetID).Count(&count).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get asset: %w", err)
+	}
+	if count == 0 {
+		return nil, 0, fmt.Errorf("asset not found")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	query := s.db.Model(&models.AssetHistory{}).Where("asset_id = ?", assetID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count asset history: %w", err)
+	}
+
+	var history []models.AssetHistory
+	if err := query.Preload("ChangedBy").
+		Order("changed_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&history).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get asset history: %w", err)
+	}
+	return history, total, nil
+}
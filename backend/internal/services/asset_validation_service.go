@@ -1,9 +1,11 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"net"
 
+	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
 	"gorm.io/gorm"
 )
@@ -18,6 +20,11 @@ func NewAssetValidationService(db *gorm.DB) *AssetValidationService {
 	return &AssetValidationService{db: db}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *AssetValidationService) WithContext(ctx context.Context) *AssetValidationService {
+	return &AssetValidationService{db: s.db.WithContext(ctx)}
+}
+
 // DuplicateCheckResult represents the result of a duplicate check
 type DuplicateCheckResult struct {
 	IsDuplicate     bool                    `json:"is_duplicate"`
@@ -65,6 +72,13 @@ func (s *AssetValidationService) ValidateCreate(asset *models.AffectedSystem) er
 		}
 	}
 
+	// Owner team must exist in the caller's organization
+	if asset.OwnerTeamID != nil {
+		if err := s.checkOwnerTeam(*asset.OwnerTeamID); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -91,6 +105,13 @@ func (s *AssetValidationService) ValidateUpdate(asset *models.AffectedSystem, up
 		}
 	}
 
+	// Validate the criticality override flag if being updated
+	if value, ok := updates["criticality_override"]; ok {
+		if _, isBool := value.(bool); !isBool {
+			return fmt.Errorf("invalid criticality_override value")
+		}
+	}
+
 	// Validate status enum if being updated
 	if status, ok := updates["status"].(string); ok {
 		stat := models.AssetStatus(status)
@@ -105,6 +126,34 @@ func (s *AssetValidationService) ValidateUpdate(asset *models.AffectedSystem, up
 		}
 	}
 
+	// Validate owner team if being updated; an empty value clears it
+	if value, ok := updates["owner_team_id"]; ok && value != nil {
+		teamID, _ := value.(string)
+		if teamID == "" {
+			updates["owner_team_id"] = nil
+		} else {
+			id, err := uuid.Parse(teamID)
+			if err != nil {
+				return fmt.Errorf("invalid owner_team_id format")
+			}
+			if err := s.checkOwnerTeam(id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkOwnerTeam verifies that an owner team exists
+func (s *AssetValidationService) checkOwnerTeam(teamID uuid.UUID) error {
+	var count int64
+	if err := s.db.Model(&models.Team{}).Where("id = ?", teamID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check owner team: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("owner team not found")
+	}
 	return nil
 }
 
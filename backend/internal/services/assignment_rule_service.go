@@ -0,0 +1,633 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Simulation limits for the number of vulnerabilities evaluated
+const (
+	defaultAssignmentSimulationLimit = 100
+	maxAssignmentSimulationLimit     = 1000
+)
+
+// AssignmentRuleService manages assignment rules and routes new vulnerabilities with them
+type AssignmentRuleService struct {
+	db *gorm.DB
+}
+
+// NewAssignmentRuleService creates a new assignment rule service
+func NewAssignmentRuleService(db *gorm.DB) *AssignmentRuleService {
+	return &AssignmentRuleService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *AssignmentRuleService) WithContext(ctx context.Context) *AssignmentRuleService {
+	return &AssignmentRuleService{db: s.db.WithContext(ctx)}
+}
+
+// AssignmentAsset describes an affected asset of a vulnerability that rules match against
+type AssignmentAsset struct {
+	ID          uuid.UUID
+	OwnerID     *uuid.UUID
+	OwnerTeamID *uuid.UUID
+	Environment models.Environment
+	Tags        []string
+}
+
+// AssignmentTarget describes the attributes of a vulnerability that rules match against
+type AssignmentTarget struct {
+	CVEID  string
+	Assets []AssignmentAsset
+}
+
+// Assignment is the analyst and team a rule routes a vulnerability to
+type Assignment struct {
+	Rule         *models.AssignmentRule
+	AssignedToID *uuid.UUID
+	OwnerTeamID  *uuid.UUID
+}
+
+// AssignmentMatcher evaluates vulnerabilities against a fixed, ordered set of enabled rules
+type AssignmentMatcher struct {
+	rules []models.AssignmentRule
+}
+
+// NewAssignmentMatcher keeps the enabled rules in the given order
+func NewAssignmentMatcher(rules []models.AssignmentRule) *AssignmentMatcher {
+	matcher := &AssignmentMatcher{}
+	for _, rule := range rules {
+		if rule.Enabled {
+			matcher.rules = append(matcher.rules, rule)
+		}
+	}
+	return matcher
+}
+
+// Len returns the number of active rules
+func (m *AssignmentMatcher) Len() int {
+	return len(m.rules)
+}
+
+// Match returns the assignment of the first rule that matches the target and assigns someone,
+// or nil. A rule assigning the asset owner is passed over when the matched asset has none.
+func (m *AssignmentMatcher) Match(target AssignmentTarget) *Assignment {
+	for i := range m.rules {
+		if assignment := matchAssignmentRule(&m.rules[i], target); assignment != nil {
+			return assignment
+		}
+	}
+	return nil
+}
+
+// matchAssignmentRule returns the assignment a rule makes for the target, or nil when a
+// criterion does not match or the rule would assign nobody
+func matchAssignmentRule(rule *models.AssignmentRule, target AssignmentTarget) *Assignment {
+	if rule.CVEPattern != "" {
+		matched, err := path.Match(strings.ToUpper(rule.CVEPattern), strings.ToUpper(target.CVEID))
+		if err != nil || !matched || target.CVEID == "" {
+			return nil
+		}
+	}
+
+	var asset *AssignmentAsset
+	if rule.AssetOwnerID != nil || rule.AssetTag != "" || rule.Environment != "" || rule.AssignAssetOwner {
+		for i := range target.Assets {
+			if assetMatchesAssignmentRule(rule, &target.Assets[i]) {
+				asset = &target.Assets[i]
+				break
+			}
+		}
+		if asset == nil {
+			return nil
+		}
+	} else if rule.CVEPattern == "" {
+		return nil // A rule without criteria would assign everything
+	}
+
+	assignment := &Assignment{Rule: rule, AssignedToID: rule.AssignToID, OwnerTeamID: rule.AssignTeamID}
+	if rule.AssignAssetOwner {
+		if assignment.AssignedToID == nil {
+			assignment.AssignedToID = asset.OwnerID
+		}
+		if assignment.OwnerTeamID == nil {
+			assignment.OwnerTeamID = asset.OwnerTeamID
+		}
+	}
+	if assignment.AssignedToID == nil && assignment.OwnerTeamID == nil {
+		return nil
+	}
+	return assignment
+}
+
+// assetMatchesAssignmentRule reports whether an asset matches every asset criterion of a rule.
+// Rules assigning the asset owner only match assets that have an owner or owner team.
+func assetMatchesAssignmentRule(rule *models.AssignmentRule, asset *AssignmentAsset) bool {
+	if rule.AssetOwnerID != nil && (asset.OwnerID == nil || *asset.OwnerID != *rule.AssetOwnerID) {
+		return false
+	}
+	if rule.Environment != "" && rule.Environment != asset.Environment {
+		return false
+	}
+	if rule.AssetTag != "" {
+		tagged := false
+		for _, tag := range asset.Tags {
+			if tag == rule.AssetTag {
+				tagged = true
+				break
+			}
+		}
+		if !tagged {
+			return false
+		}
+	}
+	if rule.AssignAssetOwner && asset.OwnerID == nil && asset.OwnerTeamID == nil {
+		return false
+	}
+	return true
+}
+
+// AssignmentRuleRequest represents a create or update assignment rule request
+type AssignmentRuleRequest struct {
+	Name             *string    `json:"name,omitempty"`
+	Description      *string    `json:"description,omitempty"`
+	Enabled          *bool      `json:"enabled,omitempty"`
+	Position         *int       `json:"position,omitempty"`
+	AssetOwnerID     *uuid.UUID `json:"asset_owner_id,omitempty"` // uuid.Nil clears the criterion
+	AssetTag         *string    `json:"asset_tag,omitempty"`
+	Environment      *string    `json:"environment,omitempty"`
+	CVEPattern       *string    `json:"cve_pattern,omitempty"`
+	AssignToID       *uuid.UUID `json:"assign_to_id,omitempty"`   // uuid.Nil clears the analyst
+	AssignTeamID     *uuid.UUID `json:"assign_team_id,omitempty"` // uuid.Nil clears the team
+	AssignAssetOwner *bool      `json:"assign_asset_owner,omitempty"`
+}
+
+// optionalID returns nil for uuid.Nil, which requests use to clear an ID
+func optionalID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
+
+// applyTo copies the provided request fields onto a rule
+func (req AssignmentRuleRequest) applyTo(rule *models.AssignmentRule) {
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		rule.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.Position != nil {
+		rule.Position = *req.Position
+	}
+	if req.AssetOwnerID != nil {
+		rule.AssetOwnerID = optionalID(*req.AssetOwnerID)
+	}
+	if req.AssetTag != nil {
+		rule.AssetTag = strings.ToLower(strings.TrimSpace(*req.AssetTag))
+	}
+	if req.Environment != nil {
+		rule.Environment = models.Environment(strings.ToUpper(strings.TrimSpace(*req.Environment)))
+	}
+	if req.CVEPattern != nil {
+		rule.CVEPattern = strings.ToUpper(strings.TrimSpace(*req.CVEPattern))
+	}
+	if req.AssignToID != nil {
+		rule.AssignToID = optionalID(*req.AssignToID)
+	}
+	if req.AssignTeamID != nil {
+		rule.AssignTeamID = optionalID(*req.AssignTeamID)
+	}
+	if req.AssignAssetOwner != nil {
+		rule.AssignAssetOwner = *req.AssignAssetOwner
+	}
+}
+
+// ValidateAssignmentRule checks that a rule has a name, at least one valid criterion and
+// someone to assign
+func ValidateAssignmentRule(rule *models.AssignmentRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if rule.AssetTag != "" {
+		if _, err := models.NormalizeTag(rule.AssetTag); err != nil {
+			return fmt.Errorf("invalid asset_tag: %v", err)
+		}
+	}
+	switch rule.Environment {
+	case "", models.EnvProduction, models.EnvStaging, models.EnvDevelopment, models.EnvTest:
+	default:
+		return fmt.Errorf("invalid environment, must be one of: PRODUCTION, STAGING, DEVELOPMENT, TEST")
+	}
+	if rule.CVEPattern != "" {
+		if _, err := path.Match(rule.CVEPattern, ""); err != nil {
+			return fmt.Errorf("invalid cve_pattern: %v", err)
+		}
+	}
+	if rule.AssetOwnerID == nil && rule.AssetTag == "" && rule.Environment == "" && rule.CVEPattern == "" && !rule.AssignAssetOwner {
+		return fmt.Errorf("invalid assignment rule, at least one criterion is required")
+	}
+	if rule.AssignToID == nil && rule.AssignTeamID == nil && !rule.AssignAssetOwner {
+		return fmt.Errorf("invalid assignment rule, assign_to_id, assign_team_id or assign_asset_owner is required")
+	}
+	return nil
+}
+
+// checkReferences checks that the users and team a rule refers to exist in the rule's organization
+func (s *AssignmentRuleService) checkReferences(rule *models.AssignmentRule) error {
+	db := s.db
+	if rule.OrgID != nil {
+		db = s.db.WithContext(tenant.WithOrg(s.db.Statement.Context, *rule.OrgID))
+	}
+
+	for field, id := range map[string]*uuid.UUID{"asset_owner_id": rule.AssetOwnerID, "assign_to_id": rule.AssignToID} {
+		if id == nil {
+			continue
+		}
+		var count int64
+		if err := db.Model(&models.User{}).Where("id = ?", *id).Count(&count).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("invalid %s, no such user", field)
+		}
+	}
+	if rule.AssignTeamID != nil {
+		if err := NewTeamService(db).Exists(*rule.AssignTeamID); err != nil {
+			return fmt.Errorf("invalid assign_team_id: %v", err)
+		}
+	}
+	return nil
+}
+
+// ListRules returns all assignment rules in evaluation order
+func (s *AssignmentRuleService) ListRules(enabledOnly bool) ([]models.AssignmentRule, error) {
+	query := s.db.Preload("AssignTo").Preload("AssignTeam").Preload("CreatedBy").Order("position, created_at")
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+
+	var rules []models.AssignmentRule
+	if err := query.Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list assignment rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetRule returns a single assignment rule
+func (s *AssignmentRuleService) GetRule(id uuid.UUID) (*models.AssignmentRule, error) {
+	var rule models.AssignmentRule
+	if err := s.db.Preload("AssetOwner").Preload("AssignTo").Preload("AssignTeam").Preload("CreatedBy").
+		First(&rule, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("assignment rule not found")
+		}
+		return nil, fmt.Errorf("failed to get assignment rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// CreateRule creates a new assignment rule; it applies to vulnerabilities created afterwards
+func (s *AssignmentRuleService) CreateRule(req AssignmentRuleRequest, createdByID uuid.UUID) (*models.AssignmentRule, error) {
+	rule := &models.AssignmentRule{
+		Enabled:     true,
+		CreatedByID: createdByID,
+	}
+	if orgID, ok := tenant.OrgFromContext(s.db.Statement.Context); ok {
+		rule.OrgID = &orgID
+	}
+	req.applyTo(rule)
+
+	if err := ValidateAssignmentRule(rule); err != nil {
+		return nil, err
+	}
+	if err := s.checkReferences(rule); err != nil {
+		return nil, err
+	}
+
+	if err := createWithZeroValues(s.db, rule, "enabled"); err != nil {
+		return nil, fmt.Errorf("failed to create assignment rule: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("rule_id", rule.ID.String()).
+		Str("created_by", createdByID.String()).
+		Msg("Assignment rule created")
+
+	return s.GetRule(rule.ID)
+}
+
+// UpdateRule updates an existing assignment rule; vulnerabilities it already routed keep their assignment
+func (s *AssignmentRuleService) UpdateRule(id uuid.UUID, req AssignmentRuleRequest, updatedByID uuid.UUID) (*models.AssignmentRule, error) {
+	rule, err := s.GetRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.applyTo(rule)
+	if err := ValidateAssignmentRule(rule); err != nil {
+		return nil, err
+	}
+	if err := s.checkReferences(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(rule).Select(
+		"name", "description", "enabled", "position", "asset_owner_id", "asset_tag", "environment",
+		"cve_pattern", "assign_to_id", "assign_team_id", "assign_asset_owner",
+	).Updates(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to update assignment rule: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("rule_id", id.String()).
+		Str("updated_by", updatedByID.String()).
+		Msg("Assignment rule updated")
+
+	return s.GetRule(id)
+}
+
+// DeleteRule soft deletes an assignment rule
+func (s *AssignmentRuleService) DeleteRule(id, deletedByID uuid.UUID) error {
+	result := s.db.Delete(&models.AssignmentRule{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete assignment rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("assignment rule not found")
+	}
+
+	utils.Logger.Info().
+		Str("rule_id", id.String()).
+		Str("deleted_by", deletedByID.String()).
+		Msg("Assignment rule deleted")
+
+	return nil
+}
+
+// LoadMatcher loads the enabled rules in evaluation order using the given transaction. Rules
+// are only those of the transaction's organization when its context is scoped to one.
+func (s *AssignmentRuleService) LoadMatcher(tx *gorm.DB) (*AssignmentMatcher, error) {
+	var rules []models.AssignmentRule
+	if err := tx.
+		Where("enabled = ?", true).
+		Order("position, created_at").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load assignment rules: %w", err)
+	}
+	return NewAssignmentMatcher(rules), nil
+}
+
+// loadAssignmentTargets loads the CVE IDs and affected assets, with their tags, of vulnerabilities
+func loadAssignmentTargets(tx *gorm.DB, vulnerabilities []*models.Vulnerability) (map[uuid.UUID]AssignmentTarget, error) {
+	ids := make([]uuid.UUID, len(vulnerabilities))
+	targets := make(map[uuid.UUID]AssignmentTarget, len(vulnerabilities))
+	for i, vulnerability := range vulnerabilities {
+		ids[i] = vulnerability.ID
+		targets[vulnerability.ID] = AssignmentTarget{CVEID: vulnerability.CVEID}
+	}
+
+	var rows []struct {
+		VulnerabilityID uuid.UUID
+		AssignmentAsset
+	}
+	if err := tx.Table("vulnerability_affected_systems").
+		Select("vulnerability_affected_systems.vulnerability_id, affected_systems.id, affected_systems.owner_id, "+
+			"affected_systems.owner_team_id, affected_systems.environment").
+		Joins("JOIN affected_systems ON affected_systems.id = vulnerability_affected_systems.affected_system_id AND affected_systems.deleted_at IS NULL").
+		Where("vulnerability_affected_systems.vulnerability_id IN ?", ids).
+		Order("affected_systems.created_at").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load affected assets: %w", err)
+	}
+	if len(rows) == 0 {
+		return targets, nil
+	}
+
+	assetIDs := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		assetIDs = append(assetIDs, row.ID)
+	}
+	var tags []models.AssetTag
+	if err := tx.Where("asset_id IN ?", assetIDs).Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to load asset tags: %w", err)
+	}
+	assetTags := make(map[uuid.UUID][]string)
+	for _, tag := range tags {
+		assetTags[tag.AssetID] = append(assetTags[tag.AssetID], tag.Tag)
+	}
+
+	for _, row := range rows {
+		asset := row.AssignmentAsset
+		asset.Tags = assetTags[asset.ID]
+		target := targets[row.VulnerabilityID]
+		target.Assets = append(target.Assets, asset)
+		targets[row.VulnerabilityID] = target
+	}
+	return targets, nil
+}
+
+// ApplyWithTx routes vulnerabilities that have just been created and linked to their assets.
+// Only the analyst and team the vulnerability was created without are filled in. Returns
+// the number of vulnerabilities assigned.
+func (s *AssignmentRuleService) ApplyWithTx(tx *gorm.DB, matcher *AssignmentMatcher, vulnerabilities []*models.Vulnerability, actorID uuid.UUID) (int, error) {
+	if matcher == nil || matcher.Len() == 0 {
+		return 0, nil
+	}
+
+	var unassigned []*models.Vulnerability
+	for _, vulnerability := range vulnerabilities {
+		if vulnerability.AssignedToID == nil || vulnerability.OwnerTeamID == nil {
+			unassigned = append(unassigned, vulnerability)
+		}
+	}
+	if len(unassigned) == 0 {
+		return 0, nil
+	}
+
+	targets, err := loadAssignmentTargets(tx, unassigned)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	assigned := 0
+	hits := make(map[uuid.UUID]int64)
+	var histories []*models.VulnerabilityAssignmentHistory
+	for _, vulnerability := range unassigned {
+		assignment := matcher.Match(targets[vulnerability.ID])
+		if assignment == nil {
+			continue
+		}
+
+		updates := map[string]interface{}{"assignment_rule_id": assignment.Rule.ID}
+		if vulnerability.AssignedToID == nil && assignment.AssignedToID != nil {
+			vulnerability.AssignedToID = assignment.AssignedToID
+			updates["assigned_to_id"] = assignment.AssignedToID
+			histories = append(histories, &models.VulnerabilityAssignmentHistory{
+				VulnerabilityID: vulnerability.ID,
+				NewAssigneeID:   assignment.AssignedToID,
+				ChangedByID:     actorID,
+				ChangedAt:       now,
+			})
+		}
+		if vulnerability.OwnerTeamID == nil && assignment.OwnerTeamID != nil {
+			vulnerability.OwnerTeamID = assignment.OwnerTeamID
+			updates["owner_team_id"] = assignment.OwnerTeamID
+		}
+		if len(updates) == 1 {
+			continue // The rule only names what the vulnerability already has
+		}
+
+		ruleID := assignment.Rule.ID
+		vulnerability.AssignmentRuleID = &ruleID
+		if err := tx.Model(&models.Vulnerability{}).Where("id = ?", vulnerability.ID).Updates(updates).Error; err != nil {
+			return 0, fmt.Errorf("failed to assign vulnerability: %w", err)
+		}
+		hits[ruleID]++
+		assigned++
+	}
+
+	if len(histories) > 0 {
+		if err := tx.CreateInBatches(histories, importBatchSize).Error; err != nil {
+			return 0, fmt.Errorf("failed to record assignment history: %w", err)
+		}
+	}
+	for ruleID, count := range hits {
+		if err := tx.Model(&models.AssignmentRule{}).
+			Where("id = ?", ruleID).
+			Updates(map[string]interface{}{
+				"hit_count":   gorm.Expr("hit_count + ?", count),
+				"last_hit_at": now,
+			}).Error; err != nil {
+			return 0, fmt.Errorf("failed to update rule hit count: %w", err)
+		}
+	}
+
+	if assigned > 0 {
+		utils.Logger.Info().
+			Int("assigned", assigned).
+			Int("rules_hit", len(hits)).
+			Msg("Vulnerabilities routed by assignment rules")
+	}
+	return assigned, nil
+}
+
+// AssignmentSimulationRequest selects the vulnerabilities a dry run evaluates and, optionally,
+// an unsaved rule to evaluate instead of the enabled rules
+type AssignmentSimulationRequest struct {
+	Rule             *AssignmentRuleRequest `json:"rule,omitempty"`
+	VulnerabilityIDs []uuid.UUID            `json:"vulnerability_ids,omitempty"` // Defaults to the latest open vulnerabilities
+	Limit            int                    `json:"limit,omitempty"`
+}
+
+// AssignmentSimulationMatch is where a dry run would route one vulnerability
+type AssignmentSimulationMatch struct {
+	VulnerabilityID     uuid.UUID  `json:"vulnerability_id"`
+	Title               string     `json:"title"`
+	CVEID               string     `json:"cve_id,omitempty"`
+	RuleID              *uuid.UUID `json:"rule_id,omitempty"` // Unset for an unsaved rule
+	RuleName            string     `json:"rule_name"`
+	AssignedToID        *uuid.UUID `json:"assigned_to_id,omitempty"`
+	OwnerTeamID         *uuid.UUID `json:"owner_team_id,omitempty"`
+	CurrentAssignedToID *uuid.UUID `json:"current_assigned_to_id,omitempty"`
+	CurrentOwnerTeamID  *uuid.UUID `json:"current_owner_team_id,omitempty"`
+}
+
+// AssignmentSimulationResult summarizes a dry run of assignment rules
+type AssignmentSimulationResult struct {
+	Evaluated int                         `json:"evaluated"`
+	Matched   int                         `json:"matched"`
+	Matches   []AssignmentSimulationMatch `json:"matches"`
+}
+
+// Simulate evaluates assignment rules against existing vulnerabilities as if they were new,
+// without assigning anything
+func (s *AssignmentRuleService) Simulate(req AssignmentSimulationRequest) (*AssignmentSimulationResult, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultAssignmentSimulationLimit
+	}
+	if limit > maxAssignmentSimulationLimit {
+		return nil, fmt.Errorf("invalid limit, must be at most %d", maxAssignmentSimulationLimit)
+	}
+	if len(req.VulnerabilityIDs) > maxAssignmentSimulationLimit {
+		return nil, fmt.Errorf("invalid vulnerability_ids, at most %d can be simulated", maxAssignmentSimulationLimit)
+	}
+
+	var matcher *AssignmentMatcher
+	if req.Rule != nil {
+		rule := &models.AssignmentRule{Enabled: true}
+		req.Rule.applyTo(rule)
+		if rule.Name == "" {
+			rule.Name = "Simulated rule"
+		}
+		if err := ValidateAssignmentRule(rule); err != nil {
+			return nil, err
+		}
+		rule.Enabled = true
+		matcher = NewAssignmentMatcher([]models.AssignmentRule{*rule})
+	} else {
+		var err error
+		if matcher, err = s.LoadMatcher(s.db); err != nil {
+			return nil, err
+		}
+	}
+
+	query := s.db.Model(&models.Vulnerability{}).Select("id, title, cve_id, assigned_to_id, owner_team_id")
+	if len(req.VulnerabilityIDs) > 0 {
+		query = query.Where("id IN ?", req.VulnerabilityIDs)
+	} else {
+		query = query.Where("status IN ?", openStatuses).Order("created_at DESC").Limit(limit)
+	}
+	var vulnerabilities []*models.Vulnerability
+	if err := query.Find(&vulnerabilities).Error; err != nil {
+		return nil, fmt.Errorf("failed to load vulnerabilities: %w", err)
+	}
+
+	result := &AssignmentSimulationResult{Evaluated: len(vulnerabilities), Matches: []AssignmentSimulationMatch{}}
+	if len(vulnerabilities) == 0 || matcher.Len() == 0 {
+		return result, nil
+	}
+	targets, err := loadAssignmentTargets(s.db, vulnerabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vulnerability := range vulnerabilities {
+		assignment := matcher.Match(targets[vulnerability.ID])
+		if assignment == nil {
+			continue
+		}
+		match := AssignmentSimulationMatch{
+			VulnerabilityID:     vulnerability.ID,
+			Title:               vulnerability.Title,
+			CVEID:               vulnerability.CVEID,
+			RuleName:            assignment.Rule.Name,
+			AssignedToID:        assignment.AssignedToID,
+			OwnerTeamID:         assignment.OwnerTeamID,
+			CurrentAssignedToID: vulnerability.AssignedToID,
+			CurrentOwnerTeamID:  vulnerability.OwnerTeamID,
+		}
+		if assignment.Rule.ID != uuid.Nil {
+			ruleID := assignment.Rule.ID
+			match.RuleID = &ruleID
+		}
+		result.Matches = append(result.Matches, match)
+	}
+	result.Matched = len(result.Matches)
+	return result, nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/storage"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// AttachmentIntegrityStatus is the outcome of verifying a stored attachment against its recorded hash
+type AttachmentIntegrityStatus string
+
+const (
+	AttachmentIntact   AttachmentIntegrityStatus = "INTACT"
+	AttachmentModified AttachmentIntegrityStatus = "MODIFIED"
+	AttachmentMissing  AttachmentIntegrityStatus = "MISSING"
+	AttachmentUnhashed AttachmentIntegrityStatus = "UNHASHED" // Uploaded before hashes were recorded
+)
+
+// AttachmentIntegrityResult reports whether an attachment still matches the hash recorded at upload
+type AttachmentIntegrityResult struct {
+	AttachmentID   uuid.UUID                 `json:"attachment_id"`
+	FindingID      uuid.UUID                 `json:"finding_id"`
+	Status         AttachmentIntegrityStatus `json:"status"`
+	ExpectedSHA256 string                    `json:"expected_sha256,omitempty"`
+	ActualSHA256   string                    `json:"actual_sha256,omitempty"`
+	OriginalSHA256 string                    `json:"original_sha256,omitempty"`
+	UploadedBy     uuid.UUID                 `json:"uploaded_by"`
+	UploadedAt     time.Time                 `json:"uploaded_at"`
+	VerifiedAt     time.Time                 `json:"verified_at"`
+}
+
+// CheckAttachmentIntegrity compares stored file contents with the hash recorded at upload.
+// readErr is the error from reading the stored file; any error counts as a missing file.
+func CheckAttachmentIntegrity(expectedSHA256 string, data []byte, readErr error) (AttachmentIntegrityStatus, string) {
+	if readErr != nil {
+		return AttachmentMissing, ""
+	}
+	actual := sha256Hex(data)
+	switch {
+	case expectedSHA256 == "":
+		return AttachmentUnhashed, actual
+	case actual != expectedSHA256:
+		return AttachmentModified, actual
+	}
+	return AttachmentIntact, actual
+}
+
+// VerifyIntegrity re-hashes an attachment's stored file, compares it with the hash recorded at
+// upload and appends the outcome to the attachment's chain of custody
+func (s *FindingAttachmentService) VerifyIntegrity(attachment *models.FindingAttachment, verifiedBy uuid.UUID) (*AttachmentIntegrityResult, error) {
+	data, readErr := readStoredFile(attachment.StorageBackend, s.objectKey(attachment.StoragePath))
+	if readErr != nil && !errors.Is(readErr, storage.ErrNotFound) {
+		// An unreachable backend says nothing about the evidence, so nothing is recorded
+		return nil, readErr
+	}
+
+	status, actual := CheckAttachmentIntegrity(attachment.SHA256, data, readErr)
+	result := &AttachmentIntegrityResult{
+		AttachmentID:   attachment.ID,
+		FindingID:      attachment.FindingID,
+		Status:         status,
+		ExpectedSHA256: attachment.SHA256,
+		ActualSHA256:   actual,
+		OriginalSHA256: attachment.OriginalSHA256,
+		UploadedBy:     attachment.UploadedBy,
+		UploadedAt:     attachment.CreatedAt,
+		VerifiedAt:     time.Now(),
+	}
+
+	action := models.AttachmentCustodyVerified
+	notes := ""
+	switch status {
+	case AttachmentModified:
+		action = models.AttachmentCustodyModified
+		notes = fmt.Sprintf("expected sha256 %s", attachment.SHA256)
+	case AttachmentMissing:
+		action = models.AttachmentCustodyMissing
+		notes = readErr.Error()
+	case AttachmentUnhashed:
+		notes = "no hash was recorded at upload"
+	}
+	if err := recordCustodyEvent(s.db, attachment, action, actual, notes, &verifiedBy); err != nil {
+		return nil, err
+	}
+
+	if status == AttachmentModified || status == AttachmentMissing {
+		utils.Logger.Warn().
+			Str("attachment_id", attachment.ID.String()).
+			Str("finding_id", attachment.FindingID.String()).
+			Str("status", string(status)).
+			Msg("Attachment failed integrity verification")
+	}
+
+	return result, nil
+}
+
+// GetCustodyChain returns an attachment's chain of custody, oldest event first. It also works
+// for deleted attachments, whose events are kept.
+func (s *FindingAttachmentService) GetCustodyChain(attachmentID uuid.UUID) ([]models.AttachmentCustodyEvent, error) {
+	var events []models.AttachmentCustodyEvent
+	if err := s.visibleFindings(s.db.Preload("Actor")).
+		Where("attachment_id = ?", attachmentID).
+		Order("created_at ASC").
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to load custody chain: %w", err)
+	}
+	return events, nil
+}
+
+// recordCustodyEvent appends an event to an attachment's chain of custody
+func recordCustodyEvent(db *gorm.DB, attachment *models.FindingAttachment, action models.AttachmentCustodyAction, observedSHA256, notes string, actorID *uuid.UUID) error {
+	event := &models.AttachmentCustodyEvent{
+		AttachmentID: attachment.ID,
+		FindingID:    attachment.FindingID,
+		Action:       action,
+		SHA256:       observedSHA256,
+		Notes:        notes,
+		ActorID:      actorID,
+	}
+	if err := db.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to record custody event: %w", err)
+	}
+	return nil
+}
+
+// sha256Hex returns the hex-encoded SHA-256 digest of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// AttachmentCategoryReport is the policy category of assessment report uploads. Finding and
+// vulnerability attachments use their attachment type (PROOF, REMEDIATION, ...) as category.
+const AttachmentCategoryReport = "REPORT"
+
+// MaxAttachmentSizeBytes caps any category's configured limit, and bounds the bodies of upload routes
+const MaxAttachmentSizeBytes = 500 * 1024 * 1024
+
+// attachmentCategories lists every category a policy can configure
+var attachmentCategories = map[string]bool{
+	models.AttachmentTypeProof:             true,
+	models.AttachmentTypeRemediation:       true,
+	models.AttachmentTypeVerification:      true,
+	models.VulnAttachmentTypeDocumentation: true,
+	models.AttachmentTypeOther:             true,
+	AttachmentCategoryReport:               true,
+}
+
+// AttachmentPolicy limits the size and MIME type of uploads per attachment category. It is stored
+// as JSON in the attachment_policy system setting.
+type AttachmentPolicy struct {
+	Categories map[string]AttachmentCategoryPolicy `json:"categories"`
+}
+
+// AttachmentCategoryPolicy is the upload limit of one attachment category. MIME types may end
+// in "/*" to allow a whole family (e.g. image/*).
+type AttachmentCategoryPolicy struct {
+	MaxSizeBytes     int64    `json:"max_size_bytes"`
+	AllowedMimeTypes []string `json:"allowed_mime_types"`
+}
+
+// DefaultAttachmentPolicy is the policy used until an administrator configures one
+func DefaultAttachmentPolicy() AttachmentPolicy {
+	evidence := AttachmentCategoryPolicy{
+		MaxSizeBytes: 10 * 1024 * 1024,
+		AllowedMimeTypes: []string{
+			"image/jpeg", "image/png", "image/gif", "image/webp", "image/bmp",
+			"application/pdf", "text/plain",
+		},
+	}
+	documents := AttachmentCategoryPolicy{
+		MaxSizeBytes: 10 * 1024 * 1024,
+		AllowedMimeTypes: append(append([]string{}, evidence.AllowedMimeTypes...),
+			"application/msword",
+			"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		),
+	}
+
+	return AttachmentPolicy{Categories: map[string]AttachmentCategoryPolicy{
+		models.AttachmentTypeProof:             evidence,
+		models.AttachmentTypeRemediation:       evidence,
+		models.AttachmentTypeVerification:      evidence,
+		models.VulnAttachmentTypeDocumentation: documents,
+		models.AttachmentTypeOther:             documents,
+		AttachmentCategoryReport: {
+			MaxSizeBytes:     100 * 1024 * 1024,
+			AllowedMimeTypes: []string{"application/pdf"},
+		},
+	}}
+}
+
+// ValidateAttachmentPolicy normalizes category names and MIME types and checks every limit.
+// Categories the policy leaves out keep their default limits.
+func ValidateAttachmentPolicy(policy *AttachmentPolicy) error {
+	defaults := DefaultAttachmentPolicy()
+	categories := make(map[string]AttachmentCategoryPolicy, len(defaults.Categories))
+	for category, limits := range defaults.Categories {
+		categories[category] = limits
+	}
+
+	for name, limits := range policy.Categories {
+		category := strings.ToUpper(strings.TrimSpace(name))
+		if !attachmentCategories[category] {
+			return fmt.Errorf("invalid category: %s", name)
+		}
+		if limits.MaxSizeBytes < 1 || limits.MaxSizeBytes > MaxAttachmentSizeBytes {
+			return fmt.Errorf("invalid max_size_bytes for %s: must be between 1 and %d", category, MaxAttachmentSizeBytes)
+		}
+		if len(limits.AllowedMimeTypes) == 0 {
+			return fmt.Errorf("allowed_mime_types is required for %s", category)
+		}
+
+		seen := make(map[string]bool, len(limits.AllowedMimeTypes))
+		mimeTypes := make([]string, 0, len(limits.AllowedMimeTypes))
+		for _, mimeType := range limits.AllowedMimeTypes {
+			mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+			parts := strings.Split(mimeType, "/")
+			if len(parts) != 2 || parts[0] == "" || parts[0] == "*" || parts[1] == "" {
+				return fmt.Errorf("invalid mime type for %s: %q", category, mimeType)
+			}
+			if !seen[mimeType] {
+				seen[mimeType] = true
+				mimeTypes = append(mimeTypes, mimeType)
+			}
+		}
+		sort.Strings(mimeTypes)
+
+		categories[category] = AttachmentCategoryPolicy{
+			MaxSizeBytes:     limits.MaxSizeBytes,
+			AllowedMimeTypes: mimeTypes,
+		}
+	}
+
+	policy.Categories = categories
+	return nil
+}
+
+// ParseAttachmentPolicy parses and validates an attachment_policy setting value
+func ParseAttachmentPolicy(value string) (*AttachmentPolicy, error) {
+	var policy AttachmentPolicy
+	if err := json.Unmarshal([]byte(value), &policy); err != nil {
+		return nil, fmt.Errorf("invalid attachment policy: %v", err)
+	}
+	if err := ValidateAttachmentPolicy(&policy); err != nil {
+		return nil, fmt.Errorf("invalid attachment policy: %v", err)
+	}
+	return &policy, nil
+}
+
+// LoadAttachmentPolicy returns the configured attachment policy, falling back to the defaults
+// when the setting is missing or unreadable so uploads never become unrestricted
+func LoadAttachmentPolicy(db *gorm.DB) AttachmentPolicy {
+	var setting models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingAttachmentPolicy)).First(&setting).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.Logger.Warn().Err(err).Msg("Failed to load attachment policy, using defaults")
+		}
+		return DefaultAttachmentPolicy()
+	}
+
+	policy, err := ParseAttachmentPolicy(setting.Value)
+	if err != nil {
+		utils.Logger.Warn().Err(err).Msg("Stored attachment policy is invalid, using defaults")
+		return DefaultAttachmentPolicy()
+	}
+	return *policy
+}
+
+// AttachmentPolicyError is returned when an upload violates the attachment policy
+type AttachmentPolicyError struct {
+	Reason string
+}
+
+func (e *AttachmentPolicyError) Error() string {
+	return e.Reason
+}
+
+// CheckSize reports whether an upload of the given category and size is allowed, so oversized
+// files can be rejected before they are read
+func (p AttachmentPolicy) CheckSize(category string, size int64) error {
+	limits, ok := p.Categories[category]
+	if !ok {
+		return &AttachmentPolicyError{Reason: fmt.Sprintf("invalid attachment category: %s", category)}
+	}
+	if size > limits.MaxSizeBytes {
+		return &AttachmentPolicyError{Reason: fmt.Sprintf("file size exceeds maximum allowed size of %s for %s attachments", formatAttachmentSize(limits.MaxSizeBytes), category)}
+	}
+	return nil
+}
+
+// CheckAttachment reports whether an upload of the given category, MIME type and size is allowed
+func (p AttachmentPolicy) CheckAttachment(category, mimeType string, size int64) error {
+	if err := p.CheckSize(category, size); err != nil {
+		return err
+	}
+
+	mimeType = strings.ToLower(mimeType)
+	for _, allowed := range p.Categories[category].AllowedMimeTypes {
+		if allowed == mimeType || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(allowed, "*"))) {
+			return nil
+		}
+	}
+	return &AttachmentPolicyError{Reason: fmt.Sprintf("file type %s is not allowed for %s attachments", mimeType, category)}
+}
+
+// IsAttachmentPolicyViolation reports whether an upload error comes from the attachment policy
+func IsAttachmentPolicyViolation(err error) bool {
+	var policyErr *AttachmentPolicyError
+	return errors.As(err, &policyErr)
+}
+
+// DetectAttachmentMimeType determines an upload's MIME type from its content, trusting the
+// client-declared type only where content sniffing cannot tell formats apart (plain text
+// variants, zip-based Office documents, unrecognized binaries)
+func DetectAttachmentMimeType(data []byte, declared string) string {
+	declared = baseMimeType(declared)
+	sniffed := baseMimeType(http.DetectContentType(data))
+
+	switch sniffed {
+	case "application/octet-stream":
+		// Images and PDFs are always recognized by sniffing, so a claim to be one is false
+		if declared != "" && !strings.HasPrefix(declared, "image/") && declared != "application/pdf" {
+			return declared
+		}
+	case "application/zip":
+		if strings.HasPrefix(declared, "application/vnd.openxmlformats-officedocument.") {
+			return declared
+		}
+	case "text/plain":
+		if strings.HasPrefix(declared, "text/") && declared != "text/html" {
+			return declared
+		}
+		if declared == "application/json" || declared == "application/xml" {
+			return declared
+		}
+	}
+	return sniffed
+}
+
+// baseMimeType strips parameters (e.g. charset) from a MIME type and lowercases it
+func baseMimeType(value string) string {
+	if mediaType, _, err := mime.ParseMediaType(value); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(strings.TrimSpace(strings.Split(value, ";")[0]))
+}
+
+// formatAttachmentSize renders a byte limit for error messages
+func formatAttachmentSize(bytes int64) string {
+	switch {
+	case bytes >= 1024*1024 && bytes%(1024*1024) == 0:
+		return fmt.Sprintf("%d MB", bytes/(1024*1024))
+	case bytes >= 1024 && bytes%1024 == 0:
+		return fmt.Sprintf("%d KB", bytes/1024)
+	}
+	return fmt.Sprintf("%d bytes", bytes)
+}
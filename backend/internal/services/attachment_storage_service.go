@@ -0,0 +1,385 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/storage"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Object key areas for each kind of stored file
+const (
+	storageAreaFindingAttachments       = "finding-attachments"
+	storageAreaVulnerabilityAttachments = "vulnerability-attachments"
+	storageAreaAssessmentReports        = "assessment-reports"
+)
+
+const (
+	defaultPresignTTLSeconds = 15 * 60
+	maxPresignTTLSeconds     = 7 * 24 * 60 * 60
+)
+
+// AttachmentStorageSettings selects and configures the attachment storage backend. It is stored
+// as JSON in the attachment_storage system setting; credentials come from the environment so
+// they are never returned by the settings API.
+type AttachmentStorageSettings struct {
+	Driver            string                   `json:"driver"` // local, s3 or azure
+	PresignTTLSeconds int                      `json:"presign_ttl_seconds,omitempty"`
+	S3                *AttachmentS3Settings    `json:"s3,omitempty"`
+	Azure             *AttachmentAzureSettings `json:"azure,omitempty"`
+}
+
+// AttachmentS3Settings configures an S3 or MinIO bucket
+type AttachmentS3Settings struct {
+	Endpoint  string `json:"endpoint,omitempty"` // Defaults to AWS; set for MinIO
+	Region    string `json:"region,omitempty"`
+	Bucket    string `json:"bucket"`
+	Prefix    string `json:"prefix,omitempty"`
+	PathStyle bool   `json:"path_style,omitempty"` // Required by MinIO
+}
+
+// AttachmentAzureSettings configures an Azure Blob Storage container
+type AttachmentAzureSettings struct {
+	Account   string `json:"account"`
+	Container string `json:"container"`
+	Prefix    string `json:"prefix,omitempty"`
+	Endpoint  string `json:"endpoint,omitempty"` // Defaults to the account's blob endpoint; set for Azurite
+}
+
+// AttachmentStorageCredentials are the storage secrets read from the environment
+type AttachmentStorageCredentials struct {
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	AzureAccountKey   string
+}
+
+// AttachmentStorage is a configured store and its presigned URL lifetime
+type AttachmentStorage struct {
+	Store      storage.Store
+	PresignTTL time.Duration
+}
+
+var (
+	attachmentStorageMu          sync.RWMutex
+	activeAttachmentStorage      *AttachmentStorage
+	attachmentStorageCredentials AttachmentStorageCredentials
+)
+
+// ValidateAttachmentStorageSettings checks the driver and its required options and fills in defaults
+func ValidateAttachmentStorageSettings(settings *AttachmentStorageSettings) error {
+	settings.Driver = strings.ToLower(strings.TrimSpace(settings.Driver))
+	if settings.Driver == "" {
+		settings.Driver = storage.DriverLocal
+	}
+	if settings.PresignTTLSeconds == 0 {
+		settings.PresignTTLSeconds = defaultPresignTTLSeconds
+	}
+	if settings.PresignTTLSeconds < 1 || settings.PresignTTLSeconds > maxPresignTTLSeconds {
+		return fmt.Errorf("invalid presign_ttl_seconds: must be between 1 and %d", maxPresignTTLSeconds)
+	}
+
+	switch settings.Driver {
+	case storage.DriverLocal:
+	case storage.DriverS3:
+		if settings.S3 == nil || strings.TrimSpace(settings.S3.Bucket) == "" {
+			return fmt.Errorf("s3.bucket is required for the s3 driver")
+		}
+	case storage.DriverAzure:
+		if settings.Azure == nil || strings.TrimSpace(settings.Azure.Account) == "" || strings.TrimSpace(settings.Azure.Container) == "" {
+			return fmt.Errorf("azure.account and azure.container are required for the azure driver")
+		}
+	default:
+		return fmt.Errorf("invalid driver: %s", settings.Driver)
+	}
+	return nil
+}
+
+// BuildAttachmentStorage parses an attachment_storage setting value and creates its store
+func BuildAttachmentStorage(value string) (*AttachmentStorage, error) {
+	var settings AttachmentStorageSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return nil, fmt.Errorf("invalid attachment storage settings: %v", err)
+	}
+	if err := ValidateAttachmentStorageSettings(&settings); err != nil {
+		return nil, fmt.Errorf("invalid attachment storage settings: %v", err)
+	}
+
+	attachmentStorageMu.RLock()
+	creds := attachmentStorageCredentials
+	attachmentStorageMu.RUnlock()
+
+	cfg := storage.Config{
+		Driver: settings.Driver,
+		Local:  storage.LocalConfig{Root: AttachmentStoreDir},
+	}
+	if settings.S3 != nil {
+		cfg.S3 = storage.S3Config{
+			Endpoint:        settings.S3.Endpoint,
+			Region:          settings.S3.Region,
+			Bucket:          settings.S3.Bucket,
+			Prefix:          settings.S3.Prefix,
+			PathStyle:       settings.S3.PathStyle,
+			AccessKeyID:     creds.S3AccessKeyID,
+			SecretAccessKey: creds.S3SecretAccessKey,
+		}
+	}
+	if settings.Azure != nil {
+		cfg.Azure = storage.AzureConfig{
+			Account:    settings.Azure.Account,
+			AccountKey: creds.AzureAccountKey,
+			Container:  settings.Azure.Container,
+			Prefix:     settings.Azure.Prefix,
+			Endpoint:   settings.Azure.Endpoint,
+		}
+	}
+
+	store, err := storage.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attachment storage settings: %v", err)
+	}
+	return &AttachmentStorage{
+		Store:      store,
+		PresignTTL: time.Duration(settings.PresignTTLSeconds) * time.Second,
+	}, nil
+}
+
+// InitAttachmentStorage records the storage credentials and activates the configured backend.
+// The local store stays active when the setting is missing or invalid.
+func InitAttachmentStorage(db *gorm.DB, creds AttachmentStorageCredentials) error {
+	attachmentStorageMu.Lock()
+	attachmentStorageCredentials = creds
+	attachmentStorageMu.Unlock()
+
+	return ReloadAttachmentStorage(db)
+}
+
+// ReloadAttachmentStorage re-reads the attachment_storage setting and activates its backend,
+// picking up changes made through another instance
+func ReloadAttachmentStorage(db *gorm.DB) error {
+	previous := ActiveAttachmentStorage().Store.Driver()
+	defer func() {
+		if current := ActiveAttachmentStorage().Store.Driver(); current != previous {
+			utils.Logger.Info().Str("from", previous).Str("to", current).Msg("Attachment storage backend changed")
+		}
+	}()
+
+	var setting models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingAttachmentStorage)).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		SetActiveAttachmentStorage(nil)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load attachment storage settings: %w", err)
+	}
+
+	attachmentStorage, err := BuildAttachmentStorage(setting.Value)
+	if err != nil {
+		return err
+	}
+	SetActiveAttachmentStorage(attachmentStorage)
+	return nil
+}
+
+// SetActiveAttachmentStorage switches the backend new files are written to (nil restores local disk)
+func SetActiveAttachmentStorage(attachmentStorage *AttachmentStorage) {
+	attachmentStorageMu.Lock()
+	defer attachmentStorageMu.Unlock()
+	activeAttachmentStorage = attachmentStorage
+}
+
+// ActiveAttachmentStorage returns the backend new files are written to
+func ActiveAttachmentStorage() *AttachmentStorage {
+	attachmentStorageMu.RLock()
+	defer attachmentStorageMu.RUnlock()
+
+	if activeAttachmentStorage == nil {
+		return &AttachmentStorage{
+			Store:      storage.NewLocalStore(AttachmentStoreDir),
+			PresignTTL: defaultPresignTTLSeconds * time.Second,
+		}
+	}
+	return activeAttachmentStorage
+}
+
+// attachmentStoreFor returns the store holding files written with the given driver. Files stay
+// readable from local disk after switching to an object store until they are migrated.
+func attachmentStoreFor(driver string) (storage.Store, error) {
+	active := ActiveAttachmentStorage()
+	if driver == "" {
+		driver = storage.DriverLocal
+	}
+	switch {
+	case active.Store.Driver() == driver:
+		return active.Store, nil
+	case driver == storage.DriverLocal:
+		return storage.NewLocalStore(AttachmentStoreDir), nil
+	}
+	return nil, fmt.Errorf("attachment storage backend %s is not configured", driver)
+}
+
+// attachmentObjectKey builds the object key of a stored file from its area and relative storage path
+func attachmentObjectKey(area, storagePath string) string {
+	return area + "/" + filepath.ToSlash(storagePath)
+}
+
+// readStoredFile reads a stored file from the backend it was written to
+func readStoredFile(driver, key string) ([]byte, error) {
+	store, err := attachmentStoreFor(driver)
+	if err != nil {
+		return nil, err
+	}
+	data, err := store.Get(context.Background(), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return data, nil
+}
+
+// presignStoredFile returns a time-limited download URL for a stored file and its expiry
+func presignStoredFile(driver, key, filename string) (string, time.Time, error) {
+	store, err := attachmentStoreFor(driver)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	ttl := ActiveAttachmentStorage().PresignTTL
+	url, err := store.PresignGet(context.Background(), key, filename, ttl)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return url, time.Now().Add(ttl), nil
+}
+
+// AttachmentStorageMigrationResult summarizes a storage migration run
+type AttachmentStorageMigrationResult struct {
+	Target  string `json:"target"`
+	Pending int    `json:"pending"` // Files found on other backends
+	Moved   int    `json:"moved"`
+	Failed  int    `json:"failed"`
+	DryRun  bool   `json:"dry_run"`
+}
+
+// storedFileRecord is the storage location of an attachment or report row
+type storedFileRecord struct {
+	ID             uuid.UUID
+	StoragePath    string
+	ThumbnailPath  string
+	StorageBackend string
+	MimeType       string
+}
+
+// storedFileTables lists the tables holding stored files and the key area of each
+var storedFileTables = []struct {
+	table        string
+	area         string
+	hasThumbnail bool
+}{
+	{"finding_attachments", storageAreaFindingAttachments, true},
+	{"vulnerability_attachments", storageAreaVulnerabilityAttachments, true},
+	{"assessment_reports", storageAreaAssessmentReports, false},
+}
+
+// MigrateAttachmentStorage copies every attachment and report stored on another backend into
+// the active one and repoints its row. Source files are removed only when deleteSource is set,
+// so a failed or partial run can simply be repeated.
+func MigrateAttachmentStorage(ctx context.Context, db *gorm.DB, dryRun, deleteSource bool) (*AttachmentStorageMigrationResult, error) {
+	target := ActiveAttachmentStorage().Store
+	result := &AttachmentStorageMigrationResult{Target: target.Driver(), DryRun: dryRun}
+
+	for _, t := range storedFileTables {
+		columns := "id, storage_path, storage_backend, mime_type, '' AS thumbnail_path"
+		if t.hasThumbnail {
+			columns = "id, storage_path, storage_backend, mime_type, thumbnail_path"
+		}
+
+		var records []storedFileRecord
+		if err := db.WithContext(ctx).Table(t.table).
+			Select(columns).
+			Where("storage_backend <> ?", target.Driver()).
+			Order("id").
+			Scan(&records).Error; err != nil {
+			return result, fmt.Errorf("failed to list %s: %w", t.table, err)
+		}
+		result.Pending += len(records)
+		if dryRun {
+			continue
+		}
+
+		for _, record := range records {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			if err := migrateStoredFile(ctx, db, t.table, t.area, record, target, deleteSource); err != nil {
+				result.Failed++
+				utils.Logger.Error().Err(err).Str("table", t.table).Str("id", record.ID.String()).Msg("Failed to migrate stored file")
+				continue
+			}
+			result.Moved++
+		}
+	}
+
+	utils.Logger.Info().
+		Str("target", result.Target).
+		Int("pending", result.Pending).
+		Int("moved", result.Moved).
+		Int("failed", result.Failed).
+		Bool("dry_run", dryRun).
+		Msg("Attachment storage migration finished")
+
+	return result, nil
+}
+
+// migrateStoredFile copies one row's file (and thumbnail) to the target store and repoints the row
+func migrateStoredFile(ctx context.Context, db *gorm.DB, table, area string, record storedFileRecord, target storage.Store, deleteSource bool) error {
+	source, err := attachmentStoreFor(record.StorageBackend)
+	if err != nil {
+		return err
+	}
+
+	paths := []string{record.StoragePath}
+	if record.ThumbnailPath != "" {
+		paths = append(paths, record.ThumbnailPath)
+	}
+	for i, storagePath := range paths {
+		key := attachmentObjectKey(area, storagePath)
+		data, err := source.Get(ctx, key)
+		if errors.Is(err, storage.ErrNotFound) && i > 0 {
+			continue // Skip missing thumbnails rather than blocking the original
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		contentType := record.MimeType
+		if i > 0 {
+			contentType = "image/jpeg"
+		}
+		if err := target.Put(ctx, key, data, contentType); err != nil {
+			return fmt.Errorf("failed to write %s: %w", key, err)
+		}
+	}
+
+	if err := db.WithContext(ctx).Table(table).
+		Where("id = ?", record.ID).
+		UpdateColumn("storage_backend", target.Driver()).Error; err != nil {
+		return fmt.Errorf("failed to update storage backend: %w", err)
+	}
+
+	if deleteSource {
+		for _, storagePath := range paths {
+			if err := source.Delete(ctx, attachmentObjectKey(area, storagePath)); err != nil {
+				utils.Logger.Warn().Err(err).Str("path", storagePath).Msg("Failed to delete migrated source file")
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,490 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Auditor share token settings
+const (
+	AuditorTokenPrefix          = "cya_"
+	DefaultAuditorShareDuration = 14 * 24 * time.Hour
+	MaxAuditorShareDuration     = 90 * 24 * time.Hour
+	auditorTokenBytes           = 32
+	maxAuditorUserAgent         = 500
+)
+
+// ErrAuditorTokenInvalid is returned for unknown, expired and revoked auditor share tokens alike,
+// so the portal does not reveal which tokens once existed
+var ErrAuditorTokenInvalid = errors.New("invalid or expired share token")
+
+// AuditorShareService issues the read-only share tokens of the auditor portal and serves an
+// assessment's reports, findings and evidence to their holders
+type AuditorShareService struct {
+	db      *gorm.DB
+	reports *AssessmentReportService
+}
+
+// NewAuditorShareService creates a new auditor share service
+func NewAuditorShareService(db *gorm.DB) *AuditorShareService {
+	return &AuditorShareService{db: db, reports: NewAssessmentReportService(db)}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *AuditorShareService) WithContext(ctx context.Context) *AuditorShareService {
+	db := s.db.WithContext(ctx)
+	return &AuditorShareService{db: db, reports: NewAssessmentReportService(db)}
+}
+
+// IssueAuditorTokenRequest asks for a share token for an external auditor
+type IssueAuditorTokenRequest struct {
+	AuditorName   string `json:"auditor_name"`
+	AuditorEmail  string `json:"auditor_email,omitempty"`
+	ExpiresInDays int    `json:"expires_in_days,omitempty"` // Default 14, at most 90
+}
+
+// IssuedAuditorToken is a new share token with its secret, which is only returned once
+type IssuedAuditorToken struct {
+	models.AuditorShareToken
+	Token string `json:"token"`
+}
+
+// IssueToken creates a time-boxed share token for an assessment
+func (s *AuditorShareService) IssueToken(assessmentID uuid.UUID, req IssueAuditorTokenRequest, createdByID uuid.UUID) (*IssuedAuditorToken, error) {
+	req.AuditorName = strings.TrimSpace(req.AuditorName)
+	req.AuditorEmail = strings.TrimSpace(req.AuditorEmail)
+	if req.AuditorName == "" {
+		return nil, fmt.Errorf("auditor_name is required")
+	}
+	if len(req.AuditorName) > 255 {
+		return nil, fmt.Errorf("invalid auditor_name: at most 255 characters")
+	}
+	if req.AuditorEmail != "" {
+		if err := utils.ValidateEmail(req.AuditorEmail); err != nil {
+			return nil, fmt.Errorf("invalid auditor_email: %w", err)
+		}
+	}
+	duration := DefaultAuditorShareDuration
+	if req.ExpiresInDays != 0 {
+		duration = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+		if req.ExpiresInDays < 0 || duration > MaxAuditorShareDuration {
+			return nil, fmt.Errorf("invalid expires_in_days: must be between 1 and %d", int(MaxAuditorShareDuration.Hours()/24))
+		}
+	}
+
+	var assessment models.Assessment
+	if err := s.db.Select("id", "org_id").First(&assessment, assessmentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("assessment not found")
+		}
+		return nil, fmt.Errorf("failed to get assessment: %w", err)
+	}
+
+	secret, err := generateAuditorToken()
+	if err != nil {
+		return nil, err
+	}
+	token := models.AuditorShareToken{
+		OrgID:        assessment.OrgID,
+		AssessmentID: assessmentID,
+		AuditorName:  req.AuditorName,
+		AuditorEmail: req.AuditorEmail,
+		TokenHash:    hashAuditorToken(secret),
+		TokenPrefix:  secret[:len(AuditorTokenPrefix)+6],
+		ExpiresAt:    time.Now().Add(duration),
+		CreatedByID:  createdByID,
+	}
+	if err := s.db.Create(&token).Error; err != nil {
+		return nil, fmt.Errorf("failed to create share token: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("assessment_id", assessmentID.String()).
+		Str("token_id", token.ID.String()).
+		Time("expires_at", token.ExpiresAt).
+		Msg("Auditor share token issued")
+	return &IssuedAuditorToken{AuditorShareToken: token, Token: secret}, nil
+}
+
+// ListTokens returns the share tokens of an assessment, newest first
+func (s *AuditorShareService) ListTokens(assessmentID uuid.UUID) ([]models.AuditorShareToken, error) {
+	var tokens []models.AuditorShareToken
+	if err := s.db.Where("assessment_id = ?", assessmentID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to list share tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken revokes a share token of an assessment; its holder loses access immediately
+func (s *AuditorShareService) RevokeToken(assessmentID, tokenID, revokedByID uuid.UUID) (*models.AuditorShareToken, error) {
+	token, err := s.getToken(assessmentID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if token.RevokedAt != nil {
+		return nil, fmt.Errorf("share token already revoked")
+	}
+
+	now := time.Now()
+	if err := s.db.Model(token).Updates(map[string]interface{}{
+		"revoked_at":    now,
+		"revoked_by_id": revokedByID,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to revoke share token: %w", err)
+	}
+	token.RevokedAt = &now
+	token.RevokedByID = &revokedByID
+
+	utils.Logger.Info().
+		Str("assessment_id", assessmentID.String()).
+		Str("token_id", tokenID.String()).
+		Msg("Auditor share token revoked")
+	return token, nil
+}
+
+// ListAccessLog returns the requests made with a share token, newest first
+func (s *AuditorShareService) ListAccessLog(assessmentID, tokenID uuid.UUID, page, limit int) ([]models.AuditorShareAccess, int64, error) {
+	if _, err := s.getToken(assessmentID, tokenID); err != nil {
+		return nil, 0, err
+	}
+
+	query := s.db.Model(&models.AuditorShareAccess{}).Where("token_id = ?", tokenID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count share token accesses: %w", err)
+	}
+	var accesses []models.AuditorShareAccess
+	if err := query.Order("accessed_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&accesses).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list share token accesses: %w", err)
+	}
+	return accesses, total, nil
+}
+
+// getToken loads a share token of an assessment
+func (s *AuditorShareService) getToken(assessmentID, tokenID uuid.UUID) (*models.AuditorShareToken, error) {
+	var token models.AuditorShareToken
+	if err := s.db.Where("id = ? AND assessment_id = ?", tokenID, assessmentID).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("share token not found")
+		}
+		return nil, fmt.Errorf("failed to get share token: %w", err)
+	}
+	return &token, nil
+}
+
+// Authenticate returns the active share token of secret. The lookup is not tenant scoped: the
+// token decides the organization of the portal request.
+func (s *AuditorShareService) Authenticate(secret string) (*models.AuditorShareToken, error) {
+	if !strings.HasPrefix(secret, AuditorTokenPrefix) {
+		return nil, ErrAuditorTokenInvalid
+	}
+	var token models.AuditorShareToken
+	if err := s.db.Where("token_hash = ?", hashAuditorToken(secret)).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAuditorTokenInvalid
+		}
+		return nil, fmt.Errorf("failed to check share token: %w", err)
+	}
+	if !token.IsActive() {
+		return nil, ErrAuditorTokenInvalid
+	}
+	return &token, nil
+}
+
+// ForToken returns a copy of the service scoped to the organization of a share token
+func (s *AuditorShareService) ForToken(token *models.AuditorShareToken) *AuditorShareService {
+	ctx := s.db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if token.OrgID != nil {
+		ctx = tenant.WithOrg(ctx, *token.OrgID)
+	}
+	return s.WithContext(ctx)
+}
+
+// RecordAccess adds a request to the access log of a share token
+func (s *AuditorShareService) RecordAccess(token *models.AuditorShareToken, resource string, resourceID *uuid.UUID, ipAddress, userAgent string) error {
+	now := time.Now()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.AuditorShareAccess{
+			TokenID:      token.ID,
+			AssessmentID: token.AssessmentID,
+			Resource:     resource,
+			ResourceID:   resourceID,
+			IPAddress:    ipAddress,
+			UserAgent:    truncateRunes(userAgent, maxAuditorUserAgent),
+			AccessedAt:   now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to log share token access: %w", err)
+		}
+		if err := tx.Model(token).UpdateColumns(map[string]interface{}{
+			"last_accessed_at": now,
+			"access_count":     gorm.Expr("access_count + 1"),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update share token: %w", err)
+		}
+		return nil
+	})
+}
+
+// AuditorPortalAssessment is the part of an assessment shown to auditors
+type AuditorPortalAssessment struct {
+	ID                   uuid.UUID               `json:"id"`
+	Name                 string                  `json:"name"`
+	Description          string                  `json:"description,omitempty"`
+	AssessmentType       models.AssessmentType   `json:"assessment_type"`
+	Status               models.AssessmentStatus `json:"status"`
+	AssessorName         string                  `json:"assessor_name"`
+	AssessorOrganization string                  `json:"assessor_organization,omitempty"`
+	StartDate            time.Time               `json:"start_date"`
+	EndDate              *time.Time              `json:"end_date,omitempty"`
+	ExecutiveSummary     string                  `json:"executive_summary,omitempty"`
+	FindingsSummary      string                  `json:"findings_summary,omitempty"`
+	Recommendations      string                  `json:"recommendations,omitempty"`
+	Score                *int                    `json:"score,omitempty"`
+}
+
+// AuditorPortalReport is a report file of the assessment
+type AuditorPortalReport struct {
+	ID           uuid.UUID `json:"id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
+	OriginalName string    `json:"original_name"`
+	MimeType     string    `json:"mime_type"`
+	FileSize     int64     `json:"file_size"`
+	Version      int       `json:"version"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AuditorPortalEvidence is an evidence file of a finding
+type AuditorPortalEvidence struct {
+	ID             uuid.UUID `json:"id"`
+	OriginalName   string    `json:"original_name"`
+	MimeType       string    `json:"mime_type"`
+	FileSize       int64     `json:"file_size"`
+	AttachmentType string    `json:"attachment_type"`
+	Description    string    `json:"description,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// AuditorPortalFinding is a vulnerability linked to the assessment with its evidence
+type AuditorPortalFinding struct {
+	ID                        uuid.UUID                    `json:"id"`
+	Title                     string                       `json:"title"`
+	Description               string                       `json:"description"`
+	Severity                  models.VulnerabilitySeverity `json:"severity"`
+	Status                    models.VulnerabilityStatus   `json:"status"`
+	CVSSScore                 *float64                     `json:"cvss_score,omitempty"`
+	CVEID                     string                       `json:"cve_id,omitempty"`
+	CWEIDs                    []string                     `json:"cwe_ids,omitempty"`
+	ImpactAssessment          string                       `json:"impact_assessment,omitempty"`
+	MitigationRecommendations string                       `json:"mitigation_recommendations,omitempty"`
+	FindingNotes              string                       `json:"finding_notes,omitempty"`
+	AffectedSystems           []string                     `json:"affected_systems"` // Hostnames, or IP addresses of unnamed systems
+	RetestStatus              models.RetestStatus          `json:"retest_status,omitempty"`
+	Evidence                  []AuditorPortalEvidence      `json:"evidence"`
+}
+
+// AuditorPortalView is everything a share token grants access to
+type AuditorPortalView struct {
+	Assessment AuditorPortalAssessment `json:"assessment"`
+	Reports    []AuditorPortalReport   `json:"reports"`
+	Findings   []AuditorPortalFinding  `json:"findings"`
+	ExpiresAt  time.Time               `json:"expires_at"`
+}
+
+// PortalView returns the assessment of a share token with its latest reports and its linked
+// findings, most severe first
+func (s *AuditorShareService) PortalView(token *models.AuditorShareToken) (*AuditorPortalView, error) {
+	var assessment models.Assessment
+	if err := s.db.First(&assessment, token.AssessmentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("assessment not found")
+		}
+		return nil, fmt.Errorf("failed to get assessment: %w", err)
+	}
+
+	reports, err := s.reports.GetAssessmentReports(assessment.ID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+
+	var links []models.AssessmentVulnerability
+	if err := s.db.Where("assessment_id = ?", assessment.ID.String()).Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to load linked vulnerabilities: %w", err)
+	}
+	notes := make(map[string]string, len(links))
+	ids := make([]string, 0, len(links))
+	for _, link := range links {
+		notes[link.VulnerabilityID] = link.FindingNotes
+		ids = append(ids, link.VulnerabilityID)
+	}
+	var vulnerabilities []models.Vulnerability
+	var attachments []models.VulnerabilityAttachment
+	if len(ids) > 0 {
+		if err := s.db.Preload("AffectedSystems").Where("id IN ?", ids).Find(&vulnerabilities).Error; err != nil {
+			return nil, fmt.Errorf("failed to load linked vulnerabilities: %w", err)
+		}
+		if err := s.db.Where("vulnerability_id IN ?", ids).Order("created_at").Find(&attachments).Error; err != nil {
+			return nil, fmt.Errorf("failed to load evidence: %w", err)
+		}
+	}
+	sort.SliceStable(vulnerabilities, func(i, j int) bool {
+		a, b := vulnerabilities[i], vulnerabilities[j]
+		if severityRank(a.Severity) != severityRank(b.Severity) {
+			return severityRank(a.Severity) > severityRank(b.Severity)
+		}
+		return a.Title < b.Title
+	})
+
+	latest, err := latestRetests(s.db, assessment.ID)
+	if err != nil {
+		return nil, err
+	}
+	retests := make(map[uuid.UUID]models.RetestStatus, len(latest))
+	for _, retest := range latest {
+		retests[retest.VulnerabilityID] = retest.Status
+	}
+	evidence := make(map[uuid.UUID][]AuditorPortalEvidence)
+	for _, attachment := range attachments {
+		evidence[attachment.VulnerabilityID] = append(evidence[attachment.VulnerabilityID], AuditorPortalEvidence{
+			ID:             attachment.ID,
+			OriginalName:   attachment.OriginalName,
+			MimeType:       attachment.MimeType,
+			FileSize:       attachment.FileSize,
+			AttachmentType: attachment.AttachmentType,
+			Description:    attachment.Description,
+			CreatedAt:      attachment.CreatedAt,
+		})
+	}
+
+	view := &AuditorPortalView{
+		Assessment: AuditorPortalAssessment{
+			ID:                   assessment.ID,
+			Name:                 assessment.Name,
+			Description:          assessment.Description,
+			AssessmentType:       assessment.AssessmentType,
+			Status:               assessment.Status,
+			AssessorName:         assessment.AssessorName,
+			AssessorOrganization: assessment.AssessorOrganization,
+			StartDate:            assessment.StartDate,
+			EndDate:              assessment.EndDate,
+			ExecutiveSummary:     assessment.ExecutiveSummary,
+			FindingsSummary:      assessment.FindingsSummary,
+			Recommendations:      assessment.Recommendations,
+			Score:                assessment.Score,
+		},
+		Reports:   make([]AuditorPortalReport, 0, len(reports)),
+		Findings:  make([]AuditorPortalFinding, 0, len(vulnerabilities)),
+		ExpiresAt: token.ExpiresAt,
+	}
+	for _, report := range reports {
+		view.Reports = append(view.Reports, AuditorPortalReport{
+			ID:           report.ID,
+			Title:        report.Title,
+			Description:  report.Description,
+			OriginalName: report.OriginalName,
+			MimeType:     report.MimeType,
+			FileSize:     report.FileSize,
+			Version:      report.Version,
+			CreatedAt:    report.CreatedAt,
+		})
+	}
+	for _, vulnerability := range vulnerabilities {
+		systems := make([]string, 0, len(vulnerability.AffectedSystems))
+		for _, system := range vulnerability.AffectedSystems {
+			name := system.Hostname
+			if name == "" {
+				name = system.IPAddress
+			}
+			systems = append(systems, name)
+		}
+		findingEvidence := evidence[vulnerability.ID]
+		if findingEvidence == nil {
+			findingEvidence = []AuditorPortalEvidence{}
+		}
+		view.Findings = append(view.Findings, AuditorPortalFinding{
+			ID:                        vulnerability.ID,
+			Title:                     vulnerability.Title,
+			Description:               vulnerability.Description,
+			Severity:                  vulnerability.Severity,
+			Status:                    vulnerability.Status,
+			CVSSScore:                 vulnerability.CVSSScore,
+			CVEID:                     vulnerability.CVEID,
+			CWEIDs:                    vulnerability.CWEIDs,
+			ImpactAssessment:          vulnerability.ImpactAssessment,
+			MitigationRecommendations: vulnerability.MitigationRecommendations,
+			FindingNotes:              notes[vulnerability.ID.String()],
+			AffectedSystems:           systems,
+			RetestStatus:              retests[vulnerability.ID],
+			Evidence:                  findingEvidence,
+		})
+	}
+	return view, nil
+}
+
+// PortalReportFile returns a report of the share token's assessment with its file
+func (s *AuditorShareService) PortalReportFile(token *models.AuditorShareToken, reportID uuid.UUID) (*models.AssessmentReport, []byte, error) {
+	var report models.AssessmentReport
+	if err := s.db.Where("id = ? AND assessment_id = ?", reportID, token.AssessmentID).First(&report).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("report not found")
+		}
+		return nil, nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	data, err := s.reports.GetReportFile(&report)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &report, data, nil
+}
+
+// PortalEvidenceFile returns an evidence file of a finding linked to the share token's assessment
+func (s *AuditorShareService) PortalEvidenceFile(token *models.AuditorShareToken, attachmentID uuid.UUID) (*models.VulnerabilityAttachment, []byte, error) {
+	var attachment models.VulnerabilityAttachment
+	if err := s.db.
+		Where("id = ? AND vulnerability_id IN (?)", attachmentID,
+			s.db.Model(&models.AssessmentVulnerability{}).Select("vulnerability_id").Where("assessment_id = ?", token.AssessmentID.String())).
+		First(&attachment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("evidence not found")
+		}
+		return nil, nil, fmt.Errorf("failed to get evidence: %w", err)
+	}
+	data, err := readStoredFile(attachment.StorageBackend, attachmentObjectKey(storageAreaVulnerabilityAttachments, attachment.StoragePath))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &attachment, data, nil
+}
+
+// generateAuditorToken returns a new random share token
+func generateAuditorToken() (string, error) {
+	bytes := make([]byte, auditorTokenBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return AuditorTokenPrefix + base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// hashAuditorToken returns the SHA-256 hex digest under which a share token is stored. Tokens
+// are high-entropy random values, so a fast hash is sufficient.
+func hashAuditorToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,539 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BusinessServiceService manages business services (applications), the assets that run
+// them and their vulnerability posture rollups
+type BusinessServiceService struct {
+	db *gorm.DB
+}
+
+// NewBusinessServiceService creates a new business service service
+func NewBusinessServiceService(db *gorm.DB) *BusinessServiceService {
+	return &BusinessServiceService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *BusinessServiceService) WithContext(ctx context.Context) *BusinessServiceService {
+	return &BusinessServiceService{db: s.db.WithContext(ctx)}
+}
+
+// BusinessServiceRequest is the payload for creating or updating a business service.
+// Nil fields are left unchanged on update.
+type BusinessServiceRequest struct {
+	Name        *string     `json:"name,omitempty"`
+	Description *string     `json:"description,omitempty"`
+	Tier        *string     `json:"tier,omitempty"`
+	OwnerID     *uuid.UUID  `json:"owner_id,omitempty"`      // uuid.Nil clears the owner
+	OwnerTeamID *uuid.UUID  `json:"owner_team_id,omitempty"` // uuid.Nil clears the owner team
+	AssetIDs    []uuid.UUID `json:"asset_ids,omitempty"`     // Assets to link, create only
+}
+
+// applyTo copies the provided request fields onto a business service
+func (req BusinessServiceRequest) applyTo(bs *models.BusinessService) {
+	if req.Name != nil {
+		bs.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		bs.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Tier != nil {
+		bs.Tier = models.BusinessServiceTier(strings.ToUpper(strings.TrimSpace(*req.Tier)))
+	}
+	if req.OwnerID != nil {
+		if *req.OwnerID == uuid.Nil {
+			bs.OwnerID = nil
+		} else {
+			ownerID := *req.OwnerID
+			bs.OwnerID = &ownerID
+		}
+	}
+	if req.OwnerTeamID != nil {
+		if *req.OwnerTeamID == uuid.Nil {
+			bs.OwnerTeamID = nil
+		} else {
+			teamID := *req.OwnerTeamID
+			bs.OwnerTeamID = &teamID
+		}
+	}
+}
+
+// ValidateBusinessService checks that a business service has a name and a known tier
+func ValidateBusinessService(bs *models.BusinessService) error {
+	if bs.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(bs.Name) > 255 {
+		return fmt.Errorf("invalid name: must be at most 255 characters")
+	}
+	switch bs.Tier {
+	case models.BusinessTier1, models.BusinessTier2, models.BusinessTier3, models.BusinessTier4:
+	default:
+		return fmt.Errorf("invalid tier '%s'", bs.Tier)
+	}
+	return nil
+}
+
+// BusinessServiceRisk aggregates the open findings on a business service's assets
+type BusinessServiceRisk struct {
+	ServiceID           uuid.UUID                  `json:"service_id"`
+	Name                string                     `json:"name"`
+	Tier                models.BusinessServiceTier `json:"tier"`
+	OwnerID             *uuid.UUID                 `json:"owner_id,omitempty"`
+	OwnerTeamID         *uuid.UUID                 `json:"owner_team_id,omitempty"`
+	AssetCount          int64                      `json:"asset_count"`
+	AffectedAssets      int64                      `json:"affected_assets"` // Assets with at least one open finding
+	OpenFindings        int64                      `json:"open_findings"`
+	OpenVulnerabilities int64                      `json:"open_vulnerabilities"` // Distinct vulnerabilities behind the open findings
+	FindingsBySeverity  map[string]int64           `json:"findings_by_severity"`
+	ExploitableFindings int64                      `json:"exploitable_findings"` // Open findings of vulnerabilities with a known public exploit
+	OldestOpenFinding   *time.Time                 `json:"oldest_open_finding,omitempty"`
+	RiskScore           float64                    `json:"risk_score"` // 0-100, weighted like the executive report
+	SecurityPosture     string                     `json:"security_posture"`
+}
+
+// List returns the business services, optionally filtered by a name search and tier, with
+// their asset counts
+func (s *BusinessServiceService) List(search, tier string) ([]models.BusinessService, error) {
+	query := s.db.Model(&models.BusinessService{}).Preload("Owner").Preload("OwnerTeam")
+	if search = strings.TrimSpace(search); search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+	if tier = strings.TrimSpace(tier); tier != "" {
+		query = query.Where("tier = ?", strings.ToUpper(tier))
+	}
+
+	var services []models.BusinessService
+	if err := query.Order("tier ASC, name ASC").Find(&services).Error; err != nil {
+		return nil, fmt.Errorf("failed to list business services: %w", err)
+	}
+	if len(services) == 0 {
+		return services, nil
+	}
+
+	ids := make([]uuid.UUID, len(services))
+	for i := range services {
+		ids[i] = services[i].ID
+	}
+	counts, err := s.assetCounts(ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range services {
+		services[i].AssetCount = counts[services[i].ID]
+	}
+	return services, nil
+}
+
+// GetByID retrieves a business service with its owners and asset count
+func (s *BusinessServiceService) GetByID(id uuid.UUID) (*models.BusinessService, error) {
+	var bs models.BusinessService
+	if err := s.db.Preload("Owner").Preload("OwnerTeam").Preload("CreatedBy").Where("id = ?", id).First(&bs).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("business service not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	counts, err := s.assetCounts([]uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+	bs.AssetCount = counts[id]
+	return &bs, nil
+}
+
+// Exists reports whether a business service is visible to the caller
+func (s *BusinessServiceService) Exists(id uuid.UUID) error {
+	var count int64
+	if err := s.db.Model(&models.BusinessService{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("business service not found")
+	}
+	return nil
+}
+
+// Create creates a business service and links its initial assets
+func (s *BusinessServiceService) Create(req BusinessServiceRequest, createdByID uuid.UUID) (*models.BusinessService, error) {
+	bs := &models.BusinessService{Tier: models.BusinessTier3, CreatedByID: createdByID}
+	req.applyTo(bs)
+	if err := s.validate(bs, uuid.Nil); err != nil {
+		return nil, err
+	}
+	if err := s.checkAssetsExist(req.AssetIDs); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(bs).Error; err != nil {
+			return fmt.Errorf("failed to create business service: %w", err)
+		}
+		return linkBusinessServiceAssets(tx, bs.ID, req.AssetIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("business_service_id", bs.ID.String()).
+		Str("name", bs.Name).
+		Str("tier", string(bs.Tier)).
+		Int("assets", len(req.AssetIDs)).
+		Msg("Business service created")
+
+	return s.GetByID(bs.ID)
+}
+
+// Update updates a business service's name, description, tier or owners
+func (s *BusinessServiceService) Update(id uuid.UUID, req BusinessServiceRequest) (*models.BusinessService, error) {
+	bs, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.applyTo(bs)
+	if err := s.validate(bs, id); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"name":          bs.Name,
+		"description":   bs.Description,
+		"tier":          bs.Tier,
+		"owner_id":      bs.OwnerID,
+		"owner_team_id": bs.OwnerTeamID,
+	}
+	if err := s.db.Model(&models.BusinessService{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update business service: %w", err)
+	}
+	return s.GetByID(id)
+}
+
+// Delete soft-deletes a business service and unlinks its assets
+func (s *BusinessServiceService) Delete(id uuid.UUID) error {
+	bs, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("service_id = ?", id).Delete(&models.BusinessServiceAsset{}).Error; err != nil {
+			return fmt.Errorf("failed to unlink business service assets: %w", err)
+		}
+		if err := tx.Delete(bs).Error; err != nil {
+			return fmt.Errorf("failed to delete business service: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListAssets returns a page of the assets that run a business service
+func (s *BusinessServiceService) ListAssets(serviceID uuid.UUID, page, limit int) ([]models.AffectedSystem, int64, error) {
+	if err := s.Exists(serviceID); err != nil {
+		return nil, 0, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	query := s.db.Model(&models.AffectedSystem{}).Where("id IN (?)", BusinessServiceAssetIDs(s.db, serviceID))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count business service assets: %w", err)
+	}
+
+	var assets []models.AffectedSystem
+	if err := query.Preload("Tags").
+		Order("hostname ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&assets).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list business service assets: %w", err)
+	}
+	return assets, total, nil
+}
+
+// AddAssets links assets to a business service
+func (s *BusinessServiceService) AddAssets(serviceID uuid.UUID, assetIDs []uuid.UUID) (*models.BusinessService, error) {
+	if len(assetIDs) == 0 {
+		return nil, fmt.Errorf("asset_ids is required")
+	}
+	if err := s.Exists(serviceID); err != nil {
+		return nil, err
+	}
+	if err := s.checkAssetsExist(assetIDs); err != nil {
+		return nil, err
+	}
+	if err := linkBusinessServiceAssets(s.db, serviceID, assetIDs); err != nil {
+		return nil, err
+	}
+	return s.GetByID(serviceID)
+}
+
+// RemoveAsset unlinks an asset from a business service
+func (s *BusinessServiceService) RemoveAsset(serviceID, assetID uuid.UUID) (*models.BusinessService, error) {
+	if err := s.Exists(serviceID); err != nil {
+		return nil, err
+	}
+
+	result := s.db.Where("service_id = ? AND asset_id = ?", serviceID, assetID).Delete(&models.BusinessServiceAsset{})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to unlink business service asset: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("asset is not linked to this business service")
+	}
+	return s.GetByID(serviceID)
+}
+
+// GetRisk rolls up the vulnerability posture of one business service
+func (s *BusinessServiceService) GetRisk(id uuid.UUID) (*BusinessServiceRisk, error) {
+	bs, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	risks, err := s.rollup([]models.BusinessService{*bs})
+	if err != nil {
+		return nil, err
+	}
+	return &risks[0], nil
+}
+
+// RiskRollup rolls up the vulnerability posture of every business service, riskiest first
+// (by tier, then risk score). A limit of zero or less returns every service.
+func (s *BusinessServiceService) RiskRollup(limit int) ([]BusinessServiceRisk, error) {
+	var services []models.BusinessService
+	if err := s.db.Find(&services).Error; err != nil {
+		return nil, fmt.Errorf("failed to list business services: %w", err)
+	}
+	if len(services) == 0 {
+		return []BusinessServiceRisk{}, nil
+	}
+	counts, err := s.assetCounts(nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range services {
+		services[i].AssetCount = counts[services[i].ID]
+	}
+
+	risks, err := s.rollup(services)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(risks, func(i, j int) bool {
+		if risks[i].Tier != risks[j].Tier {
+			return risks[i].Tier < risks[j].Tier
+		}
+		if risks[i].RiskScore != risks[j].RiskScore {
+			return risks[i].RiskScore > risks[j].RiskScore
+		}
+		return risks[i].OpenFindings > risks[j].OpenFindings
+	})
+	if limit > 0 && len(risks) > limit {
+		risks = risks[:limit]
+	}
+	return risks, nil
+}
+
+// rollup aggregates the open findings on the active assets of each business service
+func (s *BusinessServiceService) rollup(services []models.BusinessService) ([]BusinessServiceRisk, error) {
+	ids := make([]uuid.UUID, len(services))
+	for i := range services {
+		ids[i] = services[i].ID
+	}
+
+	openFindings := func() *gorm.DB {
+		return s.db.Model(&models.VulnerabilityFinding{}).
+			Joins("JOIN business_service_assets ON business_service_assets.asset_id = vulnerability_findings.affected_system_id").
+			Joins("JOIN affected_systems ON affected_systems.id = vulnerability_findings.affected_system_id AND affected_systems.deleted_at IS NULL").
+			Where("vulnerability_findings.status = ? AND business_service_assets.service_id IN ?", models.FindingStatusOpen, ids)
+	}
+
+	var bySeverity []struct {
+		ServiceID   uuid.UUID
+		Severity    string
+		Count       int64
+		Exploitable int64
+	}
+	if err := openFindings().
+		Select("business_service_assets.service_id, vulnerabilities.severity, COUNT(*) AS count, " +
+			"COUNT(*) FILTER (WHERE vulnerabilities.has_exploit) AS exploitable").
+		Joins("JOIN vulnerabilities ON vulnerabilities.id = vulnerability_findings.vulnerability_id").
+		Group("business_service_assets.service_id, vulnerabilities.severity").
+		Scan(&bySeverity).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate business service findings: %w", err)
+	}
+
+	var totals []struct {
+		ServiceID       uuid.UUID
+		Assets          int64
+		Vulnerabilities int64
+		Oldest          *time.Time
+	}
+	if err := openFindings().
+		Select("business_service_assets.service_id, " +
+			"COUNT(DISTINCT vulnerability_findings.affected_system_id) AS assets, " +
+			"COUNT(DISTINCT vulnerability_findings.vulnerability_id) AS vulnerabilities, " +
+			"MIN(vulnerability_findings.first_detected) AS oldest").
+		Group("business_service_assets.service_id").
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate business service findings: %w", err)
+	}
+
+	risks := make([]BusinessServiceRisk, len(services))
+	index := make(map[uuid.UUID]*BusinessServiceRisk, len(services))
+	for i, bs := range services {
+		risks[i] = BusinessServiceRisk{
+			ServiceID:          bs.ID,
+			Name:               bs.Name,
+			Tier:               bs.Tier,
+			OwnerID:            bs.OwnerID,
+			OwnerTeamID:        bs.OwnerTeamID,
+			AssetCount:         bs.AssetCount,
+			FindingsBySeverity: map[string]int64{},
+		}
+		index[bs.ID] = &risks[i]
+	}
+	exploitable := make(map[uuid.UUID]map[string]int64, len(services))
+	for _, row := range bySeverity {
+		if risk, ok := index[row.ServiceID]; ok {
+			risk.FindingsBySeverity[row.Severity] = row.Count
+			risk.OpenFindings += row.Count
+			risk.ExploitableFindings += row.Exploitable
+			if exploitable[row.ServiceID] == nil {
+				exploitable[row.ServiceID] = map[string]int64{}
+			}
+			exploitable[row.ServiceID][row.Severity] = row.Exploitable
+		}
+	}
+	for _, row := range totals {
+		if risk, ok := index[row.ServiceID]; ok {
+			risk.AffectedAssets = row.Assets
+			risk.OpenVulnerabilities = row.Vulnerabilities
+			risk.OldestOpenFinding = row.Oldest
+		}
+	}
+	for i := range risks {
+		risks[i].RiskScore = severityRiskScore(risks[i].FindingsBySeverity, exploitable[risks[i].ServiceID])
+		risks[i].SecurityPosture = securityPosture(risks[i].RiskScore)
+	}
+	return risks, nil
+}
+
+// assetCounts counts the active assets linked to each business service; nil counts every service
+func (s *BusinessServiceService) assetCounts(serviceIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	query := s.db.Model(&models.BusinessServiceAsset{}).
+		Select("business_service_assets.service_id, COUNT(*) AS count").
+		Joins("JOIN affected_systems ON affected_systems.id = business_service_assets.asset_id AND affected_systems.deleted_at IS NULL")
+	if serviceIDs != nil {
+		query = query.Where("business_service_assets.service_id IN ?", serviceIDs)
+	}
+
+	var counts []struct {
+		ServiceID uuid.UUID
+		Count     int64
+	}
+	if err := query.Group("business_service_assets.service_id").Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count business service assets: %w", err)
+	}
+	countMap := make(map[uuid.UUID]int64, len(counts))
+	for _, c := range counts {
+		countMap[c.ServiceID] = c.Count
+	}
+	return countMap, nil
+}
+
+// BusinessServiceAssetIDs returns a subquery selecting the asset IDs of a business service,
+// for use in "IN (?)" filters
+func BusinessServiceAssetIDs(db *gorm.DB, serviceID uuid.UUID) *gorm.DB {
+	return db.Model(&models.BusinessServiceAsset{}).
+		Select("asset_id").
+		Where("service_id = ?", serviceID)
+}
+
+// validate checks the service fields, that its owners exist, and that its name is unused
+func (s *BusinessServiceService) validate(bs *models.BusinessService, exceptID uuid.UUID) error {
+	if err := ValidateBusinessService(bs); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := s.db.Model(&models.BusinessService{}).Where("LOWER(name) = LOWER(?) AND id <> ?", bs.Name, exceptID).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("business service '%s' already exists", bs.Name)
+	}
+
+	if bs.OwnerID != nil {
+		if err := s.db.Model(&models.User{}).Where("id = ?", *bs.OwnerID).Count(&count).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("owner not found")
+		}
+	}
+	if bs.OwnerTeamID != nil {
+		if err := NewTeamService(s.db).Exists(*bs.OwnerTeamID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAssetsExist verifies that every asset ID refers to an asset visible to the caller
+func (s *BusinessServiceService) checkAssetsExist(assetIDs []uuid.UUID) error {
+	unique := make(map[uuid.UUID]bool, len(assetIDs))
+	ids := make([]uuid.UUID, 0, len(assetIDs))
+	for _, id := range assetIDs {
+		if !unique[id] {
+			unique[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var count int64
+	if err := s.db.Model(&models.AffectedSystem{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if int(count) != len(ids) {
+		return fmt.Errorf("asset not found")
+	}
+	return nil
+}
+
+// linkBusinessServiceAssets links assets to a service, ignoring assets already linked
+func linkBusinessServiceAssets(tx *gorm.DB, serviceID uuid.UUID, assetIDs []uuid.UUID) error {
+	if len(assetIDs) == 0 {
+		return nil
+	}
+	links := make([]models.BusinessServiceAsset, 0, len(assetIDs))
+	for _, assetID := range assetIDs {
+		links = append(links, models.BusinessServiceAsset{ServiceID: serviceID, AssetID: assetID})
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&links, 500).Error; err != nil {
+		return fmt.Errorf("failed to link business service assets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Cache groups; each group is invalidated as a whole when any of its source tables change
+const (
+	CacheGroupAssetStats         = "asset_stats"
+	CacheGroupVulnerabilityStats = "vulnerability_stats"
+	CacheGroupReports            = "reports"
+)
+
+// cacheKeyPrefix namespaces all cache keys in Redis
+const cacheKeyPrefix = "cyops:cache:"
+
+// cacheInvalidationSources maps tables to the cache groups derived from them
+var cacheInvalidationSources = map[string][]string{
+	"vulnerabilities":                {CacheGroupAssetStats, CacheGroupVulnerabilityStats, CacheGroupReports},
+	"vulnerability_affected_systems": {CacheGroupAssetStats, CacheGroupReports},
+	"vulnerability_findings":         {CacheGroupReports},
+	"vulnerability_status_history":   {CacheGroupReports},
+	"vulnerability_tags":             {CacheGroupReports},
+	"vulnerability_classifications":  {CacheGroupReports},
+	"finding_status_history":         {CacheGroupReports},
+	"affected_systems":               {CacheGroupAssetStats, CacheGroupReports},
+	"asset_tags":                     {CacheGroupAssetStats},
+	"assessments":                    {CacheGroupReports},
+	"daily_metrics_snapshots":        {CacheGroupReports},
+	"business_services":              {CacheGroupReports},
+	"business_service_assets":        {CacheGroupReports},
+}
+
+var (
+	activeCacheMu sync.RWMutex
+	activeCache   *CacheService
+)
+
+// SetActiveCache enables caching of stats and reports (nil disables it)
+func SetActiveCache(c *CacheService) {
+	activeCacheMu.Lock()
+	defer activeCacheMu.Unlock()
+	activeCache = c
+}
+
+// ActiveCache returns the configured cache, or nil when caching is disabled
+func ActiveCache() *CacheService {
+	activeCacheMu.RLock()
+	defer activeCacheMu.RUnlock()
+	return activeCache
+}
+
+// CacheService caches expensive aggregate results in Redis.
+// Keys embed a per-group generation counter; invalidating a group bumps the counter so
+// every instance stops reading the old entries, which then age out via their TTL.
+type CacheService struct {
+	client *redis.Client
+	ttl    time.Duration
+
+	dirtyMu sync.Mutex
+	dirty   map[string]bool
+}
+
+// NewCacheService creates a new cache service
+func NewCacheService(client *redis.Client, ttl time.Duration) *CacheService {
+	return &CacheService{
+		client: client,
+		ttl:    ttl,
+		dirty:  make(map[string]bool),
+	}
+}
+
+// generationKey returns the Redis key holding a group's generation counter
+func generationKey(group string) string {
+	return cacheKeyPrefix + "gen:" + group
+}
+
+// dataKey returns the Redis key for a cached value in the current generation of its group
+func (s *CacheService) dataKey(ctx context.Context, group, key string) (string, error) {
+	gen, err := s.client.Get(ctx, generationKey(group)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+	return fmt.Sprintf("%s%s:%d:%s", cacheKeyPrefix, group, gen, key), nil
+}
+
+// get loads a cached value into dest, reporting whether it was found
+func (s *CacheService) get(ctx context.Context, group, key string, dest interface{}) (bool, error) {
+	dataKey, err := s.dataKey(ctx, group, key)
+	if err != nil {
+		return false, err
+	}
+	raw, err := s.client.Get(ctx, dataKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(raw, dest); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// set stores a value in the current generation of its group
+func (s *CacheService) set(ctx context.Context, group, key string, value interface{}) error {
+	dataKey, err := s.dataKey(ctx, group, key)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, dataKey, raw, s.ttl).Err()
+}
+
+// InvalidateGroups immediately invalidates every cached value in the given groups
+func (s *CacheService) InvalidateGroups(ctx context.Context, groups ...string) error {
+	pipe := s.client.Pipeline()
+	for _, group := range groups {
+		pipe.Incr(ctx, generationKey(group))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// markDirty records groups to invalidate on the next flush
+func (s *CacheService) markDirty(groups []string) {
+	s.dirtyMu.Lock()
+	defer s.dirtyMu.Unlock()
+	for _, group := range groups {
+		s.dirty[group] = true
+	}
+}
+
+// FlushInvalidations invalidates the groups whose source tables changed since the last flush
+func (s *CacheService) FlushInvalidations(ctx context.Context) error {
+	s.dirtyMu.Lock()
+	groups := make([]string, 0, len(s.dirty))
+	for group := range s.dirty {
+		groups = append(groups, group)
+	}
+	s.dirty = make(map[string]bool)
+	s.dirtyMu.Unlock()
+
+	if len(groups) == 0 {
+		return nil
+	}
+	if err := s.InvalidateGroups(ctx, groups...); err != nil {
+		// Keep the groups dirty so the next flush retries
+		s.markDirty(groups)
+		return err
+	}
+	return nil
+}
+
+// RegisterInvalidationCallbacks installs GORM callbacks that mark cache groups dirty
+// whenever one of their source tables is written. Invalidations are batched and applied
+// by FlushInvalidations, so a large import bumps each group once rather than per row.
+func (s *CacheService) RegisterInvalidationCallbacks(db *gorm.DB) error {
+	markDirty := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil {
+			return
+		}
+		if groups, ok := cacheInvalidationSources[tx.Statement.Schema.Table]; ok {
+			s.markDirty(groups)
+		}
+	}
+
+	if err := db.Callback().Create().After("gorm:create").Register("cache:invalidate_create", markDirty); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("cache:invalidate_update", markDirty); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("cache:invalidate_delete", markDirty)
+}
+
+// cached returns the cached value for key, computing and storing it on a miss.
+// Keys are namespaced by the organization in dbCtx so tenants never share entries.
+// Cache errors are logged and never fail the request; a nil cache always computes.
+func cached[T any](dbCtx context.Context, c *CacheService, group, key string, load func() (*T, error)) (*T, error) {
+	if c == nil {
+		return load()
+	}
+	if orgID, ok := tenant.OrgFromContext(dbCtx); ok {
+		key = orgID.String() + ":" + key
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	var value T
+	found, err := c.get(ctx, group, key, &value)
+	if err != nil {
+		utils.Logger.Warn().Err(err).Str("group", group).Str("key", key).Msg("Cache read failed")
+	} else if found {
+		return &value, nil
+	}
+
+	result, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.set(ctx, group, key, result); err != nil {
+		utils.Logger.Warn().Err(err).Str("group", group).Str("key", key).Msg("Cache write failed")
+	}
+	return result, nil
+}
+
+// reportCacheKey builds a cache key for a report over a date range (to the minute, so the
+// default "last 30 days until now" range is reusable across requests)
+func reportCacheKey(report string, startDate, endDate time.Time) string {
+	return fmt.Sprintf("%s:%d:%d", report, startDate.Truncate(time.Minute).Unix(), endDate.Truncate(time.Minute).Unix())
+}
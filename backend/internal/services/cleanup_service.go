@@ -233,8 +233,9 @@ func (s *CleanupService) CleanupAllData() (*CleanupResult, error) {
 		return nil, fmt.Errorf("failed to delete finding status history: %w", err)
 	}
 
-	// Step 3: Delete all vulnerability findings (links vulnerabilities to assets)
-	if err := tx.Exec("TRUNCATE TABLE vulnerability_findings CASCADE").Error; err != nil {
+	// Step 3: Delete all vulnerability findings (links vulnerabilities to assets) with their
+	// comments, risk acceptances and suppression logs, which have no foreign key to cascade from
+	if err := tx.Exec("TRUNCATE TABLE vulnerability_findings, finding_comments, risk_acceptances, suppression_logs CASCADE").Error; err != nil {
 		tx.Rollback()
 		utils.Logger.Error().Err(err).Msg("Failed to delete vulnerability findings")
 		return nil, fmt.Errorf("failed to delete vulnerability findings: %w", err)
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrCloseApprovalRequired is returned when a vulnerability can only be closed through an
+// approved close request
+var ErrCloseApprovalRequired = errors.New("closing CRITICAL vulnerabilities requires a second user's approval")
+
+// ParseCriticalCloseApproval parses a critical_close_approval setting value
+func ParseCriticalCloseApproval(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "1":
+		return true, nil
+	case "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid critical close approval setting, must be true or false")
+}
+
+// LoadCriticalCloseApproval reports whether closing CRITICAL vulnerabilities needs a second
+// user's approval; without the setting it does not
+func LoadCriticalCloseApproval(db *gorm.DB) (bool, error) {
+	var setting models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingCriticalCloseApproval)).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load critical close approval setting: %w", err)
+	}
+	return ParseCriticalCloseApproval(setting.Value)
+}
+
+// RequiresCloseApproval reports whether moving a vulnerability to a status needs an approved
+// close request under the two-person rule
+func RequiresCloseApproval(enabled bool, severity models.VulnerabilitySeverity, newStatus models.VulnerabilityStatus) bool {
+	return enabled && severity == models.SeverityCritical && newStatus == models.StatusClosed
+}
+
+// CloseApprovalService handles the two-person rule for closing CRITICAL vulnerabilities:
+// one user requests the closure and a second user with the finding verify permission
+// approves it
+type CloseApprovalService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+// NewCloseApprovalService creates a new close approval service
+func NewCloseApprovalService(db *gorm.DB) *CloseApprovalService {
+	return &CloseApprovalService{
+		db:                  db,
+		notificationService: NewNotificationService(db),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *CloseApprovalService) WithContext(ctx context.Context) *CloseApprovalService {
+	return &CloseApprovalService{
+		db:                  s.db.WithContext(ctx),
+		notificationService: s.notificationService,
+	}
+}
+
+// getApproval loads a close approval of a vulnerability with its relations
+func (s *CloseApprovalService) getApproval(db *gorm.DB, vulnerabilityID, id uuid.UUID) (*models.VulnerabilityCloseApproval, error) {
+	var approval models.VulnerabilityCloseApproval
+	if err := db.Preload("RequestedBy").Preload("ReviewedBy").
+		First(&approval, "id = ? AND vulnerability_id = ?", id, vulnerabilityID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("close approval not found")
+		}
+		return nil, fmt.Errorf("failed to get close approval: %w", err)
+	}
+	return &approval, nil
+}
+
+// GetApproval returns a single close approval of a vulnerability
+func (s *CloseApprovalService) GetApproval(vulnerabilityID, id uuid.UUID) (*models.VulnerabilityCloseApproval, error) {
+	return s.getApproval(s.db, vulnerabilityID, id)
+}
+
+// ListApprovals returns the close requests of a vulnerability, newest first
+func (s *CloseApprovalService) ListApprovals(vulnerabilityID uuid.UUID) ([]models.VulnerabilityCloseApproval, error) {
+	if err := s.checkVulnerability(s.db, vulnerabilityID); err != nil {
+		return nil, err
+	}
+
+	approvals := []models.VulnerabilityCloseApproval{}
+	if err := s.db.Preload("RequestedBy").Preload("ReviewedBy").
+		Where("vulnerability_id = ?", vulnerabilityID).
+		Order("created_at DESC").
+		Find(&approvals).Error; err != nil {
+		return nil, fmt.Errorf("failed to list close approvals: %w", err)
+	}
+	return approvals, nil
+}
+
+// checkVulnerability checks that a vulnerability exists in the caller's organization
+func (s *CloseApprovalService) checkVulnerability(db *gorm.DB, id uuid.UUID) error {
+	var count int64
+	if err := db.Model(&models.Vulnerability{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to get vulnerability: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("vulnerability not found")
+	}
+	return nil
+}
+
+// RequestApproval opens a pending request to close a CRITICAL vulnerability and notifies the
+// users who can approve it
+func (s *CloseApprovalService) RequestApproval(vulnerabilityID uuid.UUID, notes string, requestedByID uuid.UUID) (*models.VulnerabilityCloseApproval, error) {
+	var approval *models.VulnerabilityCloseApproval
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var vulnerability models.Vulnerability
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&vulnerability, "id = ?", vulnerabilityID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("vulnerability not found")
+			}
+			return fmt.Errorf("failed to get vulnerability: %w", err)
+		}
+
+		enabled, err := LoadCriticalCloseApproval(tx)
+		if err != nil {
+			return err
+		}
+		if !RequiresCloseApproval(enabled, vulnerability.Severity, models.StatusClosed) {
+			return fmt.Errorf("invalid close approval request, closing this vulnerability does not require approval")
+		}
+		if err := NewVulnerabilityValidationService().ValidateStatusTransition(vulnerability.Status, models.StatusClosed); err != nil {
+			return err
+		}
+
+		var pending int64
+		if err := tx.Model(&models.VulnerabilityCloseApproval{}).
+			Where("vulnerability_id = ? AND status = ?", vulnerabilityID, models.CloseApprovalPending).
+			Count(&pending).Error; err != nil {
+			return fmt.Errorf("failed to check pending requests: %w", err)
+		}
+		if pending > 0 {
+			return fmt.Errorf("a close approval request is already pending for this vulnerability")
+		}
+
+		approval = &models.VulnerabilityCloseApproval{
+			OrgID:           vulnerability.OrgID,
+			VulnerabilityID: vulnerabilityID,
+			Status:          models.CloseApprovalPending,
+			FromStatus:      vulnerability.Status,
+			Notes:           strings.TrimSpace(notes),
+			RequestedByID:   requestedByID,
+		}
+		if err := tx.Create(approval).Error; err != nil {
+			return fmt.Errorf("failed to create close approval: %w", err)
+		}
+
+		return s.notifyApprovers(tx, approval, vulnerability.Title)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("close_approval_id", approval.ID.String()).
+		Str("vulnerability_id", vulnerabilityID.String()).
+		Str("requested_by", requestedByID.String()).
+		Msg("Vulnerability close approval requested")
+
+	return s.getApproval(s.db, vulnerabilityID, approval.ID)
+}
+
+// notifyApprovers notifies every user, other than the requester, whose role can verify findings
+func (s *CloseApprovalService) notifyApprovers(tx *gorm.DB, approval *models.VulnerabilityCloseApproval, title string) error {
+	// Resolve approver roles in Go so inherited grants and deny rules are honored
+	roleIDs, err := (&RoleService{db: tx}).RoleIDsWithPermission("finding", "verify")
+	if err != nil {
+		return fmt.Errorf("failed to find approver roles: %w", err)
+	}
+	if len(roleIDs) == 0 {
+		return nil
+	}
+
+	var approverIDs []uuid.UUID
+	if err := tx.Model(&models.User{}).
+		Where("role_id IN ?", roleIDs).
+		Where("id <> ?", approval.RequestedByID).
+		Pluck("id", &approverIDs).Error; err != nil {
+		return fmt.Errorf("failed to find approvers: %w", err)
+	}
+
+	notifications := make([]models.Notification, 0, len(approverIDs))
+	for _, approverID := range approverIDs {
+		notifications = append(notifications, s.notification(approval, approverID,
+			models.NotificationTypeCloseApprovalRequested,
+			"Vulnerability closure awaiting approval",
+			title,
+			&approval.RequestedByID,
+		))
+	}
+	return s.notificationService.CreateNotifications(tx, notifications)
+}
+
+// notification builds a close approval notification linking to the vulnerability
+func (s *CloseApprovalService) notification(approval *models.VulnerabilityCloseApproval, userID uuid.UUID, notificationType models.NotificationType, title, message string, actorID *uuid.UUID) models.Notification {
+	resourceID := approval.VulnerabilityID
+	return models.Notification{
+		UserID:       userID,
+		Type:         notificationType,
+		Title:        title,
+		Message:      message,
+		ResourceType: "vulnerability",
+		ResourceID:   &resourceID,
+		ActorID:      actorID,
+	}
+}
+
+// getPendingForReview locks a pending request for review and enforces the two-person rule
+func (s *CloseApprovalService) getPendingForReview(tx *gorm.DB, vulnerabilityID, id, reviewerID uuid.UUID) (*models.VulnerabilityCloseApproval, error) {
+	var approval models.VulnerabilityCloseApproval
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&approval, "id = ? AND vulnerability_id = ?", id, vulnerabilityID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("close approval not found")
+		}
+		return nil, fmt.Errorf("failed to get close approval: %w", err)
+	}
+	if approval.Status != models.CloseApprovalPending {
+		return nil, fmt.Errorf("close approval is not pending (status: %s)", approval.Status)
+	}
+	if approval.RequestedByID == reviewerID {
+		return nil, fmt.Errorf("requesters cannot review their own close approval")
+	}
+	return &approval, nil
+}
+
+// ApproveRequest approves a pending request and closes the vulnerability, recording the
+// closure in the status history with both users
+func (s *CloseApprovalService) ApproveRequest(vulnerabilityID, id, reviewerID uuid.UUID, notes string) (*models.VulnerabilityCloseApproval, error) {
+	notes = strings.TrimSpace(notes)
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		approval, err := s.getPendingForReview(tx, vulnerabilityID, id, reviewerID)
+		if err != nil {
+			return err
+		}
+
+		var vulnerability models.Vulnerability
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&vulnerability, "id = ?", vulnerabilityID).Error; err != nil {
+			return fmt.Errorf("failed to get vulnerability: %w", err)
+		}
+		if err := NewVulnerabilityValidationService().ValidateStatusTransition(vulnerability.Status, models.StatusClosed); err != nil {
+			return fmt.Errorf("vulnerability can no longer be closed: %v", err)
+		}
+
+		now := time.Now()
+		if err := tx.Model(approval).Updates(map[string]interface{}{
+			"status":         models.CloseApprovalApproved,
+			"reviewed_by_id": reviewerID,
+			"reviewed_at":    now,
+			"review_notes":   notes,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to approve close approval: %w", err)
+		}
+
+		historyNotes := fmt.Sprintf("Closed with approval %s (requested by %s, approved by %s)", approval.ID, approval.RequestedByID, reviewerID)
+		if approval.Notes != "" {
+			historyNotes += ": " + approval.Notes
+		}
+		if err := tx.Create(&models.VulnerabilityStatusHistory{
+			VulnerabilityID: vulnerabilityID,
+			OldStatus:       vulnerability.Status,
+			NewStatus:       models.StatusClosed,
+			Notes:           historyNotes,
+			ChangedByID:     reviewerID,
+			ChangedAt:       now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to record status history: %w", err)
+		}
+
+		if err := tx.Model(&vulnerability).Update("status", models.StatusClosed).Error; err != nil {
+			return fmt.Errorf("failed to close vulnerability: %w", err)
+		}
+
+		return s.notificationService.CreateNotifications(tx, []models.Notification{
+			s.notification(approval, approval.RequestedByID,
+				models.NotificationTypeCloseApprovalApproved,
+				"Vulnerability closure approved",
+				vulnerability.Title,
+				&reviewerID,
+			),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("close_approval_id", id.String()).
+		Str("vulnerability_id", vulnerabilityID.String()).
+		Str("reviewed_by", reviewerID.String()).
+		Msg("Vulnerability close approval approved")
+
+	return s.getApproval(s.db, vulnerabilityID, id)
+}
+
+// RejectRequest rejects a pending request; the vulnerability is left unchanged
+func (s *CloseApprovalService) RejectRequest(vulnerabilityID, id, reviewerID uuid.UUID, notes string) (*models.VulnerabilityCloseApproval, error) {
+	notes = strings.TrimSpace(notes)
+	if notes == "" {
+		return nil, fmt.Errorf("review notes are required when rejecting")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		approval, err := s.getPendingForReview(tx, vulnerabilityID, id, reviewerID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(approval).Updates(map[string]interface{}{
+			"status":         models.CloseApprovalRejected,
+			"reviewed_by_id": reviewerID,
+			"reviewed_at":    time.Now(),
+			"review_notes":   notes,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to reject close approval: %w", err)
+		}
+
+		return s.notificationService.CreateNotifications(tx, []models.Notification{
+			s.notification(approval, approval.RequestedByID,
+				models.NotificationTypeCloseApprovalRejected,
+				"Vulnerability closure rejected",
+				notes,
+				&reviewerID,
+			),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("close_approval_id", id.String()).
+		Str("vulnerability_id", vulnerabilityID.String()).
+		Str("reviewed_by", reviewerID.String()).
+		Msg("Vulnerability close approval rejected")
+
+	return s.getApproval(s.db, vulnerabilityID, id)
+}
+
+// CancelRequest withdraws a pending request (requester only)
+func (s *CloseApprovalService) CancelRequest(vulnerabilityID, id, userID uuid.UUID) (*models.VulnerabilityCloseApproval, error) {
+	approval, err := s.getApproval(s.db, vulnerabilityID, id)
+	if err != nil {
+		return nil, err
+	}
+	if approval.RequestedByID != userID {
+		return nil, fmt.Errorf("only the requester can cancel this close approval")
+	}
+	if approval.Status != models.CloseApprovalPending {
+		return nil, fmt.Errorf("close approval is not pending (status: %s)", approval.Status)
+	}
+
+	result := s.db.Model(&models.VulnerabilityCloseApproval{}).
+		Where("id = ? AND status = ?", id, models.CloseApprovalPending).
+		Update("status", models.CloseApprovalCancelled)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to cancel close approval: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("close approval is not pending")
+	}
+
+	utils.Logger.Info().
+		Str("close_approval_id", id.String()).
+		Str("vulnerability_id", vulnerabilityID.String()).
+		Str("cancelled_by", userID.String()).
+		Msg("Vulnerability close approval cancelled")
+
+	return s.getApproval(s.db, vulnerabilityID, id)
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// ErrPreconditionFailed is returned when an update's If-Match version no longer matches the
+// stored record, i.e. another editor changed it first
+var ErrPreconditionFailed = errors.New("resource was modified by another request")
+
+// checkIfMatch verifies an If-Match header against a record's current version. An empty
+// header skips the check.
+func checkIfMatch(ifMatch string, updatedAt time.Time) error {
+	if ifMatch == "" || utils.ETagMatches(ifMatch, utils.ResourceETag(updatedAt), false) {
+		return nil
+	}
+	return ErrPreconditionFailed
+}
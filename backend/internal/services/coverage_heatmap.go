@@ -0,0 +1,202 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/i18n"
+	"github.com/cyops/cyops-backend/pkg/taxonomy"
+	"github.com/cyops/cyops-backend/pkg/telemetry"
+)
+
+// CoverageHeatmap counts the period's vulnerabilities per OWASP Top 10 category and ATT&CK
+// technique, by severity. Every category, tactic and catalog technique has a cell, so the grid
+// is the same from one period to the next.
+type CoverageHeatmap struct {
+	GeneratedAt          time.Time        `json:"generated_at"`
+	StartDate            time.Time        `json:"start_date"`
+	EndDate              time.Time        `json:"end_date"`
+	TotalVulnerabilities int64            `json:"total_vulnerabilities"`
+	Classified           int64            `json:"classified"` // Mapped to at least one category or technique
+	ClassifiedPercent    float64          `json:"classified_percent"`
+	OWASP                []CoverageCell   `json:"owasp"`   // In rank order
+	Tactics              []TacticCoverage `json:"tactics"` // In kill chain order
+}
+
+// TacticCoverage is the row of an ATT&CK tactic in the coverage heatmap
+type TacticCoverage struct {
+	Tactic     string         `json:"tactic"` // Empty for techniques outside the catalog
+	Name       string         `json:"name"`
+	Techniques []CoverageCell `json:"techniques"`
+}
+
+// CoverageCell counts the vulnerabilities classified under a category or technique
+type CoverageCell struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"` // Empty for techniques outside the catalog
+	Total    int64  `json:"total"`
+	Open     int64  `json:"open"`
+	Critical int64  `json:"critical"`
+	High     int64  `json:"high"`
+	Medium   int64  `json:"medium"`
+	Low      int64  `json:"low"`
+}
+
+// ClassificationCount counts the vulnerabilities of a severity classified under a code
+type ClassificationCount struct {
+	Framework string
+	Code      string
+	Severity  models.VulnerabilitySeverity
+	Total     int64
+	Open      int64
+}
+
+// Coverage builds the OWASP Top 10 and ATT&CK coverage heatmap of the vulnerabilities created
+// between startDate and endDate
+func (s *RemediationAnalyticsService) Coverage(startDate, endDate time.Time) (heatmap *CoverageHeatmap, err error) {
+	ctx, span := telemetry.StartSpan(s.db.Statement.Context, "RemediationAnalyticsService.Coverage", reportSpanAttributes(startDate, endDate)...)
+	defer func() { telemetry.EndSpan(span, err) }()
+	traced := s.WithContext(ctx)
+
+	return cached(s.db.Statement.Context, ActiveCache(), CacheGroupReports, reportCacheKey(s.filter.reportKey("coverage"), startDate, endDate), func() (*CoverageHeatmap, error) {
+		var totals struct {
+			Total      int64
+			Classified int64
+		}
+		if err := traced.db.Model(&models.Vulnerability{}).
+			Scopes(traced.filter.scopeVulnerabilities("vulnerabilities.id")).
+			Select(`COUNT(*) AS total,
+				COUNT(*) FILTER (WHERE EXISTS (SELECT 1 FROM vulnerability_classifications WHERE vulnerability_classifications.vulnerability_id = vulnerabilities.id)) AS classified`).
+			Where("vulnerabilities.created_at BETWEEN ? AND ?", startDate, endDate).
+			Scan(&totals).Error; err != nil {
+			return nil, fmt.Errorf("failed to count classified vulnerabilities: %w", err)
+		}
+
+		var counts []ClassificationCount
+		if err := traced.db.Model(&models.Vulnerability{}).
+			Scopes(traced.filter.scopeVulnerabilities("vulnerabilities.id")).
+			Select(`vulnerability_classifications.framework, vulnerability_classifications.code, vulnerabilities.severity,
+				COUNT(*) AS total,
+				COUNT(*) FILTER (WHERE vulnerabilities.status IN ?) AS open`, openStatuses).
+			Joins("JOIN vulnerability_classifications ON vulnerability_classifications.vulnerability_id = vulnerabilities.id").
+			Where("vulnerabilities.created_at BETWEEN ? AND ?", startDate, endDate).
+			Group("vulnerability_classifications.framework, vulnerability_classifications.code, vulnerabilities.severity").
+			Scan(&counts).Error; err != nil {
+			return nil, fmt.Errorf("failed to count vulnerabilities by classification: %w", err)
+		}
+
+		heatmap := BuildCoverageHeatmap(counts, totals.Total, totals.Classified)
+		heatmap.GeneratedAt = time.Now()
+		heatmap.StartDate = startDate
+		heatmap.EndDate = endDate
+		return &heatmap, nil
+	})
+}
+
+// BuildCoverageHeatmap lays the classification counts out on the OWASP Top 10 and ATT&CK
+// grids. ATT&CK techniques outside the catalog are filed under their parent's tactic, or in a
+// last row without a tactic.
+func BuildCoverageHeatmap(counts []ClassificationCount, total, classified int64) CoverageHeatmap {
+	heatmap := CoverageHeatmap{
+		TotalVulnerabilities: total,
+		Classified:           classified,
+		OWASP:                []CoverageCell{},
+		Tactics:              []TacticCoverage{},
+	}
+	if total > 0 {
+		heatmap.ClassifiedPercent = roundDays(float64(classified) / float64(total) * 100)
+	}
+
+	cells := map[string]map[string]*CoverageCell{
+		taxonomy.FrameworkOWASP:  {},
+		taxonomy.FrameworkATTACK: {},
+	}
+	for _, count := range counts {
+		byCode, ok := cells[count.Framework]
+		if !ok {
+			continue
+		}
+		cell, ok := byCode[count.Code]
+		if !ok {
+			entry, _ := taxonomy.Lookup(count.Framework, count.Code)
+			cell = &CoverageCell{Code: count.Code, Name: entry.Name}
+			byCode[count.Code] = cell
+		}
+		cell.Total += count.Total
+		cell.Open += count.Open
+		switch count.Severity {
+		case models.SeverityCritical:
+			cell.Critical += count.Total
+		case models.SeverityHigh:
+			cell.High += count.Total
+		case models.SeverityMedium:
+			cell.Medium += count.Total
+		case models.SeverityLow:
+			cell.Low += count.Total
+		}
+	}
+
+	// cell returns the counted cell of a catalog entry, or an empty one
+	cell := func(framework string, entry taxonomy.Entry) CoverageCell {
+		if counted, ok := cells[framework][entry.ID]; ok {
+			delete(cells[framework], entry.ID)
+			return *counted
+		}
+		return CoverageCell{Code: entry.ID, Name: entry.Name}
+	}
+
+	for _, entry := range taxonomy.Entries(taxonomy.FrameworkOWASP) {
+		heatmap.OWASP = append(heatmap.OWASP, cell(taxonomy.FrameworkOWASP, entry))
+	}
+
+	rows := map[string]*TacticCoverage{}
+	for _, tactic := range taxonomy.Tactics() {
+		rows[tactic.ID] = &TacticCoverage{Tactic: tactic.ID, Name: tactic.Name, Techniques: []CoverageCell{}}
+	}
+	for _, entry := range taxonomy.Entries(taxonomy.FrameworkATTACK) {
+		row := rows[entry.Tactic]
+		row.Techniques = append(row.Techniques, cell(taxonomy.FrameworkATTACK, entry))
+	}
+	// Sub-techniques and techniques outside the catalog, in code order
+	other := &TacticCoverage{Techniques: []CoverageCell{}}
+	for _, code := range sortedCodes(cells[taxonomy.FrameworkATTACK]) {
+		row, ok := rows[taxonomy.TacticOf(code)]
+		if !ok {
+			row = other
+		}
+		row.Techniques = append(row.Techniques, *cells[taxonomy.FrameworkATTACK][code])
+	}
+	for _, tactic := range taxonomy.Tactics() {
+		heatmap.Tactics = append(heatmap.Tactics, *rows[tactic.ID])
+	}
+	if len(other.Techniques) > 0 {
+		heatmap.Tactics = append(heatmap.Tactics, *other)
+	}
+	return heatmap
+}
+
+// sortedCodes returns the codes of cells in ascending order
+func sortedCodes(cells map[string]*CoverageCell) []string {
+	codes := make([]string, 0, len(cells))
+	for code := range cells {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Localized returns a copy of the heatmap with the row of techniques outside the catalog named
+// in lang. Category, tactic and technique names are kept as the frameworks publish them.
+func (h CoverageHeatmap) Localized(lang string) CoverageHeatmap {
+	tactics := make([]TacticCoverage, len(h.Tactics))
+	for i, tactic := range h.Tactics {
+		if tactic.Tactic == "" {
+			tactic.Name = i18n.T(lang, "report.other_techniques")
+		}
+		tactics[i] = tactic
+	}
+	h.Tactics = tactics
+	return h
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// CriticalityScoringService derives asset criticality from configurable weights
+type CriticalityScoringService struct {
+	db *gorm.DB
+}
+
+// NewCriticalityScoringService creates a new criticality scoring service
+func NewCriticalityScoringService(db *gorm.DB) *CriticalityScoringService {
+	return &CriticalityScoringService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *CriticalityScoringService) WithContext(ctx context.Context) *CriticalityScoringService {
+	return &CriticalityScoringService{db: s.db.WithContext(ctx)}
+}
+
+// CriticalityProfileRequest represents an update to an organization's scoring profile
+type CriticalityProfileRequest struct {
+	Enabled *bool                      `json:"enabled,omitempty"`
+	Weights *models.CriticalityWeights `json:"weights,omitempty"`
+}
+
+// CriticalityScoreFactor is one attribute's contribution to a criticality score
+type CriticalityScoreFactor struct {
+	Factor string `json:"factor"` // environment, public_ip, business_service or tag
+	Value  string `json:"value"`
+	Points int    `json:"points"`
+}
+
+// AssetCriticalityScore explains how an asset's criticality is derived
+type AssetCriticalityScore struct {
+	AssetID            uuid.UUID                    `json:"asset_id"`
+	Score              int                          `json:"score"`
+	ScoredCriticality  models.AssetCriticality      `json:"scored_criticality"`
+	CurrentCriticality *models.AssetCriticality     `json:"current_criticality,omitempty"`
+	Override           bool                         `json:"override"` // Criticality was set manually and is not rescored
+	ScoringEnabled     bool                         `json:"scoring_enabled"`
+	Factors            []CriticalityScoreFactor     `json:"factors"`
+	Thresholds         models.CriticalityThresholds `json:"thresholds"`
+}
+
+// ScoreAssetCriticality scores an asset (with its tags loaded) given the business services it
+// supports, and lists the contributing factors in a stable order: environment, exposure,
+// business service, then tags alphabetically
+func ScoreAssetCriticality(weights models.CriticalityWeights, asset models.AffectedSystem, businessServices []models.BusinessService) (int, []CriticalityScoreFactor) {
+	factors := []CriticalityScoreFactor{}
+	if points, ok := weights.Environments[string(asset.Environment)]; ok && points != 0 {
+		factors = append(factors, CriticalityScoreFactor{Factor: "environment", Value: string(asset.Environment), Points: points})
+	}
+
+	if weights.PublicIP != 0 {
+		for _, ip := range append([]string{asset.IPAddress}, asset.IPAddresses...) {
+			if IsPublicIP(ip) {
+				factors = append(factors, CriticalityScoreFactor{Factor: "public_ip", Value: ip, Points: weights.PublicIP})
+				break
+			}
+		}
+	}
+
+	var best *CriticalityScoreFactor
+	for _, service := range businessServices {
+		points, ok := weights.BusinessTiers[string(service.Tier)]
+		if !ok || points == 0 || (best != nil && points <= best.Points) {
+			continue
+		}
+		best = &CriticalityScoreFactor{Factor: "business_service", Value: fmt.Sprintf("%s (%s)", service.Name, service.Tier), Points: points}
+	}
+	if best != nil {
+		factors = append(factors, *best)
+	}
+
+	tags := make([]string, 0, len(asset.Tags))
+	for _, tag := range asset.Tags {
+		tags = append(tags, tag.Tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		if points, ok := weights.Tags[tag]; ok && points != 0 {
+			factors = append(factors, CriticalityScoreFactor{Factor: "tag", Value: tag, Points: points})
+		}
+	}
+
+	score := 0
+	for _, f := range factors {
+		score += f.Points
+	}
+	if score < 0 {
+		score = 0
+	}
+	return score, factors
+}
+
+// IsPublicIP reports whether ip is a publicly routable unicast address
+func IsPublicIP(ip string) bool {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil || parsed.IsPrivate() || parsed.IsLoopback() || parsed.IsLinkLocalUnicast() ||
+		parsed.IsUnspecified() || parsed.IsMulticast() {
+		return false
+	}
+	// Carrier-grade NAT (RFC 6598) is not reachable from the internet either
+	_, cgnat, _ := net.ParseCIDR("100.64.0.0/10")
+	return !cgnat.Contains(parsed)
+}
+
+// ValidateCriticalityWeights normalizes tag keys and checks that thresholds are ordered
+func ValidateCriticalityWeights(weights *models.CriticalityWeights) error {
+	t := weights.Thresholds
+	if t.Medium < 0 || t.High <= t.Medium || t.Critical <= t.High {
+		return fmt.Errorf("invalid thresholds: must satisfy 0 <= medium < high < critical")
+	}
+
+	environments := make(map[string]int, len(weights.Environments))
+	for env, points := range weights.Environments {
+		env = strings.ToUpper(strings.TrimSpace(env))
+		switch models.Environment(env) {
+		case models.EnvProduction, models.EnvStaging, models.EnvDevelopment, models.EnvTest:
+		default:
+			return fmt.Errorf("invalid environment: %s", env)
+		}
+		environments[env] = points
+	}
+	weights.Environments = environments
+
+	tags := make(map[string]int, len(weights.Tags))
+	for tag, points := range weights.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return fmt.Errorf("invalid tag: tag is required")
+		}
+		tags[tag] = points
+	}
+	weights.Tags = tags
+
+	tiers := make(map[string]int, len(weights.BusinessTiers))
+	for tier, points := range weights.BusinessTiers {
+		tier = strings.ToUpper(strings.TrimSpace(tier))
+		switch models.BusinessServiceTier(tier) {
+		case models.BusinessTier1, models.BusinessTier2, models.BusinessTier3, models.BusinessTier4:
+		default:
+			return fmt.Errorf("invalid business tier: %s", tier)
+		}
+		tiers[tier] = points
+	}
+	weights.BusinessTiers = tiers
+	return nil
+}
+
+// GetProfile returns the organization's scoring profile, or an unsaved disabled profile with
+// the default weights if it has none
+func (s *CriticalityScoringService) GetProfile() (*models.CriticalityScoringProfile, error) {
+	var profile models.CriticalityScoringProfile
+	err := s.db.Preload("UpdatedBy").First(&profile).Error
+	if err == gorm.ErrRecordNotFound {
+		return &models.CriticalityScoringProfile{Config: models.DefaultCriticalityWeights()}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scoring profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// UpdateProfile creates or updates the organization's scoring profile; assets are rescored by
+// the background job
+func (s *CriticalityScoringService) UpdateProfile(req CriticalityProfileRequest, updatedByID uuid.UUID) (*models.CriticalityScoringProfile, error) {
+	profile, err := s.GetProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Enabled != nil {
+		profile.Enabled = *req.Enabled
+	}
+	if req.Weights != nil {
+		profile.Config = *req.Weights
+	}
+	if err := ValidateCriticalityWeights(&profile.Config); err != nil {
+		return nil, err
+	}
+	profile.UpdatedByID = &updatedByID
+	profile.UpdatedBy = nil
+
+	if err := s.db.Save(profile).Error; err != nil {
+		return nil, fmt.Errorf("failed to save scoring profile: %w", err)
+	}
+	criticalityChanges.mark(profile.OrgID)
+
+	utils.Logger.Info().
+		Str("profile_id", profile.ID.String()).
+		Bool("enabled", profile.Enabled).
+		Str("updated_by", updatedByID.String()).
+		Msg("Criticality scoring profile updated")
+
+	return s.GetProfile()
+}
+
+// ScoreAsset explains the score of one asset under the organization's profile
+func (s *CriticalityScoringService) ScoreAsset(assetID uuid.UUID) (*AssetCriticalityScore, error) {
+	var asset models.AffectedSystem
+	if err := s.db.Preload("Tags").First(&asset, "id = ?", assetID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("asset not found")
+		}
+		return nil, fmt.Errorf("failed to get asset: %w", err)
+	}
+
+	profile, err := s.GetProfile()
+	if err != nil {
+		return nil, err
+	}
+	businessServices, err := assetBusinessServices(s.db, []uuid.UUID{asset.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	score, factors := ScoreAssetCriticality(profile.Config, asset, businessServices[asset.ID])
+	return &AssetCriticalityScore{
+		AssetID:            asset.ID,
+		Score:              score,
+		ScoredCriticality:  profile.Config.Thresholds.Criticality(score),
+		CurrentCriticality: asset.Criticality,
+		Override:           asset.CriticalityOverride,
+		ScoringEnabled:     profile.Enabled,
+		Factors:            factors,
+		Thresholds:         profile.Config.Thresholds,
+	}, nil
+}
+
+// RescoreStale rescores the assets of every organization with an enabled profile whose assets
+// or profile changed since the last run. It runs without a tenant context and returns the
+// number of assets whose score or criticality changed.
+func (s *CriticalityScoringService) RescoreStale() (int, error) {
+	all, orgIDs := criticalityChanges.take()
+
+	query := s.db.Where("enabled = ?", true)
+	if !all {
+		if len(orgIDs) == 0 {
+			return 0, nil
+		}
+		query = query.Where("org_id IN ?", orgIDs)
+	}
+
+	var profiles []models.CriticalityScoringProfile
+	if err := query.Find(&profiles).Error; err != nil {
+		criticalityChanges.restore(all, orgIDs)
+		return 0, fmt.Errorf("failed to list scoring profiles: %w", err)
+	}
+
+	rescored := 0
+	for i := range profiles {
+		count, err := s.rescoreOrg(&profiles[i])
+		if err != nil {
+			criticalityChanges.restore(false, orgIDsOf(profiles[i].OrgID))
+			utils.Logger.Error().Err(err).Str("profile_id", profiles[i].ID.String()).Msg("Failed to rescore asset criticality")
+			continue
+		}
+		rescored += count
+	}
+	return rescored, nil
+}
+
+// rescoreOrg applies a profile to the organization's assets that have no override
+func (s *CriticalityScoringService) rescoreOrg(profile *models.CriticalityScoringProfile) (int, error) {
+	db := s.db
+	if profile.OrgID != nil {
+		db = s.db.WithContext(tenant.WithOrg(s.db.Statement.Context, *profile.OrgID))
+	}
+
+	changed := 0
+	var batch []models.AffectedSystem
+	err := db.Preload("Tags").
+		Where("criticality_override = ?", false).
+		FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			ids := make([]uuid.UUID, len(batch))
+			for i := range batch {
+				ids[i] = batch[i].ID
+			}
+			businessServices, err := assetBusinessServices(db, ids)
+			if err != nil {
+				return err
+			}
+
+			for _, asset := range batch {
+				score, _ := ScoreAssetCriticality(profile.Config, asset, businessServices[asset.ID])
+				criticality := profile.Config.Thresholds.Criticality(score)
+				if asset.CriticalityScore != nil && *asset.CriticalityScore == score &&
+					asset.Criticality != nil && *asset.Criticality == criticality {
+					continue
+				}
+				if err := db.Model(&models.AffectedSystem{}).Where("id = ?", asset.ID).Updates(map[string]interface{}{
+					"criticality_score": score,
+					"criticality":       criticality,
+				}).Error; err != nil {
+					return fmt.Errorf("failed to update asset criticality: %w", err)
+				}
+				changed++
+			}
+			return nil
+		}).Error
+	return changed, err
+}
+
+// assetBusinessServices loads the business services each asset supports
+func assetBusinessServices(db *gorm.DB, assetIDs []uuid.UUID) (map[uuid.UUID][]models.BusinessService, error) {
+	var rows []struct {
+		AssetID uuid.UUID
+		Name    string
+		Tier    models.BusinessServiceTier
+	}
+	if err := db.Model(&models.BusinessService{}).
+		Select("business_service_assets.asset_id, business_services.name, business_services.tier").
+		Joins("JOIN business_service_assets ON business_service_assets.service_id = business_services.id").
+		Where("business_service_assets.asset_id IN ?", assetIDs).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get asset business services: %w", err)
+	}
+
+	byAsset := make(map[uuid.UUID][]models.BusinessService, len(rows))
+	for _, row := range rows {
+		byAsset[row.AssetID] = append(byAsset[row.AssetID], models.BusinessService{Name: row.Name, Tier: row.Tier})
+	}
+	return byAsset, nil
+}
+
+// criticalityChanges tracks the organizations whose assets need rescoring
+var criticalityChanges = newOrgChangeSet()
+
+// criticalitySourceTables are the tables whose writes can change an asset's score
+var criticalitySourceTables = map[string]bool{
+	"affected_systems":        true,
+	"asset_tags":              true,
+	"business_services":       true,
+	"business_service_assets": true,
+}
+
+// RegisterCriticalityCallbacks installs GORM callbacks that mark asset criticality stale
+// whenever assets, their tags or their business services are written; RescoreStale applies the changes.
+func RegisterCriticalityCallbacks(db *gorm.DB) error {
+	markStale := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Schema == nil || !criticalitySourceTables[tx.Statement.Schema.Table] {
+			return
+		}
+		if orgID, ok := tenant.OrgFromContext(tx.Statement.Context); ok {
+			criticalityChanges.mark(&orgID)
+			return
+		}
+		criticalityChanges.mark(nil)
+	}
+
+	if err := db.Callback().Create().After("gorm:create").Register("criticality:mark_create", markStale); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("criticality:mark_update", markStale); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("criticality:mark_delete", markStale)
+}
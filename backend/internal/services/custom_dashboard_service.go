@@ -0,0 +1,518 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/telemetry"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Dashboard composition limits
+const (
+	MaxDashboardWidgets       = 24
+	DefaultTrendLineDays      = 30
+	DefaultTopRiskyAssetLimit = 10
+	MaxTopRiskyAssetLimit     = 50
+	SLADueSoonDays            = 3 // Unresolved vulnerabilities this close to their SLA deadline count as due soon
+)
+
+// dashboardSeverities orders severity datasets from most to least severe
+var dashboardSeverities = []models.VulnerabilitySeverity{
+	models.SeverityCritical,
+	models.SeverityHigh,
+	models.SeverityMedium,
+	models.SeverityLow,
+}
+
+// CustomDashboardService manages user-composed dashboards and computes their widget data
+type CustomDashboardService struct {
+	db *gorm.DB
+}
+
+// NewCustomDashboardService creates a new custom dashboard service
+func NewCustomDashboardService(db *gorm.DB) *CustomDashboardService {
+	return &CustomDashboardService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *CustomDashboardService) WithContext(ctx context.Context) *CustomDashboardService {
+	return &CustomDashboardService{db: s.db.WithContext(ctx)}
+}
+
+// NormalizeDashboardWidgets validates widget definitions, assigns IDs to new widgets and fills
+// in default options
+func NormalizeDashboardWidgets(widgets []models.DashboardWidget) ([]models.DashboardWidget, error) {
+	if len(widgets) > MaxDashboardWidgets {
+		return nil, fmt.Errorf("invalid widgets, a dashboard can have at most %d widgets", MaxDashboardWidgets)
+	}
+
+	normalized := make([]models.DashboardWidget, 0, len(widgets))
+	seen := make(map[string]bool, len(widgets))
+	for i, widget := range widgets {
+		widget.ID = strings.TrimSpace(widget.ID)
+		if widget.ID == "" {
+			widget.ID = uuid.New().String()
+		}
+		if seen[widget.ID] {
+			return nil, fmt.Errorf("invalid widgets, duplicate widget id %q", widget.ID)
+		}
+		seen[widget.ID] = true
+		widget.Title = strings.TrimSpace(widget.Title)
+
+		options := &widget.Options
+		switch widget.Type {
+		case models.WidgetTrendLine:
+			if options.Days == 0 {
+				options.Days = DefaultTrendLineDays
+			}
+			if options.Days < 1 || options.Days > MetricsBackfillDays {
+				return nil, fmt.Errorf("invalid days for widget %d, must be between 1 and %d", i+1, MetricsBackfillDays)
+			}
+		case models.WidgetTopRiskyAssets:
+			if options.Limit == 0 {
+				options.Limit = DefaultTopRiskyAssetLimit
+			}
+			if options.Limit < 1 || options.Limit > MaxTopRiskyAssetLimit {
+				return nil, fmt.Errorf("invalid limit for widget %d, must be between 1 and %d", i+1, MaxTopRiskyAssetLimit)
+			}
+		case models.WidgetSeverityDonut, models.WidgetSLAStatus:
+		default:
+			return nil, fmt.Errorf("invalid type for widget %d, must be one of: severity_donut, trend_line, top_risky_assets, sla_status", i+1)
+		}
+
+		// Options the widget type does not use are dropped so equal widgets share one query
+		if widget.Type != models.WidgetTrendLine {
+			options.Days = 0
+		}
+		if widget.Type != models.WidgetTopRiskyAssets {
+			options.Limit = 0
+		}
+		if widget.Type == models.WidgetTrendLine {
+			options.Environment = ""
+		}
+		options.Environment = models.Environment(strings.ToUpper(string(options.Environment)))
+		switch options.Environment {
+		case "", models.EnvProduction, models.EnvStaging, models.EnvDevelopment, models.EnvTest:
+		default:
+			return nil, fmt.Errorf("invalid environment for widget %d, must be one of: PRODUCTION, STAGING, DEVELOPMENT, TEST", i+1)
+		}
+
+		if layout := widget.Layout; layout != nil {
+			if layout.X < 0 || layout.Y < 0 || layout.Width < 1 || layout.Height < 1 {
+				return nil, fmt.Errorf("invalid layout for widget %d, position must not be negative and size must be positive", i+1)
+			}
+		}
+
+		normalized = append(normalized, widget)
+	}
+	return normalized, nil
+}
+
+// DashboardRequest represents a create or update dashboard request
+type DashboardRequest struct {
+	Name        *string                   `json:"name,omitempty"`
+	Description *string                   `json:"description,omitempty"`
+	Widgets     *[]models.DashboardWidget `json:"widgets,omitempty"`
+	Shared      *bool                     `json:"shared,omitempty"`
+}
+
+// ListDashboards returns the user's own and shared dashboards
+func (s *CustomDashboardService) ListDashboards(userID uuid.UUID) ([]models.Dashboard, error) {
+	var dashboards []models.Dashboard
+	if err := visibleTo(s.db.Model(&models.Dashboard{}), userID).
+		Preload("Owner").
+		Order("name ASC").
+		Find(&dashboards).Error; err != nil {
+		return nil, fmt.Errorf("failed to list dashboards: %w", err)
+	}
+	return dashboards, nil
+}
+
+// GetDashboard returns a dashboard visible to the user
+func (s *CustomDashboardService) GetDashboard(id, userID uuid.UUID) (*models.Dashboard, error) {
+	var dashboard models.Dashboard
+	if err := visibleTo(s.db.Preload("Owner"), userID).First(&dashboard, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("dashboard not found")
+		}
+		return nil, fmt.Errorf("failed to get dashboard: %w", err)
+	}
+	return &dashboard, nil
+}
+
+// getOwnedDashboard loads a dashboard and verifies the user owns it
+func (s *CustomDashboardService) getOwnedDashboard(id, userID uuid.UUID) (*models.Dashboard, error) {
+	dashboard, err := s.GetDashboard(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if dashboard.OwnerID != userID {
+		return nil, fmt.Errorf("only the owner can modify this dashboard")
+	}
+	return dashboard, nil
+}
+
+// CreateDashboard creates a dashboard owned by the user
+func (s *CustomDashboardService) CreateDashboard(req DashboardRequest, ownerID uuid.UUID) (*models.Dashboard, error) {
+	dashboard := &models.Dashboard{OwnerID: ownerID}
+	if req.Name != nil {
+		dashboard.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		dashboard.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Shared != nil {
+		dashboard.Shared = *req.Shared
+	}
+	if dashboard.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if req.Widgets != nil {
+		widgets, err := NormalizeDashboardWidgets(*req.Widgets)
+		if err != nil {
+			return nil, err
+		}
+		dashboard.WidgetList = widgets
+	}
+
+	if err := s.db.Create(dashboard).Error; err != nil {
+		return nil, fmt.Errorf("failed to create dashboard: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("dashboard_id", dashboard.ID.String()).
+		Str("owner_id", ownerID.String()).
+		Int("widgets", len(dashboard.WidgetList)).
+		Msg("Dashboard created")
+
+	return s.GetDashboard(dashboard.ID, ownerID)
+}
+
+// UpdateDashboard updates a dashboard (owner only); widgets are replaced as a whole
+func (s *CustomDashboardService) UpdateDashboard(id, userID uuid.UUID, req DashboardRequest) (*models.Dashboard, error) {
+	dashboard, err := s.getOwnedDashboard(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		dashboard.Name = strings.TrimSpace(*req.Name)
+		if dashboard.Name == "" {
+			return nil, fmt.Errorf("name is required")
+		}
+	}
+	if req.Description != nil {
+		dashboard.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Widgets != nil {
+		widgets, err := NormalizeDashboardWidgets(*req.Widgets)
+		if err != nil {
+			return nil, err
+		}
+		dashboard.WidgetList = widgets
+	}
+	if req.Shared != nil {
+		dashboard.Shared = *req.Shared
+	}
+
+	dashboard.Owner = nil
+	if err := s.db.Save(dashboard).Error; err != nil {
+		return nil, fmt.Errorf("failed to update dashboard: %w", err)
+	}
+
+	return s.GetDashboard(id, userID)
+}
+
+// DeleteDashboard soft deletes a dashboard (owner only)
+func (s *CustomDashboardService) DeleteDashboard(id, userID uuid.UUID) error {
+	dashboard, err := s.getOwnedDashboard(id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(dashboard).Error; err != nil {
+		return fmt.Errorf("failed to delete dashboard: %w", err)
+	}
+	return nil
+}
+
+// DashboardWidgetData is the dataset computed for one widget
+type DashboardWidgetData struct {
+	ID    string                     `json:"id"`
+	Type  models.DashboardWidgetType `json:"type"`
+	Title string                     `json:"title,omitempty"`
+	Data  interface{}                `json:"data"`
+}
+
+// DashboardData holds the datasets of every widget of a dashboard
+type DashboardData struct {
+	DashboardID uuid.UUID             `json:"dashboard_id"`
+	GeneratedAt time.Time             `json:"generated_at"`
+	Widgets     []DashboardWidgetData `json:"widgets"`
+}
+
+// SeverityCount is a severity_donut slice
+type SeverityCount struct {
+	Severity models.VulnerabilitySeverity `json:"severity"`
+	Count    int64                        `json:"count"`
+}
+
+// TrendLinePoint is one day of a trend_line widget
+type TrendLinePoint struct {
+	Date                    time.Time `json:"date"`
+	OpenCount               int64     `json:"open_count"` // Open and in progress
+	CriticalCount           int64     `json:"critical_count"`
+	HighCount               int64     `json:"high_count"`
+	NewVulnerabilities      int64     `json:"new_vulnerabilities"`
+	ResolvedVulnerabilities int64     `json:"resolved_vulnerabilities"`
+}
+
+// RiskyAsset is a top_risky_assets row
+type RiskyAsset struct {
+	ID                  uuid.UUID                `json:"id"`
+	Hostname            string                   `json:"hostname,omitempty"`
+	IPAddress           string                   `json:"ip_address,omitempty"`
+	Environment         models.Environment       `json:"environment"`
+	Criticality         *models.AssetCriticality `json:"criticality,omitempty"`
+	OpenVulnerabilities int64                    `json:"open_vulnerabilities"`
+	Critical            int64                    `json:"critical"`
+	High                int64                    `json:"high"`
+	Medium              int64                    `json:"medium"`
+	Low                 int64                    `json:"low"`
+	RiskScore           float64                  `json:"risk_score"` // Sum of risk weights of open vulnerabilities
+}
+
+// SLAStatusRow counts the unresolved vulnerabilities of one severity against its SLA
+type SLAStatusRow struct {
+	Severity       models.VulnerabilitySeverity `json:"severity"`
+	TargetDays     int                          `json:"target_days"`
+	Open           int64                        `json:"open"`
+	WithinSLA      int64                        `json:"within_sla"`
+	DueSoon        int64                        `json:"due_soon"` // Within SLA, but due within SLADueSoonDays
+	Breached       int64                        `json:"breached"`
+	ComplianceRate float64                      `json:"compliance_rate"` // Percentage within SLA
+}
+
+// SLAStatus is the sla_status widget dataset
+type SLAStatus struct {
+	Severities     []SLAStatusRow `json:"severities"`
+	Open           int64          `json:"open"`
+	Breached       int64          `json:"breached"`
+	ComplianceRate float64        `json:"compliance_rate"`
+}
+
+// GetDashboardData computes the datasets of all widgets of a dashboard in one batch. Widgets
+// with the same type and options share one query, and the batch is cached until the
+// underlying data or the dashboard changes.
+func (s *CustomDashboardService) GetDashboardData(id, userID uuid.UUID) (data *DashboardData, err error) {
+	ctx, span := telemetry.StartSpan(s.db.Statement.Context, "CustomDashboardService.GetDashboardData")
+	defer func() { telemetry.EndSpan(span, err) }()
+	traced := s.WithContext(ctx)
+
+	dashboard, err := traced.GetDashboard(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("dashboard:%s:%d", dashboard.ID, dashboard.UpdatedAt.UnixNano())
+	return cached(s.db.Statement.Context, ActiveCache(), CacheGroupReports, key, func() (*DashboardData, error) {
+		now := time.Now()
+		data := &DashboardData{
+			DashboardID: dashboard.ID,
+			GeneratedAt: now,
+			Widgets:     make([]DashboardWidgetData, 0, len(dashboard.WidgetList)),
+		}
+
+		datasets := make(map[string]interface{})
+		for _, widget := range dashboard.WidgetList {
+			spec := fmt.Sprintf("%s:%+v", widget.Type, widget.Options)
+			dataset, ok := datasets[spec]
+			if !ok {
+				var err error
+				if dataset, err = traced.widgetData(widget, now); err != nil {
+					return nil, fmt.Errorf("failed to compute widget %s: %w", widget.ID, err)
+				}
+				datasets[spec] = dataset
+			}
+			data.Widgets = append(data.Widgets, DashboardWidgetData{
+				ID:    widget.ID,
+				Type:  widget.Type,
+				Title: widget.Title,
+				Data:  dataset,
+			})
+		}
+		return data, nil
+	})
+}
+
+// widgetData computes the dataset of a single widget
+func (s *CustomDashboardService) widgetData(widget models.DashboardWidget, now time.Time) (interface{}, error) {
+	switch widget.Type {
+	case models.WidgetSeverityDonut:
+		return s.severityDonut(widget.Options)
+	case models.WidgetTrendLine:
+		return s.trendLine(widget.Options)
+	case models.WidgetTopRiskyAssets:
+		return s.topRiskyAssets(widget.Options)
+	case models.WidgetSLAStatus:
+		return s.slaStatus(widget.Options, now)
+	}
+	return nil, fmt.Errorf("unsupported widget type %q", widget.Type)
+}
+
+// unresolvedVulnerabilities selects unresolved vulnerabilities, restricted to those affecting an
+// asset in the environment when one is set
+func (s *CustomDashboardService) unresolvedVulnerabilities(environment models.Environment) *gorm.DB {
+	query := s.db.Model(&models.Vulnerability{}).Where("vulnerabilities.status IN ?", unresolvedStatuses)
+	if environment != "" {
+		assets := s.db.Table("vulnerability_affected_systems").
+			Select("1").
+			Joins("JOIN affected_systems ON affected_systems.id = vulnerability_affected_systems.affected_system_id").
+			Where("vulnerability_affected_systems.vulnerability_id = vulnerabilities.id").
+			Where("vulnerability_affected_systems.patched_at IS NULL AND affected_systems.deleted_at IS NULL").
+			Where("affected_systems.environment = ?", environment)
+		query = query.Where("EXISTS (?)", assets)
+	}
+	return query
+}
+
+// severityDonut counts unresolved vulnerabilities per severity
+func (s *CustomDashboardService) severityDonut(options models.DashboardWidgetOptions) ([]SeverityCount, error) {
+	var rows []SeverityCount
+	if err := s.unresolvedVulnerabilities(options.Environment).
+		Select("vulnerabilities.severity, COUNT(*) AS count").
+		Group("vulnerabilities.severity").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count vulnerabilities by severity: %w", err)
+	}
+
+	counts := make(map[models.VulnerabilitySeverity]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Severity] = row.Count
+	}
+	severities := append(append([]models.VulnerabilitySeverity{}, dashboardSeverities...), models.SeverityNone)
+	slices := make([]SeverityCount, 0, len(severities))
+	for _, severity := range severities {
+		slices = append(slices, SeverityCount{Severity: severity, Count: counts[severity]})
+	}
+	return slices, nil
+}
+
+// trendLine reads the daily metric snapshots of the last days
+func (s *CustomDashboardService) trendLine(options models.DashboardWidgetOptions) ([]TrendLinePoint, error) {
+	snapshots, err := NewMetricsSnapshotService(s.db).ListSnapshots(options.Days)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]TrendLinePoint, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		points = append(points, TrendLinePoint{
+			Date:                    snapshot.SnapshotDate,
+			OpenCount:               snapshot.OpenCount + snapshot.InProgressCount,
+			CriticalCount:           snapshot.CriticalCount,
+			HighCount:               snapshot.HighCount,
+			NewVulnerabilities:      snapshot.NewVulnerabilities,
+			ResolvedVulnerabilities: snapshot.ResolvedVulnerabilities,
+		})
+	}
+	return points, nil
+}
+
+// topRiskyAssets ranks assets by the risk weights of their unpatched, unresolved vulnerabilities
+func (s *CustomDashboardService) topRiskyAssets(options models.DashboardWidgetOptions) ([]RiskyAsset, error) {
+	weightSQL, args := vulnerabilityRiskWeightSQL()
+	riskSQL := "SUM" + weightSQL
+	for _, severity := range dashboardSeverities {
+		args = append(args, severity)
+	}
+
+	query := s.db.Model(&models.AffectedSystem{}).
+		Select(`affected_systems.id, affected_systems.hostname, affected_systems.ip_address,
+			affected_systems.environment, affected_systems.criticality,
+			COUNT(*) AS open_vulnerabilities,
+			`+riskSQL+` AS risk_score,
+			COUNT(*) FILTER (WHERE vulnerabilities.severity = ?) AS critical,
+			COUNT(*) FILTER (WHERE vulnerabilities.severity = ?) AS high,
+			COUNT(*) FILTER (WHERE vulnerabilities.severity = ?) AS medium,
+			COUNT(*) FILTER (WHERE vulnerabilities.severity = ?) AS low`, args...).
+		Joins("JOIN vulnerability_affected_systems ON vulnerability_affected_systems.affected_system_id = affected_systems.id AND vulnerability_affected_systems.patched_at IS NULL").
+		Joins("JOIN vulnerabilities ON vulnerabilities.id = vulnerability_affected_systems.vulnerability_id AND vulnerabilities.deleted_at IS NULL").
+		Where("vulnerabilities.status IN ?", unresolvedStatuses)
+	if options.Environment != "" {
+		query = query.Where("affected_systems.environment = ?", options.Environment)
+	}
+
+	var assets []RiskyAsset
+	if err := query.
+		Group("affected_systems.id").
+		Order("risk_score DESC, open_vulnerabilities DESC, affected_systems.hostname ASC").
+		Limit(options.Limit).
+		Scan(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to rank risky assets: %w", err)
+	}
+	return assets, nil
+}
+
+// slaStatus counts unresolved vulnerabilities within, near and past their SLA per severity
+func (s *CustomDashboardService) slaStatus(options models.DashboardWidgetOptions, now time.Time) (*SLAStatus, error) {
+	breachSQL, breachArgs := slaBreachCondition(now)
+	dueSoonSQL := "(1 = 0"
+	dueSoonArgs := []interface{}{}
+	for severity, days := range SLATargetDays {
+		deadline := now.AddDate(0, 0, -days)
+		dueSoonSQL += " OR (severity = ? AND discovery_date >= ? AND discovery_date < ?)"
+		dueSoonArgs = append(dueSoonArgs, severity, deadline, deadline.AddDate(0, 0, SLADueSoonDays))
+	}
+	dueSoonSQL += ")"
+
+	var rows []SLAStatusRow
+	if err := s.unresolvedVulnerabilities(options.Environment).
+		Select(`vulnerabilities.severity, COUNT(*) AS open,
+			COUNT(*) FILTER (WHERE `+breachSQL+`) AS breached,
+			COUNT(*) FILTER (WHERE `+dueSoonSQL+`) AS due_soon`, append(breachArgs, dueSoonArgs...)...).
+		Where("vulnerabilities.severity IN ?", dashboardSeverities).
+		Group("vulnerabilities.severity").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count SLA status: %w", err)
+	}
+	return SummarizeSLAStatus(rows), nil
+}
+
+// SummarizeSLAStatus orders the per-severity rows (filling severities without open
+// vulnerabilities) and derives within-SLA counts and compliance rates
+func SummarizeSLAStatus(rows []SLAStatusRow) *SLAStatus {
+	bySeverity := make(map[models.VulnerabilitySeverity]SLAStatusRow, len(rows))
+	for _, row := range rows {
+		bySeverity[row.Severity] = row
+	}
+
+	status := &SLAStatus{Severities: make([]SLAStatusRow, 0, len(dashboardSeverities))}
+	for _, severity := range dashboardSeverities {
+		row := bySeverity[severity]
+		row.Severity = severity
+		row.TargetDays = SLATargetDays[severity]
+		row.WithinSLA = row.Open - row.Breached
+		row.ComplianceRate = slaComplianceRate(row.Open, row.Breached)
+		status.Severities = append(status.Severities, row)
+		status.Open += row.Open
+		status.Breached += row.Breached
+	}
+	status.ComplianceRate = slaComplianceRate(status.Open, status.Breached)
+	return status
+}
+
+// slaComplianceRate is the percentage of open vulnerabilities within SLA (100 when none are open)
+func slaComplianceRate(open, breached int64) float64 {
+	if open == 0 {
+		return 100
+	}
+	return math.Round(float64(open-breached)/float64(open)*1000) / 10
+}
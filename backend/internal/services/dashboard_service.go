@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"gorm.io/gorm"
+)
+
+// WallboardRefreshInterval is how long a wallboard snapshot is served before being recomputed
+const WallboardRefreshInterval = 30 * time.Second
+
+// SLATargetDays defines the remediation SLA (days since discovery) per severity
+var SLATargetDays = map[models.VulnerabilitySeverity]int{
+	models.SeverityCritical: 7,
+	models.SeverityHigh:     30,
+	models.SeverityMedium:   90,
+	models.SeverityLow:      180,
+}
+
+// unresolvedStatuses are the statuses that count as open work
+var unresolvedStatuses = []models.VulnerabilityStatus{
+	models.StatusOpen,
+	models.StatusInProgress,
+}
+
+// DashboardService builds compact dashboard payloads
+type DashboardService struct {
+	db *gorm.DB
+
+	mu         sync.Mutex
+	wallboards map[uuid.UUID]*WallboardData // Cached snapshots per organization
+}
+
+// NewDashboardService creates a new dashboard service
+func NewDashboardService(db *gorm.DB) *DashboardService {
+	return &DashboardService{
+		db:         db,
+		wallboards: make(map[uuid.UUID]*WallboardData),
+	}
+}
+
+// WallboardCounts contains the headline counts shown on the wallboard
+type WallboardCounts struct {
+	OpenTotal    int64 `json:"open_total"`
+	OpenCritical int64 `json:"open_critical"`
+	OpenHigh     int64 `json:"open_high"`
+	OpenMedium   int64 `json:"open_medium"`
+	OpenLow      int64 `json:"open_low"`
+	Unassigned   int64 `json:"unassigned"`
+	NewToday     int64 `json:"new_today"`
+	SLABreaches  int64 `json:"sla_breaches"`
+}
+
+// WallboardVulnerability is a minimal vulnerability row for wallboard lists
+type WallboardVulnerability struct {
+	ID            uuid.UUID `json:"id"`
+	Title         string    `json:"title"`
+	Severity      string    `json:"severity"`
+	Status        string    `json:"status"`
+	DiscoveryDate time.Time `json:"discovery_date"`
+	DaysOverdue   int       `json:"days_overdue,omitempty"`
+}
+
+// WallboardData is the payload served to SOC wallboard displays
+type WallboardData struct {
+	GeneratedAt            time.Time                `json:"generated_at"`
+	RefreshIntervalSeconds int                      `json:"refresh_interval_seconds"`
+	Counts                 WallboardCounts          `json:"counts"`
+	NewestCriticals        []WallboardVulnerability `json:"newest_criticals"`
+	SLABreachTicker        []WallboardVulnerability `json:"sla_breach_ticker"`
+}
+
+// GetWallboard returns the cached wallboard snapshot for the organization in ctx, recomputing it
+// at most once per refresh interval
+func (s *DashboardService) GetWallboard(ctx context.Context) (*WallboardData, error) {
+	orgID, _ := tenant.OrgFromContext(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cached := s.wallboards[orgID]
+	if cached != nil && time.Now().Before(cached.GeneratedAt.Add(WallboardRefreshInterval)) {
+		return cached, nil
+	}
+
+	data, err := s.buildWallboard(s.db.WithContext(ctx))
+	if err != nil {
+		// Serve the stale snapshot rather than blanking the display
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	s.wallboards[orgID] = data
+	return data, nil
+}
+
+// slaBreachCondition returns a SQL condition matching unresolved vulnerabilities past their SLA
+func slaBreachCondition(now time.Time) (string, []interface{}) {
+	condition := "(1 = 0"
+	args := []interface{}{}
+	for severity, days := range SLATargetDays {
+		condition += " OR (severity = ? AND discovery_date < ?)"
+		args = append(args, severity, now.AddDate(0, 0, -days))
+	}
+	return condition + ")", args
+}
+
+// buildWallboard computes the wallboard payload with a small, fixed number of queries
+func (s *DashboardService) buildWallboard(db *gorm.DB) (*WallboardData, error) {
+	now := time.Now()
+	data := &WallboardData{
+		GeneratedAt:            now,
+		RefreshIntervalSeconds: int(WallboardRefreshInterval.Seconds()),
+		NewestCriticals:        []WallboardVulnerability{},
+		SLABreachTicker:        []WallboardVulnerability{},
+	}
+
+	breachSQL, breachArgs := slaBreachCondition(now)
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// Headline counts in a single aggregate pass
+	args := []interface{}{
+		models.SeverityCritical,
+		models.SeverityHigh,
+		models.SeverityMedium,
+		models.SeverityLow,
+		startOfDay,
+	}
+	args = append(args, breachArgs...)
+	if err := db.Model(&models.Vulnerability{}).
+		Select(`COUNT(*) AS open_total,
+			COUNT(*) FILTER (WHERE severity = ?) AS open_critical,
+			COUNT(*) FILTER (WHERE severity = ?) AS open_high,
+			COUNT(*) FILTER (WHERE severity = ?) AS open_medium,
+			COUNT(*) FILTER (WHERE severity = ?) AS open_low,
+			COUNT(*) FILTER (WHERE assigned_to_id IS NULL) AS unassigned,
+			COUNT(*) FILTER (WHERE created_at >= ?) AS new_today,
+			COUNT(*) FILTER (WHERE `+breachSQL+`) AS sla_breaches`, args...).
+		Where("status IN ?", unresolvedStatuses).
+		Scan(&data.Counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count wallboard metrics: %w", err)
+	}
+
+	// Newest unresolved criticals
+	if err := db.Model(&models.Vulnerability{}).
+		Select("id, title, severity, status, discovery_date").
+		Where("severity = ? AND status IN ?", models.SeverityCritical, unresolvedStatuses).
+		Order("created_at DESC").
+		Limit(5).
+		Scan(&data.NewestCriticals).Error; err != nil {
+		return nil, fmt.Errorf("failed to load newest criticals: %w", err)
+	}
+
+	// Oldest SLA breaches first
+	if err := db.Model(&models.Vulnerability{}).
+		Select("id, title, severity, status, discovery_date").
+		Where("status IN ?", unresolvedStatuses).
+		Where(breachSQL, breachArgs...).
+		Order("discovery_date ASC").
+		Limit(10).
+		Scan(&data.SLABreachTicker).Error; err != nil {
+		return nil, fmt.Errorf("failed to load SLA breaches: %w", err)
+	}
+	for i := range data.SLABreachTicker {
+		item := &data.SLABreachTicker[i]
+		due := item.DiscoveryDate.AddDate(0, 0, SLATargetDays[models.VulnerabilitySeverity(item.Severity)])
+		item.DaysOverdue = int(now.Sub(due).Hours() / 24)
+	}
+
+	return data, nil
+}
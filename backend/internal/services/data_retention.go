@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	maxRetentionDays = 36500
+	// retentionBatchSize bounds the rows removed per statement so purges never hold long locks
+	retentionBatchSize = 1000
+	// scheduledRetentionInterval is the time between scheduled retention runs
+	scheduledRetentionInterval = 24 * time.Hour
+)
+
+// DataRetentionSettings configures how long purgeable data is kept. It is stored as JSON in the
+// data_retention system setting. A period of 0 keeps the data forever.
+type DataRetentionSettings struct {
+	Enabled                   bool `json:"enabled"` // Purge on a daily schedule; manual runs work either way
+	ResolvedVulnerabilityDays int  `json:"resolved_vulnerability_days"`
+	AuthEventDays             int  `json:"auth_event_days"`
+	SessionDays               int  `json:"session_days"`
+	AuditLogDays              int  `json:"audit_log_days"`
+	ReportExportDays          int  `json:"report_export_days"`
+	DeletedItemDays           int  `json:"deleted_item_days"` // Time deleted vulnerabilities and assets stay restorable
+}
+
+// retentionPeriod is the retention period of one category
+type retentionPeriod struct {
+	category models.RetentionCategory
+	days     int
+}
+
+// retentionPeriods returns the retention period of every category, in purge order
+func (s DataRetentionSettings) retentionPeriods() []retentionPeriod {
+	return []retentionPeriod{
+		{models.RetentionResolvedVulnerabilities, s.ResolvedVulnerabilityDays},
+		{models.RetentionAuthEvents, s.AuthEventDays},
+		{models.RetentionSessions, s.SessionDays},
+		{models.RetentionAuditLogs, s.AuditLogDays},
+		{models.RetentionReportExports, s.ReportExportDays},
+		{models.RetentionDeletedItems, s.DeletedItemDays},
+	}
+}
+
+// ValidateDataRetentionSettings checks that every retention period is within range
+func ValidateDataRetentionSettings(settings *DataRetentionSettings) error {
+	for _, period := range settings.retentionPeriods() {
+		if period.days < 0 || period.days > maxRetentionDays {
+			return fmt.Errorf("invalid retention period for %s: must be between 0 (keep forever) and %d days", period.category, maxRetentionDays)
+		}
+	}
+	return nil
+}
+
+// ParseDataRetentionSettings parses and validates a data_retention setting value
+func ParseDataRetentionSettings(value string) (*DataRetentionSettings, error) {
+	var settings DataRetentionSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return nil, fmt.Errorf("invalid data retention settings: %v", err)
+	}
+	if err := ValidateDataRetentionSettings(&settings); err != nil {
+		return nil, fmt.Errorf("invalid data retention settings: %v", err)
+	}
+	return &settings, nil
+}
+
+// LoadDataRetentionSettings returns the configured retention periods; without the setting
+// nothing is purged
+func LoadDataRetentionSettings(db *gorm.DB) (*DataRetentionSettings, error) {
+	var setting models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingDataRetention)).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &DataRetentionSettings{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data retention settings: %w", err)
+	}
+	return ParseDataRetentionSettings(setting.Value)
+}
+
+// RetentionSettings returns the configured retention periods
+func (s *CleanupService) RetentionSettings() (*DataRetentionSettings, error) {
+	return LoadDataRetentionSettings(s.db)
+}
+
+// PreviewRetention reports what a retention run with the given settings would purge, without
+// deleting or recording anything
+func (s *CleanupService) PreviewRetention(ctx context.Context, settings *DataRetentionSettings) *models.RetentionRun {
+	return s.applyRetention(ctx, settings, true)
+}
+
+// RunRetention purges data older than the retention periods and records the run's report
+func (s *CleanupService) RunRetention(ctx context.Context, settings *DataRetentionSettings, trigger models.RetentionRunTrigger, triggeredBy *uuid.UUID) (*models.RetentionRun, error) {
+	run := s.applyRetention(ctx, settings, false)
+	run.Trigger = trigger
+	run.TriggeredBy = triggeredBy
+	if err := s.db.Create(run).Error; err != nil {
+		return run, fmt.Errorf("failed to record retention run: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("trigger", string(trigger)).
+		Int64("deleted", run.TotalDeleted).
+		Bool("failed", run.Failed).
+		Msg("Data retention run completed")
+	return run, nil
+}
+
+// RunScheduledRetention runs the retention job when it is enabled and the last scheduled run
+// is a day old, so restarts and several instances do not purge more often. Returns nil when
+// no run was due.
+func (s *CleanupService) RunScheduledRetention(ctx context.Context) (*models.RetentionRun, error) {
+	settings, err := s.RetentionSettings()
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	var last models.RetentionRun
+	err = s.db.Where("trigger = ?", models.RetentionTriggerScheduled).Order("started_at DESC").First(&last).Error
+	if err == nil && time.Since(last.StartedAt) < scheduledRetentionInterval {
+		return nil, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load last retention run: %w", err)
+	}
+	return s.RunRetention(ctx, settings, models.RetentionTriggerScheduled, nil)
+}
+
+// ListRetentionRuns returns the most recent retention run reports, newest first
+func (s *CleanupService) ListRetentionRuns(limit int) ([]models.RetentionRun, error) {
+	var runs []models.RetentionRun
+	if err := s.db.Order("started_at DESC").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list retention runs: %w", err)
+	}
+	return runs, nil
+}
+
+// applyRetention counts, and unless dryRun deletes, the records of every category older than
+// its retention period. A failing category is reported and does not stop the others.
+func (s *CleanupService) applyRetention(ctx context.Context, settings *DataRetentionSettings, dryRun bool) *models.RetentionRun {
+	now := time.Now()
+	run := &models.RetentionRun{
+		DryRun:     dryRun,
+		StartedAt:  now,
+		Categories: []models.RetentionCategoryResult{},
+	}
+
+	for _, period := range settings.retentionPeriods() {
+		if period.days == 0 {
+			continue
+		}
+		result := models.RetentionCategoryResult{
+			Category:      period.category,
+			RetentionDays: period.days,
+			Cutoff:        now.AddDate(0, 0, -period.days),
+		}
+
+		var err error
+		if result.Matched, err = s.countExpired(ctx, period.category, result.Cutoff); err == nil && !dryRun && result.Matched > 0 {
+			result.Deleted, err = s.purgeExpired(ctx, period.category, result.Cutoff)
+		}
+		if err != nil {
+			utils.Logger.Error().Err(err).Str("category", string(period.category)).Msg("Data retention purge failed")
+			result.Error = err.Error()
+			run.Failed = true
+		}
+		run.TotalDeleted += result.Deleted
+		run.Categories = append(run.Categories, result)
+	}
+
+	run.FinishedAt = time.Now()
+	return run
+}
+
+// retentionTable is a table purged by a category and the condition selecting its expired rows
+type retentionTable struct {
+	table string
+	where string
+}
+
+// retentionTables lists the tables a category purges
+func retentionTables(category models.RetentionCategory) []retentionTable {
+	switch category {
+	case models.RetentionResolvedVulnerabilities:
+		return []retentionTable{{"vulnerabilities", "status IN ('RESOLVED', 'VERIFIED', 'CLOSED', 'FALSE_POSITIVE') AND updated_at < @cutoff"}}
+	case models.RetentionAuthEvents:
+		return []retentionTable{{"auth_events", "created_at < @cutoff"}}
+	case models.RetentionSessions:
+		// Sessions that ended (expired, revoked or cleaned up) before the cutoff
+		return []retentionTable{{"sessions", "expires_at < @cutoff OR revoked_at < @cutoff OR deleted_at < @cutoff"}}
+	case models.RetentionAuditLogs:
+		return []retentionTable{
+			{"asset_history", "changed_at < @cutoff"},
+			{"vulnerability_assignment_history", "changed_at < @cutoff"},
+			{"suppression_logs", "created_at < @cutoff"},
+			{"api_key_usage", "day < @cutoff"},
+		}
+	case models.RetentionReportExports:
+		return []retentionTable{{"assessment_reports", "generated = true AND created_at < @cutoff"}}
+	case models.RetentionDeletedItems:
+		return []retentionTable{
+			{"vulnerabilities", "deleted_at < @cutoff"},
+			{"affected_systems", "deleted_at < @cutoff"},
+		}
+	}
+	return nil
+}
+
+// countExpired counts the records of a category older than the cutoff, including soft-deleted ones
+func (s *CleanupService) countExpired(ctx context.Context, category models.RetentionCategory, cutoff time.Time) (int64, error) {
+	var total int64
+	for _, t := range retentionTables(category) {
+		var count int64
+		if err := s.db.WithContext(ctx).Table(t.table).
+			Where(t.where, map[string]interface{}{"cutoff": cutoff}).
+			Count(&count).Error; err != nil {
+			return total, fmt.Errorf("failed to count expired %s: %w", t.table, err)
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// purgeExpired permanently deletes the records of a category older than the cutoff, in batches
+func (s *CleanupService) purgeExpired(ctx context.Context, category models.RetentionCategory, cutoff time.Time) (int64, error) {
+	args := map[string]interface{}{"cutoff": cutoff}
+	var total int64
+	for _, t := range retentionTables(category) {
+		for {
+			if err := ctx.Err(); err != nil {
+				return total, err
+			}
+
+			var ids []uuid.UUID
+			if err := s.db.WithContext(ctx).Table(t.table).Where(t.where, args).
+				Limit(retentionBatchSize).Pluck("id", &ids).Error; err != nil {
+				return total, fmt.Errorf("failed to select expired %s: %w", t.table, err)
+			}
+			if len(ids) == 0 {
+				break
+			}
+
+			var deleted int64
+			var err error
+			switch t.table {
+			case "vulnerabilities":
+				deleted, err = s.purgeVulnerabilities(ctx, ids)
+			case "affected_systems":
+				deleted, err = s.purgeAssets(ctx, ids)
+			case "sessions":
+				deleted, err = s.purgeSessions(ctx, ids)
+			case "assessment_reports":
+				deleted, err = s.purgeReports(ctx, ids)
+			default:
+				result := s.db.WithContext(ctx).Exec("DELETE FROM "+t.table+" WHERE id IN ?", ids)
+				deleted, err = result.RowsAffected, result.Error
+			}
+			total += deleted
+			if err != nil {
+				return total, fmt.Errorf("failed to purge expired %s: %w", t.table, err)
+			}
+			if len(ids) < retentionBatchSize {
+				break
+			}
+		}
+	}
+	return total, nil
+}
+
+// purgeVulnerabilities permanently deletes vulnerabilities with their findings, history and
+// relationships, as CleanupVulnerabilities does for soft-deleted ones
+func (s *CleanupService) purgeVulnerabilities(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	var deleted int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{
+			"vulnerability_findings",
+			"vulnerability_status_history",
+			"vulnerability_assignment_history",
+			"vulnerability_tags",
+			"vulnerability_classifications",
+			"vulnerability_affected_systems",
+			"assessment_vulnerabilities",
+		} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE vulnerability_id IN ?", ids).Error; err != nil {
+				return fmt.Errorf("failed to delete %s: %w", table, err)
+			}
+		}
+		result := tx.Exec("DELETE FROM vulnerabilities WHERE id IN ?", ids)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// purgeAssets permanently deletes assets with their tags, findings and relationships, as
+// CleanupAssets does for all soft-deleted ones
+func (s *CleanupService) purgeAssets(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	var deleted int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, statement := range []string{
+			"DELETE FROM asset_tags WHERE asset_id IN ?",
+			"DELETE FROM vulnerability_affected_systems WHERE affected_system_id IN ?",
+			"DELETE FROM vulnerability_findings WHERE affected_system_id IN ?",
+		} {
+			if err := tx.Exec(statement, ids).Error; err != nil {
+				return err
+			}
+		}
+		result := tx.Exec("DELETE FROM affected_systems WHERE id IN ?", ids)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// purgeSessions permanently deletes sessions along with their refresh tokens
+func (s *CleanupService) purgeSessions(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	var deleted int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM refresh_tokens WHERE session_id IN ?", ids).Error; err != nil {
+			return fmt.Errorf("failed to delete refresh tokens: %w", err)
+		}
+		result := tx.Exec("DELETE FROM sessions WHERE id IN ?", ids)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// purgeReports permanently deletes generated reports and their stored files. A report whose
+// file cannot be removed is kept so the file is not orphaned.
+func (s *CleanupService) purgeReports(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	var reports []models.AssessmentReport
+	if err := s.db.WithContext(ctx).Unscoped().Where("id IN ?", ids).Find(&reports).Error; err != nil {
+		return 0, err
+	}
+
+	removed := make([]uuid.UUID, 0, len(reports))
+	var lastErr error
+	for _, report := range reports {
+		store, err := attachmentStoreFor(report.StorageBackend)
+		if err == nil {
+			err = store.Delete(ctx, attachmentObjectKey(storageAreaAssessmentReports, report.StoragePath))
+		}
+		if err != nil {
+			utils.Logger.Warn().Err(err).Str("report_id", report.ID.String()).Msg("Failed to delete expired report file")
+			lastErr = err
+			continue
+		}
+		removed = append(removed, report.ID)
+	}
+
+	var deleted int64
+	if len(removed) > 0 {
+		result := s.db.WithContext(ctx).Exec("DELETE FROM assessment_reports WHERE id IN ?", removed)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		deleted = result.RowsAffected
+	}
+	if lastErr != nil {
+		return deleted, fmt.Errorf("failed to delete %d report file(s): %w", len(reports)-len(removed), lastErr)
+	}
+	return deleted, nil
+}
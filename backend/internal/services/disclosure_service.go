@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DisclosureSourceName is the source recorded on vulnerabilities accepted from a disclosure
+const DisclosureSourceName = "Disclosure"
+
+// Disclosure field limits, in characters
+const (
+	maxDisclosureText        = 10000
+	maxDisclosureTarget      = 500
+	maxDisclosureReporter    = 200
+	maxDisclosureUserAgent   = 500
+	minDisclosureDescription = 10
+)
+
+// DisclosureSubmission is a report submitted through the public disclosure form
+type DisclosureSubmission struct {
+	Title             string
+	Description       string
+	StepsToReproduce  string
+	Impact            string
+	AffectedTarget    string
+	SuggestedSeverity models.VulnerabilitySeverity
+	CVEID             string
+	ReporterName      string
+	ReporterEmail     string
+}
+
+// AcceptDisclosureRequest accepts a disclosure into the registry. Empty fields keep what the
+// reporter submitted.
+type AcceptDisclosureRequest struct {
+	Title        string
+	Severity     models.VulnerabilitySeverity
+	AssignedToID *uuid.UUID
+	OwnerTeamID  *uuid.UUID
+	Notes        string
+}
+
+// DisclosureService manages the public disclosure triage queue
+type DisclosureService struct {
+	db                   *gorm.DB
+	vulnerabilityService *VulnerabilityService
+}
+
+// NewDisclosureService creates a new disclosure service
+func NewDisclosureService(db *gorm.DB) *DisclosureService {
+	return &DisclosureService{
+		db:                   db,
+		vulnerabilityService: NewVulnerabilityService(),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *DisclosureService) WithContext(ctx context.Context) *DisclosureService {
+	return &DisclosureService{
+		db:                   s.db.WithContext(ctx),
+		vulnerabilityService: s.vulnerabilityService.WithContext(ctx),
+	}
+}
+
+// NormalizeDisclosureSubmission trims and sanitizes a submission and checks its fields
+func NormalizeDisclosureSubmission(req *DisclosureSubmission) error {
+	req.Title = utils.SanitizeString(req.Title)
+	req.Description = utils.SanitizeString(req.Description)
+	req.StepsToReproduce = utils.SanitizeString(req.StepsToReproduce)
+	req.Impact = utils.SanitizeString(req.Impact)
+	req.AffectedTarget = utils.SanitizeString(req.AffectedTarget)
+	req.ReporterName = utils.SanitizeString(req.ReporterName)
+	req.ReporterEmail = utils.NormalizeEmail(req.ReporterEmail)
+	req.CVEID = strings.ToUpper(strings.TrimSpace(req.CVEID))
+	req.SuggestedSeverity = models.VulnerabilitySeverity(strings.ToUpper(strings.TrimSpace(string(req.SuggestedSeverity))))
+
+	validator := NewVulnerabilityValidationService()
+	if err := validator.ValidateTitle(req.Title); err != nil {
+		return utils.FieldErr("title", err)
+	}
+	if req.Description == "" {
+		return utils.FieldErr("description", fmt.Errorf("description is required"))
+	}
+	if len([]rune(req.Description)) < minDisclosureDescription {
+		return utils.FieldErr("description", fmt.Errorf("description must be at least %d characters", minDisclosureDescription))
+	}
+	for _, field := range []struct{ name, value string }{
+		{"description", req.Description},
+		{"steps_to_reproduce", req.StepsToReproduce},
+		{"impact", req.Impact},
+	} {
+		if len([]rune(field.value)) > maxDisclosureText {
+			return utils.FieldErr(field.name, fmt.Errorf("%s must be at most %d characters", strings.ReplaceAll(field.name, "_", " "), maxDisclosureText))
+		}
+	}
+	if len([]rune(req.AffectedTarget)) > maxDisclosureTarget {
+		return utils.FieldErr("affected_target", fmt.Errorf("affected target must be at most %d characters", maxDisclosureTarget))
+	}
+	if len([]rune(req.ReporterName)) > maxDisclosureReporter {
+		return utils.FieldErr("reporter_name", fmt.Errorf("reporter name must be at most %d characters", maxDisclosureReporter))
+	}
+	if req.ReporterEmail != "" {
+		if err := utils.ValidateEmail(req.ReporterEmail); err != nil {
+			return utils.FieldErr("reporter_email", err)
+		}
+	}
+	if req.SuggestedSeverity != "" {
+		if err := validator.ValidateSeverity(req.SuggestedSeverity); err != nil {
+			return utils.FieldErr("suggested_severity", err)
+		}
+	}
+	if err := validator.ValidateCVEID(req.CVEID); err != nil {
+		return utils.FieldErr("cve_id", err)
+	}
+	return nil
+}
+
+// Submit queues a disclosure for triage. Submissions are anonymous requests, so they land in
+// the default organization.
+func (s *DisclosureService) Submit(req DisclosureSubmission, submitterIP, userAgent string) (*models.Disclosure, error) {
+	if err := NormalizeDisclosureSubmission(&req); err != nil {
+		return nil, err
+	}
+
+	disclosure := &models.Disclosure{
+		Title:             req.Title,
+		Description:       req.Description,
+		StepsToReproduce:  req.StepsToReproduce,
+		Impact:            req.Impact,
+		AffectedTarget:    req.AffectedTarget,
+		SuggestedSeverity: req.SuggestedSeverity,
+		CVEID:             req.CVEID,
+		ReporterName:      req.ReporterName,
+		ReporterEmail:     req.ReporterEmail,
+		SubmitterIP:       submitterIP,
+		UserAgent:         truncateRunes(userAgent, maxDisclosureUserAgent),
+		Status:            models.DisclosureNew,
+	}
+	if err := s.db.Create(disclosure).Error; err != nil {
+		return nil, fmt.Errorf("failed to create disclosure: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("disclosure_id", disclosure.ID.String()).
+		Str("submitter_ip", submitterIP).
+		Msg("Vulnerability disclosure submitted")
+
+	return disclosure, nil
+}
+
+// ListDisclosures returns the triage queue, oldest first so reports are handled in arrival order
+func (s *DisclosureService) ListDisclosures(status models.DisclosureStatus, page, limit int) ([]models.Disclosure, int64, error) {
+	query := s.db.Model(&models.Disclosure{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count disclosures: %w", err)
+	}
+
+	disclosures := []models.Disclosure{}
+	if err := query.Preload("TriagedBy").
+		Order("created_at ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&disclosures).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list disclosures: %w", err)
+	}
+	return disclosures, total, nil
+}
+
+// GetDisclosure returns a disclosure with its triager and linked vulnerability
+func (s *DisclosureService) GetDisclosure(id uuid.UUID) (*models.Disclosure, error) {
+	var disclosure models.Disclosure
+	if err := s.db.Preload("TriagedBy").Preload("Vulnerability").First(&disclosure, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("disclosure not found")
+		}
+		return nil, fmt.Errorf("failed to get disclosure: %w", err)
+	}
+	return &disclosure, nil
+}
+
+// claim moves a NEW disclosure to a triaged status. Only one triager can claim a disclosure.
+func (s *DisclosureService) claim(id uuid.UUID, status models.DisclosureStatus, vulnerabilityID *uuid.UUID, notes string, userID uuid.UUID) (*models.Disclosure, error) {
+	var disclosure models.Disclosure
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&disclosure, "id = ?", id).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("disclosure not found")
+			}
+			return fmt.Errorf("failed to get disclosure: %w", err)
+		}
+		if disclosure.Status != models.DisclosureNew {
+			return fmt.Errorf("disclosure has already been triaged (status: %s)", disclosure.Status)
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":           status,
+			"triaged_by_id":    userID,
+			"triaged_at":       now,
+			"triage_notes":     notes,
+			"vulnerability_id": vulnerabilityID,
+		}
+		if err := tx.Model(&disclosure).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update disclosure: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("disclosure_id", id.String()).
+		Str("status", string(status)).
+		Str("triaged_by", userID.String()).
+		Msg("Vulnerability disclosure triaged")
+
+	return s.GetDisclosure(id)
+}
+
+// AcceptDisclosure creates an OPEN vulnerability from a disclosure and links the two
+func (s *DisclosureService) AcceptDisclosure(id uuid.UUID, req AcceptDisclosureRequest, userID uuid.UUID) (*models.Disclosure, error) {
+	disclosure, err := s.GetDisclosure(id)
+	if err != nil {
+		return nil, err
+	}
+	if disclosure.Status != models.DisclosureNew {
+		return nil, fmt.Errorf("disclosure has already been triaged (status: %s)", disclosure.Status)
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = disclosure.Title
+	}
+	severity := req.Severity
+	if severity == "" {
+		severity = disclosure.SuggestedSeverity
+	}
+	if severity == "" {
+		return nil, fmt.Errorf("invalid severity: the reporter did not suggest one, so it is required")
+	}
+
+	description := disclosure.Description
+	if disclosure.AffectedTarget != "" {
+		description += "\n\nAffected: " + disclosure.AffectedTarget
+	}
+	reporter := "an anonymous reporter"
+	switch {
+	case disclosure.ReporterName != "" && disclosure.ReporterEmail != "":
+		reporter = fmt.Sprintf("%s <%s>", disclosure.ReporterName, disclosure.ReporterEmail)
+	case disclosure.ReporterName != "":
+		reporter = disclosure.ReporterName
+	case disclosure.ReporterEmail != "":
+		reporter = disclosure.ReporterEmail
+	}
+	description += fmt.Sprintf("\n\nDisclosed by %s on %s.", reporter, disclosure.CreatedAt.UTC().Format(time.RFC1123))
+
+	createReq := CreateVulnerabilityRequest{
+		Title:            title,
+		Description:      description,
+		Severity:         severity,
+		CVEID:            disclosure.CVEID,
+		Source:           DisclosureSourceName,
+		DiscoveryDate:    disclosure.CreatedAt,
+		ImpactAssessment: disclosure.Impact,
+		StepsToReproduce: disclosure.StepsToReproduce,
+		AssignedToID:     req.AssignedToID,
+		OwnerTeamID:      req.OwnerTeamID,
+	}
+	if err := NewVulnerabilityValidationService().ValidateCreateRequest(createReq); err != nil {
+		return nil, err
+	}
+
+	// Claim the disclosure first so two triagers cannot both create a vulnerability from it
+	if _, err := s.claim(id, models.DisclosureAccepted, nil, strings.TrimSpace(req.Notes), userID); err != nil {
+		return nil, err
+	}
+
+	vulnerability, err := s.vulnerabilityService.CreateVulnerability(createReq, userID)
+	if err != nil {
+		if releaseErr := s.db.Model(&models.Disclosure{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":        models.DisclosureNew,
+			"triaged_by_id": nil,
+			"triaged_at":    nil,
+			"triage_notes":  "",
+		}).Error; releaseErr != nil {
+			utils.Logger.Error().Err(releaseErr).Str("disclosure_id", id.String()).Msg("Failed to return disclosure to the triage queue")
+		}
+		return nil, err
+	}
+
+	if err := s.db.Model(&models.Disclosure{}).Where("id = ?", id).Update("vulnerability_id", vulnerability.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to link disclosure: %w", err)
+	}
+	return s.GetDisclosure(id)
+}
+
+// MarkDuplicate closes a disclosure as a duplicate of an existing vulnerability
+func (s *DisclosureService) MarkDuplicate(id, vulnerabilityID uuid.UUID, notes string, userID uuid.UUID) (*models.Disclosure, error) {
+	var count int64
+	if err := s.db.Model(&models.Vulnerability{}).Where("id = ?", vulnerabilityID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to get vulnerability: %w", err)
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("vulnerability not found")
+	}
+	return s.claim(id, models.DisclosureDuplicate, &vulnerabilityID, strings.TrimSpace(notes), userID)
+}
+
+// RejectDisclosure closes a disclosure that is not a vulnerability; the reason is required
+func (s *DisclosureService) RejectDisclosure(id uuid.UUID, notes string, userID uuid.UUID) (*models.Disclosure, error) {
+	notes = strings.TrimSpace(notes)
+	if notes == "" {
+		return nil, fmt.Errorf("invalid rejection, notes explaining the reason are required")
+	}
+	return s.claim(id, models.DisclosureRejected, nil, notes, userID)
+}
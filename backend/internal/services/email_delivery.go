@@ -0,0 +1,427 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/cyops/cyops-backend/pkg/mailer"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// ErrEmailNotConfigured is returned when neither an email provider nor SMTP is configured
+var ErrEmailNotConfigured = errors.New("email delivery is not configured")
+
+// emailSendTimeout bounds handing a message to the provider
+const emailSendTimeout = 30 * time.Second
+
+// EmailDeliverySettings selects the provider outgoing email is sent with. It is stored as JSON
+// in the email_delivery system setting; passwords, API keys and the webhook secret are stored
+// encrypted. Without the setting email goes to the SMTP server configured in the environment.
+type EmailDeliverySettings struct {
+	Provider string `json:"provider"`       // smtp, sendgrid, ses or mailgun
+	From     string `json:"from,omitempty"` // Sender address; FROM_EMAIL when empty
+
+	// SMTP
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"` // Left empty on update to keep the stored password
+
+	// SendGrid and Mailgun
+	APIKey string `json:"api_key,omitempty"` // Left empty on update to keep the stored key
+	Domain string `json:"domain,omitempty"`  // Mailgun sending domain
+
+	// Amazon SES
+	AccessKeyID      string `json:"access_key_id,omitempty"`
+	SecretAccessKey  string `json:"secret_access_key,omitempty"` // Left empty on update to keep the stored key
+	ConfigurationSet string `json:"configuration_set,omitempty"` // Configuration set whose SNS destination posts delivery events
+
+	Region   string `json:"region,omitempty"`   // SES region; "eu" for Mailgun's EU region
+	Endpoint string `json:"endpoint,omitempty"` // API root override
+
+	// Token providers post delivery status events with; left empty on update to keep it
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+}
+
+// secrets returns the encrypted fields of the settings
+func (s *EmailDeliverySettings) secrets() []*string {
+	return []*string{&s.Password, &s.APIKey, &s.SecretAccessKey, &s.WebhookSecret}
+}
+
+// mailerConfig converts the settings to a sender configuration
+func (s *EmailDeliverySettings) mailerConfig() mailer.Config {
+	return mailer.Config{
+		Provider:         s.Provider,
+		Host:             s.Host,
+		Port:             s.Port,
+		Username:         s.Username,
+		Password:         s.Password,
+		APIKey:           s.APIKey,
+		Domain:           s.Domain,
+		AccessKeyID:      s.AccessKeyID,
+		SecretAccessKey:  s.SecretAccessKey,
+		ConfigurationSet: s.ConfigurationSet,
+		Region:           s.Region,
+		Endpoint:         s.Endpoint,
+	}
+}
+
+// ValidateEmailDeliverySettings checks the provider and its credentials (with secrets in plain
+// text) and normalizes the settings
+func ValidateEmailDeliverySettings(settings *EmailDeliverySettings) error {
+	settings.Provider = strings.ToLower(strings.TrimSpace(settings.Provider))
+	settings.From = strings.TrimSpace(settings.From)
+	settings.Host = strings.TrimSpace(settings.Host)
+	settings.Domain = strings.TrimSpace(settings.Domain)
+	settings.Region = strings.TrimSpace(settings.Region)
+	settings.Endpoint = strings.TrimSpace(settings.Endpoint)
+
+	if settings.From != "" {
+		if err := utils.ValidateEmail(emailAddressOf(settings.From)); err != nil {
+			return fmt.Errorf("invalid from address: %v", err)
+		}
+	}
+	if settings.Port < 0 || settings.Port > 65535 {
+		return fmt.Errorf("invalid port: %d", settings.Port)
+	}
+	if settings.Endpoint != "" && !strings.HasPrefix(settings.Endpoint, "https://") && !strings.HasPrefix(settings.Endpoint, "http://") {
+		return fmt.Errorf("invalid endpoint: must be an http(s) URL")
+	}
+	if _, err := mailer.New(settings.mailerConfig()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// emailAddressOf returns the address of a From value that may carry a display name
+func emailAddressOf(value string) string {
+	if start, end := strings.LastIndex(value, "<"), strings.LastIndex(value, ">"); start >= 0 && end > start {
+		return value[start+1 : end]
+	}
+	return value
+}
+
+// PrepareEmailDeliverySetting validates an email_delivery setting value before it is saved and
+// returns the value to store: secrets left empty (or sent back as read) keep their stored value
+// and new secrets are encrypted
+func PrepareEmailDeliverySetting(db *gorm.DB, value string) (string, error) {
+	var settings EmailDeliverySettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return "", fmt.Errorf("invalid email delivery settings: %v", err)
+	}
+
+	var stored EmailDeliverySettings
+	var existing models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingEmailDelivery)).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("failed to load email delivery settings: %w", err)
+	}
+	if err == nil {
+		json.Unmarshal([]byte(existing.Value), &stored)
+	}
+
+	keyring := activeSecretKeyring("")
+	ctx := context.Background()
+	storedSecrets := stored.secrets()
+	for i, secret := range settings.secrets() {
+		storedSecret := *storedSecrets[i]
+		if storedSecret == "" || (*secret != "" && *secret != storedSecret) {
+			continue
+		}
+		plaintext, err := keyring.Decrypt(ctx, storedSecret)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt stored email delivery secret: %w", err)
+		}
+		*secret = plaintext
+	}
+
+	if err := ValidateEmailDeliverySettings(&settings); err != nil {
+		return "", fmt.Errorf("invalid email delivery settings: %v", err)
+	}
+
+	for _, secret := range settings.secrets() {
+		if *secret == "" {
+			continue
+		}
+		encrypted, err := keyring.Encrypt(ctx, *secret)
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt email delivery secret: %w", err)
+		}
+		*secret = encrypted
+	}
+
+	normalized, _ := json.Marshal(settings)
+	return string(normalized), nil
+}
+
+// LoadEmailDeliverySettings returns the configured email provider with its secrets decrypted,
+// or nil when email goes to the SMTP server from the environment
+func LoadEmailDeliverySettings(db *gorm.DB) (*EmailDeliverySettings, error) {
+	var setting models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingEmailDelivery)).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email delivery settings: %w", err)
+	}
+
+	var settings EmailDeliverySettings
+	if err := json.Unmarshal([]byte(setting.Value), &settings); err != nil {
+		return nil, fmt.Errorf("invalid email delivery settings: %v", err)
+	}
+	keyring := activeSecretKeyring("")
+	for _, secret := range settings.secrets() {
+		if *secret == "" {
+			continue
+		}
+		plaintext, err := keyring.Decrypt(context.Background(), *secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt email delivery secret: %w", err)
+		}
+		*secret = plaintext
+	}
+	return &settings, nil
+}
+
+// EmailDeliveryService sends email through the configured provider, records every message and
+// applies the delivery status events providers post back
+type EmailDeliveryService struct {
+	db  *gorm.DB
+	cfg *config.Config
+}
+
+// NewEmailDeliveryService creates a new email delivery service; cfg supplies the SMTP server and
+// sender address used when no provider is configured
+func NewEmailDeliveryService(db *gorm.DB, cfg *config.Config) *EmailDeliveryService {
+	return &EmailDeliveryService{db: db, cfg: cfg}
+}
+
+// sender returns the configured sender and the address mail is sent from
+func (s *EmailDeliveryService) sender() (mailer.Sender, string, error) {
+	from := ""
+	if s.cfg != nil {
+		from = s.cfg.FromEmail
+	}
+
+	settings, err := LoadEmailDeliverySettings(s.db)
+	if err != nil {
+		return nil, "", err
+	}
+	if settings == nil {
+		if s.cfg == nil || s.cfg.SMTPHost == "" || s.cfg.SMTPUsername == "" {
+			return nil, "", ErrEmailNotConfigured
+		}
+		sender, err := mailer.New(mailer.Config{
+			Provider: mailer.ProviderSMTP,
+			Host:     s.cfg.SMTPHost,
+			Port:     s.cfg.SMTPPort,
+			Username: s.cfg.SMTPUsername,
+			Password: s.cfg.SMTPPassword,
+		})
+		return sender, from, err
+	}
+
+	if settings.From != "" {
+		from = settings.From
+	}
+	sender, err := mailer.New(settings.mailerConfig())
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid email delivery settings: %v", err)
+	}
+	return sender, from, nil
+}
+
+// Configured reports whether email can be sent
+func (s *EmailDeliveryService) Configured() bool {
+	_, _, err := s.sender()
+	return err == nil
+}
+
+// Deliver sends an email and records it with the provider's message ID, so delivery events can
+// update it. The delivery is recorded as FAILED when the provider refuses the message.
+func (s *EmailDeliveryService) Deliver(category, to, subject, html string) (*models.EmailDelivery, error) {
+	sender, from, err := s.sender()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), emailSendTimeout)
+	defer cancel()
+	messageID, sendErr := sender.Send(ctx, mailer.Message{From: from, To: to, Subject: subject, HTML: html})
+
+	now := time.Now()
+	delivery := &models.EmailDelivery{
+		Provider:          sender.Provider(),
+		ProviderMessageID: messageID,
+		Category:          category,
+		Recipient:         to,
+		Subject:           truncateRunes(subject, 500),
+		Status:            models.EmailDeliverySent,
+		SentAt:            now,
+		StatusAt:          now,
+	}
+	if sendErr != nil {
+		delivery.Status = models.EmailDeliveryFailed
+		delivery.Error = sendErr.Error()
+	}
+	if err := s.db.Create(delivery).Error; err != nil {
+		utils.Logger.Error().Err(err).Str("to", to).Msg("Failed to record email delivery")
+	}
+
+	if sendErr != nil {
+		utils.Logger.Error().Err(sendErr).Str("to", to).Str("provider", sender.Provider()).Msg("Failed to send email")
+		return delivery, fmt.Errorf("failed to send email: %w", sendErr)
+	}
+	utils.Logger.Info().Str("to", to).Str("subject", subject).Str("provider", sender.Provider()).Msg("Email sent successfully")
+	return delivery, nil
+}
+
+// CheckWebhookSecret reports whether a token matches the configured webhook secret; the
+// delivery webhook is closed while no provider or secret is configured
+func (s *EmailDeliveryService) CheckWebhookSecret(token string) (bool, error) {
+	settings, err := LoadEmailDeliverySettings(s.db)
+	if err != nil {
+		return false, err
+	}
+	if settings == nil || settings.WebhookSecret == "" || token == "" {
+		return false, nil
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(settings.WebhookSecret)) == 1, nil
+}
+
+// emailDeliveryStatusRank orders statuses so late or out-of-order events don't undo a later
+// outcome: a deferral reported after the delivery is kept in the history only
+var emailDeliveryStatusRank = map[models.EmailDeliveryStatus]int{
+	models.EmailDeliverySent:       0,
+	models.EmailDeliveryFailed:     0,
+	models.EmailDeliveryDeferred:   1,
+	models.EmailDeliveryDelivered:  2,
+	models.EmailDeliveryBounced:    2,
+	models.EmailDeliveryDropped:    2,
+	models.EmailDeliveryComplained: 3,
+}
+
+// NextEmailDeliveryStatus returns the status of a delivery after an event, given its current
+// status and when that was reported
+func NextEmailDeliveryStatus(current models.EmailDeliveryStatus, currentAt time.Time, event models.EmailDeliveryStatus, eventAt time.Time) models.EmailDeliveryStatus {
+	currentRank, eventRank := emailDeliveryStatusRank[current], emailDeliveryStatusRank[event]
+	if eventRank > currentRank || (eventRank == currentRank && !eventAt.Before(currentAt)) {
+		return event
+	}
+	return current
+}
+
+// RecordEvents stores the delivery events a provider posted and updates the status of their
+// messages. Events for messages this platform did not send are ignored; the number recorded is
+// returned.
+func (s *EmailDeliveryService) RecordEvents(provider string, events []mailer.Event) (int, error) {
+	recorded := 0
+	for _, event := range events {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			var delivery models.EmailDelivery
+			err := tx.Where("provider = ? AND provider_message_id = ?", provider, event.MessageID).
+				Order("created_at DESC").First(&delivery).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			status := models.EmailDeliveryStatus(event.Status)
+			if err := tx.Create(&models.EmailDeliveryEvent{
+				DeliveryID:    delivery.ID,
+				Status:        status,
+				ProviderEvent: truncateRunes(event.ProviderEvent, 50),
+				Recipient:     truncateRunes(event.Recipient, 320),
+				Reason:        event.Reason,
+				OccurredAt:    event.OccurredAt,
+			}).Error; err != nil {
+				return err
+			}
+			recorded++
+
+			next := NextEmailDeliveryStatus(delivery.Status, delivery.StatusAt, status, event.OccurredAt)
+			if next == delivery.Status && next != status {
+				return nil
+			}
+			updates := map[string]interface{}{"status": next, "status_at": event.OccurredAt}
+			switch next {
+			case models.EmailDeliveryDelivered:
+				updates["error"] = ""
+			case models.EmailDeliveryBounced, models.EmailDeliveryDropped, models.EmailDeliveryDeferred, models.EmailDeliveryComplained:
+				updates["error"] = event.Reason
+			}
+			return tx.Model(&delivery).Updates(updates).Error
+		})
+		if err != nil {
+			return recorded, fmt.Errorf("failed to record email delivery event: %w", err)
+		}
+	}
+	return recorded, nil
+}
+
+// EmailDeliveryFilter narrows the delivery log
+type EmailDeliveryFilter struct {
+	Status    models.EmailDeliveryStatus
+	Provider  string
+	Category  string
+	Recipient string
+}
+
+// ListDeliveries lists sent emails, newest first
+func (s *EmailDeliveryService) ListDeliveries(filter EmailDeliveryFilter, page, limit int) ([]models.EmailDelivery, int64, error) {
+	query := s.db.Model(&models.EmailDelivery{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Provider != "" {
+		query = query.Where("provider = ?", filter.Provider)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Recipient != "" {
+		query = query.Where("LOWER(recipient) = ?", strings.ToLower(filter.Recipient))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count email deliveries: %w", err)
+	}
+
+	var deliveries []models.EmailDelivery
+	if err := query.
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list email deliveries: %w", err)
+	}
+	return deliveries, total, nil
+}
+
+// GetDelivery returns a sent email with its delivery events, oldest first
+func (s *EmailDeliveryService) GetDelivery(id uuid.UUID) (*models.EmailDelivery, error) {
+	var delivery models.EmailDelivery
+	err := s.db.Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("occurred_at ASC")
+	}).Where("id = ?", id).First(&delivery).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("email delivery not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email delivery: %w", err)
+	}
+	return &delivery, nil
+}
@@ -0,0 +1,520 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/mailbox"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// EmailSourceName is the source of vulnerabilities created from emailed reports
+const EmailSourceName = "Email"
+
+const (
+	defaultEmailPollIntervalMins = 5
+	maxEmailPollIntervalMins     = 1440
+	maxEmailPollMessages         = 50 // Messages ingested per poll; the rest wait for the next one
+)
+
+// ErrEmailIngestionDisabled is returned when reports arrive while email ingestion is turned off
+var ErrEmailIngestionDisabled = errors.New("email ingestion is disabled")
+
+// EmailIngestionSettings configures turning emailed vulnerability reports (from external
+// researchers or bug bounty platforms) into draft vulnerabilities. Reports are polled from an
+// IMAP mailbox when a host is set and accepted on the inbound webhook when a webhook secret is
+// set. It is stored as JSON in the email_ingestion system setting; the password and webhook
+// secret are stored encrypted.
+type EmailIngestionSettings struct {
+	Enabled            bool                         `json:"enabled"`
+	Host               string                       `json:"host,omitempty"`
+	Port               int                          `json:"port,omitempty"`
+	Security           string                       `json:"security,omitempty"` // tls (default) or plaintext
+	InsecureSkipVerify bool                         `json:"insecure_skip_verify,omitempty"`
+	Username           string                       `json:"username,omitempty"`
+	Password           string                       `json:"password,omitempty"` // Left empty on update to keep the stored password
+	Mailbox            string                       `json:"mailbox,omitempty"`  // Folder polled; defaults to INBOX
+	PollIntervalMins   int                          `json:"poll_interval_mins,omitempty"`
+	WebhookSecret      string                       `json:"webhook_secret,omitempty"`  // Token inbound mail providers post with; left empty on update to keep it
+	AllowedSenders     []string                     `json:"allowed_senders,omitempty"` // Addresses or domains (example.com); anyone when empty
+	ReporterUserID     uuid.UUID                    `json:"reporter_user_id"`          // Account drafts are created as
+	DefaultSeverity    models.VulnerabilitySeverity `json:"default_severity,omitempty"`
+}
+
+// ValidateEmailIngestionSettings checks the mailbox, webhook and reporter and fills in defaults
+func ValidateEmailIngestionSettings(settings *EmailIngestionSettings) error {
+	settings.Host = strings.TrimSpace(settings.Host)
+	settings.Username = strings.TrimSpace(settings.Username)
+	settings.Mailbox = strings.TrimSpace(settings.Mailbox)
+	settings.Security = strings.ToLower(strings.TrimSpace(settings.Security))
+	settings.DefaultSeverity = models.VulnerabilitySeverity(strings.ToUpper(strings.TrimSpace(string(settings.DefaultSeverity))))
+
+	if settings.Security == "" {
+		settings.Security = mailbox.SecurityTLS
+	}
+	switch settings.Security {
+	case mailbox.SecurityTLS, mailbox.SecurityPlaintext:
+	default:
+		return fmt.Errorf("invalid security: %s (must be tls or plaintext)", settings.Security)
+	}
+	if settings.Port == 0 {
+		settings.Port = 993
+		if settings.Security == mailbox.SecurityPlaintext {
+			settings.Port = 143
+		}
+	}
+	if settings.Port < 0 || settings.Port > 65535 {
+		return fmt.Errorf("invalid port: %d", settings.Port)
+	}
+	if settings.Mailbox == "" {
+		settings.Mailbox = mailbox.DefaultMailbox
+	}
+	if settings.PollIntervalMins == 0 {
+		settings.PollIntervalMins = defaultEmailPollIntervalMins
+	}
+	if settings.PollIntervalMins < 1 || settings.PollIntervalMins > maxEmailPollIntervalMins {
+		return fmt.Errorf("invalid poll_interval_mins: must be between 1 and %d", maxEmailPollIntervalMins)
+	}
+	if settings.DefaultSeverity == "" {
+		settings.DefaultSeverity = models.SeverityMedium
+	}
+	switch settings.DefaultSeverity {
+	case models.SeverityCritical, models.SeverityHigh, models.SeverityMedium, models.SeverityLow, models.SeverityNone:
+	default:
+		return fmt.Errorf("invalid default_severity: %s", settings.DefaultSeverity)
+	}
+
+	for i, sender := range settings.AllowedSenders {
+		sender = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(sender), "@"))
+		if sender == "" || strings.Count(sender, "@") > 1 || strings.ContainsAny(sender, " ,;<>") {
+			return fmt.Errorf("invalid allowed sender: %q", settings.AllowedSenders[i])
+		}
+		settings.AllowedSenders[i] = sender
+	}
+
+	if settings.Enabled {
+		if settings.ReporterUserID == uuid.Nil {
+			return fmt.Errorf("reporter_user_id is required")
+		}
+		if settings.Host == "" && settings.WebhookSecret == "" {
+			return fmt.Errorf("a mailbox host or a webhook secret is required")
+		}
+		if settings.Host != "" && (settings.Username == "" || settings.Password == "") {
+			return fmt.Errorf("username and password are required to poll a mailbox")
+		}
+	}
+	return nil
+}
+
+// ParseEmailIngestionSettings parses and validates an email_ingestion setting value
+func ParseEmailIngestionSettings(value string) (*EmailIngestionSettings, error) {
+	var settings EmailIngestionSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return nil, fmt.Errorf("invalid email ingestion settings: %v", err)
+	}
+	if err := ValidateEmailIngestionSettings(&settings); err != nil {
+		return nil, fmt.Errorf("invalid email ingestion settings: %v", err)
+	}
+	return &settings, nil
+}
+
+// PrepareEmailIngestionSetting validates an email_ingestion setting value before it is saved and
+// returns the value to store: secrets left empty (or sent back as read) keep their stored value
+// and new secrets are encrypted
+func PrepareEmailIngestionSetting(db *gorm.DB, value string) (string, error) {
+	var settings EmailIngestionSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return "", fmt.Errorf("invalid email ingestion settings: %v", err)
+	}
+
+	var stored EmailIngestionSettings
+	var existing models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingEmailIngestion)).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("failed to load email ingestion settings: %w", err)
+	}
+	if err == nil {
+		json.Unmarshal([]byte(existing.Value), &stored)
+	}
+
+	keyring := activeSecretKeyring("")
+	ctx := context.Background()
+	keepStored := func(secret *string, storedSecret string) error {
+		if storedSecret == "" || (*secret != "" && *secret != storedSecret) {
+			return nil
+		}
+		plaintext, err := keyring.Decrypt(ctx, storedSecret)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt stored email ingestion secret: %w", err)
+		}
+		*secret = plaintext
+		return nil
+	}
+	if err := keepStored(&settings.Password, stored.Password); err != nil {
+		return "", err
+	}
+	if err := keepStored(&settings.WebhookSecret, stored.WebhookSecret); err != nil {
+		return "", err
+	}
+
+	if err := ValidateEmailIngestionSettings(&settings); err != nil {
+		return "", fmt.Errorf("invalid email ingestion settings: %v", err)
+	}
+	if settings.ReporterUserID != uuid.Nil {
+		var count int64
+		if err := db.Model(&models.User{}).Where("id = ?", settings.ReporterUserID).Count(&count).Error; err != nil {
+			return "", fmt.Errorf("failed to check reporter user: %w", err)
+		}
+		if count == 0 {
+			return "", fmt.Errorf("invalid email ingestion settings: reporter user not found")
+		}
+	}
+
+	for _, secret := range []*string{&settings.Password, &settings.WebhookSecret} {
+		if *secret == "" {
+			continue
+		}
+		encrypted, err := keyring.Encrypt(ctx, *secret)
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt email ingestion secret: %w", err)
+		}
+		*secret = encrypted
+	}
+
+	normalized, _ := json.Marshal(settings)
+	return string(normalized), nil
+}
+
+// LoadEmailIngestionSettings returns the configured email ingestion with its secrets decrypted;
+// without the setting ingestion is disabled
+func LoadEmailIngestionSettings(db *gorm.DB) (*EmailIngestionSettings, error) {
+	var setting models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingEmailIngestion)).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &EmailIngestionSettings{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email ingestion settings: %w", err)
+	}
+
+	var settings EmailIngestionSettings
+	if err := json.Unmarshal([]byte(setting.Value), &settings); err != nil {
+		return nil, fmt.Errorf("invalid email ingestion settings: %v", err)
+	}
+	keyring := activeSecretKeyring("")
+	for _, secret := range []*string{&settings.Password, &settings.WebhookSecret} {
+		if *secret == "" {
+			continue
+		}
+		plaintext, err := keyring.Decrypt(context.Background(), *secret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt email ingestion secret: %w", err)
+		}
+		*secret = plaintext
+	}
+	return &settings, nil
+}
+
+// SenderAllowed reports whether a sender address matches the allowed senders (exact addresses
+// or domains, including their subdomains); every sender is allowed when the list is empty
+func SenderAllowed(allowed []string, from string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	from = strings.ToLower(from)
+	_, domain, _ := strings.Cut(from, "@")
+	for _, sender := range allowed {
+		if strings.Contains(sender, "@") {
+			if sender == from {
+				return true
+			}
+			continue
+		}
+		if domain == sender || strings.HasSuffix(domain, "."+sender) {
+			return true
+		}
+	}
+	return false
+}
+
+// EmailIngestionService turns emailed vulnerability reports into draft vulnerabilities with the
+// email's attachments. Every message is recorded, so duplicates are ignored and rejected or
+// failed messages can be reviewed.
+type EmailIngestionService struct {
+	db                *gorm.DB
+	attachmentService *VulnerabilityAttachmentService
+
+	// pollMu keeps this instance from polling the mailbox twice at once
+	pollMu sync.Mutex
+}
+
+// NewEmailIngestionService creates a new email ingestion service
+func NewEmailIngestionService(db *gorm.DB) *EmailIngestionService {
+	return &EmailIngestionService{
+		db:                db,
+		attachmentService: NewVulnerabilityAttachmentService(db),
+	}
+}
+
+// CheckWebhookSecret reports whether a token matches the configured webhook secret; the webhook
+// is closed while ingestion is disabled or no secret is configured
+func (s *EmailIngestionService) CheckWebhookSecret(token string) (bool, error) {
+	settings, err := LoadEmailIngestionSettings(s.db)
+	if err != nil {
+		return false, err
+	}
+	if !settings.Enabled || settings.WebhookSecret == "" || token == "" {
+		return false, nil
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(settings.WebhookSecret)) == 1, nil
+}
+
+// Ingest processes a raw message received on a channel. Messages seen before are returned as
+// recorded unless their processing failed, in which case they are processed again.
+func (s *EmailIngestionService) Ingest(raw []byte, channel models.InboundEmailChannel) (*models.InboundEmail, error) {
+	settings, err := LoadEmailIngestionSettings(s.db)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, ErrEmailIngestionDisabled
+	}
+
+	msg, err := ParseInboundEmail(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var record models.InboundEmail
+	err = s.db.Where("message_id = ?", msg.MessageID).First(&record).Error
+	if err == nil && record.Status != models.InboundEmailFailed {
+		return &record, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up inbound email: %w", err)
+	}
+
+	record.MessageID = msg.MessageID
+	record.Channel = channel
+	record.FromAddress = msg.From
+	record.Subject = truncateRunes(msg.Subject, 500)
+	record.SentAt = msg.Date
+	record.Reason = ""
+
+	if !SenderAllowed(settings.AllowedSenders, msg.From) {
+		record.Status = models.InboundEmailRejected
+		record.Reason = "sender is not in the allowed senders"
+		if err := s.db.Save(&record).Error; err != nil {
+			return nil, fmt.Errorf("failed to record inbound email: %w", err)
+		}
+		utils.Logger.Warn().Str("from", msg.From).Str("message_id", msg.MessageID).Msg("Rejected vulnerability report email from a sender that is not allowed")
+		return &record, nil
+	}
+
+	vulnerability, skipped, err := s.createDraft(settings, msg)
+	if err != nil {
+		record.Status = models.InboundEmailFailed
+		record.Reason = err.Error()
+		if saveErr := s.db.Save(&record).Error; saveErr != nil {
+			utils.Logger.Error().Err(saveErr).Str("message_id", msg.MessageID).Msg("Failed to record inbound email")
+		}
+		return &record, err
+	}
+
+	record.Status = models.InboundEmailCreated
+	record.VulnerabilityID = &vulnerability.ID
+	record.AttachmentCount = len(msg.Attachments) - len(skipped)
+	record.Reason = strings.Join(skipped, "; ")
+	if err := s.db.Save(&record).Error; err != nil {
+		return nil, fmt.Errorf("failed to record inbound email: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("vulnerability_id", vulnerability.ID.String()).
+		Str("from", msg.From).
+		Str("channel", string(channel)).
+		Int("attachments", record.AttachmentCount).
+		Msg("Created draft vulnerability from emailed report")
+	return &record, nil
+}
+
+// createDraft creates the draft vulnerability of a report in the reporter's organization and
+// attaches the email's files; attachments the attachment policy refuses are skipped and listed
+func (s *EmailIngestionService) createDraft(settings *EmailIngestionSettings, msg *InboundEmailMessage) (*models.Vulnerability, []string, error) {
+	var reporter models.User
+	if err := s.db.First(&reporter, "id = ?", settings.ReporterUserID).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load reporter user: %w", err)
+	}
+	ctx := context.Background()
+	if reporter.OrgID != nil {
+		ctx = tenant.WithOrg(ctx, *reporter.OrgID)
+	}
+	db := s.db.WithContext(ctx)
+
+	report := ParseEmailReport(msg.Subject, msg.Text, settings.DefaultSeverity)
+	discovered := time.Now()
+	if msg.Date != nil && msg.Date.Before(discovered) {
+		discovered = *msg.Date
+	}
+
+	sender := msg.From
+	if msg.FromName != "" {
+		sender = fmt.Sprintf("%s <%s>", msg.FromName, msg.From)
+	}
+	description := report.Description
+	if description != "" {
+		description += "\n\n"
+	}
+	description += fmt.Sprintf("Reported by email from %s on %s.", sender, discovered.UTC().Format(time.RFC1123))
+
+	vulnerability := &models.Vulnerability{
+		Title:                     report.Title,
+		Description:               description,
+		Severity:                  report.Severity,
+		CVSSScore:                 report.CVSSScore,
+		CVSSVector:                report.CVSSVector,
+		CVEID:                     report.CVEID,
+		Status:                    models.StatusDraft,
+		Source:                    EmailSourceName,
+		DiscoveryDate:             discovered,
+		ImpactAssessment:          report.ImpactAssessment,
+		StepsToReproduce:          report.StepsToReproduce,
+		MitigationRecommendations: report.MitigationRecommendations,
+		CreatedByID:               reporter.ID,
+	}
+	if err := db.Create(vulnerability).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to create draft vulnerability: %w", err)
+	}
+
+	var skipped []string
+	attachments := NewVulnerabilityAttachmentService(db)
+	for _, attachment := range msg.Attachments {
+		_, err := attachments.UploadAttachmentData(vulnerability.ID, attachment.Filename, attachment.ContentType, attachment.Data,
+			models.AttachmentTypeProof, "Attached to the emailed report", reporter.ID)
+		if err != nil {
+			if !IsAttachmentPolicyViolation(err) {
+				utils.Logger.Error().Err(err).Str("vulnerability_id", vulnerability.ID.String()).Msg("Failed to store email attachment")
+			}
+			skipped = append(skipped, fmt.Sprintf("skipped attachment %s: %v", attachment.Filename, err))
+		}
+	}
+	return vulnerability, skipped, nil
+}
+
+// PollInterval returns the configured time between mailbox polls, or 0 when polling is off
+func (s *EmailIngestionService) PollInterval() (time.Duration, error) {
+	settings, err := LoadEmailIngestionSettings(s.db)
+	if err != nil {
+		return 0, err
+	}
+	if !settings.Enabled || settings.Host == "" {
+		return 0, nil
+	}
+	return time.Duration(settings.PollIntervalMins) * time.Minute, nil
+}
+
+// Poll ingests the unseen messages of the configured mailbox and flags them as seen. Messages
+// whose processing failed stay unseen so the next poll retries them. It returns how many
+// messages it processed.
+func (s *EmailIngestionService) Poll() (int, error) {
+	settings, err := LoadEmailIngestionSettings(s.db)
+	if err != nil {
+		return 0, err
+	}
+	if !settings.Enabled || settings.Host == "" {
+		return 0, nil
+	}
+
+	s.pollMu.Lock()
+	defer s.pollMu.Unlock()
+
+	client, err := mailbox.Dial(mailbox.Config{
+		Host:               settings.Host,
+		Port:               settings.Port,
+		Security:           settings.Security,
+		InsecureSkipVerify: settings.InsecureSkipVerify,
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+
+	if err := client.Login(settings.Username, settings.Password); err != nil {
+		return 0, err
+	}
+	if err := client.Select(settings.Mailbox); err != nil {
+		return 0, err
+	}
+	uids, err := client.SearchUnseen()
+	if err != nil {
+		return 0, err
+	}
+	if len(uids) > maxEmailPollMessages {
+		uids = uids[:maxEmailPollMessages]
+	}
+
+	processed := 0
+	for _, uid := range uids {
+		raw, err := client.Fetch(uid)
+		if err != nil {
+			return processed, err
+		}
+		if _, err := s.Ingest(raw, models.InboundEmailChannelIMAP); err != nil {
+			if !errors.Is(err, ErrInvalidInboundEmail) {
+				utils.Logger.Error().Err(err).Uint32("uid", uid).Msg("Failed to ingest emailed vulnerability report, will retry")
+				continue
+			}
+			utils.Logger.Warn().Err(err).Uint32("uid", uid).Msg("Skipping unparseable email in the vulnerability report mailbox")
+		}
+		if err := client.MarkSeen(uid); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+
+	if err := client.Logout(); err != nil {
+		utils.Logger.Warn().Err(err).Msg("Failed to log out of the vulnerability report mailbox")
+	}
+	return processed, nil
+}
+
+// ListInboundEmails lists received report emails, newest first
+func (s *EmailIngestionService) ListInboundEmails(status models.InboundEmailStatus, page, limit int) ([]models.InboundEmail, int64, error) {
+	query := s.db.Model(&models.InboundEmail{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count inbound emails: %w", err)
+	}
+
+	var emails []models.InboundEmail
+	if err := query.
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&emails).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list inbound emails: %w", err)
+	}
+	return emails, total, nil
+}
+
+// truncateRunes shortens a string to at most max bytes without splitting a character
+func truncateRunes(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+	return strings.ToValidUTF8(value[:max], "")
+}
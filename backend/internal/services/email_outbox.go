@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailOutboxMaxAttempts is how many times a queued email is tried before it is dead-lettered
+const EmailOutboxMaxAttempts = 8
+
+// Retry delays of queued emails: doubling from the first, capped at the last. With
+// EmailOutboxMaxAttempts an email is given up on about an hour after it was queued.
+const (
+	emailOutboxFirstRetryDelay = 30 * time.Second
+	emailOutboxMaxRetryDelay   = time.Hour
+)
+
+// EmailOutboxRetryDelay returns how long to wait before the next attempt after a number of
+// failed attempts
+func EmailOutboxRetryDelay(attempts int) time.Duration {
+	delay := emailOutboxFirstRetryDelay
+	for i := 1; i < attempts && delay < emailOutboxMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > emailOutboxMaxRetryDelay {
+		delay = emailOutboxMaxRetryDelay
+	}
+	return delay
+}
+
+// Enqueue queues an email for the email-outbox job, so the caller doesn't wait on the provider
+func (s *EmailDeliveryService) Enqueue(category, to, subject, html string) (*models.EmailOutboxEntry, error) {
+	entry := &models.EmailOutboxEntry{
+		Category:      category,
+		Recipient:     to,
+		Subject:       truncateRunes(subject, 500),
+		HTML:          html,
+		Status:        models.EmailOutboxPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := s.db.Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to queue email: %w", err)
+	}
+	return entry, nil
+}
+
+// ProcessOutbox sends a batch of queued emails that are due. A failed email is retried with
+// backoff and dead-lettered after EmailOutboxMaxAttempts attempts. Returns the number of emails
+// sent.
+func (s *EmailDeliveryService) ProcessOutbox(ctx context.Context, batchSize int) (int, error) {
+	sent := 0
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var entries []models.EmailOutboxEntry
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.EmailOutboxPending, time.Now()).
+			Order("next_attempt_at ASC").
+			Limit(batchSize).
+			Find(&entries).Error; err != nil {
+			return fmt.Errorf("failed to load email outbox: %w", err)
+		}
+
+		for _, entry := range entries {
+			if ctx.Err() != nil {
+				// Shutting down; the rest stay due for the next run
+				return nil
+			}
+
+			delivery, sendErr := s.Deliver(entry.Category, entry.Recipient, entry.Subject, entry.HTML)
+			now := time.Now()
+			if sendErr == nil {
+				if err := tx.Model(&entry).Updates(map[string]interface{}{
+					"status":      models.EmailOutboxSent,
+					"attempts":    entry.Attempts + 1,
+					"delivery_id": delivery.ID,
+					"sent_at":     now,
+					"html":        "",
+					"last_error":  "",
+				}).Error; err != nil {
+					return err
+				}
+				sent++
+				continue
+			}
+
+			attempts := entry.Attempts + 1
+			updates := map[string]interface{}{
+				"attempts":        attempts,
+				"last_error":      sendErr.Error(),
+				"next_attempt_at": now.Add(EmailOutboxRetryDelay(attempts)),
+			}
+			if attempts >= EmailOutboxMaxAttempts {
+				updates["status"] = models.EmailOutboxDead
+				utils.Logger.Error().Err(sendErr).
+					Str("email_id", entry.ID.String()).
+					Str("to", entry.Recipient).
+					Int("attempts", attempts).
+					Msg("Giving up on queued email")
+			}
+			if err := tx.Model(&entry).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return sent, fmt.Errorf("failed to process email outbox: %w", err)
+	}
+	return sent, nil
+}
+
+// ListOutbox lists queued emails, optionally of one status, most recently queued first
+func (s *EmailDeliveryService) ListOutbox(status models.EmailOutboxStatus, page, limit int) ([]models.EmailOutboxEntry, int64, error) {
+	query := s.db.Model(&models.EmailOutboxEntry{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count queued emails: %w", err)
+	}
+
+	var entries []models.EmailOutboxEntry
+	if err := query.
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list queued emails: %w", err)
+	}
+	return entries, total, nil
+}
+
+// RetryOutboxEntry queues a dead-lettered email again with a fresh set of attempts
+func (s *EmailDeliveryService) RetryOutboxEntry(id uuid.UUID) (*models.EmailOutboxEntry, error) {
+	var entry models.EmailOutboxEntry
+	err := s.db.Where("id = ?", id).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("queued email not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load queued email: %w", err)
+	}
+	if entry.Status != models.EmailOutboxDead {
+		return nil, fmt.Errorf("invalid retry: only DEAD emails can be retried, this one is %s", entry.Status)
+	}
+
+	entry.Status = models.EmailOutboxPending
+	entry.Attempts = 0
+	entry.NextAttemptAt = time.Now()
+	if err := s.db.Model(&entry).Updates(map[string]interface{}{
+		"status":          entry.Status,
+		"attempts":        entry.Attempts,
+		"next_attempt_at": entry.NextAttemptAt,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to retry queued email: %w", err)
+	}
+	return &entry, nil
+}
+
+// PurgeSentOutbox deletes sent outbox entries older than maxAge; the delivery log keeps the
+// record of the email. Returns the number deleted.
+func (s *EmailDeliveryService) PurgeSentOutbox(maxAge time.Duration) (int64, error) {
+	result := s.db.Unscoped().
+		Where("status = ? AND sent_at < ?", models.EmailOutboxSent, time.Now().Add(-maxAge)).
+		Delete(&models.EmailOutboxEntry{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge sent emails: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
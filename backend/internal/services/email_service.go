@@ -2,91 +2,110 @@ package services
 
 import (
 	"fmt"
-	"net/smtp"
+	"html"
 	"strings"
 
+	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/cyops/cyops-backend/pkg/i18n"
 	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Email categories recorded on deliveries
+const (
+	EmailCategoryVerification  = "verification"
+	EmailCategoryPasswordReset = "password_reset"
+	EmailCategoryDigest        = "digest"
 )
 
 // EmailService handles email sending
 type EmailService struct {
-	config *config.Config
+	config   *config.Config
+	delivery *EmailDeliveryService
 }
 
-// NewEmailService creates a new email service
-func NewEmailService(cfg *config.Config) *EmailService {
+// NewEmailService creates a new email service. Mail goes through the provider configured in the
+// email_delivery setting, or the SMTP server from cfg without one.
+func NewEmailService(db *gorm.DB, cfg *config.Config) *EmailService {
 	return &EmailService{
-		config: cfg,
+		config:   cfg,
+		delivery: NewEmailDeliveryService(db, cfg),
 	}
 }
 
-// SendVerificationEmail sends an email verification email
-func (s *EmailService) SendVerificationEmail(to, name, token string) error {
+// SendVerificationEmail sends an email verification email in lang. expiresAt is the link
+// expiry, already formatted for the recipient (see UserPreferenceService.FormatTime).
+func (s *EmailService) SendVerificationEmail(to, name, lang, token, expiresAt string) error {
 	if !s.isConfigured() {
-		utils.Logger.Warn().Msg("SMTP not configured, email not sent (check logs in development)")
+		utils.Logger.Warn().Msg("Email delivery not configured, email not sent (check logs in development)")
 		// In development, log the verification link instead of sending email
 		utils.Logger.Info().
 			Str("to", to).
 			Str("token", token).
 			Str("verification_url", s.buildVerificationURL(token)).
-			Msg("Verification email (not sent - SMTP not configured)")
+			Msg("Verification email (not sent - email delivery not configured)")
 		return nil
 	}
 
-	subject := "Verify Your Email Address"
-	body := s.buildVerificationEmailBody(name, token)
+	subject := i18n.T(lang, "email.verify.subject")
+	body := s.buildVerificationEmailBody(name, lang, token, expiresAt)
 
-	return s.sendEmail(to, subject, body)
+	return s.sendEmail(EmailCategoryVerification, to, subject, body)
 }
 
-// SendPasswordResetEmail sends a password reset email
-func (s *EmailService) SendPasswordResetEmail(to, name, token string) error {
+// SendPasswordResetEmail sends a password reset email in lang. expiresAt is the link expiry,
+// already formatted for the recipient.
+func (s *EmailService) SendPasswordResetEmail(to, name, lang, token, expiresAt string) error {
 	if !s.isConfigured() {
-		utils.Logger.Warn().Msg("SMTP not configured, email not sent (check logs in development)")
+		utils.Logger.Warn().Msg("Email delivery not configured, email not sent (check logs in development)")
 		utils.Logger.Info().
 			Str("to", to).
 			Str("token", token).
 			Str("reset_url", s.buildPasswordResetURL(token)).
-			Msg("Password reset email (not sent - SMTP not configured)")
+			Msg("Password reset email (not sent - email delivery not configured)")
 		return nil
 	}
 
-	subject := "Reset Your Password"
-	body := s.buildPasswordResetEmailBody(name, token)
+	subject := i18n.T(lang, "email.reset.subject")
+	body := s.buildPasswordResetEmailBody(name, lang, token, expiresAt)
 
-	return s.sendEmail(to, subject, body)
+	return s.sendEmail(EmailCategoryPasswordReset, to, subject, body)
 }
 
-// sendEmail sends an email using SMTP
-func (s *EmailService) sendEmail(to, subject, body string) error {
-	from := s.config.FromEmail
-
-	// Set up authentication
-	auth := smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
-
-	// Build message
-	msg := []byte(fmt.Sprintf("To: %s\r\n"+
-		"From: %s\r\n"+
-		"Subject: %s\r\n"+
-		"Content-Type: text/html; charset=UTF-8\r\n"+
-		"\r\n"+
-		"%s\r\n", to, from, subject, body))
-
-	// Send email
-	addr := fmt.Sprintf("%s:%d", s.config.SMTPHost, s.config.SMTPPort)
-	if err := smtp.SendMail(addr, auth, from, []string{to}, msg); err != nil {
-		utils.Logger.Error().Err(err).Str("to", to).Msg("Failed to send email")
-		return fmt.Errorf("failed to send email: %w", err)
+// SendDigestEmail sends a notification digest in the user's language, with times in their
+// timezone and date format
+func (s *EmailService) SendDigestEmail(to, name string, pref *models.UserPreference, digest *NotificationDigestContent) error {
+	if !s.isConfigured() {
+		utils.Logger.Warn().Str("to", to).Msg("Email delivery not configured, notification digest not sent")
+		return nil
+	}
+
+	lang := pref.Language
+	if lang == "" {
+		lang = i18n.Default
 	}
+	subject := i18n.T(lang, "email.digest.subject_"+string(digest.Frequency))
+	body := s.buildDigestEmailBody(name, lang, pref, digest)
 
-	utils.Logger.Info().Str("to", to).Str("subject", subject).Msg("Email sent successfully")
+	return s.sendEmail(EmailCategoryDigest, to, subject, body)
+}
+
+// sendEmail queues an email for the email-outbox job, which sends it through the configured
+// provider and retries failures, so requests don't wait on the mail server
+func (s *EmailService) sendEmail(category, to, subject, body string) error {
+	entry, err := s.delivery.Enqueue(category, to, subject, body)
+	if err != nil {
+		utils.Logger.Error().Err(err).Str("to", to).Msg("Failed to queue email")
+		return err
+	}
+	utils.Logger.Info().Str("to", to).Str("subject", subject).Str("email_id", entry.ID.String()).Msg("Email queued")
 	return nil
 }
 
-// isConfigured checks if SMTP is configured
+// isConfigured checks if an email provider or SMTP is configured
 func (s *EmailService) isConfigured() bool {
-	return s.config.SMTPHost != "" && s.config.SMTPUsername != ""
+	return s.delivery.Configured()
 }
 
 // buildVerificationURL builds the email verification URL
@@ -102,72 +121,174 @@ func (s *EmailService) buildPasswordResetURL(token string) string {
 	return fmt.Sprintf("%s/reset-password?token=%s", frontendURL, token)
 }
 
-// buildVerificationEmailBody builds the verification email body
-func (s *EmailService) buildVerificationEmailBody(name, token string) string {
-	verificationURL := s.buildVerificationURL(token)
+// buildVulnerabilityURL builds the link to a vulnerability
+func (s *EmailService) buildVulnerabilityURL(id string) string {
+	frontendURL := "http://localhost:3000" // TODO: Get from config
+	return fmt.Sprintf("%s/vulnerabilities/%s", frontendURL, id)
+}
 
-	greeting := "Hello"
-	if name != "" {
-		greeting = fmt.Sprintf("Hello %s", name)
+// emailGreeting returns the salutation of an email, HTML-escaped
+func emailGreeting(name, lang string) string {
+	if name == "" {
+		return i18n.T(lang, "email.greeting")
 	}
+	return html.EscapeString(i18n.T(lang, "email.greeting_name", name))
+}
+
+// buildVerificationEmailBody builds the verification email body
+func (s *EmailService) buildVerificationEmailBody(name, lang, token, expiresAt string) string {
+	verificationURL := s.buildVerificationURL(token)
 
 	body := fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s" dir="%s">
 <head>
     <meta charset="UTF-8">
-    <title>Verify Your Email</title>
+    <title>%s</title>
 </head>
 <body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
-    <h2 style="color: #4a5568;">Verify Your Email Address</h2>
+    <h2 style="color: #4a5568;">%s</h2>
     <p>%s,</p>
-    <p>Thank you for registering! Please verify your email address by clicking the button below:</p>
+    <p>%s</p>
     <div style="text-align: center; margin: 30px 0;">
-        <a href="%s" style="background-color: #4299e1; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; display: inline-block;">Verify Email</a>
+        <a href="%s" style="background-color: #4299e1; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; display: inline-block;">%s</a>
     </div>
-    <p>Or copy and paste this link into your browser:</p>
-    <p style="word-break: break-all; color: #4299e1;">%s</p>
+    <p>%s</p>
+    <p dir="ltr" style="word-break: break-all; color: #4299e1;">%s</p>
     <p style="color: #718096; font-size: 14px; margin-top: 30px;">
-        This verification link will expire in 24 hours. If you didn't create an account, please ignore this email.
+        %s
     </p>
 </body>
 </html>
-`, greeting, verificationURL, verificationURL)
+`, lang, i18n.Direction(lang),
+		i18n.T(lang, "email.verify.title"),
+		i18n.T(lang, "email.verify.subject"),
+		emailGreeting(name, lang),
+		i18n.T(lang, "email.verify.intro"),
+		verificationURL, i18n.T(lang, "email.verify.button"),
+		i18n.T(lang, "email.copy_link"),
+		verificationURL,
+		i18n.T(lang, "email.verify.expiry", html.EscapeString(expiresAt)))
 
 	return strings.TrimSpace(body)
 }
 
 // buildPasswordResetEmailBody builds the password reset email body
-func (s *EmailService) buildPasswordResetEmailBody(name, token string) string {
+func (s *EmailService) buildPasswordResetEmailBody(name, lang, token, expiresAt string) string {
 	resetURL := s.buildPasswordResetURL(token)
 
-	greeting := "Hello"
-	if name != "" {
-		greeting = fmt.Sprintf("Hello %s", name)
-	}
-
 	body := fmt.Sprintf(`
 <!DOCTYPE html>
-<html>
+<html lang="%s" dir="%s">
 <head>
     <meta charset="UTF-8">
-    <title>Reset Your Password</title>
+    <title>%s</title>
 </head>
 <body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
-    <h2 style="color: #4a5568;">Reset Your Password</h2>
+    <h2 style="color: #4a5568;">%s</h2>
     <p>%s,</p>
-    <p>You requested to reset your password. Click the button below to set a new password:</p>
+    <p>%s</p>
     <div style="text-align: center; margin: 30px 0;">
-        <a href="%s" style="background-color: #4299e1; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; display: inline-block;">Reset Password</a>
+        <a href="%s" style="background-color: #4299e1; color: white; padding: 12px 24px; text-decoration: none; border-radius: 5px; display: inline-block;">%s</a>
     </div>
-    <p>Or copy and paste this link into your browser:</p>
-    <p style="word-break: break-all; color: #4299e1;">%s</p>
+    <p>%s</p>
+    <p dir="ltr" style="word-break: break-all; color: #4299e1;">%s</p>
+    <p style="color: #718096; font-size: 14px; margin-top: 30px;">
+        %s
+    </p>
+</body>
+</html>
+`, lang, i18n.Direction(lang),
+		i18n.T(lang, "email.reset.subject"),
+		i18n.T(lang, "email.reset.subject"),
+		emailGreeting(name, lang),
+		i18n.T(lang, "email.reset.intro"),
+		resetURL, i18n.T(lang, "email.reset.button"),
+		i18n.T(lang, "email.copy_link"),
+		resetURL,
+		i18n.T(lang, "email.reset.expiry", html.EscapeString(expiresAt)))
+
+	return strings.TrimSpace(body)
+}
+
+// buildDigestEmailBody builds the notification digest email body
+func (s *EmailService) buildDigestEmailBody(name, lang string, pref *models.UserPreference, digest *NotificationDigestContent) string {
+	var sections strings.Builder
+	dateLayout := pref.DateFormat.Layout()
+	loc := pref.Location()
+
+	vulnerabilityList := func(items []DigestVulnerability, total int64, describe func(DigestVulnerability) string) {
+		sections.WriteString(`<ul style="padding-inline-start: 20px;">`)
+		for _, item := range items {
+			fmt.Fprintf(&sections, `<li><a href="%s" style="color: #4299e1;">%s</a> &middot; %s &middot; %s</li>`,
+				s.buildVulnerabilityURL(item.ID.String()), html.EscapeString(item.Title),
+				i18n.T(lang, "severity."+string(item.Severity)), describe(item))
+		}
+		sections.WriteString(`</ul>`)
+		if more := total - int64(len(items)); more > 0 {
+			fmt.Fprintf(&sections, `<p style="color: #718096;">%s</p>`, i18n.T(lang, "email.digest.more", more))
+		}
+	}
+
+	if digest.AssignedTotal > 0 {
+		fmt.Fprintf(&sections, `<h3 style="color: #4a5568;">%s</h3>`, i18n.T(lang, "email.digest.assigned", digest.AssignedTotal))
+		vulnerabilityList(digest.Assigned, digest.AssignedTotal, func(item DigestVulnerability) string {
+			return i18n.T(lang, "email.digest.discovered", item.DiscoveryDate.Format(dateLayout))
+		})
+	}
+
+	if digest.SLATotal > 0 {
+		fmt.Fprintf(&sections, `<h3 style="color: #c53030;">%s</h3>`, i18n.T(lang, "email.digest.sla", digest.SLATotal))
+		vulnerabilityList(digest.SLAWarnings, digest.SLATotal, func(item DigestVulnerability) string {
+			dueOn := item.DueAt.Format(dateLayout)
+			if item.Overdue {
+				return `<strong style="color: #c53030;">` + i18n.T(lang, "email.digest.overdue", dueOn) + `</strong>`
+			}
+			return i18n.T(lang, "email.digest.due", dueOn)
+		})
+	}
+
+	if len(digest.Imports) > 0 {
+		fmt.Fprintf(&sections, `<h3 style="color: #4a5568;">%s</h3><ul style="padding-inline-start: 20px;">`, i18n.T(lang, "email.digest.imports", len(digest.Imports)))
+		for _, job := range digest.Imports {
+			outcome := i18n.T(lang, "email.digest.import_completed", job.ImportedVulnerabilities, job.NewFindings)
+			if job.Status == models.ImportJobFailed {
+				outcome = i18n.T(lang, "email.digest.import_failed", html.EscapeString(job.Error))
+			}
+			fmt.Fprintf(&sections, `<li>%s &middot; %s &middot; %s</li>`,
+				html.EscapeString(job.Scanner), html.EscapeString(pref.FormatTime(job.CompletedAt)), outcome)
+		}
+		sections.WriteString(`</ul>`)
+	}
+
+	if digest.UnreadNotifications > 0 {
+		fmt.Fprintf(&sections, `<p>%s</p>`, i18n.T(lang, "email.digest.unread", digest.UnreadNotifications))
+	}
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="%s" dir="%s">
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
+    <h2 style="color: #4a5568;">%s</h2>
+    <p>%s,</p>
+    <p>%s</p>
+    %s
     <p style="color: #718096; font-size: 14px; margin-top: 30px;">
-        This password reset link will expire in 1 hour. If you didn't request this, please ignore this email and your password will remain unchanged.
+        %s
     </p>
 </body>
 </html>
-`, greeting, resetURL, resetURL)
+`, lang, i18n.Direction(lang),
+		i18n.T(lang, "email.digest.subject_"+string(digest.Frequency)),
+		i18n.T(lang, "email.digest.subject_"+string(digest.Frequency)),
+		emailGreeting(name, lang),
+		i18n.T(lang, "email.digest.intro", digest.Since.In(loc).Format(dateLayout+" 15:04"), digest.Until.In(loc).Format(dateLayout+" 15:04 MST")),
+		sections.String(),
+		i18n.T(lang, "email.digest.footer"))
 
 	return strings.TrimSpace(body)
 }
@@ -0,0 +1,456 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// escalationWebhookTimeout bounds a notification to an escalation channel
+const escalationWebhookTimeout = 10 * time.Second
+
+// EscalationService manages escalation policies and escalates the open vulnerabilities that
+// exceed their age thresholds
+type EscalationService struct {
+	db         *gorm.DB
+	httpClient *http.Client
+}
+
+// NewEscalationService creates a new escalation service
+func NewEscalationService(db *gorm.DB) *EscalationService {
+	return &EscalationService{
+		db:         db,
+		httpClient: &http.Client{Timeout: escalationWebhookTimeout},
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *EscalationService) WithContext(ctx context.Context) *EscalationService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// EscalationPolicyRequest represents a create or update escalation policy request
+type EscalationPolicyRequest struct {
+	Name               *string `json:"name,omitempty"`
+	Description        *string `json:"description,omitempty"`
+	Enabled            *bool   `json:"enabled,omitempty"`
+	Severity           *string `json:"severity,omitempty"`
+	AgeDays            *int    `json:"age_days,omitempty"`
+	ReassignToTeamLead *bool   `json:"reassign_to_team_lead,omitempty"`
+	RaisePriority      *bool   `json:"raise_priority,omitempty"`
+	NotifyWebhookURL   *string `json:"notify_webhook_url,omitempty"` // "" stops notifying a channel
+}
+
+// applyTo copies the provided request fields onto a policy
+func (req EscalationPolicyRequest) applyTo(policy *models.EscalationPolicy) {
+	if req.Name != nil {
+		policy.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		policy.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+	if req.Severity != nil {
+		policy.Severity = models.VulnerabilitySeverity(strings.ToUpper(strings.TrimSpace(*req.Severity)))
+	}
+	if req.AgeDays != nil {
+		policy.AgeDays = *req.AgeDays
+	}
+	if req.ReassignToTeamLead != nil {
+		policy.ReassignToTeamLead = *req.ReassignToTeamLead
+	}
+	if req.RaisePriority != nil {
+		policy.RaisePriority = *req.RaisePriority
+	}
+	if req.NotifyWebhookURL != nil {
+		policy.NotifyWebhookURL = strings.TrimSpace(*req.NotifyWebhookURL)
+	}
+}
+
+// ValidateEscalationPolicy checks that a policy has a name, a valid severity and age, a valid
+// channel URL and at least one action
+func ValidateEscalationPolicy(policy *models.EscalationPolicy) error {
+	if policy.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch policy.Severity {
+	case models.SeverityCritical, models.SeverityHigh, models.SeverityMedium, models.SeverityLow, models.SeverityNone:
+	default:
+		return fmt.Errorf("invalid severity, must be one of: CRITICAL, HIGH, MEDIUM, LOW, NONE")
+	}
+	if policy.AgeDays < 1 {
+		return fmt.Errorf("invalid age_days, must be at least 1")
+	}
+	if policy.NotifyWebhookURL != "" {
+		parsed, err := url.Parse(policy.NotifyWebhookURL)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return fmt.Errorf("invalid notify_webhook_url, must be an http or https URL")
+		}
+	}
+	if !policy.ReassignToTeamLead && !policy.RaisePriority && policy.NotifyWebhookURL == "" {
+		return fmt.Errorf("invalid escalation policy, at least one action is required")
+	}
+	return nil
+}
+
+// ListPolicies returns all escalation policies ordered by severity threshold
+func (s *EscalationService) ListPolicies(enabledOnly bool) ([]models.EscalationPolicy, error) {
+	query := s.db.Preload("CreatedBy").Order("severity, age_days")
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+
+	var policies []models.EscalationPolicy
+	if err := query.Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("failed to list escalation policies: %w", err)
+	}
+	return policies, nil
+}
+
+// GetPolicy returns a single escalation policy
+func (s *EscalationService) GetPolicy(id uuid.UUID) (*models.EscalationPolicy, error) {
+	var policy models.EscalationPolicy
+	if err := s.db.Preload("CreatedBy").First(&policy, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("escalation policy not found")
+		}
+		return nil, fmt.Errorf("failed to get escalation policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// CreatePolicy creates a new escalation policy; it is applied by the next escalation run
+func (s *EscalationService) CreatePolicy(req EscalationPolicyRequest, createdByID uuid.UUID) (*models.EscalationPolicy, error) {
+	policy := &models.EscalationPolicy{
+		Enabled:     true,
+		CreatedByID: createdByID,
+	}
+	req.applyTo(policy)
+	if req.AgeDays == nil {
+		return nil, fmt.Errorf("age_days is required")
+	}
+
+	if err := ValidateEscalationPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	if err := createWithZeroValues(s.db, policy, "enabled"); err != nil {
+		return nil, fmt.Errorf("failed to create escalation policy: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("policy_id", policy.ID.String()).
+		Str("created_by", createdByID.String()).
+		Str("severity", string(policy.Severity)).
+		Int("age_days", policy.AgeDays).
+		Msg("Escalation policy created")
+
+	return s.GetPolicy(policy.ID)
+}
+
+// UpdatePolicy updates an existing escalation policy. Vulnerabilities it already escalated
+// are not escalated again.
+func (s *EscalationService) UpdatePolicy(id uuid.UUID, req EscalationPolicyRequest, updatedByID uuid.UUID) (*models.EscalationPolicy, error) {
+	policy, err := s.GetPolicy(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.applyTo(policy)
+	if err := ValidateEscalationPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(policy).Select(
+		"name", "description", "enabled", "severity", "age_days",
+		"reassign_to_team_lead", "raise_priority", "notify_webhook_url",
+	).Updates(policy).Error; err != nil {
+		return nil, fmt.Errorf("failed to update escalation policy: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("policy_id", id.String()).
+		Str("updated_by", updatedByID.String()).
+		Msg("Escalation policy updated")
+
+	return s.GetPolicy(id)
+}
+
+// DeletePolicy soft deletes an escalation policy; the escalations it made stay in the history
+func (s *EscalationService) DeletePolicy(id, deletedByID uuid.UUID) error {
+	result := s.db.Delete(&models.EscalationPolicy{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete escalation policy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("escalation policy not found")
+	}
+
+	utils.Logger.Info().
+		Str("policy_id", id.String()).
+		Str("deleted_by", deletedByID.String()).
+		Msg("Escalation policy deleted")
+
+	return nil
+}
+
+// ListEscalations returns the escalation history of a vulnerability, newest first
+func (s *EscalationService) ListEscalations(vulnerabilityID uuid.UUID) ([]models.VulnerabilityEscalation, error) {
+	escalations := []models.VulnerabilityEscalation{}
+	if err := s.db.Preload("Policy", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Assignee").
+		Where("vulnerability_id = ?", vulnerabilityID).
+		Order("escalated_at DESC").
+		Find(&escalations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list escalations: %w", err)
+	}
+	return escalations, nil
+}
+
+// EscalationMatch is an open vulnerability a policy has yet to escalate
+type EscalationMatch struct {
+	VulnerabilityID uuid.UUID
+	OrgID           *uuid.UUID
+	Title           string
+	Severity        models.VulnerabilitySeverity
+	AssignedToID    *uuid.UUID
+	TeamLeadID      *uuid.UUID
+	Priority        int
+	DiscoveredAt    time.Time
+}
+
+// EscalationEvaluationResult summarizes an escalation run
+type EscalationEvaluationResult struct {
+	PoliciesEvaluated int `json:"policies_evaluated"`
+	Escalated         int `json:"escalated"`
+	Notified          int `json:"notified"`
+	NotifyFailed      int `json:"notify_failed"`
+}
+
+// Escalation is what an escalation changes on a vulnerability
+type Escalation struct {
+	AssigneeID *uuid.UUID
+	Priority   int
+}
+
+// PlanEscalation returns the assignee and priority a policy gives a vulnerability. The owner
+// team lead only takes over when the policy reassigns and the team has a lead.
+func PlanEscalation(policy *models.EscalationPolicy, match EscalationMatch) Escalation {
+	escalation := Escalation{AssigneeID: match.AssignedToID, Priority: match.Priority}
+	if policy.ReassignToTeamLead && match.TeamLeadID != nil {
+		escalation.AssigneeID = match.TeamLeadID
+	}
+	if policy.RaisePriority {
+		escalation.Priority++
+	}
+	return escalation
+}
+
+// Evaluate applies every enabled escalation policy to the open vulnerabilities older than its
+// age threshold that it has not escalated yet
+func (s *EscalationService) Evaluate(now time.Time) (*EscalationEvaluationResult, error) {
+	policies, err := s.ListPolicies(true)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EscalationEvaluationResult{}
+	for i := range policies {
+		if err := s.evaluatePolicy(&policies[i], now, result); err != nil {
+			return result, fmt.Errorf("failed to evaluate escalation policy %s: %w", policies[i].ID, err)
+		}
+		result.PoliciesEvaluated++
+	}
+	return result, nil
+}
+
+// evaluatePolicy escalates the vulnerabilities matching one policy
+func (s *EscalationService) evaluatePolicy(policy *models.EscalationPolicy, now time.Time, result *EscalationEvaluationResult) error {
+	matches, err := s.matchPolicy(policy, now)
+	if err != nil {
+		return err
+	}
+
+	for _, match := range matches {
+		escalation, err := s.escalate(policy, match, now)
+		if err != nil {
+			return err
+		}
+		if escalation == nil {
+			continue // Escalated concurrently
+		}
+		result.Escalated++
+
+		if policy.NotifyWebhookURL == "" {
+			continue
+		}
+		notifyErr := s.notifyChannel(policy, match, escalation)
+		updates := map[string]interface{}{"notified": notifyErr == nil}
+		if notifyErr != nil {
+			updates["notify_error"] = notifyErr.Error()
+			result.NotifyFailed++
+			utils.Logger.Warn().Err(notifyErr).
+				Str("policy_id", policy.ID.String()).
+				Str("vulnerability_id", match.VulnerabilityID.String()).
+				Msg("Failed to notify escalation channel")
+		} else {
+			result.Notified++
+		}
+		if err := s.db.Model(escalation).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to record escalation notification: %w", err)
+		}
+	}
+
+	return s.db.Model(policy).UpdateColumn("last_evaluated_at", now).Error
+}
+
+// matchPolicy returns the open vulnerabilities of the policy's severity older than its age
+// threshold that it has not escalated yet
+func (s *EscalationService) matchPolicy(policy *models.EscalationPolicy, now time.Time) ([]EscalationMatch, error) {
+	discoveredAt := "LEAST(vulnerabilities.discovery_date, vulnerabilities.created_at)"
+	escalated := s.db.Table("vulnerability_escalations").
+		Select("1").
+		Where("vulnerability_escalations.vulnerability_id = vulnerabilities.id AND vulnerability_escalations.policy_id = ?", policy.ID)
+
+	var matches []EscalationMatch
+	if err := s.db.Model(&models.Vulnerability{}).
+		Select("vulnerabilities.id AS vulnerability_id, vulnerabilities.org_id, vulnerabilities.title, vulnerabilities.severity, "+
+			"vulnerabilities.assigned_to_id, teams.lead_id AS team_lead_id, vulnerabilities.priority, "+discoveredAt+" AS discovered_at").
+		Joins("LEFT JOIN teams ON teams.id = vulnerabilities.owner_team_id AND teams.deleted_at IS NULL").
+		Where("vulnerabilities.severity = ? AND vulnerabilities.status IN ?", policy.Severity, openStatuses).
+		Where(discoveredAt+" < ?", now.AddDate(0, 0, -policy.AgeDays)).
+		Where("NOT EXISTS (?)", escalated).
+		Scan(&matches).Error; err != nil {
+		return nil, fmt.Errorf("failed to match vulnerabilities: %w", err)
+	}
+	return matches, nil
+}
+
+// escalate applies a policy to a vulnerability and records the escalation, notifying the
+// assignee in-app. Returns nil when the policy already escalated the vulnerability.
+func (s *EscalationService) escalate(policy *models.EscalationPolicy, match EscalationMatch, now time.Time) (*models.VulnerabilityEscalation, error) {
+	planned := PlanEscalation(policy, match)
+	escalation := &models.VulnerabilityEscalation{
+		OrgID:              match.OrgID,
+		PolicyID:           policy.ID,
+		VulnerabilityID:    match.VulnerabilityID,
+		Severity:           match.Severity,
+		AgeDays:            int(now.Sub(match.DiscoveredAt).Hours() / 24),
+		PreviousAssigneeID: match.AssignedToID,
+		AssigneeID:         planned.AssigneeID,
+		PreviousPriority:   match.Priority,
+		Priority:           planned.Priority,
+		EscalatedAt:        now,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(escalation)
+		if created.Error != nil {
+			return fmt.Errorf("failed to record escalation: %w", created.Error)
+		}
+		if created.RowsAffected == 0 {
+			escalation = nil
+			return nil
+		}
+
+		updates := map[string]interface{}{}
+		if planned.Priority != match.Priority {
+			updates["priority"] = planned.Priority
+		}
+		if !sameUser(planned.AssigneeID, match.AssignedToID) {
+			updates["assigned_to_id"] = planned.AssigneeID
+		}
+		if len(updates) > 0 {
+			vulnerability := &models.Vulnerability{BaseModel: models.BaseModel{ID: match.VulnerabilityID}}
+			if err := tx.Model(vulnerability).Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to escalate vulnerability: %w", err)
+			}
+		}
+
+		if planned.AssigneeID == nil {
+			return nil
+		}
+		vulnerabilityID := match.VulnerabilityID
+		return NewNotificationService(tx).CreateNotifications(tx, []models.Notification{{
+			UserID:       *planned.AssigneeID,
+			Type:         models.NotificationTypeVulnerabilityEscalated,
+			Title:        fmt.Sprintf("Vulnerability escalated: %s", match.Title),
+			Message:      escalationMessage(policy, match, escalation),
+			ResourceType: "vulnerability",
+			ResourceID:   &vulnerabilityID,
+		}})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return escalation, nil
+}
+
+// sameUser reports whether two optional user IDs are the same user or both unset
+func sameUser(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// escalationMessage describes an escalation for notifications
+func escalationMessage(policy *models.EscalationPolicy, match EscalationMatch, escalation *models.VulnerabilityEscalation) string {
+	return fmt.Sprintf("%s vulnerability \"%s\" has been open for %d days, exceeding the %d days of escalation policy \"%s\"",
+		match.Severity, match.Title, escalation.AgeDays, policy.AgeDays, policy.Name)
+}
+
+// notifyChannel posts an escalation to the policy's webhook. The text field is what Slack and
+// Teams incoming webhooks display; the other fields are for programmatic receivers.
+func (s *EscalationService) notifyChannel(policy *models.EscalationPolicy, match EscalationMatch, escalation *models.VulnerabilityEscalation) error {
+	payload := map[string]interface{}{
+		"text":             escalationMessage(policy, match, escalation),
+		"event":            "vulnerability.escalated",
+		"policy_id":        policy.ID,
+		"policy_name":      policy.Name,
+		"vulnerability_id": match.VulnerabilityID,
+		"title":            match.Title,
+		"severity":         match.Severity,
+		"age_days":         escalation.AgeDays,
+		"assignee_id":      escalation.AssigneeID,
+		"priority":         escalation.Priority,
+		"escalated_at":     escalation.EscalatedAt,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(s.db.Statement.Context, http.MethodPost, policy.NotifyWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("channel responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/exploitfeed"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// ExploitFeedSyncResult summarizes an exploit feed sync
+type ExploitFeedSyncResult struct {
+	Exploits map[string]int `json:"exploits"` // Exploits referencing a CVE, by source
+	Flagged  int64          `json:"flagged"`  // Vulnerabilities newly flagged as exploitable
+}
+
+// ExploitFeedService syncs the public exploit catalogs of Exploit-DB and Metasploit and flags
+// the vulnerabilities of the CVEs they exploit
+type ExploitFeedService struct {
+	db     *gorm.DB
+	feeds  map[string]string // Feed URL by source; sources without a URL are not synced
+	client *exploitfeed.Client
+}
+
+// NewExploitFeedService creates an exploit feed service syncing the feeds at the given URLs.
+// An empty URL disables that feed.
+func NewExploitFeedService(db *gorm.DB, exploitDBURL, metasploitURL string) *ExploitFeedService {
+	feeds := map[string]string{}
+	if exploitDBURL != "" {
+		feeds[exploitfeed.SourceExploitDB] = exploitDBURL
+	}
+	if metasploitURL != "" {
+		feeds[exploitfeed.SourceMetasploit] = metasploitURL
+	}
+	return &ExploitFeedService{db: db, feeds: feeds, client: exploitfeed.NewClient()}
+}
+
+// Enabled reports whether any feed is configured
+func (s *ExploitFeedService) Enabled() bool {
+	return len(s.feeds) > 0
+}
+
+// Sync replaces the known exploits of each configured feed with its current contents, then flags
+// the vulnerabilities of the exploited CVEs. A feed that fails to download keeps its previous
+// exploits and does not stop the others.
+func (s *ExploitFeedService) Sync(ctx context.Context) (*ExploitFeedSyncResult, error) {
+	result := &ExploitFeedSyncResult{Exploits: map[string]int{}}
+	var errs []error
+	for _, source := range []string{exploitfeed.SourceExploitDB, exploitfeed.SourceMetasploit} {
+		url, ok := s.feeds[source]
+		if !ok {
+			continue
+		}
+		exploits, err := s.client.Fetch(ctx, source, url)
+		if err == nil {
+			err = s.replaceExploits(ctx, source, exploits)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to sync %s exploits: %w", source, err))
+			continue
+		}
+		result.Exploits[source] = len(exploits)
+	}
+
+	flagged, err := flagKnownExploits(s.db.WithContext(ctx))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	result.Flagged = flagged
+	return result, errors.Join(errs...)
+}
+
+// replaceExploits swaps the known exploits of source for exploits
+func (s *ExploitFeedService) replaceExploits(ctx context.Context, source string, exploits []exploitfeed.Exploit) error {
+	now := time.Now()
+	var rows []models.KnownExploit
+	for _, exploit := range exploits {
+		for _, cve := range exploit.CVEs {
+			rows = append(rows, models.KnownExploit{
+				Source:      source,
+				ExternalID:  truncate(exploit.ID, 255),
+				CVEID:       cve,
+				Title:       exploit.Title,
+				URL:         exploit.URL,
+				Verified:    exploit.Verified,
+				PublishedAt: exploit.PublishedAt,
+				SyncedAt:    now,
+			})
+		}
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("source = ?", source).Delete(&models.KnownExploit{}).Error; err != nil {
+			return fmt.Errorf("failed to clear known exploits: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(&rows, 1000).Error; err != nil {
+			return fmt.Errorf("failed to store known exploits: %w", err)
+		}
+		utils.Logger.Info().Str("source", source).Int("exploits", len(exploits)).Int("cves", len(rows)).Msg("Synced known exploits")
+		return nil
+	})
+}
+
+// flagKnownExploits flags the vulnerabilities of CVEs with a known exploit. Flags are never
+// cleared, so an exploit the scanner reported stays recorded.
+func flagKnownExploits(db *gorm.DB) (int64, error) {
+	result := db.Model(&models.Vulnerability{}).
+		Where("has_exploit = ? AND cve_id IN (?)", false, db.Model(&models.KnownExploit{}).Select("cve_id")).
+		Update("has_exploit", true)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to flag exploitable vulnerabilities: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// knownExploitCVEs returns which of cves have a known exploit
+func knownExploitCVEs(db *gorm.DB, cves []string) (map[string]bool, error) {
+	exploited := map[string]bool{}
+	var ids []string
+	for _, cve := range cves {
+		if cve != "" {
+			ids = append(ids, cve)
+		}
+	}
+	if len(ids) == 0 {
+		return exploited, nil
+	}
+	var found []string
+	if err := db.Model(&models.KnownExploit{}).Distinct("cve_id").Where("cve_id IN ?", ids).Pluck("cve_id", &found).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up known exploits: %w", err)
+	}
+	for _, cve := range found {
+		exploited[cve] = true
+	}
+	return exploited, nil
+}
@@ -1,10 +1,10 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"os"
 	"path/filepath"
 	"time"
 
@@ -18,24 +18,29 @@ import (
 type FindingAttachmentService struct {
 	db             *gorm.DB
 	imageProcessor *imageutil.ImageProcessor
-	uploadDir      string
-	maxFileSize    int64 // in bytes
+	area           string // Object key prefix in the attachment store
 }
 
 func NewFindingAttachmentService(db *gorm.DB) *FindingAttachmentService {
-	// Create uploads directory if it doesn't exist
-	uploadDir := "./uploads/finding-attachments"
-	os.MkdirAll(uploadDir, 0755)
-	os.MkdirAll(filepath.Join(uploadDir, "thumbnails"), 0755)
-
 	return &FindingAttachmentService{
 		db:             db,
 		imageProcessor: imageutil.NewImageProcessor(),
-		uploadDir:      uploadDir,
-		maxFileSize:    10 * 1024 * 1024, // 10MB default
+		area:           storageAreaFindingAttachments,
 	}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *FindingAttachmentService) WithContext(ctx context.Context) *FindingAttachmentService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// visibleFindings limits an attachment query to findings visible in the service's context
+func (s *FindingAttachmentService) visibleFindings(query *gorm.DB) *gorm.DB {
+	return query.Where("finding_id IN (?)", s.db.Model(&models.VulnerabilityFinding{}).Select("id"))
+}
+
 // UploadAttachment uploads and processes a file attachment for a finding
 func (s *FindingAttachmentService) UploadAttachment(
 	findingID uuid.UUID,
@@ -49,9 +54,10 @@ func (s *FindingAttachmentService) UploadAttachment(
 		return nil, fmt.Errorf("finding not found: %w", err)
 	}
 
-	// Validate file size
-	if file.Size > s.maxFileSize {
-		return nil, fmt.Errorf("file size exceeds maximum allowed size of %d MB", s.maxFileSize/1024/1024)
+	// Reject oversized files before reading them
+	policy := LoadAttachmentPolicy(s.db)
+	if err := policy.CheckSize(attachmentType, file.Size); err != nil {
+		return nil, err
 	}
 
 	// Open uploaded file
@@ -67,25 +73,24 @@ func (s *FindingAttachmentService) UploadAttachment(
 		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
 	}
 
-	// Detect MIME type
-	mimeType := file.Header.Get("Content-Type")
+	// Detect MIME type from the content and enforce the policy on it
+	mimeType := DetectAttachmentMimeType(fileData, file.Header.Get("Content-Type"))
+	if err := policy.CheckAttachment(attachmentType, mimeType, int64(len(fileData))); err != nil {
+		return nil, err
+	}
 	isImage := imageutil.IsImage(mimeType)
 
 	// Generate unique filename
 	ext := filepath.Ext(file.Filename)
 	uniqueName := fmt.Sprintf("%s_%d%s", uuid.New().String(), time.Now().Unix(), ext)
 	storagePath := filepath.Join(findingID.String(), uniqueName)
-	fullPath := filepath.Join(s.uploadDir, storagePath)
-
-	// Create directory for this finding
-	findingDir := filepath.Join(s.uploadDir, findingID.String())
-	if err := os.MkdirAll(findingDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create upload directory: %w", err)
-	}
+	store := ActiveAttachmentStorage().Store
+	ctx := context.Background()
 
 	var width, height int
 	var normalized bool
 	var thumbnailPath string
+	storedData := fileData // Bytes actually written, hashed for the chain of custody
 
 	// Process image if it's an image file
 	if isImage {
@@ -93,14 +98,15 @@ func (s *FindingAttachmentService) UploadAttachment(
 		if err != nil {
 			utils.Logger.Warn().Err(err).Msg("Failed to process image, saving original")
 			// Save original if processing fails
-			if err := os.WriteFile(fullPath, fileData, 0644); err != nil {
+			if err := store.Put(ctx, s.objectKey(storagePath), fileData, mimeType); err != nil {
 				return nil, fmt.Errorf("failed to save file: %w", err)
 			}
 		} else {
 			// Save processed (normalized) image
-			if err := os.WriteFile(fullPath, processed.Data, 0644); err != nil {
+			if err := store.Put(ctx, s.objectKey(storagePath), processed.Data, "image/jpeg"); err != nil {
 				return nil, fmt.Errorf("failed to save processed image: %w", err)
 			}
+			storedData = processed.Data
 
 			width = processed.Width
 			height = processed.Height
@@ -109,17 +115,9 @@ func (s *FindingAttachmentService) UploadAttachment(
 			// Save thumbnail
 			thumbnailName := fmt.Sprintf("thumb_%s", uniqueName)
 			thumbnailPath = filepath.Join("thumbnails", findingID.String(), thumbnailName)
-			thumbnailFullPath := filepath.Join(s.uploadDir, thumbnailPath)
-
-			// Create thumbnail directory
-			thumbnailDir := filepath.Join(s.uploadDir, "thumbnails", findingID.String())
-			if err := os.MkdirAll(thumbnailDir, 0755); err != nil {
-				utils.Logger.Warn().Err(err).Msg("Failed to create thumbnail directory")
-			} else {
-				if err := os.WriteFile(thumbnailFullPath, processed.Thumbnail, 0644); err != nil {
-					utils.Logger.Warn().Err(err).Msg("Failed to save thumbnail")
-					thumbnailPath = ""
-				}
+			if err := store.Put(ctx, s.objectKey(thumbnailPath), processed.Thumbnail, "image/jpeg"); err != nil {
+				utils.Logger.Warn().Err(err).Msg("Failed to save thumbnail")
+				thumbnailPath = ""
 			}
 
 			utils.Logger.Info().
@@ -132,7 +130,7 @@ func (s *FindingAttachmentService) UploadAttachment(
 		}
 	} else {
 		// Save non-image file as-is
-		if err := os.WriteFile(fullPath, fileData, 0644); err != nil {
+		if err := store.Put(ctx, s.objectKey(storagePath), fileData, mimeType); err != nil {
 			return nil, fmt.Errorf("failed to save file: %w", err)
 		}
 	}
@@ -145,6 +143,9 @@ func (s *FindingAttachmentService) UploadAttachment(
 		MimeType:       mimeType,
 		FileSize:       file.Size,
 		StoragePath:    storagePath,
+		StorageBackend: store.Driver(),
+		SHA256:         sha256Hex(storedData),
+		OriginalSHA256: sha256Hex(fileData),
 		IsImage:        isImage,
 		Width:          width,
 		Height:         height,
@@ -155,11 +156,17 @@ func (s *FindingAttachmentService) UploadAttachment(
 		UploadedBy:     uploadedBy,
 	}
 
-	if err := s.db.Create(attachment).Error; err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attachment).Error; err != nil {
+			return err
+		}
+		return recordCustodyEvent(tx, attachment, models.AttachmentCustodyUploaded, attachment.SHA256, "", &uploadedBy)
+	})
+	if err != nil {
 		// Clean up uploaded files on database error
-		os.Remove(fullPath)
+		store.Delete(ctx, s.objectKey(storagePath))
 		if thumbnailPath != "" {
-			os.Remove(filepath.Join(s.uploadDir, thumbnailPath))
+			store.Delete(ctx, s.objectKey(thumbnailPath))
 		}
 		return nil, fmt.Errorf("failed to save attachment record: %w", err)
 	}
@@ -178,7 +185,7 @@ func (s *FindingAttachmentService) UploadAttachment(
 // GetAttachment retrieves an attachment by ID
 func (s *FindingAttachmentService) GetAttachment(id uuid.UUID) (*models.FindingAttachment, error) {
 	var attachment models.FindingAttachment
-	if err := s.db.Preload("UploadedByUser").First(&attachment, "id = ?", id).Error; err != nil {
+	if err := s.visibleFindings(s.db.Preload("UploadedByUser")).First(&attachment, "id = ?", id).Error; err != nil {
 		return nil, fmt.Errorf("attachment not found: %w", err)
 	}
 	return &attachment, nil
@@ -187,8 +194,7 @@ func (s *FindingAttachmentService) GetAttachment(id uuid.UUID) (*models.FindingA
 // GetFindingAttachments retrieves all attachments for a finding
 func (s *FindingAttachmentService) GetFindingAttachments(findingID uuid.UUID) ([]models.FindingAttachment, error) {
 	var attachments []models.FindingAttachment
-	err := s.db.
-		Preload("UploadedByUser").
+	err := s.visibleFindings(s.db.Preload("UploadedByUser")).
 		Where("finding_id = ?", findingID).
 		Order("created_at DESC").
 		Find(&attachments).Error
@@ -198,34 +204,42 @@ func (s *FindingAttachmentService) GetFindingAttachments(findingID uuid.UUID) ([
 
 // GetAttachmentFile retrieves the file data for an attachment
 func (s *FindingAttachmentService) GetAttachmentFile(attachment *models.FindingAttachment, thumbnail bool) ([]byte, error) {
-	var filePath string
+	storagePath := attachment.StoragePath
 	if thumbnail && attachment.ThumbnailPath != "" {
-		filePath = filepath.Join(s.uploadDir, attachment.ThumbnailPath)
-	} else {
-		filePath = filepath.Join(s.uploadDir, attachment.StoragePath)
+		storagePath = attachment.ThumbnailPath
 	}
+	return readStoredFile(attachment.StorageBackend, s.objectKey(storagePath))
+}
 
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
-	}
+// GetDownloadURL returns a presigned download URL for an attachment and when it expires
+func (s *FindingAttachmentService) GetDownloadURL(attachment *models.FindingAttachment) (string, time.Time, error) {
+	return presignStoredFile(attachment.StorageBackend, s.objectKey(attachment.StoragePath), attachment.OriginalName)
+}
 
-	return data, nil
+// objectKey returns the attachment store key of a path relative to this service's area
+func (s *FindingAttachmentService) objectKey(storagePath string) string {
+	return attachmentObjectKey(s.area, storagePath)
 }
 
-// DeleteAttachment deletes an attachment (soft delete)
-func (s *FindingAttachmentService) DeleteAttachment(id uuid.UUID) error {
+// DeleteAttachment deletes an attachment (soft delete), closing its chain of custody
+func (s *FindingAttachmentService) DeleteAttachment(id, deletedBy uuid.UUID) error {
 	var attachment models.FindingAttachment
-	if err := s.db.First(&attachment, "id = ?", id).Error; err != nil {
+	if err := s.visibleFindings(s.db).First(&attachment, "id = ?", id).Error; err != nil {
 		return fmt.Errorf("attachment not found: %w", err)
 	}
 
 	// Soft delete
-	if err := s.db.Delete(&attachment).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&attachment).Error; err != nil {
+			return err
+		}
+		return recordCustodyEvent(tx, &attachment, models.AttachmentCustodyDeleted, attachment.SHA256, "", &deletedBy)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete attachment: %w", err)
 	}
 
-	// Note: We keep the files on disk even after soft delete for audit purposes
+	// Note: We keep the stored files even after soft delete for audit purposes
 	// Hard delete cleanup should be done by a separate cleanup job
 
 	utils.Logger.Info().
@@ -238,7 +252,7 @@ func (s *FindingAttachmentService) DeleteAttachment(id uuid.UUID) error {
 
 // GetAttachmentStats returns statistics about attachments
 func (s *FindingAttachmentService) GetAttachmentStats(findingID *uuid.UUID) (map[string]interface{}, error) {
-	query := s.db.Model(&models.FindingAttachment{})
+	query := s.visibleFindings(s.db.Model(&models.FindingAttachment{}))
 	if findingID != nil {
 		query = query.Where("finding_id = ?", *findingID)
 	}
@@ -256,15 +270,15 @@ func (s *FindingAttachmentService) GetAttachmentStats(findingID *uuid.UUID) (map
 	query.Select("COALESCE(SUM(file_size), 0) as total_size").Scan(&stats.TotalSize)
 
 	// Count by type
-	s.db.Model(&models.FindingAttachment{}).
+	s.visibleFindings(s.db.Model(&models.FindingAttachment{})).
 		Where("finding_id = ? AND is_image = ?", findingID, true).
 		Count(&stats.ImageCount)
 
-	s.db.Model(&models.FindingAttachment{}).
+	s.visibleFindings(s.db.Model(&models.FindingAttachment{})).
 		Where("finding_id = ? AND attachment_type = ?", findingID, models.AttachmentTypeProof).
 		Count(&stats.ProofCount)
 
-	s.db.Model(&models.FindingAttachment{}).
+	s.visibleFindings(s.db.Model(&models.FindingAttachment{})).
 		Where("finding_id = ? AND attachment_type = ?", findingID, models.AttachmentTypeVerification).
 		Count(&stats.VerifiedCount)
 
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxAutoCloseCleanScans bounds the clean scans a finding can be required to stay missing for
+const maxAutoCloseCleanScans = 100
+
+// FindingAutoCloseSettings configures closing findings that repeated scans of their asset no
+// longer report. It is stored as JSON in the finding_auto_close system setting.
+type FindingAutoCloseSettings struct {
+	Enabled    bool `json:"enabled"`
+	CleanScans int  `json:"clean_scans"` // Consecutive scans of the asset without the finding before it is closed
+}
+
+// ParseFindingAutoCloseSettings parses and validates a finding_auto_close setting value
+func ParseFindingAutoCloseSettings(value string) (*FindingAutoCloseSettings, error) {
+	var settings FindingAutoCloseSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return nil, fmt.Errorf("invalid finding auto-close settings: %v", err)
+	}
+	if settings.CleanScans < 1 || settings.CleanScans > maxAutoCloseCleanScans {
+		return nil, fmt.Errorf("invalid finding auto-close settings: clean_scans must be between 1 and %d", maxAutoCloseCleanScans)
+	}
+	return &settings, nil
+}
+
+// LoadFindingAutoCloseSettings returns the configured auto-close rule; without the setting
+// findings are never closed for missing from scans
+func LoadFindingAutoCloseSettings(db *gorm.DB) (*FindingAutoCloseSettings, error) {
+	var setting models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingFindingAutoClose)).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &FindingAutoCloseSettings{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load finding auto-close settings: %w", err)
+	}
+	return ParseFindingAutoCloseSettings(setting.Value)
+}
+
+// FindingAutoCloseService closes open findings that enough consecutive scans of their asset
+// did not report. Imports count the clean scans of each finding and close the findings that
+// reach the threshold; the nightly evaluation closes those left open, such as after the
+// threshold was lowered.
+type FindingAutoCloseService struct {
+	db *gorm.DB
+}
+
+// NewFindingAutoCloseService creates a new finding auto-close service
+func NewFindingAutoCloseService(db *gorm.DB) *FindingAutoCloseService {
+	return &FindingAutoCloseService{db: db}
+}
+
+// Evaluate closes the open findings whose clean scans reached the configured threshold and
+// returns how many it closed
+func (s *FindingAutoCloseService) Evaluate(now time.Time) (int, error) {
+	settings, err := LoadFindingAutoCloseSettings(s.db)
+	if err != nil {
+		return 0, err
+	}
+	if !settings.Enabled {
+		return 0, nil
+	}
+
+	closed := 0
+	for {
+		var findings []models.VulnerabilityFinding
+		if err := s.db.Select("id", "created_by").
+			Where("status = ? AND clean_scans >= ?", models.FindingStatusOpen, settings.CleanScans).
+			Limit(importBatchSize).
+			Find(&findings).Error; err != nil {
+			return closed, fmt.Errorf("failed to find findings to auto-close: %w", err)
+		}
+		if len(findings) == 0 {
+			return closed, nil
+		}
+
+		// The status history requires an actor; attribute the change to whoever imported the finding
+		byCreator := make(map[uuid.UUID][]uuid.UUID)
+		for _, finding := range findings {
+			byCreator[finding.CreatedBy] = append(byCreator[finding.CreatedBy], finding.ID)
+		}
+		for createdBy, ids := range byCreator {
+			err := s.db.Transaction(func(tx *gorm.DB) error {
+				return closeMissingFindings(tx, ids, cleanScansNote(settings.CleanScans), createdBy, now)
+			})
+			if err != nil {
+				return closed, fmt.Errorf("failed to auto-close findings: %w", err)
+			}
+			closed += len(ids)
+		}
+	}
+}
+
+// cleanScansNote is the note recorded on findings closed after clean scans
+func cleanScansNote(cleanScans int) string {
+	return fmt.Sprintf("Closed automatically: not detected in %d consecutive scans of the asset", cleanScans)
+}
+
+// closeMissingFindings marks open findings scans no longer report as fixed, recording the
+// note on the finding and in its status history
+func closeMissingFindings(tx *gorm.DB, ids []uuid.UUID, notes string, changedByID uuid.UUID, now time.Time) error {
+	if err := tx.Model(&models.VulnerabilityFinding{}).
+		Where("id IN ? AND status = ?", ids, models.FindingStatusOpen).
+		Updates(map[string]interface{}{
+			"status":    models.FindingStatusFixed,
+			"fixed_at":  now,
+			"fix_notes": notes,
+		}).Error; err != nil {
+		return err
+	}
+	histories := make([]*models.FindingStatusHistory, len(ids))
+	for i, id := range ids {
+		histories[i] = &models.FindingStatusHistory{
+			FindingID:   id,
+			OldStatus:   models.FindingStatusOpen,
+			NewStatus:   models.FindingStatusFixed,
+			Notes:       notes,
+			ChangedByID: changedByID,
+			ChangedAt:   now,
+		}
+	}
+	return tx.CreateInBatches(histories, importBatchSize).Error
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// FindingCommentService handles finding comments and @mention notifications
+type FindingCommentService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+// NewFindingCommentService creates a new finding comment service
+func NewFindingCommentService(db *gorm.DB) *FindingCommentService {
+	return &FindingCommentService{
+		db:                  db,
+		notificationService: NewNotificationService(db),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *FindingCommentService) WithContext(ctx context.Context) *FindingCommentService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// mentionPattern matches @handle or @user@example.com, not preceded by a word character
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9._%+-])@([A-Za-z0-9._+-]+(?:@[A-Za-z0-9.-]+\.[A-Za-z]{2,})?)`)
+
+// ExtractMentions returns the unique, lowercased handles mentioned in a comment body.
+// A handle is either a full email address or the local part of one.
+func ExtractMentions(body string) []string {
+	seen := map[string]bool{}
+	mentions := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		handle := strings.ToLower(strings.TrimRight(match[1], "."))
+		if handle == "" || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		mentions = append(mentions, handle)
+	}
+	return mentions
+}
+
+// resolveMentions maps mention handles to users. Handles without a domain must match
+// exactly one user's email local part; ambiguous or unknown handles are ignored.
+func (s *FindingCommentService) resolveMentions(tx *gorm.DB, body string) ([]models.User, error) {
+	handles := ExtractMentions(body)
+	if len(handles) == 0 {
+		return []models.User{}, nil
+	}
+
+	emails := []string{}
+	localParts := []string{}
+	for _, handle := range handles {
+		if strings.Contains(handle, "@") {
+			emails = append(emails, handle)
+		} else {
+			localParts = append(localParts, handle)
+		}
+	}
+
+	users := []models.User{}
+	seen := map[uuid.UUID]bool{}
+
+	if len(emails) > 0 {
+		var matched []models.User
+		if err := tx.Where("LOWER(email) IN ?", emails).Find(&matched).Error; err != nil {
+			return nil, fmt.Errorf("failed to resolve mentions: %w", err)
+		}
+		for _, user := range matched {
+			seen[user.ID] = true
+			users = append(users, user)
+		}
+	}
+
+	if len(localParts) > 0 {
+		var matched []models.User
+		if err := tx.Where("LOWER(split_part(email, '@', 1)) IN ?", localParts).Find(&matched).Error; err != nil {
+			return nil, fmt.Errorf("failed to resolve mentions: %w", err)
+		}
+		byLocalPart := map[string][]models.User{}
+		for _, user := range matched {
+			localPart := strings.ToLower(strings.SplitN(user.Email, "@", 2)[0])
+			byLocalPart[localPart] = append(byLocalPart[localPart], user)
+		}
+		for _, handle := range localParts {
+			candidates := byLocalPart[handle]
+			if len(candidates) != 1 || seen[candidates[0].ID] {
+				continue
+			}
+			seen[candidates[0].ID] = true
+			users = append(users, candidates[0])
+		}
+	}
+
+	return users, nil
+}
+
+// notifyMentions creates mention notifications for users other than the author
+func (s *FindingCommentService) notifyMentions(tx *gorm.DB, comment *models.FindingComment, users []models.User) error {
+	author := comment.AuthorID
+	findingID := comment.FindingID
+	notifications := []models.Notification{}
+	for _, user := range users {
+		if user.ID == comment.AuthorID {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			UserID:       user.ID,
+			Type:         models.NotificationTypeMention,
+			Title:        "You were mentioned in a finding comment",
+			Message:      comment.Body,
+			ResourceType: "finding",
+			ResourceID:   &findingID,
+			ActorID:      &author,
+		})
+	}
+	return s.notificationService.CreateNotifications(tx, notifications)
+}
+
+// ensureFindingExists returns a not found error if the finding does not exist
+func (s *FindingCommentService) ensureFindingExists(findingID uuid.UUID) error {
+	var count int64
+	if err := s.db.Model(&models.VulnerabilityFinding{}).Where("id = ?", findingID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to get finding: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("finding not found")
+	}
+	return nil
+}
+
+// loadComment reloads a comment with its author and mentioned users
+func (s *FindingCommentService) loadComment(commentID uuid.UUID) (*models.FindingComment, error) {
+	var comment models.FindingComment
+	if err := s.db.Preload("Author").Preload("MentionedUsers").First(&comment, commentID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load comment: %w", err)
+	}
+	return &comment, nil
+}
+
+// ListComments returns all comments for a finding, oldest first
+func (s *FindingCommentService) ListComments(findingID uuid.UUID) ([]models.FindingComment, error) {
+	if err := s.ensureFindingExists(findingID); err != nil {
+		return nil, err
+	}
+
+	var comments []models.FindingComment
+	if err := s.db.
+		Preload("Author").
+		Preload("MentionedUsers").
+		Where("finding_id = ?", findingID).
+		Order("created_at ASC").
+		Find(&comments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+// CreateComment adds a comment to a finding and notifies mentioned users
+func (s *FindingCommentService) CreateComment(findingID, authorID uuid.UUID, body string) (*models.FindingComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("comment body is required")
+	}
+
+	if err := s.ensureFindingExists(findingID); err != nil {
+		return nil, err
+	}
+
+	comment := &models.FindingComment{
+		FindingID: findingID,
+		AuthorID:  authorID,
+		Body:      body,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		mentioned, err := s.resolveMentions(tx, body)
+		if err != nil {
+			return err
+		}
+
+		comment.MentionedUsers = mentioned
+		if err := tx.Omit("MentionedUsers.*").Create(comment).Error; err != nil {
+			return fmt.Errorf("failed to create comment: %w", err)
+		}
+
+		return s.notifyMentions(tx, comment, mentioned)
+	})
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to create finding comment")
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("finding_id", findingID.String()).
+		Str("comment_id", comment.ID.String()).
+		Int("mentions", len(comment.MentionedUsers)).
+		Msg("Finding comment created")
+
+	return s.loadComment(comment.ID)
+}
+
+// getOwnedComment loads a comment and verifies that it belongs to the finding and author
+func (s *FindingCommentService) getOwnedComment(findingID, commentID, userID uuid.UUID) (*models.FindingComment, error) {
+	if err := s.ensureFindingExists(findingID); err != nil {
+		return nil, err
+	}
+
+	var comment models.FindingComment
+	if err := s.db.Preload("MentionedUsers").Where("id = ? AND finding_id = ?", commentID, findingID).First(&comment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("comment not found")
+		}
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	if comment.AuthorID != userID {
+		return nil, fmt.Errorf("only the author can modify this comment")
+	}
+
+	return &comment, nil
+}
+
+// UpdateComment edits a comment's body (author only), notifying only newly mentioned users
+func (s *FindingCommentService) UpdateComment(findingID, commentID, userID uuid.UUID, body string) (*models.FindingComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("comment body is required")
+	}
+
+	comment, err := s.getOwnedComment(findingID, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	previouslyMentioned := map[uuid.UUID]bool{}
+	for _, user := range comment.MentionedUsers {
+		previouslyMentioned[user.ID] = true
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		mentioned, err := s.resolveMentions(tx, body)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(comment).Updates(map[string]interface{}{
+			"body":      body,
+			"edited_at": time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update comment: %w", err)
+		}
+
+		if err := tx.Model(comment).Omit("MentionedUsers.*").Association("MentionedUsers").Replace(mentioned); err != nil {
+			return fmt.Errorf("failed to update mentions: %w", err)
+		}
+
+		newlyMentioned := []models.User{}
+		for _, user := range mentioned {
+			if !previouslyMentioned[user.ID] {
+				newlyMentioned = append(newlyMentioned, user)
+			}
+		}
+		comment.Body = body
+		return s.notifyMentions(tx, comment, newlyMentioned)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loadComment(comment.ID)
+}
+
+// DeleteComment soft deletes a comment (author only)
+func (s *FindingCommentService) DeleteComment(findingID, commentID, userID uuid.UUID) error {
+	comment, err := s.getOwnedComment(findingID, commentID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(comment).Error; err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("finding_id", findingID.String()).
+		Str("comment_id", commentID.String()).
+		Msg("Finding comment deleted")
+
+	return nil
+}
@@ -0,0 +1,18 @@
+package services
+
+import (
+	"gorm.io/gorm"
+)
+
+// createWithZeroValues inserts record and writes columns even when they hold zero values.
+// GORM leaves zero-valued fields out of inserts, so a false flag or an empty field whose
+// column has a default would otherwise be stored as the default. Both statements run in
+// one transaction so the default is never visible to other queries.
+func createWithZeroValues(db *gorm.DB, record interface{}, columns ...string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		return tx.Model(record).Select(columns).Updates(record).Error
+	})
+}
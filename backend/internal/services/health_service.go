@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/cache"
+	"github.com/cyops/cyops-backend/pkg/shutdown"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Dependency check statuses
+const (
+	DependencyUp       = "up"
+	DependencyDegraded = "degraded"
+	DependencyDown     = "down"
+	DependencyDisabled = "disabled"
+	DependencyUnknown  = "unknown"
+)
+
+// Readiness statuses
+const (
+	ReadinessReady    = "ready"
+	ReadinessDegraded = "degraded"
+	ReadinessNotReady = "not ready"
+)
+
+const (
+	// AttachmentStoreDir is the root directory for uploaded files
+	AttachmentStoreDir = "./uploads"
+
+	// Disk thresholds for the attachment store
+	diskCriticalFreeBytes = 100 * 1024 * 1024
+	diskWarningFreeBytes  = 1024 * 1024 * 1024
+
+	// dependencyTimeout bounds each individual check
+	dependencyTimeout = 3 * time.Second
+
+	// slowCheckTTL is how long schema and integration results are reused between probes
+	slowCheckTTL = 60 * time.Second
+)
+
+// DependencyCheck is the result of checking one dependency
+type DependencyCheck struct {
+	Status    string                 `json:"status"`
+	Critical  bool                   `json:"critical"` // A down critical dependency makes the service not ready
+	LatencyMS float64                `json:"latency_ms"`
+	Message   string                 `json:"message,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
+
+// ReadinessReport aggregates dependency checks
+type ReadinessReport struct {
+	Status    string                      `json:"status"`
+	Timestamp time.Time                   `json:"timestamp"`
+	Checks    map[string]*DependencyCheck `json:"checks"`
+}
+
+// HealthService checks the dependencies the API needs to serve traffic
+type HealthService struct {
+	db *gorm.DB
+
+	cacheMu sync.Mutex
+	cached  map[string]*DependencyCheck
+}
+
+// NewHealthService creates a new health service
+func NewHealthService(db *gorm.DB) *HealthService {
+	return &HealthService{
+		db:     db,
+		cached: make(map[string]*DependencyCheck),
+	}
+}
+
+// CheckReadiness runs all dependency checks concurrently. Critical dependencies (database,
+// schema, attachment storage) being down makes the service not ready; optional ones
+// (Redis, search, integrations) only degrade it.
+func (s *HealthService) CheckReadiness(ctx context.Context) *ReadinessReport {
+	checks := map[string]func(context.Context) *DependencyCheck{
+		"database":     s.checkDatabase,
+		"migrations":   s.cachedCheck("migrations", s.checkMigrations),
+		"disk":         s.checkDisk,
+		"redis":        s.checkRedis,
+		"search":       s.checkSearch,
+		"integrations": s.cachedCheck("integrations", s.checkIntegrations),
+	}
+
+	report := &ReadinessReport{
+		Status:    ReadinessReady,
+		Timestamp: time.Now(),
+		Checks:    make(map[string]*DependencyCheck, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) *DependencyCheck) {
+			defer wg.Done()
+			result := check(ctx)
+			mu.Lock()
+			report.Checks[name] = result
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	for _, check := range report.Checks {
+		switch {
+		case check.Status == DependencyDown && check.Critical:
+			report.Status = ReadinessNotReady
+		case (check.Status == DependencyDown || check.Status == DependencyDegraded) && report.Status == ReadinessReady:
+			report.Status = ReadinessDegraded
+		}
+	}
+
+	// A draining instance takes no new work, so load balancers stop routing to it
+	if shutdown.Draining() {
+		report.Status = ReadinessNotReady
+		report.Checks["shutdown"] = &DependencyCheck{
+			Status:    DependencyDown,
+			Critical:  true,
+			Message:   "draining in-flight work before stopping",
+			CheckedAt: time.Now(),
+		}
+	}
+
+	return report
+}
+
+// timed runs fn and records its latency on the returned check
+func timed(critical bool, fn func() *DependencyCheck) *DependencyCheck {
+	start := time.Now()
+	check := fn()
+	check.Critical = critical
+	check.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	check.CheckedAt = time.Now()
+	return check
+}
+
+// cachedCheck reuses a check result for slowCheckTTL so frequent probes stay cheap
+func (s *HealthService) cachedCheck(name string, check func(context.Context) *DependencyCheck) func(context.Context) *DependencyCheck {
+	return func(ctx context.Context) *DependencyCheck {
+		s.cacheMu.Lock()
+		if previous, ok := s.cached[name]; ok && time.Since(previous.CheckedAt) < slowCheckTTL {
+			s.cacheMu.Unlock()
+			return previous
+		}
+		s.cacheMu.Unlock()
+
+		result := check(ctx)
+
+		s.cacheMu.Lock()
+		s.cached[name] = result
+		s.cacheMu.Unlock()
+		return result
+	}
+}
+
+// checkDatabase pings the database and reports pool usage
+func (s *HealthService) checkDatabase(ctx context.Context) *DependencyCheck {
+	return timed(true, func() *DependencyCheck {
+		if s.db == nil {
+			return &DependencyCheck{Status: DependencyDown, Message: "database not initialized"}
+		}
+		sqlDB, err := s.db.DB()
+		if err != nil {
+			return &DependencyCheck{Status: DependencyDown, Message: err.Error()}
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, dependencyTimeout)
+		defer cancel()
+		if err := sqlDB.PingContext(pingCtx); err != nil {
+			return &DependencyCheck{Status: DependencyDown, Message: "ping failed: " + err.Error()}
+		}
+
+		stats := sqlDB.Stats()
+		return &DependencyCheck{
+			Status: DependencyUp,
+			Details: map[string]interface{}{
+				"open_connections": stats.OpenConnections,
+				"in_use":           stats.InUse,
+				"idle":             stats.Idle,
+				"max_open":         stats.MaxOpenConnections,
+				"wait_count":       stats.WaitCount,
+				"wait_duration_ms": stats.WaitDuration.Milliseconds(),
+			},
+		}
+	})
+}
+
+// checkMigrations verifies every registered model's table and columns exist
+func (s *HealthService) checkMigrations(ctx context.Context) *DependencyCheck {
+	return timed(true, func() *DependencyCheck {
+		if s.db == nil {
+			return &DependencyCheck{Status: DependencyUnknown, Message: "database not initialized"}
+		}
+
+		queryCtx, cancel := context.WithTimeout(ctx, dependencyTimeout)
+		defer cancel()
+
+		var columns []struct {
+			TableName  string
+			ColumnName string
+		}
+		if err := s.db.WithContext(queryCtx).Raw(`
+			SELECT table_name, column_name FROM information_schema.columns
+			WHERE table_schema = current_schema()`).Scan(&columns).Error; err != nil {
+			return &DependencyCheck{Status: DependencyDown, Message: "failed to read schema: " + err.Error()}
+		}
+
+		existing := make(map[string]map[string]bool)
+		for _, column := range columns {
+			if existing[column.TableName] == nil {
+				existing[column.TableName] = make(map[string]bool)
+			}
+			existing[column.TableName][column.ColumnName] = true
+		}
+
+		pending := []string{}
+		for _, model := range models.MigrationModels() {
+			stmt := &gorm.Statement{DB: s.db}
+			if err := stmt.Parse(model); err != nil {
+				return &DependencyCheck{Status: DependencyUnknown, Message: "failed to parse model: " + err.Error()}
+			}
+
+			tableColumns, ok := existing[stmt.Schema.Table]
+			if !ok {
+				pending = append(pending, stmt.Schema.Table)
+				continue
+			}
+			for _, column := range stmt.Schema.DBNames {
+				if !tableColumns[column] {
+					pending = append(pending, stmt.Schema.Table+"."+column)
+				}
+			}
+		}
+
+		if len(pending) > 0 {
+			return &DependencyCheck{
+				Status:  DependencyDown,
+				Message: fmt.Sprintf("%d pending schema changes", len(pending)),
+				Details: map[string]interface{}{"pending": pending},
+			}
+		}
+		return &DependencyCheck{Status: DependencyUp}
+	})
+}
+
+// checkDisk reports free space on the attachment store
+func (s *HealthService) checkDisk(ctx context.Context) *DependencyCheck {
+	return timed(true, func() *DependencyCheck {
+		path := AttachmentStoreDir
+		if _, err := os.Stat(path); err != nil {
+			// The store is created on first upload; fall back to the working directory
+			path = "."
+		}
+
+		free, total, err := utils.DiskUsage(path)
+		if err != nil {
+			return &DependencyCheck{Status: DependencyUnknown, Message: err.Error()}
+		}
+
+		details := map[string]interface{}{
+			"path":        AttachmentStoreDir,
+			"free_bytes":  free,
+			"total_bytes": total,
+		}
+		switch {
+		case free < diskCriticalFreeBytes:
+			return &DependencyCheck{Status: DependencyDown, Message: "attachment store is almost full", Details: details}
+		case free < diskWarningFreeBytes:
+			return &DependencyCheck{Status: DependencyDegraded, Message: "attachment store is low on space", Details: details}
+		}
+		return &DependencyCheck{Status: DependencyUp, Details: details}
+	})
+}
+
+// checkRedis pings Redis when caching is enabled
+func (s *HealthService) checkRedis(ctx context.Context) *DependencyCheck {
+	return timed(false, func() *DependencyCheck {
+		if cache.GetClient() == nil {
+			return &DependencyCheck{Status: DependencyDisabled}
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, dependencyTimeout)
+		defer cancel()
+		if err := cache.HealthCheck(pingCtx); err != nil {
+			return &DependencyCheck{Status: DependencyDown, Message: err.Error()}
+		}
+		return &DependencyCheck{Status: DependencyUp}
+	})
+}
+
+// checkSearch pings the OpenSearch cluster when it is the search backend
+func (s *HealthService) checkSearch(ctx context.Context) *DependencyCheck {
+	return timed(false, func() *DependencyCheck {
+		idx := ActiveSearchIndex()
+		if idx == nil {
+			return &DependencyCheck{Status: DependencyDisabled, Message: "using postgres"}
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, dependencyTimeout)
+		defer cancel()
+		if err := idx.Ping(pingCtx); err != nil {
+			// List queries fall back to Postgres
+			return &DependencyCheck{Status: DependencyDown, Message: err.Error()}
+		}
+		return &DependencyCheck{Status: DependencyUp}
+	})
+}
+
+// checkIntegrations verifies that the hosts of active integrations accept connections.
+// Only a TCP connection is attempted so probes never spend scanner API quota.
+func (s *HealthService) checkIntegrations(ctx context.Context) *DependencyCheck {
+	return timed(false, func() *DependencyCheck {
+		if s.db == nil {
+			return &DependencyCheck{Status: DependencyUnknown, Message: "database not initialized"}
+		}
+
+		var configs []models.IntegrationConfig
+		if err := s.db.WithContext(ctx).
+			Select("id", "name", "type", "base_url").
+			Where("active = ?", true).
+			Find(&configs).Error; err != nil {
+			return &DependencyCheck{Status: DependencyUnknown, Message: "failed to load integrations: " + err.Error()}
+		}
+		if len(configs) == 0 {
+			return &DependencyCheck{Status: DependencyDisabled, Message: "no active integrations"}
+		}
+
+		results := make([]map[string]interface{}, len(configs))
+		var wg sync.WaitGroup
+		for i, config := range configs {
+			wg.Add(1)
+			go func(i int, config models.IntegrationConfig) {
+				defer wg.Done()
+				result := map[string]interface{}{
+					"id":   config.ID,
+					"name": config.Name,
+					"type": config.Type,
+				}
+				if err := dialIntegration(ctx, config.BaseURL); err != nil {
+					result["status"] = DependencyDown
+					result["error"] = err.Error()
+				} else {
+					result["status"] = DependencyUp
+				}
+				results[i] = result
+			}(i, config)
+		}
+		wg.Wait()
+
+		down := 0
+		for _, result := range results {
+			if result["status"] == DependencyDown {
+				down++
+			}
+		}
+
+		check := &DependencyCheck{
+			Status:  DependencyUp,
+			Details: map[string]interface{}{"integrations": results},
+		}
+		if down > 0 {
+			check.Status = DependencyDegraded
+			check.Message = fmt.Sprintf("%d of %d integrations unreachable", down, len(configs))
+		}
+		return check
+	})
+}
+
+// dialIntegration opens (and immediately closes) a TCP connection to an integration's base URL
+func dialIntegration(ctx context.Context, baseURL string) error {
+	parsed, err := url.Parse(baseURL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("invalid base URL")
+	}
+
+	host := parsed.Host
+	if parsed.Port() == "" {
+		port := "443"
+		if parsed.Scheme == "http" {
+			port = "80"
+		}
+		host = net.JoinHostPort(parsed.Hostname(), port)
+	}
+
+	dialer := net.Dialer{Timeout: dependencyTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/auth"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// ErrImpersonationNotAllowed is returned when an administrator may not impersonate a user
+var ErrImpersonationNotAllowed = errors.New("impersonation not allowed")
+
+// StartImpersonation creates a time-boxed session in which admin acts as target. The session
+// carries the administrator's ID so every audit event it produces names them, and it has no
+// refresh token: when it expires the administrator must start a new one. Administrators can
+// only impersonate users whose role level is below their own.
+func (s *SessionService) StartImpersonation(admin, target *models.User, duration time.Duration, reason, ipAddress, userAgent string) (*models.Session, error) {
+	reason = strings.TrimSpace(reason)
+	switch {
+	case reason == "":
+		return nil, fmt.Errorf("%w: a reason is required", ErrImpersonationNotAllowed)
+	case admin.ID == target.ID:
+		return nil, fmt.Errorf("%w: you cannot impersonate yourself", ErrImpersonationNotAllowed)
+	case admin.Role == nil || (target.Role != nil && target.Role.Level >= admin.Role.Level):
+		return nil, fmt.Errorf("%w: users with an equal or higher role level cannot be impersonated", ErrImpersonationNotAllowed)
+	}
+
+	if duration <= 0 {
+		duration = auth.DefaultImpersonationDuration
+	}
+	if duration > auth.MaxImpersonationDuration {
+		duration = auth.MaxImpersonationDuration
+	}
+
+	session, err := auth.CreateSession(target.ID, ipAddress, userAgent, duration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	// Without a refresh token the access token has to last the whole session
+	session.AccessExpiresAt = session.ExpiresAt
+	session.ImpersonatorID = &admin.ID
+	session.ImpersonationReason = reason
+	session.Location = auth.LocateIP(ipAddress)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
+		}
+		event := impersonationEvent(session, models.EventTypeImpersonationStarted, ipAddress, userAgent, map[string]interface{}{
+			"reason":     reason,
+			"expires_at": session.ExpiresAt,
+		})
+		if err := tx.Create(event).Error; err != nil {
+			return fmt.Errorf("failed to record impersonation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Warn().
+		Str("session_id", session.ID.String()).
+		Str("impersonator_id", admin.ID.String()).
+		Str("user_id", target.ID.String()).
+		Str("reason", reason).
+		Time("expires_at", session.ExpiresAt).
+		Msg("Impersonation session started")
+
+	return session, nil
+}
+
+// EndImpersonation revokes an impersonation session and records that it ended
+func (s *SessionService) EndImpersonation(session *models.Session, ipAddress, userAgent string) error {
+	if !session.IsImpersonation() {
+		return fmt.Errorf("session is not an impersonation session")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := revokeSessions(tx, tx.Where("id = ?", session.ID), models.SessionRevokeImpersonated); err != nil {
+			return err
+		}
+		event := impersonationEvent(session, models.EventTypeImpersonationEnded, ipAddress, userAgent, nil)
+		if err := tx.Create(event).Error; err != nil {
+			return fmt.Errorf("failed to record impersonation end: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	utils.Logger.Warn().
+		Str("session_id", session.ID.String()).
+		Str("impersonator_id", session.ImpersonatorID.String()).
+		Str("user_id", session.UserID.String()).
+		Msg("Impersonation session ended")
+
+	return nil
+}
+
+// RecordImpersonatedRequest writes an audit event for a request made in an impersonation session
+func (s *SessionService) RecordImpersonatedRequest(session *models.Session, method, path string, status int, requestID, ipAddress, userAgent string) {
+	event := impersonationEvent(session, models.EventTypeImpersonatedRequest, ipAddress, userAgent, map[string]interface{}{
+		"method": method,
+		"path":   path,
+		"status": status,
+	})
+	event.RequestID = requestID
+	event.Success = status < 400
+	if err := s.db.Create(event).Error; err != nil {
+		utils.Logger.Error().
+			Err(err).
+			Str("session_id", session.ID.String()).
+			Msg("Failed to record impersonated request")
+	}
+}
+
+// impersonationEvent builds an auth event for session that names both the user and the administrator
+func impersonationEvent(session *models.Session, eventType models.EventType, ipAddress, userAgent string, metadata map[string]interface{}) *models.AuthEvent {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["session_id"] = session.ID.String()
+
+	event := models.NewAuthEvent(&session.UserID, eventType, ipAddress, userAgent)
+	event.ImpersonatorID = session.ImpersonatorID
+	if encoded, err := json.Marshal(metadata); err == nil {
+		event.Metadata = string(encoded)
+	}
+	return event
+}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// findingSnapshot holds the finding columns an import updates, as they were before the update
+type findingSnapshot struct {
+	ID                  uuid.UUID            `json:"-"`
+	LastSeen            time.Time            `json:"last_seen"`
+	IntegrationConfigID *uuid.UUID           `json:"integration_config_id"`
+	ScanID              string               `json:"scan_id"`
+	ScanDate            *time.Time           `json:"scan_date"`
+	ImportJobID         *uuid.UUID           `json:"import_job_id"`
+	CleanScans          int                  `json:"clean_scans"`
+	DetectedVersion     string               `json:"detected_version"`
+	FixedVersion        string               `json:"fixed_version"`
+	Status              models.FindingStatus `json:"status"`
+	FixedAt             *time.Time           `json:"fixed_at"`
+	FixNotes            string               `json:"fix_notes"`
+}
+
+// columns returns the values a rollback restores for a change: only those the change updated
+func (f *findingSnapshot) columns(action models.ImportChangeAction) map[string]interface{} {
+	if action == models.ImportChangeMissing {
+		return map[string]interface{}{
+			"clean_scans": f.CleanScans,
+			"status":      f.Status,
+			"fixed_at":    f.FixedAt,
+			"fix_notes":   f.FixNotes,
+		}
+	}
+	return map[string]interface{}{
+		"last_seen":             f.LastSeen,
+		"integration_config_id": f.IntegrationConfigID,
+		"scan_id":               f.ScanID,
+		"scan_date":             f.ScanDate,
+		"import_job_id":         f.ImportJobID,
+		"clean_scans":           f.CleanScans,
+		"detected_version":      f.DetectedVersion,
+		"fixed_version":         f.FixedVersion,
+	}
+}
+
+// recordCreated journals records an import job created
+func recordCreated(tx *gorm.DB, jobID uuid.UUID, entityType string, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	changes := make([]*models.ImportChange, len(ids))
+	for i, id := range ids {
+		changes[i] = &models.ImportChange{
+			ImportJobID: jobID,
+			EntityType:  entityType,
+			EntityID:    id,
+			Action:      models.ImportChangeCreated,
+		}
+	}
+	if err := tx.CreateInBatches(changes, importBatchSize).Error; err != nil {
+		return fmt.Errorf("failed to journal created %ss: %w", entityType, err)
+	}
+	return nil
+}
+
+// recordCreatedLinks journals the vulnerability-asset links an import job is about to add,
+// leaving out those that already exist. It must run before the links are inserted.
+func recordCreatedLinks(tx *gorm.DB, jobID uuid.UUID, links []models.VulnerabilityAffectedSystem) error {
+	if len(links) == 0 {
+		return nil
+	}
+	vulnIDs := make([]string, 0, len(links))
+	assetIDs := make([]string, 0, len(links))
+	for _, link := range links {
+		vulnIDs = append(vulnIDs, link.VulnerabilityID)
+		assetIDs = append(assetIDs, link.AffectedSystemID)
+	}
+	var existing []models.VulnerabilityAffectedSystem
+	if err := tx.Select("vulnerability_id", "affected_system_id").
+		Where("vulnerability_id IN ? AND affected_system_id IN ?", vulnIDs, assetIDs).
+		Find(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check existing asset links: %w", err)
+	}
+	linked := make(map[string]bool, len(existing))
+	for _, link := range existing {
+		linked[link.VulnerabilityID+"/"+link.AffectedSystemID] = true
+	}
+
+	var changes []*models.ImportChange
+	for _, link := range links {
+		if linked[link.VulnerabilityID+"/"+link.AffectedSystemID] {
+			continue
+		}
+		vulnID, err := uuid.Parse(link.VulnerabilityID)
+		if err != nil {
+			return fmt.Errorf("failed to journal asset links: %w", err)
+		}
+		assetID, err := uuid.Parse(link.AffectedSystemID)
+		if err != nil {
+			return fmt.Errorf("failed to journal asset links: %w", err)
+		}
+		changes = append(changes, &models.ImportChange{
+			ImportJobID: jobID,
+			EntityType:  models.ImportChangeAssetLink,
+			EntityID:    vulnID,
+			RelatedID:   &assetID,
+			Action:      models.ImportChangeCreated,
+		})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := tx.CreateInBatches(changes, importBatchSize).Error; err != nil {
+		return fmt.Errorf("failed to journal asset links: %w", err)
+	}
+	return nil
+}
+
+// recordFindingChanges journals the findings an import job is about to update, with their
+// current values. It must run before the update.
+func recordFindingChanges(tx *gorm.DB, jobID uuid.UUID, action models.ImportChangeAction, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var snapshots []findingSnapshot
+	if err := tx.Model(&models.VulnerabilityFinding{}).
+		Where("id IN ?", ids).
+		Find(&snapshots).Error; err != nil {
+		return fmt.Errorf("failed to capture findings before update: %w", err)
+	}
+
+	changes := make([]*models.ImportChange, len(snapshots))
+	for i := range snapshots {
+		previous, err := json.Marshal(&snapshots[i])
+		if err != nil {
+			return fmt.Errorf("failed to capture findings before update: %w", err)
+		}
+		changes[i] = &models.ImportChange{
+			ImportJobID: jobID,
+			EntityType:  models.ImportChangeFinding,
+			EntityID:    snapshots[i].ID,
+			Action:      action,
+			Previous:    string(previous),
+		}
+	}
+	if err := tx.CreateInBatches(changes, importBatchSize).Error; err != nil {
+		return fmt.Errorf("failed to journal updated findings: %w", err)
+	}
+	return nil
+}
+
+// ImportRollbackResult counts what rolling back an import job reverted
+type ImportRollbackResult struct {
+	ImportJobID            uuid.UUID `json:"import_job_id"`
+	DeletedAssets          int       `json:"deleted_assets"`          // Moved to the recycle bin
+	DeletedVulnerabilities int       `json:"deleted_vulnerabilities"` // Moved to the recycle bin
+	DeletedFindings        int       `json:"deleted_findings"`
+	DeletedAssetLinks      int       `json:"deleted_asset_links"` // Links the import added between vulnerabilities and assets
+	RestoredFindings       int       `json:"restored_findings"`   // Findings seen again or no longer detected, back to their earlier values
+	ReopenedFindings       int       `json:"reopened_findings"`   // Findings the import had closed as no longer detected
+	// SkippedFindings lists findings the import counted as no longer detected whose status was
+	// changed after the import; they are left as they are
+	SkippedFindings []uuid.UUID `json:"skipped_findings"`
+}
+
+// createdByJob selects the IDs of the records of one type an import job created
+func createdByJob(db *gorm.DB, jobID uuid.UUID, entityType string) *gorm.DB {
+	return db.Model(&models.ImportChange{}).
+		Select("entity_id").
+		Where("import_job_id = ? AND entity_type = ? AND action = ?", jobID, entityType, models.ImportChangeCreated)
+}
+
+// checkImportRollback refuses rolling back a job whose records later work depends on: records
+// a later import changed again, or findings added to its vulnerabilities or assets since.
+// Asset links are left out: a link findings still rely on is kept by the rollback.
+func checkImportRollback(tx *gorm.DB, job *models.ImportJob) error {
+	var later []uuid.UUID
+	if err := tx.Model(&models.ImportChange{}).
+		Joins("JOIN import_jobs ON import_jobs.id = import_changes.import_job_id").
+		Where("import_changes.entity_id IN (?)",
+			tx.Model(&models.ImportChange{}).Select("entity_id").
+				Where("import_job_id = ? AND entity_type <> ?", job.ID, models.ImportChangeAssetLink)).
+		Where("import_changes.entity_type <> ?", models.ImportChangeAssetLink).
+		Where("import_changes.import_job_id <> ? AND import_jobs.created_at > ? AND import_jobs.status <> ?",
+			job.ID, job.CreatedAt, models.ImportJobRolledBack).
+		Limit(1).
+		Pluck("import_changes.import_job_id", &later).Error; err != nil {
+		return fmt.Errorf("failed to check later imports: %w", err)
+	}
+	if len(later) > 0 {
+		return fmt.Errorf("import job cannot be rolled back: later import %s changed its records, roll that back first", later[0])
+	}
+
+	var dependent int64
+	if err := tx.Model(&models.VulnerabilityFinding{}).
+		Where("vulnerability_id IN (?) OR affected_system_id IN (?)",
+			createdByJob(tx, job.ID, models.ImportChangeVulnerability),
+			createdByJob(tx, job.ID, models.ImportChangeAsset)).
+		Where("id NOT IN (?)", createdByJob(tx, job.ID, models.ImportChangeFinding)).
+		Count(&dependent).Error; err != nil {
+		return fmt.Errorf("failed to check dependent findings: %w", err)
+	}
+	if dependent > 0 {
+		return fmt.Errorf("import job cannot be rolled back: %d findings added since reference the vulnerabilities or assets it created", dependent)
+	}
+	return nil
+}
+
+// RollbackImport reverts a finished import job: the findings and asset links it created are
+// deleted, the vulnerabilities and assets it created are moved to the recycle bin, and the
+// findings it updated get back the values the import replaced. Findings the import closed as
+// no longer detected are reopened, unless their status was changed after the import.
+func (s *VulnerabilityImportService) RollbackImport(jobID uuid.UUID, userID uuid.UUID) (*ImportRollbackResult, error) {
+	var job models.ImportJob
+	if err := s.db.First(&job, "id = ?", jobID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("import job not found")
+		}
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	switch job.Status {
+	case models.ImportJobCompleted, models.ImportJobFailed:
+	case models.ImportJobRolledBack:
+		return nil, fmt.Errorf("import job is already rolled back")
+	default:
+		return nil, fmt.Errorf("import job cannot be rolled back while it is %s", job.Status)
+	}
+
+	result := &ImportRollbackResult{ImportJobID: job.ID, SkippedFindings: []uuid.UUID{}}
+	now := time.Now()
+	finishedAt := job.StartedAt
+	if job.CompletedAt != nil {
+		finishedAt = *job.CompletedAt
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Claim the job so concurrent rollbacks do not revert it twice
+		claim := tx.Model(&models.ImportJob{}).
+			Where("id = ? AND status = ?", job.ID, job.Status).
+			Updates(map[string]interface{}{
+				"status":            models.ImportJobRolledBack,
+				"rolled_back_by_id": userID,
+				"rolled_back_at":    now,
+			})
+		if claim.Error != nil {
+			return fmt.Errorf("failed to roll back import job: %w", claim.Error)
+		}
+		if claim.RowsAffected == 0 {
+			return fmt.Errorf("import job is already rolled back")
+		}
+
+		if err := checkImportRollback(tx, &job); err != nil {
+			return err
+		}
+
+		// Restore updated findings to the values before the job's first update of each
+		var changes []models.ImportChange
+		if err := tx.Where("import_job_id = ? AND action <> ?", job.ID, models.ImportChangeCreated).
+			Order("created_at").
+			Find(&changes).Error; err != nil {
+			return fmt.Errorf("failed to load import changes: %w", err)
+		}
+		findingIDs := make([]uuid.UUID, len(changes))
+		var missingIDs []uuid.UUID
+		for i, change := range changes {
+			findingIDs[i] = change.EntityID
+			if change.Action == models.ImportChangeMissing {
+				missingIDs = append(missingIDs, change.EntityID)
+			}
+		}
+		var current []models.VulnerabilityFinding
+		if len(findingIDs) > 0 {
+			if err := tx.Select("id", "status").Where("id IN ?", findingIDs).Find(&current).Error; err != nil {
+				return fmt.Errorf("failed to load updated findings: %w", err)
+			}
+		}
+		statuses := make(map[uuid.UUID]models.FindingStatus, len(current))
+		for _, finding := range current {
+			statuses[finding.ID] = finding.Status
+		}
+
+		// Status changes made after the import win over restoring what the import replaced
+		var changedSince []uuid.UUID
+		if len(missingIDs) > 0 {
+			if err := tx.Model(&models.FindingStatusHistory{}).
+				Where("finding_id IN ? AND changed_at > ?", missingIDs, finishedAt).
+				Distinct().
+				Pluck("finding_id", &changedSince).Error; err != nil {
+				return fmt.Errorf("failed to check finding status history: %w", err)
+			}
+		}
+		statusChanged := make(map[uuid.UUID]bool, len(changedSince))
+		for _, id := range changedSince {
+			statusChanged[id] = true
+		}
+
+		restored := make(map[uuid.UUID]bool)
+		var reopened []*models.FindingStatusHistory
+		for _, change := range changes {
+			status, exists := statuses[change.EntityID]
+			if restored[change.EntityID] || !exists {
+				continue // Restored from an earlier change, or deleted since
+			}
+			restored[change.EntityID] = true
+			if change.Action == models.ImportChangeMissing && statusChanged[change.EntityID] {
+				result.SkippedFindings = append(result.SkippedFindings, change.EntityID)
+				continue
+			}
+
+			var previous findingSnapshot
+			if err := json.Unmarshal([]byte(change.Previous), &previous); err != nil {
+				return fmt.Errorf("failed to read import change %s: %w", change.ID, err)
+			}
+			if err := tx.Model(&models.VulnerabilityFinding{}).
+				Where("id = ?", change.EntityID).
+				Updates(previous.columns(change.Action)).Error; err != nil {
+				return fmt.Errorf("failed to restore finding %s: %w", change.EntityID, err)
+			}
+			result.RestoredFindings++
+
+			// Findings the import closed get a reopening entry in their status history
+			if change.Action == models.ImportChangeMissing && previous.Status != status {
+				reopened = append(reopened, &models.FindingStatusHistory{
+					FindingID:   change.EntityID,
+					OldStatus:   status,
+					NewStatus:   previous.Status,
+					Notes:       fmt.Sprintf("Reopened by rollback of import %s", job.ID),
+					ChangedByID: userID,
+					ChangedAt:   now,
+				})
+			}
+		}
+		if len(reopened) > 0 {
+			if err := tx.CreateInBatches(reopened, importBatchSize).Error; err != nil {
+				return fmt.Errorf("failed to record reopened findings: %w", err)
+			}
+			result.ReopenedFindings = len(reopened)
+		}
+
+		// Delete created findings, then move created vulnerabilities and assets to the recycle bin
+		deleted := tx.Where("id IN (?)", createdByJob(tx, job.ID, models.ImportChangeFinding)).
+			Delete(&models.VulnerabilityFinding{})
+		if deleted.Error != nil {
+			return fmt.Errorf("failed to delete findings: %w", deleted.Error)
+		}
+		result.DeletedFindings = int(deleted.RowsAffected)
+
+		// Remove the asset links it added, except those findings still rely on
+		unlinked := tx.Where("(vulnerability_id, affected_system_id) IN (?)",
+			tx.Model(&models.ImportChange{}).
+				Select("entity_id", "related_id").
+				Where("import_job_id = ? AND entity_type = ? AND action = ?", job.ID, models.ImportChangeAssetLink, models.ImportChangeCreated)).
+			Where("NOT EXISTS (?)",
+				tx.Model(&models.VulnerabilityFinding{}).
+					Select("1").
+					Where("vulnerability_findings.vulnerability_id = vulnerability_affected_systems.vulnerability_id").
+					Where("vulnerability_findings.affected_system_id = vulnerability_affected_systems.affected_system_id")).
+			Delete(&models.VulnerabilityAffectedSystem{})
+		if unlinked.Error != nil {
+			return fmt.Errorf("failed to delete asset links: %w", unlinked.Error)
+		}
+		result.DeletedAssetLinks = int(unlinked.RowsAffected)
+
+		for _, entity := range []struct {
+			entityType string
+			model      interface{}
+			count      *int
+		}{
+			{models.ImportChangeVulnerability, &models.Vulnerability{}, &result.DeletedVulnerabilities},
+			{models.ImportChangeAsset, &models.AffectedSystem{}, &result.DeletedAssets},
+		} {
+			created := createdByJob(tx, job.ID, entity.entityType)
+			if err := tx.Model(entity.model).Where("id IN (?)", created).
+				UpdateColumn("deleted_by_id", userID).Error; err != nil {
+				return fmt.Errorf("failed to delete %ss: %w", entity.entityType, err)
+			}
+			deleted := tx.Where("id IN (?)", created).Delete(entity.model)
+			if deleted.Error != nil {
+				return fmt.Errorf("failed to delete %ss: %w", entity.entityType, deleted.Error)
+			}
+			*entity.count = int(deleted.RowsAffected)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("import_job_id", job.ID.String()).
+		Str("rolled_back_by", userID.String()).
+		Int("deleted_findings", result.DeletedFindings).
+		Int("deleted_vulnerabilities", result.DeletedVulnerabilities).
+		Int("deleted_assets", result.DeletedAssets).
+		Int("deleted_asset_links", result.DeletedAssetLinks).
+		Int("restored_findings", result.RestoredFindings).
+		Int("skipped_findings", len(result.SkippedFindings)).
+		Msg("Import job rolled back")
+	return result, nil
+}
@@ -0,0 +1,433 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// ImportRuleService manages the rules that classify the hosts of imported scans
+type ImportRuleService struct {
+	db *gorm.DB
+}
+
+// NewImportRuleService creates a new import rule service
+func NewImportRuleService(db *gorm.DB) *ImportRuleService {
+	return &ImportRuleService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *ImportRuleService) WithContext(ctx context.Context) *ImportRuleService {
+	return &ImportRuleService{db: s.db.WithContext(ctx)}
+}
+
+// ImportRuleRequest represents a create or update import rule request
+type ImportRuleRequest struct {
+	Name        *string    `json:"name,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Enabled     *bool      `json:"enabled,omitempty"`
+	Position    *int       `json:"position,omitempty"`
+	MatchType   *string    `json:"match_type,omitempty"`
+	Pattern     *string    `json:"pattern,omitempty"`
+	Environment *string    `json:"environment,omitempty"`   // Empty clears the environment
+	OwnerID     *uuid.UUID `json:"owner_id,omitempty"`      // uuid.Nil clears the owner
+	OwnerTeamID *uuid.UUID `json:"owner_team_id,omitempty"` // uuid.Nil clears the owner team
+}
+
+// applyTo copies the provided request fields onto a rule
+func (req ImportRuleRequest) applyTo(rule *models.ImportRule) {
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		rule.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.Position != nil {
+		rule.Position = *req.Position
+	}
+	if req.MatchType != nil {
+		rule.MatchType = models.ImportRuleMatch(strings.ToLower(strings.TrimSpace(*req.MatchType)))
+	}
+	if req.Pattern != nil {
+		rule.Pattern = strings.TrimSpace(*req.Pattern)
+	}
+	if req.Environment != nil {
+		rule.Environment = models.Environment(strings.ToUpper(strings.TrimSpace(*req.Environment)))
+	}
+	if req.OwnerID != nil {
+		rule.OwnerID = optionalID(*req.OwnerID)
+	}
+	if req.OwnerTeamID != nil {
+		rule.OwnerTeamID = optionalID(*req.OwnerTeamID)
+	}
+}
+
+// ValidateImportRule checks that a rule has a name, a valid pattern for its match type and
+// something to assign, and rewrites CIDR patterns to their canonical network form
+func ValidateImportRule(rule *models.ImportRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if rule.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	switch rule.MatchType {
+	case models.ImportRuleMatchCIDR:
+		_, network, err := net.ParseCIDR(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern, not a cidr: %s", rule.Pattern)
+		}
+		rule.Pattern = network.String()
+	case models.ImportRuleMatchHostname, models.ImportRuleMatchScanName, models.ImportRuleMatchTag:
+		if _, err := compileImportRulePattern(rule.MatchType, rule.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+	default:
+		return fmt.Errorf("invalid match_type, must be one of: hostname, cidr, scan_name, tag")
+	}
+
+	switch rule.Environment {
+	case "", models.EnvProduction, models.EnvStaging, models.EnvDevelopment, models.EnvTest:
+	default:
+		return fmt.Errorf("invalid environment, must be one of: PRODUCTION, STAGING, DEVELOPMENT, TEST")
+	}
+	if rule.Environment == "" && rule.OwnerID == nil && rule.OwnerTeamID == nil {
+		return fmt.Errorf("invalid import rule, environment, owner_id or owner_team_id is required")
+	}
+	return nil
+}
+
+// compiledImportRule is a rule with its pattern pre-parsed
+type compiledImportRule struct {
+	rule    models.ImportRule
+	network *net.IPNet     // cidr rules
+	tag     string         // tag rules: the tag name
+	pattern *regexp.Regexp // hostname and scan_name rules, and tag rules with a value
+}
+
+// compileImportRulePattern compiles a rule's pattern. Regular expressions are case-insensitive;
+// tag patterns are a tag name, optionally followed by = and a regular expression for its value.
+func compileImportRulePattern(matchType models.ImportRuleMatch, pattern string) (*compiledImportRule, error) {
+	compiled := &compiledImportRule{}
+	switch matchType {
+	case models.ImportRuleMatchCIDR:
+		_, network, err := net.ParseCIDR(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled.network = network
+		return compiled, nil
+	case models.ImportRuleMatchTag:
+		name, value, hasValue := strings.Cut(pattern, "=")
+		compiled.tag = strings.TrimSpace(name)
+		if compiled.tag == "" {
+			return nil, fmt.Errorf("tag name is required")
+		}
+		if !hasValue {
+			return compiled, nil
+		}
+		pattern = strings.TrimSpace(value)
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, err
+	}
+	compiled.pattern = re
+	return compiled, nil
+}
+
+// matches reports whether a compiled rule matches a host
+func (r *compiledImportRule) matches(host ParsedHost) bool {
+	switch r.rule.MatchType {
+	case models.ImportRuleMatchHostname:
+		return host.Hostname != "" && r.pattern.MatchString(host.Hostname)
+	case models.ImportRuleMatchCIDR:
+		ip := net.ParseIP(strings.TrimSpace(host.IPAddress))
+		return ip != nil && r.network.Contains(ip)
+	case models.ImportRuleMatchScanName:
+		return host.ScanName != "" && r.pattern.MatchString(host.ScanName)
+	case models.ImportRuleMatchTag:
+		for name, value := range host.Tags {
+			if strings.EqualFold(name, r.tag) {
+				return r.pattern == nil || r.pattern.MatchString(value)
+			}
+		}
+	}
+	return false
+}
+
+// ImportClassification is the environment and owner import rules assign a host; the rules
+// are nil when no rule set them
+type ImportClassification struct {
+	Environment     models.Environment
+	OwnerID         *uuid.UUID
+	OwnerTeamID     *uuid.UUID
+	EnvironmentRule *models.ImportRule
+	OwnerRule       *models.ImportRule
+}
+
+// ImportRuleMatcher classifies hosts with a fixed, ordered set of enabled rules
+type ImportRuleMatcher struct {
+	rules []compiledImportRule
+}
+
+// NewImportRuleMatcher keeps the enabled rules in the given order; rules with an invalid
+// pattern are skipped
+func NewImportRuleMatcher(rules []models.ImportRule) *ImportRuleMatcher {
+	matcher := &ImportRuleMatcher{}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		compiled, err := compileImportRulePattern(rule.MatchType, rule.Pattern)
+		if err != nil {
+			continue
+		}
+		compiled.rule = rule
+		matcher.rules = append(matcher.rules, *compiled)
+	}
+	return matcher
+}
+
+// Len returns the number of active rules
+func (m *ImportRuleMatcher) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.rules)
+}
+
+// Classify returns the environment of the first matching rule that sets one and the owners of
+// the first matching rule that sets an owner
+func (m *ImportRuleMatcher) Classify(host ParsedHost) ImportClassification {
+	var classification ImportClassification
+	if m == nil {
+		return classification
+	}
+	for i := range m.rules {
+		rule := &m.rules[i].rule
+		if !m.rules[i].matches(host) {
+			continue
+		}
+		if classification.EnvironmentRule == nil && rule.Environment != "" {
+			classification.Environment = rule.Environment
+			classification.EnvironmentRule = rule
+		}
+		if classification.OwnerRule == nil && (rule.OwnerID != nil || rule.OwnerTeamID != nil) {
+			classification.OwnerID = rule.OwnerID
+			classification.OwnerTeamID = rule.OwnerTeamID
+			classification.OwnerRule = rule
+		}
+		if classification.EnvironmentRule != nil && classification.OwnerRule != nil {
+			break
+		}
+	}
+	return classification
+}
+
+// checkReferences checks that the user and team a rule assigns exist
+func (s *ImportRuleService) checkReferences(rule *models.ImportRule) error {
+	if rule.OwnerID != nil {
+		var count int64
+		if err := s.db.Model(&models.User{}).Where("id = ?", *rule.OwnerID).Count(&count).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("owner not found")
+		}
+	}
+	if rule.OwnerTeamID != nil {
+		if err := NewTeamService(s.db).Exists(*rule.OwnerTeamID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListRules returns all import rules in evaluation order
+func (s *ImportRuleService) ListRules() ([]models.ImportRule, error) {
+	var rules []models.ImportRule
+	if err := s.db.Preload("Owner").Preload("OwnerTeam").Order("position, created_at").Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list import rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetRule returns a single import rule
+func (s *ImportRuleService) GetRule(id uuid.UUID) (*models.ImportRule, error) {
+	var rule models.ImportRule
+	if err := s.db.Preload("Owner").Preload("OwnerTeam").Preload("CreatedBy").First(&rule, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("import rule not found")
+		}
+		return nil, fmt.Errorf("failed to get import rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// CreateRule creates an import rule; it applies to hosts of later imports
+func (s *ImportRuleService) CreateRule(req ImportRuleRequest, createdByID uuid.UUID) (*models.ImportRule, error) {
+	rule := &models.ImportRule{
+		Enabled:     true,
+		CreatedByID: createdByID,
+	}
+	req.applyTo(rule)
+
+	if err := ValidateImportRule(rule); err != nil {
+		return nil, err
+	}
+	if err := s.checkReferences(rule); err != nil {
+		return nil, err
+	}
+
+	if err := createWithZeroValues(s.db, rule, "enabled"); err != nil {
+		return nil, fmt.Errorf("failed to create import rule: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("rule_id", rule.ID.String()).
+		Str("match_type", string(rule.MatchType)).
+		Str("created_by", createdByID.String()).
+		Msg("Import rule created")
+
+	return s.GetRule(rule.ID)
+}
+
+// UpdateRule updates an import rule; assets it already classified keep their values
+func (s *ImportRuleService) UpdateRule(id uuid.UUID, req ImportRuleRequest, updatedByID uuid.UUID) (*models.ImportRule, error) {
+	rule, err := s.GetRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.applyTo(rule)
+	if err := ValidateImportRule(rule); err != nil {
+		return nil, err
+	}
+	if err := s.checkReferences(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(rule).Select(
+		"name", "description", "enabled", "position", "match_type", "pattern", "environment",
+		"owner_id", "owner_team_id",
+	).Updates(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to update import rule: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("rule_id", id.String()).
+		Str("updated_by", updatedByID.String()).
+		Msg("Import rule updated")
+
+	return s.GetRule(id)
+}
+
+// DeleteRule soft deletes an import rule
+func (s *ImportRuleService) DeleteRule(id, deletedByID uuid.UUID) error {
+	result := s.db.Delete(&models.ImportRule{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete import rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("import rule not found")
+	}
+
+	utils.Logger.Info().
+		Str("rule_id", id.String()).
+		Str("deleted_by", deletedByID.String()).
+		Msg("Import rule deleted")
+
+	return nil
+}
+
+// LoadMatcher loads the enabled rules in evaluation order using the given transaction
+func (s *ImportRuleService) LoadMatcher(tx *gorm.DB) (*ImportRuleMatcher, error) {
+	var rules []models.ImportRule
+	if err := tx.
+		Where("enabled = ?", true).
+		Order("position, created_at").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load import rules: %w", err)
+	}
+	return NewImportRuleMatcher(rules), nil
+}
+
+// ImportRuleTestRequest describes a scanned host to classify and, optionally, an unsaved rule
+// to classify it with instead of the enabled rules
+type ImportRuleTestRequest struct {
+	Rule      *ImportRuleRequest `json:"rule,omitempty"`
+	Hostname  string             `json:"hostname,omitempty"`
+	IPAddress string             `json:"ip_address,omitempty"`
+	ScanName  string             `json:"scan_name,omitempty"`
+	Tags      map[string]string  `json:"tags,omitempty"` // Scanner host properties, e.g. operating-system
+}
+
+// ImportRuleTestResult is how an import would classify a new asset for the host
+type ImportRuleTestResult struct {
+	Environment     models.Environment   `json:"environment"`
+	OwnerID         *uuid.UUID           `json:"owner_id,omitempty"`
+	OwnerTeamID     *uuid.UUID           `json:"owner_team_id,omitempty"`
+	Location        string               `json:"location,omitempty"`
+	EnvironmentRule *models.ImportRule   `json:"environment_rule,omitempty"`
+	OwnerRule       *models.ImportRule   `json:"owner_rule,omitempty"`
+	NetworkRange    *models.NetworkRange `json:"network_range,omitempty"`
+}
+
+// Test classifies a host as an import by the requesting user would, without creating anything
+func (s *ImportRuleService) Test(req ImportRuleTestRequest, userID uuid.UUID) (*ImportRuleTestResult, error) {
+	if strings.TrimSpace(req.Hostname) == "" && strings.TrimSpace(req.IPAddress) == "" {
+		return nil, fmt.Errorf("hostname or ip_address is required")
+	}
+
+	var matcher *ImportRuleMatcher
+	if req.Rule != nil {
+		rule := &models.ImportRule{}
+		req.Rule.applyTo(rule)
+		if rule.Name == "" {
+			rule.Name = "Tested rule"
+		}
+		if err := ValidateImportRule(rule); err != nil {
+			return nil, err
+		}
+		rule.Enabled = true
+		matcher = NewImportRuleMatcher([]models.ImportRule{*rule})
+	} else {
+		var err error
+		if matcher, err = s.LoadMatcher(s.db); err != nil {
+			return nil, err
+		}
+	}
+	networkRanges, err := NewNetworkRangeService(s.db).LoadMatcher(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	host := ParsedHost{
+		Hostname:  strings.TrimSpace(req.Hostname),
+		IPAddress: strings.TrimSpace(req.IPAddress),
+		ScanName:  strings.TrimSpace(req.ScanName),
+		Tags:      req.Tags,
+	}
+	asset := newImportedAsset(host, userID, networkRanges, matcher)
+	classification := matcher.Classify(host)
+	return &ImportRuleTestResult{
+		Environment:     asset.Environment,
+		OwnerID:         asset.OwnerID,
+		OwnerTeamID:     asset.OwnerTeamID,
+		Location:        asset.Location,
+		EnvironmentRule: classification.EnvironmentRule,
+		OwnerRule:       classification.OwnerRule,
+		NetworkRange:    networkRanges.Match(host.IPAddress),
+	}, nil
+}
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// maxImportScopeCIDRs bounds the scope ranges of import validation
+const maxImportScopeCIDRs = 500
+
+// ImportValidationSettings configures the checks report hosts pass before an import creates
+// assets for them. It is stored as JSON in the import_validation system setting.
+type ImportValidationSettings struct {
+	Enabled            bool     `json:"enabled"`
+	QuarantineTestData bool     `json:"quarantine_test_data"`  // Hold back loopback, documentation and example addresses and names
+	ScopeCIDRs         []string `json:"scope_cidrs,omitempty"` // Hosts whose IP is outside every range are out of scope; empty admits every IP
+}
+
+// DefaultImportValidationSettings are used until the import_validation setting is saved
+func DefaultImportValidationSettings() *ImportValidationSettings {
+	return &ImportValidationSettings{Enabled: true, QuarantineTestData: true}
+}
+
+// ParseImportValidationSettings parses and validates an import_validation setting value,
+// rewriting scope CIDRs to their canonical network form
+func ParseImportValidationSettings(value string) (*ImportValidationSettings, error) {
+	var settings ImportValidationSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return nil, fmt.Errorf("invalid import validation settings: %v", err)
+	}
+	if len(settings.ScopeCIDRs) > maxImportScopeCIDRs {
+		return nil, fmt.Errorf("invalid import validation settings: at most %d scope_cidrs", maxImportScopeCIDRs)
+	}
+	for i, cidr := range settings.ScopeCIDRs {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid import validation settings: invalid scope cidr %s", cidr)
+		}
+		settings.ScopeCIDRs[i] = network.String()
+	}
+	return &settings, nil
+}
+
+// LoadImportValidationSettings returns the configured import validation, or the defaults
+// without the setting
+func LoadImportValidationSettings(db *gorm.DB) (*ImportValidationSettings, error) {
+	var setting models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingImportValidation)).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return DefaultImportValidationSettings(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import validation settings: %w", err)
+	}
+	return ParseImportValidationSettings(setting.Value)
+}
+
+// testDataNetworks are loopback, unspecified, broadcast and documentation addresses
+// (RFC 5737, RFC 3849), which no real scanned host has
+var testDataNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{
+		"127.0.0.0/8", "0.0.0.0/32", "255.255.255.255/32",
+		"192.0.2.0/24", "198.51.100.0/24", "203.0.113.0/24",
+		"::1/128", "::/128", "2001:db8::/32",
+	} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// testDataDomains are the names reserved for testing and documentation (RFC 2606, RFC 6761)
+var testDataDomains = []string{"localhost", "example.com", "example.net", "example.org", "test", "example", "invalid"}
+
+// ImportHostValidator checks report hosts against import validation settings
+type ImportHostValidator struct {
+	settings ImportValidationSettings
+	scope    []*net.IPNet
+}
+
+// NewImportHostValidator compiles the settings; invalid scope CIDRs are skipped
+func NewImportHostValidator(settings ImportValidationSettings) *ImportHostValidator {
+	validator := &ImportHostValidator{settings: settings}
+	for _, cidr := range settings.ScopeCIDRs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil {
+			validator.scope = append(validator.scope, network)
+		}
+	}
+	return validator
+}
+
+// Check returns the rule a host fails with an explanation, or an empty reason when it may be
+// imported
+func (v *ImportHostValidator) Check(host ParsedHost) (models.QuarantineReason, string) {
+	if v == nil || !v.settings.Enabled {
+		return "", ""
+	}
+	hostname := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host.Hostname), "."))
+	ip := net.ParseIP(strings.TrimSpace(host.IPAddress))
+	if hostname == "" && strings.TrimSpace(host.IPAddress) == "" {
+		return models.QuarantineMissingIdentity, "host has neither a hostname nor an IP address"
+	}
+
+	if v.settings.QuarantineTestData {
+		if ip != nil {
+			for _, network := range testDataNetworks {
+				if network.Contains(ip) {
+					return models.QuarantineTestData, fmt.Sprintf("%s is a loopback, broadcast or documentation address", ip)
+				}
+			}
+		}
+		for _, domain := range testDataDomains {
+			if hostname == domain || strings.HasSuffix(hostname, "."+domain) {
+				return models.QuarantineTestData, fmt.Sprintf("%s is a name reserved for testing", hostname)
+			}
+		}
+	}
+
+	if len(v.scope) > 0 && ip != nil {
+		for _, network := range v.scope {
+			if network.Contains(ip) {
+				return "", ""
+			}
+		}
+		return models.QuarantineOutOfScope, fmt.Sprintf("%s is outside the import scope", ip)
+	}
+	return "", ""
+}
+
+// quarantineKey identifies a report host across the vulnerabilities reported on it
+type quarantineKey struct {
+	hostname  string
+	ipAddress string
+}
+
+// quarantinedResults are the results of one report host that failed validation
+type quarantinedResults struct {
+	host    *models.QuarantinedHost
+	results []ParsedVulnerability
+}
+
+// Split separates the hosts that fail validation from the results to import. Vulnerabilities
+// left without hosts are not imported; each quarantined host keeps its own results.
+func (v *ImportHostValidator) Split(vulnerabilities []ParsedVulnerability) ([]ParsedVulnerability, []*models.QuarantinedHost, [][]ParsedVulnerability) {
+	held := make(map[quarantineKey]*quarantinedResults)
+	var order []quarantineKey
+	kept := make([]ParsedVulnerability, 0, len(vulnerabilities))
+	for _, vuln := range vulnerabilities {
+		hosts := make([]ParsedHost, 0, len(vuln.AffectedHosts))
+		quarantined := make(map[quarantineKey][]ParsedHost)
+		for _, host := range vuln.AffectedHosts {
+			reason, detail := v.Check(host)
+			if reason == "" {
+				hosts = append(hosts, host)
+				continue
+			}
+			key := quarantineKey{host.Hostname, host.IPAddress}
+			if _, ok := held[key]; !ok {
+				held[key] = &quarantinedResults{host: &models.QuarantinedHost{
+					Hostname:  host.Hostname,
+					IPAddress: host.IPAddress,
+					ScanName:  host.ScanName,
+					Reason:    reason,
+					Detail:    detail,
+					Status:    models.QuarantinePending,
+				}}
+				order = append(order, key)
+			}
+			quarantined[key] = append(quarantined[key], host)
+		}
+		for key, hostResults := range quarantined {
+			hostVuln := vuln
+			hostVuln.AffectedHosts = hostResults
+			held[key].results = append(held[key].results, hostVuln)
+		}
+		if len(hosts) > 0 || len(vuln.AffectedHosts) == 0 {
+			vuln.AffectedHosts = hosts
+			kept = append(kept, vuln)
+		}
+	}
+
+	hosts := make([]*models.QuarantinedHost, len(order))
+	results := make([][]ParsedVulnerability, len(order))
+	for i, key := range order {
+		hosts[i] = held[key].host
+		hosts[i].Vulnerabilities = len(held[key].results)
+		results[i] = held[key].results
+	}
+	return kept, hosts, results
+}
+
+// loadImportHostValidator loads the import validation settings; without them hosts are not
+// validated
+func loadImportHostValidator(db *gorm.DB, result *ImportResult) *ImportHostValidator {
+	settings, err := LoadImportValidationSettings(db)
+	if err != nil {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("Import validation settings not loaded, report hosts are not validated: %v", err))
+		return nil
+	}
+	return NewImportHostValidator(*settings)
+}
+
+// quarantineHosts holds back the report hosts that fail validation, recording them on the job
+// for manual resolution, and returns the results left to import
+func (s *VulnerabilityImportService) quarantineHosts(db *gorm.DB, job *models.ImportJob, vulnerabilities []ParsedVulnerability, result *ImportResult) ([]ParsedVulnerability, error) {
+	validator := loadImportHostValidator(db, result)
+	if validator == nil {
+		return vulnerabilities, nil
+	}
+	kept, hosts, results := validator.Split(vulnerabilities)
+	if len(hosts) == 0 {
+		return kept, nil
+	}
+
+	for i, host := range hosts {
+		data, err := json.Marshal(results[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to quarantine host %s: %w", host.IPAddress, err)
+		}
+		host.ImportJobID = job.ID
+		host.Results = string(data)
+	}
+	if err := db.CreateInBatches(hosts, importBatchSize).Error; err != nil {
+		return nil, fmt.Errorf("failed to quarantine hosts: %w", err)
+	}
+	if err := db.Model(job).Update("quarantined_hosts", len(hosts)).Error; err != nil {
+		return nil, fmt.Errorf("failed to quarantine hosts: %w", err)
+	}
+
+	result.QuarantinedHosts = len(hosts)
+	result.Warnings = append(result.Warnings,
+		fmt.Sprintf("%d hosts failed validation and were quarantined for review", len(hosts)))
+
+	utils.Logger.Info().
+		Str("import_job_id", job.ID.String()).
+		Int("quarantined_hosts", len(hosts)).
+		Msg("Report hosts quarantined")
+	return kept, nil
+}
+
+// ListQuarantinedHosts returns quarantined hosts, newest first, optionally only those of one
+// import job or status
+func (s *VulnerabilityImportService) ListQuarantinedHosts(importJobID *uuid.UUID, status string, page, perPage int) ([]models.QuarantinedHost, int64, error) {
+	query := s.db.Model(&models.QuarantinedHost{})
+	if importJobID != nil {
+		query = query.Where("import_job_id = ?", *importJobID)
+	}
+	if status != "" {
+		switch models.QuarantineStatus(status) {
+		case models.QuarantinePending, models.QuarantineReleased, models.QuarantineDismissed:
+		default:
+			return nil, 0, fmt.Errorf("invalid status, must be one of: pending, released, dismissed")
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count quarantined hosts: %w", err)
+	}
+
+	hosts := []models.QuarantinedHost{}
+	if err := query.Order("created_at DESC").
+		Limit(perPage).
+		Offset((page - 1) * perPage).
+		Find(&hosts).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list quarantined hosts: %w", err)
+	}
+	return hosts, total, nil
+}
+
+// getQuarantinedHost loads a quarantined host
+func getQuarantinedHost(db *gorm.DB, id uuid.UUID) (*models.QuarantinedHost, error) {
+	var host models.QuarantinedHost
+	err := db.First(&host, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("quarantined host not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load quarantined host: %w", err)
+	}
+	return &host, nil
+}
+
+// claimQuarantinedHost moves a pending host to its resolution, so that it is resolved once
+func claimQuarantinedHost(db *gorm.DB, host *models.QuarantinedHost, status models.QuarantineStatus, note string, userID uuid.UUID) error {
+	now := time.Now()
+	claim := db.Model(&models.QuarantinedHost{}).
+		Where("id = ? AND status = ?", host.ID, models.QuarantinePending).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"resolved_by_id":  userID,
+			"resolved_at":     now,
+			"resolution_note": note,
+		})
+	if claim.Error != nil {
+		return fmt.Errorf("failed to resolve quarantined host: %w", claim.Error)
+	}
+	if claim.RowsAffected == 0 {
+		return fmt.Errorf("quarantined host is already resolved")
+	}
+	host.Status = status
+	host.ResolvedByID = &userID
+	host.ResolvedAt = &now
+	host.ResolutionNote = note
+	return nil
+}
+
+// QuarantineReleaseRequest corrects a quarantined host before it is imported
+type QuarantineReleaseRequest struct {
+	Hostname  *string `json:"hostname,omitempty"`
+	IPAddress *string `json:"ip_address,omitempty"`
+	Note      string  `json:"note,omitempty"`
+}
+
+// ReleaseQuarantinedHost imports the results of a quarantined host, with the corrected
+// hostname or IP address, into a new import of the scan it came from. Released hosts are not
+// validated again, and the import does not check the scan's other findings.
+func (s *VulnerabilityImportService) ReleaseQuarantinedHost(id uuid.UUID, req QuarantineReleaseRequest, userID uuid.UUID) (*models.QuarantinedHost, *ImportResult, error) {
+	host, err := getQuarantinedHost(s.db, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if host.Status != models.QuarantinePending {
+		return nil, nil, fmt.Errorf("quarantined host is already resolved")
+	}
+
+	var results []ParsedVulnerability
+	if err := json.Unmarshal([]byte(host.Results), &results); err != nil {
+		return nil, nil, fmt.Errorf("failed to read quarantined results: %w", err)
+	}
+	hostname, ipAddress := host.Hostname, host.IPAddress
+	if req.Hostname != nil {
+		hostname = strings.TrimSpace(*req.Hostname)
+	}
+	if req.IPAddress != nil {
+		ipAddress = strings.TrimSpace(*req.IPAddress)
+		if ipAddress != "" && net.ParseIP(ipAddress) == nil {
+			return nil, nil, fmt.Errorf("invalid ip_address: %s", ipAddress)
+		}
+	}
+	if hostname == "" && ipAddress == "" {
+		return nil, nil, fmt.Errorf("hostname or ip_address is required to release a host without either")
+	}
+	for i := range results {
+		for j := range results[i].AffectedHosts {
+			results[i].AffectedHosts[j].Hostname = hostname
+			results[i].AffectedHosts[j].IPAddress = ipAddress
+		}
+	}
+
+	var job models.ImportJob
+	if err := s.db.First(&job, "id = ?", host.ImportJobID).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load import job: %w", err)
+	}
+
+	if err := claimQuarantinedHost(s.db, host, models.QuarantineReleased, strings.TrimSpace(req.Note), userID); err != nil {
+		return nil, nil, err
+	}
+	result, err := s.ImportFromNessus(results, userID, job.SkipDuplicates, ImportSource{
+		Scanner:             job.Scanner,
+		IntegrationConfigID: job.IntegrationConfigID,
+		ScanID:              job.ScanID,
+		partial:             true,
+	})
+	if err != nil {
+		// Leave the host for another attempt
+		s.db.Model(&models.QuarantinedHost{}).Where("id = ?", host.ID).Updates(map[string]interface{}{
+			"status":          models.QuarantinePending,
+			"resolved_by_id":  nil,
+			"resolved_at":     nil,
+			"resolution_note": "",
+		})
+		return nil, nil, err
+	}
+	host.ReleasedImportJobID = result.ImportJobID
+	if err := s.db.Model(host).Update("released_import_job_id", result.ImportJobID).Error; err != nil {
+		utils.Logger.Error().Err(err).Str("quarantined_host_id", host.ID.String()).Msg("Failed to record released import job")
+	}
+
+	utils.Logger.Info().
+		Str("quarantined_host_id", host.ID.String()).
+		Str("hostname", hostname).
+		Str("ip_address", ipAddress).
+		Str("released_by", userID.String()).
+		Msg("Quarantined host released")
+	return host, result, nil
+}
+
+// DismissQuarantinedHost discards a quarantined host without importing it
+func (s *VulnerabilityImportService) DismissQuarantinedHost(id uuid.UUID, note string, userID uuid.UUID) (*models.QuarantinedHost, error) {
+	host, err := getQuarantinedHost(s.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := claimQuarantinedHost(s.db, host, models.QuarantineDismissed, strings.TrimSpace(note), userID); err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("quarantined_host_id", host.ID.String()).
+		Str("dismissed_by", userID.String()).
+		Msg("Quarantined host dismissed")
+	return host, nil
+}
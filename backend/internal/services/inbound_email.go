@@ -0,0 +1,435 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// Limits applied while parsing an inbound email
+const (
+	maxInboundEmailAttachments = 20
+	maxInboundEmailPartDepth   = 5
+	maxInboundEmailTitleLength = 255
+)
+
+// ErrInvalidInboundEmail is returned for messages that cannot be parsed; retrying them is useless
+var ErrInvalidInboundEmail = errors.New("invalid email")
+
+// InboundEmailMessage is the content of a parsed RFC 5322 message
+type InboundEmailMessage struct {
+	MessageID   string
+	From        string // Sender address, lower case
+	FromName    string
+	Subject     string
+	Date        *time.Time
+	Text        string // Plain text body, or the text of the HTML body when there is none
+	Attachments []InboundEmailAttachment
+}
+
+// InboundEmailAttachment is a file attached to an inbound email
+type InboundEmailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// charsetReader decodes the character sets email headers and bodies are declared in
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %s", charset)
+	}
+	return enc.NewDecoder().Reader(input), nil
+}
+
+var headerDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+// ParseInboundEmail parses a raw message into its sender, subject, text body and attachments.
+// Messages without a Message-ID are identified by a hash of their content.
+func ParseInboundEmail(raw []byte) (*InboundEmailMessage, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInboundEmail, err)
+	}
+
+	parser := mail.AddressParser{WordDecoder: headerDecoder}
+	from, err := parser.Parse(msg.Header.Get("From"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid From header: %v", ErrInvalidInboundEmail, err)
+	}
+
+	parsed := &InboundEmailMessage{
+		MessageID: strings.Trim(strings.TrimSpace(msg.Header.Get("Message-Id")), "<>"),
+		From:      strings.ToLower(from.Address),
+		FromName:  from.Name,
+	}
+	if parsed.MessageID == "" {
+		sum := sha256.Sum256(raw)
+		parsed.MessageID = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	subject, err := headerDecoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	parsed.Subject = strings.TrimSpace(subject)
+	if date, err := msg.Header.Date(); err == nil {
+		parsed.Date = &date
+	}
+
+	var htmlBody string
+	header := textproto.MIMEHeader(msg.Header)
+	if err := parsed.walkPart(header, msg.Body, 0, &htmlBody); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInboundEmail, err)
+	}
+	if strings.TrimSpace(parsed.Text) == "" && htmlBody != "" {
+		parsed.Text = htmlToText(htmlBody)
+	}
+	parsed.Text = strings.TrimSpace(strings.ReplaceAll(parsed.Text, "\r\n", "\n"))
+	return parsed, nil
+}
+
+// walkPart collects the first text body, the first HTML body and the attachments of a MIME part
+func (m *InboundEmailMessage) walkPart(header textproto.MIMEHeader, body io.Reader, depth int, htmlBody *string) error {
+	if depth > maxInboundEmailPartDepth {
+		return fmt.Errorf("MIME parts nested too deeply")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid multipart body: %v", err)
+			}
+			if err := m.walkPart(part.Header, part, depth+1, htmlBody); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return fmt.Errorf("invalid %s part: %v", mediaType, err)
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if decoded, err := headerDecoder.DecodeHeader(filename); err == nil {
+		filename = decoded
+	}
+
+	isBody := disposition != "attachment" && filename == ""
+	switch {
+	case isBody && mediaType == "text/plain" && m.Text == "":
+		m.Text = decodeCharset(params["charset"], data)
+	case isBody && mediaType == "text/html" && *htmlBody == "":
+		*htmlBody = decodeCharset(params["charset"], data)
+	case isBody && strings.HasPrefix(mediaType, "text/"):
+		// Further body alternatives are ignored
+	default:
+		if len(m.Attachments) >= maxInboundEmailAttachments {
+			return nil
+		}
+		if filename == "" {
+			filename = "attachment"
+			if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+				filename += exts[0]
+			}
+		}
+		m.Attachments = append(m.Attachments, InboundEmailAttachment{
+			Filename:    filename,
+			ContentType: mediaType,
+			Data:        data,
+		})
+	}
+	return nil
+}
+
+// decodeTransferEncoding undoes the Content-Transfer-Encoding of a part body
+func decodeTransferEncoding(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+// decodeCharset converts text declared in a character set other than UTF-8 or ASCII to UTF-8
+func decodeCharset(charset string, data []byte) string {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8", "us-ascii":
+		return string(data)
+	}
+	reader, err := charsetReader(charset, bytes.NewReader(data))
+	if err != nil {
+		return string(data)
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return string(data)
+	}
+	return string(decoded)
+}
+
+var (
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>|</tr>|</h[1-6]>`)
+	htmlDropPattern  = regexp.MustCompile(`(?is)<(script|style|head)\b.*?</(script|style|head)>`)
+	htmlTagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLinePattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText reduces an HTML body to its text, keeping line breaks
+func htmlToText(body string) string {
+	body = htmlDropPattern.ReplaceAllString(body, "")
+	body = htmlBreakPattern.ReplaceAllString(body, "\n")
+	body = html.UnescapeString(htmlTagPattern.ReplaceAllString(body, ""))
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return blankLinePattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+}
+
+// EmailReport is the vulnerability described by an inbound email
+type EmailReport struct {
+	Title                     string
+	Description               string
+	Severity                  models.VulnerabilitySeverity
+	CVEID                     string
+	CVSSScore                 *float64
+	CVSSVector                string
+	StepsToReproduce          string
+	ImpactAssessment          string
+	MitigationRecommendations string
+}
+
+var (
+	emailSubjectPrefixPattern = regexp.MustCompile(`(?i)^\s*((re|fw|fwd|aw|wg)\s*:\s*)+`)
+	emailCVEPattern           = regexp.MustCompile(`(?i)\bCVE-\d{4}-\d{4,}\b`)
+)
+
+// emailReportSections maps the section headings of a structured report to the section they start
+var emailReportSections = map[string]string{
+	"description":        "description",
+	"summary":            "description",
+	"details":            "description",
+	"steps to reproduce": "steps",
+	"reproduction steps": "steps",
+	"proof of concept":   "steps",
+	"poc":                "steps",
+	"impact":             "impact",
+	"remediation":        "mitigation",
+	"mitigation":         "mitigation",
+	"recommendation":     "mitigation",
+	"recommendations":    "mitigation",
+	"suggested fix":      "mitigation",
+	"fix":                "mitigation",
+}
+
+// ParseEmailReport reads a vulnerability report from an email. Reports may be structured with
+// "Field: value" lines (Title, Severity, CVE, CVSS, CVSS Vector) and section headings
+// (Description, Steps to Reproduce, Impact, Remediation) on lines of their own; unstructured
+// text becomes the description. The title falls back to the subject, the severity to the CVSS
+// score and then to defaultSeverity.
+func ParseEmailReport(subject, text string, defaultSeverity models.VulnerabilitySeverity) EmailReport {
+	report := EmailReport{}
+	sections := map[string][]string{}
+	section := "description"
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		// Stop at the signature and skip quoted replies
+		if trimmed == "--" || line == "-- " {
+			break
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+
+		name, value, hasColon := strings.Cut(trimmed, ":")
+		key := strings.ToLower(strings.Trim(strings.TrimSpace(name), "#*_ "))
+		if !hasColon && emailReportSections[key] != "" && len(trimmed) < 40 {
+			// Heading without a colon, e.g. "## Impact"
+			section = emailReportSections[key]
+			continue
+		}
+		if hasColon {
+			value = strings.TrimSpace(value)
+			if next, ok := emailReportSections[key]; ok {
+				section = next
+				if value != "" {
+					sections[section] = append(sections[section], value)
+				}
+				continue
+			}
+			if report.applyField(key, value) {
+				continue
+			}
+		}
+		sections[section] = append(sections[section], line)
+	}
+
+	report.Description = joinSection(sections["description"])
+	report.StepsToReproduce = joinSection(sections["steps"])
+	report.ImpactAssessment = joinSection(sections["impact"])
+	report.MitigationRecommendations = joinSection(sections["mitigation"])
+
+	if report.Title == "" {
+		report.Title = strings.TrimSpace(emailSubjectPrefixPattern.ReplaceAllString(subject, ""))
+	}
+	if report.Title == "" {
+		report.Title = "Vulnerability report received by email"
+	}
+	report.Title = truncateRunes(report.Title, maxInboundEmailTitleLength)
+	if report.CVEID == "" {
+		report.CVEID = strings.ToUpper(emailCVEPattern.FindString(subject + "\n" + text))
+	}
+	if report.Severity == "" && report.CVSSScore != nil {
+		report.Severity = severityForCVSS(*report.CVSSScore)
+	}
+	if report.Severity == "" {
+		report.Severity = defaultSeverity
+	}
+	return report
+}
+
+// applyField records a "Field: value" line of a structured report and reports whether it was one
+func (r *EmailReport) applyField(key, value string) bool {
+	switch key {
+	case "title", "vulnerability":
+		if value != "" {
+			r.Title = value
+		}
+	case "severity", "risk":
+		r.Severity = parseEmailSeverity(value)
+	case "cve", "cve id", "cve-id":
+		if cve := emailCVEPattern.FindString(value); cve != "" {
+			r.CVEID = strings.ToUpper(cve)
+		}
+	case "cvss", "cvss score":
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			return true
+		}
+		if score, err := strconv.ParseFloat(fields[0], 64); err == nil && score >= 0 && score <= 10 {
+			r.CVSSScore = &score
+		}
+	case "cvss vector":
+		if len(value) <= 100 {
+			r.CVSSVector = value
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// parseEmailSeverity maps a reporter's severity wording to a severity; unknown wording is ignored
+func parseEmailSeverity(value string) models.VulnerabilitySeverity {
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) == 0 {
+		return ""
+	}
+	switch fields[0] {
+	case "critical":
+		return models.SeverityCritical
+	case "high", "important":
+		return models.SeverityHigh
+	case "medium", "moderate":
+		return models.SeverityMedium
+	case "low":
+		return models.SeverityLow
+	case "none", "info", "informational":
+		return models.SeverityNone
+	}
+	return ""
+}
+
+// joinSection joins the lines of a report section, trimming surrounding blank lines
+func joinSection(lines []string) string {
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// sesNotification is the part of an Amazon SES receipt notification delivered through SNS that
+// carries the message
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	Content          string `json:"content"`
+	Receipt          struct {
+		Action struct {
+			Encoding string `json:"encoding"` // UTF8 or BASE64
+		} `json:"action"`
+	} `json:"receipt"`
+}
+
+// snsEnvelope is an Amazon SNS HTTP(S) delivery
+type snsEnvelope struct {
+	Type         string `json:"Type"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+// ParseSESNotification extracts the raw message from an Amazon SNS delivery of an SES receipt
+// notification. Subscription confirmations carry no message; their subscribe URL is returned
+// so an administrator can confirm the subscription.
+func ParseSESNotification(body []byte) (raw []byte, subscribeURL string, err error) {
+	var envelope snsEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, "", fmt.Errorf("%w: invalid SNS notification: %v", ErrInvalidInboundEmail, err)
+	}
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		return nil, envelope.SubscribeURL, nil
+	case "Notification":
+	default:
+		return nil, "", fmt.Errorf("%w: unsupported SNS message type %q", ErrInvalidInboundEmail, envelope.Type)
+	}
+
+	var notification sesNotification
+	if err := json.Unmarshal([]byte(envelope.Message), &notification); err != nil {
+		return nil, "", fmt.Errorf("%w: invalid SES notification: %v", ErrInvalidInboundEmail, err)
+	}
+	if notification.NotificationType != "Received" || notification.Content == "" {
+		return nil, "", fmt.Errorf("%w: SES notification carries no message content", ErrInvalidInboundEmail)
+	}
+	if strings.EqualFold(notification.Receipt.Action.Encoding, "BASE64") {
+		raw, err := base64.StdEncoding.DecodeString(notification.Content)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: invalid base64 content: %v", ErrInvalidInboundEmail, err)
+		}
+		return raw, "", nil
+	}
+	return []byte(notification.Content), "", nil
+}
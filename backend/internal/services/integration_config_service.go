@@ -1,41 +1,31 @@
 package services
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
-	"encoding/base64"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/kms"
 	"gorm.io/gorm"
 )
 
 type IntegrationConfigService struct {
-	db            *gorm.DB
-	encryptionKey []byte // 32-byte key for AES-256
+	db      *gorm.DB
+	keyring *kms.Keyring
 }
 
+// NewIntegrationConfigService creates the service. Secrets are encrypted with the keyring set by
+// SetSecretKeyring; encryptionKey is the secret credentials were encrypted with before envelope
+// encryption, and derives the key used when no keyring is set.
 func NewIntegrationConfigService(db *gorm.DB, encryptionKey string) *IntegrationConfigService {
-	// Ensure encryption key is exactly 32 bytes for AES-256
-	key := []byte(encryptionKey)
-	if len(key) < 32 {
-		// Pad with zeros if too short
-		padded := make([]byte, 32)
-		copy(padded, key)
-		key = padded
-	} else if len(key) > 32 {
-		// Truncate if too long
-		key = key[:32]
-	}
-
 	return &IntegrationConfigService{
-		db:            db,
-		encryptionKey: key,
+		db:      db,
+		keyring: activeSecretKeyring(encryptionKey),
 	}
 }
 
@@ -67,6 +57,14 @@ func (s *IntegrationConfigService) CreateConfig(config *models.IntegrationConfig
 		config.SecretKey = encrypted
 	}
 
+	if config.ClientKeyPEM != "" {
+		encrypted, err := s.encrypt(config.ClientKeyPEM)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt client key: %w", err)
+		}
+		config.ClientKeyPEM = encrypted
+	}
+
 	return s.db.Create(config).Error
 }
 
@@ -95,6 +93,14 @@ func (s *IntegrationConfigService) GetConfig(id uuid.UUID) (*models.IntegrationC
 		config.SecretKey = decrypted
 	}
 
+	if config.ClientKeyPEM != "" {
+		decrypted, err := s.decrypt(config.ClientKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt client key: %w", err)
+		}
+		config.ClientKeyPEM = decrypted
+	}
+
 	return &config, nil
 }
 
@@ -130,6 +136,14 @@ func (s *IntegrationConfigService) UpdateConfig(id uuid.UUID, updates map[string
 		updates["secret_key"] = encrypted
 	}
 
+	if clientKey, ok := updates["client_key_pem"].(string); ok && clientKey != "" {
+		encrypted, err := s.encrypt(clientKey)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt client key: %w", err)
+		}
+		updates["client_key_pem"] = encrypted
+	}
+
 	return s.db.Model(&models.IntegrationConfig{}).Where("id = ?", id).Updates(updates).Error
 }
 
@@ -164,54 +178,85 @@ func (s *IntegrationConfigService) UpdateLastSync(id uuid.UUID) error {
 	return s.db.Model(&models.IntegrationConfig{}).Where("id = ?", id).Update("last_sync_at", now).Error
 }
 
-// encrypt encrypts a string using AES-256-GCM
-func (s *IntegrationConfigService) encrypt(plaintext string) (string, error) {
-	block, err := aes.NewCipher(s.encryptionKey)
-	if err != nil {
-		return "", err
+// GetSeverityMapping returns the severity mapping of an integration, nil when its scanner's
+// severities are used as reported
+func (s *IntegrationConfigService) GetSeverityMapping(id uuid.UUID) (*models.SeverityMapping, error) {
+	var config models.IntegrationConfig
+	if err := s.db.Select("id", "severity_mapping").First(&config, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("integration config not found")
+		}
+		return nil, fmt.Errorf("failed to get integration config: %w", err)
 	}
-
-	gcm, err := cipher.NewGCM(block)
+	mapping, err := config.ParseSeverityMapping()
 	if err != nil {
-		return "", err
-	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to parse severity mapping: %w", err)
 	}
-
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+	return mapping, nil
 }
 
-// decrypt decrypts a string using AES-256-GCM
-func (s *IntegrationConfigService) decrypt(ciphertext string) (string, error) {
-	data, err := base64.StdEncoding.DecodeString(ciphertext)
-	if err != nil {
-		return "", err
+// UpdateSeverityMapping replaces the severity mapping applied to the integration's imports. A
+// mapping without levels or thresholds removes it. Findings already imported keep their severity.
+func (s *IntegrationConfigService) UpdateSeverityMapping(id uuid.UUID, mapping *models.SeverityMapping) (*models.SeverityMapping, error) {
+	if _, err := s.GetSeverityMapping(id); err != nil {
+		return nil, err
 	}
 
-	block, err := aes.NewCipher(s.encryptionKey)
-	if err != nil {
-		return "", err
+	var value interface{}
+	if mapping != nil && (len(mapping.Levels) > 0 || mapping.CVSSThresholds != nil) {
+		if err := ValidateSeverityMapping(mapping); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(mapping)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode severity mapping: %w", err)
+		}
+		value = string(data)
+	} else {
+		mapping = nil
 	}
 
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
+	if err := s.db.Model(&models.IntegrationConfig{}).Where("id = ?", id).
+		Update("severity_mapping", value).Error; err != nil {
+		return nil, fmt.Errorf("failed to update severity mapping: %w", err)
 	}
+	return mapping, nil
+}
 
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		return "", errors.New("ciphertext too short")
+// ValidateSeverityMapping normalizes scanner levels and severities and checks that CVSS
+// thresholds are ordered
+func ValidateSeverityMapping(mapping *models.SeverityMapping) error {
+	levels := make(map[string]models.VulnerabilitySeverity, len(mapping.Levels))
+	for level, severity := range mapping.Levels {
+		level = strings.ToLower(strings.TrimSpace(level))
+		if _, ok := models.DefaultScannerSeverities[level]; !ok {
+			return fmt.Errorf("invalid scanner severity level: %s (must be info, low, medium, high or critical)", level)
+		}
+		severity = models.VulnerabilitySeverity(strings.ToUpper(strings.TrimSpace(string(severity))))
+		switch severity {
+		case models.SeverityCritical, models.SeverityHigh, models.SeverityMedium, models.SeverityLow, models.SeverityNone:
+		default:
+			return fmt.Errorf("invalid severity for %s: %s", level, severity)
+		}
+		levels[level] = severity
 	}
+	mapping.Levels = levels
 
-	nonce, ciphertext := data[:nonceSize], string(data[nonceSize:])
-	plaintext, err := gcm.Open(nil, nonce, []byte(ciphertext), nil)
-	if err != nil {
-		return "", err
+	if t := mapping.CVSSThresholds; t != nil {
+		if t.Low < 0 || t.Medium <= t.Low || t.High <= t.Medium || t.Critical <= t.High || t.Critical > 10 {
+			return fmt.Errorf("invalid cvss thresholds: must satisfy 0 <= low < medium < high < critical <= 10")
+		}
 	}
+	return nil
+}
 
-	return string(plaintext), nil
+// encrypt encrypts a secret under the keyring's active key
+func (s *IntegrationConfigService) encrypt(plaintext string) (string, error) {
+	return s.keyring.Encrypt(context.Background(), plaintext)
+}
+
+// decrypt decrypts a secret written under any key of the keyring, including secrets encrypted
+// before envelope encryption
+func (s *IntegrationConfigService) decrypt(ciphertext string) (string, error) {
+	return s.keyring.Decrypt(context.Background(), ciphertext)
 }
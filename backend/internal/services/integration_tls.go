@@ -0,0 +1,64 @@
+package services
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cyops/cyops-backend/internal/models"
+)
+
+// IntegrationTLSConfig builds the TLS configuration used to reach an integration: the system trust
+// store plus the integration's CA certificates, its client certificate for mutual TLS, and
+// certificate verification unless it was turned off for the integration
+func IntegrationTLSConfig(config *models.IntegrationConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.TLSSkipVerify,
+	}
+
+	if strings.TrimSpace(config.CACertPEM) != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(config.CACertPEM)) {
+			return nil, fmt.Errorf("invalid TLS settings: CA certificate is not a PEM encoded certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	hasCert := strings.TrimSpace(config.ClientCertPEM) != ""
+	hasKey := strings.TrimSpace(config.ClientKeyPEM) != ""
+	if hasCert != hasKey {
+		return nil, fmt.Errorf("invalid TLS settings: client certificate and client key must be set together")
+	}
+	if hasCert {
+		cert, err := tls.X509KeyPair([]byte(config.ClientCertPEM), []byte(config.ClientKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS settings: client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// DescribeTLSError explains certificate errors met while connecting to an integration in terms of
+// the settings that resolve them, and returns other errors unchanged
+func DescribeTLSError(err error) error {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return fmt.Errorf("server certificate is not signed by a trusted CA; add the issuing CA certificate or turn off TLS verification: %w", err)
+	case errors.As(err, &hostname):
+		return fmt.Errorf("server certificate does not match the base URL host: %w", err)
+	case errors.As(err, &invalid):
+		return fmt.Errorf("server certificate is invalid: %w", err)
+	}
+	return err
+}
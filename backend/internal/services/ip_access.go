@@ -0,0 +1,215 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// maxIPAccessEntries bounds the CIDRs of one allowlist or denylist
+const maxIPAccessEntries = 200
+
+// adminIPAccessTTL is how long each instance reuses the loaded admin lists; changes made on
+// another replica take effect within this delay
+const adminIPAccessTTL = 30 * time.Second
+
+// IPAccessList restricts the client addresses of requests. Deny takes precedence over Allow;
+// an empty Allow admits every address that is not denied.
+type IPAccessList struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// ParseCIDRList validates and normalizes CIDRs; a plain address is a single-host network
+func ParseCIDRList(entries []string) ([]string, error) {
+	if len(entries) > maxIPAccessEntries {
+		return nil, fmt.Errorf("invalid CIDR list: at most %d entries are allowed", maxIPAccessEntries)
+	}
+	normalized := make([]string, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		network, err := parseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q", entry)
+		}
+		cidr := network.String()
+		if !seen[cidr] {
+			seen[cidr] = true
+			normalized = append(normalized, cidr)
+		}
+	}
+	return normalized, nil
+}
+
+// parseCIDR parses a CIDR or a plain IPv4 or IPv6 address
+func parseCIDR(entry string) (*net.IPNet, error) {
+	if strings.Contains(entry, "/") {
+		_, network, err := net.ParseCIDR(entry)
+		return network, err
+	}
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, errors.New("invalid address")
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// CIDRListContains reports whether ip is in one of the CIDRs; entries that do not parse are
+// ignored
+func CIDRListContains(cidrs []string, ip string) bool {
+	addr := net.ParseIP(strings.TrimSpace(ip))
+	if addr == nil {
+		return false
+	}
+	for _, entry := range cidrs {
+		network, err := parseCIDR(entry)
+		if err == nil && network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseIPAccessList validates and normalizes the JSON value of an IP access setting
+func ParseIPAccessList(value string) (*IPAccessList, error) {
+	var list IPAccessList
+	if strings.TrimSpace(value) != "" {
+		if err := json.Unmarshal([]byte(value), &list); err != nil {
+			return nil, fmt.Errorf("invalid IP access list: %w", err)
+		}
+	}
+	return list.Normalize()
+}
+
+// Normalize validates both lists, returning them in canonical form
+func (l IPAccessList) Normalize() (*IPAccessList, error) {
+	allow, err := ParseCIDRList(l.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := ParseCIDRList(l.Deny)
+	if err != nil {
+		return nil, err
+	}
+	return &IPAccessList{Allow: allow, Deny: deny}, nil
+}
+
+// Allows reports whether a request from ip may proceed
+func (l *IPAccessList) Allows(ip string) bool {
+	if l == nil {
+		return true
+	}
+	if CIDRListContains(l.Deny, ip) {
+		return false
+	}
+	return len(l.Allow) == 0 || CIDRListContains(l.Allow, ip)
+}
+
+// LoadAdminIPAccess returns the lists that apply to the admin routes; without the setting
+// every address is admitted
+func LoadAdminIPAccess(db *gorm.DB) (*IPAccessList, error) {
+	var setting models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingAdminIPAccess)).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &IPAccessList{Allow: []string{}, Deny: []string{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load admin IP access list: %w", err)
+	}
+	return ParseIPAccessList(setting.Value)
+}
+
+var (
+	adminIPAccessMu       sync.Mutex
+	adminIPAccess         *IPAccessList
+	adminIPAccessLoadedAt time.Time
+)
+
+// CurrentAdminIPAccess returns the admin lists, reloading them from the database at most every
+// 30 seconds. When they cannot be loaded the last known lists stay in force.
+func CurrentAdminIPAccess() *IPAccessList {
+	adminIPAccessMu.Lock()
+	defer adminIPAccessMu.Unlock()
+
+	if adminIPAccess != nil && time.Since(adminIPAccessLoadedAt) < adminIPAccessTTL {
+		return adminIPAccess
+	}
+	db := database.GetDB()
+	if db == nil {
+		return &IPAccessList{}
+	}
+
+	list, err := LoadAdminIPAccess(db)
+	if err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to load admin IP access list")
+		if adminIPAccess == nil {
+			adminIPAccess = &IPAccessList{}
+		}
+		list = adminIPAccess
+	}
+	adminIPAccess = list
+	adminIPAccessLoadedAt = time.Now()
+	return list
+}
+
+// invalidateAdminIPAccess makes this instance reload the admin lists on the next request
+func invalidateAdminIPAccess() {
+	adminIPAccessMu.Lock()
+	defer adminIPAccessMu.Unlock()
+	adminIPAccessLoadedAt = time.Time{}
+}
+
+// BlockedIPAttempt describes a request refused because of its client address
+type BlockedIPAttempt struct {
+	UserID    *uuid.UUID
+	APIKeyID  *uuid.UUID
+	Scope     string // "admin" or "api_key"
+	Method    string
+	Path      string
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+// RecordBlockedIP writes an audit event for a request refused by an IP access list
+func RecordBlockedIP(attempt BlockedIPAttempt) {
+	db := database.GetDB()
+	if db == nil {
+		return
+	}
+
+	metadata := map[string]interface{}{
+		"scope":  attempt.Scope,
+		"method": attempt.Method,
+		"path":   attempt.Path,
+	}
+	if attempt.APIKeyID != nil {
+		metadata["api_key_id"] = attempt.APIKeyID.String()
+	}
+
+	event := models.NewFailedAuthEvent(attempt.UserID, models.EventTypeIPBlocked, attempt.IPAddress, attempt.UserAgent, "client address not allowed")
+	event.RequestID = attempt.RequestID
+	if encoded, err := json.Marshal(metadata); err == nil {
+		event.Metadata = string(encoded)
+	}
+	if err := db.Create(event).Error; err != nil {
+		utils.Logger.Error().Err(err).Str("ip", attempt.IPAddress).Msg("Failed to record blocked IP attempt")
+	}
+}
@@ -0,0 +1,414 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MetricsBackfillDays is how far back missing daily snapshots are rebuilt from history
+const MetricsBackfillDays = 400
+
+// resolvedVulnerabilityStatuses are the statuses counted as resolved in trend metrics
+var resolvedVulnerabilityStatuses = []models.VulnerabilityStatus{
+	models.StatusResolved,
+	models.StatusVerified,
+	models.StatusClosed,
+}
+
+// snapshotFlowColumns are the columns recomputed when a day's snapshot is refreshed
+var snapshotFlowColumns = []string{
+	"new_vulnerabilities", "new_critical", "resolved_vulnerabilities", "new_findings", "mttr_hours", "updated_at",
+}
+
+// snapshotStockColumns are the point-in-time columns only captured for the current day
+var snapshotStockColumns = []string{
+	"critical_count", "high_count", "medium_count", "low_count", "none_count",
+	"open_count", "in_progress_count", "resolved_count", "verified_count", "closed_count", "false_positive_count",
+	"total_assets", "active_assets", "backfilled",
+}
+
+// MetricsSnapshotService maintains the daily dashboard metrics snapshot table
+type MetricsSnapshotService struct {
+	db *gorm.DB
+}
+
+// NewMetricsSnapshotService creates a new metrics snapshot service
+func NewMetricsSnapshotService(db *gorm.DB) *MetricsSnapshotService {
+	return &MetricsSnapshotService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *MetricsSnapshotService) WithContext(ctx context.Context) *MetricsSnapshotService {
+	return &MetricsSnapshotService{db: s.db.WithContext(ctx)}
+}
+
+// MetricsFlowTotals sums flow metrics over a range of daily snapshots
+type MetricsFlowTotals struct {
+	NewVulnerabilities      int64   `json:"new_vulnerabilities"`
+	NewCritical             int64   `json:"new_critical"`
+	ResolvedVulnerabilities int64   `json:"resolved_vulnerabilities"`
+	NewFindings             int64   `json:"new_findings"`
+	MTTRHours               float64 `json:"mttr_hours"`
+}
+
+// utcDay truncates a time to the start of its UTC day
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// dailyCount is a per-day aggregate row
+type dailyCount struct {
+	Day      time.Time
+	Count    int64
+	Critical int64
+	AvgHours float64
+}
+
+// computeDailyFlows aggregates an organization's flow metrics per UTC day for [start, end)
+func (s *MetricsSnapshotService) computeDailyFlows(orgID uuid.UUID, start, end time.Time) (map[time.Time]*models.DailyMetricsSnapshot, error) {
+	snapshots := map[time.Time]*models.DailyMetricsSnapshot{}
+	snapshotFor := func(day time.Time) *models.DailyMetricsSnapshot {
+		day = utcDay(day)
+		if snapshot, ok := snapshots[day]; ok {
+			return snapshot
+		}
+		snapshot := &models.DailyMetricsSnapshot{OrgID: &orgID, SnapshotDate: day}
+		snapshots[day] = snapshot
+		return snapshot
+	}
+
+	var created []dailyCount
+	if err := s.db.Raw(`
+		SELECT date_trunc('day', created_at AT TIME ZONE 'UTC') AS day,
+			COUNT(*) AS count,
+			COUNT(*) FILTER (WHERE severity = ?) AS critical
+		FROM vulnerabilities
+		WHERE deleted_at IS NULL AND org_id = ? AND created_at >= ? AND created_at < ?
+		GROUP BY 1`, models.SeverityCritical, orgID, start, end).Scan(&created).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate new vulnerabilities: %w", err)
+	}
+	for _, row := range created {
+		snapshot := snapshotFor(row.Day)
+		snapshot.NewVulnerabilities = row.Count
+		snapshot.NewCritical = row.Critical
+	}
+
+	var resolved []dailyCount
+	if err := s.db.Raw(`
+		SELECT date_trunc('day', updated_at AT TIME ZONE 'UTC') AS day,
+			COUNT(*) AS count,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (updated_at - created_at)) / 3600), 0) AS avg_hours
+		FROM vulnerabilities
+		WHERE deleted_at IS NULL AND org_id = ? AND status IN ? AND updated_at >= ? AND updated_at < ?
+		GROUP BY 1`, orgID, resolvedVulnerabilityStatuses, start, end).Scan(&resolved).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate resolved vulnerabilities: %w", err)
+	}
+	for _, row := range resolved {
+		snapshot := snapshotFor(row.Day)
+		snapshot.ResolvedVulnerabilities = row.Count
+		snapshot.MTTRHours = row.AvgHours
+	}
+
+	var findings []dailyCount
+	if err := s.db.Raw(`
+		SELECT date_trunc('day', created_at AT TIME ZONE 'UTC') AS day, COUNT(*) AS count
+		FROM vulnerability_findings
+		WHERE org_id = ? AND created_at >= ? AND created_at < ?
+		GROUP BY 1`, orgID, start, end).Scan(&findings).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate new findings: %w", err)
+	}
+	for _, row := range findings {
+		snapshotFor(row.Day).NewFindings = row.Count
+	}
+
+	// Days without activity still get a row so coverage checks can rely on row counts
+	for day := utcDay(start); day.Before(end); day = day.AddDate(0, 0, 1) {
+		snapshotFor(day)
+	}
+
+	return snapshots, nil
+}
+
+// computeStock fills the point-in-time counts on a snapshot
+func (s *MetricsSnapshotService) computeStock(snapshot *models.DailyMetricsSnapshot) error {
+	var counts struct {
+		CriticalCount      int64
+		HighCount          int64
+		MediumCount        int64
+		LowCount           int64
+		NoneCount          int64
+		OpenCount          int64
+		InProgressCount    int64
+		ResolvedCount      int64
+		VerifiedCount      int64
+		ClosedCount        int64
+		FalsePositiveCount int64
+	}
+	if err := s.db.Model(&models.Vulnerability{}).Select(`
+		COUNT(*) FILTER (WHERE severity = 'CRITICAL') AS critical_count,
+		COUNT(*) FILTER (WHERE severity = 'HIGH') AS high_count,
+		COUNT(*) FILTER (WHERE severity = 'MEDIUM') AS medium_count,
+		COUNT(*) FILTER (WHERE severity = 'LOW') AS low_count,
+		COUNT(*) FILTER (WHERE severity = 'NONE') AS none_count,
+		COUNT(*) FILTER (WHERE status = 'OPEN') AS open_count,
+		COUNT(*) FILTER (WHERE status = 'IN_PROGRESS') AS in_progress_count,
+		COUNT(*) FILTER (WHERE status = 'RESOLVED') AS resolved_count,
+		COUNT(*) FILTER (WHERE status = 'VERIFIED') AS verified_count,
+		COUNT(*) FILTER (WHERE status = 'CLOSED') AS closed_count,
+		COUNT(*) FILTER (WHERE status = 'FALSE_POSITIVE') AS false_positive_count`).
+		Scan(&counts).Error; err != nil {
+		return fmt.Errorf("failed to count vulnerabilities: %w", err)
+	}
+
+	snapshot.CriticalCount = counts.CriticalCount
+	snapshot.HighCount = counts.HighCount
+	snapshot.MediumCount = counts.MediumCount
+	snapshot.LowCount = counts.LowCount
+	snapshot.NoneCount = counts.NoneCount
+	snapshot.OpenCount = counts.OpenCount
+	snapshot.InProgressCount = counts.InProgressCount
+	snapshot.ResolvedCount = counts.ResolvedCount
+	snapshot.VerifiedCount = counts.VerifiedCount
+	snapshot.ClosedCount = counts.ClosedCount
+	snapshot.FalsePositiveCount = counts.FalsePositiveCount
+
+	if err := s.db.Model(&models.AffectedSystem{}).Count(&snapshot.TotalAssets).Error; err != nil {
+		return fmt.Errorf("failed to count assets: %w", err)
+	}
+	if err := s.db.Model(&models.AffectedSystem{}).
+		Where("status = ?", models.StatusActive).
+		Count(&snapshot.ActiveAssets).Error; err != nil {
+		return fmt.Errorf("failed to count active assets: %w", err)
+	}
+	return nil
+}
+
+// RefreshSnapshots recomputes today's and yesterday's snapshots for every active organization
+// and backfills any missing days within MetricsBackfillDays. Past days that already have a
+// snapshot are left as recorded.
+func (s *MetricsSnapshotService) RefreshSnapshots(now time.Time) error {
+	var orgIDs []uuid.UUID
+	if err := s.db.Model(&models.Organization{}).Where("active = ?", true).Pluck("id", &orgIDs).Error; err != nil {
+		return fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	for _, orgID := range orgIDs {
+		// Scoping the context filters the ORM queries and stamps new rows with the organization
+		scoped := s.WithContext(tenant.WithOrg(s.db.Statement.Context, orgID))
+		if err := scoped.refreshOrganization(orgID, now); err != nil {
+			return fmt.Errorf("organization %s: %w", orgID, err)
+		}
+	}
+	return nil
+}
+
+// refreshOrganization refreshes one organization's snapshots; s must be scoped to orgID
+func (s *MetricsSnapshotService) refreshOrganization(orgID uuid.UUID, now time.Time) error {
+	today := utcDay(now)
+	yesterday := today.AddDate(0, 0, -1)
+	backfillStart := today.AddDate(0, 0, -MetricsBackfillDays)
+
+	var existing []time.Time
+	if err := s.db.Model(&models.DailyMetricsSnapshot{}).
+		Where("snapshot_date >= ?", backfillStart).
+		Pluck("snapshot_date", &existing).Error; err != nil {
+		return fmt.Errorf("failed to load existing snapshots: %w", err)
+	}
+	have := make(map[time.Time]bool, len(existing))
+	for _, day := range existing {
+		have[utcDay(day)] = true
+	}
+
+	// Only aggregate from the earliest day that needs work
+	start := yesterday
+	for day := backfillStart; day.Before(yesterday); day = day.AddDate(0, 0, 1) {
+		if !have[day] {
+			start = day
+			break
+		}
+	}
+
+	flows, err := s.computeDailyFlows(orgID, start, today.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	backfill := []models.DailyMetricsSnapshot{}
+	for day, snapshot := range flows {
+		if day.Before(yesterday) && !have[day] {
+			snapshot.Backfilled = true
+			backfill = append(backfill, *snapshot)
+		}
+	}
+	if len(backfill) > 0 {
+		if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&backfill, 200).Error; err != nil {
+			return fmt.Errorf("failed to backfill snapshots: %w", err)
+		}
+		utils.Logger.Info().Int("days", len(backfill)).Msg("Backfilled daily metrics snapshots")
+	}
+
+	// Yesterday: finalize flow metrics, keep whatever point-in-time counts were captured
+	if snapshot, ok := flows[yesterday]; ok {
+		snapshot.Backfilled = !have[yesterday]
+		if err := s.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "org_id"}, {Name: "snapshot_date"}},
+			DoUpdates: clause.AssignmentColumns(snapshotFlowColumns),
+		}).Create(snapshot).Error; err != nil {
+			return fmt.Errorf("failed to refresh yesterday's snapshot: %w", err)
+		}
+	}
+
+	// Today: flow metrics so far plus current point-in-time counts
+	snapshot := flows[today]
+	if err := s.computeStock(snapshot); err != nil {
+		return err
+	}
+	snapshot.UpdatedAt = time.Now()
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "org_id"}, {Name: "snapshot_date"}},
+		DoUpdates: clause.AssignmentColumns(append(append([]string{}, snapshotFlowColumns...), snapshotStockColumns...)),
+	}).Create(snapshot).Error; err != nil {
+		return fmt.Errorf("failed to refresh today's snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// SumFlows totals flow metrics for the days after start's day through end's day, for the
+// organization in the service's context (or all organizations without one). The boolean is
+// false when any day in the range has no snapshot yet (callers should fall back to live queries).
+func (s *MetricsSnapshotService) SumFlows(start, end time.Time) (*MetricsFlowTotals, bool, error) {
+	firstDay := utcDay(start).AddDate(0, 0, 1)
+	lastDay := utcDay(end)
+	if lastDay.Before(firstDay) {
+		return &MetricsFlowTotals{}, true, nil
+	}
+	expectedDays := int64(lastDay.Sub(firstDay).Hours()/24) + 1
+
+	var row struct {
+		Days                    int64
+		NewVulnerabilities      int64
+		NewCritical             int64
+		ResolvedVulnerabilities int64
+		NewFindings             int64
+		ResolvedHours           float64
+	}
+	if err := s.db.Model(&models.DailyMetricsSnapshot{}).
+		Select(`COUNT(DISTINCT snapshot_date) AS days,
+			COALESCE(SUM(new_vulnerabilities), 0) AS new_vulnerabilities,
+			COALESCE(SUM(new_critical), 0) AS new_critical,
+			COALESCE(SUM(resolved_vulnerabilities), 0) AS resolved_vulnerabilities,
+			COALESCE(SUM(new_findings), 0) AS new_findings,
+			COALESCE(SUM(mttr_hours * resolved_vulnerabilities), 0) AS resolved_hours`).
+		Where("snapshot_date BETWEEN ? AND ?", firstDay, lastDay).
+		Scan(&row).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to sum snapshots: %w", err)
+	}
+	if row.Days < expectedDays {
+		return nil, false, nil
+	}
+
+	totals := &MetricsFlowTotals{
+		NewVulnerabilities:      row.NewVulnerabilities,
+		NewCritical:             row.NewCritical,
+		ResolvedVulnerabilities: row.ResolvedVulnerabilities,
+		NewFindings:             row.NewFindings,
+	}
+	if row.ResolvedVulnerabilities > 0 {
+		totals.MTTRHours = row.ResolvedHours / float64(row.ResolvedVulnerabilities)
+	}
+	return totals, true, nil
+}
+
+// MetricsBacklog is the point-in-time vulnerability backlog recorded by a snapshot
+type MetricsBacklog struct {
+	AsOf            time.Time `json:"as_of"`
+	CriticalCount   int64     `json:"critical_count"`
+	HighCount       int64     `json:"high_count"`
+	MediumCount     int64     `json:"medium_count"`
+	LowCount        int64     `json:"low_count"`
+	OpenCount       int64     `json:"open_count"`
+	InProgressCount int64     `json:"in_progress_count"`
+}
+
+// SumPeriod totals flow metrics over the snapshots recorded for the UTC days firstDay through
+// lastDay, whichever days have one, and returns the number of days covered. The backlog is
+// that of the last day in the range with point-in-time counts (nil when only backfilled days
+// were recorded).
+func (s *MetricsSnapshotService) SumPeriod(firstDay, lastDay time.Time) (*MetricsFlowTotals, int64, *MetricsBacklog, error) {
+	firstDay, lastDay = utcDay(firstDay), utcDay(lastDay)
+
+	var row struct {
+		Days                    int64
+		NewVulnerabilities      int64
+		NewCritical             int64
+		ResolvedVulnerabilities int64
+		NewFindings             int64
+		ResolvedHours           float64
+	}
+	if err := s.db.Model(&models.DailyMetricsSnapshot{}).
+		Select(`COUNT(DISTINCT snapshot_date) AS days,
+			COALESCE(SUM(new_vulnerabilities), 0) AS new_vulnerabilities,
+			COALESCE(SUM(new_critical), 0) AS new_critical,
+			COALESCE(SUM(resolved_vulnerabilities), 0) AS resolved_vulnerabilities,
+			COALESCE(SUM(new_findings), 0) AS new_findings,
+			COALESCE(SUM(mttr_hours * resolved_vulnerabilities), 0) AS resolved_hours`).
+		Where("snapshot_date BETWEEN ? AND ?", firstDay, lastDay).
+		Scan(&row).Error; err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to sum snapshots: %w", err)
+	}
+	totals := &MetricsFlowTotals{
+		NewVulnerabilities:      row.NewVulnerabilities,
+		NewCritical:             row.NewCritical,
+		ResolvedVulnerabilities: row.ResolvedVulnerabilities,
+		NewFindings:             row.NewFindings,
+	}
+	if row.ResolvedVulnerabilities > 0 {
+		totals.MTTRHours = row.ResolvedHours / float64(row.ResolvedVulnerabilities)
+	}
+
+	// Organizations are summed on the latest day any of them recorded point-in-time counts
+	var backlogs []MetricsBacklog
+	latest := s.db.Model(&models.DailyMetricsSnapshot{}).
+		Select("MAX(snapshot_date)").
+		Where("backfilled = ? AND snapshot_date BETWEEN ? AND ?", false, firstDay, lastDay)
+	if err := s.db.Model(&models.DailyMetricsSnapshot{}).
+		Select(`snapshot_date AS as_of,
+			SUM(critical_count) AS critical_count,
+			SUM(high_count) AS high_count,
+			SUM(medium_count) AS medium_count,
+			SUM(low_count) AS low_count,
+			SUM(open_count) AS open_count,
+			SUM(in_progress_count) AS in_progress_count`).
+		Where("backfilled = ? AND snapshot_date = (?)", false, latest).
+		Group("snapshot_date").
+		Scan(&backlogs).Error; err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to load snapshot backlog: %w", err)
+	}
+	var backlog *MetricsBacklog
+	if len(backlogs) > 0 {
+		backlog = &backlogs[0]
+	}
+	return totals, row.Days, backlog, nil
+}
+
+// ListSnapshots returns the daily snapshots for the last n days, oldest first
+func (s *MetricsSnapshotService) ListSnapshots(days int) ([]models.DailyMetricsSnapshot, error) {
+	var snapshots []models.DailyMetricsSnapshot
+	since := utcDay(time.Now()).AddDate(0, 0, -(days - 1))
+	if err := s.db.Where("snapshot_date >= ?", since).
+		Order("snapshot_date ASC").
+		Find(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	return snapshots, nil
+}
@@ -2,20 +2,39 @@ package services
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/faultinject"
+	"github.com/cyops/cyops-backend/pkg/shutdown"
+	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
+// Nessus folder types
+const (
+	NessusFolderMain   = "main"  // My Scans
+	NessusFolderTrash  = "trash" // Deleted scans
+	NessusFolderCustom = "custom"
+)
+
+// NessusScanTypeAgent is the type of scans run by Nessus Agents (Nessus Manager)
+const NessusScanTypeAgent = "agent"
+
 // NessusAPIService handles interactions with Nessus API
 type NessusAPIService struct {
 	configService *IntegrationConfigService
 	parser        *NessusParserService
+	ctx           context.Context
 }
 
 // NewNessusAPIService creates a new Nessus API service
@@ -23,18 +42,139 @@ func NewNessusAPIService(configService *IntegrationConfigService) *NessusAPIServ
 	return &NessusAPIService{
 		configService: configService,
 		parser:        NewNessusParserService(),
+		ctx:           context.Background(),
+	}
+}
+
+// WithContext returns a copy of the service whose requests and waits are cancelled with ctx
+func (s *NessusAPIService) WithContext(ctx context.Context) *NessusAPIService {
+	copied := *s
+	copied.ctx = ctx
+	return &copied
+}
+
+// wait pauses for d unless the service's context is cancelled first
+func (s *NessusAPIService) wait(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	case <-timer.C:
+		return nil
 	}
 }
 
-// createHTTPClient creates an HTTP client that skips TLS verification
-// This is common for Nessus installations with self-signed certificates
-func (s *NessusAPIService) createHTTPClient(timeout time.Duration) *http.Client {
+// Modes of a Nessus integration, set as "mode" in the integration's config
+const (
+	NessusModeOnPrem    = "nessus"     // Nessus Professional or Nessus Manager
+	NessusModeTenableIO = "tenable_io" // Tenable.io (Tenable Vulnerability Management)
+)
+
+// TenableIOBaseURL is the API of Tenable.io, used when a Tenable.io integration has no base URL
+const TenableIOBaseURL = "https://cloud.tenable.com"
+
+// nessusMaxRetries bounds how often a rate limited request is retried
+const nessusMaxRetries = 5
+
+// Scan exports are polled with a growing interval up to nessusExportMaxPollInterval, and given
+// up on after nessusExportTimeout
+const (
+	nessusExportMaxPollInterval = 5 * time.Second
+	nessusExportTimeout         = 5 * time.Minute
+)
+
+// NessusMode returns the mode of a Nessus integration, on-prem Nessus unless configured otherwise
+func NessusMode(config *models.IntegrationConfig) string {
+	if mode, ok := config.Config["mode"].(string); ok && mode != "" {
+		return mode
+	}
+	return NessusModeOnPrem
+}
+
+// NormalizeNessusConfig validates the mode of a Nessus integration and defaults the base URL of
+// Tenable.io integrations to the cloud API
+func NormalizeNessusConfig(config *models.IntegrationConfig) error {
+	switch NessusMode(config) {
+	case NessusModeOnPrem:
+	case NessusModeTenableIO:
+		if config.BaseURL == "" {
+			config.BaseURL = TenableIOBaseURL
+		}
+		u, err := url.Parse(config.BaseURL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("invalid base URL: Tenable.io is only reachable over https")
+		}
+		if config.TLSSkipVerify {
+			return fmt.Errorf("invalid TLS settings: certificate verification cannot be turned off for Tenable.io")
+		}
+	default:
+		return fmt.Errorf("invalid mode %q: must be %s or %s", NessusMode(config), NessusModeOnPrem, NessusModeTenableIO)
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	return nil
+}
+
+// RetryAfterDelay returns how long to wait before retrying a rate limited request, honoring the
+// Retry-After header (in seconds) and otherwise backing off exponentially up to a minute
+func RetryAfterDelay(retryAfter string, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(strings.TrimSpace(retryAfter)); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	delay := time.Second << attempt
+	if delay <= 0 || delay > time.Minute {
+		delay = time.Minute
+	}
+	return delay
+}
+
+// do sends a request authenticated with the integration's API keys. Tenable.io limits the
+// request rate of each API key and answers 429 (or 503 under load) with a Retry-After header, so
+// those responses are waited out and retried.
+func (s *NessusAPIService) do(client *http.Client, config *models.IntegrationConfig, req *http.Request) (*http.Response, error) {
+	req.Header.Set("X-ApiKeys", fmt.Sprintf("accessKey=%s; secretKey=%s", config.AccessKey, config.SecretKey))
+	if NessusMode(config) == NessusModeTenableIO {
+		// Tenable asks integrations to identify themselves so their API usage can be attributed
+		req.Header.Set("User-Agent", "Integration/1.0 (CYOPS; CYOPS; Build/1.0)")
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) || attempt >= nessusMaxRetries {
+			return resp, nil
+		}
+		resp.Body.Close()
+		if err := s.wait(RetryAfterDelay(resp.Header.Get("Retry-After"), attempt)); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// createHTTPClient creates an HTTP client using the integration's TLS settings. Nessus
+// installations commonly use self-signed certificates, which are trusted by adding their CA to
+// the integration or, failing that, by turning off verification.
+func (s *NessusAPIService) createHTTPClient(config *models.IntegrationConfig, timeout time.Duration) (*http.Client, error) {
+	tlsConfig, err := IntegrationTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
 	return &http.Client{
 		Timeout: timeout,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-	}
+		Transport: faultinject.WrapTransport(&http.Transport{
+			TLSClientConfig: tlsConfig,
+		}),
+	}, nil
 }
 
 // NessusScan represents a scan in Nessus
@@ -46,6 +186,7 @@ type NessusScan struct {
 	CreationDate     int64  `json:"creation_date"`
 	LastModification int64  `json:"last_modification_date"`
 	FolderID         int    `json:"folder_id"`
+	Type             string `json:"type"` // e.g. remote, local or agent
 	ReadOnly         bool   `json:"read"`
 	Shared           bool   `json:"shared"`
 	UserPermissions  int    `json:"user_permissions"`
@@ -54,7 +195,34 @@ type NessusScan struct {
 
 // NessusScanList represents the response from listing scans
 type NessusScanList struct {
-	Scans []NessusScan `json:"scans"`
+	Folders []NessusFolder `json:"folders"`
+	Scans   []NessusScan   `json:"scans"`
+}
+
+// NessusFolder represents a folder scans are organized in
+type NessusFolder struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"` // main, trash or custom
+	DefaultTag  int    `json:"default_tag"`
+	Custom      int    `json:"custom"`
+	UnreadCount int    `json:"unread_count"`
+}
+
+// NessusFolderList represents the response from listing folders
+type NessusFolderList struct {
+	Folders []NessusFolder `json:"folders"`
+}
+
+// NessusScanHistory represents one run of a scan. Agent scans report results per run, and the
+// latest run may still be collecting results from agents.
+type NessusScanHistory struct {
+	HistoryID        int    `json:"history_id"`
+	UUID             string `json:"uuid"`
+	Status           string `json:"status"`
+	Type             string `json:"type"`
+	CreationDate     int64  `json:"creation_date"`
+	LastModification int64  `json:"last_modification_date"`
 }
 
 // NessusScanDetail represents detailed information about a scan
@@ -62,6 +230,24 @@ type NessusScanDetail struct {
 	Info NessusScanInfo `json:"info"`
 	Hosts []NessusScanHost `json:"hosts"`
 	Vulnerabilities []NessusScanVulnerability `json:"vulnerabilities"`
+	History []NessusScanHistory `json:"history"`
+}
+
+// IsAgentScan reports whether the scan is run by Nessus Agents
+func (d *NessusScanDetail) IsAgentScan() bool {
+	return d.Info.ScanType == NessusScanTypeAgent || d.Info.AgentTargets != nil
+}
+
+// LatestCompletedRun returns the most recent completed run of the scan, or nil if none completed
+func (d *NessusScanDetail) LatestCompletedRun() *NessusScanHistory {
+	var latest *NessusScanHistory
+	for i := range d.History {
+		run := &d.History[i]
+		if run.Status == "completed" && (latest == nil || run.LastModification > latest.LastModification) {
+			latest = run
+		}
+	}
+	return latest
 }
 
 // NessusScanInfo contains scan metadata
@@ -74,6 +260,12 @@ type NessusScanInfo struct {
 	Targets          string `json:"targets"`
 	HostCount        int    `json:"hostcount"`
 	VulnCount        int    `json:"vulnerabilitycount"`
+	ScanType         string `json:"scan_type"`
+	FolderID         int    `json:"folder_id"`
+	AgentTargets     []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"agent_targets,omitempty"` // Agent groups targeted by agent scans
 }
 
 // NessusScanHost represents a host in a scan
@@ -115,20 +307,26 @@ func (s *NessusAPIService) TestConnection(configID uuid.UUID) error {
 		return fmt.Errorf("failed to get config: %w", err)
 	}
 
-	// Try to list scans - if successful, connection is good
-	client := s.createHTTPClient(10 * time.Second)
-	req, err := http.NewRequest("GET", config.BaseURL+"/scans", nil)
+	// Try to list scans - if successful, connection is good. Tenable.io containers can hold
+	// thousands of scans, so the session of the API key is checked instead.
+	path := "/scans"
+	if NessusMode(config) == NessusModeTenableIO {
+		path = "/session"
+	}
+	client, err := s.createHTTPClient(config, 10 * time.Second)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(s.ctx, "GET", config.BaseURL+path, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Set authentication headers
-	req.Header.Set("X-ApiKeys", fmt.Sprintf("accessKey=%s; secretKey=%s", config.AccessKey, config.SecretKey))
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(req)
+	resp, err := s.do(client, config, req)
 	if err != nil {
-		return fmt.Errorf("connection failed: %w", err)
+		return fmt.Errorf("connection failed: %w", DescribeTLSError(err))
 	}
 	defer resp.Body.Close()
 
@@ -140,100 +338,149 @@ func (s *NessusAPIService) TestConnection(configID uuid.UUID) error {
 	return nil
 }
 
-// ListScans retrieves all available scans from Nessus
-func (s *NessusAPIService) ListScans(configID uuid.UUID) ([]NessusScan, error) {
-	config, err := s.configService.GetConfig(configID)
+// getJSON sends an authenticated GET request to the Nessus API and decodes the response into out
+func (s *NessusAPIService) getJSON(config *models.IntegrationConfig, path string, out interface{}) error {
+	client, err := s.createHTTPClient(config, 30 * time.Second)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get config: %w", err)
+		return err
 	}
-
-	client := s.createHTTPClient(30 * time.Second)
-	req, err := http.NewRequest("GET", config.BaseURL+"/scans", nil)
+	req, err := http.NewRequestWithContext(s.ctx, "GET", config.BaseURL+path, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("X-ApiKeys", fmt.Sprintf("accessKey=%s; secretKey=%s", config.AccessKey, config.SecretKey))
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(req)
+	resp, err := s.do(client, config, req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// ListScans retrieves all available scans from Nessus, including those in the trash
+func (s *NessusAPIService) ListScans(configID uuid.UUID) ([]NessusScan, error) {
+	return s.ListFolderScans(configID, 0)
+}
+
+// ListFolderScans retrieves the scans in a folder (all folders when folderID is 0)
+func (s *NessusAPIService) ListFolderScans(configID uuid.UUID, folderID int) ([]NessusScan, error) {
+	config, err := s.configService.GetConfig(configID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
+	path := "/scans"
+	if folderID > 0 {
+		path += "?" + url.Values{"folder_id": {fmt.Sprint(folderID)}}.Encode()
 	}
 
 	var scanList NessusScanList
-	if err := json.NewDecoder(resp.Body).Decode(&scanList); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := s.getJSON(config, path, &scanList); err != nil {
+		return nil, err
 	}
 
+	// Nessus returns null rather than an empty list for folders without scans
+	if scanList.Scans == nil {
+		return []NessusScan{}, nil
+	}
 	return scanList.Scans, nil
 }
 
-// GetScanDetails retrieves detailed information about a specific scan
-func (s *NessusAPIService) GetScanDetails(configID uuid.UUID, scanID int) (*NessusScanDetail, error) {
+// ListFolders retrieves the folders scans are organized in
+func (s *NessusAPIService) ListFolders(configID uuid.UUID) ([]NessusFolder, error) {
 	config, err := s.configService.GetConfig(configID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config: %w", err)
 	}
 
-	client := s.createHTTPClient(30 * time.Second)
-	url := fmt.Sprintf("%s/scans/%d", config.BaseURL, scanID)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	var folderList NessusFolderList
+	if err := s.getJSON(config, "/folders", &folderList); err != nil {
+		return nil, err
 	}
+	if folderList.Folders == nil {
+		return []NessusFolder{}, nil
+	}
+	return folderList.Folders, nil
+}
 
-	req.Header.Set("X-ApiKeys", fmt.Sprintf("accessKey=%s; secretKey=%s", config.AccessKey, config.SecretKey))
-	req.Header.Set("Content-Type", "application/json")
+// TrashFolderID returns the ID of the trash folder, or 0 if Nessus reports none
+func TrashFolderID(folders []NessusFolder) int {
+	for _, folder := range folders {
+		if folder.Type == NessusFolderTrash {
+			return folder.ID
+		}
+	}
+	return 0
+}
+
+// GetScanDetails retrieves detailed information about a specific scan
+func (s *NessusAPIService) GetScanDetails(configID uuid.UUID, scanID int) (*NessusScanDetail, error) {
+	return s.GetScanRunDetails(configID, scanID, 0)
+}
 
-	resp, err := client.Do(req)
+// GetScanRunDetails retrieves the results of one run of a scan (the latest run when historyID is 0)
+func (s *NessusAPIService) GetScanRunDetails(configID uuid.UUID, scanID, historyID int) (*NessusScanDetail, error) {
+	config, err := s.configService.GetConfig(configID)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("failed to get config: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	path := fmt.Sprintf("/scans/%d", scanID)
+	if historyID > 0 {
+		path += "?" + url.Values{"history_id": {fmt.Sprint(historyID)}}.Encode()
 	}
 
 	var scanDetail NessusScanDetail
-	if err := json.NewDecoder(resp.Body).Decode(&scanDetail); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := s.getJSON(config, path, &scanDetail); err != nil {
+		return nil, err
 	}
-
 	return &scanDetail, nil
 }
 
 // ExportScan exports a scan in Nessus format (.nessus XML)
 func (s *NessusAPIService) ExportScan(configID uuid.UUID, scanID int) ([]byte, error) {
+	return s.ExportScanRun(configID, scanID, 0)
+}
+
+// ExportScanRun exports one run of a scan in Nessus format (the latest run when historyID is 0)
+func (s *NessusAPIService) ExportScanRun(configID uuid.UUID, scanID, historyID int) ([]byte, error) {
 	config, err := s.configService.GetConfig(configID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config: %w", err)
 	}
 
-	client := s.createHTTPClient(5 * time.Minute) // Exports can take time
+	client, err := s.createHTTPClient(config, 5 * time.Minute) // Exports can take time
+	if err != nil {
+		return nil, err
+	}
 
 	// Step 1: Request export
 	exportURL := fmt.Sprintf("%s/scans/%d/export", config.BaseURL, scanID)
+	if historyID > 0 {
+		exportURL += "?" + url.Values{"history_id": {fmt.Sprint(historyID)}}.Encode()
+	}
 	exportReq := map[string]string{"format": "nessus"}
 	exportBody, _ := json.Marshal(exportReq)
 
-	req, err := http.NewRequest("POST", exportURL, bytes.NewBuffer(exportBody))
+	req, err := http.NewRequestWithContext(s.ctx, "POST", exportURL, bytes.NewBuffer(exportBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create export request: %w", err)
 	}
 
-	req.Header.Set("X-ApiKeys", fmt.Sprintf("accessKey=%s; secretKey=%s", config.AccessKey, config.SecretKey))
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := client.Do(req)
+	resp, err := s.do(client, config, req)
 	if err != nil {
 		return nil, fmt.Errorf("export request failed: %w", err)
 	}
@@ -264,17 +511,27 @@ func (s *NessusAPIService) ExportScan(configID uuid.UUID, scanID int) ([]byte, e
 		return nil, fmt.Errorf("unexpected file ID type: %T", v)
 	}
 
-	// Step 2: Poll for export completion
+	// Step 2: Poll for export completion, checking often at first since small scans export quickly
 	statusURL := fmt.Sprintf("%s/scans/%d/export/%s/status", config.BaseURL, scanID, fileID)
-	maxRetries := 60 // 5 minutes with 5 second intervals
-	for i := 0; i < maxRetries; i++ {
-		time.Sleep(5 * time.Second)
+	deadline := time.Now().Add(nessusExportTimeout)
+	for interval := time.Second; ; interval *= 2 {
+		if interval > nessusExportMaxPollInterval {
+			interval = nessusExportMaxPollInterval
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("export of scan %d did not finish in time", scanID)
+		}
+		if err := s.wait(interval); err != nil {
+			return nil, err
+		}
 
-		statusReq, _ := http.NewRequest("GET", statusURL, nil)
-		statusReq.Header.Set("X-ApiKeys", fmt.Sprintf("accessKey=%s; secretKey=%s", config.AccessKey, config.SecretKey))
+		statusReq, _ := http.NewRequestWithContext(s.ctx, "GET", statusURL, nil)
 
-		statusResp, err := client.Do(statusReq)
+		statusResp, err := s.do(client, config, statusReq)
 		if err != nil {
+			if s.ctx.Err() != nil {
+				return nil, s.ctx.Err()
+			}
 			continue
 		}
 
@@ -285,18 +542,20 @@ func (s *NessusAPIService) ExportScan(configID uuid.UUID, scanID int) ([]byte, e
 		if status.Status == "ready" {
 			break
 		}
+		// Tenable.io reports exports it failed to generate rather than leaving them pending
+		if status.Status == "error" {
+			return nil, fmt.Errorf("export of scan %d failed", scanID)
+		}
 	}
 
 	// Step 3: Download the export file
 	downloadURL := fmt.Sprintf("%s/scans/%d/export/%s/download", config.BaseURL, scanID, fileID)
-	downloadReq, err := http.NewRequest("GET", downloadURL, nil)
+	downloadReq, err := http.NewRequestWithContext(s.ctx, "GET", downloadURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create download request: %w", err)
 	}
 
-	downloadReq.Header.Set("X-ApiKeys", fmt.Sprintf("accessKey=%s; secretKey=%s", config.AccessKey, config.SecretKey))
-
-	downloadResp, err := client.Do(downloadReq)
+	downloadResp, err := s.do(client, config, downloadReq)
 	if err != nil {
 		return nil, fmt.Errorf("download request failed: %w", err)
 	}
@@ -314,15 +573,41 @@ func (s *NessusAPIService) ExportScan(configID uuid.UUID, scanID int) ([]byte, e
 	return data, nil
 }
 
-// ImportScan exports a scan from Nessus and parses it
+// ImportScan exports a scan from Nessus and parses it. Agent scans are exported from their
+// latest completed run, since the current run may still be waiting on agents to report.
 func (s *NessusAPIService) ImportScan(configID uuid.UUID, scanID int) ([]ParsedVulnerability, error) {
+	return s.importScan(configID, scanID, nil)
+}
+
+// importScan is ImportScan, reporting the scan's phases to report when set
+func (s *NessusAPIService) importScan(configID uuid.UUID, scanID int, report func(scanID int, phase string)) ([]ParsedVulnerability, error) {
+	if report == nil {
+		report = func(int, string) {}
+	}
+
+	report(scanID, ScanImportExporting)
+	details, err := s.GetScanDetails(configID, scanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scan details: %w", err)
+	}
+
+	historyID := 0
+	if details.IsAgentScan() {
+		run := details.LatestCompletedRun()
+		if run == nil {
+			return nil, fmt.Errorf("agent scan %d has no completed runs", scanID)
+		}
+		historyID = run.HistoryID
+	}
+
 	// Export scan from Nessus
-	data, err := s.ExportScan(configID, scanID)
+	data, err := s.ExportScanRun(configID, scanID, historyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to export scan: %w", err)
 	}
 
 	// Parse the exported data using existing parser
+	report(scanID, ScanImportParsing)
 	vulnerabilities, err := s.parser.ParseNessusFile(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse scan: %w", err)
@@ -331,41 +616,146 @@ func (s *NessusAPIService) ImportScan(configID uuid.UUID, scanID int) ([]ParsedV
 	return vulnerabilities, nil
 }
 
-// ImportMultipleScans imports multiple scans
-func (s *NessusAPIService) ImportMultipleScans(configID uuid.UUID, scanIDs []int) (map[int][]ParsedVulnerability, map[int]error) {
+// nessusImportWorkers bounds how many scans ImportMultipleScans exports at once
+var nessusImportWorkers = 4
+
+// SetNessusImportWorkers sets how many scans are exported at once by multi-scan imports; values
+// below one keep the current number
+func SetNessusImportWorkers(workers int) {
+	if workers > 0 {
+		nessusImportWorkers = workers
+	}
+}
+
+// Phases of a scan in a multi-scan import
+const (
+	ScanImportQueued    = "queued"
+	ScanImportExporting = "exporting" // Export requested, polled and downloaded
+	ScanImportParsing   = "parsing"
+	ScanImportDone      = "done"
+	ScanImportFailed    = "failed"
+)
+
+// ScanImportProgress is the progress of a multi-scan import
+type ScanImportProgress struct {
+	Total     int            `json:"total"`
+	Completed int            `json:"completed"`
+	Failed    int            `json:"failed"`
+	Phases    map[int]string `json:"phases"` // Phase of each scan by scan ID
+}
+
+// ImportMultipleScans exports and parses scans concurrently with a bounded pool of workers.
+// progress, when set, receives a snapshot whenever a scan changes phase; calls are serialized.
+// Scans not started when the service's context is cancelled fail with the context's error, and
+// those not started once the server is shutting down fail with shutdown.ErrDraining.
+func (s *NessusAPIService) ImportMultipleScans(configID uuid.UUID, scanIDs []int, progress func(ScanImportProgress)) (map[int][]ParsedVulnerability, map[int]error) {
 	results := make(map[int][]ParsedVulnerability)
 	errors := make(map[int]error)
 
+	state := ScanImportProgress{Total: len(scanIDs), Phases: make(map[int]string, len(scanIDs))}
 	for _, scanID := range scanIDs {
-		vulns, err := s.ImportScan(configID, scanID)
-		if err != nil {
-			fmt.Printf("Failed to import scan %d: %v\n", scanID, err)
-			errors[scanID] = err
-		} else {
-			fmt.Printf("Successfully imported scan %d with %d vulnerabilities\n", scanID, len(vulns))
-			results[scanID] = vulns
+		state.Phases[scanID] = ScanImportQueued
+	}
+	var mu sync.Mutex
+	report := func(scanID int, phase string) {
+		mu.Lock()
+		defer mu.Unlock()
+		state.Phases[scanID] = phase
+		switch phase {
+		case ScanImportDone:
+			state.Completed++
+		case ScanImportFailed:
+			state.Failed++
+		}
+		if progress != nil {
+			snapshot := state
+			snapshot.Phases = make(map[int]string, len(state.Phases))
+			for id, p := range state.Phases {
+				snapshot.Phases[id] = p
+			}
+			progress(snapshot)
 		}
 	}
 
+	workers := nessusImportWorkers
+	if workers > len(scanIDs) {
+		workers = len(scanIDs)
+	}
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for scanID := range queue {
+				vulns, err := []ParsedVulnerability(nil), s.ctx.Err()
+				if err == nil && shutdown.Draining() {
+					err = shutdown.ErrDraining
+				}
+				if err == nil {
+					vulns, err = s.importScan(configID, scanID, report)
+				}
+				mu.Lock()
+				if err != nil {
+					errors[scanID] = err
+				} else {
+					results[scanID] = vulns
+				}
+				mu.Unlock()
+
+				if err != nil {
+					utils.Logger.Warn().Err(err).Int("scan_id", scanID).Msg("Failed to import scan")
+					report(scanID, ScanImportFailed)
+				} else {
+					utils.Logger.Info().Int("scan_id", scanID).Int("vulnerabilities", len(vulns)).Msg("Scan imported")
+					report(scanID, ScanImportDone)
+				}
+			}
+		}()
+	}
+
+	for i, scanID := range scanIDs {
+		select {
+		case queue <- scanID:
+			continue
+		case <-s.ctx.Done():
+		}
+		// Cancelled: the scans not handed to a worker fail
+		for _, skipped := range scanIDs[i:] {
+			mu.Lock()
+			errors[skipped] = s.ctx.Err()
+			mu.Unlock()
+			report(skipped, ScanImportFailed)
+		}
+		break
+	}
+	close(queue)
+	wg.Wait()
+
 	return results, errors
 }
 
-// ImportAllScans imports all available scans
+// ImportAllScans imports all available scans outside the trash
 func (s *NessusAPIService) ImportAllScans(configID uuid.UUID) (map[int][]ParsedVulnerability, map[int]error) {
 	scans, err := s.ListScans(configID)
 	if err != nil {
 		return nil, map[int]error{0: err}
 	}
+	folders, err := s.ListFolders(configID)
+	if err != nil {
+		return nil, map[int]error{0: err}
+	}
+	trashID := TrashFolderID(folders)
 
 	scanIDs := make([]int, 0, len(scans))
 	for _, scan := range scans {
 		// Only import completed scans
-		if scan.Status == "completed" {
+		if scan.Status == "completed" && (trashID == 0 || scan.FolderID != trashID) {
 			scanIDs = append(scanIDs, scan.ID)
 		}
 	}
 
-	return s.ImportMultipleScans(configID, scanIDs)
+	return s.ImportMultipleScans(configID, scanIDs, nil)
 }
 
 // GetScanSummary returns a summary of a scan for preview
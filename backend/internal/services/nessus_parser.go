@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/cwe"
 )
 
 // NessusClientData represents the root of a Nessus XML file
@@ -66,10 +67,14 @@ type NessusReportItem struct {
 	CVSS3BaseScore string `xml:"cvss3_base_score"`
 	CVSS3Vector    string `xml:"cvss3_vector"`
 	CVE            string `xml:"cve"`
+	CWE            []string `xml:"cwe"`
+	Xref           []string `xml:"xref"` // Cross references such as CWE:79 or OSVDB:1234
 	RiskFactor     string `xml:"risk_factor"`
 	ExploitAvailable string `xml:"exploit_available"`
 	PatchPublicationDate string `xml:"patch_publication_date"`
 	VulnPublicationDate  string `xml:"vuln_publication_date"`
+	Workaround     string `xml:"workaround"`
+	PluginOutput   string `xml:"plugin_output"`
 }
 
 // ParsedVulnerability represents a parsed vulnerability with its affected systems
@@ -77,13 +82,21 @@ type ParsedVulnerability struct {
 	Title                     string
 	Description               string
 	Severity                  models.VulnerabilitySeverity
+	ScannerSeverity           string // Scanner's own severity level (info to critical), for severity mappings
 	CVSSScore                 *float64
 	CVSSVector                string
 	CVEID                     string
+	CWEIDs                    []string // Normalized, e.g. CWE-79
 	ImpactAssessment          string
 	MitigationRecommendations string
 	PluginID                  string
+	PluginFamily              string
 	RiskFactor                string
+	ExploitAvailable          bool   // The scanner knows of a public exploit
+	PatchAvailable            bool   // The vendor published a patch
+	AdvisoryURL               string // Vendor advisory or KB article
+	FixedVersion              string // First version without the vulnerability
+	Workaround                string
 	ScanDate                  time.Time
 	AffectedHosts             []ParsedHost
 }
@@ -95,8 +108,13 @@ type ParsedHost struct {
 	Port          string
 	Protocol      string
 	ServiceName   string
-	OS            string
-	ScanTimestamp time.Time
+	OS              string
+	ScanTimestamp   time.Time
+	PluginOutput    string
+	DetectedVersion string // Installed version the scanner reported
+	FixedVersion    string // Version the scanner recommends upgrading to
+	ScanName        string            // Name of the scan that reported the host, for import rules
+	Tags            map[string]string // Scanner host properties by name, for import rules
 }
 
 // NessusParserService handles parsing of Nessus files
@@ -123,9 +141,11 @@ func (s *NessusParserService) ParseNessusFile(data []byte) ([]ParsedVulnerabilit
 		ipAddress := hostname
 		osName := ""
 		var scanTimestamp time.Time
+		tags := make(map[string]string, len(host.HostProperties.Tags))
 
 		// Try to get more detailed host info from properties
 		for _, tag := range host.HostProperties.Tags {
+			tags[tag.Name] = strings.TrimSpace(tag.Value)
 			if tag.Name == "host-ip" {
 				ipAddress = tag.Value
 			} else if tag.Name == "host-fqdn" {
@@ -162,13 +182,21 @@ func (s *NessusParserService) ParseNessusFile(data []byte) ([]ParsedVulnerabilit
 					Title:                     item.PluginName,
 					Description:               s.buildDescription(item),
 					Severity:                  s.mapSeverity(item.Severity, item.RiskFactor),
+					ScannerSeverity:           s.severityLevel(item.Severity, item.RiskFactor),
 					CVSSScore:                 s.parseCVSSScore(item),
 					CVSSVector:                s.getCVSSVector(item),
 					CVEID:                     s.extractCVE(item.CVE),
+					CWEIDs:                    s.extractCWEs(item),
 					ImpactAssessment:          item.Synopsis,
 					MitigationRecommendations: item.Solution,
 					PluginID:                  pluginID,
+					PluginFamily:              item.PluginFamily,
 					RiskFactor:                item.RiskFactor,
+					ExploitAvailable:          strings.EqualFold(strings.TrimSpace(item.ExploitAvailable), "true"),
+					PatchAvailable:            strings.TrimSpace(item.PatchPublicationDate) != "",
+					AdvisoryURL:               s.extractAdvisoryURL(item),
+					FixedVersion:              SolutionFixedVersion(item.Solution),
+					Workaround:                strings.TrimSpace(item.Workaround),
 					ScanDate:                  scanTimestamp,
 					AffectedHosts:             []ParsedHost{},
 				}
@@ -176,16 +204,25 @@ func (s *NessusParserService) ParseNessusFile(data []byte) ([]ParsedVulnerabilit
 			}
 
 			// Add affected host
+			detected, fixed := PluginOutputVersions(item.PluginOutput)
 			parsedHost := ParsedHost{
-				Hostname:      hostname,
-				IPAddress:     ipAddress,
-				Port:          item.Port,
-				Protocol:      item.Protocol,
-				ServiceName:   item.SvcName,
-				OS:            osName,
-				ScanTimestamp: scanTimestamp,
+				Hostname:        hostname,
+				IPAddress:       ipAddress,
+				Port:            item.Port,
+				Protocol:        item.Protocol,
+				ServiceName:     item.SvcName,
+				OS:              osName,
+				ScanTimestamp:   scanTimestamp,
+				PluginOutput:    strings.TrimSpace(item.PluginOutput),
+				DetectedVersion: detected,
+				FixedVersion:    fixed,
+				ScanName:        nessusData.Report.Name,
+				Tags:            tags,
 			}
 			vuln.AffectedHosts = append(vuln.AffectedHosts, parsedHost)
+			if vuln.FixedVersion == "" {
+				vuln.FixedVersion = fixed
+			}
 		}
 	}
 
@@ -209,31 +246,36 @@ func (s *NessusParserService) buildDescription(item NessusReportItem) string {
 
 // mapSeverity converts Nessus severity (0-4) to our severity enum
 func (s *NessusParserService) mapSeverity(severity int, riskFactor string) models.VulnerabilitySeverity {
+	return models.DefaultScannerSeverities[s.severityLevel(severity, riskFactor)]
+}
+
+// severityLevel converts Nessus severity (0-4) to a scanner severity level
+func (s *NessusParserService) severityLevel(severity int, riskFactor string) string {
 	// Nessus severity: 0=Info, 1=Low, 2=Medium, 3=High, 4=Critical
 	switch severity {
 	case 0:
-		return models.SeverityNone
+		return models.ScannerSeverityInfo
 	case 1:
-		return models.SeverityLow
+		return models.ScannerSeverityLow
 	case 2:
-		return models.SeverityMedium
+		return models.ScannerSeverityMedium
 	case 3:
-		return models.SeverityHigh
+		return models.ScannerSeverityHigh
 	case 4:
-		return models.SeverityCritical
+		return models.ScannerSeverityCritical
 	default:
 		// Fallback to risk factor
 		switch strings.ToLower(riskFactor) {
 		case "critical":
-			return models.SeverityCritical
+			return models.ScannerSeverityCritical
 		case "high":
-			return models.SeverityHigh
+			return models.ScannerSeverityHigh
 		case "medium":
-			return models.SeverityMedium
+			return models.ScannerSeverityMedium
 		case "low":
-			return models.SeverityLow
+			return models.ScannerSeverityLow
 		default:
-			return models.SeverityNone
+			return models.ScannerSeverityInfo
 		}
 	}
 }
@@ -280,6 +322,30 @@ func (s *NessusParserService) extractCVE(cveStr string) string {
 	return cveStr
 }
 
+// extractCWEs collects the weakness types of a plugin from its cwe elements and CWE cross
+// references
+func (s *NessusParserService) extractCWEs(item NessusReportItem) []string {
+	raw := append([]string(nil), item.CWE...)
+	for _, xref := range item.Xref {
+		if kind, id, ok := strings.Cut(xref, ":"); ok && strings.EqualFold(strings.TrimSpace(kind), "CWE") {
+			raw = append(raw, id)
+		}
+	}
+	return cwe.NormalizeIDs(raw)
+}
+
+// extractAdvisoryURL picks the advisory of a plugin from its MSKB cross references and see_also
+// links
+func (s *NessusParserService) extractAdvisoryURL(item NessusReportItem) string {
+	var kbs []string
+	for _, xref := range item.Xref {
+		if kind, id, ok := strings.Cut(xref, ":"); ok && strings.EqualFold(strings.TrimSpace(kind), "MSKB") {
+			kbs = append(kbs, id)
+		}
+	}
+	return advisoryURL(kbs, strings.Fields(item.SeeAlso))
+}
+
 // GetImportSummary returns a summary of what will be imported
 func (s *NessusParserService) GetImportSummary(vulnerabilities []ParsedVulnerability) map[string]interface{} {
 	totalVulns := len(vulnerabilities)
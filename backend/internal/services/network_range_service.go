@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// NetworkRangeService manages network ranges and resolves IP addresses to them
+type NetworkRangeService struct {
+	db *gorm.DB
+}
+
+// NewNetworkRangeService creates a new network range service
+func NewNetworkRangeService(db *gorm.DB) *NetworkRangeService {
+	return &NetworkRangeService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *NetworkRangeService) WithContext(ctx context.Context) *NetworkRangeService {
+	return &NetworkRangeService{db: s.db.WithContext(ctx)}
+}
+
+// NetworkRangeRequest represents a create or update network range request
+type NetworkRangeRequest struct {
+	Name        *string    `json:"name,omitempty"`
+	CIDR        *string    `json:"cidr,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Environment *string    `json:"environment,omitempty"`
+	Location    *string    `json:"location,omitempty"`
+	OwnerID     *uuid.UUID `json:"owner_id,omitempty"`      // uuid.Nil clears the owner
+	OwnerTeamID *uuid.UUID `json:"owner_team_id,omitempty"` // uuid.Nil clears the owner team
+}
+
+// applyTo copies the provided request fields onto a range
+func (req NetworkRangeRequest) applyTo(r *models.NetworkRange) {
+	if req.Name != nil {
+		r.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.CIDR != nil {
+		r.CIDR = strings.TrimSpace(*req.CIDR)
+	}
+	if req.Description != nil {
+		r.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Environment != nil {
+		r.Environment = models.Environment(strings.ToUpper(strings.TrimSpace(*req.Environment)))
+	}
+	if req.Location != nil {
+		r.Location = strings.TrimSpace(*req.Location)
+	}
+	if req.OwnerID != nil {
+		if *req.OwnerID == uuid.Nil {
+			r.OwnerID = nil
+		} else {
+			ownerID := *req.OwnerID
+			r.OwnerID = &ownerID
+		}
+	}
+	if req.OwnerTeamID != nil {
+		if *req.OwnerTeamID == uuid.Nil {
+			r.OwnerTeamID = nil
+		} else {
+			teamID := *req.OwnerTeamID
+			r.OwnerTeamID = &teamID
+		}
+	}
+}
+
+// ValidateNetworkRange checks that a range has a name, a valid environment and a valid CIDR,
+// and rewrites the CIDR to its canonical network form (10.1.2.3/16 becomes 10.1.0.0/16)
+func ValidateNetworkRange(r *models.NetworkRange) error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.CIDR == "" {
+		return fmt.Errorf("cidr is required")
+	}
+	_, network, err := net.ParseCIDR(r.CIDR)
+	if err != nil {
+		return fmt.Errorf("invalid cidr: %s", r.CIDR)
+	}
+	r.CIDR = network.String()
+
+	switch r.Environment {
+	case models.EnvProduction, models.EnvStaging, models.EnvDevelopment, models.EnvTest:
+	default:
+		return fmt.Errorf("invalid environment, must be one of: PRODUCTION, STAGING, DEVELOPMENT, TEST")
+	}
+	return nil
+}
+
+// compiledNetworkRange is a range with its CIDR pre-parsed
+type compiledNetworkRange struct {
+	networkRange models.NetworkRange
+	network      *net.IPNet
+	prefix       int
+}
+
+// NetworkRangeMatcher resolves IP addresses against a fixed set of ranges
+type NetworkRangeMatcher struct {
+	ranges []compiledNetworkRange
+}
+
+// NewNetworkRangeMatcher compiles the ranges, most specific first; ranges with an invalid CIDR are skipped
+func NewNetworkRangeMatcher(ranges []models.NetworkRange) *NetworkRangeMatcher {
+	matcher := &NetworkRangeMatcher{}
+	for _, r := range ranges {
+		_, network, err := net.ParseCIDR(r.CIDR)
+		if err != nil {
+			continue
+		}
+		prefix, _ := network.Mask.Size()
+		matcher.ranges = append(matcher.ranges, compiledNetworkRange{networkRange: r, network: network, prefix: prefix})
+	}
+	sort.SliceStable(matcher.ranges, func(i, j int) bool {
+		return matcher.ranges[i].prefix > matcher.ranges[j].prefix
+	})
+	return matcher
+}
+
+// Len returns the number of ranges
+func (m *NetworkRangeMatcher) Len() int {
+	return len(m.ranges)
+}
+
+// Match returns the most specific range containing ip, or nil
+func (m *NetworkRangeMatcher) Match(ip string) *models.NetworkRange {
+	if m == nil {
+		return nil
+	}
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return nil
+	}
+	for i := range m.ranges {
+		if m.ranges[i].network.Contains(parsed) {
+			return &m.ranges[i].networkRange
+		}
+	}
+	return nil
+}
+
+// List returns all network ranges ordered by CIDR
+func (s *NetworkRangeService) List(search string) ([]models.NetworkRange, error) {
+	query := s.db.Preload("Owner").Preload("OwnerTeam").Order("cidr ASC")
+	if search = strings.TrimSpace(search); search != "" {
+		query = query.Where("name ILIKE ? OR cidr ILIKE ? OR location ILIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%")
+	}
+
+	var ranges []models.NetworkRange
+	if err := query.Find(&ranges).Error; err != nil {
+		return nil, fmt.Errorf("failed to list network ranges: %w", err)
+	}
+	return ranges, nil
+}
+
+// Get returns a single network range
+func (s *NetworkRangeService) Get(id uuid.UUID) (*models.NetworkRange, error) {
+	var r models.NetworkRange
+	if err := s.db.Preload("Owner").Preload("OwnerTeam").Preload("CreatedBy").First(&r, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("network range not found")
+		}
+		return nil, fmt.Errorf("failed to get network range: %w", err)
+	}
+	return &r, nil
+}
+
+// Create creates a network range. Ranges may nest; an identical CIDR is rejected.
+func (s *NetworkRangeService) Create(req NetworkRangeRequest, createdByID uuid.UUID) (*models.NetworkRange, error) {
+	r := &models.NetworkRange{
+		Environment: models.EnvProduction,
+		CreatedByID: createdByID,
+	}
+	req.applyTo(r)
+
+	if err := s.validate(r, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(r).Error; err != nil {
+		return nil, fmt.Errorf("failed to create network range: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("network_range_id", r.ID.String()).
+		Str("cidr", r.CIDR).
+		Str("environment", string(r.Environment)).
+		Str("created_by", createdByID.String()).
+		Msg("Network range created")
+
+	return s.Get(r.ID)
+}
+
+// Update updates a network range; existing assets are not reclassified
+func (s *NetworkRangeService) Update(id uuid.UUID, req NetworkRangeRequest) (*models.NetworkRange, error) {
+	r, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.applyTo(r)
+	if err := s.validate(r, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(r).Select(
+		"name", "cidr", "description", "environment", "location", "owner_id", "owner_team_id",
+	).Updates(r).Error; err != nil {
+		return nil, fmt.Errorf("failed to update network range: %w", err)
+	}
+
+	return s.Get(id)
+}
+
+// Delete soft deletes a network range
+func (s *NetworkRangeService) Delete(id uuid.UUID) error {
+	result := s.db.Delete(&models.NetworkRange{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete network range: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("network range not found")
+	}
+	return nil
+}
+
+// Lookup returns the most specific range containing ip, or nil if none does
+func (s *NetworkRangeService) Lookup(ip string) (*models.NetworkRange, error) {
+	if net.ParseIP(strings.TrimSpace(ip)) == nil {
+		return nil, fmt.Errorf("invalid ip address: %s", ip)
+	}
+	matcher, err := s.LoadMatcher(s.db)
+	if err != nil {
+		return nil, err
+	}
+	return matcher.Match(ip), nil
+}
+
+// LoadMatcher loads every range using the given transaction
+func (s *NetworkRangeService) LoadMatcher(tx *gorm.DB) (*NetworkRangeMatcher, error) {
+	var ranges []models.NetworkRange
+	if err := tx.Order("created_at ASC").Find(&ranges).Error; err != nil {
+		return nil, fmt.Errorf("failed to load network ranges: %w", err)
+	}
+	return NewNetworkRangeMatcher(ranges), nil
+}
+
+// validate checks the range fields, that its owners exist, and that its CIDR is not already defined
+func (s *NetworkRangeService) validate(r *models.NetworkRange, exceptID uuid.UUID) error {
+	if err := ValidateNetworkRange(r); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := s.db.Model(&models.NetworkRange{}).Where("cidr = ? AND id <> ?", r.CIDR, exceptID).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("network range '%s' already exists", r.CIDR)
+	}
+
+	if r.OwnerID != nil {
+		if err := s.db.Model(&models.User{}).Where("id = ?", *r.OwnerID).Count(&count).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("owner not found")
+		}
+	}
+	if r.OwnerTeamID != nil {
+		if err := NewTeamService(s.db).Exists(*r.OwnerTeamID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
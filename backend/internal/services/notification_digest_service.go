@@ -0,0 +1,231 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// DigestHour is the local hour (in each user's timezone) digests are sent at; weekly digests go
+// out on Mondays
+const DigestHour = 8
+
+// digestItemLimit caps the vulnerabilities listed per digest section; the totals still count all
+const digestItemLimit = 25
+
+// DigestVulnerability is a vulnerability listed in a digest
+type DigestVulnerability struct {
+	ID            uuid.UUID                    `json:"id"`
+	Title         string                       `json:"title"`
+	Severity      models.VulnerabilitySeverity `json:"severity"`
+	Status        models.VulnerabilityStatus   `json:"status"`
+	DiscoveryDate time.Time                    `json:"discovery_date"`
+	DueAt         *time.Time                   `json:"due_at,omitempty"` // SLA deadline (SLA warnings only)
+	Overdue       bool                         `json:"overdue,omitempty"`
+}
+
+// DigestImport is an import the user started that finished during the digest period
+type DigestImport struct {
+	ID                      uuid.UUID              `json:"id"`
+	Scanner                 string                 `json:"scanner"`
+	Status                  models.ImportJobStatus `json:"status"`
+	ImportedVulnerabilities int                    `json:"imported_vulnerabilities"`
+	NewFindings             int                    `json:"new_findings"`
+	Error                   string                 `json:"error,omitempty"`
+	CompletedAt             time.Time              `json:"completed_at"`
+}
+
+// NotificationDigestContent is one user's summary of a digest period
+type NotificationDigestContent struct {
+	Frequency models.NotificationDigest `json:"frequency"`
+	Since     time.Time                 `json:"since"`
+	Until     time.Time                 `json:"until"`
+
+	Assigned      []DigestVulnerability `json:"assigned"` // Newly assigned and still open
+	AssignedTotal int64                 `json:"assigned_total"`
+	SLAWarnings   []DigestVulnerability `json:"sla_warnings"` // Open, assigned and due before the next digest
+	SLATotal      int64                 `json:"sla_total"`
+	Imports       []DigestImport        `json:"imports"`
+
+	UnreadNotifications int64 `json:"unread_notifications"` // Unread in-app notifications of the period
+}
+
+// Empty reports whether there is nothing to tell the user
+func (d *NotificationDigestContent) Empty() bool {
+	return d.AssignedTotal == 0 && d.SLATotal == 0 && len(d.Imports) == 0 && d.UnreadNotifications == 0
+}
+
+// DigestPeriod returns how long a digest frequency covers
+func DigestPeriod(frequency models.NotificationDigest) time.Duration {
+	if frequency == models.NotificationDigestWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// DigestSlot returns the latest scheduled send time at or before now: DigestHour today (or
+// yesterday), or on the latest Monday for weekly digests, in loc
+func DigestSlot(frequency models.NotificationDigest, loc *time.Location, now time.Time) time.Time {
+	local := now.In(loc)
+	slot := time.Date(local.Year(), local.Month(), local.Day(), DigestHour, 0, 0, 0, loc)
+	if slot.After(local) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	if frequency == models.NotificationDigestWeekly {
+		// Back to Monday
+		slot = slot.AddDate(0, 0, -((int(slot.Weekday()) + 6) % 7))
+	}
+	return slot
+}
+
+// DigestDue reports whether a user's digest is due at now and the start of the period it covers:
+// the last digest, or one period before the slot for a first digest
+func DigestDue(pref *models.UserPreference, now time.Time) (bool, time.Time) {
+	if pref.NotificationDigest != models.NotificationDigestDaily && pref.NotificationDigest != models.NotificationDigestWeekly {
+		return false, time.Time{}
+	}
+	slot := DigestSlot(pref.NotificationDigest, pref.Location(), now)
+	if pref.LastDigestAt != nil {
+		return pref.LastDigestAt.Before(slot), *pref.LastDigestAt
+	}
+	return true, slot.Add(-DigestPeriod(pref.NotificationDigest))
+}
+
+// NotificationDigestService compiles and sends daily and weekly notification digests
+type NotificationDigestService struct {
+	db           *gorm.DB
+	emailService *EmailService
+}
+
+// NewNotificationDigestService creates a new notification digest service
+func NewNotificationDigestService(db *gorm.DB, cfg *config.Config) *NotificationDigestService {
+	return &NotificationDigestService{db: db, emailService: NewEmailService(db, cfg)}
+}
+
+// BuildDigest compiles a user's digest of the period [since, until)
+func (s *NotificationDigestService) BuildDigest(userID uuid.UUID, frequency models.NotificationDigest, since, until time.Time) (*NotificationDigestContent, error) {
+	digest := &NotificationDigestContent{
+		Frequency:   frequency,
+		Since:       since,
+		Until:       until,
+		Assigned:    []DigestVulnerability{},
+		SLAWarnings: []DigestVulnerability{},
+		Imports:     []DigestImport{},
+	}
+	open := s.db.Model(&models.Vulnerability{}).
+		Where("assigned_to_id = ? AND status IN ?", userID, unresolvedStatuses)
+
+	// Assigned during the period, by a reassignment or on creation
+	assigned := open.Session(&gorm.Session{}).
+		Where("((created_at >= ? AND created_at < ?) OR id IN (?))", since, until,
+			s.db.Model(&models.VulnerabilityAssignmentHistory{}).
+				Select("vulnerability_id").
+				Where("new_assignee_id = ? AND changed_at >= ? AND changed_at < ?", userID, since, until))
+	if err := assigned.Session(&gorm.Session{}).Count(&digest.AssignedTotal).Error; err != nil {
+		return nil, fmt.Errorf("failed to count assigned vulnerabilities: %w", err)
+	}
+	if err := assigned.
+		Select("id, title, severity, status, discovery_date").
+		Order("created_at DESC").
+		Limit(digestItemLimit).
+		Scan(&digest.Assigned).Error; err != nil {
+		return nil, fmt.Errorf("failed to load assigned vulnerabilities: %w", err)
+	}
+	sortDigestVulnerabilities(digest.Assigned)
+
+	// Due before the next digest is sent, or already overdue
+	dueSQL, dueArgs := slaBreachCondition(until.Add(DigestPeriod(frequency)))
+	due := open.Session(&gorm.Session{}).Where(dueSQL, dueArgs...)
+	if err := due.Session(&gorm.Session{}).Count(&digest.SLATotal).Error; err != nil {
+		return nil, fmt.Errorf("failed to count SLA warnings: %w", err)
+	}
+	if err := due.
+		Select("id, title, severity, status, discovery_date").
+		Order("discovery_date ASC").
+		Limit(digestItemLimit).
+		Scan(&digest.SLAWarnings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load SLA warnings: %w", err)
+	}
+	for i := range digest.SLAWarnings {
+		item := &digest.SLAWarnings[i]
+		dueAt := item.DiscoveryDate.AddDate(0, 0, SLATargetDays[item.Severity])
+		item.DueAt = &dueAt
+		item.Overdue = dueAt.Before(until)
+	}
+	sort.SliceStable(digest.SLAWarnings, func(i, j int) bool {
+		return digest.SLAWarnings[i].DueAt.Before(*digest.SLAWarnings[j].DueAt)
+	})
+
+	if err := s.db.Model(&models.ImportJob{}).
+		Select("id, scanner, status, imported_vulnerabilities, new_findings, error, completed_at").
+		Where("created_by_id = ? AND status IN ? AND completed_at >= ? AND completed_at < ?",
+			userID, []models.ImportJobStatus{models.ImportJobCompleted, models.ImportJobFailed}, since, until).
+		Order("completed_at ASC").
+		Limit(digestItemLimit).
+		Scan(&digest.Imports).Error; err != nil {
+		return nil, fmt.Errorf("failed to load completed imports: %w", err)
+	}
+
+	if err := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL AND created_at >= ? AND created_at < ?", userID, since, until).
+		Count(&digest.UnreadNotifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	return digest, nil
+}
+
+// sortDigestVulnerabilities orders vulnerabilities most severe first
+func sortDigestVulnerabilities(items []DigestVulnerability) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return severityRank(items[i].Severity) > severityRank(items[j].Severity)
+	})
+}
+
+// SendDueDigests compiles the digests due at now and queues their emails. A digest with nothing
+// to report isn't sent, but still moves the user's next period on. Returns the number sent.
+func (s *NotificationDigestService) SendDueDigests(now time.Time) (int, error) {
+	var prefs []models.UserPreference
+	if err := s.db.
+		Joins("JOIN users ON users.id = user_preferences.user_id AND users.deleted_at IS NULL AND users.email_verified = ?", true).
+		Where("user_preferences.notification_digest IN ?", []models.NotificationDigest{models.NotificationDigestDaily, models.NotificationDigestWeekly}).
+		Preload("User").
+		Find(&prefs).Error; err != nil {
+		return 0, fmt.Errorf("failed to load digest subscribers: %w", err)
+	}
+
+	sent := 0
+	for i := range prefs {
+		pref := &prefs[i]
+		due, since := DigestDue(pref, now)
+		if !due || pref.User == nil {
+			continue
+		}
+
+		digest, err := s.BuildDigest(pref.UserID, pref.NotificationDigest, since, now)
+		if err != nil {
+			utils.Logger.Error().Err(err).Str("user_id", pref.UserID.String()).Msg("Failed to compile notification digest")
+			continue
+		}
+		if !digest.Empty() {
+			if err := s.emailService.SendDigestEmail(pref.User.Email, pref.User.Name, pref, digest); err != nil {
+				utils.Logger.Error().Err(err).Str("user_id", pref.UserID.String()).Msg("Failed to send notification digest")
+				continue
+			}
+			sent++
+		}
+
+		if err := s.db.Model(&models.UserPreference{}).
+			Where("id = ?", pref.ID).
+			Update("last_digest_at", now).Error; err != nil {
+			return sent, fmt.Errorf("failed to record notification digest: %w", err)
+		}
+	}
+	return sent, nil
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// NotificationService handles in-app user notifications
+type NotificationService struct {
+	db *gorm.DB
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db}
+}
+
+// CreateNotifications stores notifications using the given transaction (or the service DB when nil)
+func (s *NotificationService) CreateNotifications(tx *gorm.DB, notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	if tx == nil {
+		tx = s.db
+	}
+	if err := tx.Create(&notifications).Error; err != nil {
+		return fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return nil
+}
+
+// ListNotifications returns a page of the user's notifications, newest first
+func (s *NotificationService) ListNotifications(userID uuid.UUID, unreadOnly bool, page, limit int) ([]models.Notification, int64, error) {
+	query := s.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	var notifications []models.Notification
+	if err := query.
+		Preload("Actor").
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&notifications).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	return notifications, total, nil
+}
+
+// CountUnread returns the number of unread notifications for a user
+func (s *NotificationService) CountUnread(userID uuid.UUID) (int64, error) {
+	var count int64
+	if err := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks a single notification as read
+func (s *NotificationService) MarkRead(userID, notificationID uuid.UUID) error {
+	result := s.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		Where("read_at IS NULL").
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		// Distinguish an already-read notification from a missing one
+		var count int64
+		if err := s.db.Model(&models.Notification{}).
+			Where("id = ? AND user_id = ?", notificationID, userID).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to get notification: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("notification not found")
+		}
+	}
+
+	return nil
+}
+
+// MarkAllRead marks every unread notification for a user as read
+func (s *NotificationService) MarkAllRead(userID uuid.UUID) (int64, error) {
+	result := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
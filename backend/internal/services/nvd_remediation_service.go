@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/nvd"
+	"gorm.io/gorm"
+)
+
+// nvdBatchSize caps the CVEs looked up per run, keeping a run without an API key to about ten
+// minutes
+const nvdBatchSize = 100
+
+// NVDRemediationService fills in the patch and advisory details of CVE vulnerabilities from the
+// National Vulnerability Database. Details from scanners or analysts are kept; NVD only fills
+// the gaps.
+type NVDRemediationService struct {
+	db     *gorm.DB
+	client *nvd.Client // nil when NVD lookups are disabled
+}
+
+// NewNVDRemediationService creates an NVD remediation service querying the CVE API at baseURL.
+// An empty URL disables it.
+func NewNVDRemediationService(db *gorm.DB, baseURL, apiKey string) *NVDRemediationService {
+	s := &NVDRemediationService{db: db}
+	if baseURL != "" {
+		s.client = nvd.NewClient(baseURL, apiKey)
+	}
+	return s
+}
+
+// Enabled reports whether NVD lookups are configured
+func (s *NVDRemediationService) Enabled() bool {
+	return s.client != nil
+}
+
+// Enrich looks up the CVEs of vulnerabilities NVD has not been checked for, spacing requests
+// within the NVD rate limit, and returns how many vulnerabilities were checked. A failed lookup
+// stops the run; its CVE is retried next run.
+func (s *NVDRemediationService) Enrich(ctx context.Context) (int64, error) {
+	if !s.Enabled() {
+		return 0, nil
+	}
+	db := s.db.WithContext(ctx)
+
+	var cves []string
+	if err := db.Model(&models.Vulnerability{}).
+		Distinct("cve_id").
+		Where("cve_id <> '' AND nvd_checked_at IS NULL").
+		Order("cve_id").
+		Limit(nvdBatchSize).
+		Pluck("cve_id", &cves).Error; err != nil {
+		return 0, fmt.Errorf("failed to find vulnerabilities to check: %w", err)
+	}
+
+	var checked int64
+	for i, id := range cves {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return checked, ctx.Err()
+			case <-time.After(s.client.Interval()):
+			}
+		}
+		cve, err := s.client.CVE(ctx, id)
+		if err != nil {
+			return checked, fmt.Errorf("failed to look up %s: %w", id, err)
+		}
+		count, err := applyNVDRemediation(db, id, cve)
+		if err != nil {
+			return checked, err
+		}
+		checked += count
+	}
+	return checked, nil
+}
+
+// applyNVDRemediation records the remediation of a CVE on its unchecked vulnerabilities, filling
+// only empty fields. cve is nil when NVD does not know the CVE.
+func applyNVDRemediation(db *gorm.DB, id string, cve *nvd.CVE) (int64, error) {
+	updates := map[string]interface{}{"nvd_checked_at": time.Now()}
+	if cve != nil {
+		remediation := cve.Remediation()
+		if remediation.PatchAvailable {
+			updates["patch_available"] = true
+		}
+		if remediation.AdvisoryURL != "" {
+			updates["advisory_url"] = gorm.Expr("COALESCE(NULLIF(advisory_url, ''), ?)", truncate(remediation.AdvisoryURL, 500))
+		}
+		if remediation.FixedVersion != "" {
+			updates["fixed_version"] = gorm.Expr("COALESCE(NULLIF(fixed_version, ''), ?)", truncate(remediation.FixedVersion, 100))
+		}
+		if remediation.MitigationURL != "" {
+			updates["workaround"] = gorm.Expr("COALESCE(NULLIF(workaround, ''), ?)", "See "+remediation.MitigationURL)
+		}
+	}
+
+	result := db.Model(&models.Vulnerability{}).
+		Where("cve_id = ? AND nvd_checked_at IS NULL", id).
+		Updates(updates)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to record NVD remediation of %s: %w", id, result.Error)
+	}
+	return result.RowsAffected, nil
+}
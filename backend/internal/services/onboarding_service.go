@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// maxOnboardingPolicies bounds the policies users are asked to accept
+const maxOnboardingPolicies = 20
+
+// onboardingPolicyKeyPattern matches policy keys such as "terms_of_use"
+var onboardingPolicyKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+
+// OnboardingPolicy is a policy users accept; publishing a new version asks every user to
+// accept it again
+type OnboardingPolicy struct {
+	Key      string `json:"key"`     // e.g. "terms_of_use"
+	Version  string `json:"version"` // e.g. "2026-10"
+	Title    string `json:"title"`
+	URL      string `json:"url,omitempty"`
+	Required bool   `json:"required"` // onboarding is incomplete until the current version is accepted
+}
+
+// OnboardingSettings are the policies of the onboarding_policies system setting
+type OnboardingSettings struct {
+	Policies []OnboardingPolicy `json:"policies"`
+}
+
+// ParseOnboardingSettings validates the JSON value of the onboarding_policies setting
+func ParseOnboardingSettings(value string) (*OnboardingSettings, error) {
+	var settings OnboardingSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return nil, fmt.Errorf("invalid onboarding policies: %w", err)
+	}
+	if len(settings.Policies) > maxOnboardingPolicies {
+		return nil, fmt.Errorf("invalid onboarding policies: at most %d policies are allowed", maxOnboardingPolicies)
+	}
+
+	seen := make(map[string]bool, len(settings.Policies))
+	for i := range settings.Policies {
+		policy := &settings.Policies[i]
+		policy.Key = strings.TrimSpace(policy.Key)
+		policy.Version = strings.TrimSpace(policy.Version)
+		policy.Title = strings.TrimSpace(policy.Title)
+		if !onboardingPolicyKeyPattern.MatchString(policy.Key) {
+			return nil, fmt.Errorf("invalid onboarding policy key %q", policy.Key)
+		}
+		if seen[policy.Key] {
+			return nil, fmt.Errorf("invalid onboarding policies: duplicate key %q", policy.Key)
+		}
+		seen[policy.Key] = true
+		if policy.Version == "" || len(policy.Version) > 50 {
+			return nil, fmt.Errorf("invalid onboarding policy %q: version must be 1 to 50 characters", policy.Key)
+		}
+		if policy.Title == "" {
+			policy.Title = policy.Key
+		}
+	}
+	if settings.Policies == nil {
+		settings.Policies = []OnboardingPolicy{}
+	}
+	return &settings, nil
+}
+
+// OnboardingService tracks policy acceptance and first-login setup steps
+type OnboardingService struct {
+	db *gorm.DB
+}
+
+// NewOnboardingService creates a new onboarding service
+func NewOnboardingService(db *gorm.DB) *OnboardingService {
+	return &OnboardingService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *OnboardingService) WithContext(ctx context.Context) *OnboardingService {
+	return &OnboardingService{db: s.db.WithContext(ctx)}
+}
+
+// PolicyStatus is a policy with whether the user accepted its current version
+type PolicyStatus struct {
+	OnboardingPolicy
+	Accepted        bool       `json:"accepted"`
+	AcceptedAt      *time.Time `json:"accepted_at,omitempty"`
+	AcceptedVersion string     `json:"accepted_version,omitempty"` // latest version accepted, which may be outdated
+}
+
+// OnboardingStepProgress is the state of a setup step for the user
+type OnboardingStepProgress struct {
+	Step      models.OnboardingStep       `json:"step"`
+	Status    models.OnboardingStepStatus `json:"status"`
+	Skippable bool                        `json:"skippable"`
+}
+
+// OnboardingState is what the frontend needs to guide a user through onboarding
+type OnboardingState struct {
+	Complete bool                     `json:"complete"`
+	Policies []PolicyStatus           `json:"policies"`
+	Steps    []OnboardingStepProgress `json:"steps"`
+}
+
+// skippableSteps are the steps a user may dismiss instead of completing
+var skippableSteps = map[models.OnboardingStep]bool{
+	models.OnboardingStepTwoFactor: true,
+}
+
+// Settings returns the configured policies; without the setting there are none
+func (s *OnboardingService) Settings() (*OnboardingSettings, error) {
+	var setting models.SystemSetting
+	err := s.db.Where("key = ?", string(models.SystemSettingOnboardingPolicies)).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &OnboardingSettings{Policies: []OnboardingPolicy{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load onboarding policies: %w", err)
+	}
+	return ParseOnboardingSettings(setting.Value)
+}
+
+// GetState returns the user's onboarding state
+func (s *OnboardingService) GetState(userID uuid.UUID) (*OnboardingState, error) {
+	var user models.User
+	if err := s.db.Select("id", "name", "two_factor_enabled").Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	settings, err := s.Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	var acceptances []models.PolicyAcceptance
+	if err := s.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("accepted_at ASC").Find(&acceptances).Error; err != nil {
+		return nil, fmt.Errorf("failed to load policy acceptances: %w", err)
+	}
+
+	var stepStates []models.OnboardingStepState
+	if err := s.db.Where("user_id = ?", userID).Find(&stepStates).Error; err != nil {
+		return nil, fmt.Errorf("failed to load onboarding steps: %w", err)
+	}
+
+	return BuildOnboardingState(&user, settings.Policies, acceptances, stepStates), nil
+}
+
+// BuildOnboardingState combines the configured policies with the user's acceptances (oldest
+// first) and step states. Steps whose outcome shows on the account, an enabled second factor or
+// a display name, count as completed without a stored state.
+func BuildOnboardingState(user *models.User, policies []OnboardingPolicy, acceptances []models.PolicyAcceptance, stepStates []models.OnboardingStepState) *OnboardingState {
+	state := &OnboardingState{
+		Complete: true,
+		Policies: make([]PolicyStatus, 0, len(policies)),
+	}
+
+	for _, policy := range policies {
+		status := PolicyStatus{OnboardingPolicy: policy}
+		for _, acceptance := range acceptances {
+			if acceptance.Policy != policy.Key {
+				continue
+			}
+			status.AcceptedVersion = acceptance.Version
+			if acceptance.Version == policy.Version {
+				acceptedAt := acceptance.AcceptedAt
+				status.Accepted = true
+				status.AcceptedAt = &acceptedAt
+			}
+		}
+		if policy.Required && !status.Accepted {
+			state.Complete = false
+		}
+		state.Policies = append(state.Policies, status)
+	}
+
+	stored := make(map[models.OnboardingStep]models.OnboardingStepStatus, len(stepStates))
+	for _, stepState := range stepStates {
+		stored[stepState.Step] = stepState.Status
+	}
+	derived := map[models.OnboardingStep]bool{
+		models.OnboardingStepTwoFactor: user.TwoFactorEnabled,
+		models.OnboardingStepProfile:   strings.TrimSpace(user.Name) != "",
+	}
+	for _, step := range []models.OnboardingStep{models.OnboardingStepTwoFactor, models.OnboardingStepProfile} {
+		status := models.OnboardingStepPending
+		switch {
+		case derived[step]:
+			status = models.OnboardingStepCompleted
+		case stored[step] != "":
+			status = stored[step]
+		}
+		if status == models.OnboardingStepPending {
+			state.Complete = false
+		}
+		state.Steps = append(state.Steps, OnboardingStepProgress{
+			Step:      step,
+			Status:    status,
+			Skippable: skippableSteps[step],
+		})
+	}
+
+	return state
+}
+
+// AcceptPolicy records the user accepting the current version of a policy. version must be
+// the current one so users cannot accept text they were not shown.
+func (s *OnboardingService) AcceptPolicy(userID uuid.UUID, key, version, ipAddress, userAgent string) (*models.PolicyAcceptance, error) {
+	settings, err := s.Settings()
+	if err != nil {
+		return nil, err
+	}
+	var policy *OnboardingPolicy
+	for i := range settings.Policies {
+		if settings.Policies[i].Key == key {
+			policy = &settings.Policies[i]
+		}
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("policy not found")
+	}
+	if version != policy.Version {
+		return nil, fmt.Errorf("invalid version: the current version of %s is %s", policy.Key, policy.Version)
+	}
+
+	var existing models.PolicyAcceptance
+	err = s.db.Where("user_id = ? AND policy = ? AND version = ? AND revoked_at IS NULL", userID, key, version).First(&existing).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	acceptance := &models.PolicyAcceptance{
+		UserID:     userID,
+		Policy:     key,
+		Version:    version,
+		AcceptedAt: time.Now(),
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(acceptance).Error; err != nil {
+			return err
+		}
+		event := models.NewAuthEvent(&userID, models.EventTypePolicyAccepted, ipAddress, userAgent)
+		if encoded, err := json.Marshal(map[string]string{"policy": key, "version": version}); err == nil {
+			event.Metadata = string(encoded)
+		}
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record policy acceptance: %w", err)
+	}
+	return acceptance, nil
+}
+
+// SkipStep dismisses a skippable setup step
+func (s *OnboardingService) SkipStep(userID uuid.UUID, step models.OnboardingStep) error {
+	if step != models.OnboardingStepTwoFactor && step != models.OnboardingStepProfile {
+		return fmt.Errorf("step not found")
+	}
+	if !skippableSteps[step] {
+		return fmt.Errorf("invalid step: %s cannot be skipped", step)
+	}
+
+	var stepState models.OnboardingStepState
+	err := s.db.Where("user_id = ? AND step = ?", userID, step).First(&stepState).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		stepState = models.OnboardingStepState{UserID: userID, Step: step}
+	} else if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	stepState.Status = models.OnboardingStepSkipped
+	if err := s.db.Save(&stepState).Error; err != nil {
+		return fmt.Errorf("failed to save onboarding step: %w", err)
+	}
+	return nil
+}
+
+// ResetOnboarding makes a user go through onboarding again: their policy acceptances are
+// revoked (and kept as evidence) and their skipped steps are pending again
+func (s *OnboardingService) ResetOnboarding(userID, adminID uuid.UUID, resetPolicies, resetSteps bool, ipAddress, userAgent string) error {
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if resetPolicies {
+			if err := tx.Model(&models.PolicyAcceptance{}).
+				Where("user_id = ? AND revoked_at IS NULL", userID).
+				Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_by": adminID}).Error; err != nil {
+				return err
+			}
+		}
+		if resetSteps {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&models.OnboardingStepState{}).Error; err != nil {
+				return err
+			}
+		}
+
+		event := models.NewAuthEvent(&userID, models.EventTypeOnboardingReset, ipAddress, userAgent)
+		if encoded, err := json.Marshal(map[string]interface{}{
+			"reset_by": adminID.String(),
+			"policies": resetPolicies,
+			"steps":    resetSteps,
+		}); err == nil {
+			event.Metadata = string(encoded)
+		}
+		return tx.Create(event).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reset onboarding: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("user_id", userID.String()).
+		Str("admin_id", adminID.String()).
+		Bool("policies", resetPolicies).
+		Bool("steps", resetSteps).
+		Msg("Onboarding reset")
+	return nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// orgChangeSet tracks the organizations whose assets changed since a background job last
+// ran. Writes without an organization in context mark every organization.
+type orgChangeSet struct {
+	mu   sync.Mutex
+	all  bool
+	orgs map[uuid.UUID]bool
+}
+
+// newOrgChangeSet returns a change set that starts fully dirty, so a job's first run after
+// startup processes every organization
+func newOrgChangeSet() *orgChangeSet {
+	return &orgChangeSet{all: true, orgs: make(map[uuid.UUID]bool)}
+}
+
+// mark records a change for an organization, or for every organization when orgID is nil
+func (c *orgChangeSet) mark(orgID *uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if orgID == nil {
+		c.all = true
+		return
+	}
+	c.orgs[*orgID] = true
+}
+
+// take returns and clears the pending changes
+func (c *orgChangeSet) take() (bool, []uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	all := c.all
+	orgIDs := make([]uuid.UUID, 0, len(c.orgs))
+	for id := range c.orgs {
+		orgIDs = append(orgIDs, id)
+	}
+	c.all = false
+	c.orgs = make(map[uuid.UUID]bool)
+	return all, orgIDs
+}
+
+// restore re-marks changes that could not be processed
+func (c *orgChangeSet) restore(all bool, orgIDs []uuid.UUID) {
+	if all {
+		c.mark(nil)
+	}
+	for i := range orgIDs {
+		c.mark(&orgIDs[i])
+	}
+}
+
+// orgIDsOf wraps an optional organization ID as a list
+func orgIDsOf(orgID *uuid.UUID) []uuid.UUID {
+	if orgID == nil {
+		return nil
+	}
+	return []uuid.UUID{*orgID}
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var orgSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,98}[a-z0-9]$`)
+
+// OrganizationService manages organizations (tenants) and resolves the organization of a request
+type OrganizationService struct {
+	db *gorm.DB
+}
+
+// NewOrganizationService creates a new organization service
+func NewOrganizationService() *OrganizationService {
+	return &OrganizationService{
+		db: database.GetDB(),
+	}
+}
+
+// CreateOrganizationRequest is the payload for creating an organization
+type CreateOrganizationRequest struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+}
+
+// UpdateOrganizationRequest is the payload for updating an organization
+type UpdateOrganizationRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Active      *bool   `json:"active"`
+}
+
+// List returns all organizations
+func (s *OrganizationService) List() ([]models.Organization, error) {
+	var orgs []models.Organization
+	if err := s.db.Order("name ASC").Find(&orgs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+// GetByID retrieves an organization by ID
+func (s *OrganizationService) GetByID(id uuid.UUID) (*models.Organization, error) {
+	var org models.Organization
+	if err := s.db.Where("id = ?", id).First(&org).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("organization not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &org, nil
+}
+
+// Create creates a new organization
+func (s *OrganizationService) Create(req CreateOrganizationRequest) (*models.Organization, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	slug := strings.ToLower(strings.TrimSpace(req.Slug))
+	if !orgSlugPattern.MatchString(slug) {
+		return nil, fmt.Errorf("slug must be 3-100 lowercase letters, digits or hyphens")
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Organization{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("organization '%s' already exists", slug)
+	}
+
+	org := &models.Organization{
+		Name:        name,
+		Slug:        slug,
+		Description: req.Description,
+		Active:      true,
+	}
+	if err := s.db.Create(org).Error; err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("organization_id", org.ID.String()).
+		Str("slug", slug).
+		Msg("Organization created")
+
+	return org, nil
+}
+
+// Update updates an organization's name, description or active flag
+func (s *OrganizationService) Update(id uuid.UUID, req UpdateOrganizationRequest) (*models.Organization, error) {
+	org, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("name is required")
+		}
+		org.Name = name
+	}
+	if req.Description != nil {
+		org.Description = *req.Description
+	}
+	if req.Active != nil {
+		if org.IsPlatform && !*req.Active {
+			return nil, fmt.Errorf("the platform organization cannot be deactivated")
+		}
+		org.Active = *req.Active
+	}
+
+	if err := s.db.Save(org).Error; err != nil {
+		return nil, fmt.Errorf("failed to update organization: %w", err)
+	}
+	return org, nil
+}
+
+// Delete soft-deletes an organization that no longer owns any users
+func (s *OrganizationService) Delete(id uuid.UUID) error {
+	org, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if org.IsPlatform {
+		return fmt.Errorf("the platform organization cannot be deleted")
+	}
+
+	var users int64
+	if err := s.db.Model(&models.User{}).Where("org_id = ?", id).Count(&users).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if users > 0 {
+		return fmt.Errorf("organization still has %d users", users)
+	}
+
+	if err := s.db.Delete(org).Error; err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	return nil
+}
+
+// AssignUser moves a user (and the user's API keys) into an organization
+func (s *OrganizationService) AssignUser(orgID, userID uuid.UUID) error {
+	org, err := s.GetByID(orgID)
+	if err != nil {
+		return err
+	}
+	if !org.Active {
+		return fmt.Errorf("organization is not active")
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).Where("id = ?", userID).Update("org_id", orgID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to assign user: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("user not found")
+		}
+		if err := tx.Model(&models.APIKey{}).Where("user_id = ?", userID).Update("org_id", orgID).Error; err != nil {
+			return fmt.Errorf("failed to move API keys: %w", err)
+		}
+		return nil
+	})
+}
+
+// IsPlatformUser reports whether a user belongs to the platform organization
+func (s *OrganizationService) IsPlatformUser(user *models.User) bool {
+	orgID := tenant.DefaultOrgID()
+	if user.OrgID != nil {
+		orgID = *user.OrgID
+	}
+	org, err := s.GetByID(orgID)
+	return err == nil && org.IsPlatform
+}
+
+// ResolveRequestOrg returns the organization a request runs in. By default this is the
+// organization of the user (or API key); platform administrators may act in another
+// organization by naming it in requestedOrg.
+func (s *OrganizationService) ResolveRequestOrg(user *models.User, keyOrgID *uuid.UUID, requestedOrg string) (uuid.UUID, error) {
+	orgID := tenant.DefaultOrgID()
+	if user.OrgID != nil {
+		orgID = *user.OrgID
+	}
+	if keyOrgID != nil {
+		orgID = *keyOrgID
+	}
+
+	if requestedOrg == "" {
+		return orgID, nil
+	}
+
+	requested, err := uuid.Parse(requestedOrg)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid organization ID")
+	}
+	if requested == orgID {
+		return orgID, nil
+	}
+
+	if !s.IsPlatformUser(user) {
+		return uuid.Nil, fmt.Errorf("organization switching not permitted")
+	}
+	allowed, err := NewRoleService().CheckPermission(user.ID, "organization", "manage")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !allowed {
+		return uuid.Nil, fmt.Errorf("organization switching not permitted")
+	}
+
+	target, err := s.GetByID(requested)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !target.Active {
+		return uuid.Nil, fmt.Errorf("organization is not active")
+	}
+	return target.ID, nil
+}
@@ -164,13 +164,8 @@ func (s *PasswordService) ResetPassword(token, newPassword, ipAddress, userAgent
 		return nil, fmt.Errorf("failed to mark token as used: %w", err)
 	}
 
-	// Invalidate all active sessions for security
-	if err := tx.Model(&models.Session{}).
-		Where("user_id = ? AND is_active = ?", user.ID, true).
-		Updates(map[string]interface{}{
-			"is_active":  false,
-			"revoked_at": time.Now(),
-		}).Error; err != nil {
+	// Invalidate all active sessions and their refresh chains for security
+	if _, err := RevokeUserSessions(tx, user.ID, nil, models.SessionRevokePassword); err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to revoke sessions after password reset")
 		// Don't fail the reset if session revocation fails
 	}
@@ -0,0 +1,443 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Resolutions recorded on policy violations
+const (
+	PolicyResolutionNoLongerViolating = "Vulnerability no longer violates the rule"
+	PolicyResolutionRuleDisabled      = "Rule disabled"
+	PolicyResolutionRuleDeleted       = "Rule deleted"
+)
+
+// PolicyViolationService manages policy rules and tracks the vulnerabilities violating them
+type PolicyViolationService struct {
+	db *gorm.DB
+}
+
+// NewPolicyViolationService creates a new policy violation service
+func NewPolicyViolationService(db *gorm.DB) *PolicyViolationService {
+	return &PolicyViolationService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *PolicyViolationService) WithContext(ctx context.Context) *PolicyViolationService {
+	return &PolicyViolationService{db: s.db.WithContext(ctx)}
+}
+
+// PolicyRuleRequest represents a create or update policy rule request
+type PolicyRuleRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Enabled     *bool   `json:"enabled,omitempty"`
+	Severity    *string `json:"severity,omitempty"`
+	MaxAgeDays  *int    `json:"max_age_days,omitempty"`
+	Environment *string `json:"environment,omitempty"` // "" matches any environment
+	Criticality *string `json:"criticality,omitempty"` // "" matches any criticality
+}
+
+// applyTo copies the provided request fields onto a rule
+func (req PolicyRuleRequest) applyTo(rule *models.PolicyRule) {
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		rule.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.Severity != nil {
+		rule.Severity = models.VulnerabilitySeverity(strings.ToUpper(strings.TrimSpace(*req.Severity)))
+	}
+	if req.MaxAgeDays != nil {
+		rule.MaxAgeDays = *req.MaxAgeDays
+	}
+	if req.Environment != nil {
+		rule.Environment = models.Environment(strings.ToUpper(strings.TrimSpace(*req.Environment)))
+	}
+	if req.Criticality != nil {
+		if value := strings.ToUpper(strings.TrimSpace(*req.Criticality)); value == "" {
+			rule.Criticality = nil
+		} else {
+			criticality := models.AssetCriticality(value)
+			rule.Criticality = &criticality
+		}
+	}
+}
+
+// ValidatePolicyRule checks that a rule has a name, a valid severity and age, and valid asset criteria
+func ValidatePolicyRule(rule *models.PolicyRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch rule.Severity {
+	case models.SeverityCritical, models.SeverityHigh, models.SeverityMedium, models.SeverityLow, models.SeverityNone:
+	default:
+		return fmt.Errorf("invalid severity, must be one of: CRITICAL, HIGH, MEDIUM, LOW, NONE")
+	}
+	if rule.MaxAgeDays < 0 {
+		return fmt.Errorf("invalid max_age_days, must be zero or more")
+	}
+	switch rule.Environment {
+	case "", models.EnvProduction, models.EnvStaging, models.EnvDevelopment, models.EnvTest:
+	default:
+		return fmt.Errorf("invalid environment, must be one of: PRODUCTION, STAGING, DEVELOPMENT, TEST")
+	}
+	if rule.Criticality != nil {
+		switch *rule.Criticality {
+		case models.CriticalityCritical, models.CriticalityHigh, models.CriticalityMedium, models.CriticalityLow:
+		default:
+			return fmt.Errorf("invalid criticality, must be one of: CRITICAL, HIGH, MEDIUM, LOW")
+		}
+	}
+	return nil
+}
+
+// PolicyRuleCutoff returns the discovery time before which an open vulnerability violates the rule
+func PolicyRuleCutoff(rule *models.PolicyRule, now time.Time) time.Time {
+	return now.AddDate(0, 0, -rule.MaxAgeDays)
+}
+
+// ListRules returns all policy rules, newest first
+func (s *PolicyViolationService) ListRules(enabledOnly bool) ([]models.PolicyRule, error) {
+	query := s.db.Preload("CreatedBy").Order("created_at DESC")
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+
+	var rules []models.PolicyRule
+	if err := query.Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list policy rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetRule returns a single policy rule
+func (s *PolicyViolationService) GetRule(id uuid.UUID) (*models.PolicyRule, error) {
+	var rule models.PolicyRule
+	if err := s.db.Preload("CreatedBy").First(&rule, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("policy rule not found")
+		}
+		return nil, fmt.Errorf("failed to get policy rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// CreateRule creates a new policy rule; it is evaluated by the next policy evaluation run
+func (s *PolicyViolationService) CreateRule(req PolicyRuleRequest, createdByID uuid.UUID) (*models.PolicyRule, error) {
+	rule := &models.PolicyRule{
+		Enabled:     true,
+		CreatedByID: createdByID,
+	}
+	req.applyTo(rule)
+	if req.MaxAgeDays == nil {
+		return nil, fmt.Errorf("max_age_days is required")
+	}
+
+	if err := ValidatePolicyRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := createWithZeroValues(s.db, rule, "enabled"); err != nil {
+		return nil, fmt.Errorf("failed to create policy rule: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("rule_id", rule.ID.String()).
+		Str("created_by", createdByID.String()).
+		Str("severity", string(rule.Severity)).
+		Int("max_age_days", rule.MaxAgeDays).
+		Msg("Policy rule created")
+
+	return s.GetRule(rule.ID)
+}
+
+// UpdateRule updates an existing policy rule; open violations are re-checked by the next evaluation run
+func (s *PolicyViolationService) UpdateRule(id uuid.UUID, req PolicyRuleRequest, updatedByID uuid.UUID) (*models.PolicyRule, error) {
+	rule, err := s.GetRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.applyTo(rule)
+	if err := ValidatePolicyRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(rule).Select(
+		"name", "description", "enabled", "severity", "max_age_days", "environment", "criticality",
+	).Updates(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to update policy rule: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("rule_id", id.String()).
+		Str("updated_by", updatedByID.String()).
+		Msg("Policy rule updated")
+
+	return s.GetRule(id)
+}
+
+// DeleteRule soft deletes a policy rule and resolves its open violations
+func (s *PolicyViolationService) DeleteRule(id, deletedByID uuid.UUID) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.PolicyRule{}, "id = ?", id)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete policy rule: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("policy rule not found")
+		}
+
+		if err := tx.Model(&models.PolicyViolation{}).
+			Where("rule_id = ? AND status = ?", id, models.PolicyViolationOpen).
+			Updates(map[string]interface{}{
+				"status":      models.PolicyViolationResolved,
+				"resolved_at": time.Now(),
+				"resolution":  PolicyResolutionRuleDeleted,
+			}).Error; err != nil {
+			return fmt.Errorf("failed to resolve policy violations: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	utils.Logger.Info().
+		Str("rule_id", id.String()).
+		Str("deleted_by", deletedByID.String()).
+		Msg("Policy rule deleted")
+
+	return nil
+}
+
+// PolicyMatch is an open vulnerability currently violating a rule
+type PolicyMatch struct {
+	VulnerabilityID uuid.UUID
+	OrgID           *uuid.UUID
+	Severity        models.VulnerabilitySeverity
+	DiscoveredAt    time.Time
+}
+
+// PolicyEvaluationResult summarizes a policy evaluation run
+type PolicyEvaluationResult struct {
+	RulesEvaluated int `json:"rules_evaluated"`
+	Opened         int `json:"opened"`
+	Resolved       int `json:"resolved"`
+}
+
+// ReconcilePolicyViolations compares a rule's open violations with the vulnerabilities currently
+// violating it, returning the matches needing a new violation and the violations to resolve
+func ReconcilePolicyViolations(open []models.PolicyViolation, matches []PolicyMatch) ([]PolicyMatch, []models.PolicyViolation) {
+	matched := make(map[uuid.UUID]bool, len(matches))
+	for _, match := range matches {
+		matched[match.VulnerabilityID] = true
+	}
+	existing := make(map[uuid.UUID]bool, len(open))
+	var resolve []models.PolicyViolation
+	for _, violation := range open {
+		existing[violation.VulnerabilityID] = true
+		if !matched[violation.VulnerabilityID] {
+			resolve = append(resolve, violation)
+		}
+	}
+
+	var create []PolicyMatch
+	for _, match := range matches {
+		if !existing[match.VulnerabilityID] {
+			create = append(create, match)
+			existing[match.VulnerabilityID] = true
+		}
+	}
+	return create, resolve
+}
+
+// Evaluate checks every policy rule against the open vulnerabilities, opening violations for
+// newly violating vulnerabilities and resolving those that no longer violate their rule
+func (s *PolicyViolationService) Evaluate(now time.Time) (*PolicyEvaluationResult, error) {
+	// Deleted rules are included while they still have open violations (e.g. of other tenants)
+	var rules []models.PolicyRule
+	if err := s.db.Unscoped().
+		Where("deleted_at IS NULL OR id IN (?)", s.db.Table("policy_violations").Select("rule_id").Where("status = ?", models.PolicyViolationOpen)).
+		Order("created_at").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load policy rules: %w", err)
+	}
+
+	result := &PolicyEvaluationResult{}
+	for i := range rules {
+		opened, resolved, err := s.evaluateRule(&rules[i], now)
+		if err != nil {
+			return result, fmt.Errorf("failed to evaluate policy rule %s: %w", rules[i].ID, err)
+		}
+		result.RulesEvaluated++
+		result.Opened += opened
+		result.Resolved += resolved
+	}
+	return result, nil
+}
+
+// evaluateRule reconciles the open violations of one rule
+func (s *PolicyViolationService) evaluateRule(rule *models.PolicyRule, now time.Time) (int, int, error) {
+	var matches []PolicyMatch
+	resolution := PolicyResolutionRuleDisabled
+	if rule.DeletedAt.Valid {
+		resolution = PolicyResolutionRuleDeleted
+	} else if rule.Enabled {
+		var err error
+		if matches, err = s.matchRule(rule, now); err != nil {
+			return 0, 0, err
+		}
+		resolution = PolicyResolutionNoLongerViolating
+	}
+
+	var create []PolicyMatch
+	var resolve []models.PolicyViolation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var open []models.PolicyViolation
+		if err := tx.Where("rule_id = ? AND status = ?", rule.ID, models.PolicyViolationOpen).
+			Find(&open).Error; err != nil {
+			return fmt.Errorf("failed to load open violations: %w", err)
+		}
+		create, resolve = ReconcilePolicyViolations(open, matches)
+
+		for _, match := range create {
+			violation := &models.PolicyViolation{
+				OrgID:           match.OrgID,
+				RuleID:          rule.ID,
+				VulnerabilityID: match.VulnerabilityID,
+				Status:          models.PolicyViolationOpen,
+				Severity:        match.Severity,
+				DiscoveredAt:    match.DiscoveredAt,
+				DueAt:           match.DiscoveredAt.AddDate(0, 0, rule.MaxAgeDays),
+				DetectedAt:      now,
+			}
+			if err := tx.Create(violation).Error; err != nil {
+				return fmt.Errorf("failed to record policy violation: %w", err)
+			}
+		}
+
+		if len(resolve) > 0 {
+			ids := make([]uuid.UUID, len(resolve))
+			for i, violation := range resolve {
+				ids[i] = violation.ID
+			}
+			if err := tx.Model(&models.PolicyViolation{}).
+				Where("id IN ?", ids).
+				Updates(map[string]interface{}{
+					"status":      models.PolicyViolationResolved,
+					"resolved_at": now,
+					"resolution":  resolution,
+				}).Error; err != nil {
+				return fmt.Errorf("failed to resolve policy violations: %w", err)
+			}
+		}
+
+		return tx.Model(rule).UpdateColumn("last_evaluated_at", now).Error
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(create), len(resolve), nil
+}
+
+// matchRule returns the open vulnerabilities currently violating an enabled rule
+func (s *PolicyViolationService) matchRule(rule *models.PolicyRule, now time.Time) ([]PolicyMatch, error) {
+	discoveredAt := "LEAST(vulnerabilities.discovery_date, vulnerabilities.created_at)"
+	query := s.db.Model(&models.Vulnerability{}).
+		Select("vulnerabilities.id AS vulnerability_id, vulnerabilities.org_id, vulnerabilities.severity, "+discoveredAt+" AS discovered_at").
+		Where("vulnerabilities.severity = ? AND vulnerabilities.status IN ?", rule.Severity, openStatuses).
+		Where(discoveredAt+" < ?", PolicyRuleCutoff(rule, now))
+
+	// Only unpatched links to live assets matching the asset criteria count
+	if rule.Environment != "" || rule.Criticality != nil {
+		assets := s.db.Table("vulnerability_affected_systems").
+			Select("1").
+			Joins("JOIN affected_systems ON affected_systems.id = vulnerability_affected_systems.affected_system_id").
+			Where("vulnerability_affected_systems.vulnerability_id = vulnerabilities.id").
+			Where("vulnerability_affected_systems.patched_at IS NULL AND affected_systems.deleted_at IS NULL")
+		if rule.Environment != "" {
+			assets = assets.Where("affected_systems.environment = ?", rule.Environment)
+		}
+		if rule.Criticality != nil {
+			assets = assets.Where("affected_systems.criticality = ?", *rule.Criticality)
+		}
+		query = query.Where("EXISTS (?)", assets)
+	}
+
+	var matches []PolicyMatch
+	if err := query.Scan(&matches).Error; err != nil {
+		return nil, fmt.Errorf("failed to match vulnerabilities: %w", err)
+	}
+	return matches, nil
+}
+
+// PolicyViolationFilter narrows a policy violation listing
+type PolicyViolationFilter struct {
+	Status          models.PolicyViolationStatus
+	RuleID          *uuid.UUID
+	VulnerabilityID *uuid.UUID
+	Severity        models.VulnerabilitySeverity
+}
+
+// ListViolations returns policy violations matching the filter, most recently detected first
+func (s *PolicyViolationService) ListViolations(filter PolicyViolationFilter, page, limit int) ([]models.PolicyViolation, int64, error) {
+	query := s.db.Model(&models.PolicyViolation{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.RuleID != nil {
+		query = query.Where("rule_id = ?", *filter.RuleID)
+	}
+	if filter.VulnerabilityID != nil {
+		query = query.Where("vulnerability_id = ?", *filter.VulnerabilityID)
+	}
+	if filter.Severity != "" {
+		query = query.Where("severity = ?", filter.Severity)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count policy violations: %w", err)
+	}
+
+	var violations []models.PolicyViolation
+	if err := query.
+		Preload("Rule", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Vulnerability").
+		Order("detected_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&violations).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list policy violations: %w", err)
+	}
+
+	return violations, total, nil
+}
+
+// GetViolation returns a single policy violation
+func (s *PolicyViolationService) GetViolation(id uuid.UUID) (*models.PolicyViolation, error) {
+	var violation models.PolicyViolation
+	if err := s.db.
+		Preload("Rule", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Vulnerability").
+		First(&violation, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("policy violation not found")
+		}
+		return nil, fmt.Errorf("failed to get policy violation: %w", err)
+	}
+	return &violation, nil
+}
@@ -2,7 +2,6 @@ package services
 
 import (
 	"fmt"
-	"time"
 
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
@@ -168,15 +167,10 @@ func (s *ProfileService) ChangePassword(userID uuid.UUID, req ChangePasswordRequ
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
-	// Revoke all active sessions except current one for security
+	// Revoke all active sessions and their refresh chains for security
 	// Note: We don't have session token here, so we revoke all sessions
 	// The user will need to log in again
-	if err := tx.Model(&models.Session{}).
-		Where("user_id = ? AND is_active = ?", userID, true).
-		Updates(map[string]interface{}{
-			"is_active":  false,
-			"revoked_at": time.Now(),
-		}).Error; err != nil {
+	if _, err := RevokeUserSessions(tx, userID, nil, models.SessionRevokePassword); err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to revoke sessions after password change")
 	}
 
@@ -223,28 +217,20 @@ func (s *ProfileService) RevokeSession(userID, sessionID uuid.UUID) error {
 	return sessionService.RevokeSessionByID(sessionID, userID)
 }
 
-// RevokeAllSessions revokes all sessions except the current one
-func (s *ProfileService) RevokeAllSessions(userID uuid.UUID, exceptSessionID *uuid.UUID) error {
-	query := s.db.Model(&models.Session{}).
-		Where("user_id = ? AND is_active = ?", userID, true)
-
-	if exceptSessionID != nil {
-		query = query.Where("id != ?", *exceptSessionID)
+// RevokeAllSessions revokes all sessions except the current one, including their refresh
+// chains, and returns how many were revoked
+func (s *ProfileService) RevokeAllSessions(userID uuid.UUID, exceptSessionID *uuid.UUID, ipAddress, userAgent string) (int64, error) {
+	sessionService := NewSessionService()
+	count, err := sessionService.RevokeOtherSessions(userID, exceptSessionID, models.SessionRevokeOthers)
+	if err != nil {
+		return 0, err
 	}
 
-	result := query.Updates(map[string]interface{}{
-		"is_active":  false,
-		"revoked_at": time.Now(),
-	})
-
-	if result.Error != nil {
-		return fmt.Errorf("failed to revoke sessions: %w", result.Error)
+	event := models.NewAuthEvent(&userID, models.EventTypeSessionRevoked, ipAddress, userAgent)
+	event.Metadata = fmt.Sprintf(`{"revoked_sessions":%d,"scope":"others"}`, count)
+	if err := s.db.Create(event).Error; err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to log session revocation event")
 	}
 
-	utils.Logger.Info().
-		Str("user_id", userID.String()).
-		Int64("count", result.RowsAffected).
-		Msg("Sessions revoked")
-
-	return nil
+	return count, nil
 }
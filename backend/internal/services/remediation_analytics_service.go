@@ -0,0 +1,563 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/i18n"
+	"github.com/cyops/cyops-backend/pkg/telemetry"
+	"gorm.io/gorm"
+)
+
+// Burn-down intervals
+const (
+	BurnDownIntervalDay  = "day"
+	BurnDownIntervalWeek = "week"
+)
+
+// burnDownMaxDailyDays is the longest period charted per day; longer periods are charted per week
+const burnDownMaxDailyDays = 90
+
+// remediatedStatuses are the statuses a vulnerability is remediated in. False positives are
+// closed without a fix and don't count towards time to remediate.
+var remediatedStatuses = []models.VulnerabilityStatus{models.StatusResolved, models.StatusVerified, models.StatusClosed}
+
+// openStatuses are the statuses a vulnerability still needs work in
+var openStatuses = []models.VulnerabilityStatus{models.StatusOpen, models.StatusInProgress}
+
+// agingBuckets are the age ranges (in days, inclusive) open vulnerabilities are grouped into
+var agingBuckets = []struct {
+	label   string
+	minDays int
+	maxDays int // 0 means unbounded
+}{
+	{"0-7 days", 0, 7},
+	{"8-30 days", 8, 30},
+	{"31-60 days", 31, 60},
+	{"61-90 days", 61, 90},
+	{"91-180 days", 91, 180},
+	{"180+ days", 181, 0},
+}
+
+// RemediationAnalyticsService computes remediation metrics for dashboards: mean time to
+// remediate from the status history, the age of open vulnerabilities, and burn-down data
+type RemediationAnalyticsService struct {
+	db     *gorm.DB
+	loc    *time.Location // Day and month boundaries; UTC unless set with InLocation
+	filter ReportFilter   // Assets the metrics cover; all unless set with WithFilter
+}
+
+// NewRemediationAnalyticsService creates a new remediation analytics service
+func NewRemediationAnalyticsService(db *gorm.DB) *RemediationAnalyticsService {
+	return &RemediationAnalyticsService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *RemediationAnalyticsService) WithContext(ctx context.Context) *RemediationAnalyticsService {
+	return &RemediationAnalyticsService{db: s.db.WithContext(ctx), loc: s.loc, filter: s.filter}
+}
+
+// InLocation returns a copy of the service that buckets days and months in loc, such as the
+// requesting user's timezone
+func (s *RemediationAnalyticsService) InLocation(loc *time.Location) *RemediationAnalyticsService {
+	return &RemediationAnalyticsService{db: s.db, loc: loc, filter: s.filter}
+}
+
+// WithFilter returns a copy of the service whose metrics cover only the vulnerabilities
+// affecting assets that match filter
+func (s *RemediationAnalyticsService) WithFilter(filter ReportFilter) *RemediationAnalyticsService {
+	return &RemediationAnalyticsService{db: s.db, loc: s.loc, filter: filter}
+}
+
+// location returns the timezone of day and month boundaries
+func (s *RemediationAnalyticsService) location() *time.Location {
+	if s.loc == nil {
+		return time.UTC
+	}
+	return s.loc
+}
+
+// RemediationAnalytics bundles all remediation metrics for a period
+type RemediationAnalytics struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	PeriodStart time.Time         `json:"period_start"`
+	PeriodEnd   time.Time         `json:"period_end"`
+	MTTR        MTTRAnalytics     `json:"mttr"`
+	Aging       AgingAnalytics    `json:"aging"`
+	BurnDown    BurnDownAnalytics `json:"burn_down"`
+}
+
+// MTTRStats summarizes the time taken to remediate a set of vulnerabilities
+type MTTRStats struct {
+	Remediated  int64   `json:"remediated"`
+	AverageDays float64 `json:"average_days"`
+	MedianDays  float64 `json:"median_days"`
+}
+
+// MTTRGroup is the time to remediate of one severity, team or month
+type MTTRGroup struct {
+	Key   string `json:"key"`   // Severity, team ID ("" for no owning team) or month (YYYY-MM)
+	Label string `json:"label"` // Display name
+	MTTRStats
+}
+
+// MTTRAnalytics is the mean time to remediate of vulnerabilities remediated in a period
+type MTTRAnalytics struct {
+	Overall    MTTRStats   `json:"overall"`
+	BySeverity []MTTRGroup `json:"by_severity"` // Most severe first
+	ByTeam     []MTTRGroup `json:"by_team"`     // Slowest first
+	ByMonth    []MTTRGroup `json:"by_month"`    // Oldest first
+}
+
+// RemediationSample is one remediated vulnerability
+type RemediationSample struct {
+	VulnerabilityID uuid.UUID
+	Severity        models.VulnerabilitySeverity
+	TeamID          *uuid.UUID
+	TeamName        string
+	DiscoveredAt    time.Time
+	RemediatedAt    time.Time
+}
+
+// AgingBucket counts the open vulnerabilities within an age range
+type AgingBucket struct {
+	Label      string           `json:"label"`
+	MinDays    int              `json:"min_days"`
+	MaxDays    *int             `json:"max_days,omitempty"` // Omitted for the open-ended oldest bucket
+	Total      int64            `json:"total"`
+	BySeverity map[string]int64 `json:"by_severity"`
+}
+
+// AgingAnalytics is the age distribution of the currently open vulnerabilities
+type AgingAnalytics struct {
+	AsOf           time.Time     `json:"as_of"`
+	TotalOpen      int64         `json:"total_open"`
+	AverageAgeDays float64       `json:"average_age_days"`
+	OldestAgeDays  int           `json:"oldest_age_days"`
+	Buckets        []AgingBucket `json:"buckets"`
+}
+
+// OpenVulnerabilityAge is the number of open vulnerabilities of one severity discovered on a day
+type OpenVulnerabilityAge struct {
+	Severity     string
+	DiscoveredOn time.Time
+	Count        int64
+}
+
+// BurnDownPoint is the vulnerability flow of one interval and the open backlog at its end
+type BurnDownPoint struct {
+	Date     time.Time `json:"date"` // Start of the interval
+	Opened   int64     `json:"opened"`
+	Closed   int64     `json:"closed"`
+	Reopened int64     `json:"reopened"`
+	Open     int64     `json:"open"`
+}
+
+// BurnDownAnalytics charts the open vulnerability backlog over a period
+type BurnDownAnalytics struct {
+	Interval  string          `json:"interval"`
+	StartOpen int64           `json:"start_open"`
+	EndOpen   int64           `json:"end_open"`
+	Points    []BurnDownPoint `json:"points"`
+}
+
+// DailyFlow is the number of vulnerabilities opened, closed and reopened on a day
+type DailyFlow struct {
+	Day      time.Time
+	Opened   int64
+	Closed   int64
+	Reopened int64
+}
+
+// GetAnalytics returns MTTR, aging and burn-down data for a period
+func (s *RemediationAnalyticsService) GetAnalytics(startDate, endDate time.Time) (analytics *RemediationAnalytics, err error) {
+	ctx, span := telemetry.StartSpan(s.db.Statement.Context, "RemediationAnalyticsService.GetAnalytics", reportSpanAttributes(startDate, endDate)...)
+	defer func() { telemetry.EndSpan(span, err) }()
+	traced := s.WithContext(ctx)
+
+	key := reportCacheKey(s.filter.reportKey("remediation_analytics:"+s.location().String()), startDate, endDate)
+	return cached(s.db.Statement.Context, ActiveCache(), CacheGroupReports, key, func() (*RemediationAnalytics, error) {
+		mttr, err := traced.MTTR(startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		aging, err := traced.Aging()
+		if err != nil {
+			return nil, err
+		}
+		burnDown, err := traced.BurnDown(startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		return &RemediationAnalytics{
+			GeneratedAt: time.Now(),
+			PeriodStart: startDate,
+			PeriodEnd:   endDate,
+			MTTR:        *mttr,
+			Aging:       *aging,
+			BurnDown:    *burnDown,
+		}, nil
+	})
+}
+
+// MTTR computes the mean time to remediate of vulnerabilities remediated in the period. A
+// vulnerability is remediated at its latest move into a remediated status, so a reopened and
+// fixed again vulnerability counts from discovery to the final fix.
+func (s *RemediationAnalyticsService) MTTR(startDate, endDate time.Time) (*MTTRAnalytics, error) {
+	remediations := s.db.Table("vulnerability_status_history").
+		Select("vulnerability_id, MAX(changed_at) AS remediated_at").
+		Where("new_status IN ? AND old_status NOT IN ?", remediatedStatuses, remediatedStatuses).
+		Group("vulnerability_id")
+
+	var samples []RemediationSample
+	if err := s.db.Model(&models.Vulnerability{}).
+		Scopes(s.filter.scopeVulnerabilities("vulnerabilities.id")).
+		Select(`vulnerabilities.id AS vulnerability_id, vulnerabilities.severity,
+			vulnerabilities.owner_team_id AS team_id, COALESCE(teams.name, '') AS team_name,
+			LEAST(vulnerabilities.discovery_date, vulnerabilities.created_at) AS discovered_at,
+			remediations.remediated_at`).
+		Joins("JOIN (?) AS remediations ON remediations.vulnerability_id = vulnerabilities.id", remediations).
+		Joins("LEFT JOIN teams ON teams.id = vulnerabilities.owner_team_id").
+		Where("vulnerabilities.status IN ? AND remediations.remediated_at BETWEEN ? AND ?", remediatedStatuses, startDate, endDate).
+		Scan(&samples).Error; err != nil {
+		return nil, fmt.Errorf("failed to load remediated vulnerabilities: %w", err)
+	}
+
+	mttr := ComputeMTTR(samples, s.location())
+	return &mttr, nil
+}
+
+// Aging returns the age distribution of the currently open vulnerabilities
+func (s *RemediationAnalyticsService) Aging() (*AgingAnalytics, error) {
+	var ages []OpenVulnerabilityAge
+	if err := s.db.Model(&models.Vulnerability{}).
+		Scopes(s.filter.scopeVulnerabilities("vulnerabilities.id")).
+		Select("severity, DATE(LEAST(discovery_date, created_at) AT TIME ZONE ?) AS discovered_on, COUNT(*) AS count", s.location().String()).
+		Where("status IN ?", openStatuses).
+		Group("severity, discovered_on").
+		Scan(&ages).Error; err != nil {
+		return nil, fmt.Errorf("failed to load open vulnerability ages: %w", err)
+	}
+
+	aging := BucketOpenVulnerabilityAges(time.Now().In(s.location()), ages)
+	return &aging, nil
+}
+
+// BurnDown charts the open backlog over the period. The backlog at the end of each interval is
+// derived from today's open count by undoing the flows recorded since.
+func (s *RemediationAnalyticsService) BurnDown(startDate, endDate time.Time) (*BurnDownAnalytics, error) {
+	var currentOpen int64
+	if err := s.db.Model(&models.Vulnerability{}).
+		Scopes(s.filter.scopeVulnerabilities("vulnerabilities.id")).
+		Where("status IN ?", openStatuses).
+		Count(&currentOpen).Error; err != nil {
+		return nil, fmt.Errorf("failed to count open vulnerabilities: %w", err)
+	}
+
+	flows := make(map[string]*DailyFlow)
+	flow := func(day time.Time) *DailyFlow {
+		key := day.Format("2006-01-02")
+		if flows[key] == nil {
+			flows[key] = &DailyFlow{Day: day}
+		}
+		return flows[key]
+	}
+
+	type dayCount struct {
+		Day   time.Time
+		Count int64
+	}
+
+	var opened []dayCount
+	if err := s.db.Model(&models.Vulnerability{}).
+		Scopes(s.filter.scopeVulnerabilities("vulnerabilities.id")).
+		Select("DATE(created_at AT TIME ZONE ?) AS day, COUNT(*) AS count", s.location().String()).
+		Where("created_at >= ?", startDate).
+		Group("day").
+		Scan(&opened).Error; err != nil {
+		return nil, fmt.Errorf("failed to count opened vulnerabilities: %w", err)
+	}
+	for _, dc := range opened {
+		flow(dc.Day).Opened += dc.Count
+	}
+
+	transitions := func(condition string) ([]dayCount, error) {
+		var counts []dayCount
+		err := s.db.Model(&models.Vulnerability{}).
+			Scopes(s.filter.scopeVulnerabilities("vulnerabilities.id")).
+			Select("DATE(vulnerability_status_history.changed_at AT TIME ZONE ?) AS day, COUNT(*) AS count", s.location().String()).
+			Joins("JOIN vulnerability_status_history ON vulnerability_status_history.vulnerability_id = vulnerabilities.id").
+			Where("vulnerability_status_history.changed_at >= ?", startDate).
+			Where(condition, openStatuses, openStatuses).
+			Group("day").
+			Scan(&counts).Error
+		return counts, err
+	}
+
+	closed, err := transitions("vulnerability_status_history.old_status IN ? AND vulnerability_status_history.new_status NOT IN ?")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count closed vulnerabilities: %w", err)
+	}
+	for _, dc := range closed {
+		flow(dc.Day).Closed += dc.Count
+	}
+
+	// The initial status entry of imported vulnerabilities has no old status and isn't a reopen
+	reopened, err := transitions("vulnerability_status_history.old_status NOT IN ? AND vulnerability_status_history.old_status <> '' AND vulnerability_status_history.new_status IN ?")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reopened vulnerabilities: %w", err)
+	}
+	for _, dc := range reopened {
+		flow(dc.Day).Reopened += dc.Count
+	}
+
+	daily := make([]DailyFlow, 0, len(flows))
+	for _, f := range flows {
+		daily = append(daily, *f)
+	}
+
+	burnDown := BuildBurnDown(startDate, endDate, currentOpen, daily)
+	return &burnDown, nil
+}
+
+// ComputeMTTR summarizes remediation times overall and by severity, owning team and month of
+// remediation in loc
+func ComputeMTTR(samples []RemediationSample, loc *time.Location) MTTRAnalytics {
+	var all []float64
+	bySeverity := make(map[string][]float64)
+	byTeam := make(map[string][]float64)
+	teamLabels := make(map[string]string)
+	byMonth := make(map[string][]float64)
+
+	for _, sample := range samples {
+		days := sample.RemediatedAt.Sub(sample.DiscoveredAt).Hours() / 24
+		if days < 0 {
+			days = 0
+		}
+		all = append(all, days)
+		bySeverity[string(sample.Severity)] = append(bySeverity[string(sample.Severity)], days)
+
+		teamKey, teamLabel := "", "Unassigned"
+		if sample.TeamID != nil {
+			teamKey, teamLabel = sample.TeamID.String(), sample.TeamName
+		}
+		byTeam[teamKey] = append(byTeam[teamKey], days)
+		teamLabels[teamKey] = teamLabel
+
+		month := sample.RemediatedAt.In(loc).Format("2006-01")
+		byMonth[month] = append(byMonth[month], days)
+	}
+
+	mttr := MTTRAnalytics{
+		Overall:    mttrStats(all),
+		BySeverity: []MTTRGroup{},
+		ByTeam:     []MTTRGroup{},
+		ByMonth:    []MTTRGroup{},
+	}
+
+	for _, severity := range []models.VulnerabilitySeverity{
+		models.SeverityCritical, models.SeverityHigh, models.SeverityMedium, models.SeverityLow, models.SeverityNone,
+	} {
+		if days, ok := bySeverity[string(severity)]; ok {
+			mttr.BySeverity = append(mttr.BySeverity, MTTRGroup{Key: string(severity), Label: string(severity), MTTRStats: mttrStats(days)})
+		}
+	}
+
+	for key, days := range byTeam {
+		mttr.ByTeam = append(mttr.ByTeam, MTTRGroup{Key: key, Label: teamLabels[key], MTTRStats: mttrStats(days)})
+	}
+	sort.Slice(mttr.ByTeam, func(i, j int) bool {
+		if mttr.ByTeam[i].AverageDays != mttr.ByTeam[j].AverageDays {
+			return mttr.ByTeam[i].AverageDays > mttr.ByTeam[j].AverageDays
+		}
+		return mttr.ByTeam[i].Label < mttr.ByTeam[j].Label
+	})
+
+	for month, days := range byMonth {
+		label := month
+		if t, err := time.Parse("2006-01", month); err == nil {
+			label = t.Format("Jan 2006")
+		}
+		mttr.ByMonth = append(mttr.ByMonth, MTTRGroup{Key: month, Label: label, MTTRStats: mttrStats(days)})
+	}
+	sort.Slice(mttr.ByMonth, func(i, j int) bool {
+		return mttr.ByMonth[i].Key < mttr.ByMonth[j].Key
+	})
+
+	return mttr
+}
+
+// mttrStats returns the count, mean and median of remediation times in days
+func mttrStats(days []float64) MTTRStats {
+	if len(days) == 0 {
+		return MTTRStats{}
+	}
+	sorted := append([]float64(nil), days...)
+	sort.Float64s(sorted)
+
+	total := 0.0
+	for _, d := range sorted {
+		total += d
+	}
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	return MTTRStats{
+		Remediated:  int64(len(sorted)),
+		AverageDays: roundDays(total / float64(len(sorted))),
+		MedianDays:  roundDays(median),
+	}
+}
+
+// BucketOpenVulnerabilityAges groups open vulnerabilities into age buckets as of now
+func BucketOpenVulnerabilityAges(now time.Time, ages []OpenVulnerabilityAge) AgingAnalytics {
+	aging := AgingAnalytics{AsOf: now}
+	for _, b := range agingBuckets {
+		bucket := AgingBucket{Label: b.label, MinDays: b.minDays, BySeverity: make(map[string]int64)}
+		if b.maxDays > 0 {
+			maxDays := b.maxDays
+			bucket.MaxDays = &maxDays
+		}
+		aging.Buckets = append(aging.Buckets, bucket)
+	}
+
+	today := truncateToDay(now)
+	var totalAge int64
+	for _, age := range ages {
+		days := int(today.Sub(truncateToDay(age.DiscoveredOn)).Hours() / 24)
+		if days < 0 {
+			days = 0
+		}
+		for i, b := range agingBuckets {
+			if days >= b.minDays && (b.maxDays == 0 || days <= b.maxDays) {
+				aging.Buckets[i].Total += age.Count
+				aging.Buckets[i].BySeverity[age.Severity] += age.Count
+				break
+			}
+		}
+		aging.TotalOpen += age.Count
+		totalAge += int64(days) * age.Count
+		if days > aging.OldestAgeDays {
+			aging.OldestAgeDays = days
+		}
+	}
+	if aging.TotalOpen > 0 {
+		aging.AverageAgeDays = roundDays(float64(totalAge) / float64(aging.TotalOpen))
+	}
+	return aging
+}
+
+// BuildBurnDown charts the open backlog from startDate to endDate. currentOpen is today's open
+// count and daily holds the flows from startDate up to today; the backlog at the end of each
+// interval is currentOpen minus the net flow after it. Periods longer than 90 days are charted
+// per week.
+func BuildBurnDown(startDate, endDate time.Time, currentOpen int64, daily []DailyFlow) BurnDownAnalytics {
+	start, end := truncateToDay(startDate), truncateToDay(endDate)
+	step, interval := 1, BurnDownIntervalDay
+	if end.Sub(start).Hours()/24 > burnDownMaxDailyDays {
+		step, interval = 7, BurnDownIntervalWeek
+	}
+
+	burnDown := BurnDownAnalytics{Interval: interval, Points: []BurnDownPoint{}}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, step) {
+		burnDown.Points = append(burnDown.Points, BurnDownPoint{Date: day})
+	}
+
+	// netAfter[i] is the net backlog change after the end of interval i
+	netAfter := make([]int64, len(burnDown.Points))
+	for _, f := range daily {
+		day := truncateToDay(f.Day)
+		if day.Before(start) {
+			continue
+		}
+		net := f.Opened + f.Reopened - f.Closed
+		i := int(day.Sub(start).Hours()/24) / step
+		if i < len(burnDown.Points) && !day.After(end) {
+			burnDown.Points[i].Opened += f.Opened
+			burnDown.Points[i].Closed += f.Closed
+			burnDown.Points[i].Reopened += f.Reopened
+		}
+		for j := 0; j < len(netAfter) && j < i; j++ {
+			netAfter[j] += net
+		}
+	}
+
+	for i := range burnDown.Points {
+		burnDown.Points[i].Open = currentOpen - netAfter[i]
+	}
+	if len(burnDown.Points) > 0 {
+		first := burnDown.Points[0]
+		burnDown.StartOpen = first.Open - first.Opened - first.Reopened + first.Closed
+		burnDown.EndOpen = burnDown.Points[len(burnDown.Points)-1].Open
+	}
+	return burnDown
+}
+
+// truncateToDay returns midnight UTC of the day t falls on in its own location
+func truncateToDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// roundDays rounds a number of days to two decimals
+func roundDays(days float64) float64 {
+	return math.Round(days*100) / 100
+}
+
+// Localized returns a copy of the analytics with its labels in lang
+func (a RemediationAnalytics) Localized(lang string) RemediationAnalytics {
+	a.MTTR = a.MTTR.Localized(lang)
+	a.Aging = a.Aging.Localized(lang)
+	return a
+}
+
+// Localized returns a copy of the MTTR analytics with severity, team and month labels in lang.
+// Team names are kept; only the group of vulnerabilities without an owning team is translated.
+func (m MTTRAnalytics) Localized(lang string) MTTRAnalytics {
+	localize := func(groups []MTTRGroup, label func(MTTRGroup) string) []MTTRGroup {
+		localized := make([]MTTRGroup, len(groups))
+		for i, group := range groups {
+			group.Label = label(group)
+			localized[i] = group
+		}
+		return localized
+	}
+
+	m.BySeverity = localize(m.BySeverity, func(g MTTRGroup) string {
+		return i18n.T(lang, "severity."+g.Key)
+	})
+	m.ByTeam = localize(m.ByTeam, func(g MTTRGroup) string {
+		if g.Key == "" {
+			return i18n.T(lang, "report.unassigned")
+		}
+		return g.Label
+	})
+	m.ByMonth = localize(m.ByMonth, func(g MTTRGroup) string {
+		if t, err := time.Parse("2006-01", g.Key); err == nil {
+			return i18n.FormatMonth(lang, t)
+		}
+		return g.Label
+	})
+	return m
+}
+
+// Localized returns a copy of the aging analytics with bucket labels in lang
+func (a AgingAnalytics) Localized(lang string) AgingAnalytics {
+	buckets := make([]AgingBucket, len(a.Buckets))
+	for i, bucket := range a.Buckets {
+		if bucket.MaxDays != nil {
+			bucket.Label = i18n.T(lang, "report.days_range", bucket.MinDays, *bucket.MaxDays)
+		} else {
+			bucket.Label = i18n.T(lang, "report.days_over", bucket.MinDays-1)
+		}
+		buckets[i] = bucket
+	}
+	a.Buckets = buckets
+	return a
+}
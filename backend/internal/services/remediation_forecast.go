@@ -0,0 +1,259 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/i18n"
+	"github.com/cyops/cyops-backend/pkg/telemetry"
+)
+
+// Bounds of the history remediation velocity is measured over and of the projection, in days
+const (
+	DefaultForecastLookbackDays = 90
+	MaxForecastLookbackDays     = 365
+	DefaultForecastHorizonDays  = 180
+	MaxForecastHorizonDays      = 730
+)
+
+// forecastIntervalDays is the spacing of the projected burn-down points
+const forecastIntervalDays = 7
+
+// forecastSeverities are the severities the backlog is projected for, most severe first
+var forecastSeverities = []models.VulnerabilitySeverity{
+	models.SeverityCritical, models.SeverityHigh, models.SeverityMedium, models.SeverityLow,
+}
+
+// RemediationForecast projects the open backlog from the remediation velocity of the lookback
+// period, assuming vulnerabilities keep being opened and closed at the same daily rates
+type RemediationForecast struct {
+	GeneratedAt  time.Time          `json:"generated_at"`
+	AsOf         time.Time          `json:"as_of"`
+	LookbackDays int                `json:"lookback_days"`
+	HorizonDays  int                `json:"horizon_days"`
+	BySeverity   []SeverityForecast `json:"by_severity"` // Most severe first
+	Points       []ForecastPoint    `json:"points"`      // Weekly from AsOf to the horizon
+	// When the open critical vulnerabilities are projected to be cleared; omitted when the
+	// critical backlog isn't shrinking
+	CriticalClearDate *time.Time `json:"critical_clear_date,omitempty"`
+}
+
+// SeverityForecast is the remediation velocity and projected clear date of one severity
+type SeverityForecast struct {
+	Severity       models.VulnerabilitySeverity `json:"severity"`
+	Label          string                       `json:"label"`
+	Open           int64                        `json:"open"`
+	OpenedPerDay   float64                      `json:"opened_per_day"`   // Opened and reopened
+	ResolvedPerDay float64                      `json:"resolved_per_day"` // Moved out of open and in progress
+	NetBurnPerDay  float64                      `json:"net_burn_per_day"` // Resolved minus opened; positive shrinks the backlog
+	ClearDate      *time.Time                   `json:"clear_date,omitempty"`
+}
+
+// ForecastPoint is the projected open backlog on a day
+type ForecastPoint struct {
+	Date     time.Time `json:"date"`
+	Open     int64     `json:"open"`
+	Critical int64     `json:"critical"`
+	High     int64     `json:"high"`
+	Medium   int64     `json:"medium"`
+	Low      int64     `json:"low"`
+}
+
+// SeverityVelocity counts the open vulnerabilities of a severity and the vulnerabilities of
+// that severity opened and resolved over the lookback period
+type SeverityVelocity struct {
+	Severity models.VulnerabilitySeverity
+	Open     int64
+	Opened   int64
+	Resolved int64
+}
+
+// Forecast projects the open backlog horizonDays ahead from the velocity of the last
+// lookbackDays
+func (s *RemediationAnalyticsService) Forecast(asOf time.Time, lookbackDays, horizonDays int) (forecast *RemediationForecast, err error) {
+	since := asOf.AddDate(0, 0, -lookbackDays)
+	ctx, span := telemetry.StartSpan(s.db.Statement.Context, "RemediationAnalyticsService.Forecast", reportSpanAttributes(since, asOf)...)
+	defer func() { telemetry.EndSpan(span, err) }()
+	traced := s.WithContext(ctx)
+
+	day := utcDay(asOf)
+	key := reportCacheKey(s.filter.reportKey(fmt.Sprintf("forecast:%d:%d", lookbackDays, horizonDays)), day, day)
+	return cached(s.db.Statement.Context, ActiveCache(), CacheGroupReports, key, func() (*RemediationForecast, error) {
+		velocities, err := traced.severityVelocities(since)
+		if err != nil {
+			return nil, err
+		}
+		forecast := ProjectRemediation(asOf, velocities, lookbackDays, horizonDays)
+		forecast.GeneratedAt = time.Now()
+		return &forecast, nil
+	})
+}
+
+// severityVelocities counts open vulnerabilities per severity and the vulnerabilities opened,
+// reopened and resolved since
+func (s *RemediationAnalyticsService) severityVelocities(since time.Time) ([]SeverityVelocity, error) {
+	type severityCount struct {
+		Severity models.VulnerabilitySeverity
+		Count    int64
+	}
+	count := func(what string, build func() ([]severityCount, error)) (map[models.VulnerabilitySeverity]int64, error) {
+		counts, err := build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s vulnerabilities: %w", what, err)
+		}
+		bySeverity := make(map[models.VulnerabilitySeverity]int64, len(counts))
+		for _, c := range counts {
+			bySeverity[c.Severity] += c.Count
+		}
+		return bySeverity, nil
+	}
+	transitions := func(condition string) func() ([]severityCount, error) {
+		return func() ([]severityCount, error) {
+			var counts []severityCount
+			err := s.db.Model(&models.Vulnerability{}).
+				Scopes(s.filter.scopeVulnerabilities("vulnerabilities.id")).
+				Select("vulnerabilities.severity, COUNT(*) AS count").
+				Joins("JOIN vulnerability_status_history ON vulnerability_status_history.vulnerability_id = vulnerabilities.id").
+				Where("vulnerability_status_history.changed_at >= ?", since).
+				Where(condition, openStatuses, openStatuses).
+				Group("vulnerabilities.severity").
+				Scan(&counts).Error
+			return counts, err
+		}
+	}
+
+	open, err := count("open", func() ([]severityCount, error) {
+		var counts []severityCount
+		err := s.db.Model(&models.Vulnerability{}).
+			Scopes(s.filter.scopeVulnerabilities("vulnerabilities.id")).
+			Select("severity, COUNT(*) AS count").
+			Where("status IN ?", openStatuses).
+			Group("severity").
+			Scan(&counts).Error
+		return counts, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	opened, err := count("opened", func() ([]severityCount, error) {
+		var counts []severityCount
+		err := s.db.Model(&models.Vulnerability{}).
+			Scopes(s.filter.scopeVulnerabilities("vulnerabilities.id")).
+			Select("severity, COUNT(*) AS count").
+			Where("created_at >= ?", since).
+			Group("severity").
+			Scan(&counts).Error
+		return counts, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The initial status entry of imported vulnerabilities has no old status and isn't a reopen
+	reopened, err := count("reopened", transitions("vulnerability_status_history.old_status NOT IN ? AND vulnerability_status_history.old_status <> '' AND vulnerability_status_history.new_status IN ?"))
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := count("resolved", transitions("vulnerability_status_history.old_status IN ? AND vulnerability_status_history.new_status NOT IN ?"))
+	if err != nil {
+		return nil, err
+	}
+
+	velocities := make([]SeverityVelocity, 0, len(forecastSeverities))
+	for _, severity := range forecastSeverities {
+		velocities = append(velocities, SeverityVelocity{
+			Severity: severity,
+			Open:     open[severity],
+			Opened:   opened[severity] + reopened[severity],
+			Resolved: resolved[severity],
+		})
+	}
+	return velocities, nil
+}
+
+// ProjectRemediation projects each severity's open backlog at its average daily net burn over
+// the lookback period. A severity is cleared once its projected backlog reaches zero; one
+// opened as fast as it is resolved, or faster, is never cleared.
+func ProjectRemediation(asOf time.Time, velocities []SeverityVelocity, lookbackDays, horizonDays int) RemediationForecast {
+	asOf = utcDay(asOf)
+	forecast := RemediationForecast{
+		AsOf:         asOf,
+		LookbackDays: lookbackDays,
+		HorizonDays:  horizonDays,
+		BySeverity:   []SeverityForecast{},
+		Points:       []ForecastPoint{},
+	}
+	if lookbackDays < 1 {
+		lookbackDays = 1
+	}
+
+	for _, velocity := range velocities {
+		severity := SeverityForecast{
+			Severity:       velocity.Severity,
+			Label:          string(velocity.Severity),
+			Open:           velocity.Open,
+			OpenedPerDay:   roundDays(float64(velocity.Opened) / float64(lookbackDays)),
+			ResolvedPerDay: roundDays(float64(velocity.Resolved) / float64(lookbackDays)),
+		}
+		netBurn := float64(velocity.Resolved-velocity.Opened) / float64(lookbackDays)
+		severity.NetBurnPerDay = roundDays(netBurn)
+		switch {
+		case velocity.Open == 0:
+			cleared := asOf
+			severity.ClearDate = &cleared
+		case netBurn > 0:
+			clear := asOf.AddDate(0, 0, int(math.Ceil(float64(velocity.Open)/netBurn)))
+			severity.ClearDate = &clear
+		}
+		if velocity.Severity == models.SeverityCritical {
+			forecast.CriticalClearDate = severity.ClearDate
+		}
+		forecast.BySeverity = append(forecast.BySeverity, severity)
+	}
+
+	projected := func(velocity SeverityVelocity, days int) int64 {
+		netBurn := float64(velocity.Resolved-velocity.Opened) / float64(lookbackDays)
+		open := float64(velocity.Open) - netBurn*float64(days)
+		if open < 0 {
+			return 0
+		}
+		return int64(math.Round(open))
+	}
+	for days := 0; ; days += forecastIntervalDays {
+		if days > horizonDays {
+			days = horizonDays
+		}
+		point := ForecastPoint{Date: asOf.AddDate(0, 0, days)}
+		for _, velocity := range velocities {
+			open := projected(velocity, days)
+			point.Open += open
+			switch velocity.Severity {
+			case models.SeverityCritical:
+				point.Critical = open
+			case models.SeverityHigh:
+				point.High = open
+			case models.SeverityMedium:
+				point.Medium = open
+			case models.SeverityLow:
+				point.Low = open
+			}
+		}
+		forecast.Points = append(forecast.Points, point)
+		if days >= horizonDays {
+			break
+		}
+	}
+	return forecast
+}
+
+// Localized returns a copy of the forecast with severity labels in lang
+func (f RemediationForecast) Localized(lang string) RemediationForecast {
+	bySeverity := make([]SeverityForecast, len(f.BySeverity))
+	for i, severity := range f.BySeverity {
+		severity.Label = i18n.T(lang, "severity."+string(severity.Severity))
+		bySeverity[i] = severity
+	}
+	f.BySeverity = bySeverity
+	return f
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/telemetry"
+)
+
+// comparisonTopRegressions caps the regressions highlighted in a period comparison
+const comparisonTopRegressions = 5
+
+// Trends of a compared metric
+const (
+	MetricImproved  = "improved"
+	MetricRegressed = "regressed"
+	MetricUnchanged = "unchanged"
+)
+
+// PeriodMetrics summarizes one month from the daily metrics snapshots
+type PeriodMetrics struct {
+	Period                  string          `json:"period"` // YYYY-MM
+	Start                   time.Time       `json:"start"`
+	End                     time.Time       `json:"end"`          // Last day covered; today for the current month
+	Days                    int64           `json:"days"`         // Days in the period up to End
+	DaysCovered             int64           `json:"days_covered"` // Days with a snapshot
+	Complete                bool            `json:"complete"`     // Every day has a snapshot
+	NewVulnerabilities      int64           `json:"new_vulnerabilities"`
+	NewCritical             int64           `json:"new_critical"`
+	ResolvedVulnerabilities int64           `json:"resolved_vulnerabilities"`
+	NetChange               int64           `json:"net_change"` // New minus resolved; positive grows the backlog
+	NewFindings             int64           `json:"new_findings"`
+	MTTRHours               float64         `json:"mttr_hours"`
+	RiskScore               float64         `json:"risk_score"`        // Share of new vulnerabilities that are critical, as in the monthly trend
+	Backlog                 *MetricsBacklog `json:"backlog,omitempty"` // At the end of the period, when recorded
+}
+
+// MetricDelta compares one metric across two periods
+type MetricDelta struct {
+	Metric        string   `json:"metric"`
+	From          float64  `json:"from"`
+	To            float64  `json:"to"`
+	Change        float64  `json:"change"`
+	ChangePercent *float64 `json:"change_percent,omitempty"` // Omitted when the metric was zero
+	Trend         string   `json:"trend"`                    // improved, regressed or unchanged
+}
+
+// PeriodComparison compares two months for a delta dashboard
+type PeriodComparison struct {
+	GeneratedAt    time.Time     `json:"generated_at"`
+	From           PeriodMetrics `json:"from"`
+	To             PeriodMetrics `json:"to"`
+	RiskScoreDelta float64       `json:"risk_score_delta"`
+	Metrics        []MetricDelta `json:"metrics"`
+	TopRegressions []MetricDelta `json:"top_regressions"` // Largest relative regressions first
+}
+
+// ParseReportMonth parses a YYYY-MM month into its first UTC day
+func ParseReportMonth(value string) (time.Time, error) {
+	month, err := time.ParseInLocation("2006-01", value, time.UTC)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month '%s', use YYYY-MM", value)
+	}
+	return month, nil
+}
+
+// ParseComparisonPeriods parses the from and to months (YYYY-MM) of a period comparison; from
+// must precede to and to can't be after the current month
+func ParseComparisonPeriods(from, to string, now time.Time) (time.Time, time.Time, error) {
+	if from == "" || to == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("from and to are required")
+	}
+	fromMonth, err := ParseReportMonth(from)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	toMonth, err := ParseReportMonth(to)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !fromMonth.Before(toMonth) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period, from must be before to")
+	}
+	if toMonth.After(utcDay(now)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period, to must not be in the future")
+	}
+	return fromMonth, toMonth, nil
+}
+
+// ComparePeriods compares the snapshot metrics of the months starting at fromMonth and
+// toMonth. The current month is summarized up to today.
+func (s *ReportService) ComparePeriods(fromMonth, toMonth, now time.Time) (comparison *PeriodComparison, err error) {
+	ctx, span := telemetry.StartSpan(s.db.Statement.Context, "ReportService.ComparePeriods", reportSpanAttributes(fromMonth, toMonth)...)
+	defer func() { telemetry.EndSpan(span, err) }()
+	traced := s.WithContext(ctx)
+
+	return cached(s.db.Statement.Context, ActiveCache(), CacheGroupReports, reportCacheKey("compare", fromMonth, toMonth), func() (*PeriodComparison, error) {
+		fromMetrics, err := traced.periodMetrics(fromMonth, now)
+		if err != nil {
+			return nil, err
+		}
+		toMetrics, err := traced.periodMetrics(toMonth, now)
+		if err != nil {
+			return nil, err
+		}
+		comparison := ComparePeriodMetrics(*fromMetrics, *toMetrics)
+		comparison.GeneratedAt = time.Now()
+		return &comparison, nil
+	})
+}
+
+// periodMetrics summarizes the snapshots of the month starting at month
+func (s *ReportService) periodMetrics(month, now time.Time) (*PeriodMetrics, error) {
+	lastDay := month.AddDate(0, 1, -1)
+	if today := utcDay(now); lastDay.After(today) {
+		lastDay = today
+	}
+
+	totals, covered, backlog, err := s.metrics.SumPeriod(month, lastDay)
+	if err != nil {
+		return nil, err
+	}
+	days := int64(lastDay.Sub(month).Hours()/24) + 1
+	return &PeriodMetrics{
+		Period:                  month.Format("2006-01"),
+		Start:                   month,
+		End:                     lastDay,
+		Days:                    days,
+		DaysCovered:             covered,
+		Complete:                covered >= days,
+		NewVulnerabilities:      totals.NewVulnerabilities,
+		NewCritical:             totals.NewCritical,
+		ResolvedVulnerabilities: totals.ResolvedVulnerabilities,
+		NetChange:               totals.NewVulnerabilities - totals.ResolvedVulnerabilities,
+		NewFindings:             totals.NewFindings,
+		MTTRHours:               roundDays(totals.MTTRHours),
+		RiskScore:               roundDays(newVulnerabilityRiskScore(totals.NewCritical, totals.NewVulnerabilities)),
+		Backlog:                 backlog,
+	}, nil
+}
+
+// ComparePeriodMetrics computes the change of every metric from one period to the next and
+// ranks the regressions. Backlog metrics are compared when both periods recorded a backlog.
+func ComparePeriodMetrics(from, to PeriodMetrics) PeriodComparison {
+	comparison := PeriodComparison{
+		From:           from,
+		To:             to,
+		RiskScoreDelta: roundDays(to.RiskScore - from.RiskScore),
+		Metrics:        []MetricDelta{},
+		TopRegressions: []MetricDelta{},
+	}
+
+	add := func(metric string, fromValue, toValue float64, higherIsWorse bool) {
+		delta := MetricDelta{
+			Metric: metric,
+			From:   fromValue,
+			To:     toValue,
+			Change: roundDays(toValue - fromValue),
+			Trend:  MetricUnchanged,
+		}
+		if fromValue != 0 {
+			percent := roundDays((toValue - fromValue) / math.Abs(fromValue) * 100)
+			delta.ChangePercent = &percent
+		}
+		if worse := toValue > fromValue; toValue != fromValue {
+			if worse == higherIsWorse {
+				delta.Trend = MetricRegressed
+			} else {
+				delta.Trend = MetricImproved
+			}
+		}
+		comparison.Metrics = append(comparison.Metrics, delta)
+	}
+
+	add("new_vulnerabilities", float64(from.NewVulnerabilities), float64(to.NewVulnerabilities), true)
+	add("new_critical", float64(from.NewCritical), float64(to.NewCritical), true)
+	add("resolved_vulnerabilities", float64(from.ResolvedVulnerabilities), float64(to.ResolvedVulnerabilities), false)
+	add("net_change", float64(from.NetChange), float64(to.NetChange), true)
+	add("new_findings", float64(from.NewFindings), float64(to.NewFindings), true)
+	add("mttr_hours", from.MTTRHours, to.MTTRHours, true)
+	add("risk_score", from.RiskScore, to.RiskScore, true)
+	if from.Backlog != nil && to.Backlog != nil {
+		add("critical_backlog", float64(from.Backlog.CriticalCount), float64(to.Backlog.CriticalCount), true)
+		add("high_backlog", float64(from.Backlog.HighCount), float64(to.Backlog.HighCount), true)
+		add("open_backlog", float64(from.Backlog.OpenCount+from.Backlog.InProgressCount), float64(to.Backlog.OpenCount+to.Backlog.InProgressCount), true)
+	}
+
+	for _, delta := range comparison.Metrics {
+		if delta.Trend == MetricRegressed {
+			comparison.TopRegressions = append(comparison.TopRegressions, delta)
+		}
+	}
+	// A metric that regressed from zero has no percentage and ranks first
+	relative := func(delta MetricDelta) float64 {
+		if delta.ChangePercent == nil {
+			return math.Inf(1)
+		}
+		return math.Abs(*delta.ChangePercent)
+	}
+	sort.SliceStable(comparison.TopRegressions, func(i, j int) bool {
+		return relative(comparison.TopRegressions[i]) > relative(comparison.TopRegressions[j])
+	})
+	if len(comparison.TopRegressions) > comparisonTopRegressions {
+		comparison.TopRegressions = comparison.TopRegressions[:comparisonTopRegressions]
+	}
+	return comparison
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ReportFilter narrows reports and remediation analytics to a subset of assets, such as a
+// business unit or an environment. Populated conditions must all match: assets in the asset
+// group, in the environment, carrying every tag and owned by the team. Vulnerabilities are in
+// scope when they affect an asset in scope; findings, retests, violations, evidence and history
+// follow their vulnerability. The zero value doesn't filter.
+type ReportFilter struct {
+	AssetGroupID *uuid.UUID         `json:"asset_group,omitempty"`
+	Environment  models.Environment `json:"environment,omitempty"`
+	Tags         []string           `json:"tags,omitempty"`
+	OwnerTeamID  *uuid.UUID         `json:"owner_team,omitempty"`
+}
+
+// ParseReportFilter builds a filter from the asset_group, environment, tags (comma separated)
+// and owner_team query parameters
+func ParseReportFilter(assetGroup, environment, tags, ownerTeam string) (ReportFilter, error) {
+	var filter ReportFilter
+	if assetGroup != "" {
+		id, err := uuid.Parse(assetGroup)
+		if err != nil {
+			return ReportFilter{}, fmt.Errorf("invalid asset_group, must be a UUID")
+		}
+		filter.AssetGroupID = &id
+	}
+	if environment != "" {
+		filter.Environment = models.Environment(strings.ToUpper(strings.TrimSpace(environment)))
+		switch filter.Environment {
+		case models.EnvProduction, models.EnvStaging, models.EnvDevelopment, models.EnvTest:
+		default:
+			return ReportFilter{}, fmt.Errorf("invalid environment '%s'", environment)
+		}
+	}
+	if tags != "" {
+		normalized, err := NormalizeTags(strings.Split(tags, ","))
+		if err != nil {
+			return ReportFilter{}, err
+		}
+		sort.Strings(normalized)
+		filter.Tags = normalized
+	}
+	if ownerTeam != "" {
+		id, err := uuid.Parse(ownerTeam)
+		if err != nil {
+			return ReportFilter{}, fmt.Errorf("invalid owner_team, must be a UUID")
+		}
+		filter.OwnerTeamID = &id
+	}
+	return filter, nil
+}
+
+// IsZero reports whether the filter is empty and reports cover every asset
+func (f ReportFilter) IsZero() bool {
+	return f.AssetGroupID == nil && f.Environment == "" && len(f.Tags) == 0 && f.OwnerTeamID == nil
+}
+
+// CacheKey identifies the filter in report cache keys; empty for the zero filter
+func (f ReportFilter) CacheKey() string {
+	if f.IsZero() {
+		return ""
+	}
+	var group, team string
+	if f.AssetGroupID != nil {
+		group = f.AssetGroupID.String()
+	}
+	if f.OwnerTeamID != nil {
+		team = f.OwnerTeamID.String()
+	}
+	return fmt.Sprintf("group=%s,env=%s,tags=%s,team=%s", group, f.Environment, strings.Join(f.Tags, "+"), team)
+}
+
+// assetIDs returns a subquery of the IDs of the assets in scope
+func (f ReportFilter) assetIDs(db *gorm.DB) *gorm.DB {
+	query := db.Session(&gorm.Session{NewDB: true}).Model(&models.AffectedSystem{}).Select("affected_systems.id")
+	if f.AssetGroupID != nil {
+		query = query.Where("affected_systems.id IN (?)",
+			db.Session(&gorm.Session{NewDB: true}).Table("asset_group_members").Select("asset_id").Where("group_id = ?", *f.AssetGroupID))
+	}
+	if f.Environment != "" {
+		query = query.Where("affected_systems.environment = ?", f.Environment)
+	}
+	if len(f.Tags) > 0 {
+		query = query.Where("affected_systems.id IN (?)",
+			db.Session(&gorm.Session{NewDB: true}).Table("asset_tags").
+				Select("asset_id").
+				Where("tag IN ?", f.Tags).
+				Group("asset_id").
+				Having("COUNT(DISTINCT tag) = ?", len(f.Tags)))
+	}
+	if f.OwnerTeamID != nil {
+		query = query.Where("affected_systems.owner_team_id = ?", *f.OwnerTeamID)
+	}
+	return query
+}
+
+// vulnerabilityIDs returns a subquery of the IDs of the vulnerabilities in scope
+func (f ReportFilter) vulnerabilityIDs(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Table("vulnerability_affected_systems").
+		Select("vulnerability_id").
+		Where("affected_system_id IN (?)", f.assetIDs(db))
+}
+
+// scopeAssets limits a query to assets in scope; column holds the asset ID
+func (f ReportFilter) scopeAssets(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.IsZero() {
+			return db
+		}
+		return db.Where(column+" IN (?)", f.assetIDs(db))
+	}
+}
+
+// scopeVulnerabilities limits a query to vulnerabilities in scope; column holds the
+// vulnerability ID
+func (f ReportFilter) scopeVulnerabilities(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.IsZero() {
+			return db
+		}
+		return db.Where(column+" IN (?)", f.vulnerabilityIDs(db))
+	}
+}
+
+// scopeFindings limits a query to findings on vulnerabilities in scope; column holds the
+// finding ID
+func (f ReportFilter) scopeFindings(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.IsZero() {
+			return db
+		}
+		return db.Where(column+" IN (?)",
+			db.Session(&gorm.Session{NewDB: true}).Model(&models.VulnerabilityFinding{}).
+				Select("id").
+				Where("vulnerability_id IN (?)", f.vulnerabilityIDs(db)))
+	}
+}
+
+// scopeAssessments limits a query to assessments covering an asset or vulnerability in scope;
+// column holds the assessment ID
+func (f ReportFilter) scopeAssessments(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.IsZero() {
+			return db
+		}
+		return db.Where("("+column+" IN (?) OR "+column+" IN (?))",
+			db.Session(&gorm.Session{NewDB: true}).Table("assessment_assets").
+				Select("assessment_id").
+				Where("asset_id IN (?)", f.assetIDs(db)),
+			db.Session(&gorm.Session{NewDB: true}).Table("assessment_vulnerabilities").
+				Select("assessment_id").
+				Where("vulnerability_id IN (?)", f.vulnerabilityIDs(db)))
+	}
+}
+
+// scopeAttachments limits a query to evidence attached to findings in scope; column holds the
+// attachment ID
+func (f ReportFilter) scopeAttachments(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if f.IsZero() {
+			return db
+		}
+		return db.Where(column+" IN (?)",
+			db.Session(&gorm.Session{NewDB: true}).Table("finding_attachments").
+				Select("finding_attachments.id").
+				Scopes(f.scopeFindings("finding_attachments.finding_id")))
+	}
+}
+
+// reportKey names a report in cache keys, so filtered reports are cached apart
+func (f ReportFilter) reportKey(report string) string {
+	if f.IsZero() {
+		return report
+	}
+	return report + "[" + f.CacheKey() + "]"
+}
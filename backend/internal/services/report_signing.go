@@ -0,0 +1,93 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/signing"
+)
+
+// ReportSignatureHeader carries the base64 encoded detached signature of a signed download
+const ReportSignatureHeader = "X-Report-Signature"
+
+var (
+	reportSignerMu sync.RWMutex
+	reportSigner   *signing.Signer
+)
+
+// SetReportSigner sets the signer of generated reports and report exports
+func SetReportSigner(signer *signing.Signer) {
+	reportSignerMu.Lock()
+	defer reportSignerMu.Unlock()
+	reportSigner = signer
+}
+
+// ReportSigner returns the signer of report artifacts, or nil when signing is not set up (tools
+// and tests), in which case reports are left unsigned
+func ReportSigner() *signing.Signer {
+	reportSignerMu.RLock()
+	defer reportSignerMu.RUnlock()
+	return reportSigner
+}
+
+// SignReportArtifact returns a detached signature of a report artifact, or nil without a signer
+func SignReportArtifact(data []byte) *signing.Signature {
+	signer := ReportSigner()
+	if signer == nil {
+		return nil
+	}
+	return signer.Sign(data)
+}
+
+// EncodeReportSignature encodes a detached signature for the X-Report-Signature header
+func EncodeReportSignature(signature *signing.Signature) string {
+	data, _ := json.Marshal(signature)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// DecodeReportSignature parses a detached signature, either its JSON document (a saved .sig file)
+// or the base64 encoding of the X-Report-Signature header
+func DecodeReportSignature(value []byte) (*signing.Signature, error) {
+	if decoded, err := base64.StdEncoding.DecodeString(string(value)); err == nil {
+		value = decoded
+	}
+	var signature signing.Signature
+	if err := json.Unmarshal(value, &signature); err != nil {
+		return nil, fmt.Errorf("invalid signature: not a report signature document")
+	}
+	return &signature, nil
+}
+
+// ReportVerification is the outcome of checking a report against its detached signature
+type ReportVerification struct {
+	Valid    bool      `json:"valid"`
+	Reason   string    `json:"reason,omitempty"` // Why the report did not verify
+	KeyID    string    `json:"key_id,omitempty"`
+	SignedAt time.Time `json:"signed_at,omitempty"`
+	SHA256   string    `json:"sha256"` // Digest of the checked file
+	Size     int64     `json:"size"`
+}
+
+// VerifyReportArtifact checks that a report was signed by this instance and is unmodified
+func VerifyReportArtifact(data []byte, signature *signing.Signature) (*ReportVerification, error) {
+	signer := ReportSigner()
+	if signer == nil {
+		return nil, fmt.Errorf("report signing is not configured")
+	}
+	digest := signing.Digest(data)
+	result := &ReportVerification{
+		KeyID:    signature.KeyID,
+		SignedAt: signature.SignedAt,
+		SHA256:   digest,
+		Size:     int64(len(data)),
+	}
+	if err := signer.Verify(data, signature); err != nil {
+		result.Reason = err.Error()
+		return result, nil
+	}
+	result.Valid = true
+	return result, nil
+}
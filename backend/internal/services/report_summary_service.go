@@ -0,0 +1,384 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/llm"
+	"gorm.io/gorm"
+)
+
+// ErrSummarizerNotConfigured is returned when no language model is configured for summaries
+var ErrSummarizerNotConfigured = errors.New("report summarizer is not configured")
+
+const (
+	defaultSummaryMaxTokens   = 800
+	maxSummaryTimeoutSeconds  = 300
+	maxAssessmentSummaryItems = 25 // Vulnerabilities listed in an assessment prompt
+)
+
+// ReportSummarizerSettings selects the language model that writes report summaries. It is
+// stored as JSON in the report_summarizer system setting; the API key comes from the
+// environment (LLM_API_KEY) so it is never returned by the settings API.
+type ReportSummarizerSettings struct {
+	Provider       string `json:"provider"`              // openai, azure or ollama
+	BaseURL        string `json:"base_url,omitempty"`    // API root; required for azure
+	Model          string `json:"model"`                 // Model name; the deployment name for azure
+	APIVersion     string `json:"api_version,omitempty"` // Azure API version
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+	MaxTokens      int    `json:"max_tokens,omitempty"`
+}
+
+var (
+	summarizerAPIKeyMu sync.RWMutex
+	summarizerAPIKey   string
+)
+
+// SetReportSummarizerAPIKey records the language model API key read from the environment
+func SetReportSummarizerAPIKey(key string) {
+	summarizerAPIKeyMu.Lock()
+	defer summarizerAPIKeyMu.Unlock()
+	summarizerAPIKey = key
+}
+
+// ValidateReportSummarizerSettings checks the provider and its required options and fills in defaults
+func ValidateReportSummarizerSettings(settings *ReportSummarizerSettings) error {
+	settings.Provider = strings.ToLower(strings.TrimSpace(settings.Provider))
+	settings.BaseURL = strings.TrimSpace(settings.BaseURL)
+	settings.Model = strings.TrimSpace(settings.Model)
+
+	switch settings.Provider {
+	case llm.ProviderOpenAI, llm.ProviderOllama:
+	case llm.ProviderAzure:
+		if settings.BaseURL == "" {
+			return fmt.Errorf("base_url is required for the azure provider")
+		}
+	default:
+		return fmt.Errorf("invalid provider: %s", settings.Provider)
+	}
+	if settings.Model == "" {
+		return fmt.Errorf("model is required")
+	}
+	if settings.TimeoutSeconds < 0 || settings.TimeoutSeconds > maxSummaryTimeoutSeconds {
+		return fmt.Errorf("invalid timeout_seconds: must be at most %d", maxSummaryTimeoutSeconds)
+	}
+	if settings.MaxTokens < 0 {
+		return fmt.Errorf("invalid max_tokens: must not be negative")
+	}
+	if settings.MaxTokens == 0 {
+		settings.MaxTokens = defaultSummaryMaxTokens
+	}
+	return nil
+}
+
+// BuildReportSummarizer parses a report_summarizer setting value and creates its client
+func BuildReportSummarizer(value string) (llm.Client, *ReportSummarizerSettings, error) {
+	var settings ReportSummarizerSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return nil, nil, fmt.Errorf("invalid report summarizer settings: %v", err)
+	}
+	if err := ValidateReportSummarizerSettings(&settings); err != nil {
+		return nil, nil, fmt.Errorf("invalid report summarizer settings: %v", err)
+	}
+
+	summarizerAPIKeyMu.RLock()
+	apiKey := summarizerAPIKey
+	summarizerAPIKeyMu.RUnlock()
+
+	client, err := llm.New(llm.Config{
+		Provider:   settings.Provider,
+		BaseURL:    settings.BaseURL,
+		Model:      settings.Model,
+		APIKey:     apiKey,
+		APIVersion: settings.APIVersion,
+		Timeout:    time.Duration(settings.TimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid report summarizer settings: %v", err)
+	}
+	return client, &settings, nil
+}
+
+// ReportSummaryService writes natural-language summaries of reports and assessments with the
+// configured language model and stores them alongside the report
+type ReportSummaryService struct {
+	db      *gorm.DB
+	ctx     context.Context
+	reports *ReportService
+}
+
+// NewReportSummaryService creates a new report summary service
+func NewReportSummaryService(db *gorm.DB) *ReportSummaryService {
+	return &ReportSummaryService{db: db, ctx: context.Background(), reports: NewReportService(db)}
+}
+
+// WithContext returns a copy of the service whose queries and model calls use ctx
+func (s *ReportSummaryService) WithContext(ctx context.Context) *ReportSummaryService {
+	return &ReportSummaryService{db: s.db.WithContext(ctx), ctx: ctx, reports: s.reports.WithContext(ctx)}
+}
+
+// summarizer loads the configured language model
+func (s *ReportSummaryService) summarizer() (llm.Client, *ReportSummarizerSettings, error) {
+	var setting models.SystemSetting
+	err := s.db.Where("key = ?", string(models.SystemSettingReportSummarizer)).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrSummarizerNotConfigured
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load report summarizer settings: %w", err)
+	}
+	return BuildReportSummarizer(setting.Value)
+}
+
+// summarySystemPrompt instructs the model on the shape of its answer, which ParseSummaryCompletion reads
+const summarySystemPrompt = `You are a security analyst writing for executives and auditors.
+Write in plain, factual business language using only the data provided; do not invent numbers.
+Answer with exactly two sections:
+EXECUTIVE SUMMARY:
+<two or three short paragraphs on overall security posture, trend and remediation progress>
+KEY RISKS:
+<a short narrative paragraph for each of the most significant risks and the recommended action>`
+
+// SummarizeExecutiveReport summarizes the executive report of a period and stores the summary
+func (s *ReportSummaryService) SummarizeExecutiveReport(startDate, endDate time.Time, userID uuid.UUID) (*models.ReportSummary, error) {
+	client, settings, err := s.summarizer()
+	if err != nil {
+		return nil, err
+	}
+	report, err := s.reports.GenerateExecutiveReport(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.summarize(client, settings, ExecutiveSummaryPrompt(report, startDate, endDate))
+	if err != nil {
+		return nil, err
+	}
+	start, end := reportDate(startDate), reportDate(endDate)
+	summary.Kind = models.ReportSummaryExecutive
+	summary.PeriodStart = &start
+	summary.PeriodEnd = &end
+	summary.GeneratedByID = userID
+	if err := s.db.Create(summary).Error; err != nil {
+		return nil, fmt.Errorf("failed to save report summary: %w", err)
+	}
+	return summary, nil
+}
+
+// SummarizeAssessment summarizes an assessment and its linked vulnerabilities and stores the summary
+func (s *ReportSummaryService) SummarizeAssessment(assessmentID, userID uuid.UUID) (*models.ReportSummary, error) {
+	var assessment models.Assessment
+	if err := s.db.Preload("Vulnerabilities").Preload("Assets").First(&assessment, "id = ?", assessmentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("assessment not found")
+		}
+		return nil, err
+	}
+	client, settings, err := s.summarizer()
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.summarize(client, settings, AssessmentSummaryPrompt(&assessment))
+	if err != nil {
+		return nil, err
+	}
+	summary.Kind = models.ReportSummaryAssessment
+	summary.AssessmentID = &assessment.ID
+	summary.GeneratedByID = userID
+	if err := s.db.Create(summary).Error; err != nil {
+		return nil, fmt.Errorf("failed to save report summary: %w", err)
+	}
+	return summary, nil
+}
+
+// summarize asks the model for a summary of prompt
+func (s *ReportSummaryService) summarize(client llm.Client, settings *ReportSummarizerSettings, prompt string) (*models.ReportSummary, error) {
+	text, err := client.Complete(s.ctx, llm.Request{
+		System:    summarySystemPrompt,
+		Prompt:    prompt,
+		MaxTokens: settings.MaxTokens,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("summary generation failed: %w", err)
+	}
+
+	executive, keyRisks := ParseSummaryCompletion(text)
+	return &models.ReportSummary{
+		ExecutiveSummary: executive,
+		KeyRisks:         keyRisks,
+		Provider:         client.Provider(),
+		Model:            client.Model(),
+	}, nil
+}
+
+// LatestExecutiveSummary returns the newest stored summary of the executive report of a period,
+// or nil when it has not been summarized
+func (s *ReportSummaryService) LatestExecutiveSummary(startDate, endDate time.Time) (*models.ReportSummary, error) {
+	var summary models.ReportSummary
+	err := s.db.Where("kind = ? AND period_start = ? AND period_end = ?",
+		models.ReportSummaryExecutive, reportDate(startDate), reportDate(endDate)).
+		Order("created_at DESC").First(&summary).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// ListAssessmentSummaries returns the stored summaries of an assessment, newest first
+func (s *ReportSummaryService) ListAssessmentSummaries(assessmentID uuid.UUID) ([]models.ReportSummary, error) {
+	var summaries []models.ReportSummary
+	err := s.db.Preload("GeneratedBy").
+		Where("kind = ? AND assessment_id = ?", models.ReportSummaryAssessment, assessmentID).
+		Order("created_at DESC").Find(&summaries).Error
+	return summaries, err
+}
+
+// reportDate truncates a report boundary to its calendar date, as stored
+func reportDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ExecutiveSummaryPrompt describes an executive report to the language model
+func ExecutiveSummaryPrompt(report *ExecutiveReportData, startDate, endDate time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Executive security report for %s to %s.\n\n", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	fmt.Fprintf(&b, "Security posture: %s\n", report.SecurityPosture)
+	fmt.Fprintf(&b, "Risk score: %.1f/100\n", report.RiskScore)
+	fmt.Fprintf(&b, "Open critical vulnerabilities: %d\n", report.CriticalVulnerabilities)
+	fmt.Fprintf(&b, "Open high vulnerabilities: %d\n", report.HighVulnerabilities)
+	fmt.Fprintf(&b, "Total assets: %d\n", report.TotalAssets)
+	fmt.Fprintf(&b, "Compliance score: %.1f%%\n", report.ComplianceScore)
+	fmt.Fprintf(&b, "Remediation rate: %.1f%%\n", report.RemediationRate)
+	fmt.Fprintf(&b, "Average time to remediate: %.1f days\n", report.AverageTimeToRemediate)
+	if report.CostImpactEstimate > 0 {
+		fmt.Fprintf(&b, "Estimated cost impact: %.0f\n", report.CostImpactEstimate)
+	}
+
+	writeList(&b, "Key risks identified", report.KeyRisks)
+	writeList(&b, "Recommended actions", report.RecommendedActions)
+
+	if len(report.MonthlyTrend) > 0 {
+		b.WriteString("\nMonthly trend:\n")
+		for _, month := range report.MonthlyTrend {
+			fmt.Fprintf(&b, "- %s: %d new, %d resolved, risk score %.1f\n",
+				month.Month, month.Vulnerabilities, month.Resolved, month.RiskScore)
+		}
+	}
+	if report.Forecast != nil {
+		b.WriteString("\nRemediation forecast:\n")
+		for _, severity := range report.Forecast.BySeverity {
+			clear := "not projected to clear at the current rate"
+			if severity.ClearDate != nil {
+				clear = "projected to clear by " + severity.ClearDate.Format("2006-01-02")
+			}
+			fmt.Fprintf(&b, "- %s: %d open, %.2f resolved and %.2f opened per day, %s\n",
+				severity.Severity, severity.Open, severity.ResolvedPerDay, severity.OpenedPerDay, clear)
+		}
+	}
+	if len(report.BusinessServices) > 0 {
+		b.WriteString("\nRiskiest business services:\n")
+		for _, service := range report.BusinessServices {
+			fmt.Fprintf(&b, "- %s (tier %s): risk score %.1f, %d open vulnerabilities\n",
+				service.Name, service.Tier, service.RiskScore, service.OpenVulnerabilities)
+		}
+	}
+	return b.String()
+}
+
+// AssessmentSummaryPrompt describes an assessment to the language model
+func AssessmentSummaryPrompt(assessment *models.Assessment) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Security assessment %q (%s), status %s.\n", assessment.Name, assessment.AssessmentType, assessment.Status)
+	fmt.Fprintf(&b, "Assessor: %s", assessment.AssessorName)
+	if assessment.AssessorOrganization != "" {
+		fmt.Fprintf(&b, " (%s)", assessment.AssessorOrganization)
+	}
+	fmt.Fprintf(&b, "\nPeriod: %s", assessment.StartDate.Format("2006-01-02"))
+	if assessment.EndDate != nil {
+		fmt.Fprintf(&b, " to %s", assessment.EndDate.Format("2006-01-02"))
+	}
+	b.WriteString("\n")
+	if assessment.Score != nil {
+		fmt.Fprintf(&b, "Score: %d/100\n", *assessment.Score)
+	}
+	fmt.Fprintf(&b, "Assets in scope: %d\n", len(assessment.Assets))
+	if assessment.Description != "" {
+		fmt.Fprintf(&b, "\nDescription:\n%s\n", assessment.Description)
+	}
+	if assessment.FindingsSummary != "" {
+		fmt.Fprintf(&b, "\nAssessor's findings summary:\n%s\n", assessment.FindingsSummary)
+	}
+	if assessment.Recommendations != "" {
+		fmt.Fprintf(&b, "\nAssessor's recommendations:\n%s\n", assessment.Recommendations)
+	}
+
+	counts := map[string]int{}
+	for _, vuln := range assessment.Vulnerabilities {
+		counts[string(vuln.Severity)]++
+	}
+	fmt.Fprintf(&b, "\nLinked vulnerabilities: %d", len(assessment.Vulnerabilities))
+	for _, severity := range []string{"CRITICAL", "HIGH", "MEDIUM", "LOW"} {
+		if counts[severity] > 0 {
+			fmt.Fprintf(&b, ", %d %s", counts[severity], strings.ToLower(severity))
+		}
+	}
+	b.WriteString("\n")
+
+	// Most severe first
+	vulns := append([]models.Vulnerability(nil), assessment.Vulnerabilities...)
+	sort.SliceStable(vulns, func(i, j int) bool {
+		return severityWeights[string(vulns[i].Severity)] > severityWeights[string(vulns[j].Severity)]
+	})
+	for i, vuln := range vulns {
+		if i == maxAssessmentSummaryItems {
+			fmt.Fprintf(&b, "- ... and %d more\n", len(vulns)-i)
+			break
+		}
+		fmt.Fprintf(&b, "- [%s] %s (status %s)\n", vuln.Severity, vuln.Title, vuln.Status)
+	}
+	return b.String()
+}
+
+// writeList writes a titled bullet list when it has items
+func writeList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n%s:\n", title)
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+}
+
+var (
+	// Section headings, also when the model dresses them up as Markdown
+	summaryHeadingPattern  = regexp.MustCompile(`(?im)^[ \t#*_]*executive summary[ \t*_]*(?::[ \t*_]*|$)`)
+	keyRisksHeadingPattern = regexp.MustCompile(`(?im)^[ \t#*_]*key risks[ \t*_]*(?::[ \t*_]*|$)`)
+)
+
+// ParseSummaryCompletion splits the model's answer into the executive summary and the key-risk
+// narrative. Answers without the KEY RISKS section are kept whole as the summary.
+func ParseSummaryCompletion(text string) (string, string) {
+	var keyRisks string
+	if loc := keyRisksHeadingPattern.FindStringIndex(text); loc != nil {
+		keyRisks = strings.TrimSpace(text[loc[1]:])
+		text = text[:loc[0]]
+	}
+	if loc := summaryHeadingPattern.FindStringIndex(text); loc != nil {
+		text = text[loc[1]:]
+	}
+	return strings.TrimSpace(text), keyRisks
+}
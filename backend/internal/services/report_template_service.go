@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/docgen"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// MaxReportLogoSize is the largest logo a report template accepts
+const MaxReportLogoSize = 1 << 20
+
+// reportLogoMimeTypes are the logo formats report templates accept
+var reportLogoMimeTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+}
+
+// reportTemplateColumns are the columns a template update writes (all but the logo)
+var reportTemplateColumns = []string{
+	"name", "description", "is_default", "company_name", "intro_text",
+	"section_summary", "section_scope", "section_findings", "section_evidence", "section_remediation", "section_retests",
+}
+
+// ReportTemplateService manages report branding templates
+type ReportTemplateService struct {
+	db *gorm.DB
+}
+
+// NewReportTemplateService creates a new report template service
+func NewReportTemplateService(db *gorm.DB) *ReportTemplateService {
+	return &ReportTemplateService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries use ctx
+func (s *ReportTemplateService) WithContext(ctx context.Context) *ReportTemplateService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// ReportSectionsRequest switches report sections; omitted sections keep their setting
+type ReportSectionsRequest struct {
+	Summary     *bool `json:"summary,omitempty"`
+	Scope       *bool `json:"scope,omitempty"`
+	Findings    *bool `json:"findings,omitempty"`
+	Evidence    *bool `json:"evidence,omitempty"`
+	Remediation *bool `json:"remediation,omitempty"`
+	Retests     *bool `json:"retests,omitempty"`
+}
+
+// ReportTemplateRequest represents a create or update report template request
+type ReportTemplateRequest struct {
+	Name        *string                `json:"name,omitempty"`
+	Description *string                `json:"description,omitempty"`
+	IsDefault   *bool                  `json:"is_default,omitempty"`
+	CompanyName *string                `json:"company_name,omitempty"`
+	IntroText   *string                `json:"intro_text,omitempty"`
+	Sections    *ReportSectionsRequest `json:"sections,omitempty"`
+}
+
+// applyTo copies the provided request fields onto a template
+func (req ReportTemplateRequest) applyTo(template *models.ReportTemplate) {
+	if req.Name != nil {
+		template.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		template.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.IsDefault != nil {
+		template.IsDefault = *req.IsDefault
+	}
+	if req.CompanyName != nil {
+		template.CompanyName = strings.TrimSpace(*req.CompanyName)
+	}
+	if req.IntroText != nil {
+		template.IntroText = strings.TrimSpace(*req.IntroText)
+	}
+	if sections := req.Sections; sections != nil {
+		for _, toggle := range []struct {
+			value  *bool
+			target *bool
+		}{
+			{sections.Summary, &template.Sections.Summary},
+			{sections.Scope, &template.Sections.Scope},
+			{sections.Findings, &template.Sections.Findings},
+			{sections.Evidence, &template.Sections.Evidence},
+			{sections.Remediation, &template.Sections.Remediation},
+			{sections.Retests, &template.Sections.Retests},
+		} {
+			if toggle.value != nil {
+				*toggle.target = *toggle.value
+			}
+		}
+	}
+}
+
+// ValidateReportTemplate checks a template's name and text lengths
+func ValidateReportTemplate(template *models.ReportTemplate) error {
+	if template.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(template.Name) > 255 {
+		return fmt.Errorf("invalid name, must be at most 255 characters")
+	}
+	if len(template.CompanyName) > 255 {
+		return fmt.Errorf("invalid company_name, must be at most 255 characters")
+	}
+	if len(template.IntroText) > 10000 {
+		return fmt.Errorf("invalid intro_text, must be at most 10000 characters")
+	}
+	return nil
+}
+
+// ListTemplates returns all report templates, the default first
+func (s *ReportTemplateService) ListTemplates() ([]models.ReportTemplate, error) {
+	var templates []models.ReportTemplate
+	if err := s.db.Omit("logo").
+		Order("is_default DESC, name ASC").
+		Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("failed to list report templates: %w", err)
+	}
+
+	// Logos are only loaded with a single template
+	var withLogo []uuid.UUID
+	if err := s.db.Model(&models.ReportTemplate{}).Where("logo IS NOT NULL").Pluck("id", &withLogo).Error; err != nil {
+		return nil, fmt.Errorf("failed to list report templates: %w", err)
+	}
+	for i := range templates {
+		for _, id := range withLogo {
+			if templates[i].ID == id {
+				templates[i].HasLogo = true
+			}
+		}
+	}
+	return templates, nil
+}
+
+// GetTemplate returns a report template with its logo
+func (s *ReportTemplateService) GetTemplate(id uuid.UUID) (*models.ReportTemplate, error) {
+	var template models.ReportTemplate
+	if err := s.db.First(&template, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("report template not found")
+		}
+		return nil, fmt.Errorf("failed to get report template: %w", err)
+	}
+	template.HasLogo = len(template.Logo) > 0
+	return &template, nil
+}
+
+// ResolveTemplate returns the template a report is generated with: the named one, else the
+// default template, else nil for unbranded reports with every section
+func (s *ReportTemplateService) ResolveTemplate(id *uuid.UUID) (*models.ReportTemplate, error) {
+	if id != nil {
+		return s.GetTemplate(*id)
+	}
+
+	var template models.ReportTemplate
+	err := s.db.Where("is_default = ?", true).First(&template).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the default report template: %w", err)
+	}
+	template.HasLogo = len(template.Logo) > 0
+	return &template, nil
+}
+
+// CreateTemplate creates a report template; every section is included unless switched off
+func (s *ReportTemplateService) CreateTemplate(req ReportTemplateRequest, createdByID uuid.UUID) (*models.ReportTemplate, error) {
+	template := &models.ReportTemplate{
+		Sections:    models.AllReportSections(),
+		CreatedByID: createdByID,
+	}
+	req.applyTo(template)
+	if err := ValidateReportTemplate(template); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkNameAvailable(tx, template.Name, uuid.Nil); err != nil {
+			return err
+		}
+		if template.IsDefault {
+			if err := clearDefaultReportTemplate(tx); err != nil {
+				return err
+			}
+		}
+		// Switched-off sections are zero values
+		if err := createWithZeroValues(tx, template, reportTemplateColumns...); err != nil {
+			return fmt.Errorf("failed to create report template: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("template_id", template.ID.String()).
+		Str("created_by", createdByID.String()).
+		Str("name", template.Name).
+		Msg("Report template created")
+
+	return s.GetTemplate(template.ID)
+}
+
+// UpdateTemplate updates a report template; making it the default unsets the previous default
+func (s *ReportTemplateService) UpdateTemplate(id uuid.UUID, req ReportTemplateRequest, updatedByID uuid.UUID) (*models.ReportTemplate, error) {
+	template, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	wasDefault := template.IsDefault
+
+	req.applyTo(template)
+	if err := ValidateReportTemplate(template); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.checkNameAvailable(tx, template.Name, id); err != nil {
+			return err
+		}
+		if template.IsDefault && !wasDefault {
+			if err := clearDefaultReportTemplate(tx); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(template).Select(reportTemplateColumns).Updates(template).Error; err != nil {
+			return fmt.Errorf("failed to update report template: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("template_id", id.String()).
+		Str("updated_by", updatedByID.String()).
+		Msg("Report template updated")
+
+	return s.GetTemplate(id)
+}
+
+// checkNameAvailable rejects a name another template already uses
+func (s *ReportTemplateService) checkNameAvailable(tx *gorm.DB, name string, id uuid.UUID) error {
+	var count int64
+	if err := tx.Model(&models.ReportTemplate{}).
+		Where("LOWER(name) = LOWER(?) AND id <> ?", name, id).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check report template name: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("invalid name, a report template named %q already exists", name)
+	}
+	return nil
+}
+
+// clearDefaultReportTemplate unsets the current default template
+func clearDefaultReportTemplate(tx *gorm.DB) error {
+	if err := tx.Model(&models.ReportTemplate{}).
+		Where("is_default = ?", true).
+		Update("is_default", false).Error; err != nil {
+		return fmt.Errorf("failed to update the default report template: %w", err)
+	}
+	return nil
+}
+
+// DeleteTemplate soft deletes a report template; reports already generated keep its branding
+func (s *ReportTemplateService) DeleteTemplate(id, deletedByID uuid.UUID) error {
+	result := s.db.Delete(&models.ReportTemplate{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete report template: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("report template not found")
+	}
+
+	utils.Logger.Info().
+		Str("template_id", id.String()).
+		Str("deleted_by", deletedByID.String()).
+		Msg("Report template deleted")
+
+	return nil
+}
+
+// ValidateReportLogo checks an uploaded logo's size and format and returns its MIME type
+func ValidateReportLogo(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("logo is required")
+	}
+	if len(data) > MaxReportLogoSize {
+		return "", fmt.Errorf("invalid logo, must be at most %d KB", MaxReportLogoSize/1024)
+	}
+	mimeType := http.DetectContentType(data)
+	if !reportLogoMimeTypes[mimeType] {
+		return "", fmt.Errorf("invalid logo, must be a PNG, JPEG or GIF image")
+	}
+	if _, err := docgen.NewImage(data); err != nil {
+		return "", fmt.Errorf("invalid logo: %v", err)
+	}
+	return mimeType, nil
+}
+
+// SetLogo replaces a template's logo; nil data removes it
+func (s *ReportTemplateService) SetLogo(id uuid.UUID, data []byte, updatedByID uuid.UUID) (*models.ReportTemplate, error) {
+	template, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+
+	mimeType := ""
+	if data != nil {
+		if mimeType, err = ValidateReportLogo(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.db.Model(template).Updates(map[string]interface{}{
+		"logo":           data,
+		"logo_mime_type": mimeType,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update report template logo: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("template_id", id.String()).
+		Str("updated_by", updatedByID.String()).
+		Bool("removed", data == nil).
+		Msg("Report template logo updated")
+
+	return s.GetTemplate(id)
+}
@@ -0,0 +1,485 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxRiskAcceptanceDays is the longest period a risk acceptance may be granted for
+const MaxRiskAcceptanceDays = 365
+
+// RiskAcceptanceService handles the risk acceptance request, review, and expiry workflow
+type RiskAcceptanceService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+// NewRiskAcceptanceService creates a new risk acceptance service
+func NewRiskAcceptanceService(db *gorm.DB) *RiskAcceptanceService {
+	return &RiskAcceptanceService{
+		db:                  db,
+		notificationService: NewNotificationService(db),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *RiskAcceptanceService) WithContext(ctx context.Context) *RiskAcceptanceService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// CreateRiskAcceptanceRequest represents a new risk acceptance request
+type CreateRiskAcceptanceRequest struct {
+	FindingID            uuid.UUID `json:"finding_id"`
+	Justification        string    `json:"justification"`
+	CompensatingControls string    `json:"compensating_controls,omitempty"`
+	ExpiresAt            time.Time `json:"expires_at"`
+}
+
+// RiskAcceptanceFilters represents filters for the review queue
+type RiskAcceptanceFilters struct {
+	Status        string
+	FindingID     *uuid.UUID
+	RequestedByID *uuid.UUID
+}
+
+// validateExpiry checks that an expiry date is in the future and within the allowed window
+func validateExpiry(expiresAt, now time.Time) error {
+	if expiresAt.IsZero() {
+		return fmt.Errorf("expires_at is required")
+	}
+	if !expiresAt.After(now) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+	if expiresAt.After(now.AddDate(0, 0, MaxRiskAcceptanceDays)) {
+		return fmt.Errorf("expires_at cannot be more than %d days in the future", MaxRiskAcceptanceDays)
+	}
+	return nil
+}
+
+// getRiskAcceptance loads a risk acceptance with its relations
+func (s *RiskAcceptanceService) getRiskAcceptance(db *gorm.DB, id uuid.UUID) (*models.RiskAcceptance, error) {
+	var acceptance models.RiskAcceptance
+	if err := db.
+		Preload("Finding").
+		Preload("Finding.Vulnerability").
+		Preload("Finding.AffectedSystem").
+		Preload("RequestedBy").
+		Preload("ReviewedBy").
+		First(&acceptance, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("risk acceptance not found")
+		}
+		return nil, fmt.Errorf("failed to get risk acceptance: %w", err)
+	}
+	return &acceptance, nil
+}
+
+// GetRiskAcceptance returns a single risk acceptance
+func (s *RiskAcceptanceService) GetRiskAcceptance(id uuid.UUID) (*models.RiskAcceptance, error) {
+	return s.getRiskAcceptance(s.db, id)
+}
+
+// ListRiskAcceptances returns the review queue, oldest pending requests first
+func (s *RiskAcceptanceService) ListRiskAcceptances(filters RiskAcceptanceFilters, page, limit int) ([]models.RiskAcceptance, int64, error) {
+	query := s.db.Model(&models.RiskAcceptance{})
+	if filters.Status != "" {
+		query = query.Where("status = ?", strings.ToUpper(filters.Status))
+	}
+	if filters.FindingID != nil {
+		query = query.Where("finding_id = ?", *filters.FindingID)
+	}
+	if filters.RequestedByID != nil {
+		query = query.Where("requested_by_id = ?", *filters.RequestedByID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count risk acceptances: %w", err)
+	}
+
+	var acceptances []models.RiskAcceptance
+	if err := query.
+		Preload("Finding").
+		Preload("Finding.Vulnerability").
+		Preload("Finding.AffectedSystem").
+		Preload("RequestedBy").
+		Preload("ReviewedBy").
+		Order("created_at ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&acceptances).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list risk acceptances: %w", err)
+	}
+
+	return acceptances, total, nil
+}
+
+// RequestRiskAcceptance opens a pending risk acceptance request for a finding
+func (s *RiskAcceptanceService) RequestRiskAcceptance(req CreateRiskAcceptanceRequest, requestedByID uuid.UUID) (*models.RiskAcceptance, error) {
+	req.Justification = strings.TrimSpace(req.Justification)
+	if req.Justification == "" {
+		return nil, fmt.Errorf("justification is required")
+	}
+	if err := validateExpiry(req.ExpiresAt, time.Now()); err != nil {
+		return nil, err
+	}
+
+	acceptance := &models.RiskAcceptance{
+		FindingID:            req.FindingID,
+		Status:               models.RiskAcceptancePending,
+		Justification:        req.Justification,
+		CompensatingControls: strings.TrimSpace(req.CompensatingControls),
+		ExpiresAt:            req.ExpiresAt,
+		RequestedByID:        requestedByID,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var finding models.VulnerabilityFinding
+		if err := tx.First(&finding, "id = ?", req.FindingID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("finding not found")
+			}
+			return fmt.Errorf("failed to get finding: %w", err)
+		}
+		acceptance.OrgID = finding.OrgID
+
+		switch finding.Status {
+		case models.FindingStatusAccepted:
+			return fmt.Errorf("finding risk is already accepted")
+		case models.FindingStatusFixed, models.FindingStatusVerified:
+			return fmt.Errorf("finding is already remediated")
+		}
+
+		var pending int64
+		if err := tx.Model(&models.RiskAcceptance{}).
+			Where("finding_id = ? AND status = ?", req.FindingID, models.RiskAcceptancePending).
+			Count(&pending).Error; err != nil {
+			return fmt.Errorf("failed to check pending requests: %w", err)
+		}
+		if pending > 0 {
+			return fmt.Errorf("a risk acceptance request is already pending for this finding")
+		}
+
+		if err := tx.Create(acceptance).Error; err != nil {
+			return fmt.Errorf("failed to create risk acceptance: %w", err)
+		}
+
+		return s.notifyApprovers(tx, acceptance)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("risk_acceptance_id", acceptance.ID.String()).
+		Str("finding_id", req.FindingID.String()).
+		Str("requested_by", requestedByID.String()).
+		Msg("Risk acceptance requested")
+
+	return s.getRiskAcceptance(s.db, acceptance.ID)
+}
+
+// notifyApprovers notifies every user in the requester's organization whose role can approve
+// risk acceptances
+func (s *RiskAcceptanceService) notifyApprovers(tx *gorm.DB, acceptance *models.RiskAcceptance) error {
+	// Resolve approver roles in Go so inherited grants and deny rules are honored
+	roleIDs, err := (&RoleService{db: tx}).RoleIDsWithPermission("finding", "accept_risk")
+	if err != nil {
+		return fmt.Errorf("failed to find approver roles: %w", err)
+	}
+	if len(roleIDs) == 0 {
+		return nil
+	}
+
+	var approverIDs []uuid.UUID
+	if err := tx.Model(&models.User{}).
+		Where("role_id IN ?", roleIDs).
+		Where("id <> ?", acceptance.RequestedByID).
+		Where("org_id = (?)", tx.Model(&models.User{}).Select("org_id").Where("id = ?", acceptance.RequestedByID)).
+		Pluck("id", &approverIDs).Error; err != nil {
+		return fmt.Errorf("failed to find approvers: %w", err)
+	}
+
+	notifications := make([]models.Notification, 0, len(approverIDs))
+	for _, approverID := range approverIDs {
+		notifications = append(notifications, s.notification(acceptance, approverID,
+			models.NotificationTypeRiskAcceptanceRequested,
+			"Risk acceptance awaiting review",
+			acceptance.Justification,
+			&acceptance.RequestedByID,
+		))
+	}
+	return s.notificationService.CreateNotifications(tx, notifications)
+}
+
+// notification builds a risk acceptance notification
+func (s *RiskAcceptanceService) notification(acceptance *models.RiskAcceptance, userID uuid.UUID, notificationType models.NotificationType, title, message string, actorID *uuid.UUID) models.Notification {
+	resourceID := acceptance.ID
+	return models.Notification{
+		UserID:       userID,
+		Type:         notificationType,
+		Title:        title,
+		Message:      message,
+		ResourceType: "risk_acceptance",
+		ResourceID:   &resourceID,
+		ActorID:      actorID,
+	}
+}
+
+// getPendingForReview locks a pending request for review and enforces separation of duties
+func (s *RiskAcceptanceService) getPendingForReview(tx *gorm.DB, id, reviewerID uuid.UUID) (*models.RiskAcceptance, error) {
+	var acceptance models.RiskAcceptance
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&acceptance, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("risk acceptance not found")
+		}
+		return nil, fmt.Errorf("failed to get risk acceptance: %w", err)
+	}
+	if acceptance.Status != models.RiskAcceptancePending {
+		return nil, fmt.Errorf("risk acceptance is not pending (status: %s)", acceptance.Status)
+	}
+	if acceptance.RequestedByID == reviewerID {
+		return nil, fmt.Errorf("requesters cannot review their own risk acceptance")
+	}
+	return &acceptance, nil
+}
+
+// ApproveRiskAcceptance approves a pending request and moves the finding to ACCEPTED
+func (s *RiskAcceptanceService) ApproveRiskAcceptance(id, reviewerID uuid.UUID, notes string) (*models.RiskAcceptance, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		acceptance, err := s.getPendingForReview(tx, id, reviewerID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if !acceptance.ExpiresAt.After(now) {
+			return fmt.Errorf("risk acceptance expiry date has already passed")
+		}
+
+		if err := tx.Model(acceptance).Updates(map[string]interface{}{
+			"status":         models.RiskAcceptanceApproved,
+			"reviewed_by_id": reviewerID,
+			"reviewed_at":    now,
+			"review_notes":   strings.TrimSpace(notes),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to approve risk acceptance: %w", err)
+		}
+
+		var finding models.VulnerabilityFinding
+		if err := tx.First(&finding, "id = ?", acceptance.FindingID).Error; err != nil {
+			return fmt.Errorf("failed to get finding: %w", err)
+		}
+
+		if err := tx.Model(&finding).Updates(map[string]interface{}{
+			"status":            models.FindingStatusAccepted,
+			"risk_accepted_by":  reviewerID,
+			"risk_accepted_at":  now,
+			"acceptance_reason": acceptance.Justification,
+			"expires_at":        acceptance.ExpiresAt,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update finding: %w", err)
+		}
+
+		if err := tx.Create(&models.FindingStatusHistory{
+			FindingID:   finding.ID,
+			OldStatus:   finding.Status,
+			NewStatus:   models.FindingStatusAccepted,
+			Notes:       fmt.Sprintf("Risk accepted until %s: %s", acceptance.ExpiresAt.Format("2006-01-02"), acceptance.Justification),
+			ChangedByID: reviewerID,
+			ChangedAt:   now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to record status history: %w", err)
+		}
+
+		return s.notificationService.CreateNotifications(tx, []models.Notification{
+			s.notification(acceptance, acceptance.RequestedByID,
+				models.NotificationTypeRiskAcceptanceApproved,
+				"Risk acceptance approved",
+				notes,
+				&reviewerID,
+			),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("risk_acceptance_id", id.String()).
+		Str("reviewed_by", reviewerID.String()).
+		Msg("Risk acceptance approved")
+
+	return s.getRiskAcceptance(s.db, id)
+}
+
+// RejectRiskAcceptance rejects a pending request; the finding is left unchanged
+func (s *RiskAcceptanceService) RejectRiskAcceptance(id, reviewerID uuid.UUID, notes string) (*models.RiskAcceptance, error) {
+	notes = strings.TrimSpace(notes)
+	if notes == "" {
+		return nil, fmt.Errorf("review notes are required when rejecting")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		acceptance, err := s.getPendingForReview(tx, id, reviewerID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(acceptance).Updates(map[string]interface{}{
+			"status":         models.RiskAcceptanceRejected,
+			"reviewed_by_id": reviewerID,
+			"reviewed_at":    time.Now(),
+			"review_notes":   notes,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to reject risk acceptance: %w", err)
+		}
+
+		return s.notificationService.CreateNotifications(tx, []models.Notification{
+			s.notification(acceptance, acceptance.RequestedByID,
+				models.NotificationTypeRiskAcceptanceRejected,
+				"Risk acceptance rejected",
+				notes,
+				&reviewerID,
+			),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("risk_acceptance_id", id.String()).
+		Str("reviewed_by", reviewerID.String()).
+		Msg("Risk acceptance rejected")
+
+	return s.getRiskAcceptance(s.db, id)
+}
+
+// CancelRiskAcceptance withdraws a pending request (requester only)
+func (s *RiskAcceptanceService) CancelRiskAcceptance(id, userID uuid.UUID) (*models.RiskAcceptance, error) {
+	var acceptance models.RiskAcceptance
+	if err := s.db.First(&acceptance, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("risk acceptance not found")
+		}
+		return nil, fmt.Errorf("failed to get risk acceptance: %w", err)
+	}
+	if acceptance.RequestedByID != userID {
+		return nil, fmt.Errorf("only the requester can cancel this risk acceptance")
+	}
+	if acceptance.Status != models.RiskAcceptancePending {
+		return nil, fmt.Errorf("risk acceptance is not pending (status: %s)", acceptance.Status)
+	}
+
+	if err := s.db.Model(&acceptance).Update("status", models.RiskAcceptanceCancelled).Error; err != nil {
+		return nil, fmt.Errorf("failed to cancel risk acceptance: %w", err)
+	}
+
+	return s.getRiskAcceptance(s.db, id)
+}
+
+// ExpireRiskAcceptances re-opens findings whose accepted risk has expired and closes out
+// pending requests whose expiry date passed before review. Returns the number of re-opened findings.
+func (s *RiskAcceptanceService) ExpireRiskAcceptances() (int, error) {
+	now := time.Now()
+
+	// Pending requests that were never reviewed in time
+	if err := s.db.Model(&models.RiskAcceptance{}).
+		Where("status = ? AND expires_at < ?", models.RiskAcceptancePending, now).
+		Updates(map[string]interface{}{
+			"status":     models.RiskAcceptanceExpired,
+			"expired_at": now,
+		}).Error; err != nil {
+		return 0, fmt.Errorf("failed to expire pending risk acceptances: %w", err)
+	}
+
+	var findings []models.VulnerabilityFinding
+	if err := s.db.
+		Where("status = ? AND expires_at IS NOT NULL AND expires_at < ?", models.FindingStatusAccepted, now).
+		Find(&findings).Error; err != nil {
+		return 0, fmt.Errorf("failed to find expired risk acceptances: %w", err)
+	}
+
+	reopened := 0
+	for _, finding := range findings {
+		if err := s.reopenFinding(finding, now); err != nil {
+			utils.Logger.Error().Err(err).Str("finding_id", finding.ID.String()).Msg("Failed to re-open finding after risk acceptance expiry")
+			continue
+		}
+		reopened++
+	}
+
+	return reopened, nil
+}
+
+// reopenFinding moves a finding with expired accepted risk back to OPEN
+func (s *RiskAcceptanceService) reopenFinding(finding models.VulnerabilityFinding, now time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// The status history requires an actor; attribute the change to whoever accepted the risk
+		changedByID := finding.CreatedBy
+		if finding.RiskAcceptedBy != nil {
+			changedByID = *finding.RiskAcceptedBy
+		}
+
+		result := tx.Model(&models.VulnerabilityFinding{}).
+			Where("id = ? AND status = ?", finding.ID, models.FindingStatusAccepted).
+			Update("status", models.FindingStatusOpen)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil // Changed concurrently
+		}
+
+		if err := tx.Create(&models.FindingStatusHistory{
+			FindingID:   finding.ID,
+			OldStatus:   models.FindingStatusAccepted,
+			NewStatus:   models.FindingStatusOpen,
+			Notes:       fmt.Sprintf("Risk acceptance expired on %s; finding re-opened automatically", finding.ExpiresAt.Format("2006-01-02")),
+			ChangedByID: changedByID,
+			ChangedAt:   now,
+		}).Error; err != nil {
+			return err
+		}
+
+		var acceptances []models.RiskAcceptance
+		if err := tx.Where("finding_id = ? AND status = ?", finding.ID, models.RiskAcceptanceApproved).
+			Find(&acceptances).Error; err != nil {
+			return err
+		}
+
+		notifications := []models.Notification{}
+		for i := range acceptances {
+			acceptance := &acceptances[i]
+			if err := tx.Model(acceptance).Updates(map[string]interface{}{
+				"status":     models.RiskAcceptanceExpired,
+				"expired_at": now,
+			}).Error; err != nil {
+				return err
+			}
+			notifications = append(notifications, s.notification(acceptance, acceptance.RequestedByID,
+				models.NotificationTypeRiskAcceptanceExpired,
+				"Risk acceptance expired",
+				"The accepted risk has expired and the finding was re-opened",
+				nil,
+			))
+		}
+
+		utils.Logger.Info().
+			Str("finding_id", finding.ID.String()).
+			Msg("Finding re-opened after risk acceptance expiry")
+
+		return s.notificationService.CreateNotifications(tx, notifications)
+	})
+}
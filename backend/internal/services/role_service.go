@@ -22,26 +22,48 @@ func NewRoleService() *RoleService {
 	}
 }
 
+// RoleDefinition holds the editable fields of a custom role
+type RoleDefinition struct {
+	DisplayName       string
+	Description       string
+	Level             int
+	Permissions       models.PermissionMap
+	DeniedPermissions models.PermissionMap
+	Constraints       []models.AccessConstraint
+	ParentID          *uuid.UUID
+}
+
 // CreateRole creates a new role
-func (s *RoleService) CreateRole(name, displayName, description string, level int, permissions models.PermissionMap) (*models.Role, error) {
+func (s *RoleService) CreateRole(name string, def RoleDefinition) (*models.Role, error) {
 	// Check if role already exists
 	var existing models.Role
 	if err := s.db.Where("name = ?", name).First(&existing).Error; err == nil {
 		return nil, fmt.Errorf("role '%s' already exists", name)
 	}
 
+	if err := s.validateDefinition(uuid.Nil, def); err != nil {
+		return nil, err
+	}
+
 	role := &models.Role{
 		Name:        name,
-		DisplayName: displayName,
-		Description: description,
-		Level:       level,
+		DisplayName: def.DisplayName,
+		Description: def.Description,
+		Level:       def.Level,
+		ParentID:    def.ParentID,
 		IsDefault:   false,
 		IsSystem:    false,
 	}
 
-	if err := role.SetPermissions(permissions); err != nil {
+	if err := role.SetPermissions(def.Permissions); err != nil {
 		return nil, fmt.Errorf("failed to set permissions: %w", err)
 	}
+	if err := role.SetDeniedPermissions(def.DeniedPermissions); err != nil {
+		return nil, fmt.Errorf("failed to set denied permissions: %w", err)
+	}
+	if err := role.SetConstraints(def.Constraints); err != nil {
+		return nil, fmt.Errorf("failed to set constraints: %w", err)
+	}
 
 	if err := s.db.Create(role).Error; err != nil {
 		return nil, fmt.Errorf("failed to create role: %w", err)
@@ -49,7 +71,7 @@ func (s *RoleService) CreateRole(name, displayName, description string, level in
 
 	utils.Logger.Info().
 		Str("role_name", name).
-		Int("level", level).
+		Int("level", def.Level).
 		Msg("Role created successfully")
 
 	return role, nil
@@ -89,7 +111,7 @@ func (s *RoleService) GetAllRoles() ([]models.Role, error) {
 }
 
 // UpdateRole updates an existing role
-func (s *RoleService) UpdateRole(id uuid.UUID, displayName, description string, level int, permissions models.PermissionMap) (*models.Role, error) {
+func (s *RoleService) UpdateRole(id uuid.UUID, def RoleDefinition) (*models.Role, error) {
 	var role models.Role
 	if err := s.db.Where("id = ?", id).First(&role).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -103,13 +125,24 @@ func (s *RoleService) UpdateRole(id uuid.UUID, displayName, description string,
 		return nil, fmt.Errorf("cannot modify system role")
 	}
 
-	role.DisplayName = displayName
-	role.Description = description
-	role.Level = level
+	if err := s.validateDefinition(id, def); err != nil {
+		return nil, err
+	}
+
+	role.DisplayName = def.DisplayName
+	role.Description = def.Description
+	role.Level = def.Level
+	role.ParentID = def.ParentID
 
-	if err := role.SetPermissions(permissions); err != nil {
+	if err := role.SetPermissions(def.Permissions); err != nil {
 		return nil, fmt.Errorf("failed to set permissions: %w", err)
 	}
+	if err := role.SetDeniedPermissions(def.DeniedPermissions); err != nil {
+		return nil, fmt.Errorf("failed to set denied permissions: %w", err)
+	}
+	if err := role.SetConstraints(def.Constraints); err != nil {
+		return nil, fmt.Errorf("failed to set constraints: %w", err)
+	}
 
 	if err := s.db.Save(&role).Error; err != nil {
 		return nil, fmt.Errorf("failed to update role: %w", err)
@@ -148,6 +181,16 @@ func (s *RoleService) DeleteRole(id uuid.UUID) error {
 		return fmt.Errorf("cannot delete role: %d users are assigned to this role", userCount)
 	}
 
+	// Check if any roles inherit from this role
+	var childCount int64
+	if err := s.db.Model(&models.Role{}).Where("parent_id = ?", id).Count(&childCount).Error; err != nil {
+		return fmt.Errorf("failed to check role inheritance: %w", err)
+	}
+
+	if childCount > 0 {
+		return fmt.Errorf("cannot delete role: %d roles inherit from this role", childCount)
+	}
+
 	if err := s.db.Delete(&role).Error; err != nil {
 		return fmt.Errorf("failed to delete role: %w", err)
 	}
@@ -211,8 +254,134 @@ func (s *RoleService) CheckPermission(userID uuid.UUID, resource, action string)
 		return false, nil
 	}
 
-	// Check if role has the permission
-	return user.Role.HasPermission(resource, action), nil
+	// Check the role and its ancestors
+	effective, err := s.resolve(user.Role)
+	if err != nil {
+		return false, err
+	}
+	return effective.Allows(resource, action), nil
+}
+
+// GetEffectivePermissions returns the permissions a role grants after inheritance and deny rules
+func (s *RoleService) GetEffectivePermissions(id uuid.UUID) (*models.EffectivePermissions, error) {
+	role, err := s.GetRoleByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolve(role)
+}
+
+// RoleIDsWithPermission returns the IDs of every role whose effective permissions allow resource:action
+func (s *RoleService) RoleIDsWithPermission(resource, action string) ([]string, error) {
+	var roles []models.Role
+	if err := s.db.Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	ids := []string{}
+	for i := range roles {
+		effective, err := s.resolve(&roles[i])
+		if err != nil {
+			return nil, err
+		}
+		if effective.Allows(resource, action) {
+			ids = append(ids, roles[i].ID.String())
+		}
+	}
+	return ids, nil
+}
+
+// resolve merges a role with its ancestors
+func (s *RoleService) resolve(role *models.Role) (*models.EffectivePermissions, error) {
+	chain, err := s.roleChain(role)
+	if err != nil {
+		return nil, err
+	}
+	effective, err := models.ResolvePermissions(chain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid role permissions: %w", err)
+	}
+	return effective, nil
+}
+
+// roleChain returns the role followed by its ancestors, nearest first
+func (s *RoleService) roleChain(role *models.Role) ([]*models.Role, error) {
+	chain := []*models.Role{role}
+	seen := map[uuid.UUID]bool{role.ID: true}
+	for parentID := role.ParentID; parentID != nil; {
+		if len(chain) > models.MaxRoleInheritanceDepth {
+			return nil, fmt.Errorf("role inheritance is deeper than %d levels", models.MaxRoleInheritanceDepth)
+		}
+		if seen[*parentID] {
+			return nil, fmt.Errorf("role inheritance cycle detected")
+		}
+		parent, err := s.GetRoleByID(*parentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load parent role: %w", err)
+		}
+		seen[parent.ID] = true
+		chain = append(chain, parent)
+		parentID = parent.ParentID
+	}
+	return chain, nil
+}
+
+// validateDefinition checks permissions against the catalog and the parent against the inheritance rules.
+// id is uuid.Nil for a role that does not exist yet.
+func (s *RoleService) validateDefinition(id uuid.UUID, def RoleDefinition) error {
+	if err := models.ValidatePermissions(def.Permissions); err != nil {
+		return err
+	}
+	if err := models.ValidatePermissions(def.DeniedPermissions); err != nil {
+		return fmt.Errorf("denied permissions: %w", err)
+	}
+	for _, constraint := range def.Constraints {
+		if err := constraint.Validate(); err != nil {
+			return err
+		}
+	}
+
+	// Roles inheriting from this one must not end up above it
+	if id != uuid.Nil {
+		var higherChildren int64
+		if err := s.db.Model(&models.Role{}).Where("parent_id = ? AND level < ?", id, def.Level).Count(&higherChildren).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+		if higherChildren > 0 {
+			return fmt.Errorf("level must not exceed the level of roles that inherit from this role")
+		}
+	}
+
+	if def.ParentID == nil {
+		return nil
+	}
+	if *def.ParentID == id {
+		return fmt.Errorf("a role cannot inherit from itself")
+	}
+	parent, err := s.GetRoleByID(*def.ParentID)
+	if err != nil {
+		if err.Error() == "role not found" {
+			return fmt.Errorf("parent role not found")
+		}
+		return err
+	}
+	if parent.Level > def.Level {
+		return fmt.Errorf("a role can only inherit from a role of equal or lower level")
+	}
+
+	chain, err := s.roleChain(parent)
+	if err != nil {
+		return err
+	}
+	for _, ancestor := range chain {
+		if ancestor.ID == id {
+			return fmt.Errorf("role inheritance cycle detected")
+		}
+	}
+	if len(chain) > models.MaxRoleInheritanceDepth {
+		return fmt.Errorf("role inheritance is deeper than %d levels", models.MaxRoleInheritanceDepth)
+	}
+	return nil
 }
 
 // GetDefaultRole returns the default role for new users
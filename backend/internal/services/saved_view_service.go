@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// savedViewFilterKeys lists the list-endpoint query parameters a saved view may store per resource
+var savedViewFilterKeys = map[models.SavedViewResource][]string{
+	models.SavedViewResourceVulnerability: {
+		"severity", "status", "search", "assignedTo", "createdBy", "asset_id", "owner_team_id", "tags", "sortBy", "sortOrder", "limit",
+	},
+	models.SavedViewResourceAsset: {
+		"search", "criticality", "status", "environment", "system_type", "owner_id", "owner_team_id", "sort_by", "sort_order", "limit",
+	},
+}
+
+// SavedViewService handles saved filter/sort views for list endpoints
+type SavedViewService struct {
+	db *gorm.DB
+}
+
+// NewSavedViewService creates a new saved view service
+func NewSavedViewService(db *gorm.DB) *SavedViewService {
+	return &SavedViewService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *SavedViewService) WithContext(ctx context.Context) *SavedViewService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// ValidateSavedViewFilters checks the resource type and that every filter key is supported by its list endpoint
+func ValidateSavedViewFilters(resourceType models.SavedViewResource, filters map[string]string) error {
+	allowed, ok := savedViewFilterKeys[resourceType]
+	if !ok {
+		return fmt.Errorf("invalid resource_type, must be one of: vulnerability, asset")
+	}
+
+	for key := range filters {
+		supported := false
+		for _, allowedKey := range allowed {
+			if key == allowedKey {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			keys := append([]string{}, allowed...)
+			sort.Strings(keys)
+			return fmt.Errorf("invalid filter %q for %s views, must be one of: %s", key, resourceType, strings.Join(keys, ", "))
+		}
+	}
+	return nil
+}
+
+// SavedViewRequest represents a create or update saved view request
+type SavedViewRequest struct {
+	Name         *string            `json:"name,omitempty"`
+	Description  *string            `json:"description,omitempty"`
+	ResourceType *string            `json:"resource_type,omitempty"`
+	Filters      *map[string]string `json:"filters,omitempty"`
+	Shared       *bool              `json:"shared,omitempty"`
+}
+
+// visibleTo restricts a query to views owned by the user or shared
+func visibleTo(query *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return query.Where("owner_id = ? OR shared = ?", userID, true)
+}
+
+// ListViews returns the user's own and shared views, optionally for one resource type
+func (s *SavedViewService) ListViews(userID uuid.UUID, resourceType string) ([]models.SavedView, error) {
+	query := visibleTo(s.db.Model(&models.SavedView{}), userID)
+	if resourceType != "" {
+		query = query.Where("resource_type = ?", resourceType)
+	}
+
+	var views []models.SavedView
+	if err := query.Preload("Owner").Order("name ASC").Find(&views).Error; err != nil {
+		return nil, fmt.Errorf("failed to list saved views: %w", err)
+	}
+	return views, nil
+}
+
+// GetView returns a view visible to the user
+func (s *SavedViewService) GetView(id, userID uuid.UUID) (*models.SavedView, error) {
+	var view models.SavedView
+	if err := visibleTo(s.db.Preload("Owner"), userID).First(&view, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("saved view not found")
+		}
+		return nil, fmt.Errorf("failed to get saved view: %w", err)
+	}
+	return &view, nil
+}
+
+// getOwnedView loads a view and verifies the user owns it
+func (s *SavedViewService) getOwnedView(id, userID uuid.UUID) (*models.SavedView, error) {
+	view, err := s.GetView(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if view.OwnerID != userID {
+		return nil, fmt.Errorf("only the owner can modify this saved view")
+	}
+	return view, nil
+}
+
+// CreateView creates a saved view owned by the user
+func (s *SavedViewService) CreateView(req SavedViewRequest, ownerID uuid.UUID) (*models.SavedView, error) {
+	view := &models.SavedView{OwnerID: ownerID}
+	if req.Name != nil {
+		view.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		view.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.ResourceType != nil {
+		view.ResourceType = models.SavedViewResource(strings.ToLower(strings.TrimSpace(*req.ResourceType)))
+	}
+	if req.Filters != nil {
+		view.FilterValues = *req.Filters
+	}
+	if req.Shared != nil {
+		view.Shared = *req.Shared
+	}
+
+	if view.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if err := ValidateSavedViewFilters(view.ResourceType, view.FilterValues); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(view).Error; err != nil {
+		return nil, fmt.Errorf("failed to create saved view: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("saved_view_id", view.ID.String()).
+		Str("owner_id", ownerID.String()).
+		Msg("Saved view created")
+
+	return s.GetView(view.ID, ownerID)
+}
+
+// UpdateView updates a saved view (owner only); the resource type cannot change
+func (s *SavedViewService) UpdateView(id, userID uuid.UUID, req SavedViewRequest) (*models.SavedView, error) {
+	view, err := s.getOwnedView(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		view.Name = strings.TrimSpace(*req.Name)
+		if view.Name == "" {
+			return nil, fmt.Errorf("name is required")
+		}
+	}
+	if req.Description != nil {
+		view.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Filters != nil {
+		if err := ValidateSavedViewFilters(view.ResourceType, *req.Filters); err != nil {
+			return nil, err
+		}
+		view.FilterValues = *req.Filters
+	}
+	if req.Shared != nil {
+		view.Shared = *req.Shared
+	}
+
+	view.Owner = nil
+	if err := s.db.Save(view).Error; err != nil {
+		return nil, fmt.Errorf("failed to update saved view: %w", err)
+	}
+
+	return s.GetView(id, userID)
+}
+
+// DeleteView soft deletes a saved view (owner only)
+func (s *SavedViewService) DeleteView(id, userID uuid.UUID) error {
+	view, err := s.getOwnedView(id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(view).Error; err != nil {
+		return fmt.Errorf("failed to delete saved view: %w", err)
+	}
+	return nil
+}
+
+// ResolveFilters returns the stored filters of a view visible to the user for the given resource type
+func (s *SavedViewService) ResolveFilters(id, userID uuid.UUID, resourceType models.SavedViewResource) (map[string]string, error) {
+	view, err := s.GetView(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if view.ResourceType != resourceType {
+		return nil, fmt.Errorf("saved view is for %s, not %s", view.ResourceType, resourceType)
+	}
+	return view.FilterValues, nil
+}
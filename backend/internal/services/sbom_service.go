@@ -0,0 +1,536 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/sbom"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SBOMScannerName identifies findings created from SBOM components
+const SBOMScannerName = "sbom"
+
+// SBOMImportResult summarizes an SBOM upload
+type SBOMImportResult struct {
+	Format                 string   `json:"format"`
+	SpecVersion            string   `json:"spec_version,omitempty"`
+	Components             int      `json:"components"`
+	VulnerableComponents   int      `json:"vulnerable_components"`
+	CreatedVulnerabilities int      `json:"created_vulnerabilities"`
+	CreatedFindings        int      `json:"created_findings"`
+	UpdatedFindings        int      `json:"updated_findings"`
+	SuppressedFindings     int      `json:"suppressed_findings"`
+	Warnings               []string `json:"warnings,omitempty"`
+}
+
+// SBOMService stores the software components of assets and correlates them with known vulnerabilities
+type SBOMService struct {
+	db                 *gorm.DB
+	enrichment         *VulnerabilityEnrichmentService
+	findingService     *VulnerabilityFindingService
+	suppressionService *SuppressionService
+}
+
+// NewSBOMService creates a new SBOM service
+func NewSBOMService(db *gorm.DB) *SBOMService {
+	return &SBOMService{
+		db:                 db,
+		enrichment:         NewVulnerabilityEnrichmentService(db),
+		findingService:     NewVulnerabilityFindingService(db),
+		suppressionService: NewSuppressionService(db),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *SBOMService) WithContext(ctx context.Context) *SBOMService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	copied.enrichment = s.enrichment.WithContext(ctx)
+	return &copied
+}
+
+// sbomAdvisory is a known vulnerability and the asset components it affects
+type sbomAdvisory struct {
+	known      KnownVulnerability
+	components []sbom.Component
+}
+
+// ImportSBOM replaces an asset's components with those of a CycloneDX or SPDX document and
+// records a finding on the asset for every known vulnerability affecting a component. When
+// the vulnerability database is disabled or unreachable the components are still stored and
+// the result carries a warning.
+func (s *SBOMService) ImportSBOM(assetID uuid.UUID, data []byte, userID uuid.UUID) (*SBOMImportResult, error) {
+	doc, err := sbom.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var asset models.AffectedSystem
+	if err := s.db.Select("id", "ip_address").First(&asset, "id = ?", assetID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("asset not found")
+		}
+		return nil, fmt.Errorf("failed to get asset: %w", err)
+	}
+
+	result := &SBOMImportResult{
+		Format:      doc.Format,
+		SpecVersion: doc.SpecVersion,
+		Components:  len(doc.Components),
+	}
+
+	// Look advisories up before opening the transaction; the vulnerability database may be slow
+	purls := make([]string, 0, len(doc.Components))
+	for _, c := range doc.Components {
+		if purl := sbom.VersionedPURL(c); purl != "" {
+			purls = append(purls, purl)
+		}
+	}
+	found, err := s.enrichment.LookupPackages(purls)
+	switch {
+	case errors.Is(err, ErrEnrichmentDisabled):
+		result.Warnings = append(result.Warnings, "Vulnerability correlation is disabled; components were stored without findings")
+	case err != nil:
+		utils.Logger.Warn().Err(err).Str("asset_id", assetID.String()).Msg("SBOM vulnerability lookup failed")
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Vulnerability lookup failed; components were stored without findings: %v", err))
+	}
+
+	now := time.Now()
+	rows := make([]models.AssetSBOMComponent, len(doc.Components))
+	advisories := map[string]*sbomAdvisory{}
+	var advisoryIDs []string
+	for i, c := range doc.Components {
+		rows[i] = models.AssetSBOMComponent{
+			AssetID:    assetID,
+			BOMRef:     c.BOMRef,
+			Type:       c.Type,
+			Group:      truncate(c.Group, 255),
+			Name:       truncate(c.Name, 255),
+			Version:    truncate(c.Version, 255),
+			PURL:       c.PURL,
+			CPE:        c.CPE,
+			Licenses:   c.Licenses,
+			Supplier:   truncate(c.Supplier, 255),
+			SBOMFormat: doc.Format,
+			ImportedAt: now,
+		}
+		vulns := found[sbom.VersionedPURL(c)]
+		if len(vulns) == 0 {
+			continue
+		}
+		result.VulnerableComponents++
+		for _, known := range vulns {
+			rows[i].Advisories = append(rows[i].Advisories, known.ID)
+			if known.CVEID != "" && known.CVEID != known.ID {
+				rows[i].Advisories = append(rows[i].Advisories, known.CVEID)
+			}
+			advisory := advisories[known.ID]
+			if advisory == nil {
+				advisory = &sbomAdvisory{known: known}
+				advisories[known.ID] = advisory
+				advisoryIDs = append(advisoryIDs, known.ID)
+			}
+			advisory.components = append(advisory.components, c)
+		}
+	}
+	sort.Strings(advisoryIDs)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("asset_id = ?", assetID).Delete(&models.AssetSBOMComponent{}).Error; err != nil {
+			return fmt.Errorf("failed to clear asset components: %w", err)
+		}
+		if len(rows) > 0 {
+			if err := tx.CreateInBatches(&rows, 1000).Error; err != nil {
+				return fmt.Errorf("failed to store asset components: %w", err)
+			}
+		}
+		if len(advisoryIDs) == 0 {
+			return nil
+		}
+
+		suppressions, err := s.suppressionService.LoadMatcher(tx)
+		if err != nil {
+			return err
+		}
+
+		// Advisories that alias the same CVE map to one vulnerability, and so to one finding
+		outputs := map[uuid.UUID][]string{}
+		detectedVersions, fixedVersions := map[uuid.UUID]string{}, map[uuid.UUID]string{}
+		vulnerabilities := map[uuid.UUID]*models.Vulnerability{}
+		var order []uuid.UUID
+		for _, id := range advisoryIDs {
+			advisory := advisories[id]
+			vulnerability, created, err := s.enrichment.FindOrCreateVulnerability(tx, advisory.known, "SBOM", userID)
+			if err != nil {
+				return err
+			}
+			if created {
+				result.CreatedVulnerabilities++
+			}
+			if _, ok := vulnerabilities[vulnerability.ID]; !ok {
+				vulnerabilities[vulnerability.ID] = vulnerability
+				order = append(order, vulnerability.ID)
+			}
+			outputs[vulnerability.ID] = append(outputs[vulnerability.ID], sbomFindingOutput(advisory))
+			if detectedVersions[vulnerability.ID] == "" && len(advisory.components) > 0 {
+				detectedVersions[vulnerability.ID] = truncate(advisory.components[0].Version, 100)
+			}
+			if fixedVersions[vulnerability.ID] == "" && len(advisory.known.FixedVersions) > 0 {
+				fixedVersions[vulnerability.ID] = truncate(advisory.known.FixedVersions[0], 100)
+			}
+		}
+
+		for _, vulnID := range order {
+			vulnerability := vulnerabilities[vulnID]
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.VulnerabilityAffectedSystem{
+				VulnerabilityID:  vulnID.String(),
+				AffectedSystemID: assetID.String(),
+			}).Error; err != nil {
+				return fmt.Errorf("failed to link asset to vulnerability: %w", err)
+			}
+
+			pluginID := vulnerability.CVEID
+			if pluginID == "" {
+				pluginID, _, _ = strings.Cut(vulnerability.Title, ":")
+			}
+			finding, created, err := s.findingService.FindOrCreateFindingWithTx(tx, &models.VulnerabilityFinding{
+				VulnerabilityID:  vulnID,
+				AffectedSystemID: assetID,
+				PluginID:         truncate(pluginID, 50),
+				PluginOutput:     strings.Join(outputs[vulnID], "\n\n"),
+				DetectedVersion:  detectedVersions[vulnID],
+				FixedVersion:     fixedVersions[vulnID],
+				ScannerName:      SBOMScannerName,
+				Status:           models.FindingStatusOpen,
+				FirstDetected:    now,
+				LastSeen:         now,
+				CreatedBy:        userID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to record finding: %w", err)
+			}
+			if created {
+				result.CreatedFindings++
+			} else {
+				result.UpdatedFindings++
+			}
+
+			rule, err := s.suppressionService.ApplyWithTx(tx, suppressions, finding, SuppressionTarget{
+				PluginID:  finding.PluginID,
+				CVEID:     vulnerability.CVEID,
+				AssetID:   assetID,
+				IPAddress: asset.IPAddress,
+			}, "sbom import", userID)
+			if err != nil {
+				return err
+			}
+			if rule != nil {
+				result.SuppressedFindings++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("asset_id", assetID.String()).
+		Str("format", doc.Format).
+		Int("components", result.Components).
+		Int("vulnerable_components", result.VulnerableComponents).
+		Int("created_findings", result.CreatedFindings).
+		Msg("SBOM imported")
+
+	return result, nil
+}
+
+// sbomFindingOutput describes the components an advisory affects, for the finding's plugin output
+func sbomFindingOutput(advisory *sbomAdvisory) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s affects:", advisory.known.ID)
+	for _, c := range advisory.components {
+		name := c.Name
+		if c.Group != "" {
+			name = c.Group + "/" + c.Name
+		}
+		fmt.Fprintf(&b, "\n  %s %s", name, c.Version)
+		if c.PURL != "" {
+			fmt.Fprintf(&b, " (%s)", c.PURL)
+		}
+	}
+	if len(advisory.known.FixedVersions) > 0 {
+		fmt.Fprintf(&b, "\nFixed in: %s", strings.Join(advisory.known.FixedVersions, ", "))
+	}
+	return b.String()
+}
+
+// ListComponents returns a page of an asset's SBOM components ordered by name. With
+// vulnerableOnly, only components affected by a known advisory are returned.
+func (s *SBOMService) ListComponents(assetID uuid.UUID, search string, vulnerableOnly bool, page, limit int) ([]models.AssetSBOMComponent, int64, error) {
+	var count int64
+	if err := s.db.Model(&models.AffectedSystem{}).Where("id = ?", assetID).Count(&count).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get asset: %w", err)
+	}
+	if count == 0 {
+		return nil, 0, fmt.Errorf("asset not found")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	query := s.db.Model(&models.AssetSBOMComponent{}).Where("asset_id = ?", assetID)
+	if search = strings.TrimSpace(search); search != "" {
+		query = query.Where("name ILIKE ? OR purl ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+	if vulnerableOnly {
+		query = query.Where("cardinality(advisories) > 0")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count asset components: %w", err)
+	}
+
+	var components []models.AssetSBOMComponent
+	if err := query.Order("name ASC, version ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&components).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list asset components: %w", err)
+	}
+	return components, total, nil
+}
+
+// vexStateRank orders analysis states from least to most settled; an asset's statement for a
+// vulnerability found more than once (e.g. on several ports) is its least settled finding's
+var vexStateRank = map[string]int{
+	sbom.VEXStateExploitable:   0,
+	sbom.VEXStateInTriage:      1,
+	sbom.VEXStateNotAffected:   2,
+	sbom.VEXStateFalsePositive: 3,
+	sbom.VEXStateResolved:      4,
+}
+
+// vexAnalysisFor states a finding's status as a CycloneDX analysis. Risk acceptances and
+// exceptions are not_affected with a will_not_fix response, mitigations not_affected through a
+// mitigating control.
+func vexAnalysisFor(finding *models.VulnerabilityFinding) sbom.VEXAnalysis {
+	switch finding.Status {
+	case models.FindingStatusFixed, models.FindingStatusVerified:
+		return sbom.VEXAnalysis{State: sbom.VEXStateResolved, Detail: finding.FixNotes}
+	case models.FindingStatusMitigated:
+		return sbom.VEXAnalysis{
+			State:         sbom.VEXStateNotAffected,
+			Justification: sbom.VEXJustificationMitigatingControl,
+			Detail:        finding.FixNotes,
+		}
+	case models.FindingStatusAccepted, models.FindingStatusException:
+		detail := "Risk accepted"
+		if finding.AcceptanceReason != "" {
+			detail += ": " + finding.AcceptanceReason
+		}
+		if finding.ExpiresAt != nil {
+			detail += fmt.Sprintf(" (until %s)", finding.ExpiresAt.UTC().Format("2006-01-02"))
+		}
+		return sbom.VEXAnalysis{
+			State:    sbom.VEXStateNotAffected,
+			Response: []string{sbom.VEXResponseWillNotFix},
+			Detail:   detail,
+		}
+	case models.FindingStatusFalsePositive:
+		return sbom.VEXAnalysis{State: sbom.VEXStateFalsePositive}
+	case models.FindingStatusSuppressed:
+		return sbom.VEXAnalysis{
+			State:    sbom.VEXStateNotAffected,
+			Response: []string{sbom.VEXResponseWillNotFix},
+			Detail:   "Suppressed by rule",
+		}
+	}
+	return sbom.VEXAnalysis{State: sbom.VEXStateExploitable}
+}
+
+// vexSource names the database a vulnerability ID comes from
+func vexSource(id string) *sbom.VEXSource {
+	switch {
+	case strings.HasPrefix(id, "CVE-"):
+		return &sbom.VEXSource{Name: "NVD", URL: "https://nvd.nist.gov/vuln/detail/" + id}
+	case strings.HasPrefix(id, "GHSA-"):
+		return &sbom.VEXSource{Name: "GitHub", URL: "https://github.com/advisories/" + id}
+	}
+	return &sbom.VEXSource{Name: "OSV", URL: "https://osv.dev/vulnerability/" + id}
+}
+
+// vexComponentType maps an asset's system type to a CycloneDX component type
+func vexComponentType(systemType models.SystemType) string {
+	switch systemType {
+	case models.SystemTypeApplication:
+		return "application"
+	case models.SystemTypeContainer:
+		return "container"
+	case models.SystemTypeCloudService:
+		return "platform"
+	}
+	return "device"
+}
+
+// BuildAssetVEX writes a CycloneDX VEX document stating the exploitability of the asset's
+// findings. Findings are identified by CVE, or by advisory ID for SBOM findings without one;
+// other findings are left out. Each statement affects the SBOM components the advisory was
+// matched to, or the asset itself. findings must be ordered by detection and have their
+// Vulnerability loaded.
+func BuildAssetVEX(asset *models.AffectedSystem, components []models.AssetSBOMComponent, findings []models.VulnerabilityFinding, now time.Time) *sbom.VEXDocument {
+	assetName := asset.Hostname
+	for _, name := range []string{asset.IPAddress, asset.AssetID, asset.ID.String()} {
+		if assetName == "" {
+			assetName = name
+		}
+	}
+	assetRef := "asset-" + asset.ID.String()
+
+	doc := &sbom.VEXDocument{
+		BOMFormat:       "CycloneDX",
+		SpecVersion:     sbom.VEXSpecVersion,
+		SerialNumber:    "urn:uuid:" + uuid.New().String(),
+		Version:         1,
+		Vulnerabilities: []sbom.VEXVulnerability{},
+	}
+	doc.Metadata.Timestamp = now.UTC().Format(time.RFC3339)
+	doc.Metadata.Tools.Components = []sbom.VEXComponent{{Type: "application", Name: "CYOPS"}}
+	doc.Metadata.Component = &sbom.VEXComponent{BOMRef: assetRef, Type: vexComponentType(asset.SystemType), Name: assetName}
+
+	// Components affected by each advisory, by the ref the document gives them
+	affected := map[string][]string{}
+	refs := map[string]bool{assetRef: true}
+	for _, c := range components {
+		ref := c.BOMRef
+		if ref == "" {
+			ref = c.PURL
+		}
+		if ref == "" || refs[ref] {
+			ref = c.ID.String()
+		}
+		refs[ref] = true
+		componentType := c.Type
+		if componentType == "" {
+			componentType = "library"
+		}
+		doc.Components = append(doc.Components, sbom.VEXComponent{
+			BOMRef:  ref,
+			Type:    componentType,
+			Group:   c.Group,
+			Name:    c.Name,
+			Version: c.Version,
+			PURL:    c.PURL,
+			CPE:     c.CPE,
+		})
+		for _, advisory := range c.Advisories {
+			affected[advisory] = append(affected[advisory], ref)
+		}
+	}
+
+	index := map[string]int{}
+	for i := range findings {
+		finding := &findings[i]
+		vulnerability := finding.Vulnerability
+		if vulnerability == nil {
+			continue
+		}
+		id := vulnerability.CVEID
+		if id == "" && finding.ScannerName == SBOMScannerName {
+			id = finding.PluginID
+		}
+		if id == "" {
+			continue
+		}
+
+		analysis := vexAnalysisFor(finding)
+		analysis.FirstIssued = finding.FirstDetected.UTC().Format(time.RFC3339)
+		analysis.LastUpdated = finding.UpdatedAt.UTC().Format(time.RFC3339)
+
+		if i, ok := index[id]; ok {
+			// Findings are ordered by detection, so the statement keeps the first one's issue date
+			statement := &doc.Vulnerabilities[i]
+			lastUpdated := max(statement.Analysis.LastUpdated, analysis.LastUpdated)
+			if vexStateRank[analysis.State] < vexStateRank[statement.Analysis.State] {
+				analysis.FirstIssued = statement.Analysis.FirstIssued
+				statement.Analysis = analysis
+			}
+			statement.Analysis.LastUpdated = lastUpdated
+			continue
+		}
+
+		statement := sbom.VEXVulnerability{
+			BOMRef:         "vuln-" + id,
+			ID:             id,
+			Source:         vexSource(id),
+			Description:    vulnerability.Title,
+			Recommendation: vulnerability.MitigationRecommendations,
+			Analysis:       analysis,
+		}
+		if vulnerability.Severity != "" || vulnerability.CVSSScore != nil {
+			rating := sbom.VEXRating{
+				Score:    vulnerability.CVSSScore,
+				Severity: strings.ToLower(string(vulnerability.Severity)),
+				Vector:   vulnerability.CVSSVector,
+				Method:   "other",
+			}
+			switch {
+			case strings.HasPrefix(rating.Vector, "CVSS:3.1/"):
+				rating.Method = "CVSSv31"
+			case strings.HasPrefix(rating.Vector, "CVSS:3.0/"):
+				rating.Method = "CVSSv3"
+			}
+			statement.Ratings = []sbom.VEXRating{rating}
+		}
+		for _, ref := range affected[id] {
+			statement.Affects = append(statement.Affects, sbom.VEXAffect{Ref: ref})
+		}
+		if len(statement.Affects) == 0 {
+			statement.Affects = []sbom.VEXAffect{{Ref: assetRef}}
+		}
+
+		index[id] = len(doc.Vulnerabilities)
+		doc.Vulnerabilities = append(doc.Vulnerabilities, statement)
+	}
+	return doc
+}
+
+// ExportVEX returns a CycloneDX VEX document stating the exploitability of an asset's findings
+// against the components of its SBOM
+func (s *SBOMService) ExportVEX(assetID uuid.UUID) (*sbom.VEXDocument, error) {
+	var asset models.AffectedSystem
+	if err := s.db.First(&asset, "id = ?", assetID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("asset not found")
+		}
+		return nil, fmt.Errorf("failed to get asset: %w", err)
+	}
+
+	var components []models.AssetSBOMComponent
+	if err := s.db.Where("asset_id = ?", assetID).Order("name ASC, version ASC").Find(&components).Error; err != nil {
+		return nil, fmt.Errorf("failed to list asset components: %w", err)
+	}
+
+	var findings []models.VulnerabilityFinding
+	if err := s.db.Preload("Vulnerability").
+		Where("affected_system_id = ?", assetID).
+		Order("first_detected ASC").
+		Find(&findings).Error; err != nil {
+		return nil, fmt.Errorf("failed to list asset findings: %w", err)
+	}
+
+	return BuildAssetVEX(&asset, components, findings, time.Now()), nil
+}
@@ -0,0 +1,889 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/search"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchOutboxMaxAttempts is how many times an outbox entry is retried before it is left for inspection
+const SearchOutboxMaxAttempts = 5
+
+// maxSearchWindow is the deepest page OpenSearch serves by default (index.max_result_window)
+const maxSearchWindow = 10000
+
+var (
+	activeSearchIndexMu sync.RWMutex
+	activeSearchIndex   *SearchIndexService
+)
+
+// SetActiveSearchIndex routes list/search queries through the given index (nil routes them to Postgres)
+func SetActiveSearchIndex(idx *SearchIndexService) {
+	activeSearchIndexMu.Lock()
+	defer activeSearchIndexMu.Unlock()
+	activeSearchIndex = idx
+}
+
+// ActiveSearchIndex returns the configured search index, or nil when SEARCH_BACKEND is postgres
+func ActiveSearchIndex() *SearchIndexService {
+	activeSearchIndexMu.RLock()
+	defer activeSearchIndexMu.RUnlock()
+	return activeSearchIndex
+}
+
+// SearchIndexService mirrors vulnerabilities, assets, and findings into OpenSearch via the outbox
+type SearchIndexService struct {
+	db     *gorm.DB
+	client *search.Client
+}
+
+// NewSearchIndexService creates a new search index service
+func NewSearchIndexService(db *gorm.DB, client *search.Client) *SearchIndexService {
+	return &SearchIndexService{db: db, client: client}
+}
+
+// searchOutboxSource maps a table to the indexed entity affected by writes to it
+type searchOutboxSource struct {
+	entity string
+	field  string // Field holding the entity ID
+}
+
+// searchOutboxSources lists the tables whose writes are mirrored into the search index
+var searchOutboxSources = map[string]searchOutboxSource{
+	"vulnerabilities":                {models.SearchEntityVulnerability, "ID"},
+	"vulnerability_affected_systems": {models.SearchEntityVulnerability, "VulnerabilityID"},
+	"vulnerability_tags":             {models.SearchEntityVulnerability, "VulnerabilityID"},
+	"affected_systems":               {models.SearchEntityAsset, "ID"},
+	"asset_tags":                     {models.SearchEntityAsset, "AssetID"},
+	"vulnerability_findings":         {models.SearchEntityFinding, "ID"},
+}
+
+// RegisterSearchOutboxCallbacks installs GORM callbacks that write outbox entries in the same
+// transaction as every create, update, and delete of an indexed model. Bulk writes that do not
+// carry the record IDs (e.g. Model(&T{}).Where(...).Updates(...)) are not captured; run a
+// reindex after large manual changes.
+func RegisterSearchOutboxCallbacks(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Register("search:outbox_create", enqueueSearchOutbox); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("search:outbox_update", enqueueSearchOutbox); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("search:outbox_delete", enqueueSearchOutbox)
+}
+
+// enqueueSearchOutbox records outbox entries for the records written by a statement
+func enqueueSearchOutbox(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.Schema == nil {
+		return
+	}
+	source, ok := searchOutboxSources[tx.Statement.Schema.Table]
+	if !ok {
+		return
+	}
+	field := tx.Statement.Schema.LookUpField(source.field)
+	if field == nil {
+		return
+	}
+
+	ids := []uuid.UUID{}
+	collect := func(rv reflect.Value) {
+		rv = reflect.Indirect(rv)
+		if rv.Kind() != reflect.Struct {
+			return
+		}
+		value, zero := field.ValueOf(tx.Statement.Context, rv)
+		if zero {
+			return
+		}
+		switch v := value.(type) {
+		case uuid.UUID:
+			ids = append(ids, v)
+		case *uuid.UUID:
+			if v != nil {
+				ids = append(ids, *v)
+			}
+		case string:
+			if id, err := uuid.Parse(v); err == nil {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	rv := reflect.Indirect(tx.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			collect(rv.Index(i))
+		}
+	case reflect.Struct:
+		collect(rv)
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	entries := make([]models.SearchOutboxEntry, 0, len(ids))
+	for _, id := range ids {
+		entries = append(entries, models.SearchOutboxEntry{EntityType: source.entity, EntityID: id})
+	}
+
+	// Same connection (and transaction) as the write, so the entry commits or rolls back with it
+	if err := tx.Session(&gorm.Session{NewDB: true}).Create(&entries).Error; err != nil {
+		tx.AddError(fmt.Errorf("failed to record search outbox entry: %w", err))
+	}
+}
+
+// searchIndexMappings defines the index mappings per entity type
+var searchIndexMappings = map[string]map[string]interface{}{
+	models.SearchEntityVulnerability: {
+		"properties": map[string]interface{}{
+			"title":          textWithKeyword(),
+			"description":    map[string]string{"type": "text"},
+			"cve_id":         map[string]string{"type": "keyword"},
+			"severity":       map[string]string{"type": "keyword"},
+			"severity_rank":  map[string]string{"type": "integer"},
+			"status":         map[string]string{"type": "keyword"},
+			"source":         map[string]string{"type": "keyword"},
+			"cvss_score":     map[string]string{"type": "float"},
+			"assigned_to_id": map[string]string{"type": "keyword"},
+			"owner_team_id":  map[string]string{"type": "keyword"},
+			"created_by_id":  map[string]string{"type": "keyword"},
+			"asset_ids":      map[string]string{"type": "keyword"},
+			"tags":           map[string]string{"type": "keyword"},
+			"org_id":         map[string]string{"type": "keyword"},
+			"discovery_date": map[string]string{"type": "date"},
+			"created_at":     map[string]string{"type": "date"},
+			"updated_at":     map[string]string{"type": "date"},
+		},
+	},
+	models.SearchEntityAsset: {
+		"properties": map[string]interface{}{
+			"hostname":         textWithKeyword(),
+			"ip_address":       textWithKeyword(),
+			"asset_id":         textWithKeyword(),
+			"description":      map[string]string{"type": "text"},
+			"system_type":      map[string]string{"type": "keyword"},
+			"environment":      map[string]string{"type": "keyword"},
+			"criticality":      map[string]string{"type": "keyword"},
+			"criticality_rank": map[string]string{"type": "integer"},
+			"status":           map[string]string{"type": "keyword"},
+			"owner_id":         map[string]string{"type": "keyword"},
+			"owner_team_id":    map[string]string{"type": "keyword"},
+			"department":       map[string]string{"type": "keyword"},
+			"location":         map[string]string{"type": "keyword"},
+			"tags":             map[string]string{"type": "keyword"},
+			"org_id":           map[string]string{"type": "keyword"},
+			"created_at":       map[string]string{"type": "date"},
+			"updated_at":       map[string]string{"type": "date"},
+		},
+	},
+	models.SearchEntityFinding: {
+		"properties": map[string]interface{}{
+			"vulnerability_id":   map[string]string{"type": "keyword"},
+			"affected_system_id": map[string]string{"type": "keyword"},
+			"title":              textWithKeyword(),
+			"severity":           map[string]string{"type": "keyword"},
+			"hostname":           textWithKeyword(),
+			"ip_address":         textWithKeyword(),
+			"plugin_id":          map[string]string{"type": "keyword"},
+			"plugin_output":      map[string]string{"type": "text"},
+			"port":               map[string]string{"type": "keyword"},
+			"protocol":           map[string]string{"type": "keyword"},
+			"service_name":       map[string]string{"type": "keyword"},
+			"status":             map[string]string{"type": "keyword"},
+			"org_id":             map[string]string{"type": "keyword"},
+			"first_detected":     map[string]string{"type": "date"},
+			"last_seen":          map[string]string{"type": "date"},
+			"fixed_at":           map[string]string{"type": "date"},
+		},
+	},
+}
+
+// textWithKeyword maps a field as full text with a keyword sub-field for sorting and exact matches
+func textWithKeyword() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "text",
+		"fields": map[string]interface{}{
+			"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256},
+		},
+	}
+}
+
+// EnsureIndices creates the search indices if they do not exist
+func (s *SearchIndexService) EnsureIndices(ctx context.Context) error {
+	for entity, mappings := range searchIndexMappings {
+		if err := s.client.EnsureIndex(ctx, s.client.IndexName(entity), mappings); err != nil {
+			return fmt.Errorf("failed to ensure %s index: %w", entity, err)
+		}
+	}
+	return nil
+}
+
+// Ping checks that the search cluster is reachable
+func (s *SearchIndexService) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx)
+}
+
+// severityRank orders severities for sorting (higher is more severe)
+func severityRank(severity models.VulnerabilitySeverity) int {
+	switch severity {
+	case models.SeverityCritical:
+		return 4
+	case models.SeverityHigh:
+		return 3
+	case models.SeverityMedium:
+		return 2
+	case models.SeverityLow:
+		return 1
+	}
+	return 0
+}
+
+// criticalityRank orders asset criticality for sorting (higher is more critical)
+func criticalityRank(criticality *models.AssetCriticality) int {
+	if criticality == nil {
+		return 0
+	}
+	switch *criticality {
+	case models.CriticalityCritical:
+		return 4
+	case models.CriticalityHigh:
+		return 3
+	case models.CriticalityMedium:
+		return 2
+	case models.CriticalityLow:
+		return 1
+	}
+	return 0
+}
+
+// vulnerabilityDocument builds the search document for a vulnerability
+func vulnerabilityDocument(v *models.Vulnerability) map[string]interface{} {
+	assetIDs := make([]string, 0, len(v.AffectedSystems))
+	for _, system := range v.AffectedSystems {
+		assetIDs = append(assetIDs, system.ID.String())
+	}
+	tags := make([]string, 0, len(v.Tags))
+	for _, tag := range v.Tags {
+		tags = append(tags, tag.Tag)
+	}
+	doc := map[string]interface{}{
+		"title":          v.Title,
+		"description":    v.Description,
+		"cve_id":         v.CVEID,
+		"severity":       v.Severity,
+		"severity_rank":  severityRank(v.Severity),
+		"status":         v.Status,
+		"source":         v.Source,
+		"cvss_score":     v.CVSSScore,
+		"created_by_id":  v.CreatedByID.String(),
+		"asset_ids":      assetIDs,
+		"tags":           tags,
+		"discovery_date": v.DiscoveryDate,
+		"created_at":     v.CreatedAt,
+		"updated_at":     v.UpdatedAt,
+	}
+	if v.AssignedToID != nil {
+		doc["assigned_to_id"] = v.AssignedToID.String()
+	}
+	if v.OwnerTeamID != nil {
+		doc["owner_team_id"] = v.OwnerTeamID.String()
+	}
+	if v.OrgID != nil {
+		doc["org_id"] = v.OrgID.String()
+	}
+	return doc
+}
+
+// assetDocument builds the search document for an asset
+func assetDocument(a *models.AffectedSystem) map[string]interface{} {
+	tags := make([]string, 0, len(a.Tags))
+	for _, tag := range a.Tags {
+		tags = append(tags, tag.Tag)
+	}
+	doc := map[string]interface{}{
+		"hostname":         a.Hostname,
+		"ip_address":       a.IPAddress,
+		"asset_id":         a.AssetID,
+		"description":      a.Description,
+		"system_type":      a.SystemType,
+		"environment":      a.Environment,
+		"criticality_rank": criticalityRank(a.Criticality),
+		"status":           a.Status,
+		"department":       a.Department,
+		"location":         a.Location,
+		"tags":             tags,
+		"created_at":       a.CreatedAt,
+		"updated_at":       a.UpdatedAt,
+	}
+	if a.Criticality != nil {
+		doc["criticality"] = *a.Criticality
+	}
+	if a.OwnerID != nil {
+		doc["owner_id"] = a.OwnerID.String()
+	}
+	if a.OwnerTeamID != nil {
+		doc["owner_team_id"] = a.OwnerTeamID.String()
+	}
+	if a.OrgID != nil {
+		doc["org_id"] = a.OrgID.String()
+	}
+	return doc
+}
+
+// findingDocument builds the search document for a finding (denormalizing vulnerability and asset fields)
+func findingDocument(f *models.VulnerabilityFinding) map[string]interface{} {
+	doc := map[string]interface{}{
+		"vulnerability_id":   f.VulnerabilityID.String(),
+		"affected_system_id": f.AffectedSystemID.String(),
+		"plugin_id":          f.PluginID,
+		"plugin_output":      f.PluginOutput,
+		"port":               f.Port,
+		"protocol":           f.Protocol,
+		"service_name":       f.ServiceName,
+		"status":             f.Status,
+		"first_detected":     f.FirstDetected,
+		"last_seen":          f.LastSeen,
+		"fixed_at":           f.FixedAt,
+	}
+	if f.Vulnerability != nil {
+		doc["title"] = f.Vulnerability.Title
+		doc["severity"] = f.Vulnerability.Severity
+	}
+	if f.AffectedSystem != nil {
+		doc["hostname"] = f.AffectedSystem.Hostname
+		doc["ip_address"] = f.AffectedSystem.IPAddress
+	}
+	if f.OrgID != nil {
+		doc["org_id"] = f.OrgID.String()
+	}
+	return doc
+}
+
+// buildBulkOperations loads the current state of the given entities and returns index
+// operations for live records and delete operations for missing or soft-deleted ones
+func (s *SearchIndexService) buildBulkOperations(tx *gorm.DB, entity string, ids []uuid.UUID) ([]search.BulkOperation, error) {
+	index := s.client.IndexName(entity)
+	docs := map[uuid.UUID]map[string]interface{}{}
+
+	switch entity {
+	case models.SearchEntityVulnerability:
+		var vulns []models.Vulnerability
+		if err := tx.Preload("AffectedSystems").Preload("Tags").Where("id IN ?", ids).Find(&vulns).Error; err != nil {
+			return nil, err
+		}
+		for i := range vulns {
+			docs[vulns[i].ID] = vulnerabilityDocument(&vulns[i])
+		}
+
+		// Findings denormalize the vulnerability title and severity
+		if err := tx.Exec(`INSERT INTO search_outbox (entity_type, entity_id)
+			SELECT ?, f.id FROM vulnerability_findings f
+			WHERE f.vulnerability_id IN ?
+			AND NOT EXISTS (
+				SELECT 1 FROM search_outbox o
+				WHERE o.entity_type = ? AND o.entity_id = f.id AND o.processed_at IS NULL
+			)`, models.SearchEntityFinding, ids, models.SearchEntityFinding).Error; err != nil {
+			return nil, err
+		}
+	case models.SearchEntityAsset:
+		var assets []models.AffectedSystem
+		if err := tx.Preload("Tags").Where("id IN ?", ids).Find(&assets).Error; err != nil {
+			return nil, err
+		}
+		for i := range assets {
+			docs[assets[i].ID] = assetDocument(&assets[i])
+		}
+	case models.SearchEntityFinding:
+		var findings []models.VulnerabilityFinding
+		if err := tx.Preload("Vulnerability").Preload("AffectedSystem").Where("id IN ?", ids).Find(&findings).Error; err != nil {
+			return nil, err
+		}
+		for i := range findings {
+			docs[findings[i].ID] = findingDocument(&findings[i])
+		}
+	default:
+		return nil, fmt.Errorf("unknown search entity type %q", entity)
+	}
+
+	ops := make([]search.BulkOperation, 0, len(ids))
+	for _, id := range ids {
+		if doc, ok := docs[id]; ok {
+			ops = append(ops, search.BulkOperation{Index: index, ID: id.String(), Document: doc})
+		} else {
+			ops = append(ops, search.BulkOperation{Index: index, ID: id.String(), Delete: true})
+		}
+	}
+	return ops, nil
+}
+
+// ProcessOutbox mirrors a batch of pending outbox entries into the search index.
+// Returns the number of entries processed.
+func (s *SearchIndexService) ProcessOutbox(ctx context.Context, batchSize int) (int, error) {
+	processed := 0
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var entries []models.SearchOutboxEntry
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("processed_at IS NULL AND attempts < ?", SearchOutboxMaxAttempts).
+			Order("created_at ASC").
+			Limit(batchSize).
+			Find(&entries).Error; err != nil {
+			return fmt.Errorf("failed to load search outbox: %w", err)
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+
+		// Deduplicate: the latest state is indexed once per entity
+		idsByEntity := map[string][]uuid.UUID{}
+		seen := map[string]bool{}
+		for _, entry := range entries {
+			key := entry.EntityType + ":" + entry.EntityID.String()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			idsByEntity[entry.EntityType] = append(idsByEntity[entry.EntityType], entry.EntityID)
+		}
+
+		ops := []search.BulkOperation{}
+		for entity, ids := range idsByEntity {
+			entityOps, err := s.buildBulkOperations(tx, entity, ids)
+			if err != nil {
+				return fmt.Errorf("failed to build %s documents: %w", entity, err)
+			}
+			ops = append(ops, entityOps...)
+		}
+
+		entryIDs := make([]uuid.UUID, len(entries))
+		for i, entry := range entries {
+			entryIDs[i] = entry.ID
+		}
+
+		failures, err := s.client.Bulk(ctx, ops)
+		if err != nil {
+			// Whole batch failed (e.g. cluster unreachable); retry later
+			return tx.Model(&models.SearchOutboxEntry{}).
+				Where("id IN ?", entryIDs).
+				Updates(map[string]interface{}{
+					"attempts":   gorm.Expr("attempts + 1"),
+					"last_error": err.Error(),
+				}).Error
+		}
+
+		failedByID := map[string]string{}
+		for _, failure := range failures {
+			failedByID[failure.ID] = failure.Reason
+		}
+
+		now := time.Now()
+		succeeded := []uuid.UUID{}
+		for _, entry := range entries {
+			if reason, failed := failedByID[entry.EntityID.String()]; failed {
+				if err := tx.Model(&models.SearchOutboxEntry{}).
+					Where("id = ?", entry.ID).
+					Updates(map[string]interface{}{
+						"attempts":   gorm.Expr("attempts + 1"),
+						"last_error": reason,
+					}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			succeeded = append(succeeded, entry.ID)
+		}
+
+		if len(succeeded) > 0 {
+			if err := tx.Model(&models.SearchOutboxEntry{}).
+				Where("id IN ?", succeeded).
+				Update("processed_at", now).Error; err != nil {
+				return err
+			}
+		}
+
+		processed = len(succeeded)
+		if len(failures) > 0 {
+			utils.Logger.Warn().Int("failed", len(failures)).Msg("Some search index operations failed")
+		}
+		return nil
+	})
+
+	return processed, err
+}
+
+// PurgeProcessedOutbox deletes processed outbox entries older than the given age
+func (s *SearchIndexService) PurgeProcessedOutbox(olderThan time.Duration) (int64, error) {
+	result := s.db.Where("processed_at IS NOT NULL AND processed_at < ?", time.Now().Add(-olderThan)).
+		Delete(&models.SearchOutboxEntry{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge search outbox: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// EnqueueFullReindex queues every vulnerability, asset, and finding for indexing
+func (s *SearchIndexService) EnqueueFullReindex() (int64, error) {
+	var total int64
+	sources := []struct {
+		entity string
+		table  string
+		where  string
+	}{
+		{models.SearchEntityVulnerability, "vulnerabilities", "deleted_at IS NULL"},
+		{models.SearchEntityAsset, "affected_systems", "deleted_at IS NULL"},
+		{models.SearchEntityFinding, "vulnerability_findings", "1 = 1"},
+	}
+
+	for _, source := range sources {
+		result := s.db.Exec(fmt.Sprintf(
+			"INSERT INTO search_outbox (entity_type, entity_id) SELECT ?, id FROM %s WHERE %s",
+			source.table, source.where,
+		), source.entity)
+		if result.Error != nil {
+			return total, fmt.Errorf("failed to enqueue %s reindex: %w", source.entity, result.Error)
+		}
+		total += result.RowsAffected
+	}
+
+	utils.Logger.Info().Int64("entries", total).Msg("Full search reindex enqueued")
+	return total, nil
+}
+
+// SearchIndexStatus summarizes the search backend state
+type SearchIndexStatus struct {
+	Backend       string `json:"backend"`
+	Reachable     bool   `json:"reachable"`
+	Error         string `json:"error,omitempty"`
+	PendingOutbox int64  `json:"pending_outbox"`
+	FailedOutbox  int64  `json:"failed_outbox"`
+}
+
+// GetStatus returns reachability and outbox backlog information
+func (s *SearchIndexService) GetStatus(ctx context.Context) (*SearchIndexStatus, error) {
+	status := &SearchIndexStatus{Backend: "opensearch", Reachable: true}
+	if err := s.client.Ping(ctx); err != nil {
+		status.Reachable = false
+		status.Error = err.Error()
+	}
+
+	if err := s.db.Model(&models.SearchOutboxEntry{}).
+		Where("processed_at IS NULL AND attempts < ?", SearchOutboxMaxAttempts).
+		Count(&status.PendingOutbox).Error; err != nil {
+		return nil, fmt.Errorf("failed to count pending outbox entries: %w", err)
+	}
+	if err := s.db.Model(&models.SearchOutboxEntry{}).
+		Where("processed_at IS NULL AND attempts >= ?", SearchOutboxMaxAttempts).
+		Count(&status.FailedOutbox).Error; err != nil {
+		return nil, fmt.Errorf("failed to count failed outbox entries: %w", err)
+	}
+	return status, nil
+}
+
+// searchSort builds an OpenSearch sort clause from an allowed field map
+func searchSort(sortBy, sortOrder, defaultField string, fields map[string]string) ([]interface{}, error) {
+	if sortBy == "" {
+		sortBy = defaultField
+	}
+	field, ok := fields[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("sort field %q is not supported by the search index", sortBy)
+	}
+	order := "desc"
+	if strings.EqualFold(sortOrder, "asc") {
+		order = "asc"
+	}
+	return []interface{}{
+		map[string]interface{}{field: map[string]string{"order": order, "missing": "_last"}},
+		map[string]interface{}{"_id": map[string]string{"order": "asc"}}, // Stable tie-breaker
+	}, nil
+}
+
+// searchPage validates pagination against the search window
+func searchPage(page, limit int) (int, error) {
+	from := (page - 1) * limit
+	if from+limit > maxSearchWindow {
+		return 0, fmt.Errorf("page is beyond the search index result window")
+	}
+	return from, nil
+}
+
+// termsFilter returns a terms filter for a set of values
+func termsFilter(field string, values []string) map[string]interface{} {
+	return map[string]interface{}{"terms": map[string]interface{}{field: values}}
+}
+
+// termFilter returns a term filter for a single value
+func termFilter(field string, value interface{}) map[string]interface{} {
+	return map[string]interface{}{"term": map[string]interface{}{field: value}}
+}
+
+// textQuery returns a prefix-aware multi-field text query
+func textQuery(text string, fields []string) map[string]interface{} {
+	return map[string]interface{}{
+		"multi_match": map[string]interface{}{
+			"query":    text,
+			"type":     "bool_prefix",
+			"operator": "and",
+			"fields":   fields,
+		},
+	}
+}
+
+// boolQuery wraps must and filter clauses
+func boolQuery(must, filter []interface{}) map[string]interface{} {
+	b := map[string]interface{}{}
+	if len(must) > 0 {
+		b["must"] = must
+	}
+	if len(filter) > 0 {
+		b["filter"] = filter
+	}
+	if len(b) == 0 {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+	return map[string]interface{}{"bool": b}
+}
+
+// vulnerabilitySortFields maps list sort parameters to index fields
+var vulnerabilitySortFields = map[string]string{
+	"created_at":     "created_at",
+	"updated_at":     "updated_at",
+	"discovery_date": "discovery_date",
+	"severity":       "severity_rank",
+	"cvss_score":     "cvss_score",
+	"title":          "title.keyword",
+	"status":         "status",
+}
+
+// BuildVulnerabilitySearchQuery translates a vulnerability list request into an OpenSearch query
+func BuildVulnerabilitySearchQuery(req ListVulnerabilitiesRequest, page, limit int) (map[string]interface{}, error) {
+	from, err := searchPage(page, limit)
+	if err != nil {
+		return nil, err
+	}
+	sort, err := searchSort(req.SortBy, req.SortOrder, "created_at", vulnerabilitySortFields)
+	if err != nil {
+		return nil, err
+	}
+
+	must := []interface{}{}
+	filter := []interface{}{}
+	if req.Search != "" {
+		must = append(must, textQuery(req.Search, []string{"title^3", "cve_id^3", "description"}))
+	}
+	if len(req.Severity) > 0 {
+		values := make([]string, len(req.Severity))
+		for i, severity := range req.Severity {
+			values[i] = string(severity)
+		}
+		filter = append(filter, termsFilter("severity", values))
+	}
+	if len(req.Status) > 0 {
+		values := make([]string, len(req.Status))
+		for i, status := range req.Status {
+			values[i] = string(status)
+		}
+		filter = append(filter, termsFilter("status", values))
+	}
+	if req.AssignedTo != nil {
+		filter = append(filter, termFilter("assigned_to_id", req.AssignedTo.String()))
+	}
+	if req.OwnerTeamID != nil {
+		filter = append(filter, termFilter("owner_team_id", req.OwnerTeamID.String()))
+	}
+	if req.CreatedBy != nil {
+		filter = append(filter, termFilter("created_by_id", req.CreatedBy.String()))
+	}
+	if req.AssetID != nil {
+		filter = append(filter, termFilter("asset_ids", req.AssetID.String()))
+	}
+	// Vulnerabilities must carry every requested tag, as in the Postgres filter
+	for _, tag := range req.Tags {
+		filter = append(filter, termFilter("tags", strings.ToLower(strings.TrimSpace(tag))))
+	}
+	if req.OrgID != nil {
+		filter = append(filter, termFilter("org_id", req.OrgID.String()))
+	}
+
+	return map[string]interface{}{
+		"query": boolQuery(must, filter),
+		"sort":  sort,
+		"from":  from,
+		"size":  limit,
+	}, nil
+}
+
+// assetSortFields maps list sort parameters to index fields
+var assetSortFields = map[string]string{
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+	"hostname":    "hostname.keyword",
+	"criticality": "criticality_rank",
+	"status":      "status",
+}
+
+// BuildAssetSearchQuery translates an asset list request into an OpenSearch query
+func BuildAssetSearchQuery(params AssetListParams) (map[string]interface{}, error) {
+	from, err := searchPage(params.Page, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	sort, err := searchSort(params.SortBy, params.SortOrder, "created_at", assetSortFields)
+	if err != nil {
+		return nil, err
+	}
+
+	must := []interface{}{}
+	filter := []interface{}{}
+	if params.Search != "" {
+		must = append(must, textQuery(params.Search, []string{"hostname^3", "ip_address^3", "asset_id^2", "description"}))
+	}
+
+	// Match the Postgres behavior of listing only active assets by default
+	if params.Status != nil {
+		filter = append(filter, termFilter("status", string(*params.Status)))
+	} else {
+		filter = append(filter, termFilter("status", string(models.StatusActive)))
+	}
+	if params.Criticality != nil {
+		filter = append(filter, termFilter("criticality", string(*params.Criticality)))
+	}
+	if params.Environment != nil {
+		filter = append(filter, termFilter("environment", string(*params.Environment)))
+	}
+	if params.SystemType != nil {
+		filter = append(filter, termFilter("system_type", string(*params.SystemType)))
+	}
+	if params.OwnerID != nil {
+		filter = append(filter, termFilter("owner_id", params.OwnerID.String()))
+	}
+	if params.OwnerTeamID != nil {
+		filter = append(filter, termFilter("owner_team_id", params.OwnerTeamID.String()))
+	}
+	if params.OrgID != nil {
+		filter = append(filter, termFilter("org_id", params.OrgID.String()))
+	}
+	// Assets must carry every requested tag, as in the Postgres filter
+	for _, tag := range params.Tags {
+		filter = append(filter, termFilter("tags", strings.ToLower(strings.TrimSpace(tag))))
+	}
+
+	return map[string]interface{}{
+		"query": boolQuery(must, filter),
+		"sort":  sort,
+		"from":  from,
+		"size":  params.Limit,
+	}, nil
+}
+
+// BuildFindingSearchQuery translates finding list filters into an OpenSearch query
+func BuildFindingSearchQuery(filters map[string]interface{}, page, limit int) (map[string]interface{}, error) {
+	from, err := searchPage(page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := []interface{}{}
+	sortField := "last_seen"
+	if status, ok := filters["status"].(string); ok && status != "" {
+		filter = append(filter, termFilter("status", status))
+		if status == string(models.FindingStatusFixed) {
+			sortField = "fixed_at"
+		}
+	}
+	if severity, ok := filters["severity"].(string); ok && severity != "" {
+		filter = append(filter, termFilter("severity", severity))
+	}
+	if pluginID, ok := filters["plugin_id"].(string); ok && pluginID != "" {
+		filter = append(filter, termFilter("plugin_id", pluginID))
+	}
+	if orgID, ok := filters["org_id"].(uuid.UUID); ok && orgID != uuid.Nil {
+		filter = append(filter, termFilter("org_id", orgID.String()))
+	}
+
+	return map[string]interface{}{
+		"query": boolQuery(nil, filter),
+		"sort": []interface{}{
+			map[string]interface{}{sortField: map[string]string{"order": "desc", "missing": "_last"}},
+			map[string]interface{}{"_id": map[string]string{"order": "asc"}},
+		},
+		"from": from,
+		"size": limit,
+	}, nil
+}
+
+// searchIDs runs a query against an entity index and parses the resulting IDs
+func (s *SearchIndexService) searchIDs(entity string, query map[string]interface{}) ([]uuid.UUID, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.client.SearchIDs(ctx, s.client.IndexName(entity), query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(result.IDs))
+	for _, raw := range result.IDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, result.Total, nil
+}
+
+// orderByIDs reorders records to match the ranked ID list from the search index
+func orderByIDs[T any](records []T, ids []uuid.UUID, idOf func(*T) uuid.UUID) []T {
+	byID := make(map[uuid.UUID]T, len(records))
+	for i := range records {
+		byID[idOf(&records[i])] = records[i]
+	}
+	ordered := make([]T, 0, len(records))
+	for _, id := range ids {
+		if record, ok := byID[id]; ok {
+			ordered = append(ordered, record)
+		}
+	}
+	return ordered
+}
+
+// SearchVulnerabilityIDs returns the ranked vulnerability IDs for a list request
+func (s *SearchIndexService) SearchVulnerabilityIDs(req ListVulnerabilitiesRequest, page, limit int) ([]uuid.UUID, int64, error) {
+	query, err := BuildVulnerabilitySearchQuery(req, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.searchIDs(models.SearchEntityVulnerability, query)
+}
+
+// SearchAssetIDs returns the ranked asset IDs for a list request
+func (s *SearchIndexService) SearchAssetIDs(params AssetListParams) ([]uuid.UUID, int64, error) {
+	query, err := BuildAssetSearchQuery(params)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.searchIDs(models.SearchEntityAsset, query)
+}
+
+// SearchFindingIDs returns the ranked finding IDs for a list request
+func (s *SearchIndexService) SearchFindingIDs(filters map[string]interface{}, page, limit int) ([]uuid.UUID, int64, error) {
+	query, err := BuildFindingSearchQuery(filters, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.searchIDs(models.SearchEntityFinding, query)
+}
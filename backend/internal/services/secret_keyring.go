@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/kms"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	secretKeyringMu sync.RWMutex
+	secretKeyring   *kms.Keyring
+)
+
+// SetSecretKeyring sets the keyring integration credentials are encrypted with
+func SetSecretKeyring(keyring *kms.Keyring) {
+	secretKeyringMu.Lock()
+	defer secretKeyringMu.Unlock()
+	secretKeyring = keyring
+}
+
+// activeSecretKeyring returns the configured keyring, or when none is configured (tools and
+// tests that skip the KMS setup) a local keyring derived from the legacy secret
+func activeSecretKeyring(legacySecret string) *kms.Keyring {
+	secretKeyringMu.RLock()
+	defer secretKeyringMu.RUnlock()
+	if secretKeyring != nil {
+		return secretKeyring
+	}
+	return kms.NewKeyring(kms.DeriveLocalProvider(legacySecret)).WithLegacyKey(LegacySecretKey(legacySecret))
+}
+
+// LegacySecretKey returns the AES-256 key integration credentials were encrypted with before
+// envelope encryption: the secret zero padded or truncated to 32 bytes
+func LegacySecretKey(secret string) []byte {
+	key := make([]byte, 32)
+	copy(key, secret)
+	return key
+}
+
+// integrationSecretColumns are the integration config columns holding encrypted secrets
+var integrationSecretColumns = []string{"access_key", "secret_key", "client_key_pem"}
+
+// SecretRotationResult summarizes a re-encryption of stored secrets
+type SecretRotationResult struct {
+	Provider    string `json:"provider"`
+	KeyID       string `json:"key_id"`
+	Scanned     int    `json:"scanned"`
+	Reencrypted int    `json:"reencrypted"`
+	Current     int    `json:"current"` // Already encrypted under the active key
+	Failed      int    `json:"failed"`  // Integrations with a secret that could not be re-encrypted
+	DryRun      bool   `json:"dry_run"`
+}
+
+// ReencryptIntegrationSecrets re-encrypts integration credentials under the active key of the
+// keyring, including those of deleted integrations. Secrets already under the active key are
+// left alone unless force is set, which re-wraps them too (e.g. after rotating the key version
+// in Vault or AWS KMS).
+func ReencryptIntegrationSecrets(ctx context.Context, db *gorm.DB, keyring *kms.Keyring, dryRun, force bool) (*SecretRotationResult, error) {
+	result := &SecretRotationResult{
+		Provider: keyring.Active().Name(),
+		KeyID:    keyring.Active().KeyID(),
+		DryRun:   dryRun,
+	}
+
+	var configs []models.IntegrationConfig
+	if err := db.WithContext(ctx).Unscoped().Select("id", "access_key", "secret_key", "client_key_pem").Find(&configs).Error; err != nil {
+		return result, fmt.Errorf("failed to load integration configs: %w", err)
+	}
+
+	for _, config := range configs {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		values := map[string]string{
+			"access_key":     config.AccessKey,
+			"secret_key":     config.SecretKey,
+			"client_key_pem": config.ClientKeyPEM,
+		}
+		updates := make(map[string]interface{})
+		failed := false
+		for _, column := range integrationSecretColumns {
+			ciphertext := values[column]
+			if ciphertext == "" {
+				continue
+			}
+			result.Scanned++
+			if keyring.IsCurrent(ciphertext) && !force {
+				result.Current++
+				continue
+			}
+
+			plaintext, err := keyring.Decrypt(ctx, ciphertext)
+			if err != nil {
+				utils.Logger.Error().Err(err).Str("integration_id", config.ID.String()).Str("column", column).Msg("Failed to decrypt integration secret")
+				failed = true
+				continue
+			}
+			if dryRun {
+				result.Reencrypted++
+				continue
+			}
+			reencrypted, err := keyring.Encrypt(ctx, plaintext)
+			if err != nil {
+				utils.Logger.Error().Err(err).Str("integration_id", config.ID.String()).Str("column", column).Msg("Failed to encrypt integration secret")
+				failed = true
+				continue
+			}
+			updates[column] = reencrypted
+		}
+
+		if len(updates) > 0 {
+			if err := db.WithContext(ctx).Model(&models.IntegrationConfig{}).Unscoped().Where("id = ?", config.ID).UpdateColumns(updates).Error; err != nil {
+				utils.Logger.Error().Err(err).Str("integration_id", config.ID.String()).Msg("Failed to store re-encrypted integration secrets")
+				failed = true
+			} else {
+				result.Reencrypted += len(updates)
+			}
+		}
+		if failed {
+			result.Failed++
+		}
+	}
+
+	return result, nil
+}
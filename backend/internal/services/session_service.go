@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,6 +11,18 @@ import (
 	"github.com/cyops/cyops-backend/pkg/database"
 	"github.com/cyops/cyops-backend/pkg/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrAccessTokenExpired is returned for an access token past its lifetime; the client
+	// should redeem its refresh token
+	ErrAccessTokenExpired = errors.New("access token has expired")
+	// ErrInvalidRefreshToken is returned for unknown, expired or revoked refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when an already redeemed refresh token is presented
+	// again; the session it belongs to has been revoked
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
 )
 
 // SessionService handles session-related operations
@@ -24,23 +37,189 @@ func NewSessionService() *SessionService {
 	}
 }
 
-// CreateSession creates a new session for a user
-func (s *SessionService) CreateSession(userID uuid.UUID, ipAddress, userAgent string) (*models.Session, error) {
+// SessionTokens are the credentials handed to a client when a session is created or refreshed.
+// The refresh token is only ever returned here; the database keeps its hash.
+type SessionTokens struct {
+	Session          *models.Session
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// CreateSession creates a new session for a user along with the first refresh token of its chain.
+// location is the client's approximate location, or "" when unknown.
+func (s *SessionService) CreateSession(userID uuid.UUID, ipAddress, userAgent, location string) (*SessionTokens, error) {
 	session, err := auth.CreateSession(userID, ipAddress, userAgent, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
+	session.Location = location
 
-	if err := s.db.Create(session).Error; err != nil {
-		return nil, fmt.Errorf("failed to save session: %w", err)
+	var tokens *SessionTokens
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
+		}
+		refreshToken, refresh, err := issueRefreshToken(tx, session, ipAddress, time.Now())
+		if err != nil {
+			return err
+		}
+		tokens = &SessionTokens{
+			Session:          session,
+			AccessToken:      session.Token,
+			AccessExpiresAt:  session.AccessExpiresAt,
+			RefreshToken:     refreshToken,
+			RefreshExpiresAt: refresh.ExpiresAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	utils.Logger.Info().
 		Str("session_id", session.ID.String()).
 		Str("user_id", userID.String()).
+		Str("browser", session.Browser).
+		Str("os", session.OS).
 		Msg("Session created")
 
-	return session, nil
+	return tokens, nil
+}
+
+// Refresh redeems a refresh token: it rotates the session's access token and issues the next
+// refresh token of the chain. Presenting a token that was already redeemed revokes the session,
+// since either the client or an attacker holds a stolen copy.
+func (s *SessionService) Refresh(refreshToken, ipAddress, userAgent, location string) (*SessionTokens, error) {
+	if err := auth.ValidateSessionToken(refreshToken); err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var tokens *SessionTokens
+	var reused *models.RefreshToken
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var current models.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", auth.HashRefreshToken(refreshToken)).
+			First(&current).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		if current.UsedAt != nil {
+			// Commit the revocation instead of rolling it back with the error
+			if _, err := revokeSessions(tx, tx.Where("id = ?", current.SessionID), models.SessionRevokeRefreshReuse); err != nil {
+				return err
+			}
+			reused = &current
+			return nil
+		}
+		if !current.IsUsable() {
+			return ErrInvalidRefreshToken
+		}
+
+		var session models.Session
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", current.SessionID).
+			First(&session).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return fmt.Errorf("database error: %w", err)
+		}
+		if !session.IsValid() {
+			return ErrInvalidRefreshToken
+		}
+
+		now := time.Now()
+		accessToken, err := auth.GenerateSessionToken()
+		if err != nil {
+			return err
+		}
+		session.Token = accessToken
+		session.AccessExpiresAt = auth.AccessTokenExpiry(&session, now)
+		session.RefreshedAt = &now
+		session.LastUsedAt = &now
+		session.IPAddress = ipAddress
+		if location != "" {
+			session.Location = location
+		}
+		if userAgent != "" && userAgent != session.UserAgent {
+			device := auth.ParseUserAgent(userAgent)
+			session.UserAgent = userAgent
+			session.Browser, session.OS, session.DeviceType = device.Browser, device.OS, device.DeviceType
+		}
+		if err := tx.Save(&session).Error; err != nil {
+			return fmt.Errorf("failed to rotate access token: %w", err)
+		}
+
+		nextToken, next, err := issueRefreshToken(tx, &session, ipAddress, now)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&current).Updates(map[string]interface{}{
+			"used_at":     now,
+			"replaced_by": next.ID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to retire refresh token: %w", err)
+		}
+
+		tokens = &SessionTokens{
+			Session:          &session,
+			AccessToken:      accessToken,
+			AccessExpiresAt:  session.AccessExpiresAt,
+			RefreshToken:     nextToken,
+			RefreshExpiresAt: next.ExpiresAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if reused != nil {
+		event := models.NewAuthEvent(&reused.UserID, models.EventTypeRefreshTokenReuse, ipAddress, userAgent)
+		event.Success = false
+		event.FailReason = "refresh token replayed; session revoked"
+		event.Metadata = fmt.Sprintf(`{"session_id":%q}`, reused.SessionID.String())
+		if err := s.db.Create(event).Error; err != nil {
+			utils.Logger.Error().Err(err).Msg("Failed to log refresh token reuse event")
+		}
+
+		utils.Logger.Warn().
+			Str("session_id", reused.SessionID.String()).
+			Str("user_id", reused.UserID.String()).
+			Str("ip", ipAddress).
+			Msg("Refresh token reuse detected, session revoked")
+
+		return nil, ErrRefreshTokenReused
+	}
+
+	return tokens, nil
+}
+
+// issueRefreshToken creates the next refresh token of session's chain and returns its plaintext
+func issueRefreshToken(tx *gorm.DB, session *models.Session, ipAddress string, now time.Time) (string, *models.RefreshToken, error) {
+	token, err := auth.GenerateSessionToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	refresh := &models.RefreshToken{
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		TokenHash: auth.HashRefreshToken(token),
+		ExpiresAt: auth.RefreshTokenExpiry(session, now),
+		IPAddress: ipAddress,
+	}
+	if err := tx.Create(refresh).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	return token, refresh, nil
 }
 
 // GetSessionByToken retrieves a session by token
@@ -60,7 +239,7 @@ func (s *SessionService) GetSessionByToken(token string) (*models.Session, error
 	return &session, nil
 }
 
-// ValidateSession validates a session token and returns the session if valid
+// ValidateSession validates an access token and returns its session if valid
 func (s *SessionService) ValidateSession(token string) (*models.Session, error) {
 	session, err := s.GetSessionByToken(token)
 	if err != nil {
@@ -76,10 +255,13 @@ func (s *SessionService) ValidateSession(token string) (*models.Session, error)
 		}
 		return nil, fmt.Errorf("session is not active")
 	}
+	if session.IsAccessExpired() {
+		return nil, ErrAccessTokenExpired
+	}
 
 	// Update last used timestamp
 	session.UpdateLastUsed()
-	if err := s.db.Save(session).Error; err != nil {
+	if err := s.db.Model(session).UpdateColumn("last_used_at", session.LastUsedAt).Error; err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to update session last used timestamp")
 		// Don't fail validation if update fails
 	}
@@ -87,16 +269,15 @@ func (s *SessionService) ValidateSession(token string) (*models.Session, error)
 	return session, nil
 }
 
-// RevokeSession revokes a session by token
+// RevokeSession revokes the session of an access token and its refresh chain
 func (s *SessionService) RevokeSession(token string) error {
 	session, err := s.GetSessionByToken(token)
 	if err != nil {
 		return err
 	}
 
-	session.Revoke()
-	if err := s.db.Save(session).Error; err != nil {
-		return fmt.Errorf("failed to revoke session: %w", err)
+	if _, err := revokeSessions(s.db, s.db.Where("id = ?", session.ID), models.SessionRevokeLogout); err != nil {
+		return err
 	}
 
 	utils.Logger.Info().
@@ -107,19 +288,18 @@ func (s *SessionService) RevokeSession(token string) error {
 	return nil
 }
 
-// RevokeSessionByID revokes a session by its ID
+// RevokeSessionByID revokes one of a user's sessions and its refresh chain
 func (s *SessionService) RevokeSessionByID(sessionID uuid.UUID, userID uuid.UUID) error {
 	var session models.Session
-	if err := s.db.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
+	if err := s.db.Where("id = ? AND user_id = ? AND is_active = ?", sessionID, userID, true).First(&session).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return fmt.Errorf("session not found")
 		}
 		return fmt.Errorf("database error: %w", err)
 	}
 
-	session.Revoke()
-	if err := s.db.Save(&session).Error; err != nil {
-		return fmt.Errorf("failed to revoke session: %w", err)
+	if _, err := revokeSessions(s.db, s.db.Where("id = ?", session.ID), models.SessionRevokeUser); err != nil {
+		return err
 	}
 
 	utils.Logger.Info().
@@ -130,33 +310,82 @@ func (s *SessionService) RevokeSessionByID(sessionID uuid.UUID, userID uuid.UUID
 	return nil
 }
 
+// RevokeOtherSessions revokes every active session of a user except exceptSessionID (all of
+// them when nil), including their refresh chains, so the devices cannot sign back in silently
+func (s *SessionService) RevokeOtherSessions(userID uuid.UUID, exceptSessionID *uuid.UUID, reason string) (int64, error) {
+	var count int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		count, err = RevokeUserSessions(tx, userID, exceptSessionID, reason)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	utils.Logger.Info().
+		Str("user_id", userID.String()).
+		Int64("count", count).
+		Str("reason", reason).
+		Msg("User sessions revoked")
+
+	return count, nil
+}
+
 // RevokeAllUserSessions revokes all sessions for a user
-func (s *SessionService) RevokeAllUserSessions(userID uuid.UUID) error {
+func (s *SessionService) RevokeAllUserSessions(userID uuid.UUID, reason string) error {
+	_, err := s.RevokeOtherSessions(userID, nil, reason)
+	return err
+}
+
+// RevokeUserSessions revokes a user's active sessions except exceptSessionID, and their refresh
+// chains, within tx. It lets other services revoke sessions inside their own transactions.
+func RevokeUserSessions(tx *gorm.DB, userID uuid.UUID, exceptSessionID *uuid.UUID, reason string) (int64, error) {
+	query := tx.Where("user_id = ? AND is_active = ?", userID, true)
+	if exceptSessionID != nil {
+		query = query.Where("id != ?", *exceptSessionID)
+	}
+	return revokeSessions(tx, query, reason)
+}
+
+// revokeSessions revokes the sessions matched by scope and every unredeemed refresh token of
+// their chains. Revoking the refresh tokens as well as the session keeps a revoked chain dead
+// even if the session row were reactivated.
+func revokeSessions(tx *gorm.DB, scope *gorm.DB, reason string) (int64, error) {
+	var ids []uuid.UUID
+	if err := tx.Model(&models.Session{}).Where(scope).Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("failed to find sessions: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
 	now := time.Now()
-	result := s.db.Model(&models.Session{}).
-		Where("user_id = ? AND is_active = ?", userID, true).
+	result := tx.Model(&models.Session{}).
+		Where("id IN ?", ids).
 		Updates(map[string]interface{}{
-			"is_active":  false,
-			"revoked_at": now,
+			"is_active":     false,
+			"revoked_at":    gorm.Expr("COALESCE(revoked_at, ?)", now),
+			"revoke_reason": gorm.Expr("COALESCE(NULLIF(revoke_reason, ''), ?)", reason),
 		})
-
 	if result.Error != nil {
-		return fmt.Errorf("failed to revoke sessions: %w", result.Error)
+		return 0, fmt.Errorf("failed to revoke sessions: %w", result.Error)
 	}
 
-	utils.Logger.Info().
-		Str("user_id", userID.String()).
-		Int64("count", result.RowsAffected).
-		Msg("All user sessions revoked")
+	if err := tx.Model(&models.RefreshToken{}).
+		Where("session_id IN ? AND revoked_at IS NULL", ids).
+		Update("revoked_at", now).Error; err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
 
-	return nil
+	return result.RowsAffected, nil
 }
 
 // GetUserSessions retrieves all active sessions for a user
 func (s *SessionService) GetUserSessions(userID uuid.UUID) ([]models.Session, error) {
 	var sessions []models.Session
-	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).
-		Order("created_at DESC").
+	if err := s.db.Where("user_id = ? AND is_active = ? AND expires_at > ?", userID, true, time.Now()).
+		Order("last_used_at DESC NULLS LAST, created_at DESC").
 		Find(&sessions).Error; err != nil {
 		return nil, fmt.Errorf("failed to retrieve sessions: %w", err)
 	}
@@ -164,7 +393,7 @@ func (s *SessionService) GetUserSessions(userID uuid.UUID) ([]models.Session, er
 	return sessions, nil
 }
 
-// CleanupExpiredSessions removes expired sessions from the database
+// CleanupExpiredSessions removes expired sessions and refresh tokens from the database
 func (s *SessionService) CleanupExpiredSessions() (int64, error) {
 	result := s.db.Where("expires_at < ? OR (is_active = ? AND revoked_at < ?)",
 		time.Now(),
@@ -176,6 +405,11 @@ func (s *SessionService) CleanupExpiredSessions() (int64, error) {
 		return 0, fmt.Errorf("failed to cleanup sessions: %w", result.Error)
 	}
 
+	// Redeemed tokens are kept until they expire so that replaying them is still detected
+	if err := s.db.Where("expires_at < ?", time.Now()).Delete(&models.RefreshToken{}).Error; err != nil {
+		return 0, fmt.Errorf("failed to cleanup refresh tokens: %w", err)
+	}
+
 	if result.RowsAffected > 0 {
 		utils.Logger.Info().
 			Int64("count", result.RowsAffected).
@@ -0,0 +1,474 @@
+package services
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/siem"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Event categories a SIEM forwarder can subscribe to
+const (
+	SIEMCategoryAudit         = "audit"         // Authentication and account security events
+	SIEMCategoryVulnerability = "vulnerability" // Vulnerability and finding lifecycle events
+)
+
+const (
+	maxSIEMBufferSize = 1000000
+	siemCloseTimeout  = 5 * time.Second
+)
+
+// SIEMForwarderSettings configures forwarding of audit and vulnerability lifecycle events to a
+// SIEM. It is stored as JSON in the siem_forwarder system setting.
+type SIEMForwarderSettings struct {
+	Enabled            bool     `json:"enabled"`
+	Host               string   `json:"host"`
+	Port               int      `json:"port"`
+	Protocol           string   `json:"protocol"`                       // tcp or tls
+	Format             string   `json:"format"`                         // cef or rfc5424
+	Framing            string   `json:"framing,omitempty"`              // newline (default) or octet_counting
+	CACert             string   `json:"ca_cert,omitempty"`              // PEM bundle trusted for tls, in addition to the system roots
+	ServerName         string   `json:"server_name,omitempty"`          // Expected certificate name; defaults to host
+	InsecureSkipVerify bool     `json:"insecure_skip_verify,omitempty"` // Accept any certificate (testing only)
+	BufferSize         int      `json:"buffer_size,omitempty"`          // Events held while the SIEM is unreachable
+	Categories         []string `json:"categories,omitempty"`           // Forwarded categories; all when empty
+}
+
+// SIEMForwarder is the running forwarder of a siem_forwarder setting
+type SIEMForwarder struct {
+	*siem.Forwarder
+	Settings   SIEMForwarderSettings
+	categories map[string]bool
+}
+
+// Forwards reports whether events of a category are forwarded
+func (f *SIEMForwarder) Forwards(category string) bool {
+	return len(f.categories) == 0 || f.categories[category]
+}
+
+var (
+	siemForwarderMu     sync.RWMutex
+	activeSIEMForwarder *SIEMForwarder
+	activeSIEMSetting   string // Setting value of the active forwarder, to skip no-op reloads
+)
+
+// ValidateSIEMForwarderSettings checks the destination, transport and format and fills in defaults
+func ValidateSIEMForwarderSettings(settings *SIEMForwarderSettings) error {
+	settings.Host = strings.TrimSpace(settings.Host)
+	settings.Protocol = strings.ToLower(strings.TrimSpace(settings.Protocol))
+	settings.Format = strings.ToLower(strings.TrimSpace(settings.Format))
+	settings.Framing = strings.ToLower(strings.TrimSpace(settings.Framing))
+
+	if settings.Protocol == "" {
+		settings.Protocol = siem.ProtocolTCP
+	}
+	if settings.Format == "" {
+		settings.Format = siem.FormatCEF
+	}
+	if settings.Framing == "" {
+		settings.Framing = siem.FramingNewline
+	}
+
+	if settings.Enabled && settings.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if settings.Port < 0 || settings.Port > 65535 || (settings.Enabled && settings.Port == 0) {
+		return fmt.Errorf("invalid port: %d", settings.Port)
+	}
+	switch settings.Protocol {
+	case siem.ProtocolTCP, siem.ProtocolTLS:
+	default:
+		return fmt.Errorf("invalid protocol: %s (must be tcp or tls)", settings.Protocol)
+	}
+	switch settings.Format {
+	case siem.FormatCEF, siem.FormatRFC5424:
+	default:
+		return fmt.Errorf("invalid format: %s (must be cef or rfc5424)", settings.Format)
+	}
+	switch settings.Framing {
+	case siem.FramingNewline, siem.FramingOctetCounting:
+	default:
+		return fmt.Errorf("invalid framing: %s (must be newline or octet_counting)", settings.Framing)
+	}
+	if settings.CACert != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(settings.CACert)) {
+		return fmt.Errorf("invalid ca_cert: no PEM certificates found")
+	}
+	if settings.BufferSize < 0 || settings.BufferSize > maxSIEMBufferSize {
+		return fmt.Errorf("invalid buffer_size: must be at most %d", maxSIEMBufferSize)
+	}
+	if settings.BufferSize == 0 {
+		settings.BufferSize = siem.DefaultBufferSize
+	}
+	for i, category := range settings.Categories {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if category != SIEMCategoryAudit && category != SIEMCategoryVulnerability {
+			return fmt.Errorf("invalid category: %s", category)
+		}
+		settings.Categories[i] = category
+	}
+	return nil
+}
+
+// ParseSIEMForwarderSettings parses and validates a siem_forwarder setting value
+func ParseSIEMForwarderSettings(value string) (*SIEMForwarderSettings, error) {
+	var settings SIEMForwarderSettings
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return nil, fmt.Errorf("invalid SIEM forwarder settings: %v", err)
+	}
+	if err := ValidateSIEMForwarderSettings(&settings); err != nil {
+		return nil, fmt.Errorf("invalid SIEM forwarder settings: %v", err)
+	}
+	return &settings, nil
+}
+
+// BuildSIEMForwarder starts the forwarder of validated settings; disabled settings build none
+func BuildSIEMForwarder(settings *SIEMForwarderSettings) (*SIEMForwarder, error) {
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	var tlsConfig *tls.Config
+	if settings.Protocol == siem.ProtocolTLS {
+		tlsConfig = &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			ServerName:         settings.ServerName,
+			InsecureSkipVerify: settings.InsecureSkipVerify,
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = settings.Host
+		}
+		if settings.CACert != "" {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			pool.AppendCertsFromPEM([]byte(settings.CACert))
+			tlsConfig.RootCAs = pool
+		}
+	}
+
+	hostname, _ := os.Hostname()
+	forwarder, err := siem.New(siem.Config{
+		Address:    net.JoinHostPort(settings.Host, strconv.Itoa(settings.Port)),
+		Protocol:   settings.Protocol,
+		Format:     settings.Format,
+		Framing:    settings.Framing,
+		TLSConfig:  tlsConfig,
+		BufferSize: settings.BufferSize,
+		Hostname:   hostname,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid SIEM forwarder settings: %v", err)
+	}
+
+	categories := map[string]bool{}
+	for _, category := range settings.Categories {
+		categories[category] = true
+	}
+	return &SIEMForwarder{Forwarder: forwarder, Settings: *settings, categories: categories}, nil
+}
+
+// ReloadSIEMForwarder re-reads the siem_forwarder setting and restarts the forwarder when it
+// changed, picking up changes made through another instance
+func ReloadSIEMForwarder(db *gorm.DB) error {
+	var setting models.SystemSetting
+	err := db.Where("key = ?", string(models.SystemSettingSIEMForwarder)).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		SetActiveSIEMForwarder(nil, "")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load SIEM forwarder settings: %w", err)
+	}
+
+	siemForwarderMu.RLock()
+	unchanged := setting.Value == activeSIEMSetting
+	siemForwarderMu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	settings, err := ParseSIEMForwarderSettings(setting.Value)
+	if err != nil {
+		return err
+	}
+	return activateSIEMForwarder(settings, setting.Value)
+}
+
+// activateSIEMForwarder starts the forwarder of saved settings in place of the active one
+func activateSIEMForwarder(settings *SIEMForwarderSettings, value string) error {
+	forwarder, err := BuildSIEMForwarder(settings)
+	if err != nil {
+		return err
+	}
+	SetActiveSIEMForwarder(forwarder, value)
+	return nil
+}
+
+// SetActiveSIEMForwarder switches the forwarder events are sent to (nil stops forwarding) and
+// stops the previous one after it delivered what it buffered
+func SetActiveSIEMForwarder(forwarder *SIEMForwarder, value string) {
+	siemForwarderMu.Lock()
+	previous := activeSIEMForwarder
+	activeSIEMForwarder = forwarder
+	activeSIEMSetting = value
+	siemForwarderMu.Unlock()
+
+	if previous != nil {
+		go previous.Close(siemCloseTimeout)
+	}
+	if forwarder != nil {
+		utils.Logger.Info().
+			Str("host", forwarder.Settings.Host).
+			Int("port", forwarder.Settings.Port).
+			Str("protocol", forwarder.Settings.Protocol).
+			Str("format", forwarder.Settings.Format).
+			Msg("SIEM event forwarding enabled")
+	} else if previous != nil {
+		utils.Logger.Info().Msg("SIEM event forwarding disabled")
+	}
+}
+
+// ActiveSIEMForwarder returns the running forwarder, or nil when forwarding is disabled
+func ActiveSIEMForwarder() *SIEMForwarder {
+	siemForwarderMu.RLock()
+	defer siemForwarderMu.RUnlock()
+	return activeSIEMForwarder
+}
+
+// CloseSIEMForwarder stops forwarding on shutdown, delivering buffered events if the SIEM is reachable
+func CloseSIEMForwarder() {
+	siemForwarderMu.Lock()
+	forwarder := activeSIEMForwarder
+	activeSIEMForwarder = nil
+	activeSIEMSetting = ""
+	siemForwarderMu.Unlock()
+
+	if forwarder != nil {
+		forwarder.Close(siemCloseTimeout)
+	}
+}
+
+// RegisterSIEMCallbacks installs GORM callbacks that forward audit events and vulnerability
+// lifecycle changes to the active SIEM forwarder. Events are forwarded once written, so a
+// write rolled back with its transaction may still have been forwarded.
+func RegisterSIEMCallbacks(db *gorm.DB) error {
+	forward := func(deleted bool) func(tx *gorm.DB) {
+		return func(tx *gorm.DB) {
+			if tx.Error != nil || tx.Statement.Schema == nil || tx.Statement.RowsAffected == 0 {
+				return
+			}
+			forwarder := ActiveSIEMForwarder()
+			if forwarder == nil {
+				return
+			}
+			var orgID string
+			if id, ok := tenant.OrgFromContext(tx.Statement.Context); ok {
+				orgID = id.String()
+			}
+			eachRecord(tx.Statement.ReflectValue, func(record interface{}) {
+				category, event, ok := SIEMEventFor(record, deleted)
+				if !ok || !forwarder.Forwards(category) {
+					return
+				}
+				if orgID != "" && event.Fields["orgId"] == "" {
+					event.Fields["orgId"] = orgID
+				}
+				forwarder.Send(event)
+			})
+		}
+	}
+
+	if err := db.Callback().Create().After("gorm:create").Register("siem:forward_create", forward(false)); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("siem:forward_delete", forward(true))
+}
+
+// eachRecord calls fn with every struct a statement wrote
+func eachRecord(value reflect.Value, fn func(record interface{})) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			eachRecord(value.Index(i), fn)
+		}
+	case reflect.Struct:
+		if value.CanAddr() {
+			fn(value.Addr().Interface())
+		} else {
+			fn(value.Interface())
+		}
+	}
+}
+
+// siemAuthSeverity rates authentication events that indicate an attack or account takeover
+var siemAuthSeverity = map[models.EventType]int{
+	models.EventTypeLoginFailed:          5,
+	models.EventTypeAccountLocked:        7,
+	models.EventTypeRefreshTokenReuse:    8,
+	models.EventTypeImpersonationStarted: 6,
+	models.EventTypeImpersonatedRequest:  4,
+	models.EventTypeTwoFactorDisabled:    5,
+	models.EventTypePasswordReset:        4,
+	models.EventTypeBackupFailed:         5,
+	models.EventTypeRestoreStarted:       6,
+	models.EventTypeRestoreFailed:        6,
+	models.EventTypeIPBlocked:            6,
+}
+
+// siemVulnerabilitySeverity maps vulnerability severities to CEF severities
+var siemVulnerabilitySeverity = map[models.VulnerabilitySeverity]int{
+	models.SeverityCritical: 9,
+	models.SeverityHigh:     7,
+	models.SeverityMedium:   5,
+	models.SeverityLow:      3,
+}
+
+// SIEMEventFor converts a written record into a SIEM event and its category. Records that are
+// not audit or lifecycle events report false.
+func SIEMEventFor(record interface{}, deleted bool) (string, siem.Event, bool) {
+	switch r := record.(type) {
+	case *models.AuthEvent:
+		if deleted {
+			return "", siem.Event{}, false
+		}
+		severity := siemAuthSeverity[r.EventType]
+		if severity == 0 {
+			severity = 3
+		}
+		outcome := "success"
+		if !r.Success {
+			outcome = "failure"
+			if severity < 5 {
+				severity = 5
+			}
+		}
+		return SIEMCategoryAudit, siem.Event{
+			Time:     r.CreatedAt,
+			Type:     "auth." + string(r.EventType),
+			Name:     "Authentication event: " + strings.ReplaceAll(string(r.EventType), "_", " "),
+			Severity: severity,
+			Fields: map[string]string{
+				"externalId":               r.ID.String(),
+				"suid":                     uuidString(r.UserID),
+				"src":                      r.IPAddress,
+				"requestClientApplication": r.UserAgent,
+				"outcome":                  outcome,
+				"reason":                   r.FailReason,
+				"requestId":                r.RequestID,
+				"impersonatorId":           uuidString(r.ImpersonatorID),
+			},
+		}, true
+
+	case *models.Vulnerability:
+		eventType, name := "vulnerability.created", "Vulnerability created"
+		if deleted {
+			eventType, name = "vulnerability.deleted", "Vulnerability deleted"
+		}
+		fields := map[string]string{
+			"externalId": r.ID.String(),
+			"orgId":      uuidString(r.OrgID),
+			"title":      r.Title,
+			"severity":   string(r.Severity),
+			"status":     string(r.Status),
+			"cveId":      r.CVEID,
+			"source":     r.Source,
+		}
+		if !deleted {
+			fields["suid"] = r.CreatedByID.String()
+		}
+		if r.ID == uuid.Nil {
+			return "", siem.Event{}, false
+		}
+		return SIEMCategoryVulnerability, siem.Event{
+			Time:     time.Now(),
+			Type:     eventType,
+			Name:     name,
+			Severity: siemVulnerabilitySeverity[r.Severity],
+			Fields:   fields,
+		}, true
+
+	case *models.VulnerabilityStatusHistory:
+		if deleted {
+			return "", siem.Event{}, false
+		}
+		return SIEMCategoryVulnerability, siem.Event{
+			Time:     r.ChangedAt,
+			Type:     "vulnerability.status_changed",
+			Name:     fmt.Sprintf("Vulnerability status changed from %s to %s", r.OldStatus, r.NewStatus),
+			Severity: 3,
+			Fields: map[string]string{
+				"externalId": r.VulnerabilityID.String(),
+				"suid":       r.ChangedByID.String(),
+				"oldStatus":  string(r.OldStatus),
+				"newStatus":  string(r.NewStatus),
+				"notes":      r.Notes,
+			},
+		}, true
+
+	case *models.VulnerabilityAssignmentHistory:
+		if deleted {
+			return "", siem.Event{}, false
+		}
+		return SIEMCategoryVulnerability, siem.Event{
+			Time:     r.ChangedAt,
+			Type:     "vulnerability.assigned",
+			Name:     "Vulnerability assignee changed",
+			Severity: 1,
+			Fields: map[string]string{
+				"externalId":  r.VulnerabilityID.String(),
+				"suid":        r.ChangedByID.String(),
+				"oldAssignee": uuidString(r.OldAssigneeID),
+				"duid":        uuidString(r.NewAssigneeID),
+			},
+		}, true
+
+	case *models.FindingStatusHistory:
+		if deleted {
+			return "", siem.Event{}, false
+		}
+		return SIEMCategoryVulnerability, siem.Event{
+			Time:     r.ChangedAt,
+			Type:     "finding.status_changed",
+			Name:     fmt.Sprintf("Finding status changed from %s to %s", r.OldStatus, r.NewStatus),
+			Severity: 3,
+			Fields: map[string]string{
+				"externalId": r.FindingID.String(),
+				"suid":       r.ChangedByID.String(),
+				"oldStatus":  string(r.OldStatus),
+				"newStatus":  string(r.NewStatus),
+				"notes":      r.Notes,
+			},
+		}, true
+	}
+	return "", siem.Event{}, false
+}
+
+// uuidString formats an optional ID, empty when unset
+func uuidString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
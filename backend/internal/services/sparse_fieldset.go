@@ -0,0 +1,278 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// SparseResource describes the fields (?fields=) and relations (?include=) a REST resource
+// exposes. Both are named by their JSON keys and derived from the model's schema.
+type SparseResource struct {
+	name     string
+	model    interface{}
+	computed []string // Response-only fields added by the handler, with no column
+
+	once   sync.Once
+	schema *schema.Schema
+	err    error
+}
+
+// Sparse fieldset resources
+var (
+	VulnerabilityFields = &SparseResource{name: "vulnerabilities", model: &models.Vulnerability{}}
+	AssetFields         = &SparseResource{name: "assets", model: &models.AffectedSystem{}, computed: []string{"vulnerability_count", "vulnerability_stats"}}
+	FindingFields       = &SparseResource{name: "findings", model: &models.VulnerabilityFinding{}, computed: []string{"attachments_count"}}
+)
+
+var sparseSchemaCache sync.Map
+
+// parse resolves the resource's model schema once
+func (r *SparseResource) parse() (*schema.Schema, error) {
+	r.once.Do(func() {
+		r.schema, r.err = schema.Parse(r.model, &sparseSchemaCache, schema.NamingStrategy{})
+	})
+	return r.schema, r.err
+}
+
+// jsonName returns the JSON key of a schema field, or "" when it is not serialized
+func jsonName(field *schema.Field) string {
+	tag := strings.Split(field.Tag.Get("json"), ",")[0]
+	if tag == "-" {
+		return ""
+	}
+	if tag == "" {
+		return field.Name
+	}
+	return tag
+}
+
+// Fieldset is a parsed ?fields= and ?include= request for one resource. A nil Fieldset
+// keeps the endpoint's default columns and relations.
+type Fieldset struct {
+	resource *SparseResource
+
+	Fields  []string // Requested JSON keys; empty returns every field
+	Include []string // Requested relations as JSON key paths, e.g. "comments.author"
+
+	columns     []string // Columns to select, empty selects all
+	preloads    []string // Association paths to preload
+	includeSet  bool     // ?include= was given
+	projectKeys map[string]bool
+}
+
+// ParseFieldset validates comma-separated ?fields= and ?include= values; include is nil when
+// the parameter was not given. Only included relations are loaded once either parameter is
+// given. Returns nil when neither parameter is given, keeping the endpoint's defaults.
+func ParseFieldset(resource *SparseResource, fields string, include *string) (*Fieldset, error) {
+	sch, err := resource.parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s schema: %w", resource.name, err)
+	}
+
+	fieldset := &Fieldset{resource: resource, projectKeys: map[string]bool{}}
+
+	if include != nil {
+		fieldset.includeSet = true
+		for _, path := range splitList(*include) {
+			association, columns, err := resolveInclude(sch, path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid include %q for %s: %w", path, resource.name, err)
+			}
+			fieldset.Include = append(fieldset.Include, path)
+			fieldset.preloads = append(fieldset.preloads, association)
+			fieldset.columns = appendUnique(fieldset.columns, columns...)
+			fieldset.projectKeys[strings.Split(path, ".")[0]] = true
+		}
+	}
+
+	requested := splitList(fields)
+	if len(requested) > 0 {
+		columnsByKey := map[string]string{}
+		for _, field := range sch.Fields {
+			if key := jsonName(field); key != "" && field.DBName != "" {
+				columnsByKey[key] = field.DBName
+			}
+		}
+		computed := map[string]bool{}
+		for _, key := range resource.computed {
+			computed[key] = true
+		}
+
+		fieldset.columns = appendUnique(fieldset.columns, sch.PrioritizedPrimaryField.DBName)
+		fieldset.projectKeys[jsonName(sch.PrioritizedPrimaryField)] = true
+		// updated_at backs the detail endpoints' ETag, so it is loaded even when not returned
+		if field := sch.LookUpField("updated_at"); field != nil {
+			fieldset.columns = appendUnique(fieldset.columns, field.DBName)
+		}
+		for _, key := range requested {
+			column, ok := columnsByKey[key]
+			if !ok && !computed[key] {
+				return nil, fmt.Errorf("invalid field %q for %s, must be one of: %s", key, resource.name, strings.Join(sparseFieldNames(columnsByKey, resource.computed), ", "))
+			}
+			if ok {
+				fieldset.columns = appendUnique(fieldset.columns, column)
+			}
+			fieldset.Fields = append(fieldset.Fields, key)
+			fieldset.projectKeys[key] = true
+		}
+	} else {
+		// Every field is returned, so neither columns nor keys are restricted
+		fieldset.columns = nil
+		fieldset.projectKeys = nil
+	}
+
+	if !fieldset.includeSet && len(fieldset.Fields) == 0 {
+		return nil, nil
+	}
+	return fieldset, nil
+}
+
+// resolveInclude maps a JSON relation path to its association path and the parent columns
+// the preload needs
+func resolveInclude(sch *schema.Schema, path string) (string, []string, error) {
+	var associations []string
+	var columns []string
+	current := sch
+	for depth, key := range strings.Split(path, ".") {
+		var relation *schema.Relationship
+		for _, candidate := range current.Relationships.Relations {
+			if jsonName(candidate.Field) == key {
+				relation = candidate
+				break
+			}
+		}
+		if relation == nil {
+			return "", nil, fmt.Errorf("unknown relation %q", key)
+		}
+
+		if depth == 0 {
+			// The parent row must carry the key the relation is loaded by
+			for _, ref := range relation.References {
+				if ref.OwnPrimaryKey && ref.PrimaryKey != nil {
+					columns = append(columns, ref.PrimaryKey.DBName)
+				} else if !ref.OwnPrimaryKey && ref.ForeignKey != nil && ref.ForeignKey.Schema == current {
+					columns = append(columns, ref.ForeignKey.DBName)
+				}
+			}
+		}
+		associations = append(associations, relation.Name)
+		current = relation.FieldSchema
+	}
+	return strings.Join(associations, "."), columns, nil
+}
+
+// sparseFieldNames lists the selectable field names in order
+func sparseFieldNames(columnsByKey map[string]string, computed []string) []string {
+	names := make([]string, 0, len(columnsByKey)+len(computed))
+	for key := range columnsByKey {
+		names = append(names, key)
+	}
+	names = append(names, computed...)
+	sort.Strings(names)
+	return names
+}
+
+// splitList splits a comma-separated parameter, dropping blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func appendUnique(values []string, extra ...string) []string {
+	for _, value := range extra {
+		found := false
+		for _, existing := range values {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Sparse reports whether only some fields were requested
+func (f *Fieldset) Sparse() bool {
+	return f != nil && len(f.Fields) > 0
+}
+
+// Wants reports whether a field is part of the response
+func (f *Fieldset) Wants(key string) bool {
+	return !f.Sparse() || f.projectKeys[key]
+}
+
+// Apply restricts the query to the requested columns and preloads the requested relations.
+// Without a fieldset the endpoint's default relations are preloaded via defaults; a sparse
+// request only returns the relations it includes.
+func (f *Fieldset) Apply(query *gorm.DB, defaults func(*gorm.DB) *gorm.DB) *gorm.DB {
+	if f == nil {
+		return defaults(query)
+	}
+	for _, association := range f.preloads {
+		query = query.Preload(association)
+	}
+
+	if f.Sparse() {
+		table := f.resource.schema.Table
+		columns := make([]string, len(f.columns))
+		for i, column := range f.columns {
+			columns[i] = table + "." + column
+		}
+		query = query.Select(columns)
+	}
+	return query
+}
+
+// Project drops the fields that were not requested from a record or list of records. It
+// returns value unchanged when every field was requested.
+func (f *Fieldset) Project(value interface{}) (interface{}, error) {
+	if !f.Sparse() {
+		return value, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	project := func(record interface{}) interface{} {
+		object, ok := record.(map[string]interface{})
+		if !ok {
+			return record
+		}
+		for key := range object {
+			if !f.projectKeys[key] {
+				delete(object, key)
+			}
+		}
+		return object
+	}
+
+	if list, ok := decoded.([]interface{}); ok {
+		for i := range list {
+			list[i] = project(list[i])
+		}
+		return list, nil
+	}
+	return project(decoded), nil
+}
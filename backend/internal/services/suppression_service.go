@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// SuppressionService manages suppression rules and applies them to imported findings
+type SuppressionService struct {
+	db *gorm.DB
+}
+
+// NewSuppressionService creates a new suppression service
+func NewSuppressionService(db *gorm.DB) *SuppressionService {
+	return &SuppressionService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *SuppressionService) WithContext(ctx context.Context) *SuppressionService {
+	return &SuppressionService{db: s.db.WithContext(ctx)}
+}
+
+// SuppressionTarget describes the attributes of a finding that rules match against
+type SuppressionTarget struct {
+	PluginID  string
+	CVEID     string
+	AssetID   uuid.UUID
+	IPAddress string
+}
+
+// compiledSuppressionRule is a rule with its CIDR pre-parsed
+type compiledSuppressionRule struct {
+	rule    models.SuppressionRule
+	network *net.IPNet
+}
+
+// SuppressionMatcher evaluates findings against a fixed set of active rules
+type SuppressionMatcher struct {
+	rules []compiledSuppressionRule
+}
+
+// NewSuppressionMatcher compiles the enabled, unexpired rules; rules with an invalid CIDR are skipped
+func NewSuppressionMatcher(rules []models.SuppressionRule, now time.Time) *SuppressionMatcher {
+	matcher := &SuppressionMatcher{}
+	for _, rule := range rules {
+		if !rule.Enabled || (rule.ExpiresAt != nil && !rule.ExpiresAt.After(now)) {
+			continue
+		}
+		compiled := compiledSuppressionRule{rule: rule}
+		if rule.CIDR != "" {
+			_, network, err := net.ParseCIDR(rule.CIDR)
+			if err != nil {
+				continue
+			}
+			compiled.network = network
+		}
+		matcher.rules = append(matcher.rules, compiled)
+	}
+	return matcher
+}
+
+// Len returns the number of active rules
+func (m *SuppressionMatcher) Len() int {
+	return len(m.rules)
+}
+
+// Match returns the first rule whose criteria all match the target, or nil
+func (m *SuppressionMatcher) Match(target SuppressionTarget) *models.SuppressionRule {
+	for i := range m.rules {
+		if m.rules[i].matches(target) {
+			return &m.rules[i].rule
+		}
+	}
+	return nil
+}
+
+// matches reports whether every populated criterion of the rule matches the target
+func (r compiledSuppressionRule) matches(target SuppressionTarget) bool {
+	criteria := 0
+
+	if r.rule.PluginID != "" {
+		criteria++
+		if r.rule.PluginID != target.PluginID {
+			return false
+		}
+	}
+	if r.rule.CVEID != "" {
+		criteria++
+		if !strings.EqualFold(r.rule.CVEID, target.CVEID) {
+			return false
+		}
+	}
+	if r.rule.AssetID != nil {
+		criteria++
+		if *r.rule.AssetID != target.AssetID {
+			return false
+		}
+	}
+	if r.network != nil {
+		criteria++
+		ip := net.ParseIP(target.IPAddress)
+		if ip == nil || !r.network.Contains(ip) {
+			return false
+		}
+	}
+
+	// A rule without criteria would suppress everything
+	return criteria > 0
+}
+
+// SuppressionRuleRequest represents a create or update suppression rule request
+type SuppressionRuleRequest struct {
+	Name        *string    `json:"name,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Action      *string    `json:"action,omitempty"`
+	Enabled     *bool      `json:"enabled,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	PluginID    *string    `json:"plugin_id,omitempty"`
+	CVEID       *string    `json:"cve_id,omitempty"`
+	AssetID     *uuid.UUID `json:"asset_id,omitempty"`
+	CIDR        *string    `json:"cidr,omitempty"`
+}
+
+// applyTo copies the provided request fields onto a rule
+func (req SuppressionRuleRequest) applyTo(rule *models.SuppressionRule) {
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		rule.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Action != nil {
+		rule.Action = models.SuppressionAction(strings.ToUpper(strings.TrimSpace(*req.Action)))
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.ExpiresAt != nil {
+		rule.ExpiresAt = req.ExpiresAt
+	}
+	if req.PluginID != nil {
+		rule.PluginID = strings.TrimSpace(*req.PluginID)
+	}
+	if req.CVEID != nil {
+		rule.CVEID = strings.ToUpper(strings.TrimSpace(*req.CVEID))
+	}
+	if req.AssetID != nil {
+		if *req.AssetID == uuid.Nil {
+			rule.AssetID = nil
+		} else {
+			assetID := *req.AssetID
+			rule.AssetID = &assetID
+		}
+	}
+	if req.CIDR != nil {
+		rule.CIDR = strings.TrimSpace(*req.CIDR)
+	}
+}
+
+// ValidateSuppressionRule checks that a rule has a name, a valid action, and at least one valid criterion
+func ValidateSuppressionRule(rule *models.SuppressionRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if rule.Action != models.SuppressionActionFalsePositive && rule.Action != models.SuppressionActionSuppress {
+		return fmt.Errorf("invalid action, must be one of: FALSE_POSITIVE, SUPPRESSED")
+	}
+	if rule.PluginID == "" && rule.CVEID == "" && rule.AssetID == nil && rule.CIDR == "" {
+		return fmt.Errorf("at least one match criterion is required (plugin_id, cve_id, asset_id, cidr)")
+	}
+	if rule.CIDR != "" {
+		if _, _, err := net.ParseCIDR(rule.CIDR); err != nil {
+			return fmt.Errorf("invalid cidr: %s", rule.CIDR)
+		}
+	}
+	return nil
+}
+
+// ListRules returns all suppression rules, newest first
+func (s *SuppressionService) ListRules(enabledOnly bool) ([]models.SuppressionRule, error) {
+	query := s.db.Preload("CreatedBy").Preload("Asset").Order("created_at DESC")
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+
+	var rules []models.SuppressionRule
+	if err := query.Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to list suppression rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetRule returns a single suppression rule
+func (s *SuppressionService) GetRule(id uuid.UUID) (*models.SuppressionRule, error) {
+	var rule models.SuppressionRule
+	if err := s.db.Preload("CreatedBy").Preload("Asset").First(&rule, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("suppression rule not found")
+		}
+		return nil, fmt.Errorf("failed to get suppression rule: %w", err)
+	}
+	return &rule, nil
+}
+
+// CreateRule creates a new suppression rule
+func (s *SuppressionService) CreateRule(req SuppressionRuleRequest, createdByID uuid.UUID) (*models.SuppressionRule, error) {
+	rule := &models.SuppressionRule{
+		Action:      models.SuppressionActionSuppress,
+		Enabled:     true,
+		CreatedByID: createdByID,
+	}
+	req.applyTo(rule)
+
+	if err := ValidateSuppressionRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := createWithZeroValues(s.db, rule, "enabled"); err != nil {
+		return nil, fmt.Errorf("failed to create suppression rule: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("rule_id", rule.ID.String()).
+		Str("created_by", createdByID.String()).
+		Str("action", string(rule.Action)).
+		Msg("Suppression rule created")
+
+	return s.GetRule(rule.ID)
+}
+
+// UpdateRule updates an existing suppression rule
+func (s *SuppressionService) UpdateRule(id uuid.UUID, req SuppressionRuleRequest, updatedByID uuid.UUID) (*models.SuppressionRule, error) {
+	rule, err := s.GetRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.applyTo(rule)
+	if err := ValidateSuppressionRule(rule); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(rule).Select(
+		"name", "description", "action", "enabled", "expires_at",
+		"plugin_id", "cve_id", "asset_id", "cidr",
+	).Updates(rule).Error; err != nil {
+		return nil, fmt.Errorf("failed to update suppression rule: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("rule_id", id.String()).
+		Str("updated_by", updatedByID.String()).
+		Msg("Suppression rule updated")
+
+	return s.GetRule(id)
+}
+
+// DeleteRule soft deletes a suppression rule; already-suppressed findings are not changed
+func (s *SuppressionService) DeleteRule(id, deletedByID uuid.UUID) error {
+	result := s.db.Delete(&models.SuppressionRule{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete suppression rule: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("suppression rule not found")
+	}
+
+	utils.Logger.Info().
+		Str("rule_id", id.String()).
+		Str("deleted_by", deletedByID.String()).
+		Msg("Suppression rule deleted")
+
+	return nil
+}
+
+// ListRuleHits returns the audit log of findings suppressed by a rule, newest first
+func (s *SuppressionService) ListRuleHits(ruleID uuid.UUID, page, limit int) ([]models.SuppressionLog, int64, error) {
+	if _, err := s.GetRule(ruleID); err != nil {
+		return nil, 0, err
+	}
+
+	query := s.db.Model(&models.SuppressionLog{}).Where("rule_id = ?", ruleID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count suppression hits: %w", err)
+	}
+
+	var logs []models.SuppressionLog
+	if err := query.
+		Preload("Finding").
+		Preload("Finding.AffectedSystem").
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&logs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list suppression hits: %w", err)
+	}
+
+	return logs, total, nil
+}
+
+// LoadMatcher loads the active rules using the given transaction
+func (s *SuppressionService) LoadMatcher(tx *gorm.DB) (*SuppressionMatcher, error) {
+	now := time.Now()
+
+	var rules []models.SuppressionRule
+	if err := tx.
+		Where("enabled = ?", true).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Order("created_at ASC").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load suppression rules: %w", err)
+	}
+
+	return NewSuppressionMatcher(rules, now), nil
+}
+
+// ApplyWithTx suppresses an open finding if a rule matches, recording the status change,
+// the audit log entry, and the rule hit. Returns the matched rule, or nil if none applied.
+func (s *SuppressionService) ApplyWithTx(
+	tx *gorm.DB,
+	matcher *SuppressionMatcher,
+	finding *models.VulnerabilityFinding,
+	target SuppressionTarget,
+	source string,
+	actorID uuid.UUID,
+) (*models.SuppressionRule, error) {
+	if matcher == nil || matcher.Len() == 0 || finding.Status != models.FindingStatusOpen {
+		return nil, nil
+	}
+
+	rule := matcher.Match(target)
+	if rule == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	newStatus := models.FindingStatus(rule.Action)
+
+	if err := tx.Model(finding).Update("status", newStatus).Error; err != nil {
+		return nil, fmt.Errorf("failed to suppress finding: %w", err)
+	}
+
+	if err := tx.Create(&models.FindingStatusHistory{
+		FindingID:   finding.ID,
+		OldStatus:   models.FindingStatusOpen,
+		NewStatus:   newStatus,
+		Notes:       fmt.Sprintf("Suppressed by rule %q (%s)", rule.Name, rule.ID),
+		ChangedByID: actorID,
+		ChangedAt:   now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to record status history: %w", err)
+	}
+
+	if err := tx.Create(&models.SuppressionLog{
+		RuleID:    rule.ID,
+		FindingID: finding.ID,
+		Action:    rule.Action,
+		OldStatus: models.FindingStatusOpen,
+		Source:    source,
+		CreatedAt: now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to record suppression: %w", err)
+	}
+
+	if err := tx.Model(&models.SuppressionRule{}).
+		Where("id = ?", rule.ID).
+		Updates(map[string]interface{}{
+			"hit_count":   gorm.Expr("hit_count + 1"),
+			"last_hit_at": now,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update rule hit count: %w", err)
+	}
+
+	finding.Status = newStatus
+
+	utils.Logger.Info().
+		Str("rule_id", rule.ID.String()).
+		Str("finding_id", finding.ID.String()).
+		Str("action", string(rule.Action)).
+		Str("source", source).
+		Msg("Finding suppressed by rule")
+
+	return rule, nil
+}
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/cyops/cyops-backend/internal/models"
 	"gorm.io/gorm"
@@ -40,8 +42,172 @@ func (s *SystemSettingsService) GetAllSettings() ([]models.SystemSetting, error)
 	return settings, nil
 }
 
-// UpdateSetting updates or creates a system setting
+// ErrAdminIPLockout is returned when admin IP access lists would block the caller's own address
+var ErrAdminIPLockout = errors.New("invalid admin IP access lists: they would block your own address")
+
+// UpdateSetting updates or creates a system setting. The admin IP access lists are refused:
+// they are changed through UpdateAdminIPAccess, which keeps administrators from locking
+// themselves out.
 func (s *SystemSettingsService) UpdateSetting(key, value, description, updatedBy string) (*models.SystemSetting, error) {
+	if key == string(models.SystemSettingAdminIPAccess) {
+		return nil, fmt.Errorf("invalid setting: %s is managed through PUT /api/v1/admin/ip-access", key)
+	}
+	return s.saveSetting(key, value, description, updatedBy)
+}
+
+// UpdateAdminIPAccess replaces the admin allowlist and denylist, refusing lists that would
+// block clientIP, the address of the administrator making the change
+func (s *SystemSettingsService) UpdateAdminIPAccess(list *IPAccessList, clientIP, updatedBy string) (*models.SystemSetting, error) {
+	if !list.Allows(clientIP) {
+		return nil, ErrAdminIPLockout
+	}
+	value, _ := json.Marshal(list)
+	return s.saveSetting(string(models.SystemSettingAdminIPAccess), string(value), "", updatedBy)
+}
+
+// saveSetting validates and stores a system setting, creating it when missing
+func (s *SystemSettingsService) saveSetting(key, value, description, updatedBy string) (*models.SystemSetting, error) {
+	// Storage settings are validated (and their credentials checked) before they are saved
+	var attachmentStorage *AttachmentStorage
+	if key == string(models.SystemSettingAttachmentStorage) {
+		var err error
+		if attachmentStorage, err = BuildAttachmentStorage(value); err != nil {
+			return nil, err
+		}
+		if description == "" {
+			description = "Attachment storage backend (local, s3 or azure) and its options"
+		}
+	}
+	if key == string(models.SystemSettingAttachmentPolicy) {
+		policy, err := ParseAttachmentPolicy(value)
+		if err != nil {
+			return nil, err
+		}
+		// Store the normalized policy so reads show the effective limits of every category
+		normalized, _ := json.Marshal(policy)
+		value = string(normalized)
+		if description == "" {
+			description = "Allowed MIME types and maximum upload sizes per attachment category"
+		}
+	}
+	if key == string(models.SystemSettingReportSummarizer) {
+		_, settings, err := BuildReportSummarizer(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized, _ := json.Marshal(settings)
+		value = string(normalized)
+		if description == "" {
+			description = "Language model (openai, azure or ollama) that writes report summaries"
+		}
+	}
+	if key == string(models.SystemSettingDataRetention) {
+		settings, err := ParseDataRetentionSettings(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized, _ := json.Marshal(settings)
+		value = string(normalized)
+		if description == "" {
+			description = "Retention periods in days of resolved vulnerabilities, auth events, sessions, audit logs, report exports and deleted items (0 keeps forever)"
+		}
+	}
+	if key == string(models.SystemSettingFindingAutoClose) {
+		settings, err := ParseFindingAutoCloseSettings(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized, _ := json.Marshal(settings)
+		value = string(normalized)
+		if description == "" {
+			description = "Close open findings after a number of consecutive scans of their asset no longer report them"
+		}
+	}
+	if key == string(models.SystemSettingImportValidation) {
+		settings, err := ParseImportValidationSettings(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized, _ := json.Marshal(settings)
+		value = string(normalized)
+		if description == "" {
+			description = "Quarantine report hosts without an identity, outside the scope CIDRs or with test data instead of importing them"
+		}
+	}
+	if key == string(models.SystemSettingCriticalCloseApproval) {
+		enabled, err := ParseCriticalCloseApproval(value)
+		if err != nil {
+			return nil, err
+		}
+		value = fmt.Sprintf("%t", enabled)
+		if description == "" {
+			description = "Require a second user with the finding verify permission to approve closing CRITICAL vulnerabilities"
+		}
+	}
+	if key == string(models.SystemSettingEmailIngestion) {
+		var err error
+		if value, err = PrepareEmailIngestionSetting(s.db, value); err != nil {
+			return nil, err
+		}
+		if description == "" {
+			description = "IMAP mailbox and inbound webhook that turn emailed vulnerability reports into draft vulnerabilities"
+		}
+	}
+	if key == string(models.SystemSettingEmailDelivery) {
+		var err error
+		if value, err = PrepareEmailDeliverySetting(s.db, value); err != nil {
+			return nil, err
+		}
+		if description == "" {
+			description = "Provider outgoing email is sent with (smtp, sendgrid, ses or mailgun) and its credentials"
+		}
+	}
+	if key == string(models.SystemSettingAPIRateLimits) {
+		settings, err := ParseAPIRateLimitSettings(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized, _ := json.Marshal(settings)
+		value = string(normalized)
+		if description == "" {
+			description = "Per-minute request quotas of each user and API key, by API route group"
+		}
+	}
+	if key == string(models.SystemSettingAdminIPAccess) {
+		list, err := ParseIPAccessList(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized, _ := json.Marshal(list)
+		value = string(normalized)
+		if description == "" {
+			description = "Client networks allowed and denied access to the admin API"
+		}
+	}
+	if key == string(models.SystemSettingOnboardingPolicies) {
+		settings, err := ParseOnboardingSettings(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized, _ := json.Marshal(settings)
+		value = string(normalized)
+		if description == "" {
+			description = "Policies users accept during onboarding and their current versions"
+		}
+	}
+	var siemSettings *SIEMForwarderSettings
+	if key == string(models.SystemSettingSIEMForwarder) {
+		var err error
+		if siemSettings, err = ParseSIEMForwarderSettings(value); err != nil {
+			return nil, err
+		}
+		normalized, _ := json.Marshal(siemSettings)
+		value = string(normalized)
+		if description == "" {
+			description = "Forwarding of audit and vulnerability lifecycle events to a SIEM (CEF or RFC 5424 syslog over TCP/TLS)"
+		}
+	}
+
 	var setting models.SystemSetting
 
 	// Try to find existing setting
@@ -58,6 +224,20 @@ func (s *SystemSettingsService) UpdateSetting(key, value, description, updatedBy
 		if err := s.db.Create(&setting).Error; err != nil {
 			return nil, err
 		}
+		if attachmentStorage != nil {
+			SetActiveAttachmentStorage(attachmentStorage)
+		}
+		if siemSettings != nil {
+			if err := activateSIEMForwarder(siemSettings, value); err != nil {
+				return nil, err
+			}
+		}
+		if key == string(models.SystemSettingAPIRateLimits) {
+			invalidateAPIRateLimitSettings()
+		}
+		if key == string(models.SystemSettingAdminIPAccess) {
+			invalidateAdminIPAccess()
+		}
 		return &setting, nil
 	}
 
@@ -75,6 +255,20 @@ func (s *SystemSettingsService) UpdateSetting(key, value, description, updatedBy
 	if err := s.db.Save(&setting).Error; err != nil {
 		return nil, err
 	}
+	if attachmentStorage != nil {
+		SetActiveAttachmentStorage(attachmentStorage)
+	}
+	if siemSettings != nil {
+		if err := activateSIEMForwarder(siemSettings, value); err != nil {
+			return nil, err
+		}
+	}
+	if key == string(models.SystemSettingAPIRateLimits) {
+		invalidateAPIRateLimitSettings()
+	}
+	if key == string(models.SystemSettingAdminIPAccess) {
+		invalidateAdminIPAccess()
+	}
 
 	return &setting, nil
 }
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TeamService manages teams, their membership and team-based notification routing
+type TeamService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+// NewTeamService creates a new team service
+func NewTeamService(db *gorm.DB) *TeamService {
+	return &TeamService{
+		db:                  db,
+		notificationService: NewNotificationService(db),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *TeamService) WithContext(ctx context.Context) *TeamService {
+	return &TeamService{
+		db:                  s.db.WithContext(ctx),
+		notificationService: s.notificationService,
+	}
+}
+
+// CreateTeamRequest is the payload for creating a team
+type CreateTeamRequest struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	LeadID      *uuid.UUID  `json:"lead_id"`
+	MemberIDs   []uuid.UUID `json:"member_ids"`
+}
+
+// UpdateTeamRequest is the payload for updating a team
+type UpdateTeamRequest struct {
+	Name        *string    `json:"name"`
+	Description *string    `json:"description"`
+	LeadID      *uuid.UUID `json:"lead_id"`
+	ClearLead   bool       `json:"clear_lead"`
+}
+
+// List returns all teams, optionally filtered by a name search or member
+func (s *TeamService) List(search string, memberID *uuid.UUID) ([]models.Team, error) {
+	query := s.db.Model(&models.Team{}).Preload("Lead")
+	if search = strings.TrimSpace(search); search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+	if memberID != nil {
+		query = query.Where("id IN (?)", s.db.Model(&models.TeamMember{}).Select("team_id").Where("user_id = ?", *memberID))
+	}
+
+	var teams []models.Team
+	if err := query.Order("name ASC").Find(&teams).Error; err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	return teams, nil
+}
+
+// GetByID retrieves a team with its lead and members
+func (s *TeamService) GetByID(id uuid.UUID) (*models.Team, error) {
+	var team models.Team
+	if err := s.db.Preload("Lead").Preload("Members.User").Where("id = ?", id).First(&team).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("team not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	return &team, nil
+}
+
+// Exists reports whether a team is visible to the caller; used to validate owner_team_id
+func (s *TeamService) Exists(id uuid.UUID) error {
+	var count int64
+	if err := s.db.Model(&models.Team{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("team not found")
+	}
+	return nil
+}
+
+// Create creates a team. The lead is always added as a member.
+func (s *TeamService) Create(req CreateTeamRequest) (*models.Team, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if err := s.checkNameAvailable(name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	memberIDs := req.MemberIDs
+	if req.LeadID != nil {
+		memberIDs = append(memberIDs, *req.LeadID)
+	}
+	if err := s.checkUsersExist(memberIDs); err != nil {
+		return nil, err
+	}
+
+	team := &models.Team{
+		Name:        name,
+		Description: req.Description,
+		LeadID:      req.LeadID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(team).Error; err != nil {
+			return fmt.Errorf("failed to create team: %w", err)
+		}
+		return s.addMembers(tx, team.ID, memberIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("team_id", team.ID.String()).
+		Str("name", name).
+		Int("members", len(memberIDs)).
+		Msg("Team created")
+
+	return s.GetByID(team.ID)
+}
+
+// Update updates a team's name, description or lead
+func (s *TeamService) Update(id uuid.UUID, req UpdateTeamRequest) (*models.Team, error) {
+	team, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("name is required")
+		}
+		if err := s.checkNameAvailable(name, id); err != nil {
+			return nil, err
+		}
+		team.Name = name
+	}
+	if req.Description != nil {
+		team.Description = *req.Description
+	}
+	if req.ClearLead {
+		team.LeadID = nil
+	} else if req.LeadID != nil {
+		if err := s.checkUsersExist([]uuid.UUID{*req.LeadID}); err != nil {
+			return nil, err
+		}
+		team.LeadID = req.LeadID
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"name":        team.Name,
+			"description": team.Description,
+			"lead_id":     team.LeadID,
+		}
+		if err := tx.Model(&models.Team{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update team: %w", err)
+		}
+		if team.LeadID != nil {
+			return s.addMembers(tx, id, []uuid.UUID{*team.LeadID})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(id)
+}
+
+// Delete soft-deletes a team and releases its ownership of assets and vulnerabilities
+func (s *TeamService) Delete(id uuid.UUID) error {
+	team, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AffectedSystem{}).Where("owner_team_id = ?", id).Update("owner_team_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to release team assets: %w", err)
+		}
+		if err := tx.Model(&models.Vulnerability{}).Where("owner_team_id = ?", id).Update("owner_team_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to release team vulnerabilities: %w", err)
+		}
+		if err := tx.Where("team_id = ?", id).Delete(&models.TeamMember{}).Error; err != nil {
+			return fmt.Errorf("failed to remove team members: %w", err)
+		}
+		if err := tx.Delete(team).Error; err != nil {
+			return fmt.Errorf("failed to delete team: %w", err)
+		}
+		return nil
+	})
+}
+
+// AddMember adds a user to a team
+func (s *TeamService) AddMember(teamID, userID uuid.UUID) (*models.Team, error) {
+	if _, err := s.GetByID(teamID); err != nil {
+		return nil, err
+	}
+	if err := s.checkUsersExist([]uuid.UUID{userID}); err != nil {
+		return nil, err
+	}
+	if err := s.addMembers(s.db, teamID, []uuid.UUID{userID}); err != nil {
+		return nil, err
+	}
+	return s.GetByID(teamID)
+}
+
+// RemoveMember removes a user from a team. The team lead cannot be removed.
+func (s *TeamService) RemoveMember(teamID, userID uuid.UUID) (*models.Team, error) {
+	team, err := s.GetByID(teamID)
+	if err != nil {
+		return nil, err
+	}
+	if team.LeadID != nil && *team.LeadID == userID {
+		return nil, fmt.Errorf("the team lead cannot be removed; assign a new lead first")
+	}
+
+	result := s.db.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&models.TeamMember{})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to remove team member: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("user is not a member of this team")
+	}
+	return s.GetByID(teamID)
+}
+
+// MemberIDs returns the IDs of the users in a team
+func (s *TeamService) MemberIDs(tx *gorm.DB, teamID uuid.UUID) ([]uuid.UUID, error) {
+	if tx == nil {
+		tx = s.db
+	}
+	var ids []uuid.UUID
+	if err := tx.Model(&models.TeamMember{}).Where("team_id = ?", teamID).Pluck("user_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to list team members: %w", err)
+	}
+	return ids, nil
+}
+
+// NotifyMembers routes a notification to every member of a team except the actor
+func (s *TeamService) NotifyMembers(tx *gorm.DB, teamID uuid.UUID, actorID *uuid.UUID, template models.Notification) error {
+	memberIDs, err := s.MemberIDs(tx, teamID)
+	if err != nil {
+		return err
+	}
+
+	notifications := make([]models.Notification, 0, len(memberIDs))
+	for _, memberID := range memberIDs {
+		if actorID != nil && memberID == *actorID {
+			continue
+		}
+		n := template
+		n.UserID = memberID
+		n.ActorID = actorID
+		notifications = append(notifications, n)
+	}
+	return s.notificationService.CreateNotifications(tx, notifications)
+}
+
+// checkNameAvailable rejects a team name already used by another team in the organization
+func (s *TeamService) checkNameAvailable(name string, exceptID uuid.UUID) error {
+	var count int64
+	if err := s.db.Model(&models.Team{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("team '%s' already exists", name)
+	}
+	return nil
+}
+
+// checkUsersExist verifies that every user ID refers to a user visible to the caller
+func (s *TeamService) checkUsersExist(userIDs []uuid.UUID) error {
+	unique := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		unique[id] = true
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(unique))
+	for id := range unique {
+		ids = append(ids, id)
+	}
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+	if int(count) != len(ids) {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// addMembers inserts memberships, ignoring users that are already members
+func (s *TeamService) addMembers(tx *gorm.DB, teamID uuid.UUID, userIDs []uuid.UUID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	members := make([]models.TeamMember, 0, len(userIDs))
+	for _, userID := range userIDs {
+		members = append(members, models.TeamMember{TeamID: teamID, UserID: userID})
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error; err != nil {
+		return fmt.Errorf("failed to add team members: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/pkg/cwe"
+)
+
+// Tenable.io vulnerability export settings
+const (
+	tenableExportAssetsPerChunk = 500
+	tenableExportPollInterval   = 10 * time.Second
+	tenableExportMaxPolls       = 360 // 1 hour
+)
+
+// TenableVulnExportStatus represents the status of a Tenable.io vulnerability export
+type TenableVulnExportStatus struct {
+	Status          string `json:"status"` // QUEUED, PROCESSING, FINISHED, CANCELLED or ERROR
+	ChunksAvailable []int  `json:"chunks_available"`
+}
+
+// TenableVulnRecord is one vulnerability on one asset in a Tenable.io vulnerability export chunk
+type TenableVulnRecord struct {
+	Asset struct {
+		UUID            string   `json:"uuid"`
+		Hostname        string   `json:"hostname"`
+		FQDN            string   `json:"fqdn"`
+		IPv4            string   `json:"ipv4"`
+		OperatingSystem []string `json:"operating_system"`
+	} `json:"asset"`
+	Plugin struct {
+		ID               int           `json:"id"`
+		Name             string        `json:"name"`
+		Family           string        `json:"family"`
+		Description      string        `json:"description"`
+		Synopsis         string        `json:"synopsis"`
+		Solution         string        `json:"solution"`
+		RiskFactor       string        `json:"risk_factor"`
+		CVE              []string      `json:"cve"`
+		Xrefs            []TenableXref `json:"xrefs"`
+		ExploitAvailable bool          `json:"exploit_available"`
+		HasPatch         bool          `json:"has_patch"`
+		SeeAlso          []string      `json:"see_also"`
+		CVSSBaseScore    float64       `json:"cvss_base_score"`
+		CVSS3BaseScore   float64       `json:"cvss3_base_score"`
+		CVSS3Vector      struct {
+			Raw string `json:"raw"`
+		} `json:"cvss3_vector"`
+	} `json:"plugin"`
+	Port struct {
+		Port     int    `json:"port"`
+		Protocol string `json:"protocol"`
+		Service  string `json:"service"`
+	} `json:"port"`
+	Output     string `json:"output"` // Plugin output for this asset
+	SeverityID int    `json:"severity_id"`
+	State      string `json:"state"`
+	FirstFound string `json:"first_found"`
+	LastFound  string `json:"last_found"`
+}
+
+// ExportVulnerabilities imports the open vulnerabilities Tenable.io has seen since a point in time
+// (all of them when since is zero) through its vulnerability export API. Tenable.io splits the
+// export into chunks of assets, which are downloaded as they become available.
+func (s *NessusAPIService) ExportVulnerabilities(configID uuid.UUID, since time.Time) ([]ParsedVulnerability, error) {
+	config, err := s.configService.GetConfig(configID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+	if NessusMode(config) != NessusModeTenableIO {
+		return nil, fmt.Errorf("invalid integration: vulnerability exports require Tenable.io mode")
+	}
+
+	client, err := s.createHTTPClient(config, 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 1: Request export
+	filters := map[string]interface{}{
+		"state":    []string{"OPEN", "REOPENED"},
+		"severity": []string{"low", "medium", "high", "critical"},
+	}
+	if !since.IsZero() {
+		filters["since"] = since.Unix()
+	}
+	exportBody, _ := json.Marshal(map[string]interface{}{
+		"num_assets": tenableExportAssetsPerChunk,
+		"filters":    filters,
+	})
+
+	req, err := http.NewRequestWithContext(s.ctx, "POST", config.BaseURL+"/vulns/export", bytes.NewReader(exportBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.do(client, config, req)
+	if err != nil {
+		return nil, fmt.Errorf("export request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("export API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var exportResp struct {
+		ExportUUID string `json:"export_uuid"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&exportResp); err != nil || exportResp.ExportUUID == "" {
+		return nil, fmt.Errorf("failed to decode export response: %v", err)
+	}
+
+	// Step 2: Poll the export, downloading chunks as they become available
+	var records []TenableVulnRecord
+	downloaded := make(map[int]bool)
+	for i := 0; i < tenableExportMaxPolls; i++ {
+		var status TenableVulnExportStatus
+		if err := s.getJSON(config, "/vulns/export/"+exportResp.ExportUUID+"/status", &status); err != nil {
+			return nil, fmt.Errorf("failed to get export status: %w", err)
+		}
+
+		for _, chunkID := range status.ChunksAvailable {
+			if downloaded[chunkID] {
+				continue
+			}
+			var chunk []TenableVulnRecord
+			path := fmt.Sprintf("/vulns/export/%s/chunks/%d", exportResp.ExportUUID, chunkID)
+			if err := s.getJSON(config, path, &chunk); err != nil {
+				return nil, fmt.Errorf("failed to download export chunk %d: %w", chunkID, err)
+			}
+			records = append(records, chunk...)
+			downloaded[chunkID] = true
+		}
+
+		switch status.Status {
+		case "FINISHED":
+			return ParsedVulnerabilitiesFromTenable(records), nil
+		case "CANCELLED", "ERROR":
+			return nil, fmt.Errorf("vulnerability export %s ended with status %s", exportResp.ExportUUID, status.Status)
+		}
+		if err := s.wait(tenableExportPollInterval); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("vulnerability export %s did not finish in time", exportResp.ExportUUID)
+}
+
+// TenableXref is a plugin cross reference, such as type CWE and ID 79
+type TenableXref struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// tenablePluginCWEs collects the weakness types of a plugin from its CWE cross references
+func tenablePluginCWEs(xrefs []TenableXref) []string {
+	var raw []string
+	for _, xref := range xrefs {
+		if strings.EqualFold(xref.Type, "CWE") {
+			raw = append(raw, xref.ID)
+		}
+	}
+	return cwe.NormalizeIDs(raw)
+}
+
+// tenablePluginKBs collects the Microsoft KB articles of a plugin from its MSKB cross references
+func tenablePluginKBs(xrefs []TenableXref) []string {
+	var kbs []string
+	for _, xref := range xrefs {
+		if strings.EqualFold(xref.Type, "MSKB") {
+			kbs = append(kbs, xref.ID)
+		}
+	}
+	return kbs
+}
+
+// ParsedVulnerabilitiesFromTenable converts Tenable.io vulnerability export records into the
+// vulnerabilities the Nessus import pipeline consumes, grouping the affected hosts of each plugin
+// the way a .nessus file is parsed. Informational findings are skipped.
+func ParsedVulnerabilitiesFromTenable(records []TenableVulnRecord) []ParsedVulnerability {
+	parser := NewNessusParserService()
+	byPlugin := make(map[int]*ParsedVulnerability)
+
+	for _, record := range records {
+		if record.SeverityID <= 0 {
+			continue
+		}
+
+		lastFound, err := time.Parse(time.RFC3339, record.LastFound)
+		if err != nil {
+			lastFound = time.Now()
+		}
+
+		vuln, ok := byPlugin[record.Plugin.ID]
+		if !ok {
+			plugin := record.Plugin
+			description := plugin.Description
+			if description == "" {
+				description = plugin.Synopsis
+			}
+			var cvssScore *float64
+			if plugin.CVSS3BaseScore > 0 {
+				cvssScore = &plugin.CVSS3BaseScore
+			} else if plugin.CVSSBaseScore > 0 {
+				cvssScore = &plugin.CVSSBaseScore
+			}
+			cveID := ""
+			if len(plugin.CVE) > 0 {
+				cveID = parser.extractCVE(plugin.CVE[0])
+			}
+
+			vuln = &ParsedVulnerability{
+				Title:                     plugin.Name,
+				Description:               description,
+				Severity:                  parser.mapSeverity(record.SeverityID, plugin.RiskFactor),
+				ScannerSeverity:           parser.severityLevel(record.SeverityID, plugin.RiskFactor),
+				CVSSScore:                 cvssScore,
+				CVSSVector:                plugin.CVSS3Vector.Raw,
+				CVEID:                     cveID,
+				CWEIDs:                    tenablePluginCWEs(plugin.Xrefs),
+				ImpactAssessment:          plugin.Synopsis,
+				MitigationRecommendations: plugin.Solution,
+				PluginID:                  strconv.Itoa(plugin.ID),
+				PluginFamily:              plugin.Family,
+				RiskFactor:                plugin.RiskFactor,
+				ExploitAvailable:          plugin.ExploitAvailable,
+				PatchAvailable:            plugin.HasPatch,
+				AdvisoryURL:               advisoryURL(tenablePluginKBs(plugin.Xrefs), plugin.SeeAlso),
+				FixedVersion:              SolutionFixedVersion(plugin.Solution),
+				ScanDate:                  lastFound,
+			}
+			byPlugin[record.Plugin.ID] = vuln
+		}
+		if lastFound.After(vuln.ScanDate) {
+			vuln.ScanDate = lastFound
+		}
+
+		hostname := record.Asset.FQDN
+		if hostname == "" {
+			hostname = record.Asset.Hostname
+		}
+		if hostname == "" {
+			hostname = record.Asset.IPv4
+		}
+		os := ""
+		if len(record.Asset.OperatingSystem) > 0 {
+			os = record.Asset.OperatingSystem[0]
+		}
+		detected, fixed := PluginOutputVersions(record.Output)
+		vuln.AffectedHosts = append(vuln.AffectedHosts, ParsedHost{
+			Hostname:        hostname,
+			IPAddress:       record.Asset.IPv4,
+			Port:            strconv.Itoa(record.Port.Port),
+			Protocol:        strings.ToLower(record.Port.Protocol),
+			ServiceName:     record.Port.Service,
+			OS:              os,
+			ScanTimestamp:   lastFound,
+			PluginOutput:    strings.TrimSpace(record.Output),
+			DetectedVersion: detected,
+			FixedVersion:    fixed,
+		})
+		if vuln.FixedVersion == "" {
+			vuln.FixedVersion = fixed
+		}
+	}
+
+	pluginIDs := make([]int, 0, len(byPlugin))
+	for id := range byPlugin {
+		pluginIDs = append(pluginIDs, id)
+	}
+	sort.Ints(pluginIDs)
+
+	vulnerabilities := make([]ParsedVulnerability, 0, len(pluginIDs))
+	for _, id := range pluginIDs {
+		vulnerabilities = append(vulnerabilities, *byPlugin[id])
+	}
+	return vulnerabilities
+}
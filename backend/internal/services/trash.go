@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// Recycle bin item types
+const (
+	TrashTypeVulnerability = "vulnerability"
+	TrashTypeAsset         = "asset"
+)
+
+// TrashUser identifies who deleted a recycle bin item
+type TrashUser struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+	Name  string    `json:"name,omitempty"`
+}
+
+// TrashItem is a soft-deleted vulnerability or asset that can still be restored
+type TrashItem struct {
+	Type        string     `json:"type"`
+	ID          uuid.UUID  `json:"id"`
+	OrgID       *uuid.UUID `json:"org_id,omitempty"`
+	Name        string     `json:"name"` // Vulnerability title, or asset hostname, IP address or asset ID
+	DeletedAt   time.Time  `json:"deleted_at"`
+	DeletedByID *uuid.UUID `json:"deleted_by_id,omitempty"` // Nil for items deleted before deleters were recorded
+	DeletedBy   *TrashUser `json:"deleted_by,omitempty"`
+	PurgeAt     *time.Time `json:"purge_at,omitempty"` // When data retention deletes it permanently; nil when kept until cleaned up
+}
+
+// trashQueries select the recycle bin items of each type
+var trashQueries = map[string]string{
+	TrashTypeVulnerability: `SELECT 'vulnerability' AS type, id, org_id, title AS name, deleted_at, deleted_by_id
+		FROM vulnerabilities WHERE deleted_at IS NOT NULL`,
+	TrashTypeAsset: `SELECT 'asset' AS type, id, org_id, COALESCE(NULLIF(hostname, ''), NULLIF(ip_address, ''), asset_id, '') AS name, deleted_at, deleted_by_id
+		FROM affected_systems WHERE deleted_at IS NOT NULL`,
+}
+
+// ListTrash returns a page of soft-deleted vulnerabilities and assets, most recently deleted
+// first, optionally restricted to one type
+func (s *CleanupService) ListTrash(itemType string, page, perPage int) ([]TrashItem, int64, error) {
+	var query string
+	switch itemType {
+	case "":
+		query = trashQueries[TrashTypeVulnerability] + " UNION ALL " + trashQueries[TrashTypeAsset]
+	case TrashTypeVulnerability, TrashTypeAsset:
+		query = trashQueries[itemType]
+	default:
+		return nil, 0, fmt.Errorf("invalid trash item type: %s", itemType)
+	}
+
+	var total int64
+	if err := s.db.Raw("SELECT COUNT(*) FROM (" + query + ") AS trash").Scan(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count deleted items: %w", err)
+	}
+
+	items := []TrashItem{}
+	if err := s.db.Raw("SELECT * FROM ("+query+") AS trash ORDER BY deleted_at DESC, id LIMIT ? OFFSET ?",
+		perPage, (page-1)*perPage).Scan(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list deleted items: %w", err)
+	}
+
+	// Deleters, including users deleted since
+	var userIDs []uuid.UUID
+	for _, item := range items {
+		if item.DeletedByID != nil {
+			userIDs = append(userIDs, *item.DeletedByID)
+		}
+	}
+	users := map[uuid.UUID]*TrashUser{}
+	if len(userIDs) > 0 {
+		var records []models.User
+		if err := s.db.Unscoped().Select("id", "email", "name").Where("id IN ?", userIDs).Find(&records).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to load deleting users: %w", err)
+		}
+		for _, user := range records {
+			users[user.ID] = &TrashUser{ID: user.ID, Email: user.Email, Name: user.Name}
+		}
+	}
+
+	settings, err := s.RetentionSettings()
+	if err != nil {
+		utils.Logger.Warn().Err(err).Msg("Failed to load data retention settings for the recycle bin")
+		settings = &DataRetentionSettings{}
+	}
+	for i := range items {
+		if items[i].DeletedByID != nil {
+			items[i].DeletedBy = users[*items[i].DeletedByID]
+		}
+		if settings.DeletedItemDays > 0 {
+			purgeAt := items[i].DeletedAt.AddDate(0, 0, settings.DeletedItemDays)
+			items[i].PurgeAt = &purgeAt
+		}
+	}
+	return items, total, nil
+}
+
+// RestoreTrashItem undoes the deletion of a vulnerability or asset that has not been purged yet
+func (s *CleanupService) RestoreTrashItem(itemType string, id uuid.UUID, restoredByID uuid.UUID) error {
+	var record interface{}
+	switch itemType {
+	case TrashTypeVulnerability:
+		record = &models.Vulnerability{}
+	case TrashTypeAsset:
+		record = &models.AffectedSystem{}
+	default:
+		return fmt.Errorf("invalid trash item type: %s", itemType)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(record).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("deleted %s not found", itemType)
+			}
+			return err
+		}
+
+		// Update through the model so write callbacks (search indexing) pick the item up again
+		if err := tx.Unscoped().Model(record).UpdateColumns(map[string]interface{}{
+			"deleted_at":    nil,
+			"deleted_by_id": nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to restore %s: %w", itemType, err)
+		}
+
+		if itemType == TrashTypeAsset {
+			return recordAssetHistory(tx, restoredByID, models.AssetHistory{
+				AssetID: id,
+				Action:  models.AssetHistoryRestored,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	utils.Logger.Info().
+		Str("type", itemType).
+		Str("id", id.String()).
+		Str("restored_by", restoredByID.String()).
+		Msg("Deleted item restored")
+	return nil
+}
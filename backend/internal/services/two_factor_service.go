@@ -1,8 +1,10 @@
 package services
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
@@ -10,6 +12,7 @@ import (
 	"github.com/cyops/cyops-backend/pkg/database"
 	"github.com/cyops/cyops-backend/pkg/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TwoFactorService handles 2FA operations
@@ -60,21 +63,15 @@ func (s *TwoFactorService) EnableTwoFactor(userID uuid.UUID, issuer string) (*En
 		return nil, fmt.Errorf("failed to generate QR code: %w", err)
 	}
 
-	// Generate backup codes
-	backupCodes, err := auth.GenerateBackupCodes(8)
+	// Generate backup codes (only their hashes are stored)
+	backupCodes, storedCodes, err := issueBackupCodes()
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate backup codes: %w", err)
-	}
-
-	// Store backup codes as JSON
-	backupCodesJSON, err := json.Marshal(backupCodes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode backup codes: %w", err)
+		return nil, err
 	}
 
 	// Save secret and backup codes (not yet enabled)
 	user.TwoFactorSecret = key.Secret()
-	user.BackupCodes = string(backupCodesJSON)
+	user.BackupCodes = storedCodes
 
 	if err := s.db.Save(&user).Error; err != nil {
 		return nil, fmt.Errorf("failed to save 2FA setup: %w", err)
@@ -172,9 +169,9 @@ func (s *TwoFactorService) DisableTwoFactor(userID uuid.UUID, password, code str
 
 	// Verify TOTP code or backup code
 	validCode := auth.ValidateTOTPCode(code, user.TwoFactorSecret)
-	if !validCode {
+	if !validCode && auth.IsBackupCodeFormat(code) {
 		// Check if it's a valid backup code
-		validCode = s.validateBackupCode(&user, code)
+		validCode = matchBackupCode(storedBackupCodes(&user), code) >= 0
 	}
 
 	if !validCode {
@@ -217,7 +214,8 @@ func (s *TwoFactorService) DisableTwoFactor(userID uuid.UUID, password, code str
 	return nil
 }
 
-// VerifyTOTP verifies a TOTP code for a user (used during login)
+// VerifyTOTP verifies a TOTP code for a user (used during login). A backup code is accepted in
+// place of the TOTP code and consumed.
 func (s *TwoFactorService) VerifyTOTP(userID uuid.UUID, code string) (bool, error) {
 	var user models.User
 	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
@@ -237,62 +235,177 @@ func (s *TwoFactorService) VerifyTOTP(userID uuid.UUID, code string) (bool, erro
 	}
 
 	// Check backup code
-	if s.validateBackupCode(&user, code) {
-		// Remove used backup code
-		if err := s.removeBackupCode(&user, code); err != nil {
-			utils.Logger.Error().Err(err).Msg("Failed to remove used backup code")
-		}
-		return true, nil
+	if auth.IsBackupCodeFormat(code) {
+		valid, _, err := s.consumeBackupCode(userID, code)
+		return valid, err
 	}
 
 	return false, nil
 }
 
-// validateBackupCode checks if a backup code is valid
-func (s *TwoFactorService) validateBackupCode(user *models.User, code string) bool {
-	if user.BackupCodes == "" {
-		return false
+// VerifyBackupCode checks a recovery code for a user whose TOTP device is unavailable and consumes
+// it. It returns whether the code was valid and how many unused codes remain.
+func (s *TwoFactorService) VerifyBackupCode(userID uuid.UUID, code, ipAddress, userAgent string) (bool, int, error) {
+	if !auth.IsBackupCodeFormat(code) {
+		return false, 0, nil
 	}
 
-	var backupCodes []string
-	if err := json.Unmarshal([]byte(user.BackupCodes), &backupCodes); err != nil {
-		return false
+	valid, remaining, err := s.consumeBackupCode(userID, code)
+	if err != nil || !valid {
+		return valid, remaining, err
 	}
 
-	for _, bc := range backupCodes {
-		if bc == code {
-			return true
-		}
+	event := models.NewAuthEvent(&userID, models.EventTypeBackupCodeUsed, ipAddress, userAgent)
+	if err := s.db.Create(event).Error; err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to log backup code event")
 	}
 
-	return false
+	utils.Logger.Info().
+		Str("user_id", userID.String()).
+		Int("remaining", remaining).
+		Msg("2FA backup code used")
+
+	return true, remaining, nil
 }
 
-// removeBackupCode removes a used backup code
-func (s *TwoFactorService) removeBackupCode(user *models.User, code string) error {
-	if user.BackupCodes == "" {
+// RegenerateBackupCodes replaces all backup codes of a user after verifying the password and a
+// current TOTP code. Previously issued codes stop working.
+func (s *TwoFactorService) RegenerateBackupCodes(userID uuid.UUID, password, code, ipAddress, userAgent string) ([]string, error) {
+	var user models.User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if !user.TwoFactorEnabled {
+		return nil, fmt.Errorf("two-factor authentication is not enabled")
+	}
+	if !user.CheckPassword(password) {
+		return nil, fmt.Errorf("incorrect password")
+	}
+	if !auth.ValidateTOTPCode(code, user.TwoFactorSecret) {
+		return nil, fmt.Errorf("invalid verification code")
+	}
+
+	backupCodes, storedCodes, err := issueBackupCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("backup_codes", storedCodes).Error; err != nil {
+			return fmt.Errorf("failed to save backup codes: %w", err)
+		}
+		event := models.NewAuthEvent(&userID, models.EventTypeBackupCodesRenewed, ipAddress, userAgent)
+		if err := tx.Create(event).Error; err != nil {
+			utils.Logger.Error().Err(err).Msg("Failed to log backup code regeneration event")
+		}
 		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	var backupCodes []string
-	if err := json.Unmarshal([]byte(user.BackupCodes), &backupCodes); err != nil {
-		return err
+	utils.Logger.Info().
+		Str("user_id", userID.String()).
+		Msg("2FA backup codes regenerated")
+
+	return backupCodes, nil
+}
+
+// BackupCodesRemaining returns the number of unused backup codes of a user
+func (s *TwoFactorService) BackupCodesRemaining(userID uuid.UUID) (int, error) {
+	var user models.User
+	if err := s.db.Select("id", "backup_codes").Where("id = ?", userID).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, fmt.Errorf("user not found")
+		}
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+	return len(storedBackupCodes(&user)), nil
+}
+
+// consumeBackupCode removes a matching backup code; the user row is locked so a code can only be used once
+func (s *TwoFactorService) consumeBackupCode(userID uuid.UUID, code string) (bool, int, error) {
+	valid := false
+	remaining := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", userID).First(&user).Error; err != nil {
+			return fmt.Errorf("database error: %w", err)
+		}
+
+		codes := storedBackupCodes(&user)
+		remaining = len(codes)
+		index := matchBackupCode(codes, code)
+		if index < 0 {
+			return nil
+		}
+
+		codes = append(codes[:index], codes[index+1:]...)
+		encoded, err := json.Marshal(codes)
+		if err != nil {
+			return fmt.Errorf("failed to encode backup codes: %w", err)
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("backup_codes", string(encoded)).Error; err != nil {
+			return fmt.Errorf("failed to consume backup code: %w", err)
+		}
+		valid = true
+		remaining = len(codes)
+		return nil
+	})
+	return valid, remaining, err
+}
+
+// issueBackupCodes generates a fresh set of backup codes and their JSON-encoded hashes for storage
+func issueBackupCodes() ([]string, string, error) {
+	codes, err := auth.GenerateBackupCodes(auth.BackupCodeCount)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate backup codes: %w", err)
 	}
 
-	// Remove the used code
-	updatedCodes := make([]string, 0)
-	for _, bc := range backupCodes {
-		if bc != code {
-			updatedCodes = append(updatedCodes, bc)
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		if hashes[i], err = auth.HashBackupCode(code); err != nil {
+			return nil, "", fmt.Errorf("failed to hash backup codes: %w", err)
 		}
 	}
 
-	// Update user
-	backupCodesJSON, err := json.Marshal(updatedCodes)
+	encoded, err := json.Marshal(hashes)
 	if err != nil {
-		return err
+		return nil, "", fmt.Errorf("failed to encode backup codes: %w", err)
+	}
+	return codes, string(encoded), nil
+}
+
+// storedBackupCodes decodes the stored backup code hashes of a user
+func storedBackupCodes(user *models.User) []string {
+	if user.BackupCodes == "" {
+		return nil
+	}
+	var codes []string
+	if err := json.Unmarshal([]byte(user.BackupCodes), &codes); err != nil {
+		return nil
 	}
+	return codes
+}
 
-	user.BackupCodes = string(backupCodesJSON)
-	return s.db.Save(user).Error
+// matchBackupCode returns the index of the stored code matching code, or -1. Codes issued before
+// hashing was introduced are stored in plain text and compared in constant time.
+func matchBackupCode(stored []string, code string) int {
+	normalized := auth.NormalizeBackupCode(code)
+	for i, entry := range stored {
+		if strings.HasPrefix(entry, "$2") {
+			if auth.CheckBackupCode(normalized, entry) {
+				return i
+			}
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(auth.NormalizeBackupCode(entry)), []byte(normalized)) == 1 {
+			return i
+		}
+	}
+	return -1
 }
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/i18n"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// UserPreferenceService handles per-user preference storage
+type UserPreferenceService struct {
+	db *gorm.DB
+}
+
+// NewUserPreferenceService creates a new user preference service
+func NewUserPreferenceService(db *gorm.DB) *UserPreferenceService {
+	return &UserPreferenceService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *UserPreferenceService) WithContext(ctx context.Context) *UserPreferenceService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// csvDelimiterAliases maps accepted delimiter names to the delimiter character
+var csvDelimiterAliases = map[string]string{
+	",":         ",",
+	"comma":     ",",
+	";":         ";",
+	"semicolon": ";",
+	"\t":        "\t",
+	"tab":       "\t",
+	"|":         "|",
+	"pipe":      "|",
+}
+
+// NormalizeCSVDelimiter validates a delimiter (character or name) and returns the delimiter character
+func NormalizeCSVDelimiter(value string) (string, error) {
+	if delimiter, ok := csvDelimiterAliases[strings.ToLower(value)]; ok {
+		return delimiter, nil
+	}
+	return "", fmt.Errorf("invalid delimiter, must be one of: comma, semicolon, tab, pipe")
+}
+
+// NormalizeCSVEncoding validates an encoding name and returns its canonical form
+func NormalizeCSVEncoding(value string) (models.CSVEncoding, error) {
+	switch strings.ToLower(strings.ReplaceAll(value, "_", "-")) {
+	case "utf-8", "utf8":
+		return models.CSVEncodingUTF8, nil
+	case "iso-8859-1", "latin1", "latin-1":
+		return models.CSVEncodingISO88591, nil
+	case "iso-8859-15", "latin9", "latin-9":
+		return models.CSVEncodingISO885915, nil
+	case "windows-1252", "cp1252":
+		return models.CSVEncodingWindows1252, nil
+	}
+	return "", fmt.Errorf("invalid encoding, must be one of: utf-8, iso-8859-1, iso-8859-15, windows-1252")
+}
+
+// landingViews are the pages a user can choose to open after signing in
+var landingViews = map[string]bool{
+	"dashboard":       true,
+	"vulnerabilities": true,
+	"assets":          true,
+	"assessments":     true,
+	"reports":         true,
+}
+
+// rowsPerPageChoices are the list page sizes a user can choose
+var rowsPerPageChoices = map[int]bool{10: true, 20: true, 25: true, 50: true, 100: true}
+
+// NormalizeTimezone validates an IANA timezone name such as "Europe/Paris"
+func NormalizeTimezone(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "utc") {
+		return "UTC", nil
+	}
+	if value == "Local" {
+		return "", fmt.Errorf("invalid timezone, use an IANA name such as Europe/Paris")
+	}
+	loc, err := time.LoadLocation(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid timezone, use an IANA name such as Europe/Paris")
+	}
+	return loc.String(), nil
+}
+
+// NormalizeLanguage validates a language preference; an empty value follows Accept-Language
+func NormalizeLanguage(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || i18n.IsSupported(value) {
+		return value, nil
+	}
+	return "", fmt.Errorf("invalid language, must be one of: %s", strings.Join(i18n.Supported(), ", "))
+}
+
+// NormalizeDateFormat validates a date format
+func NormalizeDateFormat(value string) (models.DateFormat, error) {
+	switch format := models.DateFormat(strings.ToUpper(strings.TrimSpace(value))); format {
+	case models.DateFormatISO, models.DateFormatEU, models.DateFormatUS, models.DateFormatDE:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid date format, must be one of: YYYY-MM-DD, DD/MM/YYYY, MM/DD/YYYY, DD.MM.YYYY")
+}
+
+// NormalizeNotificationDigest validates a notification digest frequency
+func NormalizeNotificationDigest(value string) (models.NotificationDigest, error) {
+	switch digest := models.NotificationDigest(strings.ToLower(strings.TrimSpace(value))); digest {
+	case models.NotificationDigestOff, models.NotificationDigestDaily, models.NotificationDigestWeekly:
+		return digest, nil
+	}
+	return "", fmt.Errorf("invalid notification digest, must be one of: off, daily, weekly")
+}
+
+// GetPreferences returns the user's stored preferences, or defaults if none are saved
+func (s *UserPreferenceService) GetPreferences(userID uuid.UUID) (*models.UserPreference, error) {
+	var pref models.UserPreference
+	if err := s.db.Where("user_id = ?", userID).First(&pref).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.DefaultUserPreference(userID), nil
+		}
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+	return &pref, nil
+}
+
+// UpdatePreferencesRequest represents a preference update request
+type UpdatePreferencesRequest struct {
+	CSVDelimiter  *string `json:"csv_delimiter,omitempty"`
+	CSVEncoding   *string `json:"csv_encoding,omitempty"`
+	CSVIncludeBOM *bool   `json:"csv_include_bom,omitempty"`
+
+	Language           *string `json:"language,omitempty"`
+	Timezone           *string `json:"timezone,omitempty"`
+	DateFormat         *string `json:"date_format,omitempty"`
+	LandingView        *string `json:"landing_view,omitempty"`
+	RowsPerPage        *int    `json:"rows_per_page,omitempty"`
+	NotificationDigest *string `json:"notification_digest,omitempty"`
+
+	// Saved view IDs; an empty string clears the default
+	DefaultVulnerabilityViewID *string `json:"default_vulnerability_view_id,omitempty"`
+	DefaultAssetViewID         *string `json:"default_asset_view_id,omitempty"`
+}
+
+// UpdatePreferences validates and saves the user's preferences
+func (s *UserPreferenceService) UpdatePreferences(userID uuid.UUID, req UpdatePreferencesRequest) (*models.UserPreference, error) {
+	pref, err := s.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CSVDelimiter != nil {
+		delimiter, err := NormalizeCSVDelimiter(*req.CSVDelimiter)
+		if err != nil {
+			return nil, err
+		}
+		pref.CSVDelimiter = delimiter
+	}
+
+	if req.CSVEncoding != nil {
+		encoding, err := NormalizeCSVEncoding(*req.CSVEncoding)
+		if err != nil {
+			return nil, err
+		}
+		pref.CSVEncoding = encoding
+	}
+
+	if req.CSVIncludeBOM != nil {
+		pref.CSVIncludeBOM = *req.CSVIncludeBOM
+	}
+
+	if req.Language != nil {
+		lang, err := NormalizeLanguage(*req.Language)
+		if err != nil {
+			return nil, err
+		}
+		pref.Language = lang
+	}
+
+	if req.Timezone != nil {
+		timezone, err := NormalizeTimezone(*req.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		pref.Timezone = timezone
+	}
+
+	if req.DateFormat != nil {
+		format, err := NormalizeDateFormat(*req.DateFormat)
+		if err != nil {
+			return nil, err
+		}
+		pref.DateFormat = format
+	}
+
+	if req.LandingView != nil {
+		if !landingViews[*req.LandingView] {
+			return nil, fmt.Errorf("invalid landing view, must be one of: dashboard, vulnerabilities, assets, assessments, reports")
+		}
+		pref.LandingView = *req.LandingView
+	}
+
+	if req.RowsPerPage != nil {
+		if !rowsPerPageChoices[*req.RowsPerPage] {
+			return nil, fmt.Errorf("invalid rows per page, must be one of: 10, 20, 25, 50, 100")
+		}
+		pref.RowsPerPage = *req.RowsPerPage
+	}
+
+	if req.NotificationDigest != nil {
+		digest, err := NormalizeNotificationDigest(*req.NotificationDigest)
+		if err != nil {
+			return nil, err
+		}
+		pref.NotificationDigest = digest
+	}
+
+	if req.DefaultVulnerabilityViewID != nil {
+		viewID, err := s.defaultViewID(userID, *req.DefaultVulnerabilityViewID, models.SavedViewResourceVulnerability)
+		if err != nil {
+			return nil, err
+		}
+		pref.DefaultVulnerabilityViewID = viewID
+	}
+
+	if req.DefaultAssetViewID != nil {
+		viewID, err := s.defaultViewID(userID, *req.DefaultAssetViewID, models.SavedViewResourceAsset)
+		if err != nil {
+			return nil, err
+		}
+		pref.DefaultAssetViewID = viewID
+	}
+
+	// Save creates the row on first update and updates it afterwards
+	if err := s.db.Save(pref).Error; err != nil {
+		return nil, fmt.Errorf("failed to save user preferences: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("user_id", userID.String()).
+		Msg("User preferences updated")
+
+	return pref, nil
+}
+
+// defaultViewID validates a saved view chosen as a list's default: the user must be able to see
+// it and it must be for the list's resource. An empty value clears the default.
+func (s *UserPreferenceService) defaultViewID(userID uuid.UUID, value string, resourceType models.SavedViewResource) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	viewID, err := uuid.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid saved view ID")
+	}
+	view, err := NewSavedViewService(s.db).GetView(viewID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid saved view: %w", err)
+	}
+	if view.ResourceType != resourceType {
+		return nil, fmt.Errorf("invalid saved view: it is for %s, not %s", view.ResourceType, resourceType)
+	}
+	return &viewID, nil
+}
+
+// GetLocation returns the user's preferred timezone, or UTC when it cannot be loaded
+func (s *UserPreferenceService) GetLocation(userID uuid.UUID) *time.Location {
+	pref, err := s.GetPreferences(userID)
+	if err != nil {
+		utils.Logger.Warn().Err(err).Str("user_id", userID.String()).Msg("Failed to load timezone preference, using UTC")
+		return time.UTC
+	}
+	return pref.Location()
+}
+
+// FormatTime formats a timestamp for the user in their timezone and date format
+func (s *UserPreferenceService) FormatTime(userID uuid.UUID, t time.Time) string {
+	pref, err := s.GetPreferences(userID)
+	if err != nil {
+		utils.Logger.Warn().Err(err).Str("user_id", userID.String()).Msg("Failed to load date preferences, using defaults")
+		pref = nil
+	}
+	return pref.FormatTime(t)
+}
+
+// GetLanguage returns the user's preferred language, or "" when they have none and requests
+// should negotiate it from Accept-Language
+func (s *UserPreferenceService) GetLanguage(userID uuid.UUID) string {
+	pref, err := s.GetPreferences(userID)
+	if err != nil {
+		utils.Logger.Warn().Err(err).Str("user_id", userID.String()).Msg("Failed to load language preference")
+		return ""
+	}
+	return pref.Language
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -24,6 +25,11 @@ func NewUserService() *UserService {
 	}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *UserService) WithContext(ctx context.Context) *UserService {
+	return &UserService{db: s.db.WithContext(ctx)}
+}
+
 // GetDB returns the database instance
 func (s *UserService) GetDB() *gorm.DB {
 	return s.db
@@ -1,10 +1,10 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"os"
 	"path/filepath"
 	"time"
 
@@ -18,24 +18,29 @@ import (
 type VulnerabilityAttachmentService struct {
 	db             *gorm.DB
 	imageProcessor *imageutil.ImageProcessor
-	uploadDir      string
-	maxFileSize    int64 // in bytes
+	area           string // Object key prefix in the attachment store
 }
 
 func NewVulnerabilityAttachmentService(db *gorm.DB) *VulnerabilityAttachmentService {
-	// Create uploads directory if it doesn't exist
-	uploadDir := "./uploads/vulnerability-attachments"
-	os.MkdirAll(uploadDir, 0755)
-	os.MkdirAll(filepath.Join(uploadDir, "thumbnails"), 0755)
-
 	return &VulnerabilityAttachmentService{
 		db:             db,
 		imageProcessor: imageutil.NewImageProcessor(),
-		uploadDir:      uploadDir,
-		maxFileSize:    10 * 1024 * 1024, // 10MB default
+		area:           storageAreaVulnerabilityAttachments,
 	}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *VulnerabilityAttachmentService) WithContext(ctx context.Context) *VulnerabilityAttachmentService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// visibleVulnerabilities limits an attachment query to vulnerabilities visible in the service's context
+func (s *VulnerabilityAttachmentService) visibleVulnerabilities(query *gorm.DB) *gorm.DB {
+	return query.Where("vulnerability_id IN (?)", s.db.Model(&models.Vulnerability{}).Select("id"))
+}
+
 // UploadAttachment uploads and processes a file attachment for a vulnerability
 func (s *VulnerabilityAttachmentService) UploadAttachment(
 	vulnerabilityID uuid.UUID,
@@ -43,15 +48,10 @@ func (s *VulnerabilityAttachmentService) UploadAttachment(
 	attachmentType, description string,
 	uploadedBy uuid.UUID,
 ) (*models.VulnerabilityAttachment, error) {
-	// Validate vulnerability exists
-	var vulnerability models.Vulnerability
-	if err := s.db.First(&vulnerability, "id = ?", vulnerabilityID).Error; err != nil {
-		return nil, fmt.Errorf("vulnerability not found: %w", err)
-	}
-
-	// Validate file size
-	if file.Size > s.maxFileSize {
-		return nil, fmt.Errorf("file size exceeds maximum allowed size of %d MB", s.maxFileSize/1024/1024)
+	// Reject oversized files before reading them
+	policy := LoadAttachmentPolicy(s.db)
+	if err := policy.CheckSize(attachmentType, file.Size); err != nil {
+		return nil, err
 	}
 
 	// Open uploaded file
@@ -67,21 +67,39 @@ func (s *VulnerabilityAttachmentService) UploadAttachment(
 		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
 	}
 
-	// Detect MIME type
-	mimeType := file.Header.Get("Content-Type")
+	return s.UploadAttachmentData(vulnerabilityID, file.Filename, file.Header.Get("Content-Type"), fileData, attachmentType, description, uploadedBy)
+}
+
+// UploadAttachmentData stores file content received other than as a form upload (such as an
+// email attachment) as an attachment of a vulnerability
+func (s *VulnerabilityAttachmentService) UploadAttachmentData(
+	vulnerabilityID uuid.UUID,
+	filename, declaredType string,
+	fileData []byte,
+	attachmentType, description string,
+	uploadedBy uuid.UUID,
+) (*models.VulnerabilityAttachment, error) {
+	// Validate vulnerability exists
+	var vulnerability models.Vulnerability
+	if err := s.db.First(&vulnerability, "id = ?", vulnerabilityID).Error; err != nil {
+		return nil, fmt.Errorf("vulnerability not found: %w", err)
+	}
+
+	policy := LoadAttachmentPolicy(s.db)
+
+	// Detect MIME type from the content and enforce the policy on it
+	mimeType := DetectAttachmentMimeType(fileData, declaredType)
+	if err := policy.CheckAttachment(attachmentType, mimeType, int64(len(fileData))); err != nil {
+		return nil, err
+	}
 	isImage := imageutil.IsImage(mimeType)
 
 	// Generate unique filename
-	ext := filepath.Ext(file.Filename)
+	ext := filepath.Ext(filename)
 	uniqueName := fmt.Sprintf("%s_%d%s", uuid.New().String(), time.Now().Unix(), ext)
 	storagePath := filepath.Join(vulnerabilityID.String(), uniqueName)
-	fullPath := filepath.Join(s.uploadDir, storagePath)
-
-	// Create directory for this vulnerability
-	vulnDir := filepath.Join(s.uploadDir, vulnerabilityID.String())
-	if err := os.MkdirAll(vulnDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create upload directory: %w", err)
-	}
+	store := ActiveAttachmentStorage().Store
+	ctx := context.Background()
 
 	var width, height int
 	var normalized bool
@@ -89,16 +107,16 @@ func (s *VulnerabilityAttachmentService) UploadAttachment(
 
 	// Process image if it's an image file
 	if isImage {
-		processed, err := s.imageProcessor.ProcessImage(fileData, file.Filename)
+		processed, err := s.imageProcessor.ProcessImage(fileData, filename)
 		if err != nil {
 			utils.Logger.Warn().Err(err).Msg("Failed to process image, saving original")
 			// Save original if processing fails
-			if err := os.WriteFile(fullPath, fileData, 0644); err != nil {
+			if err := store.Put(ctx, s.objectKey(storagePath), fileData, mimeType); err != nil {
 				return nil, fmt.Errorf("failed to save file: %w", err)
 			}
 		} else {
 			// Save processed (normalized) image
-			if err := os.WriteFile(fullPath, processed.Data, 0644); err != nil {
+			if err := store.Put(ctx, s.objectKey(storagePath), processed.Data, "image/jpeg"); err != nil {
 				return nil, fmt.Errorf("failed to save processed image: %w", err)
 			}
 
@@ -109,17 +127,9 @@ func (s *VulnerabilityAttachmentService) UploadAttachment(
 			// Save thumbnail
 			thumbnailName := fmt.Sprintf("thumb_%s", uniqueName)
 			thumbnailPath = filepath.Join("thumbnails", vulnerabilityID.String(), thumbnailName)
-			thumbnailFullPath := filepath.Join(s.uploadDir, thumbnailPath)
-
-			// Create thumbnail directory
-			thumbnailDir := filepath.Join(s.uploadDir, "thumbnails", vulnerabilityID.String())
-			if err := os.MkdirAll(thumbnailDir, 0755); err != nil {
-				utils.Logger.Warn().Err(err).Msg("Failed to create thumbnail directory")
-			} else {
-				if err := os.WriteFile(thumbnailFullPath, processed.Thumbnail, 0644); err != nil {
-					utils.Logger.Warn().Err(err).Msg("Failed to save thumbnail")
-					thumbnailPath = ""
-				}
+			if err := store.Put(ctx, s.objectKey(thumbnailPath), processed.Thumbnail, "image/jpeg"); err != nil {
+				utils.Logger.Warn().Err(err).Msg("Failed to save thumbnail")
+				thumbnailPath = ""
 			}
 
 			utils.Logger.Info().
@@ -132,7 +142,7 @@ func (s *VulnerabilityAttachmentService) UploadAttachment(
 		}
 	} else {
 		// Save non-image file as-is
-		if err := os.WriteFile(fullPath, fileData, 0644); err != nil {
+		if err := store.Put(ctx, s.objectKey(storagePath), fileData, mimeType); err != nil {
 			return nil, fmt.Errorf("failed to save file: %w", err)
 		}
 	}
@@ -141,10 +151,11 @@ func (s *VulnerabilityAttachmentService) UploadAttachment(
 	attachment := &models.VulnerabilityAttachment{
 		VulnerabilityID: vulnerabilityID,
 		Filename:        uniqueName,
-		OriginalName:    file.Filename,
+		OriginalName:    filename,
 		MimeType:        mimeType,
-		FileSize:        file.Size,
+		FileSize:        int64(len(fileData)),
 		StoragePath:     storagePath,
+		StorageBackend:  store.Driver(),
 		IsImage:         isImage,
 		Width:           width,
 		Height:          height,
@@ -157,9 +168,9 @@ func (s *VulnerabilityAttachmentService) UploadAttachment(
 
 	if err := s.db.Create(attachment).Error; err != nil {
 		// Clean up uploaded files on database error
-		os.Remove(fullPath)
+		store.Delete(ctx, s.objectKey(storagePath))
 		if thumbnailPath != "" {
-			os.Remove(filepath.Join(s.uploadDir, thumbnailPath))
+			store.Delete(ctx, s.objectKey(thumbnailPath))
 		}
 		return nil, fmt.Errorf("failed to save attachment record: %w", err)
 	}
@@ -167,7 +178,7 @@ func (s *VulnerabilityAttachmentService) UploadAttachment(
 	utils.Logger.Info().
 		Str("attachment_id", attachment.ID.String()).
 		Str("vulnerability_id", vulnerabilityID.String()).
-		Str("filename", file.Filename).
+		Str("filename", filename).
 		Bool("is_image", isImage).
 		Bool("normalized", normalized).
 		Msg("Vulnerability attachment uploaded successfully")
@@ -178,7 +189,7 @@ func (s *VulnerabilityAttachmentService) UploadAttachment(
 // GetAttachment retrieves an attachment by ID
 func (s *VulnerabilityAttachmentService) GetAttachment(id uuid.UUID) (*models.VulnerabilityAttachment, error) {
 	var attachment models.VulnerabilityAttachment
-	if err := s.db.Preload("UploadedByUser").First(&attachment, "id = ?", id).Error; err != nil {
+	if err := s.visibleVulnerabilities(s.db.Preload("UploadedByUser")).First(&attachment, "id = ?", id).Error; err != nil {
 		return nil, fmt.Errorf("attachment not found: %w", err)
 	}
 	return &attachment, nil
@@ -187,8 +198,7 @@ func (s *VulnerabilityAttachmentService) GetAttachment(id uuid.UUID) (*models.Vu
 // GetVulnerabilityAttachments retrieves all attachments for a vulnerability
 func (s *VulnerabilityAttachmentService) GetVulnerabilityAttachments(vulnerabilityID uuid.UUID) ([]models.VulnerabilityAttachment, error) {
 	var attachments []models.VulnerabilityAttachment
-	err := s.db.
-		Preload("UploadedByUser").
+	err := s.visibleVulnerabilities(s.db.Preload("UploadedByUser")).
 		Where("vulnerability_id = ?", vulnerabilityID).
 		Order("created_at DESC").
 		Find(&attachments).Error
@@ -198,25 +208,27 @@ func (s *VulnerabilityAttachmentService) GetVulnerabilityAttachments(vulnerabili
 
 // GetAttachmentFile retrieves the file data for an attachment
 func (s *VulnerabilityAttachmentService) GetAttachmentFile(attachment *models.VulnerabilityAttachment, thumbnail bool) ([]byte, error) {
-	var filePath string
+	storagePath := attachment.StoragePath
 	if thumbnail && attachment.ThumbnailPath != "" {
-		filePath = filepath.Join(s.uploadDir, attachment.ThumbnailPath)
-	} else {
-		filePath = filepath.Join(s.uploadDir, attachment.StoragePath)
+		storagePath = attachment.ThumbnailPath
 	}
+	return readStoredFile(attachment.StorageBackend, s.objectKey(storagePath))
+}
 
-	data, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
-	}
+// GetDownloadURL returns a presigned download URL for an attachment and when it expires
+func (s *VulnerabilityAttachmentService) GetDownloadURL(attachment *models.VulnerabilityAttachment) (string, time.Time, error) {
+	return presignStoredFile(attachment.StorageBackend, s.objectKey(attachment.StoragePath), attachment.OriginalName)
+}
 
-	return data, nil
+// objectKey returns the attachment store key of a path relative to this service's area
+func (s *VulnerabilityAttachmentService) objectKey(storagePath string) string {
+	return attachmentObjectKey(s.area, storagePath)
 }
 
 // DeleteAttachment deletes an attachment (soft delete)
 func (s *VulnerabilityAttachmentService) DeleteAttachment(id uuid.UUID) error {
 	var attachment models.VulnerabilityAttachment
-	if err := s.db.First(&attachment, "id = ?", id).Error; err != nil {
+	if err := s.visibleVulnerabilities(s.db).First(&attachment, "id = ?", id).Error; err != nil {
 		return fmt.Errorf("attachment not found: %w", err)
 	}
 
@@ -225,7 +237,7 @@ func (s *VulnerabilityAttachmentService) DeleteAttachment(id uuid.UUID) error {
 		return fmt.Errorf("failed to delete attachment: %w", err)
 	}
 
-	// Note: We keep the files on disk even after soft delete for audit purposes
+	// Note: We keep the stored files even after soft delete for audit purposes
 	// Hard delete cleanup should be done by a separate cleanup job
 
 	utils.Logger.Info().
@@ -238,7 +250,7 @@ func (s *VulnerabilityAttachmentService) DeleteAttachment(id uuid.UUID) error {
 
 // GetAttachmentStats returns statistics about attachments
 func (s *VulnerabilityAttachmentService) GetAttachmentStats(vulnerabilityID *uuid.UUID) (map[string]interface{}, error) {
-	query := s.db.Model(&models.VulnerabilityAttachment{})
+	query := s.visibleVulnerabilities(s.db.Model(&models.VulnerabilityAttachment{}))
 	if vulnerabilityID != nil {
 		query = query.Where("vulnerability_id = ?", *vulnerabilityID)
 	}
@@ -256,19 +268,19 @@ func (s *VulnerabilityAttachmentService) GetAttachmentStats(vulnerabilityID *uui
 
 	// Count by type
 	if vulnerabilityID != nil {
-		s.db.Model(&models.VulnerabilityAttachment{}).
+		s.visibleVulnerabilities(s.db.Model(&models.VulnerabilityAttachment{})).
 			Where("vulnerability_id = ? AND is_image = ?", vulnerabilityID, true).
 			Count(&stats.ImageCount)
 
-		s.db.Model(&models.VulnerabilityAttachment{}).
+		s.visibleVulnerabilities(s.db.Model(&models.VulnerabilityAttachment{})).
 			Where("vulnerability_id = ? AND attachment_type = ?", vulnerabilityID, models.VulnAttachmentTypeProof).
 			Count(&stats.ProofCount)
 	} else {
-		s.db.Model(&models.VulnerabilityAttachment{}).
+		s.visibleVulnerabilities(s.db.Model(&models.VulnerabilityAttachment{})).
 			Where("is_image = ?", true).
 			Count(&stats.ImageCount)
 
-		s.db.Model(&models.VulnerabilityAttachment{}).
+		s.visibleVulnerabilities(s.db.Model(&models.VulnerabilityAttachment{})).
 			Where("attachment_type = ?", models.VulnAttachmentTypeProof).
 			Count(&stats.ProofCount)
 	}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/taxonomy"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetClassificationsRequest replaces the OWASP Top 10 categories and ATT&CK techniques of a
+// vulnerability. A framework left out keeps its classifications; an empty list clears them.
+type SetClassificationsRequest struct {
+	OWASP  *[]string `json:"owasp,omitempty"`
+	ATTACK *[]string `json:"attack,omitempty"`
+}
+
+// SetClassifications replaces the classifications of a vulnerability in each framework of the
+// request with manual ones, including those inferred from its weaknesses or scanner plugin
+func (s *VulnerabilityService) SetClassifications(vulnerabilityID uuid.UUID, req SetClassificationsRequest) (*models.Vulnerability, error) {
+	frameworks := map[string]*[]string{taxonomy.FrameworkOWASP: req.OWASP, taxonomy.FrameworkATTACK: req.ATTACK}
+	codes := make(map[string][]string, len(frameworks))
+	for framework, raw := range frameworks {
+		if raw == nil {
+			continue
+		}
+		parsed, err := taxonomy.ParseCodes(framework, *raw)
+		if err != nil {
+			return nil, err
+		}
+		codes[framework] = parsed
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("owasp or attack is required")
+	}
+	if err := s.checkTaggable([]uuid.UUID{vulnerabilityID}); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for framework, frameworkCodes := range codes {
+			if err := tx.Where("vulnerability_id = ? AND framework = ?", vulnerabilityID, framework).
+				Delete(&models.VulnerabilityClassification{}).Error; err != nil {
+				return fmt.Errorf("failed to clear vulnerability classifications: %w", err)
+			}
+			if len(frameworkCodes) == 0 {
+				continue
+			}
+			rows := make([]models.VulnerabilityClassification, 0, len(frameworkCodes))
+			for _, code := range frameworkCodes {
+				rows = append(rows, models.VulnerabilityClassification{
+					VulnerabilityID: vulnerabilityID,
+					Framework:       framework,
+					Code:            code,
+					Source:          models.ClassificationSourceManual,
+				})
+			}
+			if err := tx.Create(&rows).Error; err != nil {
+				return fmt.Errorf("failed to classify vulnerability: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Str("vulnerability_id", vulnerabilityID.String()).
+		Strs("owasp", codes[taxonomy.FrameworkOWASP]).
+		Strs("attack", codes[taxonomy.FrameworkATTACK]).
+		Msg("Vulnerability classified")
+
+	return s.GetVulnerabilityByID(vulnerabilityID)
+}
+
+// addInferredClassifications records the heuristic mapping of each vulnerability, keeping the
+// classifications it already has
+func addInferredClassifications(tx *gorm.DB, mappings map[uuid.UUID]taxonomy.Mapping) error {
+	var rows []models.VulnerabilityClassification
+	for id, mapping := range mappings {
+		for framework, codes := range map[string][]string{taxonomy.FrameworkOWASP: mapping.OWASP, taxonomy.FrameworkATTACK: mapping.ATTACK} {
+			for _, code := range codes {
+				rows = append(rows, models.VulnerabilityClassification{
+					VulnerabilityID: id,
+					Framework:       framework,
+					Code:            code,
+					Source:          models.ClassificationSourceHeuristic,
+				})
+			}
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 500).Error; err != nil {
+		return fmt.Errorf("failed to classify vulnerabilities: %w", err)
+	}
+	return nil
+}
+
+// classifiedVulnerabilityIDs selects the vulnerabilities classified under any of codes of
+// framework. An ATT&CK technique also matches its sub-techniques.
+func classifiedVulnerabilityIDs(db *gorm.DB, framework string, codes []string) *gorm.DB {
+	query := db.Model(&models.VulnerabilityClassification{}).
+		Select("vulnerability_id").
+		Where("framework = ?", framework)
+	if framework == taxonomy.FrameworkATTACK {
+		return query.Where("code IN ? OR split_part(code, '.', 1) IN ?", codes, codes)
+	}
+	return query.Where("code IN ?", codes)
+}
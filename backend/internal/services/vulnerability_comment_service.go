@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// VulnerabilityCommentService handles vulnerability comments and the activity timeline
+type VulnerabilityCommentService struct {
+	db *gorm.DB
+}
+
+// NewVulnerabilityCommentService creates a new vulnerability comment service
+func NewVulnerabilityCommentService(db *gorm.DB) *VulnerabilityCommentService {
+	return &VulnerabilityCommentService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *VulnerabilityCommentService) WithContext(ctx context.Context) *VulnerabilityCommentService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// Activity entry types returned by GetActivity
+const (
+	ActivityTypeComment           = "comment"
+	ActivityTypeStatusChange      = "status_change"
+	ActivityTypeAssignment        = "assignment"
+	ActivityTypeAttachmentAdded   = "attachment_added"
+	ActivityTypeAttachmentRemoved = "attachment_removed"
+)
+
+// ActivityEntry represents a single event in a vulnerability's activity timeline
+type ActivityEntry struct {
+	Type      string                 `json:"type"`
+	Timestamp time.Time              `json:"timestamp"`
+	ActorID   *uuid.UUID             `json:"actor_id,omitempty"`
+	Actor     string                 `json:"actor,omitempty"`
+	Summary   string                 `json:"summary"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// ensureVulnerabilityExists returns a not found error if the vulnerability does not exist
+func (s *VulnerabilityCommentService) ensureVulnerabilityExists(vulnerabilityID uuid.UUID) error {
+	var count int64
+	if err := s.db.Model(&models.Vulnerability{}).Where("id = ?", vulnerabilityID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to get vulnerability: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("vulnerability not found")
+	}
+	return nil
+}
+
+// ListComments returns all comments for a vulnerability, oldest first
+func (s *VulnerabilityCommentService) ListComments(vulnerabilityID uuid.UUID) ([]models.VulnerabilityComment, error) {
+	if err := s.ensureVulnerabilityExists(vulnerabilityID); err != nil {
+		return nil, err
+	}
+
+	var comments []models.VulnerabilityComment
+	if err := s.db.
+		Preload("Author").
+		Where("vulnerability_id = ?", vulnerabilityID).
+		Order("created_at ASC").
+		Find(&comments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+// CreateComment adds a comment to a vulnerability
+func (s *VulnerabilityCommentService) CreateComment(vulnerabilityID, authorID uuid.UUID, body string) (*models.VulnerabilityComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("comment body is required")
+	}
+
+	if err := s.ensureVulnerabilityExists(vulnerabilityID); err != nil {
+		return nil, err
+	}
+
+	comment := &models.VulnerabilityComment{
+		VulnerabilityID: vulnerabilityID,
+		AuthorID:        authorID,
+		Body:            body,
+	}
+
+	if err := s.db.Create(comment).Error; err != nil {
+		utils.Logger.Error().Err(err).Msg("Failed to create comment")
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	if err := s.db.Preload("Author").First(comment, comment.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load comment: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("vulnerability_id", vulnerabilityID.String()).
+		Str("comment_id", comment.ID.String()).
+		Msg("Vulnerability comment created")
+
+	return comment, nil
+}
+
+// getOwnedComment loads a comment and verifies that it belongs to the vulnerability and author
+func (s *VulnerabilityCommentService) getOwnedComment(vulnerabilityID, commentID, userID uuid.UUID) (*models.VulnerabilityComment, error) {
+	if err := s.ensureVulnerabilityExists(vulnerabilityID); err != nil {
+		return nil, err
+	}
+
+	var comment models.VulnerabilityComment
+	if err := s.db.Where("id = ? AND vulnerability_id = ?", commentID, vulnerabilityID).First(&comment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("comment not found")
+		}
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	if comment.AuthorID != userID {
+		return nil, fmt.Errorf("only the author can modify this comment")
+	}
+
+	return &comment, nil
+}
+
+// UpdateComment edits a comment's body (author only)
+func (s *VulnerabilityCommentService) UpdateComment(vulnerabilityID, commentID, userID uuid.UUID, body string) (*models.VulnerabilityComment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("comment body is required")
+	}
+
+	comment, err := s.getOwnedComment(vulnerabilityID, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.db.Model(comment).Updates(map[string]interface{}{
+		"body":      body,
+		"edited_at": now,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	if err := s.db.Preload("Author").First(comment, comment.ID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// DeleteComment soft deletes a comment (author only)
+func (s *VulnerabilityCommentService) DeleteComment(vulnerabilityID, commentID, userID uuid.UUID) error {
+	comment, err := s.getOwnedComment(vulnerabilityID, commentID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(comment).Error; err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("vulnerability_id", vulnerabilityID.String()).
+		Str("comment_id", commentID.String()).
+		Msg("Vulnerability comment deleted")
+
+	return nil
+}
+
+// GetActivity merges comments, status changes, assignments, and attachment events
+// into a single chronological timeline (oldest first)
+func (s *VulnerabilityCommentService) GetActivity(vulnerabilityID uuid.UUID) ([]ActivityEntry, error) {
+	if err := s.ensureVulnerabilityExists(vulnerabilityID); err != nil {
+		return nil, err
+	}
+
+	entries := []ActivityEntry{}
+
+	// Comments
+	var comments []models.VulnerabilityComment
+	if err := s.db.Preload("Author").Where("vulnerability_id = ?", vulnerabilityID).Find(&comments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load comments: %w", err)
+	}
+	for _, comment := range comments {
+		authorID := comment.AuthorID
+		entries = append(entries, ActivityEntry{
+			Type:      ActivityTypeComment,
+			Timestamp: comment.CreatedAt,
+			ActorID:   &authorID,
+			Actor:     userDisplayName(comment.Author),
+			Summary:   "Added a comment",
+			Details: map[string]interface{}{
+				"comment_id": comment.ID,
+				"body":       comment.Body,
+				"edited_at":  comment.EditedAt,
+			},
+		})
+	}
+
+	// Status changes
+	var statusHistory []models.VulnerabilityStatusHistory
+	if err := s.db.Preload("ChangedBy").Where("vulnerability_id = ?", vulnerabilityID).Find(&statusHistory).Error; err != nil {
+		return nil, fmt.Errorf("failed to load status history: %w", err)
+	}
+	for _, change := range statusHistory {
+		changedByID := change.ChangedByID
+		entries = append(entries, ActivityEntry{
+			Type:      ActivityTypeStatusChange,
+			Timestamp: change.ChangedAt,
+			ActorID:   &changedByID,
+			Actor:     userDisplayName(change.ChangedBy),
+			Summary:   fmt.Sprintf("Changed status from %s to %s", change.OldStatus, change.NewStatus),
+			Details: map[string]interface{}{
+				"old_status": change.OldStatus,
+				"new_status": change.NewStatus,
+				"notes":      change.Notes,
+			},
+		})
+	}
+
+	// Assignments
+	var assignments []models.VulnerabilityAssignmentHistory
+	if err := s.db.
+		Preload("ChangedBy").
+		Preload("OldAssignee").
+		Preload("NewAssignee").
+		Where("vulnerability_id = ?", vulnerabilityID).
+		Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load assignment history: %w", err)
+	}
+	for _, assignment := range assignments {
+		changedByID := assignment.ChangedByID
+		summary := "Unassigned the vulnerability"
+		if assignment.NewAssigneeID != nil {
+			summary = fmt.Sprintf("Assigned to %s", userDisplayName(assignment.NewAssignee))
+		}
+		entries = append(entries, ActivityEntry{
+			Type:      ActivityTypeAssignment,
+			Timestamp: assignment.ChangedAt,
+			ActorID:   &changedByID,
+			Actor:     userDisplayName(assignment.ChangedBy),
+			Summary:   summary,
+			Details: map[string]interface{}{
+				"old_assignee_id": assignment.OldAssigneeID,
+				"new_assignee_id": assignment.NewAssigneeID,
+			},
+		})
+	}
+
+	// Attachments (uploads, plus removals for soft-deleted attachments)
+	var attachments []models.VulnerabilityAttachment
+	if err := s.db.Preload("UploadedByUser").Where("vulnerability_id = ?", vulnerabilityID).Find(&attachments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load attachments: %w", err)
+	}
+	for _, attachment := range attachments {
+		uploadedBy := attachment.UploadedBy
+		details := map[string]interface{}{
+			"attachment_id":   attachment.ID,
+			"original_name":   attachment.OriginalName,
+			"attachment_type": attachment.AttachmentType,
+		}
+		entries = append(entries, ActivityEntry{
+			Type:      ActivityTypeAttachmentAdded,
+			Timestamp: attachment.CreatedAt,
+			ActorID:   &uploadedBy,
+			Actor:     userDisplayName(attachment.UploadedByUser),
+			Summary:   fmt.Sprintf("Attached %s", attachment.OriginalName),
+			Details:   details,
+		})
+		if attachment.DeletedAt != nil {
+			entries = append(entries, ActivityEntry{
+				Type:      ActivityTypeAttachmentRemoved,
+				Timestamp: *attachment.DeletedAt,
+				Summary:   fmt.Sprintf("Removed attachment %s", attachment.OriginalName),
+				Details:   details,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	return entries, nil
+}
+
+// userDisplayName returns the user's name, falling back to their email
+func userDisplayName(user *models.User) string {
+	if user == nil {
+		return ""
+	}
+	if user.Name != "" {
+		return user.Name
+	}
+	return user.Email
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/cwe"
+	"github.com/cyops/cyops-backend/pkg/osv"
+	"github.com/cyops/cyops-backend/pkg/taxonomy"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// ErrEnrichmentDisabled is returned when no known-vulnerability source is configured
+var ErrEnrichmentDisabled = errors.New("vulnerability enrichment is disabled")
+
+// KnownVulnerability is a published advisory affecting a software package
+type KnownVulnerability struct {
+	ID            string // Advisory ID, e.g. GHSA-xxxx-xxxx-xxxx or CVE-2024-1234
+	CVEID         string // CVE the advisory is or aliases, if any
+	Summary       string
+	Details       string
+	Severity      models.VulnerabilitySeverity
+	CVSSScore     *float64
+	CVSSVector    string
+	CWEIDs        []string // Normalized, e.g. CWE-79
+	FixedVersions []string
+	References    []string
+}
+
+// KnownVulnerabilitySource looks up the advisories affecting packages identified by versioned
+// package URL. The result is keyed by package URL; unaffected packages are absent.
+type KnownVulnerabilitySource interface {
+	LookupPackages(ctx context.Context, purls []string) (map[string][]KnownVulnerability, error)
+}
+
+var (
+	knownVulnSourceMu     sync.RWMutex
+	activeKnownVulnSource KnownVulnerabilitySource
+)
+
+// SetKnownVulnerabilitySource sets the source advisories are looked up in (nil disables enrichment)
+func SetKnownVulnerabilitySource(source KnownVulnerabilitySource) {
+	knownVulnSourceMu.Lock()
+	defer knownVulnSourceMu.Unlock()
+	activeKnownVulnSource = source
+}
+
+// ActiveKnownVulnerabilitySource returns the source advisories are looked up in, or nil when disabled
+func ActiveKnownVulnerabilitySource() KnownVulnerabilitySource {
+	knownVulnSourceMu.RLock()
+	defer knownVulnSourceMu.RUnlock()
+	return activeKnownVulnSource
+}
+
+// OSVSource looks up advisories in the OSV database
+type OSVSource struct {
+	client *osv.Client
+}
+
+// NewOSVSource creates a source backed by the OSV API at baseURL
+func NewOSVSource(baseURL string) *OSVSource {
+	return &OSVSource{client: osv.NewClient(baseURL)}
+}
+
+// LookupPackages implements KnownVulnerabilitySource
+func (s *OSVSource) LookupPackages(ctx context.Context, purls []string) (map[string][]KnownVulnerability, error) {
+	found, err := s.client.QueryPURLs(ctx, purls)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string][]KnownVulnerability, len(found))
+	for purl, vulns := range found {
+		for _, vuln := range vulns {
+			result[purl] = append(result[purl], KnownVulnerabilityFromOSV(vuln))
+		}
+	}
+	return result, nil
+}
+
+// osvSeverities maps GitHub advisory severities reported by OSV
+var osvSeverities = map[string]models.VulnerabilitySeverity{
+	"CRITICAL": models.SeverityCritical,
+	"HIGH":     models.SeverityHigh,
+	"MODERATE": models.SeverityMedium,
+	"MEDIUM":   models.SeverityMedium,
+	"LOW":      models.SeverityLow,
+}
+
+// KnownVulnerabilityFromOSV converts an OSV advisory. The severity comes from the advisory
+// database when it rates one, otherwise from the CVSS v3 base score (MEDIUM when unscored).
+func KnownVulnerabilityFromOSV(vuln *osv.Vulnerability) KnownVulnerability {
+	known := KnownVulnerability{
+		ID:            vuln.ID,
+		CVEID:         vuln.CVE(),
+		Summary:       strings.TrimSpace(vuln.Summary),
+		Details:       strings.TrimSpace(vuln.Details),
+		CVSSVector:    vuln.CVSSVector(),
+		CWEIDs:        cwe.NormalizeIDs(vuln.DatabaseSpecific.CWEIDs),
+		FixedVersions: vuln.FixedVersions(),
+	}
+	for _, ref := range vuln.References {
+		known.References = append(known.References, ref.URL)
+	}
+	if score, ok := osv.CVSSv3BaseScore(known.CVSSVector); ok {
+		known.CVSSScore = &score
+	}
+
+	known.Severity = osvSeverities[strings.ToUpper(vuln.DatabaseSpecific.Severity)]
+	if known.Severity == "" {
+		known.Severity = models.SeverityMedium
+		if known.CVSSScore != nil {
+			known.Severity = severityForCVSS(*known.CVSSScore)
+		}
+	}
+	return known
+}
+
+// severityForCVSS rates a CVSS base score using the CVSS v3 qualitative scale
+func severityForCVSS(score float64) models.VulnerabilitySeverity {
+	switch {
+	case score >= 9:
+		return models.SeverityCritical
+	case score >= 7:
+		return models.SeverityHigh
+	case score >= 4:
+		return models.SeverityMedium
+	case score > 0:
+		return models.SeverityLow
+	}
+	return models.SeverityNone
+}
+
+// VulnerabilityEnrichmentService correlates software components with known vulnerabilities
+type VulnerabilityEnrichmentService struct {
+	db *gorm.DB
+}
+
+// NewVulnerabilityEnrichmentService creates a new vulnerability enrichment service
+func NewVulnerabilityEnrichmentService(db *gorm.DB) *VulnerabilityEnrichmentService {
+	return &VulnerabilityEnrichmentService{db: db}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *VulnerabilityEnrichmentService) WithContext(ctx context.Context) *VulnerabilityEnrichmentService {
+	return &VulnerabilityEnrichmentService{db: s.db.WithContext(ctx)}
+}
+
+// LookupPackages returns the known vulnerabilities affecting each versioned package URL
+func (s *VulnerabilityEnrichmentService) LookupPackages(purls []string) (map[string][]KnownVulnerability, error) {
+	source := ActiveKnownVulnerabilitySource()
+	if source == nil {
+		return nil, ErrEnrichmentDisabled
+	}
+	if len(purls) == 0 {
+		return map[string][]KnownVulnerability{}, nil
+	}
+	return source.LookupPackages(s.db.Statement.Context, purls)
+}
+
+// FindOrCreateVulnerability returns the vulnerability recording a known advisory, matched by CVE
+// or, for advisories without one, by the advisory ID its title starts with. Missing
+// vulnerabilities are created open, attributed to createdByID.
+func (s *VulnerabilityEnrichmentService) FindOrCreateVulnerability(tx *gorm.DB, known KnownVulnerability, source string, createdByID uuid.UUID) (*models.Vulnerability, bool, error) {
+	var existing models.Vulnerability
+	query := tx.Model(&models.Vulnerability{})
+	if known.CVEID != "" {
+		query = query.Where("cve_id = ?", known.CVEID)
+	} else {
+		query = query.Where("title LIKE ?", known.ID+":%")
+	}
+	err := query.Order("created_at ASC").First(&existing).Error
+	if err == nil {
+		// Vulnerabilities recorded without weakness types take them from the advisory
+		if len(existing.CWEIDs) == 0 && len(known.CWEIDs) > 0 {
+			if err := tx.Model(&existing).Update("cwe_ids", pq.StringArray(known.CWEIDs)).Error; err != nil {
+				return nil, false, fmt.Errorf("failed to record weakness types of %s: %w", known.ID, err)
+			}
+			if err := addInferredClassifications(tx, map[uuid.UUID]taxonomy.Mapping{existing.ID: taxonomy.Infer(known.CWEIDs, "")}); err != nil {
+				return nil, false, err
+			}
+		}
+		return &existing, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, fmt.Errorf("failed to find vulnerability %s: %w", known.ID, err)
+	}
+
+	id := known.ID
+	if known.CVEID != "" {
+		id = known.CVEID
+	}
+	summary := known.Summary
+	if summary == "" {
+		summary = "Vulnerability in third-party component"
+	}
+	description := known.Details
+	if description == "" {
+		description = summary
+	}
+
+	vulnerability := &models.Vulnerability{
+		Title:         truncate(id+": "+summary, 255),
+		Description:   description,
+		Severity:      known.Severity,
+		CVSSScore:     known.CVSSScore,
+		CVSSVector:    truncate(known.CVSSVector, 100),
+		CVEID:         known.CVEID,
+		CWEIDs:        known.CWEIDs,
+		Status:        models.StatusOpen,
+		Source:        source,
+		DiscoveryDate: time.Now(),
+		CreatedByID:   createdByID,
+	}
+	if len(known.FixedVersions) > 0 {
+		vulnerability.MitigationRecommendations = "Upgrade to a fixed version: " + strings.Join(known.FixedVersions, ", ")
+		vulnerability.PatchAvailable = true
+		vulnerability.FixedVersion = truncate(known.FixedVersions[0], 100)
+	}
+	exploited, err := knownExploitCVEs(tx, []string{known.CVEID})
+	if err != nil {
+		return nil, false, err
+	}
+	vulnerability.HasExploit = exploited[known.CVEID]
+	if err := tx.Create(vulnerability).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to create vulnerability %s: %w", known.ID, err)
+	}
+	if err := addInferredClassifications(tx, map[uuid.UUID]taxonomy.Mapping{vulnerability.ID: taxonomy.Infer(known.CWEIDs, "")}); err != nil {
+		return nil, false, err
+	}
+
+	history := &models.VulnerabilityStatusHistory{
+		VulnerabilityID: vulnerability.ID,
+		NewStatus:       models.StatusOpen,
+		ChangedByID:     createdByID,
+		Notes:           "Created from advisory " + known.ID,
+	}
+	if err := tx.Create(history).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to create status history: %w", err)
+	}
+	return vulnerability, true, nil
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -1,11 +1,15 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/cyops/cyops-backend/pkg/taxonomy"
+	"github.com/cyops/cyops-backend/pkg/utils"
 	"gorm.io/gorm"
 )
 
@@ -17,6 +21,11 @@ func NewVulnerabilityFindingService(db *gorm.DB) *VulnerabilityFindingService {
 	return &VulnerabilityFindingService{db: db}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *VulnerabilityFindingService) WithContext(ctx context.Context) *VulnerabilityFindingService {
+	return &VulnerabilityFindingService{db: s.db.WithContext(ctx)}
+}
+
 // CreateFinding creates a new vulnerability finding
 func (s *VulnerabilityFindingService) CreateFinding(finding *models.VulnerabilityFinding) error {
 	return s.db.Create(finding).Error
@@ -40,13 +49,26 @@ func (s *VulnerabilityFindingService) CreateFindings(findings []models.Vulnerabi
 
 // GetFinding retrieves a finding by ID
 func (s *VulnerabilityFindingService) GetFinding(id uuid.UUID) (*models.VulnerabilityFinding, error) {
+	return s.GetFindingWithFieldset(id, nil)
+}
+
+// GetFindingWithFieldset retrieves a finding by ID, loading only the requested columns and
+// relations (nil loads all relations)
+func (s *VulnerabilityFindingService) GetFindingWithFieldset(id uuid.UUID, fieldset *Fieldset) (*models.VulnerabilityFinding, error) {
 	var finding models.VulnerabilityFinding
-	err := s.db.
-		Preload("Vulnerability").
-		Preload("AffectedSystem").
-		Preload("FixedByUser").
-		Preload("CreatedByUser").
-		Where("id = ?", id).
+	err := fieldset.Apply(s.db, func(query *gorm.DB) *gorm.DB {
+		return query.
+			Preload("Vulnerability").
+			Preload("AffectedSystem").
+			Preload("FixedByUser").
+			Preload("CreatedByUser").
+			Preload("Comments", func(db *gorm.DB) *gorm.DB {
+				return db.Order("created_at ASC")
+			}).
+			Preload("Comments.Author").
+			Preload("Comments.MentionedUsers")
+	}).
+		Where("vulnerability_findings.id = ?", id).
 		First(&finding).Error
 
 	if err != nil {
@@ -87,15 +109,45 @@ func (s *VulnerabilityFindingService) ListFindingsBySystem(systemID uuid.UUID, s
 	return findings, err
 }
 
+// findingListPreloads are the relations loaded for finding lists by default
+func findingListPreloads(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("Vulnerability").
+		Preload("AffectedSystem").
+		Preload("FixedByUser")
+}
+
 // ListFindings lists findings with filters
 func (s *VulnerabilityFindingService) ListFindings(filters map[string]interface{}, page, limit int) ([]models.VulnerabilityFinding, int64, error) {
+	return s.ListFindingsWithFieldset(filters, page, limit, nil)
+}
+
+// ListFindingsWithFieldset lists findings with filters, loading only the requested columns and
+// relations (nil loads the default relations)
+func (s *VulnerabilityFindingService) ListFindingsWithFieldset(filters map[string]interface{}, page, limit int, fieldset *Fieldset) ([]models.VulnerabilityFinding, int64, error) {
 	var findings []models.VulnerabilityFinding
 	var total int64
 
-	query := s.db.Model(&models.VulnerabilityFinding{}).
-		Preload("Vulnerability").
-		Preload("AffectedSystem").
-		Preload("FixedByUser")
+	// Route through the search index when configured, falling back to Postgres on any error.
+	// Asset group membership, creation dates, classifications and exploits are not indexed.
+	_, byGroup := filters["asset_group_id"].(uuid.UUID)
+	_, createdAfter := filters["created_after"].(time.Time)
+	_, createdBefore := filters["created_before"].(time.Time)
+	_, byOWASP := filters["owasp"].([]string)
+	_, byATTACK := filters["attack"].([]string)
+	_, byExploit := filters["has_exploit"].(bool)
+	if idx := ActiveSearchIndex(); idx != nil && !byGroup && !createdAfter && !createdBefore && !byOWASP && !byATTACK && !byExploit {
+		if orgID, ok := tenant.OrgFromContext(s.db.Statement.Context); ok {
+			filters["org_id"] = orgID
+		}
+		indexed, total, err := s.listFindingsFromIndex(idx, filters, page, limit, fieldset)
+		if err == nil {
+			return indexed, total, nil
+		}
+		utils.Logger.Warn().Err(err).Msg("Search index query failed, falling back to Postgres")
+	}
+
+	query := s.db.Model(&models.VulnerabilityFinding{})
 
 	// Apply filters
 	if status, ok := filters["status"].(string); ok && status != "" {
@@ -108,6 +160,28 @@ func (s *VulnerabilityFindingService) ListFindings(filters map[string]interface{
 	if pluginID, ok := filters["plugin_id"].(string); ok && pluginID != "" {
 		query = query.Where("plugin_id = ?", pluginID)
 	}
+	if groupID, ok := filters["asset_group_id"].(uuid.UUID); ok {
+		query = query.Where("vulnerability_findings.affected_system_id IN (?)", AssetGroupMemberIDs(s.db, groupID))
+	}
+	// Findings of vulnerabilities classified under an OWASP Top 10 category or ATT&CK technique
+	for _, framework := range taxonomy.Frameworks() {
+		if codes, ok := filters[framework].([]string); ok {
+			query = query.Where("vulnerability_findings.vulnerability_id IN (?)", classifiedVulnerabilityIDs(s.db, framework, codes))
+		}
+	}
+	// Findings of vulnerabilities with (or without) a known public exploit
+	if hasExploit, ok := filters["has_exploit"].(bool); ok {
+		query = query.Where("vulnerability_findings.vulnerability_id IN (?)", s.db.Model(&models.Vulnerability{}).
+			Select("id").
+			Where("has_exploit = ?", hasExploit))
+	}
+	// Bounds on the partition key let Postgres skip the months outside them
+	if after, ok := filters["created_after"].(time.Time); ok {
+		query = query.Where("vulnerability_findings.created_at >= ?", after)
+	}
+	if before, ok := filters["created_before"].(time.Time); ok {
+		query = query.Where("vulnerability_findings.created_at < ?", before)
+	}
 
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
@@ -123,11 +197,31 @@ func (s *VulnerabilityFindingService) ListFindings(filters map[string]interface{
 		orderBy = "fixed_at DESC NULLS LAST"
 	}
 
-	err := query.Offset(offset).Limit(limit).Order(orderBy).Find(&findings).Error
+	err := fieldset.Apply(query, findingListPreloads).Offset(offset).Limit(limit).Order(orderBy).Find(&findings).Error
 
 	return findings, total, err
 }
 
+// listFindingsFromIndex resolves a list request against the search index and hydrates from Postgres
+func (s *VulnerabilityFindingService) listFindingsFromIndex(idx *SearchIndexService, filters map[string]interface{}, page, limit int, fieldset *Fieldset) ([]models.VulnerabilityFinding, int64, error) {
+	ids, total, err := idx.SearchFindingIDs(filters, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(ids) == 0 {
+		return []models.VulnerabilityFinding{}, total, nil
+	}
+
+	var findings []models.VulnerabilityFinding
+	if err := fieldset.Apply(s.db, findingListPreloads).
+		Where("id IN ?", ids).
+		Find(&findings).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return orderByIDs(findings, ids, func(f *models.VulnerabilityFinding) uuid.UUID { return f.ID }), total, nil
+}
+
 // MarkFindingFixed marks a finding as fixed
 func (s *VulnerabilityFindingService) MarkFindingFixed(findingID, fixedBy uuid.UUID, notes string) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
@@ -213,10 +307,10 @@ func (s *VulnerabilityFindingService) AcceptRisk(findingID, acceptedBy uuid.UUID
 		oldStatus := finding.Status
 
 		updates := map[string]interface{}{
-			"status":             models.FindingStatusAccepted,
-			"risk_accepted_by":   acceptedBy,
-			"risk_accepted_at":   now,
-			"acceptance_reason":  reason,
+			"status":            models.FindingStatusAccepted,
+			"risk_accepted_by":  acceptedBy,
+			"risk_accepted_at":  now,
+			"acceptance_reason": reason,
 		}
 		if expiresAt != nil {
 			updates["expires_at"] = expiresAt
@@ -297,10 +391,17 @@ func (s *VulnerabilityFindingService) FindOrCreateFindingWithTx(tx *gorm.DB, fin
 
 	if err == nil {
 		// Found existing - update last_seen with the scan timestamp
-		tx.Model(&existing).Updates(map[string]interface{}{
-			"last_seen":     finding.LastSeen, // Use scan timestamp, not current time
+		updates := map[string]interface{}{
+			"last_seen":     finding.LastSeen,     // Use scan timestamp, not current time
 			"plugin_output": finding.PluginOutput, // Update with latest scan output
-		})
+		}
+		if finding.DetectedVersion != "" {
+			updates["detected_version"] = finding.DetectedVersion
+		}
+		if finding.FixedVersion != "" {
+			updates["fixed_version"] = finding.FixedVersion
+		}
+		tx.Model(&existing).Updates(updates)
 		return &existing, false, nil
 	}
 
@@ -364,13 +465,13 @@ func (s *VulnerabilityFindingService) GetFindingStatistics(filters map[string]in
 	// Calculate totals
 	var total int64
 	stats := map[string]interface{}{
-		"total":        int64(0),
-		"open":         int64(0),
-		"mitigated":    int64(0),
-		"fixed":        int64(0),
-		"verified":     int64(0),
-		"accepted":     int64(0),
-		"exception":    int64(0),
+		"total":     int64(0),
+		"open":      int64(0),
+		"mitigated": int64(0),
+		"fixed":     int64(0),
+		"verified":  int64(0),
+		"accepted":  int64(0),
+		"exception": int64(0),
 	}
 
 	for _, sc := range statusCounts {
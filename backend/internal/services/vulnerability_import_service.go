@@ -1,13 +1,23 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/faultinject"
+	"github.com/cyops/cyops-backend/pkg/shutdown"
+	"github.com/cyops/cyops-backend/pkg/taxonomy"
+	"github.com/cyops/cyops-backend/pkg/telemetry"
+	"github.com/cyops/cyops-backend/pkg/tenant"
 	"github.com/cyops/cyops-backend/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ImportResult represents the result of an import operation
@@ -21,11 +31,48 @@ type ImportResult struct {
 	TotalFindings           int                    `json:"total_findings"`
 	CreatedFindings         int                    `json:"created_findings"`
 	UpdatedFindings         int                    `json:"updated_findings"`
+	SuppressedFindings      int                    `json:"suppressed_findings"`
+	AssignedVulnerabilities int                    `json:"assigned_vulnerabilities"` // New vulnerabilities routed by assignment rules
+	QuarantinedHosts        int                    `json:"quarantined_hosts"`        // Report hosts held back by validation, listed on the import job
+	Batches                 int                    `json:"batches"`
+	FailedBatches           int                    `json:"failed_batches"`
+	Conflicts               ImportConflictStats    `json:"conflicts"`
+	ImportJobID             *uuid.UUID             `json:"import_job_id,omitempty"`
+	Interrupted             bool                   `json:"interrupted,omitempty"` // Stopped by a server shutdown; the job can be resumed
+	Diff                    ImportDiff             `json:"diff"`
 	Errors                  []string               `json:"errors,omitempty"`
 	Warnings                []string               `json:"warnings,omitempty"`
 	Summary                 map[string]interface{} `json:"summary"`
 }
 
+// ImportConflictStats counts rows an import found already written instead of inserting them
+type ImportConflictStats struct {
+	Assets     int `json:"assets"`      // Assets created by a concurrent import between lookup and insert
+	AssetLinks int `json:"asset_links"` // Asset links to a vulnerability already present
+	Findings   int `json:"findings"`    // Hosts and ports repeated within a vulnerability, merged into one finding
+}
+
+// ImportDiff compares the findings of an import with what earlier imports of the same scan reported
+type ImportDiff struct {
+	New              int `json:"new"`                // Findings the scan had not reported before
+	StillPresent     int `json:"still_present"`      // Findings reported again
+	NoLongerDetected int `json:"no_longer_detected"` // Open findings on scanned assets the scan no longer reports
+	AutoClosed       int `json:"auto_closed"`        // No-longer-detected findings closed as fixed
+}
+
+// ImportSource identifies where imported results come from. Imports of the same scan (same
+// scanner, integration and scan ID) reuse the vulnerabilities earlier imports of it created
+// and are diffed against them.
+type ImportSource struct {
+	Scanner             string     // Scanner name recorded on findings; defaults to nessus
+	IntegrationConfigID *uuid.UUID // Integration the results were fetched through; nil for uploaded files
+	ScanID              string     // Scanner's scan ID; empty when the results are not one scan
+
+	// partial results are only part of the scan, such as a released quarantined host: they are
+	// not validated and the findings they miss are not checked
+	partial bool
+}
+
 // VulnerabilityImportService handles importing vulnerabilities from external sources
 type VulnerabilityImportService struct {
 	db                  *gorm.DB
@@ -33,6 +80,10 @@ type VulnerabilityImportService struct {
 	findingService      *VulnerabilityFindingService
 	assetService        *AssetService
 	assetValidation     *AssetValidationService
+	suppressionService  *SuppressionService
+	assignmentService   *AssignmentRuleService
+	networkRangeService *NetworkRangeService
+	importRuleService   *ImportRuleService
 }
 
 // NewVulnerabilityImportService creates a new import service
@@ -44,217 +95,1252 @@ func NewVulnerabilityImportService() *VulnerabilityImportService {
 		findingService:      NewVulnerabilityFindingService(db),
 		assetService:        NewAssetService(db),
 		assetValidation:     NewAssetValidationService(db),
+		suppressionService:  NewSuppressionService(db),
+		assignmentService:   NewAssignmentRuleService(db),
+		networkRangeService: NewNetworkRangeService(db),
+		importRuleService:   NewImportRuleService(db),
 	}
 }
 
-// ImportFromNessus imports vulnerabilities from parsed Nessus data
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *VulnerabilityImportService) WithContext(ctx context.Context) *VulnerabilityImportService {
+	copied := *s
+	copied.db = s.db.WithContext(ctx)
+	return &copied
+}
+
+// importBatchSize is how many parsed vulnerabilities ImportFromNessus writes per transaction
+var importBatchSize = 500
+
+// maxPluginOutput caps the plugin output stored with a finding
+const maxPluginOutput = 16 * 1024
+
+// SetImportBatchSize sets how many vulnerabilities imports write per transaction; sizes below
+// one keep the current size
+func SetImportBatchSize(size int) {
+	if size > 0 {
+		importBatchSize = size
+	}
+}
+
+// ImportFromNessus imports vulnerabilities from parsed Nessus data. Vulnerabilities are
+// written in batches, each in its own transaction with multi-row inserts; a batch that fails
+// is rolled back and reported without affecting the others.
+//
+// Every import is recorded as an ImportJob. When the source names a scan, vulnerabilities an
+// earlier import of the scan created are reused (skipDuplicates does not apply to them), the
+// findings it reports again are updated instead of duplicated, and open findings of the scan
+// on the scanned assets that it no longer reports are counted as a clean scan. They are
+// closed as fixed when the integration has auto_close_missing set, or when the
+// finding_auto_close setting is enabled and they reached its number of clean scans.
+//
+// Each batch checkpoints the job in its transaction. When the server shuts down, the import
+// stops at the next checkpoint and the job is left interrupted, to be resumed with ResumeImport.
 func (s *VulnerabilityImportService) ImportFromNessus(
 	vulnerabilities []ParsedVulnerability,
 	createdByID uuid.UUID,
 	skipDuplicates bool,
-) (*ImportResult, error) {
-	result := &ImportResult{
+	source ImportSource,
+) (result *ImportResult, err error) {
+	if source.Scanner == "" {
+		source.Scanner = "nessus"
+	}
+	ctx, span := telemetry.StartSpan(s.db.Statement.Context, "VulnerabilityImportService.ImportFromNessus",
+		attribute.Int("import.vulnerabilities", len(vulnerabilities)),
+		attribute.Bool("import.skip_duplicates", skipDuplicates),
+		attribute.Int("import.batch_size", importBatchSize),
+		attribute.String("import.scanner", source.Scanner),
+		attribute.String("import.scan_id", source.ScanID),
+	)
+	defer func() {
+		if result != nil {
+			span.SetAttributes(
+				attribute.Int("import.imported", result.ImportedVulnerabilities),
+				attribute.Int("import.created_findings", result.CreatedFindings),
+				attribute.Int("import.updated_findings", result.UpdatedFindings),
+				attribute.Int("import.failed_batches", result.FailedBatches),
+				attribute.Int("import.no_longer_detected", result.Diff.NoLongerDetected),
+				attribute.Int("import.auto_closed", result.Diff.AutoClosed),
+				attribute.Int("import.errors", len(result.Errors)),
+				attribute.Bool("import.interrupted", result.Interrupted),
+			)
+		}
+		telemetry.EndSpan(span, err)
+	}()
+
+	if err := faultinject.Inject(faultinject.PointImport); err != nil {
+		return nil, fmt.Errorf("import failed: %w", err)
+	}
+
+	done, err := shutdown.Begin("import")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	result = &ImportResult{
 		TotalVulnerabilities: len(vulnerabilities),
 		Errors:               []string{},
 		Warnings:             []string{},
 		Summary:              make(map[string]interface{}),
 	}
+	db := s.db.WithContext(ctx)
 
-	// Start transaction
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	autoClose := false
+	if source.IntegrationConfigID != nil {
+		if autoClose, err = autoCloseMissingFindings(db, *source.IntegrationConfigID); err != nil {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("Integration settings not loaded, findings no longer detected are left open: %v", err))
 		}
-	}()
+	}
 
-	for _, parsedVuln := range vulnerabilities {
-		// Check for duplicate vulnerability by title or CVE
-		if skipDuplicates {
-			var existingCount int64
-			query := tx.Model(&models.Vulnerability{})
+	cleanScans := 0
+	if settings, err := LoadFindingAutoCloseSettings(db); err != nil {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("Finding auto-close settings not loaded, clean scans do not close findings: %v", err))
+	} else if settings.Enabled {
+		cleanScans = settings.CleanScans
+	}
 
-			if parsedVuln.CVEID != "" {
-				query = query.Where("cve_id = ?", parsedVuln.CVEID)
-			} else {
-				query = query.Where("title = ?", parsedVuln.Title)
-			}
+	job := &models.ImportJob{
+		Scanner:             source.Scanner,
+		IntegrationConfigID: source.IntegrationConfigID,
+		ScanID:              source.ScanID,
+		Status:              models.ImportJobRunning,
+		AutoCloseMissing:    autoClose,
+		SkipDuplicates:      skipDuplicates,
+		CreatedByID:         createdByID,
+		StartedAt:           time.Now(),
+	}
+	if err := db.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+	result.ImportJobID = &job.ID
 
-			query.Count(&existingCount)
+	// Hold back report hosts that fail validation instead of creating assets for them
+	if !source.partial {
+		if vulnerabilities, err = s.quarantineHosts(db, job, vulnerabilities, result); err != nil {
+			db.Model(job).Updates(map[string]interface{}{
+				"status":       models.ImportJobFailed,
+				"error":        err.Error(),
+				"completed_at": time.Now(),
+			})
+			return nil, err
+		}
+	}
 
-			if existingCount > 0 {
-				result.SkippedVulnerabilities++
-				result.Warnings = append(result.Warnings,
-					fmt.Sprintf("Skipped duplicate: %s", parsedVuln.Title))
-				continue
+	state := s.newImportState(db, job, source, result)
+	s.runImport(db, job, state, vulnerabilities, 0, cleanScans, result)
+	return result, nil
+}
+
+// ResumeImport continues an interrupted import from its checkpoint with the same parsed
+// results, such as a new export of the same scan. The findings no longer detected are not
+// checked, since the earlier part of the import is not known to this run.
+func (s *VulnerabilityImportService) ResumeImport(jobID uuid.UUID, vulnerabilities []ParsedVulnerability) (*ImportResult, error) {
+	done, err := shutdown.Begin("import")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	db := s.db
+	var job models.ImportJob
+	err = db.First(&job, "id = ?", jobID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("import job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import job: %w", err)
+	}
+	if job.Status != models.ImportJobInterrupted {
+		return nil, fmt.Errorf("only interrupted imports can be resumed")
+	}
+	// The checkpoint counts the results left after validation, so hold back the same hosts
+	// again; the first run quarantined them
+	if job.QuarantinedHosts > 0 {
+		validator := loadImportHostValidator(db, &ImportResult{})
+		if validator == nil {
+			return nil, fmt.Errorf("import validation settings are needed to resume an import that quarantined hosts")
+		}
+		vulnerabilities, _, _ = validator.Split(vulnerabilities)
+	}
+	if job.ProcessedVulnerabilities > len(vulnerabilities) {
+		return nil, fmt.Errorf("the results have fewer vulnerabilities than the import already processed")
+	}
+
+	// Claim the job so that two resumes cannot run at once
+	claim := db.Model(&models.ImportJob{}).
+		Where("id = ? AND status = ?", job.ID, models.ImportJobInterrupted).
+		Update("status", models.ImportJobRunning)
+	if claim.Error != nil {
+		return nil, fmt.Errorf("failed to resume import job: %w", claim.Error)
+	}
+	if claim.RowsAffected == 0 {
+		return nil, fmt.Errorf("only interrupted imports can be resumed")
+	}
+
+	result := &ImportResult{
+		TotalVulnerabilities:    len(vulnerabilities),
+		ImportedVulnerabilities: job.ImportedVulnerabilities,
+		SkippedVulnerabilities:  job.SkippedVulnerabilities,
+		Diff: ImportDiff{
+			New:          job.NewFindings,
+			StillPresent: job.StillPresentFindings,
+		},
+		ImportJobID: &job.ID,
+		Errors:      []string{},
+		Warnings:    []string{},
+		Summary:     make(map[string]interface{}),
+	}
+	source := ImportSource{
+		Scanner:             job.Scanner,
+		IntegrationConfigID: job.IntegrationConfigID,
+		ScanID:              job.ScanID,
+	}
+	state := s.newImportState(db, &job, source, result)
+	s.runImport(db, &job, state, vulnerabilities, job.ProcessedVulnerabilities, 0, result)
+	return result, nil
+}
+
+// newImportState loads the rules applied to the vulnerabilities of an import. Rules that
+// cannot be loaded are skipped with a warning.
+func (s *VulnerabilityImportService) newImportState(db *gorm.DB, job *models.ImportJob, source ImportSource, result *ImportResult) *nessusImportState {
+	// Rules are loaded for the importing organization, also when a resume runs without one
+	rulesDB := db
+	if job.OrgID != nil {
+		rulesDB = db.WithContext(tenant.WithOrg(db.Statement.Context, *job.OrgID))
+	}
+
+	// Load suppression rules once per import
+	suppressions, err := s.suppressionService.LoadMatcher(rulesDB)
+	if err != nil {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("Suppression rules not applied: %v", err))
+	}
+
+	// Load assignment rules once per import to route new vulnerabilities
+	assignments, err := s.assignmentService.LoadMatcher(rulesDB)
+	if err != nil {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("Assignment rules not applied: %v", err))
+	}
+
+	// Load network ranges once per import to classify new hosts
+	networkRanges, err := s.networkRangeService.LoadMatcher(rulesDB)
+	if err != nil {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("Network ranges not applied, new hosts default to PRODUCTION: %v", err))
+	}
+
+	// Load import rules once per import to classify new hosts ahead of network ranges
+	importRules, err := s.importRuleService.LoadMatcher(rulesDB)
+	if err != nil {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("Import rules not applied to new hosts: %v", err))
+	}
+
+	// Load the integration's severity mapping to translate the scanner's severities
+	var severities *models.SeverityMapping
+	if source.IntegrationConfigID != nil {
+		if severities, err = integrationSeverityMapping(db, *source.IntegrationConfigID); err != nil {
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("Severity mapping not applied, scanner severities are used as reported: %v", err))
+		}
+	}
+
+	return &nessusImportState{
+		createdByID:    job.CreatedByID,
+		skipDuplicates: job.SkipDuplicates,
+		suppressions:   suppressions,
+		assignments:    assignments,
+		networkRanges:  networkRanges,
+		importRules:    importRules,
+		severities:     severities,
+		source:         source,
+		jobID:          job.ID,
+		assets:         make(map[importHostKey]uuid.UUID),
+		seen:           make(map[string]bool),
+		scanVulns:      make(map[string]uuid.UUID),
+		scannedAssets:  make(map[uuid.UUID]bool),
+	}
+}
+
+// runImport writes the vulnerabilities from start on in batches and records the outcome on
+// the job. A batch commits with the job's checkpoint; once the server is draining, the
+// remaining batches are left for a resume.
+func (s *VulnerabilityImportService) runImport(
+	db *gorm.DB,
+	job *models.ImportJob,
+	state *nessusImportState,
+	vulnerabilities []ParsedVulnerability,
+	start int,
+	cleanScans int,
+	result *ImportResult,
+) {
+	for ; start < len(vulnerabilities); start += importBatchSize {
+		if shutdown.Draining() {
+			result.Interrupted = true
+			result.Warnings = append(result.Warnings,
+				fmt.Sprintf("Import interrupted by a server shutdown after %d of %d vulnerabilities; resume the import job to continue", start, len(vulnerabilities)))
+			break
+		}
+
+		end := start + importBatchSize
+		if end > len(vulnerabilities) {
+			end = len(vulnerabilities)
+		}
+
+		batch := &ImportResult{}
+		pending := state.begin()
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := s.importNessusBatch(tx, vulnerabilities[start:end], pending, batch); err != nil {
+				return err
 			}
+			return tx.Model(&models.ImportJob{}).Where("id = ?", job.ID).
+				Update("processed_vulnerabilities", end).Error
+		})
+		result.Batches++
+		if err != nil {
+			result.FailedBatches++
+			result.Errors = append(result.Errors,
+				fmt.Sprintf("Failed to import vulnerabilities %d-%d: %v", start+1, end, err))
+			continue
 		}
+		state.commit(pending)
+		result.Merge(batch)
+	}
 
-		// Create or find affected systems and track host details
-		type HostAssetPair struct {
-			Host    ParsedHost
-			AssetID uuid.UUID
+	// Findings missing from a partial import are not known to be gone
+	if state.source.ScanID != "" && !state.source.partial && len(state.scannedAssets) > 0 {
+		switch {
+		case result.FailedBatches > 0:
+			result.Warnings = append(result.Warnings,
+				"Findings no longer detected were not checked because some batches failed")
+		case result.Interrupted || job.ProcessedVulnerabilities > 0:
+			result.Warnings = append(result.Warnings,
+				"Findings no longer detected were not checked because the import was interrupted")
+		default:
+			if err := s.diffMissingFindings(db, state, job.AutoCloseMissing, cleanScans, result); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("Failed to check findings no longer detected: %v", err))
+			}
 		}
-		affectedSystemIDs := []uuid.UUID{}
-		hostAssetPairs := []HostAssetPair{}
+	}
 
-		for _, host := range parsedVuln.AffectedHosts {
-			assetID, created, err := s.findOrCreateAsset(tx, host, createdByID)
-			if err != nil {
-				result.Errors = append(result.Errors,
-					fmt.Sprintf("Failed to create asset %s: %v", host.IPAddress, err))
+	result.summarize()
+	s.finishImportJob(db, job, state, result)
+
+	utils.Logger.Info().
+		Int("total", result.TotalVulnerabilities).
+		Int("imported", result.ImportedVulnerabilities).
+		Int("skipped", result.SkippedVulnerabilities).
+		Int("created_assets", result.CreatedAssets).
+		Int("suppressed_findings", result.SuppressedFindings).
+		Int("assigned_vulnerabilities", result.AssignedVulnerabilities).
+		Int("batches", result.Batches).
+		Int("failed_batches", result.FailedBatches).
+		Int("new_findings", result.Diff.New).
+		Int("still_present_findings", result.Diff.StillPresent).
+		Int("no_longer_detected", result.Diff.NoLongerDetected).
+		Int("auto_closed", result.Diff.AutoClosed).
+		Bool("interrupted", result.Interrupted).
+		Str("import_job_id", job.ID.String()).
+		Msg("Nessus import completed")
+}
+
+// finishImportJob records the outcome of an import on its job. The import has already been
+// written, so failing to record it is only logged.
+func (s *VulnerabilityImportService) finishImportJob(db *gorm.DB, job *models.ImportJob, state *nessusImportState, result *ImportResult) {
+	status := models.ImportJobCompleted
+	errorMessage := ""
+	if result.Batches > 0 && result.FailedBatches == result.Batches {
+		status = models.ImportJobFailed
+	}
+	if result.Interrupted {
+		status = models.ImportJobInterrupted
+	}
+	if len(result.Errors) > 0 {
+		errorMessage = result.Errors[0]
+	}
+	var scanDate *time.Time
+	if !state.scanDate.IsZero() {
+		scanDate = &state.scanDate
+	}
+
+	var completedAt *time.Time
+	if status != models.ImportJobInterrupted {
+		now := time.Now()
+		completedAt = &now
+	}
+	if err := db.Model(job).Updates(map[string]interface{}{
+		"status":                      status,
+		"scan_date":                   scanDate,
+		"imported_vulnerabilities":    result.ImportedVulnerabilities,
+		"skipped_vulnerabilities":     result.SkippedVulnerabilities,
+		"new_findings":                result.Diff.New,
+		"still_present_findings":      result.Diff.StillPresent,
+		"no_longer_detected_findings": result.Diff.NoLongerDetected,
+		"auto_closed_findings":        result.Diff.AutoClosed,
+		"errors":                      len(result.Errors),
+		"error":                       errorMessage,
+		"completed_at":                completedAt,
+	}).Error; err != nil {
+		utils.Logger.Error().Err(err).Str("import_job_id", job.ID.String()).Msg("Failed to record import job outcome")
+	}
+}
+
+// autoCloseMissingFindings reports whether the integration closes findings its re-imported scans no longer report
+func autoCloseMissingFindings(db *gorm.DB, integrationConfigID uuid.UUID) (bool, error) {
+	var settings []bool
+	if err := db.Model(&models.IntegrationConfig{}).
+		Where("id = ?", integrationConfigID).
+		Pluck("auto_close_missing", &settings).Error; err != nil {
+		return false, err
+	}
+	return len(settings) > 0 && settings[0], nil
+}
+
+// integrationSeverityMapping loads the severity mapping of an integration, nil when it has none
+func integrationSeverityMapping(db *gorm.DB, integrationConfigID uuid.UUID) (*models.SeverityMapping, error) {
+	var mappings []string
+	if err := db.Model(&models.IntegrationConfig{}).
+		Where("id = ? AND severity_mapping IS NOT NULL", integrationConfigID).
+		Pluck("severity_mapping", &mappings).Error; err != nil {
+		return nil, err
+	}
+	if len(mappings) == 0 {
+		return nil, nil
+	}
+	return models.ParseSeverityMapping(mappings[0])
+}
+
+// diffMissingFindings counts the open findings of the imported scan on the assets it scanned
+// that this import did not report, adding a clean scan to each. They are closed as fixed when
+// autoClose is set, or once their clean scans reach cleanScans when it is not zero.
+func (s *VulnerabilityImportService) diffMissingFindings(db *gorm.DB, state *nessusImportState, autoClose bool, cleanScans int, result *ImportResult) error {
+	assetIDs := make([]uuid.UUID, 0, len(state.scannedAssets))
+	for id := range state.scannedAssets {
+		assetIDs = append(assetIDs, id)
+	}
+
+	for start := 0; start < len(assetIDs); start += importBatchSize {
+		end := start + importBatchSize
+		if end > len(assetIDs) {
+			end = len(assetIDs)
+		}
+
+		var missing []uuid.UUID
+		if err := scanFindings(db, state.source).
+			Where("status = ? AND affected_system_id IN ?", models.FindingStatusOpen, assetIDs[start:end]).
+			Where("import_job_id IS NULL OR import_job_id <> ?", state.jobID).
+			Pluck("id", &missing).Error; err != nil {
+			return err
+		}
+		result.Diff.NoLongerDetected += len(missing)
+		if len(missing) == 0 {
+			continue
+		}
+		// Journal the findings before counting the clean scan, so that a rollback restores them
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := recordFindingChanges(tx, state.jobID, models.ImportChangeMissing, missing); err != nil {
+				return err
+			}
+			return tx.Model(&models.VulnerabilityFinding{}).
+				Where("id IN ?", missing).
+				Update("clean_scans", gorm.Expr("clean_scans + 1")).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to count clean scans: %w", err)
+		}
+
+		toClose := missing
+		notes := fmt.Sprintf("No longer detected by %s scan %s", state.source.Scanner, state.source.ScanID)
+		if !autoClose {
+			if cleanScans == 0 {
 				continue
 			}
+			toClose = nil
+			if err := db.Model(&models.VulnerabilityFinding{}).
+				Where("id IN ? AND clean_scans >= ?", missing, cleanScans).
+				Pluck("id", &toClose).Error; err != nil {
+				return err
+			}
+			notes = cleanScansNote(cleanScans)
+		}
+		if len(toClose) == 0 {
+			continue
+		}
 
-			affectedSystemIDs = append(affectedSystemIDs, assetID)
-			hostAssetPairs = append(hostAssetPairs, HostAssetPair{Host: host, AssetID: assetID})
-			result.TotalAssets++
+		err = db.Transaction(func(tx *gorm.DB) error {
+			return closeMissingFindings(tx, toClose, notes, state.createdByID, time.Now())
+		})
+		if err != nil {
+			return fmt.Errorf("failed to close findings no longer detected: %w", err)
+		}
+		result.Diff.AutoClosed += len(toClose)
+	}
+	return nil
+}
 
-			if created {
-				result.CreatedAssets++
-			} else {
-				result.ExistingAssets++
+// scanFindings selects the findings earlier imports of the source's scan reported
+func scanFindings(db *gorm.DB, source ImportSource) *gorm.DB {
+	query := db.Model(&models.VulnerabilityFinding{}).
+		Where("scanner_name = ? AND scan_id = ?", source.Scanner, source.ScanID)
+	if source.IntegrationConfigID != nil {
+		return query.Where("integration_config_id = ?", *source.IntegrationConfigID)
+	}
+	return query.Where("integration_config_id IS NULL")
+}
+
+// summarize computes the summary of the result's counts
+func (r *ImportResult) summarize() {
+	successRate := 0.0
+	if r.TotalVulnerabilities > 0 {
+		successRate = float64(r.ImportedVulnerabilities) / float64(r.TotalVulnerabilities) * 100
+	}
+
+	r.Summary = map[string]interface{}{
+		"success_rate": successRate,
+		"has_errors":   len(r.Errors) > 0,
+		"has_warnings": len(r.Warnings) > 0,
+	}
+}
+
+// Merge accumulates the counts, errors and warnings of another import, such as a committed
+// batch or the import of another scan, and recomputes the summary
+func (r *ImportResult) Merge(other *ImportResult) {
+	r.TotalVulnerabilities += other.TotalVulnerabilities
+	r.Batches += other.Batches
+	r.FailedBatches += other.FailedBatches
+	r.Diff.New += other.Diff.New
+	r.Diff.StillPresent += other.Diff.StillPresent
+	r.Diff.NoLongerDetected += other.Diff.NoLongerDetected
+	r.Diff.AutoClosed += other.Diff.AutoClosed
+	r.ImportedVulnerabilities += other.ImportedVulnerabilities
+	r.SkippedVulnerabilities += other.SkippedVulnerabilities
+	r.TotalAssets += other.TotalAssets
+	r.CreatedAssets += other.CreatedAssets
+	r.ExistingAssets += other.ExistingAssets
+	r.TotalFindings += other.TotalFindings
+	r.CreatedFindings += other.CreatedFindings
+	r.UpdatedFindings += other.UpdatedFindings
+	r.SuppressedFindings += other.SuppressedFindings
+	r.AssignedVulnerabilities += other.AssignedVulnerabilities
+	r.QuarantinedHosts += other.QuarantinedHosts
+	r.Interrupted = r.Interrupted || other.Interrupted
+	r.Conflicts.Assets += other.Conflicts.Assets
+	r.Conflicts.AssetLinks += other.Conflicts.AssetLinks
+	r.Conflicts.Findings += other.Conflicts.Findings
+	r.Errors = append(r.Errors, other.Errors...)
+	r.Warnings = append(r.Warnings, other.Warnings...)
+	r.summarize()
+}
+
+// importHostKey identifies the asset of a scanned host
+type importHostKey struct {
+	IPAddress   string
+	Hostname    string
+	Environment models.Environment
+}
+
+// nessusImportState carries what an import has resolved across its batches. Assets and
+// vulnerabilities of a batch are only remembered once the batch commits.
+type nessusImportState struct {
+	createdByID    uuid.UUID
+	skipDuplicates bool
+	suppressions   *SuppressionMatcher
+	assignments    *AssignmentMatcher
+	networkRanges  *NetworkRangeMatcher
+	importRules    *ImportRuleMatcher
+	severities     *models.SeverityMapping // Integration's severity mapping; nil for the defaults
+	source         ImportSource
+	jobID          uuid.UUID
+	assets         map[importHostKey]uuid.UUID
+	seen           map[string]bool      // CVE IDs and titles imported, for skipDuplicates
+	scanVulns      map[string]uuid.UUID // Vulnerabilities earlier imports of the scan created, by plugin ID
+	scannedAssets  map[uuid.UUID]bool   // Assets of the hosts the scan reported
+	scanDate       time.Time            // Latest host scan time
+}
+
+// begin returns a copy of the state for a batch to extend
+func (st *nessusImportState) begin() *nessusImportState {
+	pending := *st
+	pending.assets = make(map[importHostKey]uuid.UUID, len(st.assets))
+	for key, id := range st.assets {
+		pending.assets[key] = id
+	}
+	pending.seen = make(map[string]bool, len(st.seen))
+	for key := range st.seen {
+		pending.seen[key] = true
+	}
+	pending.scanVulns = make(map[string]uuid.UUID, len(st.scanVulns))
+	for pluginID, id := range st.scanVulns {
+		pending.scanVulns[pluginID] = id
+	}
+	pending.scannedAssets = make(map[uuid.UUID]bool, len(st.scannedAssets))
+	for id := range st.scannedAssets {
+		pending.scannedAssets[id] = true
+	}
+	return &pending
+}
+
+// commit keeps what a committed batch resolved
+func (st *nessusImportState) commit(pending *nessusImportState) {
+	st.assets = pending.assets
+	st.seen = pending.seen
+	st.scanVulns = pending.scanVulns
+	st.scannedAssets = pending.scannedAssets
+	st.scanDate = pending.scanDate
+}
+
+// duplicateKey is the CVE ID of a vulnerability, or its title when it has none
+func duplicateKey(vuln ParsedVulnerability) string {
+	if vuln.CVEID != "" {
+		return "cve:" + vuln.CVEID
+	}
+	return "title:" + vuln.Title
+}
+
+// importNessusBatch writes a batch of parsed vulnerabilities with their assets, asset links,
+// status history and findings, using one multi-row insert per table
+func (s *VulnerabilityImportService) importNessusBatch(
+	tx *gorm.DB,
+	parsed []ParsedVulnerability,
+	state *nessusImportState,
+	result *ImportResult,
+) error {
+	// Vulnerabilities earlier imports of the scan created are reused
+	if state.source.ScanID != "" {
+		if err := loadScanVulnerabilities(tx, parsed, state); err != nil {
+			return err
+		}
+	}
+
+	// Skip vulnerabilities already present by CVE ID, or by title when they have none
+	if state.skipDuplicates {
+		if err := s.loadExistingVulnerabilities(tx, parsed, state); err != nil {
+			return err
+		}
+	}
+	var toImport []ParsedVulnerability
+	for _, parsedVuln := range parsed {
+		if _, ok := state.scanVulns[parsedVuln.PluginID]; ok && parsedVuln.PluginID != "" {
+			toImport = append(toImport, parsedVuln)
+			continue
+		}
+		if state.skipDuplicates {
+			key := duplicateKey(parsedVuln)
+			if state.seen[key] {
+				result.SkippedVulnerabilities++
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("Skipped duplicate: %s", parsedVuln.Title))
+				continue
 			}
+			state.seen[key] = true
 		}
+		toImport = append(toImport, parsedVuln)
+	}
+	if len(toImport) == 0 {
+		return nil
+	}
+
+	// Find or create the assets of every host in the batch
+	hostAssets, err := s.resolveAssets(tx, toImport, state, result)
+	if err != nil {
+		return err
+	}
 
-		// Create vulnerability
-		vulnerability := &models.Vulnerability{
+	// Create vulnerabilities the scan has not reported before, flagged when the scanner or an
+	// exploit feed knows of a public exploit
+	cves := make([]string, len(toImport))
+	for i := range toImport {
+		cves[i] = toImport[i].CVEID
+	}
+	exploited, err := knownExploitCVEs(tx, cves)
+	if err != nil {
+		return err
+	}
+	vulns := make([]*models.Vulnerability, len(toImport))
+	var newVulns []*models.Vulnerability
+	reused := make(map[uuid.UUID]bool)
+	for i, parsedVuln := range toImport {
+		if id, ok := state.scanVulns[parsedVuln.PluginID]; ok && parsedVuln.PluginID != "" {
+			vulns[i] = &models.Vulnerability{BaseModel: models.BaseModel{ID: id}}
+			reused[id] = true
+			continue
+		}
+		scanDate := parsedVuln.ScanDate
+		vulns[i] = &models.Vulnerability{
 			Title:                     parsedVuln.Title,
 			Description:               parsedVuln.Description,
-			Severity:                  parsedVuln.Severity,
+			Severity:                  state.severities.Severity(parsedVuln.ScannerSeverity, parsedVuln.CVSSScore, parsedVuln.Severity),
 			CVSSScore:                 parsedVuln.CVSSScore,
 			CVSSVector:                parsedVuln.CVSSVector,
 			CVEID:                     parsedVuln.CVEID,
+			CWEIDs:                    parsedVuln.CWEIDs,
+			HasExploit:                parsedVuln.ExploitAvailable || exploited[parsedVuln.CVEID],
 			Status:                    models.StatusOpen,
 			Source:                    "Nessus",
 			DiscoveryDate:             parsedVuln.ScanDate,
 			ImpactAssessment:          parsedVuln.ImpactAssessment,
 			MitigationRecommendations: parsedVuln.MitigationRecommendations,
-			CreatedByID:               createdByID,
+			PatchAvailable:            parsedVuln.PatchAvailable,
+			AdvisoryURL:               parsedVuln.AdvisoryURL,
+			FixedVersion:              parsedVuln.FixedVersion,
+			Workaround:                parsedVuln.Workaround,
+			CreatedByID:               state.createdByID,
+			ImportJobID:               &state.jobID,
+			SourceScanID:              state.source.ScanID,
+			SourceScanDate:            &scanDate,
 		}
-
-		if err := tx.Create(vulnerability).Error; err != nil {
-			result.Errors = append(result.Errors,
-				fmt.Sprintf("Failed to create vulnerability %s: %v", parsedVuln.Title, err))
-			continue
+		newVulns = append(newVulns, vulns[i])
+	}
+	if len(newVulns) > 0 {
+		if err := tx.CreateInBatches(newVulns, importBatchSize).Error; err != nil {
+			return fmt.Errorf("failed to create vulnerabilities: %w", err)
 		}
-
-		// Link affected systems (deduplicate first - same asset may have multiple ports)
-		uniqueAssetIDs := make(map[uuid.UUID]bool)
-		for _, assetID := range affectedSystemIDs {
-			uniqueAssetIDs[assetID] = true
+		newIDs := make([]uuid.UUID, len(newVulns))
+		for i, vulnerability := range newVulns {
+			newIDs[i] = vulnerability.ID
+		}
+		if err := recordCreated(tx, state.jobID, models.ImportChangeVulnerability, newIDs); err != nil {
+			return err
+		}
+		// Map new vulnerabilities to OWASP and ATT&CK from their weaknesses and plugin family
+		mappings := make(map[uuid.UUID]taxonomy.Mapping, len(newVulns))
+		for i, vulnerability := range vulns {
+			if !reused[vulnerability.ID] {
+				mappings[vulnerability.ID] = taxonomy.Infer(toImport[i].CWEIDs, toImport[i].PluginFamily)
+			}
+		}
+		if err := addInferredClassifications(tx, mappings); err != nil {
+			return err
 		}
+	}
+	for i, vulnerability := range vulns {
+		if pluginID := toImport[i].PluginID; pluginID != "" && state.source.ScanID != "" {
+			state.scanVulns[pluginID] = vulnerability.ID
+		}
+	}
+
+	// Findings the scan reported before are updated rather than created again
+	existingFindings, err := loadScanFindings(tx, reused)
+	if err != nil {
+		return err
+	}
+
+	// Link affected systems (deduplicate first - same asset may have multiple ports), record
+	// the initial status and build one finding per host and port
+	var links []models.VulnerabilityAffectedSystem
+	histories := make([]*models.VulnerabilityStatusHistory, 0, len(newVulns))
+	var findings, seenAgain []*models.VulnerabilityFinding
+	var findingHosts []ParsedHost
+	for i, vulnerability := range vulns {
+		linked := make(map[uuid.UUID]bool)
+		findingIndex := make(map[string]*models.VulnerabilityFinding)
 
-		for assetID := range uniqueAssetIDs {
-			link := &models.VulnerabilityAffectedSystem{
-				VulnerabilityID:  vulnerability.ID.String(),
-				AffectedSystemID: assetID.String(),
+		for j, host := range toImport[i].AffectedHosts {
+			assetID, ok := hostAssets[i][j]
+			if !ok {
+				continue
 			}
-			if err := tx.Create(link).Error; err != nil {
-				result.Warnings = append(result.Warnings,
-					fmt.Sprintf("Failed to link asset to vulnerability: %v", err))
+			state.scannedAssets[assetID] = true
+			if host.ScanTimestamp.After(state.scanDate) {
+				state.scanDate = host.ScanTimestamp
+			}
+
+			if !linked[assetID] {
+				linked[assetID] = true
+				links = append(links, models.VulnerabilityAffectedSystem{
+					VulnerabilityID:  vulnerability.ID.String(),
+					AffectedSystemID: assetID.String(),
+				})
+			}
+
+			// The same host and port reported twice is one finding seen at the later scan time
+			key := fmt.Sprintf("%s/%s/%s", assetID, host.Port, host.Protocol)
+			if existing, ok := findingIndex[key]; ok {
+				if host.ScanTimestamp.After(existing.LastSeen) {
+					existing.LastSeen = host.ScanTimestamp
+				}
+				result.TotalFindings++
+				result.UpdatedFindings++
+				result.Conflicts.Findings++
+				continue
+			}
+			finding := &models.VulnerabilityFinding{
+				VulnerabilityID:  vulnerability.ID,
+				AffectedSystemID: assetID,
+				Port:             host.Port,
+				Protocol:         host.Protocol,
+				ServiceName:      host.ServiceName,
+				PluginID:         toImport[i].PluginID,
+				PluginOutput:     truncate(host.PluginOutput, maxPluginOutput),
+				DetectedVersion:  host.DetectedVersion,
+				FixedVersion:     host.FixedVersion,
+				ScannerName:      state.source.Scanner,
+				Status:           models.FindingStatusOpen,
+				FirstDetected:    host.ScanTimestamp,
+				LastSeen:         host.ScanTimestamp,
+				CreatedBy:        state.createdByID,
 			}
+			findingIndex[key] = finding
+			if id, ok := existingFindings[findingKey(vulnerability.ID, key)]; ok {
+				finding.ID = id
+				seenAgain = append(seenAgain, finding)
+				continue
+			}
+			findings = append(findings, finding)
+			findingHosts = append(findingHosts, host)
 		}
 
-		// Create initial status history
-		statusHistory := &models.VulnerabilityStatusHistory{
+		if reused[vulnerability.ID] {
+			continue // Created by an earlier import of the scan
+		}
+		histories = append(histories, &models.VulnerabilityStatusHistory{
 			VulnerabilityID: vulnerability.ID,
 			OldStatus:       "",
 			NewStatus:       models.StatusOpen,
-			ChangedByID:     createdByID,
+			ChangedByID:     state.createdByID,
 			Notes:           "Imported from Nessus scan",
+		})
+	}
+
+	if len(links) > 0 {
+		if err := recordCreatedLinks(tx, state.jobID, links); err != nil {
+			return err
+		}
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&links, importBatchSize)
+		if created.Error != nil {
+			return fmt.Errorf("failed to link assets to vulnerabilities: %w", created.Error)
 		}
-		if err := tx.Create(statusHistory).Error; err != nil {
+		result.Conflicts.AssetLinks += len(links) - int(created.RowsAffected)
+	}
+
+	if len(histories) > 0 {
+		if err := tx.CreateInBatches(histories, importBatchSize).Error; err != nil {
+			return fmt.Errorf("failed to create status history: %w", err)
+		}
+	}
+
+	// Route the new vulnerabilities with the assignment rules
+	assigned, err := s.assignmentService.ApplyWithTx(tx, state.assignments, newVulns, state.createdByID)
+	if err != nil {
+		return err
+	}
+	result.AssignedVulnerabilities += assigned
+
+	seenIDs := make([]uuid.UUID, len(seenAgain))
+	for i, finding := range seenAgain {
+		seenIDs[i] = finding.ID
+	}
+	if err := recordFindingChanges(tx, state.jobID, models.ImportChangeSeen, seenIDs); err != nil {
+		return err
+	}
+	if err := s.markFindingsSeen(tx, seenAgain, state); err != nil {
+		return err
+	}
+	result.TotalFindings += len(seenAgain)
+	result.UpdatedFindings += len(seenAgain)
+	result.Diff.StillPresent += len(seenAgain)
+
+	if len(findings) > 0 {
+		for _, finding := range findings {
+			scanDate := finding.LastSeen
+			finding.IntegrationConfigID = state.source.IntegrationConfigID
+			finding.ScanID = state.source.ScanID
+			finding.ScanDate = &scanDate
+			finding.ImportJobID = &state.jobID
+		}
+		if err := tx.CreateInBatches(findings, importBatchSize).Error; err != nil {
+			return fmt.Errorf("failed to create findings: %w", err)
+		}
+		createdIDs := make([]uuid.UUID, len(findings))
+		for i, finding := range findings {
+			createdIDs[i] = finding.ID
+		}
+		if err := recordCreated(tx, state.jobID, models.ImportChangeFinding, createdIDs); err != nil {
+			return err
+		}
+	}
+	vulnByID := make(map[uuid.UUID]ParsedVulnerability, len(vulns))
+	for i, vulnerability := range vulns {
+		vulnByID[vulnerability.ID] = toImport[i]
+	}
+	for i, finding := range findings {
+		host := findingHosts[i]
+		parsedVuln := vulnByID[finding.VulnerabilityID]
+
+		// Auto-suppress findings matching a suppression rule
+		rule, err := s.suppressionService.ApplyWithTx(tx, state.suppressions, finding, SuppressionTarget{
+			PluginID:  parsedVuln.PluginID,
+			CVEID:     parsedVuln.CVEID,
+			AssetID:   finding.AffectedSystemID,
+			IPAddress: host.IPAddress,
+		}, state.source.Scanner+" import", state.createdByID)
+		if err != nil {
 			result.Warnings = append(result.Warnings,
-				fmt.Sprintf("Failed to create status history: %v", err))
+				fmt.Sprintf("Failed to apply suppression rule for asset %s: %v", host.IPAddress, err))
+		} else if rule != nil {
+			result.SuppressedFindings++
 		}
+	}
+	result.TotalFindings += len(findings)
+	result.CreatedFindings += len(findings)
+	result.Diff.New += len(findings)
 
-		// Create individual findings for each affected host
-		for _, pair := range hostAssetPairs {
-			finding := &models.VulnerabilityFinding{
-				VulnerabilityID:  vulnerability.ID,
-				AffectedSystemID: pair.AssetID,
-				Port:             pair.Host.Port,
-				Protocol:         pair.Host.Protocol,
-				ServiceName:      pair.Host.ServiceName,
-				PluginID:         parsedVuln.PluginID,
-				PluginOutput:     "", // Nessus output per host (not currently captured)
-				ScannerName:      "nessus",
-				Status:           models.FindingStatusOpen,
-				FirstDetected:    pair.Host.ScanTimestamp,
-				LastSeen:         pair.Host.ScanTimestamp,
-				CreatedBy:        createdByID,
-			}
+	result.ImportedVulnerabilities += len(vulns)
+	return nil
+}
 
-			// Check if finding already exists (for rescans) - use transaction
-			existingFinding, created, err := s.findingService.FindOrCreateFindingWithTx(tx, finding)
-			if err != nil {
-				result.Warnings = append(result.Warnings,
-					fmt.Sprintf("Failed to create finding for asset %s: %v", pair.Host.IPAddress, err))
+// loadScanVulnerabilities remembers the vulnerabilities earlier imports of the scan created
+// for the plugins of the batch, as the latest one reporting each plugin
+func loadScanVulnerabilities(tx *gorm.DB, parsed []ParsedVulnerability, state *nessusImportState) error {
+	var pluginIDs []string
+	for _, parsedVuln := range parsed {
+		if _, ok := state.scanVulns[parsedVuln.PluginID]; !ok && parsedVuln.PluginID != "" {
+			pluginIDs = append(pluginIDs, parsedVuln.PluginID)
+		}
+	}
+	if len(pluginIDs) == 0 {
+		return nil
+	}
+
+	var previous []struct {
+		PluginID        string
+		VulnerabilityID uuid.UUID
+	}
+	if err := scanFindings(tx, state.source).
+		Select("DISTINCT ON (vulnerability_findings.plugin_id) vulnerability_findings.plugin_id, vulnerability_findings.vulnerability_id").
+		Joins("JOIN vulnerabilities ON vulnerabilities.id = vulnerability_findings.vulnerability_id AND vulnerabilities.deleted_at IS NULL").
+		Where("vulnerability_findings.plugin_id IN ?", pluginIDs).
+		Order("vulnerability_findings.plugin_id, vulnerability_findings.last_seen DESC").
+		Scan(&previous).Error; err != nil {
+		return fmt.Errorf("failed to find vulnerabilities of earlier imports: %w", err)
+	}
+	for _, p := range previous {
+		state.scanVulns[p.PluginID] = p.VulnerabilityID
+	}
+	return nil
+}
+
+// findingKey identifies a finding by vulnerability, asset, port and protocol
+func findingKey(vulnerabilityID uuid.UUID, hostKey string) string {
+	return vulnerabilityID.String() + "/" + hostKey
+}
+
+// loadScanFindings loads the IDs of the findings of the reused vulnerabilities, by findingKey
+func loadScanFindings(tx *gorm.DB, reused map[uuid.UUID]bool) (map[string]uuid.UUID, error) {
+	existing := make(map[string]uuid.UUID)
+	if len(reused) == 0 {
+		return existing, nil
+	}
+	ids := make([]uuid.UUID, 0, len(reused))
+	for id := range reused {
+		ids = append(ids, id)
+	}
+
+	var findings []models.VulnerabilityFinding
+	if err := tx.Select("id", "vulnerability_id", "affected_system_id", "port", "protocol").
+		Where("vulnerability_id IN ?", ids).
+		Order("created_at").
+		Find(&findings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load findings of earlier imports: %w", err)
+	}
+	for _, finding := range findings {
+		key := findingKey(finding.VulnerabilityID, fmt.Sprintf("%s/%s/%s", finding.AffectedSystemID, finding.Port, finding.Protocol))
+		if _, ok := existing[key]; !ok {
+			existing[key] = finding.ID
+		}
+	}
+	return existing, nil
+}
+
+// markFindingsSeen records that findings reported again were seen by this import, with the
+// versions the scan detected, in one update per scan time and versions
+func (s *VulnerabilityImportService) markFindingsSeen(tx *gorm.DB, findings []*models.VulnerabilityFinding, state *nessusImportState) error {
+	type seenKey struct {
+		scanTime        int64
+		detectedVersion string
+		fixedVersion    string
+	}
+	bySeen := make(map[seenKey][]uuid.UUID)
+	scanTimes := make(map[seenKey]time.Time)
+	for _, finding := range findings {
+		key := seenKey{finding.LastSeen.UnixNano(), finding.DetectedVersion, finding.FixedVersion}
+		bySeen[key] = append(bySeen[key], finding.ID)
+		scanTimes[key] = finding.LastSeen
+	}
+
+	for key, ids := range bySeen {
+		seen := scanTimes[key]
+		updates := map[string]interface{}{
+			"last_seen":             gorm.Expr("GREATEST(last_seen, ?)", seen),
+			"integration_config_id": state.source.IntegrationConfigID,
+			"scan_id":               state.source.ScanID,
+			"scan_date":             seen,
+			"import_job_id":         state.jobID,
+			"clean_scans":           0,
+		}
+		// Scans without version details keep the versions recorded earlier
+		if key.detectedVersion != "" {
+			updates["detected_version"] = key.detectedVersion
+		}
+		if key.fixedVersion != "" {
+			updates["fixed_version"] = key.fixedVersion
+		}
+		if err := tx.Model(&models.VulnerabilityFinding{}).
+			Where("id IN ?", ids).
+			Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update findings seen again: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadExistingVulnerabilities marks the CVE IDs and titles of the batch that are already
+// stored as seen
+func (s *VulnerabilityImportService) loadExistingVulnerabilities(tx *gorm.DB, parsed []ParsedVulnerability, state *nessusImportState) error {
+	var cveIDs, titles []string
+	for _, parsedVuln := range parsed {
+		if parsedVuln.CVEID != "" {
+			cveIDs = append(cveIDs, parsedVuln.CVEID)
+		} else {
+			titles = append(titles, parsedVuln.Title)
+		}
+	}
+
+	var existing []struct {
+		CVEID string
+		Title string
+	}
+	query := tx.Model(&models.Vulnerability{}).Select("cve_id, title")
+	switch {
+	case len(cveIDs) > 0 && len(titles) > 0:
+		query = query.Where("cve_id IN ? OR title IN ?", cveIDs, titles)
+	case len(cveIDs) > 0:
+		query = query.Where("cve_id IN ?", cveIDs)
+	default:
+		query = query.Where("title IN ?", titles)
+	}
+	if err := query.Scan(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check duplicate vulnerabilities: %w", err)
+	}
+	for _, v := range existing {
+		if v.CVEID != "" {
+			state.seen["cve:"+v.CVEID] = true
+		}
+		state.seen["title:"+v.Title] = true
+	}
+	return nil
+}
+
+// resolveAssets finds or creates the asset of every host in the batch, returning the asset ID
+// by vulnerability and host index. Hosts match an existing asset of their environment by IP
+// address or hostname; assets missing are created with one multi-row insert.
+func (s *VulnerabilityImportService) resolveAssets(
+	tx *gorm.DB,
+	vulns []ParsedVulnerability,
+	state *nessusImportState,
+	result *ImportResult,
+) ([]map[int]uuid.UUID, error) {
+	// Assets each host would be created as, by host
+	wanted := make(map[importHostKey]*models.AffectedSystem)
+	var order []importHostKey
+	var ips, hostnames []string
+	for _, vuln := range vulns {
+		for _, host := range vuln.AffectedHosts {
+			asset := newImportedAsset(host, state.createdByID, state.networkRanges, state.importRules)
+			key := importHostKey{asset.IPAddress, asset.Hostname, asset.Environment}
+			if _, ok := state.assets[key]; ok {
+				continue
+			}
+			if _, ok := wanted[key]; ok {
 				continue
 			}
+			wanted[key] = asset
+			order = append(order, key)
+			ips = append(ips, asset.IPAddress)
+			hostnames = append(hostnames, asset.Hostname)
+		}
+	}
 
-			result.TotalFindings++
-			if created {
-				result.CreatedFindings++
-			} else {
-				result.UpdatedFindings++
-				utils.Logger.Debug().
-					Str("finding_id", existingFinding.ID.String()).
-					Msg("Updated existing finding last_seen timestamp")
+	resolved := make(map[importHostKey]*models.AffectedSystem, len(order))
+	created := make(map[importHostKey]bool)
+	invalid := make(map[importHostKey]error)
+	if len(order) > 0 {
+		existing, err := findImportedAssets(tx, ips, hostnames)
+		if err != nil {
+			return nil, err
+		}
+
+		// Hosts sharing an IP address or hostname with another new host share its asset
+		var newAssets []*models.AffectedSystem
+		for _, key := range order {
+			if asset := matchImportedAsset(existing, key); asset != nil {
+				resolved[key] = asset
+				continue
+			}
+			if asset := matchImportedAsset(newAssets, key); asset != nil {
+				resolved[key] = asset
+				continue
+			}
+			asset := wanted[key]
+			if err := asset.BeforeCreate(tx); err != nil {
+				invalid[key] = err
+				continue
 			}
+			// IDs are set here so rows skipped on conflict cannot shift the returned ones
+			asset.ID = uuid.New()
+			newAssets = append(newAssets, asset)
+			resolved[key] = asset
+			created[key] = true
 		}
 
-		result.ImportedVulnerabilities++
+		if len(newAssets) > 0 {
+			newIDs := make([]uuid.UUID, len(newAssets))
+			for i, asset := range newAssets {
+				newIDs[i] = asset.ID
+			}
+			inserted := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(newAssets, importBatchSize)
+			if inserted.Error != nil {
+				return nil, fmt.Errorf("failed to create assets: %w", inserted.Error)
+			}
+			if int(inserted.RowsAffected) < len(newAssets) {
+				// Assets created by another import meanwhile were skipped; use theirs
+				if err := s.replaceSkippedAssets(tx, newAssets, result); err != nil {
+					return nil, err
+				}
+			}
+			var createdIDs []uuid.UUID
+			for i, asset := range newAssets {
+				if asset.ID == newIDs[i] {
+					createdIDs = append(createdIDs, asset.ID)
+				}
+			}
+			if err := recordCreated(tx, state.jobID, models.ImportChangeAsset, createdIDs); err != nil {
+				return nil, err
+			}
+		}
+		for key, asset := range resolved {
+			state.assets[key] = asset.ID
+		}
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return nil, fmt.Errorf("failed to commit import transaction: %w", err)
-	}
+	hostAssets := make([]map[int]uuid.UUID, len(vulns))
+	for i, vuln := range vulns {
+		hostAssets[i] = make(map[int]uuid.UUID, len(vuln.AffectedHosts))
+		for j, host := range vuln.AffectedHosts {
+			asset := newImportedAsset(host, state.createdByID, state.networkRanges, state.importRules)
+			key := importHostKey{asset.IPAddress, asset.Hostname, asset.Environment}
+			assetID, ok := state.assets[key]
+			if !ok {
+				result.Errors = append(result.Errors,
+					fmt.Sprintf("Failed to create asset %s: %v", host.IPAddress, invalid[key]))
+				continue
+			}
+			hostAssets[i][j] = assetID
 
-	// Build summary
-	successRate := 0.0
-	if result.TotalVulnerabilities > 0 {
-		successRate = float64(result.ImportedVulnerabilities) / float64(result.TotalVulnerabilities) * 100
+			result.TotalAssets++
+			if created[key] {
+				result.CreatedAssets++
+				delete(created, key) // Later occurrences of the host find the asset
+			} else {
+				result.ExistingAssets++
+			}
+		}
 	}
+	return hostAssets, nil
+}
 
-	result.Summary = map[string]interface{}{
-		"success_rate": successRate,
-		"has_errors":   len(result.Errors) > 0,
-		"has_warnings": len(result.Warnings) > 0,
+// replaceSkippedAssets points the assets whose insert was skipped on conflict at the rows
+// they conflicted with
+func (s *VulnerabilityImportService) replaceSkippedAssets(tx *gorm.DB, assets []*models.AffectedSystem, result *ImportResult) error {
+	ids := make([]uuid.UUID, len(assets))
+	var ips, hostnames []string
+	for i, asset := range assets {
+		ids[i] = asset.ID
+		ips = append(ips, asset.IPAddress)
+		hostnames = append(hostnames, asset.Hostname)
+	}
+	var insertedIDs []uuid.UUID
+	if err := tx.Model(&models.AffectedSystem{}).Where("id IN ?", ids).Pluck("id", &insertedIDs).Error; err != nil {
+		return fmt.Errorf("failed to check created assets: %w", err)
+	}
+	inserted := make(map[uuid.UUID]bool, len(insertedIDs))
+	for _, id := range insertedIDs {
+		inserted[id] = true
 	}
 
-	utils.Logger.Info().
-		Int("total", result.TotalVulnerabilities).
-		Int("imported", result.ImportedVulnerabilities).
-		Int("skipped", result.SkippedVulnerabilities).
-		Int("created_assets", result.CreatedAssets).
-		Msg("Nessus import completed")
-
-	return result, nil
+	existing, err := findImportedAssets(tx, ips, hostnames)
+	if err != nil {
+		return err
+	}
+	for _, asset := range assets {
+		if inserted[asset.ID] {
+			continue
+		}
+		match := matchImportedAsset(existing, importHostKey{asset.IPAddress, asset.Hostname, asset.Environment})
+		if match == nil {
+			return fmt.Errorf("failed to create asset %s", asset.IPAddress)
+		}
+		asset.ID = match.ID
+		result.Conflicts.Assets++
+	}
+	return nil
 }
 
-// findOrCreateAsset finds an existing asset or creates a new one
-func (s *VulnerabilityImportService) findOrCreateAsset(
-	tx *gorm.DB,
-	host ParsedHost,
-	createdByID uuid.UUID,
-) (uuid.UUID, bool, error) {
-	// Try to find existing asset by IP or hostname
-	var existingAsset models.AffectedSystem
+// findImportedAssets loads the identifiers of the assets with any of the IP addresses or hostnames
+func findImportedAssets(tx *gorm.DB, ips, hostnames []string) ([]*models.AffectedSystem, error) {
+	var existing []*models.AffectedSystem
+	if err := tx.Select("id", "ip_address", "hostname", "environment").
+		Where("ip_address IN ? OR hostname IN ?", ips, hostnames).
+		Order("created_at").
+		Find(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to find existing assets: %w", err)
+	}
+	return existing, nil
+}
 
-	query := tx.Where("(ip_address = ? OR hostname = ?) AND environment = ?",
-		host.IPAddress, host.Hostname, models.EnvProduction)
+// matchImportedAsset returns the first asset of the host's environment with its IP address or hostname
+func matchImportedAsset(assets []*models.AffectedSystem, key importHostKey) *models.AffectedSystem {
+	for _, asset := range assets {
+		if asset.Environment == key.Environment && (asset.IPAddress == key.IPAddress || asset.Hostname == key.Hostname) {
+			return asset
+		}
+	}
+	return nil
+}
 
-	if err := query.First(&existingAsset).Error; err == nil {
-		// Asset exists
-		return existingAsset.ID, false, nil
-	} else if err != gorm.ErrRecordNotFound {
-		return uuid.Nil, false, err
+// newImportedAsset builds the asset created for a scanned host. The host's environment,
+// location and owner come from the most specific network range containing its IP, and import
+// rules override its environment and owner; hosts neither classifies default to PRODUCTION,
+// owned by the importing user.
+func newImportedAsset(host ParsedHost, createdByID uuid.UUID, networkRanges *NetworkRangeMatcher, importRules *ImportRuleMatcher) *models.AffectedSystem {
+	environment := models.EnvProduction
+	ownerID := &createdByID
+	var ownerTeamID *uuid.UUID
+	location := ""
+	if r := networkRanges.Match(host.IPAddress); r != nil {
+		environment = r.Environment
+		location = r.Location
+		ownerTeamID = r.OwnerTeamID
+		if r.OwnerID != nil {
+			ownerID = r.OwnerID
+		}
+	}
+	classification := importRules.Classify(host)
+	if classification.EnvironmentRule != nil {
+		environment = classification.Environment
+	}
+	if classification.OwnerRule != nil {
+		ownerID = &createdByID
+		if classification.OwnerID != nil {
+			ownerID = classification.OwnerID
+		}
+		ownerTeamID = classification.OwnerTeamID
 	}
 
-	// Create new asset
 	systemType := models.SystemTypeServer
 	if host.ServiceName == "www" || host.ServiceName == "http" || host.ServiceName == "https" {
 		systemType = models.SystemTypeApplication
@@ -266,30 +1352,83 @@ func (s *VulnerabilityImportService) findOrCreateAsset(
 	}
 
 	criticality := models.CriticalityMedium
-	newAsset := &models.AffectedSystem{
+	return &models.AffectedSystem{
 		Hostname:     host.Hostname,
 		IPAddress:    host.IPAddress,
 		SystemType:   systemType,
-		Environment:  models.EnvProduction,
+		Environment:  environment,
 		Status:       models.StatusActive,
 		Criticality:  &criticality,
 		Description:  description,
-		OwnerID:      &createdByID,
+		OwnerID:      ownerID,
+		OwnerTeamID:  ownerTeamID,
+		Location:     location,
+	}
+}
+
+// ListImportJobs returns import jobs, newest first, optionally only those of one scan
+// (scanID) or integration
+func (s *VulnerabilityImportService) ListImportJobs(integrationConfigID *uuid.UUID, scanID string, page, perPage int) ([]models.ImportJob, int64, error) {
+	query := s.db.Model(&models.ImportJob{})
+	if integrationConfigID != nil {
+		query = query.Where("integration_config_id = ?", *integrationConfigID)
+	}
+	if scanID != "" {
+		query = query.Where("scan_id = ?", scanID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count import jobs: %w", err)
 	}
 
-	if err := tx.Create(newAsset).Error; err != nil {
-		return uuid.Nil, false, err
+	jobs := []models.ImportJob{}
+	if err := query.Order("started_at DESC").
+		Limit(perPage).
+		Offset((page - 1) * perPage).
+		Find(&jobs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list import jobs: %w", err)
 	}
+	return jobs, total, nil
+}
+
+// GetImportJob returns an import job with its outcome
+func (s *VulnerabilityImportService) GetImportJob(id uuid.UUID) (*models.ImportJob, error) {
+	var job models.ImportJob
+	err := s.db.First(&job, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("import job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import job: %w", err)
+	}
+	return &job, nil
+}
+
+// nessusUploadLimit is the largest .nessus file uploads accept, in bytes
+var nessusUploadLimit int64 = 200 * 1024 * 1024
 
-	return newAsset.ID, true, nil
+// SetNessusUploadLimit sets the largest .nessus file uploads accept, in MB; values below one
+// keep the current limit
+func SetNessusUploadLimit(megabytes int) {
+	if megabytes > 0 {
+		nessusUploadLimit = int64(megabytes) * 1024 * 1024
+	}
+}
+
+// CheckNessusFileSize rejects an uploaded .nessus file larger than the upload limit, before it
+// is read
+func (s *VulnerabilityImportService) CheckNessusFileSize(size int64) error {
+	if size > nessusUploadLimit {
+		return fmt.Errorf("file size exceeds maximum allowed size of %dMB", nessusUploadLimit/(1024*1024))
+	}
+	return nil
 }
 
 // ValidateNessusFile performs basic validation on uploaded file
 func (s *VulnerabilityImportService) ValidateNessusFile(data []byte, filename string) error {
-	// Check file size (max 50MB)
-	maxSize := 50 * 1024 * 1024
-	if len(data) > maxSize {
-		return fmt.Errorf("file size exceeds maximum allowed size of 50MB")
+	if err := s.CheckNessusFileSize(int64(len(data))); err != nil {
+		return err
 	}
 
 	// Check if it's XML
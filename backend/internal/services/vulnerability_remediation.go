@@ -0,0 +1,178 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Remediation actions suggested for an affected system
+const (
+	RemediationActionUpgrade    = "upgrade"          // Upgrade to the fixed version
+	RemediationActionPatch      = "apply_patch"      // Apply the vendor patch
+	RemediationActionWorkaround = "apply_workaround" // No fix is known; apply the workaround
+	RemediationActionReview     = "review"           // No fix or workaround is known
+)
+
+var (
+	// Version lines of scanner plugin output, e.g. "Installed version : 2.4.41" and
+	// "Fixed version : 2.4.58", or "Remote package installed : openssl-1.0.2k-19.el7" and
+	// "Should be : openssl-1.0.2k-26.el7_9" for package checks
+	installedVersionPattern = regexp.MustCompile(`(?im)^\s*(?:installed version|remote version|current version|version installed|reported version|product version|installed package|remote package installed)\s*:\s*(.+?)\s*$`)
+	fixedVersionPattern     = regexp.MustCompile(`(?im)^\s*(?:fixed version|minimum fixed version|version fixed|fixed package|should be)\s*:\s*(.+?)\s*$`)
+	// Upgrade advice in a plugin solution, e.g. "Upgrade to Apache version 2.4.58 or later."
+	solutionVersionPattern = regexp.MustCompile(`(?i)\b(?:upgrade|update)\b[^\n]*?\bversions?\s+v?(\d[\w.\-+~:]*\w|\d)`)
+)
+
+// PluginOutputVersions extracts the installed and fixed versions from the plugin output of a
+// finding. Either is empty when the output does not report it.
+func PluginOutputVersions(output string) (installed, fixed string) {
+	if m := installedVersionPattern.FindStringSubmatch(output); m != nil {
+		installed = truncate(m[1], 100)
+	}
+	if m := fixedVersionPattern.FindStringSubmatch(output); m != nil {
+		fixed = truncate(m[1], 100)
+	}
+	return installed, fixed
+}
+
+// SolutionFixedVersion extracts the version a plugin solution recommends upgrading to, or
+// returns empty
+func SolutionFixedVersion(solution string) string {
+	if m := solutionVersionPattern.FindStringSubmatch(solution); m != nil {
+		return truncate(m[1], 100)
+	}
+	return ""
+}
+
+// advisoryURL picks the advisory of a vulnerability: the Microsoft KB article of the first KB
+// reference, else the first web link
+func advisoryURL(kbs []string, links []string) string {
+	for _, kb := range kbs {
+		if kb = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(kb)), "KB"); kb != "" {
+			return "https://support.microsoft.com/help/" + kb
+		}
+	}
+	for _, link := range links {
+		link = strings.TrimSpace(link)
+		if strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") {
+			return truncate(link, 500)
+		}
+	}
+	return ""
+}
+
+// SystemRemediation is the remediation of a vulnerability on one unpatched affected system
+type SystemRemediation struct {
+	AffectedSystemID uuid.UUID `json:"affected_system_id"`
+	Hostname         string    `json:"hostname,omitempty"`
+	IPAddress        string    `json:"ip_address,omitempty"`
+	DetectedVersions []string  `json:"detected_versions"`       // Versions the scanners found installed
+	FixedVersion     string    `json:"fixed_version,omitempty"` // Version to upgrade this system to
+	OpenFindings     int       `json:"open_findings"`
+	Action           string    `json:"action"`
+}
+
+// RemediationGuidance is the patch and workaround guidance of a vulnerability with the action
+// each unpatched affected system needs
+type RemediationGuidance struct {
+	VulnerabilityID           uuid.UUID           `json:"vulnerability_id"`
+	CVEID                     string              `json:"cve_id,omitempty"`
+	PatchAvailable            bool                `json:"patch_available"`
+	AdvisoryURL               string              `json:"advisory_url,omitempty"`
+	FixedVersion              string              `json:"fixed_version,omitempty"`
+	Workaround                string              `json:"workaround,omitempty"`
+	MitigationRecommendations string              `json:"mitigation_recommendations,omitempty"`
+	Systems                   []SystemRemediation `json:"systems"`
+}
+
+// GetRemediationGuidance aggregates the remediation of a vulnerability per unpatched affected
+// system, from the versions its findings detected on that system
+func (s *VulnerabilityService) GetRemediationGuidance(id uuid.UUID) (*RemediationGuidance, error) {
+	var vulnerability models.Vulnerability
+	if err := s.db.First(&vulnerability, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("vulnerability not found")
+		}
+		return nil, fmt.Errorf("failed to get vulnerability: %w", err)
+	}
+
+	var systems []SystemRemediation
+	if err := s.db.Model(&models.AffectedSystem{}).
+		Select("affected_systems.id AS affected_system_id, affected_systems.hostname, affected_systems.ip_address").
+		Joins("JOIN vulnerability_affected_systems ON vulnerability_affected_systems.affected_system_id = affected_systems.id").
+		Where("vulnerability_affected_systems.vulnerability_id = ? AND vulnerability_affected_systems.patched_at IS NULL", id).
+		Order("affected_systems.hostname, affected_systems.ip_address").
+		Scan(&systems).Error; err != nil {
+		return nil, fmt.Errorf("failed to load affected systems: %w", err)
+	}
+
+	var findings []struct {
+		AffectedSystemID uuid.UUID
+		DetectedVersion  string
+		FixedVersion     string
+	}
+	if err := s.db.Model(&models.VulnerabilityFinding{}).
+		Select("affected_system_id, detected_version, fixed_version").
+		Where("vulnerability_id = ? AND status = ?", id, models.FindingStatusOpen).
+		Order("last_seen DESC").
+		Scan(&findings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load findings: %w", err)
+	}
+
+	guidance := &RemediationGuidance{
+		VulnerabilityID:           vulnerability.ID,
+		CVEID:                     vulnerability.CVEID,
+		PatchAvailable:            vulnerability.PatchAvailable,
+		AdvisoryURL:               vulnerability.AdvisoryURL,
+		FixedVersion:              vulnerability.FixedVersion,
+		Workaround:                vulnerability.Workaround,
+		MitigationRecommendations: vulnerability.MitigationRecommendations,
+		Systems:                   make([]SystemRemediation, 0, len(systems)),
+	}
+	for _, system := range systems {
+		detected := map[string]bool{}
+		system.DetectedVersions = []string{}
+		for _, finding := range findings {
+			if finding.AffectedSystemID != system.AffectedSystemID {
+				continue
+			}
+			system.OpenFindings++
+			if finding.DetectedVersion != "" && !detected[finding.DetectedVersion] {
+				detected[finding.DetectedVersion] = true
+				system.DetectedVersions = append(system.DetectedVersions, finding.DetectedVersion)
+			}
+			// Findings are newest first, so the latest scan's advice wins
+			if system.FixedVersion == "" {
+				system.FixedVersion = finding.FixedVersion
+			}
+		}
+		sort.Strings(system.DetectedVersions)
+		if system.FixedVersion == "" {
+			system.FixedVersion = guidance.FixedVersion
+		}
+		system.Action = remediationAction(system.FixedVersion, guidance.PatchAvailable, guidance.Workaround)
+		guidance.Systems = append(guidance.Systems, system)
+	}
+	return guidance, nil
+}
+
+// remediationAction suggests how to remediate a system: upgrading when a fixed version is known,
+// else patching, else the workaround
+func remediationAction(fixedVersion string, patchAvailable bool, workaround string) string {
+	switch {
+	case fixedVersion != "":
+		return RemediationActionUpgrade
+	case patchAvailable:
+		return RemediationActionPatch
+	case workaround != "":
+		return RemediationActionWorkaround
+	default:
+		return RemediationActionReview
+	}
+}
@@ -1,21 +1,28 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/tenant"
 	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/policy"
+	"github.com/cyops/cyops-backend/pkg/taxonomy"
 	"github.com/cyops/cyops-backend/pkg/utils"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // VulnerabilityService handles vulnerability-related operations
 type VulnerabilityService struct {
 	db           *gorm.DB
 	assetService *AssetService
+	teamService  *TeamService
 }
 
 // NewVulnerabilityService creates a new vulnerability service
@@ -24,6 +31,16 @@ func NewVulnerabilityService() *VulnerabilityService {
 	return &VulnerabilityService{
 		db:           db,
 		assetService: NewAssetService(db),
+		teamService:  NewTeamService(db),
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx (tenant scoping, tracing and cancellation)
+func (s *VulnerabilityService) WithContext(ctx context.Context) *VulnerabilityService {
+	return &VulnerabilityService{
+		db:           s.db.WithContext(ctx),
+		assetService: s.assetService.WithContext(ctx),
+		teamService:  s.teamService.WithContext(ctx),
 	}
 }
 
@@ -43,12 +60,18 @@ type CreateVulnerabilityRequest struct {
 	CVSSScore                 *float64
 	CVSSVector                string
 	CVEID                     string
+	CWEIDs                    []string
 	Source                    string
 	DiscoveryDate             time.Time
 	ImpactAssessment          string
 	StepsToReproduce          string
 	MitigationRecommendations string
+	PatchAvailable            bool
+	AdvisoryURL               string
+	FixedVersion              string
+	Workaround                string
 	AssignedToID              *uuid.UUID
+	OwnerTeamID               *uuid.UUID
 	AffectedSystemIDs         []uuid.UUID
 	NewAffectedSystems        []NewAffectedSystemData // For auto-creation
 }
@@ -76,16 +99,39 @@ func (s *VulnerabilityService) CreateVulnerability(req CreateVulnerabilityReques
 		CVSSScore:                 req.CVSSScore,
 		CVSSVector:                req.CVSSVector,
 		CVEID:                     req.CVEID,
+		CWEIDs:                    req.CWEIDs,
 		Status:                    models.StatusOpen,
 		Source:                    req.Source,
 		DiscoveryDate:             req.DiscoveryDate,
 		ImpactAssessment:          req.ImpactAssessment,
 		StepsToReproduce:          req.StepsToReproduce,
 		MitigationRecommendations: req.MitigationRecommendations,
+		PatchAvailable:            req.PatchAvailable,
+		AdvisoryURL:               req.AdvisoryURL,
+		FixedVersion:              req.FixedVersion,
+		Workaround:                req.Workaround,
 		CreatedByID:               createdByID,
 		AssignedToID:              req.AssignedToID,
+		OwnerTeamID:               req.OwnerTeamID,
+	}
+
+	if req.OwnerTeamID != nil {
+		if err := s.teamService.Exists(*req.OwnerTeamID); err != nil {
+			return nil, err
+		}
 	}
 
+	if err := s.authorizeCreate(req); err != nil {
+		return nil, err
+	}
+
+	// Flag CVEs with a known public exploit
+	exploited, err := knownExploitCVEs(s.db, []string{req.CVEID})
+	if err != nil {
+		return nil, err
+	}
+	vulnerability.HasExploit = exploited[req.CVEID]
+
 	// Start transaction
 	tx := s.db.Begin()
 	defer func() {
@@ -101,6 +147,12 @@ func (s *VulnerabilityService) CreateVulnerability(req CreateVulnerabilityReques
 		return nil, fmt.Errorf("failed to create vulnerability: %w", err)
 	}
 
+	// Map the vulnerability to OWASP and ATT&CK from its weaknesses
+	if err := addInferredClassifications(tx, map[uuid.UUID]taxonomy.Mapping{vulnerability.ID: taxonomy.Infer(req.CWEIDs, "")}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Associate existing affected systems if provided
 	if len(req.AffectedSystemIDs) > 0 {
 		var affectedSystems []models.AffectedSystem
@@ -121,6 +173,20 @@ func (s *VulnerabilityService) CreateVulnerability(req CreateVulnerabilityReques
 	// Note: We'll handle this in CreateVulnerabilityWithAutoAssets for Phase 4
 	// This method maintains backward compatibility
 
+	// Route the vulnerability with the assignment rules when it was created without an analyst or team
+	if err := s.applyAssignmentRules(tx, vulnerability, createdByID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Route a notification to the owning team
+	if vulnerability.OwnerTeamID != nil {
+		if err := s.notifyOwnerTeam(tx, vulnerability, models.NotificationTypeTeamAssigned, "New vulnerability assigned to your team", &createdByID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to commit transaction")
@@ -128,7 +194,7 @@ func (s *VulnerabilityService) CreateVulnerability(req CreateVulnerabilityReques
 	}
 
 	// Load associations for response
-	if err := s.db.Preload("CreatedBy").Preload("AssignedTo").Preload("AffectedSystems").First(vulnerability, vulnerability.ID).Error; err != nil {
+	if err := s.db.Preload("CreatedBy").Preload("AssignedTo").Preload("OwnerTeam").Preload("AffectedSystems").First(vulnerability, vulnerability.ID).Error; err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to load vulnerability with associations")
 		return nil, fmt.Errorf("failed to load vulnerability: %w", err)
 	}
@@ -147,15 +213,38 @@ func (s *VulnerabilityService) CreateVulnerabilityWithAutoAssets(req CreateVulne
 		CVSSScore:                 req.CVSSScore,
 		CVSSVector:                req.CVSSVector,
 		CVEID:                     req.CVEID,
+		CWEIDs:                    req.CWEIDs,
 		Status:                    models.StatusOpen,
 		Source:                    req.Source,
 		DiscoveryDate:             req.DiscoveryDate,
 		ImpactAssessment:          req.ImpactAssessment,
 		StepsToReproduce:          req.StepsToReproduce,
 		MitigationRecommendations: req.MitigationRecommendations,
+		PatchAvailable:            req.PatchAvailable,
+		AdvisoryURL:               req.AdvisoryURL,
+		FixedVersion:              req.FixedVersion,
+		Workaround:                req.Workaround,
 		CreatedByID:               createdByID,
 		AssignedToID:              req.AssignedToID,
+		OwnerTeamID:               req.OwnerTeamID,
+	}
+
+	if req.OwnerTeamID != nil {
+		if err := s.teamService.Exists(*req.OwnerTeamID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.authorizeCreate(req); err != nil {
+		return nil, err
+	}
+
+	// Flag CVEs with a known public exploit
+	exploited, err := knownExploitCVEs(s.db, []string{req.CVEID})
+	if err != nil {
+		return nil, err
 	}
+	vulnerability.HasExploit = exploited[req.CVEID]
 
 	// Start transaction
 	tx := s.db.Begin()
@@ -172,6 +261,12 @@ func (s *VulnerabilityService) CreateVulnerabilityWithAutoAssets(req CreateVulne
 		return nil, fmt.Errorf("failed to create vulnerability: %w", err)
 	}
 
+	// Map the vulnerability to OWASP and ATT&CK from its weaknesses
+	if err := addInferredClassifications(tx, map[uuid.UUID]taxonomy.Mapping{vulnerability.ID: taxonomy.Infer(req.CWEIDs, "")}); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Track auto-created assets
 	autoCreatedAssets := []AutoCreatedAsset{}
 
@@ -238,6 +333,20 @@ func (s *VulnerabilityService) CreateVulnerabilityWithAutoAssets(req CreateVulne
 		}
 	}
 
+	// Route the vulnerability with the assignment rules when it was created without an analyst or team
+	if err := s.applyAssignmentRules(tx, vulnerability, createdByID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Route a notification to the owning team
+	if vulnerability.OwnerTeamID != nil {
+		if err := s.notifyOwnerTeam(tx, vulnerability, models.NotificationTypeTeamAssigned, "New vulnerability assigned to your team", &createdByID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to commit transaction")
@@ -245,7 +354,7 @@ func (s *VulnerabilityService) CreateVulnerabilityWithAutoAssets(req CreateVulne
 	}
 
 	// Load associations for response
-	if err := s.db.Preload("CreatedBy").Preload("AssignedTo").Preload("AffectedSystems").First(vulnerability, vulnerability.ID).Error; err != nil {
+	if err := s.db.Preload("CreatedBy").Preload("AssignedTo").Preload("OwnerTeam").Preload("AffectedSystems").First(vulnerability, vulnerability.ID).Error; err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to load vulnerability with associations")
 		return nil, fmt.Errorf("failed to load vulnerability: %w", err)
 	}
@@ -258,16 +367,34 @@ func (s *VulnerabilityService) CreateVulnerabilityWithAutoAssets(req CreateVulne
 
 // ListVulnerabilitiesRequest represents a list request with filters
 type ListVulnerabilitiesRequest struct {
-	Page       int
-	Limit      int
-	Severity   []models.VulnerabilitySeverity
-	Status     []models.VulnerabilityStatus
-	Search     string
-	AssignedTo *uuid.UUID
-	CreatedBy  *uuid.UUID
-	AssetID    *uuid.UUID
-	SortBy     string
-	SortOrder  string
+	Page         int
+	Limit        int
+	Severity     []models.VulnerabilitySeverity
+	Status       []models.VulnerabilityStatus
+	Search       string
+	AssignedTo   *uuid.UUID
+	OwnerTeamID  *uuid.UUID
+	CreatedBy    *uuid.UUID
+	AssetID      *uuid.UUID
+	AssetGroupID *uuid.UUID // Not indexed; always served from Postgres
+	Tags         []string   // Vulnerabilities must carry every tag
+	OWASP        []string   // Classified under any of these OWASP Top 10 categories; not indexed
+	ATTACK       []string   // Classified under any of these ATT&CK techniques or their sub-techniques; not indexed
+	HasExploit   *bool      // A public exploit is (or is not) known; not indexed
+	SortBy       string
+	SortOrder    string
+	OrgID        *uuid.UUID // Set from the request context; only used by the search index
+	Fieldset     *Fieldset  // Columns and relations to load (?fields=, ?include=); nil loads the defaults
+}
+
+// vulnerabilityListPreloads are the relations loaded for vulnerability lists by default
+func vulnerabilityListPreloads(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("CreatedBy").
+		Preload("AssignedTo").
+		Preload("OwnerTeam").
+		Preload("Tags").
+		Preload("Classifications")
 }
 
 // ListVulnerabilities returns a paginated list of vulnerabilities
@@ -275,6 +402,18 @@ func (s *VulnerabilityService) ListVulnerabilities(req ListVulnerabilitiesReques
 	var vulnerabilities []models.Vulnerability
 	var total int64
 
+	// Route through the search index when configured, falling back to Postgres on any error
+	if idx := ActiveSearchIndex(); idx != nil && req.AssetGroupID == nil && len(req.OWASP) == 0 && len(req.ATTACK) == 0 && req.HasExploit == nil {
+		if orgID, ok := tenant.OrgFromContext(s.db.Statement.Context); ok {
+			req.OrgID = &orgID
+		}
+		vulns, total, err := s.listVulnerabilitiesFromIndex(idx, req)
+		if err == nil {
+			return vulns, total, nil
+		}
+		utils.Logger.Warn().Err(err).Msg("Search index query failed, falling back to Postgres")
+	}
+
 	// Build query
 	query := s.db.Model(&models.Vulnerability{})
 
@@ -296,6 +435,10 @@ func (s *VulnerabilityService) ListVulnerabilities(req ListVulnerabilitiesReques
 		query = query.Where("assigned_to_id = ?", *req.AssignedTo)
 	}
 
+	if req.OwnerTeamID != nil {
+		query = query.Where("owner_team_id = ?", *req.OwnerTeamID)
+	}
+
 	if req.CreatedBy != nil {
 		query = query.Where("created_by_id = ?", *req.CreatedBy)
 	}
@@ -306,6 +449,35 @@ func (s *VulnerabilityService) ListVulnerabilities(req ListVulnerabilitiesReques
 			Where("vulnerability_affected_systems.affected_system_id = ?", *req.AssetID)
 	}
 
+	// Filter by asset group membership
+	if req.AssetGroupID != nil {
+		query = query.Where("vulnerabilities.id IN (?)", s.db.Model(&models.VulnerabilityAffectedSystem{}).
+			Select("vulnerability_id").
+			Where("affected_system_id IN (?)", AssetGroupMemberIDs(s.db, *req.AssetGroupID)))
+	}
+
+	// Filter by tags (AND logic)
+	if len(req.Tags) > 0 {
+		query = query.Where("vulnerabilities.id IN (?)", s.db.Model(&models.VulnerabilityTag{}).
+			Select("vulnerability_id").
+			Where("tag IN ?", req.Tags).
+			Group("vulnerability_id").
+			Having("COUNT(DISTINCT tag) = ?", len(req.Tags)))
+	}
+
+	// Filter by OWASP Top 10 category and ATT&CK technique
+	if len(req.OWASP) > 0 {
+		query = query.Where("vulnerabilities.id IN (?)", classifiedVulnerabilityIDs(s.db, taxonomy.FrameworkOWASP, req.OWASP))
+	}
+	if len(req.ATTACK) > 0 {
+		query = query.Where("vulnerabilities.id IN (?)", classifiedVulnerabilityIDs(s.db, taxonomy.FrameworkATTACK, req.ATTACK))
+	}
+
+	// Filter by known public exploit
+	if req.HasExploit != nil {
+		query = query.Where("vulnerabilities.has_exploit = ?", *req.HasExploit)
+	}
+
 	// Count total
 	if err := query.Count(&total).Error; err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to count vulnerabilities")
@@ -335,9 +507,7 @@ func (s *VulnerabilityService) ListVulnerabilities(req ListVulnerabilitiesReques
 	offset := (page - 1) * limit
 
 	// Fetch vulnerabilities with associations
-	if err := query.
-		Preload("CreatedBy").
-		Preload("AssignedTo").
+	if err := req.Fieldset.Apply(query, vulnerabilityListPreloads).
 		Offset(offset).
 		Limit(limit).
 		Find(&vulnerabilities).Error; err != nil {
@@ -348,17 +518,65 @@ func (s *VulnerabilityService) ListVulnerabilities(req ListVulnerabilitiesReques
 	return vulnerabilities, total, nil
 }
 
-// GetVulnerabilityByID retrieves a vulnerability by ID with all associations
-func (s *VulnerabilityService) GetVulnerabilityByID(id uuid.UUID) (*models.Vulnerability, error) {
-	var vulnerability models.Vulnerability
+// listVulnerabilitiesFromIndex resolves a list request against the search index and hydrates from Postgres
+func (s *VulnerabilityService) listVulnerabilitiesFromIndex(idx *SearchIndexService, req ListVulnerabilitiesRequest) ([]models.Vulnerability, int64, error) {
+	page := 1
+	if req.Page > 0 {
+		page = req.Page
+	}
+	limit := 50
+	if req.Limit > 0 && req.Limit <= 100 {
+		limit = req.Limit
+	}
+
+	ids, total, err := idx.SearchVulnerabilityIDs(req, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(ids) == 0 {
+		return []models.Vulnerability{}, total, nil
+	}
 
-	if err := s.db.
+	var vulnerabilities []models.Vulnerability
+	if err := req.Fieldset.Apply(s.db, vulnerabilityListPreloads).
+		Where("id IN ?", ids).
+		Find(&vulnerabilities).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load vulnerabilities: %w", err)
+	}
+
+	return orderByIDs(vulnerabilities, ids, func(v *models.Vulnerability) uuid.UUID { return v.ID }), total, nil
+}
+
+// vulnerabilityDetailPreloads are the relations loaded for a full vulnerability record by default
+func vulnerabilityDetailPreloads(query *gorm.DB) *gorm.DB {
+	return query.
 		Preload("CreatedBy").
 		Preload("AssignedTo").
+		Preload("OwnerTeam").
 		Preload("AffectedSystems").
+		Preload("Tags").
+		Preload("Classifications").
 		Preload("StatusHistory", func(db *gorm.DB) *gorm.DB {
 			return db.Order("changed_at DESC").Preload("ChangedBy")
 		}).
+		Preload("Escalations", func(db *gorm.DB) *gorm.DB {
+			return db.Order("escalated_at DESC").Preload("Policy", func(db *gorm.DB) *gorm.DB {
+				return db.Unscoped() // Escalations of deleted policies stay in the history
+			})
+		})
+}
+
+// GetVulnerabilityByID retrieves a vulnerability by ID with all associations
+func (s *VulnerabilityService) GetVulnerabilityByID(id uuid.UUID) (*models.Vulnerability, error) {
+	return s.GetVulnerabilityWithFieldset(id, nil)
+}
+
+// GetVulnerabilityWithFieldset retrieves a vulnerability by ID, loading only the requested
+// columns and relations (nil loads all relations)
+func (s *VulnerabilityService) GetVulnerabilityWithFieldset(id uuid.UUID, fieldset *Fieldset) (*models.Vulnerability, error) {
+	var vulnerability models.Vulnerability
+
+	if err := fieldset.Apply(s.db, vulnerabilityDetailPreloads).
 		First(&vulnerability, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("vulnerability not found")
@@ -370,6 +588,73 @@ func (s *VulnerabilityService) GetVulnerabilityByID(id uuid.UUID) (*models.Vulne
 	return &vulnerability, nil
 }
 
+// MaxBatchGetVulnerabilities caps the vulnerabilities a single batch get request can return
+const MaxBatchGetVulnerabilities = 500
+
+// BatchGetVulnerabilitiesRequest asks for many vulnerabilities by ID
+type BatchGetVulnerabilitiesRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+// BatchGetVulnerabilitiesResult holds the found vulnerabilities in request order and the IDs
+// that do not exist or are not visible to the caller
+type BatchGetVulnerabilitiesResult struct {
+	Vulnerabilities []models.Vulnerability `json:"vulnerabilities"`
+	NotFound        []uuid.UUID            `json:"not_found"`
+}
+
+// NormalizeBatchGetIDs drops duplicate IDs, keeping the first occurrence, and enforces the
+// batch size limit
+func NormalizeBatchGetIDs(ids []uuid.UUID) ([]uuid.UUID, error) {
+	unique := make([]uuid.UUID, 0, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("ids is required")
+	}
+	if len(unique) > MaxBatchGetVulnerabilities {
+		return nil, fmt.Errorf("invalid ids: at most %d vulnerabilities per request", MaxBatchGetVulnerabilities)
+	}
+	return unique, nil
+}
+
+// BatchGetVulnerabilities returns full vulnerability records for many IDs with a single
+// query (plus one per preloaded relation), so integrations can hydrate references without
+// a request per vulnerability
+func (s *VulnerabilityService) BatchGetVulnerabilities(ids []uuid.UUID, fieldset *Fieldset) (*BatchGetVulnerabilitiesResult, error) {
+	ids, err := NormalizeBatchGetIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var vulnerabilities []models.Vulnerability
+	if err := fieldset.Apply(s.db, vulnerabilityDetailPreloads).
+		Where("vulnerabilities.id IN ?", ids).
+		Find(&vulnerabilities).Error; err != nil {
+		return nil, fmt.Errorf("failed to get vulnerabilities: %w", err)
+	}
+
+	result := &BatchGetVulnerabilitiesResult{
+		Vulnerabilities: orderByIDs(vulnerabilities, ids, func(v *models.Vulnerability) uuid.UUID { return v.ID }),
+		NotFound:        []uuid.UUID{},
+	}
+	found := make(map[uuid.UUID]bool, len(vulnerabilities))
+	for _, vulnerability := range vulnerabilities {
+		found[vulnerability.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			result.NotFound = append(result.NotFound, id)
+		}
+	}
+	return result, nil
+}
+
 // UpdateVulnerabilityRequest represents a vulnerability update request
 type UpdateVulnerabilityRequest struct {
 	Title                     *string
@@ -378,10 +663,16 @@ type UpdateVulnerabilityRequest struct {
 	CVSSScore                 *float64
 	CVSSVector                *string
 	CVEID                     *string
+	CWEIDs                    *[]string
 	RemediationNotes          *string
 	ImpactAssessment          *string
 	StepsToReproduce          *string
 	MitigationRecommendations *string
+	PatchAvailable            *bool
+	AdvisoryURL               *string
+	FixedVersion              *string
+	Workaround                *string
+	IfMatch                   string // Expected version (ETag); empty updates unconditionally
 }
 
 // UpdateVulnerability updates a vulnerability
@@ -396,6 +687,14 @@ func (s *VulnerabilityService) UpdateVulnerability(id uuid.UUID, req UpdateVulne
 		return nil, fmt.Errorf("failed to get vulnerability: %w", err)
 	}
 
+	if err := s.authorize(s.db, id, "write"); err != nil {
+		return nil, err
+	}
+
+	if err := checkIfMatch(req.IfMatch, vulnerability.UpdatedAt); err != nil {
+		return nil, err
+	}
+
 	// Update fields if provided
 	updates := make(map[string]interface{})
 
@@ -417,6 +716,9 @@ func (s *VulnerabilityService) UpdateVulnerability(id uuid.UUID, req UpdateVulne
 	if req.CVEID != nil {
 		updates["cve_id"] = *req.CVEID
 	}
+	if req.CWEIDs != nil {
+		updates["cwe_ids"] = pq.StringArray(*req.CWEIDs)
+	}
 	if req.RemediationNotes != nil {
 		updates["remediation_notes"] = *req.RemediationNotes
 	}
@@ -429,15 +731,35 @@ func (s *VulnerabilityService) UpdateVulnerability(id uuid.UUID, req UpdateVulne
 	if req.MitigationRecommendations != nil {
 		updates["mitigation_recommendations"] = *req.MitigationRecommendations
 	}
+	if req.PatchAvailable != nil {
+		updates["patch_available"] = *req.PatchAvailable
+	}
+	if req.AdvisoryURL != nil {
+		updates["advisory_url"] = *req.AdvisoryURL
+	}
+	if req.FixedVersion != nil {
+		updates["fixed_version"] = *req.FixedVersion
+	}
+	if req.Workaround != nil {
+		updates["workaround"] = *req.Workaround
+	}
 
-	// Perform update
-	if err := s.db.Model(&vulnerability).Updates(updates).Error; err != nil {
-		utils.Logger.Error().Err(err).Str("id", id.String()).Msg("Failed to update vulnerability")
-		return nil, fmt.Errorf("failed to update vulnerability: %w", err)
+	// Perform update; a conditional update only applies to the version it was checked against
+	query := s.db.Model(&vulnerability)
+	if req.IfMatch != "" {
+		query = query.Where("updated_at = ?", vulnerability.UpdatedAt)
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		utils.Logger.Error().Err(result.Error).Str("id", id.String()).Msg("Failed to update vulnerability")
+		return nil, fmt.Errorf("failed to update vulnerability: %w", result.Error)
+	}
+	if req.IfMatch != "" && len(updates) > 0 && result.RowsAffected == 0 {
+		return nil, ErrPreconditionFailed
 	}
 
 	// Reload with associations
-	if err := s.db.Preload("CreatedBy").Preload("AssignedTo").Preload("AffectedSystems").First(&vulnerability, id).Error; err != nil {
+	if err := s.db.Preload("CreatedBy").Preload("AssignedTo").Preload("OwnerTeam").Preload("AffectedSystems").First(&vulnerability, id).Error; err != nil {
 		return nil, fmt.Errorf("failed to reload vulnerability: %w", err)
 	}
 
@@ -469,12 +791,28 @@ func (s *VulnerabilityService) UpdateVulnerabilityStatus(id uuid.UUID, newStatus
 		return nil, fmt.Errorf("failed to get vulnerability: %w", err)
 	}
 
+	if err := s.authorize(tx, id, "status_change"); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	// Check if status is actually changing
 	if vulnerability.Status == newStatus {
 		tx.Rollback()
 		return nil, fmt.Errorf("vulnerability is already in status: %s", newStatus)
 	}
 
+	// CRITICAL vulnerabilities are closed by approving a close request under the two-person rule
+	closeApproval, err := LoadCriticalCloseApproval(tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if RequiresCloseApproval(closeApproval, vulnerability.Severity, newStatus) {
+		tx.Rollback()
+		return nil, ErrCloseApprovalRequired
+	}
+
 	oldStatus := vulnerability.Status
 
 	// Create status history entry
@@ -500,6 +838,15 @@ func (s *VulnerabilityService) UpdateVulnerabilityStatus(id uuid.UUID, newStatus
 		return nil, fmt.Errorf("failed to update vulnerability status: %w", err)
 	}
 
+	// Route a notification to the owning team
+	if vulnerability.OwnerTeamID != nil {
+		title := fmt.Sprintf("Status changed from %s to %s", oldStatus, newStatus)
+		if err := s.notifyOwnerTeam(tx, &vulnerability, models.NotificationTypeTeamStatusChanged, title, &changedByID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to commit transaction")
@@ -507,7 +854,7 @@ func (s *VulnerabilityService) UpdateVulnerabilityStatus(id uuid.UUID, newStatus
 	}
 
 	// Reload with associations
-	if err := s.db.Preload("CreatedBy").Preload("AssignedTo").First(&vulnerability, id).Error; err != nil {
+	if err := s.db.Preload("CreatedBy").Preload("AssignedTo").Preload("OwnerTeam").First(&vulnerability, id).Error; err != nil {
 		return nil, fmt.Errorf("failed to reload vulnerability: %w", err)
 	}
 
@@ -521,8 +868,8 @@ func (s *VulnerabilityService) UpdateVulnerabilityStatus(id uuid.UUID, newStatus
 	return &vulnerability, nil
 }
 
-// AssignVulnerability assigns a vulnerability to a user
-func (s *VulnerabilityService) AssignVulnerability(id uuid.UUID, assignedToID *uuid.UUID) (*models.Vulnerability, error) {
+// AssignVulnerability assigns a vulnerability to a user and records the change in the assignment history
+func (s *VulnerabilityService) AssignVulnerability(id uuid.UUID, assignedToID *uuid.UUID, changedByID uuid.UUID) (*models.Vulnerability, error) {
 	var vulnerability models.Vulnerability
 
 	// Get existing vulnerability
@@ -533,14 +880,34 @@ func (s *VulnerabilityService) AssignVulnerability(id uuid.UUID, assignedToID *u
 		return nil, fmt.Errorf("failed to get vulnerability: %w", err)
 	}
 
-	// Update assignment
-	if err := s.db.Model(&vulnerability).Update("assigned_to_id", assignedToID).Error; err != nil {
+	if err := s.authorize(s.db, id, "assign"); err != nil {
+		return nil, err
+	}
+
+	oldAssigneeID := vulnerability.AssignedToID
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Update assignment
+		if err := tx.Model(&vulnerability).Update("assigned_to_id", assignedToID).Error; err != nil {
+			return err
+		}
+
+		// Record assignment history entry
+		return tx.Create(&models.VulnerabilityAssignmentHistory{
+			VulnerabilityID: id,
+			OldAssigneeID:   oldAssigneeID,
+			NewAssigneeID:   assignedToID,
+			ChangedByID:     changedByID,
+			ChangedAt:       time.Now(),
+		}).Error
+	})
+	if err != nil {
 		utils.Logger.Error().Err(err).Str("id", id.String()).Msg("Failed to assign vulnerability")
 		return nil, fmt.Errorf("failed to assign vulnerability: %w", err)
 	}
 
 	// Reload with associations
-	if err := s.db.Preload("CreatedBy").Preload("AssignedTo").First(&vulnerability, id).Error; err != nil {
+	if err := s.db.Preload("CreatedBy").Preload("AssignedTo").Preload("OwnerTeam").First(&vulnerability, id).Error; err != nil {
 		return nil, fmt.Errorf("failed to reload vulnerability: %w", err)
 	}
 
@@ -557,16 +924,160 @@ func (s *VulnerabilityService) AssignVulnerability(id uuid.UUID, assignedToID *u
 	return &vulnerability, nil
 }
 
+// AssignVulnerabilityTeam sets or clears the team that owns a vulnerability and notifies the new team's members
+func (s *VulnerabilityService) AssignVulnerabilityTeam(id uuid.UUID, teamID *uuid.UUID, changedByID uuid.UUID) (*models.Vulnerability, error) {
+	var vulnerability models.Vulnerability
+
+	// Get existing vulnerability
+	if err := s.db.First(&vulnerability, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("vulnerability not found")
+		}
+		return nil, fmt.Errorf("failed to get vulnerability: %w", err)
+	}
+
+	if teamID != nil {
+		if err := s.teamService.Exists(*teamID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.authorize(s.db, id, "assign"); err != nil {
+		return nil, err
+	}
+
+	changed := (vulnerability.OwnerTeamID == nil) != (teamID == nil) ||
+		(teamID != nil && *vulnerability.OwnerTeamID != *teamID)
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&vulnerability).Update("owner_team_id", teamID).Error; err != nil {
+			return fmt.Errorf("failed to assign vulnerability team: %w", err)
+		}
+		if changed && teamID != nil {
+			vulnerability.OwnerTeamID = teamID
+			return s.notifyOwnerTeam(tx, &vulnerability, models.NotificationTypeTeamAssigned, "Vulnerability assigned to your team", &changedByID)
+		}
+		return nil
+	})
+	if err != nil {
+		utils.Logger.Error().Err(err).Str("id", id.String()).Msg("Failed to assign vulnerability team")
+		return nil, err
+	}
+
+	// Reload with associations
+	if err := s.db.Preload("CreatedBy").Preload("AssignedTo").Preload("OwnerTeam").First(&vulnerability, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to reload vulnerability: %w", err)
+	}
+
+	utils.Logger.Info().
+		Str("vulnerability_id", id.String()).
+		Str("owner_team", func() string {
+			if teamID != nil {
+				return teamID.String()
+			}
+			return "none"
+		}()).
+		Msg("Vulnerability team assigned successfully")
+
+	return &vulnerability, nil
+}
+
+// authorize checks a vulnerability action against the caller's role constraints, using the
+// vulnerability's current affected assets plus any extra assets the action adds
+func (s *VulnerabilityService) authorize(db *gorm.DB, vulnerabilityID uuid.UUID, action string, extra ...policy.AssetAttributes) error {
+	ctx := s.db.Statement.Context
+	if !policy.Constrained(ctx, "vulnerability", action) {
+		return nil
+	}
+
+	var attrs []policy.AssetAttributes
+	if err := db.Model(&models.AffectedSystem{}).
+		Select("affected_systems.environment, affected_systems.criticality").
+		Joins("JOIN vulnerability_affected_systems ON vulnerability_affected_systems.affected_system_id = affected_systems.id").
+		Where("vulnerability_affected_systems.vulnerability_id = ?", vulnerabilityID).
+		Scan(&attrs).Error; err != nil {
+		return fmt.Errorf("failed to load affected systems: %w", err)
+	}
+	return policy.Authorize(ctx, "vulnerability", action, append(attrs, extra...))
+}
+
+// authorizeCreate checks vulnerability creation against the caller's role constraints using the requested assets
+func (s *VulnerabilityService) authorizeCreate(req CreateVulnerabilityRequest) error {
+	ctx := s.db.Statement.Context
+	if !policy.Constrained(ctx, "vulnerability", "write") {
+		return nil
+	}
+
+	var attrs []policy.AssetAttributes
+	if len(req.AffectedSystemIDs) > 0 {
+		if err := s.db.Model(&models.AffectedSystem{}).
+			Select("environment, criticality").
+			Where("id IN ?", req.AffectedSystemIDs).
+			Scan(&attrs).Error; err != nil {
+			return fmt.Errorf("failed to load affected systems: %w", err)
+		}
+	}
+	for _, system := range req.NewAffectedSystems {
+		attrs = append(attrs, policy.AssetAttributes{Environment: system.Environment})
+	}
+	return policy.Authorize(ctx, "vulnerability", "write", attrs)
+}
+
+// applyAssignmentRules assigns a new vulnerability with the first matching assignment rule
+func (s *VulnerabilityService) applyAssignmentRules(tx *gorm.DB, vulnerability *models.Vulnerability, createdByID uuid.UUID) error {
+	if vulnerability.AssignedToID != nil && vulnerability.OwnerTeamID != nil {
+		return nil
+	}
+	// Only the rules of the vulnerability's organization apply, also outside a request
+	if vulnerability.OrgID != nil {
+		tx = tx.WithContext(tenant.WithOrg(tx.Statement.Context, *vulnerability.OrgID))
+	}
+	rules := NewAssignmentRuleService(tx)
+	matcher, err := rules.LoadMatcher(tx)
+	if err != nil {
+		return err
+	}
+	_, err = rules.ApplyWithTx(tx, matcher, []*models.Vulnerability{vulnerability}, createdByID)
+	return err
+}
+
+// notifyOwnerTeam routes a vulnerability notification to the members of its owning team
+func (s *VulnerabilityService) notifyOwnerTeam(tx *gorm.DB, vulnerability *models.Vulnerability, notificationType models.NotificationType, title string, actorID *uuid.UUID) error {
+	resourceID := vulnerability.ID
+	return s.teamService.NotifyMembers(tx, *vulnerability.OwnerTeamID, actorID, models.Notification{
+		Type:         notificationType,
+		Title:        title,
+		Message:      vulnerability.Title,
+		ResourceType: "vulnerability",
+		ResourceID:   &resourceID,
+	})
+}
+
 // DeleteVulnerability soft deletes a vulnerability
-func (s *VulnerabilityService) DeleteVulnerability(id uuid.UUID) error {
-	result := s.db.Delete(&models.Vulnerability{}, id)
-	if result.Error != nil {
-		utils.Logger.Error().Err(result.Error).Str("id", id.String()).Msg("Failed to delete vulnerability")
-		return fmt.Errorf("failed to delete vulnerability: %w", result.Error)
+func (s *VulnerabilityService) DeleteVulnerability(id uuid.UUID, deletedByID uuid.UUID) error {
+	if err := s.authorize(s.db, id, "delete"); err != nil {
+		return err
 	}
 
-	if result.RowsAffected == 0 {
-		return fmt.Errorf("vulnerability not found")
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Record who deleted it for the recycle bin
+		if err := tx.Model(&models.Vulnerability{}).Where("id = ?", id).UpdateColumn("deleted_by_id", deletedByID).Error; err != nil {
+			return fmt.Errorf("failed to delete vulnerability: %w", err)
+		}
+
+		// Delete through the model so write callbacks (SIEM forwarding) see which vulnerability was removed
+		result := tx.Delete(&models.Vulnerability{BaseModel: models.BaseModel{ID: id}})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete vulnerability: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("vulnerability not found")
+		}
+		return nil
+	})
+	if err != nil {
+		utils.Logger.Error().Err(err).Str("id", id.String()).Msg("Failed to delete vulnerability")
+		return err
 	}
 
 	utils.Logger.Info().
@@ -588,70 +1099,48 @@ type VulnerabilityStats struct {
 
 // GetVulnerabilityStats returns statistics about vulnerabilities
 func (s *VulnerabilityService) GetVulnerabilityStats() (*VulnerabilityStats, error) {
+	return cached(s.db.Statement.Context, ActiveCache(), CacheGroupVulnerabilityStats, "all", s.computeVulnerabilityStats)
+}
+
+// computeVulnerabilityStats aggregates vulnerability statistics from the database in a
+// single pass grouped by severity and status
+func (s *VulnerabilityService) computeVulnerabilityStats() (*VulnerabilityStats, error) {
 	stats := &VulnerabilityStats{
 		BySeverity: make(map[string]int64),
 		ByStatus:   make(map[string]int64),
 	}
 
-	// Total count
-	if err := s.db.Model(&models.Vulnerability{}).Count(&stats.TotalCount).Error; err != nil {
-		return nil, fmt.Errorf("failed to count total vulnerabilities: %w", err)
-	}
-
-	// Count by severity
-	var severityCounts []struct {
-		Severity string
-		Count    int64
-	}
-	if err := s.db.Model(&models.Vulnerability{}).
-		Select("severity, COUNT(*) as count").
-		Group("severity").
-		Scan(&severityCounts).Error; err != nil {
-		return nil, fmt.Errorf("failed to count vulnerabilities by severity: %w", err)
-	}
-	for _, sc := range severityCounts {
-		stats.BySeverity[strings.ToLower(sc.Severity)] = sc.Count
-	}
-
-	// Count by status
-	var statusCounts []struct {
-		Status string
-		Count  int64
-	}
-	if err := s.db.Model(&models.Vulnerability{}).
-		Select("status, COUNT(*) as count").
-		Group("status").
-		Scan(&statusCounts).Error; err != nil {
-		return nil, fmt.Errorf("failed to count vulnerabilities by status: %w", err)
-	}
-	for _, sc := range statusCounts {
-		stats.ByStatus[strings.ToLower(sc.Status)] = sc.Count
-	}
-
-	// Recent discoveries (last 30 days)
 	thirtyDaysAgo := time.Now().AddDate(0, 0, -30)
-	if err := s.db.Model(&models.Vulnerability{}).
-		Where("discovery_date >= ?", thirtyDaysAgo).
-		Count(&stats.RecentDiscoveries).Error; err != nil {
-		return nil, fmt.Errorf("failed to count recent discoveries: %w", err)
+	var counts []struct {
+		Severity           string
+		Status             string
+		Count              int64
+		RecentDiscoveries  int64
+		UnassignedCount    int64
+		CriticalUnresolved int64
 	}
-
-	// Unassigned count
-	if err := s.db.Model(&models.Vulnerability{}).
-		Where("assigned_to_id IS NULL").
-		Count(&stats.UnassignedCount).Error; err != nil {
-		return nil, fmt.Errorf("failed to count unassigned vulnerabilities: %w", err)
-	}
-
-	// Critical unresolved
 	if err := s.db.Model(&models.Vulnerability{}).
-		Where("severity = ? AND status NOT IN ?", models.SeverityCritical, []models.VulnerabilityStatus{
-			models.StatusResolved,
-			models.StatusVerified,
-			models.StatusClosed,
-		}).
-		Count(&stats.CriticalUnresolved).Error; err != nil {
-		return nil, fmt.Errorf("failed to count critical unresolved vulnerabilities: %w", err)
+		Select(`severity, status, COUNT(*) as count,
+			COUNT(*) FILTER (WHERE discovery_date >= ?) as recent_discoveries,
+			COUNT(*) FILTER (WHERE assigned_to_id IS NULL) as unassigned_count,
+			COUNT(*) FILTER (WHERE severity = ? AND status NOT IN ?) as critical_unresolved`,
+			thirtyDaysAgo, models.SeverityCritical, []models.VulnerabilityStatus{
+				models.StatusResolved,
+				models.StatusVerified,
+				models.StatusClosed,
+			}).
+		Group("severity, status").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count vulnerabilities: %w", err)
+	}
+
+	for _, c := range counts {
+		stats.TotalCount += c.Count
+		stats.BySeverity[strings.ToLower(c.Severity)] += c.Count
+		stats.ByStatus[strings.ToLower(c.Status)] += c.Count
+		stats.RecentDiscoveries += c.RecentDiscoveries
+		stats.UnassignedCount += c.UnassignedCount
+		stats.CriticalUnresolved += c.CriticalUnresolved
 	}
 
 	return stats, nil
@@ -679,6 +1168,11 @@ func (s *VulnerabilityService) AddAffectedSystems(vulnerabilityID uuid.UUID, sys
 		return fmt.Errorf("some affected systems not found")
 	}
 
+	// Both the current and the added assets must be within the caller's constraints
+	if err := s.authorize(s.db, vulnerabilityID, "write", policy.Attributes(systems...)...); err != nil {
+		return err
+	}
+
 	// Add the systems to the vulnerability
 	if err := s.db.Model(&vulnerability).Association("AffectedSystems").Append(&systems); err != nil {
 		utils.Logger.Error().Err(err).Msg("Failed to add affected systems to vulnerability")
@@ -703,6 +1197,10 @@ func (s *VulnerabilityService) RemoveAffectedSystems(vulnerabilityID uuid.UUID,
 		return fmt.Errorf("failed to get vulnerability: %w", err)
 	}
 
+	if err := s.authorize(s.db, vulnerabilityID, "write"); err != nil {
+		return err
+	}
+
 	var systems []models.AffectedSystem
 	if err := s.db.Where("id IN ?", systemIDs).Find(&systems).Error; err != nil {
 		return fmt.Errorf("failed to find affected systems: %w", err)
@@ -725,3 +1223,205 @@ func (s *VulnerabilityService) RemoveAffectedSystems(vulnerabilityID uuid.UUID,
 func (s *VulnerabilityService) RemoveAffectedSystem(vulnerabilityID uuid.UUID, systemID uuid.UUID) error {
 	return s.RemoveAffectedSystems(vulnerabilityID, []uuid.UUID{systemID})
 }
+
+// MaxBulkTagVulnerabilities caps the vulnerabilities a single bulk tagging request can change
+const MaxBulkTagVulnerabilities = 500
+
+// BulkTagVulnerabilitiesRequest adds and removes tags across many vulnerabilities
+type BulkTagVulnerabilitiesRequest struct {
+	VulnerabilityIDs []uuid.UUID `json:"vulnerability_ids"`
+	Add              []string    `json:"add,omitempty"`
+	Remove           []string    `json:"remove,omitempty"`
+}
+
+// BulkTagVulnerabilitiesResult reports how many tags a bulk tagging request changed
+type BulkTagVulnerabilitiesResult struct {
+	Vulnerabilities int   `json:"vulnerabilities"`
+	TagsAdded       int64 `json:"tags_added"`
+	TagsRemoved     int64 `json:"tags_removed"`
+}
+
+// TagCount is a tag and the number of vulnerabilities carrying it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// NormalizeTags normalizes, validates and de-duplicates tags, keeping their order
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, raw := range tags {
+		tag, err := models.NormalizeTag(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag '%s': %v", raw, err)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+	return out, nil
+}
+
+// AddTags adds tags to a vulnerability, ignoring tags it already has
+func (s *VulnerabilityService) AddTags(vulnerabilityID uuid.UUID, tags []string) (*models.Vulnerability, error) {
+	normalized, err := NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("at least one tag is required")
+	}
+	if err := s.checkTaggable([]uuid.UUID{vulnerabilityID}); err != nil {
+		return nil, err
+	}
+
+	if _, err := addVulnerabilityTags(s.db, []uuid.UUID{vulnerabilityID}, normalized); err != nil {
+		return nil, err
+	}
+	return s.GetVulnerabilityByID(vulnerabilityID)
+}
+
+// RemoveTag removes a tag from a vulnerability
+func (s *VulnerabilityService) RemoveTag(vulnerabilityID uuid.UUID, tag string) (*models.Vulnerability, error) {
+	if err := s.checkTaggable([]uuid.UUID{vulnerabilityID}); err != nil {
+		return nil, err
+	}
+
+	removed, err := removeVulnerabilityTags(s.db, []uuid.UUID{vulnerabilityID}, []string{strings.ToLower(strings.TrimSpace(tag))})
+	if err != nil {
+		return nil, err
+	}
+	if removed == 0 {
+		return nil, fmt.Errorf("tag not found on vulnerability")
+	}
+	return s.GetVulnerabilityByID(vulnerabilityID)
+}
+
+// BulkTag adds and removes tags across many vulnerabilities in one transaction
+func (s *VulnerabilityService) BulkTag(req BulkTagVulnerabilitiesRequest) (*BulkTagVulnerabilitiesResult, error) {
+	ids := make([]uuid.UUID, 0, len(req.VulnerabilityIDs))
+	seen := make(map[uuid.UUID]bool, len(req.VulnerabilityIDs))
+	for _, id := range req.VulnerabilityIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("vulnerability_ids is required")
+	}
+	if len(ids) > MaxBulkTagVulnerabilities {
+		return nil, fmt.Errorf("invalid vulnerability_ids: at most %d vulnerabilities per request", MaxBulkTagVulnerabilities)
+	}
+
+	add, err := NormalizeTags(req.Add)
+	if err != nil {
+		return nil, err
+	}
+	remove, err := NormalizeTags(req.Remove)
+	if err != nil {
+		return nil, err
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return nil, fmt.Errorf("add or remove tags are required")
+	}
+	if err := s.checkTaggable(ids); err != nil {
+		return nil, err
+	}
+
+	result := &BulkTagVulnerabilitiesResult{Vulnerabilities: len(ids)}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if result.TagsRemoved, err = removeVulnerabilityTags(tx, ids, remove); err != nil {
+			return err
+		}
+		result.TagsAdded, err = addVulnerabilityTags(tx, ids, add)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Logger.Info().
+		Int("vulnerabilities", len(ids)).
+		Int64("tags_added", result.TagsAdded).
+		Int64("tags_removed", result.TagsRemoved).
+		Msg("Vulnerabilities tagged in bulk")
+
+	return result, nil
+}
+
+// ListTags returns the tags in use on the caller's vulnerabilities, most used first,
+// optionally filtered by prefix
+func (s *VulnerabilityService) ListTags(prefix string) ([]TagCount, error) {
+	query := s.db.Model(&models.Vulnerability{}).
+		Select("vulnerability_tags.tag, COUNT(*) AS count").
+		Joins("JOIN vulnerability_tags ON vulnerability_tags.vulnerability_id = vulnerabilities.id")
+	if prefix = strings.ToLower(strings.TrimSpace(prefix)); prefix != "" {
+		query = query.Where("vulnerability_tags.tag LIKE ?", prefix+"%")
+	}
+
+	tags := []TagCount{}
+	if err := query.Group("vulnerability_tags.tag").
+		Order("count DESC, vulnerability_tags.tag ASC").
+		Limit(100).
+		Scan(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to list vulnerability tags: %w", err)
+	}
+	return tags, nil
+}
+
+// checkTaggable verifies that every vulnerability exists and that the caller may write to it
+func (s *VulnerabilityService) checkTaggable(ids []uuid.UUID) error {
+	var count int64
+	if err := s.db.Model(&models.Vulnerability{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to get vulnerability: %w", err)
+	}
+	if int(count) != len(ids) {
+		return fmt.Errorf("vulnerability not found")
+	}
+	for _, id := range ids {
+		if err := s.authorize(s.db, id, "write"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addVulnerabilityTags applies every tag to every vulnerability, ignoring existing tags,
+// and returns the number of tags added
+func addVulnerabilityTags(tx *gorm.DB, vulnerabilityIDs []uuid.UUID, tags []string) (int64, error) {
+	if len(tags) == 0 {
+		return 0, nil
+	}
+	rows := make([]models.VulnerabilityTag, 0, len(vulnerabilityIDs)*len(tags))
+	for _, id := range vulnerabilityIDs {
+		for _, tag := range tags {
+			rows = append(rows, models.VulnerabilityTag{VulnerabilityID: id, Tag: tag})
+		}
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 500)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to add vulnerability tags: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// removeVulnerabilityTags removes tags from vulnerabilities and returns the number removed.
+// Rows are deleted one vulnerability at a time so the search index sees every change.
+func removeVulnerabilityTags(tx *gorm.DB, vulnerabilityIDs []uuid.UUID, tags []string) (int64, error) {
+	if len(tags) == 0 {
+		return 0, nil
+	}
+	var removed int64
+	for _, id := range vulnerabilityIDs {
+		result := tx.Where("tag IN ?", tags).Delete(&models.VulnerabilityTag{VulnerabilityID: id})
+		if result.Error != nil {
+			return 0, fmt.Errorf("failed to remove vulnerability tags: %w", result.Error)
+		}
+		removed += result.RowsAffected
+	}
+	return removed, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/utils"
 )
 
 // VulnerabilityValidationService handles validation for vulnerability operations
@@ -25,61 +26,73 @@ var cveIDPattern = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
 func (s *VulnerabilityValidationService) ValidateCreateRequest(req CreateVulnerabilityRequest) error {
 	// Validate title
 	if err := s.ValidateTitle(req.Title); err != nil {
-		return err
+		return utils.FieldErr("title", err)
 	}
 
 	// Validate description
 	if err := s.ValidateDescription(req.Description); err != nil {
-		return err
+		return utils.FieldErr("description", err)
 	}
 
 	// Validate severity
 	if err := s.ValidateSeverity(req.Severity); err != nil {
-		return err
+		return utils.FieldErr("severity", err)
 	}
 
 	// Validate CVSS score if provided
 	if req.CVSSScore != nil {
 		if err := s.ValidateCVSSScore(*req.CVSSScore); err != nil {
-			return err
+			return utils.FieldErr("cvss_score", err)
 		}
 	}
 
 	// Validate CVSS vector if provided
 	if req.CVSSVector != "" {
 		if err := s.ValidateCVSSVector(req.CVSSVector); err != nil {
-			return err
+			return utils.FieldErr("cvss_vector", err)
 		}
 	}
 
 	// Validate CVE ID if provided
 	if req.CVEID != "" {
 		if err := s.ValidateCVEID(req.CVEID); err != nil {
-			return err
+			return utils.FieldErr("cve_id", err)
 		}
 	}
 
 	// Validate discovery date
 	if err := s.ValidateDiscoveryDate(req.DiscoveryDate); err != nil {
-		return err
+		return utils.FieldErr("discovery_date", err)
 	}
 
 	// Validate affected systems (required for create)
 	if err := s.ValidateAffectedSystems(req.AffectedSystemIDs); err != nil {
-		return err
+		return utils.FieldErr("affected_system_ids", err)
 	}
 
 	// Validate text fields length
 	if req.ImpactAssessment != "" && len(req.ImpactAssessment) > 10000 {
-		return fmt.Errorf("impact assessment must be less than 10,000 characters")
+		return utils.FieldErr("impact_assessment", fmt.Errorf("impact assessment must be less than 10,000 characters"))
 	}
 
 	if req.StepsToReproduce != "" && len(req.StepsToReproduce) > 10000 {
-		return fmt.Errorf("steps to reproduce must be less than 10,000 characters")
+		return utils.FieldErr("steps_to_reproduce", fmt.Errorf("steps to reproduce must be less than 10,000 characters"))
 	}
 
 	if req.MitigationRecommendations != "" && len(req.MitigationRecommendations) > 10000 {
-		return fmt.Errorf("mitigation recommendations must be less than 10,000 characters")
+		return utils.FieldErr("mitigation_recommendations", fmt.Errorf("mitigation recommendations must be less than 10,000 characters"))
+	}
+
+	if err := utils.ValidateURL(req.AdvisoryURL); err != nil {
+		return utils.FieldErr("advisory_url", err)
+	}
+
+	if len(req.FixedVersion) > 100 {
+		return utils.FieldErr("fixed_version", fmt.Errorf("fixed version must be less than 100 characters"))
+	}
+
+	if len(req.Workaround) > 10000 {
+		return utils.FieldErr("workaround", fmt.Errorf("workaround must be less than 10,000 characters"))
 	}
 
 	return nil
@@ -90,60 +103,74 @@ func (s *VulnerabilityValidationService) ValidateUpdateRequest(req UpdateVulnera
 	// Validate title if provided
 	if req.Title != nil {
 		if err := s.ValidateTitle(*req.Title); err != nil {
-			return err
+			return utils.FieldErr("title", err)
 		}
 	}
 
 	// Validate description if provided
 	if req.Description != nil {
 		if err := s.ValidateDescription(*req.Description); err != nil {
-			return err
+			return utils.FieldErr("description", err)
 		}
 	}
 
 	// Validate severity if provided
 	if req.Severity != nil {
 		if err := s.ValidateSeverity(*req.Severity); err != nil {
-			return err
+			return utils.FieldErr("severity", err)
 		}
 	}
 
 	// Validate CVSS score if provided
 	if req.CVSSScore != nil {
 		if err := s.ValidateCVSSScore(*req.CVSSScore); err != nil {
-			return err
+			return utils.FieldErr("cvss_score", err)
 		}
 	}
 
 	// Validate CVSS vector if provided
 	if req.CVSSVector != nil && *req.CVSSVector != "" {
 		if err := s.ValidateCVSSVector(*req.CVSSVector); err != nil {
-			return err
+			return utils.FieldErr("cvss_vector", err)
 		}
 	}
 
 	// Validate CVE ID if provided
 	if req.CVEID != nil && *req.CVEID != "" {
 		if err := s.ValidateCVEID(*req.CVEID); err != nil {
-			return err
+			return utils.FieldErr("cve_id", err)
 		}
 	}
 
 	// Validate text fields length
 	if req.RemediationNotes != nil && len(*req.RemediationNotes) > 10000 {
-		return fmt.Errorf("remediation notes must be less than 10,000 characters")
+		return utils.FieldErr("remediation_notes", fmt.Errorf("remediation notes must be less than 10,000 characters"))
 	}
 
 	if req.ImpactAssessment != nil && len(*req.ImpactAssessment) > 10000 {
-		return fmt.Errorf("impact assessment must be less than 10,000 characters")
+		return utils.FieldErr("impact_assessment", fmt.Errorf("impact assessment must be less than 10,000 characters"))
 	}
 
 	if req.StepsToReproduce != nil && len(*req.StepsToReproduce) > 10000 {
-		return fmt.Errorf("steps to reproduce must be less than 10,000 characters")
+		return utils.FieldErr("steps_to_reproduce", fmt.Errorf("steps to reproduce must be less than 10,000 characters"))
 	}
 
 	if req.MitigationRecommendations != nil && len(*req.MitigationRecommendations) > 10000 {
-		return fmt.Errorf("mitigation recommendations must be less than 10,000 characters")
+		return utils.FieldErr("mitigation_recommendations", fmt.Errorf("mitigation recommendations must be less than 10,000 characters"))
+	}
+
+	if req.AdvisoryURL != nil {
+		if err := utils.ValidateURL(*req.AdvisoryURL); err != nil {
+			return utils.FieldErr("advisory_url", err)
+		}
+	}
+
+	if req.FixedVersion != nil && len(*req.FixedVersion) > 100 {
+		return utils.FieldErr("fixed_version", fmt.Errorf("fixed version must be less than 100 characters"))
+	}
+
+	if req.Workaround != nil && len(*req.Workaround) > 10000 {
+		return utils.FieldErr("workaround", fmt.Errorf("workaround must be less than 10,000 characters"))
 	}
 
 	return nil
@@ -276,6 +303,10 @@ func (s *VulnerabilityValidationService) ValidateDiscoveryDate(date time.Time) e
 func (s *VulnerabilityValidationService) ValidateStatusTransition(oldStatus, newStatus models.VulnerabilityStatus) error {
 	// Define valid transitions
 	validTransitions := map[models.VulnerabilityStatus][]models.VulnerabilityStatus{
+		models.StatusDraft: {
+			models.StatusOpen,
+			models.StatusFalsePositive,
+		},
 		models.StatusOpen: {
 			models.StatusInProgress,
 			models.StatusFalsePositive,
@@ -328,6 +359,7 @@ func (s *VulnerabilityValidationService) ValidateUserID(userID uuid.UUID) error
 // ValidateStatus validates vulnerability status
 func (s *VulnerabilityValidationService) ValidateStatus(status models.VulnerabilityStatus) error {
 	validStatuses := []models.VulnerabilityStatus{
+		models.StatusDraft,
 		models.StatusOpen,
 		models.StatusInProgress,
 		models.StatusResolved,
@@ -342,7 +374,7 @@ func (s *VulnerabilityValidationService) ValidateStatus(status models.Vulnerabil
 		}
 	}
 
-	return fmt.Errorf("invalid status: must be one of OPEN, IN_PROGRESS, RESOLVED, VERIFIED, CLOSED, FALSE_POSITIVE")
+	return fmt.Errorf("invalid status: must be one of DRAFT, OPEN, IN_PROGRESS, RESOLVED, VERIFIED, CLOSED, FALSE_POSITIVE")
 }
 
 // ValidateAffectedSystems validates that at least one affected system is provided
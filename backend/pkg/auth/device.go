@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Device types reported by ParseUserAgent
+const (
+	DeviceTypeDesktop = "desktop"
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+	DeviceTypeBot     = "bot"
+	DeviceTypeUnknown = "unknown"
+)
+
+// DeviceInfo is the device fingerprint shown in the sessions list
+type DeviceInfo struct {
+	Browser    string `json:"browser"`
+	OS         string `json:"os"`
+	DeviceType string `json:"device_type"`
+}
+
+// browserPatterns are checked in order: most user agents also name the engines they are
+// compatible with (Edge claims Chrome, Chrome claims Safari), so specific browsers come first
+var browserPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/(\d+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|Opera)/(\d+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/(\d+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/(\d+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/(\d+)`)},
+	{"Safari", regexp.MustCompile(`Version/(\d+)[.\d]* (?:Mobile/\S+ )?Safari/`)},
+	{"curl", regexp.MustCompile(`^curl/(\d+)`)},
+	{"Postman", regexp.MustCompile(`PostmanRuntime/(\d+)`)},
+}
+
+var (
+	windowsVersion = regexp.MustCompile(`Windows NT (\d+\.\d+)`)
+	macVersion     = regexp.MustCompile(`Mac OS X (\d+)[_.](\d+)`)
+	iosVersion     = regexp.MustCompile(`(?:iPhone|CPU) OS (\d+)`)
+	androidVersion = regexp.MustCompile(`Android (\d+)`)
+	botPattern     = regexp.MustCompile(`(?i)bot|crawler|spider|curl/|wget/|python-requests|go-http-client`)
+)
+
+// windowsReleases maps Windows NT versions to marketing names. NT 10.0 covers Windows 10
+// and 11, which user agents do not distinguish.
+var windowsReleases = map[string]string{
+	"10.0": "Windows 10/11",
+	"6.3":  "Windows 8.1",
+	"6.2":  "Windows 8",
+	"6.1":  "Windows 7",
+}
+
+// ParseUserAgent derives the browser, operating system and device type from a User-Agent
+// header. Unrecognised values are reported as "Unknown" rather than guessed.
+func ParseUserAgent(userAgent string) DeviceInfo {
+	info := DeviceInfo{Browser: "Unknown", OS: "Unknown", DeviceType: DeviceTypeUnknown}
+	if strings.TrimSpace(userAgent) == "" {
+		return info
+	}
+
+	for _, browser := range browserPatterns {
+		if match := browser.pattern.FindStringSubmatch(userAgent); match != nil {
+			info.Browser = browser.name + " " + match[1]
+			break
+		}
+	}
+
+	switch {
+	case strings.Contains(userAgent, "iPad"):
+		info.OS = "iPadOS" + versionSuffix(iosVersion, userAgent)
+	case strings.Contains(userAgent, "iPhone") || strings.Contains(userAgent, "iPod"):
+		info.OS = "iOS" + versionSuffix(iosVersion, userAgent)
+	case strings.Contains(userAgent, "Android"):
+		info.OS = "Android" + versionSuffix(androidVersion, userAgent)
+	case strings.Contains(userAgent, "Windows"):
+		info.OS = "Windows"
+		if match := windowsVersion.FindStringSubmatch(userAgent); match != nil {
+			if name, ok := windowsReleases[match[1]]; ok {
+				info.OS = name
+			}
+		}
+	case strings.Contains(userAgent, "Mac OS X"):
+		info.OS = "macOS"
+		if match := macVersion.FindStringSubmatch(userAgent); match != nil {
+			info.OS += " " + match[1] + "." + match[2]
+		}
+	case strings.Contains(userAgent, "CrOS"):
+		info.OS = "ChromeOS"
+	case strings.Contains(userAgent, "Linux"):
+		info.OS = "Linux"
+	}
+
+	switch {
+	case botPattern.MatchString(userAgent):
+		info.DeviceType = DeviceTypeBot
+	case strings.Contains(userAgent, "iPad") || (strings.Contains(userAgent, "Android") && !strings.Contains(userAgent, "Mobile")):
+		info.DeviceType = DeviceTypeTablet
+	case strings.Contains(userAgent, "Mobi") || strings.Contains(userAgent, "iPhone"):
+		info.DeviceType = DeviceTypeMobile
+	case info.OS != "Unknown":
+		info.DeviceType = DeviceTypeDesktop
+	}
+
+	return info
+}
+
+// versionSuffix returns " <major>" when pattern finds a version in userAgent
+func versionSuffix(pattern *regexp.Regexp, userAgent string) string {
+	if match := pattern.FindStringSubmatch(userAgent); match != nil {
+		return " " + match[1]
+	}
+	return ""
+}
+
+// LocateIP describes where a client address is when that can be told from the address
+// alone: loopback and private addresses never reach a geolocation database. It returns
+// "" for public addresses, which are located from the edge proxy's geo headers instead.
+func LocateIP(ip string) string {
+	addr := net.ParseIP(strings.TrimSpace(ip))
+	switch {
+	case addr == nil:
+		return ""
+	case addr.IsLoopback():
+		return "Localhost"
+	case addr.IsPrivate() || addr.IsLinkLocalUnicast():
+		return "Private network"
+	}
+	return ""
+}
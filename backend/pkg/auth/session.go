@@ -2,7 +2,9 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -11,8 +13,16 @@ import (
 )
 
 const (
-	// DefaultSessionDuration is the default session duration (24 hours)
-	DefaultSessionDuration = 24 * time.Hour
+	// AccessTokenDuration is how long an access token is accepted before it must be refreshed
+	AccessTokenDuration = 15 * time.Minute
+	// RefreshTokenDuration is how long an unused refresh token stays valid (idle timeout)
+	RefreshTokenDuration = 7 * 24 * time.Hour
+	// MaxSessionLifetime caps how long a session can be kept alive by refreshing
+	MaxSessionLifetime = 30 * 24 * time.Hour
+	// DefaultImpersonationDuration is how long an impersonation session lasts unless requested otherwise
+	DefaultImpersonationDuration = 30 * time.Minute
+	// MaxImpersonationDuration caps impersonation sessions, which cannot be refreshed
+	MaxImpersonationDuration = time.Hour
 	// SessionTokenLength is the length of session tokens in bytes
 	SessionTokenLength = 32
 )
@@ -26,10 +36,18 @@ func GenerateSessionToken() (string, error) {
 	return base64.URLEncoding.EncodeToString(bytes), nil
 }
 
-// CreateSession creates a new session for a user
-func CreateSession(userID uuid.UUID, ipAddress, userAgent string, duration time.Duration) (*models.Session, error) {
-	if duration == 0 {
-		duration = DefaultSessionDuration
+// HashRefreshToken returns the SHA-256 hex digest under which a refresh token is stored.
+// Refresh tokens are high-entropy random values, so a fast hash is sufficient.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSession creates a new session for a user with a fresh access token. The session can
+// be refreshed until it is maxLifetime old (MaxSessionLifetime when zero).
+func CreateSession(userID uuid.UUID, ipAddress, userAgent string, maxLifetime time.Duration) (*models.Session, error) {
+	if maxLifetime == 0 {
+		maxLifetime = MaxSessionLifetime
 	}
 
 	token, err := GenerateSessionToken()
@@ -37,18 +55,43 @@ func CreateSession(userID uuid.UUID, ipAddress, userAgent string, duration time.
 		return nil, err
 	}
 
+	now := time.Now()
+	device := ParseUserAgent(userAgent)
 	session := &models.Session{
-		UserID:    userID,
-		Token:     token,
-		ExpiresAt: time.Now().Add(duration),
-		IPAddress: ipAddress,
-		UserAgent: userAgent,
-		IsActive:  true,
+		UserID:     userID,
+		Token:      token,
+		ExpiresAt:  now.Add(maxLifetime),
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		Browser:    device.Browser,
+		OS:         device.OS,
+		DeviceType: device.DeviceType,
+		IsActive:   true,
 	}
+	session.AccessExpiresAt = AccessTokenExpiry(session, now)
 
 	return session, nil
 }
 
+// RefreshTokenExpiry returns when a refresh token issued at now for session expires: after
+// the idle timeout, but never later than the session itself
+func RefreshTokenExpiry(session *models.Session, now time.Time) time.Time {
+	return capTime(now.Add(RefreshTokenDuration), session.ExpiresAt)
+}
+
+// AccessTokenExpiry returns when an access token issued at now for session expires
+func AccessTokenExpiry(session *models.Session, now time.Time) time.Time {
+	return capTime(now.Add(AccessTokenDuration), session.ExpiresAt)
+}
+
+// capTime returns t, or limit when t is later
+func capTime(t, limit time.Time) time.Time {
+	if t.After(limit) {
+		return limit
+	}
+	return t
+}
+
 // ValidateSessionToken validates that a token meets security requirements
 func ValidateSessionToken(token string) error {
 	if token == "" {
@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"crypto/rand"
 	"encoding/base64"
-	"encoding/hex"
 	"fmt"
 	"image/png"
 	"strings"
@@ -51,23 +50,64 @@ func GenerateQRCode(key *otp.Key) (string, error) {
 	return fmt.Sprintf("data:image/png;base64,%s", encoded), nil
 }
 
-// GenerateBackupCodes generates a set of backup codes
+// BackupCodeCount is the number of recovery codes issued when 2FA is enabled or the codes are regenerated
+const BackupCodeCount = 10
+
+// backupCodeAlphabet avoids characters that are easily confused when read back (0/O, 1/I)
+const backupCodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// GenerateBackupCodes generates single-use recovery codes formatted as XXXXX-XXXXX (50 bits each)
 func GenerateBackupCodes(count int) ([]string, error) {
 	codes := make([]string, count)
 	for i := 0; i < count; i++ {
-		// Generate 8 random bytes
-		b := make([]byte, 8)
+		b := make([]byte, 10)
 		if _, err := rand.Read(b); err != nil {
 			return nil, err
 		}
-		// Convert to hex string (16 chars)
-		token := hex.EncodeToString(b)
-		// Format as XXXX-XXXX for readability
-		codes[i] = strings.ToUpper(token[:4] + "-" + token[4:8])
+		for j := range b {
+			b[j] = backupCodeAlphabet[int(b[j])%len(backupCodeAlphabet)]
+		}
+		codes[i] = string(b[:5]) + "-" + string(b[5:])
 	}
 	return codes, nil
 }
 
+// NormalizeBackupCode uppercases a backup code and strips separators and spaces
+func NormalizeBackupCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// IsBackupCodeFormat reports whether code looks like a backup code rather than a TOTP code.
+// Codes issued before hashing was introduced were 8 hex characters (XXXX-XXXX).
+func IsBackupCodeFormat(code string) bool {
+	normalized := NormalizeBackupCode(code)
+	alphabet := backupCodeAlphabet
+	switch len(normalized) {
+	case 10:
+	case 8:
+		alphabet = "0123456789ABCDEF"
+	default:
+		return false
+	}
+	for _, r := range normalized {
+		if !strings.ContainsRune(alphabet, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// HashBackupCode hashes a backup code for storage
+func HashBackupCode(code string) (string, error) {
+	return HashPassword(NormalizeBackupCode(code))
+}
+
+// CheckBackupCode compares a backup code with a stored hash
+func CheckBackupCode(code, hash string) bool {
+	return CheckPasswordHash(NormalizeBackupCode(code), hash)
+}
+
 // GetTOTPURL returns the provisioning URI for the TOTP key
 func GetTOTPURL(key *otp.Key) string {
 	return key.URL()
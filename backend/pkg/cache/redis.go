@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var Client *redis.Client
+
+// Connect establishes the Redis connection from a redis:// URL
+func Connect(url string) error {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	Client = client
+	log.Println("Redis connected successfully")
+	return nil
+}
+
+// GetClient returns the global Redis client, or nil when Redis is not configured
+func GetClient() *redis.Client {
+	return Client
+}
+
+// Close closes the Redis connection
+func Close() error {
+	if Client != nil {
+		return Client.Close()
+	}
+	return nil
+}
+
+// HealthCheck verifies Redis connectivity
+func HealthCheck(ctx context.Context) error {
+	if Client == nil {
+		return fmt.Errorf("redis not initialized")
+	}
+	return Client.Ping(ctx).Err()
+}
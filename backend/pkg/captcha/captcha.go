@@ -0,0 +1,89 @@
+// Package captcha verifies captcha responses with the provider's siteverify endpoint. hCaptcha,
+// Google reCAPTCHA and Cloudflare Turnstile share the same protocol: the secret, the client's
+// response token and optionally its IP are posted as a form and the provider answers with a
+// JSON success flag.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Siteverify endpoints of the supported providers
+const (
+	HCaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+	ReCaptchaVerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+	TurnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+)
+
+// DefaultTimeout bounds a verification request
+const DefaultTimeout = 10 * time.Second
+
+// maxResponseBody bounds how much of a provider response is read
+const maxResponseBody = 64 * 1024
+
+// Verifier checks captcha responses against a provider
+type Verifier struct {
+	verifyURL string
+	secret    string
+	client    *http.Client
+}
+
+// NewVerifier creates a verifier for a siteverify endpoint and secret key
+func NewVerifier(verifyURL, secret string) *Verifier {
+	return &Verifier{
+		verifyURL: verifyURL,
+		secret:    secret,
+		client:    &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// verifyResponse is the provider's answer
+type verifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// Verify reports whether a response token was issued for a solved challenge. An error is
+// returned only when the provider could not be asked; rejected tokens return false.
+func (v *Verifier) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	if strings.TrimSpace(token) == "" {
+		return false, nil
+	}
+
+	form := url.Values{
+		"secret":   {v.secret},
+		"response": {token},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, fmt.Errorf("failed to build captcha verification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("captcha verification request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha verification returned HTTP %d", resp.StatusCode)
+	}
+
+	var result verifyResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBody)).Decode(&result); err != nil {
+		return false, fmt.Errorf("invalid captcha verification response: %w", err)
+	}
+	return result.Success, nil
+}
@@ -4,12 +4,27 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/cyops/cyops-backend/pkg/kms"
+	"github.com/cyops/cyops-backend/pkg/signing"
+	"github.com/cyops/cyops-backend/pkg/storage"
 )
 
 type Config struct {
 	// Server
 	Port  string
 	GoEnv string
+	// Seconds a stopping server waits for running imports, reports, backups and jobs to reach
+	// a checkpoint; keep it below the orchestrator's termination grace period
+	ShutdownTimeoutSeconds int
+	// Request body limit of routes without an upload limit of their own (MB)
+	RequestBodyLimitMB int
+	// Comma-separated addresses or CIDRs of reverse proxies whose X-Real-IP header gives the
+	// client address; requests from other peers are attributed to the peer itself
+	TrustedProxies string
 
 	// Database
 	DBHost     string
@@ -19,9 +34,111 @@ type Config struct {
 	DBPassword string
 	DBSSLMode  string
 
+	// Database connection pool and slow query logging (durations in minutes and milliseconds;
+	// a zero threshold disables that log level)
+	DBMaxOpenConns             int
+	DBMaxIdleConns             int
+	DBConnMaxLifetimeMinutes   int
+	DBConnMaxIdleTimeMinutes   int
+	DBSlowQueryMs              int
+	DBCriticalQueryMs          int
+	DBPoolStatsIntervalSeconds int // Zero disables the periodic pool stats log
+
 	// Redis
 	RedisURL string
 
+	// Stats/report cache (requires Redis)
+	CacheEnabled    bool
+	CacheTTLSeconds int
+
+	// Where rate limit counters are kept: "memory" (per instance) or "redis" (shared by all
+	// replicas, falling back to memory while Redis is unreachable)
+	RateLimitStore string
+
+	// Tracing (OTLP exporter settings come from the standard OTEL_EXPORTER_OTLP_* variables)
+	TracingEnabled     bool
+	TracingServiceName string
+	TracingSampleRatio float64
+
+	// Read-only GraphQL endpoint at /api/v1/graphql
+	GraphQLEnabled bool
+
+	// Response envelope of /api/v1 ("legacy" or "standard"); clients may override it per request
+	// with the X-API-Envelope header
+	APIResponseEnvelope string
+
+	// Deprecation of /api/v1 in favour of /api/v2 (dates as YYYY-MM-DD; empty when not scheduled)
+	APIV1DeprecationDate string
+	APIV1SunsetDate      string
+	APIDeprecationDocURL string
+
+	// Search backend ("postgres" or "opensearch")
+	SearchBackend         string
+	OpenSearchURL         string
+	OpenSearchUsername    string
+	OpenSearchPassword    string
+	OpenSearchIndexPrefix string
+
+	// Attachment storage credentials (the backend itself is chosen by the attachment_storage setting)
+	StorageS3AccessKeyID     string
+	StorageS3SecretAccessKey string
+	StorageAzureAccountKey   string
+
+	// Report summarizer API key (the provider itself is chosen by the report_summarizer setting)
+	LLMAPIKey string
+
+	// Known-vulnerability database SBOM components are correlated against (empty disables it)
+	OSVAPIURL string
+
+	// Public exploit feeds synced daily to flag exploitable vulnerabilities (empty disables a feed)
+	ExploitDBFeedURL  string
+	MetasploitFeedURL string
+
+	// NVD CVE API vulnerabilities are checked against for patch and advisory details (empty
+	// disables it); the optional API key raises the NVD rate limit
+	NVDAPIURL string
+	NVDAPIKey string
+
+	// Vulnerabilities written per transaction by scan imports, and scans exported at once from
+	// Nessus by multi-scan imports
+	ImportBatchSize     int
+	NessusImportWorkers int
+	// Largest .nessus file accepted by uploads (MB)
+	NessusUploadLimitMB int
+
+	// Destination of database backups (BACKUP_STORAGE selects local or s3); pg_dump and
+	// pg_restore must be on the PATH
+	BackupStorage           string
+	BackupDir               string
+	BackupS3Endpoint        string
+	BackupS3Region          string
+	BackupS3Bucket          string
+	BackupS3Prefix          string
+	BackupS3PathStyle       bool
+	BackupS3AccessKeyID     string
+	BackupS3SecretAccessKey string
+
+	// Envelope encryption of integration credentials (KMS_PROVIDER selects local, aws or vault)
+	KMSProvider           string
+	KMSLocalKey           string
+	KMSPreviousLocalKeys  string
+	KMSAWSRegion          string
+	KMSAWSKeyID           string
+	KMSAWSEndpoint        string
+	KMSAWSAccessKeyID     string
+	KMSAWSSecretAccessKey string
+	KMSAWSSessionToken    string
+	KMSVaultAddress       string
+	KMSVaultToken         string
+	KMSVaultNamespace     string
+	KMSVaultMount         string
+	KMSVaultKey           string
+
+	// Ed25519 key generated reports and CSV exports are signed with (base64 seed or PEM), derived
+	// from ENCRYPTION_KEY when unset; previous keys only verify older signatures
+	ReportSigningKey          string
+	ReportSigningPreviousKeys string
+
 	// JWT & Session
 	JWTSecret     string
 	SessionSecret string
@@ -43,17 +160,42 @@ type Config struct {
 	// CORS
 	CORSOrigins string
 
+	// Trust geolocation headers set by the edge proxy (CF-IPCountry, CloudFront-Viewer-Country,
+	// X-Geo-Country and their city counterparts) when describing session locations
+	GeoHeadersTrusted bool
+
+	// Public vulnerability disclosure form at /api/v1/disclosures. Submissions must carry a
+	// captcha response verified with the secret at the siteverify URL (hCaptcha, reCAPTCHA or
+	// Turnstile); without a secret they are only accepted in development.
+	DisclosuresEnabled bool
+	CaptchaVerifyURL   string
+	CaptchaSecret      string
+
 	// Admin Seed
 	AdminEmail    string
 	AdminPassword string
 	AdminName     string
+
+	// Secrets read from a Vault KV version 2 engine, overriding their environment variables
+	// (see LoadVaultSecrets). Authenticates with VaultToken or an AppRole.
+	VaultAddress       string
+	VaultToken         string
+	VaultRoleID        string
+	VaultSecretID      string
+	VaultNamespace     string
+	VaultKVMount       string
+	VaultSecretsPath   string
+	VaultRenewInterval int // Minutes between token renewals and secret refreshes
 }
 
 func Load() *Config {
 	return &Config{
 		// Server
-		Port:  getEnv("PORT", "8080"),
-		GoEnv: getEnv("GO_ENV", "development"),
+		Port:                   getEnv("PORT", "8080"),
+		GoEnv:                  getEnv("GO_ENV", "development"),
+		ShutdownTimeoutSeconds: getEnvAsInt("SHUTDOWN_TIMEOUT_SECONDS", 25),
+		RequestBodyLimitMB:     getEnvAsInt("REQUEST_BODY_LIMIT_MB", 5),
+		TrustedProxies:         getEnv("TRUSTED_PROXIES", ""),
 
 		// Database
 		DBHost:     getEnv("DB_HOST", "localhost"),
@@ -63,9 +205,103 @@ func Load() *Config {
 		DBPassword: getEnv("DB_PASSWORD", "postgres"),
 		DBSSLMode:  getEnv("DB_SSL_MODE", "disable"),
 
+		// Database pool
+		DBMaxOpenConns:             getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:             getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
+		DBConnMaxLifetimeMinutes:   getEnvAsInt("DB_CONN_MAX_LIFETIME_MINUTES", 5),
+		DBConnMaxIdleTimeMinutes:   getEnvAsInt("DB_CONN_MAX_IDLE_TIME_MINUTES", 0),
+		DBSlowQueryMs:              getEnvAsInt("DB_SLOW_QUERY_MS", 200),
+		DBCriticalQueryMs:          getEnvAsInt("DB_CRITICAL_QUERY_MS", 2000),
+		DBPoolStatsIntervalSeconds: getEnvAsInt("DB_POOL_STATS_INTERVAL_SECONDS", 300),
+
 		// Redis
 		RedisURL: getEnv("REDIS_URL", "redis://localhost:6379"),
 
+		// Stats/report cache
+		CacheEnabled:    getEnv("CACHE_ENABLED", "false") == "true",
+		CacheTTLSeconds: getEnvAsInt("CACHE_TTL_SECONDS", 300),
+
+		// Rate limits
+		RateLimitStore: getEnv("RATE_LIMIT_STORE", "memory"),
+
+		// Tracing
+		TracingEnabled:     getEnv("TRACING_ENABLED", "false") == "true",
+		TracingServiceName: getEnv("OTEL_SERVICE_NAME", "cyops-backend"),
+		TracingSampleRatio: getEnvAsFloat("OTEL_TRACES_SAMPLER_ARG", 1.0),
+
+		// GraphQL
+		GraphQLEnabled: getEnv("GRAPHQL_ENABLED", "false") == "true",
+
+		// Response envelope
+		APIResponseEnvelope: getEnv("API_RESPONSE_ENVELOPE", "legacy"),
+
+		// API versioning
+		APIV1DeprecationDate: getEnv("API_V1_DEPRECATION_DATE", ""),
+		APIV1SunsetDate:      getEnv("API_V1_SUNSET_DATE", ""),
+		APIDeprecationDocURL: getEnv("API_DEPRECATION_DOC_URL", ""),
+
+		// Search backend
+		SearchBackend:         getEnv("SEARCH_BACKEND", "postgres"),
+		OpenSearchURL:         getEnv("OPENSEARCH_URL", "http://localhost:9200"),
+		OpenSearchUsername:    getEnv("OPENSEARCH_USERNAME", ""),
+		OpenSearchPassword:    getEnv("OPENSEARCH_PASSWORD", ""),
+		OpenSearchIndexPrefix: getEnv("OPENSEARCH_INDEX_PREFIX", "cyops"),
+
+		// Attachment storage credentials
+		StorageS3AccessKeyID:     getEnv("STORAGE_S3_ACCESS_KEY_ID", ""),
+		StorageS3SecretAccessKey: getEnv("STORAGE_S3_SECRET_ACCESS_KEY", ""),
+		StorageAzureAccountKey:   getEnv("STORAGE_AZURE_ACCOUNT_KEY", ""),
+
+		// Report summarizer API key
+		LLMAPIKey: getEnv("LLM_API_KEY", ""),
+
+		// SBOM vulnerability correlation
+		OSVAPIURL: getEnvOrEmpty("OSV_API_URL", "https://api.osv.dev"),
+
+		// Exploit availability enrichment
+		ExploitDBFeedURL:  getEnvOrEmpty("EXPLOITDB_FEED_URL", "https://gitlab.com/exploit-database/exploitdb/-/raw/main/files_exploits.csv"),
+		MetasploitFeedURL: getEnvOrEmpty("METASPLOIT_FEED_URL", "https://raw.githubusercontent.com/rapid7/metasploit-framework/master/db/modules_metadata_base.json"),
+
+		// Patch and advisory enrichment
+		NVDAPIURL: getEnvOrEmpty("NVD_API_URL", "https://services.nvd.nist.gov/rest/json/cves/2.0"),
+		NVDAPIKey: getEnv("NVD_API_KEY", ""),
+
+		// Scan imports
+		ImportBatchSize:     getEnvAsInt("IMPORT_BATCH_SIZE", 500),
+		NessusImportWorkers: getEnvAsInt("NESSUS_IMPORT_WORKERS", 4),
+		NessusUploadLimitMB: getEnvAsInt("NESSUS_UPLOAD_LIMIT_MB", 200),
+
+		// Backups
+		BackupStorage:           getEnv("BACKUP_STORAGE", "local"),
+		BackupDir:               getEnv("BACKUP_DIR", "./backups"),
+		BackupS3Endpoint:        getEnv("BACKUP_S3_ENDPOINT", ""),
+		BackupS3Region:          getEnv("BACKUP_S3_REGION", "us-east-1"),
+		BackupS3Bucket:          getEnv("BACKUP_S3_BUCKET", ""),
+		BackupS3Prefix:          getEnv("BACKUP_S3_PREFIX", ""),
+		BackupS3PathStyle:       getEnv("BACKUP_S3_PATH_STYLE", "false") == "true",
+		BackupS3AccessKeyID:     getEnv("BACKUP_S3_ACCESS_KEY_ID", ""),
+		BackupS3SecretAccessKey: getEnv("BACKUP_S3_SECRET_ACCESS_KEY", ""),
+
+		// Envelope encryption
+		KMSProvider:           getEnv("KMS_PROVIDER", "local"),
+		KMSLocalKey:           getEnv("KMS_LOCAL_KEY", ""),
+		KMSPreviousLocalKeys:  getEnv("KMS_PREVIOUS_LOCAL_KEYS", ""),
+		KMSAWSRegion:          getEnv("KMS_AWS_REGION", "us-east-1"),
+		KMSAWSKeyID:           getEnv("KMS_AWS_KEY_ID", ""),
+		KMSAWSEndpoint:        getEnv("KMS_AWS_ENDPOINT", ""),
+		KMSAWSAccessKeyID:     getEnv("KMS_AWS_ACCESS_KEY_ID", ""),
+		KMSAWSSecretAccessKey: getEnv("KMS_AWS_SECRET_ACCESS_KEY", ""),
+		KMSAWSSessionToken:    getEnv("KMS_AWS_SESSION_TOKEN", ""),
+		KMSVaultAddress:       getEnv("KMS_VAULT_ADDR", ""),
+		KMSVaultToken:         getEnv("KMS_VAULT_TOKEN", ""),
+		KMSVaultNamespace:     getEnv("KMS_VAULT_NAMESPACE", ""),
+		KMSVaultMount:         getEnv("KMS_VAULT_MOUNT", "transit"),
+		KMSVaultKey:           getEnv("KMS_VAULT_KEY", ""),
+
+		// Report signing
+		ReportSigningKey:          getEnv("REPORT_SIGNING_KEY", ""),
+		ReportSigningPreviousKeys: getEnv("REPORT_SIGNING_PREVIOUS_KEYS", ""),
+
 		// JWT & Session
 		JWTSecret:     getEnv("JWT_SECRET", "dev-jwt-secret"),
 		SessionSecret: getEnv("SESSION_SECRET", "dev-session-secret"),
@@ -87,10 +323,28 @@ func Load() *Config {
 		// CORS
 		CORSOrigins: getEnv("CORS_ORIGINS", "*"),
 
+		// Session geolocation
+		GeoHeadersTrusted: getEnv("GEO_HEADERS_TRUSTED", "false") == "true",
+
+		// Public disclosure form
+		DisclosuresEnabled: getEnv("DISCLOSURES_ENABLED", "false") == "true",
+		CaptchaVerifyURL:   getEnv("CAPTCHA_VERIFY_URL", "https://api.hcaptcha.com/siteverify"),
+		CaptchaSecret:      getEnv("CAPTCHA_SECRET", ""),
+
 		// Admin Seed
 		AdminEmail:    getEnv("ADMIN_EMAIL", ""),
 		AdminPassword: getEnv("ADMIN_PASSWORD", ""),
 		AdminName:     getEnv("ADMIN_NAME", "System Administrator"),
+
+		// Vault secrets
+		VaultAddress:       getEnv("VAULT_ADDR", ""),
+		VaultToken:         getEnv("VAULT_TOKEN", ""),
+		VaultRoleID:        getEnv("VAULT_ROLE_ID", ""),
+		VaultSecretID:      getEnv("VAULT_SECRET_ID", ""),
+		VaultNamespace:     getEnv("VAULT_NAMESPACE", ""),
+		VaultKVMount:       getEnv("VAULT_KV_MOUNT", "secret"),
+		VaultSecretsPath:   getEnv("VAULT_SECRETS_PATH", "cyops"),
+		VaultRenewInterval: getEnvAsInt("VAULT_RENEW_INTERVAL_MINUTES", 15),
 	}
 }
 
@@ -101,6 +355,90 @@ func (c *Config) DatabaseDSN() string {
 	)
 }
 
+// DatabaseConnection returns the connection, pool and slow query settings of the database
+func (c *Config) DatabaseConnection() database.ConnectionConfig {
+	return database.ConnectionConfig{
+		DSN:                    c.DatabaseDSN(),
+		IsDevelopment:          c.GoEnv == "development",
+		MaxOpenConns:           c.DBMaxOpenConns,
+		MaxIdleConns:           c.DBMaxIdleConns,
+		ConnMaxLifetime:        time.Duration(c.DBConnMaxLifetimeMinutes) * time.Minute,
+		ConnMaxIdleTime:        time.Duration(c.DBConnMaxIdleTimeMinutes) * time.Minute,
+		SlowQueryThreshold:     time.Duration(c.DBSlowQueryMs) * time.Millisecond,
+		CriticalQueryThreshold: time.Duration(c.DBCriticalQueryMs) * time.Millisecond,
+	}
+}
+
+// BackupStorageConfig returns the store backups are written to
+func (c *Config) BackupStorageConfig() storage.Config {
+	return storage.Config{
+		Driver: c.BackupStorage,
+		Local:  storage.LocalConfig{Root: c.BackupDir},
+		S3: storage.S3Config{
+			Endpoint:        c.BackupS3Endpoint,
+			Region:          c.BackupS3Region,
+			Bucket:          c.BackupS3Bucket,
+			Prefix:          c.BackupS3Prefix,
+			PathStyle:       c.BackupS3PathStyle,
+			AccessKeyID:     c.BackupS3AccessKeyID,
+			SecretAccessKey: c.BackupS3SecretAccessKey,
+		},
+	}
+}
+
+// KMS returns the envelope encryption settings. Without a dedicated local key, the local key is
+// derived from ENCRYPTION_KEY.
+func (c *Config) KMS() kms.Config {
+	return kms.Config{
+		Provider:          c.KMSProvider,
+		LocalKey:          c.KMSLocalKey,
+		PreviousLocalKeys: strings.Split(c.KMSPreviousLocalKeys, ","),
+		FallbackSecret:    c.EncryptionKey,
+		AWS: kms.AWSConfig{
+			Region:          c.KMSAWSRegion,
+			KeyID:           c.KMSAWSKeyID,
+			Endpoint:        c.KMSAWSEndpoint,
+			AccessKeyID:     c.KMSAWSAccessKeyID,
+			SecretAccessKey: c.KMSAWSSecretAccessKey,
+			SessionToken:    c.KMSAWSSessionToken,
+		},
+		Vault: kms.VaultConfig{
+			Address:   c.KMSVaultAddress,
+			Token:     c.KMSVaultToken,
+			Namespace: c.KMSVaultNamespace,
+			Mount:     c.KMSVaultMount,
+			KeyName:   c.KMSVaultKey,
+		},
+	}
+}
+
+// ReportSigner returns the signer of report artifacts. Without a dedicated key, the key is
+// derived from ENCRYPTION_KEY; once a dedicated key is configured the derived key still verifies
+// reports signed before.
+func (c *Config) ReportSigner() (*signing.Signer, error) {
+	var previous []*signing.Key
+	active := signing.DeriveKey(c.EncryptionKey)
+	if c.ReportSigningKey != "" {
+		key, err := signing.ParseKey(c.ReportSigningKey)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, active)
+		active = key
+	}
+	for _, encoded := range strings.Split(c.ReportSigningPreviousKeys, ",") {
+		if encoded = strings.TrimSpace(encoded); encoded == "" {
+			continue
+		}
+		key, err := signing.ParseKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous report signing key: %w", err)
+		}
+		previous = append(previous, key)
+	}
+	return signing.NewSigner(active, previous...), nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -108,6 +446,14 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvOrEmpty is getEnv for settings where an explicitly empty value means "disabled"
+func getEnvOrEmpty(key, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
 func getEnvAsInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
@@ -116,3 +462,12 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,254 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// secretFields maps the keys a Vault secret may hold to the settings they override. Keys are the
+// names of the environment variables they replace, so secrets can move between the two freely.
+func (c *Config) secretFields() map[string]*string {
+	return map[string]*string{
+		"DB_USER":                      &c.DBUser,
+		"DB_PASSWORD":                  &c.DBPassword,
+		"REDIS_URL":                    &c.RedisURL,
+		"SMTP_USERNAME":                &c.SMTPUsername,
+		"SMTP_PASSWORD":                &c.SMTPPassword,
+		"JWT_SECRET":                   &c.JWTSecret,
+		"SESSION_SECRET":               &c.SessionSecret,
+		"ENCRYPTION_KEY":               &c.EncryptionKey,
+		"OPENSEARCH_PASSWORD":          &c.OpenSearchPassword,
+		"STORAGE_S3_ACCESS_KEY_ID":     &c.StorageS3AccessKeyID,
+		"STORAGE_S3_SECRET_ACCESS_KEY": &c.StorageS3SecretAccessKey,
+		"STORAGE_AZURE_ACCOUNT_KEY":    &c.StorageAzureAccountKey,
+		"LLM_API_KEY":                  &c.LLMAPIKey,
+		"BACKUP_S3_ACCESS_KEY_ID":      &c.BackupS3AccessKeyID,
+		"BACKUP_S3_SECRET_ACCESS_KEY":  &c.BackupS3SecretAccessKey,
+		"KMS_LOCAL_KEY":                &c.KMSLocalKey,
+		"KMS_PREVIOUS_LOCAL_KEYS":      &c.KMSPreviousLocalKeys,
+		"KMS_AWS_ACCESS_KEY_ID":        &c.KMSAWSAccessKeyID,
+		"KMS_AWS_SECRET_ACCESS_KEY":    &c.KMSAWSSecretAccessKey,
+		"KMS_AWS_SESSION_TOKEN":        &c.KMSAWSSessionToken,
+		"KMS_VAULT_TOKEN":              &c.KMSVaultToken,
+		"REPORT_SIGNING_KEY":           &c.ReportSigningKey,
+		"REPORT_SIGNING_PREVIOUS_KEYS": &c.ReportSigningPreviousKeys,
+		"GOOGLE_CLIENT_SECRET":         &c.GoogleClientSecret,
+		"GITHUB_CLIENT_SECRET":         &c.GitHubClientSecret,
+		"ADMIN_PASSWORD":               &c.AdminPassword,
+		"CAPTCHA_SECRET":               &c.CaptchaSecret,
+	}
+}
+
+// VaultSecrets reads application secrets from a Vault KV version 2 secret and keeps its Vault
+// token alive
+type VaultSecrets struct {
+	address    string
+	roleID     string
+	secretID   string
+	namespace  string
+	mount      string
+	path       string
+	interval   time.Duration
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	token  string
+	values map[string]string
+}
+
+// LoadVaultSecrets overrides the secrets of cfg with those stored in Vault when VAULT_ADDR is set,
+// and returns nil when it is not. Settings the Vault secret does not hold keep their environment
+// value.
+func LoadVaultSecrets(ctx context.Context, cfg *Config) (*VaultSecrets, error) {
+	if cfg.VaultAddress == "" {
+		return nil, nil
+	}
+	if cfg.VaultToken == "" && (cfg.VaultRoleID == "" || cfg.VaultSecretID == "") {
+		return nil, fmt.Errorf("vault secrets need VAULT_TOKEN or VAULT_ROLE_ID and VAULT_SECRET_ID")
+	}
+	address, err := url.Parse(strings.TrimRight(cfg.VaultAddress, "/"))
+	if err != nil || address.Host == "" || (address.Scheme != "http" && address.Scheme != "https") {
+		return nil, fmt.Errorf("invalid vault address: %s", cfg.VaultAddress)
+	}
+
+	interval := time.Duration(cfg.VaultRenewInterval) * time.Minute
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	v := &VaultSecrets{
+		address:    address.String(),
+		roleID:     cfg.VaultRoleID,
+		secretID:   cfg.VaultSecretID,
+		namespace:  cfg.VaultNamespace,
+		mount:      strings.Trim(cfg.VaultKVMount, "/"),
+		path:       strings.Trim(cfg.VaultSecretsPath, "/"),
+		interval:   interval,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		token:      cfg.VaultToken,
+	}
+	if v.token == "" {
+		if err := v.login(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	values, err := v.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v.values = values
+	cfg.applySecrets(values)
+	return v, nil
+}
+
+// applySecrets overrides settings with the secrets that are set
+func (c *Config) applySecrets(values map[string]string) {
+	for key, field := range c.secretFields() {
+		if value, ok := values[key]; ok && value != "" {
+			*field = value
+		}
+	}
+}
+
+// Refresh renews the Vault token (logging in again with the AppRole when it can no longer be
+// renewed), re-reads the secret and returns the secrets whose values changed
+func (v *VaultSecrets) Refresh(ctx context.Context) (map[string]string, error) {
+	if err := v.request(ctx, "POST", "/v1/auth/token/renew-self", map[string]string{}, nil); err != nil {
+		if v.roleID == "" {
+			return nil, fmt.Errorf("failed to renew vault token: %w", err)
+		}
+		if err := v.login(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	values, err := v.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	changed := make(map[string]string)
+	for key, value := range values {
+		if v.values[key] != value {
+			changed[key] = value
+		}
+	}
+	v.values = values
+	return changed, nil
+}
+
+// Watch refreshes the secrets every VAULT_RENEW_INTERVAL_MINUTES until ctx is done, passing the
+// secrets whose values changed to onChange and refresh failures to onError
+func (v *VaultSecrets) Watch(ctx context.Context, onChange func(changed map[string]string), onError func(error)) {
+	ticker := time.NewTicker(v.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := v.Refresh(ctx)
+			if err != nil {
+				onError(err)
+				continue
+			}
+			if len(changed) > 0 {
+				onChange(changed)
+			}
+		}
+	}
+}
+
+// login exchanges the AppRole credentials for a token
+func (v *VaultSecrets) login(ctx context.Context) error {
+	v.mu.Lock()
+	v.token = ""
+	v.mu.Unlock()
+
+	var out struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	err := v.request(ctx, "POST", "/v1/auth/approle/login", map[string]string{
+		"role_id":   v.roleID,
+		"secret_id": v.secretID,
+	}, &out)
+	if err != nil {
+		return fmt.Errorf("vault approle login failed: %w", err)
+	}
+	v.mu.Lock()
+	v.token = out.Auth.ClientToken
+	v.mu.Unlock()
+	return nil
+}
+
+// read returns the string values of the secret
+func (v *VaultSecrets) read(ctx context.Context) (map[string]string, error) {
+	var out struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := v.request(ctx, "GET", "/v1/"+v.mount+"/data/"+v.path, nil, &out); err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %s/%s: %w", v.mount, v.path, err)
+	}
+
+	values := make(map[string]string, len(out.Data.Data))
+	for key, value := range out.Data.Data {
+		if s, ok := value.(string); ok {
+			values[key] = s
+		}
+	}
+	return values, nil
+}
+
+// request calls the Vault API, decoding the response into out when it is not nil
+func (v *VaultSecrets) request(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, v.address+path, body)
+	if err != nil {
+		return err
+	}
+	v.mu.Lock()
+	token := v.token
+	v.mu.Unlock()
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("vault returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,121 @@
+// Package cwe identifies weakness types from the Common Weakness Enumeration
+// (https://cwe.mitre.org). Names and descriptions of the weaknesses scanners and advisories
+// commonly report are embedded from weaknesses.json; other valid IDs are accepted without them.
+package cwe
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Weakness is a CWE entry
+type Weakness struct {
+	ID          string `json:"id"` // CWE-79
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+//go:embed weaknesses.json
+var weaknessesJSON []byte
+
+var (
+	// weaknesses are the embedded entries, ordered by number
+	weaknesses []Weakness
+	// byID maps a normalized ID to its entry
+	byID = map[string]Weakness{}
+)
+
+func init() {
+	if err := json.Unmarshal(weaknessesJSON, &weaknesses); err != nil {
+		panic(fmt.Sprintf("cwe: invalid dataset: %v", err))
+	}
+	for _, weakness := range weaknesses {
+		byID[weakness.ID] = weakness
+	}
+}
+
+// Normalize parses a CWE ID written as 79, CWE-79, CWE:79 or "CWE 79" into its canonical
+// CWE-79 form. NVD placeholders such as NVD-CWE-Other are not CWE IDs and are rejected.
+func Normalize(raw string) (string, bool) {
+	id := strings.ToUpper(strings.TrimSpace(raw))
+	if rest, ok := strings.CutPrefix(id, "CWE"); ok {
+		id = strings.TrimLeft(rest, "-: ")
+	}
+	number, err := strconv.Atoi(id)
+	if err != nil || number <= 0 || len(id) > 6 {
+		return "", false
+	}
+	return fmt.Sprintf("CWE-%d", number), true
+}
+
+// NormalizeIDs normalizes a list of CWE IDs, dropping invalid and duplicate ones and keeping
+// the order they were first listed in
+func NormalizeIDs(raw []string) []string {
+	var ids []string
+	seen := make(map[string]bool, len(raw))
+	for _, r := range raw {
+		id, ok := Normalize(r)
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// ParseIDs normalizes a list of CWE IDs entered by a user, rejecting invalid ones. Duplicates
+// are dropped.
+func ParseIDs(raw []string) ([]string, error) {
+	for _, r := range raw {
+		if _, ok := Normalize(r); !ok {
+			return nil, fmt.Errorf("invalid CWE ID '%s', use CWE-<number>", r)
+		}
+	}
+	return NormalizeIDs(raw), nil
+}
+
+// Lookup returns the embedded entry of a CWE ID in any form Normalize accepts
+func Lookup(raw string) (Weakness, bool) {
+	id, ok := Normalize(raw)
+	if !ok {
+		return Weakness{}, false
+	}
+	weakness, ok := byID[id]
+	return weakness, ok
+}
+
+// Name returns the name of a CWE ID, or "" when it isn't in the embedded dataset
+func Name(raw string) string {
+	weakness, _ := Lookup(raw)
+	return weakness.Name
+}
+
+// All returns the embedded entries ordered by number
+func All() []Weakness {
+	return append([]Weakness(nil), weaknesses...)
+}
+
+// Search returns the embedded entries whose ID or name contains query (case-insensitive),
+// ordered by number
+func Search(query string) []Weakness {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return All()
+	}
+	if id, ok := Normalize(query); ok {
+		if weakness, ok := byID[id]; ok {
+			return []Weakness{weakness}
+		}
+	}
+	matches := []Weakness{}
+	for _, weakness := range weaknesses {
+		if strings.Contains(strings.ToLower(weakness.ID), query) || strings.Contains(strings.ToLower(weakness.Name), query) {
+			matches = append(matches, weakness)
+		}
+	}
+	return matches
+}
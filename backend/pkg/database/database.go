@@ -3,9 +3,9 @@ package database
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
+	"github.com/cyops/cyops-backend/pkg/utils"
 	"github.com/rs/zerolog"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -21,16 +21,30 @@ type ConnectionConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration // Zero keeps idle connections until ConnMaxLifetime
+
+	// Queries slower than SlowQueryThreshold are logged as warnings and those slower than
+	// CriticalQueryThreshold as errors (zero disables either)
+	SlowQueryThreshold     time.Duration
+	CriticalQueryThreshold time.Duration
 }
 
+// slowQueryThreshold is the threshold WithQueryLogging reports against
+var slowQueryThreshold = 200 * time.Millisecond
+
 // Connect establishes database connection with configuration
 func Connect(dsn string, isDevelopment bool) error {
 	config := ConnectionConfig{
-		DSN:             dsn,
-		IsDevelopment:   isDevelopment,
-		MaxOpenConns:    25,
-		MaxIdleConns:    5,
-		ConnMaxLifetime: 5 * time.Minute,
+		DSN:                    dsn,
+		IsDevelopment:          isDevelopment,
+		MaxOpenConns:           25,
+		MaxIdleConns:           5,
+		ConnMaxLifetime:        5 * time.Minute,
+		SlowQueryThreshold:     200 * time.Millisecond,
+		CriticalQueryThreshold: 2 * time.Second,
+	}
+	if isDevelopment {
+		config.SlowQueryThreshold = 100 * time.Millisecond
 	}
 	return ConnectWithConfig(config)
 }
@@ -39,32 +53,15 @@ func Connect(dsn string, isDevelopment bool) error {
 func ConnectWithConfig(config ConnectionConfig) error {
 	var err error
 
-	gormConfig := &gorm.Config{}
-
-	// Configure logger with slow query detection
+	// Development logs every query; otherwise only slow queries and errors are logged
+	level := logger.Warn
 	if config.IsDevelopment {
-		// Development: Log all queries and slow queries (>100ms)
-		gormConfig.Logger = logger.New(
-			log.New(log.Writer(), "\r\n", log.LstdFlags),
-			logger.Config{
-				SlowThreshold:             100 * time.Millisecond, // Slow SQL threshold
-				LogLevel:                  logger.Info,            // Log all queries
-				IgnoreRecordNotFoundError: true,
-				Colorful:                  true,
-			},
-		)
-	} else {
-		// Production: Only log slow queries (>200ms) and errors
-		gormConfig.Logger = logger.New(
-			log.New(log.Writer(), "\r\n", log.LstdFlags),
-			logger.Config{
-				SlowThreshold:             200 * time.Millisecond, // Slow SQL threshold
-				LogLevel:                  logger.Warn,            // Only warnings and errors
-				IgnoreRecordNotFoundError: true,
-				Colorful:                  false,
-			},
-		)
+		level = logger.Info
 	}
+	gormConfig := &gorm.Config{
+		Logger: newQueryLogger(level, config.SlowQueryThreshold, config.CriticalQueryThreshold),
+	}
+	slowQueryThreshold = config.SlowQueryThreshold
 
 	DB, err = gorm.Open(postgres.Open(config.DSN), gormConfig)
 	if err != nil {
@@ -81,13 +78,19 @@ func ConnectWithConfig(config ConnectionConfig) error {
 	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
 	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
 	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 
 	// Test connection
 	if err := sqlDB.Ping(); err != nil {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("Database connected successfully")
+	utils.Logger.Info().
+		Int("max_open_conns", config.MaxOpenConns).
+		Int("max_idle_conns", config.MaxIdleConns).
+		Dur("conn_max_lifetime", config.ConnMaxLifetime).
+		Dur("slow_query_threshold", config.SlowQueryThreshold).
+		Msg("Database connected successfully")
 	return nil
 }
 
@@ -151,7 +154,55 @@ func QueryStats() map[string]interface{} {
 		"wait_count":           stats.WaitCount,
 		"wait_duration":        stats.WaitDuration.String(),
 		"max_idle_closed":      stats.MaxIdleClosed,
+		"max_idle_time_closed": stats.MaxIdleTimeClosed,
 		"max_lifetime_closed":  stats.MaxLifetimeClosed,
+		"slow_queries":         slowQueryCount.Load(),
+		"critical_queries":     criticalQueryCount.Load(),
+	}
+}
+
+// ReportPoolStats logs connection pool metrics every interval until ctx is cancelled, warning
+// when requests had to wait for a free connection since the previous report
+func ReportPoolStats(ctx context.Context, interval time.Duration) {
+	if DB == nil || interval <= 0 {
+		return
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	previous := sqlDB.Stats()
+	previousSlow := slowQueryCount.Load()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats := sqlDB.Stats()
+			slow := slowQueryCount.Load()
+
+			event := utils.Logger.Info()
+			if stats.WaitCount > previous.WaitCount {
+				event = utils.Logger.Warn()
+			}
+			event.
+				Int("max_open", stats.MaxOpenConnections).
+				Int("open", stats.OpenConnections).
+				Int("in_use", stats.InUse).
+				Int("idle", stats.Idle).
+				Int64("waits", stats.WaitCount-previous.WaitCount).
+				Dur("wait_duration", stats.WaitDuration-previous.WaitDuration).
+				Int64("idle_closed", stats.MaxIdleClosed-previous.MaxIdleClosed).
+				Int64("lifetime_closed", stats.MaxLifetimeClosed-previous.MaxLifetimeClosed).
+				Int64("slow_queries", slow-previousSlow).
+				Msg("Database pool stats")
+
+			previous, previousSlow = stats, slow
+		}
 	}
 }
 
@@ -162,7 +213,7 @@ func WithQueryLogging(ctx context.Context, db *gorm.DB, operation string, fn fun
 	duration := time.Since(start)
 
 	// Log slow queries
-	if duration > 200*time.Millisecond {
+	if slowQueryThreshold > 0 && duration > slowQueryThreshold {
 		log := zerolog.Ctx(ctx)
 		log.Warn().
 			Str("operation", operation).
@@ -0,0 +1,219 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// PartitionedTable is a table range partitioned by month on a timestamp column
+type PartitionedTable struct {
+	Name   string
+	Column string
+}
+
+// PartitionedTables are split into monthly partitions so queries bounded by the partition column
+// only scan the months they cover, and old months can be dropped cheaply
+var PartitionedTables = []PartitionedTable{
+	{Name: "vulnerability_findings", Column: "created_at"},
+	{Name: "finding_status_history", Column: "changed_at"},
+}
+
+// findingChildTables hold rows belonging to a finding. Foreign keys cannot reference a
+// partitioned table by id alone, so a trigger deletes these rows along with their finding.
+var findingChildTables = []string{
+	"finding_comments",
+	"finding_attachments",
+	"risk_acceptances",
+	"suppression_logs",
+	"finding_status_history",
+}
+
+// MonthlyPartition returns the name and bounds of the partition of table holding month
+func MonthlyPartition(table string, month time.Time) (string, time.Time, time.Time) {
+	month = month.UTC()
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return fmt.Sprintf("%s_p%04d_%02d", table, from.Year(), int(from.Month())), from, from.AddDate(0, 1, 0)
+}
+
+// isPartitioned reports whether table exists as a partitioned table
+func isPartitioned(db *gorm.DB, table string) (bool, error) {
+	var kind string
+	err := db.Raw(`SELECT c.relkind::text FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = current_schema() AND c.relname = ?`, table).Scan(&kind).Error
+	return kind == "p", err
+}
+
+// PartitionTables converts the partitioned tables created by AutoMigrate as plain tables,
+// copying their rows into monthly partitions, and installs the trigger that deletes the rows
+// of a finding along with it. Run AutoMigrate again afterwards to recreate the indexes and
+// foreign keys of the converted tables.
+func PartitionTables(db *gorm.DB, monthsAhead int) error {
+	for _, table := range PartitionedTables {
+		partitioned, err := isPartitioned(db, table.Name)
+		if err != nil {
+			return fmt.Errorf("failed to inspect %s: %w", table.Name, err)
+		}
+		if partitioned {
+			continue
+		}
+
+		start := time.Now()
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return convertToPartitioned(tx, table, monthsAhead)
+		}); err != nil {
+			return fmt.Errorf("failed to partition %s: %w", table.Name, err)
+		}
+		utils.Logger.Info().Str("table", table.Name).Dur("duration", time.Since(start)).Msg("Table converted to monthly partitions")
+	}
+
+	return installFindingDeleteTrigger(db)
+}
+
+// convertToPartitioned recreates a plain table as a partitioned one with the same rows
+func convertToPartitioned(tx *gorm.DB, table PartitionedTable, monthsAhead int) error {
+	old := table.Name + "_unpartitioned"
+
+	// Foreign keys referencing the table would need its partition column as well
+	var references []struct {
+		Conname  string
+		Conrelid string
+	}
+	if err := tx.Raw(`SELECT conname, conrelid::regclass::text AS conrelid FROM pg_constraint
+		WHERE contype = 'f' AND confrelid = ?::regclass`, table.Name).Scan(&references).Error; err != nil {
+		return err
+	}
+	for _, ref := range references {
+		if err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s DROP CONSTRAINT %q`, ref.Conrelid, ref.Conname)).Error; err != nil {
+			return err
+		}
+	}
+
+	statements := []string{
+		fmt.Sprintf(`ALTER TABLE %s RENAME TO %s`, table.Name, old),
+		fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS) PARTITION BY RANGE (%s)`, table.Name, old, table.Column),
+		fmt.Sprintf(`CREATE TABLE %s_default PARTITION OF %s DEFAULT`, table.Name, table.Name),
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	// Partitions from the oldest row's month onwards
+	var oldest *time.Time
+	if err := tx.Raw(fmt.Sprintf(`SELECT MIN(%s) FROM %s`, table.Column, old)).Scan(&oldest).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	_, last, _ := MonthlyPartition(table.Name, now.AddDate(0, monthsAhead, 0))
+	_, month, _ := MonthlyPartition(table.Name, now)
+	if oldest != nil && oldest.Before(month) {
+		_, month, _ = MonthlyPartition(table.Name, *oldest)
+	}
+	for ; !month.After(last); month = month.AddDate(0, 1, 0) {
+		if _, err := createMonthlyPartition(tx, table, month); err != nil {
+			return err
+		}
+	}
+
+	statements = []string{
+		fmt.Sprintf(`INSERT INTO %s SELECT * FROM %s`, table.Name, old),
+		fmt.Sprintf(`DROP TABLE %s`, old),
+		fmt.Sprintf(`ALTER TABLE %s ADD PRIMARY KEY (id, %s)`, table.Name, table.Column),
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createMonthlyPartition attaches the partition of table holding month unless it exists.
+// Rows of that month already caught by the default partition are moved into it.
+func createMonthlyPartition(tx *gorm.DB, table PartitionedTable, month time.Time) (bool, error) {
+	name, from, to := MonthlyPartition(table.Name, month)
+
+	var exists bool
+	if err := tx.Raw(`SELECT to_regclass(?) IS NOT NULL`, name).Scan(&exists).Error; err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	bound := func(t time.Time) string { return t.Format("2006-01-02 15:04:05Z07:00") }
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS)`, name, table.Name),
+		fmt.Sprintf(`WITH moved AS (DELETE FROM %[1]s_default WHERE %[2]s >= '%[3]s' AND %[2]s < '%[4]s' RETURNING *)
+			INSERT INTO %[5]s SELECT * FROM moved`, table.Name, table.Column, bound(from), bound(to), name),
+		fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`, table.Name, name, bound(from), bound(to)),
+	}
+	for _, statement := range statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			return false, fmt.Errorf("failed to create partition %s: %w", name, err)
+		}
+	}
+	return true, nil
+}
+
+// EnsurePartitions creates the monthly partitions of the current month and the monthsAhead
+// following ones, returning the names of those created
+func EnsurePartitions(db *gorm.DB, now time.Time, monthsAhead int) ([]string, error) {
+	var created []string
+	for _, table := range PartitionedTables {
+		partitioned, err := isPartitioned(db, table.Name)
+		if err != nil {
+			return created, fmt.Errorf("failed to inspect %s: %w", table.Name, err)
+		}
+		if !partitioned {
+			continue
+		}
+
+		_, current, _ := MonthlyPartition(table.Name, now)
+		for i := 0; i <= monthsAhead; i++ {
+			month := current.AddDate(0, i, 0)
+			var ok bool
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				ok, err = createMonthlyPartition(tx, table, month)
+				return err
+			}); err != nil {
+				return created, err
+			}
+			if ok {
+				name, _, _ := MonthlyPartition(table.Name, month)
+				created = append(created, name)
+			}
+		}
+	}
+	return created, nil
+}
+
+// installFindingDeleteTrigger deletes the comments, attachments, risk acceptances, suppression
+// logs and status history of a finding when the finding is deleted
+func installFindingDeleteTrigger(db *gorm.DB) error {
+	body := ""
+	for _, child := range findingChildTables {
+		body += fmt.Sprintf("\tDELETE FROM %s WHERE finding_id = OLD.id;\n", child)
+	}
+	statements := []string{
+		`CREATE OR REPLACE FUNCTION delete_finding_children() RETURNS trigger AS $$
+BEGIN
+` + body + `	RETURN OLD;
+END;
+$$ LANGUAGE plpgsql`,
+		`DROP TRIGGER IF EXISTS vulnerability_findings_delete_children ON vulnerability_findings`,
+		`CREATE TRIGGER vulnerability_findings_delete_children AFTER DELETE ON vulnerability_findings
+			FOR EACH ROW EXECUTE FUNCTION delete_finding_children()`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to install finding delete trigger: %w", err)
+		}
+	}
+	return nil
+}
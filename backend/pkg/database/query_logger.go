@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/telemetry"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	gormutils "gorm.io/gorm/utils"
+)
+
+// maxLoggedSQLLength caps the SQL written to slow query log lines
+const maxLoggedSQLLength = 2000
+
+// Slow query counters since startup, reported by QueryStats
+var (
+	slowQueryCount     atomic.Int64
+	criticalQueryCount atomic.Int64
+)
+
+// queryLogger writes GORM query logs through the application logger. Queries slower than
+// slowThreshold are logged as warnings and those slower than criticalThreshold as errors, with
+// their SQL, duration, rows and calling code. A zero threshold disables that level.
+type queryLogger struct {
+	level             logger.LogLevel
+	slowThreshold     time.Duration
+	criticalThreshold time.Duration
+}
+
+func newQueryLogger(level logger.LogLevel, slowThreshold, criticalThreshold time.Duration) *queryLogger {
+	return &queryLogger{
+		level:             level,
+		slowThreshold:     slowThreshold,
+		criticalThreshold: criticalThreshold,
+	}
+}
+
+// LogMode returns a copy of the logger at the given level
+func (l *queryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *queryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		utils.Logger.Info().Str("caller", gormutils.FileWithLineNum()).Msg(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *queryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		utils.Logger.Warn().Str("caller", gormutils.FileWithLineNum()).Msg(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *queryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		utils.Logger.Error().Str("caller", gormutils.FileWithLineNum()).Msg(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace logs a finished statement according to its outcome and duration
+func (l *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+
+	critical := l.criticalThreshold > 0 && elapsed > l.criticalThreshold
+	slow := critical || (l.slowThreshold > 0 && elapsed > l.slowThreshold)
+	if critical {
+		criticalQueryCount.Add(1)
+	}
+	if slow {
+		slowQueryCount.Add(1)
+	}
+
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	var event *zerolog.Event
+	var message string
+	switch {
+	case failed && l.level >= logger.Error:
+		event, message = utils.Logger.Error().Err(err), "Query failed"
+	case critical && l.level >= logger.Warn:
+		event, message = utils.Logger.Error(), "Very slow query detected"
+	case slow && l.level >= logger.Warn:
+		event, message = utils.Logger.Warn(), "Slow query detected"
+	case l.level >= logger.Info:
+		event, message = utils.Logger.Debug(), "Query executed"
+	default:
+		return
+	}
+
+	sql, rows := fc()
+	if len(sql) > maxLoggedSQLLength {
+		sql = sql[:maxLoggedSQLLength]
+	}
+	event = event.
+		Str("sql", sql).
+		Int64("rows", rows).
+		Dur("duration_ms", elapsed).
+		Str("caller", gormutils.FileWithLineNum())
+	if ctx != nil {
+		if requestID := telemetry.RequestIDFromContext(ctx); requestID != "" {
+			event = event.Str("request_id", requestID)
+		}
+	}
+	event.Msg(message)
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// SeedDefaultOrganization creates the default (platform) organization, assigns existing
+// rows without an organization to it, and makes it the default for new rows. It returns
+// the number of rows assigned.
+func SeedDefaultOrganization(db *gorm.DB) (*models.Organization, int64, error) {
+	var org models.Organization
+	result := db.Where("slug = ?", models.DefaultOrganizationSlug).First(&org)
+
+	if result.Error == gorm.ErrRecordNotFound {
+		org = models.Organization{
+			Name:       "Default Organization",
+			Slug:       models.DefaultOrganizationSlug,
+			Active:     true,
+			IsPlatform: true,
+		}
+		if err := db.Create(&org).Error; err != nil {
+			utils.Logger.Error().Err(err).Msg("Failed to create default organization")
+			return nil, 0, err
+		}
+		utils.Logger.Info().Str("organization_id", org.ID.String()).Msg("Default organization created")
+	} else if result.Error != nil {
+		utils.Logger.Error().Err(result.Error).Msg("Error checking default organization")
+		return nil, 0, result.Error
+	}
+
+	// Backfill rows created before multi-tenancy
+	var backfilled int64
+	for table := range tenant.ScopedTables {
+		res := db.Exec(fmt.Sprintf("UPDATE %s SET org_id = ? WHERE org_id IS NULL", table), org.ID)
+		if res.Error != nil {
+			return nil, 0, fmt.Errorf("failed to backfill org_id on %s: %w", table, res.Error)
+		}
+		backfilled += res.RowsAffected
+		if res.RowsAffected > 0 {
+			utils.Logger.Info().Str("table", table).Int64("rows", res.RowsAffected).Msg("Assigned existing rows to default organization")
+		}
+	}
+
+	tenant.SetDefaultOrgID(org.ID)
+	return &org, backfilled, nil
+}
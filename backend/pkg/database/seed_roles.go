@@ -67,32 +67,48 @@ func SeedRoles(db *gorm.DB) error {
 		"admin":         {"access"},
 		"profile":       {"read", "update"},
 		"vulnerability": {"read", "write", "delete", "assign", "import", "export", "status_change"},
-		"finding":       {"read", "mark_fixed", "verify", "accept_risk", "upload_attachment"},
+		"finding":       {"read", "mark_fixed", "verify", "accept_risk", "upload_attachment", "comment", "request_risk_acceptance"},
 		"asset":         {"read", "write", "delete"},
-		"assessment":    {"read", "create", "update", "delete", "link_vulnerability", "upload_report"},
+		"assessment":    {"read", "create", "update", "delete", "link_vulnerability", "upload_report", "request_retest", "retest"},
 		"report":        {"read", "generate", "export"},
 		"integration":   {"read", "configure", "test", "execute"},
+		"suppression":   {"read", "manage"},
+		"policy":        {"read", "manage"},
+		"organization":  {"read", "manage"},
+		"team":          {"read", "manage"},
+		"network_range": {"read", "manage"},
+		"disclosure":    {"read", "triage"},
 	}
 
 	securityManagerPerms := models.PermissionMap{
 		"users":         {"read"},
 		"profile":       {"read", "update"},
 		"vulnerability": {"read", "write", "delete", "assign", "import", "export", "status_change"},
-		"finding":       {"read", "mark_fixed", "verify", "accept_risk", "upload_attachment"},
+		"finding":       {"read", "mark_fixed", "verify", "accept_risk", "upload_attachment", "comment", "request_risk_acceptance"},
 		"asset":         {"read"},
-		"assessment":    {"read", "create", "update", "delete", "link_vulnerability", "upload_report"},
+		"assessment":    {"read", "create", "update", "delete", "link_vulnerability", "upload_report", "request_retest", "retest"},
 		"report":        {"read", "generate", "export"},
 		"integration":   {"read", "configure", "execute"},
+		"suppression":   {"read", "manage"},
+		"policy":        {"read", "manage"},
+		"team":          {"read", "manage"},
+		"network_range": {"read"},
+		"disclosure":    {"read", "triage"},
 	}
 
 	securityAnalystPerms := models.PermissionMap{
 		"profile":       {"read", "update"},
 		"vulnerability": {"read", "write", "import", "export"},
-		"finding":       {"read", "mark_fixed", "upload_attachment"},
+		"finding":       {"read", "mark_fixed", "upload_attachment", "comment", "request_risk_acceptance"},
 		"asset":         {"read"},
-		"assessment":    {"read", "create", "update", "link_vulnerability", "upload_report"},
+		"assessment":    {"read", "create", "update", "link_vulnerability", "upload_report", "request_retest", "retest"},
 		"report":        {"read", "generate", "export"},
 		"integration":   {"read", "execute"},
+		"suppression":   {"read"},
+		"policy":        {"read"},
+		"team":          {"read"},
+		"network_range": {"read"},
+		"disclosure":    {"read", "triage"},
 	}
 
 	assetManagerPerms := models.PermissionMap{
@@ -102,6 +118,8 @@ func SeedRoles(db *gorm.DB) error {
 		"asset":         {"read", "write", "delete"},
 		"assessment":    {"read"},
 		"report":        {"read", "generate", "export"},
+		"team":          {"read"},
+		"network_range": {"read", "manage"},
 	}
 
 	auditorPerms := models.PermissionMap{
@@ -111,6 +129,10 @@ func SeedRoles(db *gorm.DB) error {
 		"asset":         {"read"},
 		"assessment":    {"read"},
 		"report":        {"read", "generate", "export"},
+		"policy":        {"read"},
+		"team":          {"read"},
+		"network_range": {"read"},
+		"disclosure":    {"read"},
 	}
 
 	scannerPerms := models.PermissionMap{
@@ -0,0 +1,214 @@
+// Package docgen renders simple documents (headings, paragraphs, lists, tables, code and
+// images) to PDF and DOCX without external dependencies. Documents are usually produced by
+// parsing a small line-based markup that server-side text/templates emit.
+package docgen
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// Document is a renderable document
+type Document struct {
+	Title  string // Used for document metadata and the page footer
+	Blocks []Block
+}
+
+// Block is one element of a document's body
+type Block interface {
+	block()
+}
+
+// Heading is a section title (levels 1 to 3)
+type Heading struct {
+	Level int
+	Text  string
+}
+
+// Paragraph is a run of wrapped body text
+type Paragraph struct {
+	Text string
+}
+
+// List is a bulleted list
+type List struct {
+	Items []string
+}
+
+// Table is a grid whose first row is the header
+type Table struct {
+	Rows [][]string
+}
+
+// Code is preformatted monospace text; lines are kept as written
+type Code struct {
+	Lines []string
+}
+
+// Figure is an image with an optional caption
+type Figure struct {
+	Image   Image
+	Caption string
+}
+
+// PageBreak starts a new page
+type PageBreak struct{}
+
+func (Heading) block()   {}
+func (Paragraph) block() {}
+func (List) block()      {}
+func (Table) block()     {}
+func (Code) block()      {}
+func (Figure) block()    {}
+func (PageBreak) block() {}
+
+// Parse builds a document from markup. Each line is one of:
+//
+//	# Heading, ## Heading, ### Heading
+//	- list item
+//	| table | row |        (consecutive rows form a table; the first is the header)
+//	![caption](image-key)  (figure; the key is looked up in images)
+//	```                    (starts or ends a code block)
+//	---                    (page break)
+//	text                   (consecutive text lines form one paragraph)
+//
+// A blank line ends the current paragraph, list or table. A leading backslash makes the rest
+// of a line plain text. Figures whose key is missing from images are skipped.
+func Parse(title, markup string, images map[string]Image) (*Document, error) {
+	doc := &Document{Title: title}
+	var paragraph []string
+	var list *List
+	var table *Table
+	var code *Code
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			doc.Blocks = append(doc.Blocks, Paragraph{Text: strings.Join(paragraph, " ")})
+			paragraph = nil
+		}
+		if list != nil {
+			doc.Blocks = append(doc.Blocks, *list)
+			list = nil
+		}
+		if table != nil {
+			doc.Blocks = append(doc.Blocks, *table)
+			table = nil
+		}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(markup))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if code != nil {
+			if strings.TrimSpace(raw) == "```" {
+				doc.Blocks = append(doc.Blocks, *code)
+				code = nil
+				continue
+			}
+			code.Lines = append(code.Lines, strings.ReplaceAll(raw, "\t", "    "))
+			continue
+		}
+
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, `\`):
+			if list != nil || table != nil {
+				flush()
+			}
+			paragraph = append(paragraph, line[1:])
+		case line == "```":
+			flush()
+			code = &Code{}
+		case line == "---":
+			flush()
+			doc.Blocks = append(doc.Blocks, PageBreak{})
+		case strings.HasPrefix(line, "#"):
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			text := strings.TrimSpace(line[level:])
+			if level > 3 || text == "" {
+				return nil, fmt.Errorf("line %d: invalid heading", lineNo)
+			}
+			flush()
+			doc.Blocks = append(doc.Blocks, Heading{Level: level, Text: text})
+		case strings.HasPrefix(line, "- "):
+			if list == nil {
+				flush()
+				list = &List{}
+			}
+			list.Items = append(list.Items, strings.TrimSpace(line[2:]))
+		case strings.HasPrefix(line, "|"):
+			if table == nil {
+				flush()
+				table = &Table{}
+			}
+			row := strings.Split(strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|"), "|")
+			for i := range row {
+				row[i] = strings.TrimSpace(row[i])
+			}
+			if len(table.Rows) > 0 && len(row) != len(table.Rows[0]) {
+				return nil, fmt.Errorf("line %d: table row has %d cells, header has %d", lineNo, len(row), len(table.Rows[0]))
+			}
+			table.Rows = append(table.Rows, row)
+		case strings.HasPrefix(line, "!["):
+			closing := strings.LastIndex(line, "](")
+			if closing < 0 || !strings.HasSuffix(line, ")") {
+				return nil, fmt.Errorf("line %d: invalid figure", lineNo)
+			}
+			flush()
+			if image, ok := images[line[closing+2:len(line)-1]]; ok {
+				doc.Blocks = append(doc.Blocks, Figure{Image: image, Caption: line[2:closing]})
+			}
+		default:
+			if list != nil || table != nil {
+				flush()
+			}
+			paragraph = append(paragraph, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read markup: %w", err)
+	}
+	if code != nil {
+		return nil, fmt.Errorf("unterminated code block")
+	}
+	flush()
+	return doc, nil
+}
+
+// EscapeText makes user-supplied text safe to place in markup as paragraphs: line breaks
+// inside a paragraph are kept as spaces, blank lines still separate paragraphs, and lines
+// that would otherwise be read as markup are escaped
+func EscapeText(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && strings.ContainsAny(trimmed[:1], `#-|!`+"`"+`\`) {
+			lines[i] = `\` + trimmed
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// EscapeCell makes user-supplied text safe to place in a table cell
+func EscapeCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.ReplaceAll(text, "|", "/")
+}
+
+// EscapeCode makes user-supplied text safe to place inside a code block
+func EscapeCode(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "```" {
+			lines[i] = "` ` `"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,289 @@
+package docgen
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A4 geometry in twentieths of a point, with 2cm margins
+const (
+	docxPageWidth  = 11906
+	docxPageHeight = 16838
+	docxMargin     = 1134
+	docxTextWidth  = docxPageWidth - 2*docxMargin
+)
+
+// Image limits in EMU (914400 per inch)
+const (
+	docxMaxImageWidth  = 6 * 914400
+	docxMaxImageHeight = 8 * 914400
+)
+
+// RenderDOCX renders a document as a Word (Office Open XML) document
+func RenderDOCX(doc *Document) ([]byte, error) {
+	var body strings.Builder
+	var images []Image
+
+	for _, block := range doc.Blocks {
+		switch b := block.(type) {
+		case Heading:
+			docxParagraph(&body, fmt.Sprintf("Heading%d", b.Level), b.Text)
+		case Paragraph:
+			docxParagraph(&body, "", b.Text)
+		case List:
+			for _, item := range b.Items {
+				docxParagraph(&body, "ListBullet", "•\t"+item)
+			}
+		case Table:
+			docxTable(&body, b)
+		case Code:
+			for _, line := range b.Lines {
+				docxParagraph(&body, "Code", line)
+			}
+		case Figure:
+			if b.Image.Width == 0 || b.Image.Height == 0 {
+				continue
+			}
+			images = append(images, b.Image)
+			docxFigure(&body, b.Image, len(images))
+			if b.Caption != "" {
+				docxParagraph(&body, "Caption", b.Caption)
+			}
+		case PageBreak:
+			body.WriteString(`<w:p><w:r><w:br w:type="page"/></w:r></w:p>`)
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name string
+		data string
+	}{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRootRels},
+		{"docProps/core.xml", docxCoreProperties(doc.Title)},
+		{"word/document.xml", docxDocument(body.String())},
+		{"word/styles.xml", docxStyles},
+		{"word/footer1.xml", docxFooter(doc.Title)},
+		{"word/_rels/document.xml.rels", docxDocumentRels(len(images))},
+	}
+	for _, file := range files {
+		if err := writeZipFile(zw, file.name, []byte(file.data)); err != nil {
+			return nil, err
+		}
+	}
+	for i, img := range images {
+		if err := writeZipFile(zw, fmt.Sprintf("word/media/image%d.jpeg", i+1), img.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write document: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// xmlText escapes text for element content, dropping characters XML cannot represent
+func xmlText(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(strings.ToValidUTF8(text, string(utf8.RuneError))))
+	return buf.String()
+}
+
+// docxRuns writes text as runs, turning tabs into tab characters
+func docxRuns(text string) string {
+	var sb strings.Builder
+	for i, part := range strings.Split(text, "\t") {
+		if i > 0 {
+			sb.WriteString(`<w:r><w:tab/></w:r>`)
+		}
+		if part != "" {
+			fmt.Fprintf(&sb, `<w:r><w:t xml:space="preserve">%s</w:t></w:r>`, xmlText(part))
+		}
+	}
+	return sb.String()
+}
+
+func docxParagraph(body *strings.Builder, style, text string) {
+	body.WriteString(`<w:p>`)
+	if style != "" {
+		fmt.Fprintf(body, `<w:pPr><w:pStyle w:val="%s"/></w:pPr>`, style)
+	}
+	body.WriteString(docxRuns(text))
+	body.WriteString(`</w:p>`)
+}
+
+func docxTable(body *strings.Builder, table Table) {
+	if len(table.Rows) == 0 {
+		return
+	}
+	shares := columnShares(table.Rows, func(text string) float64 {
+		return float64(utf8.RuneCountInString(text)) * 5
+	})
+	widths := make([]int, len(shares))
+	for i, share := range shares {
+		widths[i] = int(share * docxTextWidth)
+	}
+
+	body.WriteString(`<w:tbl><w:tblPr><w:tblStyle w:val="TableGrid"/><w:tblW w:w="0" w:type="auto"/><w:tblLayout w:type="fixed"/></w:tblPr><w:tblGrid>`)
+	for _, width := range widths {
+		fmt.Fprintf(body, `<w:gridCol w:w="%d"/>`, width)
+	}
+	body.WriteString(`</w:tblGrid>`)
+
+	for r, row := range table.Rows {
+		body.WriteString(`<w:tr>`)
+		if r == 0 {
+			// Repeat the header row on every page the table spans
+			body.WriteString(`<w:trPr><w:tblHeader/></w:trPr>`)
+		}
+		for i, cell := range row {
+			fmt.Fprintf(body, `<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/>`, widths[i])
+			if r == 0 {
+				body.WriteString(`<w:shd w:val="clear" w:color="auto" w:fill="DDDDDD"/>`)
+			}
+			body.WriteString(`</w:tcPr><w:p><w:pPr><w:pStyle w:val="TableText"/></w:pPr>`)
+			if r == 0 {
+				fmt.Fprintf(body, `<w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">%s</w:t></w:r>`, xmlText(cell))
+			} else {
+				body.WriteString(docxRuns(cell))
+			}
+			body.WriteString(`</w:p></w:tc>`)
+		}
+		body.WriteString(`</w:tr>`)
+	}
+	// Word requires a paragraph between consecutive tables
+	body.WriteString(`</w:tbl><w:p/>`)
+}
+
+func docxFigure(body *strings.Builder, img Image, index int) {
+	// 96 DPI screenshots: 9525 EMU per pixel
+	width := int64(img.Width) * 9525
+	height := int64(img.Height) * 9525
+	if width > docxMaxImageWidth {
+		height = height * docxMaxImageWidth / width
+		width = docxMaxImageWidth
+	}
+	if height > docxMaxImageHeight {
+		width = width * docxMaxImageHeight / height
+		height = docxMaxImageHeight
+	}
+
+	fmt.Fprintf(body, `<w:p><w:pPr><w:keepNext/><w:jc w:val="center"/></w:pPr><w:r><w:drawing>`+
+		`<wp:inline distT="0" distB="0" distL="0" distR="0"><wp:extent cx="%[1]d" cy="%[2]d"/><wp:docPr id="%[3]d" name="Picture %[3]d"/>`+
+		`<a:graphic xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:pic xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:nvPicPr><pic:cNvPr id="%[3]d" name="image%[3]d.jpeg"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="rIdImage%[3]d"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%[1]d" cy="%[2]d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r></w:p>`,
+		width, height, index)
+}
+
+func docxDocument(body string) string {
+	return xml.Header +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+		`xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"><w:body>` +
+		body +
+		fmt.Sprintf(`<w:sectPr><w:footerReference w:type="default" r:id="rIdFooter"/><w:pgSz w:w="%d" w:h="%d"/>`+
+			`<w:pgMar w:top="%[3]d" w:right="%[3]d" w:bottom="%[3]d" w:left="%[3]d" w:header="709" w:footer="709" w:gutter="0"/></w:sectPr>`,
+			docxPageWidth, docxPageHeight, docxMargin) +
+		`</w:body></w:document>`
+}
+
+func docxFooter(title string) string {
+	prefix := ""
+	if title != "" {
+		prefix = xmlText(title) + " — "
+	}
+	return xml.Header +
+		`<w:ftr xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:p><w:pPr><w:pStyle w:val="Footer"/><w:jc w:val="center"/></w:pPr>` +
+		`<w:r><w:t xml:space="preserve">` + prefix + `Page </w:t></w:r><w:fldSimple w:instr="PAGE"><w:r><w:t>1</w:t></w:r></w:fldSimple>` +
+		`<w:r><w:t xml:space="preserve"> of </w:t></w:r><w:fldSimple w:instr="NUMPAGES"><w:r><w:t>1</w:t></w:r></w:fldSimple></w:p></w:ftr>`
+}
+
+func docxDocumentRels(images int) string {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	sb.WriteString(`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+	sb.WriteString(`<Relationship Id="rIdFooter" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer" Target="footer1.xml"/>`)
+	for i := 1; i <= images; i++ {
+		fmt.Fprintf(&sb, `<Relationship Id="rIdImage%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image%d.jpeg"/>`, i, i)
+	}
+	sb.WriteString(`</Relationships>`)
+	return sb.String()
+}
+
+func docxCoreProperties(title string) string {
+	return xml.Header +
+		`<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" ` +
+		`xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/" ` +
+		`xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">` +
+		`<dc:title>` + xmlText(title) + `</dc:title><dc:creator>CYOPS</dc:creator>` +
+		`<dcterms:created xsi:type="dcterms:W3CDTF">` + time.Now().UTC().Format(time.RFC3339) + `</dcterms:created>` +
+		`</cp:coreProperties>`
+}
+
+const docxContentTypes = xml.Header +
+	`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Default Extension="jpeg" ContentType="image/jpeg"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`<Override PartName="/word/footer1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"/>` +
+	`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+	`</Types>`
+
+const docxRootRels = xml.Header +
+	`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+	`</Relationships>`
+
+const docxStyles = xml.Header +
+	`<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:cs="Calibri"/><w:sz w:val="21"/></w:rPr></w:rPrDefault>` +
+	`<w:pPrDefault><w:pPr><w:spacing w:after="120" w:line="276" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/>` +
+	`<w:pPr><w:keepNext/><w:pBdr><w:bottom w:val="single" w:sz="6" w:space="4" w:color="808080"/></w:pBdr><w:spacing w:before="360" w:after="160"/><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:sz w:val="36"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/>` +
+	`<w:pPr><w:keepNext/><w:spacing w:before="280" w:after="120"/><w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:b/><w:sz w:val="28"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading3"><w:name w:val="heading 3"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/>` +
+	`<w:pPr><w:keepNext/><w:spacing w:before="200" w:after="80"/><w:outlineLvl w:val="2"/></w:pPr><w:rPr><w:b/><w:sz w:val="24"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="ListBullet"><w:name w:val="List Bullet"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:tabs><w:tab w:val="left" w:pos="360"/></w:tabs><w:spacing w:after="40"/><w:ind w:left="360" w:hanging="360"/></w:pPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Code"><w:name w:val="Code"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:shd w:val="clear" w:color="auto" w:fill="F0F0F0"/><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr>` +
+	`<w:rPr><w:rFonts w:ascii="Courier New" w:hAnsi="Courier New" w:cs="Courier New"/><w:sz w:val="17"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Caption"><w:name w:val="caption"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:jc w:val="center"/><w:spacing w:after="200"/></w:pPr><w:rPr><w:i/><w:sz w:val="18"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="TableText"><w:name w:val="Table Text"/><w:basedOn w:val="Normal"/>` +
+	`<w:pPr><w:spacing w:before="40" w:after="40" w:line="240" w:lineRule="auto"/></w:pPr><w:rPr><w:sz w:val="18"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Footer"><w:name w:val="footer"/><w:basedOn w:val="Normal"/><w:rPr><w:color w:val="666666"/><w:sz w:val="16"/></w:rPr></w:style>` +
+	`<w:style w:type="table" w:styleId="TableGrid"><w:name w:val="Table Grid"/><w:tblPr><w:tblBorders>` +
+	`<w:top w:val="single" w:sz="4" w:space="0" w:color="999999"/><w:left w:val="single" w:sz="4" w:space="0" w:color="999999"/>` +
+	`<w:bottom w:val="single" w:sz="4" w:space="0" w:color="999999"/><w:right w:val="single" w:sz="4" w:space="0" w:color="999999"/>` +
+	`<w:insideH w:val="single" w:sz="4" w:space="0" w:color="999999"/><w:insideV w:val="single" w:sz="4" w:space="0" w:color="999999"/>` +
+	`</w:tblBorders><w:tblCellMar><w:left w:w="80" w:type="dxa"/><w:right w:w="80" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>` +
+	`</w:styles>`
@@ -0,0 +1,93 @@
+package docgen
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+
+	_ "image/gif" // Register decoders for evidence formats
+	_ "image/png"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+// Image is a JPEG image ready to embed in a document
+type Image struct {
+	Data      []byte
+	Width     int // Pixels
+	Height    int
+	Grayscale bool
+}
+
+// NewImage prepares image data for embedding. JPEGs are embedded as-is; other formats (and
+// CMYK JPEGs) are flattened onto white and re-encoded as JPEG.
+func NewImage(data []byte) (Image, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("unsupported image: %w", err)
+	}
+	if format == "jpeg" {
+		switch cfg.ColorModel {
+		case color.YCbCrModel, color.RGBAModel:
+			return Image{Data: data, Width: cfg.Width, Height: cfg.Height}, nil
+		case color.GrayModel:
+			return Image{Data: data, Width: cfg.Width, Height: cfg.Height, Grayscale: true}, nil
+		}
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to decode image: %w", err)
+	}
+	bounds := src.Bounds()
+	flattened := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flattened, flattened.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flattened, flattened.Bounds(), src, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flattened, &jpeg.Options{Quality: 85}); err != nil {
+		return Image{}, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return Image{Data: buf.Bytes(), Width: bounds.Dx(), Height: bounds.Dy()}, nil
+}
+
+// NewImageFit prepares image data like NewImage, scaling it down to fit within maxWidth by
+// maxHeight pixels (e.g. a logo that must not fill the page)
+func NewImageFit(data []byte, maxWidth, maxHeight int) (Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("unsupported image: %w", err)
+	}
+	if cfg.Width <= maxWidth && cfg.Height <= maxHeight {
+		return NewImage(data)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("failed to decode image: %w", err)
+	}
+	scale := float64(maxWidth) / float64(cfg.Width)
+	if heightScale := float64(maxHeight) / float64(cfg.Height); heightScale < scale {
+		scale = heightScale
+	}
+	width, height := int(float64(cfg.Width)*scale), int(float64(cfg.Height)*scale)
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(scaled, scaled.Bounds(), image.White, image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(scaled, scaled.Bounds(), src, src.Bounds(), draw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: 90}); err != nil {
+		return Image{}, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return Image{Data: buf.Bytes(), Width: width, Height: height}, nil
+}
@@ -0,0 +1,530 @@
+package docgen
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"math"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// A4 page geometry in points
+const (
+	pdfPageWidth    = 595.0
+	pdfPageHeight   = 842.0
+	pdfMargin       = 50.0
+	pdfFooterHeight = 25.0
+	pdfTextWidth    = pdfPageWidth - 2*pdfMargin
+	pdfTop          = pdfPageHeight - pdfMargin
+	pdfBottom       = pdfMargin + pdfFooterHeight
+)
+
+// pdfFont is one of the standard 14 PDF fonts, which viewers provide so nothing is embedded
+type pdfFont struct {
+	resource string // Resource name used in content streams
+	baseFont string
+	widths   *[95]int // Glyph widths of ASCII 32-126 in 1/1000 em; nil for monospace
+}
+
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+var (
+	fontRegular = &pdfFont{resource: "F1", baseFont: "Helvetica", widths: &helveticaWidths}
+	fontBold    = &pdfFont{resource: "F2", baseFont: "Helvetica-Bold", widths: &helveticaBoldWidths}
+	fontItalic  = &pdfFont{resource: "F3", baseFont: "Helvetica-Oblique", widths: &helveticaWidths}
+	fontMono    = &pdfFont{resource: "F4", baseFont: "Courier"}
+	pdfFonts    = []*pdfFont{fontRegular, fontBold, fontItalic, fontMono}
+)
+
+// width returns the width of WinAnsi-encoded text in points
+func (f *pdfFont) width(text []byte, size float64) float64 {
+	total := 0
+	for _, b := range text {
+		switch {
+		case f.widths == nil:
+			total += 600
+		case b >= 32 && b <= 126:
+			total += f.widths[b-32]
+		case b == 0x95: // Bullet
+			total += 350
+		case b == 0x97: // Em dash
+			total += 1000
+		default:
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// encodeWinAnsi converts text to the WinAnsi encoding of the standard fonts; characters it
+// cannot represent become '?'
+func encodeWinAnsi(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		if r == '\t' || r == '\n' || r == '\r' {
+			r = ' '
+		}
+		b, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok || b < 32 {
+			b = '?'
+		}
+		encoded = append(encoded, b)
+	}
+	return encoded
+}
+
+// pdfString writes encoded text as a PDF literal string
+func pdfString(text []byte) string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for _, b := range text {
+		if b == '(' || b == ')' || b == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(b)
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+// pdfLayout places document blocks onto pages
+type pdfLayout struct {
+	pages  []*bytes.Buffer
+	page   *bytes.Buffer
+	y      float64 // Baseline cursor, measured from the bottom of the page
+	images []Image // Image XObjects, referenced as /Im1, /Im2, ...
+}
+
+// RenderPDF renders a document as an A4 PDF
+func RenderPDF(doc *Document) ([]byte, error) {
+	layout := &pdfLayout{}
+	layout.newPage()
+
+	for i, block := range doc.Blocks {
+		switch b := block.(type) {
+		case Heading:
+			layout.heading(b, nextBlock(doc.Blocks, i))
+		case Paragraph:
+			layout.paragraph(b.Text, fontRegular, 10, 0)
+		case List:
+			layout.list(b)
+		case Table:
+			layout.table(b)
+		case Code:
+			layout.code(b)
+		case Figure:
+			layout.figure(b)
+		case PageBreak:
+			if !layout.atTop() {
+				layout.newPage()
+			}
+		}
+	}
+
+	return layout.write(doc.Title)
+}
+
+// nextBlock returns the block after index i, or nil at the end of the document
+func nextBlock(blocks []Block, i int) Block {
+	if i+1 < len(blocks) {
+		return blocks[i+1]
+	}
+	return nil
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = &bytes.Buffer{}
+	l.pages = append(l.pages, l.page)
+	l.y = pdfTop
+}
+
+func (l *pdfLayout) atTop() bool {
+	return l.y == pdfTop
+}
+
+// ensure starts a new page unless height points fit above the bottom margin
+func (l *pdfLayout) ensure(height float64) {
+	if l.y-height < pdfBottom && !l.atTop() {
+		l.newPage()
+	}
+}
+
+// space moves the cursor down, unless at the top of a page
+func (l *pdfLayout) space(height float64) {
+	if !l.atTop() {
+		l.y -= height
+	}
+}
+
+func (l *pdfLayout) text(font *pdfFont, size, x, y float64, text []byte) {
+	fmt.Fprintf(l.page, "BT /%s %.2f Tf %.2f %.2f Td %s Tj ET\n", font.resource, size, x, y, pdfString(text))
+}
+
+func (l *pdfLayout) fillRect(gray, x, y, w, h float64) {
+	fmt.Fprintf(l.page, "%.2f g %.2f %.2f %.2f %.2f re f 0 g\n", gray, x, y, w, h)
+}
+
+func (l *pdfLayout) strokeRect(x, y, w, h float64) {
+	fmt.Fprintf(l.page, "0.6 G 0.5 w %.2f %.2f %.2f %.2f re S 0 G\n", x, y, w, h)
+}
+
+// wrap breaks text into lines no wider than width, splitting overlong words
+func wrap(text string, font *pdfFont, size, width float64) [][]byte {
+	var lines [][]byte
+	var line []byte
+	for _, word := range strings.Fields(text) {
+		encoded := encodeWinAnsi(word)
+		candidate := encoded
+		if len(line) > 0 {
+			candidate = append(append(append([]byte{}, line...), ' '), encoded...)
+		}
+		if font.width(candidate, size) <= width {
+			line = candidate
+			continue
+		}
+		if len(line) > 0 {
+			lines = append(lines, line)
+			line = nil
+		}
+		for font.width(encoded, size) > width {
+			cut := 1
+			for cut < len(encoded) && font.width(encoded[:cut+1], size) <= width {
+				cut++
+			}
+			lines = append(lines, encoded[:cut])
+			encoded = encoded[cut:]
+		}
+		line = encoded
+	}
+	if len(line) > 0 || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (l *pdfLayout) heading(h Heading, next Block) {
+	size := map[int]float64{1: 18, 2: 14, 3: 12}[h.Level]
+	lines := wrap(h.Text, fontBold, size, pdfTextWidth)
+	leading := size * 1.25
+
+	l.space(size * 0.8)
+	// Keep the heading with the start of the block that follows it
+	keep := float64(len(lines)) * leading
+	if _, ok := next.(Heading); !ok && next != nil {
+		keep += 60
+	}
+	l.ensure(keep)
+
+	for _, line := range lines {
+		l.y -= leading
+		l.text(fontBold, size, pdfMargin, l.y, line)
+	}
+	if h.Level == 1 {
+		l.y -= 6
+		fmt.Fprintf(l.page, "0.5 G 0.75 w %.2f %.2f m %.2f %.2f l S 0 G\n", pdfMargin, l.y, pdfMargin+pdfTextWidth, l.y)
+	}
+	l.y -= 6
+}
+
+func (l *pdfLayout) paragraph(text string, font *pdfFont, size, indent float64) {
+	leading := size * 1.4
+	for _, line := range wrap(text, font, size, pdfTextWidth-indent) {
+		l.ensure(leading)
+		l.y -= leading
+		l.text(font, size, pdfMargin+indent, l.y, line)
+	}
+	l.y -= 6
+}
+
+func (l *pdfLayout) list(list List) {
+	const size, leading, indent = 10.0, 14.0, 15.0
+	for _, item := range list.Items {
+		for i, line := range wrap(item, fontRegular, size, pdfTextWidth-indent) {
+			l.ensure(leading)
+			l.y -= leading
+			if i == 0 {
+				l.text(fontRegular, size, pdfMargin+4, l.y, []byte{0x95})
+			}
+			l.text(fontRegular, size, pdfMargin+indent, l.y, line)
+		}
+	}
+	l.y -= 6
+}
+
+func (l *pdfLayout) code(code Code) {
+	const size, leading, padding = 8.5, 11.0, 6.0
+	l.space(2)
+	for _, raw := range code.Lines {
+		encoded := encodeWinAnsi(raw)
+		lines := [][]byte{encoded}
+		// Courier is monospace, so long lines are cut at a fixed column
+		columns := int(math.Floor((pdfTextWidth - 2*padding) / (0.6 * size)))
+		if len(encoded) > columns {
+			lines = nil
+			for len(encoded) > columns {
+				lines = append(lines, encoded[:columns])
+				encoded = encoded[columns:]
+			}
+			lines = append(lines, encoded)
+		}
+		for _, line := range lines {
+			l.ensure(leading)
+			l.fillRect(0.94, pdfMargin, l.y-leading, pdfTextWidth, leading)
+			l.y -= leading
+			l.text(fontMono, size, pdfMargin+padding, l.y+2.5, line)
+		}
+	}
+	l.y -= 8
+}
+
+func (l *pdfLayout) table(table Table) {
+	const size, leading, padding = 9.0, 11.5, 4.0
+	if len(table.Rows) == 0 {
+		return
+	}
+
+	shares := columnShares(table.Rows, func(text string) float64 {
+		return fontRegular.width(encodeWinAnsi(text), size)
+	})
+	widths := make([]float64, len(shares))
+	for i, share := range shares {
+		widths[i] = share * pdfTextWidth
+	}
+
+	// The tallest row a page can hold; longer cells are truncated
+	maxLines := int(math.Floor((pdfTop - pdfBottom - 2*padding) / leading))
+
+	type tableRow struct {
+		cells  [][][]byte
+		font   *pdfFont
+		header bool
+		height float64
+	}
+	layoutRow := func(row []string, header bool) tableRow {
+		laid := tableRow{cells: make([][][]byte, len(row)), font: fontRegular, header: header}
+		if header {
+			laid.font = fontBold
+		}
+		lines := 1
+		for i, cell := range row {
+			laid.cells[i] = wrap(cell, laid.font, size, widths[i]-2*padding)
+			if len(laid.cells[i]) > maxLines {
+				laid.cells[i] = append(laid.cells[i][:maxLines-1], []byte("..."))
+			}
+			if len(laid.cells[i]) > lines {
+				lines = len(laid.cells[i])
+			}
+		}
+		laid.height = float64(lines)*leading + 2*padding
+		return laid
+	}
+	drawRow := func(row tableRow) {
+		top := l.y
+		x := pdfMargin
+		for i, cell := range row.cells {
+			if row.header {
+				l.fillRect(0.87, x, top-row.height, widths[i], row.height)
+			}
+			l.strokeRect(x, top-row.height, widths[i], row.height)
+			for j, line := range cell {
+				l.text(row.font, size, x+padding, top-padding-float64(j+1)*leading+2.5, line)
+			}
+			x += widths[i]
+		}
+		l.y = top - row.height
+	}
+
+	header := layoutRow(table.Rows[0], true)
+	l.space(4)
+	if len(table.Rows) > 1 {
+		// Keep the header with the first row
+		l.ensure(header.height + layoutRow(table.Rows[1], false).height)
+	} else {
+		l.ensure(header.height)
+	}
+	drawRow(header)
+	for _, cells := range table.Rows[1:] {
+		row := layoutRow(cells, false)
+		if l.y-row.height < pdfBottom {
+			// Repeat the header on every page the table spans
+			l.newPage()
+			drawRow(header)
+		}
+		drawRow(row)
+	}
+	l.y -= 10
+}
+
+func (l *pdfLayout) figure(figure Figure) {
+	img := figure.Image
+	if img.Width == 0 || img.Height == 0 {
+		return
+	}
+	// Screenshots are usually 96 DPI
+	width := float64(img.Width) * 0.75
+	if width > pdfTextWidth {
+		width = pdfTextWidth
+	}
+	height := width * float64(img.Height) / float64(img.Width)
+	if maxHeight := (pdfTop - pdfBottom) * 0.75; height > maxHeight {
+		height = maxHeight
+		width = height * float64(img.Width) / float64(img.Height)
+	}
+
+	var caption [][]byte
+	if figure.Caption != "" {
+		caption = wrap(figure.Caption, fontItalic, 9, pdfTextWidth)
+	}
+	l.space(6)
+	l.ensure(height + float64(len(caption))*12 + 4)
+
+	l.images = append(l.images, img)
+	l.y -= height
+	fmt.Fprintf(l.page, "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n",
+		width, height, pdfMargin+(pdfTextWidth-width)/2, l.y, len(l.images))
+	l.y -= 4
+	for _, line := range caption {
+		l.y -= 12
+		l.text(fontItalic, 9, pdfMargin+(pdfTextWidth-fontItalic.width(line, 9))/2, l.y, line)
+	}
+	l.y -= 10
+}
+
+// write serializes the laid-out pages, adding a footer to each
+func (l *pdfLayout) write(title string) ([]byte, error) {
+	var out bytes.Buffer
+	var offsets []int
+	addObject := func(body string) int {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+		return len(offsets)
+	}
+	addStream := func(dict string, data []byte) int {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n<< %s /Length %d >>\nstream\n", len(offsets), dict, len(data))
+		out.Write(data)
+		out.WriteString("\nendstream\nendobj\n")
+		return len(offsets)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1 and 2 are the catalog and page tree; the page tree is written last since it
+	// lists the page objects
+	catalog := addObject("<< /Type /Catalog /Pages 2 0 R >>")
+	offsets = append(offsets, 0)
+	pagesIndex := len(offsets) - 1
+
+	var fontRefs strings.Builder
+	for _, font := range pdfFonts {
+		ref := addObject(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font.baseFont))
+		fmt.Fprintf(&fontRefs, "/%s %d 0 R ", font.resource, ref)
+	}
+
+	var imageRefs strings.Builder
+	for i, img := range l.images {
+		colorSpace := "/DeviceRGB"
+		if img.Grayscale {
+			colorSpace = "/DeviceGray"
+		}
+		ref := addStream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode",
+			img.Width, img.Height, colorSpace), img.Data)
+		fmt.Fprintf(&imageRefs, "/Im%d %d 0 R ", i+1, ref)
+	}
+
+	resources := fmt.Sprintf("<< /Font << %s>> /XObject << %s>> >>", fontRefs.String(), imageRefs.String())
+	footerTitle := encodeWinAnsi(title)
+	var pageRefs []string
+	for i, page := range l.pages {
+		footer := append(append([]byte{}, footerTitle...), encodeWinAnsi(fmt.Sprintf(" — Page %d of %d", i+1, len(l.pages)))...)
+		if len(footerTitle) == 0 {
+			footer = encodeWinAnsi(fmt.Sprintf("Page %d of %d", i+1, len(l.pages)))
+		}
+		fmt.Fprintf(page, "0.4 g BT /%s 8 Tf %.2f %.2f Td %s Tj ET 0 g\n",
+			fontRegular.resource, pdfMargin+(pdfTextWidth-fontRegular.width(footer, 8))/2, pdfMargin, pdfString(footer))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return nil, fmt.Errorf("failed to compress page: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress page: %w", err)
+		}
+		content := addStream("/Filter /FlateDecode", compressed.Bytes())
+		ref := addObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources %s /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, resources, content))
+		pageRefs = append(pageRefs, fmt.Sprintf("%d 0 R", ref))
+	}
+
+	offsets[pagesIndex] = out.Len()
+	fmt.Fprintf(&out, "2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(pageRefs, " "), len(pageRefs))
+
+	info := addObject(fmt.Sprintf("<< /Title %s /Producer (CYOPS) >>", pdfString(footerTitle)))
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, catalog, info, xref)
+
+	return out.Bytes(), nil
+}
+
+// columnShares splits the page width between table columns in proportion to their content,
+// so a short ID column does not get as much room as a long description
+func columnShares(rows [][]string, measure func(string) float64) []float64 {
+	const minShare, maxContent = 0.08, 250.0
+	columns := len(rows[0])
+	content := make([]float64, columns)
+	for _, row := range rows {
+		for i, cell := range row {
+			width := measure(cell)
+			if width > maxContent {
+				width = maxContent
+			}
+			if width > content[i] {
+				content[i] = width
+			}
+		}
+	}
+
+	total := 0.0
+	for i := range content {
+		content[i] += 10
+		total += content[i]
+	}
+	shares := make([]float64, columns)
+	scaled := 0.0
+	for i := range content {
+		shares[i] = content[i] / total
+		if shares[i] < minShare {
+			shares[i] = minShare
+		}
+		scaled += shares[i]
+	}
+	for i := range shares {
+		shares[i] /= scaled
+	}
+	return shares
+}
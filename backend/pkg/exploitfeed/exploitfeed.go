@@ -0,0 +1,198 @@
+// Package exploitfeed reads the public exploit catalogs of Exploit-DB
+// (https://www.exploit-db.com) and the Metasploit Framework (https://www.metasploit.com) to
+// find the CVEs with a known public exploit.
+package exploitfeed
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sources of public exploits
+const (
+	SourceExploitDB  = "exploit-db"
+	SourceMetasploit = "metasploit"
+)
+
+// Default feed locations
+const (
+	DefaultExploitDBURL  = "https://gitlab.com/exploit-database/exploitdb/-/raw/main/files_exploits.csv"
+	DefaultMetasploitURL = "https://raw.githubusercontent.com/rapid7/metasploit-framework/master/db/modules_metadata_base.json"
+)
+
+// maxErrorBody bounds how much of a failed response is quoted in errors
+const maxErrorBody = 512
+
+var cvePattern = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+
+// Exploit is a public exploit of one or more CVEs
+type Exploit struct {
+	Source      string
+	ID          string // Exploit-DB ID or Metasploit module name, e.g. exploit/windows/smb/ms17_010_eternalblue
+	Title       string
+	URL         string
+	CVEs        []string
+	Verified    bool       // Exploit-DB verified the exploit; Metasploit modules always are
+	PublishedAt *time.Time // Publication (Exploit-DB) or disclosure (Metasploit) date
+}
+
+// ParseExploitDB reads the files_exploits.csv index of the Exploit-DB repository. Exploits
+// without a CVE are skipped.
+func ParseExploitDB(r io.Reader) ([]Exploit, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid exploit-db feed: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"id", "description", "codes"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("invalid exploit-db feed: missing %s column", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	exploits := []Exploit{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid exploit-db feed: %w", err)
+		}
+		cves := cveIDs(strings.Split(field(record, "codes"), ";"))
+		id := field(record, "id")
+		if len(cves) == 0 || id == "" {
+			continue
+		}
+		exploits = append(exploits, Exploit{
+			Source:      SourceExploitDB,
+			ID:          id,
+			Title:       field(record, "description"),
+			URL:         "https://www.exploit-db.com/exploits/" + id,
+			CVEs:        cves,
+			Verified:    field(record, "verified") == "1",
+			PublishedAt: parseDate(field(record, "date_published")),
+		})
+	}
+	return exploits, nil
+}
+
+// ParseMetasploit reads the module metadata cache (db/modules_metadata_base.json) of the
+// Metasploit Framework. Only exploit modules referencing a CVE are returned; auxiliary and
+// post-exploitation modules are skipped.
+func ParseMetasploit(r io.Reader) ([]Exploit, error) {
+	var modules map[string]struct {
+		Name           string   `json:"name"`
+		FullName       string   `json:"fullname"`
+		Type           string   `json:"type"`
+		References     []string `json:"references"`
+		DisclosureDate string   `json:"disclosure_date"`
+	}
+	if err := json.NewDecoder(r).Decode(&modules); err != nil {
+		return nil, fmt.Errorf("invalid metasploit feed: %w", err)
+	}
+
+	exploits := []Exploit{}
+	for _, module := range modules {
+		if module.Type != "exploit" || module.FullName == "" {
+			continue
+		}
+		cves := cveIDs(module.References)
+		if len(cves) == 0 {
+			continue
+		}
+		exploits = append(exploits, Exploit{
+			Source:      SourceMetasploit,
+			ID:          module.FullName,
+			Title:       module.Name,
+			URL:         "https://www.rapid7.com/db/modules/" + module.FullName + "/",
+			CVEs:        cves,
+			Verified:    true,
+			PublishedAt: parseDate(module.DisclosureDate),
+		})
+	}
+	// The metadata is a JSON object, so order the modules for stable results
+	sort.Slice(exploits, func(i, j int) bool { return exploits[i].ID < exploits[j].ID })
+	return exploits, nil
+}
+
+// cveIDs keeps the CVE references among raw, upper-cased and without duplicates
+func cveIDs(raw []string) []string {
+	var cves []string
+	seen := map[string]bool{}
+	for _, ref := range raw {
+		id := strings.ToUpper(strings.TrimSpace(ref))
+		if cvePattern.MatchString(id) && !seen[id] {
+			seen[id] = true
+			cves = append(cves, id)
+		}
+	}
+	return cves
+}
+
+// parseDate parses a YYYY-MM-DD date, or returns nil
+func parseDate(value string) *time.Time {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil
+	}
+	return &date
+}
+
+// Client downloads exploit feeds
+type Client struct {
+	http *http.Client
+}
+
+// NewClient creates a feed client. The feeds are tens of megabytes, hence the long timeout.
+func NewClient() *Client {
+	return &Client{http: &http.Client{Timeout: 5 * time.Minute}}
+}
+
+// Fetch downloads and parses the feed of source from url
+func (c *Client) Fetch(ctx context.Context, source, url string) ([]Exploit, error) {
+	var parse func(io.Reader) ([]Exploit, error)
+	switch source {
+	case SourceExploitDB:
+		parse = ParseExploitDB
+	case SourceMetasploit:
+		parse = ParseMetasploit
+	default:
+		return nil, fmt.Errorf("invalid exploit source '%s'", source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s feed request failed: %w", source, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, fmt.Errorf("%s feed request failed: %s: %s", source, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return parse(resp.Body)
+}
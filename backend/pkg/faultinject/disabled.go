@@ -0,0 +1,45 @@
+//go:build !chaos
+
+package faultinject
+
+import (
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// Enabled reports whether this binary was built with fault injection support
+func Enabled() bool {
+	return false
+}
+
+// Set is unavailable without the chaos build tag
+func Set(point Point, fault Fault) error {
+	return ErrDisabled
+}
+
+// Clear is a no-op without the chaos build tag
+func Clear(point Point) {}
+
+// ClearAll is a no-op without the chaos build tag
+func ClearAll() {}
+
+// List always returns an empty set without the chaos build tag
+func List() map[Point]Fault {
+	return map[Point]Fault{}
+}
+
+// Inject never fails without the chaos build tag
+func Inject(point Point) error {
+	return nil
+}
+
+// WrapTransport returns the transport unchanged without the chaos build tag
+func WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return rt
+}
+
+// RegisterGORMCallbacks is a no-op without the chaos build tag
+func RegisterGORMCallbacks(db *gorm.DB) error {
+	return nil
+}
@@ -0,0 +1,153 @@
+//go:build chaos
+
+package faultinject
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	mu     sync.RWMutex
+	faults = map[Point]Fault{}
+)
+
+// Enabled reports whether this binary was built with fault injection support
+func Enabled() bool {
+	return true
+}
+
+// Set configures the fault injected at a point
+func Set(point Point, fault Fault) error {
+	if err := fault.Validate(); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	faults[point] = fault
+
+	utils.Logger.Warn().
+		Str("point", string(point)).
+		Int("delay_ms", fault.DelayMS).
+		Float64("error_rate", fault.ErrorRate).
+		Bool("timeout", fault.Timeout).
+		Msg("Fault injection configured")
+	return nil
+}
+
+// Clear removes the fault configured at a point
+func Clear(point Point) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(faults, point)
+}
+
+// ClearAll removes every configured fault
+func ClearAll() {
+	mu.Lock()
+	defer mu.Unlock()
+	faults = map[Point]Fault{}
+}
+
+// List returns a copy of the configured faults
+func List() map[Point]Fault {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	result := make(map[Point]Fault, len(faults))
+	for point, fault := range faults {
+		result[point] = fault
+	}
+	return result
+}
+
+// Inject applies the fault configured at a point, sleeping for the configured
+// delay and returning an error according to the configured error rate
+func Inject(point Point) error {
+	mu.RLock()
+	fault, ok := faults[point]
+	mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if fault.DelayMS > 0 {
+		time.Sleep(time.Duration(fault.DelayMS) * time.Millisecond)
+	}
+
+	if fault.ErrorRate <= 0 || rand.Float64() >= fault.ErrorRate {
+		return nil
+	}
+
+	message := fault.Message
+	if message == "" {
+		message = "simulated failure"
+	}
+
+	var err error
+	if fault.Timeout {
+		err = fmt.Errorf("%w at %s: %s: %w", ErrInjected, point, message, context.DeadlineExceeded)
+	} else {
+		err = fmt.Errorf("%w at %s: %s", ErrInjected, point, message)
+	}
+
+	utils.Logger.Warn().
+		Str("point", string(point)).
+		Bool("fault_injected", true).
+		Err(err).
+		Msg("Injected fault")
+
+	return err
+}
+
+// faultTransport injects integration faults before outbound HTTP requests
+type faultTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := Inject(PointIntegration); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// WrapTransport wraps an HTTP transport so integration faults apply to it
+func WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &faultTransport{next: rt}
+}
+
+// RegisterGORMCallbacks installs callbacks that inject database faults before every operation
+func RegisterGORMCallbacks(db *gorm.DB) error {
+	inject := func(tx *gorm.DB) {
+		if err := Inject(PointDatabase); err != nil {
+			tx.AddError(err)
+		}
+	}
+
+	if err := db.Callback().Query().Before("gorm:query").Register("faultinject:query", inject); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().Before("gorm:create").Register("faultinject:create", inject); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("faultinject:update", inject); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("faultinject:delete", inject); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register("faultinject:row", inject)
+}
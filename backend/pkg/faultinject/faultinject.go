@@ -0,0 +1,59 @@
+// Package faultinject provides chaos testing hooks (delays, forced errors, timeouts)
+// for resilience validation. Faults can only be configured in binaries built with
+// the "chaos" build tag; in regular builds every hook is a no-op.
+//
+//	go build -tags chaos ./cmd/server
+package faultinject
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Point identifies a place in the application where faults can be injected
+type Point string
+
+const (
+	PointDatabase    Point = "database"    // Every GORM query/create/update/delete
+	PointIntegration Point = "integration" // Outbound HTTP calls to scanners (Nessus)
+	PointImport      Point = "import"      // Vulnerability import pipeline
+	PointReport      Point = "report"      // Report generation
+)
+
+// Points lists all supported injection points
+var Points = []Point{PointDatabase, PointIntegration, PointImport, PointReport}
+
+// ErrInjected is wrapped by every error produced by an injected fault
+var ErrInjected = errors.New("injected fault")
+
+// ErrDisabled is returned when configuring faults in a build without the chaos tag
+var ErrDisabled = errors.New("fault injection is not available in this build")
+
+// Fault describes the behavior injected at a point
+type Fault struct {
+	DelayMS   int     `json:"delay_ms"`          // Added latency before the operation
+	ErrorRate float64 `json:"error_rate"`        // Probability (0-1) of failing the operation
+	Timeout   bool    `json:"timeout"`           // Fail with a timeout error instead of a generic one
+	Message   string  `json:"message,omitempty"` // Optional error message
+}
+
+// Validate checks that the fault configuration is usable
+func (f Fault) Validate() error {
+	if f.DelayMS < 0 || f.DelayMS > 120000 {
+		return fmt.Errorf("delay_ms must be between 0 and 120000")
+	}
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		return fmt.Errorf("error_rate must be between 0 and 1")
+	}
+	return nil
+}
+
+// ParsePoint validates an injection point name
+func ParsePoint(name string) (Point, error) {
+	for _, p := range Points {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown injection point %q", name)
+}
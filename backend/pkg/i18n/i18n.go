@@ -0,0 +1,119 @@
+// Package i18n translates API messages, emails and report labels. Catalogs map message IDs to
+// text and are embedded from locales/<language>.json; English is the source language, so every
+// ID must be in the English catalog and other catalogs may lag behind it.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// Supported languages
+const (
+	English = "en"
+	Arabic  = "ar"
+)
+
+// Default is the language used when none is requested or the requested one is not supported
+const Default = English
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// languages are the supported languages, the default first
+var languages = []string{English, Arabic}
+
+var (
+	// catalogs maps a language to its message ID -> text catalog
+	catalogs = map[string]map[string]string{}
+	// sourceIDs maps English text to its message ID, so messages written in English can be
+	// translated without every caller knowing the ID
+	sourceIDs = map[string]string{}
+	matcher   language.Matcher
+)
+
+func init() {
+	tags := make([]language.Tag, 0, len(languages))
+	for _, lang := range languages {
+		data, err := localeFiles.ReadFile("locales/" + lang + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", lang, err))
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", lang, err))
+		}
+		catalogs[lang] = catalog
+		tags = append(tags, language.Make(lang))
+	}
+	for id, text := range catalogs[English] {
+		sourceIDs[text] = id
+	}
+	matcher = language.NewMatcher(tags)
+}
+
+// Supported returns the supported languages, the default first
+func Supported() []string {
+	return append([]string(nil), languages...)
+}
+
+// IsSupported reports whether there is a catalog for lang
+func IsSupported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Negotiate picks the supported language that best matches an Accept-Language header
+func Negotiate(acceptLanguage string) string {
+	if strings.TrimSpace(acceptLanguage) == "" {
+		return Default
+	}
+	_, index := language.MatchStrings(matcher, acceptLanguage)
+	return languages[index]
+}
+
+// Direction returns the text direction of lang, "rtl" or "ltr"
+func Direction(lang string) string {
+	if lang == Arabic {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// T returns the text of a message ID in lang, formatted with args. Messages missing from the
+// catalog fall back to English, and unknown IDs to the ID itself.
+func T(lang, id string, args ...interface{}) string {
+	text, ok := catalogs[lang][id]
+	if !ok {
+		if text, ok = catalogs[English][id]; !ok {
+			text = id
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// Translate translates a message written in English, such as a validation error. Messages
+// that are not in the English catalog are returned unchanged.
+func Translate(lang, message string) string {
+	if lang == English {
+		return message
+	}
+	id, ok := sourceIDs[message]
+	if !ok {
+		return message
+	}
+	return T(lang, id)
+}
+
+// FormatMonth formats the month and year of t, e.g. "Jan 2026"
+func FormatMonth(lang string, t time.Time) string {
+	return fmt.Sprintf("%s %d", T(lang, fmt.Sprintf("month.%02d", int(t.Month()))), t.Year())
+}
@@ -11,13 +11,14 @@ import (
 	"strings"
 
 	"github.com/disintegration/imaging"
+	_ "golang.org/x/image/webp" // Register the WebP decoder for image.Decode
 )
 
 // ImageProcessor handles image normalization and thumbnail generation
 type ImageProcessor struct {
 	MaxWidth       int  // Maximum width for normalized images
 	MaxHeight      int  // Maximum height for normalized images
-	ThumbnailSize  int  // Size for square thumbnails
+	ThumbnailSize  int  // Bounding box for preview thumbnails
 	JPEGQuality    int  // JPEG compression quality (1-100)
 }
 
@@ -26,7 +27,7 @@ func NewImageProcessor() *ImageProcessor {
 	return &ImageProcessor{
 		MaxWidth:      1920,  // Standard reporting size
 		MaxHeight:     1080,  // Standard reporting size
-		ThumbnailSize: 256,   // 256x256 thumbnails
+		ThumbnailSize: 480,   // Previews fit within 480x480
 		JPEGQuality:   85,    // Good quality/size balance
 	}
 }
@@ -62,8 +63,11 @@ func (p *ImageProcessor) ProcessImage(data []byte, originalFilename string) (*Pr
 		normalized = imaging.Fit(img, p.MaxWidth, p.MaxHeight, imaging.Lanczos)
 	}
 
-	// Generate thumbnail (square, center crop)
-	thumbnail := imaging.Fill(img, p.ThumbnailSize, p.ThumbnailSize, imaging.Center, imaging.Lanczos)
+	// Generate thumbnail (aspect ratio preserved so screenshot previews are not cropped)
+	thumbnail := img
+	if originalWidth > p.ThumbnailSize || originalHeight > p.ThumbnailSize {
+		thumbnail = imaging.Fit(img, p.ThumbnailSize, p.ThumbnailSize, imaging.Lanczos)
+	}
 
 	// Encode normalized image as JPEG
 	var normalizedBuf bytes.Buffer
@@ -0,0 +1,165 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/awsauth"
+)
+
+// AWSConfig configures an AWS KMS key
+type AWSConfig struct {
+	Region          string
+	KeyID           string // Key ID, key ARN or alias (alias/cyops-secrets)
+	Endpoint        string // Defaults to https://kms.<region>.amazonaws.com
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // Set when using temporary credentials
+
+	// Now returns the signing time; defaults to time.Now (overridden in tests)
+	Now func() time.Time
+}
+
+// AWSProvider wraps data keys with an AWS KMS key, signing requests with AWS Signature Version 4
+type AWSProvider struct {
+	cfg        AWSConfig
+	endpoint   *url.URL
+	signer     awsauth.Signer
+	httpClient *http.Client
+}
+
+// NewAWSProvider creates an AWS KMS provider
+func NewAWSProvider(cfg AWSConfig) (*AWSProvider, error) {
+	if cfg.KeyID == "" {
+		return nil, fmt.Errorf("aws kms key ID is required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("aws credentials are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://kms." + cfg.Region + ".amazonaws.com"
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid aws kms endpoint: %s", cfg.Endpoint)
+	}
+
+	return &AWSProvider{
+		cfg:      cfg,
+		endpoint: endpoint,
+		signer: awsauth.Signer{
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			Region:          cfg.Region,
+			Service:         "kms",
+		},
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Name returns "aws"
+func (p *AWSProvider) Name() string {
+	return ProviderAWS
+}
+
+// KeyID returns the configured KMS key
+func (p *AWSProvider) KeyID() string {
+	return p.cfg.KeyID
+}
+
+// Wrap encrypts a data key with the KMS key
+func (p *AWSProvider) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	var out struct {
+		CiphertextBlob []byte `json:"CiphertextBlob"`
+	}
+	err := p.call(ctx, "Encrypt", map[string]interface{}{
+		"KeyId":     p.cfg.KeyID,
+		"Plaintext": dataKey,
+	}, &out)
+	return out.CiphertextBlob, err
+}
+
+// Unwrap decrypts a data key wrapped by Wrap. KMS keeps earlier key material after automatic
+// rotation, so data keys wrapped before a rotation still unwrap.
+func (p *AWSProvider) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Plaintext []byte `json:"Plaintext"`
+	}
+	err := p.call(ctx, "Decrypt", map[string]interface{}{
+		"KeyId":          p.cfg.KeyID,
+		"CiphertextBlob": wrapped,
+	}, &out)
+	return out.Plaintext, err
+}
+
+// call sends a signed request to a KMS API action
+func (p *AWSProvider) call(ctx context.Context, action string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", p.endpoint.String()+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create kms request: %w", err)
+	}
+
+	now := p.cfg.Now().UTC()
+	payloadHash := awsauth.SHA256Hex(body)
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	req.Header.Set("X-Amz-Date", now.Format(awsauth.TimeFormat))
+	if p.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", p.cfg.SessionToken)
+	}
+
+	// Sign host, content type and every x-amz-* header
+	values := map[string]string{
+		"content-type": req.Header.Get("Content-Type"),
+		"host":         p.endpoint.Host,
+		"x-amz-date":   req.Header.Get("X-Amz-Date"),
+		"x-amz-target": req.Header.Get("X-Amz-Target"),
+	}
+	if p.cfg.SessionToken != "" {
+		values["x-amz-security-token"] = p.cfg.SessionToken
+	}
+	canonicalHeaders, signedHeaders := awsauth.CanonicalHeaders(values)
+
+	canonicalRequest := strings.Join([]string{
+		"POST",
+		"/",
+		"",
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	req.Header.Set("Authorization", p.signer.Authorization(now, signedHeaders, canonicalRequest))
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("kms request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("kms %s failed with status %d: %s", action, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode kms response: %w", err)
+	}
+	return nil
+}
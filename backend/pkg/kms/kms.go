@@ -0,0 +1,288 @@
+// Package kms provides envelope encryption of stored secrets: each secret is encrypted with its
+// own AES-256-GCM data key, and the data key is wrapped by a key encryption key held by a key
+// management backend — a local key, AWS KMS or the HashiCorp Vault transit engine.
+package kms
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Key providers
+const (
+	ProviderLocal = "local"
+	ProviderAWS   = "aws"
+	ProviderVault = "vault"
+)
+
+// envelopePrefix marks secrets written by a Keyring; anything else is a legacy ciphertext
+const envelopePrefix = "env1:"
+
+// KeyProvider wraps and unwraps data keys with a key encryption key it never discloses
+type KeyProvider interface {
+	// Name returns the provider name recorded with each wrapped data key
+	Name() string
+	// KeyID identifies the key encryption key, recorded with each wrapped data key
+	KeyID() string
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// envelope is the stored form of a secret
+type envelope struct {
+	Provider   string `json:"p"`
+	KeyID      string `json:"k"`
+	WrappedKey []byte `json:"dk"`
+	Ciphertext []byte `json:"ct"` // nonce followed by the AES-256-GCM sealed secret
+}
+
+// Keyring encrypts secrets under its active provider and decrypts secrets written under any
+// provider it holds, so secrets stay readable while they are re-encrypted under a new key
+type Keyring struct {
+	active    KeyProvider
+	providers map[string]KeyProvider // by provider name and key ID
+	legacyKey []byte
+}
+
+// NewKeyring creates a keyring encrypting under active and also decrypting under previous
+func NewKeyring(active KeyProvider, previous ...KeyProvider) *Keyring {
+	k := &Keyring{
+		active:    active,
+		providers: make(map[string]KeyProvider),
+	}
+	for _, provider := range append(previous, active) {
+		if provider != nil {
+			k.providers[providerRef(provider.Name(), provider.KeyID())] = provider
+		}
+	}
+	return k
+}
+
+// WithLegacyKey lets the keyring decrypt secrets encrypted directly with an AES-256 key, as
+// they were before envelope encryption
+func (k *Keyring) WithLegacyKey(key []byte) *Keyring {
+	k.legacyKey = key
+	return k
+}
+
+// Active returns the provider new secrets are encrypted under
+func (k *Keyring) Active() KeyProvider {
+	return k.active
+}
+
+func providerRef(name, keyID string) string {
+	return name + "/" + keyID
+}
+
+// Encrypt seals a secret under a fresh data key wrapped by the active provider
+func (k *Keyring) Encrypt(ctx context.Context, plaintext string) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := k.active.Wrap(ctx, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap data key with %s: %w", k.active.Name(), err)
+	}
+
+	encoded, err := json.Marshal(envelope{
+		Provider:   k.active.Name(),
+		KeyID:      k.active.KeyID(),
+		WrappedKey: wrapped,
+		Ciphertext: ciphertext,
+	})
+	if err != nil {
+		return "", err
+	}
+	return envelopePrefix + base64.StdEncoding.EncodeToString(encoded), nil
+}
+
+// Decrypt opens a secret written by Encrypt under any provider of the keyring, or a legacy
+// ciphertext when the keyring has a legacy key
+func (k *Keyring) Decrypt(ctx context.Context, ciphertext string) (string, error) {
+	if !strings.HasPrefix(ciphertext, envelopePrefix) {
+		return k.decryptLegacy(ciphertext)
+	}
+
+	env, err := parseEnvelope(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	provider, ok := k.providers[providerRef(env.Provider, env.KeyID)]
+	if !ok {
+		return "", fmt.Errorf("secret is encrypted under unknown key %s %s", env.Provider, env.KeyID)
+	}
+	dataKey, err := provider.Unwrap(ctx, env.WrappedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key with %s: %w", env.Provider, err)
+	}
+	plaintext, err := open(dataKey, env.Ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// IsCurrent reports whether a secret is already encrypted under the active provider and key
+func (k *Keyring) IsCurrent(ciphertext string) bool {
+	if !strings.HasPrefix(ciphertext, envelopePrefix) {
+		return false
+	}
+	env, err := parseEnvelope(ciphertext)
+	return err == nil && env.Provider == k.active.Name() && env.KeyID == k.active.KeyID()
+}
+
+func parseEnvelope(ciphertext string) (*envelope, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, envelopePrefix))
+	if err != nil {
+		return nil, fmt.Errorf("malformed secret envelope: %w", err)
+	}
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("malformed secret envelope: %w", err)
+	}
+	return &env, nil
+}
+
+func (k *Keyring) decryptLegacy(ciphertext string) (string, error) {
+	if k.legacyKey == nil {
+		return "", errors.New("secret is not envelope encrypted and no legacy key is configured")
+	}
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(k.legacyKey, data)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// seal encrypts with AES-256-GCM, prefixing the random nonce
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open decrypts the output of seal
+func open(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+	return gcm.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Config selects the provider new secrets are encrypted under and configures every provider
+// existing secrets may be encrypted under
+type Config struct {
+	Provider string // Defaults to local
+
+	// LocalKey is the base64 encoded 32-byte local key; when empty it is derived from
+	// FallbackSecret. PreviousLocalKeys still decrypt secrets written before a key change.
+	LocalKey          string
+	PreviousLocalKeys []string
+	FallbackSecret    string
+
+	AWS   AWSConfig   // Used when AWS.KeyID is set
+	Vault VaultConfig // Used when Vault.Address is set
+}
+
+// New creates the keyring described by cfg
+func New(cfg Config) (*Keyring, error) {
+	var providers []KeyProvider
+
+	var local KeyProvider
+	if cfg.LocalKey != "" {
+		provider, err := NewLocalProviderFromBase64(cfg.LocalKey)
+		if err != nil {
+			return nil, err
+		}
+		local = provider
+	} else if cfg.FallbackSecret != "" {
+		local = DeriveLocalProvider(cfg.FallbackSecret)
+	}
+	if local != nil {
+		providers = append(providers, local)
+	}
+	// Secrets written under the derived key stay readable after a dedicated key is configured
+	if cfg.LocalKey != "" && cfg.FallbackSecret != "" {
+		providers = append(providers, DeriveLocalProvider(cfg.FallbackSecret))
+	}
+	for _, encoded := range cfg.PreviousLocalKeys {
+		if encoded = strings.TrimSpace(encoded); encoded == "" {
+			continue
+		}
+		provider, err := NewLocalProviderFromBase64(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous local key: %w", err)
+		}
+		providers = append(providers, provider)
+	}
+
+	var aws, vault KeyProvider
+	if cfg.AWS.KeyID != "" {
+		provider, err := NewAWSProvider(cfg.AWS)
+		if err != nil {
+			return nil, err
+		}
+		aws = provider
+		providers = append(providers, aws)
+	}
+	if cfg.Vault.Address != "" {
+		provider, err := NewVaultProvider(cfg.Vault)
+		if err != nil {
+			return nil, err
+		}
+		vault = provider
+		providers = append(providers, vault)
+	}
+
+	var active KeyProvider
+	switch cfg.Provider {
+	case "", ProviderLocal:
+		active = local
+	case ProviderAWS:
+		active = aws
+	case ProviderVault:
+		active = vault
+	default:
+		return nil, fmt.Errorf("unknown kms provider: %s", cfg.Provider)
+	}
+	if active == nil {
+		return nil, fmt.Errorf("kms provider %s is not configured", cfg.Provider)
+	}
+	return NewKeyring(active, providers...), nil
+}
@@ -0,0 +1,65 @@
+package kms
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// LocalProvider wraps data keys with an AES-256 key held in the application's configuration
+type LocalProvider struct {
+	key   []byte
+	keyID string
+}
+
+// NewLocalProvider creates a local provider from a 32-byte key
+func NewLocalProvider(key []byte) (*LocalProvider, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("local key must be 32 bytes, got %d", len(key))
+	}
+	fingerprint := sha256.Sum256(key)
+	return &LocalProvider{
+		key:   key,
+		keyID: hex.EncodeToString(fingerprint[:8]),
+	}, nil
+}
+
+// NewLocalProviderFromBase64 creates a local provider from a base64 encoded 32-byte key, as
+// generated by `openssl rand -base64 32`
+func NewLocalProviderFromBase64(encoded string) (*LocalProvider, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("local key is not valid base64: %w", err)
+	}
+	return NewLocalProvider(key)
+}
+
+// DeriveLocalProvider creates a local provider whose key is derived from another secret, for
+// installations that have not configured a dedicated key
+func DeriveLocalProvider(secret string) *LocalProvider {
+	key := sha256.Sum256([]byte("cyops-kms-local:" + secret))
+	provider, _ := NewLocalProvider(key[:])
+	return provider
+}
+
+// Name returns "local"
+func (p *LocalProvider) Name() string {
+	return ProviderLocal
+}
+
+// KeyID returns a fingerprint of the key, which identifies it without disclosing it
+func (p *LocalProvider) KeyID() string {
+	return p.keyID
+}
+
+// Wrap encrypts a data key with the local key
+func (p *LocalProvider) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	return seal(p.key, dataKey)
+}
+
+// Unwrap decrypts a data key wrapped by Wrap
+func (p *LocalProvider) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	return open(p.key, wrapped)
+}
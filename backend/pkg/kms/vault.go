@@ -0,0 +1,131 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultConfig configures a HashiCorp Vault transit engine key
+type VaultConfig struct {
+	Address   string // e.g. https://vault.example.com:8200
+	Token     string
+	Namespace string // Vault Enterprise namespace, optional
+	Mount     string // Transit engine mount path; defaults to "transit"
+	KeyName   string
+}
+
+// VaultProvider wraps data keys with a key of the Vault transit engine. Rotating the key in
+// Vault keeps earlier versions able to unwrap, so no key ID change is needed.
+type VaultProvider struct {
+	cfg        VaultConfig
+	address    *url.URL
+	httpClient *http.Client
+}
+
+// NewVaultProvider creates a Vault transit provider
+func NewVaultProvider(cfg VaultConfig) (*VaultProvider, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("vault token is required")
+	}
+	if cfg.KeyName == "" {
+		return nil, fmt.Errorf("vault transit key name is required")
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = "transit"
+	}
+	cfg.Mount = strings.Trim(cfg.Mount, "/")
+
+	address, err := url.Parse(strings.TrimRight(cfg.Address, "/"))
+	if err != nil || address.Host == "" || (address.Scheme != "http" && address.Scheme != "https") {
+		return nil, fmt.Errorf("invalid vault address: %s", cfg.Address)
+	}
+
+	return &VaultProvider{
+		cfg:        cfg,
+		address:    address,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Name returns "vault"
+func (p *VaultProvider) Name() string {
+	return ProviderVault
+}
+
+// KeyID returns the transit mount and key name
+func (p *VaultProvider) KeyID() string {
+	return p.cfg.Mount + "/" + p.cfg.KeyName
+}
+
+// Wrap encrypts a data key with the transit key
+func (p *VaultProvider) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	var out struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	err := p.post(ctx, "encrypt", map[string]string{
+		"plaintext": base64.StdEncoding.EncodeToString(dataKey),
+	}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out.Data.Ciphertext), nil
+}
+
+// Unwrap decrypts a data key wrapped by Wrap (under any version of the transit key)
+func (p *VaultProvider) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	err := p.post(ctx, "decrypt", map[string]string{
+		"ciphertext": string(wrapped),
+	}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(out.Data.Plaintext)
+}
+
+// post calls a transit endpoint for the key
+func (p *VaultProvider) post(ctx context.Context, operation string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/v1/%s/%s/%s", p.address, p.cfg.Mount, operation, url.PathEscape(p.cfg.KeyName))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", p.cfg.Token)
+	if p.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.cfg.Namespace)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("vault %s failed with status %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+// Package llm provides the pluggable large language model clients used to write report
+// narratives: OpenAI (and OpenAI-compatible endpoints), Azure OpenAI and Ollama.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Providers
+const (
+	ProviderOpenAI = "openai"
+	ProviderAzure  = "azure"
+	ProviderOllama = "ollama"
+)
+
+// Defaults applied when a Config leaves them empty
+const (
+	DefaultOpenAIBaseURL   = "https://api.openai.com/v1"
+	DefaultOllamaBaseURL   = "http://localhost:11434"
+	DefaultAzureAPIVersion = "2024-06-01"
+	DefaultTimeout         = 60 * time.Second
+)
+
+// ErrEmptyCompletion is returned when the model answers without any text
+var ErrEmptyCompletion = errors.New("the model returned an empty completion")
+
+// Request is a single-turn completion request
+type Request struct {
+	System      string  // Instructions
+	Prompt      string  // User message
+	MaxTokens   int     // 0 leaves the provider default
+	Temperature float64 // 0 is deterministic
+}
+
+// Client completes prompts with a configured model
+type Client interface {
+	// Provider returns the provider name recorded alongside generated text
+	Provider() string
+	// Model returns the model (or Azure deployment) that answers requests
+	Model() string
+	Complete(ctx context.Context, req Request) (string, error)
+}
+
+// Config selects and configures a client
+type Config struct {
+	Provider   string
+	BaseURL    string // API root; the Azure resource endpoint for azure
+	Model      string // Model name; the deployment name for azure
+	APIKey     string // Not used by ollama
+	APIVersion string // Azure API version
+	Timeout    time.Duration
+}
+
+// New creates the client selected by cfg.Provider
+func New(cfg Config) (Client, error) {
+	if strings.TrimSpace(cfg.Model) == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	httpClient := &http.Client{Timeout: cfg.Timeout}
+
+	switch cfg.Provider {
+	case ProviderOpenAI:
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = DefaultOpenAIBaseURL
+		}
+		endpoint, err := joinURL(cfg.BaseURL, "chat/completions")
+		if err != nil {
+			return nil, err
+		}
+		return &chatClient{provider: ProviderOpenAI, model: cfg.Model, endpoint: endpoint,
+			headers: bearer(cfg.APIKey), sendModel: true, http: httpClient}, nil
+
+	case ProviderAzure:
+		if cfg.BaseURL == "" {
+			return nil, fmt.Errorf("base_url (the Azure OpenAI resource endpoint) is required for azure")
+		}
+		if cfg.APIVersion == "" {
+			cfg.APIVersion = DefaultAzureAPIVersion
+		}
+		endpoint, err := joinURL(cfg.BaseURL, "openai/deployments/"+url.PathEscape(cfg.Model)+"/chat/completions")
+		if err != nil {
+			return nil, err
+		}
+		endpoint += "?api-version=" + url.QueryEscape(cfg.APIVersion)
+		return &chatClient{provider: ProviderAzure, model: cfg.Model, endpoint: endpoint,
+			headers: map[string]string{"api-key": cfg.APIKey}, http: httpClient}, nil
+
+	case ProviderOllama:
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = DefaultOllamaBaseURL
+		}
+		endpoint, err := joinURL(cfg.BaseURL, "api/chat")
+		if err != nil {
+			return nil, err
+		}
+		return &ollamaClient{model: cfg.Model, endpoint: endpoint, http: httpClient}, nil
+	}
+	return nil, fmt.Errorf("unknown provider: %s", cfg.Provider)
+}
+
+// joinURL appends a path to an API root
+func joinURL(base, path string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(base))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid base_url: %s", base)
+	}
+	return strings.TrimSuffix(parsed.String(), "/") + "/" + path, nil
+}
+
+// bearer returns the Authorization header of an API key, if any
+func bearer(apiKey string) map[string]string {
+	if apiKey == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + apiKey}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// messages returns the chat messages of a request
+func messages(req Request) []chatMessage {
+	var msgs []chatMessage
+	if req.System != "" {
+		msgs = append(msgs, chatMessage{Role: "system", Content: req.System})
+	}
+	return append(msgs, chatMessage{Role: "user", Content: req.Prompt})
+}
+
+// chatClient calls the OpenAI chat completions API, which Azure OpenAI also serves
+type chatClient struct {
+	provider  string
+	model     string
+	endpoint  string
+	headers   map[string]string
+	sendModel bool // Azure selects the model by deployment in the URL
+	http      *http.Client
+}
+
+func (c *chatClient) Provider() string { return c.provider }
+func (c *chatClient) Model() string    { return c.model }
+
+func (c *chatClient) Complete(ctx context.Context, req Request) (string, error) {
+	body := map[string]interface{}{
+		"messages":    messages(req),
+		"temperature": req.Temperature,
+	}
+	if c.sendModel {
+		body["model"] = c.model
+	}
+	if req.MaxTokens > 0 {
+		body["max_tokens"] = req.MaxTokens
+	}
+
+	var resp struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := postJSON(ctx, c.http, c.endpoint, c.headers, body, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return "", ErrEmptyCompletion
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
+
+// ollamaClient calls a local Ollama server's chat API
+type ollamaClient struct {
+	model    string
+	endpoint string
+	http     *http.Client
+}
+
+func (c *ollamaClient) Provider() string { return ProviderOllama }
+func (c *ollamaClient) Model() string    { return c.model }
+
+func (c *ollamaClient) Complete(ctx context.Context, req Request) (string, error) {
+	options := map[string]interface{}{"temperature": req.Temperature}
+	if req.MaxTokens > 0 {
+		options["num_predict"] = req.MaxTokens
+	}
+	body := map[string]interface{}{
+		"model":    c.model,
+		"messages": messages(req),
+		"stream":   false,
+		"options":  options,
+	}
+
+	var resp struct {
+		Message chatMessage `json:"message"`
+	}
+	if err := postJSON(ctx, c.http, c.endpoint, nil, body, &resp); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(resp.Message.Content) == "" {
+		return "", ErrEmptyCompletion
+	}
+	return strings.TrimSpace(resp.Message.Content), nil
+}
+
+// maxErrorBody bounds how much of a failed response is quoted in errors
+const maxErrorBody = 512
+
+// postJSON posts body as JSON and decodes the response into out
+func postJSON(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("llm request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("llm request failed: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid llm response: %w", err)
+	}
+	return nil
+}
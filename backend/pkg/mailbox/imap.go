@@ -0,0 +1,254 @@
+// Package mailbox reads messages from an IMAP4rev1 mailbox (RFC 3501). It implements the small
+// subset of the protocol needed to poll a mailbox: log in, select a folder, search for unseen
+// messages, fetch them whole and flag them as seen.
+package mailbox
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transports
+const (
+	SecurityTLS       = "tls"       // Implicit TLS, usually port 993
+	SecurityPlaintext = "plaintext" // Unencrypted, for local testing only
+)
+
+// Defaults applied when a Config leaves them empty
+const (
+	DefaultMailbox     = "INBOX"
+	DefaultDialTimeout = 15 * time.Second
+	DefaultIOTimeout   = 60 * time.Second
+)
+
+// maxLiteralSize bounds a literal (such as a fetched message) read from the server
+const maxLiteralSize = 50 * 1024 * 1024
+
+// Config is the connection to an IMAP server
+type Config struct {
+	Host               string
+	Port               int
+	Security           string // tls (default) or plaintext
+	InsecureSkipVerify bool   // Accept any certificate (testing only)
+	DialTimeout        time.Duration
+	IOTimeout          time.Duration // Deadline of each command
+}
+
+// Client is a connection to an IMAP server. It is not safe for concurrent use.
+type Client struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	ioTimeout time.Duration
+	tag       int
+}
+
+// response is an untagged server response; the literals it carried are collected in order
+type response struct {
+	text     string
+	literals [][]byte
+}
+
+// Dial connects to the server and reads its greeting
+func Dial(cfg Config) (*Client, error) {
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = DefaultDialTimeout
+	}
+	if cfg.IOTimeout == 0 {
+		cfg.IOTimeout = DefaultIOTimeout
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	dialer := &net.Dialer{Timeout: cfg.DialTimeout}
+
+	var conn net.Conn
+	var err error
+	switch cfg.Security {
+	case SecurityPlaintext:
+		conn, err = dialer.Dial("tcp", addr)
+	case SecurityTLS, "":
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{
+			ServerName:         cfg.Host,
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+			MinVersion:         tls.VersionTLS12,
+		})
+	default:
+		return nil, fmt.Errorf("invalid security: %s", cfg.Security)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	return NewClient(conn, cfg.IOTimeout)
+}
+
+// NewClient reads the greeting on an established connection
+func NewClient(conn net.Conn, ioTimeout time.Duration) (*Client, error) {
+	c := &Client{conn: conn, reader: bufio.NewReader(conn), ioTimeout: ioTimeout}
+	if ioTimeout > 0 {
+		conn.SetDeadline(time.Now().Add(ioTimeout))
+	}
+	greeting, err := c.readResponse()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read greeting: %w", err)
+	}
+	if !strings.HasPrefix(greeting.text, "* OK") && !strings.HasPrefix(greeting.text, "* PREAUTH") {
+		conn.Close()
+		return nil, fmt.Errorf("server rejected connection: %s", greeting.text)
+	}
+	return c, nil
+}
+
+// Close closes the connection without logging out
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Login authenticates with a user name and password
+func (c *Client) Login(username, password string) error {
+	_, err := c.command("LOGIN " + quote(username) + " " + quote(password))
+	return err
+}
+
+// Select opens a mailbox for reading and writing
+func (c *Client) Select(mailbox string) error {
+	if mailbox == "" {
+		mailbox = DefaultMailbox
+	}
+	_, err := c.command("SELECT " + quote(mailbox))
+	return err
+}
+
+// SearchUnseen returns the UIDs of the messages of the selected mailbox without the \Seen flag
+func (c *Client) SearchUnseen() ([]uint32, error) {
+	responses, err := c.command("UID SEARCH UNSEEN")
+	if err != nil {
+		return nil, err
+	}
+	var uids []uint32
+	for _, resp := range responses {
+		if !strings.HasPrefix(resp.text, "* SEARCH") {
+			continue
+		}
+		for _, field := range strings.Fields(strings.TrimPrefix(resp.text, "* SEARCH")) {
+			uid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid search response: %s", resp.text)
+			}
+			uids = append(uids, uint32(uid))
+		}
+	}
+	return uids, nil
+}
+
+// Fetch returns the full RFC 5322 message of a UID without setting its \Seen flag
+func (c *Client) Fetch(uid uint32) ([]byte, error) {
+	responses, err := c.command(fmt.Sprintf("UID FETCH %d BODY.PEEK[]", uid))
+	if err != nil {
+		return nil, err
+	}
+	for _, resp := range responses {
+		if strings.Contains(resp.text, " FETCH ") && len(resp.literals) > 0 {
+			return resp.literals[0], nil
+		}
+	}
+	return nil, fmt.Errorf("message %d not found", uid)
+}
+
+// MarkSeen sets the \Seen flag of a UID
+func (c *Client) MarkSeen(uid uint32) error {
+	_, err := c.command(fmt.Sprintf("UID STORE %d +FLAGS.SILENT (\\Seen)", uid))
+	return err
+}
+
+// Logout ends the session and closes the connection
+func (c *Client) Logout() error {
+	_, err := c.command("LOGOUT")
+	c.conn.Close()
+	return err
+}
+
+// command sends a tagged command and returns the untagged responses that preceded its
+// completion; a NO or BAD completion is returned as an error
+func (c *Client) command(cmd string) ([]response, error) {
+	c.tag++
+	tag := fmt.Sprintf("A%04d", c.tag)
+	if c.ioTimeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.ioTimeout))
+	}
+	if _, err := io.WriteString(c.conn, tag+" "+cmd+"\r\n"); err != nil {
+		return nil, fmt.Errorf("failed to send command: %w", err)
+	}
+
+	var responses []response
+	for {
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if !strings.HasPrefix(resp.text, tag+" ") {
+			responses = append(responses, resp)
+			continue
+		}
+		status := strings.TrimPrefix(resp.text, tag+" ")
+		if strings.HasPrefix(status, "OK") {
+			return responses, nil
+		}
+		name, _, _ := strings.Cut(cmd, " ")
+		return nil, fmt.Errorf("%s failed: %s", name, status)
+	}
+}
+
+// readResponse reads one response line together with the literals embedded in it
+func (c *Client) readResponse() (response, error) {
+	var resp response
+	var text strings.Builder
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return resp, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		size, ok := literalSize(line)
+		if !ok {
+			text.WriteString(line)
+			resp.text = text.String()
+			return resp, nil
+		}
+		if size > maxLiteralSize {
+			return resp, fmt.Errorf("literal of %d bytes exceeds the %d byte limit", size, maxLiteralSize)
+		}
+		literal := make([]byte, size)
+		if _, err := io.ReadFull(c.reader, literal); err != nil {
+			return resp, err
+		}
+		resp.literals = append(resp.literals, literal)
+		text.WriteString(line[:strings.LastIndex(line, "{")])
+	}
+}
+
+// literalSize returns the size of the literal announced at the end of a line ("... {123}")
+func literalSize(line string) (int, bool) {
+	if !strings.HasSuffix(line, "}") {
+		return 0, false
+	}
+	start := strings.LastIndex(line, "{")
+	if start < 0 {
+		return 0, false
+	}
+	size, err := strconv.Atoi(strings.TrimSuffix(line[start+1:len(line)-1], "+"))
+	if err != nil || size < 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+// quote returns a string as an IMAP quoted string
+func quote(value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", "").Replace(value)
+	return `"` + value + `"`
+}
@@ -0,0 +1,260 @@
+package mailer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Delivery statuses reported by provider events
+const (
+	StatusDelivered  = "DELIVERED"  // Accepted by the recipient's mail server
+	StatusDeferred   = "DEFERRED"   // Temporarily refused; the provider retries
+	StatusBounced    = "BOUNCED"    // Permanently refused by the recipient's mail server
+	StatusDropped    = "DROPPED"    // Not sent by the provider (suppressed or rejected)
+	StatusComplained = "COMPLAINED" // Marked as spam by the recipient
+)
+
+// Event is a delivery status change of a sent message
+type Event struct {
+	MessageID     string    `json:"message_id"` // Provider message ID returned by Send
+	Recipient     string    `json:"recipient"`
+	Status        string    `json:"status"`
+	Reason        string    `json:"reason,omitempty"`
+	ProviderEvent string    `json:"provider_event,omitempty"` // The provider's own event name
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// ParseSendGridEvents parses a SendGrid Event Webhook batch. Engagement events (opens, clicks,
+// unsubscribes) and "processed" are skipped; they don't change the delivery status.
+func ParseSendGridEvents(body []byte) ([]Event, error) {
+	var batch []struct {
+		Email       string `json:"email"`
+		Timestamp   int64  `json:"timestamp"`
+		Event       string `json:"event"`
+		SGMessageID string `json:"sg_message_id"`
+		Reason      string `json:"reason"`
+		Response    string `json:"response"`
+	}
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, fmt.Errorf("invalid sendgrid events: %v", err)
+	}
+
+	var events []Event
+	for _, e := range batch {
+		status := map[string]string{
+			"delivered":  StatusDelivered,
+			"deferred":   StatusDeferred,
+			"bounce":     StatusBounced,
+			"dropped":    StatusDropped,
+			"spamreport": StatusComplained,
+		}[e.Event]
+		if status == "" || e.SGMessageID == "" {
+			continue
+		}
+		// sg_message_id is the X-Message-Id returned on send followed by a filter suffix
+		messageID, _, _ := strings.Cut(e.SGMessageID, ".")
+		reason := e.Reason
+		if reason == "" {
+			reason = e.Response
+		}
+		events = append(events, Event{
+			MessageID:     messageID,
+			Recipient:     e.Email,
+			Status:        status,
+			Reason:        reason,
+			ProviderEvent: e.Event,
+			OccurredAt:    time.Unix(e.Timestamp, 0).UTC(),
+		})
+	}
+	return events, nil
+}
+
+// ParseMailgunEvent parses a Mailgun webhook. Events that don't change the delivery status
+// (accepted, opened, clicked, unsubscribed) return no event.
+func ParseMailgunEvent(body []byte) ([]Event, error) {
+	var payload struct {
+		EventData struct {
+			Event     string  `json:"event"`
+			Severity  string  `json:"severity"`
+			Timestamp float64 `json:"timestamp"`
+			Recipient string  `json:"recipient"`
+			Reason    string  `json:"reason"`
+			Message   struct {
+				Headers struct {
+					MessageID string `json:"message-id"`
+				} `json:"headers"`
+			} `json:"message"`
+			DeliveryStatus struct {
+				Message     string `json:"message"`
+				Description string `json:"description"`
+			} `json:"delivery-status"`
+		} `json:"event-data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid mailgun event: %v", err)
+	}
+	data := payload.EventData
+
+	var status string
+	switch data.Event {
+	case "delivered":
+		status = StatusDelivered
+	case "failed":
+		status = StatusBounced
+		if data.Severity == "temporary" {
+			status = StatusDeferred
+		} else if strings.HasPrefix(data.Reason, "suppress") {
+			status = StatusDropped
+		}
+	case "complained":
+		status = StatusComplained
+	}
+	if status == "" || data.Message.Headers.MessageID == "" {
+		return nil, nil
+	}
+
+	reason := data.DeliveryStatus.Description
+	if reason == "" {
+		reason = data.DeliveryStatus.Message
+	}
+	if reason == "" {
+		reason = data.Reason
+	}
+	sec, frac := math.Modf(data.Timestamp)
+	return []Event{{
+		MessageID:     strings.Trim(data.Message.Headers.MessageID, "<>"),
+		Recipient:     data.Recipient,
+		Status:        status,
+		Reason:        reason,
+		ProviderEvent: data.Event,
+		OccurredAt:    time.Unix(int64(sec), int64(frac*1e9)).UTC(),
+	}}, nil
+}
+
+// snsEnvelope is an Amazon SNS HTTP(S) delivery
+type snsEnvelope struct {
+	Type         string `json:"Type"`
+	Message      string `json:"Message"`
+	SubscribeURL string `json:"SubscribeURL"`
+}
+
+type sesRecipient struct {
+	EmailAddress   string `json:"emailAddress"`
+	DiagnosticCode string `json:"diagnosticCode"`
+}
+
+// ParseSESEvent parses an Amazon SNS delivery of an SES event (from a configuration set's event
+// destination or an identity's notifications). Subscription confirmations carry no event; their
+// subscribe URL is returned so an administrator can confirm the subscription.
+func ParseSESEvent(body []byte) (events []Event, subscribeURL string, err error) {
+	var envelope snsEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, "", fmt.Errorf("invalid SNS notification: %v", err)
+	}
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		return nil, envelope.SubscribeURL, nil
+	case "Notification":
+	default:
+		return nil, "", fmt.Errorf("unsupported SNS message type %q", envelope.Type)
+	}
+
+	var notification struct {
+		EventType        string `json:"eventType"`        // Configuration set event publishing
+		NotificationType string `json:"notificationType"` // Identity notifications
+		Mail             struct {
+			MessageID   string   `json:"messageId"`
+			Destination []string `json:"destination"`
+			Timestamp   string   `json:"timestamp"`
+		} `json:"mail"`
+		Bounce *struct {
+			BounceType        string         `json:"bounceType"`
+			BouncedRecipients []sesRecipient `json:"bouncedRecipients"`
+			Timestamp         string         `json:"timestamp"`
+		} `json:"bounce"`
+		Complaint *struct {
+			ComplainedRecipients  []sesRecipient `json:"complainedRecipients"`
+			ComplaintFeedbackType string         `json:"complaintFeedbackType"`
+			Timestamp             string         `json:"timestamp"`
+		} `json:"complaint"`
+		Delivery *struct {
+			Recipients   []string `json:"recipients"`
+			SMTPResponse string   `json:"smtpResponse"`
+			Timestamp    string   `json:"timestamp"`
+		} `json:"delivery"`
+		DeliveryDelay *struct {
+			DelayType         string         `json:"delayType"`
+			DelayedRecipients []sesRecipient `json:"delayedRecipients"`
+			Timestamp         string         `json:"timestamp"`
+		} `json:"deliveryDelay"`
+		Reject *struct {
+			Reason string `json:"reason"`
+		} `json:"reject"`
+	}
+	if err := json.Unmarshal([]byte(envelope.Message), &notification); err != nil {
+		return nil, "", fmt.Errorf("invalid SES event: %v", err)
+	}
+
+	eventType := notification.EventType
+	if eventType == "" {
+		eventType = notification.NotificationType
+	}
+	event := Event{MessageID: notification.Mail.MessageID, ProviderEvent: eventType}
+	if len(notification.Mail.Destination) > 0 {
+		event.Recipient = notification.Mail.Destination[0]
+	}
+	timestamp := notification.Mail.Timestamp
+	firstRecipient := func(recipients []sesRecipient) {
+		if len(recipients) > 0 {
+			event.Recipient = recipients[0].EmailAddress
+			event.Reason = recipients[0].DiagnosticCode
+		}
+	}
+
+	switch {
+	case eventType == "Delivery" && notification.Delivery != nil:
+		event.Status = StatusDelivered
+		event.Reason = notification.Delivery.SMTPResponse
+		timestamp = notification.Delivery.Timestamp
+	case eventType == "Bounce" && notification.Bounce != nil:
+		event.Status = StatusBounced
+		if notification.Bounce.BounceType == "Transient" {
+			event.Status = StatusDeferred
+		}
+		firstRecipient(notification.Bounce.BouncedRecipients)
+		if event.Reason == "" {
+			event.Reason = notification.Bounce.BounceType + " bounce"
+		}
+		timestamp = notification.Bounce.Timestamp
+	case eventType == "Complaint" && notification.Complaint != nil:
+		event.Status = StatusComplained
+		firstRecipient(notification.Complaint.ComplainedRecipients)
+		event.Reason = notification.Complaint.ComplaintFeedbackType
+		timestamp = notification.Complaint.Timestamp
+	case eventType == "DeliveryDelay" && notification.DeliveryDelay != nil:
+		event.Status = StatusDeferred
+		firstRecipient(notification.DeliveryDelay.DelayedRecipients)
+		if event.Reason == "" {
+			event.Reason = notification.DeliveryDelay.DelayType
+		}
+		timestamp = notification.DeliveryDelay.Timestamp
+	case eventType == "Reject" && notification.Reject != nil:
+		event.Status = StatusDropped
+		event.Reason = notification.Reject.Reason
+	default:
+		// Send, Open, Click and other events don't change the delivery status
+		return nil, "", nil
+	}
+	if event.MessageID == "" {
+		return nil, "", nil
+	}
+
+	event.OccurredAt = time.Now().UTC()
+	if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
+		event.OccurredAt = t.UTC()
+	}
+	return []Event{event}, "", nil
+}
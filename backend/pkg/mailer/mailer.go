@@ -0,0 +1,149 @@
+// Package mailer delivers email through SMTP or an email API (SendGrid, Amazon SES, Mailgun)
+// and parses the delivery status events those providers post back.
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Providers
+const (
+	ProviderSMTP     = "smtp"
+	ProviderSendGrid = "sendgrid"
+	ProviderSES      = "ses"
+	ProviderMailgun  = "mailgun"
+)
+
+// DefaultTimeout bounds a provider API call when a Config leaves it empty
+const DefaultTimeout = 30 * time.Second
+
+// Message is an HTML email to a single recipient
+type Message struct {
+	From    string // Address, optionally with a display name ("CyOps <noreply@example.com>")
+	To      string
+	Subject string
+	HTML    string
+}
+
+// Sender delivers messages through a provider
+type Sender interface {
+	// Provider returns the provider name recorded with each delivery
+	Provider() string
+	// Send hands the message to the provider and returns the provider's message ID, which its
+	// delivery status events refer to
+	Send(ctx context.Context, msg Message) (string, error)
+}
+
+// Config selects and configures a sender
+type Config struct {
+	Provider string
+
+	// SMTP
+	Host     string
+	Port     int
+	Username string
+	Password string
+
+	// SendGrid and Mailgun
+	APIKey string
+	Domain string // Mailgun sending domain
+
+	// Amazon SES
+	AccessKeyID      string
+	SecretAccessKey  string
+	ConfigurationSet string // Publishes delivery events to SNS
+
+	Region   string // SES region; "eu" for Mailgun's EU region
+	Endpoint string // API root override (tests and compatible services)
+	Timeout  time.Duration
+
+	// Now returns the signing time of SES requests; defaults to time.Now (overridden in tests)
+	Now func() time.Time
+}
+
+// New creates the sender selected by cfg.Provider
+func New(cfg Config) (Sender, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	httpClient := &http.Client{Timeout: cfg.Timeout}
+
+	switch cfg.Provider {
+	case ProviderSMTP:
+		if cfg.Host == "" {
+			return nil, fmt.Errorf("smtp host is required")
+		}
+		if cfg.Port == 0 {
+			cfg.Port = 587
+		}
+		return &smtpSender{cfg: cfg}, nil
+	case ProviderSendGrid:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("sendgrid api key is required")
+		}
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = "https://api.sendgrid.com"
+		}
+		return &sendGridSender{cfg: cfg, httpClient: httpClient}, nil
+	case ProviderMailgun:
+		if cfg.APIKey == "" || cfg.Domain == "" {
+			return nil, fmt.Errorf("mailgun api key and domain are required")
+		}
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = "https://api.mailgun.net"
+			if strings.EqualFold(cfg.Region, "eu") {
+				cfg.Endpoint = "https://api.eu.mailgun.net"
+			}
+		}
+		return &mailgunSender{cfg: cfg, httpClient: httpClient}, nil
+	case ProviderSES:
+		if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+			return nil, fmt.Errorf("aws credentials are required")
+		}
+		if cfg.Region == "" {
+			cfg.Region = "us-east-1"
+		}
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = "https://email." + cfg.Region + ".amazonaws.com"
+		}
+		return newSESSender(cfg, httpClient)
+	}
+	return nil, fmt.Errorf("invalid provider: %s", cfg.Provider)
+}
+
+// parseAddress splits a From or To value into its display name and address
+func parseAddress(value string) (*mail.Address, error) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %v", value, err)
+	}
+	return addr, nil
+}
+
+// newMessageID generates a Message-ID for mail sent over SMTP, at the sender's domain
+func newMessageID(from string) string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	domain := "localhost"
+	if _, d, ok := strings.Cut(from, "@"); ok && d != "" {
+		domain = d
+	}
+	return hex.EncodeToString(buf) + "@" + domain
+}
+
+// apiError reads the body of a failed provider API response into an error
+func apiError(provider string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s returned status %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(msg)))
+}
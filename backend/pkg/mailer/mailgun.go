@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// mailgunSender sends mail with the Mailgun Messages API
+type mailgunSender struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+func (s *mailgunSender) Provider() string {
+	return ProviderMailgun
+}
+
+func (s *mailgunSender) Send(ctx context.Context, msg Message) (string, error) {
+	form := url.Values{}
+	form.Set("from", msg.From)
+	form.Set("to", msg.To)
+	form.Set("subject", msg.Subject)
+	form.Set("html", msg.HTML)
+
+	endpoint := strings.TrimRight(s.cfg.Endpoint, "/") + "/v3/" + url.PathEscape(s.cfg.Domain) + "/messages"
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create mailgun request: %w", err)
+	}
+	req.SetBasicAuth("api", s.cfg.APIKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("mailgun request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", apiError(ProviderMailgun, resp)
+	}
+
+	var out struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode mailgun response: %w", err)
+	}
+	// Events carry the Message-ID without its angle brackets
+	return strings.Trim(out.ID, "<>"), nil
+}
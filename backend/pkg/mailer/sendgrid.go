@@ -0,0 +1,66 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// sendGridSender sends mail with the SendGrid v3 Mail Send API
+type sendGridSender struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+func (s *sendGridSender) Provider() string {
+	return ProviderSendGrid
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+func (s *sendGridSender) Send(ctx context.Context, msg Message) (string, error) {
+	from, err := parseAddress(msg.From)
+	if err != nil {
+		return "", err
+	}
+	to, err := parseAddress(msg.To)
+	if err != nil {
+		return "", err
+	}
+
+	payload := map[string]interface{}{
+		"personalizations": []map[string]interface{}{
+			{"to": []sendGridAddress{{Email: to.Address, Name: to.Name}}},
+		},
+		"from":    sendGridAddress{Email: from.Address, Name: from.Name},
+		"subject": msg.Subject,
+		"content": []map[string]string{{"type": "text/html", "value": msg.HTML}},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", strings.TrimRight(s.cfg.Endpoint, "/")+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create sendgrid request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.cfg.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("sendgrid request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return "", apiError(ProviderSendGrid, resp)
+	}
+	return resp.Header.Get("X-Message-Id"), nil
+}
@@ -0,0 +1,109 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cyops/cyops-backend/pkg/awsauth"
+)
+
+// sesSender sends mail with the Amazon SES v2 API, signing requests with AWS Signature Version 4
+type sesSender struct {
+	cfg        Config
+	endpoint   *url.URL
+	signer     awsauth.Signer
+	httpClient *http.Client
+}
+
+func newSESSender(cfg Config, httpClient *http.Client) (*sesSender, error) {
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid ses endpoint: %s", cfg.Endpoint)
+	}
+	signer := awsauth.Signer{
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		Region:          cfg.Region,
+		Service:         "ses",
+	}
+	return &sesSender{cfg: cfg, endpoint: endpoint, signer: signer, httpClient: httpClient}, nil
+}
+
+func (s *sesSender) Provider() string {
+	return ProviderSES
+}
+
+func (s *sesSender) Send(ctx context.Context, msg Message) (string, error) {
+	content := func(data string) map[string]string {
+		return map[string]string{"Data": data, "Charset": "UTF-8"}
+	}
+	payload := map[string]interface{}{
+		"FromEmailAddress": msg.From,
+		"Destination":      map[string][]string{"ToAddresses": {msg.To}},
+		"Content": map[string]interface{}{
+			"Simple": map[string]interface{}{
+				"Subject": content(msg.Subject),
+				"Body":    map[string]interface{}{"Html": content(msg.HTML)},
+			},
+		},
+	}
+	if s.cfg.ConfigurationSet != "" {
+		payload["ConfigurationSetName"] = s.cfg.ConfigurationSet
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	const path = "/v2/email/outbound-emails"
+	req, err := http.NewRequestWithContext(ctx, "POST", s.endpoint.String()+path, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create ses request: %w", err)
+	}
+
+	now := s.cfg.Now().UTC()
+	payloadHash := awsauth.SHA256Hex(body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Amz-Date", now.Format(awsauth.TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Sign host, content type and the x-amz-* headers
+	canonicalHeaders, signedHeaders := awsauth.CanonicalHeaders(map[string]string{
+		"content-type":         req.Header.Get("Content-Type"),
+		"host":                 s.endpoint.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           req.Header.Get("X-Amz-Date"),
+	})
+
+	canonicalRequest := strings.Join([]string{
+		"POST",
+		path,
+		"",
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	req.Header.Set("Authorization", s.signer.Authorization(now, signedHeaders, canonicalRequest))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ses request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", apiError(ProviderSES, resp)
+	}
+
+	var out struct {
+		MessageID string `json:"MessageId"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("failed to decode ses response: %w", err)
+	}
+	return out.MessageID, nil
+}
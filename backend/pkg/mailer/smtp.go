@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// smtpSender delivers mail to an SMTP relay
+type smtpSender struct {
+	cfg Config
+}
+
+func (s *smtpSender) Provider() string {
+	return ProviderSMTP
+}
+
+// Send relays the message. SMTP reports no delivery events, so the returned ID is the
+// Message-ID header the message was sent with.
+func (s *smtpSender) Send(ctx context.Context, msg Message) (string, error) {
+	from, err := parseAddress(msg.From)
+	if err != nil {
+		return "", err
+	}
+	to, err := parseAddress(msg.To)
+	if err != nil {
+		return "", err
+	}
+
+	messageID := newMessageID(from.Address)
+	body := []byte(fmt.Sprintf("To: %s\r\n"+
+		"From: %s\r\n"+
+		"Subject: %s\r\n"+
+		"Date: %s\r\n"+
+		"Message-ID: <%s>\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/html; charset=UTF-8\r\n"+
+		"\r\n"+
+		"%s\r\n", msg.To, msg.From, mime.QEncoding.Encode("utf-8", msg.Subject), time.Now().Format(time.RFC1123Z), messageID, msg.HTML))
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := s.cfg.Host + ":" + strconv.Itoa(s.cfg.Port)
+	if err := smtp.SendMail(addr, auth, from.Address, []string{to.Address}, body); err != nil {
+		return "", fmt.Errorf("smtp delivery failed: %w", err)
+	}
+	return messageID, nil
+}
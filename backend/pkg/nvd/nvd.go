@@ -0,0 +1,168 @@
+// Package nvd queries the NIST National Vulnerability Database CVE API 2.0
+// (https://nvd.nist.gov/developers/vulnerabilities) for the remediation of a CVE: its patch and
+// vendor advisory references and the versions its affected configurations end at.
+package nvd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the public CVE API
+const DefaultBaseURL = "https://services.nvd.nist.gov/rest/json/cves/2.0"
+
+// maxErrorBody bounds how much of a failed response is quoted in errors
+const maxErrorBody = 512
+
+// Request spacing within the NVD rate limits: 5 requests per 30 seconds without an API key,
+// 50 with one
+const (
+	RequestInterval        = 6 * time.Second
+	RequestIntervalWithKey = 600 * time.Millisecond
+)
+
+// Reference tags NVD analysts assign
+const (
+	TagPatch          = "Patch"
+	TagVendorAdvisory = "Vendor Advisory"
+	TagMitigation     = "Mitigation"
+)
+
+// CVE is the part of an NVD CVE record describing its remediation
+type CVE struct {
+	ID         string `json:"id"`
+	References []struct {
+		URL  string   `json:"url"`
+		Tags []string `json:"tags"`
+	} `json:"references"`
+	Configurations []struct {
+		Nodes []struct {
+			CPEMatch []struct {
+				Vulnerable          bool   `json:"vulnerable"`
+				Criteria            string `json:"criteria"`
+				VersionEndExcluding string `json:"versionEndExcluding"`
+			} `json:"cpeMatch"`
+		} `json:"nodes"`
+	} `json:"configurations"`
+}
+
+// Remediation is the remediation guidance NVD records for a CVE
+type Remediation struct {
+	PatchAvailable bool   // A reference is tagged Patch
+	AdvisoryURL    string // First vendor advisory, else first patch reference
+	MitigationURL  string // First reference tagged Mitigation
+	FixedVersion   string // First version outside a vulnerable configuration range
+}
+
+// Remediation summarizes the CVE's references and configurations
+func (c *CVE) Remediation() Remediation {
+	var r Remediation
+	var patchURL string
+	for _, ref := range c.References {
+		for _, tag := range ref.Tags {
+			switch tag {
+			case TagPatch:
+				r.PatchAvailable = true
+				if patchURL == "" {
+					patchURL = ref.URL
+				}
+			case TagVendorAdvisory:
+				if r.AdvisoryURL == "" {
+					r.AdvisoryURL = ref.URL
+				}
+			case TagMitigation:
+				if r.MitigationURL == "" {
+					r.MitigationURL = ref.URL
+				}
+			}
+		}
+	}
+	if r.AdvisoryURL == "" {
+		r.AdvisoryURL = patchURL
+	}
+	for _, config := range c.Configurations {
+		for _, node := range config.Nodes {
+			for _, match := range node.CPEMatch {
+				if match.Vulnerable && match.VersionEndExcluding != "" && r.FixedVersion == "" {
+					r.FixedVersion = match.VersionEndExcluding
+				}
+			}
+		}
+	}
+	return r
+}
+
+// Client queries the CVE API
+type Client struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+}
+
+// NewClient creates a client of the CVE API at baseURL (DefaultBaseURL when empty). The API key
+// is optional and raises the rate limit.
+func NewClient(baseURL, apiKey string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Interval returns the spacing between requests that keeps the client within the rate limit
+func (c *Client) Interval() time.Duration {
+	if c.apiKey != "" {
+		return RequestIntervalWithKey
+	}
+	return RequestInterval
+}
+
+// CVE returns the record of a CVE, or nil when NVD does not know it
+func (c *Client) CVE(ctx context.Context, id string) (*CVE, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"?cveId="+url.QueryEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.apiKey != "" {
+		req.Header.Set("apiKey", c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("nvd request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, fmt.Errorf("nvd request failed: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+
+	var body struct {
+		Vulnerabilities []struct {
+			CVE CVE `json:"cve"`
+		} `json:"vulnerabilities"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid nvd response: %w", err)
+	}
+	for _, v := range body.Vulnerabilities {
+		if strings.EqualFold(v.CVE.ID, id) {
+			cve := v.CVE
+			return &cve, nil
+		}
+	}
+	return nil, nil
+}
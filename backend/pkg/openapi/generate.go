@@ -0,0 +1,398 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Annotation documents one handler. cmd/openapi-gen builds them from the handlers' doc
+// comments and swag-style annotations (@Summary, @Param, @Success, ...) and from the request
+// bodies and query parameters the handlers read.
+type Annotation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Params      []ParamAnnotation
+	Responses   []ResponseAnnotation
+}
+
+// ParamAnnotation documents a request parameter or body
+type ParamAnnotation struct {
+	Name        string // Empty for a query struct, whose `query` tags name the parameters
+	In          string // path, query, header or body
+	Type        string // string, int, bool, number; ignored when Model is set
+	Required    bool
+	Description string
+	Default     string
+	Model       reflect.Type // Body type, or the struct a handler binds the query string into
+}
+
+// ResponseAnnotation documents one response status
+type ResponseAnnotation struct {
+	Status      int
+	Description string
+	Model       reflect.Type // Nil for a response without a documented body
+	Array       bool         // The body is a JSON array of Model
+	File        bool         // The body is a file download
+}
+
+// Route is a registered endpoint and the handler chain that serves it, including the group
+// middleware registered before it with Use
+type Route struct {
+	Method   string
+	Path     string
+	Handlers []fiber.Handler
+}
+
+// Options configures document generation
+type Options struct {
+	Info     Info
+	BasePath string // Only routes below it are documented, with paths relative to it
+	// Annotations are keyed by HandlerName
+	Annotations map[string]Annotation
+	// Authenticators are the HandlerName prefixes of middleware that require a bearer token
+	Authenticators []string
+	// ErrorModel documents error responses of operations that do not annotate their own
+	ErrorModel reflect.Type
+	// AnnotatedErrorModel is the error body handler annotations refer to; responses annotated
+	// with it are documented with ErrorModel, since the API version decides the error shape
+	AnnotatedErrorModel reflect.Type
+}
+
+// BearerAuth is the name of the bearer token security scheme
+const BearerAuth = "BearerAuth"
+
+// Routes lists the endpoints of app in registration order. Fiber stores middleware added with
+// Use as separate routes, so each endpoint is given the Use handlers registered before it on
+// a matching path prefix.
+func Routes(app *fiber.App) []Route {
+	endpoints := app.GetRoutes(true)
+	var routes []Route
+	var middleware []fiber.Route
+	next := 0
+	method := ""
+	for _, route := range app.GetRoutes() {
+		if route.Method != method {
+			// Every method has its own stack
+			method = route.Method
+			middleware = nil
+		}
+		if next >= len(endpoints) || !sameRoute(route, endpoints[next]) {
+			middleware = append(middleware, route)
+			continue
+		}
+		next++
+
+		var handlers []fiber.Handler
+		for _, use := range middleware {
+			if usePrefixMatches(use.Path, route.Path) {
+				handlers = append(handlers, use.Handlers...)
+			}
+		}
+		routes = append(routes, Route{
+			Method:   route.Method,
+			Path:     route.Path,
+			Handlers: append(handlers, route.Handlers...),
+		})
+	}
+	return routes
+}
+
+// sameRoute reports whether a and b are copies of the same registered route
+func sameRoute(a, b fiber.Route) bool {
+	return a.Method == b.Method && a.Path == b.Path && len(a.Handlers) == len(b.Handlers) &&
+		len(a.Handlers) > 0 && &a.Handlers[0] == &b.Handlers[0]
+}
+
+// usePrefixMatches reports whether Use middleware registered on prefix runs for path
+func usePrefixMatches(prefix, path string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// HandlerName returns the package-qualified name of a handler function, e.g.
+// "handlers.(*VulnerabilityHandler).GetVulnerability" or "middleware.AuthMiddleware.func1"
+func HandlerName(handler fiber.Handler) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(handler).Pointer())
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	if slash := strings.LastIndex(name, "/"); slash >= 0 {
+		name = name[slash+1:]
+	}
+	// Method values are wrapped in a -fm function
+	return strings.TrimSuffix(name, "-fm")
+}
+
+var (
+	pathParamPattern = regexp.MustCompile(`:([A-Za-z0-9_]+)(<[^>]*>)?\??`)
+	nonWordPattern   = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// documentedMethods are the methods listed in the document; HEAD routes mirror GET ones
+var documentedMethods = map[string]bool{
+	fiber.MethodGet: true, fiber.MethodPost: true, fiber.MethodPut: true,
+	fiber.MethodPatch: true, fiber.MethodDelete: true,
+}
+
+// Generate builds the document of the routes below opts.BasePath
+func Generate(routes []Route, opts Options) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    opts.Info,
+		Servers: []Server{{URL: opts.BasePath}},
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				BearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Session token or API key"},
+			},
+		},
+	}
+	schemas := NewSchemas()
+	operationIDs := map[string]bool{}
+	tags := map[string]bool{}
+
+	basePath := strings.TrimSuffix(opts.BasePath, "/")
+	for _, route := range routes {
+		if !documentedMethods[route.Method] || len(route.Handlers) == 0 {
+			continue
+		}
+		relative, ok := strings.CutPrefix(route.Path, basePath)
+		if !ok || (relative != "" && !strings.HasPrefix(relative, "/")) {
+			continue
+		}
+		relative = strings.TrimSuffix(relative, "/")
+		if relative == "" {
+			relative = "/"
+		}
+		path := pathParamPattern.ReplaceAllString(strings.ReplaceAll(relative, "*", ":path"), "{$1}")
+
+		method := strings.ToLower(route.Method)
+		item := doc.Paths[path]
+		if item == nil {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		if _, exists := item[method]; exists {
+			// Fiber serves the first registration
+			continue
+		}
+
+		handler := HandlerName(route.Handlers[len(route.Handlers)-1])
+		op := buildOperation(schemas, route, path, handler, opts)
+		op.OperationID = uniqueOperationID(operationIDs, operationID(handler, method, path))
+		for _, tag := range op.Tags {
+			tags[tag] = true
+		}
+		item[method] = op
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	if len(schemas.Components) > 0 {
+		doc.Components.Schemas = schemas.Components
+	}
+	return doc
+}
+
+// buildOperation documents one route from its path, middleware and handler annotation
+func buildOperation(schemas *Schemas, route Route, path, handler string, opts Options) *Operation {
+	annotation := opts.Annotations[handler]
+	op := &Operation{
+		Summary:     annotation.Summary,
+		Description: annotation.Description,
+		Tags:        annotation.Tags,
+		Responses:   map[string]Response{},
+	}
+	if len(op.Tags) == 0 {
+		op.Tags = []string{pathTag(path)}
+	}
+
+	for _, mw := range route.Handlers[:len(route.Handlers)-1] {
+		if hasAnyPrefix(HandlerName(mw), opts.Authenticators) {
+			op.Security = []map[string][]string{{BearerAuth: {}}}
+			break
+		}
+	}
+
+	documented := map[string]bool{}
+	for _, param := range annotation.Params {
+		switch {
+		case param.In == "body":
+			if op.RequestBody == nil && param.Model != nil {
+				op.RequestBody = &RequestBody{
+					Description: param.Description,
+					Required:    param.Required,
+					Content:     jsonContent(schemas.SchemaOf(param.Model), false),
+				}
+			}
+		case param.Name == "" && param.Model != nil:
+			for _, p := range queryStructParams(schemas, param.Model) {
+				if !documented[p.In+":"+p.Name] {
+					documented[p.In+":"+p.Name] = true
+					op.Parameters = append(op.Parameters, p)
+				}
+			}
+		case param.Name != "" && !documented[param.In+":"+param.Name]:
+			documented[param.In+":"+param.Name] = true
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        param.Name,
+				In:          param.In,
+				Description: param.Description,
+				Required:    param.Required || param.In == "path",
+				Schema:      paramSchema(param),
+			})
+		}
+	}
+	// Path parameters the annotation does not describe
+	for _, match := range pathParamPattern.FindAllStringSubmatch(strings.ReplaceAll(route.Path, "*", ":path"), -1) {
+		if !documented["path:"+match[1]] {
+			documented["path:"+match[1]] = true
+			op.Parameters = append(op.Parameters, Parameter{
+				Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"},
+			})
+		}
+	}
+
+	hasError := false
+	for _, response := range annotation.Responses {
+		if response.Model != nil && response.Model == opts.AnnotatedErrorModel && opts.ErrorModel != nil {
+			response.Model = opts.ErrorModel
+		}
+		var content map[string]MediaType
+		switch {
+		case response.File:
+			content = map[string]MediaType{"application/octet-stream": {Schema: &Schema{Type: "string", Format: "binary"}}}
+		case response.Model != nil:
+			content = jsonContent(schemas.SchemaOf(response.Model), response.Array)
+		}
+		description := response.Description
+		if description == "" {
+			description = http.StatusText(response.Status)
+		}
+		op.Responses[strconv.Itoa(response.Status)] = Response{Description: description, Content: content}
+		hasError = hasError || response.Status >= 400
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["200"] = Response{Description: http.StatusText(http.StatusOK)}
+	}
+	if !hasError && opts.ErrorModel != nil {
+		op.Responses["default"] = Response{
+			Description: "Error",
+			Content:     jsonContent(schemas.SchemaOf(opts.ErrorModel), false),
+		}
+	}
+	return op
+}
+
+// queryStructParams lists the query parameters of a struct bound with QueryParser
+func queryStructParams(schemas *Schemas, model reflect.Type) []Parameter {
+	for model.Kind() == reflect.Ptr {
+		model = model.Elem()
+	}
+	if model.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []Parameter
+	for i := 0; i < model.NumField(); i++ {
+		field := model.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("query"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		params = append(params, Parameter{Name: name, In: "query", Schema: schemas.SchemaOf(field.Type)})
+	}
+	return params
+}
+
+// paramSchema returns the schema of a swag parameter type
+func paramSchema(param ParamAnnotation) *Schema {
+	var schema *Schema
+	switch param.Type {
+	case "int", "integer":
+		schema = &Schema{Type: "integer"}
+	case "bool", "boolean":
+		schema = &Schema{Type: "boolean"}
+	case "number", "float":
+		schema = &Schema{Type: "number"}
+	case "file":
+		schema = &Schema{Type: "string", Format: "binary"}
+	default:
+		schema = &Schema{Type: "string"}
+	}
+	if param.Default != "" {
+		schema.Default = param.Default
+	}
+	return schema
+}
+
+// jsonContent returns a JSON body of schema, or of an array of it
+func jsonContent(schema *Schema, array bool) map[string]MediaType {
+	if array {
+		schema = &Schema{Type: "array", Items: schema}
+	}
+	return map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: schema}}
+}
+
+// pathTag returns the default tag of a path: its first segment, e.g. "Business Services" for
+// /business-services/{id}
+func pathTag(path string) string {
+	segment := strings.Split(strings.TrimPrefix(path, "/"), "/")[0]
+	if segment == "" || strings.HasPrefix(segment, "{") {
+		return "API"
+	}
+	words := strings.Fields(nonWordPattern.ReplaceAllString(segment, " "))
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// operationID returns the operation ID of a handler: its method name, or the method and path
+// for anonymous handlers
+func operationID(handler, method, path string) string {
+	name := handler[strings.LastIndex(handler, ".")+1:]
+	if name != "" && !strings.HasPrefix(name, "func") {
+		return name
+	}
+
+	id := method
+	for _, word := range strings.Fields(nonWordPattern.ReplaceAllString(path, " ")) {
+		id += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return id
+}
+
+// uniqueOperationID suffixes id when a handler serves several routes
+func uniqueOperationID(seen map[string]bool, id string) string {
+	unique := id
+	for n := 2; seen[unique]; n++ {
+		unique = id + strconv.Itoa(n)
+	}
+	seen[unique] = true
+	return unique
+}
+
+// hasAnyPrefix reports whether s starts with one of prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
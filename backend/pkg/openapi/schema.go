@@ -0,0 +1,157 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	uuidType          = reflect.TypeOf(uuid.UUID{})
+	deletedAtType     = reflect.TypeOf(gorm.DeletedAt{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Schemas builds JSON schemas of Go types as encoding/json serializes them. Named structs
+// become component schemas, referenced by name (e.g. models.Vulnerability).
+type Schemas struct {
+	Components map[string]*Schema
+}
+
+// NewSchemas creates an empty schema registry
+func NewSchemas() *Schemas {
+	return &Schemas{Components: map[string]*Schema{}}
+}
+
+// SchemaName returns the component name of a named type: its package name and type name
+func SchemaName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+// SchemaOf returns the schema of t, registering the component schemas it refers to
+func (s *Schemas) SchemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case deletedAtType:
+		return &Schema{Type: "string", Format: "date-time", Nullable: true}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case rawMessageType:
+		return &Schema{}
+	}
+	if implements(t, jsonMarshalerType) {
+		// Custom JSON encodings have no schema to reflect
+		return &Schema{}
+	}
+	if implements(t, textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8,
+		reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.SchemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.SchemaOf(t.Elem())}
+	case reflect.Struct:
+		return s.structSchema(t)
+	}
+	// Interfaces and anything else encoding/json decides at run time
+	return &Schema{}
+}
+
+// structSchema returns the schema of a struct: a reference for named structs, inline otherwise
+func (s *Schemas) structSchema(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return s.objectSchema(t)
+	}
+
+	name := SchemaName(t)
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if _, ok := s.Components[name]; ok {
+		return ref
+	}
+	// Registered before its fields so self-referencing types terminate
+	s.Components[name] = &Schema{Type: "object"}
+	*s.Components[name] = *s.objectSchema(t)
+	return ref
+}
+
+// objectSchema lists the JSON properties of a struct, flattening embedded structs the way
+// encoding/json does
+func (s *Schemas) objectSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for key, value := range s.objectSchema(fieldType).Properties {
+				if _, exists := schema.Properties[key]; !exists {
+					schema.Properties[key] = value
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = s.SchemaOf(field.Type)
+	}
+	return schema
+}
+
+// jsonFieldName returns the JSON name of a struct field: empty when it keeps its Go name (or
+// is an untagged embedded struct), and false when encoding/json skips it
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if !field.IsExported() && !(field.Anonymous && name == "") {
+		return "", false
+	}
+	return name, true
+}
+
+// implements reports whether t or *t implements iface
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
@@ -0,0 +1,293 @@
+// Package osv queries the OSV vulnerability database (https://osv.dev) for advisories that
+// affect software packages identified by package URL.
+package osv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the public OSV API
+const DefaultBaseURL = "https://api.osv.dev"
+
+// maxBatchQueries is the most queries the querybatch endpoint accepts in one request
+const maxBatchQueries = 1000
+
+// maxErrorBody bounds how much of a failed response is quoted in errors
+const maxErrorBody = 512
+
+// Vulnerability is an OSV advisory
+type Vulnerability struct {
+	ID       string   `json:"id"`
+	Summary  string   `json:"summary"`
+	Details  string   `json:"details"`
+	Aliases  []string `json:"aliases"`
+	Modified string   `json:"modified"`
+	Severity []struct {
+		Type  string `json:"type"`  // e.g. CVSS_V3
+		Score string `json:"score"` // CVSS vector
+	} `json:"severity"`
+	Affected []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+			PURL      string `json:"purl"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string `json:"type"`
+			Events []struct {
+				Introduced string `json:"introduced,omitempty"`
+				Fixed      string `json:"fixed,omitempty"`
+			} `json:"events"`
+		} `json:"ranges"`
+	} `json:"affected"`
+	References []struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"references"`
+	DatabaseSpecific struct {
+		Severity string   `json:"severity"` // GitHub advisory severity: LOW, MODERATE, HIGH, CRITICAL
+		CWEIDs   []string `json:"cwe_ids"`  // Weakness types, as GitHub advisories record them from NVD
+	} `json:"database_specific"`
+}
+
+// CVE returns the advisory's CVE identifier, if it is or aliases one
+func (v *Vulnerability) CVE() string {
+	if strings.HasPrefix(v.ID, "CVE-") {
+		return v.ID
+	}
+	for _, alias := range v.Aliases {
+		if strings.HasPrefix(alias, "CVE-") {
+			return alias
+		}
+	}
+	return ""
+}
+
+// CVSSVector returns the advisory's CVSS v3 vector, if any
+func (v *Vulnerability) CVSSVector() string {
+	for _, s := range v.Severity {
+		if s.Type == "CVSS_V3" {
+			return s.Score
+		}
+	}
+	return ""
+}
+
+// FixedVersions returns the versions that fix the advisory
+func (v *Vulnerability) FixedVersions() []string {
+	var fixed []string
+	for _, affected := range v.Affected {
+		for _, r := range affected.Ranges {
+			for _, event := range r.Events {
+				if event.Fixed != "" {
+					fixed = append(fixed, event.Fixed)
+				}
+			}
+		}
+	}
+	return fixed
+}
+
+// Client queries the OSV API. Advisory details are cached for the life of the client.
+type Client struct {
+	baseURL string
+	http    *http.Client
+
+	mu    sync.Mutex
+	vulns map[string]*Vulnerability
+}
+
+// NewClient creates a client of the OSV API at baseURL (DefaultBaseURL when empty)
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: 30 * time.Second},
+		vulns:   map[string]*Vulnerability{},
+	}
+}
+
+// QueryPURLs returns the advisories affecting each package URL, which must include a version.
+// The result is keyed by package URL; packages without advisories are absent.
+func (c *Client) QueryPURLs(ctx context.Context, purls []string) (map[string][]*Vulnerability, error) {
+	result := map[string][]*Vulnerability{}
+	for start := 0; start < len(purls); start += maxBatchQueries {
+		end := min(start+maxBatchQueries, len(purls))
+		batch := purls[start:end]
+
+		type query struct {
+			Package struct {
+				PURL string `json:"purl"`
+			} `json:"package"`
+		}
+		queries := make([]query, len(batch))
+		for i, purl := range batch {
+			queries[i].Package.PURL = purl
+		}
+
+		var resp struct {
+			Results []struct {
+				Vulns []struct {
+					ID string `json:"id"`
+				} `json:"vulns"`
+			} `json:"results"`
+		}
+		if err := c.do(ctx, http.MethodPost, "/v1/querybatch", map[string]interface{}{"queries": queries}, &resp); err != nil {
+			return nil, err
+		}
+
+		for i, r := range resp.Results {
+			if i >= len(batch) {
+				break
+			}
+			for _, ref := range r.Vulns {
+				vuln, err := c.Vulnerability(ctx, ref.ID)
+				if err != nil {
+					return nil, err
+				}
+				result[batch[i]] = append(result[batch[i]], vuln)
+			}
+		}
+	}
+	return result, nil
+}
+
+// Vulnerability returns an advisory by ID
+func (c *Client) Vulnerability(ctx context.Context, id string) (*Vulnerability, error) {
+	c.mu.Lock()
+	cached := c.vulns[id]
+	c.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	var vuln Vulnerability
+	if err := c.do(ctx, http.MethodGet, "/v1/vulns/"+url.PathEscape(id), nil, &vuln); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.vulns[id] = &vuln
+	c.mu.Unlock()
+	return &vuln, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("osv request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("osv request failed: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid osv response: %w", err)
+	}
+	return nil
+}
+
+// CVSSv3BaseScore computes the base score of a CVSS v3.0 or v3.1 vector
+func CVSSv3BaseScore(vector string) (float64, bool) {
+	parts := strings.Split(vector, "/")
+	if len(parts) < 9 || !strings.HasPrefix(parts[0], "CVSS:3") {
+		return 0, false
+	}
+	metrics := map[string]string{}
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return 0, false
+		}
+		metrics[key] = value
+	}
+
+	weights := map[string]map[string]float64{
+		"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+		"AC": {"L": 0.77, "H": 0.44},
+		"UI": {"N": 0.85, "R": 0.62},
+		"C":  {"H": 0.56, "L": 0.22, "N": 0},
+		"I":  {"H": 0.56, "L": 0.22, "N": 0},
+		"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	}
+	value := func(metric string) (float64, bool) {
+		w, ok := weights[metric][metrics[metric]]
+		return w, ok
+	}
+
+	scopeChanged := metrics["S"] == "C"
+	if metrics["S"] != "C" && metrics["S"] != "U" {
+		return 0, false
+	}
+	privileges := map[string]float64{"N": 0.85, "L": 0.62, "H": 0.27}
+	if scopeChanged {
+		privileges = map[string]float64{"N": 0.85, "L": 0.68, "H": 0.5}
+	}
+	pr, ok := privileges[metrics["PR"]]
+	if !ok {
+		return 0, false
+	}
+
+	var w [6]float64
+	for i, metric := range []string{"AV", "AC", "UI", "C", "I", "A"} {
+		if w[i], ok = value(metric); !ok {
+			return 0, false
+		}
+	}
+	av, ac, ui, conf, integ, avail := w[0], w[1], w[2], w[3], w[4], w[5]
+
+	iss := 1 - (1-conf)*(1-integ)*(1-avail)
+	var impact float64
+	if scopeChanged {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+	exploitability := 8.22 * av * ac * pr * ui
+	if scopeChanged {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// roundUp rounds up to one decimal as specified by CVSS v3.1
+func roundUp(value float64) float64 {
+	scaled := int(math.Round(value * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return (math.Floor(float64(scaled)/10000) + 1) / 10
+}
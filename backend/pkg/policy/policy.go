@@ -0,0 +1,115 @@
+// Package policy enforces attribute-based constraints on modifying actions.
+//
+// Authenticated requests carry the caller's role constraints in the request context. Services
+// call Authorize with the attributes of the assets an action touches before changing data, so
+// the constraints hold no matter which route or integration reaches the service. Contexts
+// without a subject (startup, background jobs, imports run by the system) are not constrained.
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+)
+
+// ErrDenied is returned (wrapped) when a constraint forbids an action
+var ErrDenied = errors.New("access denied by role policy")
+
+// Subject is the authenticated principal whose constraints apply to a request
+type Subject struct {
+	UserID      uuid.UUID
+	Constraints []models.AccessConstraint
+}
+
+// AssetAttributes are the asset properties constraints are evaluated against
+type AssetAttributes struct {
+	Environment models.Environment       `gorm:"column:environment"`
+	Criticality *models.AssetCriticality `gorm:"column:criticality"`
+}
+
+type subjectKey struct{}
+
+// WithSubject returns a context carrying the subject's constraints
+func WithSubject(ctx context.Context, subject *Subject) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext returns the subject of a context
+func SubjectFromContext(ctx context.Context) (*Subject, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	subject, ok := ctx.Value(subjectKey{}).(*Subject)
+	return subject, ok && subject != nil
+}
+
+// Constrained reports whether resource:action is subject to constraints in ctx.
+// Services use it to skip loading asset attributes for unconstrained callers.
+func Constrained(ctx context.Context, resource, action string) bool {
+	return len(applicable(ctx, resource, action)) > 0
+}
+
+// Authorize checks resource:action against the constraints in ctx. Every asset must satisfy
+// every applicable constraint; a constrained action on a vulnerability without assets is denied.
+func Authorize(ctx context.Context, resource, action string, assets []AssetAttributes) error {
+	constraints := applicable(ctx, resource, action)
+	if len(constraints) == 0 {
+		return nil
+	}
+	if len(assets) == 0 {
+		return fmt.Errorf("%w: %s:%s is restricted to specific assets", ErrDenied, resource, action)
+	}
+
+	for _, constraint := range constraints {
+		for _, asset := range assets {
+			if !constraint.Permits(asset.Environment, asset.Criticality) {
+				return fmt.Errorf("%w: %s:%s is restricted to %s", ErrDenied, resource, action, describe(constraint))
+			}
+		}
+	}
+	return nil
+}
+
+// Attributes extracts the constrained attributes of assets
+func Attributes(assets ...models.AffectedSystem) []AssetAttributes {
+	attrs := make([]AssetAttributes, 0, len(assets))
+	for _, asset := range assets {
+		attrs = append(attrs, AssetAttributes{Environment: asset.Environment, Criticality: asset.Criticality})
+	}
+	return attrs
+}
+
+// applicable returns the constraints in ctx that cover resource:action
+func applicable(ctx context.Context, resource, action string) []models.AccessConstraint {
+	subject, ok := SubjectFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	var constraints []models.AccessConstraint
+	for _, constraint := range subject.Constraints {
+		if constraint.AppliesTo(resource, action) {
+			constraints = append(constraints, constraint)
+		}
+	}
+	return constraints
+}
+
+// describe renders a constraint for error messages
+func describe(constraint models.AccessConstraint) string {
+	var parts []string
+	if len(constraint.Environments) > 0 {
+		envs := make([]string, len(constraint.Environments))
+		for i, env := range constraint.Environments {
+			envs[i] = string(env)
+		}
+		parts = append(parts, "environment "+strings.Join(envs, " or "))
+	}
+	if constraint.MaxCriticality != nil {
+		parts = append(parts, "criticality up to "+string(*constraint.MaxCriticality))
+	}
+	return "assets with " + strings.Join(parts, " and ")
+}
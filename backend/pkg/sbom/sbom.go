@@ -0,0 +1,283 @@
+// Package sbom parses software bills of materials in the CycloneDX and SPDX JSON formats into a
+// flat list of components.
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Document formats
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+)
+
+// Component is a software component listed in an SBOM
+type Component struct {
+	BOMRef   string // Identifier within the document (CycloneDX bom-ref or SPDX SPDXID)
+	Type     string // e.g. library, application, operating-system
+	Group    string // Namespace, e.g. a Maven group ID
+	Name     string
+	Version  string
+	PURL     string // Package URL
+	CPE      string
+	Licenses []string
+	Supplier string
+}
+
+// Document is a parsed SBOM
+type Document struct {
+	Format      string
+	SpecVersion string
+	Name        string // Subject of the SBOM, when the document names one
+	Components  []Component
+}
+
+// Parse detects the format of an SBOM document and parses its components. Components without a
+// name, and repeated components, are skipped.
+func Parse(data []byte) (*Document, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("invalid SBOM: document is empty")
+	}
+	if data[0] == '<' {
+		return nil, fmt.Errorf("invalid SBOM: only JSON documents are supported")
+	}
+
+	var probe struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("invalid SBOM: %v", err)
+	}
+
+	var doc *Document
+	var err error
+	switch {
+	case strings.EqualFold(probe.BOMFormat, "CycloneDX"):
+		doc, err = parseCycloneDX(data)
+	case probe.SPDXVersion != "":
+		doc, err = parseSPDX(data)
+	default:
+		return nil, fmt.Errorf("invalid SBOM: not a CycloneDX or SPDX document")
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc.Components = dedupe(doc.Components)
+	return doc, nil
+}
+
+type cycloneDXComponent struct {
+	BOMRef   string `json:"bom-ref"`
+	Type     string `json:"type"`
+	Group    string `json:"group"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	PURL     string `json:"purl"`
+	CPE      string `json:"cpe"`
+	Supplier *struct {
+		Name string `json:"name"`
+	} `json:"supplier"`
+	Licenses []struct {
+		License *struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"license"`
+		Expression string `json:"expression"`
+	} `json:"licenses"`
+	Components []cycloneDXComponent `json:"components"` // Nested (e.g. bundled) components
+}
+
+func parseCycloneDX(data []byte) (*Document, error) {
+	var bom struct {
+		SpecVersion string `json:"specVersion"`
+		Metadata    struct {
+			Component *cycloneDXComponent `json:"component"`
+		} `json:"metadata"`
+		Components []cycloneDXComponent `json:"components"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, fmt.Errorf("invalid CycloneDX document: %v", err)
+	}
+
+	doc := &Document{Format: FormatCycloneDX, SpecVersion: bom.SpecVersion}
+	if bom.Metadata.Component != nil {
+		doc.Name = bom.Metadata.Component.Name
+	}
+
+	var walk func(components []cycloneDXComponent)
+	walk = func(components []cycloneDXComponent) {
+		for _, c := range components {
+			component := Component{
+				BOMRef:  c.BOMRef,
+				Type:    c.Type,
+				Group:   c.Group,
+				Name:    c.Name,
+				Version: c.Version,
+				PURL:    c.PURL,
+				CPE:     c.CPE,
+			}
+			if c.Supplier != nil {
+				component.Supplier = c.Supplier.Name
+			}
+			for _, l := range c.Licenses {
+				switch {
+				case l.License != nil && l.License.ID != "":
+					component.Licenses = append(component.Licenses, l.License.ID)
+				case l.License != nil && l.License.Name != "":
+					component.Licenses = append(component.Licenses, l.License.Name)
+				case l.Expression != "":
+					component.Licenses = append(component.Licenses, l.Expression)
+				}
+			}
+			doc.Components = append(doc.Components, component)
+			walk(c.Components)
+		}
+	}
+	walk(bom.Components)
+	return doc, nil
+}
+
+func parseSPDX(data []byte) (*Document, error) {
+	var spdx struct {
+		SPDXVersion string `json:"spdxVersion"`
+		SPDXID      string `json:"SPDXID"`
+		Name        string `json:"name"`
+		Packages    []struct {
+			SPDXID           string `json:"SPDXID"`
+			Name             string `json:"name"`
+			VersionInfo      string `json:"versionInfo"`
+			Supplier         string `json:"supplier"`
+			LicenseConcluded string `json:"licenseConcluded"`
+			LicenseDeclared  string `json:"licenseDeclared"`
+			PrimaryPurpose   string `json:"primaryPackagePurpose"`
+			ExternalRefs     []struct {
+				Category string `json:"referenceCategory"`
+				Type     string `json:"referenceType"`
+				Locator  string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+		Relationships []struct {
+			Element string `json:"spdxElementId"`
+			Type    string `json:"relationshipType"`
+			Related string `json:"relatedSpdxElement"`
+		} `json:"relationships"`
+	}
+	if err := json.Unmarshal(data, &spdx); err != nil {
+		return nil, fmt.Errorf("invalid SPDX document: %v", err)
+	}
+
+	// The packages the document describes are the subject of the SBOM, not its components
+	described := map[string]bool{}
+	for _, r := range spdx.Relationships {
+		if r.Element == spdx.SPDXID && r.Type == "DESCRIBES" {
+			described[r.Related] = true
+		}
+	}
+
+	doc := &Document{Format: FormatSPDX, SpecVersion: strings.TrimPrefix(spdx.SPDXVersion, "SPDX-"), Name: spdx.Name}
+	for _, p := range spdx.Packages {
+		if described[p.SPDXID] && len(spdx.Packages) > 1 {
+			continue
+		}
+		component := Component{
+			BOMRef:   p.SPDXID,
+			Type:     strings.ToLower(p.PrimaryPurpose),
+			Name:     p.Name,
+			Version:  spdxValue(p.VersionInfo),
+			Supplier: strings.TrimPrefix(strings.TrimPrefix(spdxValue(p.Supplier), "Organization: "), "Person: "),
+		}
+		if license := spdxValue(p.LicenseConcluded); license != "" {
+			component.Licenses = []string{license}
+		} else if license := spdxValue(p.LicenseDeclared); license != "" {
+			component.Licenses = []string{license}
+		}
+		for _, ref := range p.ExternalRefs {
+			switch strings.ToLower(ref.Type) {
+			case "purl":
+				component.PURL = ref.Locator
+			case "cpe23type", "cpe22type":
+				if component.CPE == "" {
+					component.CPE = ref.Locator
+				}
+			}
+		}
+		doc.Components = append(doc.Components, component)
+	}
+	return doc, nil
+}
+
+// spdxValue returns a field value, empty for NOASSERTION and NONE
+func spdxValue(value string) string {
+	if value == "NOASSERTION" || value == "NONE" {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+// dedupe drops unnamed and repeated components, keeping the first occurrence
+func dedupe(components []Component) []Component {
+	seen := map[string]bool{}
+	result := make([]Component, 0, len(components))
+	for _, c := range components {
+		c.Name = strings.TrimSpace(c.Name)
+		c.Version = strings.TrimSpace(c.Version)
+		c.PURL = strings.TrimSpace(c.PURL)
+		if c.Name == "" {
+			continue
+		}
+		key := c.PURL
+		if key == "" {
+			key = c.Group + "/" + c.Name + "@" + c.Version
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, c)
+	}
+	return result
+}
+
+// PURLEcosystem returns the package type of a package URL (e.g. npm, maven, pypi), or "" when
+// the URL is not a valid purl
+func PURLEcosystem(purl string) string {
+	rest, ok := strings.CutPrefix(purl, "pkg:")
+	if !ok {
+		return ""
+	}
+	ecosystem, _, ok := strings.Cut(rest, "/")
+	if !ok {
+		return ""
+	}
+	if decoded, err := url.PathUnescape(ecosystem); err == nil {
+		ecosystem = decoded
+	}
+	return strings.ToLower(ecosystem)
+}
+
+// VersionedPURL returns the package URL of a component with its version, which vulnerability
+// lookups require. Package URLs that already carry a version are returned unchanged; "" is
+// returned when the component has no package URL or no version.
+func VersionedPURL(c Component) string {
+	if c.PURL == "" {
+		return ""
+	}
+	base, suffix := c.PURL, ""
+	if i := strings.IndexAny(base, "?#"); i >= 0 {
+		base, suffix = base[:i], base[i:]
+	}
+	if strings.Contains(base[strings.LastIndex(base, "/")+1:], "@") {
+		return c.PURL
+	}
+	if c.Version == "" {
+		return ""
+	}
+	return base + "@" + url.PathEscape(c.Version) + suffix
+}
@@ -0,0 +1,95 @@
+package sbom
+
+// CycloneDX VEX analysis states
+const (
+	VEXStateResolved      = "resolved"
+	VEXStateExploitable   = "exploitable" // Affected
+	VEXStateInTriage      = "in_triage"
+	VEXStateFalsePositive = "false_positive"
+	VEXStateNotAffected   = "not_affected"
+)
+
+// CycloneDX VEX responses
+const (
+	VEXResponseWillNotFix = "will_not_fix"
+	VEXResponseUpdate     = "update"
+)
+
+// VEXJustificationMitigatingControl is the CycloneDX justification for vulnerabilities a
+// compensating control prevents from being exploited
+const VEXJustificationMitigatingControl = "protected_by_mitigating_control"
+
+// VEXSpecVersion is the CycloneDX version VEX documents are written in
+const VEXSpecVersion = "1.5"
+
+// VEXDocument is a CycloneDX BOM carrying vulnerability exploitability statements
+type VEXDocument struct {
+	BOMFormat       string             `json:"bomFormat"`
+	SpecVersion     string             `json:"specVersion"`
+	SerialNumber    string             `json:"serialNumber"`
+	Version         int                `json:"version"`
+	Metadata        VEXMetadata        `json:"metadata"`
+	Components      []VEXComponent     `json:"components,omitempty"`
+	Vulnerabilities []VEXVulnerability `json:"vulnerabilities"`
+}
+
+// VEXMetadata describes when, by what and about what a VEX document was written
+type VEXMetadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []VEXComponent `json:"components"`
+	} `json:"tools"`
+	Component *VEXComponent `json:"component,omitempty"`
+}
+
+// VEXComponent is a component VEX statements refer to
+type VEXComponent struct {
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Type    string `json:"type"`
+	Group   string `json:"group,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+	CPE     string `json:"cpe,omitempty"`
+}
+
+// VEXVulnerability is the exploitability statement for one vulnerability
+type VEXVulnerability struct {
+	BOMRef         string      `json:"bom-ref,omitempty"`
+	ID             string      `json:"id"`
+	Source         *VEXSource  `json:"source,omitempty"`
+	Ratings        []VEXRating `json:"ratings,omitempty"`
+	Description    string      `json:"description,omitempty"`
+	Recommendation string      `json:"recommendation,omitempty"`
+	Analysis       VEXAnalysis `json:"analysis"`
+	Affects        []VEXAffect `json:"affects"`
+}
+
+// VEXSource is the database a vulnerability ID comes from
+type VEXSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// VEXRating is a severity rating of a vulnerability
+type VEXRating struct {
+	Score    *float64 `json:"score,omitempty"`
+	Severity string   `json:"severity,omitempty"`
+	Method   string   `json:"method,omitempty"`
+	Vector   string   `json:"vector,omitempty"`
+}
+
+// VEXAnalysis records the exploitability decision for a vulnerability
+type VEXAnalysis struct {
+	State         string   `json:"state"`
+	Justification string   `json:"justification,omitempty"`
+	Response      []string `json:"response,omitempty"`
+	Detail        string   `json:"detail,omitempty"`
+	FirstIssued   string   `json:"firstIssued,omitempty"`
+	LastUpdated   string   `json:"lastUpdated,omitempty"`
+}
+
+// VEXAffect references a component a vulnerability statement applies to
+type VEXAffect struct {
+	Ref string `json:"ref"`
+}
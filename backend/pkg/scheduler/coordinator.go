@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxErrorLength bounds the error recorded for a failed run
+const maxErrorLength = 2000
+
+// PostgresCoordinator coordinates cluster jobs through Postgres. A session-level advisory lock
+// keeps a run from overlapping another instance's run of the same job, and is released by
+// Postgres if the instance dies. The scheduled_jobs registry then claims the run only when the
+// job has not started within its interval, so each period's run happens once.
+type PostgresCoordinator struct {
+	db       *gorm.DB
+	instance string
+}
+
+// NewPostgresCoordinator creates a coordinator on the application database
+func NewPostgresCoordinator(db *gorm.DB, instance string) *PostgresCoordinator {
+	return &PostgresCoordinator{db: db, instance: instance}
+}
+
+// advisoryLockKey maps a job name to the 64-bit key of its advisory lock
+func advisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("cyops:scheduler:" + name))
+	return int64(h.Sum64())
+}
+
+// Acquire claims the run of a job at now unless a run started after cutoff
+func (p *PostgresCoordinator) Acquire(ctx context.Context, job *Job, now, cutoff time.Time) (func(error), bool, error) {
+	sqlDB, err := p.db.DB()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	// Advisory locks belong to a connection, so the lock is taken and released on a dedicated one
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get database connection: %w", err)
+	}
+	key := advisoryLockKey(job.Name)
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to lock job %s: %w", job.Name, err)
+	}
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+	unlock := func() {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+		conn.Close()
+	}
+
+	claimed, err := p.claim(ctx, job, now, cutoff)
+	if err != nil || !claimed {
+		unlock()
+		return nil, false, err
+	}
+
+	release := func(runErr error) {
+		defer unlock()
+		p.finish(job, now, runErr)
+	}
+	return release, true, nil
+}
+
+// claim registers the job and marks its run as started unless it already started this period
+func (p *PostgresCoordinator) claim(ctx context.Context, job *Job, now, cutoff time.Time) (bool, error) {
+	db := p.db.WithContext(ctx)
+	entry := models.ScheduledJob{
+		Name:            job.Name,
+		Description:     job.Description,
+		IntervalSeconds: int64(job.Interval / time.Second),
+	}
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description", "interval_seconds"}),
+	}).Create(&entry).Error; err != nil {
+		return false, fmt.Errorf("failed to register job %s: %w", job.Name, err)
+	}
+
+	result := db.Model(&models.ScheduledJob{}).
+		Where("name = ?", job.Name).
+		Where("last_started_at IS NULL OR last_started_at <= ?", cutoff).
+		Updates(map[string]interface{}{
+			"last_started_at": now,
+			"last_status":     models.ScheduledJobRunning,
+			"last_instance":   p.instance,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim job %s: %w", job.Name, result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// finish records the outcome of a run
+func (p *PostgresCoordinator) finish(job *Job, started time.Time, runErr error) {
+	finished := time.Now()
+	updates := map[string]interface{}{
+		"last_finished_at": finished,
+		"last_duration_ms": finished.Sub(started).Milliseconds(),
+		"last_status":      models.ScheduledJobSucceeded,
+		"last_error":       "",
+		"run_count":        gorm.Expr("run_count + 1"),
+	}
+	if runErr != nil {
+		message := runErr.Error()
+		if len(message) > maxErrorLength {
+			message = message[:maxErrorLength]
+		}
+		updates["last_status"] = models.ScheduledJobFailed
+		updates["last_error"] = message
+	}
+	// The job's context may be cancelled by shutdown; the outcome is still recorded
+	p.db.WithContext(context.Background()).Model(&models.ScheduledJob{}).Where("name = ?", job.Name).Updates(updates)
+}
+
+// LocalCoordinator coordinates cluster jobs between schedulers of the same process. It suits
+// single-instance deployments and tests.
+type LocalCoordinator struct {
+	mu          sync.Mutex
+	running     map[string]bool
+	lastStarted map[string]time.Time
+}
+
+// NewLocalCoordinator creates an in-process coordinator
+func NewLocalCoordinator() *LocalCoordinator {
+	return &LocalCoordinator{
+		running:     make(map[string]bool),
+		lastStarted: make(map[string]time.Time),
+	}
+}
+
+// Acquire claims the run of a job at now unless a run started after cutoff
+func (l *LocalCoordinator) Acquire(ctx context.Context, job *Job, now, cutoff time.Time) (func(error), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.running[job.Name] {
+		return nil, false, nil
+	}
+	if last, ok := l.lastStarted[job.Name]; ok && last.After(cutoff) {
+		return nil, false, nil
+	}
+	l.running[job.Name] = true
+	l.lastStarted[job.Name] = now
+
+	release := func(error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.running, job.Name)
+	}
+	return release, true, nil
+}
+
+// ListJobs returns the registry of cluster jobs with the outcome of their last run
+func ListJobs(db *gorm.DB) ([]models.ScheduledJob, error) {
+	jobs := []models.ScheduledJob{}
+	if err := db.Order("name").Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list scheduled jobs: %w", err)
+	}
+	return jobs, nil
+}
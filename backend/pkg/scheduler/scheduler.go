@@ -0,0 +1,239 @@
+// Package scheduler runs background jobs on a schedule. Cluster jobs run on one instance per
+// period however many replicas are running: a Coordinator hands each due run to a single
+// instance. Instance jobs run on every replica, for work such as reloading local state.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/utils"
+)
+
+// Scopes
+const (
+	ScopeCluster  = "cluster"  // One instance runs each period's run
+	ScopeInstance = "instance" // Every instance runs the job
+)
+
+// Job is a registered background job
+type Job struct {
+	Name        string
+	Description string
+	Scope       string        // ScopeCluster (default) or ScopeInstance
+	Interval    time.Duration // Time between runs
+	// Next returns the delay until the next run, for jobs on a calendar schedule such as
+	// nightly; nil runs the job every Interval
+	Next func(now time.Time) time.Duration
+	// Period returns the time a cluster job must wait after its previous run, for jobs whose
+	// period depends on settings; the job is still checked every Interval. Zero skips the run.
+	Period     func() (time.Duration, error)
+	RunOnStart bool // Run as soon as the scheduler starts
+	RunOnStop  bool // Run once more when the scheduler stops (instance jobs only)
+	Run        func(ctx context.Context) error
+}
+
+// delay returns the time until the job's next run
+func (j *Job) delay(now time.Time) time.Duration {
+	if j.Next != nil {
+		return j.Next(now)
+	}
+	return j.Interval
+}
+
+// Coordinator hands each due run of a cluster job to a single instance
+type Coordinator interface {
+	// Acquire claims the run of a job at now unless a run started after cutoff. When ok, the
+	// caller runs the job and then calls release with its outcome.
+	Acquire(ctx context.Context, job *Job, now, cutoff time.Time) (release func(runErr error), ok bool, err error)
+}
+
+// Scheduler runs registered jobs
+type Scheduler struct {
+	coordinator Coordinator
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	started bool
+	wg      sync.WaitGroup
+}
+
+// New creates a scheduler whose cluster jobs are coordinated by coordinator
+func New(coordinator Coordinator) *Scheduler {
+	return &Scheduler{
+		coordinator: coordinator,
+		jobs:        make(map[string]*Job),
+	}
+}
+
+// InstanceName identifies this process among the replicas
+func InstanceName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("invalid job: name and run function are required")
+	}
+	if job.Interval <= 0 {
+		return fmt.Errorf("invalid job %s: interval must be positive", job.Name)
+	}
+	if job.Scope == "" {
+		job.Scope = ScopeCluster
+	}
+	if job.Scope != ScopeCluster && job.Scope != ScopeInstance {
+		return fmt.Errorf("invalid job %s: unknown scope %s", job.Name, job.Scope)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return fmt.Errorf("invalid job %s: the scheduler has already started", job.Name)
+	}
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("invalid job %s: already registered", job.Name)
+	}
+	s.jobs[job.Name] = &job
+	return nil
+}
+
+// Jobs returns the registered jobs sorted by name
+func (s *Scheduler) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs
+}
+
+// Start runs every registered job on its schedule until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.started = true
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	s.mu.Unlock()
+
+	for _, job := range jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Wait blocks until every job loop has returned after its context was cancelled, including
+// runs in progress, or until ctx is done. It reports whether every loop returned.
+func (s *Scheduler) Wait(ctx context.Context) bool {
+	stopped := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// loop runs a job on its schedule
+func (s *Scheduler) loop(ctx context.Context, job *Job) {
+	defer s.wg.Done()
+
+	utils.Logger.Info().Str("job", job.Name).Str("scope", job.Scope).Msg("Starting scheduled job")
+	if job.RunOnStart {
+		s.RunOnce(ctx, job)
+	}
+
+	timer := time.NewTimer(job.delay(time.Now()))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if job.RunOnStop && job.Scope == ScopeInstance {
+				s.RunOnce(context.Background(), job)
+			}
+			utils.Logger.Info().Str("job", job.Name).Msg("Stopping scheduled job")
+			return
+		case <-timer.C:
+			s.RunOnce(ctx, job)
+			timer.Reset(job.delay(time.Now()))
+		}
+	}
+}
+
+// RunOnce runs a job now. A cluster job only runs when the coordinator hands this instance the
+// run, so it is skipped when another instance is running it or already ran it this period.
+func (s *Scheduler) RunOnce(ctx context.Context, job *Job) {
+	if job.Scope == ScopeInstance {
+		if err := run(ctx, job); err != nil {
+			utils.Logger.Error().Err(err).Str("job", job.Name).Msg("Scheduled job failed")
+		}
+		return
+	}
+
+	period := job.Interval
+	if job.Period != nil {
+		var err error
+		if period, err = job.Period(); err != nil {
+			utils.Logger.Error().Err(err).Str("job", job.Name).Msg("Failed to get scheduled job period")
+			return
+		}
+		if period <= 0 {
+			return
+		}
+	}
+
+	now := time.Now()
+	release, ok, err := s.coordinator.Acquire(ctx, job, now, dueCutoff(period, now))
+	if err != nil {
+		utils.Logger.Error().Err(err).Str("job", job.Name).Msg("Failed to claim scheduled job run")
+		return
+	}
+	if !ok {
+		utils.Logger.Debug().Str("job", job.Name).Msg("Scheduled job run claimed by another instance")
+		return
+	}
+
+	err = run(ctx, job)
+	release(err)
+	if err != nil {
+		utils.Logger.Error().Err(err).Str("job", job.Name).Msg("Scheduled job failed")
+	}
+}
+
+// run calls a job's run function, turning a panic into an error so one job cannot stop the others
+func run(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// dueCutoff returns the latest start time of a previous run that leaves a job with the given
+// period due at now. A tenth of the period (at most a minute) of slack absorbs timer jitter
+// between instances.
+func dueCutoff(period time.Duration, now time.Time) time.Time {
+	slack := period / 10
+	if slack > time.Minute {
+		slack = time.Minute
+	}
+	return now.Add(-period + slack)
+}
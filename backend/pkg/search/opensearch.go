@@ -0,0 +1,228 @@
+// Package search provides a minimal OpenSearch (and Elasticsearch-compatible) REST client
+// used by the optional search backend.
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client is a small OpenSearch REST client covering index management, bulk writes, and ID searches
+type Client struct {
+	baseURL     string
+	username    string
+	password    string
+	indexPrefix string
+	httpClient  *http.Client
+}
+
+// NewClient creates a new OpenSearch client
+func NewClient(baseURL, username, password, indexPrefix string) *Client {
+	return &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		username:    username,
+		password:    password,
+		indexPrefix: indexPrefix,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// IndexName returns the prefixed index name for an entity type
+func (c *Client) IndexName(entity string) string {
+	if c.indexPrefix == "" {
+		return entity
+	}
+	return c.indexPrefix + "-" + entity
+}
+
+// BulkOperation is a single index or delete action in a bulk request
+type BulkOperation struct {
+	Index    string
+	ID       string
+	Delete   bool
+	Document interface{}
+}
+
+// BulkItemError describes a failed item in a bulk response
+type BulkItemError struct {
+	ID     string `json:"id"`
+	Status int    `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// SearchResult contains the IDs of matching documents in ranked order and the total hit count
+type SearchResult struct {
+	IDs   []string
+	Total int64
+}
+
+// do sends a request and decodes a JSON response into out (when non-nil)
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader, contentType string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("opensearch request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read opensearch response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("opensearch %s %s returned status %d: %s", method, path, resp.StatusCode, truncate(string(data), 500))
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse opensearch response: %w", err)
+		}
+	}
+	return nil
+}
+
+// Ping checks that the cluster is reachable
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/", nil, "", nil)
+}
+
+// EnsureIndex creates an index with the given mappings, or adds new fields to an existing index
+func (c *Client) EnsureIndex(ctx context.Context, index string, mappings map[string]interface{}) error {
+	err := c.do(ctx, http.MethodHead, "/"+index, nil, "", nil)
+	if err == nil {
+		// Existing index: add any fields introduced since it was created
+		body, err := json.Marshal(mappings)
+		if err != nil {
+			return fmt.Errorf("failed to encode index mappings: %w", err)
+		}
+		return c.do(ctx, http.MethodPut, "/"+index+"/_mapping", bytes.NewReader(body), "application/json", nil)
+	}
+	if !strings.Contains(err.Error(), "status 404") {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"mappings": mappings})
+	if err != nil {
+		return fmt.Errorf("failed to encode index mappings: %w", err)
+	}
+	return c.do(ctx, http.MethodPut, "/"+index, bytes.NewReader(body), "application/json", nil)
+}
+
+// Bulk executes index and delete operations, returning per-item failures
+func (c *Client) Bulk(ctx context.Context, ops []BulkOperation) ([]BulkItemError, error) {
+	if len(ops) == 0 {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, op := range ops {
+		action := "index"
+		if op.Delete {
+			action = "delete"
+		}
+		if err := encoder.Encode(map[string]interface{}{
+			action: map[string]string{"_index": op.Index, "_id": op.ID},
+		}); err != nil {
+			return nil, fmt.Errorf("failed to encode bulk action: %w", err)
+		}
+		if !op.Delete {
+			if err := encoder.Encode(op.Document); err != nil {
+				return nil, fmt.Errorf("failed to encode document %s: %w", op.ID, err)
+			}
+		}
+	}
+
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID     string `json:"_id"`
+			Status int    `json:"status"`
+			Error  *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/_bulk", &buf, "application/x-ndjson", &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Errors {
+		return nil, nil
+	}
+
+	failures := []BulkItemError{}
+	for _, item := range resp.Items {
+		for action, result := range item {
+			// Deleting a document that was never indexed is not a failure
+			if result.Error == nil || (action == "delete" && result.Status == http.StatusNotFound) {
+				continue
+			}
+			failures = append(failures, BulkItemError{
+				ID:     result.ID,
+				Status: result.Status,
+				Reason: result.Error.Type + ": " + result.Error.Reason,
+			})
+		}
+	}
+	return failures, nil
+}
+
+// SearchIDs runs a search request body and returns the matching document IDs
+func (c *Client) SearchIDs(ctx context.Context, index string, query map[string]interface{}) (*SearchResult, error) {
+	query["_source"] = false
+	query["track_total_hits"] = true
+
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode search query: %w", err)
+	}
+
+	var resp struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/"+index+"/_search", bytes.NewReader(body), "application/json", &resp); err != nil {
+		return nil, err
+	}
+
+	result := &SearchResult{
+		IDs:   make([]string, 0, len(resp.Hits.Hits)),
+		Total: resp.Hits.Total.Value,
+	}
+	for _, hit := range resp.Hits.Hits {
+		result.IDs = append(result.IDs, hit.ID)
+	}
+	return result, nil
+}
+
+// truncate shortens long error bodies for logging
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
@@ -0,0 +1,111 @@
+// Package shutdown drains long-running work when the server stops. Imports, report generations
+// and backups register while they run; once draining starts no new work is accepted, and
+// running work stops at its next checkpoint so the server can exit without killing it
+// mid-transaction.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrDraining is returned when work is refused because the server is shutting down
+var ErrDraining = errors.New("server is shutting down")
+
+// Coordinator tracks the work in flight
+type Coordinator struct {
+	mu       sync.Mutex
+	draining bool
+	running  map[uint64]string // Kind of each piece of running work
+	nextID   uint64
+	idle     chan struct{} // Closed when nothing is running while draining
+}
+
+// NewCoordinator creates a coordinator that accepts work
+func NewCoordinator() *Coordinator {
+	return &Coordinator{running: make(map[uint64]string)}
+}
+
+// Begin registers a piece of work of the given kind, such as "import". It fails with
+// ErrDraining once draining started; otherwise the caller calls done when the work ends.
+func (c *Coordinator) Begin(kind string) (done func(), err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.draining {
+		return nil, ErrDraining
+	}
+	c.nextID++
+	id := c.nextID
+	c.running[id] = kind
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			delete(c.running, id)
+			if c.draining && len(c.running) == 0 && c.idle != nil {
+				close(c.idle)
+				c.idle = nil
+			}
+		})
+	}, nil
+}
+
+// Draining reports whether running work should stop at its next checkpoint
+func (c *Coordinator) Draining() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.draining
+}
+
+// Drain stops accepting work and waits until the running work ended or ctx is done. It
+// returns the kinds of the work still running, sorted.
+func (c *Coordinator) Drain(ctx context.Context) []string {
+	c.mu.Lock()
+	c.draining = true
+	if len(c.running) == 0 {
+		c.mu.Unlock()
+		return nil
+	}
+	if c.idle == nil {
+		c.idle = make(chan struct{})
+	}
+	idle := c.idle
+	c.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kinds := make([]string, 0, len(c.running))
+	for _, kind := range c.running {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// defaultCoordinator tracks the work of this process
+var defaultCoordinator = NewCoordinator()
+
+// Begin registers work with the process coordinator
+func Begin(kind string) (done func(), err error) {
+	return defaultCoordinator.Begin(kind)
+}
+
+// Draining reports whether the process is shutting down
+func Draining() bool {
+	return defaultCoordinator.Draining()
+}
+
+// Drain drains the process coordinator
+func Drain(ctx context.Context) []string {
+	return defaultCoordinator.Drain(ctx)
+}
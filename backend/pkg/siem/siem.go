@@ -0,0 +1,469 @@
+// Package siem forwards security events to a SIEM as CEF or RFC 5424 syslog messages over
+// TCP or TLS. Events are buffered in memory and written by a background sender that reconnects
+// with backoff, so producers never block on the network.
+package siem
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message formats
+const (
+	FormatCEF     = "cef"     // ArcSight Common Event Format carried in a syslog message
+	FormatRFC5424 = "rfc5424" // Syslog with the event fields as structured data
+)
+
+// Transports
+const (
+	ProtocolTCP = "tcp"
+	ProtocolTLS = "tls"
+)
+
+// Message framing on the stream (RFC 6587)
+const (
+	FramingNewline       = "newline"        // Non-transparent framing, one message per line
+	FramingOctetCounting = "octet_counting" // Each message prefixed with its length
+)
+
+// Defaults applied when a Config leaves them empty
+const (
+	DefaultBufferSize   = 10000
+	DefaultAppName      = "cyops"
+	DefaultDialTimeout  = 10 * time.Second
+	DefaultWriteTimeout = 10 * time.Second
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = time.Minute
+)
+
+// CEF header fields
+const (
+	cefVendor  = "CYOPS"
+	cefProduct = "CYOPS"
+	cefVersion = "1.0"
+)
+
+// syslogFacility is local4, commonly routed to security tooling
+const syslogFacility = 20
+
+// sdID is the structured data ID of RFC 5424 messages (private enterprise number 32473 is reserved for documentation)
+const sdID = "cyops@32473"
+
+// Event is a security event to forward
+type Event struct {
+	Time     time.Time
+	Type     string // e.g. auth.login_failed or vulnerability.status_changed
+	Name     string // Human-readable description
+	Severity int    // 0 (lowest) to 10 (highest), as in CEF
+	Fields   map[string]string
+}
+
+// Config configures a forwarder
+type Config struct {
+	Address      string // host:port
+	Protocol     string // tcp or tls
+	Format       string // cef or rfc5424
+	Framing      string // newline or octet_counting
+	TLSConfig    *tls.Config
+	BufferSize   int
+	Hostname     string // HOSTNAME of the syslog header
+	AppName      string // APP-NAME of the syslog header
+	DialTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+// Stats reports the forwarder's delivery counters
+type Stats struct {
+	Connected bool   `json:"connected"`
+	Queued    int    `json:"queued"`
+	Sent      uint64 `json:"sent"`
+	Dropped   uint64 `json:"dropped"` // Discarded because the buffer was full
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Forwarder buffers events and streams them to a SIEM
+type Forwarder struct {
+	cfg    Config
+	queue  chan Event
+	done   chan struct{}
+	closed chan struct{}
+	once   sync.Once
+
+	mu        sync.Mutex
+	conn      net.Conn
+	sent      uint64
+	dropped   uint64
+	lastError string
+}
+
+// New validates cfg and starts a forwarder; Close stops it
+func New(cfg Config) (*Forwarder, error) {
+	if _, _, err := net.SplitHostPort(cfg.Address); err != nil {
+		return nil, fmt.Errorf("invalid address: %s", cfg.Address)
+	}
+	switch cfg.Protocol {
+	case ProtocolTCP, ProtocolTLS:
+	default:
+		return nil, fmt.Errorf("invalid protocol: %s", cfg.Protocol)
+	}
+	switch cfg.Format {
+	case FormatCEF, FormatRFC5424:
+	default:
+		return nil, fmt.Errorf("invalid format: %s", cfg.Format)
+	}
+	switch cfg.Framing {
+	case "":
+		cfg.Framing = FramingNewline
+	case FramingNewline, FramingOctetCounting:
+	default:
+		return nil, fmt.Errorf("invalid framing: %s", cfg.Framing)
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultBufferSize
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = DefaultAppName
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = DefaultDialTimeout
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = DefaultWriteTimeout
+	}
+
+	f := &Forwarder{
+		cfg:    cfg,
+		queue:  make(chan Event, cfg.BufferSize),
+		done:   make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+	go f.run()
+	return f, nil
+}
+
+// Send queues an event without blocking. It returns false, and counts the event as dropped,
+// when the buffer is full because the SIEM has been unreachable for too long.
+func (f *Forwarder) Send(event Event) bool {
+	select {
+	case <-f.done:
+		return false
+	default:
+	}
+	select {
+	case f.queue <- event:
+		return true
+	default:
+		f.mu.Lock()
+		f.dropped++
+		f.mu.Unlock()
+		return false
+	}
+}
+
+// Stats returns the current delivery counters
+func (f *Forwarder) Stats() Stats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return Stats{
+		Connected: f.conn != nil,
+		Queued:    len(f.queue),
+		Sent:      f.sent,
+		Dropped:   f.dropped,
+		LastError: f.lastError,
+	}
+}
+
+// Close stops the forwarder, giving it up to timeout to deliver the events still buffered
+func (f *Forwarder) Close(timeout time.Duration) {
+	f.once.Do(func() { close(f.done) })
+	select {
+	case <-f.closed:
+	case <-time.After(timeout):
+		// Unblock a pending dial or write
+		f.mu.Lock()
+		if f.conn != nil {
+			f.conn.Close()
+		}
+		f.mu.Unlock()
+		<-f.closed
+	}
+}
+
+// run delivers queued events, reconnecting with exponential backoff. An event whose write
+// fails is retried on the next connection.
+func (f *Forwarder) run() {
+	defer close(f.closed)
+	defer f.disconnect(nil)
+
+	backoff := minReconnectBackoff
+	var pending *Event
+	for {
+		if pending == nil {
+			select {
+			case event := <-f.queue:
+				pending = &event
+			case <-f.done:
+				// Flush what is buffered, but do not wait for an unreachable SIEM
+				select {
+				case event := <-f.queue:
+					pending = &event
+				default:
+					return
+				}
+			}
+		}
+
+		if err := f.write(*pending); err != nil {
+			f.disconnect(err)
+			select {
+			case <-time.After(backoff):
+			case <-f.done:
+				if f.isDisconnected() {
+					return
+				}
+			}
+			if backoff *= 2; backoff > maxReconnectBackoff {
+				backoff = maxReconnectBackoff
+			}
+			continue
+		}
+		backoff = minReconnectBackoff
+		pending = nil
+	}
+}
+
+// write sends one event, connecting first if needed
+func (f *Forwarder) write(event Event) error {
+	f.mu.Lock()
+	conn := f.conn
+	f.mu.Unlock()
+
+	if conn == nil {
+		var err error
+		if conn, err = f.dial(); err != nil {
+			return err
+		}
+		f.mu.Lock()
+		f.conn = conn
+		f.mu.Unlock()
+		go f.watch(conn)
+	}
+
+	frame := Frame(f.Format(event), f.cfg.Framing)
+	conn.SetWriteDeadline(time.Now().Add(f.cfg.WriteTimeout))
+	if _, err := conn.Write([]byte(frame)); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.sent++
+	f.lastError = ""
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *Forwarder) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: f.cfg.DialTimeout, KeepAlive: 30 * time.Second}
+	if f.cfg.Protocol == ProtocolTLS {
+		return tls.DialWithDialer(dialer, "tcp", f.cfg.Address, f.cfg.TLSConfig)
+	}
+	return dialer.Dial("tcp", f.cfg.Address)
+}
+
+// watch drops a connection as soon as the SIEM closes it. Collectors never send data, so a read
+// only returns when the connection ends; without this the next event would be written into a
+// socket the peer already closed, and lost.
+func (f *Forwarder) watch(conn net.Conn) {
+	var buf [1]byte
+	for {
+		if _, err := conn.Read(buf[:]); err != nil {
+			break
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn == conn {
+		f.conn.Close()
+		f.conn = nil
+		f.lastError = "connection closed by peer"
+	}
+}
+
+// disconnect drops the connection, recording why
+func (f *Forwarder) disconnect(cause error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn != nil {
+		f.conn.Close()
+		f.conn = nil
+	}
+	if cause != nil {
+		f.lastError = cause.Error()
+	}
+}
+
+func (f *Forwarder) isDisconnected() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.conn == nil
+}
+
+// Format renders an event in the forwarder's message format
+func (f *Forwarder) Format(event Event) string {
+	if f.cfg.Format == FormatCEF {
+		return FormatCEFMessage(event, f.cfg.Hostname, f.cfg.AppName)
+	}
+	return FormatRFC5424Message(event, f.cfg.Hostname, f.cfg.AppName)
+}
+
+// Frame frames a message for a stream transport
+func Frame(message, framing string) string {
+	if framing == FramingOctetCounting {
+		return strconv.Itoa(len(message)) + " " + message
+	}
+	return message + "\n"
+}
+
+// FormatRFC5424Message renders an event as an RFC 5424 syslog message with its fields as structured data
+func FormatRFC5424Message(event Event, hostname, appName string) string {
+	var sd strings.Builder
+	sd.WriteString("[" + sdID)
+	sd.WriteString(` type="` + escapeSDValue(event.Type) + `"`)
+	for _, key := range sortedKeys(event.Fields) {
+		sd.WriteString(" " + sdName(key) + `="` + escapeSDValue(event.Fields[key]) + `"`)
+	}
+	sd.WriteString("]")
+	return syslogHeader(event, hostname, appName, msgID(event.Type)) + " " + sd.String() + " " + event.Name
+}
+
+// FormatCEFMessage renders an event as a CEF record in an RFC 5424 syslog message
+func FormatCEFMessage(event Event, hostname, appName string) string {
+	var ext []string
+	ext = append(ext, "rt="+strconv.FormatInt(eventTime(event).UnixMilli(), 10))
+	for _, key := range sortedKeys(event.Fields) {
+		ext = append(ext, cefKey(key)+"="+escapeCEFExtension(event.Fields[key]))
+	}
+	cef := strings.Join([]string{
+		"CEF:0",
+		escapeCEFHeader(cefVendor),
+		escapeCEFHeader(cefProduct),
+		escapeCEFHeader(cefVersion),
+		escapeCEFHeader(event.Type),
+		escapeCEFHeader(event.Name),
+		strconv.Itoa(clampSeverity(event.Severity)),
+		strings.Join(ext, " "),
+	}, "|")
+	return syslogHeader(event, hostname, appName, msgID(event.Type)) + " - " + cef
+}
+
+// syslogHeader renders the RFC 5424 header up to MSGID
+func syslogHeader(event Event, hostname, appName, msgID string) string {
+	pri := syslogFacility*8 + syslogSeverity(event.Severity)
+	return fmt.Sprintf("<%d>1 %s %s %s - %s", pri,
+		eventTime(event).UTC().Format("2006-01-02T15:04:05.000Z"),
+		headerField(hostname), headerField(appName), headerField(msgID))
+}
+
+// syslogSeverity maps a 0-10 CEF severity to a syslog severity
+func syslogSeverity(severity int) int {
+	switch severity = clampSeverity(severity); {
+	case severity >= 9:
+		return 2 // Critical
+	case severity >= 7:
+		return 3 // Error
+	case severity >= 4:
+		return 4 // Warning
+	case severity >= 1:
+		return 5 // Notice
+	}
+	return 6 // Informational
+}
+
+func clampSeverity(severity int) int {
+	if severity < 0 {
+		return 0
+	}
+	if severity > 10 {
+		return 10
+	}
+	return severity
+}
+
+func eventTime(event Event) time.Time {
+	if event.Time.IsZero() {
+		return time.Now()
+	}
+	return event.Time
+}
+
+// msgID derives the syslog MSGID from an event type (at most 32 printable characters)
+func msgID(eventType string) string {
+	if len(eventType) > 32 {
+		eventType = eventType[:32]
+	}
+	return eventType
+}
+
+// headerField replaces characters not allowed in syslog header fields; empty fields are "-"
+func headerField(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, value)
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// sdName makes a structured data parameter name (no '=', ' ', ']', '"')
+func sdName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '=' || r == ']' || r == '"' || r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+var (
+	sdValueEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+	cefHeaderEscaper    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+)
+
+func escapeSDValue(value string) string { return sdValueEscaper.Replace(value) }
+
+func escapeCEFHeader(value string) string { return cefHeaderEscaper.Replace(value) }
+
+func escapeCEFExtension(value string) string { return cefExtensionEscaper.Replace(value) }
+
+// cefKey makes an extension key (alphanumeric only)
+func cefKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return -1
+	}, key)
+}
+
+func sortedKeys(fields map[string]string) []string {
+	keys := make([]string, 0, len(fields))
+	for key, value := range fields {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
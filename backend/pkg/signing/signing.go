@@ -0,0 +1,187 @@
+// Package signing signs report artifacts with Ed25519 and verifies their detached signatures, so
+// recipients can confirm a report came unmodified from the instance that produced it
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Algorithm names the signature scheme in detached signatures
+const Algorithm = "Ed25519"
+
+// statementPrefix versions the signed statement; it binds the signature to this format
+const statementPrefix = "cyops-report-signature-v1"
+
+// Verification failures
+var (
+	ErrUnknownKey       = errors.New("signature was made with a key this instance does not hold")
+	ErrDigestMismatch   = errors.New("file does not match the signed digest")
+	ErrInvalidSignature = errors.New("signature is not valid")
+	ErrMalformed        = errors.New("malformed signature")
+)
+
+// Signature is a detached signature of a file. The signature covers the file's SHA-256 digest and
+// the signing time.
+type Signature struct {
+	Algorithm string    `json:"algorithm"`
+	KeyID     string    `json:"key_id"`
+	PublicKey string    `json:"public_key"` // Base64 encoded, for verification without this instance
+	SHA256    string    `json:"sha256"`
+	Size      int64     `json:"size"`
+	SignedAt  time.Time `json:"signed_at"`
+	Signature string    `json:"signature"` // Base64 encoded
+}
+
+// Key is an Ed25519 signing key
+type Key struct {
+	private ed25519.PrivateKey
+	keyID   string
+}
+
+// NewKey creates a key from a 32-byte Ed25519 seed
+func NewKey(seed []byte) (*Key, error) {
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key seed must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+	private := ed25519.NewKeyFromSeed(seed)
+	return &Key{private: private, keyID: keyID(private.Public().(ed25519.PublicKey))}, nil
+}
+
+// ParseKey creates a key from a base64 encoded 32-byte seed, as generated by
+// `openssl rand -base64 32`, or a PEM encoded PKCS #8 Ed25519 private key, as generated by
+// `openssl genpkey -algorithm ed25519`
+func ParseKey(encoded string) (*Key, error) {
+	encoded = strings.TrimSpace(encoded)
+	if strings.HasPrefix(encoded, "-----BEGIN") {
+		block, _ := pem.Decode([]byte(encoded))
+		if block == nil {
+			return nil, fmt.Errorf("signing key is not valid PEM")
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("signing key is not a PKCS #8 private key: %w", err)
+		}
+		private, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("signing key is not an Ed25519 key")
+		}
+		return NewKey(private.Seed())
+	}
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("signing key is not valid base64: %w", err)
+	}
+	return NewKey(seed)
+}
+
+// DeriveKey creates a key derived from another secret, for installations that have not
+// configured a dedicated signing key
+func DeriveKey(secret string) *Key {
+	seed := sha256.Sum256([]byte("cyops-report-signing:" + secret))
+	key, _ := NewKey(seed[:])
+	return key
+}
+
+// KeyID returns a fingerprint of the public key
+func (k *Key) KeyID() string {
+	return k.keyID
+}
+
+// PublicKey returns the public key
+func (k *Key) PublicKey() ed25519.PublicKey {
+	return k.private.Public().(ed25519.PublicKey)
+}
+
+// keyID fingerprints a public key
+func keyID(public ed25519.PublicKey) string {
+	sum := sha256.Sum256(public)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Digest returns the hex encoded SHA-256 digest of data
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// statement is the message signed for a file
+func statement(digest string, size int64, signedAt time.Time) []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%d\n%s\n", statementPrefix, digest, size, signedAt.UTC().Format(time.RFC3339)))
+}
+
+// Signer signs with its active key and verifies signatures of its active and previous keys
+type Signer struct {
+	active   *Key
+	previous map[string]*Key
+}
+
+// NewSigner creates a signer. Previous keys only verify, so reports signed before a key rotation
+// stay verifiable.
+func NewSigner(active *Key, previous ...*Key) *Signer {
+	s := &Signer{active: active, previous: make(map[string]*Key, len(previous))}
+	for _, key := range previous {
+		s.previous[key.KeyID()] = key
+	}
+	return s
+}
+
+// Active returns the key new signatures are made with
+func (s *Signer) Active() *Key {
+	return s.active
+}
+
+// Sign returns a detached signature of data
+func (s *Signer) Sign(data []byte) *Signature {
+	digest := Digest(data)
+	signedAt := time.Now().UTC().Truncate(time.Second)
+	return &Signature{
+		Algorithm: Algorithm,
+		KeyID:     s.active.KeyID(),
+		PublicKey: base64.StdEncoding.EncodeToString(s.active.PublicKey()),
+		SHA256:    digest,
+		Size:      int64(len(data)),
+		SignedAt:  signedAt,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(s.active.private, statement(digest, int64(len(data)), signedAt))),
+	}
+}
+
+// Verify checks a detached signature of data. The signature must have been made by one of the
+// signer's keys; the public key it carries is not trusted.
+func (s *Signer) Verify(data []byte, signature *Signature) error {
+	if signature == nil || signature.Algorithm != Algorithm {
+		return ErrMalformed
+	}
+	key := s.previous[signature.KeyID]
+	if signature.KeyID == s.active.KeyID() {
+		key = s.active
+	}
+	if key == nil {
+		return ErrUnknownKey
+	}
+	if claimed, err := base64.StdEncoding.DecodeString(signature.PublicKey); err == nil && len(claimed) > 0 && !bytes.Equal(claimed, key.PublicKey()) {
+		return ErrUnknownKey
+	}
+	raw, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil || len(raw) != ed25519.SignatureSize {
+		return ErrMalformed
+	}
+
+	digest := Digest(data)
+	if !strings.EqualFold(digest, signature.SHA256) || int64(len(data)) != signature.Size {
+		return ErrDigestMismatch
+	}
+	if !ed25519.Verify(key.PublicKey(), statement(digest, signature.Size, signature.SignedAt), raw) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
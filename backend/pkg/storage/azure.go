@@ -0,0 +1,279 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AzureConfig configures an Azure Blob Storage store
+type AzureConfig struct {
+	Account    string
+	AccountKey string // Base64 storage account key
+	Container  string
+	Prefix     string // Optional blob name prefix inside the container
+	Endpoint   string // Defaults to https://<account>.blob.core.windows.net (override for Azurite)
+
+	// Now returns the signing time; defaults to time.Now (overridden in tests)
+	Now func() time.Time
+}
+
+// AzureStore stores objects as block blobs, authorizing requests with the account's shared key
+type AzureStore struct {
+	cfg        AzureConfig
+	key        []byte
+	endpoint   *url.URL
+	httpClient *http.Client
+}
+
+// azureAPIVersion is the Blob service REST version used for requests and SAS tokens
+const azureAPIVersion = "2020-12-06"
+
+// NewAzureStore creates an Azure Blob store
+func NewAzureStore(cfg AzureConfig) (*AzureStore, error) {
+	if cfg.Account == "" || cfg.Container == "" {
+		return nil, fmt.Errorf("azure account and container are required")
+	}
+	key, err := base64.StdEncoding.DecodeString(cfg.AccountKey)
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("invalid azure account key")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", cfg.Account)
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid azure endpoint: %s", cfg.Endpoint)
+	}
+
+	return &AzureStore{
+		cfg:        cfg,
+		key:        key,
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Driver returns "azure"
+func (s *AzureStore) Driver() string {
+	return DriverAzure
+}
+
+// blobPath returns the escaped URL path of a blob and its unescaped container-relative name
+func (s *AzureStore) blobPath(key string) (escapedPath, blobName string, err error) {
+	key, err = CleanKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	blobName = prefixed(s.cfg.Prefix, key)
+
+	// Path-style endpoints (Azurite) carry the account in the path
+	base := strings.TrimRight(s.endpoint.Path, "/")
+	return base + "/" + escapePath(s.cfg.Container+"/"+blobName), blobName, nil
+}
+
+// Put uploads a block blob
+func (s *AzureStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	headers := http.Header{}
+	headers.Set("X-Ms-Blob-Type", "BlockBlob")
+	if contentType != "" {
+		headers.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, data, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return azureError("put", resp)
+	}
+	return nil
+}
+
+// Get downloads a blob
+func (s *AzureStore) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read azure blob: %w", err)
+		}
+		return data, nil
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	}
+	return nil, azureError("get", resp)
+}
+
+// Delete removes a blob; deleting a missing blob is not an error
+func (s *AzureStore) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNotFound {
+		return azureError("delete", resp)
+	}
+	return nil
+}
+
+// PresignGet returns a URL carrying a read-only service SAS for the blob, valid for ttl
+func (s *AzureStore) PresignGet(ctx context.Context, key, filename string, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		return "", fmt.Errorf("invalid presign ttl: must be positive")
+	}
+	escapedPath, blobName, err := s.blobPath(key)
+	if err != nil {
+		return "", err
+	}
+
+	expiry := s.cfg.Now().UTC().Add(ttl).Format(time.RFC3339)
+	disposition := ""
+	if filename != "" {
+		disposition = contentDisposition(filename)
+	}
+	protocol := "https"
+	if s.endpoint.Scheme == "http" {
+		protocol = "https,http"
+	}
+
+	// Service SAS string-to-sign for versions 2020-12-06 and later
+	stringToSign := strings.Join([]string{
+		"r",    // signedPermissions
+		"",     // signedStart
+		expiry, // signedExpiry
+		"/blob/" + s.cfg.Account + "/" + s.cfg.Container + "/" + blobName,
+		"",       // signedIdentifier
+		"",       // signedIP
+		protocol, // signedProtocol
+		azureAPIVersion,
+		"b",         // signedResource
+		"",          // signedSnapshotTime
+		"",          // signedEncryptionScope
+		"",          // rscc
+		disposition, // rscd
+		"",          // rsce
+		"",          // rscl
+		"",          // rsct
+	}, "\n")
+
+	query := url.Values{}
+	query.Set("sv", azureAPIVersion)
+	query.Set("sr", "b")
+	query.Set("sp", "r")
+	query.Set("se", expiry)
+	query.Set("spr", protocol)
+	if disposition != "" {
+		query.Set("rscd", disposition)
+	}
+	query.Set("sig", s.sign(stringToSign))
+
+	return fmt.Sprintf("%s://%s%s?%s", s.endpoint.Scheme, s.endpoint.Host, escapedPath, query.Encode()), nil
+}
+
+// do sends a Shared Key authorized request for a blob
+func (s *AzureStore) do(ctx context.Context, method, key string, body []byte, headers http.Header) (*http.Response, error) {
+	escapedPath, _, err := s.blobPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint.Scheme+"://"+s.endpoint.Host+escapedPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create azure request: %w", err)
+	}
+	req.URL.RawPath = escapedPath
+	req.ContentLength = int64(len(body))
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("X-Ms-Date", s.cfg.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Ms-Version", azureAPIVersion)
+
+	req.Header.Set("Authorization", fmt.Sprintf("SharedKey %s:%s", s.cfg.Account, s.sign(s.sharedKeyStringToSign(req))))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("azure request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// sharedKeyStringToSign builds the Shared Key string-to-sign for a Blob service request
+func (s *AzureStore) sharedKeyStringToSign(req *http.Request) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var msHeaders []string
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower+":"+strings.TrimSpace(req.Header.Get(name)))
+		}
+	}
+	sort.Strings(msHeaders)
+
+	canonicalizedResource := "/" + s.cfg.Account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		canonicalizedResource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+
+	return strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date (x-ms-date is used instead)
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		strings.Join(msHeaders, "\n"),
+		canonicalizedResource,
+	}, "\n")
+}
+
+// sign computes the base64 HMAC-SHA256 signature of a string with the account key
+func (s *AzureStore) sign(stringToSign string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// azureError builds an error from a failed Blob service response
+func azureError(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("azure %s failed with status %d: %s", op, resp.StatusCode, strings.TrimSpace(string(body)))
+}
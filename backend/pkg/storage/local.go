@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LocalConfig configures the local filesystem store
+type LocalConfig struct {
+	Root string // Directory holding all objects
+}
+
+// LocalStore keeps objects as files under a root directory
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore creates a filesystem store rooted at root
+func NewLocalStore(root string) *LocalStore {
+	if root == "" {
+		root = "./uploads"
+	}
+	return &LocalStore{root: root}
+}
+
+// Driver returns "local"
+func (s *LocalStore) Driver() string {
+	return DriverLocal
+}
+
+// path resolves a key to a file path under the root
+func (s *LocalStore) path(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes an object, creating parent directories as needed
+func (s *LocalStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(fullPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// Get reads an object
+func (s *LocalStore) Get(ctx context.Context, key string) ([]byte, error) {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(fullPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return data, nil
+}
+
+// Delete removes an object; deleting a missing object is not an error
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(fullPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// PresignGet is not supported; local files are only served through the API
+func (s *LocalStore) PresignGet(ctx context.Context, key, filename string, ttl time.Duration) (string, error) {
+	return "", ErrPresignUnsupported
+}
@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/awsauth"
+)
+
+// S3Config configures an S3-compatible store (AWS S3 or MinIO)
+type S3Config struct {
+	Endpoint        string // e.g. https://s3.amazonaws.com or http://minio:9000
+	Region          string
+	Bucket          string
+	Prefix          string // Optional key prefix inside the bucket
+	PathStyle       bool   // Address the bucket in the path (required by MinIO)
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// Now returns the signing time; defaults to time.Now (overridden in tests)
+	Now func() time.Time
+}
+
+// S3Store stores objects in an S3 bucket, signing requests with AWS Signature Version 4
+type S3Store struct {
+	cfg        S3Config
+	endpoint   *url.URL
+	signer     awsauth.Signer
+	httpClient *http.Client
+}
+
+const (
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3MaxPresignTTL   = 7 * 24 * time.Hour
+)
+
+// NewS3Store creates an S3 store
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 credentials are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3.amazonaws.com"
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid s3 endpoint: %s", cfg.Endpoint)
+	}
+
+	return &S3Store{
+		cfg:      cfg,
+		endpoint: endpoint,
+		signer: awsauth.Signer{
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			Region:          cfg.Region,
+			Service:         "s3",
+		},
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Driver returns "s3"
+func (s *S3Store) Driver() string {
+	return DriverS3
+}
+
+// objectURL returns the host and escaped path addressing an object
+func (s *S3Store) objectURL(key string) (host, escapedPath string, err error) {
+	key, err = CleanKey(key)
+	if err != nil {
+		return "", "", err
+	}
+	key = prefixed(s.cfg.Prefix, key)
+
+	if s.cfg.PathStyle {
+		return s.endpoint.Host, "/" + escapePath(s.cfg.Bucket+"/"+key), nil
+	}
+	return s.cfg.Bucket + "." + s.endpoint.Host, "/" + escapePath(key), nil
+}
+
+// Put uploads an object
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	headers := http.Header{}
+	if contentType != "" {
+		headers.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, data, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("put", resp)
+	}
+	return nil
+}
+
+// Get downloads an object
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read s3 object: %w", err)
+		}
+		return data, nil
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	}
+	return nil, s3Error("get", resp)
+}
+
+// Delete removes an object; S3 treats deleting a missing object as success
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s3Error("delete", resp)
+	}
+	return nil
+}
+
+// PresignGet returns a query-signed GET URL valid for ttl (at most seven days)
+func (s *S3Store) PresignGet(ctx context.Context, key, filename string, ttl time.Duration) (string, error) {
+	if ttl <= 0 || ttl > s3MaxPresignTTL {
+		return "", fmt.Errorf("invalid presign ttl: must be between 1s and %s", s3MaxPresignTTL)
+	}
+	host, escapedPath, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+
+	now := s.cfg.Now().UTC()
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", awsauth.Algorithm)
+	query.Set("X-Amz-Credential", s.signer.Credential(now))
+	query.Set("X-Amz-Date", now.Format(awsauth.TimeFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if filename != "" {
+		query.Set("response-content-disposition", contentDisposition(filename))
+	}
+
+	canonicalQuery := s3CanonicalQuery(query)
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		escapedPath,
+		canonicalQuery,
+		"host:" + host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+	signature := s.signer.Sign(now, canonicalRequest)
+
+	return fmt.Sprintf("%s://%s%s?%s&X-Amz-Signature=%s", s.endpoint.Scheme, host, escapedPath, canonicalQuery, signature), nil
+}
+
+// do sends a header-signed request for an object
+func (s *S3Store) do(ctx context.Context, method, key string, body []byte, headers http.Header) (*http.Response, error) {
+	host, escapedPath, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.endpoint.Scheme+"://"+host+escapedPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 request: %w", err)
+	}
+	req.URL.RawPath = escapedPath
+	req.ContentLength = int64(len(body))
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+
+	now := s.cfg.Now().UTC()
+	payloadHash := awsauth.SHA256Hex(body)
+	req.Header.Set("X-Amz-Date", now.Format(awsauth.TimeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Sign host, content type and every x-amz-* header
+	signed := map[string]string{"host": host}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			signed[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	canonicalHeaders, signedHeaders := awsauth.CanonicalHeaders(signed)
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		escapedPath,
+		"",
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	req.Header.Set("Authorization", s.signer.Authorization(now, signedHeaders, canonicalRequest))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// s3CanonicalQuery encodes query parameters sorted by name, as Signature Version 4 requires
+func s3CanonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, uriEscape(name)+"="+uriEscape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escapePath URI-encodes each segment of an object path, keeping the slashes
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = uriEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// uriEscape URI-encodes everything except the RFC 3986 unreserved characters
+func uriEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Error builds an error from a failed S3 response
+func s3Error(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 %s failed with status %d: %s", op, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,90 @@
+// Package storage provides the pluggable blob stores that hold uploaded files (attachments and
+// assessment reports): the local filesystem, S3-compatible object stores (AWS S3, MinIO) and
+// Azure Blob Storage.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Storage drivers
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+	DriverAzure = "azure"
+)
+
+var (
+	// ErrNotFound is returned when an object does not exist
+	ErrNotFound = errors.New("object not found")
+	// ErrPresignUnsupported is returned by stores that cannot issue presigned download URLs
+	ErrPresignUnsupported = errors.New("presigned URLs are not supported by this storage backend")
+)
+
+// Store reads and writes objects by key. Keys are slash-separated relative paths such as
+// "finding-attachments/<finding-id>/<file>".
+type Store interface {
+	// Driver returns the driver name recorded alongside each stored object
+	Driver() string
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	// PresignGet returns a time-limited URL that downloads the object without API credentials.
+	// A non-empty filename is sent back as an attachment Content-Disposition.
+	PresignGet(ctx context.Context, key, filename string, ttl time.Duration) (string, error)
+}
+
+// Config selects and configures a store
+type Config struct {
+	Driver string
+	Local  LocalConfig
+	S3     S3Config
+	Azure  AzureConfig
+}
+
+// New creates the store selected by cfg.Driver
+func New(cfg Config) (Store, error) {
+	switch cfg.Driver {
+	case "", DriverLocal:
+		return NewLocalStore(cfg.Local.Root), nil
+	case DriverS3:
+		return NewS3Store(cfg.S3)
+	case DriverAzure:
+		return NewAzureStore(cfg.Azure)
+	}
+	return nil, fmt.Errorf("unknown storage driver: %s", cfg.Driver)
+}
+
+// CleanKey validates an object key and returns it in canonical form, rejecting absolute
+// paths and parent directory references
+func CleanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", fmt.Errorf("invalid object key: %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." {
+			return "", fmt.Errorf("invalid object key: %q", key)
+		}
+	}
+	return path.Clean(key), nil
+}
+
+// contentDisposition builds an attachment Content-Disposition header for a download filename
+func contentDisposition(filename string) string {
+	return fmt.Sprintf("attachment; filename=\"%s\"", strings.NewReplacer("\"", "", "\r", "", "\n", "").Replace(filename))
+}
+
+// prefixed joins an optional key prefix onto a key
+func prefixed(prefix, key string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return key
+	}
+	return prefix + "/" + key
+}
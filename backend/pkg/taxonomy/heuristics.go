@@ -0,0 +1,140 @@
+package taxonomy
+
+import (
+	"sort"
+	"strings"
+)
+
+// Mapping is the OWASP categories and ATT&CK techniques of a vulnerability
+type Mapping struct {
+	OWASP  []string `json:"owasp"`
+	ATTACK []string `json:"attack"`
+}
+
+// Empty reports whether the mapping has no categories and no techniques
+func (m Mapping) Empty() bool {
+	return len(m.OWASP) == 0 && len(m.ATTACK) == 0
+}
+
+// cweOWASP maps weaknesses to their OWASP Top 10 (2021) category, following the CWE lists
+// OWASP publishes per category
+var cweOWASP = map[string]string{
+	"CWE-22": "A01:2021", "CWE-200": "A01:2021", "CWE-276": "A01:2021", "CWE-284": "A01:2021",
+	"CWE-352": "A01:2021", "CWE-538": "A01:2021", "CWE-548": "A01:2021", "CWE-601": "A01:2021",
+	"CWE-639": "A01:2021", "CWE-668": "A01:2021", "CWE-732": "A01:2021", "CWE-862": "A01:2021",
+	"CWE-863": "A01:2021",
+
+	"CWE-319": "A02:2021", "CWE-326": "A02:2021", "CWE-327": "A02:2021", "CWE-328": "A02:2021",
+	"CWE-330": "A02:2021", "CWE-347": "A02:2021", "CWE-757": "A02:2021", "CWE-916": "A02:2021",
+
+	"CWE-20": "A03:2021", "CWE-77": "A03:2021", "CWE-78": "A03:2021", "CWE-79": "A03:2021",
+	"CWE-89": "A03:2021", "CWE-90": "A03:2021", "CWE-91": "A03:2021", "CWE-93": "A03:2021",
+	"CWE-94": "A03:2021", "CWE-113": "A03:2021", "CWE-917": "A03:2021",
+
+	"CWE-209": "A04:2021", "CWE-256": "A04:2021", "CWE-269": "A04:2021", "CWE-311": "A04:2021",
+	"CWE-312": "A04:2021", "CWE-434": "A04:2021", "CWE-444": "A04:2021", "CWE-522": "A04:2021",
+	"CWE-1021": "A04:2021",
+
+	"CWE-611": "A05:2021", "CWE-614": "A05:2021", "CWE-1004": "A05:2021",
+
+	"CWE-1104": "A06:2021",
+
+	"CWE-259": "A07:2021", "CWE-287": "A07:2021", "CWE-295": "A07:2021", "CWE-306": "A07:2021",
+	"CWE-307": "A07:2021", "CWE-384": "A07:2021", "CWE-521": "A07:2021", "CWE-613": "A07:2021",
+	"CWE-640": "A07:2021", "CWE-798": "A07:2021", "CWE-1392": "A07:2021",
+
+	"CWE-345": "A08:2021", "CWE-426": "A08:2021", "CWE-502": "A08:2021", "CWE-829": "A08:2021",
+	"CWE-1321": "A08:2021",
+
+	"CWE-532": "A09:2021",
+
+	"CWE-918": "A10:2021",
+}
+
+// cweTechniques maps weaknesses to the ATT&CK techniques adversaries exploit them with
+var cweTechniques = map[string][]string{
+	// Injection and unsafe handling of requests to exposed services
+	"CWE-22": {"T1190"}, "CWE-77": {"T1190", "T1059"}, "CWE-78": {"T1190", "T1059"},
+	"CWE-89": {"T1190"}, "CWE-90": {"T1190"}, "CWE-91": {"T1190"}, "CWE-94": {"T1190", "T1059"},
+	"CWE-287": {"T1190"}, "CWE-306": {"T1190"}, "CWE-434": {"T1190", "T1505.003"},
+	"CWE-502": {"T1190"}, "CWE-611": {"T1190"}, "CWE-917": {"T1190"}, "CWE-918": {"T1190"},
+	"CWE-79": {"T1189"},
+	// Privilege escalation
+	"CWE-269": {"T1068"}, "CWE-276": {"T1068"}, "CWE-732": {"T1068"},
+	"CWE-426": {"T1574"}, "CWE-427": {"T1574"},
+	// Credentials
+	"CWE-259": {"T1078"}, "CWE-798": {"T1078", "T1552"}, "CWE-1392": {"T1078.001"},
+	"CWE-256": {"T1552"}, "CWE-312": {"T1552"}, "CWE-522": {"T1552"}, "CWE-532": {"T1552"},
+	"CWE-307": {"T1110"}, "CWE-521": {"T1110"},
+	// Traffic interception and session theft
+	"CWE-295": {"T1557"}, "CWE-311": {"T1557"}, "CWE-319": {"T1557", "T1040"},
+	"CWE-326": {"T1557"}, "CWE-327": {"T1557"}, "CWE-757": {"T1557"},
+	"CWE-384": {"T1539"}, "CWE-613": {"T1539"}, "CWE-614": {"T1539"}, "CWE-1004": {"T1539"},
+	// Resource exhaustion
+	"CWE-400": {"T1499"}, "CWE-674": {"T1499"}, "CWE-770": {"T1499"}, "CWE-835": {"T1499"},
+	"CWE-1333": {"T1499"},
+	// Untrusted components and data
+	"CWE-345": {"T1195"}, "CWE-347": {"T1195"}, "CWE-829": {"T1195"}, "CWE-1104": {"T1195"},
+}
+
+// pluginFamilies maps scanner plugin families (lower case, as Nessus and Tenable.io name them)
+// to the categories and techniques of the vulnerabilities they detect
+var pluginFamilies = map[string]Mapping{
+	"cgi abuses":                    {ATTACK: []string{"T1190"}},
+	"cgi abuses : xss":              {OWASP: []string{"A03:2021"}, ATTACK: []string{"T1189"}},
+	"web servers":                   {ATTACK: []string{"T1190"}},
+	"databases":                     {ATTACK: []string{"T1190"}},
+	"default unix accounts":         {OWASP: []string{"A07:2021"}, ATTACK: []string{"T1078.001"}},
+	"brute force attacks":           {OWASP: []string{"A07:2021"}, ATTACK: []string{"T1110"}},
+	"denial of service":             {ATTACK: []string{"T1499"}},
+	"gain a shell remotely":         {ATTACK: []string{"T1210"}},
+	"rpc":                           {ATTACK: []string{"T1210"}},
+	"ftp":                           {ATTACK: []string{"T1210"}},
+	"smtp problems":                 {ATTACK: []string{"T1210"}},
+	"windows":                       {OWASP: []string{"A06:2021"}, ATTACK: []string{"T1068"}},
+	"windows : microsoft bulletins": {OWASP: []string{"A06:2021"}, ATTACK: []string{"T1068"}},
+}
+
+// localChecksSuffix ends the names of the plugin families detecting missing OS patches, such as
+// "Ubuntu Local Security Checks"
+const localChecksSuffix = "local security checks"
+
+// Infer maps a vulnerability from its weaknesses and the family of the scanner plugin that
+// detected it. Weaknesses without a known mapping and unknown families contribute nothing.
+func Infer(cweIDs []string, pluginFamily string) Mapping {
+	owasp := map[string]bool{}
+	attack := map[string]bool{}
+	for _, id := range cweIDs {
+		if category, ok := cweOWASP[id]; ok {
+			owasp[category] = true
+		}
+		for _, technique := range cweTechniques[id] {
+			attack[technique] = true
+		}
+	}
+
+	family := strings.ToLower(strings.TrimSpace(pluginFamily))
+	mapping, ok := pluginFamilies[family]
+	if !ok && strings.HasSuffix(family, localChecksSuffix) {
+		mapping, ok = Mapping{OWASP: []string{"A06:2021"}, ATTACK: []string{"T1068"}}, true
+	}
+	if ok {
+		for _, category := range mapping.OWASP {
+			owasp[category] = true
+		}
+		for _, technique := range mapping.ATTACK {
+			attack[technique] = true
+		}
+	}
+
+	return Mapping{OWASP: sortedKeys(owasp), ATTACK: sortedKeys(attack)}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
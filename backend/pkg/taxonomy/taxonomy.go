@@ -0,0 +1,204 @@
+// Package taxonomy classifies vulnerabilities in the OWASP Top 10 (2021) categories
+// (https://owasp.org/Top10) and MITRE ATT&CK Enterprise techniques (https://attack.mitre.org).
+// The ATT&CK catalog is the subset of techniques exploited through the vulnerabilities scanners
+// report; other valid technique IDs are accepted without a name.
+package taxonomy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Frameworks vulnerabilities are classified in
+const (
+	FrameworkOWASP  = "owasp"  // OWASP Top 10 category, e.g. A03:2021
+	FrameworkATTACK = "attack" // ATT&CK technique, e.g. T1190 or T1078.001
+)
+
+// Entry is a category or technique of a framework
+type Entry struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Tactic string `json:"tactic,omitempty"` // ATT&CK tactic ID, e.g. TA0001
+}
+
+// Tactic is an ATT&CK tactic, the adversary goal techniques are grouped under
+type Tactic struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// owaspTop10 are the OWASP Top 10 (2021) categories
+var owaspTop10 = []Entry{
+	{ID: "A01:2021", Name: "Broken Access Control"},
+	{ID: "A02:2021", Name: "Cryptographic Failures"},
+	{ID: "A03:2021", Name: "Injection"},
+	{ID: "A04:2021", Name: "Insecure Design"},
+	{ID: "A05:2021", Name: "Security Misconfiguration"},
+	{ID: "A06:2021", Name: "Vulnerable and Outdated Components"},
+	{ID: "A07:2021", Name: "Identification and Authentication Failures"},
+	{ID: "A08:2021", Name: "Software and Data Integrity Failures"},
+	{ID: "A09:2021", Name: "Security Logging and Monitoring Failures"},
+	{ID: "A10:2021", Name: "Server-Side Request Forgery (SSRF)"},
+}
+
+// attackTactics are the ATT&CK tactics of the catalog, in kill chain order
+var attackTactics = []Tactic{
+	{ID: "TA0001", Name: "Initial Access"},
+	{ID: "TA0002", Name: "Execution"},
+	{ID: "TA0003", Name: "Persistence"},
+	{ID: "TA0004", Name: "Privilege Escalation"},
+	{ID: "TA0005", Name: "Defense Evasion"},
+	{ID: "TA0006", Name: "Credential Access"},
+	{ID: "TA0007", Name: "Discovery"},
+	{ID: "TA0008", Name: "Lateral Movement"},
+	{ID: "TA0009", Name: "Collection"},
+	{ID: "TA0040", Name: "Impact"},
+}
+
+// attackTechniques are the ATT&CK techniques of the catalog, by tactic. A technique listed
+// under several tactics in ATT&CK is filed under the one vulnerabilities enable most directly.
+var attackTechniques = []Entry{
+	{ID: "T1078", Name: "Valid Accounts", Tactic: "TA0001"},
+	{ID: "T1078.001", Name: "Default Accounts", Tactic: "TA0001"},
+	{ID: "T1133", Name: "External Remote Services", Tactic: "TA0001"},
+	{ID: "T1189", Name: "Drive-by Compromise", Tactic: "TA0001"},
+	{ID: "T1190", Name: "Exploit Public-Facing Application", Tactic: "TA0001"},
+	{ID: "T1195", Name: "Supply Chain Compromise", Tactic: "TA0001"},
+	{ID: "T1566", Name: "Phishing", Tactic: "TA0001"},
+	{ID: "T1059", Name: "Command and Scripting Interpreter", Tactic: "TA0002"},
+	{ID: "T1203", Name: "Exploitation for Client Execution", Tactic: "TA0002"},
+	{ID: "T1505.003", Name: "Web Shell", Tactic: "TA0003"},
+	{ID: "T1068", Name: "Exploitation for Privilege Escalation", Tactic: "TA0004"},
+	{ID: "T1548", Name: "Abuse Elevation Control Mechanism", Tactic: "TA0004"},
+	{ID: "T1574", Name: "Hijack Execution Flow", Tactic: "TA0004"},
+	{ID: "T1211", Name: "Exploitation for Defense Evasion", Tactic: "TA0005"},
+	{ID: "T1562", Name: "Impair Defenses", Tactic: "TA0005"},
+	{ID: "T1040", Name: "Network Sniffing", Tactic: "TA0006"},
+	{ID: "T1110", Name: "Brute Force", Tactic: "TA0006"},
+	{ID: "T1212", Name: "Exploitation for Credential Access", Tactic: "TA0006"},
+	{ID: "T1539", Name: "Steal Web Session Cookie", Tactic: "TA0006"},
+	{ID: "T1552", Name: "Unsecured Credentials", Tactic: "TA0006"},
+	{ID: "T1557", Name: "Adversary-in-the-Middle", Tactic: "TA0006"},
+	{ID: "T1046", Name: "Network Service Discovery", Tactic: "TA0007"},
+	{ID: "T1083", Name: "File and Directory Discovery", Tactic: "TA0007"},
+	{ID: "T1021", Name: "Remote Services", Tactic: "TA0008"},
+	{ID: "T1210", Name: "Exploitation of Remote Services", Tactic: "TA0008"},
+	{ID: "T1005", Name: "Data from Local System", Tactic: "TA0009"},
+	{ID: "T1213", Name: "Data from Information Repositories", Tactic: "TA0009"},
+	{ID: "T1498", Name: "Network Denial of Service", Tactic: "TA0040"},
+	{ID: "T1499", Name: "Endpoint Denial of Service", Tactic: "TA0040"},
+	{ID: "T1565", Name: "Data Manipulation", Tactic: "TA0040"},
+}
+
+var (
+	owaspPattern     = regexp.MustCompile(`^A(\d{1,2})(?::2021)?$`)
+	techniquePattern = regexp.MustCompile(`^T\d{4}(?:\.\d{3})?$`)
+	// catalog maps a framework to its entries by ID
+	catalog = map[string]map[string]Entry{}
+)
+
+func init() {
+	for framework, entries := range map[string][]Entry{FrameworkOWASP: owaspTop10, FrameworkATTACK: attackTechniques} {
+		catalog[framework] = make(map[string]Entry, len(entries))
+		for _, entry := range entries {
+			catalog[framework][entry.ID] = entry
+		}
+	}
+}
+
+// Frameworks lists the frameworks vulnerabilities can be classified in
+func Frameworks() []string {
+	return []string{FrameworkOWASP, FrameworkATTACK}
+}
+
+// Normalize parses a code of framework into its canonical form. OWASP categories are written
+// as A03:2021, A03 or A3; ATT&CK techniques as T1190 or T1078.001 (case-insensitive).
+func Normalize(framework, raw string) (string, bool) {
+	code := strings.ToUpper(strings.TrimSpace(raw))
+	switch framework {
+	case FrameworkOWASP:
+		match := owaspPattern.FindStringSubmatch(code)
+		if match == nil {
+			return "", false
+		}
+		number, _ := strconv.Atoi(match[1])
+		if number < 1 || number > len(owaspTop10) {
+			return "", false
+		}
+		return fmt.Sprintf("A%02d:2021", number), true
+	case FrameworkATTACK:
+		if !techniquePattern.MatchString(code) {
+			return "", false
+		}
+		return code, true
+	}
+	return "", false
+}
+
+// ParseCodes normalizes codes of framework entered by a user, rejecting invalid ones and
+// dropping duplicates
+func ParseCodes(framework string, raw []string) ([]string, error) {
+	if _, ok := catalog[framework]; !ok {
+		return nil, fmt.Errorf("invalid framework '%s', use %s", framework, strings.Join(Frameworks(), " or "))
+	}
+	codes := []string{}
+	seen := make(map[string]bool, len(raw))
+	for _, r := range raw {
+		code, ok := Normalize(framework, r)
+		if !ok {
+			if framework == FrameworkOWASP {
+				return nil, fmt.Errorf("invalid OWASP Top 10 category '%s', use A01:2021 to A10:2021", r)
+			}
+			return nil, fmt.Errorf("invalid ATT&CK technique '%s', use T<number> or T<number>.<number>", r)
+		}
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	return codes, nil
+}
+
+// Lookup returns the catalog entry of a code of framework in any form Normalize accepts
+func Lookup(framework, raw string) (Entry, bool) {
+	code, ok := Normalize(framework, raw)
+	if !ok {
+		return Entry{}, false
+	}
+	entry, ok := catalog[framework][code]
+	return entry, ok
+}
+
+// Entries returns the catalog entries of framework: OWASP categories in rank order and ATT&CK
+// techniques by tactic
+func Entries(framework string) []Entry {
+	switch framework {
+	case FrameworkOWASP:
+		return append([]Entry(nil), owaspTop10...)
+	case FrameworkATTACK:
+		return append([]Entry(nil), attackTechniques...)
+	}
+	return nil
+}
+
+// Tactics returns the ATT&CK tactics of the catalog in kill chain order
+func Tactics() []Tactic {
+	return append([]Tactic(nil), attackTactics...)
+}
+
+// TacticOf returns the tactic an ATT&CK technique is filed under. Sub-techniques outside the
+// catalog take the tactic of their parent; "" when neither is in the catalog.
+func TacticOf(technique string) string {
+	if entry, ok := Lookup(FrameworkATTACK, technique); ok {
+		return entry.Tactic
+	}
+	if parent, _, ok := strings.Cut(technique, "."); ok {
+		if entry, ok := Lookup(FrameworkATTACK, parent); ok {
+			return entry.Tactic
+		}
+	}
+	return ""
+}
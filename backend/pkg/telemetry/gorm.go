@@ -0,0 +1,87 @@
+package telemetry
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "telemetry:span"
+
+// maxStatementLength caps the SQL recorded on spans
+const maxStatementLength = 2000
+
+// RegisterGORMCallbacks creates a span for every query whose context carries an active
+// span (i.e. queries made on behalf of a traced request). Queries from untraced contexts,
+// such as periodic background jobs, are not recorded.
+func RegisterGORMCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	registrations := []func() error{
+		func() error { return cb.Create().Before("gorm:create").Register("telemetry:before_create", startGORMSpan("create")) },
+		func() error { return cb.Create().After("gorm:create").Register("telemetry:after_create", endGORMSpan) },
+		func() error { return cb.Query().Before("gorm:query").Register("telemetry:before_query", startGORMSpan("query")) },
+		func() error { return cb.Query().After("gorm:query").Register("telemetry:after_query", endGORMSpan) },
+		func() error { return cb.Update().Before("gorm:update").Register("telemetry:before_update", startGORMSpan("update")) },
+		func() error { return cb.Update().After("gorm:update").Register("telemetry:after_update", endGORMSpan) },
+		func() error { return cb.Delete().Before("gorm:delete").Register("telemetry:before_delete", startGORMSpan("delete")) },
+		func() error { return cb.Delete().After("gorm:delete").Register("telemetry:after_delete", endGORMSpan) },
+		func() error { return cb.Row().Before("gorm:row").Register("telemetry:before_row", startGORMSpan("row")) },
+		func() error { return cb.Row().After("gorm:row").Register("telemetry:after_row", endGORMSpan) },
+		func() error { return cb.Raw().Before("gorm:raw").Register("telemetry:before_raw", startGORMSpan("raw")) },
+		func() error { return cb.Raw().After("gorm:raw").Register("telemetry:after_raw", endGORMSpan) },
+	}
+	for _, register := range registrations {
+		if err := register(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startGORMSpan returns a callback that opens a span for the statement
+func startGORMSpan(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		if ctx == nil || !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			return
+		}
+
+		name := "gorm." + operation
+		if tx.Statement.Table != "" {
+			name += " " + tx.Statement.Table
+		}
+		_, span := StartSpan(ctx, name,
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation", operation),
+			attribute.String("db.sql.table", tx.Statement.Table),
+		)
+		tx.InstanceSet(gormSpanKey, span)
+	}
+}
+
+// endGORMSpan closes the statement span, recording the SQL, rows affected, and any error
+func endGORMSpan(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+
+	statement := tx.Statement.SQL.String()
+	if len(statement) > maxStatementLength {
+		statement = statement[:maxStatementLength]
+	}
+	span.SetAttributes(
+		attribute.String("db.statement", statement),
+		attribute.Int64("db.rows_affected", tx.RowsAffected),
+	)
+	if tx.Error != nil && tx.Error != gorm.ErrRecordNotFound {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+	span.End()
+}
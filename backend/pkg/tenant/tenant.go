@@ -0,0 +1,168 @@
+// Package tenant scopes database access to an organization.
+//
+// Authenticated requests carry their organization in the request context. Queries issued
+// with that context (db.WithContext(ctx)) on tenant-owned tables are filtered by org_id,
+// and new rows are stamped with it. Queries without an organization in their context
+// (startup, background jobs, authentication) are not scoped.
+package tenant
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Column is the tenant column on scoped tables
+const Column = "org_id"
+
+// ScopedTables lists the tables that carry an org_id column and are filtered per tenant
+var ScopedTables = map[string]bool{
+	"users":                         true,
+	"affected_systems":              true,
+	"vulnerabilities":               true,
+	"vulnerability_findings":        true,
+	"assessments":                   true,
+	"api_keys":                      true,
+	"daily_metrics_snapshots":       true,
+	"teams":                         true,
+	"asset_groups":                  true,
+	"network_ranges":                true,
+	"import_rules":                  true,
+	"criticality_scoring_profiles":  true,
+	"business_services":             true,
+	"policy_violations":             true,
+	"dashboards":                    true,
+	"report_summaries":              true,
+	"import_jobs":                   true,
+	"quarantined_hosts":             true,
+	"import_changes":                true,
+	"vulnerability_escalations":     true,
+	"vulnerability_close_approvals": true,
+	"disclosures":                   true,
+	"auditor_share_tokens":          true,
+	"suppression_rules":             true,
+	"saved_views":                   true,
+	"risk_acceptances":              true,
+	"assignment_rules":              true,
+}
+
+type orgKey struct{}
+
+var (
+	defaultOrgMu sync.RWMutex
+	defaultOrgID uuid.UUID
+)
+
+// SetDefaultOrgID sets the organization assigned to rows created outside a tenant context
+func SetDefaultOrgID(id uuid.UUID) {
+	defaultOrgMu.Lock()
+	defer defaultOrgMu.Unlock()
+	defaultOrgID = id
+}
+
+// DefaultOrgID returns the organization assigned to rows created outside a tenant context
+func DefaultOrgID() uuid.UUID {
+	defaultOrgMu.RLock()
+	defer defaultOrgMu.RUnlock()
+	return defaultOrgID
+}
+
+// WithOrg returns a context scoped to the given organization
+func WithOrg(ctx context.Context, orgID uuid.UUID) context.Context {
+	return context.WithValue(ctx, orgKey{}, orgID)
+}
+
+// OrgFromContext returns the organization a context is scoped to
+func OrgFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	orgID, ok := ctx.Value(orgKey{}).(uuid.UUID)
+	return orgID, ok && orgID != uuid.Nil
+}
+
+// RegisterGORMCallbacks installs the tenant scoping callbacks
+func RegisterGORMCallbacks(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Query().Before("gorm:query").Register("tenant:scope_query", scopeStatement); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tenant:scope_row", scopeStatement); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant:scope_update", scopeStatement); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tenant:scope_delete", scopeStatement); err != nil {
+		return err
+	}
+	return cb.Create().Before("gorm:create").Register("tenant:assign_create", assignOrg)
+}
+
+// isScoped reports whether the statement targets a tenant-owned table
+func isScoped(tx *gorm.DB) bool {
+	if tx.Statement.Schema != nil {
+		return ScopedTables[tx.Statement.Schema.Table]
+	}
+	return ScopedTables[tx.Statement.Table]
+}
+
+// scopeStatement filters reads and writes on tenant tables by the context's organization
+func scopeStatement(tx *gorm.DB) {
+	if tx.Error != nil || !isScoped(tx) {
+		return
+	}
+	orgID, ok := OrgFromContext(tx.Statement.Context)
+	if !ok {
+		return
+	}
+	tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: Column}, Value: orgID},
+	}})
+}
+
+// assignOrg stamps new rows with the context's organization (or the default organization)
+func assignOrg(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.Schema == nil || !ScopedTables[tx.Statement.Schema.Table] {
+		return
+	}
+	field := tx.Statement.Schema.LookUpField("OrgID")
+	if field == nil {
+		return
+	}
+
+	orgID, ok := OrgFromContext(tx.Statement.Context)
+	if !ok {
+		orgID = DefaultOrgID()
+	}
+	if orgID == uuid.Nil {
+		return
+	}
+
+	assign := func(rv reflect.Value) {
+		rv = reflect.Indirect(rv)
+		if rv.Kind() != reflect.Struct {
+			return
+		}
+		if _, zero := field.ValueOf(tx.Statement.Context, rv); zero {
+			value := orgID
+			if err := field.Set(tx.Statement.Context, rv, &value); err != nil {
+				tx.AddError(err)
+			}
+		}
+	}
+
+	rv := reflect.Indirect(tx.Statement.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			assign(rv.Index(i))
+		}
+	case reflect.Struct:
+		assign(rv)
+	}
+}
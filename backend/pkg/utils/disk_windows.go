@@ -0,0 +1,10 @@
+//go:build windows
+
+package utils
+
+import "errors"
+
+// DiskUsage is not implemented on Windows
+func DiskUsage(path string) (free uint64, total uint64, err error) {
+	return 0, 0, errors.New("disk usage is not supported on this platform")
+}
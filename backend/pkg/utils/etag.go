@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResourceETag returns the strong entity tag of a record version, derived from its updated_at
+// timestamp at the database's microsecond precision
+func ResourceETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 36) + `"`
+}
+
+// ETagMatches reports whether an If-Match or If-None-Match header value lists etag. "*" matches
+// any current version. Weak comparison (If-None-Match) ignores the W/ prefix; strong comparison
+// (If-Match) never matches a weak tag.
+func ETagMatches(header, etag string, weak bool) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
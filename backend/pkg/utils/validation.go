@@ -160,3 +160,26 @@ func ValidateCVEID(cveID string) error {
 
 	return nil
 }
+
+// FieldError is a validation error attributed to one request field, reported to clients as a
+// field-level error
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErr attributes a validation error to a request field; a nil err stays nil
+func FieldErr(field string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &FieldError{Field: field, Err: err}
+}
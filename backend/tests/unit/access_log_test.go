@@ -0,0 +1,102 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs sends the global logger to a buffer for the duration of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := utils.Logger
+	utils.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { utils.Logger = previous })
+	return &buf
+}
+
+// accessLogEntries decodes the captured access log lines
+func accessLogEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	entries := []map[string]interface{}{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if entry["log_type"] == "access" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func TestRequestIDGeneratedOrEchoed(t *testing.T) {
+	app := fiber.New()
+	app.Use(middleware.RequestID())
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString(middleware.GetRequestID(c)) })
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	require.NoError(t, err)
+	_, err = uuid.Parse(resp.Header.Get("X-Request-ID"))
+	assert.NoError(t, err, "a request ID is generated when the client sends none")
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set("X-Request-ID", "client-supplied")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, "client-supplied", resp.Header.Get("X-Request-ID"))
+}
+
+func TestAccessLogRecordsFinalResponse(t *testing.T) {
+	logs := captureLogs(t)
+	userID := uuid.New()
+
+	app := fiber.New()
+	app.Use(middleware.RequestID(), middleware.AccessLog())
+	app.Get("/assets/:id", func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		c.Locals("auth_method", "jwt")
+		return fiber.NewError(fiber.StatusNotFound, "asset not found")
+	})
+	app.Get("/health", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	app.Get("/crash", func(c *fiber.Ctx) error { return fiber.ErrInternalServerError })
+
+	req := httptest.NewRequest(fiber.MethodGet, "/assets/42", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode, "the error is rendered once")
+	for _, path := range []string{"/health", "/crash"} {
+		_, err = app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		require.NoError(t, err)
+	}
+
+	entries := accessLogEntries(t, logs)
+	require.Len(t, entries, 3)
+
+	notFound := entries[0]
+	assert.Equal(t, "warn", notFound["level"])
+	assert.Equal(t, "req-123", notFound["request_id"])
+	assert.Equal(t, "/assets/:id", notFound["route"])
+	assert.Equal(t, "/assets/42", notFound["path"])
+	assert.Equal(t, float64(fiber.StatusNotFound), notFound["status"])
+	assert.Equal(t, userID.String(), notFound["user_id"])
+	assert.Equal(t, "jwt", notFound["auth_method"])
+	assert.Equal(t, "asset not found", notFound["error"])
+	assert.Equal(t, float64(len("asset not found")), notFound["bytes_out"])
+
+	assert.Equal(t, "debug", entries[1]["level"], "health probes log at debug")
+	assert.NotContains(t, entries[1], "user_id")
+	assert.Equal(t, "error", entries[2]["level"])
+}
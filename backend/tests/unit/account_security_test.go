@@ -0,0 +1,89 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chromeOnWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+	firefoxOnLinux  = "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0"
+)
+
+func TestSecurityOverviewUnknownUser(t *testing.T) {
+	db, _ := setupSchemaDB(t)
+	if db == nil {
+		return
+	}
+
+	_, err := services.NewProfileService().GetSecurityOverview(uuid.New())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not found")
+	}
+}
+
+func TestSecurityOverviewListsOwnLoginsKeysAndActions(t *testing.T) {
+	db, user := setupSchemaDB(t)
+	if db == nil {
+		return
+	}
+	other := &models.User{Email: "other@example.com", Password: "hashedpassword", Name: "Other User"}
+	require.NoError(t, db.Create(other).Error)
+
+	sessions := services.NewSessionService()
+	sessions.RecordLogin(services.LoginAttempt{UserID: &user.ID, Success: true, IPAddress: "203.0.113.7", UserAgent: chromeOnWindows, Location: "Berlin, DE"})
+	sessions.RecordLogin(services.LoginAttempt{UserID: &user.ID, FailReason: "invalid_password", IPAddress: "198.51.100.9", UserAgent: chromeOnWindows})
+	sessions.RecordLogin(services.LoginAttempt{UserID: &other.ID, Success: true, IPAddress: "192.0.2.1", UserAgent: chromeOnWindows})
+	// Logins recorded before device metadata was stored
+	require.NoError(t, db.Create(models.NewAuthEvent(&user.ID, models.EventTypeLogin, "203.0.113.8", firefoxOnLinux)).Error)
+	require.NoError(t, db.Create(models.NewAuthEvent(&user.ID, models.EventTypePasswordChange, "203.0.113.7", chromeOnWindows)).Error)
+	require.NoError(t, db.Create(models.NewAuthEvent(&user.ID, models.EventTypeLogout, "203.0.113.7", chromeOnWindows)).Error)
+
+	expired := time.Now().Add(-time.Hour)
+	keys := []models.APIKey{
+		{UserID: user.ID, Name: "CI pipeline", Status: models.APIKeyStatusActive},
+		{UserID: user.ID, Name: "Revoked", Status: models.APIKeyStatusRevoked},
+		{UserID: user.ID, Name: "Expired", Status: models.APIKeyStatusActive, ExpiresAt: &expired},
+		{UserID: other.ID, Name: "Someone else's", Status: models.APIKeyStatusActive},
+	}
+	for i := range keys {
+		keys[i].Type = models.APIKeyTypePersonal
+		keys[i].KeyHash = uuid.NewString()
+		keys[i].KeyPrefix = "cyops_" + keys[i].KeyHash[:4]
+		keys[i].Scopes = pq.StringArray{"assets:read"}
+		require.NoError(t, db.Create(&keys[i]).Error)
+	}
+
+	overview, err := services.NewProfileService().GetSecurityOverview(user.ID)
+	require.NoError(t, err)
+	assert.False(t, overview.TwoFactor.Enabled)
+	assert.Equal(t, int64(1), overview.FailedLogins)
+
+	require.Len(t, overview.RecentLogins, 3, "only the user's own logins")
+	logins := map[string]services.LoginRecord{}
+	for _, login := range overview.RecentLogins {
+		logins[login.IPAddress] = login
+	}
+	assert.True(t, logins["203.0.113.7"].Success)
+	assert.Equal(t, "Berlin, DE", logins["203.0.113.7"].Location)
+	assert.Equal(t, "Chrome 126", logins["203.0.113.7"].Browser)
+	assert.Equal(t, "Windows 10/11", logins["203.0.113.7"].OS)
+	assert.False(t, logins["198.51.100.9"].Success)
+	assert.Equal(t, "invalid_password", logins["198.51.100.9"].FailReason)
+	assert.Equal(t, "Firefox 128", logins["203.0.113.8"].Browser, "derived from the user agent")
+	assert.Equal(t, "Linux", logins["203.0.113.8"].OS)
+
+	require.Len(t, overview.APIKeys, 1, "revoked, expired and other users' keys are not listed")
+	assert.Equal(t, "CI pipeline", overview.APIKeys[0].Name)
+	assert.Equal(t, []string{"assets:read"}, overview.APIKeys[0].Scopes)
+
+	require.Len(t, overview.RecentActions, 1, "logins and logouts are not sensitive actions")
+	assert.Equal(t, models.EventTypePasswordChange, overview.RecentActions[0].EventType)
+}
@@ -0,0 +1,67 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAgentCheckin(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("normalizes inventory", func(t *testing.T) {
+		req := services.AgentCheckinRequest{
+			AgentID:     " agent-1 ",
+			Hostname:    " Laptop-42 ",
+			IPAddresses: []string{"10.0.0.5", " 10.0.0.5", "2001:db8::1"},
+			Packages: []services.AgentPackage{
+				{Name: "openssl", Version: "3.0.2"},
+				{Name: " openssl ", Version: "3.0.2 "},
+				{Name: "openssl", Version: "1.1.1"},
+			},
+		}
+		require.NoError(t, services.ValidateAgentCheckin(&req, now))
+
+		assert.Equal(t, "agent-1", req.AgentID)
+		assert.Equal(t, "laptop-42", req.Hostname)
+		assert.Equal(t, []string{"10.0.0.5", "2001:db8::1"}, req.IPAddresses)
+		assert.Len(t, req.Packages, 2)
+		require.NotNil(t, req.SeenAt)
+		assert.Equal(t, now, *req.SeenAt)
+	})
+
+	t.Run("keeps past seen_at and clamps future seen_at", func(t *testing.T) {
+		past := now.Add(-time.Hour)
+		req := services.AgentCheckinRequest{AgentID: "a", Hostname: "h", SeenAt: &past}
+		require.NoError(t, services.ValidateAgentCheckin(&req, now))
+		assert.Equal(t, past, *req.SeenAt)
+
+		future := now.Add(time.Hour)
+		req = services.AgentCheckinRequest{AgentID: "a", Hostname: "h", SeenAt: &future}
+		require.NoError(t, services.ValidateAgentCheckin(&req, now))
+		assert.Equal(t, now, *req.SeenAt)
+	})
+
+	t.Run("rejects invalid check-ins", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  services.AgentCheckinRequest
+			err  string
+		}{
+			{"missing agent id", services.AgentCheckinRequest{Hostname: "h"}, "agent_id is required"},
+			{"no identifier", services.AgentCheckinRequest{AgentID: "a"}, "hostname or ip_addresses is required"},
+			{"bad ip", services.AgentCheckinRequest{AgentID: "a", IPAddresses: []string{"10.0.0.300"}}, "invalid ip address: 10.0.0.300"},
+			{"unnamed package", services.AgentCheckinRequest{AgentID: "a", Hostname: "h", Packages: []services.AgentPackage{{Version: "1"}}}, "invalid packages: package name is required"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := services.ValidateAgentCheckin(&tt.req, now)
+				require.Error(t, err)
+				assert.Equal(t, tt.err, err.Error())
+			})
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlidingWindowLimiterEnforcesLimitPerKey(t *testing.T) {
+	limiter := middleware.NewSlidingWindowLimiter(time.Minute)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		allowed, remaining, _ := limiter.Allow("key-a", 3, start.Add(time.Duration(i)*time.Second))
+		assert.True(t, allowed)
+		assert.Equal(t, 2-i, remaining)
+	}
+
+	allowed, _, retryAfter := limiter.Allow("key-a", 3, start.Add(10*time.Second))
+	assert.False(t, allowed)
+	assert.Greater(t, retryAfter, time.Duration(0))
+
+	// Other keys have their own budget
+	allowed, _, _ = limiter.Allow("key-b", 3, start.Add(10*time.Second))
+	assert.True(t, allowed)
+}
+
+func TestSlidingWindowLimiterWeightsPreviousWindow(t *testing.T) {
+	limiter := middleware.NewSlidingWindowLimiter(time.Minute)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 4; i++ {
+		allowed, _, _ := limiter.Allow("key", 4, start.Add(50*time.Second))
+		assert.True(t, allowed)
+	}
+
+	// 15s into the next window, 75% of the previous window still counts: 3 of 4 used
+	allowed, remaining, _ := limiter.Allow("key", 4, start.Add(75*time.Second))
+	assert.True(t, allowed)
+	assert.Equal(t, 0, remaining)
+	allowed, _, _ = limiter.Allow("key", 4, start.Add(75*time.Second))
+	assert.False(t, allowed)
+
+	// Two windows later the history is gone
+	allowed, remaining, _ = limiter.Allow("key", 4, start.Add(3*time.Minute))
+	assert.True(t, allowed)
+	assert.Equal(t, 3, remaining)
+}
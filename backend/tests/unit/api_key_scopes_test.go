@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/handlers"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsCatalogScope(t *testing.T) {
+	assert.True(t, models.IsCatalogScope("findings:read"))
+	assert.True(t, models.IsCatalogScope("admin:*"))
+	assert.True(t, models.IsCatalogScope(models.ScopeWildcard))
+
+	assert.False(t, models.IsCatalogScope("findings:fly"))
+	assert.False(t, models.IsCatalogScope("spaceship:*"))
+	assert.False(t, models.IsCatalogScope("findings"))
+}
+
+func TestScopeGrantedWildcards(t *testing.T) {
+	assert.True(t, models.ScopeGranted([]string{"assets:read"}, "assets:read"))
+	assert.False(t, models.ScopeGranted([]string{"assets:read"}, "assets:write"))
+	assert.True(t, models.ScopeGranted([]string{"assets:*"}, "assets:delete"))
+	assert.False(t, models.ScopeGranted([]string{"assets:*"}, "findings:read"))
+	assert.True(t, models.ScopeGranted([]string{models.ScopeWildcard}, "admin:write"))
+	assert.False(t, models.ScopeGranted(nil, "assets:read"))
+}
+
+func TestExpandScopes(t *testing.T) {
+	assert.Equal(t,
+		[]string{"reports:read", "reports:export", "dashboard:wallboard"},
+		models.ExpandScopes([]string{"dashboard:wallboard", "reports:*"}),
+	)
+	assert.Empty(t, models.ExpandScopes([]string{"unknown:read"}))
+}
+
+func TestAPIKeyRotationSupport(t *testing.T) {
+	assert.True(t, (&models.APIKey{Type: models.APIKeyTypeService}).SupportsRotation())
+	assert.True(t, (&models.APIKey{Type: models.APIKeyTypeMCP}).SupportsRotation())
+	assert.False(t, (&models.APIKey{Type: models.APIKeyTypePersonal}).SupportsRotation())
+
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	assert.True(t, (&models.APIKey{SecondaryKeyHash: "hash", SecondaryExpiresAt: &future}).HasActiveSecondary())
+	assert.False(t, (&models.APIKey{SecondaryKeyHash: "hash", SecondaryExpiresAt: &past}).HasActiveSecondary())
+	assert.False(t, (&models.APIKey{SecondaryExpiresAt: &future}).HasActiveSecondary())
+}
+
+// apiKeyApp serves a handler chain as a request authenticated with an API key holding scopes
+func apiKeyApp(scopes []string, method, path string, chain ...fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("auth_method", "api_key")
+		c.Locals("api_key_scopes", scopes)
+		c.Locals("user_id", uuid.New())
+		return c.Next()
+	})
+	app.Add(method, path, chain...)
+	return app
+}
+
+func TestReadOnlyAPIKeyCannotWrite(t *testing.T) {
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) }
+
+	// Scopes guarding suppression rules, organizations, teams and API keys
+	for _, scope := range []string{"rules:write", "admin:write", "api_keys:write"} {
+		resource, _, _ := strings.Cut(scope, ":")
+		readOnly := []string{resource + ":read", "vulnerabilities:read"}
+
+		app := apiKeyApp(readOnly, fiber.MethodPost, "/", middleware.RequireScope(scope), ok)
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode, scope)
+
+		app = apiKeyApp(readOnly, fiber.MethodGet, "/", middleware.RequireScope(resource+":read"), ok)
+		resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode, scope)
+	}
+}
+
+func TestAPIKeyCannotMintBroaderKey(t *testing.T) {
+	handler := handlers.NewAPIKeyHandler()
+	caller := []string{"api_keys:write", "assets:read"}
+
+	for _, scopes := range []string{`["*:*"]`, `["assets:*"]`, `["assets:read","admin:write"]`} {
+		app := apiKeyApp(caller, fiber.MethodPost, "/", handler.CreateAPIKey)
+		req := httptest.NewRequest(fiber.MethodPost, "/",
+			strings.NewReader(`{"name":"ci key","type":"service","scopes":`+scopes+`}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode, scopes)
+	}
+}
@@ -0,0 +1,78 @@
+package unit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteGroup(t *testing.T) {
+	assert.Equal(t, "vulnerabilities", middleware.RouteGroup("/api/v1/vulnerabilities/123"))
+	assert.Equal(t, "reports", middleware.RouteGroup("/api/v2/reports"))
+	assert.Equal(t, "health", middleware.RouteGroup("/health"))
+	assert.Equal(t, "", middleware.RouteGroup("/api/v1"))
+}
+
+func TestParseAPIRateLimitSettings(t *testing.T) {
+	settings, err := services.ParseAPIRateLimitSettings(`{"enabled":true,"default":{"user_per_minute":600,"api_key_per_minute":300},"groups":{"reports":{"user_per_minute":30}}}`)
+	require.NoError(t, err)
+	assert.Equal(t, 600, settings.LimitFor("vulnerabilities").UserPerMinute)
+	assert.Equal(t, 30, settings.LimitFor("reports").UserPerMinute)
+	assert.Equal(t, 0, settings.LimitFor("reports").APIKeyPerMinute, "a group override replaces the default quota")
+
+	for _, value := range []string{
+		`not json`,
+		`{"default":{"user_per_minute":-1}}`,
+		`{"default":{"api_key_per_minute":1000000}}`,
+		`{"groups":{"Reports":{"user_per_minute":10}}}`,
+		`{"groups":{"reports/export":{"user_per_minute":10}}}`,
+	} {
+		_, err := services.ParseAPIRateLimitSettings(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	store := middleware.NewMemoryRateLimitStore()
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	result, err := store.Allow(ctx, "user:a", 2, time.Minute, now)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+
+	store.Allow(ctx, "user:a", 2, time.Minute, now)
+	result, _ = store.Allow(ctx, "user:a", 2, time.Minute, now)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Minute, result.RetryAfter)
+
+	// Windows of different lengths count separately
+	result, _ = store.Allow(ctx, "user:a", 2, time.Hour, now)
+	assert.True(t, result.Allowed)
+}
+
+func TestRateLimiterSetsRetryAfter(t *testing.T) {
+	app := fiber.New()
+	app.Get("/limited", middleware.NewRateLimiter(middleware.RateLimitConfig{Max: 1, Expiration: time.Hour}), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/limited", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/limited", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get(fiber.HeaderRetryAfter))
+}
@@ -0,0 +1,56 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	page, err := middleware.DecodeCursor(middleware.EncodeCursor(7))
+	require.NoError(t, err)
+	assert.Equal(t, 7, page)
+
+	for _, cursor := range []string{"", "not base64!", "cDE6MA", "eHl6"} {
+		_, err := middleware.DecodeCursor(cursor)
+		assert.Error(t, err, cursor)
+	}
+}
+
+func TestV2ResponseCursorMeta(t *testing.T) {
+	body := `{"data":[1,2],"meta":{"page":2,"limit":2,"total":5,"total_pages":3}}`
+	out, err := middleware.V2Response(200, []byte(body), "")
+	require.NoError(t, err)
+
+	var envelope middleware.APIEnvelope
+	require.NoError(t, json.Unmarshal(out, &envelope))
+	assert.Equal(t, []interface{}{float64(1), float64(2)}, envelope.Data)
+	assert.NotContains(t, envelope.Meta, "page")
+	assert.NotContains(t, envelope.Meta, "total_pages")
+	assert.Equal(t, float64(5), envelope.Meta["total"])
+	assert.Equal(t, true, envelope.Meta["has_more"])
+	assert.Equal(t, middleware.EncodeCursor(3), envelope.Meta["next_cursor"])
+	assert.Equal(t, middleware.EncodeCursor(1), envelope.Meta["prev_cursor"])
+
+	// Top-level pagination (e.g. the asset list) is paged the same way
+	out, err = middleware.V2Response(200, []byte(`{"data":[],"page":1,"limit":50,"total":3}`), "")
+	require.NoError(t, err)
+	envelope = middleware.APIEnvelope{}
+	require.NoError(t, json.Unmarshal(out, &envelope))
+	assert.Equal(t, false, envelope.Meta["has_more"])
+	assert.NotContains(t, envelope.Meta, "next_cursor")
+	assert.NotContains(t, envelope.Meta, "prev_cursor")
+}
+
+func TestV2ResponseNonList(t *testing.T) {
+	out, err := middleware.V2Response(200, []byte(`{"id":"a"}`), "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":{"id":"a"}}`, string(out))
+
+	out, err = middleware.V2Response(404, []byte(`{"error":"Asset not found"}`), "req-1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"error":{"code":"not_found","message":"Asset not found","status":404,"request_id":"req-1"}}`, string(out))
+}
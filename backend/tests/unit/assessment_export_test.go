@@ -0,0 +1,87 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssessmentExportWritePackage(t *testing.T) {
+	assessmentID := uuid.New()
+	pkg := &services.ExportPackage{
+		Filename: "Q3_Pentest_export_20261017.zip",
+		Data: services.ExportAssessmentData{
+			SchemaVersion: services.ExportSchemaVersion,
+			GeneratedAt:   time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+			GeneratedBy:   services.ExportUser{ID: uuid.New(), Name: "Lead Assessor"},
+			Assessment:    models.Assessment{BaseModel: models.BaseModel{ID: assessmentID}, Name: "Q3 Pentest"},
+			Findings: []services.ExportFinding{{
+				Ref:      "F-01",
+				Title:    "SQL injection in login form",
+				Severity: models.SeverityCritical,
+				Evidence: []services.ExportEvidence{},
+			}},
+			Report: services.ExportReport{Title: "Assessment Report", Path: "report/Q3_Pentest_report.pdf", MimeType: "application/pdf"},
+		},
+		AuditTrail: services.ExportAuditTrail{Entries: []services.ExportAuditEntry{{
+			Timestamp: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+			Action:    services.ExportAuditRetestRequested,
+			Resource:  "retest",
+		}}},
+		ReportData: []byte("%PDF-1.7 report"),
+	}
+
+	var buf bytes.Buffer
+	manifest, err := services.NewAssessmentExportService(nil).WritePackage(pkg, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, assessmentID, manifest.AssessmentID)
+	assert.Equal(t, "SHA-256", manifest.Algorithm)
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	var names []string
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		files[file.Name] = data
+		names = append(names, file.Name)
+	}
+	require.NotEmpty(t, names)
+	assert.Equal(t, "manifest.json", names[len(names)-1], "the manifest is written last")
+	assert.Equal(t, []byte("%PDF-1.7 report"), files["report/Q3_Pentest_report.pdf"])
+
+	var written services.ExportManifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &written))
+	require.Len(t, written.Files, 3)
+	for _, entry := range written.Files {
+		data, ok := files[entry.Path]
+		require.True(t, ok, "manifest lists %s", entry.Path)
+		sum := sha256.Sum256(data)
+		assert.Equal(t, hex.EncodeToString(sum[:]), entry.SHA256, entry.Path)
+		assert.Equal(t, int64(len(data)), entry.Size, entry.Path)
+	}
+
+	var data services.ExportAssessmentData
+	require.NoError(t, json.Unmarshal(files["assessment.json"], &data))
+	require.Len(t, data.Findings, 1)
+	assert.Equal(t, "F-01", data.Findings[0].Ref)
+
+	var trail services.ExportAuditTrail
+	require.NoError(t, json.Unmarshal(files["audit_trail.json"], &trail))
+	require.Len(t, trail.Entries, 1)
+	assert.Equal(t, services.ExportAuditRetestRequested, trail.Entries[0].Action)
+}
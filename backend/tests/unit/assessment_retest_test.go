@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteRetestRequestValidate(t *testing.T) {
+	req := services.CompleteRetestRequest{Outcome: " partially_fixed ", Evidence: " login still reflects input "}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, models.RetestPartiallyFixed, req.Outcome)
+	assert.Equal(t, "login still reflects input", req.Evidence)
+
+	// Attachments alone are enough evidence
+	req = services.CompleteRetestRequest{Outcome: models.RetestFixed, EvidenceAttachmentIDs: []uuid.UUID{uuid.New()}}
+	assert.NoError(t, req.Validate())
+
+	tests := []struct {
+		name    string
+		req     services.CompleteRetestRequest
+		wantErr string
+	}{
+		{"missing outcome", services.CompleteRetestRequest{Evidence: "x"}, "outcome must be one of"},
+		{"ready is not an outcome", services.CompleteRetestRequest{Outcome: models.RetestReady, Evidence: "x"}, "outcome must be one of"},
+		{"no evidence", services.CompleteRetestRequest{Outcome: models.RetestNotFixed, Evidence: "   "}, "evidence or evidence_attachment_ids is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSummarizeRetests(t *testing.T) {
+	fixed, pending, untouched := uuid.New(), uuid.New(), uuid.New()
+	retesterID := uuid.New()
+	retestedAt := time.Now()
+
+	summary := services.SummarizeRetests([]uuid.UUID{fixed, pending, untouched}, []models.AssessmentRetest{
+		{VulnerabilityID: fixed, Round: 1, Status: models.RetestNotFixed},
+		{VulnerabilityID: fixed, Round: 2, Status: models.RetestFixed, RetesterID: &retesterID, RetestedAt: &retestedAt},
+		{VulnerabilityID: pending, Round: 1, Status: models.RetestReady},
+	})
+
+	assert.Equal(t, 3, summary.TotalFindings)
+	assert.Equal(t, 1, summary.Fixed)
+	assert.Equal(t, 0, summary.NotFixed, "earlier rounds are superseded by the latest")
+	assert.Equal(t, 1, summary.ReadyForRetest)
+	assert.Equal(t, 1, summary.NotRequested)
+
+	require.Len(t, summary.Findings, 3)
+	assert.Equal(t, models.RetestFixed, summary.Findings[0].Status)
+	assert.Equal(t, 2, summary.Findings[0].Round)
+	assert.Equal(t, &retesterID, summary.Findings[0].RetesterID)
+	assert.Empty(t, summary.Findings[2].Status)
+	assert.Nil(t, summary.Findings[2].RetestID)
+}
@@ -0,0 +1,35 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecommissionAssetValidation(t *testing.T) {
+	// Requests are validated before the asset is loaded
+	service := services.NewAssetService(nil)
+	negative := -1
+	tooLong := 366
+
+	tests := []struct {
+		name string
+		req  services.AssetDecommissionRequest
+		want string
+	}{
+		{"missing reason", services.AssetDecommissionRequest{Reason: "  "}, "reason is required"},
+		{"unknown disposition", services.AssetDecommissionRequest{Reason: "Retired", Disposition: "archive"}, "invalid disposition"},
+		{"negative grace period", services.AssetDecommissionRequest{Reason: "Retired", GraceDays: &negative}, "invalid grace_days"},
+		{"grace period too long", services.AssetDecommissionRequest{Reason: "Retired", GraceDays: &tooLong}, "invalid grace_days"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.DecommissionAsset(uuid.New(), tt.req, uuid.New())
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.want)
+			}
+		})
+	}
+}
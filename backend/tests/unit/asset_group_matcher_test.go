@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssetGroupMatcher(t *testing.T) {
+	group := &models.AssetGroup{
+		Name:             "prod web",
+		RuleTags:         pq.StringArray{"web", "dmz"},
+		RuleEnvironments: pq.StringArray{"PRODUCTION"},
+		RuleCIDRs:        pq.StringArray{"10.20.0.0/16", "192.168.5.0/24"},
+	}
+	matcher := services.NewAssetGroupMatcher(group)
+
+	asset := func(env models.Environment, ip string, tags ...string) *models.AffectedSystem {
+		a := &models.AffectedSystem{Environment: env, IPAddress: ip, SystemType: models.SystemTypeServer}
+		for _, tag := range tags {
+			a.Tags = append(a.Tags, models.AssetTag{Tag: tag})
+		}
+		return a
+	}
+
+	tests := []struct {
+		name     string
+		asset    *models.AffectedSystem
+		expected bool
+	}{
+		{"all rules match", asset(models.EnvProduction, "10.20.1.5", "web"), true},
+		{"any listed tag matches", asset(models.EnvProduction, "192.168.5.9", "dmz", "linux"), true},
+		{"wrong environment", asset(models.EnvStaging, "10.20.1.5", "web"), false},
+		{"outside every CIDR", asset(models.EnvProduction, "10.30.1.5", "web"), false},
+		{"missing tag", asset(models.EnvProduction, "10.20.1.5", "linux"), false},
+		{"no IP address", asset(models.EnvProduction, "", "web"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matcher.Matches(tt.asset))
+		})
+	}
+}
+
+func TestAssetGroupMatcherWithoutRules(t *testing.T) {
+	group := &models.AssetGroup{Name: "static only"}
+	assert.False(t, group.HasRules())
+
+	matcher := services.NewAssetGroupMatcher(group)
+	assert.False(t, matcher.Matches(&models.AffectedSystem{Environment: models.EnvProduction, IPAddress: "10.0.0.1"}))
+}
+
+func TestValidateAssetGroupRules(t *testing.T) {
+	valid := &models.AssetGroup{
+		RuleEnvironments: pq.StringArray{"PRODUCTION", "STAGING"},
+		RuleSystemTypes:  pq.StringArray{"SERVER", "CONTAINER"},
+		RuleCIDRs:        pq.StringArray{"10.0.0.0/8", "2001:db8::/32"},
+	}
+	assert.NoError(t, services.ValidateAssetGroupRules(valid))
+
+	assert.Error(t, services.ValidateAssetGroupRules(&models.AssetGroup{RuleEnvironments: pq.StringArray{"QA"}}))
+	assert.Error(t, services.ValidateAssetGroupRules(&models.AssetGroup{RuleSystemTypes: pq.StringArray{"MAINFRAME"}}))
+	assert.Error(t, services.ValidateAssetGroupRules(&models.AssetGroup{RuleCIDRs: pq.StringArray{"10.0.0.0"}}))
+}
@@ -0,0 +1,53 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssetFieldChanges(t *testing.T) {
+	ownerID := uuid.New()
+	before := models.AffectedSystem{
+		Hostname:    "web-01",
+		IPAddress:   "10.0.0.5",
+		SystemType:  models.SystemTypeServer,
+		Environment: models.EnvStaging,
+		Criticality: criticalityPtr(models.CriticalityMedium),
+		Status:      models.StatusActive,
+		Location:    "DC1",
+	}
+
+	t.Run("no changes", func(t *testing.T) {
+		assert.Empty(t, services.AssetFieldChanges(before, before))
+	})
+
+	t.Run("changed fields in stable order", func(t *testing.T) {
+		after := before
+		after.Environment = models.EnvProduction
+		after.Criticality = criticalityPtr(models.CriticalityCritical)
+		after.OwnerID = &ownerID
+		after.Location = "DC2"
+
+		changes := services.AssetFieldChanges(before, after)
+		assert.Equal(t, []services.AssetFieldChange{
+			{Field: "environment", OldValue: "STAGING", NewValue: "PRODUCTION"},
+			{Field: "criticality", OldValue: "MEDIUM", NewValue: "CRITICAL"},
+			{Field: "owner_id", OldValue: "", NewValue: ownerID.String()},
+			{Field: "location", OldValue: "DC1", NewValue: "DC2"},
+		}, changes)
+	})
+
+	t.Run("cleared optional field", func(t *testing.T) {
+		after := before
+		after.Criticality = nil
+
+		changes := services.AssetFieldChanges(before, after)
+		assert.Equal(t, []services.AssetFieldChange{
+			{Field: "criticality", OldValue: "MEDIUM", NewValue: ""},
+		}, changes)
+	})
+}
@@ -0,0 +1,45 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeOwnerTimeToFix(t *testing.T) {
+	detected := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	alice, platform := uuid.New(), uuid.New()
+	samples := []services.FindingFixSample{
+		{OwnerID: &alice, OwnerName: "Alice", OwnerTeamID: &platform, OwnerTeamName: "Platform", DetectedAt: detected, FixedAt: detected.AddDate(0, 0, 4)},
+		{OwnerID: &alice, OwnerName: "Alice", OwnerTeamID: &platform, OwnerTeamName: "Platform", DetectedAt: detected, FixedAt: detected.AddDate(0, 0, 10)},
+		{OwnerTeamID: &platform, OwnerTeamName: "Platform", DetectedAt: detected, FixedAt: detected.AddDate(0, 0, 30)},
+		// Fixed before detection counts as fixed immediately
+		{DetectedAt: detected, FixedAt: detected.AddDate(0, 0, -1)},
+	}
+
+	byOwner, byOwnerTeam := services.ComputeOwnerTimeToFix(samples)
+	require.Len(t, byOwner, 2)
+	assert.Equal(t, "", byOwner[0].Key)
+	assert.Equal(t, "Unassigned", byOwner[0].Label)
+	assert.Equal(t, int64(2), byOwner[0].Remediated)
+	assert.Equal(t, 15.0, byOwner[0].AverageDays)
+	assert.Equal(t, alice.String(), byOwner[1].Key)
+	assert.Equal(t, "Alice", byOwner[1].Label)
+	assert.Equal(t, 7.0, byOwner[1].AverageDays)
+
+	// Slowest team first
+	require.Len(t, byOwnerTeam, 2)
+	assert.Equal(t, "Platform", byOwnerTeam[0].Label)
+	assert.Equal(t, int64(3), byOwnerTeam[0].Remediated)
+	assert.Equal(t, 10.0, byOwnerTeam[0].MedianDays)
+	assert.Equal(t, 0.0, byOwnerTeam[1].AverageDays)
+
+	localized := services.TopAssetAnalytics{ByOwner: byOwner, ByOwnerTeam: byOwnerTeam}.Localized("ar")
+	assert.NotEqual(t, "Unassigned", localized.ByOwner[0].Label)
+	assert.Equal(t, "Alice", localized.ByOwner[1].Label)
+	assert.Equal(t, "Unassigned", byOwner[0].Label)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/cyops/cyops-backend/internal/models"
 	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/database"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/postgres"
@@ -61,6 +62,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	// Clean up any existing data
 	db.Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public;")
 	db.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\";")
+	db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm;")
 
 	// Run migrations
 	err = db.AutoMigrate(
@@ -86,6 +88,23 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	return db
 }
 
+// setupSchemaDB creates a test database with every model migrated, as the server does, and a
+// seeded user. Services built without a db argument use it through database.DB.
+func setupSchemaDB(t *testing.T) (*gorm.DB, *models.User) {
+	db := setupTestDB(t)
+	if db == nil {
+		return nil, nil // Skipped
+	}
+	t.Cleanup(func() { cleanupTestDB(db) })
+	require.NoError(t, db.AutoMigrate(models.MigrationModels()...))
+
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = previous })
+
+	return db, seedTestData(t, db)
+}
+
 // cleanupTestDB cleans up the test database after tests
 func cleanupTestDB(db *gorm.DB) {
 	if db != nil {
@@ -511,7 +530,7 @@ func TestAssetServiceUpdate(t *testing.T) {
 		updates := map[string]interface{}{
 			"criticality": criticalityPtr(models.CriticalityCritical),
 		}
-		updated, err := assetService.Update(asset.ID.String(), updates)
+		updated, err := assetService.Update(asset.ID.String(), updates, uuid.Nil)
 		require.NoError(t, err)
 		assert.Equal(t, models.CriticalityCritical, *updated.Criticality)
 	})
@@ -522,7 +541,7 @@ func TestAssetServiceUpdate(t *testing.T) {
 			"location":    "New Data Center",
 			"department":  "Security",
 		}
-		updated, err := assetService.Update(asset.ID.String(), updates)
+		updated, err := assetService.Update(asset.ID.String(), updates, uuid.Nil)
 		require.NoError(t, err)
 		assert.Equal(t, "Updated description", updated.Description)
 		assert.Equal(t, "New Data Center", updated.Location)
@@ -533,7 +552,7 @@ func TestAssetServiceUpdate(t *testing.T) {
 		updates := map[string]interface{}{
 			"hostname": "renamed-server",
 		}
-		updated, err := assetService.Update(asset.ID.String(), updates)
+		updated, err := assetService.Update(asset.ID.String(), updates, uuid.Nil)
 		require.NoError(t, err)
 		assert.Equal(t, "renamed-server", updated.Hostname)
 	})
@@ -543,7 +562,7 @@ func TestAssetServiceUpdate(t *testing.T) {
 		updates := map[string]interface{}{
 			"description": "This should fail",
 		}
-		_, err := assetService.Update(randomID, updates)
+		_, err := assetService.Update(randomID, updates, uuid.Nil)
 		assert.Error(t, err, "Should return error for non-existent asset")
 	})
 }
@@ -568,7 +587,7 @@ func TestAssetServiceDelete(t *testing.T) {
 		require.NoError(t, assetService.Create(asset))
 
 		// Delete the asset
-		err := assetService.Delete(asset.ID.String())
+		err := assetService.Delete(asset.ID.String(), uuid.Nil)
 		require.NoError(t, err)
 
 		// Verify it's soft deleted (not in normal queries)
@@ -584,7 +603,7 @@ func TestAssetServiceDelete(t *testing.T) {
 
 	t.Run("delete non-existent asset", func(t *testing.T) {
 		randomID := uuid.New().String()
-		err := assetService.Delete(randomID)
+		err := assetService.Delete(randomID, uuid.Nil)
 		assert.Error(t, err, "Should return error for non-existent asset")
 	})
 }
@@ -636,3 +655,45 @@ func TestAssetServiceGetStats(t *testing.T) {
 		assert.Equal(t, 1, stats.BySystemType["WORKSTATION"])
 	})
 }
+
+func TestAssetServiceCheckDuplicate(t *testing.T) {
+	db := setupTestDB(t)
+	if db == nil {
+		return // Skipped
+	}
+	defer cleanupTestDB(db)
+
+	seedTestData(t, db)
+	assetService := services.NewAssetService(db)
+
+	assets := []models.AffectedSystem{
+		{Hostname: "dup-web1", IPAddress: "192.168.70.1", AssetID: "WEB-SERVER-001", SystemType: models.SystemTypeServer, Environment: models.EnvProduction, Status: models.StatusActive},
+		{Hostname: "dup-db1", IPAddress: "192.168.70.2", AssetID: "DATABASE-PRIMARY", SystemType: models.SystemTypeServer, Environment: models.EnvProduction, Status: models.StatusActive},
+	}
+	for i := range assets {
+		require.NoError(t, assetService.Create(&assets[i]))
+	}
+
+	t.Run("exact IP match", func(t *testing.T) {
+		matches, err := assetService.CheckDuplicate("", "192.168.70.2", "", 0)
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.True(t, matches[0].MatchedOnIP)
+		assert.Equal(t, 100.0, matches[0].Similarity)
+	})
+
+	t.Run("similar asset ID", func(t *testing.T) {
+		matches, err := assetService.CheckDuplicate("web-server-002", "", "", 60)
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, assets[0].ID, matches[0].Asset.ID)
+		assert.True(t, matches[0].MatchedOnName)
+		assert.GreaterOrEqual(t, matches[0].Similarity, 60.0)
+	})
+
+	t.Run("dissimilar asset ID", func(t *testing.T) {
+		matches, err := assetService.CheckDuplicate("MAIL-GATEWAY", "", "", 80)
+		require.NoError(t, err)
+		assert.Empty(t, matches)
+	})
+}
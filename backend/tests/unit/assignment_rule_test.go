@@ -0,0 +1,90 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAssignmentRule(t *testing.T) {
+	analyst := uuid.New()
+	tests := []struct {
+		name    string
+		rule    models.AssignmentRule
+		wantErr string
+	}{
+		{"valid cve pattern", models.AssignmentRule{Name: "Log4j", CVEPattern: "CVE-2021-44*", AssignToID: &analyst}, ""},
+		{"valid asset owner", models.AssignmentRule{Name: "Owners", AssignAssetOwner: true}, ""},
+		{"missing name", models.AssignmentRule{CVEPattern: "CVE-*", AssignToID: &analyst}, "name is required"},
+		{"invalid environment", models.AssignmentRule{Name: "x", Environment: "QA", AssignToID: &analyst}, "invalid environment"},
+		{"invalid tag", models.AssignmentRule{Name: "x", AssetTag: "PCI scope", AssignToID: &analyst}, "invalid asset_tag"},
+		{"invalid pattern", models.AssignmentRule{Name: "x", CVEPattern: "CVE-[", AssignToID: &analyst}, "invalid cve_pattern"},
+		{"no criteria", models.AssignmentRule{Name: "x", AssignToID: &analyst}, "at least one criterion"},
+		{"no assignee", models.AssignmentRule{Name: "x", Environment: models.EnvProduction}, "assign_to_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := services.ValidateAssignmentRule(&tt.rule)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAssignmentMatcher(t *testing.T) {
+	analyst, owner, ownerTeam, team := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	rules := []models.AssignmentRule{
+		{BaseModel: models.BaseModel{ID: uuid.New()}, Name: "disabled", Enabled: false, CVEPattern: "CVE-*", AssignToID: &analyst},
+		{BaseModel: models.BaseModel{ID: uuid.New()}, Name: "pci", Enabled: true, AssetTag: "pci", Environment: models.EnvProduction, AssignTeamID: &team},
+		{BaseModel: models.BaseModel{ID: uuid.New()}, Name: "owners", Enabled: true, AssignAssetOwner: true},
+		{BaseModel: models.BaseModel{ID: uuid.New()}, Name: "log4j", Enabled: true, CVEPattern: "cve-2021-44*", AssignToID: &analyst},
+	}
+	matcher := services.NewAssignmentMatcher(rules)
+	assert.Equal(t, 3, matcher.Len())
+
+	// Asset criteria must all match the same asset
+	split := services.AssignmentTarget{Assets: []services.AssignmentAsset{
+		{ID: uuid.New(), Environment: models.EnvProduction},
+		{ID: uuid.New(), Environment: models.EnvStaging, Tags: []string{"pci"}},
+	}}
+	assert.Nil(t, matcher.Match(split))
+
+	pci := services.AssignmentTarget{Assets: []services.AssignmentAsset{
+		{ID: uuid.New(), Environment: models.EnvProduction, Tags: []string{"internet", "pci"}, OwnerID: &owner},
+	}}
+	assignment := matcher.Match(pci)
+	require.NotNil(t, assignment)
+	assert.Equal(t, "pci", assignment.Rule.Name)
+	assert.Equal(t, &team, assignment.OwnerTeamID)
+	assert.Nil(t, assignment.AssignedToID)
+
+	// Asset owner rules take the owner and owner team of the first owned asset
+	owned := services.AssignmentTarget{CVEID: "CVE-2021-44228", Assets: []services.AssignmentAsset{
+		{ID: uuid.New(), Environment: models.EnvTest},
+		{ID: uuid.New(), Environment: models.EnvTest, OwnerID: &owner, OwnerTeamID: &ownerTeam},
+	}}
+	assignment = matcher.Match(owned)
+	require.NotNil(t, assignment)
+	assert.Equal(t, "owners", assignment.Rule.Name)
+	assert.Equal(t, &owner, assignment.AssignedToID)
+	assert.Equal(t, &ownerTeam, assignment.OwnerTeamID)
+
+	// Without an owned asset the next rule matches the CVE case-insensitively
+	owned.Assets = owned.Assets[:1]
+	assignment = matcher.Match(owned)
+	require.NotNil(t, assignment)
+	assert.Equal(t, "log4j", assignment.Rule.Name)
+	assert.Equal(t, &analyst, assignment.AssignedToID)
+
+	assert.Nil(t, matcher.Match(services.AssignmentTarget{CVEID: "CVE-2022-0001"}))
+}
@@ -0,0 +1,30 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAttachmentIntegrity(t *testing.T) {
+	data := []byte("screenshot bytes")
+	recorded := "01c3ff201b261a9d41650c8cfa4386af4b68568c27f666212d86f708a188d4b6" // sha256 of data
+
+	status, actual := services.CheckAttachmentIntegrity(recorded, data, nil)
+	assert.Equal(t, services.AttachmentIntact, status)
+	assert.Equal(t, recorded, actual)
+
+	status, actual = services.CheckAttachmentIntegrity(recorded, []byte("screenshot bytes, edited"), nil)
+	assert.Equal(t, services.AttachmentModified, status)
+	assert.NotEqual(t, recorded, actual)
+
+	status, actual = services.CheckAttachmentIntegrity("", data, nil)
+	assert.Equal(t, services.AttachmentUnhashed, status)
+	assert.Equal(t, recorded, actual)
+
+	status, actual = services.CheckAttachmentIntegrity(recorded, nil, storage.ErrNotFound)
+	assert.Equal(t, services.AttachmentMissing, status)
+	assert.Empty(t, actual)
+}
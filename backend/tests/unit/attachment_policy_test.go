@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentPolicyCheckAttachment(t *testing.T) {
+	policy := services.DefaultAttachmentPolicy()
+
+	assert.NoError(t, policy.CheckAttachment("PROOF", "image/png", 1024))
+	assert.NoError(t, policy.CheckAttachment("REPORT", "application/pdf", 50*1024*1024))
+
+	tests := []struct {
+		name     string
+		category string
+		mimeType string
+		size     int64
+		wantErr  string
+	}{
+		{"too large", "PROOF", "image/png", 11 * 1024 * 1024, "maximum allowed size of 10 MB for PROOF"},
+		{"disallowed type", "PROOF", "application/zip", 1024, "file type application/zip is not allowed for PROOF"},
+		{"report must be pdf", "REPORT", "image/png", 1024, "not allowed for REPORT"},
+		{"unknown category", "SECRET", "image/png", 1024, "invalid attachment category"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.CheckAttachment(tt.category, tt.mimeType, tt.size)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.True(t, services.IsAttachmentPolicyViolation(err))
+			}
+		})
+	}
+}
+
+func TestValidateAttachmentPolicy(t *testing.T) {
+	t.Run("normalizes and keeps defaults for other categories", func(t *testing.T) {
+		policy := services.AttachmentPolicy{Categories: map[string]services.AttachmentCategoryPolicy{
+			" proof ": {MaxSizeBytes: 2048, AllowedMimeTypes: []string{"IMAGE/*", "image/png", " text/plain "}},
+		}}
+		require.NoError(t, services.ValidateAttachmentPolicy(&policy))
+
+		assert.Equal(t, services.AttachmentCategoryPolicy{
+			MaxSizeBytes:     2048,
+			AllowedMimeTypes: []string{"image/*", "image/png", "text/plain"},
+		}, policy.Categories["PROOF"])
+		assert.Equal(t, services.DefaultAttachmentPolicy().Categories["REPORT"], policy.Categories["REPORT"])
+
+		// Wildcards allow a whole family
+		assert.NoError(t, policy.CheckAttachment("PROOF", "image/webp", 1024))
+		assert.Error(t, policy.CheckAttachment("PROOF", "image/webp", 4096))
+	})
+
+	tests := []struct {
+		name   string
+		limits services.AttachmentCategoryPolicy
+		key    string
+	}{
+		{"unknown category", services.AttachmentCategoryPolicy{MaxSizeBytes: 1, AllowedMimeTypes: []string{"image/png"}}, "ARCHIVE"},
+		{"zero size", services.AttachmentCategoryPolicy{AllowedMimeTypes: []string{"image/png"}}, "PROOF"},
+		{"no mime types", services.AttachmentCategoryPolicy{MaxSizeBytes: 1}, "PROOF"},
+		{"malformed mime type", services.AttachmentCategoryPolicy{MaxSizeBytes: 1, AllowedMimeTypes: []string{"png"}}, "PROOF"},
+		{"allow everything", services.AttachmentCategoryPolicy{MaxSizeBytes: 1, AllowedMimeTypes: []string{"*/*"}}, "PROOF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := services.AttachmentPolicy{Categories: map[string]services.AttachmentCategoryPolicy{tt.key: tt.limits}}
+			assert.Error(t, services.ValidateAttachmentPolicy(&policy))
+		})
+	}
+}
+
+func TestDetectAttachmentMimeType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	pdf := []byte("%PDF-1.7\n")
+	docx := []byte("PK\x03\x04\x14\x00\x06\x00")
+
+	tests := []struct {
+		name     string
+		data     []byte
+		declared string
+		want     string
+	}{
+		{"content wins over declared type", png, "application/pdf", "image/png"},
+		{"html posing as an image", []byte("<html><script>alert(1)</script>"), "image/png", "text/html"},
+		{"pdf", pdf, "", "application/pdf"},
+		{"office document", docx, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{"plain zip", docx, "application/zip", "application/zip"},
+		{"csv keeps declared text type", []byte("host,port\n10.0.0.1,22\n"), "text/csv; charset=utf-8", "text/csv"},
+		{"unknown binary keeps declared type", []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}, "application/msword", "application/msword"},
+		{"unknown binary cannot claim to be an image", []byte{0x00, 0x01, 0x02, 0x03}, "image/png", "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, services.DetectAttachmentMimeType(tt.data, tt.declared))
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditorShareTokenIsActive(t *testing.T) {
+	token := models.AuditorShareToken{ExpiresAt: time.Now().Add(time.Hour)}
+	assert.True(t, token.IsActive())
+
+	expired := models.AuditorShareToken{ExpiresAt: time.Now().Add(-time.Minute)}
+	assert.False(t, expired.IsActive())
+
+	revokedAt := time.Now()
+	revoked := models.AuditorShareToken{ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt}
+	assert.False(t, revoked.IsActive())
+}
+
+func TestAuditorShareIssueTokenValidation(t *testing.T) {
+	service := services.NewAuditorShareService(nil)
+	assessmentID, userID := uuid.New(), uuid.New()
+
+	_, err := service.IssueToken(assessmentID, services.IssueAuditorTokenRequest{AuditorName: "  "}, userID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "required")
+
+	_, err = service.IssueToken(assessmentID, services.IssueAuditorTokenRequest{AuditorName: "Jane Auditor", AuditorEmail: "not-an-email"}, userID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid auditor_email")
+
+	for _, days := range []int{-1, 91} {
+		_, err = service.IssueToken(assessmentID, services.IssueAuditorTokenRequest{AuditorName: "Jane Auditor", ExpiresInDays: days}, userID)
+		require.Error(t, err, "expires_in_days %d", days)
+		assert.Contains(t, err.Error(), "invalid expires_in_days")
+	}
+}
+
+func TestAuditorShareAuthenticateRejectsForeignTokens(t *testing.T) {
+	service := services.NewAuditorShareService(nil)
+	for _, secret := range []string{"", "kfm_abcdef", "Bearer cya_abcdef"} {
+		_, err := service.Authenticate(secret)
+		assert.ErrorIs(t, err, services.ErrAuditorTokenInvalid, "token %q", secret)
+	}
+}
@@ -0,0 +1,33 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/awsauth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignerGetVanilla(t *testing.T) {
+	// "get-vanilla" from the AWS Signature Version 4 test suite
+	signer := awsauth.Signer{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         "service",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	canonicalHeaders, signedHeaders := awsauth.CanonicalHeaders(map[string]string{
+		"x-amz-date": now.Format(awsauth.TimeFormat),
+		"host":       "example.amazonaws.com",
+	})
+	assert.Equal(t, "host:example.amazonaws.com\nx-amz-date:20150830T123600Z\n", canonicalHeaders)
+	assert.Equal(t, "host;x-amz-date", signedHeaders)
+
+	canonicalRequest := strings.Join([]string{"GET", "/", "", canonicalHeaders, signedHeaders, awsauth.SHA256Hex(nil)}, "\n")
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		signer.Authorization(now, signedHeaders, canonicalRequest))
+}
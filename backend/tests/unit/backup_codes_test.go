@@ -0,0 +1,44 @@
+package unit
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cyops/cyops-backend/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateBackupCodes(t *testing.T) {
+	codes, err := auth.GenerateBackupCodes(auth.BackupCodeCount)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	format := regexp.MustCompile(`^[2-9A-HJ-NP-Z]{5}-[2-9A-HJ-NP-Z]{5}$`)
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Regexp(t, format, code)
+		assert.True(t, auth.IsBackupCodeFormat(code))
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+}
+
+func TestBackupCodeFormatAndHashing(t *testing.T) {
+	// TOTP codes are not backup codes; legacy 8-hex-character codes are
+	assert.False(t, auth.IsBackupCodeFormat("123456"))
+	assert.True(t, auth.IsBackupCodeFormat("ab12-cd34"))
+	assert.False(t, auth.IsBackupCodeFormat("ABCDE-FGHI!"))
+
+	codes, err := auth.GenerateBackupCodes(1)
+	require.NoError(t, err)
+	hash, err := auth.HashBackupCode(codes[0])
+	require.NoError(t, err)
+	assert.NotContains(t, hash, codes[0])
+
+	// Case and separators do not matter when a user types the code back
+	typed := strings.ToLower(strings.ReplaceAll(codes[0], "-", " "))
+	assert.True(t, auth.CheckBackupCode(typed, hash))
+	assert.False(t, auth.CheckBackupCode("ZZZZZ-ZZZZZ", hash))
+}
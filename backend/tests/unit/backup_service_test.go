@@ -0,0 +1,56 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/cyops/cyops-backend/pkg/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRestoreDatabase(t *testing.T) {
+	for _, name := range []string{"cyops_restore", "_restore", "restore_2026_10_17"} {
+		assert.NoError(t, services.ValidateRestoreDatabase(name), name)
+	}
+
+	for _, name := range []string{"", "Restore", "2026_restore", "cyops-restore", "cyops; DROP DATABASE cyops", "db name"} {
+		err := services.ValidateRestoreDatabase(name)
+		if assert.Error(t, err, name) {
+			assert.Contains(t, err.Error(), "invalid target database")
+		}
+	}
+}
+
+func TestBackupConfigDSN(t *testing.T) {
+	cfg := services.BackupConfig{
+		DBHost:     "postgres",
+		DBPort:     "5432",
+		DBUser:     "cyops",
+		DBPassword: "secret",
+		DBName:     "cyops",
+		DBSSLMode:  "disable",
+	}
+	assert.Equal(t, "host=postgres port=5432 user=cyops password=secret dbname=cyops_restore sslmode=disable", cfg.DSN("cyops_restore"))
+}
+
+func TestBackupStorageConfig(t *testing.T) {
+	cfg := &config.Config{
+		BackupStorage:           "s3",
+		BackupDir:               "/app/backups",
+		BackupS3Endpoint:        "http://minio:9000",
+		BackupS3Bucket:          "backups",
+		BackupS3PathStyle:       true,
+		BackupS3AccessKeyID:     "key",
+		BackupS3SecretAccessKey: "secret",
+	}
+	storageCfg := cfg.BackupStorageConfig()
+	assert.Equal(t, storage.DriverS3, storageCfg.Driver)
+	assert.Equal(t, "/app/backups", storageCfg.Local.Root)
+	assert.Equal(t, "backups", storageCfg.S3.Bucket)
+	assert.True(t, storageCfg.S3.PathStyle)
+
+	store, err := storage.New(storageCfg)
+	assert.NoError(t, err)
+	assert.Equal(t, storage.DriverS3, store.Driver())
+}
@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testBodyLimitRules = []middleware.BodyLimitRule{
+	{Method: fiber.MethodPost, Path: "/vulnerabilities/import/nessus", Limit: 1024},
+	{Method: fiber.MethodPost, Path: "/vulnerabilities/:id/attachments", Limit: 2048},
+}
+
+func TestBodyLimitFor(t *testing.T) {
+	assert.Equal(t, int64(1024), middleware.BodyLimitFor("POST", "/api/v1/vulnerabilities/import/nessus", 100, testBodyLimitRules))
+	assert.Equal(t, int64(1024), middleware.BodyLimitFor("POST", "/api/v2/vulnerabilities/import/nessus/", 100, testBodyLimitRules))
+	assert.Equal(t, int64(2048), middleware.BodyLimitFor("POST", "/api/v1/vulnerabilities/123/attachments", 100, testBodyLimitRules))
+	assert.Equal(t, int64(100), middleware.BodyLimitFor("PUT", "/api/v1/vulnerabilities/import/nessus", 100, testBodyLimitRules))
+	assert.Equal(t, int64(100), middleware.BodyLimitFor("POST", "/api/v1/vulnerabilities/123/attachments/x", 100, testBodyLimitRules))
+	assert.Equal(t, int64(100), middleware.BodyLimitFor("POST", "/health", 100, testBodyLimitRules))
+}
+
+func TestRequestBodyLimit(t *testing.T) {
+	app := fiber.New(fiber.Config{BodyLimit: 100, StreamRequestBody: true})
+	app.Use(middleware.RequestBodyLimit(100, testBodyLimitRules))
+	echo := func(c *fiber.Ctx) error {
+		return c.SendString(string(c.Body()))
+	}
+	app.Post("/api/v1/vulnerabilities/import/nessus", echo)
+	app.Post("/api/v1/vulnerabilities", echo)
+
+	// A body over the default limit is refused before the handler reads it
+	resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/vulnerabilities", strings.NewReader(strings.Repeat("a", 101))))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	// An upload route accepts more, streaming what exceeds the server's buffer
+	body := strings.Repeat("b", 1000)
+	resp, err = app.Test(httptest.NewRequest("POST", "/api/v1/vulnerabilities/import/nessus", strings.NewReader(body)))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	received, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, body, string(received))
+
+	resp, err = app.Test(httptest.NewRequest("POST", "/api/v1/vulnerabilities/import/nessus", strings.NewReader(strings.Repeat("b", 1025))))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+
+	// Chunked bodies have no length to check up front
+	req := httptest.NewRequest("POST", "/api/v1/vulnerabilities", io.MultiReader(bytes.NewReader([]byte("{}"))))
+	req.ContentLength = -1
+	req.TransferEncoding = []string{"chunked"}
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusLengthRequired, resp.StatusCode)
+}
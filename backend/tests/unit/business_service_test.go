@@ -0,0 +1,37 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBusinessService(t *testing.T) {
+	tests := []struct {
+		name    string
+		service models.BusinessService
+		wantErr string
+	}{
+		{"valid", models.BusinessService{Name: "Payments", Tier: models.BusinessTier1}, ""},
+		{"missing name", models.BusinessService{Tier: models.BusinessTier2}, "name is required"},
+		{"long name", models.BusinessService{Name: strings.Repeat("a", 256), Tier: models.BusinessTier2}, "invalid name"},
+		{"unknown tier", models.BusinessService{Name: "Payments", Tier: "TIER_9"}, "invalid tier"},
+		{"missing tier", models.BusinessService{Name: "Payments"}, "invalid tier"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := services.ValidateBusinessService(&tt.service)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestRedis connects to the test Redis, skipping the test when it is not available
+func setupTestRedis(t *testing.T) *redis.Client {
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		t.Skip("Skipping test: Redis not available")
+		return nil
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestCacheInvalidationsBatchedUntilFlush(t *testing.T) {
+	// Dry-run writes fire the callbacks without a database; Redis is unreachable,
+	// so a flush that has groups to invalidate fails while one with none succeeds
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer client.Close()
+	ctx := context.Background()
+
+	cache := services.NewCacheService(client, time.Minute)
+	require.NoError(t, cache.RegisterInvalidationCallbacks(db))
+
+	db.Create(&models.User{Email: "unrelated@example.com"})
+	assert.NoError(t, cache.FlushInvalidations(ctx), "users are not a cache source")
+
+	db.Create(&models.AffectedSystem{Hostname: "web-01"})
+	assert.Error(t, cache.FlushInvalidations(ctx))
+	assert.Error(t, cache.FlushInvalidations(ctx), "groups stay dirty until a flush succeeds")
+}
+
+func TestCachedAssetStatsRefreshAfterFlush(t *testing.T) {
+	client := setupTestRedis(t)
+	if client == nil {
+		return
+	}
+	db, _ := setupSchemaDB(t)
+	if db == nil {
+		return
+	}
+	ctx := context.Background()
+
+	cache := services.NewCacheService(client, time.Minute)
+	require.NoError(t, cache.RegisterInvalidationCallbacks(db))
+	// Drop entries left by earlier runs
+	require.NoError(t, cache.InvalidateGroups(ctx, services.CacheGroupAssetStats))
+	services.SetActiveCache(cache)
+	t.Cleanup(func() { services.SetActiveCache(nil) })
+
+	service := services.NewAssetService(db)
+	stats, err := service.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.TotalAssets)
+
+	require.NoError(t, db.Create(&models.AffectedSystem{Hostname: "web-01", SystemType: models.SystemTypeServer}).Error)
+	stats, err = service.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.TotalAssets, "served from the cache until invalidations are flushed")
+
+	require.NoError(t, cache.FlushInvalidations(ctx))
+	stats, err = service.GetStats()
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.TotalAssets)
+}
@@ -0,0 +1,42 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCriticalCloseApproval(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{"true", true, false},
+		{" TRUE ", true, false},
+		{"false", false, false},
+		{"1", true, false},
+		{"sometimes", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := services.ParseCriticalCloseApproval(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRequiresCloseApproval(t *testing.T) {
+	assert.True(t, services.RequiresCloseApproval(true, models.SeverityCritical, models.StatusClosed))
+	assert.False(t, services.RequiresCloseApproval(false, models.SeverityCritical, models.StatusClosed))
+	assert.False(t, services.RequiresCloseApproval(true, models.SeverityHigh, models.StatusClosed))
+	assert.False(t, services.RequiresCloseApproval(true, models.SeverityCritical, models.StatusResolved))
+}
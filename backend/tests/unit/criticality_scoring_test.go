@@ -0,0 +1,140 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreAssetCriticality(t *testing.T) {
+	weights := models.DefaultCriticalityWeights()
+
+	t.Run("sums environment, exposure and tags", func(t *testing.T) {
+		asset := models.AffectedSystem{
+			Environment: models.EnvProduction,
+			IPAddress:   "10.0.0.5",
+			IPAddresses: []string{"8.8.8.8"},
+			Tags:        []models.AssetTag{{Tag: "pii"}, {Tag: "build-server"}, {Tag: "crown-jewel"}},
+		}
+		score, factors := services.ScoreAssetCriticality(weights, asset, nil)
+
+		assert.Equal(t, 40+25+40+25, score)
+		assert.Equal(t, []services.CriticalityScoreFactor{
+			{Factor: "environment", Value: "PRODUCTION", Points: 40},
+			{Factor: "public_ip", Value: "8.8.8.8", Points: 25},
+			{Factor: "tag", Value: "crown-jewel", Points: 40},
+			{Factor: "tag", Value: "pii", Points: 25},
+		}, factors)
+		assert.Equal(t, models.CriticalityCritical, weights.Thresholds.Criticality(score))
+	})
+
+	t.Run("counts only the highest-weighted business service", func(t *testing.T) {
+		asset := models.AffectedSystem{Environment: models.EnvStaging}
+		businessServices := []models.BusinessService{
+			{Name: "Intranet", Tier: models.BusinessTier3},
+			{Name: "Payments", Tier: models.BusinessTier1},
+			{Name: "Archive", Tier: models.BusinessTier4},
+		}
+		score, factors := services.ScoreAssetCriticality(weights, asset, businessServices)
+
+		assert.Equal(t, 20+40, score)
+		assert.Equal(t, []services.CriticalityScoreFactor{
+			{Factor: "environment", Value: "STAGING", Points: 20},
+			{Factor: "business_service", Value: "Payments (TIER_1)", Points: 40},
+		}, factors)
+	})
+
+	t.Run("omits zero-weight factors", func(t *testing.T) {
+		asset := models.AffectedSystem{Environment: models.EnvTest, IPAddress: "192.168.1.10"}
+		score, factors := services.ScoreAssetCriticality(weights, asset, nil)
+
+		assert.Equal(t, 0, score)
+		assert.Empty(t, factors)
+		assert.Equal(t, models.CriticalityLow, weights.Thresholds.Criticality(score))
+	})
+
+	t.Run("never scores below zero", func(t *testing.T) {
+		custom := models.CriticalityWeights{
+			Tags:       map[string]int{"sandbox": -50},
+			Thresholds: weights.Thresholds,
+		}
+		score, factors := services.ScoreAssetCriticality(custom, models.AffectedSystem{Tags: []models.AssetTag{{Tag: "sandbox"}}}, nil)
+
+		assert.Equal(t, 0, score)
+		assert.Len(t, factors, 1)
+	})
+}
+
+func TestCriticalityThresholds(t *testing.T) {
+	thresholds := models.CriticalityThresholds{Critical: 80, High: 55, Medium: 30}
+
+	assert.Equal(t, models.CriticalityCritical, thresholds.Criticality(80))
+	assert.Equal(t, models.CriticalityHigh, thresholds.Criticality(79))
+	assert.Equal(t, models.CriticalityHigh, thresholds.Criticality(55))
+	assert.Equal(t, models.CriticalityMedium, thresholds.Criticality(30))
+	assert.Equal(t, models.CriticalityLow, thresholds.Criticality(29))
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"8.8.8.8", true},
+		{" 203.0.113.7 ", true},
+		{"2606:4700::1111", true},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.0.1", false},
+		{"127.0.0.1", false},
+		{"169.254.1.1", false},
+		{"100.64.0.1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"", false},
+		{"not-an-ip", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.public, services.IsPublicIP(tt.ip), tt.ip)
+	}
+}
+
+func TestValidateCriticalityWeights(t *testing.T) {
+	t.Run("normalizes keys", func(t *testing.T) {
+		weights := models.CriticalityWeights{
+			Environments:  map[string]int{" production ": 50},
+			Tags:          map[string]int{" PCI ": 30},
+			BusinessTiers: map[string]int{"tier_1": 45},
+			Thresholds:    models.CriticalityThresholds{Critical: 90, High: 60, Medium: 30},
+		}
+		require.NoError(t, services.ValidateCriticalityWeights(&weights))
+
+		assert.Equal(t, map[string]int{"PRODUCTION": 50}, weights.Environments)
+		assert.Equal(t, map[string]int{"pci": 30}, weights.Tags)
+		assert.Equal(t, map[string]int{"TIER_1": 45}, weights.BusinessTiers)
+	})
+
+	t.Run("rejects invalid weights", func(t *testing.T) {
+		valid := models.CriticalityThresholds{Critical: 90, High: 60, Medium: 30}
+		tests := []struct {
+			name    string
+			weights models.CriticalityWeights
+		}{
+			{"unordered thresholds", models.CriticalityWeights{Thresholds: models.CriticalityThresholds{Critical: 50, High: 60, Medium: 30}}},
+			{"negative medium threshold", models.CriticalityWeights{Thresholds: models.CriticalityThresholds{Critical: 90, High: 60, Medium: -1}}},
+			{"unknown environment", models.CriticalityWeights{Environments: map[string]int{"QA": 10}, Thresholds: valid}},
+			{"blank tag", models.CriticalityWeights{Tags: map[string]int{" ": 10}, Thresholds: valid}},
+			{"unknown business tier", models.CriticalityWeights{BusinessTiers: map[string]int{"GOLD": 10}, Thresholds: valid}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Error(t, services.ValidateCriticalityWeights(&tt.weights))
+			})
+		}
+	})
+}
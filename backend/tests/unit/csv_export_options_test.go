@@ -0,0 +1,72 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{",", ","},
+		{"comma", ","},
+		{";", ";"},
+		{"Semicolon", ";"},
+		{"tab", "\t"},
+		{"\t", "\t"},
+		{"pipe", "|"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			delimiter, err := services.NormalizeCSVDelimiter(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, delimiter)
+		})
+	}
+
+	_, err := services.NormalizeCSVDelimiter(":")
+	assert.Error(t, err, "Unsupported delimiter should be rejected")
+	_, err = services.NormalizeCSVDelimiter("")
+	assert.Error(t, err, "Empty delimiter should be rejected")
+	_, err = services.NormalizeCSVDelimiter(",,")
+	assert.Error(t, err, "Delimiters are a single character")
+}
+
+func TestPreferenceDelimiterFallsBackToComma(t *testing.T) {
+	assert.Equal(t, ';', (&models.UserPreference{CSVDelimiter: ";"}).Delimiter())
+	assert.Equal(t, '\t', (&models.UserPreference{CSVDelimiter: "\t"}).Delimiter())
+	assert.Equal(t, ',', (&models.UserPreference{CSVDelimiter: ""}).Delimiter())
+	assert.Equal(t, ',', (&models.UserPreference{CSVDelimiter: ";;"}).Delimiter())
+	assert.Equal(t, ',', (*models.UserPreference)(nil).Delimiter())
+}
+
+func TestNormalizeCSVEncoding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected models.CSVEncoding
+	}{
+		{"utf-8", models.CSVEncodingUTF8},
+		{"UTF8", models.CSVEncodingUTF8},
+		{"latin1", models.CSVEncodingISO88591},
+		{"ISO_8859_15", models.CSVEncodingISO885915},
+		{"cp1252", models.CSVEncodingWindows1252},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			enc, err := services.NormalizeCSVEncoding(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, enc)
+		})
+	}
+
+	_, err := services.NormalizeCSVEncoding("utf-16")
+	assert.Error(t, err, "Unsupported encoding should be rejected")
+}
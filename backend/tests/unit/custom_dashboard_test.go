@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDashboardWidgetsDefaults(t *testing.T) {
+	widgets, err := services.NormalizeDashboardWidgets([]models.DashboardWidget{
+		{ID: " donut ", Type: models.WidgetSeverityDonut, Options: models.DashboardWidgetOptions{Days: 10, Environment: "production"}},
+		{Type: models.WidgetTrendLine, Options: models.DashboardWidgetOptions{Environment: models.EnvStaging}},
+		{Type: models.WidgetTopRiskyAssets},
+		{Type: models.WidgetSLAStatus, Layout: &models.DashboardWidgetLayout{X: 0, Y: 2, Width: 4, Height: 2}},
+	})
+	require.NoError(t, err)
+	require.Len(t, widgets, 4)
+
+	assert.Equal(t, "donut", widgets[0].ID)
+	assert.Equal(t, 0, widgets[0].Options.Days, "unused options are dropped")
+	assert.Equal(t, models.EnvProduction, widgets[0].Options.Environment)
+
+	assert.NotEmpty(t, widgets[1].ID)
+	assert.Equal(t, services.DefaultTrendLineDays, widgets[1].Options.Days)
+	assert.Empty(t, widgets[1].Options.Environment)
+
+	assert.Equal(t, services.DefaultTopRiskyAssetLimit, widgets[2].Options.Limit)
+	assert.NotEqual(t, widgets[1].ID, widgets[2].ID)
+}
+
+func TestNormalizeDashboardWidgetsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		widgets []models.DashboardWidget
+		wantErr string
+	}{
+		{"unknown type", []models.DashboardWidget{{Type: "pie"}}, "invalid type"},
+		{"duplicate id", []models.DashboardWidget{{ID: "a", Type: models.WidgetSLAStatus}, {ID: "a", Type: models.WidgetSeverityDonut}}, "duplicate widget id"},
+		{"days out of range", []models.DashboardWidget{{Type: models.WidgetTrendLine, Options: models.DashboardWidgetOptions{Days: services.MetricsBackfillDays + 1}}}, "invalid days"},
+		{"limit out of range", []models.DashboardWidget{{Type: models.WidgetTopRiskyAssets, Options: models.DashboardWidgetOptions{Limit: -1}}}, "invalid limit"},
+		{"invalid environment", []models.DashboardWidget{{Type: models.WidgetSeverityDonut, Options: models.DashboardWidgetOptions{Environment: "QA"}}}, "invalid environment"},
+		{"invalid layout", []models.DashboardWidget{{Type: models.WidgetSLAStatus, Layout: &models.DashboardWidgetLayout{Width: 0, Height: 1}}}, "invalid layout"},
+		{"too many widgets", make([]models.DashboardWidget, services.MaxDashboardWidgets+1), "at most"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := services.NormalizeDashboardWidgets(tt.widgets)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestSummarizeSLAStatus(t *testing.T) {
+	status := services.SummarizeSLAStatus([]services.SLAStatusRow{
+		{Severity: models.SeverityHigh, Open: 8, Breached: 2, DueSoon: 1},
+		{Severity: models.SeverityCritical, Open: 2, Breached: 2},
+	})
+
+	require.Len(t, status.Severities, 4)
+	assert.Equal(t, models.SeverityCritical, status.Severities[0].Severity)
+	assert.Equal(t, int64(0), status.Severities[0].WithinSLA)
+	assert.Equal(t, float64(0), status.Severities[0].ComplianceRate)
+
+	high := status.Severities[1]
+	assert.Equal(t, services.SLATargetDays[models.SeverityHigh], high.TargetDays)
+	assert.Equal(t, int64(6), high.WithinSLA)
+	assert.Equal(t, int64(1), high.DueSoon)
+	assert.Equal(t, 75.0, high.ComplianceRate)
+
+	assert.Equal(t, models.SeverityLow, status.Severities[3].Severity)
+	assert.Equal(t, 100.0, status.Severities[3].ComplianceRate, "no open vulnerabilities is fully compliant")
+
+	assert.Equal(t, int64(10), status.Open)
+	assert.Equal(t, int64(4), status.Breached)
+	assert.Equal(t, 60.0, status.ComplianceRate)
+}
@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/cwe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCWENormalize(t *testing.T) {
+	for raw, want := range map[string]string{
+		"79":       "CWE-79",
+		"CWE-79":   "CWE-79",
+		"cwe-079":  "CWE-79",
+		"CWE:89":   "CWE-89",
+		" CWE 22 ": "CWE-22",
+	} {
+		id, ok := cwe.Normalize(raw)
+		assert.True(t, ok, raw)
+		assert.Equal(t, want, id, raw)
+	}
+
+	for _, raw := range []string{"", "CWE-", "NVD-CWE-Other", "NVD-CWE-noinfo", "CWE-0", "CWE-7a", "XSS", "1234567"} {
+		_, ok := cwe.Normalize(raw)
+		assert.False(t, ok, raw)
+	}
+}
+
+func TestCWENormalizeAndParseIDs(t *testing.T) {
+	assert.Equal(t, []string{"CWE-79", "CWE-89"}, cwe.NormalizeIDs([]string{"79", "NVD-CWE-Other", "CWE-89", "cwe-79"}))
+	assert.Empty(t, cwe.NormalizeIDs([]string{"NVD-CWE-noinfo"}))
+
+	ids, err := cwe.ParseIDs([]string{"CWE-787", "787", "cwe:20"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CWE-787", "CWE-20"}, ids)
+
+	_, err = cwe.ParseIDs([]string{"CWE-79", "XSS"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid CWE ID 'XSS'")
+}
+
+func TestCWELookupAndSearch(t *testing.T) {
+	weakness, ok := cwe.Lookup("79")
+	require.True(t, ok)
+	assert.Equal(t, "CWE-79", weakness.ID)
+	assert.Contains(t, weakness.Name, "Cross-site Scripting")
+	assert.NotEmpty(t, weakness.Description)
+
+	// Valid IDs outside the embedded dataset have no entry
+	_, ok = cwe.Lookup("CWE-999999")
+	assert.False(t, ok)
+	assert.Empty(t, cwe.Name("CWE-999999"))
+	assert.Equal(t, weakness.Name, cwe.Name("CWE-79"))
+
+	all := cwe.All()
+	require.NotEmpty(t, all)
+	assert.Len(t, cwe.Search(""), len(all))
+
+	exact := cwe.Search("cwe-89")
+	require.Len(t, exact, 1)
+	assert.Equal(t, "CWE-89", exact[0].ID)
+
+	for _, match := range cwe.Search("injection") {
+		assert.Contains(t, match.Name, "Injection")
+	}
+	assert.NotEmpty(t, cwe.Search("injection"))
+	assert.Empty(t, cwe.Search("no such weakness"))
+}
+
+func TestParseNessusFileCWEs(t *testing.T) {
+	vulns, err := services.NewNessusParserService().ParseNessusFile([]byte(`<?xml version="1.0"?>
+<NessusClientData_v2>
+  <Report name="scan">
+    <ReportHost name="10.0.0.5">
+      <HostProperties><tag name="host-ip">10.0.0.5</tag></HostProperties>
+      <ReportItem port="443" svc_name="www" protocol="tcp" severity="3" pluginID="142960" pluginName="SQL Injection" pluginFamily="CGI abuses">
+        <description>SQL injection</description>
+        <cwe>89</cwe>
+        <xref>CWE:20</xref>
+        <xref>OSVDB:1234</xref>
+        <xref>CWE:89</xref>
+        <risk_factor>High</risk_factor>
+      </ReportItem>
+    </ReportHost>
+  </Report>
+</NessusClientData_v2>`))
+	require.NoError(t, err)
+	require.Len(t, vulns, 1)
+	assert.Equal(t, []string{"CWE-89", "CWE-20"}, vulns[0].CWEIDs)
+	assert.Equal(t, "CGI abuses", vulns[0].PluginFamily)
+}
+
+func TestParsedVulnerabilitiesFromTenableCWEs(t *testing.T) {
+	var records []services.TenableVulnRecord
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"asset": {"ipv4": "10.0.0.7"},
+		 "plugin": {"id": 98115, "name": "Cross-Site Scripting", "xrefs": [{"type": "CWE", "id": "79"}, {"type": "OWASP", "id": "2021-A3"}, {"type": "cwe", "id": "80"}]},
+		 "port": {"port": 443, "protocol": "TCP"},
+		 "severity_id": 2, "state": "OPEN", "last_found": "2026-09-01T10:00:00Z"}
+	]`), &records))
+
+	vulns := services.ParsedVulnerabilitiesFromTenable(records)
+	require.Len(t, vulns, 1)
+	assert.Equal(t, []string{"CWE-79", "CWE-80"}, vulns[0].CWEIDs)
+}
@@ -0,0 +1,75 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDataRetentionSettings(t *testing.T) {
+	settings, err := services.ParseDataRetentionSettings(`{"enabled": true, "auth_event_days": 90, "session_days": 30, "report_export_days": 365, "deleted_item_days": 30}`)
+	require.NoError(t, err)
+	assert.True(t, settings.Enabled)
+	assert.Equal(t, 90, settings.AuthEventDays)
+	assert.Equal(t, 30, settings.SessionDays)
+	assert.Equal(t, 365, settings.ReportExportDays)
+	assert.Equal(t, 30, settings.DeletedItemDays)
+	assert.Zero(t, settings.ResolvedVulnerabilityDays, "unset periods keep data forever")
+	assert.Zero(t, settings.AuditLogDays)
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{"malformed", `{"enabled": "yes"}`, "invalid data retention settings"},
+		{"negative", `{"audit_log_days": -1}`, "audit_logs"},
+		{"too long", `{"resolved_vulnerability_days": 40000}`, "resolved_vulnerabilities"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := services.ParseDataRetentionSettings(tt.value)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestRetentionRunCategoriesRoundTrip(t *testing.T) {
+	cutoff := time.Date(2026, 7, 19, 0, 0, 0, 0, time.UTC)
+	run := &models.RetentionRun{
+		Categories: []models.RetentionCategoryResult{
+			{Category: models.RetentionAuthEvents, RetentionDays: 90, Cutoff: cutoff, Matched: 12, Deleted: 12},
+			{Category: models.RetentionReportExports, RetentionDays: 90, Cutoff: cutoff, Matched: 2, Error: "failed to delete 2 report file(s)"},
+		},
+	}
+	require.NoError(t, run.BeforeSave(nil))
+
+	stored := &models.RetentionRun{Results: run.Results}
+	require.NoError(t, stored.AfterFind(nil))
+	require.Len(t, stored.Categories, 2)
+	assert.Equal(t, models.RetentionAuthEvents, stored.Categories[0].Category)
+	assert.Equal(t, int64(12), stored.Categories[0].Deleted)
+	assert.True(t, cutoff.Equal(stored.Categories[1].Cutoff))
+	assert.NotEmpty(t, stored.Categories[1].Error)
+
+	empty := &models.RetentionRun{}
+	require.NoError(t, empty.BeforeSave(nil))
+	assert.Equal(t, "[]", empty.Results)
+}
+
+func TestTrashRejectsUnknownItemTypes(t *testing.T) {
+	cleanup := services.NewCleanupService()
+
+	_, _, err := cleanup.ListTrash("report", 1, 20)
+	assert.ErrorContains(t, err, "invalid trash item type")
+
+	err = cleanup.RestoreTrashItem("user", uuid.New(), uuid.New())
+	assert.ErrorContains(t, err, "invalid trash item type")
+}
@@ -0,0 +1,40 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabaseConnectionConfig(t *testing.T) {
+	cfg := &config.Config{
+		GoEnv:                    "production",
+		DBHost:                   "postgres",
+		DBPort:                   "5432",
+		DBUser:                   "cyops",
+		DBPassword:               "secret",
+		DBName:                   "cyops",
+		DBSSLMode:                "require",
+		DBMaxOpenConns:           50,
+		DBMaxIdleConns:           10,
+		DBConnMaxLifetimeMinutes: 30,
+		DBConnMaxIdleTimeMinutes: 2,
+		DBSlowQueryMs:            500,
+		DBCriticalQueryMs:        0,
+	}
+
+	conn := cfg.DatabaseConnection()
+	assert.Equal(t, cfg.DatabaseDSN(), conn.DSN)
+	assert.False(t, conn.IsDevelopment)
+	assert.Equal(t, 50, conn.MaxOpenConns)
+	assert.Equal(t, 10, conn.MaxIdleConns)
+	assert.Equal(t, 30*time.Minute, conn.ConnMaxLifetime)
+	assert.Equal(t, 2*time.Minute, conn.ConnMaxIdleTime)
+	assert.Equal(t, 500*time.Millisecond, conn.SlowQueryThreshold)
+	assert.Zero(t, conn.CriticalQueryThreshold, "a zero threshold disables error level slow query logs")
+
+	cfg.GoEnv = "development"
+	assert.True(t, cfg.DatabaseConnection().IsDevelopment)
+}
@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/captcha"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDisclosureSubmission(t *testing.T) {
+	valid := func() services.DisclosureSubmission {
+		return services.DisclosureSubmission{
+			Title:             "  Stored XSS in the support portal ",
+			Description:       "The ticket subject is rendered without escaping.",
+			SuggestedSeverity: "high",
+			CVEID:             " cve-2024-12345 ",
+			ReporterEmail:     " Researcher@Example.com ",
+		}
+	}
+
+	req := valid()
+	require.NoError(t, services.NormalizeDisclosureSubmission(&req))
+	assert.Equal(t, "Stored XSS in the support portal", req.Title)
+	assert.Equal(t, models.SeverityHigh, req.SuggestedSeverity)
+	assert.Equal(t, "CVE-2024-12345", req.CVEID)
+	assert.Equal(t, "researcher@example.com", req.ReporterEmail)
+
+	anonymous := valid()
+	anonymous.ReporterEmail = ""
+	anonymous.SuggestedSeverity = ""
+	assert.NoError(t, services.NormalizeDisclosureSubmission(&anonymous))
+
+	tests := []struct {
+		name   string
+		mutate func(*services.DisclosureSubmission)
+		field  string
+	}{
+		{"missing title", func(r *services.DisclosureSubmission) { r.Title = " " }, "title"},
+		{"missing description", func(r *services.DisclosureSubmission) { r.Description = "" }, "description"},
+		{"short description", func(r *services.DisclosureSubmission) { r.Description = "xss" }, "description"},
+		{"long impact", func(r *services.DisclosureSubmission) { r.Impact = strings.Repeat("a", 10001) }, "impact"},
+		{"long target", func(r *services.DisclosureSubmission) { r.AffectedTarget = strings.Repeat("a", 501) }, "affected_target"},
+		{"bad email", func(r *services.DisclosureSubmission) { r.ReporterEmail = "not-an-email" }, "reporter_email"},
+		{"bad severity", func(r *services.DisclosureSubmission) { r.SuggestedSeverity = "URGENT" }, "suggested_severity"},
+		{"bad cve", func(r *services.DisclosureSubmission) { r.CVEID = "CVE-24-1" }, "cve_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.mutate(&req)
+			err := services.NormalizeDisclosureSubmission(&req)
+			var fieldErr *utils.FieldError
+			require.True(t, errors.As(err, &fieldErr), "expected a field error, got %v", err)
+			assert.Equal(t, tt.field, fieldErr.Field)
+		})
+	}
+}
+
+func TestCaptchaVerifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "shh", r.PostForm.Get("secret"))
+		assert.Equal(t, "203.0.113.7", r.PostForm.Get("remoteip"))
+		switch r.PostForm.Get("response") {
+		case "solved":
+			w.Write([]byte(`{"success":true}`))
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+		}
+	}))
+	defer server.Close()
+
+	verifier := captcha.NewVerifier(server.URL, "shh")
+	ctx := context.Background()
+
+	ok, err := verifier.Verify(ctx, "solved", "203.0.113.7")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = verifier.Verify(ctx, "forged", "203.0.113.7")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = verifier.Verify(ctx, "", "203.0.113.7")
+	require.NoError(t, err)
+	assert.False(t, ok, "an empty token is rejected without asking the provider")
+
+	_, err = verifier.Verify(ctx, "broken", "203.0.113.7")
+	assert.Error(t, err)
+}
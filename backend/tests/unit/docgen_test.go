@@ -0,0 +1,161 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/docgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocgenParse(t *testing.T) {
+	markup := strings.Join([]string{
+		"# Report",
+		"first line",
+		"second line",
+		"",
+		"- one",
+		"- two",
+		"| Ref | Title |",
+		"| F-01 | SQL injection |",
+		"```",
+		"# not a heading",
+		"```",
+		"![Login page](shot)",
+		"![Missing](unknown)",
+		"---",
+		`\# escaped`,
+	}, "\n")
+	img := docgen.Image{Data: []byte{0xff}, Width: 1, Height: 1}
+
+	doc, err := docgen.Parse("Title", markup, map[string]docgen.Image{"shot": img})
+	require.NoError(t, err)
+	assert.Equal(t, []docgen.Block{
+		docgen.Heading{Level: 1, Text: "Report"},
+		docgen.Paragraph{Text: "first line second line"},
+		docgen.List{Items: []string{"one", "two"}},
+		docgen.Table{Rows: [][]string{{"Ref", "Title"}, {"F-01", "SQL injection"}}},
+		docgen.Code{Lines: []string{"# not a heading"}},
+		docgen.Figure{Image: img, Caption: "Login page"},
+		docgen.PageBreak{},
+		docgen.Paragraph{Text: "# escaped"},
+	}, doc.Blocks)
+
+	for _, invalid := range []string{"#### too deep", "| a | b |\n| c |", "```\nunterminated", "![broken"} {
+		_, err := docgen.Parse("", invalid, nil)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestDocgenEscaping(t *testing.T) {
+	hostile := "# heading\n- item\n| cell |\n```\n![x](y)\n---"
+
+	doc, err := docgen.Parse("", docgen.EscapeText(hostile), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []docgen.Block{docgen.Paragraph{Text: "# heading - item | cell | ``` ![x](y) ---"}}, doc.Blocks)
+
+	doc, err = docgen.Parse("", "| A |\n| "+docgen.EscapeCell("a | b\nc")+" |", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []docgen.Block{docgen.Table{Rows: [][]string{{"A"}, {"a / b c"}}}}, doc.Blocks)
+
+	doc, err = docgen.Parse("", "```\n"+docgen.EscapeCode("curl x\n```\n# y")+"\n```", nil)
+	require.NoError(t, err)
+	require.Len(t, doc.Blocks, 1)
+	assert.Len(t, doc.Blocks[0].(docgen.Code).Lines, 3)
+}
+
+func TestDocgenNewImageConvertsToJPEG(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	src.Set(1, 1, color.NRGBA{R: 255, A: 128})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, src))
+
+	img, err := docgen.NewImage(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, 40, img.Width)
+	assert.Equal(t, 20, img.Height)
+	assert.Equal(t, []byte{0xff, 0xd8}, img.Data[:2])
+
+	_, err = docgen.NewImage([]byte("not an image"))
+	assert.Error(t, err)
+}
+
+func TestRenderAssessmentReport(t *testing.T) {
+	cvss := 9.8
+	content := services.AssessmentReportContent{
+		Title: "External Pentest (Q3)",
+		Assessment: models.Assessment{
+			Name:           "Perimeter review",
+			AssessmentType: models.AssessmentPenTest,
+			Status:         models.AssessmentCompleted,
+			AssessorName:   "Red Team",
+			StartDate:      time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Scope: []models.AffectedSystem{{Hostname: "web01", IPAddress: "10.0.0.5", SystemType: models.SystemType("SERVER")}},
+		Findings: []services.AssessmentReportFinding{{
+			Ref: "F-01",
+			Vulnerability: models.Vulnerability{
+				Title:            "SQL injection | login",
+				Description:      "# not a heading\nThe login form is injectable.",
+				Severity:         models.SeverityCritical,
+				CVSSScore:        &cvss,
+				StepsToReproduce: "curl -d \"user=' OR 1=1--\" https://web01/login",
+			},
+			Remediation: "Use parameterized queries.",
+		}},
+		GeneratedAt: time.Now(),
+		Branding:    services.NewReportBranding(nil, nil),
+	}
+
+	pdf, mimeType, err := services.RenderAssessmentReport(content, nil, services.ReportFormatPDF)
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", mimeType)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(pdf, []byte("%%EOF\n")))
+
+	docx, mimeType, err := services.RenderAssessmentReport(content, nil, services.ReportFormatDOCX)
+	require.NoError(t, err)
+	assert.Equal(t, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", mimeType)
+	archive, err := zip.NewReader(bytes.NewReader(docx), int64(len(docx)))
+	require.NoError(t, err)
+	var document string
+	for _, file := range archive.File {
+		if file.Name == "word/document.xml" {
+			rc, err := file.Open()
+			require.NoError(t, err)
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			document = string(data)
+		}
+	}
+	assert.Contains(t, document, "SQL injection / login")
+	assert.Contains(t, document, "# not a heading")
+	assert.Contains(t, document, "Penetration Test")
+
+	_, _, err = services.RenderAssessmentReport(content, nil, "html")
+	assert.Error(t, err)
+}
+
+func TestGenerateReportRequestValidate(t *testing.T) {
+	req := services.GenerateReportRequest{Format: " DOCX "}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, services.ReportFormatDOCX, req.Format)
+	assert.Equal(t, "Assessment Report", req.Title)
+
+	req = services.GenerateReportRequest{}
+	require.NoError(t, req.Validate())
+	assert.Equal(t, services.ReportFormatPDF, req.Format)
+
+	req = services.GenerateReportRequest{Format: "html"}
+	assert.Error(t, req.Validate())
+}
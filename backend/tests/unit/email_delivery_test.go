@@ -0,0 +1,212 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var deliveryMessage = mailer.Message{
+	From:    "CyOps <noreply@cyops.example>",
+	To:      "analyst@example.com",
+	Subject: "Verify your email",
+	HTML:    "<p>Hello</p>",
+}
+
+func TestSendGridSender(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/mail/send", r.URL.Path)
+		assert.Equal(t, "Bearer SG.key", r.Header.Get("Authorization"))
+		var payload map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "Verify your email", payload["subject"])
+		w.Header().Set("X-Message-Id", "sg-abc123")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender, err := mailer.New(mailer.Config{Provider: mailer.ProviderSendGrid, APIKey: "SG.key", Endpoint: server.URL})
+	require.NoError(t, err)
+	id, err := sender.Send(context.Background(), deliveryMessage)
+	require.NoError(t, err)
+	assert.Equal(t, "sg-abc123", id)
+}
+
+func TestMailgunSender(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/mg.example.com/messages", r.URL.Path)
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "api", user)
+		assert.Equal(t, "key-1", pass)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "analyst@example.com", r.PostForm.Get("to"))
+		w.Write([]byte(`{"id":"<20260101.1@mg.example.com>","message":"Queued. Thank you."}`))
+	}))
+	defer server.Close()
+
+	sender, err := mailer.New(mailer.Config{Provider: mailer.ProviderMailgun, APIKey: "key-1", Domain: "mg.example.com", Endpoint: server.URL})
+	require.NoError(t, err)
+	id, err := sender.Send(context.Background(), deliveryMessage)
+	require.NoError(t, err)
+	assert.Equal(t, "20260101.1@mg.example.com", id)
+}
+
+func TestSESSender(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/email/outbound-emails", r.URL.Path)
+		auth := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260101/eu-west-1/ses/aws4_request"), auth)
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), `"ConfigurationSetName":"cyops"`)
+		w.Write([]byte(`{"MessageId":"0100018c-ses"}`))
+	}))
+	defer server.Close()
+
+	sender, err := mailer.New(mailer.Config{
+		Provider:         mailer.ProviderSES,
+		AccessKeyID:      "AKIDEXAMPLE",
+		SecretAccessKey:  "secret",
+		ConfigurationSet: "cyops",
+		Region:           "eu-west-1",
+		Endpoint:         server.URL,
+		Now:              func() time.Time { return time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC) },
+	})
+	require.NoError(t, err)
+	id, err := sender.Send(context.Background(), deliveryMessage)
+	require.NoError(t, err)
+	assert.Equal(t, "0100018c-ses", id)
+}
+
+func TestSenderAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errors":[{"message":"The from address does not match a verified Sender Identity"}]}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	sender, err := mailer.New(mailer.Config{Provider: mailer.ProviderSendGrid, APIKey: "SG.key", Endpoint: server.URL})
+	require.NoError(t, err)
+	_, err = sender.Send(context.Background(), deliveryMessage)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+	assert.Contains(t, err.Error(), "verified Sender Identity")
+}
+
+func TestValidateEmailDeliverySettings(t *testing.T) {
+	valid := services.EmailDeliverySettings{Provider: " SendGrid ", APIKey: "SG.key", From: "CyOps <noreply@cyops.example>"}
+	require.NoError(t, services.ValidateEmailDeliverySettings(&valid))
+	assert.Equal(t, "sendgrid", valid.Provider)
+
+	for name, settings := range map[string]services.EmailDeliverySettings{
+		"unknown provider": {Provider: "postmark", APIKey: "x"},
+		"sendgrid key":     {Provider: "sendgrid"},
+		"mailgun domain":   {Provider: "mailgun", APIKey: "key-1"},
+		"ses credentials":  {Provider: "ses", AccessKeyID: "AKID"},
+		"smtp host":        {Provider: "smtp"},
+		"from address":     {Provider: "sendgrid", APIKey: "SG.key", From: "not an address"},
+		"endpoint scheme":  {Provider: "sendgrid", APIKey: "SG.key", Endpoint: "ftp://example.com"},
+	} {
+		settings := settings
+		assert.Error(t, services.ValidateEmailDeliverySettings(&settings), name)
+	}
+}
+
+func TestParseSendGridEvents(t *testing.T) {
+	events, err := mailer.ParseSendGridEvents([]byte(`[
+		{"email":"analyst@example.com","timestamp":1767268800,"event":"processed","sg_message_id":"sg-abc123.filter0001"},
+		{"email":"analyst@example.com","timestamp":1767268805,"event":"delivered","sg_message_id":"sg-abc123.filter0001","response":"250 OK"},
+		{"email":"analyst@example.com","timestamp":1767268900,"event":"open","sg_message_id":"sg-abc123.filter0001"},
+		{"email":"other@example.com","timestamp":1767268810,"event":"bounce","sg_message_id":"sg-def456.filter0002","reason":"550 5.1.1 User unknown"}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "sg-abc123", events[0].MessageID)
+	assert.Equal(t, mailer.StatusDelivered, events[0].Status)
+	assert.Equal(t, "250 OK", events[0].Reason)
+	assert.Equal(t, mailer.StatusBounced, events[1].Status)
+	assert.Equal(t, "550 5.1.1 User unknown", events[1].Reason)
+	assert.Equal(t, time.Unix(1767268810, 0).UTC(), events[1].OccurredAt)
+}
+
+func TestParseMailgunEvent(t *testing.T) {
+	events, err := mailer.ParseMailgunEvent([]byte(`{"signature":{},"event-data":{
+		"event":"failed","severity":"temporary","timestamp":1767268800.5,"recipient":"analyst@example.com",
+		"message":{"headers":{"message-id":"20260101.1@mg.example.com"}},
+		"delivery-status":{"message":"4.2.2 Mailbox full"}}}`))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "20260101.1@mg.example.com", events[0].MessageID)
+	assert.Equal(t, mailer.StatusDeferred, events[0].Status)
+	assert.Equal(t, "4.2.2 Mailbox full", events[0].Reason)
+
+	events, err = mailer.ParseMailgunEvent([]byte(`{"event-data":{"event":"opened","message":{"headers":{"message-id":"x"}}}}`))
+	require.NoError(t, err)
+	assert.Empty(t, events, "engagement events don't change the delivery status")
+}
+
+func TestParseSESEvent(t *testing.T) {
+	notification := func(message string) []byte {
+		body, _ := json.Marshal(map[string]string{"Type": "Notification", "Message": message})
+		return body
+	}
+
+	events, subscribeURL, err := mailer.ParseSESEvent(notification(`{"eventType":"Bounce",
+		"mail":{"messageId":"0100018c-ses","destination":["analyst@example.com"],"timestamp":"2026-01-01T12:00:00.000Z"},
+		"bounce":{"bounceType":"Permanent","timestamp":"2026-01-01T12:00:05.000Z",
+			"bouncedRecipients":[{"emailAddress":"analyst@example.com","diagnosticCode":"smtp; 550 5.1.1 user unknown"}]}}`))
+	require.NoError(t, err)
+	assert.Empty(t, subscribeURL)
+	require.Len(t, events, 1)
+	assert.Equal(t, "0100018c-ses", events[0].MessageID)
+	assert.Equal(t, mailer.StatusBounced, events[0].Status)
+	assert.Equal(t, "smtp; 550 5.1.1 user unknown", events[0].Reason)
+
+	events, _, err = mailer.ParseSESEvent(notification(`{"notificationType":"Delivery",
+		"mail":{"messageId":"0100018c-ses","destination":["analyst@example.com"]},
+		"delivery":{"recipients":["analyst@example.com"],"smtpResponse":"250 2.0.0 OK","timestamp":"2026-01-01T12:00:02.000Z"}}`))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, mailer.StatusDelivered, events[0].Status)
+
+	events, subscribeURL, err = mailer.ParseSESEvent([]byte(`{"Type":"SubscriptionConfirmation","SubscribeURL":"https://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription"}`))
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Equal(t, "https://sns.us-east-1.amazonaws.com/?Action=ConfirmSubscription", subscribeURL)
+}
+
+func TestNextEmailDeliveryStatus(t *testing.T) {
+	sentAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	later := sentAt.Add(time.Minute)
+
+	assert.Equal(t, models.EmailDeliveryDeferred, services.NextEmailDeliveryStatus(models.EmailDeliverySent, sentAt, models.EmailDeliveryDeferred, later))
+	assert.Equal(t, models.EmailDeliveryDelivered, services.NextEmailDeliveryStatus(models.EmailDeliveryDeferred, sentAt, models.EmailDeliveryDelivered, later))
+	assert.Equal(t, models.EmailDeliveryDelivered, services.NextEmailDeliveryStatus(models.EmailDeliveryDelivered, later, models.EmailDeliveryDeferred, sentAt),
+		"a deferral reported after the delivery doesn't undo it")
+	assert.Equal(t, models.EmailDeliveryComplained, services.NextEmailDeliveryStatus(models.EmailDeliveryDelivered, later, models.EmailDeliveryComplained, later.Add(time.Hour)))
+	assert.Equal(t, models.EmailDeliveryBounced, services.NextEmailDeliveryStatus(models.EmailDeliveryDelivered, sentAt, models.EmailDeliveryBounced, later),
+		"the later of two final outcomes wins")
+}
+
+func TestEmailOutboxRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, services.EmailOutboxRetryDelay(1))
+	assert.Equal(t, time.Minute, services.EmailOutboxRetryDelay(2))
+	assert.Equal(t, 16*time.Minute, services.EmailOutboxRetryDelay(6))
+	assert.Equal(t, time.Hour, services.EmailOutboxRetryDelay(20), "capped")
+
+	var total time.Duration
+	for attempts := 1; attempts < services.EmailOutboxMaxAttempts; attempts++ {
+		total += services.EmailOutboxRetryDelay(attempts)
+	}
+	assert.Less(t, total, 2*time.Hour, "dead-lettered within a couple of hours")
+}
@@ -0,0 +1,218 @@
+package unit
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/mailbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const researcherEmail = "From: =?UTF-8?Q?J=C3=B6rg?= <Joerg@Research.example>\r\n" +
+	"To: security@cyops.example\r\n" +
+	"Subject: Re: [Bug Bounty] SQL injection in login\r\n" +
+	"Message-ID: <report-1@research.example>\r\n" +
+	"Date: Mon, 02 Jan 2006 15:04:05 +0000\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"inner\"\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Severity: High\r\n" +
+	"CVSS: 8.1\r\n" +
+	"The login form does not escape the user name =E2=80=93 see below.\r\n" +
+	"\r\n" +
+	"Steps to Reproduce:\r\n" +
+	"1. Enter ' OR 1=3D1 -- as user name\r\n" +
+	"\r\n" +
+	"## Impact\r\n" +
+	"Full database read access.\r\n" +
+	"\r\n" +
+	"> quoted reply\r\n" +
+	"-- \r\n" +
+	"Signature\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>ignored</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-Disposition: attachment; filename=\"poc.png\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"iVBORw0KGgo=\r\n" +
+	"--outer--\r\n"
+
+func TestParseInboundEmail(t *testing.T) {
+	msg, err := services.ParseInboundEmail([]byte(researcherEmail))
+	require.NoError(t, err)
+
+	assert.Equal(t, "report-1@research.example", msg.MessageID)
+	assert.Equal(t, "joerg@research.example", msg.From)
+	assert.Equal(t, "Jörg", msg.FromName)
+	assert.Equal(t, "Re: [Bug Bounty] SQL injection in login", msg.Subject)
+	require.NotNil(t, msg.Date)
+	assert.Contains(t, msg.Text, "does not escape the user name – see below")
+	assert.Contains(t, msg.Text, "' OR 1=1 --")
+
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, "poc.png", msg.Attachments[0].Filename)
+	assert.Equal(t, "image/png", msg.Attachments[0].ContentType)
+	assert.Equal(t, []byte("\x89PNG\r\n\x1a\n"), msg.Attachments[0].Data)
+
+	report := services.ParseEmailReport(msg.Subject, msg.Text, models.SeverityMedium)
+	assert.Equal(t, "[Bug Bounty] SQL injection in login", report.Title)
+	assert.Equal(t, models.SeverityHigh, report.Severity)
+	require.NotNil(t, report.CVSSScore)
+	assert.Equal(t, 8.1, *report.CVSSScore)
+	assert.Equal(t, "The login form does not escape the user name – see below.", report.Description)
+	assert.Equal(t, "1. Enter ' OR 1=1 -- as user name", report.StepsToReproduce)
+	assert.Equal(t, "Full database read access.", report.ImpactAssessment)
+	assert.Empty(t, report.MitigationRecommendations)
+}
+
+func TestParseInboundEmailWithoutMessageID(t *testing.T) {
+	raw := "From: someone@example.com\r\nSubject: Hello\r\nContent-Type: text/html\r\n\r\n<p>CVE-2024-12345 in <b>nginx</b></p><script>x()</script>"
+	msg, err := services.ParseInboundEmail([]byte(raw))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(msg.MessageID, "sha256:"))
+	assert.Equal(t, "CVE-2024-12345 in nginx", msg.Text)
+
+	report := services.ParseEmailReport(msg.Subject, msg.Text, models.SeverityLow)
+	assert.Equal(t, "CVE-2024-12345", report.CVEID)
+	assert.Equal(t, models.SeverityLow, report.Severity)
+
+	_, err = services.ParseInboundEmail([]byte("not an email"))
+	assert.ErrorIs(t, err, services.ErrInvalidInboundEmail)
+}
+
+func TestParseEmailReportSeverityFromCVSS(t *testing.T) {
+	report := services.ParseEmailReport("", "Title: RCE in upload\nCVSS Score: 9.8\nCVE: cve-2023-0001", models.SeverityMedium)
+	assert.Equal(t, "RCE in upload", report.Title)
+	assert.Equal(t, models.SeverityCritical, report.Severity)
+	assert.Equal(t, "CVE-2023-0001", report.CVEID)
+}
+
+func TestSenderAllowed(t *testing.T) {
+	allowed := []string{"hackerone.com", "alice@example.com"}
+	assert.True(t, services.SenderAllowed(allowed, "reports@hackerone.com"))
+	assert.True(t, services.SenderAllowed(allowed, "reports@mail.hackerone.com"))
+	assert.True(t, services.SenderAllowed(allowed, "Alice@Example.com"))
+	assert.False(t, services.SenderAllowed(allowed, "bob@example.com"))
+	assert.False(t, services.SenderAllowed(allowed, "x@evilhackerone.com"))
+	assert.True(t, services.SenderAllowed(nil, "anyone@anywhere.test"))
+}
+
+func TestValidateEmailIngestionSettings(t *testing.T) {
+	settings, err := services.ParseEmailIngestionSettings(`{"enabled":true,"webhook_secret":"s3cret","reporter_user_id":"6f1c2b1e-2b43-4c8e-9d1a-1c2b3d4e5f60","allowed_senders":[" @HackerOne.com "]}`)
+	require.NoError(t, err)
+	assert.Equal(t, mailbox.SecurityTLS, settings.Security)
+	assert.Equal(t, 993, settings.Port)
+	assert.Equal(t, "INBOX", settings.Mailbox)
+	assert.Equal(t, models.SeverityMedium, settings.DefaultSeverity)
+	assert.Equal(t, []string{"hackerone.com"}, settings.AllowedSenders)
+
+	for value, wantErr := range map[string]string{
+		`{"enabled":true,"webhook_secret":"x"}`:                                                                "reporter_user_id is required",
+		`{"enabled":true,"reporter_user_id":"6f1c2b1e-2b43-4c8e-9d1a-1c2b3d4e5f60"}`:                           "mailbox host or a webhook secret",
+		`{"enabled":true,"host":"imap.example.com","reporter_user_id":"6f1c2b1e-2b43-4c8e-9d1a-1c2b3d4e5f60"}`: "username and password",
+		`{"security":"ssl"}`:            "invalid security",
+		`{"default_severity":"URGENT"}`: "invalid default_severity",
+	} {
+		_, err := services.ParseEmailIngestionSettings(value)
+		if assert.Error(t, err, value) {
+			assert.Contains(t, err.Error(), wantErr)
+		}
+	}
+}
+
+func TestParseSESNotification(t *testing.T) {
+	notification, _ := json.Marshal(map[string]interface{}{
+		"notificationType": "Received",
+		"content":          base64.StdEncoding.EncodeToString([]byte(researcherEmail)),
+		"receipt":          map[string]interface{}{"action": map[string]string{"encoding": "BASE64"}},
+	})
+	body, _ := json.Marshal(map[string]string{"Type": "Notification", "Message": string(notification)})
+
+	raw, subscribeURL, err := services.ParseSESNotification(body)
+	require.NoError(t, err)
+	assert.Empty(t, subscribeURL)
+	assert.Equal(t, researcherEmail, string(raw))
+
+	raw, subscribeURL, err = services.ParseSESNotification([]byte(`{"Type":"SubscriptionConfirmation","SubscribeURL":"https://sns.example/confirm"}`))
+	require.NoError(t, err)
+	assert.Nil(t, raw)
+	assert.Equal(t, "https://sns.example/confirm", subscribeURL)
+}
+
+// serveIMAP answers the commands of a mailbox poll with one unseen message
+func serveIMAP(t *testing.T, conn net.Conn, message string) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	fmt.Fprint(conn, "* OK IMAP4rev1 ready\r\n")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		tag, command, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch {
+		case strings.HasPrefix(command, "LOGIN"):
+			if command != `LOGIN "reports" "pa\"ss"` {
+				fmt.Fprintf(conn, "%s NO invalid credentials\r\n", tag)
+				continue
+			}
+		case strings.HasPrefix(command, "SELECT"):
+			fmt.Fprint(conn, "* 1 EXISTS\r\n")
+		case command == "UID SEARCH UNSEEN":
+			fmt.Fprint(conn, "* SEARCH 42\r\n")
+		case command == "UID FETCH 42 BODY.PEEK[]":
+			fmt.Fprintf(conn, "* 1 FETCH (UID 42 BODY[] {%d}\r\n%s)\r\n", len(message), message)
+		case strings.HasPrefix(command, "UID STORE 42"):
+		case command == "LOGOUT":
+			fmt.Fprintf(conn, "* BYE\r\n%s OK LOGOUT completed\r\n", tag)
+			return
+		default:
+			fmt.Fprintf(conn, "%s BAD unknown command\r\n", tag)
+			continue
+		}
+		fmt.Fprintf(conn, "%s OK done\r\n", tag)
+	}
+}
+
+func TestIMAPClientPoll(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	go serveIMAP(t, serverConn, researcherEmail)
+
+	client, err := mailbox.NewClient(clientConn, 5*time.Second)
+	require.NoError(t, err)
+	assert.ErrorContains(t, client.Login("reports", "wrong"), "invalid credentials")
+	require.NoError(t, client.Login("reports", `pa"ss`))
+	require.NoError(t, client.Select(""))
+
+	uids, err := client.SearchUnseen()
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{42}, uids)
+
+	raw, err := client.Fetch(42)
+	require.NoError(t, err)
+	assert.Equal(t, researcherEmail, string(raw))
+
+	require.NoError(t, client.MarkSeen(42))
+	require.NoError(t, client.Logout())
+}
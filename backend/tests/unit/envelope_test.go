@@ -0,0 +1,110 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envelope(t *testing.T, status int, body string) map[string]interface{} {
+	t.Helper()
+	out, err := middleware.StandardEnvelope(status, []byte(body), "req-1")
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	return decoded
+}
+
+func TestStandardEnvelopeSuccess(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"raw object", `{"id":"a","name":"x"}`, `{"data":{"id":"a","name":"x"}}`},
+		{"raw list", `[1,2]`, `{"data":[1,2]}`},
+		{"data with meta", `{"data":[1],"meta":{"page":1,"total":1}}`, `{"data":[1],"meta":{"page":1,"total":1}}`},
+		{"top-level pagination", `{"data":[1],"total":1,"page":2,"limit":50,"total_pages":1}`, `{"data":[1],"meta":{"limit":50,"page":2,"total":1,"total_pages":1}}`},
+		{"message only", `{"message":"Deleted"}`, `{"data":null,"message":"Deleted"}`},
+		{"message with data", `{"message":"Created","data":{"id":"a"}}`, `{"data":{"id":"a"},"message":"Created"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := middleware.StandardEnvelope(200, []byte(tt.body), "")
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(out))
+		})
+	}
+}
+
+func TestStandardEnvelopeErrors(t *testing.T) {
+	t.Run("plain error", func(t *testing.T) {
+		apiErr := envelope(t, 404, `{"error":"Vulnerability not found"}`)["error"].(map[string]interface{})
+		assert.Equal(t, middleware.CodeNotFound, apiErr["code"])
+		assert.Equal(t, "Vulnerability not found", apiErr["message"])
+		assert.Equal(t, float64(404), apiErr["status"])
+		assert.Equal(t, "req-1", apiErr["request_id"])
+	})
+
+	t.Run("explicit code", func(t *testing.T) {
+		apiErr := envelope(t, 401, `{"error":"Access token expired","code":"token_expired"}`)["error"].(map[string]interface{})
+		assert.Equal(t, "token_expired", apiErr["code"])
+		assert.Equal(t, "Access token expired", apiErr["message"])
+	})
+
+	t.Run("error response with field errors", func(t *testing.T) {
+		body := `{"error":"validation_error","message":"title is required","status":400,"request_id":"req-2",` +
+			`"details":{"fields":[{"field":"title","message":"title is required"}],"hint":"x"}}`
+		apiErr := envelope(t, 400, body)["error"].(map[string]interface{})
+		assert.Equal(t, middleware.CodeValidation, apiErr["code"])
+		assert.Equal(t, "title is required", apiErr["message"])
+		assert.Equal(t, "req-2", apiErr["request_id"])
+		assert.Equal(t, []interface{}{map[string]interface{}{"field": "title", "message": "title is required"}}, apiErr["fields"])
+		assert.Equal(t, map[string]interface{}{"hint": "x"}, apiErr["details"])
+	})
+
+	t.Run("extra keys become details", func(t *testing.T) {
+		apiErr := envelope(t, 409, `{"error":"Duplicate asset","matches":[1]}`)["error"].(map[string]interface{})
+		assert.Equal(t, middleware.CodeConflict, apiErr["code"])
+		assert.Equal(t, map[string]interface{}{"matches": []interface{}{float64(1)}}, apiErr["details"])
+	})
+
+	t.Run("already enveloped", func(t *testing.T) {
+		body := `{"error":{"code":"x","message":"y","status":400}}`
+		out, err := middleware.StandardEnvelope(400, []byte(body), "")
+		require.NoError(t, err)
+		assert.JSONEq(t, body, string(out))
+	})
+
+	_, err := middleware.StandardEnvelope(200, []byte("id,name"), "")
+	assert.Error(t, err, "non-JSON bodies are left alone")
+}
+
+func TestErrorCodeForStatus(t *testing.T) {
+	assert.Equal(t, middleware.CodeBadRequest, middleware.ErrorCodeForStatus(400))
+	assert.Equal(t, middleware.CodePreconditionFailed, middleware.ErrorCodeForStatus(412))
+	assert.Equal(t, middleware.CodeRateLimited, middleware.ErrorCodeForStatus(429))
+	assert.Equal(t, middleware.CodeInternal, middleware.ErrorCodeForStatus(502))
+	assert.Equal(t, middleware.CodeUnavailable, middleware.ErrorCodeForStatus(503))
+}
+
+func TestVulnerabilityValidationFieldErrors(t *testing.T) {
+	validator := services.NewVulnerabilityValidationService()
+
+	title := "ab"
+	err := validator.ValidateUpdateRequest(services.UpdateVulnerabilityRequest{Title: &title})
+	var fieldErr *utils.FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "title", fieldErr.Field)
+	assert.Equal(t, "title must be at least 3 characters", err.Error())
+
+	assert.Nil(t, utils.FieldErr("title", nil))
+}
@@ -0,0 +1,56 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateEscalationPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  models.EscalationPolicy
+		wantErr string
+	}{
+		{"valid reassign", models.EscalationPolicy{Name: "Old highs", Severity: models.SeverityHigh, AgeDays: 30, ReassignToTeamLead: true}, ""},
+		{"valid webhook", models.EscalationPolicy{Name: "Criticals", Severity: models.SeverityCritical, AgeDays: 7, NotifyWebhookURL: "https://hooks.slack.com/services/T0/B0/x"}, ""},
+		{"missing name", models.EscalationPolicy{Severity: models.SeverityHigh, AgeDays: 30, RaisePriority: true}, "name is required"},
+		{"invalid severity", models.EscalationPolicy{Name: "x", Severity: "SEVERE", AgeDays: 30, RaisePriority: true}, "invalid severity"},
+		{"zero age", models.EscalationPolicy{Name: "x", Severity: models.SeverityLow, AgeDays: 0, RaisePriority: true}, "invalid age_days"},
+		{"bad webhook scheme", models.EscalationPolicy{Name: "x", Severity: models.SeverityLow, AgeDays: 1, NotifyWebhookURL: "ftp://example.com/hook"}, "invalid notify_webhook_url"},
+		{"no action", models.EscalationPolicy{Name: "x", Severity: models.SeverityLow, AgeDays: 1}, "at least one action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := services.ValidateEscalationPolicy(&tt.policy)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlanEscalation(t *testing.T) {
+	assignee, lead := uuid.New(), uuid.New()
+	match := services.EscalationMatch{AssignedToID: &assignee, TeamLeadID: &lead, Priority: 1}
+
+	planned := services.PlanEscalation(&models.EscalationPolicy{ReassignToTeamLead: true, RaisePriority: true}, match)
+	assert.Equal(t, &lead, planned.AssigneeID)
+	assert.Equal(t, 2, planned.Priority)
+
+	planned = services.PlanEscalation(&models.EscalationPolicy{RaisePriority: true}, match)
+	assert.Equal(t, &assignee, planned.AssigneeID, "assignee kept when the policy does not reassign")
+
+	match.TeamLeadID = nil
+	planned = services.PlanEscalation(&models.EscalationPolicy{ReassignToTeamLead: true}, match)
+	assert.Equal(t, &assignee, planned.AssigneeID, "assignee kept without an owner team lead")
+	assert.Equal(t, 1, planned.Priority)
+}
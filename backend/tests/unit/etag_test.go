@@ -0,0 +1,45 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceETag(t *testing.T) {
+	updatedAt := time.Date(2026, 3, 14, 9, 26, 53, 589793000, time.UTC)
+
+	etag := utils.ResourceETag(updatedAt)
+	assert.Regexp(t, `^"[0-9a-z]+"$`, etag, "a strong, quoted entity tag")
+	assert.Equal(t, etag, utils.ResourceETag(updatedAt.In(time.FixedZone("CET", 3600))), "independent of time zone")
+	assert.Equal(t, etag, utils.ResourceETag(updatedAt.Add(999*time.Nanosecond)), "database precision is microseconds")
+	assert.NotEqual(t, etag, utils.ResourceETag(updatedAt.Add(time.Microsecond)))
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := `"abc"`
+
+	tests := []struct {
+		name   string
+		header string
+		weak   bool
+		want   bool
+	}{
+		{"exact", `"abc"`, false, true},
+		{"wildcard", "*", false, true},
+		{"list", `"xyz", "abc"`, false, true},
+		{"different version", `"xyz"`, false, false},
+		{"unquoted", `abc`, false, false},
+		{"weak tag in strong comparison", `W/"abc"`, false, false},
+		{"weak tag in weak comparison", `W/"abc"`, true, true},
+		{"empty", "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, utils.ETagMatches(tt.header, etag, tt.weak))
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package unit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/exploitfeed"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExploitDB(t *testing.T) {
+	feed := `id,file,description,date_published,author,type,platform,port,date_added,date_updated,verified,codes,tags,aliases,screenshot_url,application_url,source_url
+50592,exploits/java/remote/50592.py,"Apache Log4j 2 - Remote Code Execution (RCE)",2021-12-14,kozmer,remote,java,,2021-12-14,2021-12-14,1,CVE-2021-44228;OSVDB-1234;cve-2021-45046,,,,,
+12345,exploits/php/webapps/12345.txt,"Some Web App - SQL Injection",2010-04-01,someone,webapps,php,,2010-04-01,2010-04-01,0,OSVDB-99,,,,,
+51000,exploits/linux/local/51000.c,"Linux Kernel - Local Privilege Escalation",2022-03-07,other,local,linux,,2022-03-07,2022-03-07,0,CVE-2022-0847,,,,,
+`
+	exploits, err := exploitfeed.ParseExploitDB(strings.NewReader(feed))
+	require.NoError(t, err)
+	require.Len(t, exploits, 2, "exploits without a CVE are skipped")
+
+	log4j := exploits[0]
+	assert.Equal(t, exploitfeed.SourceExploitDB, log4j.Source)
+	assert.Equal(t, "50592", log4j.ID)
+	assert.Equal(t, "Apache Log4j 2 - Remote Code Execution (RCE)", log4j.Title)
+	assert.Equal(t, "https://www.exploit-db.com/exploits/50592", log4j.URL)
+	assert.Equal(t, []string{"CVE-2021-44228", "CVE-2021-45046"}, log4j.CVEs)
+	assert.True(t, log4j.Verified)
+	require.NotNil(t, log4j.PublishedAt)
+	assert.Equal(t, "2021-12-14", log4j.PublishedAt.Format("2006-01-02"))
+
+	assert.False(t, exploits[1].Verified)
+
+	_, err = exploitfeed.ParseExploitDB(strings.NewReader("id,file,description\n1,a,b\n"))
+	assert.Error(t, err, "a feed without the codes column is rejected")
+}
+
+func TestParseMetasploit(t *testing.T) {
+	feed := `{
+  "exploit_windows/smb/ms17_010_eternalblue": {
+    "name": "MS17-010 EternalBlue SMB Remote Windows Kernel Pool Corruption",
+    "fullname": "exploit/windows/smb/ms17_010_eternalblue",
+    "type": "exploit",
+    "references": ["CVE-2017-0143", "CVE-2017-0144", "MSB-MS17-010", "URL-https://example.com"],
+    "disclosure_date": "2017-03-14",
+    "rank": 200
+  },
+  "auxiliary_scanner/smb/smb_ms17_010": {
+    "name": "MS17-010 SMB RCE Detection",
+    "fullname": "auxiliary/scanner/smb/smb_ms17_010",
+    "type": "auxiliary",
+    "references": ["CVE-2017-0143"]
+  },
+  "exploit_multi/handler": {
+    "name": "Generic Payload Handler",
+    "fullname": "exploit/multi/handler",
+    "type": "exploit",
+    "references": [],
+    "disclosure_date": null
+  }
+}`
+	exploits, err := exploitfeed.ParseMetasploit(strings.NewReader(feed))
+	require.NoError(t, err)
+	require.Len(t, exploits, 1, "auxiliary modules and modules without a CVE are skipped")
+
+	module := exploits[0]
+	assert.Equal(t, exploitfeed.SourceMetasploit, module.Source)
+	assert.Equal(t, "exploit/windows/smb/ms17_010_eternalblue", module.ID)
+	assert.Equal(t, "https://www.rapid7.com/db/modules/exploit/windows/smb/ms17_010_eternalblue/", module.URL)
+	assert.Equal(t, []string{"CVE-2017-0143", "CVE-2017-0144"}, module.CVEs)
+	assert.True(t, module.Verified)
+	require.NotNil(t, module.PublishedAt)
+	assert.Equal(t, "2017-03-14", module.PublishedAt.Format("2006-01-02"))
+
+	_, err = exploitfeed.ParseMetasploit(strings.NewReader("[]"))
+	assert.Error(t, err)
+}
+
+func TestVulnerabilityRiskWeight(t *testing.T) {
+	assert.Equal(t, 10.0, services.VulnerabilityRiskWeight("CRITICAL", false))
+	assert.Equal(t, 15.0, services.VulnerabilityRiskWeight("CRITICAL", true))
+	assert.Equal(t, 6.0, services.VulnerabilityRiskWeight("MEDIUM", true))
+	// An exploitable high outweighs an unexploited critical
+	assert.Greater(t, services.VulnerabilityRiskWeight("HIGH", true), services.VulnerabilityRiskWeight("CRITICAL", false))
+	assert.Equal(t, 0.0, services.VulnerabilityRiskWeight("NONE", true))
+}
+
+func TestParseNessusFileExploitAvailable(t *testing.T) {
+	vulns, err := services.NewNessusParserService().ParseNessusFile([]byte(`<?xml version="1.0"?>
+<NessusClientData_v2>
+  <Report name="scan">
+    <ReportHost name="10.0.0.5">
+      <HostProperties><tag name="host-ip">10.0.0.5</tag></HostProperties>
+      <ReportItem port="445" svc_name="cifs" protocol="tcp" severity="4" pluginID="97833" pluginName="MS17-010" pluginFamily="Windows">
+        <description>SMB server vulnerabilities</description>
+        <exploit_available>true</exploit_available>
+        <risk_factor>Critical</risk_factor>
+      </ReportItem>
+      <ReportItem port="443" svc_name="www" protocol="tcp" severity="2" pluginID="51192" pluginName="SSL Certificate Cannot Be Trusted" pluginFamily="General">
+        <description>Untrusted certificate</description>
+        <risk_factor>Medium</risk_factor>
+      </ReportItem>
+    </ReportHost>
+  </Report>
+</NessusClientData_v2>`))
+	require.NoError(t, err)
+	require.Len(t, vulns, 2)
+	exploitable := map[string]bool{}
+	for _, vuln := range vulns {
+		exploitable[vuln.PluginID] = vuln.ExploitAvailable
+	}
+	assert.True(t, exploitable["97833"])
+	assert.False(t, exploitable["51192"])
+}
+
+func TestParsedVulnerabilitiesFromTenableExploitAvailable(t *testing.T) {
+	var records []services.TenableVulnRecord
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"asset": {"ipv4": "10.0.0.7"},
+		 "plugin": {"id": 97833, "name": "MS17-010", "exploit_available": true},
+		 "port": {"port": 445, "protocol": "TCP"},
+		 "severity_id": 4, "state": "OPEN", "last_found": "2026-09-01T10:00:00Z"}
+	]`), &records))
+
+	vulns := services.ParsedVulnerabilitiesFromTenable(records)
+	require.Len(t, vulns, 1)
+	assert.True(t, vulns[0].ExploitAvailable)
+}
@@ -0,0 +1,35 @@
+//go:build chaos
+
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cyops/cyops-backend/pkg/faultinject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultInjectionWithChaosTag(t *testing.T) {
+	t.Cleanup(faultinject.ClearAll)
+	assert.True(t, faultinject.Enabled())
+
+	assert.Error(t, faultinject.Set(faultinject.PointImport, faultinject.Fault{ErrorRate: 2}), "invalid faults are refused")
+	assert.NoError(t, faultinject.Inject(faultinject.PointImport), "points without a fault never fail")
+
+	require.NoError(t, faultinject.Set(faultinject.PointImport, faultinject.Fault{ErrorRate: 1, Message: "disk full"}))
+	err := faultinject.Inject(faultinject.PointImport)
+	assert.ErrorIs(t, err, faultinject.ErrInjected)
+	assert.Contains(t, err.Error(), "disk full")
+	assert.NoError(t, faultinject.Inject(faultinject.PointReport), "faults only apply at their point")
+
+	require.NoError(t, faultinject.Set(faultinject.PointReport, faultinject.Fault{ErrorRate: 1, Timeout: true}))
+	assert.ErrorIs(t, faultinject.Inject(faultinject.PointReport), context.DeadlineExceeded)
+	assert.Len(t, faultinject.List(), 2)
+
+	faultinject.Clear(faultinject.PointImport)
+	assert.NoError(t, faultinject.Inject(faultinject.PointImport))
+	require.NoError(t, faultinject.Set(faultinject.PointImport, faultinject.Fault{ErrorRate: 0}))
+	assert.NoError(t, faultinject.Inject(faultinject.PointImport), "a zero error rate never fails")
+}
@@ -0,0 +1,17 @@
+//go:build !chaos
+
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/pkg/faultinject"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFaultInjectionDisabledWithoutChaosTag(t *testing.T) {
+	assert.False(t, faultinject.Enabled())
+	assert.ErrorIs(t, faultinject.Set(faultinject.PointDatabase, faultinject.Fault{ErrorRate: 1}), faultinject.ErrDisabled)
+	assert.NoError(t, faultinject.Inject(faultinject.PointDatabase))
+	assert.Empty(t, faultinject.List())
+}
@@ -0,0 +1,32 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/pkg/faultinject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaultValidate(t *testing.T) {
+	assert.NoError(t, faultinject.Fault{DelayMS: 250, ErrorRate: 0.5}.Validate())
+	assert.NoError(t, faultinject.Fault{DelayMS: 120000, ErrorRate: 1}.Validate())
+
+	assert.Error(t, faultinject.Fault{DelayMS: -1}.Validate())
+	assert.Error(t, faultinject.Fault{DelayMS: 120001}.Validate())
+	assert.Error(t, faultinject.Fault{ErrorRate: -0.1}.Validate())
+	assert.Error(t, faultinject.Fault{ErrorRate: 1.5}.Validate())
+}
+
+func TestParseFaultPoint(t *testing.T) {
+	for _, point := range faultinject.Points {
+		parsed, err := faultinject.ParsePoint(string(point))
+		require.NoError(t, err)
+		assert.Equal(t, point, parsed)
+	}
+
+	_, err := faultinject.ParsePoint("filesystem")
+	assert.Error(t, err)
+	_, err = faultinject.ParsePoint("Database")
+	assert.Error(t, err, "point names are case sensitive")
+}
@@ -0,0 +1,30 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{"no mentions", "Patched on all hosts", []string{}},
+		{"local part", "@alice please verify", []string{"alice"}},
+		{"full email", "cc @Bob.Smith@Example.com", []string{"bob.smith@example.com"}},
+		{"trailing punctuation", "Thanks @carol.", []string{"carol"}},
+		{"deduplicated", "@dave and @Dave again", []string{"dave"}},
+		{"plain email ignored", "contact ops@example.com", []string{}},
+		{"multiple", "@erin, @frank: see plugin output", []string{"erin", "frank"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, services.ExtractMentions(tt.body))
+		})
+	}
+}
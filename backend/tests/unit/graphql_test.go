@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/handlers"
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLQueryDepth(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{"flat", `{ vulnerabilities { id title } }`, 1},
+		{"traversal", `{ vulnerabilities { assets { findings { vulnerability { id } } } } }`, 4},
+		{"fragment", `query { assets { ...A } } fragment A on Asset { vulnerabilities { findings { id } } }`, 3},
+		{"recursive fragment", `query { assets { ...A } } fragment A on Asset { vulnerabilities { assets { ...A } } }`, 3},
+		{"inline fragment", `{ finding(id: "x") { ... on Finding { asset { id } } } }`, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			depth, err := handlers.GraphQLQueryDepth(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, depth)
+		})
+	}
+
+	_, err := handlers.GraphQLQueryDepth(`{ vulnerabilities {`)
+	assert.Error(t, err)
+}
+
+func TestGraphQLSchema(t *testing.T) {
+	schema, err := handlers.NewGraphQLSchema()
+	require.NoError(t, err)
+
+	for _, field := range []string{"vulnerability", "vulnerabilities", "asset", "assets", "finding", "findings", "assessment", "assessments"} {
+		assert.Contains(t, schema.QueryType().Fields(), field)
+	}
+	assert.Contains(t, schema.Type("Vulnerability").(*graphql.Object).Fields(), "assets")
+	assert.Contains(t, schema.Type("Asset").(*graphql.Object).Fields(), "vulnerabilities")
+	assert.Contains(t, schema.Type("Finding").(*graphql.Object).Fields(), "vulnerability")
+	assert.Contains(t, schema.Type("Assessment").(*graphql.Object).Fields(), "vulnerabilities")
+
+	// Without an authenticated caller every field resolves to null with an error
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ vulnerabilities { id } }`,
+		Context:       context.Background(),
+	})
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, "authentication required")
+	assert.Equal(t, map[string]interface{}{"vulnerabilities": nil}, result.Data)
+}
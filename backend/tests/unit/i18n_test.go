@@ -0,0 +1,117 @@
+package unit
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/i18n"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadCatalog(t *testing.T, lang string) map[string]string {
+	data, err := os.ReadFile(filepath.Join("..", "..", "pkg", "i18n", "locales", lang+".json"))
+	require.NoError(t, err)
+	catalog := map[string]string{}
+	require.NoError(t, json.Unmarshal(data, &catalog))
+	return catalog
+}
+
+func TestI18nCatalogsMatchEnglish(t *testing.T) {
+	english := loadCatalog(t, i18n.English)
+	for _, lang := range i18n.Supported() {
+		for id, text := range loadCatalog(t, lang) {
+			source, ok := english[id]
+			if !assert.True(t, ok, "%s: %s is not in the English catalog", lang, id) {
+				continue
+			}
+			assert.Equal(t, strings.Count(source, "%"), strings.Count(text, "%"), "%s: %s has different format verbs", lang, id)
+		}
+	}
+
+	// A catalog file for a language missing from Supported() would never be loaded
+	entries, err := fs.Glob(os.DirFS(filepath.Join("..", "..", "pkg", "i18n", "locales")), "*.json")
+	require.NoError(t, err)
+	assert.Len(t, entries, len(i18n.Supported()))
+}
+
+func TestI18nNegotiate(t *testing.T) {
+	assert.Equal(t, i18n.English, i18n.Negotiate(""))
+	assert.Equal(t, i18n.Arabic, i18n.Negotiate("ar-SA,ar;q=0.9,en;q=0.8"))
+	assert.Equal(t, i18n.English, i18n.Negotiate("fr-FR,en;q=0.5,ar;q=0.1"))
+	assert.Equal(t, i18n.English, i18n.Negotiate("de-DE"), "unsupported languages fall back to English")
+	assert.Equal(t, "rtl", i18n.Direction(i18n.Arabic))
+}
+
+func TestI18nTranslate(t *testing.T) {
+	assert.Equal(t, "Invalid request body", i18n.Translate(i18n.English, "Invalid request body"))
+	assert.Equal(t, "نص الطلب غير صالح", i18n.Translate(i18n.Arabic, "Invalid request body"))
+	assert.Equal(t, "Something unforeseen", i18n.Translate(i18n.Arabic, "Something unforeseen"), "unknown messages are kept")
+	assert.Equal(t, "User not found", i18n.T(i18n.English, "error.not_found", "User"))
+	assert.Equal(t, "مارس 2026", i18n.FormatMonth(i18n.Arabic, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestErrorResponsesAreLocalized(t *testing.T) {
+	app := fiber.New()
+	app.Get("/invalid", func(c *fiber.Ctx) error {
+		return middleware.ValidationError(c, "Invalid request body", nil)
+	})
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return middleware.NotFoundError(c, "User")
+	})
+
+	get := func(path, acceptLanguage string) (string, middleware.ErrorResponse) {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		var body middleware.ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp.Header.Get("Content-Language"), body
+	}
+
+	lang, body := get("/invalid", "ar")
+	assert.Equal(t, "ar", lang)
+	assert.Equal(t, "نص الطلب غير صالح", body.Message)
+	assert.Equal(t, middleware.CodeValidation, body.Error, "error codes are not translated")
+
+	_, body = get("/missing", "ar")
+	assert.Equal(t, "لم يتم العثور على المستخدم", body.Message)
+
+	lang, body = get("/missing", "en-GB")
+	assert.Equal(t, "en", lang)
+	assert.Equal(t, "User not found", body.Message)
+}
+
+func TestRemediationAnalyticsLocalized(t *testing.T) {
+	maxDays := 7
+	aging := services.AgingAnalytics{Buckets: []services.AgingBucket{
+		{Label: "0-7 days", MinDays: 0, MaxDays: &maxDays},
+		{Label: "180+ days", MinDays: 181},
+	}}
+	english := aging.Localized(i18n.English)
+	assert.Equal(t, "0-7 days", english.Buckets[0].Label)
+	assert.Equal(t, "180+ days", english.Buckets[1].Label)
+	arabic := aging.Localized(i18n.Arabic)
+	assert.Equal(t, "من 0 إلى 7 يوماً", arabic.Buckets[0].Label)
+	assert.Equal(t, "0-7 days", aging.Buckets[0].Label, "the original is not modified")
+
+	mttr := services.MTTRAnalytics{
+		BySeverity: []services.MTTRGroup{{Key: "CRITICAL", Label: "CRITICAL"}},
+		ByTeam:     []services.MTTRGroup{{Key: "", Label: "Unassigned"}, {Key: "team", Label: "Platform"}},
+		ByMonth:    []services.MTTRGroup{{Key: "2026-01", Label: "Jan 2026"}},
+	}.Localized(i18n.Arabic)
+	assert.Equal(t, "حرجة", mttr.BySeverity[0].Label)
+	assert.Equal(t, "غير مُسند", mttr.ByTeam[0].Label)
+	assert.Equal(t, "Platform", mttr.ByTeam[1].Label, "team names are kept")
+	assert.Equal(t, "يناير 2026", mttr.ByMonth[0].Label)
+}
@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartImpersonationRules(t *testing.T) {
+	// Every case is refused before the database is touched
+	sessionService := services.NewSessionService()
+
+	admin := &models.User{Role: &models.Role{Name: "admin", Level: 100}}
+	admin.ID = uuid.New()
+	peer := &models.User{Role: &models.Role{Name: "admin", Level: 100}}
+	peer.ID = uuid.New()
+	analyst := &models.User{Role: &models.Role{Name: "analyst", Level: 50}}
+	analyst.ID = uuid.New()
+
+	cases := []struct {
+		name   string
+		admin  *models.User
+		target *models.User
+		reason string
+	}{
+		{"reason required", admin, analyst, "  "},
+		{"self", admin, admin, "testing"},
+		{"equal role level", admin, peer, "testing"},
+		{"higher role level", analyst, admin, "testing"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := sessionService.StartImpersonation(tc.admin, tc.target, 0, tc.reason, "127.0.0.1", "test")
+			assert.True(t, errors.Is(err, services.ErrImpersonationNotAllowed), "got %v", err)
+		})
+	}
+}
+
+func TestImpersonationInfo(t *testing.T) {
+	session := &models.Session{UserID: uuid.New(), ExpiresAt: time.Now().Add(30 * time.Minute)}
+	session.ID = uuid.New()
+	assert.False(t, session.IsImpersonation())
+	assert.Nil(t, session.ToImpersonationInfo(nil))
+
+	adminID := uuid.New()
+	session.ImpersonatorID = &adminID
+	session.ImpersonationReason = "ticket 42"
+	assert.True(t, session.IsImpersonation())
+	assert.True(t, session.ToPublic().Impersonated)
+
+	admin := &models.User{Email: "admin@example.com", Name: "Admin"}
+	info := session.ToImpersonationInfo(admin)
+	if assert.NotNil(t, info) {
+		assert.True(t, info.Banner)
+		assert.Equal(t, adminID.String(), info.ImpersonatorID)
+		assert.Equal(t, "admin@example.com", info.ImpersonatorEmail)
+		assert.Equal(t, session.UserID.String(), info.UserID)
+		assert.Equal(t, "ticket 42", info.Reason)
+		assert.Equal(t, session.ExpiresAt, info.ExpiresAt)
+	}
+
+	// The banner survives an impersonator that can no longer be loaded
+	assert.Equal(t, "", session.ToImpersonationInfo(nil).ImpersonatorEmail)
+}
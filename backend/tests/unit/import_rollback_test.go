@@ -0,0 +1,284 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// rollbackFixture holds records an import job is journaled against
+type rollbackFixture struct {
+	t       *testing.T
+	db      *gorm.DB
+	user    *models.User
+	service *services.VulnerabilityImportService
+}
+
+// setupImportRollback migrates the full schema and returns an import service on the test database
+func setupImportRollback(t *testing.T) *rollbackFixture {
+	db, user := setupSchemaDB(t)
+	if db == nil {
+		return nil // Skipped
+	}
+	return &rollbackFixture{
+		t:       t,
+		db:      db,
+		user:    user,
+		service: services.NewVulnerabilityImportService(),
+	}
+}
+
+// job creates a finished import job that started at startedAt
+func (f *rollbackFixture) job(startedAt time.Time) *models.ImportJob {
+	completedAt := startedAt.Add(time.Minute)
+	job := &models.ImportJob{
+		Scanner:     "nessus",
+		Status:      models.ImportJobCompleted,
+		CreatedByID: f.user.ID,
+		StartedAt:   startedAt,
+		CompletedAt: &completedAt,
+	}
+	job.CreatedAt = startedAt
+	require.NoError(f.t, f.db.Create(job).Error)
+	return job
+}
+
+func (f *rollbackFixture) asset(hostname string) *models.AffectedSystem {
+	asset := &models.AffectedSystem{Hostname: hostname, SystemType: models.SystemTypeServer}
+	require.NoError(f.t, f.db.Create(asset).Error)
+	return asset
+}
+
+func (f *rollbackFixture) vulnerability(title string) *models.Vulnerability {
+	vulnerability := &models.Vulnerability{
+		Title:         title,
+		Description:   title,
+		Severity:      models.SeverityHigh,
+		DiscoveryDate: time.Now(),
+		CreatedByID:   f.user.ID,
+	}
+	require.NoError(f.t, f.db.Create(vulnerability).Error)
+	return vulnerability
+}
+
+func (f *rollbackFixture) finding(vulnerability *models.Vulnerability, asset *models.AffectedSystem, status models.FindingStatus, lastSeen time.Time) *models.VulnerabilityFinding {
+	finding := &models.VulnerabilityFinding{
+		VulnerabilityID:  vulnerability.ID,
+		AffectedSystemID: asset.ID,
+		Port:             "443",
+		Protocol:         "tcp",
+		Status:           status,
+		FirstDetected:    lastSeen,
+		LastSeen:         lastSeen,
+		CreatedBy:        f.user.ID,
+	}
+	require.NoError(f.t, f.db.Create(finding).Error)
+	return finding
+}
+
+func (f *rollbackFixture) link(vulnerability *models.Vulnerability, asset *models.AffectedSystem) {
+	require.NoError(f.t, f.db.Create(&models.VulnerabilityAffectedSystem{
+		VulnerabilityID:  vulnerability.ID.String(),
+		AffectedSystemID: asset.ID.String(),
+	}).Error)
+}
+
+// journal records a change of job, as the import does while it runs
+func (f *rollbackFixture) journal(job *models.ImportJob, entityType string, entityID uuid.UUID, action models.ImportChangeAction, previous map[string]interface{}) {
+	change := &models.ImportChange{ImportJobID: job.ID, EntityType: entityType, EntityID: entityID, Action: action, Previous: "{}"}
+	if previous != nil {
+		encoded, err := json.Marshal(previous)
+		require.NoError(f.t, err)
+		change.Previous = string(encoded)
+	}
+	require.NoError(f.t, f.db.Create(change).Error)
+}
+
+// journalLink records a vulnerability-asset link job added
+func (f *rollbackFixture) journalLink(job *models.ImportJob, vulnerability *models.Vulnerability, asset *models.AffectedSystem) {
+	require.NoError(f.t, f.db.Create(&models.ImportChange{
+		ImportJobID: job.ID,
+		EntityType:  models.ImportChangeAssetLink,
+		EntityID:    vulnerability.ID,
+		RelatedID:   &asset.ID,
+		Action:      models.ImportChangeCreated,
+		Previous:    "{}",
+	}).Error)
+}
+
+func (f *rollbackFixture) reload(finding *models.VulnerabilityFinding) *models.VulnerabilityFinding {
+	var current models.VulnerabilityFinding
+	require.NoError(f.t, f.db.First(&current, "id = ?", finding.ID).Error)
+	return &current
+}
+
+func (f *rollbackFixture) linked(vulnerability *models.Vulnerability, asset *models.AffectedSystem) bool {
+	var count int64
+	require.NoError(f.t, f.db.Model(&models.VulnerabilityAffectedSystem{}).
+		Where("vulnerability_id = ? AND affected_system_id = ?", vulnerability.ID, asset.ID).
+		Count(&count).Error)
+	return count > 0
+}
+
+func TestRollbackImportRevertsCreatedSeenAndMissing(t *testing.T) {
+	f := setupImportRollback(t)
+	if f == nil {
+		return
+	}
+	before := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	job := f.job(time.Now().Add(-2 * time.Hour))
+
+	// Records that existed before the import
+	server := f.asset("web-01")
+	known := f.vulnerability("Known TLS issue")
+	f.link(known, server)
+	seen := f.finding(known, server, models.FindingStatusOpen, job.StartedAt)
+	f.journal(job, models.ImportChangeFinding, seen.ID, models.ImportChangeSeen, map[string]interface{}{
+		"last_seen": before, "scan_id": "previous-scan", "clean_scans": 2, "detected_version": "1.0",
+	})
+
+	otherHost := f.asset("web-02")
+	closed := f.finding(known, otherHost, models.FindingStatusFixed, before)
+	f.journal(job, models.ImportChangeFinding, closed.ID, models.ImportChangeMissing, map[string]interface{}{
+		"clean_scans": 0, "status": models.FindingStatusOpen, "fixed_at": nil, "fix_notes": "",
+	})
+	require.NoError(t, f.db.Create(&models.FindingStatusHistory{
+		FindingID: closed.ID, OldStatus: models.FindingStatusOpen, NewStatus: models.FindingStatusFixed,
+		Notes: "No longer detected", ChangedByID: f.user.ID, ChangedAt: job.StartedAt.Add(30 * time.Second),
+	}).Error)
+
+	// Records the import created
+	newHost := f.asset("db-01")
+	f.journal(job, models.ImportChangeAsset, newHost.ID, models.ImportChangeCreated, nil)
+	newVuln := f.vulnerability("New SQL issue")
+	f.journal(job, models.ImportChangeVulnerability, newVuln.ID, models.ImportChangeCreated, nil)
+	f.link(newVuln, newHost)
+	f.journalLink(job, newVuln, newHost)
+	f.link(newVuln, server)
+	f.journalLink(job, newVuln, server)
+	created := f.finding(newVuln, newHost, models.FindingStatusOpen, job.StartedAt)
+	f.journal(job, models.ImportChangeFinding, created.ID, models.ImportChangeCreated, nil)
+	createdOnServer := f.finding(newVuln, server, models.FindingStatusOpen, job.StartedAt)
+	f.journal(job, models.ImportChangeFinding, createdOnServer.ID, models.ImportChangeCreated, nil)
+
+	result, err := f.service.RollbackImport(job.ID, f.user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.DeletedFindings)
+	assert.Equal(t, 1, result.DeletedVulnerabilities)
+	assert.Equal(t, 1, result.DeletedAssets)
+	assert.Equal(t, 2, result.DeletedAssetLinks)
+	assert.Equal(t, 2, result.RestoredFindings)
+	assert.Equal(t, 1, result.ReopenedFindings)
+	assert.Empty(t, result.SkippedFindings)
+
+	// Created: findings and links deleted, vulnerability and asset in the recycle bin
+	var remaining int64
+	f.db.Model(&models.VulnerabilityFinding{}).Where("id IN ?", []uuid.UUID{created.ID, createdOnServer.ID}).Count(&remaining)
+	assert.Zero(t, remaining)
+	assert.False(t, f.linked(newVuln, newHost))
+	assert.False(t, f.linked(newVuln, server))
+	assert.True(t, f.linked(known, server), "links that existed before the import are kept")
+	var binned models.Vulnerability
+	require.NoError(t, f.db.Unscoped().First(&binned, "id = ?", newVuln.ID).Error)
+	assert.True(t, binned.DeletedAt.Valid)
+	require.NotNil(t, binned.DeletedByID)
+	assert.Equal(t, f.user.ID, *binned.DeletedByID)
+	assert.Error(t, f.db.First(&models.AffectedSystem{}, "id = ?", newHost.ID).Error)
+
+	// Seen: scan source and sighting restored
+	restored := f.reload(seen)
+	assert.True(t, restored.LastSeen.Equal(before))
+	assert.Equal(t, "previous-scan", restored.ScanID)
+	assert.Equal(t, 2, restored.CleanScans)
+	assert.Equal(t, "1.0", restored.DetectedVersion)
+
+	// Missing: reopened, with the reopening in its status history
+	reopened := f.reload(closed)
+	assert.Equal(t, models.FindingStatusOpen, reopened.Status)
+	assert.Nil(t, reopened.FixedAt)
+	var history []models.FindingStatusHistory
+	require.NoError(t, f.db.Where("finding_id = ?", closed.ID).Order("changed_at").Find(&history).Error)
+	require.Len(t, history, 2)
+	assert.Equal(t, models.FindingStatusFixed, history[1].OldStatus)
+	assert.Equal(t, models.FindingStatusOpen, history[1].NewStatus)
+
+	var rolledBack models.ImportJob
+	require.NoError(t, f.db.First(&rolledBack, "id = ?", job.ID).Error)
+	assert.Equal(t, models.ImportJobRolledBack, rolledBack.Status)
+	require.NotNil(t, rolledBack.RolledBackByID)
+	assert.Equal(t, f.user.ID, *rolledBack.RolledBackByID)
+
+	_, err = f.service.RollbackImport(job.ID, f.user.ID)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "already rolled back")
+	}
+}
+
+func TestRollbackImportRefusesWhenLaterImportChangedRecords(t *testing.T) {
+	f := setupImportRollback(t)
+	if f == nil {
+		return
+	}
+	first := f.job(time.Now().Add(-3 * time.Hour))
+	later := f.job(time.Now().Add(-1 * time.Hour))
+
+	vulnerability := f.vulnerability("Shared finding")
+	asset := f.asset("app-01")
+	finding := f.finding(vulnerability, asset, models.FindingStatusOpen, later.StartedAt)
+	f.journal(first, models.ImportChangeFinding, finding.ID, models.ImportChangeSeen, map[string]interface{}{
+		"last_seen": first.StartedAt.Add(-24 * time.Hour), "scan_id": "before-first",
+	})
+	f.journal(later, models.ImportChangeFinding, finding.ID, models.ImportChangeSeen, map[string]interface{}{
+		"last_seen": first.StartedAt, "scan_id": "from-first",
+	})
+
+	_, err := f.service.RollbackImport(first.ID, f.user.ID)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "later import")
+	}
+	var unchanged models.ImportJob
+	require.NoError(t, f.db.First(&unchanged, "id = ?", first.ID).Error)
+	assert.Equal(t, models.ImportJobCompleted, unchanged.Status, "a refused rollback leaves the job as it was")
+
+	// Rolling back the later import first unblocks the earlier one
+	_, err = f.service.RollbackImport(later.ID, f.user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "from-first", f.reload(finding).ScanID)
+	_, err = f.service.RollbackImport(first.ID, f.user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "before-first", f.reload(finding).ScanID)
+}
+
+func TestRollbackImportSkipsFindingsChangedSince(t *testing.T) {
+	f := setupImportRollback(t)
+	if f == nil {
+		return
+	}
+	job := f.job(time.Now().Add(-3 * time.Hour))
+
+	vulnerability := f.vulnerability("Closed then accepted")
+	asset := f.asset("app-02")
+	finding := f.finding(vulnerability, asset, models.FindingStatusAccepted, job.StartedAt)
+	f.journal(job, models.ImportChangeFinding, finding.ID, models.ImportChangeMissing, map[string]interface{}{
+		"clean_scans": 0, "status": models.FindingStatusOpen,
+	})
+	// An analyst accepted the risk after the import closed the finding
+	require.NoError(t, f.db.Create(&models.FindingStatusHistory{
+		FindingID: finding.ID, OldStatus: models.FindingStatusFixed, NewStatus: models.FindingStatusAccepted,
+		ChangedByID: f.user.ID, ChangedAt: job.CompletedAt.Add(time.Hour),
+	}).Error)
+
+	result, err := f.service.RollbackImport(job.ID, f.user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{finding.ID}, result.SkippedFindings)
+	assert.Zero(t, result.RestoredFindings)
+	assert.Zero(t, result.ReopenedFindings)
+	assert.Equal(t, models.FindingStatusAccepted, f.reload(finding).Status)
+}
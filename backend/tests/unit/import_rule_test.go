@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportRuleMatcher(t *testing.T) {
+	ownerID := uuid.New()
+	teamID := uuid.New()
+	rules := []models.ImportRule{
+		{Name: "disabled", Enabled: false, MatchType: models.ImportRuleMatchCIDR, Pattern: "0.0.0.0/0", Environment: models.EnvTest},
+		{Name: "staging hosts", Enabled: true, MatchType: models.ImportRuleMatchHostname, Pattern: `^stg-`, Environment: models.EnvStaging},
+		{Name: "lab network", Enabled: true, MatchType: models.ImportRuleMatchCIDR, Pattern: "10.50.0.0/16", Environment: models.EnvTest, OwnerTeamID: &teamID},
+		{Name: "dev scans", Enabled: true, MatchType: models.ImportRuleMatchScanName, Pattern: `dev`, Environment: models.EnvDevelopment},
+		{Name: "windows", Enabled: true, MatchType: models.ImportRuleMatchTag, Pattern: "operating-system=windows", OwnerID: &ownerID},
+		{Name: "broken", Enabled: true, MatchType: models.ImportRuleMatchHostname, Pattern: "(", Environment: models.EnvTest},
+	}
+	matcher := services.NewImportRuleMatcher(rules)
+	assert.Equal(t, 4, matcher.Len())
+
+	t.Run("first rule setting each value wins", func(t *testing.T) {
+		c := matcher.Classify(services.ParsedHost{Hostname: "STG-web01", IPAddress: "10.50.1.2"})
+		require.NotNil(t, c.EnvironmentRule)
+		assert.Equal(t, "staging hosts", c.EnvironmentRule.Name)
+		assert.Equal(t, models.EnvStaging, c.Environment)
+		require.NotNil(t, c.OwnerRule)
+		assert.Equal(t, "lab network", c.OwnerRule.Name)
+		assert.Nil(t, c.OwnerID)
+		assert.Equal(t, &teamID, c.OwnerTeamID)
+	})
+
+	t.Run("scan name and tag rules", func(t *testing.T) {
+		c := matcher.Classify(services.ParsedHost{
+			Hostname: "app01",
+			ScanName: "Weekly Dev sweep",
+			Tags:     map[string]string{"operating-system": "Microsoft Windows Server 2019"},
+		})
+		assert.Equal(t, models.EnvDevelopment, c.Environment)
+		assert.Equal(t, &ownerID, c.OwnerID)
+	})
+
+	t.Run("no rule matches", func(t *testing.T) {
+		c := matcher.Classify(services.ParsedHost{Hostname: "prod-db", IPAddress: "192.168.1.1"})
+		assert.Nil(t, c.EnvironmentRule)
+		assert.Nil(t, c.OwnerRule)
+	})
+
+	t.Run("nil matcher", func(t *testing.T) {
+		var nilMatcher *services.ImportRuleMatcher
+		assert.Nil(t, nilMatcher.Classify(services.ParsedHost{Hostname: "stg-web01"}).EnvironmentRule)
+	})
+}
+
+func TestValidateImportRule(t *testing.T) {
+	rule := &models.ImportRule{Name: "lab", MatchType: models.ImportRuleMatchCIDR, Pattern: "10.50.3.4/16", Environment: models.EnvTest}
+	require.NoError(t, services.ValidateImportRule(rule))
+	assert.Equal(t, "10.50.0.0/16", rule.Pattern, "CIDR is stored in canonical form")
+
+	require.NoError(t, services.ValidateImportRule(&models.ImportRule{Name: "tag", MatchType: models.ImportRuleMatchTag, Pattern: "netbios-name", Environment: models.EnvTest}))
+
+	invalid := []*models.ImportRule{
+		{MatchType: models.ImportRuleMatchHostname, Pattern: "^web", Environment: models.EnvTest},
+		{Name: "no pattern", MatchType: models.ImportRuleMatchHostname, Environment: models.EnvTest},
+		{Name: "bad type", MatchType: "mac", Pattern: "x", Environment: models.EnvTest},
+		{Name: "bad regex", MatchType: models.ImportRuleMatchScanName, Pattern: "[", Environment: models.EnvTest},
+		{Name: "bad cidr", MatchType: models.ImportRuleMatchCIDR, Pattern: "10.0.0.0", Environment: models.EnvTest},
+		{Name: "empty tag", MatchType: models.ImportRuleMatchTag, Pattern: "=x", Environment: models.EnvTest},
+		{Name: "bad env", MatchType: models.ImportRuleMatchHostname, Pattern: "^web", Environment: "QA"},
+		{Name: "assigns nothing", MatchType: models.ImportRuleMatchHostname, Pattern: "^web"},
+	}
+	for _, rule := range invalid {
+		assert.Error(t, services.ValidateImportRule(rule), rule.Name)
+	}
+}
+
+func TestParseNessusFileHostContext(t *testing.T) {
+	vulns, err := services.NewNessusParserService().ParseNessusFile([]byte(`<?xml version="1.0"?>
+<NessusClientData_v2>
+  <Report name="Staging weekly">
+    <ReportHost name="10.0.0.5">
+      <HostProperties>
+        <tag name="host-ip">10.0.0.5</tag>
+        <tag name="operating-system">Linux Kernel 5.4</tag>
+      </HostProperties>
+      <ReportItem port="22" svc_name="ssh" protocol="tcp" severity="2" pluginID="1001" pluginName="SSH Weak Algorithms" pluginFamily="Misc.">
+        <risk_factor>Medium</risk_factor>
+      </ReportItem>
+    </ReportHost>
+  </Report>
+</NessusClientData_v2>`))
+	require.NoError(t, err)
+	require.Len(t, vulns, 1)
+	require.Len(t, vulns[0].AffectedHosts, 1)
+	host := vulns[0].AffectedHosts[0]
+	assert.Equal(t, "Staging weekly", host.ScanName)
+	assert.Equal(t, "Linux Kernel 5.4", host.Tags["operating-system"])
+	assert.Equal(t, models.ScannerSeverityMedium, vulns[0].ScannerSeverity)
+}
@@ -0,0 +1,80 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportHostValidatorCheck(t *testing.T) {
+	validator := services.NewImportHostValidator(services.ImportValidationSettings{
+		Enabled:            true,
+		QuarantineTestData: true,
+		ScopeCIDRs:         []string{"10.0.0.0/8"},
+	})
+
+	tests := []struct {
+		name   string
+		host   services.ParsedHost
+		reason models.QuarantineReason
+	}{
+		{"in scope", services.ParsedHost{Hostname: "web01", IPAddress: "10.1.2.3"}, ""},
+		{"hostname only", services.ParsedHost{Hostname: "web01.corp.local"}, ""},
+		{"no identity", services.ParsedHost{Hostname: "  "}, models.QuarantineMissingIdentity},
+		{"loopback", services.ParsedHost{IPAddress: "127.0.0.1"}, models.QuarantineTestData},
+		{"documentation range", services.ParsedHost{IPAddress: "192.0.2.10"}, models.QuarantineTestData},
+		{"example domain", services.ParsedHost{Hostname: "Host.Example.com.", IPAddress: "10.0.0.1"}, models.QuarantineTestData},
+		{"out of scope", services.ParsedHost{Hostname: "db01", IPAddress: "172.16.0.5"}, models.QuarantineOutOfScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, _ := validator.Check(tt.host)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		disabled := services.NewImportHostValidator(services.ImportValidationSettings{})
+		reason, _ := disabled.Check(services.ParsedHost{})
+		assert.Empty(t, reason)
+	})
+}
+
+func TestImportHostValidatorSplit(t *testing.T) {
+	validator := services.NewImportHostValidator(*services.DefaultImportValidationSettings())
+	good := services.ParsedHost{Hostname: "web01", IPAddress: "10.0.0.1"}
+	bad := services.ParsedHost{Hostname: "localhost", IPAddress: "127.0.0.1"}
+
+	kept, hosts, results := validator.Split([]services.ParsedVulnerability{
+		{Title: "shared", AffectedHosts: []services.ParsedHost{good, bad}},
+		{Title: "bad only", AffectedHosts: []services.ParsedHost{bad}},
+		{Title: "good only", AffectedHosts: []services.ParsedHost{good}},
+	})
+
+	require.Len(t, kept, 2)
+	assert.Equal(t, "shared", kept[0].Title)
+	assert.Equal(t, []services.ParsedHost{good}, kept[0].AffectedHosts)
+	assert.Equal(t, "good only", kept[1].Title)
+
+	require.Len(t, hosts, 1)
+	assert.Equal(t, "127.0.0.1", hosts[0].IPAddress)
+	assert.Equal(t, models.QuarantineTestData, hosts[0].Reason)
+	assert.Equal(t, 2, hosts[0].Vulnerabilities)
+	require.Len(t, results[0], 2)
+	assert.Equal(t, []services.ParsedHost{bad}, results[0][0].AffectedHosts)
+}
+
+func TestParseImportValidationSettings(t *testing.T) {
+	settings, err := services.ParseImportValidationSettings(`{"enabled":true,"scope_cidrs":["10.1.2.3/16"]}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.1.0.0/16"}, settings.ScopeCIDRs)
+	assert.False(t, settings.QuarantineTestData)
+
+	_, err = services.ParseImportValidationSettings(`{"scope_cidrs":["10.0.0.0"]}`)
+	assert.Error(t, err)
+	_, err = services.ParseImportValidationSettings(`not json`)
+	assert.Error(t, err)
+}
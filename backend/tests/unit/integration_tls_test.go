@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func selfSignedClientCert(t *testing.T) (certPEM, keyPEM string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "cyops"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return certPEM, keyPEM
+}
+
+func TestIntegrationTLSConfig(t *testing.T) {
+	tlsConfig, err := services.IntegrationTLSConfig(&models.IntegrationConfig{})
+	require.NoError(t, err)
+	assert.False(t, tlsConfig.InsecureSkipVerify, "verification is on by default")
+
+	tlsConfig, err = services.IntegrationTLSConfig(&models.IntegrationConfig{TLSSkipVerify: true})
+	require.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+
+	_, err = services.IntegrationTLSConfig(&models.IntegrationConfig{CACertPEM: "not a certificate"})
+	assert.ErrorContains(t, err, "invalid TLS settings")
+
+	certPEM, keyPEM := selfSignedClientCert(t)
+	_, err = services.IntegrationTLSConfig(&models.IntegrationConfig{ClientCertPEM: certPEM})
+	assert.ErrorContains(t, err, "must be set together")
+
+	tlsConfig, err = services.IntegrationTLSConfig(&models.IntegrationConfig{ClientCertPEM: certPEM, ClientKeyPEM: keyPEM})
+	require.NoError(t, err)
+	assert.Len(t, tlsConfig.Certificates, 1)
+
+	_, otherKeyPEM := selfSignedClientCert(t)
+	_, err = services.IntegrationTLSConfig(&models.IntegrationConfig{ClientCertPEM: certPEM, ClientKeyPEM: otherKeyPEM})
+	assert.ErrorContains(t, err, "client certificate")
+}
+
+func TestIntegrationTLSConfigCustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	connect := func(config *models.IntegrationConfig) error {
+		tlsConfig, err := services.IntegrationTLSConfig(config)
+		require.NoError(t, err)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	err := connect(&models.IntegrationConfig{})
+	require.Error(t, err)
+	assert.Contains(t, services.DescribeTLSError(err).Error(), "not signed by a trusted CA")
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	assert.NoError(t, connect(&models.IntegrationConfig{CACertPEM: caPEM}))
+	assert.NoError(t, connect(&models.IntegrationConfig{TLSSkipVerify: true}))
+}
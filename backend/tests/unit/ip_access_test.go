@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCIDRListNormalizes(t *testing.T) {
+	cidrs, err := services.ParseCIDRList([]string{" 10.0.0.7/8 ", "203.0.113.5", "2001:db8::1", "", "10.0.0.0/8"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "203.0.113.5/32", "2001:db8::1/128"}, cidrs)
+
+	_, err = services.ParseCIDRList([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = services.ParseCIDRList([]string{"example.com"})
+	assert.Error(t, err)
+}
+
+func TestIPAccessListAllows(t *testing.T) {
+	list, err := services.ParseIPAccessList(`{"allow":["10.0.0.0/8","2001:db8::/32"],"deny":["10.1.0.0/16"]}`)
+	require.NoError(t, err)
+
+	assert.True(t, list.Allows("10.2.3.4"))
+	assert.True(t, list.Allows("2001:db8::42"))
+	assert.False(t, list.Allows("10.1.2.3"), "deny takes precedence over allow")
+	assert.False(t, list.Allows("192.168.1.1"), "addresses outside the allowlist are refused")
+	assert.False(t, list.Allows("not-an-ip"))
+
+	denyOnly, err := services.ParseIPAccessList(`{"deny":["198.51.100.0/24"]}`)
+	require.NoError(t, err)
+	assert.True(t, denyOnly.Allows("192.168.1.1"), "an empty allowlist admits any address")
+	assert.False(t, denyOnly.Allows("198.51.100.9"))
+
+	_, err = services.ParseIPAccessList(`{"allow":["bogus"]}`)
+	assert.Error(t, err)
+}
+
+func TestCIDRListContains(t *testing.T) {
+	cidrs := []string{"203.0.113.0/24"}
+	assert.True(t, services.CIDRListContains(cidrs, "203.0.113.200"))
+	assert.False(t, services.CIDRListContains(cidrs, "203.0.114.1"))
+	assert.False(t, services.CIDRListContains(nil, "203.0.113.200"))
+}
+
+func TestAdminIPAccessOnlyThroughLockoutCheck(t *testing.T) {
+	// Both refusals happen before the database is touched
+	service := services.NewSystemSettingsService(nil)
+
+	_, err := service.UpdateSetting("admin_ip_access", `{"allow":["192.0.2.0/24"]}`, "", "admin@example.com")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid setting")
+	}
+
+	list, err := services.ParseIPAccessList(`{"allow":["192.0.2.0/24"]}`)
+	require.NoError(t, err)
+	_, err = service.UpdateAdminIPAccess(list, "198.51.100.7", "admin@example.com")
+	assert.ErrorIs(t, err, services.ErrAdminIPLockout)
+}
@@ -0,0 +1,190 @@
+package unit
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/kms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomLocalProvider(t *testing.T) *kms.LocalProvider {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	provider, err := kms.NewLocalProvider(key)
+	require.NoError(t, err)
+	return provider
+}
+
+func TestKeyringEnvelopeEncryption(t *testing.T) {
+	ctx := context.Background()
+	provider := randomLocalProvider(t)
+	keyring := kms.NewKeyring(provider)
+
+	first, err := keyring.Encrypt(ctx, "s3cr3t")
+	require.NoError(t, err)
+	second, err := keyring.Encrypt(ctx, "s3cr3t")
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second, "every secret gets its own data key")
+	assert.NotContains(t, first, "s3cr3t")
+	assert.True(t, keyring.IsCurrent(first))
+
+	plaintext, err := keyring.Decrypt(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", plaintext)
+
+	// A keyring without the key cannot open the secret
+	_, err = kms.NewKeyring(randomLocalProvider(t)).Decrypt(ctx, first)
+	assert.ErrorContains(t, err, "unknown key")
+}
+
+func TestKeyringRotation(t *testing.T) {
+	ctx := context.Background()
+	oldProvider := randomLocalProvider(t)
+	ciphertext, err := kms.NewKeyring(oldProvider).Encrypt(ctx, "access-key")
+	require.NoError(t, err)
+
+	rotated := kms.NewKeyring(randomLocalProvider(t), oldProvider)
+	assert.False(t, rotated.IsCurrent(ciphertext))
+	plaintext, err := rotated.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "access-key", plaintext)
+
+	reencrypted, err := rotated.Encrypt(ctx, plaintext)
+	require.NoError(t, err)
+	assert.True(t, rotated.IsCurrent(reencrypted))
+}
+
+func TestKeyringLegacySecrets(t *testing.T) {
+	// Credentials stored before envelope encryption: AES-256-GCM under the zero padded JWT secret
+	legacyKey := services.LegacySecretKey("dev-jwt-secret")
+	block, err := aes.NewCipher(legacyKey)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := make([]byte, gcm.NonceSize())
+	legacy := base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte("legacy-secret"), nil))
+
+	keyring := kms.NewKeyring(randomLocalProvider(t))
+	_, err = keyring.Decrypt(context.Background(), legacy)
+	assert.Error(t, err)
+
+	keyring.WithLegacyKey(legacyKey)
+	plaintext, err := keyring.Decrypt(context.Background(), legacy)
+	require.NoError(t, err)
+	assert.Equal(t, "legacy-secret", plaintext)
+	assert.False(t, keyring.IsCurrent(legacy))
+}
+
+func TestKMSConfig(t *testing.T) {
+	keyring, err := kms.New(kms.Config{FallbackSecret: "encryption-key"})
+	require.NoError(t, err)
+	assert.Equal(t, kms.ProviderLocal, keyring.Active().Name())
+	derived, err := keyring.Encrypt(context.Background(), "secret")
+	require.NoError(t, err)
+
+	// Secrets under the derived key stay readable once a dedicated key is configured
+	dedicated, err := kms.New(kms.Config{
+		LocalKey:       base64.StdEncoding.EncodeToString(make([]byte, 32)),
+		FallbackSecret: "encryption-key",
+	})
+	require.NoError(t, err)
+	assert.False(t, dedicated.IsCurrent(derived))
+	plaintext, err := dedicated.Decrypt(context.Background(), derived)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plaintext)
+
+	_, err = kms.New(kms.Config{LocalKey: "dG9vIHNob3J0"})
+	assert.Error(t, err)
+	_, err = kms.New(kms.Config{Provider: kms.ProviderVault, FallbackSecret: "encryption-key"})
+	assert.ErrorContains(t, err, "not configured")
+	_, err = kms.New(kms.Config{Provider: "hsm"})
+	assert.ErrorContains(t, err, "unknown kms provider")
+}
+
+func TestVaultProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/cyops":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{"ciphertext": "vault:v1:" + body["plaintext"]},
+			})
+		case "/v1/transit/decrypt/cyops":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:")},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := kms.NewVaultProvider(kms.VaultConfig{Address: server.URL, Token: "vault-token", KeyName: "cyops"})
+	require.NoError(t, err)
+	assert.Equal(t, "transit/cyops", provider.KeyID())
+
+	keyring := kms.NewKeyring(provider)
+	ciphertext, err := keyring.Encrypt(context.Background(), "nessus-secret")
+	require.NoError(t, err)
+	plaintext, err := keyring.Decrypt(context.Background(), ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "nessus-secret", plaintext)
+}
+
+func TestAWSProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-amz-json-1.1", r.Header.Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20261017/eu-west-1/kms/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-target, Signature="))
+
+		var body struct {
+			KeyId          string
+			Plaintext      []byte
+			CiphertextBlob []byte
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "alias/cyops-secrets", body.KeyId)
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.Encrypt":
+			json.NewEncoder(w).Encode(map[string][]byte{"CiphertextBlob": append([]byte("kms:"), body.Plaintext...)})
+		case "TrentService.Decrypt":
+			json.NewEncoder(w).Encode(map[string][]byte{"Plaintext": body.CiphertextBlob[4:]})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := kms.NewAWSProvider(kms.AWSConfig{
+		Region:          "eu-west-1",
+		KeyID:           "alias/cyops-secrets",
+		Endpoint:        server.URL,
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Now:             func() time.Time { return time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC) },
+	})
+	require.NoError(t, err)
+
+	keyring := kms.NewKeyring(provider)
+	ciphertext, err := keyring.Encrypt(context.Background(), "qualys-password")
+	require.NoError(t, err)
+	plaintext, err := keyring.Decrypt(context.Background(), ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "qualys-password", plaintext)
+}
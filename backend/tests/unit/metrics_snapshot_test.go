@@ -0,0 +1,72 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSumFlowsEmptyRange(t *testing.T) {
+	// A range within a single day has no whole days after its start, so nothing is queried
+	service := services.NewMetricsSnapshotService(nil)
+	now := time.Now()
+
+	totals, complete, err := service.SumFlows(now, now)
+	require.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, &services.MetricsFlowTotals{}, totals)
+}
+
+func TestRefreshSnapshotsBackfillsAndSums(t *testing.T) {
+	db, user := setupSchemaDB(t)
+	if db == nil {
+		return
+	}
+	service := services.NewMetricsSnapshotService(db)
+	now := time.Now().UTC()
+
+	org := &models.Organization{Name: "Acme", Slug: "acme", Active: true}
+	require.NoError(t, db.Create(org).Error)
+
+	// Found today, and one resolved yesterday a day after it was found
+	require.NoError(t, db.Create(&models.Vulnerability{
+		OrgID: &org.ID, Title: "RCE in web app", Description: "RCE", Severity: models.SeverityCritical,
+		Status: models.StatusOpen, DiscoveryDate: now, CreatedByID: user.ID,
+		BaseModel: models.BaseModel{CreatedAt: now, UpdatedAt: now},
+	}).Error)
+	resolvedAt := now.AddDate(0, 0, -1)
+	require.NoError(t, db.Create(&models.Vulnerability{
+		OrgID: &org.ID, Title: "Weak TLS", Description: "TLS", Severity: models.SeverityHigh,
+		Status: models.StatusResolved, DiscoveryDate: now, CreatedByID: user.ID,
+		BaseModel: models.BaseModel{CreatedAt: resolvedAt.Add(-24 * time.Hour), UpdatedAt: resolvedAt},
+	}).Error)
+
+	require.NoError(t, service.RefreshSnapshots(now))
+	var rows int64
+	require.NoError(t, db.Model(&models.DailyMetricsSnapshot{}).Count(&rows).Error)
+	assert.Equal(t, int64(services.MetricsBackfillDays+1), rows, "every day in the backfill window has a row")
+
+	// Refreshing again only updates today and yesterday
+	require.NoError(t, service.RefreshSnapshots(now))
+	require.NoError(t, db.Model(&models.DailyMetricsSnapshot{}).Count(&rows).Error)
+	assert.Equal(t, int64(services.MetricsBackfillDays+1), rows)
+
+	totals, complete, err := service.SumFlows(now.AddDate(0, 0, -3), now)
+	require.NoError(t, err)
+	require.True(t, complete)
+	assert.Equal(t, int64(2), totals.NewVulnerabilities)
+	assert.Equal(t, int64(1), totals.NewCritical)
+	assert.Equal(t, int64(1), totals.ResolvedVulnerabilities)
+	assert.InDelta(t, 24, totals.MTTRHours, 0.01)
+
+	snapshots, err := service.ListSnapshots(1)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.False(t, snapshots[0].Backfilled, "today's snapshot has point-in-time counts")
+	assert.Equal(t, int64(1), snapshots[0].CriticalCount)
+	assert.Equal(t, int64(1), snapshots[0].ResolvedCount)
+}
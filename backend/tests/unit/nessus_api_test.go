@@ -0,0 +1,145 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNessusAgentScanRuns(t *testing.T) {
+	var detail services.NessusScanDetail
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"info": {"name": "Laptops", "status": "running", "agent_targets": [{"id": 3, "name": "Laptops"}]},
+		"history": [
+			{"history_id": 11, "status": "completed", "last_modification_date": 1700000000},
+			{"history_id": 12, "status": "completed", "last_modification_date": 1700600000},
+			{"history_id": 13, "status": "running", "last_modification_date": 1701200000}
+		]
+	}`), &detail))
+
+	assert.True(t, detail.IsAgentScan())
+	run := detail.LatestCompletedRun()
+	require.NotNil(t, run)
+	assert.Equal(t, 12, run.HistoryID)
+
+	remote := services.NessusScanDetail{Info: services.NessusScanInfo{ScanType: "remote"}}
+	assert.False(t, remote.IsAgentScan())
+	assert.Nil(t, remote.LatestCompletedRun())
+}
+
+func TestNessusTrashFolderID(t *testing.T) {
+	var folders services.NessusFolderList
+	require.NoError(t, json.Unmarshal([]byte(`{"folders": [
+		{"id": 2, "name": "Trash", "type": "trash"},
+		{"id": 3, "name": "My Scans", "type": "main", "default_tag": 1},
+		{"id": 7, "name": "Agents", "type": "custom", "custom": 1}
+	]}`), &folders))
+
+	assert.Equal(t, 2, services.TrashFolderID(folders.Folders))
+	assert.Equal(t, 0, services.TrashFolderID(folders.Folders[1:]))
+}
+
+func TestNormalizeNessusConfig(t *testing.T) {
+	cloud := &models.IntegrationConfig{Type: models.IntegrationTypeNessus, Config: map[string]interface{}{"mode": "tenable_io"}}
+	require.NoError(t, services.NormalizeNessusConfig(cloud))
+	assert.Equal(t, services.TenableIOBaseURL, cloud.BaseURL)
+	assert.Equal(t, services.NessusModeTenableIO, services.NessusMode(cloud))
+
+	onPrem := &models.IntegrationConfig{Type: models.IntegrationTypeNessus, BaseURL: "https://nessus.local:8834/"}
+	require.NoError(t, services.NormalizeNessusConfig(onPrem))
+	assert.Equal(t, "https://nessus.local:8834", onPrem.BaseURL)
+	assert.Equal(t, services.NessusModeOnPrem, services.NessusMode(onPrem))
+
+	insecure := &models.IntegrationConfig{BaseURL: "http://cloud.tenable.com", Config: map[string]interface{}{"mode": "tenable_io"}}
+	assert.Error(t, services.NormalizeNessusConfig(insecure))
+
+	unverified := &models.IntegrationConfig{TLSSkipVerify: true, Config: map[string]interface{}{"mode": "tenable_io"}}
+	assert.Error(t, services.NormalizeNessusConfig(unverified))
+
+	unknown := &models.IntegrationConfig{BaseURL: "https://scanner", Config: map[string]interface{}{"mode": "securitycenter"}}
+	assert.Error(t, services.NormalizeNessusConfig(unknown))
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, services.RetryAfterDelay("30", 0))
+	assert.Equal(t, time.Duration(0), services.RetryAfterDelay("0", 3))
+	assert.Equal(t, 4*time.Second, services.RetryAfterDelay("", 2))
+	assert.Equal(t, time.Minute, services.RetryAfterDelay("", 10))
+	assert.Equal(t, time.Minute, services.RetryAfterDelay("soon", 100))
+}
+
+func TestParsedVulnerabilitiesFromTenable(t *testing.T) {
+	var records []services.TenableVulnRecord
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"asset": {"fqdn": "web01.example.com", "ipv4": "10.0.0.5", "operating_system": ["Ubuntu 22.04"]},
+		 "plugin": {"id": 156032, "name": "Apache Log4j RCE", "synopsis": "Remote code execution", "solution": "Upgrade", "cve": ["CVE-2021-44228"], "cvss3_base_score": 10.0, "cvss3_vector": {"raw": "AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"}},
+		 "port": {"port": 8443, "protocol": "TCP", "service": "www"},
+		 "severity_id": 4, "state": "OPEN", "last_found": "2026-09-01T10:00:00Z"},
+		{"asset": {"hostname": "db01", "ipv4": "10.0.0.6"},
+		 "plugin": {"id": 156032, "name": "Apache Log4j RCE", "cve": ["CVE-2021-44228"], "cvss3_base_score": 10.0},
+		 "port": {"port": 0, "protocol": "TCP"},
+		 "severity_id": 4, "state": "REOPENED", "last_found": "2026-09-02T10:00:00Z"},
+		{"asset": {"ipv4": "10.0.0.7"},
+		 "plugin": {"id": 51192, "name": "SSL Certificate Cannot Be Trusted", "cvss_base_score": 6.4},
+		 "port": {"port": 443, "protocol": "TCP"},
+		 "severity_id": 2, "state": "OPEN", "last_found": "2026-09-01T10:00:00Z"},
+		{"asset": {"ipv4": "10.0.0.7"},
+		 "plugin": {"id": 19506, "name": "Nessus Scan Information"},
+		 "severity_id": 0, "state": "OPEN", "last_found": "2026-09-01T10:00:00Z"}
+	]`), &records))
+
+	vulns := services.ParsedVulnerabilitiesFromTenable(records)
+	require.Len(t, vulns, 2)
+
+	ssl := vulns[0]
+	assert.Equal(t, "51192", ssl.PluginID)
+	assert.Equal(t, models.SeverityMedium, ssl.Severity)
+	require.NotNil(t, ssl.CVSSScore)
+	assert.Equal(t, 6.4, *ssl.CVSSScore)
+	require.Len(t, ssl.AffectedHosts, 1)
+	assert.Equal(t, "10.0.0.7", ssl.AffectedHosts[0].Hostname)
+
+	log4j := vulns[1]
+	assert.Equal(t, "156032", log4j.PluginID)
+	assert.Equal(t, "CVE-2021-44228", log4j.CVEID)
+	assert.Equal(t, models.SeverityCritical, log4j.Severity)
+	assert.Equal(t, "Remote code execution", log4j.Description)
+	assert.Equal(t, "AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", log4j.CVSSVector)
+	assert.Equal(t, time.Date(2026, 9, 2, 10, 0, 0, 0, time.UTC), log4j.ScanDate)
+	require.Len(t, log4j.AffectedHosts, 2)
+	assert.Equal(t, "web01.example.com", log4j.AffectedHosts[0].Hostname)
+	assert.Equal(t, "8443", log4j.AffectedHosts[0].Port)
+	assert.Equal(t, "tcp", log4j.AffectedHosts[0].Protocol)
+	assert.Equal(t, "Ubuntu 22.04", log4j.AffectedHosts[0].OS)
+	assert.Equal(t, "db01", log4j.AffectedHosts[1].Hostname)
+}
+
+func TestImportMultipleScansCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var last services.ScanImportProgress
+	calls := 0
+	results, errs := services.NewNessusAPIService(nil).WithContext(ctx).ImportMultipleScans(uuid.New(), []int{1, 2, 3}, func(progress services.ScanImportProgress) {
+		calls++
+		last = progress
+	})
+
+	assert.Empty(t, results)
+	require.Len(t, errs, 3)
+	for _, err := range errs {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 3, last.Total)
+	assert.Equal(t, 3, last.Failed)
+	assert.Zero(t, last.Completed)
+	assert.Equal(t, map[int]string{1: services.ScanImportFailed, 2: services.ScanImportFailed, 3: services.ScanImportFailed}, last.Phases)
+}
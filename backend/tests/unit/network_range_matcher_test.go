@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkRangeMatcher(t *testing.T) {
+	ranges := []models.NetworkRange{
+		{Name: "corporate", CIDR: "10.0.0.0/8", Environment: models.EnvProduction},
+		{Name: "staging", CIDR: "10.20.0.0/16", Environment: models.EnvStaging},
+		{Name: "build farm", CIDR: "10.20.30.0/24", Environment: models.EnvDevelopment},
+		{Name: "v6 lab", CIDR: "2001:db8::/32", Environment: models.EnvTest},
+		{Name: "broken", CIDR: "not-a-cidr", Environment: models.EnvTest},
+	}
+	matcher := services.NewNetworkRangeMatcher(ranges)
+	assert.Equal(t, 4, matcher.Len())
+
+	tests := []struct {
+		name     string
+		ip       string
+		expected string
+	}{
+		{"most specific wins", "10.20.30.7", "build farm"},
+		{"middle range", "10.20.1.1", "staging"},
+		{"widest range", "10.99.0.1", "corporate"},
+		{"ipv6", "2001:db8::42", "v6 lab"},
+		{"outside every range", "192.168.1.1", ""},
+		{"not an ip", "host.example.com", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := matcher.Match(tt.ip)
+			if tt.expected == "" {
+				assert.Nil(t, r)
+				return
+			}
+			require.NotNil(t, r)
+			assert.Equal(t, tt.expected, r.Name)
+		})
+	}
+}
+
+func TestNilNetworkRangeMatcher(t *testing.T) {
+	var matcher *services.NetworkRangeMatcher
+	assert.Nil(t, matcher.Match("10.0.0.1"))
+}
+
+func TestValidateNetworkRange(t *testing.T) {
+	r := &models.NetworkRange{Name: "dmz", CIDR: "172.16.5.9/24", Environment: models.EnvProduction}
+	require.NoError(t, services.ValidateNetworkRange(r))
+	assert.Equal(t, "172.16.5.0/24", r.CIDR, "CIDR is stored in canonical form")
+
+	assert.Error(t, services.ValidateNetworkRange(&models.NetworkRange{CIDR: "10.0.0.0/8", Environment: models.EnvProduction}))
+	assert.Error(t, services.ValidateNetworkRange(&models.NetworkRange{Name: "bad", CIDR: "10.0.0.0", Environment: models.EnvProduction}))
+	assert.Error(t, services.ValidateNetworkRange(&models.NetworkRange{Name: "bad env", CIDR: "10.0.0.0/8", Environment: "QA"}))
+}
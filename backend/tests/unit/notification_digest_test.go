@@ -0,0 +1,56 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigestSlot(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// Wednesday 2026-03-04 06:00 in Tokyo, before the 08:00 send time
+	now := time.Date(2026, 3, 3, 21, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 3, 3, 8, 0, 0, 0, tokyo), services.DigestSlot(models.NotificationDigestDaily, tokyo, now))
+	assert.Equal(t, time.Date(2026, 3, 2, 8, 0, 0, 0, tokyo), services.DigestSlot(models.NotificationDigestWeekly, tokyo, now), "latest Monday")
+
+	// Monday 08:00 exactly
+	monday := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, monday, services.DigestSlot(models.NotificationDigestWeekly, time.UTC, monday))
+	assert.Equal(t, monday.AddDate(0, 0, -7), services.DigestSlot(models.NotificationDigestWeekly, time.UTC, monday.Add(-time.Minute)))
+}
+
+func TestDigestDue(t *testing.T) {
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	pref := models.DefaultUserPreference(uuid.New())
+
+	due, _ := services.DigestDue(pref, now)
+	assert.False(t, due, "digests are off by default")
+
+	pref.NotificationDigest = models.NotificationDigestDaily
+	due, since := services.DigestDue(pref, now)
+	assert.True(t, due, "first digest")
+	assert.Equal(t, time.Date(2026, 3, 3, 8, 0, 0, 0, time.UTC), since, "first digest covers one period")
+
+	sentAt := time.Date(2026, 3, 4, 8, 5, 0, 0, time.UTC)
+	pref.LastDigestAt = &sentAt
+	due, _ = services.DigestDue(pref, now)
+	assert.False(t, due, "already sent today")
+
+	due, since = services.DigestDue(pref, now.AddDate(0, 0, 1))
+	assert.True(t, due)
+	assert.Equal(t, sentAt, since, "picks up where the last digest stopped")
+}
+
+func TestNotificationDigestEmpty(t *testing.T) {
+	digest := &services.NotificationDigestContent{}
+	assert.True(t, digest.Empty())
+	digest.SLATotal = 1
+	assert.False(t, digest.Empty())
+}
@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOnboardingSettings(t *testing.T) {
+	settings, err := services.ParseOnboardingSettings(`{"policies":[{"key":"terms_of_use","version":" 2026-10 ","required":true}]}`)
+	require.NoError(t, err)
+	require.Len(t, settings.Policies, 1)
+	assert.Equal(t, "2026-10", settings.Policies[0].Version)
+	assert.Equal(t, "terms_of_use", settings.Policies[0].Title, "the key is the default title")
+
+	for _, value := range []string{
+		`{"policies":[{"key":"Terms","version":"1"}]}`,
+		`{"policies":[{"key":"terms","version":""}]}`,
+		`{"policies":[{"key":"terms","version":"1"},{"key":"terms","version":"2"}]}`,
+		`not json`,
+	} {
+		_, err := services.ParseOnboardingSettings(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestBuildOnboardingState(t *testing.T) {
+	user := &models.User{Name: ""}
+	policies := []services.OnboardingPolicy{
+		{Key: "terms_of_use", Version: "2026-10", Required: true},
+		{Key: "newsletter", Version: "1"},
+	}
+	acceptances := []models.PolicyAcceptance{
+		{Policy: "terms_of_use", Version: "2026-01", AcceptedAt: time.Now().AddDate(0, -9, 0)},
+	}
+
+	state := services.BuildOnboardingState(user, policies, acceptances, nil)
+	assert.False(t, state.Complete)
+	assert.False(t, state.Policies[0].Accepted, "an older version does not count")
+	assert.Equal(t, "2026-01", state.Policies[0].AcceptedVersion)
+	require.Len(t, state.Steps, 2)
+	assert.Equal(t, models.OnboardingStepPending, state.Steps[0].Status)
+	assert.True(t, state.Steps[0].Skippable)
+
+	// Accepting the current version, skipping the 2FA prompt and naming the profile completes
+	// onboarding; optional policies do not hold it up
+	user.Name = "Alex"
+	acceptances = append(acceptances, models.PolicyAcceptance{Policy: "terms_of_use", Version: "2026-10", AcceptedAt: time.Now()})
+	steps := []models.OnboardingStepState{{Step: models.OnboardingStepTwoFactor, Status: models.OnboardingStepSkipped}}
+	state = services.BuildOnboardingState(user, policies, acceptances, steps)
+	assert.True(t, state.Complete)
+	assert.True(t, state.Policies[0].Accepted)
+	assert.Equal(t, models.OnboardingStepSkipped, state.Steps[0].Status)
+	assert.Equal(t, models.OnboardingStepCompleted, state.Steps[1].Status)
+
+	// Enabling 2FA completes the step whatever was stored
+	user.TwoFactorEnabled = true
+	state = services.BuildOnboardingState(user, policies, acceptances, steps)
+	assert.Equal(t, models.OnboardingStepCompleted, state.Steps[0].Status)
+}
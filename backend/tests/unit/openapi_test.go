@@ -0,0 +1,157 @@
+package unit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/pkg/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type openAPITestBase struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type openAPITestWidget struct {
+	openAPITestBase
+	Name     string             `json:"name"`
+	Count    *int               `json:"count,omitempty"`
+	Tags     []string           `json:"tags"`
+	Parent   *openAPITestWidget `json:"parent,omitempty"`
+	Internal string             `json:"-"`
+	hidden   string
+}
+
+type openAPITestQuery struct {
+	Page   int    `query:"page"`
+	Search string `query:"search"`
+}
+
+func openAPITestAuth(c *fiber.Ctx) error { return c.Next() }
+
+func openAPITestGetWidget(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+
+func openAPITestCreateWidget(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusCreated) }
+
+func TestOpenAPISchemaReflection(t *testing.T) {
+	schemas := openapi.NewSchemas()
+	ref := schemas.SchemaOf(reflect.TypeOf(&openAPITestWidget{}))
+	assert.Equal(t, "#/components/schemas/unit.openAPITestWidget", ref.Ref)
+
+	widget := schemas.Components["unit.openAPITestWidget"]
+	require.NotNil(t, widget)
+	assert.ElementsMatch(t, []string{"id", "created_at", "name", "count", "tags", "parent"}, mapKeys(widget.Properties))
+	assert.Equal(t, "uuid", widget.Properties["id"].Format)
+	assert.Equal(t, "date-time", widget.Properties["created_at"].Format)
+	assert.Equal(t, "integer", widget.Properties["count"].Type)
+	assert.Equal(t, "string", widget.Properties["tags"].Items.Type)
+	// Self references resolve to the component instead of recursing
+	assert.Equal(t, ref.Ref, widget.Properties["parent"].Ref)
+
+	free := schemas.SchemaOf(reflect.TypeOf(map[string]interface{}{}))
+	assert.Equal(t, "object", free.Type)
+	assert.NotNil(t, free.AdditionalProperties)
+}
+
+func TestOpenAPIRoutesIncludeGroupMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Get("/health", openAPITestGetWidget)
+	api := app.Group("/api/v1")
+	widgets := api.Group("/widgets")
+	widgets.Use(openAPITestAuth)
+	widgets.Get("/:id", openAPITestGetWidget)
+
+	routes := openapi.Routes(app)
+	var found bool
+	for _, route := range routes {
+		if route.Method == fiber.MethodGet && route.Path == "/api/v1/widgets/:id" {
+			found = true
+			require.Len(t, route.Handlers, 2)
+			assert.Equal(t, "unit.openAPITestAuth", openapi.HandlerName(route.Handlers[0]))
+			assert.Equal(t, "unit.openAPITestGetWidget", openapi.HandlerName(route.Handlers[1]))
+		}
+		if route.Path == "/health" {
+			assert.Len(t, route.Handlers, 1)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestOpenAPIGenerate(t *testing.T) {
+	app := fiber.New()
+	app.Get("/health", openAPITestGetWidget)
+	widgets := app.Group("/api/v1/widgets")
+	widgets.Use(openAPITestAuth)
+	widgets.Get("/", openAPITestGetWidget)
+	widgets.Post("/", openAPITestCreateWidget)
+	widgets.Get("/:id/children/:childId", openAPITestGetWidget)
+
+	doc := openapi.Generate(openapi.Routes(app), openapi.Options{
+		Info:     openapi.Info{Title: "Test", Version: "1.0.0"},
+		BasePath: "/api/v1",
+		Annotations: map[string]openapi.Annotation{
+			"unit.openAPITestCreateWidget": {
+				Summary: "Create widget",
+				Params: []openapi.ParamAnnotation{
+					{In: "body", Required: true, Model: reflect.TypeOf(openAPITestWidget{})},
+				},
+				Responses: []openapi.ResponseAnnotation{
+					{Status: 201, Model: reflect.TypeOf(openAPITestWidget{})},
+					{Status: 400, Model: reflect.TypeOf(middleware.ErrorResponse{})},
+				},
+			},
+			"unit.openAPITestGetWidget": {
+				Params: []openapi.ParamAnnotation{{In: "query", Model: reflect.TypeOf(openAPITestQuery{})}},
+			},
+		},
+		Authenticators:      []string{"unit.openAPITestAuth"},
+		ErrorModel:          reflect.TypeOf(middleware.APIErrorEnvelope{}),
+		AnnotatedErrorModel: reflect.TypeOf(middleware.ErrorResponse{}),
+	})
+
+	// Only routes below the base path, relative to it, with fiber params as OpenAPI params
+	assert.ElementsMatch(t, []string{"/widgets", "/widgets/{id}/children/{childId}"}, mapKeys(doc.Paths))
+	assert.Equal(t, "/api/v1", doc.Servers[0].URL)
+
+	create := doc.Paths["/widgets"]["post"]
+	require.NotNil(t, create)
+	assert.Equal(t, "Create widget", create.Summary)
+	assert.Equal(t, []string{"Widgets"}, create.Tags)
+	assert.Equal(t, "openAPITestCreateWidget", create.OperationID)
+	assert.Equal(t, []map[string][]string{{openapi.BearerAuth: {}}}, create.Security)
+	require.NotNil(t, create.RequestBody)
+	assert.Equal(t, "#/components/schemas/unit.openAPITestWidget", create.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Contains(t, create.Responses, "201")
+	// The annotated legacy error body is documented with the version's error model
+	assert.Equal(t, "#/components/schemas/middleware.APIErrorEnvelope",
+		create.Responses["400"].Content["application/json"].Schema.Ref)
+	assert.NotContains(t, create.Responses, "default")
+
+	list := doc.Paths["/widgets"]["get"]
+	require.NotNil(t, list)
+	var names []string
+	for _, param := range list.Parameters {
+		names = append(names, param.In+":"+param.Name)
+	}
+	assert.Equal(t, []string{"query:page", "query:search"}, names)
+	assert.Contains(t, list.Responses, "default")
+
+	// A handler serving several routes gets unique operation IDs
+	child := doc.Paths["/widgets/{id}/children/{childId}"]["get"]
+	require.NotNil(t, child)
+	assert.NotEqual(t, list.OperationID, child.OperationID)
+	names = nil
+	for _, param := range child.Parameters {
+		if param.In == "path" {
+			names = append(names, param.Name)
+			assert.True(t, param.Required)
+		}
+	}
+	assert.Equal(t, []string{"id", "childId"}, names)
+}
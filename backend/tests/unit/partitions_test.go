@@ -0,0 +1,27 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonthlyPartition(t *testing.T) {
+	name, from, to := database.MonthlyPartition("vulnerability_findings", time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC))
+	assert.Equal(t, "vulnerability_findings_p2026_10", name)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), to)
+
+	// Months roll over the year and bounds are taken in UTC
+	name, from, to = database.MonthlyPartition("finding_status_history", time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC))
+	assert.Equal(t, "finding_status_history_p2026_12", name)
+	assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), to)
+
+	eastern := time.FixedZone("UTC+10", 10*60*60)
+	name, from, _ = database.MonthlyPartition("vulnerability_findings", time.Date(2027, 1, 1, 5, 0, 0, 0, eastern))
+	assert.Equal(t, "vulnerability_findings_p2026_12", name)
+	assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), from)
+}
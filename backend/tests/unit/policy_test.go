@@ -0,0 +1,78 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessConstraintValidate(t *testing.T) {
+	medium := models.CriticalityMedium
+	assert.NoError(t, models.AccessConstraint{
+		Resource:       "vulnerability",
+		Actions:        []string{"write", "status_change"},
+		Environments:   []models.Environment{models.EnvStaging},
+		MaxCriticality: &medium,
+	}.Validate())
+
+	assert.Error(t, models.AccessConstraint{Resource: "report", Environments: []models.Environment{models.EnvStaging}}.Validate())
+	assert.Error(t, models.AccessConstraint{Resource: "asset"}.Validate())
+	assert.Error(t, models.AccessConstraint{Resource: "asset", Actions: []string{"read"}, MaxCriticality: &medium}.Validate())
+	assert.Error(t, models.AccessConstraint{Resource: "asset", Environments: []models.Environment{"MOON"}}.Validate())
+}
+
+func TestPolicyAuthorize(t *testing.T) {
+	medium := models.CriticalityMedium
+	critical := models.CriticalityCritical
+	ctx := policy.WithSubject(context.Background(), &policy.Subject{
+		UserID: uuid.New(),
+		Constraints: []models.AccessConstraint{{
+			Resource:       "vulnerability",
+			Environments:   []models.Environment{models.EnvStaging},
+			MaxCriticality: &medium,
+		}},
+	})
+
+	staging := policy.AssetAttributes{Environment: models.EnvStaging}
+	assert.NoError(t, policy.Authorize(ctx, "vulnerability", "write", []policy.AssetAttributes{staging}))
+
+	// Reads and other resources are not constrained
+	assert.NoError(t, policy.Authorize(ctx, "vulnerability", "read", nil))
+	assert.NoError(t, policy.Authorize(ctx, "asset", "write", nil))
+	assert.False(t, policy.Constrained(ctx, "asset", "delete"))
+
+	production := policy.AssetAttributes{Environment: models.EnvProduction}
+	err := policy.Authorize(ctx, "vulnerability", "write", []policy.AssetAttributes{staging, production})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, policy.ErrDenied))
+
+	tooCritical := policy.AssetAttributes{Environment: models.EnvStaging, Criticality: &critical}
+	assert.ErrorIs(t, policy.Authorize(ctx, "vulnerability", "delete", []policy.AssetAttributes{tooCritical}), policy.ErrDenied)
+
+	// A constrained action on a vulnerability without assets is denied
+	assert.ErrorIs(t, policy.Authorize(ctx, "vulnerability", "write", nil), policy.ErrDenied)
+
+	// Contexts without a subject are not constrained
+	assert.NoError(t, policy.Authorize(context.Background(), "vulnerability", "write", []policy.AssetAttributes{production}))
+}
+
+func TestResolvePermissionsAccumulatesConstraints(t *testing.T) {
+	low := models.CriticalityLow
+	parent := &models.Role{Name: "contractor"}
+	require.NoError(t, parent.SetConstraints([]models.AccessConstraint{{Resource: "asset", MaxCriticality: &low}}))
+	child := &models.Role{Name: "staging_contractor"}
+	require.NoError(t, child.SetConstraints([]models.AccessConstraint{{
+		Resource:     "vulnerability",
+		Environments: []models.Environment{models.EnvStaging},
+	}}))
+
+	effective, err := models.ResolvePermissions([]*models.Role{child, parent})
+	require.NoError(t, err)
+	assert.Len(t, effective.Constraints, 2)
+}
@@ -0,0 +1,77 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePolicyRule(t *testing.T) {
+	high := models.CriticalityHigh
+	bogus := models.AssetCriticality("EXTREME")
+
+	tests := []struct {
+		name    string
+		rule    models.PolicyRule
+		wantErr string
+	}{
+		{"valid production rule", models.PolicyRule{Name: "No old criticals", Severity: models.SeverityCritical, MaxAgeDays: 7, Environment: models.EnvProduction}, ""},
+		{"valid without asset criteria", models.PolicyRule{Name: "Highs", Severity: models.SeverityHigh, MaxAgeDays: 30}, ""},
+		{"valid criticality", models.PolicyRule{Name: "Crown jewels", Severity: models.SeverityMedium, MaxAgeDays: 0, Criticality: &high}, ""},
+		{"missing name", models.PolicyRule{Severity: models.SeverityCritical, MaxAgeDays: 7}, "name is required"},
+		{"invalid severity", models.PolicyRule{Name: "x", Severity: "SEVERE", MaxAgeDays: 7}, "invalid severity"},
+		{"negative age", models.PolicyRule{Name: "x", Severity: models.SeverityLow, MaxAgeDays: -1}, "invalid max_age_days"},
+		{"invalid environment", models.PolicyRule{Name: "x", Severity: models.SeverityLow, MaxAgeDays: 1, Environment: "QA"}, "invalid environment"},
+		{"invalid criticality", models.PolicyRule{Name: "x", Severity: models.SeverityLow, MaxAgeDays: 1, Criticality: &bogus}, "invalid criticality"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := services.ValidatePolicyRule(&tt.rule)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyRuleCutoff(t *testing.T) {
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	rule := &models.PolicyRule{MaxAgeDays: 7}
+	assert.Equal(t, time.Date(2026, 5, 3, 12, 0, 0, 0, time.UTC), services.PolicyRuleCutoff(rule, now))
+}
+
+func TestReconcilePolicyViolations(t *testing.T) {
+	stillViolating, fixed, newlyViolating := uuid.New(), uuid.New(), uuid.New()
+
+	open := []models.PolicyViolation{
+		{VulnerabilityID: stillViolating, Status: models.PolicyViolationOpen},
+		{VulnerabilityID: fixed, Status: models.PolicyViolationOpen},
+	}
+	matches := []services.PolicyMatch{
+		{VulnerabilityID: stillViolating},
+		{VulnerabilityID: newlyViolating},
+		{VulnerabilityID: newlyViolating}, // Vulnerabilities matched twice get one violation
+	}
+
+	create, resolve := services.ReconcilePolicyViolations(open, matches)
+	if assert.Len(t, create, 1) {
+		assert.Equal(t, newlyViolating, create[0].VulnerabilityID)
+	}
+	if assert.Len(t, resolve, 1) {
+		assert.Equal(t, fixed, resolve[0].VulnerabilityID)
+	}
+
+	// A disabled rule matches nothing, so every open violation resolves
+	create, resolve = services.ReconcilePolicyViolations(open, nil)
+	assert.Empty(t, create)
+	assert.Len(t, resolve, 2)
+}
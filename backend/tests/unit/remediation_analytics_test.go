@@ -0,0 +1,152 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeMTTR(t *testing.T) {
+	discovered := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	teamA := uuid.New()
+	sample := func(severity models.VulnerabilitySeverity, team *uuid.UUID, days float64, remediatedAt time.Time) services.RemediationSample {
+		return services.RemediationSample{
+			VulnerabilityID: uuid.New(),
+			Severity:        severity,
+			TeamID:          team,
+			TeamName:        "Platform",
+			DiscoveredAt:    remediatedAt.Add(-time.Duration(days * 24 * float64(time.Hour))),
+			RemediatedAt:    remediatedAt,
+		}
+	}
+
+	mttr := services.ComputeMTTR([]services.RemediationSample{
+		sample(models.SeverityHigh, &teamA, 10, discovered.AddDate(0, 1, 0)),
+		sample(models.SeverityCritical, &teamA, 2, discovered.AddDate(0, 0, 10)),
+		sample(models.SeverityHigh, nil, 30, discovered.AddDate(0, 1, 5)),
+		sample(models.SeverityCritical, nil, 4, discovered.AddDate(0, 0, 20)),
+	}, time.UTC)
+
+	assert.Equal(t, int64(4), mttr.Overall.Remediated)
+	assert.Equal(t, 11.5, mttr.Overall.AverageDays)
+	assert.Equal(t, 7.0, mttr.Overall.MedianDays)
+
+	require.Len(t, mttr.BySeverity, 2)
+	assert.Equal(t, "CRITICAL", mttr.BySeverity[0].Key, "most severe first")
+	assert.Equal(t, 3.0, mttr.BySeverity[0].AverageDays)
+	assert.Equal(t, 20.0, mttr.BySeverity[1].AverageDays)
+
+	require.Len(t, mttr.ByTeam, 2)
+	assert.Equal(t, "", mttr.ByTeam[0].Key, "slowest team first")
+	assert.Equal(t, "Unassigned", mttr.ByTeam[0].Label)
+	assert.Equal(t, 17.0, mttr.ByTeam[0].AverageDays)
+	assert.Equal(t, teamA.String(), mttr.ByTeam[1].Key)
+	assert.Equal(t, "Platform", mttr.ByTeam[1].Label)
+
+	require.Len(t, mttr.ByMonth, 2)
+	assert.Equal(t, "2026-01", mttr.ByMonth[0].Key)
+	assert.Equal(t, "Jan 2026", mttr.ByMonth[0].Label)
+	assert.Equal(t, int64(2), mttr.ByMonth[0].Remediated)
+	assert.Equal(t, "2026-02", mttr.ByMonth[1].Key)
+}
+
+func TestComputeMTTRNoSamples(t *testing.T) {
+	mttr := services.ComputeMTTR(nil, time.UTC)
+	assert.Zero(t, mttr.Overall)
+	assert.NotNil(t, mttr.BySeverity)
+	assert.NotNil(t, mttr.ByTeam)
+	assert.NotNil(t, mttr.ByMonth)
+}
+
+func TestComputeMTTRMonthsInLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// 20:00 UTC on 31 January is already February in Tokyo
+	remediated := time.Date(2026, 1, 31, 20, 0, 0, 0, time.UTC)
+	samples := []services.RemediationSample{{
+		VulnerabilityID: uuid.New(),
+		Severity:        models.SeverityHigh,
+		DiscoveredAt:    remediated.AddDate(0, 0, -3),
+		RemediatedAt:    remediated,
+	}}
+
+	require.Len(t, services.ComputeMTTR(samples, time.UTC).ByMonth, 1)
+	assert.Equal(t, "2026-01", services.ComputeMTTR(samples, time.UTC).ByMonth[0].Key)
+	assert.Equal(t, "2026-02", services.ComputeMTTR(samples, tokyo).ByMonth[0].Key)
+}
+
+func TestBucketOpenVulnerabilityAges(t *testing.T) {
+	now := time.Date(2026, 6, 30, 15, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	aging := services.BucketOpenVulnerabilityAges(now, []services.OpenVulnerabilityAge{
+		{Severity: "CRITICAL", DiscoveredOn: daysAgo(0), Count: 2},
+		{Severity: "HIGH", DiscoveredOn: daysAgo(7), Count: 1},
+		{Severity: "HIGH", DiscoveredOn: daysAgo(8), Count: 1},
+		{Severity: "LOW", DiscoveredOn: daysAgo(400), Count: 1},
+	})
+
+	require.Len(t, aging.Buckets, 6)
+	assert.Equal(t, int64(5), aging.TotalOpen)
+	assert.Equal(t, 400, aging.OldestAgeDays)
+	assert.Equal(t, 83.0, aging.AverageAgeDays)
+
+	assert.Equal(t, int64(3), aging.Buckets[0].Total)
+	assert.Equal(t, int64(2), aging.Buckets[0].BySeverity["CRITICAL"])
+	assert.Equal(t, int64(1), aging.Buckets[0].BySeverity["HIGH"])
+	assert.Equal(t, int64(1), aging.Buckets[1].Total)
+	assert.Equal(t, int64(1), aging.Buckets[5].Total)
+	assert.Nil(t, aging.Buckets[5].MaxDays, "oldest bucket is open-ended")
+	require.NotNil(t, aging.Buckets[1].MaxDays)
+	assert.Equal(t, 30, *aging.Buckets[1].MaxDays)
+}
+
+func TestBuildBurnDown(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+	day := func(offset int) time.Time { return start.AddDate(0, 0, offset) }
+
+	// 10 open today; flows after the period (day 5) are undone as well
+	burnDown := services.BuildBurnDown(start, end, 10, []services.DailyFlow{
+		{Day: day(0), Opened: 3, Closed: 1},
+		{Day: day(1), Closed: 4, Reopened: 1},
+		{Day: day(2), Opened: 2},
+		{Day: day(5), Opened: 1, Closed: 2},
+	})
+
+	assert.Equal(t, services.BurnDownIntervalDay, burnDown.Interval)
+	require.Len(t, burnDown.Points, 3)
+	assert.Equal(t, int64(11), burnDown.Points[2].Open)
+	assert.Equal(t, int64(9), burnDown.Points[1].Open)
+	assert.Equal(t, int64(12), burnDown.Points[0].Open)
+	assert.Equal(t, int64(10), burnDown.StartOpen)
+	assert.Equal(t, int64(11), burnDown.EndOpen)
+	assert.Equal(t, int64(4), burnDown.Points[1].Closed)
+	assert.Equal(t, int64(1), burnDown.Points[1].Reopened)
+}
+
+func TestBuildBurnDownWeekly(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 120)
+
+	burnDown := services.BuildBurnDown(start, end, 5, []services.DailyFlow{
+		{Day: start.AddDate(0, 0, 3), Opened: 2},
+		{Day: start.AddDate(0, 0, 6), Closed: 1},
+		{Day: start.AddDate(0, 0, 7), Opened: 1},
+	})
+
+	assert.Equal(t, services.BurnDownIntervalWeek, burnDown.Interval)
+	require.Len(t, burnDown.Points, 18)
+	assert.Equal(t, int64(2), burnDown.Points[0].Opened)
+	assert.Equal(t, int64(1), burnDown.Points[0].Closed)
+	assert.Equal(t, int64(1), burnDown.Points[1].Opened)
+	assert.Equal(t, int64(4), burnDown.Points[0].Open)
+	assert.Equal(t, int64(3), burnDown.StartOpen)
+	assert.Equal(t, int64(5), burnDown.EndOpen)
+}
@@ -0,0 +1,57 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectRemediation(t *testing.T) {
+	asOf := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+	day := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	velocities := []services.SeverityVelocity{
+		// Net burn of 1 a day: 20 open clear in 20 days
+		{Severity: models.SeverityCritical, Open: 20, Opened: 30, Resolved: 60},
+		// Opened faster than resolved: never clears
+		{Severity: models.SeverityHigh, Open: 50, Opened: 60, Resolved: 30},
+		{Severity: models.SeverityMedium, Open: 0, Opened: 3, Resolved: 3},
+		{Severity: models.SeverityLow, Open: 10, Opened: 0, Resolved: 0},
+	}
+
+	forecast := services.ProjectRemediation(asOf, velocities, 30, 30)
+	assert.Equal(t, day, forecast.AsOf)
+	require.Len(t, forecast.BySeverity, 4)
+
+	critical := forecast.BySeverity[0]
+	assert.Equal(t, 1.0, critical.OpenedPerDay)
+	assert.Equal(t, 2.0, critical.ResolvedPerDay)
+	assert.Equal(t, 1.0, critical.NetBurnPerDay)
+	require.NotNil(t, critical.ClearDate)
+	assert.Equal(t, day.AddDate(0, 0, 20), *critical.ClearDate)
+	require.NotNil(t, forecast.CriticalClearDate)
+	assert.Equal(t, *critical.ClearDate, *forecast.CriticalClearDate)
+
+	assert.Nil(t, forecast.BySeverity[1].ClearDate)
+	require.NotNil(t, forecast.BySeverity[2].ClearDate)
+	assert.Equal(t, day, *forecast.BySeverity[2].ClearDate)
+	assert.Nil(t, forecast.BySeverity[3].ClearDate)
+
+	// Weekly points up to and including the horizon
+	require.Len(t, forecast.Points, 6)
+	first, last := forecast.Points[0], forecast.Points[len(forecast.Points)-1]
+	assert.Equal(t, int64(80), first.Open)
+	assert.Equal(t, day.AddDate(0, 0, 30), last.Date)
+	assert.Equal(t, int64(0), last.Critical)
+	assert.Equal(t, int64(80), last.High)
+	assert.Equal(t, int64(10), last.Low)
+	assert.Equal(t, int64(90), last.Open)
+	assert.Equal(t, int64(13), forecast.Points[1].Critical)
+
+	// A growing critical backlog has no clear date
+	velocities[0].Opened = 90
+	assert.Nil(t, services.ProjectRemediation(asOf, velocities, 30, 30).CriticalClearDate)
+}
@@ -0,0 +1,167 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/nvd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginOutputVersions(t *testing.T) {
+	installed, fixed := services.PluginOutputVersions(`
+  URL               : https://10.0.0.5/
+  Installed version : 2.4.41
+  Fixed version     : 2.4.58
+`)
+	assert.Equal(t, "2.4.41", installed)
+	assert.Equal(t, "2.4.58", fixed)
+
+	installed, fixed = services.PluginOutputVersions(`
+Remote package installed : openssl-1.0.2k-19.el7
+Should be                : openssl-1.0.2k-26.el7_9
+`)
+	assert.Equal(t, "openssl-1.0.2k-19.el7", installed)
+	assert.Equal(t, "openssl-1.0.2k-26.el7_9", fixed)
+
+	installed, fixed = services.PluginOutputVersions("The remote host accepts SSLv3 connections.")
+	assert.Empty(t, installed)
+	assert.Empty(t, fixed)
+}
+
+func TestSolutionFixedVersion(t *testing.T) {
+	assert.Equal(t, "2.4.58", services.SolutionFixedVersion("Upgrade to Apache version 2.4.58 or later."))
+	assert.Equal(t, "8.1.27", services.SolutionFixedVersion("Update to PHP version 8.1.27."))
+	assert.Equal(t, "", services.SolutionFixedVersion("Apply the patches referenced in the vendor advisory."))
+}
+
+func TestParseNessusFileRemediation(t *testing.T) {
+	vulns, err := services.NewNessusParserService().ParseNessusFile([]byte(`<?xml version="1.0"?>
+<NessusClientData_v2>
+  <Report name="scan">
+    <ReportHost name="10.0.0.5">
+      <HostProperties><tag name="host-ip">10.0.0.5</tag></HostProperties>
+      <ReportItem port="443" svc_name="www" protocol="tcp" severity="3" pluginID="183391" pluginName="Apache 2.4.x &lt; 2.4.58" pluginFamily="Web Servers">
+        <description>The remote web server is affected by multiple vulnerabilities.</description>
+        <solution>Upgrade to Apache version 2.4.58 or later.</solution>
+        <see_also>https://httpd.apache.org/security/vulnerabilities_24.html
+https://example.com/other</see_also>
+        <patch_publication_date>2023/10/19</patch_publication_date>
+        <plugin_output>
+  Installed version : 2.4.41
+  Fixed version     : 2.4.58
+</plugin_output>
+        <risk_factor>High</risk_factor>
+      </ReportItem>
+      <ReportItem port="445" svc_name="cifs" protocol="tcp" severity="4" pluginID="97833" pluginName="MS17-010" pluginFamily="Windows">
+        <description>SMB server vulnerabilities</description>
+        <solution>Microsoft has released a set of patches for Windows.</solution>
+        <workaround>Disable SMBv1.</workaround>
+        <xref>MSKB:4012212</xref>
+        <risk_factor>Critical</risk_factor>
+      </ReportItem>
+    </ReportHost>
+  </Report>
+</NessusClientData_v2>`))
+	require.NoError(t, err)
+	require.Len(t, vulns, 2)
+	byPlugin := map[string]services.ParsedVulnerability{}
+	for _, vuln := range vulns {
+		byPlugin[vuln.PluginID] = vuln
+	}
+
+	apache := byPlugin["183391"]
+	assert.True(t, apache.PatchAvailable)
+	assert.Equal(t, "https://httpd.apache.org/security/vulnerabilities_24.html", apache.AdvisoryURL)
+	assert.Equal(t, "2.4.58", apache.FixedVersion)
+	require.Len(t, apache.AffectedHosts, 1)
+	assert.Equal(t, "2.4.41", apache.AffectedHosts[0].DetectedVersion)
+	assert.Equal(t, "2.4.58", apache.AffectedHosts[0].FixedVersion)
+	assert.Contains(t, apache.AffectedHosts[0].PluginOutput, "Installed version")
+
+	smb := byPlugin["97833"]
+	assert.False(t, smb.PatchAvailable)
+	assert.Equal(t, "https://support.microsoft.com/help/4012212", smb.AdvisoryURL)
+	assert.Equal(t, "Disable SMBv1.", smb.Workaround)
+	assert.Empty(t, smb.FixedVersion)
+}
+
+func TestParsedVulnerabilitiesFromTenableRemediation(t *testing.T) {
+	var records []services.TenableVulnRecord
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"asset": {"ipv4": "10.0.0.7"},
+		 "plugin": {"id": 183391, "name": "Apache 2.4.x < 2.4.58", "solution": "Apply the vendor update.",
+		            "has_patch": true, "see_also": ["https://httpd.apache.org/security/vulnerabilities_24.html"]},
+		 "port": {"port": 443, "protocol": "TCP"},
+		 "output": "\n  Installed version : 2.4.41\n  Fixed version     : 2.4.58\n",
+		 "severity_id": 3, "state": "OPEN", "last_found": "2026-09-01T10:00:00Z"}
+	]`), &records))
+
+	vulns := services.ParsedVulnerabilitiesFromTenable(records)
+	require.Len(t, vulns, 1)
+	assert.True(t, vulns[0].PatchAvailable)
+	assert.Equal(t, "https://httpd.apache.org/security/vulnerabilities_24.html", vulns[0].AdvisoryURL)
+	assert.Equal(t, "2.4.58", vulns[0].FixedVersion, "falls back to the fixed version of the plugin output")
+	require.Len(t, vulns[0].AffectedHosts, 1)
+	assert.Equal(t, "2.4.41", vulns[0].AffectedHosts[0].DetectedVersion)
+}
+
+const nvdLog4jResponse = `{
+  "resultsPerPage": 1,
+  "vulnerabilities": [{
+    "cve": {
+      "id": "CVE-2021-44228",
+      "references": [
+        {"url": "https://logging.apache.org/log4j/2.x/security.html", "tags": ["Mitigation", "Vendor Advisory"]},
+        {"url": "https://github.com/apache/logging-log4j2/pull/608", "tags": ["Patch", "Third Party Advisory"]},
+        {"url": "https://www.exploit-db.com/exploits/50592", "tags": ["Exploit"]}
+      ],
+      "configurations": [{
+        "nodes": [{
+          "cpeMatch": [
+            {"vulnerable": true, "criteria": "cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*", "versionStartIncluding": "2.0.1", "versionEndExcluding": "2.3.1"},
+            {"vulnerable": true, "criteria": "cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*", "versionStartIncluding": "2.13.0", "versionEndExcluding": "2.15.0"}
+          ]
+        }]
+      }]
+    }
+  }]
+}`
+
+func TestNVDCVERemediation(t *testing.T) {
+	var gotID, gotKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID, gotKey = r.URL.Query().Get("cveId"), r.Header.Get("apiKey")
+		if gotID != "CVE-2021-44228" {
+			w.Write([]byte(`{"resultsPerPage": 0, "vulnerabilities": []}`))
+			return
+		}
+		w.Write([]byte(nvdLog4jResponse))
+	}))
+	defer server.Close()
+
+	client := nvd.NewClient(server.URL, "secret")
+	assert.Equal(t, nvd.RequestIntervalWithKey, client.Interval())
+
+	cve, err := client.CVE(context.Background(), "CVE-2021-44228")
+	require.NoError(t, err)
+	require.NotNil(t, cve)
+	assert.Equal(t, "secret", gotKey)
+
+	remediation := cve.Remediation()
+	assert.True(t, remediation.PatchAvailable)
+	assert.Equal(t, "https://logging.apache.org/log4j/2.x/security.html", remediation.AdvisoryURL)
+	assert.Equal(t, "https://logging.apache.org/log4j/2.x/security.html", remediation.MitigationURL)
+	assert.Equal(t, "2.3.1", remediation.FixedVersion)
+
+	unknown, err := client.CVE(context.Background(), "CVE-2099-0001")
+	require.NoError(t, err)
+	assert.Nil(t, unknown)
+
+	assert.Equal(t, nvd.RequestInterval, nvd.NewClient(server.URL, "").Interval())
+}
@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// seedAggregateData stores vulnerabilities across severities, statuses and assignees, and
+// assets across criticalities and environments
+func seedAggregateData(t *testing.T, db *gorm.DB, user *models.User) {
+	now := time.Now()
+	vulnerabilities := []struct {
+		severity   models.VulnerabilitySeverity
+		status     models.VulnerabilityStatus
+		assignee   *uuid.UUID
+		discovered time.Time
+	}{
+		{models.SeverityCritical, models.StatusOpen, nil, now},
+		{models.SeverityCritical, models.StatusResolved, &user.ID, now},
+		{models.SeverityHigh, models.StatusInProgress, &user.ID, now.AddDate(0, 0, -60)},
+		{models.SeverityLow, models.StatusOpen, nil, now},
+	}
+	for i, v := range vulnerabilities {
+		require.NoError(t, db.Create(&models.Vulnerability{
+			Title:         "Vulnerability " + string(rune('A'+i)),
+			Description:   "Seeded for aggregate tests",
+			Severity:      v.severity,
+			Status:        v.status,
+			AssignedToID:  v.assignee,
+			DiscoveryDate: v.discovered,
+			CreatedByID:   user.ID,
+		}).Error)
+	}
+
+	assets := []struct {
+		hostname    string
+		criticality models.AssetCriticality
+		environment models.Environment
+	}{
+		{"web-01", models.CriticalityHigh, models.EnvProduction},
+		{"web-02", models.CriticalityHigh, models.EnvProduction},
+		{"build-01", models.CriticalityLow, models.EnvStaging},
+	}
+	for _, a := range assets {
+		require.NoError(t, db.Create(&models.AffectedSystem{
+			Hostname:    a.hostname,
+			SystemType:  models.SystemTypeServer,
+			Criticality: criticalityPtr(a.criticality),
+			Environment: a.environment,
+		}).Error)
+	}
+}
+
+func TestVulnerabilityStatsSinglePass(t *testing.T) {
+	db, user := setupSchemaDB(t)
+	if db == nil {
+		return
+	}
+	seedAggregateData(t, db, user)
+
+	stats, err := services.NewVulnerabilityService().GetVulnerabilityStats()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), stats.TotalCount)
+	assert.Equal(t, map[string]int64{"critical": 2, "high": 1, "low": 1}, stats.BySeverity)
+	assert.Equal(t, map[string]int64{"open": 2, "in_progress": 1, "resolved": 1}, stats.ByStatus)
+	assert.Equal(t, int64(3), stats.RecentDiscoveries)
+	assert.Equal(t, int64(2), stats.UnassignedCount)
+	assert.Equal(t, int64(1), stats.CriticalUnresolved, "the resolved critical is not counted")
+}
+
+func TestAnalystReportAggregateSections(t *testing.T) {
+	db, user := setupSchemaDB(t)
+	if db == nil {
+		return
+	}
+	seedAggregateData(t, db, user)
+
+	report, err := services.NewReportService(db).GenerateAnalystReport(time.Now().AddDate(0, 0, -1), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(4), report.TotalVulnerabilities)
+	assert.Equal(t, int64(2), report.VulnerabilitiesBySeverity["CRITICAL"])
+	assert.Equal(t, int64(2), report.VulnerabilitiesByStatus["OPEN"])
+	assert.Equal(t, int64(3), report.OpenVulnerabilities, "open and in progress")
+	assert.Equal(t, int64(1), report.ResolvedVulnerabilities)
+
+	assert.Equal(t, int64(3), report.TotalAssets)
+	assert.Equal(t, map[string]int64{"HIGH": 2, "LOW": 1}, report.AssetsByCriticality)
+	assert.Equal(t, map[string]int64{"PRODUCTION": 2, "STAGING": 1}, report.AssetsByEnvironment)
+
+	assignees := map[string]services.AssigneeStats{}
+	for _, stats := range report.AssignedVulnerabilities {
+		assignees[stats.AssigneeName] = stats
+	}
+	assert.Equal(t, services.AssigneeStats{AssigneeName: user.Name, Total: 2, InProgress: 1, Resolved: 1}, assignees[user.Name])
+	assert.Equal(t, services.AssigneeStats{AssigneeName: "Unassigned", Total: 2, Open: 2}, assignees["Unassigned"])
+	assert.Len(t, report.RecentVulnerabilities, 4)
+}
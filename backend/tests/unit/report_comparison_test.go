@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseComparisonPeriods(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	from, to, err := services.ParseComparisonPeriods("2026-08", "2026-10", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), to)
+
+	tests := []struct {
+		name     string
+		from, to string
+		wantErr  string
+	}{
+		{"missing from", "", "2026-09", "required"},
+		{"bad month", "2026-8", "2026-09", "invalid month"},
+		{"same month", "2026-09", "2026-09", "from must be before to"},
+		{"reversed", "2026-09", "2026-08", "from must be before to"},
+		{"future", "2026-09", "2026-11", "must not be in the future"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := services.ParseComparisonPeriods(tt.from, tt.to, now)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestComparePeriodMetrics(t *testing.T) {
+	from := services.PeriodMetrics{
+		Period:                  "2026-08",
+		NewVulnerabilities:      40,
+		NewCritical:             4,
+		ResolvedVulnerabilities: 50,
+		NetChange:               -10,
+		NewFindings:             0,
+		MTTRHours:               48,
+		RiskScore:               10,
+		Backlog:                 &services.MetricsBacklog{CriticalCount: 5, HighCount: 10, OpenCount: 30},
+	}
+	to := services.PeriodMetrics{
+		Period:                  "2026-09",
+		NewVulnerabilities:      50,
+		NewCritical:             10,
+		ResolvedVulnerabilities: 25,
+		NetChange:               25,
+		NewFindings:             3,
+		MTTRHours:               24,
+		RiskScore:               20,
+		Backlog:                 &services.MetricsBacklog{CriticalCount: 5, HighCount: 12, OpenCount: 55},
+	}
+
+	comparison := services.ComparePeriodMetrics(from, to)
+	assert.Equal(t, 10.0, comparison.RiskScoreDelta)
+
+	deltas := map[string]services.MetricDelta{}
+	for _, delta := range comparison.Metrics {
+		deltas[delta.Metric] = delta
+	}
+	require.Len(t, deltas, 10)
+	assert.Equal(t, services.MetricRegressed, deltas["new_vulnerabilities"].Trend)
+	require.NotNil(t, deltas["new_vulnerabilities"].ChangePercent)
+	assert.Equal(t, 25.0, *deltas["new_vulnerabilities"].ChangePercent)
+	assert.Equal(t, services.MetricRegressed, deltas["resolved_vulnerabilities"].Trend)
+	assert.Equal(t, services.MetricImproved, deltas["mttr_hours"].Trend)
+	assert.Equal(t, services.MetricUnchanged, deltas["critical_backlog"].Trend)
+	assert.Nil(t, deltas["new_findings"].ChangePercent)
+
+	// Regressions from zero rank first, then by relative change, capped at five
+	require.Len(t, comparison.TopRegressions, 5)
+	assert.Equal(t, "new_findings", comparison.TopRegressions[0].Metric)
+	assert.Equal(t, "net_change", comparison.TopRegressions[1].Metric)
+	assert.Equal(t, "new_critical", comparison.TopRegressions[2].Metric)
+	for _, delta := range comparison.TopRegressions {
+		assert.Equal(t, services.MetricRegressed, delta.Trend)
+	}
+
+	// Backlog metrics need a backlog on both sides
+	to.Backlog = nil
+	assert.Len(t, services.ComparePeriodMetrics(from, to).Metrics, 7)
+}
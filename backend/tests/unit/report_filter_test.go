@@ -0,0 +1,56 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReportFilter(t *testing.T) {
+	filter, err := services.ParseReportFilter("", "", "", "")
+	require.NoError(t, err)
+	assert.True(t, filter.IsZero())
+	assert.Empty(t, filter.CacheKey())
+
+	group := "7b0c1a52-2f5e-4c1e-9a43-0d6f0b7a1c11"
+	team := "0e2a7f3c-8d41-4b6a-b5f1-3c9e2d8a7b60"
+	filter, err = services.ParseReportFilter(group, "staging", " PCI, payments ,pci", team)
+	require.NoError(t, err)
+	assert.False(t, filter.IsZero())
+	require.NotNil(t, filter.AssetGroupID)
+	assert.Equal(t, group, filter.AssetGroupID.String())
+	assert.Equal(t, models.EnvStaging, filter.Environment)
+	assert.Equal(t, []string{"payments", "pci"}, filter.Tags)
+	require.NotNil(t, filter.OwnerTeamID)
+	assert.Equal(t, team, filter.OwnerTeamID.String())
+
+	// Tag order doesn't change the cache key
+	reordered, err := services.ParseReportFilter(group, "STAGING", "payments,pci", team)
+	require.NoError(t, err)
+	assert.Equal(t, filter.CacheKey(), reordered.CacheKey())
+
+	tagOnly, err := services.ParseReportFilter("", "", "pci", "")
+	require.NoError(t, err)
+	assert.NotEqual(t, filter.CacheKey(), tagOnly.CacheKey())
+
+	tests := []struct {
+		name                                 string
+		assetGroup, environment, tags, owner string
+		wantErr                              string
+	}{
+		{"bad asset group", "web", "", "", "", "invalid asset_group"},
+		{"bad environment", "", "qa", "", "", "invalid environment"},
+		{"bad tag", "", "", "pci,bad tag!", "", "invalid tag"},
+		{"bad owner team", "", "", "", "ops", "invalid owner_team"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := services.ParseReportFilter(tt.assetGroup, tt.environment, tt.tags, tt.owner)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/cyops/cyops-backend/pkg/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportSignatureRoundTrip(t *testing.T) {
+	signer := signing.NewSigner(signing.DeriveKey("test-secret"))
+	report := []byte("%PDF-1.7 assessment report")
+
+	signature := signer.Sign(report)
+	assert.Equal(t, signing.Algorithm, signature.Algorithm)
+	assert.Equal(t, signer.Active().KeyID(), signature.KeyID)
+	assert.Equal(t, int64(len(report)), signature.Size)
+	require.NoError(t, signer.Verify(report, signature))
+
+	// A saved .sig document verifies after a JSON round trip
+	document, err := json.Marshal(signature)
+	require.NoError(t, err)
+	var saved signing.Signature
+	require.NoError(t, json.Unmarshal(document, &saved))
+	require.NoError(t, signer.Verify(report, &saved))
+
+	tampered := append([]byte{}, report...)
+	tampered[0] = 'X'
+	assert.ErrorIs(t, signer.Verify(tampered, signature), signing.ErrDigestMismatch)
+
+	backdated := *signature
+	backdated.SignedAt = backdated.SignedAt.AddDate(-1, 0, 0)
+	assert.ErrorIs(t, signer.Verify(report, &backdated), signing.ErrInvalidSignature)
+
+	other := signing.NewSigner(signing.DeriveKey("another-instance"))
+	assert.ErrorIs(t, other.Verify(report, signature), signing.ErrUnknownKey)
+}
+
+func TestReportSignerKeyRotation(t *testing.T) {
+	old := signing.DeriveKey("old-secret")
+	report := []byte("severity,count\nCRITICAL,3\n")
+	signature := signing.NewSigner(old).Sign(report)
+
+	seed := make([]byte, ed25519.SeedSize)
+	_, err := rand.Read(seed)
+	require.NoError(t, err)
+	active, err := signing.ParseKey(base64.StdEncoding.EncodeToString(seed))
+	require.NoError(t, err)
+
+	rotated := signing.NewSigner(active, old)
+	require.NoError(t, rotated.Verify(report, signature), "previous keys still verify")
+	assert.Equal(t, active.KeyID(), rotated.Sign(report).KeyID, "new signatures use the active key")
+}
+
+func TestParseSigningKeyPEM(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+
+	key, err := signing.ParseKey(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+	require.NoError(t, err)
+	assert.Equal(t, private.Public(), key.PublicKey())
+
+	_, err = signing.ParseKey(base64.StdEncoding.EncodeToString([]byte("too short")))
+	assert.Error(t, err)
+}
+
+func TestConfigReportSignerDerivedKeyStillVerifies(t *testing.T) {
+	cfg := &config.Config{EncryptionKey: "dev-encryption-key-32-chars!!"}
+	derived, err := cfg.ReportSigner()
+	require.NoError(t, err)
+	report := []byte("report")
+	signature := derived.Sign(report)
+
+	seed := make([]byte, ed25519.SeedSize)
+	cfg.ReportSigningKey = base64.StdEncoding.EncodeToString(seed)
+	dedicated, err := cfg.ReportSigner()
+	require.NoError(t, err)
+	assert.NotEqual(t, derived.Active().KeyID(), dedicated.Active().KeyID())
+	assert.NoError(t, dedicated.Verify(report, signature))
+}
+
+func TestDecodeReportSignature(t *testing.T) {
+	signature := signing.NewSigner(signing.DeriveKey("test-secret")).Sign([]byte("report"))
+
+	fromHeader, err := services.DecodeReportSignature([]byte(services.EncodeReportSignature(signature)))
+	require.NoError(t, err)
+	assert.Equal(t, signature.Signature, fromHeader.Signature)
+
+	document, err := json.Marshal(signature)
+	require.NoError(t, err)
+	fromFile, err := services.DecodeReportSignature(document)
+	require.NoError(t, err)
+	assert.Equal(t, signature.KeyID, fromFile.KeyID)
+
+	_, err = services.DecodeReportSignature([]byte("not a signature"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSummaryCompletion(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		wantSummary  string
+		wantKeyRisks string
+	}{
+		{
+			name:         "plain headings",
+			text:         "EXECUTIVE SUMMARY:\nPosture improved.\n\nKEY RISKS:\nUnpatched VPN gateway.",
+			wantSummary:  "Posture improved.",
+			wantKeyRisks: "Unpatched VPN gateway.",
+		},
+		{
+			name:         "markdown headings",
+			text:         "## **Executive Summary**\nPosture improved.\n\n**Key Risks:**\nUnpatched VPN gateway.",
+			wantSummary:  "Posture improved.",
+			wantKeyRisks: "Unpatched VPN gateway.",
+		},
+		{
+			name:        "no sections",
+			text:        "  Posture improved.  ",
+			wantSummary: "Posture improved.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, keyRisks := services.ParseSummaryCompletion(tt.text)
+			assert.Equal(t, tt.wantSummary, summary)
+			assert.Equal(t, tt.wantKeyRisks, keyRisks)
+		})
+	}
+}
+
+func TestValidateReportSummarizerSettings(t *testing.T) {
+	settings := services.ReportSummarizerSettings{Provider: " OpenAI ", Model: " gpt-4o-mini "}
+	require.NoError(t, services.ValidateReportSummarizerSettings(&settings))
+	assert.Equal(t, "openai", settings.Provider)
+	assert.Equal(t, "gpt-4o-mini", settings.Model)
+	assert.Positive(t, settings.MaxTokens)
+
+	tests := []struct {
+		name     string
+		settings services.ReportSummarizerSettings
+		wantErr  string
+	}{
+		{"unknown provider", services.ReportSummarizerSettings{Provider: "bard", Model: "x"}, "invalid provider"},
+		{"azure needs endpoint", services.ReportSummarizerSettings{Provider: "azure", Model: "x"}, "base_url is required"},
+		{"missing model", services.ReportSummarizerSettings{Provider: "ollama"}, "model is required"},
+		{"timeout too long", services.ReportSummarizerSettings{Provider: "ollama", Model: "x", TimeoutSeconds: 3600}, "invalid timeout_seconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := services.ValidateReportSummarizerSettings(&tt.settings)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLLMClients(t *testing.T) {
+	t.Run("openai", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "gpt-4o-mini", body["model"])
+			assert.Len(t, body["messages"], 2)
+			w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":" Done. "}}]}`))
+		}))
+		defer server.Close()
+
+		client, err := llm.New(llm.Config{Provider: llm.ProviderOpenAI, BaseURL: server.URL + "/v1", Model: "gpt-4o-mini", APIKey: "secret"})
+		require.NoError(t, err)
+		text, err := client.Complete(context.Background(), llm.Request{System: "Be brief.", Prompt: "Summarize."})
+		require.NoError(t, err)
+		assert.Equal(t, "Done.", text)
+	})
+
+	t.Run("azure", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/openai/deployments/reports/chat/completions", r.URL.Path)
+			assert.Equal(t, llm.DefaultAzureAPIVersion, r.URL.Query().Get("api-version"))
+			assert.Equal(t, "secret", r.Header.Get("api-key"))
+			w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"Done."}}]}`))
+		}))
+		defer server.Close()
+
+		client, err := llm.New(llm.Config{Provider: llm.ProviderAzure, BaseURL: server.URL, Model: "reports", APIKey: "secret"})
+		require.NoError(t, err)
+		text, err := client.Complete(context.Background(), llm.Request{Prompt: "Summarize."})
+		require.NoError(t, err)
+		assert.Equal(t, "Done.", text)
+	})
+
+	t.Run("ollama", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/chat", r.URL.Path)
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, false, body["stream"])
+			w.Write([]byte(`{"message":{"role":"assistant","content":"Done."}}`))
+		}))
+		defer server.Close()
+
+		client, err := llm.New(llm.Config{Provider: llm.ProviderOllama, BaseURL: server.URL, Model: "llama3.1"})
+		require.NoError(t, err)
+		text, err := client.Complete(context.Background(), llm.Request{Prompt: "Summarize."})
+		require.NoError(t, err)
+		assert.Equal(t, "Done.", text)
+	})
+
+	t.Run("provider errors and empty answers", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "missing key", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"choices":[]}`))
+		}))
+		defer server.Close()
+
+		client, err := llm.New(llm.Config{Provider: llm.ProviderOpenAI, BaseURL: server.URL, Model: "m"})
+		require.NoError(t, err)
+		_, err = client.Complete(context.Background(), llm.Request{Prompt: "Summarize."})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "401")
+		}
+
+		client, err = llm.New(llm.Config{Provider: llm.ProviderOpenAI, BaseURL: server.URL, Model: "m", APIKey: "k"})
+		require.NoError(t, err)
+		_, err = client.Complete(context.Background(), llm.Request{Prompt: "Summarize."})
+		assert.ErrorIs(t, err, llm.ErrEmptyCompletion)
+	})
+
+	_, err := llm.New(llm.Config{Provider: llm.ProviderOllama, BaseURL: "ftp://host", Model: "m"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,155 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/docgen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestValidateReportTemplate(t *testing.T) {
+	valid := &models.ReportTemplate{Name: "Acme client", CompanyName: "Acme MSSP"}
+	assert.NoError(t, services.ValidateReportTemplate(valid))
+
+	tests := []struct {
+		name     string
+		template models.ReportTemplate
+		wantErr  string
+	}{
+		{"missing name", models.ReportTemplate{}, "name is required"},
+		{"long name", models.ReportTemplate{Name: strings.Repeat("n", 256)}, "invalid name"},
+		{"long company name", models.ReportTemplate{Name: "x", CompanyName: strings.Repeat("c", 256)}, "invalid company_name"},
+		{"long intro", models.ReportTemplate{Name: "x", IntroText: strings.Repeat("i", 10001)}, "invalid intro_text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := services.ValidateReportTemplate(&tt.template)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestValidateReportLogo(t *testing.T) {
+	mimeType, err := services.ValidateReportLogo(encodeTestPNG(t, 10, 10))
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", mimeType)
+
+	_, err = services.ValidateReportLogo(nil)
+	assert.ErrorContains(t, err, "logo is required")
+	_, err = services.ValidateReportLogo([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"/>"))
+	assert.ErrorContains(t, err, "invalid logo")
+	_, err = services.ValidateReportLogo(make([]byte, services.MaxReportLogoSize+1))
+	assert.ErrorContains(t, err, "invalid logo")
+}
+
+func TestDocgenNewImageFit(t *testing.T) {
+	img, err := docgen.NewImageFit(encodeTestPNG(t, 800, 200), 320, 120)
+	require.NoError(t, err)
+	assert.Equal(t, 320, img.Width)
+	assert.Equal(t, 80, img.Height)
+
+	img, err = docgen.NewImageFit(encodeTestPNG(t, 100, 600), 320, 120)
+	require.NoError(t, err)
+	assert.Equal(t, 20, img.Width)
+	assert.Equal(t, 120, img.Height)
+
+	// Images that already fit keep their size
+	img, err = docgen.NewImageFit(encodeTestPNG(t, 50, 40), 320, 120)
+	require.NoError(t, err)
+	assert.Equal(t, 50, img.Width)
+	assert.Equal(t, 40, img.Height)
+}
+
+func TestNewReportBranding(t *testing.T) {
+	branding := services.NewReportBranding(nil, map[string]docgen.Image{})
+	assert.Equal(t, models.AllReportSections(), branding.Sections)
+	assert.Empty(t, branding.CompanyName)
+	assert.Empty(t, branding.LogoKey)
+
+	sections := models.AllReportSections()
+	sections.Scope = false
+	reportTemplate := &models.ReportTemplate{
+		CompanyName: "Acme MSSP",
+		IntroText:   "Prepared for the board.",
+		Sections:    sections,
+		Logo:        encodeTestPNG(t, 640, 120),
+	}
+	images := map[string]docgen.Image{}
+	branding = services.NewReportBranding(reportTemplate, images)
+	assert.Equal(t, "Acme MSSP", branding.CompanyName)
+	assert.False(t, branding.Sections.Scope)
+	require.NotEmpty(t, branding.LogoKey)
+	assert.Equal(t, 320, images[branding.LogoKey].Width)
+
+	// CSV exports pass no images and get no logo
+	assert.Empty(t, services.NewReportBranding(reportTemplate, nil).LogoKey)
+}
+
+func TestRenderAssessmentReportBranding(t *testing.T) {
+	sections := models.AllReportSections()
+	sections.Scope = false
+	sections.Remediation = false
+	images := map[string]docgen.Image{}
+	content := services.AssessmentReportContent{
+		Title: "Quarterly Pentest",
+		Assessment: models.Assessment{
+			Name:           "Perimeter review",
+			AssessmentType: models.AssessmentPenTest,
+			Status:         models.AssessmentCompleted,
+			StartDate:      time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC),
+		},
+		Scope: []models.AffectedSystem{{Hostname: "web01"}},
+		Findings: []services.AssessmentReportFinding{{
+			Ref:           "F-01",
+			Vulnerability: models.Vulnerability{Title: "Open redirect", Severity: models.SeverityLow},
+		}},
+		GeneratedAt: time.Now(),
+		Branding: services.NewReportBranding(&models.ReportTemplate{
+			CompanyName: "Acme MSSP",
+			IntroText:   "Prepared for the board.",
+			Sections:    sections,
+			Logo:        encodeTestPNG(t, 40, 20),
+		}, images),
+	}
+
+	docx, _, err := services.RenderAssessmentReport(content, images, services.ReportFormatDOCX)
+	require.NoError(t, err)
+	archive, err := zip.NewReader(bytes.NewReader(docx), int64(len(docx)))
+	require.NoError(t, err)
+	var document string
+	for _, file := range archive.File {
+		if file.Name == "word/document.xml" {
+			rc, err := file.Open()
+			require.NoError(t, err)
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			document = string(data)
+		}
+	}
+	assert.Contains(t, document, "Prepared by Acme MSSP")
+	assert.Contains(t, document, "Prepared for the board.")
+	assert.Contains(t, document, "Executive Summary")
+	assert.Contains(t, document, "Open redirect")
+	assert.NotContains(t, document, "web01")
+	assert.NotContains(t, document, "Remediation Summary")
+
+	_, _, err = services.RenderAssessmentReport(content, images, services.ReportFormatPDF)
+	require.NoError(t, err)
+}
@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRiskAcceptanceRequestValidation(t *testing.T) {
+	// Requests are validated before the finding is looked up
+	service := services.NewRiskAcceptanceService(nil)
+	findingID := uuid.New()
+
+	cases := map[string]services.CreateRiskAcceptanceRequest{
+		"required":           {FindingID: findingID, Justification: "  ", ExpiresAt: time.Now().AddDate(0, 1, 0)},
+		"expires_at is":      {FindingID: findingID, Justification: "Isolated network"},
+		"in the future":      {FindingID: findingID, Justification: "Isolated network", ExpiresAt: time.Now().Add(-time.Hour)},
+		"more than 365 days": {FindingID: findingID, Justification: "Isolated network", ExpiresAt: time.Now().AddDate(0, 0, services.MaxRiskAcceptanceDays+1)},
+	}
+	for message, req := range cases {
+		_, err := service.RequestRiskAcceptance(req, uuid.New())
+		if assert.Error(t, err, message) {
+			assert.Contains(t, err.Error(), message)
+		}
+	}
+
+	_, err := service.RejectRiskAcceptance(uuid.New(), uuid.New(), " ")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "required")
+	}
+}
+
+func TestRiskAcceptanceWorkflow(t *testing.T) {
+	db, requester := setupSchemaDB(t)
+	if db == nil {
+		return
+	}
+	service := services.NewRiskAcceptanceService(db)
+
+	reviewer := &models.User{Email: "reviewer@example.com", Password: "hashedpassword", Name: "Reviewer"}
+	require.NoError(t, db.Create(reviewer).Error)
+	asset := &models.AffectedSystem{Hostname: "legacy-01", SystemType: models.SystemTypeServer}
+	require.NoError(t, db.Create(asset).Error)
+	vulnerability := createTestVulnerability(t, db, requester, "Unsupported OS")
+	finding := &models.VulnerabilityFinding{
+		VulnerabilityID:  vulnerability.ID,
+		AffectedSystemID: asset.ID,
+		Status:           models.FindingStatusOpen,
+		FirstDetected:    time.Now(),
+		LastSeen:         time.Now(),
+		CreatedBy:        requester.ID,
+	}
+	require.NoError(t, db.Create(finding).Error)
+
+	req := services.CreateRiskAcceptanceRequest{
+		FindingID:     finding.ID,
+		Justification: "Host is isolated until replacement",
+		ExpiresAt:     time.Now().AddDate(0, 1, 0),
+	}
+	acceptance, err := service.RequestRiskAcceptance(req, requester.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RiskAcceptancePending, acceptance.Status)
+
+	_, err = service.RequestRiskAcceptance(req, requester.ID)
+	if assert.Error(t, err, "only one request may be pending per finding") {
+		assert.Contains(t, err.Error(), "already pending")
+	}
+	_, err = service.ApproveRiskAcceptance(acceptance.ID, requester.ID, "")
+	if assert.Error(t, err, "requesters cannot approve their own request") {
+		assert.Contains(t, err.Error(), "own risk acceptance")
+	}
+
+	approved, err := service.ApproveRiskAcceptance(acceptance.ID, reviewer.ID, "Approved for one month")
+	require.NoError(t, err)
+	assert.Equal(t, models.RiskAcceptanceApproved, approved.Status)
+	require.NotNil(t, approved.ReviewedByID)
+	assert.Equal(t, reviewer.ID, *approved.ReviewedByID)
+
+	var current models.VulnerabilityFinding
+	require.NoError(t, db.First(&current, "id = ?", finding.ID).Error)
+	assert.Equal(t, models.FindingStatusAccepted, current.Status)
+
+	_, err = service.RejectRiskAcceptance(acceptance.ID, reviewer.ID, "Too late")
+	if assert.Error(t, err, "reviewed requests cannot be reviewed again") {
+		assert.Contains(t, err.Error(), "not pending")
+	}
+
+	// The accepted risk lapses
+	require.NoError(t, db.Model(&current).Update("expires_at", time.Now().Add(-time.Hour)).Error)
+	reopened, err := service.ExpireRiskAcceptances()
+	require.NoError(t, err)
+	assert.Equal(t, 1, reopened)
+
+	require.NoError(t, db.First(&current, "id = ?", finding.ID).Error)
+	assert.Equal(t, models.FindingStatusOpen, current.Status)
+	expired, err := service.GetRiskAcceptance(acceptance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RiskAcceptanceExpired, expired.Status)
+	assert.NotNil(t, expired.ExpiredAt)
+}
+
+func TestCancelRiskAcceptanceRequesterOnly(t *testing.T) {
+	db, requester := setupSchemaDB(t)
+	if db == nil {
+		return
+	}
+	service := services.NewRiskAcceptanceService(db)
+	acceptance := &models.RiskAcceptance{
+		FindingID:     uuid.New(),
+		Status:        models.RiskAcceptancePending,
+		Justification: "Compensating WAF rule",
+		ExpiresAt:     time.Now().AddDate(0, 1, 0),
+		RequestedByID: requester.ID,
+	}
+	require.NoError(t, db.Create(acceptance).Error)
+
+	_, err := service.CancelRiskAcceptance(acceptance.ID, uuid.New())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "only the requester")
+	}
+
+	cancelled, err := service.CancelRiskAcceptance(acceptance.ID, requester.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RiskAcceptanceCancelled, cancelled.Status)
+
+	_, err = service.CancelRiskAcceptance(acceptance.ID, requester.ID)
+	assert.Error(t, err, "only pending requests can be cancelled")
+}
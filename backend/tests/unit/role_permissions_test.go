@@ -0,0 +1,51 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePermissionsAgainstCatalog(t *testing.T) {
+	assert.NoError(t, models.ValidatePermissions(models.PermissionMap{
+		"vulnerability": {"read", "assign"},
+		"asset":         {models.PermissionWildcard},
+	}))
+
+	err := models.ValidatePermissions(models.PermissionMap{"vulnerability": {"fly"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "vulnerability:fly")
+
+	err = models.ValidatePermissions(models.PermissionMap{"spaceship": {"read"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "spaceship")
+}
+
+func TestResolvePermissionsInheritanceAndDeny(t *testing.T) {
+	parent := &models.Role{Name: "analyst"}
+	require.NoError(t, parent.SetPermissions(models.PermissionMap{
+		"vulnerability": {models.PermissionWildcard},
+		"asset":         {"read"},
+	}))
+
+	child := &models.Role{Name: "contractor"}
+	require.NoError(t, child.SetPermissions(models.PermissionMap{"report": {"read"}}))
+	require.NoError(t, child.SetDeniedPermissions(models.PermissionMap{"vulnerability": {"delete"}}))
+
+	effective, err := models.ResolvePermissions([]*models.Role{child, parent})
+	require.NoError(t, err)
+
+	// Inherited grants, including the wildcard
+	assert.True(t, effective.Allows("asset", "read"))
+	assert.True(t, effective.Allows("vulnerability", "assign"))
+	assert.True(t, effective.Allows("report", "read"))
+
+	// Deny rules win over inherited wildcard grants
+	assert.False(t, effective.Allows("vulnerability", "delete"))
+	assert.False(t, effective.Allows("asset", "write"))
+
+	// A role on its own does not see its parent's grants
+	assert.False(t, child.HasPermission("asset", "read"))
+}
@@ -0,0 +1,28 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSavedViewFilters(t *testing.T) {
+	assert.NoError(t, services.ValidateSavedViewFilters(models.SavedViewResourceVulnerability, map[string]string{
+		"severity": "CRITICAL,HIGH",
+		"status":   "OPEN",
+		"sortBy":   "cvss_score",
+	}))
+
+	assert.NoError(t, services.ValidateSavedViewFilters(models.SavedViewResourceAsset, map[string]string{
+		"environment": "production",
+		"sort_by":     "hostname",
+	}))
+
+	assert.Error(t, services.ValidateSavedViewFilters(models.SavedViewResourceAsset, map[string]string{
+		"severity": "CRITICAL",
+	}), "Vulnerability filters are not valid for asset views")
+
+	assert.Error(t, services.ValidateSavedViewFilters("finding", map[string]string{}), "Unknown resource types are rejected")
+}
@@ -0,0 +1,243 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/osv"
+	"github.com/cyops/cyops-backend/pkg/sbom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCycloneDX(t *testing.T) {
+	doc, err := sbom.Parse([]byte(`{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.5",
+		"metadata": {"component": {"name": "billing-api"}},
+		"components": [
+			{
+				"bom-ref": "pkg:npm/lodash@4.17.20",
+				"type": "library",
+				"name": "lodash",
+				"version": "4.17.20",
+				"purl": "pkg:npm/lodash@4.17.20",
+				"licenses": [{"license": {"id": "MIT"}}],
+				"components": [{"type": "library", "name": "nested", "version": "1.0.0"}]
+			},
+			{"type": "library", "name": "lodash", "version": "4.17.20", "purl": "pkg:npm/lodash@4.17.20"},
+			{"type": "library", "name": ""}
+		]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, sbom.FormatCycloneDX, doc.Format)
+	assert.Equal(t, "1.5", doc.SpecVersion)
+	assert.Equal(t, "billing-api", doc.Name)
+	require.Len(t, doc.Components, 2)
+	assert.Equal(t, "lodash", doc.Components[0].Name)
+	assert.Equal(t, []string{"MIT"}, doc.Components[0].Licenses)
+	assert.Equal(t, "nested", doc.Components[1].Name)
+}
+
+func TestParseSPDX(t *testing.T) {
+	doc, err := sbom.Parse([]byte(`{
+		"spdxVersion": "SPDX-2.3",
+		"SPDXID": "SPDXRef-DOCUMENT",
+		"name": "web-image",
+		"packages": [
+			{"SPDXID": "SPDXRef-image", "name": "web-image", "versionInfo": "1.0"},
+			{
+				"SPDXID": "SPDXRef-openssl",
+				"name": "openssl",
+				"versionInfo": "3.0.1",
+				"supplier": "Organization: OpenSSL",
+				"licenseConcluded": "NOASSERTION",
+				"licenseDeclared": "Apache-2.0",
+				"externalRefs": [
+					{"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:openssl:openssl:3.0.1:*:*:*:*:*:*:*"},
+					{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:deb/debian/openssl@3.0.1"}
+				]
+			}
+		],
+		"relationships": [
+			{"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-image"}
+		]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, sbom.FormatSPDX, doc.Format)
+	assert.Equal(t, "2.3", doc.SpecVersion)
+	require.Len(t, doc.Components, 1)
+	c := doc.Components[0]
+	assert.Equal(t, "openssl", c.Name)
+	assert.Equal(t, "OpenSSL", c.Supplier)
+	assert.Equal(t, []string{"Apache-2.0"}, c.Licenses)
+	assert.Equal(t, "pkg:deb/debian/openssl@3.0.1", c.PURL)
+	assert.Contains(t, c.CPE, "cpe:2.3:a:openssl")
+	assert.Equal(t, "deb", sbom.PURLEcosystem(c.PURL))
+}
+
+func TestParseSBOMRejectsUnknownDocuments(t *testing.T) {
+	for name, data := range map[string]string{
+		"empty":   "",
+		"xml":     `<bom xmlns="http://cyclonedx.org/schema/bom/1.5"></bom>`,
+		"unknown": `{"name": "not an sbom"}`,
+		"broken":  `{"bomFormat": `,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := sbom.Parse([]byte(data))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), "invalid SBOM")
+			}
+		})
+	}
+}
+
+func TestVersionedPURL(t *testing.T) {
+	assert.Equal(t, "pkg:npm/lodash@4.17.20", sbom.VersionedPURL(sbom.Component{PURL: "pkg:npm/lodash@4.17.20", Version: "1"}))
+	assert.Equal(t, "pkg:npm/%40babel/core@7.0.0", sbom.VersionedPURL(sbom.Component{PURL: "pkg:npm/%40babel/core", Version: "7.0.0"}))
+	assert.Equal(t, "pkg:deb/debian/curl@7.88.1?arch=amd64", sbom.VersionedPURL(sbom.Component{PURL: "pkg:deb/debian/curl?arch=amd64", Version: "7.88.1"}))
+	assert.Empty(t, sbom.VersionedPURL(sbom.Component{PURL: "pkg:npm/lodash"}))
+	assert.Empty(t, sbom.VersionedPURL(sbom.Component{Name: "lodash", Version: "4.17.20"}))
+}
+
+func TestCVSSv3BaseScore(t *testing.T) {
+	tests := []struct {
+		vector string
+		score  float64
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1},
+		{"CVSS:3.0/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 1.8},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0},
+	}
+	for _, tt := range tests {
+		score, ok := osv.CVSSv3BaseScore(tt.vector)
+		assert.True(t, ok, tt.vector)
+		assert.Equal(t, tt.score, score, tt.vector)
+	}
+
+	_, ok := osv.CVSSv3BaseScore("AV:N/AC:L/Au:N/C:P/I:P/A:P")
+	assert.False(t, ok)
+	_, ok = osv.CVSSv3BaseScore("CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H")
+	assert.False(t, ok)
+}
+
+func TestOSVSourceLookupPackages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/querybatch":
+			var body struct {
+				Queries []struct {
+					Package struct {
+						PURL string `json:"purl"`
+					} `json:"package"`
+				} `json:"queries"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Len(t, body.Queries, 2)
+			assert.Equal(t, "pkg:npm/lodash@4.17.20", body.Queries[0].Package.PURL)
+			w.Write([]byte(`{"results": [{"vulns": [{"id": "GHSA-35jh-r3h4-6jhm"}]}, {}]}`))
+		case "/v1/vulns/GHSA-35jh-r3h4-6jhm":
+			w.Write([]byte(`{
+				"id": "GHSA-35jh-r3h4-6jhm",
+				"summary": "Command Injection in lodash",
+				"aliases": ["CVE-2021-23337"],
+				"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}],
+				"affected": [{"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]}],
+				"references": [{"type": "ADVISORY", "url": "https://nvd.nist.gov/vuln/detail/CVE-2021-23337"}],
+				"database_specific": {"severity": "HIGH"}
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	found, err := services.NewOSVSource(server.URL).LookupPackages(context.Background(), []string{"pkg:npm/lodash@4.17.20", "pkg:npm/left-pad@1.3.0"})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Len(t, found["pkg:npm/lodash@4.17.20"], 1)
+
+	known := found["pkg:npm/lodash@4.17.20"][0]
+	assert.Equal(t, "GHSA-35jh-r3h4-6jhm", known.ID)
+	assert.Equal(t, "CVE-2021-23337", known.CVEID)
+	assert.Equal(t, models.SeverityHigh, known.Severity)
+	require.NotNil(t, known.CVSSScore)
+	assert.Equal(t, 7.2, *known.CVSSScore)
+	assert.Equal(t, []string{"4.17.21"}, known.FixedVersions)
+	assert.Equal(t, []string{"https://nvd.nist.gov/vuln/detail/CVE-2021-23337"}, known.References)
+}
+
+func TestKnownVulnerabilityFromOSVSeverity(t *testing.T) {
+	vuln := &osv.Vulnerability{ID: "PYSEC-2024-1"}
+	assert.Equal(t, models.SeverityMedium, services.KnownVulnerabilityFromOSV(vuln).Severity)
+
+	vuln.Severity = append(vuln.Severity, struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	}{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"})
+	known := services.KnownVulnerabilityFromOSV(vuln)
+	assert.Equal(t, models.SeverityCritical, known.Severity)
+	assert.Empty(t, known.CVEID)
+
+	vuln.DatabaseSpecific.Severity = "moderate"
+	assert.Equal(t, models.SeverityMedium, services.KnownVulnerabilityFromOSV(vuln).Severity)
+}
+
+func TestBuildAssetVEX(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	asset := &models.AffectedSystem{BaseModel: models.BaseModel{ID: uuid.New()}, Hostname: "web-01", SystemType: models.SystemTypeContainer}
+	components := []models.AssetSBOMComponent{
+		{ID: uuid.New(), Name: "lodash", Version: "4.17.20", PURL: "pkg:npm/lodash@4.17.20", Advisories: []string{"GHSA-35jh-r3h4-6jhm", "CVE-2021-23337"}},
+		{ID: uuid.New(), BOMRef: "openssl", Type: "library", Name: "openssl", Version: "3.0.1"},
+	}
+	score := 7.2
+	lodash := &models.Vulnerability{Title: "CVE-2021-23337: Command Injection in lodash", CVEID: "CVE-2021-23337", Severity: models.SeverityHigh, CVSSScore: &score, CVSSVector: "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}
+	nessus := &models.Vulnerability{Title: "TLS weak ciphers", CVEID: "CVE-2016-2183", Severity: models.SeverityMedium}
+	expires := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	findings := []models.VulnerabilityFinding{
+		{Vulnerability: lodash, ScannerName: services.SBOMScannerName, PluginID: "CVE-2021-23337", Status: models.FindingStatusAccepted, AcceptanceReason: "Not reachable", ExpiresAt: &expires, FirstDetected: now.Add(-48 * time.Hour), UpdatedAt: now.Add(-time.Hour)},
+		{Vulnerability: nessus, ScannerName: "nessus", Port: "443", Status: models.FindingStatusFixed, FirstDetected: now.Add(-24 * time.Hour), UpdatedAt: now},
+		{Vulnerability: nessus, ScannerName: "nessus", Port: "8443", Status: models.FindingStatusOpen, FirstDetected: now.Add(-12 * time.Hour), UpdatedAt: now.Add(-2 * time.Hour)},
+		{Vulnerability: &models.Vulnerability{Title: "Self-signed certificate"}, ScannerName: "nessus", Status: models.FindingStatusOpen},
+	}
+
+	doc := services.BuildAssetVEX(asset, components, findings, now)
+	assert.Equal(t, "CycloneDX", doc.BOMFormat)
+	assert.Equal(t, "2026-05-01T12:00:00Z", doc.Metadata.Timestamp)
+	require.NotNil(t, doc.Metadata.Component)
+	assert.Equal(t, "web-01", doc.Metadata.Component.Name)
+	assert.Equal(t, "container", doc.Metadata.Component.Type)
+	require.Len(t, doc.Components, 2)
+	assert.Equal(t, "pkg:npm/lodash@4.17.20", doc.Components[0].BOMRef)
+	assert.Equal(t, "library", doc.Components[0].Type)
+
+	// Findings without a CVE or advisory ID are left out
+	require.Len(t, doc.Vulnerabilities, 2)
+
+	accepted := doc.Vulnerabilities[0]
+	assert.Equal(t, "CVE-2021-23337", accepted.ID)
+	assert.Equal(t, "NVD", accepted.Source.Name)
+	assert.Equal(t, sbom.VEXStateNotAffected, accepted.Analysis.State)
+	assert.Equal(t, []string{sbom.VEXResponseWillNotFix}, accepted.Analysis.Response)
+	assert.Equal(t, "Risk accepted: Not reachable (until 2026-12-31)", accepted.Analysis.Detail)
+	assert.Equal(t, []sbom.VEXAffect{{Ref: "pkg:npm/lodash@4.17.20"}}, accepted.Affects)
+	require.Len(t, accepted.Ratings, 1)
+	assert.Equal(t, "CVSSv31", accepted.Ratings[0].Method)
+	assert.Equal(t, "high", accepted.Ratings[0].Severity)
+
+	// The port still open keeps the vulnerability affected; it applies to the asset itself
+	mixed := doc.Vulnerabilities[1]
+	assert.Equal(t, "CVE-2016-2183", mixed.ID)
+	assert.Equal(t, sbom.VEXStateExploitable, mixed.Analysis.State)
+	assert.Equal(t, "2026-04-30T12:00:00Z", mixed.Analysis.FirstIssued)
+	assert.Equal(t, "2026-05-01T12:00:00Z", mixed.Analysis.LastUpdated)
+	assert.Equal(t, []sbom.VEXAffect{{Ref: "asset-" + asset.ID.String()}}, mixed.Affects)
+}
@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerRunsClusterJobOnceAcrossInstances(t *testing.T) {
+	coordinator := scheduler.NewLocalCoordinator()
+	first := scheduler.New(coordinator)
+	second := scheduler.New(coordinator)
+
+	var clusterRuns, instanceRuns int32
+	cluster := scheduler.Job{
+		Name:     "cluster-job",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&clusterRuns, 1)
+			return nil
+		},
+	}
+	instance := scheduler.Job{
+		Name:     "instance-job",
+		Scope:    scheduler.ScopeInstance,
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&instanceRuns, 1)
+			return nil
+		},
+	}
+	for _, s := range []*scheduler.Scheduler{first, second} {
+		require.NoError(t, s.Register(cluster))
+		require.NoError(t, s.Register(instance))
+	}
+
+	ctx := context.Background()
+	for _, s := range []*scheduler.Scheduler{first, second} {
+		for _, job := range s.Jobs() {
+			job := job
+			s.RunOnce(ctx, &job)
+		}
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&clusterRuns), "a cluster job runs once per period")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&instanceRuns), "an instance job runs on every instance")
+}
+
+func TestSchedulerSkipsDisabledPeriod(t *testing.T) {
+	s := scheduler.New(scheduler.NewLocalCoordinator())
+	var runs int32
+	job := scheduler.Job{
+		Name:     "disabled-job",
+		Interval: time.Minute,
+		Period:   func() (time.Duration, error) { return 0, nil },
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		},
+	}
+	require.NoError(t, s.Register(job))
+	s.RunOnce(context.Background(), &job)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&runs))
+}
+
+func TestSchedulerRegisterValidation(t *testing.T) {
+	s := scheduler.New(scheduler.NewLocalCoordinator())
+	run := func(ctx context.Context) error { return nil }
+
+	require.NoError(t, s.Register(scheduler.Job{Name: "job", Interval: time.Minute, Run: run}))
+	assert.Error(t, s.Register(scheduler.Job{Name: "job", Interval: time.Minute, Run: run}), "duplicate name")
+	assert.Error(t, s.Register(scheduler.Job{Name: "no-interval", Run: run}))
+	assert.Error(t, s.Register(scheduler.Job{Name: "bad-scope", Scope: "global", Interval: time.Minute, Run: run}))
+	assert.Error(t, s.Register(scheduler.Job{Name: "no-run", Interval: time.Minute}))
+
+	jobs := s.Jobs()
+	require.Len(t, jobs, 1)
+	assert.Equal(t, scheduler.ScopeCluster, jobs[0].Scope, "jobs default to the cluster scope")
+}
+
+func TestSchedulerRecoversPanicsAndStops(t *testing.T) {
+	s := scheduler.New(scheduler.NewLocalCoordinator())
+	var stopRuns int32
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:       "panics",
+		Interval:   time.Hour,
+		RunOnStart: true,
+		Run:        func(ctx context.Context) error { panic("boom") },
+	}))
+	require.NoError(t, s.Register(scheduler.Job{
+		Name:      "flush",
+		Scope:     scheduler.ScopeInstance,
+		Interval:  time.Hour,
+		RunOnStop: true,
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&stopRuns, 1)
+			return errors.New("ignored")
+		},
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+	cancel()
+	require.True(t, s.Wait(context.Background()))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&stopRuns), "instance jobs run once more on stop")
+}
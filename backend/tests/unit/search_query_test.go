@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildVulnerabilitySearchQuery(t *testing.T) {
+	assetID := uuid.New()
+
+	query, err := services.BuildVulnerabilitySearchQuery(services.ListVulnerabilitiesRequest{
+		Search:    "openssl",
+		Severity:  []models.VulnerabilitySeverity{models.SeverityCritical},
+		AssetID:   &assetID,
+		SortBy:    "severity",
+		SortOrder: "asc",
+	}, 3, 20)
+	require.NoError(t, err)
+
+	assert.Equal(t, 40, query["from"])
+	assert.Equal(t, 20, query["size"])
+
+	boolClause := query["query"].(map[string]interface{})["bool"].(map[string]interface{})
+	assert.Len(t, boolClause["must"], 1)
+	assert.Len(t, boolClause["filter"], 2)
+
+	sort := query["sort"].([]interface{})
+	primary := sort[0].(map[string]interface{})
+	assert.Contains(t, primary, "severity_rank")
+	assert.Equal(t, "asc", primary["severity_rank"].(map[string]string)["order"])
+}
+
+func TestBuildVulnerabilitySearchQueryRejectsUnsupported(t *testing.T) {
+	// Unknown sort fields and deep pages are left to the Postgres fallback
+	_, err := services.BuildVulnerabilitySearchQuery(services.ListVulnerabilitiesRequest{SortBy: "cvss_vector"}, 1, 50)
+	assert.Error(t, err)
+
+	_, err = services.BuildVulnerabilitySearchQuery(services.ListVulnerabilitiesRequest{}, 300, 50)
+	assert.Error(t, err)
+}
+
+func TestBuildVulnerabilitySearchQueryFiltersTags(t *testing.T) {
+	query, err := services.BuildVulnerabilitySearchQuery(services.ListVulnerabilitiesRequest{
+		Tags: []string{"pci", "external"},
+	}, 1, 50)
+	require.NoError(t, err)
+
+	// Every requested tag must match
+	filters := query["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"].([]interface{})
+	require.Len(t, filters, 2)
+	assert.Equal(t, map[string]interface{}{"term": map[string]interface{}{"tags": "pci"}}, filters[0])
+	assert.Equal(t, map[string]interface{}{"term": map[string]interface{}{"tags": "external"}}, filters[1])
+}
+
+func TestBuildAssetSearchQueryDefaults(t *testing.T) {
+	query, err := services.BuildAssetSearchQuery(services.AssetListParams{
+		Page:  1,
+		Limit: 50,
+		Tags:  []string{" PCI ", "dmz"},
+	})
+	require.NoError(t, err)
+
+	filters := query["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"].([]interface{})
+	require.Len(t, filters, 3)
+
+	// Only active assets by default, and every requested tag must match
+	assert.Equal(t, map[string]interface{}{"term": map[string]interface{}{"status": string(models.StatusActive)}}, filters[0])
+	assert.Equal(t, map[string]interface{}{"term": map[string]interface{}{"tags": "pci"}}, filters[1])
+	assert.Equal(t, map[string]interface{}{"term": map[string]interface{}{"tags": "dmz"}}, filters[2])
+}
+
+func TestBuildAssetSearchQueryFiltersOwnerTeam(t *testing.T) {
+	teamID := uuid.New()
+
+	query, err := services.BuildAssetSearchQuery(services.AssetListParams{
+		Page:        1,
+		Limit:       50,
+		OwnerTeamID: &teamID,
+	})
+	require.NoError(t, err)
+
+	filters := query["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"].([]interface{})
+	require.Len(t, filters, 2)
+	assert.Equal(t, map[string]interface{}{"term": map[string]interface{}{"owner_team_id": teamID.String()}}, filters[1])
+}
+
+func TestBuildFindingSearchQueryScopesOrganization(t *testing.T) {
+	orgID := uuid.New()
+
+	query, err := services.BuildFindingSearchQuery(map[string]interface{}{
+		"status": "OPEN",
+		"org_id": orgID,
+	}, 1, 25)
+	require.NoError(t, err)
+
+	filters := query["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"].([]interface{})
+	require.Len(t, filters, 2)
+	assert.Equal(t, map[string]interface{}{"term": map[string]interface{}{"org_id": orgID.String()}}, filters[1])
+}
@@ -0,0 +1,126 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	cases := []struct {
+		name      string
+		userAgent string
+		want      auth.DeviceInfo
+	}{
+		{
+			name:      "chrome on windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36",
+			want:      auth.DeviceInfo{Browser: "Chrome 126", OS: "Windows 10/11", DeviceType: auth.DeviceTypeDesktop},
+		},
+		{
+			name:      "edge claims chrome",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.2592.87",
+			want:      auth.DeviceInfo{Browser: "Edge 126", OS: "Windows 10/11", DeviceType: auth.DeviceTypeDesktop},
+		},
+		{
+			name:      "safari on iphone",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1",
+			want:      auth.DeviceInfo{Browser: "Safari 17", OS: "iOS 17", DeviceType: auth.DeviceTypeMobile},
+		},
+		{
+			name:      "firefox on macos",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:127.0) Gecko/20100101 Firefox/127.0",
+			want:      auth.DeviceInfo{Browser: "Firefox 127", OS: "macOS 10.15", DeviceType: auth.DeviceTypeDesktop},
+		},
+		{
+			name:      "android tablet",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36",
+			want:      auth.DeviceInfo{Browser: "Chrome 125", OS: "Android 14", DeviceType: auth.DeviceTypeTablet},
+		},
+		{
+			name:      "curl",
+			userAgent: "curl/8.5.0",
+			want:      auth.DeviceInfo{Browser: "curl 8", OS: "Unknown", DeviceType: auth.DeviceTypeBot},
+		},
+		{
+			name:      "empty",
+			userAgent: "",
+			want:      auth.DeviceInfo{Browser: "Unknown", OS: "Unknown", DeviceType: auth.DeviceTypeUnknown},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, auth.ParseUserAgent(tc.userAgent))
+		})
+	}
+}
+
+func TestLocateIP(t *testing.T) {
+	assert.Equal(t, "Localhost", auth.LocateIP("127.0.0.1"))
+	assert.Equal(t, "Localhost", auth.LocateIP("::1"))
+	assert.Equal(t, "Private network", auth.LocateIP("10.1.2.3"))
+	assert.Equal(t, "Private network", auth.LocateIP("192.168.20.21"))
+	assert.Equal(t, "", auth.LocateIP("203.0.113.7"))
+	assert.Equal(t, "", auth.LocateIP("not-an-ip"))
+}
+
+func TestCreateSessionUsesShortLivedAccessToken(t *testing.T) {
+	session, err := auth.CreateSession(uuid.New(), "10.0.0.1", "curl/8.5.0", 0)
+	require.NoError(t, err)
+
+	require.NoError(t, auth.ValidateSessionToken(session.Token))
+	assert.WithinDuration(t, time.Now().Add(auth.AccessTokenDuration), session.AccessExpiresAt, time.Second)
+	assert.WithinDuration(t, time.Now().Add(auth.MaxSessionLifetime), session.ExpiresAt, time.Second)
+	assert.Equal(t, "curl 8", session.Browser)
+	assert.False(t, session.IsAccessExpired())
+	assert.True(t, session.IsValid())
+}
+
+func TestTokenExpiryIsCappedBySession(t *testing.T) {
+	now := time.Now()
+	session := &models.Session{ExpiresAt: now.Add(time.Hour)}
+
+	// The refresh idle timeout is longer than what is left of the session
+	assert.Equal(t, session.ExpiresAt, auth.RefreshTokenExpiry(session, now))
+	assert.Equal(t, now.Add(auth.AccessTokenDuration), auth.AccessTokenExpiry(session, now))
+
+	session.ExpiresAt = now.Add(5 * time.Minute)
+	assert.Equal(t, session.ExpiresAt, auth.AccessTokenExpiry(session, now))
+}
+
+func TestSessionAccessExpiry(t *testing.T) {
+	session := &models.Session{IsActive: true, ExpiresAt: time.Now().Add(time.Hour)}
+
+	// Sessions created before access tokens existed fall back to the session expiry
+	assert.False(t, session.IsAccessExpired())
+
+	session.AccessExpiresAt = time.Now().Add(-time.Minute)
+	assert.True(t, session.IsAccessExpired())
+	assert.True(t, session.IsValid(), "an expired access token leaves the session refreshable")
+
+	session.Revoke(models.SessionRevokeOthers)
+	assert.False(t, session.IsValid())
+	assert.Equal(t, models.SessionRevokeOthers, session.RevokeReason)
+}
+
+func TestRefreshTokenUsable(t *testing.T) {
+	now := time.Now()
+	token := &models.RefreshToken{ExpiresAt: now.Add(time.Hour)}
+	assert.True(t, token.IsUsable())
+
+	token.UsedAt = &now
+	assert.False(t, token.IsUsable())
+
+	token.UsedAt = nil
+	token.RevokedAt = &now
+	assert.False(t, token.IsUsable())
+
+	assert.Equal(t, auth.HashRefreshToken("abc"), auth.HashRefreshToken("abc"))
+	assert.Len(t, auth.HashRefreshToken("abc"), 64)
+}
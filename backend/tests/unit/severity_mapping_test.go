@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeverityMapping(t *testing.T) {
+	score := func(v float64) *float64 { return &v }
+
+	t.Run("nil mapping uses the default levels", func(t *testing.T) {
+		var mapping *models.SeverityMapping
+		assert.Equal(t, models.SeverityNone, mapping.Severity(models.ScannerSeverityInfo, score(9.8), models.SeverityLow))
+		assert.Equal(t, models.SeverityHigh, mapping.Severity(models.ScannerSeverityHigh, nil, models.SeverityLow))
+		assert.Equal(t, models.SeverityLow, mapping.Severity("", nil, models.SeverityLow))
+	})
+
+	t.Run("levels override the defaults they name", func(t *testing.T) {
+		mapping := &models.SeverityMapping{Levels: map[string]models.VulnerabilitySeverity{
+			models.ScannerSeverityInfo:   models.SeverityLow,
+			models.ScannerSeverityMedium: models.SeverityHigh,
+		}}
+		assert.Equal(t, models.SeverityLow, mapping.Severity(models.ScannerSeverityInfo, nil, models.SeverityNone))
+		assert.Equal(t, models.SeverityHigh, mapping.Severity(models.ScannerSeverityMedium, nil, models.SeverityMedium))
+		assert.Equal(t, models.SeverityCritical, mapping.Severity(models.ScannerSeverityCritical, nil, models.SeverityCritical))
+	})
+
+	t.Run("cvss thresholds classify results with a score", func(t *testing.T) {
+		mapping := &models.SeverityMapping{
+			Levels:         map[string]models.VulnerabilitySeverity{models.ScannerSeverityHigh: models.SeverityMedium},
+			CVSSThresholds: &models.CVSSSeverityThresholds{Critical: 9, High: 6.5, Medium: 4, Low: 0.1},
+		}
+		assert.Equal(t, models.SeverityHigh, mapping.Severity(models.ScannerSeverityMedium, score(6.5), models.SeverityMedium))
+		assert.Equal(t, models.SeverityMedium, mapping.Severity(models.ScannerSeverityHigh, score(6.4), models.SeverityHigh))
+		assert.Equal(t, models.SeverityNone, mapping.Severity(models.ScannerSeverityLow, score(0), models.SeverityLow))
+		assert.Equal(t, models.SeverityMedium, mapping.Severity(models.ScannerSeverityHigh, nil, models.SeverityHigh))
+	})
+}
+
+func TestValidateSeverityMapping(t *testing.T) {
+	t.Run("normalizes levels and severities", func(t *testing.T) {
+		mapping := &models.SeverityMapping{Levels: map[string]models.VulnerabilitySeverity{" Info ": "low"}}
+		require.NoError(t, services.ValidateSeverityMapping(mapping))
+		assert.Equal(t, map[string]models.VulnerabilitySeverity{"info": models.SeverityLow}, mapping.Levels)
+	})
+
+	t.Run("rejects unknown levels and severities", func(t *testing.T) {
+		err := services.ValidateSeverityMapping(&models.SeverityMapping{Levels: map[string]models.VulnerabilitySeverity{"urgent": models.SeverityHigh}})
+		assert.ErrorContains(t, err, "invalid scanner severity level")
+
+		err = services.ValidateSeverityMapping(&models.SeverityMapping{Levels: map[string]models.VulnerabilitySeverity{"low": "SEVERE"}})
+		assert.ErrorContains(t, err, "invalid severity")
+	})
+
+	t.Run("requires ordered thresholds", func(t *testing.T) {
+		for _, thresholds := range []models.CVSSSeverityThresholds{
+			{Critical: 9, High: 9, Medium: 4, Low: 0.1},
+			{Critical: 11, High: 7, Medium: 4, Low: 0.1},
+			{Critical: 9, High: 7, Medium: 4, Low: -1},
+		} {
+			thresholds := thresholds
+			err := services.ValidateSeverityMapping(&models.SeverityMapping{CVSSThresholds: &thresholds})
+			assert.ErrorContains(t, err, "invalid cvss thresholds")
+		}
+		require.NoError(t, services.ValidateSeverityMapping(&models.SeverityMapping{
+			CVSSThresholds: &models.CVSSSeverityThresholds{Critical: 9, High: 7, Medium: 4, Low: 0},
+		}))
+	})
+}
+
+func TestParseSeverityMapping(t *testing.T) {
+	mapping, err := models.ParseSeverityMapping("")
+	require.NoError(t, err)
+	assert.Nil(t, mapping)
+
+	config := models.IntegrationConfig{SeverityMapping: `{"levels":{"info":"LOW"},"cvss_thresholds":{"critical":9,"high":6.5,"medium":4,"low":0.1}}`}
+	mapping, err = config.ParseSeverityMapping()
+	require.NoError(t, err)
+	assert.Equal(t, models.SeverityLow, mapping.Levels["info"])
+	assert.Equal(t, 6.5, mapping.CVSSThresholds.High)
+	assert.Equal(t, mapping, config.ToPublic().SeverityMapping)
+}
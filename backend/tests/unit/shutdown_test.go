@@ -0,0 +1,55 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/pkg/shutdown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownDrainWaitsForRunningWork(t *testing.T) {
+	coordinator := shutdown.NewCoordinator()
+	done, err := coordinator.Begin("import")
+	require.NoError(t, err)
+	assert.False(t, coordinator.Draining())
+
+	drained := make(chan []string)
+	go func() {
+		drained <- coordinator.Drain(context.Background())
+	}()
+
+	// Running work sees the drain at its next checkpoint; new work is refused
+	require.Eventually(t, coordinator.Draining, time.Second, time.Millisecond)
+	_, err = coordinator.Begin("report")
+	assert.ErrorIs(t, err, shutdown.ErrDraining)
+
+	done()
+	done() // Calling done twice is harmless
+	select {
+	case running := <-drained:
+		assert.Empty(t, running)
+	case <-time.After(time.Second):
+		t.Fatal("drain did not return after the work ended")
+	}
+}
+
+func TestShutdownDrainTimeout(t *testing.T) {
+	coordinator := shutdown.NewCoordinator()
+	_, err := coordinator.Begin("import")
+	require.NoError(t, err)
+	_, err = coordinator.Begin("backup")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, []string{"backup", "import"}, coordinator.Drain(ctx))
+}
+
+func TestShutdownDrainWithoutWork(t *testing.T) {
+	coordinator := shutdown.NewCoordinator()
+	assert.Empty(t, coordinator.Drain(context.Background()))
+	assert.True(t, coordinator.Draining())
+}
@@ -0,0 +1,190 @@
+package unit
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/siem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func siemTestEvent() siem.Event {
+	return siem.Event{
+		Time:     time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC),
+		Type:     "auth.login_failed",
+		Name:     "Login failed | bad password",
+		Severity: 5,
+		Fields:   map[string]string{"src": "10.0.0.1", "reason": `wrong="pw"]`, "empty": ""},
+	}
+}
+
+func TestSIEMMessageFormats(t *testing.T) {
+	t.Run("cef", func(t *testing.T) {
+		msg := siem.FormatCEFMessage(siemTestEvent(), "web 1", "cyops")
+		assert.Equal(t,
+			`<164>1 2026-03-04T05:06:07.000Z web_1 cyops - auth.login_failed - `+
+				`CEF:0|CYOPS|CYOPS|1.0|auth.login_failed|Login failed \| bad password|5|`+
+				`rt=1772600767000 reason=wrong\="pw"] src=10.0.0.1`,
+			msg)
+	})
+
+	t.Run("rfc5424", func(t *testing.T) {
+		msg := siem.FormatRFC5424Message(siemTestEvent(), "", "cyops")
+		assert.Equal(t,
+			`<164>1 2026-03-04T05:06:07.000Z - cyops - auth.login_failed `+
+				`[cyops@32473 type="auth.login_failed" reason="wrong=\"pw\"\]" src="10.0.0.1"] Login failed | bad password`,
+			msg)
+	})
+
+	t.Run("framing", func(t *testing.T) {
+		assert.Equal(t, "abc\n", siem.Frame("abc", siem.FramingNewline))
+		assert.Equal(t, "3 abc", siem.Frame("abc", siem.FramingOctetCounting))
+	})
+}
+
+func TestSIEMForwarderReconnects(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	lines := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+					// Drop the connection after the first message to force a reconnect
+					return
+				}
+			}()
+		}
+	}()
+
+	forwarder, err := siem.New(siem.Config{
+		Address:  listener.Addr().String(),
+		Protocol: siem.ProtocolTCP,
+		Format:   siem.FormatRFC5424,
+	})
+	require.NoError(t, err)
+	defer forwarder.Close(time.Second)
+
+	receive := func() string {
+		select {
+		case line := <-lines:
+			return line
+		case <-time.After(10 * time.Second):
+			t.Fatal("no message received")
+			return ""
+		}
+	}
+
+	first := siemTestEvent()
+	first.Type = "first"
+	require.True(t, forwarder.Send(first))
+	assert.Contains(t, receive(), `type="first"`)
+
+	// The collector closed the connection; the next event goes out on a new one
+	assert.Eventually(t, func() bool { return !forwarder.Stats().Connected }, 5*time.Second, 10*time.Millisecond)
+	second := siemTestEvent()
+	second.Type = "second"
+	require.True(t, forwarder.Send(second))
+	assert.Contains(t, receive(), `type="second"`)
+	assert.Equal(t, uint64(2), forwarder.Stats().Sent)
+}
+
+func TestSIEMForwarderDropsWhenFull(t *testing.T) {
+	// Nothing listens on port 1, so events stay buffered
+	forwarder, err := siem.New(siem.Config{
+		Address:     "127.0.0.1:1",
+		Protocol:    siem.ProtocolTCP,
+		Format:      siem.FormatCEF,
+		BufferSize:  1,
+		DialTimeout: 100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer forwarder.Close(0)
+
+	dropped := 0
+	for i := 0; i < 5; i++ {
+		if !forwarder.Send(siemTestEvent()) {
+			dropped++
+		}
+	}
+	assert.GreaterOrEqual(t, dropped, 3)
+	assert.Equal(t, uint64(dropped), forwarder.Stats().Dropped)
+}
+
+func TestValidateSIEMForwarderSettings(t *testing.T) {
+	settings := services.SIEMForwarderSettings{Enabled: true, Host: " siem.local ", Port: 6514, Protocol: "TLS", Categories: []string{" Audit "}}
+	require.NoError(t, services.ValidateSIEMForwarderSettings(&settings))
+	assert.Equal(t, "siem.local", settings.Host)
+	assert.Equal(t, siem.ProtocolTLS, settings.Protocol)
+	assert.Equal(t, siem.FormatCEF, settings.Format)
+	assert.Equal(t, siem.FramingNewline, settings.Framing)
+	assert.Equal(t, siem.DefaultBufferSize, settings.BufferSize)
+	assert.Equal(t, []string{services.SIEMCategoryAudit}, settings.Categories)
+
+	// Disabled settings may leave the destination empty
+	assert.NoError(t, services.ValidateSIEMForwarderSettings(&services.SIEMForwarderSettings{}))
+
+	tests := []struct {
+		name     string
+		settings services.SIEMForwarderSettings
+		wantErr  string
+	}{
+		{"missing host", services.SIEMForwarderSettings{Enabled: true, Port: 514}, "host is required"},
+		{"missing port", services.SIEMForwarderSettings{Enabled: true, Host: "siem"}, "invalid port"},
+		{"udp", services.SIEMForwarderSettings{Enabled: true, Host: "siem", Port: 514, Protocol: "udp"}, "invalid protocol"},
+		{"leef", services.SIEMForwarderSettings{Enabled: true, Host: "siem", Port: 514, Format: "leef"}, "invalid format"},
+		{"bad ca", services.SIEMForwarderSettings{Enabled: true, Host: "siem", Port: 514, CACert: "not a cert"}, "invalid ca_cert"},
+		{"bad category", services.SIEMForwarderSettings{Enabled: true, Host: "siem", Port: 514, Categories: []string{"assets"}}, "invalid category"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := services.ValidateSIEMForwarderSettings(&tt.settings)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSIEMEventFor(t *testing.T) {
+	userID := uuid.New()
+	authEvent := models.NewFailedAuthEvent(&userID, models.EventTypeLoginFailed, "10.0.0.1", "curl", "invalid password")
+	category, event, ok := services.SIEMEventFor(authEvent, false)
+	require.True(t, ok)
+	assert.Equal(t, services.SIEMCategoryAudit, category)
+	assert.Equal(t, "auth.login_failed", event.Type)
+	assert.Equal(t, "failure", event.Fields["outcome"])
+	assert.Equal(t, userID.String(), event.Fields["suid"])
+	assert.Equal(t, 5, event.Severity)
+
+	history := &models.VulnerabilityStatusHistory{VulnerabilityID: uuid.New(), OldStatus: models.StatusOpen, NewStatus: models.StatusResolved, ChangedByID: userID}
+	category, event, ok = services.SIEMEventFor(history, false)
+	require.True(t, ok)
+	assert.Equal(t, services.SIEMCategoryVulnerability, category)
+	assert.Equal(t, "vulnerability.status_changed", event.Type)
+	assert.Equal(t, "RESOLVED", event.Fields["newStatus"])
+
+	vuln := &models.Vulnerability{BaseModel: models.BaseModel{ID: uuid.New()}, Severity: models.SeverityCritical}
+	_, event, ok = services.SIEMEventFor(vuln, true)
+	require.True(t, ok)
+	assert.Equal(t, "vulnerability.deleted", event.Type)
+	assert.Equal(t, 9, event.Severity)
+
+	_, _, ok = services.SIEMEventFor(&models.Team{}, false)
+	assert.False(t, ok)
+}
@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func stringPtr(s string) *string { return &s }
+
+func TestParseFieldsetDefaults(t *testing.T) {
+	fieldset, err := services.ParseFieldset(services.VulnerabilityFields, "", nil)
+	require.NoError(t, err)
+	assert.Nil(t, fieldset, "no parameters keep the endpoint defaults")
+	assert.False(t, fieldset.Sparse())
+	assert.True(t, fieldset.Wants("title"))
+
+	fieldset, err = services.ParseFieldset(services.VulnerabilityFields, "", stringPtr(""))
+	require.NoError(t, err)
+	require.NotNil(t, fieldset, "an empty include loads no relations")
+	assert.Empty(t, fieldset.Include)
+	assert.False(t, fieldset.Sparse())
+}
+
+func TestParseFieldsetValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource *services.SparseResource
+		fields   string
+		include  *string
+		wantErr  string
+	}{
+		{"unknown field", services.VulnerabilityFields, "title,nope", nil, `invalid field "nope" for vulnerabilities`},
+		{"relation is not a field", services.FindingFields, "comments", nil, `invalid field "comments"`},
+		{"unknown relation", services.FindingFields, "", stringPtr("owner"), `invalid include "owner" for findings`},
+		{"unknown nested relation", services.FindingFields, "", stringPtr("comments.nope"), `invalid include "comments.nope"`},
+		{"computed field of another resource", services.FindingFields, "vulnerability_count", nil, "invalid field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := services.ParseFieldset(tt.resource, tt.fields, tt.include)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestParseFieldsetComputedFields(t *testing.T) {
+	fieldset, err := services.ParseFieldset(services.AssetFields, "hostname, vulnerability_count", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hostname", "vulnerability_count"}, fieldset.Fields)
+	assert.True(t, fieldset.Sparse())
+	assert.True(t, fieldset.Wants("vulnerability_count"))
+	assert.True(t, fieldset.Wants("id"), "the primary key is always returned")
+	assert.False(t, fieldset.Wants("vulnerability_stats"))
+}
+
+func TestFieldsetApplySelectsColumns(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	fieldset, err := services.ParseFieldset(services.FindingFields, "status", stringPtr("comments.author,fixed_by_user"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"comments.author", "fixed_by_user"}, fieldset.Include)
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var findings []models.VulnerabilityFinding
+		return fieldset.Apply(tx, nil).Find(&findings)
+	})
+	assert.Contains(t, sql, "vulnerability_findings.id")
+	assert.Contains(t, sql, "vulnerability_findings.fixed_by", "preloaded relations need their foreign key")
+	assert.Contains(t, sql, "vulnerability_findings.status")
+	assert.NotContains(t, sql, "first_seen")
+}
+
+func TestFieldsetProject(t *testing.T) {
+	fieldset, err := services.ParseFieldset(services.VulnerabilityFields, "title", stringPtr("tags"))
+	require.NoError(t, err)
+
+	list, err := fieldset.Project([]models.Vulnerability{{Title: "a", Severity: models.SeverityHigh}, {Title: "b"}})
+	require.NoError(t, err)
+	records := list.([]interface{})
+	require.Len(t, records, 2)
+	assert.ElementsMatch(t, []string{"id", "title"}, mapKeys(records[0].(map[string]interface{})))
+
+	object, err := fieldset.Project(&models.Vulnerability{Title: "c"})
+	require.NoError(t, err)
+	assert.Equal(t, "c", object.(map[string]interface{})["title"])
+	assert.NotContains(t, object.(map[string]interface{}), "severity")
+
+	unchanged, err := (*services.Fieldset)(nil).Project("value")
+	require.NoError(t, err)
+	assert.Equal(t, "value", unchanged)
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
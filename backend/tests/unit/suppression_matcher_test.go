@@ -0,0 +1,68 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuppressionMatcher(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	assetID := uuid.New()
+
+	rules := []models.SuppressionRule{
+		{Name: "plugin in lab", PluginID: "10863", CIDR: "10.10.0.0/16", Enabled: true},
+		{Name: "cve", CVEID: "CVE-2023-1234", Enabled: true},
+		{Name: "asset", AssetID: &assetID, Enabled: true},
+		{Name: "disabled", PluginID: "99999", Enabled: false},
+		{Name: "expired", PluginID: "88888", Enabled: true, ExpiresAt: &past},
+		{Name: "no criteria", Enabled: true},
+	}
+	matcher := services.NewSuppressionMatcher(rules, now)
+
+	tests := []struct {
+		name     string
+		target   services.SuppressionTarget
+		expected string
+	}{
+		{"plugin and cidr", services.SuppressionTarget{PluginID: "10863", IPAddress: "10.10.4.2"}, "plugin in lab"},
+		{"plugin outside cidr", services.SuppressionTarget{PluginID: "10863", IPAddress: "192.168.1.5"}, ""},
+		{"cve case-insensitive", services.SuppressionTarget{CVEID: "cve-2023-1234"}, "cve"},
+		{"asset", services.SuppressionTarget{AssetID: assetID}, "asset"},
+		{"disabled rule", services.SuppressionTarget{PluginID: "99999"}, ""},
+		{"expired rule", services.SuppressionTarget{PluginID: "88888"}, ""},
+		{"no match", services.SuppressionTarget{PluginID: "1", IPAddress: "10.0.0.1"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := matcher.Match(tt.target)
+			if tt.expected == "" {
+				assert.Nil(t, rule)
+				return
+			}
+			require.NotNil(t, rule)
+			assert.Equal(t, tt.expected, rule.Name)
+		})
+	}
+}
+
+func TestValidateSuppressionRule(t *testing.T) {
+	valid := &models.SuppressionRule{Name: "ok", Action: models.SuppressionActionSuppress, CIDR: "10.0.0.0/8"}
+	assert.NoError(t, services.ValidateSuppressionRule(valid))
+
+	noCriteria := &models.SuppressionRule{Name: "none", Action: models.SuppressionActionSuppress}
+	assert.Error(t, services.ValidateSuppressionRule(noCriteria))
+
+	badCIDR := &models.SuppressionRule{Name: "bad", Action: models.SuppressionActionFalsePositive, CIDR: "10.0.0.0/99"}
+	assert.Error(t, services.ValidateSuppressionRule(badCIDR))
+
+	badAction := &models.SuppressionRule{Name: "bad", Action: "DELETE", PluginID: "1"}
+	assert.Error(t, services.ValidateSuppressionRule(badAction))
+}
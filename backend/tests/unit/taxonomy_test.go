@@ -0,0 +1,144 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/taxonomy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxonomyNormalize(t *testing.T) {
+	for raw, want := range map[string]string{"A03:2021": "A03:2021", "a3": "A03:2021", " A10 ": "A10:2021"} {
+		code, ok := taxonomy.Normalize(taxonomy.FrameworkOWASP, raw)
+		assert.True(t, ok, raw)
+		assert.Equal(t, want, code, raw)
+	}
+	for _, raw := range []string{"", "A00", "A11", "A03:2017", "Injection"} {
+		_, ok := taxonomy.Normalize(taxonomy.FrameworkOWASP, raw)
+		assert.False(t, ok, raw)
+	}
+
+	for raw, want := range map[string]string{"T1190": "T1190", "t1078.001": "T1078.001"} {
+		code, ok := taxonomy.Normalize(taxonomy.FrameworkATTACK, raw)
+		assert.True(t, ok, raw)
+		assert.Equal(t, want, code, raw)
+	}
+	for _, raw := range []string{"T119", "T1078.1", "TA0001", "1190"} {
+		_, ok := taxonomy.Normalize(taxonomy.FrameworkATTACK, raw)
+		assert.False(t, ok, raw)
+	}
+}
+
+func TestTaxonomyParseCodes(t *testing.T) {
+	codes, err := taxonomy.ParseCodes(taxonomy.FrameworkOWASP, []string{"A3", "A03:2021", "a01"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A03:2021", "A01:2021"}, codes)
+
+	codes, err = taxonomy.ParseCodes(taxonomy.FrameworkATTACK, []string{})
+	require.NoError(t, err)
+	assert.Empty(t, codes)
+
+	_, err = taxonomy.ParseCodes(taxonomy.FrameworkATTACK, []string{"T1190", "phishing"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid ATT&CK technique 'phishing'")
+
+	_, err = taxonomy.ParseCodes("capec", []string{"1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid framework")
+}
+
+func TestTaxonomyLookupAndTactics(t *testing.T) {
+	entry, ok := taxonomy.Lookup(taxonomy.FrameworkOWASP, "a3")
+	require.True(t, ok)
+	assert.Equal(t, "Injection", entry.Name)
+	assert.Len(t, taxonomy.Entries(taxonomy.FrameworkOWASP), 10)
+
+	entry, ok = taxonomy.Lookup(taxonomy.FrameworkATTACK, "T1190")
+	require.True(t, ok)
+	assert.Equal(t, "Exploit Public-Facing Application", entry.Name)
+	assert.Equal(t, "TA0001", entry.Tactic)
+
+	// Every catalog technique is filed under a catalog tactic
+	tactics := map[string]bool{}
+	for _, tactic := range taxonomy.Tactics() {
+		tactics[tactic.ID] = true
+	}
+	for _, technique := range taxonomy.Entries(taxonomy.FrameworkATTACK) {
+		assert.True(t, tactics[technique.Tactic], technique.ID)
+	}
+
+	// Sub-techniques outside the catalog take their parent's tactic
+	assert.Equal(t, "TA0001", taxonomy.TacticOf("T1078.003"))
+	assert.Equal(t, "TA0004", taxonomy.TacticOf("T1068"))
+	assert.Empty(t, taxonomy.TacticOf("T9999"))
+}
+
+func TestTaxonomyInfer(t *testing.T) {
+	mapping := taxonomy.Infer([]string{"CWE-89", "CWE-79", "CWE-999999"}, "")
+	assert.Equal(t, []string{"A03:2021"}, mapping.OWASP)
+	assert.Equal(t, []string{"T1189", "T1190"}, mapping.ATTACK)
+
+	mapping = taxonomy.Infer(nil, "Default Unix Accounts")
+	assert.Equal(t, []string{"A07:2021"}, mapping.OWASP)
+	assert.Equal(t, []string{"T1078.001"}, mapping.ATTACK)
+
+	// Missing OS patches are outdated components exploited locally
+	mapping = taxonomy.Infer([]string{"CWE-787"}, "Ubuntu Local Security Checks")
+	assert.Equal(t, []string{"A06:2021"}, mapping.OWASP)
+	assert.Equal(t, []string{"T1068"}, mapping.ATTACK)
+
+	assert.True(t, taxonomy.Infer([]string{"CWE-787"}, "General").Empty())
+}
+
+func TestBuildCoverageHeatmap(t *testing.T) {
+	heatmap := services.BuildCoverageHeatmap([]services.ClassificationCount{
+		{Framework: taxonomy.FrameworkOWASP, Code: "A03:2021", Severity: models.SeverityCritical, Total: 2, Open: 1},
+		{Framework: taxonomy.FrameworkOWASP, Code: "A03:2021", Severity: models.SeverityLow, Total: 3, Open: 3},
+		{Framework: taxonomy.FrameworkATTACK, Code: "T1190", Severity: models.SeverityHigh, Total: 4, Open: 2},
+		{Framework: taxonomy.FrameworkATTACK, Code: "T1078.003", Severity: models.SeverityMedium, Total: 1, Open: 1},
+		{Framework: taxonomy.FrameworkATTACK, Code: "T9999", Severity: models.SeverityMedium, Total: 1},
+	}, 8, 6)
+
+	assert.Equal(t, int64(8), heatmap.TotalVulnerabilities)
+	assert.Equal(t, 75.0, heatmap.ClassifiedPercent)
+
+	// Every category has a cell, in rank order
+	require.Len(t, heatmap.OWASP, 10)
+	assert.Equal(t, "A01:2021", heatmap.OWASP[0].Code)
+	injection := heatmap.OWASP[2]
+	assert.Equal(t, "Injection", injection.Name)
+	assert.Equal(t, int64(5), injection.Total)
+	assert.Equal(t, int64(4), injection.Open)
+	assert.Equal(t, int64(2), injection.Critical)
+	assert.Equal(t, int64(3), injection.Low)
+
+	require.Len(t, heatmap.Tactics, len(taxonomy.Tactics())+1)
+	initialAccess := heatmap.Tactics[0]
+	assert.Equal(t, "TA0001", initialAccess.Tactic)
+	cells := map[string]services.CoverageCell{}
+	for _, cell := range initialAccess.Techniques {
+		cells[cell.Code] = cell
+	}
+	assert.Equal(t, int64(4), cells["T1190"].High)
+	assert.Equal(t, int64(0), cells["T1133"].Total)
+	// The sub-technique outside the catalog follows its parent's catalog techniques
+	last := initialAccess.Techniques[len(initialAccess.Techniques)-1]
+	assert.Equal(t, "T1078.003", last.Code)
+	assert.Empty(t, last.Name)
+
+	unknown := heatmap.Localized("en").Tactics[len(heatmap.Tactics)-1]
+	assert.Empty(t, unknown.Tactic)
+	assert.Equal(t, "Other techniques", unknown.Name)
+	require.Len(t, unknown.Techniques, 1)
+	assert.Equal(t, "T9999", unknown.Techniques[0].Code)
+}
+
+func TestBuildCoverageHeatmapEmpty(t *testing.T) {
+	heatmap := services.BuildCoverageHeatmap(nil, 0, 0)
+	assert.Zero(t, heatmap.ClassifiedPercent)
+	assert.Len(t, heatmap.OWASP, 10)
+	assert.Len(t, heatmap.Tactics, len(taxonomy.Tactics()))
+}
@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/cyops/cyops-backend/pkg/tenant"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+func TestTenantContext(t *testing.T) {
+	_, ok := tenant.OrgFromContext(context.Background())
+	assert.False(t, ok)
+
+	// The nil organization never scopes queries
+	_, ok = tenant.OrgFromContext(tenant.WithOrg(context.Background(), uuid.Nil))
+	assert.False(t, ok)
+
+	orgID := uuid.New()
+	got, ok := tenant.OrgFromContext(tenant.WithOrg(context.Background(), orgID))
+	assert.True(t, ok)
+	assert.Equal(t, orgID, got)
+}
+
+func TestScopedTablesHaveOrgColumn(t *testing.T) {
+	// Organizations seeding backfills org_id on every scoped table
+	withOrg := map[string]bool{}
+	for _, model := range models.MigrationModels() {
+		parsed, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		require.NoError(t, err)
+		withOrg[parsed.Table] = parsed.LookUpField("OrgID") != nil
+	}
+	for table := range tenant.ScopedTables {
+		assert.True(t, withOrg[table], "%s has no OrgID column", table)
+	}
+}
+
+// dryRunTenantDB builds statements with the tenant callbacks without a database
+func dryRunTenantDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, tenant.RegisterGORMCallbacks(db))
+	return db
+}
+
+func TestSuppressionRulesScopedToOrganization(t *testing.T) {
+	db := dryRunTenantDB(t)
+	ctx := tenant.WithOrg(context.Background(), uuid.New())
+
+	var rules []models.SuppressionRule
+	scoped := db.WithContext(ctx).Find(&rules)
+	assert.Contains(t, scoped.Statement.SQL.String(), `"suppression_rules"."org_id" =`)
+
+	unscoped := db.Find(&rules)
+	assert.NotContains(t, unscoped.Statement.SQL.String(), "org_id")
+}
+
+func TestSavedViewsAndRiskAcceptancesScopedToOrganization(t *testing.T) {
+	db := dryRunTenantDB(t).WithContext(tenant.WithOrg(context.Background(), uuid.New()))
+
+	var views []models.SavedView
+	assert.Contains(t, db.Find(&views).Statement.SQL.String(), `"saved_views"."org_id" =`)
+
+	var acceptances []models.RiskAcceptance
+	assert.Contains(t, db.Find(&acceptances).Statement.SQL.String(), `"risk_acceptances"."org_id" =`)
+}
+
+func TestParentSubqueryScopedToOrganization(t *testing.T) {
+	// Child records without an org_id column are limited to parents visible to the tenant
+	db := dryRunTenantDB(t).WithContext(tenant.WithOrg(context.Background(), uuid.New()))
+
+	var attachments []models.FindingAttachment
+	sql := db.Where("finding_id IN (?)", db.Model(&models.VulnerabilityFinding{}).Select("id")).
+		Find(&attachments).Statement.SQL.String()
+	assert.Contains(t, sql, `finding_id IN (SELECT "id" FROM "vulnerability_findings" WHERE "vulnerability_findings"."org_id" =`)
+	assert.NotContains(t, sql, `"finding_attachments"."org_id"`)
+}
+
+func TestAssignmentRulesScopedToOrganization(t *testing.T) {
+	db := dryRunTenantDB(t)
+	var statements []string
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	}))
+	service := services.NewAssignmentRuleService(db).WithContext(tenant.WithOrg(context.Background(), uuid.New()))
+
+	_, err := service.ListRules(false)
+	require.NoError(t, err)
+	require.NotEmpty(t, statements)
+	assert.Contains(t, statements[0], `"assignment_rules"."org_id" =`)
+
+	// Users and teams of other organizations cannot be assigned
+	statements = nil
+	name, pattern, analyst := "Web", "CVE-2024-*", uuid.New()
+	_, err = service.CreateRule(services.AssignmentRuleRequest{Name: &name, CVEPattern: &pattern, AssignToID: &analyst}, uuid.New())
+	assert.ErrorContains(t, err, "no such user")
+	require.NotEmpty(t, statements)
+	assert.Contains(t, statements[0], `"users"."org_id" =`)
+}
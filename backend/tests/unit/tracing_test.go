@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/cyops/cyops-backend/internal/middleware"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/pkg/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestTracing records spans in memory for the duration of the test
+func setupTestTracing(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+// spanAttribute returns the value of a span attribute
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingContinuesIncomingTrace(t *testing.T) {
+	recorder := setupTestTracing(t)
+
+	// Dry-run queries fire the callbacks without a database
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, telemetry.RegisterGORMCallbacks(db))
+
+	app := fiber.New()
+	app.Use(middleware.RequestID(), middleware.Tracing())
+	app.Get("/assets/:id", func(c *fiber.Ctx) error {
+		var assets []models.AffectedSystem
+		db.WithContext(c.UserContext()).Find(&assets)
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/assets/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("X-Request-ID", "req-123")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", resp.Header.Get("X-Trace-ID"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	query, server := spans[0], spans[1]
+
+	assert.Equal(t, "GET /assets/:id", server.Name(), "named by route template")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, "req-123", spanAttribute(server, telemetry.RequestIDAttribute).AsString())
+	assert.Equal(t, int64(fiber.StatusNoContent), spanAttribute(server, "http.response.status_code").AsInt64())
+
+	assert.Equal(t, "gorm.query affected_systems", query.Name())
+	assert.Equal(t, server.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Equal(t, "req-123", spanAttribute(query, telemetry.RequestIDAttribute).AsString())
+	assert.Contains(t, spanAttribute(query, "db.statement").AsString(), "affected_systems")
+}
+
+func TestTracingMarksServerErrors(t *testing.T) {
+	recorder := setupTestTracing(t)
+
+	app := fiber.New()
+	app.Use(middleware.RequestID(), middleware.Tracing())
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusNotFound, "not found")
+	})
+	app.Get("/down", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusServiceUnavailable, "database unavailable")
+	})
+
+	for _, path := range []string{"/missing", "/down"} {
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+		require.NoError(t, err)
+	}
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, int64(fiber.StatusNotFound), spanAttribute(spans[0], "http.response.status_code").AsInt64())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code, "client errors are not span errors")
+	assert.Equal(t, int64(fiber.StatusServiceUnavailable), spanAttribute(spans[1], "http.response.status_code").AsInt64())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
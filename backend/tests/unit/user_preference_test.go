@@ -0,0 +1,65 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTimezone(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":                "UTC",
+		"utc":             "UTC",
+		" Europe/Paris ":  "Europe/Paris",
+		"America/Chicago": "America/Chicago",
+	} {
+		timezone, err := services.NormalizeTimezone(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, timezone)
+	}
+
+	for _, input := range []string{"Local", "Mars/Olympus", "+02:00"} {
+		_, err := services.NormalizeTimezone(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestNormalizeDisplayPreferences(t *testing.T) {
+	format, err := services.NormalizeDateFormat("dd.mm.yyyy")
+	require.NoError(t, err)
+	assert.Equal(t, models.DateFormatDE, format)
+	_, err = services.NormalizeDateFormat("YYYY/MM/DD")
+	assert.Error(t, err)
+
+	digest, err := services.NormalizeNotificationDigest(" Weekly ")
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificationDigestWeekly, digest)
+	_, err = services.NormalizeNotificationDigest("hourly")
+	assert.Error(t, err)
+
+	lang, err := services.NormalizeLanguage(" AR ")
+	require.NoError(t, err)
+	assert.Equal(t, "ar", lang)
+	lang, err = services.NormalizeLanguage("")
+	require.NoError(t, err)
+	assert.Equal(t, "", lang, "empty follows Accept-Language")
+	_, err = services.NormalizeLanguage("fr")
+	assert.Error(t, err)
+}
+
+func TestUserPreferenceFormatTime(t *testing.T) {
+	ts := time.Date(2026, 3, 4, 23, 30, 0, 0, time.UTC)
+
+	var none *models.UserPreference
+	assert.Equal(t, "2026-03-04 23:30 UTC", none.FormatTime(ts))
+
+	pref := &models.UserPreference{Timezone: "Europe/Berlin", DateFormat: models.DateFormatDE}
+	assert.Equal(t, "05.03.2026 00:30 CET", pref.FormatTime(ts))
+
+	pref = &models.UserPreference{Timezone: "Not/AZone", DateFormat: models.DateFormatUS}
+	assert.Equal(t, "03/04/2026 23:30 UTC", pref.FormatTime(ts), "unknown timezones fall back to UTC")
+}
@@ -0,0 +1,87 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cyops/cyops-backend/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadVaultSecrets(t *testing.T) {
+	secrets := map[string]interface{}{
+		"DB_PASSWORD":   "vault-db-password",
+		"SMTP_PASSWORD": "vault-smtp-password",
+		"LLM_API_KEY":   "",
+	}
+	renewals := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "role", body["role_id"])
+			assert.Equal(t, "secret", body["secret_id"])
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"auth": map[string]string{"client_token": "approle-token"},
+			})
+		case "/v1/auth/token/renew-self":
+			assert.Equal(t, "approle-token", r.Header.Get("X-Vault-Token"))
+			renewals++
+			json.NewEncoder(w).Encode(map[string]interface{}{})
+		case "/v1/kv/data/apps/cyops":
+			assert.Equal(t, "approle-token", r.Header.Get("X-Vault-Token"))
+			assert.Equal(t, "team", r.Header.Get("X-Vault-Namespace"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"data": secrets},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		VaultAddress:     server.URL + "/",
+		VaultRoleID:      "role",
+		VaultSecretID:    "secret",
+		VaultNamespace:   "team",
+		VaultKVMount:     "kv",
+		VaultSecretsPath: "/apps/cyops",
+		DBPassword:       "env-db-password",
+		SMTPPassword:     "env-smtp-password",
+		JWTSecret:        "env-jwt-secret",
+		LLMAPIKey:        "env-llm-key",
+	}
+	vault, err := config.LoadVaultSecrets(context.Background(), cfg)
+	require.NoError(t, err)
+	require.NotNil(t, vault)
+	assert.Equal(t, "vault-db-password", cfg.DBPassword)
+	assert.Equal(t, "vault-smtp-password", cfg.SMTPPassword)
+	// Settings missing or empty in Vault keep their environment value
+	assert.Equal(t, "env-jwt-secret", cfg.JWTSecret)
+	assert.Equal(t, "env-llm-key", cfg.LLMAPIKey)
+
+	secrets["SMTP_PASSWORD"] = "rotated-smtp-password"
+	changed, err := vault.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, renewals)
+	assert.Equal(t, map[string]string{"SMTP_PASSWORD": "rotated-smtp-password"}, changed)
+}
+
+func TestLoadVaultSecretsDisabled(t *testing.T) {
+	cfg := &config.Config{DBPassword: "env-db-password"}
+	vault, err := config.LoadVaultSecrets(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Nil(t, vault)
+	assert.Equal(t, "env-db-password", cfg.DBPassword)
+
+	_, err = config.LoadVaultSecrets(context.Background(), &config.Config{VaultAddress: "https://vault.example.com"})
+	assert.ErrorContains(t, err, "VAULT_TOKEN")
+	_, err = config.LoadVaultSecrets(context.Background(), &config.Config{VaultAddress: "vault.example.com", VaultToken: "token"})
+	assert.ErrorContains(t, err, "invalid vault address")
+}
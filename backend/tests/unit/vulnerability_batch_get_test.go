@@ -0,0 +1,32 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeBatchGetIDs(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+
+	ids, err := services.NormalizeBatchGetIDs([]uuid.UUID{b, a, b})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{b, a}, ids, "duplicates are dropped, request order is kept")
+
+	_, err = services.NormalizeBatchGetIDs(nil)
+	assert.EqualError(t, err, "ids is required")
+
+	tooMany := make([]uuid.UUID, services.MaxBatchGetVulnerabilities+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.New()
+	}
+	_, err = services.NormalizeBatchGetIDs(tooMany)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at most 500")
+
+	_, err = services.NormalizeBatchGetIDs(tooMany[:services.MaxBatchGetVulnerabilities])
+	assert.NoError(t, err)
+}
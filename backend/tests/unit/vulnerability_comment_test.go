@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// createTestVulnerability stores a vulnerability created by user
+func createTestVulnerability(t *testing.T, db *gorm.DB, user *models.User, title string) *models.Vulnerability {
+	vulnerability := &models.Vulnerability{
+		Title:         title,
+		Description:   title,
+		Severity:      models.SeverityHigh,
+		DiscoveryDate: time.Now(),
+		CreatedByID:   user.ID,
+	}
+	require.NoError(t, db.Create(vulnerability).Error)
+	return vulnerability
+}
+
+func TestCreateCommentRequiresBody(t *testing.T) {
+	// The body is checked before the vulnerability is looked up
+	service := services.NewVulnerabilityCommentService(nil)
+
+	_, err := service.CreateComment(uuid.New(), uuid.New(), "   \n")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "required")
+	}
+	_, err = service.UpdateComment(uuid.New(), uuid.New(), uuid.New(), "")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "required")
+	}
+}
+
+func TestVulnerabilityCommentsAuthorOnly(t *testing.T) {
+	db, user := setupSchemaDB(t)
+	if db == nil {
+		return
+	}
+	service := services.NewVulnerabilityCommentService(db)
+	vulnerability := createTestVulnerability(t, db, user, "Outdated OpenSSL")
+
+	_, err := service.CreateComment(uuid.New(), user.ID, "On a missing vulnerability")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not found")
+	}
+
+	comment, err := service.CreateComment(vulnerability.ID, user.ID, "  Patch scheduled for Friday  ")
+	require.NoError(t, err)
+	assert.Equal(t, "Patch scheduled for Friday", comment.Body, "the body is trimmed")
+	assert.Nil(t, comment.EditedAt)
+
+	_, err = service.UpdateComment(vulnerability.ID, comment.ID, uuid.New(), "Hijacked")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "only the author")
+	}
+	assert.Error(t, service.DeleteComment(vulnerability.ID, comment.ID, uuid.New()))
+	_, err = service.UpdateComment(uuid.New(), comment.ID, user.ID, "Wrong vulnerability")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "not found")
+	}
+
+	edited, err := service.UpdateComment(vulnerability.ID, comment.ID, user.ID, "Patch moved to Monday")
+	require.NoError(t, err)
+	assert.Equal(t, "Patch moved to Monday", edited.Body)
+	assert.NotNil(t, edited.EditedAt)
+
+	require.NoError(t, service.DeleteComment(vulnerability.ID, comment.ID, user.ID))
+	comments, err := service.ListComments(vulnerability.ID)
+	require.NoError(t, err)
+	assert.Empty(t, comments)
+}
+
+func TestVulnerabilityActivityTimeline(t *testing.T) {
+	db, user := setupSchemaDB(t)
+	if db == nil {
+		return
+	}
+	service := services.NewVulnerabilityCommentService(db)
+	vulnerability := createTestVulnerability(t, db, user, "Weak TLS ciphers")
+
+	comment, err := service.CreateComment(vulnerability.ID, user.ID, "Confirmed on the load balancer")
+	require.NoError(t, err)
+	// A status change made before the comment
+	require.NoError(t, db.Create(&models.VulnerabilityStatusHistory{
+		VulnerabilityID: vulnerability.ID,
+		OldStatus:       models.StatusOpen,
+		NewStatus:       models.StatusInProgress,
+		ChangedByID:     user.ID,
+		ChangedAt:       comment.CreatedAt.Add(-time.Hour),
+	}).Error)
+
+	activity, err := service.GetActivity(vulnerability.ID)
+	require.NoError(t, err)
+	require.Len(t, activity, 2)
+	assert.Equal(t, services.ActivityTypeStatusChange, activity[0].Type, "entries are oldest first")
+	assert.Equal(t, "Changed status from OPEN to IN_PROGRESS", activity[0].Summary)
+	assert.Equal(t, services.ActivityTypeComment, activity[1].Type)
+	assert.Equal(t, user.Name, activity[1].Actor)
+	assert.Equal(t, "Confirmed on the load balancer", activity[1].Details["body"])
+
+	_, err = service.GetActivity(uuid.New())
+	assert.Error(t, err)
+}
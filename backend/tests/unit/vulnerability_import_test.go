@@ -0,0 +1,124 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportResultMergeSumsScanImports(t *testing.T) {
+	merged := &services.ImportResult{Errors: []string{}, Warnings: []string{}}
+
+	merged.Merge(&services.ImportResult{
+		TotalVulnerabilities:    4,
+		ImportedVulnerabilities: 4,
+		CreatedFindings:         3,
+		UpdatedFindings:         2,
+		Batches:                 1,
+		Diff:                    services.ImportDiff{New: 3, StillPresent: 2, NoLongerDetected: 1, AutoClosed: 1},
+	})
+	merged.Merge(&services.ImportResult{
+		TotalVulnerabilities:   2,
+		SkippedVulnerabilities: 1,
+		Batches:                1,
+		FailedBatches:          1,
+		Diff:                   services.ImportDiff{New: 1, NoLongerDetected: 2},
+		Errors:                 []string{"Failed to import vulnerabilities 1-2: timeout"},
+	})
+
+	assert.Equal(t, 6, merged.TotalVulnerabilities)
+	assert.Equal(t, 4, merged.ImportedVulnerabilities)
+	assert.Equal(t, 1, merged.SkippedVulnerabilities)
+	assert.Equal(t, 2, merged.Batches)
+	assert.Equal(t, 1, merged.FailedBatches)
+	assert.Equal(t, services.ImportDiff{New: 4, StillPresent: 2, NoLongerDetected: 3, AutoClosed: 1}, merged.Diff)
+	assert.Len(t, merged.Errors, 1)
+	assert.InDelta(t, 66.67, merged.Summary["success_rate"], 0.01)
+	assert.Equal(t, true, merged.Summary["has_errors"])
+}
+
+func TestParseFindingAutoCloseSettings(t *testing.T) {
+	settings, err := services.ParseFindingAutoCloseSettings(`{"enabled": true, "clean_scans": 3}`)
+	require.NoError(t, err)
+	assert.True(t, settings.Enabled)
+	assert.Equal(t, 3, settings.CleanScans)
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"malformed", `{"enabled": "yes"}`},
+		{"missing threshold", `{"enabled": true}`},
+		{"too many scans", `{"enabled": true, "clean_scans": 101}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := services.ParseFindingAutoCloseSettings(tt.value)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "invalid finding auto-close settings")
+		})
+	}
+}
+
+func TestImportResultMergeSumsConflicts(t *testing.T) {
+	merged := &services.ImportResult{Errors: []string{}, Warnings: []string{}}
+	merged.Merge(&services.ImportResult{Conflicts: services.ImportConflictStats{Assets: 1, Findings: 2}})
+	merged.Merge(&services.ImportResult{Conflicts: services.ImportConflictStats{AssetLinks: 3, Findings: 1}})
+
+	assert.Equal(t, services.ImportConflictStats{Assets: 1, AssetLinks: 3, Findings: 3}, merged.Conflicts)
+}
+
+// parsedScan returns three scanned vulnerabilities over two hosts; the first reports one
+// host and port twice
+func parsedScan(scannedAt time.Time) []services.ParsedVulnerability {
+	web := services.ParsedHost{Hostname: "web-01", IPAddress: "10.0.0.10", Port: "443", Protocol: "tcp", ScanTimestamp: scannedAt}
+	db := services.ParsedHost{Hostname: "db-01", IPAddress: "10.0.0.20", Port: "5432", Protocol: "tcp", ScanTimestamp: scannedAt}
+	return []services.ParsedVulnerability{
+		{Title: "Weak TLS ciphers", Description: "TLS", Severity: models.SeverityMedium, CVEID: "CVE-2024-0001", PluginID: "1001", ScanDate: scannedAt, AffectedHosts: []services.ParsedHost{web, web, db}},
+		{Title: "Outdated OpenSSL", Description: "OpenSSL", Severity: models.SeverityHigh, CVEID: "CVE-2024-0002", PluginID: "1002", ScanDate: scannedAt, AffectedHosts: []services.ParsedHost{web}},
+		{Title: "Database banner disclosure", Description: "Banner", Severity: models.SeverityLow, PluginID: "1003", ScanDate: scannedAt, AffectedHosts: []services.ParsedHost{db}},
+	}
+}
+
+func TestImportFromNessusBatchesAndUpserts(t *testing.T) {
+	db, user := setupSchemaDB(t)
+	if db == nil {
+		return
+	}
+	services.SetImportBatchSize(2)
+	t.Cleanup(func() { services.SetImportBatchSize(500) }) // The default
+	service := services.NewVulnerabilityImportService()
+	source := services.ImportSource{ScanID: "scan-42"}
+	count := func(model interface{}) int64 {
+		var n int64
+		require.NoError(t, db.Model(model).Count(&n).Error)
+		return n
+	}
+
+	result, err := service.ImportFromNessus(parsedScan(time.Now().Add(-time.Hour)), user.ID, false, source)
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, 2, result.Batches)
+	assert.Equal(t, 3, result.ImportedVulnerabilities)
+	assert.Equal(t, 2, result.CreatedAssets, "hosts are created once across batches")
+	assert.Equal(t, 4, result.CreatedFindings)
+	assert.Equal(t, 1, result.Conflicts.Findings, "the repeated host and port is merged")
+	assert.Equal(t, int64(4), count(&models.VulnerabilityFinding{}))
+	assert.Equal(t, int64(4), count(&models.VulnerabilityAffectedSystem{}))
+
+	// The same scan again updates what the first import wrote
+	result, err = service.ImportFromNessus(parsedScan(time.Now()), user.ID, false, source)
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, 0, result.CreatedAssets)
+	assert.Equal(t, 0, result.CreatedFindings)
+	assert.Equal(t, 4, result.Conflicts.AssetLinks, "existing links are kept on conflict")
+	assert.Equal(t, int64(3), count(&models.Vulnerability{}))
+	assert.Equal(t, int64(2), count(&models.AffectedSystem{}))
+	assert.Equal(t, int64(4), count(&models.VulnerabilityFinding{}))
+	assert.Equal(t, int64(4), count(&models.VulnerabilityAffectedSystem{}))
+}
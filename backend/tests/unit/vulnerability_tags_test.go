@@ -0,0 +1,59 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cyops/cyops-backend/internal/models"
+	"github.com/cyops/cyops-backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"pci", "pci", false},
+		{"  Internet-Facing ", "internet-facing", false},
+		{"needs_patch_2024", "needs_patch_2024", false},
+		{"", "", true},
+		{"   ", "", true},
+		{"has space", "", true},
+		{"semi;colon", "", true},
+		{strings.Repeat("a", 51), "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := models.NormalizeTag(tt.input)
+		if tt.wantErr {
+			assert.Error(t, err, tt.input)
+			continue
+		}
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	t.Run("normalizes and de-duplicates in order", func(t *testing.T) {
+		tags, err := services.NormalizeTags([]string{"PCI", "external", " pci ", "External"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pci", "external"}, tags)
+	})
+
+	t.Run("rejects invalid tags", func(t *testing.T) {
+		_, err := services.NormalizeTags([]string{"pci", "not valid"})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "invalid tag")
+		}
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		tags, err := services.NormalizeTags(nil)
+		require.NoError(t, err)
+		assert.Empty(t, tags)
+	})
+}
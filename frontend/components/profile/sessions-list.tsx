@@ -38,8 +38,10 @@ export function SessionsList() {
 
   const revokeAllMutation = useMutation({
     mutationFn: profileApi.revokeAllSessions,
-    onSuccess: () => {
-      toast.success("All other sessions revoked");
+    onSuccess: (result) => {
+      toast.success(
+        `Signed out ${result.revoked} other session${result.revoked === 1 ? "" : "s"}`,
+      );
       queryClient.invalidateQueries({ queryKey: ["sessions"] });
     },
     onError: (error: unknown) => {
@@ -117,10 +119,18 @@ export function SessionsList() {
               >
                 <div className="flex-1">
                   <p className="font-medium text-sm">
-                    {session.user_agent || "Unknown Device"}
+                    {session.browser && session.os
+                      ? `${session.browser} on ${session.os}`
+                      : session.user_agent || "Unknown Device"}
+                    {session.is_current && (
+                      <span className="ml-2 text-xs font-normal text-green-600">
+                        This device
+                      </span>
+                    )}
                   </p>
                   <p className="text-xs text-gray-500">
                     IP: {session.ip_address || "Unknown"}
+                    {session.location && ` · ${session.location}`}
                   </p>
                   <p className="text-xs text-gray-500">
                     Created: {new Date(session.created_at).toLocaleString()}
@@ -132,14 +142,16 @@ export function SessionsList() {
                     </p>
                   )}
                 </div>
-                <Button
-                  variant="destructive"
-                  size="sm"
-                  onClick={() => handleRevokeSession(session.id)}
-                  disabled={revokingId === session.id}
-                >
-                  {revokingId === session.id ? "Revoking..." : "Revoke"}
-                </Button>
+                {!session.is_current && (
+                  <Button
+                    variant="destructive"
+                    size="sm"
+                    onClick={() => handleRevokeSession(session.id)}
+                    disabled={revokingId === session.id}
+                  >
+                    {revokingId === session.id ? "Revoking..." : "Revoke"}
+                  </Button>
+                )}
               </div>
             ))}
           </div>
@@ -376,6 +376,47 @@ export function AnalystReportView({ data }: AnalystReportViewProps) {
           </Card>
         )}
 
+      {/* Vulnerabilities by Tag */}
+      {data.vulnerabilities_by_tag &&
+        data.vulnerabilities_by_tag.length > 0 && (
+          <Card>
+            <CardHeader>
+              <CardTitle>Vulnerabilities by Tag</CardTitle>
+              <CardDescription>
+                Most used vulnerability tags and their open exposure
+              </CardDescription>
+            </CardHeader>
+            <CardContent>
+              <Table>
+                <TableHeader>
+                  <TableRow>
+                    <TableHead>Tag</TableHead>
+                    <TableHead className="text-right">Total</TableHead>
+                    <TableHead className="text-right">Open</TableHead>
+                    <TableHead className="text-right">Critical</TableHead>
+                    <TableHead className="text-right">High</TableHead>
+                  </TableRow>
+                </TableHeader>
+                <TableBody>
+                  {data.vulnerabilities_by_tag.map((tag: any) => (
+                    <TableRow key={tag.tag}>
+                      <TableCell className="font-medium">
+                        <Badge variant="outline">{tag.tag}</Badge>
+                      </TableCell>
+                      <TableCell className="text-right">{tag.total}</TableCell>
+                      <TableCell className="text-right">{tag.open}</TableCell>
+                      <TableCell className="text-right">
+                        {tag.critical}
+                      </TableCell>
+                      <TableCell className="text-right">{tag.high}</TableCell>
+                    </TableRow>
+                  ))}
+                </TableBody>
+              </Table>
+            </CardContent>
+          </Card>
+        )}
+
       {/* Trend Data */}
       <Card>
         <CardHeader>
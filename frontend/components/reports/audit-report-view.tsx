@@ -205,6 +205,69 @@ export function AuditReportView({ data }: AuditReportViewProps) {
         </Card>
       )}
 
+      {/* Policy Violations */}
+      {data.violations && data.violations.length > 0 && (
+        <Card>
+          <CardHeader>
+            <CardTitle className="flex items-center gap-2">
+              <AlertTriangle className="h-5 w-5" />
+              Policy Violations
+            </CardTitle>
+            <CardDescription>
+              Vulnerabilities breaching remediation policies during the period
+            </CardDescription>
+          </CardHeader>
+          <CardContent>
+            <Table>
+              <TableHeader>
+                <TableRow>
+                  <TableHead>Rule</TableHead>
+                  <TableHead>Vulnerability</TableHead>
+                  <TableHead>Severity</TableHead>
+                  <TableHead>Status</TableHead>
+                  <TableHead>Due</TableHead>
+                  <TableHead>Resolved</TableHead>
+                </TableRow>
+              </TableHeader>
+              <TableBody>
+                {data.violations.map((violation: any) => (
+                  <TableRow key={violation.violation_id}>
+                    <TableCell className="text-sm">
+                      {violation.rule_name}
+                    </TableCell>
+                    <TableCell className="text-sm max-w-xs truncate">
+                      {violation.vulnerability_title}
+                    </TableCell>
+                    <TableCell>
+                      <Badge variant="outline">{violation.severity}</Badge>
+                    </TableCell>
+                    <TableCell>
+                      <Badge
+                        variant={
+                          violation.status === "OPEN"
+                            ? "destructive"
+                            : "secondary"
+                        }
+                      >
+                        {violation.status}
+                      </Badge>
+                    </TableCell>
+                    <TableCell className="text-xs">
+                      {format(new Date(violation.due_at), "MMM d, yyyy")}
+                    </TableCell>
+                    <TableCell className="text-xs">
+                      {violation.resolved_at
+                        ? format(new Date(violation.resolved_at), "MMM d, yyyy")
+                        : "-"}
+                    </TableCell>
+                  </TableRow>
+                ))}
+              </TableBody>
+            </Table>
+          </CardContent>
+        </Card>
+      )}
+
       {/* Audit Trail */}
       {data.audit_trail && data.audit_trail.length > 0 && (
         <Card>
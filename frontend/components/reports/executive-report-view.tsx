@@ -176,6 +176,59 @@ export function ExecutiveReportView({ data }: ExecutiveReportViewProps) {
         </Card>
       )}
 
+      {/* Business Services */}
+      {data.business_services && data.business_services.length > 0 && (
+        <Card>
+          <CardHeader>
+            <CardTitle className="flex items-center gap-2">
+              <Shield className="h-5 w-5 text-muted-foreground" />
+              Business Services
+            </CardTitle>
+            <CardDescription>
+              Open findings per application, most critical tiers first
+            </CardDescription>
+          </CardHeader>
+          <CardContent>
+            <div className="space-y-3">
+              {data.business_services.map((service: any) => (
+                <div
+                  key={service.service_id}
+                  className="flex items-center justify-between gap-4"
+                >
+                  <div className="min-w-0">
+                    <div className="flex items-center gap-2">
+                      <span className="text-sm font-medium truncate">
+                        {service.name}
+                      </span>
+                      <Badge variant="outline">
+                        {service.tier.replace("_", " ")}
+                      </Badge>
+                    </div>
+                    <p className="text-xs text-muted-foreground">
+                      {service.open_findings} open findings on{" "}
+                      {service.affected_assets} of {service.asset_count} assets
+                      {service.findings_by_severity?.CRITICAL
+                        ? ` (${service.findings_by_severity.CRITICAL} critical)`
+                        : ""}
+                    </p>
+                  </div>
+                  <div className="flex items-center gap-2 flex-shrink-0">
+                    <span
+                      className={`text-sm font-bold ${getRiskColor(service.risk_score)}`}
+                    >
+                      {service.risk_score.toFixed(1)}
+                    </span>
+                    <Badge className={getPostureColor(service.security_posture)}>
+                      {service.security_posture}
+                    </Badge>
+                  </div>
+                </div>
+              ))}
+            </div>
+          </CardContent>
+        </Card>
+      )}
+
       {/* Recommended Actions */}
       {data.recommended_actions && data.recommended_actions.length > 0 && (
         <Card>
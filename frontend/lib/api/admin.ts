@@ -1,6 +1,8 @@
 import { apiClient } from "./client";
 import type {
   CreateRoleRequest,
+  ImpersonateUserRequest,
+  ImpersonateUserResponse,
   Role,
   RoleListResponse,
   RoleResponse,
@@ -71,6 +73,19 @@ export const adminApi = {
     return response.data;
   },
 
+  // Starts a time-boxed session acting as the user. The returned token is not
+  // stored here: the caller decides how to switch to it and back.
+  impersonateUser: async (
+    userId: string,
+    data: ImpersonateUserRequest,
+  ): Promise<ImpersonateUserResponse> => {
+    const response = await apiClient.post<ImpersonateUserResponse>(
+      `/admin/users/${userId}/impersonate`,
+      data,
+    );
+    return response.data;
+  },
+
   // Database cleanup operations
   getCleanupStats: async (): Promise<{
     stats: { assets: number; vulnerabilities: number };
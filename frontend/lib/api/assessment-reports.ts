@@ -4,6 +4,8 @@ import type {
   AssessmentReportListResponse,
   AssessmentReportStats,
   AssessmentReportVersionsResponse,
+  DownloadURLResponse,
+  GenerateReportRequest,
   UploadReportRequest,
 } from "@/types/assessment-report";
 
@@ -32,6 +34,18 @@ export const assessmentReportApi = {
     return response.data;
   },
 
+  // Generate a PDF/DOCX report from the assessment's linked data
+  generate: async (
+    assessmentId: string,
+    data: GenerateReportRequest = {},
+  ): Promise<{ data: AssessmentReport; message: string }> => {
+    const response = await apiClient.post<{
+      data: AssessmentReport;
+      message: string;
+    }>(`/assessments/${assessmentId}/reports/generate`, data);
+    return response.data;
+  },
+
   // List reports for an assessment
   list: async (
     assessmentId: string,
@@ -86,6 +100,17 @@ export const assessmentReportApi = {
     window.URL.revokeObjectURL(url);
   },
 
+  // Get a presigned download URL (object storage backends only)
+  getDownloadUrl: async (
+    assessmentId: string,
+    reportId: string,
+  ): Promise<DownloadURLResponse> => {
+    const response = await apiClient.get<DownloadURLResponse>(
+      `/assessments/${assessmentId}/reports/${reportId}/download-url`,
+    );
+    return response.data;
+  },
+
   // Get version history for a report
   getVersions: async (
     assessmentId: string,
@@ -0,0 +1,85 @@
+import { apiClient } from "./client";
+import type {
+  AssessmentRetest,
+  AssessmentRetestListResponse,
+  CompleteRetestRequest,
+  RequestRetestRequest,
+  RetestStatus,
+  RetestSummary,
+} from "@/types/assessment-retest";
+
+// Assessment Retest API
+export const assessmentRetestApi = {
+  // List retest rounds for an assessment, optionally for one finding or status
+  list: async (
+    assessmentId: string,
+    params?: { vulnerability_id?: string; status?: RetestStatus },
+  ): Promise<AssessmentRetestListResponse> => {
+    const response = await apiClient.get<AssessmentRetestListResponse>(
+      `/assessments/${assessmentId}/retests`,
+      { params },
+    );
+    return response.data;
+  },
+
+  // Get the current retest status of every linked finding
+  getSummary: async (
+    assessmentId: string,
+  ): Promise<{ data: RetestSummary }> => {
+    const response = await apiClient.get<{ data: RetestSummary }>(
+      `/assessments/${assessmentId}/retests/summary`,
+    );
+    return response.data;
+  },
+
+  // Get a retest round
+  get: async (
+    assessmentId: string,
+    retestId: string,
+  ): Promise<{ data: AssessmentRetest }> => {
+    const response = await apiClient.get<{ data: AssessmentRetest }>(
+      `/assessments/${assessmentId}/retests/${retestId}`,
+    );
+    return response.data;
+  },
+
+  // Mark a finding as ready for retest
+  request: async (
+    assessmentId: string,
+    data: RequestRetestRequest,
+  ): Promise<{ data: AssessmentRetest; message: string }> => {
+    const response = await apiClient.post<{
+      data: AssessmentRetest;
+      message: string;
+    }>(`/assessments/${assessmentId}/retests`, data);
+    return response.data;
+  },
+
+  // Assign or reassign the retester of a pending retest
+  assignRetester: async (
+    assessmentId: string,
+    retestId: string,
+    retesterId: string,
+  ): Promise<{ data: AssessmentRetest; message: string }> => {
+    const response = await apiClient.put<{
+      data: AssessmentRetest;
+      message: string;
+    }>(`/assessments/${assessmentId}/retests/${retestId}/retester`, {
+      retester_id: retesterId,
+    });
+    return response.data;
+  },
+
+  // Record the retest outcome and evidence
+  complete: async (
+    assessmentId: string,
+    retestId: string,
+    data: CompleteRetestRequest,
+  ): Promise<{ data: AssessmentRetest; message: string }> => {
+    const response = await apiClient.post<{
+      data: AssessmentRetest;
+      message: string;
+    }>(`/assessments/${assessmentId}/retests/${retestId}/complete`, data);
+    return response.data;
+  },
+};
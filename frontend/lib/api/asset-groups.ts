@@ -0,0 +1,90 @@
+import { apiClient } from "./client";
+import type {
+  AssetGroup,
+  AssetGroupMembersResponse,
+  AssetGroupRequest,
+} from "@/types/asset";
+
+// Asset group API functions
+export const assetGroupApi = {
+  // List asset groups, optionally filtered by name
+  list: async (search?: string): Promise<AssetGroup[]> => {
+    const response = await apiClient.get<{ data: AssetGroup[] }>(
+      "/asset-groups",
+      { params: { search } },
+    );
+    return response.data.data;
+  },
+
+  // Get asset group by ID
+  get: async (id: string): Promise<AssetGroup> => {
+    const response = await apiClient.get<{ data: AssetGroup }>(
+      `/asset-groups/${id}`,
+    );
+    return response.data.data;
+  },
+
+  // Create asset group
+  create: async (data: AssetGroupRequest): Promise<AssetGroup> => {
+    const response = await apiClient.post<{ data: AssetGroup }>(
+      "/asset-groups",
+      data,
+    );
+    return response.data.data;
+  },
+
+  // Update asset group name, description or rules
+  update: async (id: string, data: AssetGroupRequest): Promise<AssetGroup> => {
+    const response = await apiClient.put<{ data: AssetGroup }>(
+      `/asset-groups/${id}`,
+      data,
+    );
+    return response.data.data;
+  },
+
+  // Delete asset group
+  delete: async (id: string): Promise<{ message: string }> => {
+    const response = await apiClient.delete<{ message: string }>(
+      `/asset-groups/${id}`,
+    );
+    return response.data;
+  },
+
+  // List group members (static and rule-matched)
+  listMembers: async (
+    id: string,
+    page = 1,
+    limit = 50,
+  ): Promise<AssetGroupMembersResponse> => {
+    const response = await apiClient.get<AssetGroupMembersResponse>(
+      `/asset-groups/${id}/members`,
+      { params: { page, limit } },
+    );
+    return response.data;
+  },
+
+  // Add static members
+  addMembers: async (id: string, assetIds: string[]): Promise<AssetGroup> => {
+    const response = await apiClient.post<{ data: AssetGroup }>(
+      `/asset-groups/${id}/members`,
+      { asset_ids: assetIds },
+    );
+    return response.data.data;
+  },
+
+  // Remove a static member
+  removeMember: async (id: string, assetId: string): Promise<AssetGroup> => {
+    const response = await apiClient.delete<{ data: AssetGroup }>(
+      `/asset-groups/${id}/members/${assetId}`,
+    );
+    return response.data.data;
+  },
+
+  // Re-evaluate the group's rules now
+  recompute: async (id: string): Promise<AssetGroup> => {
+    const response = await apiClient.post<{ data: AssetGroup }>(
+      `/asset-groups/${id}/recompute`,
+    );
+    return response.data.data;
+  },
+};
@@ -2,14 +2,21 @@ import { apiClient } from "./client";
 import type {
   AddTagsRequest,
   Asset,
+  AssetCriticalityScore,
   AssetDetailResponse,
+  AssetHistoryResponse,
   AssetListParams,
   AssetListResponse,
+  AssetPackagesResponse,
+  AssetSBOMComponentsResponse,
   AssetStats,
   CheckDuplicateRequest,
   CreateAssetRequest,
+  CriticalityProfileRequest,
+  CriticalityScoringProfile,
   CreateAssetResponse,
   DuplicateCheckResponse,
+  SBOMImportResult,
   UpdateAssetRequest,
   UpdateAssetStatusRequest,
 } from "@/types/asset";
@@ -127,4 +134,100 @@ export const assetApi = {
     );
     return response.data;
   },
+
+  // Get the packages reported by an asset's agent
+  getPackages: async (
+    id: string,
+    params?: { search?: string; page?: number; limit?: number },
+  ): Promise<AssetPackagesResponse> => {
+    const response = await apiClient.get<AssetPackagesResponse>(
+      `/assets/${id}/packages`,
+      { params },
+    );
+    return response.data;
+  },
+
+  // Upload a CycloneDX or SPDX JSON SBOM, replacing the asset's components
+  uploadSBOM: async (id: string, file: File): Promise<SBOMImportResult> => {
+    const formData = new FormData();
+    formData.append("file", file);
+    const response = await apiClient.post<{ data: SBOMImportResult }>(
+      `/assets/${id}/sbom`,
+      formData,
+      { headers: { "Content-Type": "multipart/form-data" } },
+    );
+    return response.data.data;
+  },
+
+  // Get the components of the asset's SBOM
+  getSBOMComponents: async (
+    id: string,
+    params?: {
+      search?: string;
+      vulnerable?: boolean;
+      page?: number;
+      limit?: number;
+    },
+  ): Promise<AssetSBOMComponentsResponse> => {
+    const response = await apiClient.get<AssetSBOMComponentsResponse>(
+      `/assets/${id}/sbom`,
+      { params },
+    );
+    return response.data;
+  },
+
+  // Download a CycloneDX VEX document of the asset's finding statuses
+  downloadVEX: async (id: string): Promise<void> => {
+    const response = await apiClient.get(`/assets/${id}/vex`, {
+      responseType: "blob",
+    });
+
+    const url = window.URL.createObjectURL(new Blob([response.data]));
+    const link = document.createElement("a");
+    link.href = url;
+    link.setAttribute("download", `asset-${id}.vex.json`);
+    document.body.appendChild(link);
+    link.click();
+    link.remove();
+    window.URL.revokeObjectURL(url);
+  },
+
+  // Get the change history of an asset, newest first
+  getHistory: async (
+    id: string,
+    params?: { page?: number; limit?: number },
+  ): Promise<AssetHistoryResponse> => {
+    const response = await apiClient.get<AssetHistoryResponse>(
+      `/assets/${id}/history`,
+      { params },
+    );
+    return response.data;
+  },
+
+  // Explain how an asset's criticality score is derived
+  getScore: async (id: string): Promise<AssetCriticalityScore> => {
+    const response = await apiClient.get<{ data: AssetCriticalityScore }>(
+      `/assets/${id}/score`,
+    );
+    return response.data.data;
+  },
+
+  // Get the organization's criticality scoring profile
+  getScoringProfile: async (): Promise<CriticalityScoringProfile> => {
+    const response = await apiClient.get<{ data: CriticalityScoringProfile }>(
+      "/asset-scoring/profile",
+    );
+    return response.data.data;
+  },
+
+  // Update the scoring profile; assets are rescored in the background
+  updateScoringProfile: async (
+    data: CriticalityProfileRequest,
+  ): Promise<CriticalityScoringProfile> => {
+    const response = await apiClient.put<{ data: CriticalityScoringProfile }>(
+      "/asset-scoring/profile",
+      data,
+    );
+    return response.data.data;
+  },
 };
@@ -1,4 +1,9 @@
-import { apiClient, setAuthToken, removeAuthToken } from "./client";
+import {
+  apiClient,
+  removeAuthToken,
+  setAuthToken,
+  setRefreshToken,
+} from "./client";
 import type {
   ChangePasswordRequest,
   ChangePasswordResponse,
@@ -7,6 +12,7 @@ import type {
   Enable2FAResponse,
   ForgotPasswordRequest,
   ForgotPasswordResponse,
+  ImpersonationInfo,
   LoginRequest,
   LoginResponse,
   RegisterRequest,
@@ -49,6 +55,9 @@ export const authApi = {
     if (response.data.token) {
       setAuthToken(response.data.token);
     }
+    if (response.data.refresh_token) {
+      setRefreshToken(response.data.refresh_token);
+    }
     return response.data;
   },
 
@@ -81,8 +90,14 @@ export const authApi = {
 
 // Profile API functions
 export const profileApi = {
-  getProfile: async (): Promise<{ user: User }> => {
-    const response = await apiClient.get<{ user: User }>("/profile");
+  getProfile: async (): Promise<{
+    user: User;
+    impersonation?: ImpersonationInfo;
+  }> => {
+    const response = await apiClient.get<{
+      user: User;
+      impersonation?: ImpersonationInfo;
+    }>("/profile");
     return response.data;
   },
 
@@ -120,8 +135,14 @@ export const profileApi = {
     return response.data;
   },
 
-  revokeAllSessions: async (): Promise<{ message: string }> => {
-    const response = await apiClient.delete<{ message: string }>(
+  revokeAllSessions: async (): Promise<{
+    message: string;
+    revoked: number;
+  }> => {
+    const response = await apiClient.delete<{
+      message: string;
+      revoked: number;
+    }>(
       "/profile/sessions",
     );
     return response.data;
@@ -0,0 +1,113 @@
+import { apiClient } from "./client";
+import type {
+  BusinessService,
+  BusinessServiceAssetsResponse,
+  BusinessServiceRequest,
+  BusinessServiceRisk,
+  BusinessServiceTier,
+} from "@/types/asset";
+
+// Business service API functions
+export const businessServiceApi = {
+  // List business services, optionally filtered by name and tier
+  list: async (params?: {
+    search?: string;
+    tier?: BusinessServiceTier;
+  }): Promise<BusinessService[]> => {
+    const response = await apiClient.get<{ data: BusinessService[] }>(
+      "/business-services",
+      { params },
+    );
+    return response.data.data;
+  },
+
+  // Get business service by ID
+  get: async (id: string): Promise<BusinessService> => {
+    const response = await apiClient.get<{ data: BusinessService }>(
+      `/business-services/${id}`,
+    );
+    return response.data.data;
+  },
+
+  // Create business service
+  create: async (data: BusinessServiceRequest): Promise<BusinessService> => {
+    const response = await apiClient.post<{ data: BusinessService }>(
+      "/business-services",
+      data,
+    );
+    return response.data.data;
+  },
+
+  // Update business service name, description, tier or owners
+  update: async (
+    id: string,
+    data: BusinessServiceRequest,
+  ): Promise<BusinessService> => {
+    const response = await apiClient.put<{ data: BusinessService }>(
+      `/business-services/${id}`,
+      data,
+    );
+    return response.data.data;
+  },
+
+  // Delete business service
+  delete: async (id: string): Promise<{ message: string }> => {
+    const response = await apiClient.delete<{ message: string }>(
+      `/business-services/${id}`,
+    );
+    return response.data;
+  },
+
+  // Roll up the vulnerability posture of one business service
+  getRisk: async (id: string): Promise<BusinessServiceRisk> => {
+    const response = await apiClient.get<{ data: BusinessServiceRisk }>(
+      `/business-services/${id}/risk`,
+    );
+    return response.data.data;
+  },
+
+  // Roll up every business service, riskiest first (limit 0 returns all)
+  getRiskRollup: async (limit = 0): Promise<BusinessServiceRisk[]> => {
+    const response = await apiClient.get<{ data: BusinessServiceRisk[] }>(
+      "/business-services/risk",
+      { params: { limit } },
+    );
+    return response.data.data;
+  },
+
+  // List the assets that run a business service
+  listAssets: async (
+    id: string,
+    page = 1,
+    limit = 50,
+  ): Promise<BusinessServiceAssetsResponse> => {
+    const response = await apiClient.get<BusinessServiceAssetsResponse>(
+      `/business-services/${id}/assets`,
+      { params: { page, limit } },
+    );
+    return response.data;
+  },
+
+  // Link assets
+  addAssets: async (
+    id: string,
+    assetIds: string[],
+  ): Promise<BusinessService> => {
+    const response = await apiClient.post<{ data: BusinessService }>(
+      `/business-services/${id}/assets`,
+      { asset_ids: assetIds },
+    );
+    return response.data.data;
+  },
+
+  // Unlink an asset
+  removeAsset: async (
+    id: string,
+    assetId: string,
+  ): Promise<BusinessService> => {
+    const response = await apiClient.delete<{ data: BusinessService }>(
+      `/business-services/${id}/assets/${assetId}`,
+    );
+    return response.data.data;
+  },
+};
@@ -1,4 +1,8 @@
-import axios, { type AxiosError, type AxiosInstance } from "axios";
+import axios, {
+  type AxiosError,
+  type AxiosInstance,
+  type InternalAxiosRequestConfig,
+} from "axios";
 import { AppError, ErrorType } from "@/lib/error-handler";
 
 // API client configuration
@@ -35,11 +39,61 @@ export const getAuthToken = (): string | null => {
 export const removeAuthToken = () => {
   if (typeof window !== "undefined") {
     localStorage.removeItem(TOKEN_KEY);
+    localStorage.removeItem(REFRESH_TOKEN_KEY);
     // Also remove cookie
     document.cookie = "auth_token=; path=/; max-age=0";
   }
 };
 
+// Refresh token management. Access tokens are short-lived; the refresh token
+// obtains the next one and is itself replaced on every use.
+const REFRESH_TOKEN_KEY = "refresh_token";
+
+export const setRefreshToken = (token: string) => {
+  if (typeof window !== "undefined") {
+    localStorage.setItem(REFRESH_TOKEN_KEY, token);
+  }
+};
+
+export const getRefreshToken = (): string | null => {
+  if (typeof window !== "undefined") {
+    return localStorage.getItem(REFRESH_TOKEN_KEY);
+  }
+  return null;
+};
+
+// A refresh token can only be redeemed once, so concurrent requests that hit
+// an expired access token share a single refresh call.
+let refreshPromise: Promise<string | null> | null = null;
+
+const refreshAccessToken = (): Promise<string | null> => {
+  if (!refreshPromise) {
+    refreshPromise = (async () => {
+      const refreshToken = getRefreshToken();
+      if (!refreshToken) return null;
+      try {
+        // Plain axios: the refresh call must not go through the interceptors
+        const response = await axios.post<{
+          token: string;
+          refresh_token: string;
+        }>(
+          `${API_BASE_URL}/api/v1/auth/refresh`,
+          { refresh_token: refreshToken },
+          { withCredentials: true },
+        );
+        setAuthToken(response.data.token);
+        setRefreshToken(response.data.refresh_token);
+        return response.data.token;
+      } catch {
+        return null;
+      } finally {
+        refreshPromise = null;
+      }
+    })();
+  }
+  return refreshPromise;
+};
+
 // Request interceptor
 apiClient.interceptors.request.use(
   (config) => {
@@ -60,17 +114,41 @@ apiClient.interceptors.response.use(
   (response) => {
     return response;
   },
-  (error: AxiosError) => {
+  async (error: AxiosError) => {
     // Handle common error scenarios
     if (error.response) {
       // Server responded with error
       const status = error.response.status;
-      const data = error.response.data as {
+      // The standard response envelope nests errors under "error"
+      const body = error.response.data as { error?: unknown } | undefined;
+      const data = (
+        body && typeof body.error === "object" ? body.error : body
+      ) as {
         message?: string;
+        code?: string;
         field?: string;
+        fields?: { field: string; message: string }[];
         details?: Record<string, unknown>;
       };
 
+      // Expired access token: refresh once and replay the request
+      const original = error.config as
+        | (InternalAxiosRequestConfig & { _retried?: boolean })
+        | undefined;
+      if (
+        status === 401 &&
+        data?.code === "token_expired" &&
+        original &&
+        !original._retried
+      ) {
+        original._retried = true;
+        const token = await refreshAccessToken();
+        if (token) {
+          original.headers.Authorization = `Bearer ${token}`;
+          return apiClient(original);
+        }
+      }
+
       // Create structured error
       let errorType = ErrorType.UNKNOWN;
       let message =
@@ -126,7 +204,7 @@ apiClient.interceptors.response.use(
       // Throw structured AppError
       throw new AppError(message, errorType, {
         statusCode: status,
-        field: data?.field,
+        field: data?.field ?? data?.fields?.[0]?.field,
         details: data?.details,
         retryable:
           status === 429 || status === 503 || status === 504 || status >= 500,
@@ -0,0 +1,62 @@
+import { apiClient } from "./client";
+import type {
+  Dashboard,
+  DashboardData,
+  DashboardRequest,
+} from "@/types/dashboard";
+
+// Configurable dashboard API
+export const dashboardApi = {
+  // List own and shared dashboards
+  list: async (): Promise<{ data: Dashboard[] }> => {
+    const response = await apiClient.get<{ data: Dashboard[] }>(`/dashboards`);
+    return response.data;
+  },
+
+  // Get a dashboard definition
+  get: async (id: string): Promise<{ data: Dashboard }> => {
+    const response = await apiClient.get<{ data: Dashboard }>(
+      `/dashboards/${id}`,
+    );
+    return response.data;
+  },
+
+  // Create a dashboard
+  create: async (
+    data: DashboardRequest,
+  ): Promise<{ data: Dashboard; message: string }> => {
+    const response = await apiClient.post<{
+      data: Dashboard;
+      message: string;
+    }>(`/dashboards`, data);
+    return response.data;
+  },
+
+  // Update a dashboard (owner only; widgets are replaced as a whole)
+  update: async (
+    id: string,
+    data: DashboardRequest,
+  ): Promise<{ data: Dashboard; message: string }> => {
+    const response = await apiClient.put<{
+      data: Dashboard;
+      message: string;
+    }>(`/dashboards/${id}`, data);
+    return response.data;
+  },
+
+  // Delete a dashboard (owner only)
+  delete: async (id: string): Promise<{ message: string }> => {
+    const response = await apiClient.delete<{ message: string }>(
+      `/dashboards/${id}`,
+    );
+    return response.data;
+  },
+
+  // Get the data of all widgets in one batched request
+  getData: async (id: string): Promise<{ data: DashboardData }> => {
+    const response = await apiClient.get<{ data: DashboardData }>(
+      `/dashboards/${id}/data`,
+    );
+    return response.data;
+  },
+};
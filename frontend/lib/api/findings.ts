@@ -8,6 +8,11 @@ import type {
   MarkFindingVerifiedRequest,
   VulnerabilityFinding,
 } from "@/types/vulnerability";
+import type {
+  AttachmentCustodyEvent,
+  AttachmentIntegrityResult,
+  AttachmentPolicy,
+} from "@/types/finding-attachment";
 
 // Vulnerability Finding API
 export const vulnerabilityFindingApi = {
@@ -114,6 +119,34 @@ export const vulnerabilityFindingApi = {
     return response.data;
   },
 
+  // Get allowed MIME types and maximum sizes per attachment category
+  getAttachmentPolicy: async (): Promise<{ data: AttachmentPolicy }> => {
+    const response = await apiClient.get<{ data: AttachmentPolicy }>(
+      "/vulnerabilities/attachments/policy",
+    );
+    return response.data;
+  },
+
+  // Verify an attachment against the hash recorded at upload
+  verifyAttachment: async (
+    attachmentId: string,
+  ): Promise<{ data: AttachmentIntegrityResult }> => {
+    const response = await apiClient.post<{
+      data: AttachmentIntegrityResult;
+    }>(`/vulnerabilities/attachments/${attachmentId}/verify`);
+    return response.data;
+  },
+
+  // Get an attachment's chain of custody
+  getAttachmentCustody: async (
+    attachmentId: string,
+  ): Promise<{ data: AttachmentCustodyEvent[] }> => {
+    const response = await apiClient.get<{ data: AttachmentCustodyEvent[] }>(
+      `/vulnerabilities/attachments/${attachmentId}/custody`,
+    );
+    return response.data;
+  },
+
   // Get findings statistics
   getStatistics: async (
     params?: FindingListParams,
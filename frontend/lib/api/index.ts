@@ -16,6 +16,9 @@ export { apiKeyApi } from "./api-keys";
 export { settingsApi } from "./settings";
 export { vulnerabilityApi } from "./vulnerabilities";
 export { assetApi } from "./assets";
+export { assetGroupApi } from "./asset-groups";
+export { businessServiceApi } from "./business-services";
+export { networkRangeApi } from "./network-ranges";
 export { vulnerabilityFindingApi } from "./findings";
 export { affectedSystemApi } from "./affected-systems";
 export {
@@ -33,7 +36,10 @@ export {
 } from "./integrations";
 export { assessmentApi } from "./assessments";
 export { assessmentReportApi } from "./assessment-reports";
+export { assessmentRetestApi } from "./assessment-retests";
 export { reportApi } from "./reports";
+export { policyApi } from "./policies";
+export { dashboardApi } from "./dashboards";
 
 // Re-export default client for backwards compatibility
 export { default } from "./client";
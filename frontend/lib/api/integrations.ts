@@ -1,5 +1,13 @@
 import { apiClient } from "./client";
 
+// TLS settings of an integration. Certificates are PEM encoded; the client key is write-only.
+export interface IntegrationTLSSettings {
+  verify_tls?: boolean; // Defaults to true
+  ca_cert_pem?: string;
+  client_cert_pem?: string;
+  client_key_pem?: string;
+}
+
 // Integration Configuration API functions
 export const integrationConfigApi = {
   // Create integration configuration
@@ -12,7 +20,7 @@ export const integrationConfigApi = {
     config?: Record<string, any>;
     auto_sync?: boolean;
     sync_interval_mins?: number;
-  }): Promise<{ message: string; data: any }> => {
+  } & IntegrationTLSSettings): Promise<{ message: string; data: any }> => {
     const response = await apiClient.post(
       "/vulnerabilities/integrations/configs",
       data,
@@ -50,7 +58,7 @@ export const integrationConfigApi = {
       active?: boolean;
       auto_sync?: boolean;
       sync_interval_mins?: number;
-    },
+    } & IntegrationTLSSettings,
   ): Promise<{ message: string }> => {
     const response = await apiClient.put(
       `/vulnerabilities/integrations/configs/${id}`,
@@ -85,12 +93,32 @@ export interface NessusScan {
   creation_date: number;
   last_modification_date: number;
   folder_id: number;
+  type?: string; // e.g. remote, local or agent
   read: boolean;
   shared: boolean;
   user_permissions: number;
   owner: string;
 }
 
+export interface NessusFolder {
+  id: number;
+  name: string;
+  type: "main" | "trash" | "custom";
+  default_tag: number;
+  custom: number;
+  unread_count: number;
+}
+
+// A run of a scan; agent scans are imported from their latest completed run
+export interface NessusScanHistory {
+  history_id: number;
+  uuid: string;
+  status: string;
+  type: string;
+  creation_date: number;
+  last_modification_date: number;
+}
+
 export interface NessusScanDetail {
   info: {
     uuid: string;
@@ -101,6 +129,9 @@ export interface NessusScanDetail {
     targets: string;
     hostcount: number;
     vulnerabilitycount: number;
+    scan_type?: string;
+    folder_id?: number;
+    agent_targets?: Array<{ id: number; name: string }>;
   };
   hosts: Array<{
     host_id: number;
@@ -118,6 +149,7 @@ export interface NessusScanDetail {
     count: number;
     severity_index: number;
   }>;
+  history?: NessusScanHistory[];
 }
 
 export interface NessusScanPreview {
@@ -148,6 +180,30 @@ export interface ImportMultipleScansRequest extends ImportScanRequest {
 
 export interface ImportAllScansRequest extends ImportScanRequest {
   status_filter?: "completed" | "running" | "all";
+  folder_id?: number; // Only scans in this folder; the trash is skipped otherwise
+}
+
+// Mode of a Nessus integration, set as `config.mode`
+export type NessusMode = "nessus" | "tenable_io";
+
+export interface ImportTenableVulnerabilitiesRequest {
+  since?: string; // RFC 3339; only vulnerabilities seen since then
+  update_existing?: boolean;
+}
+
+export interface VulnerabilityImportResult {
+  total_vulnerabilities: number;
+  imported_vulnerabilities: number;
+  skipped_vulnerabilities: number;
+  total_assets: number;
+  created_assets: number;
+  existing_assets: number;
+  total_findings: number;
+  created_findings: number;
+  updated_findings: number;
+  suppressed_findings: number;
+  errors?: string[];
+  warnings?: string[];
 }
 
 export interface ImportResult {
@@ -187,20 +243,48 @@ export const nessusIntegrationApi = {
   // List all available scans from Nessus
   listScans: async (
     configId: string,
+    params?: { folder_id?: number; type?: string },
   ): Promise<{ data: NessusScan[]; count: number }> => {
     const response = await apiClient.get<{ data: NessusScan[]; count: number }>(
       `/vulnerabilities/integrations/nessus/${configId}/scans`,
+      { params },
     );
     return response.data;
   },
 
-  // Get scan details
+  // List scan folders
+  listFolders: async (
+    configId: string,
+  ): Promise<{ data: NessusFolder[]; count: number }> => {
+    const response = await apiClient.get<{
+      data: NessusFolder[];
+      count: number;
+    }>(`/vulnerabilities/integrations/nessus/${configId}/folders`);
+    return response.data;
+  },
+
+  // List the scans in a folder
+  listFolderScans: async (
+    configId: string,
+    folderId: number,
+    params?: { type?: string },
+  ): Promise<{ data: NessusScan[]; count: number }> => {
+    const response = await apiClient.get<{ data: NessusScan[]; count: number }>(
+      `/vulnerabilities/integrations/nessus/${configId}/folders/${folderId}/scans`,
+      { params },
+    );
+    return response.data;
+  },
+
+  // Get scan details, or those of one run of the scan
   getScanDetails: async (
     configId: string,
     scanId: number,
+    historyId?: number,
   ): Promise<{ data: NessusScanDetail }> => {
     const response = await apiClient.get<{ data: NessusScanDetail }>(
       `/vulnerabilities/integrations/nessus/${configId}/scans/${scanId}`,
+      { params: historyId ? { history_id: historyId } : undefined },
     );
     return response.data;
   },
@@ -261,4 +345,17 @@ export const nessusIntegrationApi = {
     );
     return response.data;
   },
+
+  // Import open vulnerabilities through the Tenable.io vulnerability export API
+  // (Tenable.io integrations only)
+  importTenableVulnerabilities: async (
+    configId: string,
+    data: ImportTenableVulnerabilitiesRequest = {},
+  ): Promise<{ message: string; data: VulnerabilityImportResult }> => {
+    const response = await apiClient.post<{
+      message: string;
+      data: VulnerabilityImportResult;
+    }>(`/vulnerabilities/integrations/nessus/${configId}/vulns/import`, data);
+    return response.data;
+  },
 };
@@ -0,0 +1,60 @@
+import { apiClient } from "./client";
+import type { NetworkRange, NetworkRangeRequest } from "@/types/asset";
+
+// Network range API functions
+export const networkRangeApi = {
+  // List network ranges, optionally filtered by name, CIDR or location
+  list: async (search?: string): Promise<NetworkRange[]> => {
+    const response = await apiClient.get<{ data: NetworkRange[] }>(
+      "/network-ranges",
+      { params: { search } },
+    );
+    return response.data.data;
+  },
+
+  // Resolve an IP address to its most specific range
+  lookup: async (ip: string): Promise<NetworkRange | null> => {
+    const response = await apiClient.get<{ data: NetworkRange | null }>(
+      "/network-ranges/lookup",
+      { params: { ip } },
+    );
+    return response.data.data;
+  },
+
+  // Get network range by ID
+  get: async (id: string): Promise<NetworkRange> => {
+    const response = await apiClient.get<{ data: NetworkRange }>(
+      `/network-ranges/${id}`,
+    );
+    return response.data.data;
+  },
+
+  // Create network range
+  create: async (data: NetworkRangeRequest): Promise<NetworkRange> => {
+    const response = await apiClient.post<{ data: NetworkRange }>(
+      "/network-ranges",
+      data,
+    );
+    return response.data.data;
+  },
+
+  // Update network range
+  update: async (
+    id: string,
+    data: NetworkRangeRequest,
+  ): Promise<NetworkRange> => {
+    const response = await apiClient.put<{ data: NetworkRange }>(
+      `/network-ranges/${id}`,
+      data,
+    );
+    return response.data.data;
+  },
+
+  // Delete network range
+  delete: async (id: string): Promise<{ message: string }> => {
+    const response = await apiClient.delete<{ message: string }>(
+      `/network-ranges/${id}`,
+    );
+    return response.data;
+  },
+};
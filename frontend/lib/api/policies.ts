@@ -0,0 +1,91 @@
+import { apiClient } from "./client";
+import type {
+  PolicyEvaluationResult,
+  PolicyRule,
+  PolicyRuleRequest,
+  PolicyViolation,
+  PolicyViolationListParams,
+  PolicyViolationListResponse,
+} from "@/types/policy";
+
+// Policy rule and violation API
+export const policyApi = {
+  // List policy rules
+  listRules: async (enabledOnly = false): Promise<{ data: PolicyRule[] }> => {
+    const response = await apiClient.get<{ data: PolicyRule[] }>(
+      `/policy-rules`,
+      { params: { enabled: enabledOnly } },
+    );
+    return response.data;
+  },
+
+  // Get a policy rule
+  getRule: async (id: string): Promise<{ data: PolicyRule }> => {
+    const response = await apiClient.get<{ data: PolicyRule }>(
+      `/policy-rules/${id}`,
+    );
+    return response.data;
+  },
+
+  // Create a policy rule
+  createRule: async (
+    data: PolicyRuleRequest,
+  ): Promise<{ data: PolicyRule; message: string }> => {
+    const response = await apiClient.post<{
+      data: PolicyRule;
+      message: string;
+    }>(`/policy-rules`, data);
+    return response.data;
+  },
+
+  // Update a policy rule
+  updateRule: async (
+    id: string,
+    data: PolicyRuleRequest,
+  ): Promise<{ data: PolicyRule; message: string }> => {
+    const response = await apiClient.put<{
+      data: PolicyRule;
+      message: string;
+    }>(`/policy-rules/${id}`, data);
+    return response.data;
+  },
+
+  // Delete a policy rule (resolves its open violations)
+  deleteRule: async (id: string): Promise<{ message: string }> => {
+    const response = await apiClient.delete<{ message: string }>(
+      `/policy-rules/${id}`,
+    );
+    return response.data;
+  },
+
+  // Evaluate all policy rules now
+  evaluate: async (): Promise<{
+    data: PolicyEvaluationResult;
+    message: string;
+  }> => {
+    const response = await apiClient.post<{
+      data: PolicyEvaluationResult;
+      message: string;
+    }>(`/policy-rules/evaluate`);
+    return response.data;
+  },
+
+  // List policy violations
+  listViolations: async (
+    params?: PolicyViolationListParams,
+  ): Promise<PolicyViolationListResponse> => {
+    const response = await apiClient.get<PolicyViolationListResponse>(
+      `/policy-violations`,
+      { params },
+    );
+    return response.data;
+  },
+
+  // Get a policy violation
+  getViolation: async (id: string): Promise<{ data: PolicyViolation }> => {
+    const response = await apiClient.get<{ data: PolicyViolation }>(
+      `/policy-violations/${id}`,
+    );
+    return response.data;
+  },
+};
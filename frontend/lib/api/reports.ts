@@ -1,4 +1,11 @@
 import { apiClient } from "./client";
+import type {
+  AgingAnalytics,
+  BurnDownAnalytics,
+  MTTRAnalytics,
+  RemediationAnalytics,
+} from "@/types/remediation-analytics";
+import type { ReportSummary } from "@/types/report-summary";
 
 import axios from "axios";
 
@@ -28,6 +35,37 @@ export const reportApi = {
     return response.data;
   },
 
+  // Write and store an executive summary and key-risk narrative of the executive report
+  summarizeExecutiveReport: async (
+    startDate: string,
+    endDate: string,
+  ): Promise<ReportSummary> => {
+    const response = await apiClient.post<{ data: ReportSummary }>(
+      `/reports/executive/summarize`,
+      null,
+      { params: { start_date: startDate, end_date: endDate } },
+    );
+    return response.data.data;
+  },
+
+  // Write and store an executive summary and key-risk narrative of an assessment
+  summarizeAssessment: async (assessmentId: string): Promise<ReportSummary> => {
+    const response = await apiClient.post<{ data: ReportSummary }>(
+      `/assessments/${assessmentId}/summarize`,
+    );
+    return response.data.data;
+  },
+
+  // List the stored summaries of an assessment, newest first
+  getAssessmentSummaries: async (
+    assessmentId: string,
+  ): Promise<ReportSummary[]> => {
+    const response = await apiClient.get<{ data: ReportSummary[] }>(
+      `/assessments/${assessmentId}/summaries`,
+    );
+    return response.data.data;
+  },
+
   // Get audit report
   getAuditReport: async (startDate: string, endDate: string): Promise<any> => {
     const response = await apiClient.get(`/reports/audit`, {
@@ -36,6 +74,50 @@ export const reportApi = {
     return response.data;
   },
 
+  // Get remediation analytics (MTTR, aging and burn-down) for dashboards
+  getRemediationAnalytics: async (
+    startDate: string,
+    endDate: string,
+  ): Promise<RemediationAnalytics> => {
+    const response = await apiClient.get<RemediationAnalytics>(
+      `/reports/analytics`,
+      { params: { start_date: startDate, end_date: endDate } },
+    );
+    return response.data;
+  },
+
+  // Get mean time to remediate by severity, team and month
+  getMTTR: async (
+    startDate: string,
+    endDate: string,
+  ): Promise<MTTRAnalytics> => {
+    const response = await apiClient.get<MTTRAnalytics>(
+      `/reports/analytics/mttr`,
+      { params: { start_date: startDate, end_date: endDate } },
+    );
+    return response.data;
+  },
+
+  // Get aging buckets of the currently open vulnerabilities
+  getAging: async (): Promise<AgingAnalytics> => {
+    const response = await apiClient.get<AgingAnalytics>(
+      `/reports/analytics/aging`,
+    );
+    return response.data;
+  },
+
+  // Get burn-down of the open vulnerability backlog
+  getBurnDown: async (
+    startDate: string,
+    endDate: string,
+  ): Promise<BurnDownAnalytics> => {
+    const response = await apiClient.get<BurnDownAnalytics>(
+      `/reports/analytics/burndown`,
+      { params: { start_date: startDate, end_date: endDate } },
+    );
+    return response.data;
+  },
+
   // Get export URL for downloading CSV
   getExportURL: (
     reportType: string,
@@ -14,6 +14,22 @@ export interface MCPStatus {
   key: string;
 }
 
+// Delivery status of the SIEM forwarder configured by the siem_forwarder setting
+export interface SIEMStatus {
+  enabled: boolean;
+  key: string;
+  destination?: string;
+  protocol?: "tcp" | "tls";
+  format?: "cef" | "rfc5424";
+  stats?: {
+    connected: boolean;
+    queued: number;
+    sent: number;
+    dropped: number;
+    last_error?: string;
+  };
+}
+
 export interface ToggleMCPRequest {
   enabled: boolean;
 }
@@ -54,6 +70,12 @@ export const settingsApi = {
     return response.data;
   },
 
+  // Get SIEM forwarder status
+  getSIEMStatus: async (): Promise<SIEMStatus> => {
+    const response = await apiClient.get<SIEMStatus>("/settings/siem/status");
+    return response.data;
+  },
+
   // Get MCP server status
   getMCPStatus: async (): Promise<MCPStatus> => {
     const response = await apiClient.get<MCPStatus>("/settings/mcp/status");
@@ -2,6 +2,9 @@ import { apiClient } from "./client";
 import type {
   AffectedSystem,
   AssignVulnerabilityRequest,
+  BatchGetVulnerabilitiesResponse,
+  BulkTagVulnerabilitiesRequest,
+  BulkTagVulnerabilitiesResult,
   CreateVulnerabilityRequest,
   CreateVulnerabilityResponse,
   UpdateStatusRequest,
@@ -11,6 +14,7 @@ import type {
   VulnerabilityListResponse,
   VulnerabilityResponse,
   VulnerabilityStats,
+  VulnerabilityTagCount,
 } from "@/types/vulnerability";
 
 // Vulnerability API functions
@@ -192,4 +196,59 @@ export const vulnerabilityApi = {
     );
     return response.data;
   },
+
+  // Add tags to a vulnerability
+  addTags: async (
+    id: string,
+    tags: string[],
+  ): Promise<VulnerabilityResponse> => {
+    const response = await apiClient.post<VulnerabilityResponse>(
+      `/vulnerabilities/${id}/tags`,
+      { tags },
+    );
+    return response.data;
+  },
+
+  // Remove a tag from a vulnerability
+  removeTag: async (
+    id: string,
+    tag: string,
+  ): Promise<VulnerabilityResponse> => {
+    const response = await apiClient.delete<VulnerabilityResponse>(
+      `/vulnerabilities/${id}/tags/${encodeURIComponent(tag)}`,
+    );
+    return response.data;
+  },
+
+  // Add and remove tags across many vulnerabilities
+  bulkTag: async (
+    data: BulkTagVulnerabilitiesRequest,
+  ): Promise<{ data: BulkTagVulnerabilitiesResult }> => {
+    const response = await apiClient.post<{
+      data: BulkTagVulnerabilitiesResult;
+    }>("/vulnerabilities/tags/bulk", data);
+    return response.data;
+  },
+
+  // Get many vulnerabilities by ID in one request
+  batchGet: async (
+    ids: string[],
+  ): Promise<BatchGetVulnerabilitiesResponse> => {
+    const response = await apiClient.post<BatchGetVulnerabilitiesResponse>(
+      "/vulnerabilities/batch-get",
+      { ids },
+    );
+    return response.data;
+  },
+
+  // List tags in use, most used first
+  listTags: async (
+    search?: string,
+  ): Promise<{ data: VulnerabilityTagCount[] }> => {
+    const response = await apiClient.get<{ data: VulnerabilityTagCount[] }>(
+      "/vulnerabilities/tags",
+      { params: { search } },
+    );
+    return response.data;
+  },
 };
@@ -10,9 +10,12 @@ export interface APIKey {
   type: APIKeyType;
   status: APIKeyStatus;
   key_prefix: string;
+  secondary_expires_at?: string;
+  rotated_at?: string;
   scopes: string[];
   expires_at?: string;
   last_used_at?: string;
+  last_used_ip?: string;
   rate_limit_per_minute: number;
   description: string;
   created_at: string;
@@ -34,6 +37,41 @@ export interface CreateAPIKeyResponse {
   message: string;
 }
 
+export interface APIKeyDailyUsage {
+  day: string;
+  requests: number;
+  errors: number;
+  rate_limited: number;
+}
+
+export interface APIKeyEndpointUsage {
+  method: string;
+  route: string;
+  requests: number;
+  errors: number;
+  rate_limited: number;
+  last_ip: string;
+  last_used_at: string;
+}
+
+export interface APIKeyUsageSummary {
+  api_key_id: string;
+  rate_limit_per_minute: number;
+  since: string;
+  total_requests: number;
+  total_errors: number;
+  total_rate_limited: number;
+  last_used_at?: string;
+  last_used_ip?: string;
+  daily: APIKeyDailyUsage[];
+  endpoints: APIKeyEndpointUsage[];
+}
+
+export interface RotateAPIKeyRequest {
+  // Hours the previous secret stays valid (default 24, max 168, 0 revokes it immediately)
+  grace_period_hours?: number;
+}
+
 export interface UpdateAPIKeyStatusRequest {
   status: APIKeyStatus;
 }
@@ -45,12 +83,58 @@ export const API_KEY_SCOPES = {
     WRITE: "vulnerabilities:write",
     DELETE: "vulnerabilities:delete",
     STATS: "vulnerabilities:stats",
+    IMPORT: "vulnerabilities:import",
+  },
+  FINDINGS: {
+    READ: "findings:read",
+    WRITE: "findings:write",
   },
   ASSETS: {
     READ: "assets:read",
     WRITE: "assets:write",
     DELETE: "assets:delete",
   },
+  ASSESSMENTS: {
+    READ: "assessments:read",
+    WRITE: "assessments:write",
+    DELETE: "assessments:delete",
+  },
+  REPORTS: {
+    READ: "reports:read",
+    EXPORT: "reports:export",
+  },
+  INTEGRATIONS: {
+    READ: "integrations:read",
+    WRITE: "integrations:write",
+  },
+  DASHBOARD: {
+    READ: "dashboard:read",
+    WRITE: "dashboard:write",
+    WALLBOARD: "dashboard:wallboard",
+  },
+  AGENT: {
+    CHECKIN: "agent:checkin",
+  },
+  RULES: {
+    READ: "rules:read",
+    WRITE: "rules:write",
+  },
+  PROFILE: {
+    READ: "profile:read",
+    WRITE: "profile:write",
+  },
+  NOTIFICATIONS: {
+    READ: "notifications:read",
+    WRITE: "notifications:write",
+  },
+  SAVED_VIEWS: {
+    READ: "saved_views:read",
+    WRITE: "saved_views:write",
+  },
+  API_KEYS: {
+    READ: "api_keys:read",
+    WRITE: "api_keys:write",
+  },
   ADMIN: {
     ALL: "admin:*",
   },
@@ -67,26 +151,42 @@ export interface ScopeGroup {
 export const SCOPE_GROUPS: Record<string, ScopeGroup> = {
   READ_ONLY: {
     label: "Read Only",
-    description: "View vulnerabilities and assets without making changes",
+    description: "View vulnerabilities, findings, assets, assessments and reports without making changes",
     scopes: [
       API_KEY_SCOPES.VULNERABILITIES.READ,
       API_KEY_SCOPES.VULNERABILITIES.STATS,
+      API_KEY_SCOPES.FINDINGS.READ,
       API_KEY_SCOPES.ASSETS.READ,
+      API_KEY_SCOPES.ASSESSMENTS.READ,
+      API_KEY_SCOPES.REPORTS.READ,
     ],
   },
   FULL_ACCESS: {
     label: "Full Access",
-    description: "Complete access to vulnerabilities and assets",
+    description: "Complete access to vulnerabilities, findings, assets, assessments and reports",
     scopes: [
       API_KEY_SCOPES.VULNERABILITIES.READ,
       API_KEY_SCOPES.VULNERABILITIES.WRITE,
       API_KEY_SCOPES.VULNERABILITIES.DELETE,
       API_KEY_SCOPES.VULNERABILITIES.STATS,
+      API_KEY_SCOPES.VULNERABILITIES.IMPORT,
+      API_KEY_SCOPES.FINDINGS.READ,
+      API_KEY_SCOPES.FINDINGS.WRITE,
       API_KEY_SCOPES.ASSETS.READ,
       API_KEY_SCOPES.ASSETS.WRITE,
       API_KEY_SCOPES.ASSETS.DELETE,
+      API_KEY_SCOPES.ASSESSMENTS.READ,
+      API_KEY_SCOPES.ASSESSMENTS.WRITE,
+      API_KEY_SCOPES.ASSESSMENTS.DELETE,
+      API_KEY_SCOPES.REPORTS.READ,
+      API_KEY_SCOPES.REPORTS.EXPORT,
     ],
   },
+  AGENT: {
+    label: "Endpoint Agent",
+    description: "Report endpoint inventory from installed agents",
+    scopes: [API_KEY_SCOPES.AGENT.CHECKIN],
+  },
   ADMIN: {
     label: "Administrator",
     description: "Full administrative access to all resources",
@@ -34,8 +34,15 @@ export interface Session {
   expires_at: string;
   ip_address?: string;
   user_agent?: string;
+  browser?: string;
+  os?: string;
+  device_type?: "desktop" | "mobile" | "tablet" | "bot" | "unknown";
+  location?: string;
   is_active: boolean;
+  is_current?: boolean;
+  impersonated?: boolean;
   last_used_at?: string;
+  refreshed_at?: string;
   revoked_at?: string;
   created_at: string;
 }
@@ -56,13 +63,20 @@ export interface LoginRequest {
   email: string;
   password: string;
   two_factor_code?: string;
+  backup_code?: string;
 }
 
 export interface LoginResponse {
   message: string;
   user?: User;
+  // Short-lived access token; refresh_token obtains the next one
   token?: string;
+  expires_at?: string;
+  refresh_token?: string;
+  refresh_expires_at?: string;
+  session_id?: string;
   requires_two_factor?: boolean;
+  backup_codes_remaining?: number;
 }
 
 export interface VerifyEmailRequest {
@@ -197,6 +211,34 @@ export interface UpdateUserStatusRequest {
   email_verified?: boolean;
 }
 
+// Impersonation types
+export interface ImpersonateUserRequest {
+  reason: string;
+  // Defaults to 30 minutes, at most 60
+  duration_minutes?: number;
+}
+
+export interface ImpersonationInfo {
+  // Show the "acting as" banner while set
+  banner: boolean;
+  session_id: string;
+  user_id: string;
+  impersonator_id: string;
+  impersonator_email?: string;
+  impersonator_name?: string;
+  reason: string;
+  expires_at: string;
+}
+
+export interface ImpersonateUserResponse {
+  message: string;
+  token: string;
+  expires_at: string;
+  session_id: string;
+  user: User;
+  impersonation: ImpersonationInfo;
+}
+
 // Common types
 export interface PaginationParams {
   page?: number;
@@ -6,8 +6,10 @@ export interface AssessmentReport {
   mime_type: string;
   file_size: number;
   storage_path: string;
+  storage_backend: "local" | "s3" | "azure";
   title: string;
   description?: string;
+  generated: boolean;
   version: number;
   is_latest: boolean;
   parent_id?: string;
@@ -43,6 +45,15 @@ export interface UploadReportRequest {
   description?: string;
 }
 
+export type ReportFormat = "pdf" | "docx";
+
+export interface GenerateReportRequest {
+  format?: ReportFormat;
+  title?: string;
+  description?: string;
+  include_evidence?: boolean;
+}
+
 export interface AssessmentReportStats {
   total_count: number;
   latest_count: number;
@@ -50,3 +61,11 @@ export interface AssessmentReportStats {
   total_size_bytes: number;
   total_size_mb: number;
 }
+
+// Time-limited download URL issued by object storage backends (S3/MinIO, Azure)
+export interface DownloadURLResponse {
+  data: {
+    url: string;
+    expires_at: string;
+  };
+}
@@ -0,0 +1,77 @@
+export type RetestStatus =
+  | "READY_FOR_RETEST"
+  | "FIXED"
+  | "NOT_FIXED"
+  | "PARTIALLY_FIXED";
+
+export type RetestOutcome = Exclude<RetestStatus, "READY_FOR_RETEST">;
+
+interface RetestUser {
+  id: string;
+  email: string;
+  name: string;
+}
+
+export interface AssessmentRetest {
+  id: string;
+  assessment_id: string;
+  vulnerability_id: string;
+  vulnerability?: {
+    id: string;
+    title: string;
+    severity: string;
+  };
+  round: number;
+  status: RetestStatus;
+  requested_by_id: string;
+  requested_by?: RetestUser;
+  request_notes?: string;
+  retester_id?: string;
+  retester?: RetestUser;
+  retested_by_id?: string;
+  retested_by?: RetestUser;
+  retested_at?: string;
+  outcome_notes?: string;
+  evidence?: string;
+  evidence_attachment_ids?: string[];
+  created_at: string;
+  updated_at: string;
+}
+
+export interface AssessmentRetestListResponse {
+  data: AssessmentRetest[];
+  total: number;
+}
+
+// Current retest status of one finding; status is omitted when no retest was requested
+export interface FindingRetestStatus {
+  vulnerability_id: string;
+  status?: RetestStatus;
+  round?: number;
+  retest_id?: string;
+  retester_id?: string;
+  retested_at?: string;
+}
+
+export interface RetestSummary {
+  total_findings: number;
+  not_requested: number;
+  ready_for_retest: number;
+  fixed: number;
+  not_fixed: number;
+  partially_fixed: number;
+  findings: FindingRetestStatus[];
+}
+
+export interface RequestRetestRequest {
+  vulnerability_id: string;
+  retester_id?: string;
+  notes?: string;
+}
+
+export interface CompleteRetestRequest {
+  outcome: RetestOutcome;
+  notes?: string;
+  evidence?: string;
+  evidence_attachment_ids?: string[];
+}
@@ -5,7 +5,7 @@
 
 import type { User } from "./api";
 import type { Vulnerability } from "./vulnerability";
-import type { Asset } from "./asset";
+import type { Asset, AssetGroup } from "./asset";
 
 export type AssessmentType =
   | "INTERNAL_AUDIT"
@@ -43,6 +43,8 @@ export interface Assessment {
   created_by?: User;
   vulnerabilities?: Vulnerability[];
   assets?: Asset[];
+  // Members of linked groups are in scope too; see GET /assessments/:id/scope
+  asset_groups?: AssetGroup[];
   created_at: string; // ISO datetime format
   updated_at: string; // ISO datetime format
 }
@@ -57,6 +59,7 @@ export interface CreateAssessmentRequest {
   end_date?: string; // ISO date format
   vulnerability_ids?: string[];
   asset_ids?: string[];
+  asset_group_ids?: string[];
 }
 
 export interface UpdateAssessmentRequest {
@@ -48,6 +48,10 @@ export interface Asset {
   environment: Environment;
   criticality?: AssetCriticality;
   status: AssetStatus;
+  // Score from the organization's scoring profile; an override keeps a
+  // manually set criticality
+  criticality_score?: number;
+  criticality_override?: boolean;
 
   // Ownership fields
   owner_id?: string;
@@ -56,6 +60,14 @@ export interface Asset {
   location?: string;
   last_scan_date?: string;
 
+  // Endpoint agent inventory (set by agent check-ins)
+  agent_id?: string;
+  operating_system?: string;
+  ip_addresses?: string[];
+  last_seen_at?: string;
+  // No check-in within the stale threshold
+  agent_stale?: boolean;
+
   // Relationships
   tags?: AssetTag[];
   vulnerability_count?: number;
@@ -78,8 +90,12 @@ export interface AssetListParams {
   system_type?: SystemType;
   owner_id?: string;
   tags?: string[];
+  // Agent-managed assets that stopped (true) or kept (false) checking in
+  agent_stale?: boolean;
   sort_by?: string;
   sort_order?: "ASC" | "DESC";
+  fields?: string; // Comma-separated fields to return; id is always included
+  include?: string; // Comma-separated relations to load, e.g. "owner_team"
 }
 
 // Asset list response
@@ -125,6 +141,7 @@ export interface CreateAssetRequest {
   description?: string;
   environment: Environment;
   criticality?: AssetCriticality;
+  criticality_override?: boolean;
   status?: AssetStatus;
   owner_id?: string;
   department?: string;
@@ -147,7 +164,9 @@ export interface UpdateAssetRequest {
   system_type?: SystemType;
   description?: string;
   environment?: Environment;
+  // Setting criticality without this flag also sets the override
   criticality?: AssetCriticality;
+  criticality_override?: boolean;
   status?: AssetStatus;
   owner_id?: string;
   department?: string;
@@ -193,3 +212,264 @@ export interface UpdateAssetStatusRequest {
   status: AssetStatus;
   notes?: string;
 }
+
+// Package installed on an asset, as last reported by its agent
+export interface AssetPackage {
+  asset_id: string;
+  name: string;
+  version: string;
+  architecture?: string;
+  reported_at: string;
+}
+
+export interface AssetPackagesResponse {
+  data: AssetPackage[];
+  meta: {
+    page: number;
+    limit: number;
+    total: number;
+  };
+}
+
+// Software components from the SBOM last uploaded for an asset
+export interface AssetSBOMComponent {
+  id: string;
+  asset_id: string;
+  bom_ref?: string;
+  type?: string;
+  group?: string;
+  name: string;
+  version?: string;
+  purl?: string;
+  cpe?: string;
+  licenses?: string[];
+  supplier?: string;
+  advisories?: string[];
+  sbom_format: "cyclonedx" | "spdx";
+  imported_at: string;
+}
+
+export interface AssetSBOMComponentsResponse {
+  data: AssetSBOMComponent[];
+  meta: {
+    page: number;
+    limit: number;
+    total: number;
+  };
+}
+
+export interface SBOMImportResult {
+  format: "cyclonedx" | "spdx";
+  spec_version?: string;
+  components: number;
+  vulnerable_components: number;
+  created_vulnerabilities: number;
+  created_findings: number;
+  updated_findings: number;
+  suppressed_findings: number;
+  warnings?: string[];
+}
+
+// Asset change history; field updates produce one entry per changed field
+export type AssetHistoryAction =
+  | "UPDATED"
+  | "STATUS_CHANGED"
+  | "TAG_ADDED"
+  | "TAG_REMOVED"
+  | "DELETED";
+
+export interface AssetHistoryEntry {
+  id: string;
+  asset_id: string;
+  action: AssetHistoryAction;
+  field?: string;
+  old_value?: string;
+  new_value?: string;
+  // Decommission reason for status changes to DECOMMISSIONED
+  notes?: string;
+  changed_by_id?: string;
+  changed_by?: User;
+  changed_at: string;
+}
+
+export interface AssetHistoryResponse {
+  data: AssetHistoryEntry[];
+  meta: {
+    page: number;
+    limit: number;
+    total: number;
+  };
+}
+
+// Asset groups: static members plus assets matching every populated rule
+// (any listed value within a rule matches)
+export interface AssetGroup {
+  id: string;
+  name: string;
+  description?: string;
+  rule_tags: string[];
+  rule_environments: Environment[];
+  rule_system_types: SystemType[];
+  rule_cidrs: string[];
+  membership_computed_at?: string;
+  member_count: number;
+  created_by_id: string;
+  created_by?: User;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface AssetGroupRequest {
+  name?: string;
+  description?: string;
+  rule_tags?: string[];
+  rule_environments?: Environment[];
+  rule_system_types?: SystemType[];
+  rule_cidrs?: string[];
+  // Static members, create only
+  asset_ids?: string[];
+}
+
+export interface AssetGroupMembersResponse {
+  data: Asset[];
+  meta: {
+    page: number;
+    limit: number;
+    total: number;
+  };
+}
+
+// Business services: applications and the assets that run them. The tier
+// feeds asset criticality scoring and orders the executive report.
+export type BusinessServiceTier = "TIER_1" | "TIER_2" | "TIER_3" | "TIER_4";
+
+export interface BusinessService {
+  id: string;
+  name: string;
+  description?: string;
+  tier: BusinessServiceTier;
+  owner_id?: string;
+  owner?: User;
+  owner_team_id?: string;
+  asset_count: number;
+  created_by_id: string;
+  created_by?: User;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface BusinessServiceRequest {
+  name?: string;
+  description?: string;
+  tier?: BusinessServiceTier;
+  // Send the nil UUID to clear an owner
+  owner_id?: string;
+  owner_team_id?: string;
+  // Assets to link, create only
+  asset_ids?: string[];
+}
+
+export interface BusinessServiceAssetsResponse {
+  data: Asset[];
+  meta: {
+    page: number;
+    limit: number;
+    total: number;
+  };
+}
+
+// Open findings on a business service's active assets
+export interface BusinessServiceRisk {
+  service_id: string;
+  name: string;
+  tier: BusinessServiceTier;
+  owner_id?: string;
+  owner_team_id?: string;
+  asset_count: number;
+  affected_assets: number;
+  open_findings: number;
+  open_vulnerabilities: number;
+  findings_by_severity: Record<string, number>;
+  oldest_open_finding?: string;
+  risk_score: number;
+  security_posture: string;
+}
+
+// Network ranges: imported hosts inside a range inherit its environment,
+// location and owner (most specific range wins)
+export interface NetworkRange {
+  id: string;
+  name: string;
+  cidr: string;
+  description?: string;
+  environment: Environment;
+  location?: string;
+  owner_id?: string;
+  owner?: User;
+  owner_team_id?: string;
+  created_by_id: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface NetworkRangeRequest {
+  name?: string;
+  cidr?: string;
+  description?: string;
+  environment?: Environment;
+  location?: string;
+  // Send the nil UUID to clear an owner
+  owner_id?: string;
+  owner_team_id?: string;
+}
+
+// Criticality scoring: environment, exposure, business services and tags add
+// points, and the thresholds map the total to a criticality
+export interface CriticalityThresholds {
+  critical: number;
+  high: number;
+  medium: number;
+}
+
+export interface CriticalityWeights {
+  environments: Record<string, number>;
+  tags: Record<string, number>;
+  public_ip: number;
+  // Keyed by tier; only the asset's highest-weighted service counts
+  business_tiers: Record<string, number>;
+  thresholds: CriticalityThresholds;
+}
+
+export interface CriticalityScoringProfile {
+  id: string;
+  org_id?: string;
+  enabled: boolean;
+  weights: CriticalityWeights;
+  updated_by_id?: string;
+  updated_by?: User;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface CriticalityProfileRequest {
+  enabled?: boolean;
+  weights?: CriticalityWeights;
+}
+
+export interface CriticalityScoreFactor {
+  factor: "environment" | "public_ip" | "business_service" | "tag";
+  value: string;
+  points: number;
+}
+
+export interface AssetCriticalityScore {
+  asset_id: string;
+  score: number;
+  scored_criticality: AssetCriticality;
+  current_criticality?: AssetCriticality;
+  // Criticality was set manually and is not rescored
+  override: boolean;
+  scoring_enabled: boolean;
+  factors: CriticalityScoreFactor[];
+  thresholds: CriticalityThresholds;
+}
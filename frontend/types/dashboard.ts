@@ -0,0 +1,118 @@
+export type DashboardWidgetType =
+  | "severity_donut"
+  | "trend_line"
+  | "top_risky_assets"
+  | "sla_status";
+
+export interface DashboardWidgetOptions {
+  days?: number; // trend_line
+  limit?: number; // top_risky_assets
+  environment?: string;
+}
+
+export interface DashboardWidgetLayout {
+  x: number;
+  y: number;
+  w: number;
+  h: number;
+}
+
+export interface DashboardWidget {
+  id?: string; // Assigned by the server for new widgets
+  type: DashboardWidgetType;
+  title?: string;
+  options?: DashboardWidgetOptions;
+  layout?: DashboardWidgetLayout;
+}
+
+// A user-composed dashboard
+export interface Dashboard {
+  id: string;
+  name: string;
+  description?: string;
+  widgets: DashboardWidget[];
+  shared: boolean;
+  owner_id: string;
+  owner?: {
+    id: string;
+    email: string;
+    name: string;
+  };
+  created_at: string;
+  updated_at: string;
+}
+
+export interface DashboardRequest {
+  name?: string;
+  description?: string;
+  widgets?: DashboardWidget[];
+  shared?: boolean;
+}
+
+export interface SeverityCount {
+  severity: string;
+  count: number;
+}
+
+export interface TrendLinePoint {
+  date: string;
+  open_count: number;
+  critical_count: number;
+  high_count: number;
+  new_vulnerabilities: number;
+  resolved_vulnerabilities: number;
+}
+
+export interface RiskyAsset {
+  id: string;
+  hostname?: string;
+  ip_address?: string;
+  environment: string;
+  criticality?: string;
+  open_vulnerabilities: number;
+  critical: number;
+  high: number;
+  medium: number;
+  low: number;
+  risk_score: number;
+}
+
+export interface SLAStatusRow {
+  severity: string;
+  target_days: number;
+  open: number;
+  within_sla: number;
+  due_soon: number;
+  breached: number;
+  compliance_rate: number;
+}
+
+export interface SLAStatus {
+  severities: SLAStatusRow[];
+  open: number;
+  breached: number;
+  compliance_rate: number;
+}
+
+export type DashboardWidgetData =
+  | {
+      id: string;
+      type: "severity_donut";
+      title?: string;
+      data: SeverityCount[];
+    }
+  | { id: string; type: "trend_line"; title?: string; data: TrendLinePoint[] }
+  | {
+      id: string;
+      type: "top_risky_assets";
+      title?: string;
+      data: RiskyAsset[];
+    }
+  | { id: string; type: "sla_status"; title?: string; data: SLAStatus };
+
+// Datasets of every widget of a dashboard, in widget order
+export interface DashboardData {
+  dashboard_id: string;
+  generated_at: string;
+  widgets: DashboardWidgetData[];
+}
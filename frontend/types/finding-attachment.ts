@@ -8,6 +8,9 @@ export interface FindingAttachment {
   mime_type: string;
   file_size: number;
   storage_path: string;
+  storage_backend: "local" | "s3" | "azure";
+  sha256?: string; // Hash of the stored file, recorded at upload
+  original_sha256?: string; // Hash of the file as uploaded
   is_image: boolean;
   width?: number;
   height?: number;
@@ -25,6 +28,48 @@ export interface FindingAttachment {
   updated_at: string;
 }
 
+export type AttachmentCustodyAction =
+  | "UPLOADED"
+  | "VERIFIED"
+  | "MODIFIED"
+  | "MISSING"
+  | "DELETED";
+
+// One event in an attachment's chain of custody
+export interface AttachmentCustodyEvent {
+  id: string;
+  attachment_id: string;
+  finding_id: string;
+  action: AttachmentCustodyAction;
+  sha256?: string;
+  notes?: string;
+  actor_id?: string;
+  actor?: {
+    id: string;
+    name: string;
+    email: string;
+  };
+  created_at: string;
+}
+
+export type AttachmentIntegrityStatus =
+  | "INTACT"
+  | "MODIFIED"
+  | "MISSING"
+  | "UNHASHED";
+
+export interface AttachmentIntegrityResult {
+  attachment_id: string;
+  finding_id: string;
+  status: AttachmentIntegrityStatus;
+  expected_sha256?: string;
+  actual_sha256?: string;
+  original_sha256?: string;
+  uploaded_by: string;
+  uploaded_at: string;
+  verified_at: string;
+}
+
 export interface AttachmentStats {
   total_count: number;
   total_size_bytes: number;
@@ -34,6 +79,17 @@ export interface AttachmentStats {
   verification_count: number;
 }
 
+// Upload limits of one attachment category (server-enforced)
+export interface AttachmentCategoryPolicy {
+  max_size_bytes: number;
+  allowed_mime_types: string[];
+}
+
+// Effective attachment policy, keyed by attachment type plus "REPORT"
+export interface AttachmentPolicy {
+  categories: Record<string, AttachmentCategoryPolicy>;
+}
+
 export interface UploadAttachmentRequest {
   file: File;
   attachment_type: AttachmentType;
@@ -0,0 +1,78 @@
+export type PolicyViolationStatus = "OPEN" | "RESOLVED";
+
+// A remediation policy, e.g. no CRITICAL vulnerability open longer than 7 days in PRODUCTION
+export interface PolicyRule {
+  id: string;
+  name: string;
+  description?: string;
+  enabled: boolean;
+  severity: string;
+  max_age_days: number;
+  environment?: string;
+  criticality?: string;
+  last_evaluated_at?: string;
+  created_by_id: string;
+  created_by?: {
+    id: string;
+    email: string;
+    name: string;
+  };
+  created_at: string;
+  updated_at: string;
+}
+
+export interface PolicyRuleRequest {
+  name?: string;
+  description?: string;
+  enabled?: boolean;
+  severity?: string;
+  max_age_days?: number;
+  environment?: string;
+  criticality?: string;
+}
+
+export interface PolicyViolation {
+  id: string;
+  rule_id: string;
+  rule?: PolicyRule;
+  vulnerability_id: string;
+  vulnerability?: {
+    id: string;
+    title: string;
+    severity: string;
+    status: string;
+  };
+  status: PolicyViolationStatus;
+  severity: string;
+  discovered_at: string;
+  due_at: string;
+  detected_at: string;
+  resolved_at?: string;
+  resolution?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface PolicyViolationListParams {
+  status?: PolicyViolationStatus;
+  rule_id?: string;
+  vulnerability_id?: string;
+  severity?: string;
+  page?: number;
+  limit?: number;
+}
+
+export interface PolicyViolationListResponse {
+  data: PolicyViolation[];
+  meta: {
+    page: number;
+    limit: number;
+    total: number;
+  };
+}
+
+export interface PolicyEvaluationResult {
+  rules_evaluated: number;
+  opened: number;
+  resolved: number;
+}
@@ -0,0 +1,58 @@
+export interface MTTRStats {
+  remediated: number;
+  average_days: number;
+  median_days: number;
+}
+
+// Time to remediate of one severity, owning team ("" key for no team) or month (YYYY-MM)
+export interface MTTRGroup extends MTTRStats {
+  key: string;
+  label: string;
+}
+
+export interface MTTRAnalytics {
+  overall: MTTRStats;
+  by_severity: MTTRGroup[];
+  by_team: MTTRGroup[];
+  by_month: MTTRGroup[];
+}
+
+export interface AgingBucket {
+  label: string;
+  min_days: number;
+  max_days?: number;
+  total: number;
+  by_severity: Record<string, number>;
+}
+
+export interface AgingAnalytics {
+  as_of: string;
+  total_open: number;
+  average_age_days: number;
+  oldest_age_days: number;
+  buckets: AgingBucket[];
+}
+
+export interface BurnDownPoint {
+  date: string;
+  opened: number;
+  closed: number;
+  reopened: number;
+  open: number;
+}
+
+export interface BurnDownAnalytics {
+  interval: "day" | "week";
+  start_open: number;
+  end_open: number;
+  points: BurnDownPoint[];
+}
+
+export interface RemediationAnalytics {
+  generated_at: string;
+  period_start: string;
+  period_end: string;
+  mttr: MTTRAnalytics;
+  aging: AgingAnalytics;
+  burn_down: BurnDownAnalytics;
+}
@@ -0,0 +1,18 @@
+// Natural-language executive summary and key-risk narrative written by the
+// language model configured in the report_summarizer system setting.
+export type ReportSummaryKind = "executive" | "assessment";
+
+export interface ReportSummary {
+  id: string;
+  kind: ReportSummaryKind;
+  period_start?: string;
+  period_end?: string;
+  assessment_id?: string;
+  executive_summary: string;
+  key_risks: string;
+  provider: string;
+  model: string;
+  generated_by_id: string;
+  created_at: string;
+  updated_at: string;
+}
@@ -8,6 +8,7 @@ export interface VulnerabilityAttachment {
   mime_type: string;
   file_size: number;
   storage_path: string;
+  storage_backend: "local" | "s3" | "azure";
   is_image: boolean;
   width?: number;
   height?: number;
@@ -47,10 +47,43 @@ export interface Vulnerability {
   mitigation_recommendations?: string | null;
   created_by_id: string;
   assigned_to_id?: string | null;
+  tags?: VulnerabilityTag[];
   created_at: string; // ISO datetime format
   updated_at: string; // ISO datetime format
 }
 
+export interface VulnerabilityTag {
+  vulnerability_id: string;
+  tag: string;
+  created_at: string;
+}
+
+export interface VulnerabilityTagCount {
+  tag: string;
+  count: number;
+}
+
+export interface BulkTagVulnerabilitiesRequest {
+  vulnerability_ids: string[];
+  add?: string[];
+  remove?: string[];
+}
+
+export interface BulkTagVulnerabilitiesResult {
+  vulnerabilities: number;
+  tags_added: number;
+  tags_removed: number;
+}
+
+// Batch get by IDs (at most 500); missing or hidden IDs are listed in not_found
+export interface BatchGetVulnerabilitiesResponse {
+  data: VulnerabilityDetail[];
+  meta: {
+    found: number;
+    not_found: string[];
+  };
+}
+
 export interface VulnerabilityDetail extends Vulnerability {
   created_by?: User;
   assigned_to?: User;
@@ -187,10 +220,14 @@ export interface VulnerabilityListParams {
   assignedTo?: string;
   createdBy?: string;
   asset_id?: string; // Filter by affected system/asset
+  asset_group_id?: string; // Filter by asset group membership
+  tags?: string; // Comma-separated; vulnerabilities must carry every tag
   discoveryDateFrom?: string;
   discoveryDateTo?: string;
   sortBy?: "discovery_date" | "severity" | "created_at" | "updated_at";
   sortOrder?: "asc" | "desc";
+  fields?: string; // Comma-separated fields to return; id is always included
+  include?: string; // Comma-separated relations to load, e.g. "assigned_to"
 }
 
 export interface PaginationMeta {
@@ -382,12 +419,15 @@ export interface FindingListParams {
   limit?: number;
   vulnerability_id?: string;
   affected_system_id?: string;
+  asset_group_id?: string; // Filter by asset group membership
   status?: string; // Comma-separated status values
   scanner_name?: string;
   port?: string;
   protocol?: string;
   sortBy?: "first_detected" | "last_seen" | "status";
   sortOrder?: "asc" | "desc";
+  fields?: string; // Comma-separated fields to return; id is always included
+  include?: string; // Comma-separated relations to load, e.g. "comments.author"
 }
 
 export interface FindingListResponse {